@@ -0,0 +1,13 @@
+const { ethers } = require("hardhat");
+
+module.exports = async function (hre) {
+  const { deployments, getNamedAccounts } = hre;
+  const { deployer } = await getNamedAccounts();
+  var keyperConfig = await ethers.getContract("KeyperConfig");
+  await deployments.deploy("KeyperRotations", {
+    contract: "KeyperRotations",
+    from: deployer,
+    args: [keyperConfig.address],
+    log: true,
+  });
+};
@@ -0,0 +1,12 @@
+const { ethers } = require("hardhat");
+
+module.exports = async function (hre) {
+  const { deployments, getNamedAccounts } = hre;
+  const { deployer } = await getNamedAccounts();
+  await deployments.deploy("KeyperBonds", {
+    contract: "KeyperBonds",
+    from: deployer,
+    args: [ethers.utils.parseEther("1"), 100],
+    log: true,
+  });
+};
@@ -0,0 +1,10 @@
+module.exports = async function (hre) {
+  const { deployments, getNamedAccounts } = hre;
+  const { deployer } = await getNamedAccounts();
+  await deployments.deploy("VersionRequirements", {
+    contract: "VersionRequirements",
+    from: deployer,
+    args: ["v0.0.0", 1],
+    log: true,
+  });
+};
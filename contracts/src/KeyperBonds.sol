@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: MIT
+
+pragma solidity =0.8.9;
+
+import "@openzeppelin/contracts/access/Ownable.sol";
+
+/**
+@title KeyperBonds holds the bonds keypers put up for participating in the keyper set
+
+@dev Keypers bond ether by calling bond. Unbonding is delayed: unbond moves an amount out of the
+bond immediately, but it can only be withdrawn once the unbonding period has passed. Further
+unbonds add to the unbonding amount and restart the period. Every change emits BondChanged with the
+full state of the keyper's bond, so that nodes can sync it without reading storage.
+*/
+contract KeyperBonds is Ownable {
+    struct Bond {
+        uint256 bonded;
+        uint256 unbonding;
+        uint64 unbondingBlock;
+    }
+
+    uint256 public minimumBond;
+    uint64 public unbondingPeriod; // in blocks
+    mapping(address => Bond) public bonds;
+
+    event BondChanged(
+        address keyper,
+        uint256 bonded,
+        uint256 unbonding,
+        uint64 unbondingBlock
+    );
+    event MinimumBondChanged(uint256 minimumBond);
+
+    constructor(uint256 _minimumBond, uint64 _unbondingPeriod) {
+        minimumBond = _minimumBond;
+        unbondingPeriod = _unbondingPeriod;
+        emit MinimumBondChanged(_minimumBond);
+    }
+
+    /**
+       @notice setMinimumBond sets the bond keypers are required to hold.
+     */
+    function setMinimumBond(uint256 _minimumBond) public onlyOwner {
+        minimumBond = _minimumBond;
+        emit MinimumBondChanged(_minimumBond);
+    }
+
+    /**
+       @notice bond adds the sent value to the bond of the sender.
+     */
+    function bond() public payable {
+        require(msg.value > 0, "KeyperBonds.bond: value is zero");
+        Bond storage b = bonds[msg.sender];
+        b.bonded += msg.value;
+        emitBondChanged(msg.sender, b);
+    }
+
+    /**
+       @notice unbond starts unbonding amount of the sender's bond.
+     */
+    function unbond(uint256 amount) public {
+        Bond storage b = bonds[msg.sender];
+        require(amount > 0, "KeyperBonds.unbond: amount is zero");
+        require(
+            amount <= b.bonded,
+            "KeyperBonds.unbond: amount exceeds bond"
+        );
+        b.bonded -= amount;
+        b.unbonding += amount;
+        b.unbondingBlock = uint64(block.number) + unbondingPeriod;
+        emitBondChanged(msg.sender, b);
+    }
+
+    /**
+       @notice withdraw sends the unbonded amount to the sender once the unbonding period has
+       passed.
+     */
+    function withdraw() public {
+        Bond storage b = bonds[msg.sender];
+        require(b.unbonding > 0, "KeyperBonds.withdraw: nothing to withdraw");
+        require(
+            block.number >= b.unbondingBlock,
+            "KeyperBonds.withdraw: unbonding period has not passed"
+        );
+        uint256 amount = b.unbonding;
+        b.unbonding = 0;
+        b.unbondingBlock = 0;
+        emitBondChanged(msg.sender, b);
+        payable(msg.sender).transfer(amount);
+    }
+
+    function emitBondChanged(address keyper, Bond storage b) private {
+        emit BondChanged({
+            keyper: keyper,
+            bonded: b.bonded,
+            unbonding: b.unbonding,
+            unbondingBlock: b.unbondingBlock
+        });
+    }
+}
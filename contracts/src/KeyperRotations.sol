@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: MIT
+
+pragma solidity =0.8.9;
+
+import "./AddrsSeq.sol";
+import "./KeypersConfigsList.sol";
+import "@openzeppelin/contracts/access/Ownable.sol";
+import "@openzeppelin/contracts/utils/cryptography/ECDSA.sol";
+
+/**
+@title KeyperRotations records rotations of keyper addresses
+
+@dev A keyper can move to a new Ethereum address without running a new DKG. The rotation is signed
+by both the old and the new address, which links the two. Nodes observing the Rotated event replace
+the old address by the new one in the keyper set of the config, while the keyper keeps its index
+and thereby its eon key share. The address lists stored in AddrsSeq are never changed.
+*/
+contract KeyperRotations is Ownable {
+    KeypersConfigsList public keypersConfigsList;
+    AddrsSeq public addrsSeq;
+
+    // rotated maps keyper config index and keyper index to the current address of keypers that
+    // have been rotated
+    mapping(uint64 => mapping(uint64 => address)) private rotated;
+
+    event Rotated(
+        uint64 keyperConfigIndex,
+        uint64 keyperIndex,
+        address oldAddress,
+        address newAddress
+    );
+
+    constructor(KeypersConfigsList _keypersConfigsList) {
+        keypersConfigsList = _keypersConfigsList;
+        addrsSeq = _keypersConfigsList.addrsSeq();
+    }
+
+    /**
+       @notice keyperAt returns the current address of the keyper at index keyperIndex in the
+       config at index keyperConfigIndex, taking rotations into account.
+     */
+    function keyperAt(
+        uint64 keyperConfigIndex,
+        uint64 keyperIndex
+    ) public view returns (address) {
+        address current = rotated[keyperConfigIndex][keyperIndex];
+        if (current != address(0)) {
+            return current;
+        }
+        (, uint64 setIndex, ) = keypersConfigsList.keypersConfigs(
+            keyperConfigIndex
+        );
+        return addrsSeq.at(setIndex, keyperIndex);
+    }
+
+    /**
+       @notice rotationHash returns the hash both addresses have to sign to rotate a keyper.
+     */
+    function rotationHash(
+        uint64 keyperConfigIndex,
+        uint64 keyperIndex,
+        address oldAddress,
+        address newAddress
+    ) public view returns (bytes32) {
+        return
+            keccak256(
+                abi.encodePacked(
+                    bytes1(0x19),
+                    "keyperRotation",
+                    block.chainid,
+                    address(this),
+                    keyperConfigIndex,
+                    keyperIndex,
+                    oldAddress,
+                    newAddress
+                )
+            );
+    }
+
+    /**
+       @notice rotate replaces the current address of a keyper by newAddress.
+     */
+    function rotate(
+        uint64 keyperConfigIndex,
+        uint64 keyperIndex,
+        address newAddress,
+        bytes calldata oldSignature,
+        bytes calldata newSignature
+    ) public onlyOwner {
+        require(
+            newAddress != address(0),
+            "KeyperRotations.rotate: new address is zero"
+        );
+        address oldAddress = keyperAt(keyperConfigIndex, keyperIndex);
+        require(
+            newAddress != oldAddress,
+            "KeyperRotations.rotate: new address equals old address"
+        );
+        bytes32 hash = rotationHash(
+            keyperConfigIndex,
+            keyperIndex,
+            oldAddress,
+            newAddress
+        );
+        require(
+            ECDSA.recover(hash, oldSignature) == oldAddress,
+            "KeyperRotations.rotate: invalid signature of old address"
+        );
+        require(
+            ECDSA.recover(hash, newSignature) == newAddress,
+            "KeyperRotations.rotate: invalid signature of new address"
+        );
+        rotated[keyperConfigIndex][keyperIndex] = newAddress;
+        emit Rotated({
+            keyperConfigIndex: keyperConfigIndex,
+            keyperIndex: keyperIndex,
+            oldAddress: oldAddress,
+            newAddress: newAddress
+        });
+    }
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: MIT
+
+pragma solidity =0.8.9;
+
+import "@openzeppelin/contracts/access/Ownable.sol";
+
+/**
+@title VersionRequirements announces the minimum node software and p2p protocol versions
+
+@dev The owner raises the minimum versions ahead of upgrades that are incompatible with older nodes.
+Nodes sync the MinimumVersionChanged events, warn their operators when they fall below the
+minimum, and can be configured to refuse to take part in new eons until they have been upgraded.
+The software version is a semantic version string like "v1.2.3".
+*/
+contract VersionRequirements is Ownable {
+    string public minimumVersion;
+    uint64 public minimumProtocolVersion;
+
+    event MinimumVersionChanged(
+        string minimumVersion,
+        uint64 minimumProtocolVersion
+    );
+
+    constructor(
+        string memory _minimumVersion,
+        uint64 _minimumProtocolVersion
+    ) {
+        setMinimumVersion(_minimumVersion, _minimumProtocolVersion);
+    }
+
+    /**
+       @notice setMinimumVersion sets the minimum software and protocol versions nodes are
+       required to run.
+     */
+    function setMinimumVersion(
+        string memory _minimumVersion,
+        uint64 _minimumProtocolVersion
+    ) public onlyOwner {
+        require(
+            bytes(_minimumVersion).length > 0,
+            "VersionRequirements.setMinimumVersion: version is empty"
+        );
+        minimumVersion = _minimumVersion;
+        minimumProtocolVersion = _minimumProtocolVersion;
+        emit MinimumVersionChanged(_minimumVersion, _minimumProtocolVersion);
+    }
+}
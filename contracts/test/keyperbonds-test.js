@@ -0,0 +1,62 @@
+const { expect } = require("chai");
+const { ethers } = require("hardhat");
+
+const unbondingPeriod = 5;
+
+async function deploy() {
+  const factory = await ethers.getContractFactory("KeyperBonds");
+  const bonds = await factory.deploy(100, unbondingPeriod);
+  await bonds.deployed();
+  return bonds;
+}
+
+describe("KeyperBonds", function () {
+  it("bonding and unbonding should emit the bond state", async function () {
+    const bonds = await deploy();
+    const [keyper] = await ethers.getSigners();
+
+    await expect(bonds.bond({ value: 150 }))
+      .to.emit(bonds, "BondChanged")
+      .withArgs(keyper.address, 150, 0, 0);
+
+    const tx = await bonds.unbond(60);
+    const receipt = await tx.wait();
+    await expect(tx)
+      .to.emit(bonds, "BondChanged")
+      .withArgs(keyper.address, 90, 60, receipt.blockNumber + unbondingPeriod);
+    await expect(bonds.unbond(91)).to.be.revertedWith(
+      "KeyperBonds.unbond: amount exceeds bond"
+    );
+  });
+
+  it("should only allow withdrawing after the unbonding period", async function () {
+    const bonds = await deploy();
+    const [keyper] = await ethers.getSigners();
+    await bonds.bond({ value: 100 });
+    await bonds.unbond(100);
+
+    await expect(bonds.withdraw()).to.be.revertedWith(
+      "KeyperBonds.withdraw: unbonding period has not passed"
+    );
+    for (let i = 0; i < unbondingPeriod; i++) {
+      await ethers.provider.send("evm_mine", []);
+    }
+    await expect(bonds.withdraw())
+      .to.emit(bonds, "BondChanged")
+      .withArgs(keyper.address, 0, 0, 0);
+    await expect(bonds.withdraw()).to.be.revertedWith(
+      "KeyperBonds.withdraw: nothing to withdraw"
+    );
+  });
+
+  it("should only allow the owner to set the minimum bond", async function () {
+    const bonds = await deploy();
+    const [, other] = await ethers.getSigners();
+    await expect(bonds.setMinimumBond(200))
+      .to.emit(bonds, "MinimumBondChanged")
+      .withArgs(200);
+    await expect(bonds.connect(other).setMinimumBond(0)).to.be.revertedWith(
+      "Ownable: caller is not the owner"
+    );
+  });
+});
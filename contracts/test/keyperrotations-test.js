@@ -0,0 +1,125 @@
+const { expect } = require("chai");
+const { ethers } = require("hardhat");
+
+async function deploy(keypers) {
+  const addrsSeqFactory = await ethers.getContractFactory("AddrsSeq");
+  const addrsSeq = await addrsSeqFactory.deploy();
+  await addrsSeq.append();
+  await addrsSeq.add(keypers.map((k) => k.address));
+  await addrsSeq.append();
+
+  const configsFactory = await ethers.getContractFactory("KeypersConfigsList");
+  const configs = await configsFactory.deploy(addrsSeq.address);
+  await configs.addNewCfg({
+    activationBlockNumber: 100,
+    setIndex: 1,
+    threshold: 2,
+  });
+
+  const rotationsFactory = await ethers.getContractFactory("KeyperRotations");
+  const rotations = await rotationsFactory.deploy(configs.address);
+  await rotations.deployed();
+  return rotations;
+}
+
+async function sign(rotations, configIndex, keyperIndex, oldWallet, newWallet) {
+  const hash = await rotations.rotationHash(
+    configIndex,
+    keyperIndex,
+    oldWallet.address,
+    newWallet.address
+  );
+  const key = new ethers.utils.SigningKey(oldWallet.privateKey);
+  const newKey = new ethers.utils.SigningKey(newWallet.privateKey);
+  return [
+    ethers.utils.joinSignature(key.signDigest(hash)),
+    ethers.utils.joinSignature(newKey.signDigest(hash)),
+  ];
+}
+
+describe("KeyperRotations", function () {
+  const keypers = [
+    ethers.Wallet.createRandom(),
+    ethers.Wallet.createRandom(),
+    ethers.Wallet.createRandom(),
+  ];
+
+  it("rotating a keyper should emit an event", async function () {
+    const rotations = await deploy(keypers);
+    const newWallet = ethers.Wallet.createRandom();
+    const [oldSig, newSig] = await sign(
+      rotations,
+      1,
+      1,
+      keypers[1],
+      newWallet
+    );
+
+    expect(await rotations.keyperAt(1, 1)).to.equal(keypers[1].address);
+    await expect(rotations.rotate(1, 1, newWallet.address, oldSig, newSig))
+      .to.emit(rotations, "Rotated")
+      .withArgs(1, 1, keypers[1].address, newWallet.address);
+    expect(await rotations.keyperAt(1, 1)).to.equal(newWallet.address);
+    expect(await rotations.keyperAt(1, 0)).to.equal(keypers[0].address);
+
+    // the rotated address can be rotated again
+    const newerWallet = ethers.Wallet.createRandom();
+    const [oldSig2, newSig2] = await sign(
+      rotations,
+      1,
+      1,
+      newWallet,
+      newerWallet
+    );
+    await expect(rotations.rotate(1, 1, newerWallet.address, oldSig2, newSig2))
+      .to.emit(rotations, "Rotated")
+      .withArgs(1, 1, newWallet.address, newerWallet.address);
+  });
+
+  it("should require signatures of both addresses", async function () {
+    const rotations = await deploy(keypers);
+    const newWallet = ethers.Wallet.createRandom();
+    const [oldSig, newSig] = await sign(
+      rotations,
+      1,
+      0,
+      keypers[0],
+      newWallet
+    );
+
+    await expect(
+      rotations.rotate(1, 0, newWallet.address, newSig, newSig)
+    ).to.be.revertedWith(
+      "KeyperRotations.rotate: invalid signature of old address"
+    );
+    await expect(
+      rotations.rotate(1, 0, newWallet.address, oldSig, oldSig)
+    ).to.be.revertedWith(
+      "KeyperRotations.rotate: invalid signature of new address"
+    );
+    // signatures are bound to the keyper index
+    await expect(
+      rotations.rotate(1, 1, newWallet.address, oldSig, newSig)
+    ).to.be.revertedWith(
+      "KeyperRotations.rotate: invalid signature of old address"
+    );
+  });
+
+  it("should only be callable by the owner", async function () {
+    const rotations = await deploy(keypers);
+    const [, other] = await ethers.getSigners();
+    const newWallet = ethers.Wallet.createRandom();
+    const [oldSig, newSig] = await sign(
+      rotations,
+      1,
+      2,
+      keypers[2],
+      newWallet
+    );
+    await expect(
+      rotations
+        .connect(other)
+        .rotate(1, 2, newWallet.address, oldSig, newSig)
+    ).to.be.revertedWith("Ownable: caller is not the owner");
+  });
+});
@@ -0,0 +1,38 @@
+const { expect } = require("chai");
+const { ethers } = require("hardhat");
+
+async function deploy() {
+  const factory = await ethers.getContractFactory("VersionRequirements");
+  const requirements = await factory.deploy("v1.0.0", 1);
+  await requirements.deployed();
+  return requirements;
+}
+
+describe("VersionRequirements", function () {
+  it("should emit the initial minimum version", async function () {
+    const requirements = await deploy();
+    await expect(requirements.deployTransaction)
+      .to.emit(requirements, "MinimumVersionChanged")
+      .withArgs("v1.0.0", 1);
+  });
+
+  it("should let the owner set the minimum version", async function () {
+    const requirements = await deploy();
+    await expect(requirements.setMinimumVersion("v1.1.0", 2))
+      .to.emit(requirements, "MinimumVersionChanged")
+      .withArgs("v1.1.0", 2);
+    expect(await requirements.minimumVersion()).to.equal("v1.1.0");
+    expect(await requirements.minimumProtocolVersion()).to.equal(2);
+    await expect(requirements.setMinimumVersion("", 2)).to.be.revertedWith(
+      "VersionRequirements.setMinimumVersion: version is empty"
+    );
+  });
+
+  it("should only let the owner set the minimum version", async function () {
+    const requirements = await deploy();
+    const [, other] = await ethers.getSigners();
+    await expect(
+      requirements.connect(other).setMinimumVersion("v2.0.0", 1)
+    ).to.be.revertedWith("Ownable: caller is not the owner");
+  });
+});
@@ -0,0 +1,67 @@
+package chainobserver
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/beaconapi"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+)
+
+// EnableBlobs makes the observer fetch the blobs schema events refer to from the beacon node
+// before handing the events to their handlers, see eventsyncer.DynamicEvent.Blobs. Fetched blobs
+// are cached in the db, so that events can be handled again after the beacon node has pruned them.
+func (chainobs *ChainObserver) EnableBlobs(beacon *beaconapi.Client) {
+	chainobs.blobs = eventsyncer.NewBlobFetcher(beacon, chainobs.contracts.Client)
+}
+
+// loadBlobs returns the blobs the event refers to, from the db if they have been fetched before.
+func (chainobs *ChainObserver) loadBlobs(
+	ctx context.Context, db *chainobsdb.Queries, event eventsyncer.DynamicEvent,
+) (map[common.Hash]*kzg4844.Blob, error) {
+	blobs := make(map[common.Hash]*kzg4844.Blob)
+	missing := []common.Hash{}
+	for _, hash := range event.BlobHashes {
+		row, err := db.GetBlob(ctx, hash.Bytes())
+		if errors.Is(err, pgx.ErrNoRows) {
+			missing = append(missing, hash)
+			continue
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to query blob %s from db", hash.Hex())
+		}
+		var blob kzg4844.Blob
+		if len(row.Data) != len(blob) {
+			return nil, errors.Errorf("blob %s in db has %d bytes", hash.Hex(), len(row.Data))
+		}
+		copy(blob[:], row.Data)
+		blobs[hash] = &blob
+	}
+	if len(missing) == 0 {
+		return blobs, nil
+	}
+
+	fetched, err := chainobs.blobs.Fetch(ctx, event.Raw.BlockHash, missing)
+	if err != nil {
+		return nil, err
+	}
+	for hash, blob := range fetched {
+		err := db.InsertBlob(ctx, chainobsdb.InsertBlobParams{
+			VersionedHash: hash.Bytes(),
+			BlockNumber:   int64(event.Raw.BlockNumber),
+			Data:          blob[:],
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to store blob %s in db", hash.Hex())
+		}
+		blobs[hash] = blob
+	}
+	log.Debug().Int("count", len(fetched)).Uint64("block-number", event.Raw.BlockNumber).
+		Msg("fetched blobs referenced by event")
+	return blobs, nil
+}
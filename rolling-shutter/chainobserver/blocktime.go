@@ -0,0 +1,88 @@
+package chainobserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+)
+
+const (
+	// blockSampleInterval is the minimum time between two block samples.
+	blockSampleInterval = 10 * time.Minute
+	// maxBlockSamples is the number of block samples kept in the db, i.e. block times are averaged
+	// over roughly the last day.
+	maxBlockSamples = 144
+	// defaultBlockTime is assumed as long as there are not enough samples to estimate the block
+	// time, e.g. right after the first start of the node.
+	defaultBlockTime = 12 * time.Second
+)
+
+// BlockTimeEstimate estimates the time at which future blocks will be produced by extrapolating from
+// the most recent block sample with the average block time of all samples.
+type BlockTimeEstimate struct {
+	BlockNumber int64
+	Timestamp   time.Time
+	BlockTime   time.Duration
+}
+
+// NewBlockTimeEstimate computes an estimate from block samples ordered by block number in
+// descending order, as returned by GetBlockSamples. It returns false if there are no samples. If
+// there is only a single sample, the default block time is assumed.
+func NewBlockTimeEstimate(samples []chainobsdb.BlockSample) (BlockTimeEstimate, bool) {
+	if len(samples) == 0 {
+		return BlockTimeEstimate{}, false
+	}
+	newest := samples[0]
+	oldest := samples[len(samples)-1]
+	e := BlockTimeEstimate{
+		BlockNumber: newest.BlockNumber,
+		Timestamp:   time.Unix(newest.Timestamp, 0),
+		BlockTime:   defaultBlockTime,
+	}
+	if newest.BlockNumber > oldest.BlockNumber && newest.Timestamp > oldest.Timestamp {
+		e.BlockTime = time.Duration(newest.Timestamp-oldest.Timestamp) * time.Second /
+			time.Duration(newest.BlockNumber-oldest.BlockNumber)
+	}
+	return e, true
+}
+
+// Time returns the estimated time of the given block.
+func (e BlockTimeEstimate) Time(blockNumber int64) time.Time {
+	return e.Timestamp.Add(time.Duration(blockNumber-e.BlockNumber) * e.BlockTime)
+}
+
+// sampleBlock stores the header as block sample, unless the previous sample is too recent.
+func sampleBlock(ctx context.Context, db *chainobsdb.Queries, header *types.Header) error {
+	samples, err := db.GetBlockSamples(ctx, 1)
+	if err != nil {
+		return errors.Wrap(err, "failed to get block samples from db")
+	}
+	if len(samples) > 0 && int64(header.Time)-samples[0].Timestamp < int64(blockSampleInterval/time.Second) {
+		return nil
+	}
+	err = db.InsertBlockSample(ctx, chainobsdb.InsertBlockSampleParams{
+		BlockNumber: header.Number.Int64(),
+		Timestamp:   int64(header.Time),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to insert block sample into db")
+	}
+	if _, err := db.PruneBlockSamples(ctx, maxBlockSamples); err != nil {
+		return errors.Wrap(err, "failed to prune block samples")
+	}
+	return nil
+}
+
+// estimateBlockTimes loads the block samples from the db and computes an estimate from them.
+func estimateBlockTimes(ctx context.Context, db *chainobsdb.Queries) (BlockTimeEstimate, bool, error) {
+	samples, err := db.GetBlockSamples(ctx, maxBlockSamples)
+	if err != nil {
+		return BlockTimeEstimate{}, false, errors.Wrap(err, "failed to get block samples from db")
+	}
+	e, ok := NewBlockTimeEstimate(samples)
+	return e, ok, nil
+}
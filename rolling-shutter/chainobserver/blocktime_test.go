@@ -0,0 +1,30 @@
+package chainobserver
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+)
+
+func TestBlockTimeEstimate(t *testing.T) {
+	_, ok := NewBlockTimeEstimate(nil)
+	assert.Check(t, !ok)
+
+	e, ok := NewBlockTimeEstimate([]chainobsdb.BlockSample{{BlockNumber: 100, Timestamp: 1000}})
+	assert.Check(t, ok)
+	assert.Equal(t, e.BlockTime, defaultBlockTime)
+	assert.Equal(t, e.Time(110), time.Unix(1000+120, 0))
+
+	e, ok = NewBlockTimeEstimate([]chainobsdb.BlockSample{
+		{BlockNumber: 200, Timestamp: 1500},
+		{BlockNumber: 150, Timestamp: 1250},
+		{BlockNumber: 100, Timestamp: 1000},
+	})
+	assert.Check(t, ok)
+	assert.Equal(t, e.BlockTime, 5*time.Second)
+	assert.Equal(t, e.Time(260), time.Unix(1500+300, 0))
+	assert.Equal(t, e.Time(180), time.Unix(1500-100, 0))
+}
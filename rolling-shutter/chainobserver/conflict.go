@@ -0,0 +1,156 @@
+package chainobserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/alert"
+)
+
+// ErrConfigConflict is returned if the config contract announces a keyper set that differs from a
+// known set with the same index or activation block, e.g. after the contract has been redeployed.
+// The observer halts, as it can't tell which of the sets is the right one.
+var ErrConfigConflict = errors.New("keyper config conflicts with a known one")
+
+// ConfigConflictError describes how an announced keyper set differs from a known one.
+type ConfigConflictError struct {
+	Known     chainobsdb.KeyperSet
+	Announced chainobsdb.KeyperSet
+	Diff      []string
+}
+
+func (e *ConfigConflictError) Error() string {
+	return fmt.Sprintf(
+		"keyper config %d conflicts with known keyper config %d: %s",
+		e.Announced.KeyperConfigIndex, e.Known.KeyperConfigIndex, strings.Join(e.Diff, "; "))
+}
+
+func (e *ConfigConflictError) Is(target error) bool {
+	return target == ErrConfigConflict
+}
+
+// checkKeyperSet compares an announced keyper set with the known sets with the same index or
+// activation block. It returns true if the set is known already, e.g. because its event has been
+// replayed, in which case it must not be inserted again. A set with a different index, but the same
+// content as a known one is accepted as well, but has to be inserted.
+func checkKeyperSet(
+	ctx context.Context, db *chainobsdb.Queries, announced chainobsdb.KeyperSet,
+) (bool, error) {
+	known, err := db.GetKeyperSetsByActivationBlockNumber(ctx, announced.ActivationBlockNumber)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get keyper sets by activation block number")
+	}
+	sameIndex, err := db.GetKeyperSetByKeyperConfigIndex(ctx, announced.KeyperConfigIndex)
+	if err == nil {
+		known = append(known, sameIndex)
+	} else if err != pgx.ErrNoRows {
+		return false, errors.Wrap(err, "failed to get keyper set by keyper config index")
+	}
+	if len(known) == 0 {
+		return false, nil
+	}
+	rotations, err := db.GetKeyperRotations(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get keyper rotations")
+	}
+	return resolveKeyperSet(announced, known, rotations)
+}
+
+// resolveKeyperSet implements checkKeyperSet given the known sets with the same index or
+// activation block as the announced one. Rotations of the known sets are undone before they are
+// compared, as the contract announces the original addresses.
+func resolveKeyperSet(
+	announced chainobsdb.KeyperSet, known []chainobsdb.KeyperSet, rotations []chainobsdb.KeyperRotation,
+) (bool, error) {
+	duplicate := false
+	for _, set := range known {
+		set.Keypers = unrotatedKeypers(set, rotations)
+		if diff := diffKeyperSets(set, announced); len(diff) > 0 {
+			return false, &ConfigConflictError{Known: set, Announced: announced, Diff: diff}
+		}
+		if set.KeyperConfigIndex == announced.KeyperConfigIndex {
+			duplicate = true
+		}
+	}
+	return duplicate, nil
+}
+
+// unrotatedKeypers returns the keypers of a set as announced by the config contract, i.e. before
+// any of their addresses have been rotated.
+func unrotatedKeypers(set chainobsdb.KeyperSet, rotations []chainobsdb.KeyperRotation) []string {
+	keypers := append([]string{}, set.Keypers...)
+	for i := len(rotations) - 1; i >= 0; i-- {
+		r := rotations[i]
+		if r.KeyperConfigIndex != set.KeyperConfigIndex || r.KeyperIndex >= int64(len(keypers)) {
+			continue
+		}
+		if keypers[r.KeyperIndex] == r.NewAddress {
+			keypers[r.KeyperIndex] = r.OldAddress
+		}
+	}
+	return keypers
+}
+
+// diffKeyperSets lists the differences in content between two keyper sets, one line each. The
+// index of the sets isn't compared.
+func diffKeyperSets(known, announced chainobsdb.KeyperSet) []string {
+	diff := []string{}
+	if known.ActivationBlockNumber != announced.ActivationBlockNumber {
+		diff = append(diff, fmt.Sprintf(
+			"activation-block-number: known %d, announced %d",
+			known.ActivationBlockNumber, announced.ActivationBlockNumber))
+	}
+	if known.Threshold != announced.Threshold {
+		diff = append(diff, fmt.Sprintf("threshold: known %d, announced %d", known.Threshold, announced.Threshold))
+	}
+	for i := 0; i < len(known.Keypers) || i < len(announced.Keypers); i++ {
+		k, a := "none", "none"
+		if i < len(known.Keypers) {
+			k = known.Keypers[i]
+		}
+		if i < len(announced.Keypers) {
+			a = announced.Keypers[i]
+		}
+		if k != a {
+			diff = append(diff, fmt.Sprintf("keyper %d: known %s, announced %s", i, k, a))
+		}
+	}
+	return diff
+}
+
+// notifyConfigConflict alerts the operator that the observer halted because of conflicting keyper
+// configs.
+func (chainobs *ChainObserver) notifyConfigConflict(ctx context.Context, err error) {
+	var conflict *ConfigConflictError
+	if !errors.As(err, &conflict) {
+		return
+	}
+	log.Error().Err(err).Msg("halting, the config contract announced a conflicting keyper config")
+	if chainobs.notifier == nil {
+		return
+	}
+	details := map[string]string{
+		"known-keyper-config-index":     fmt.Sprint(conflict.Known.KeyperConfigIndex),
+		"announced-keyper-config-index": fmt.Sprint(conflict.Announced.KeyperConfigIndex),
+		"diff":                          strings.Join(conflict.Diff, "\n"),
+	}
+	var evErr *eventError
+	if errors.As(err, &evErr) {
+		details["block-number"] = fmt.Sprint(evErr.log.BlockNumber)
+		details["tx-hash"] = evErr.log.TxHash.Hex()
+	}
+	nerr := chainobs.notifier.Notify(ctx, alert.Alert{
+		Severity: alert.SeverityCritical,
+		Summary:  "contract event syncing halted, the config contract announced a conflicting keyper config",
+		Details:  details,
+	})
+	if nerr != nil {
+		log.Error().Err(nerr).Msg("failed to notify about conflicting keyper config")
+	}
+}
@@ -0,0 +1,54 @@
+package chainobserver
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+)
+
+func TestDiffKeyperSets(t *testing.T) {
+	known := chainobsdb.KeyperSet{ActivationBlockNumber: 100, Keypers: []string{"0x1", "0x2"}, Threshold: 2}
+	assert.DeepEqual(t, diffKeyperSets(known, known), []string{})
+
+	announced := chainobsdb.KeyperSet{ActivationBlockNumber: 100, Keypers: []string{"0x1", "0x3", "0x4"}, Threshold: 3}
+	assert.DeepEqual(t, diffKeyperSets(known, announced), []string{
+		"threshold: known 2, announced 3",
+		"keyper 1: known 0x2, announced 0x3",
+		"keyper 2: known none, announced 0x4",
+	})
+}
+
+func TestResolveKeyperSet(t *testing.T) {
+	known := chainobsdb.KeyperSet{KeyperConfigIndex: 1, ActivationBlockNumber: 100, Keypers: []string{"0x1", "0x2"}, Threshold: 2}
+
+	duplicate, err := resolveKeyperSet(known, []chainobsdb.KeyperSet{known}, nil)
+	assert.NilError(t, err)
+	assert.Check(t, duplicate, "a replayed config must be recognized")
+
+	sameContent := known
+	sameContent.KeyperConfigIndex = 2
+	duplicate, err = resolveKeyperSet(sameContent, []chainobsdb.KeyperSet{known}, nil)
+	assert.NilError(t, err)
+	assert.Check(t, !duplicate, "a config with a new index must be inserted")
+
+	conflicting := sameContent
+	conflicting.Threshold = 1
+	_, err = resolveKeyperSet(conflicting, []chainobsdb.KeyperSet{known}, nil)
+	assert.Check(t, errors.Is(err, ErrConfigConflict))
+	var conflict *ConfigConflictError
+	assert.Assert(t, errors.As(err, &conflict))
+	assert.DeepEqual(t, conflict.Diff, []string{"threshold: known 2, announced 1"})
+
+	rotated := known
+	rotated.Keypers = []string{"0x1", "0x5"}
+	rotations := []chainobsdb.KeyperRotation{
+		{KeyperConfigIndex: 1, KeyperIndex: 1, OldAddress: "0x2", NewAddress: "0x5"},
+		{KeyperConfigIndex: 2, KeyperIndex: 0, OldAddress: "0x1", NewAddress: "0x6"},
+	}
+	duplicate, err = resolveKeyperSet(known, []chainobsdb.KeyperSet{rotated}, rotations)
+	assert.NilError(t, err, "rotations of a known config are no conflict")
+	assert.Check(t, duplicate)
+}
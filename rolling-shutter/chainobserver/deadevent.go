@@ -0,0 +1,224 @@
+package chainobserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/auditdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/alert"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+)
+
+// deadEventRetryInterval is how often the observer checks for dead events the operator wants to
+// be retried.
+const deadEventRetryInterval = 10 * time.Second
+
+// eventError is returned when handling a particular event failed.
+type eventError struct {
+	log types.Log
+	err error
+}
+
+func (e *eventError) Error() string {
+	return e.err.Error()
+}
+
+func (e *eventError) Unwrap() error {
+	return e.err
+}
+
+// wrapEventError attaches the log of event to err, so that the failing event can be identified.
+func wrapEventError(event interface{}, err error) error {
+	if err == nil {
+		return nil
+	}
+	raw, ok := rawLog(event)
+	if !ok {
+		return err
+	}
+	return &eventError{log: raw, err: err}
+}
+
+// EnableDeadEvents makes the observer give up on an event once handling it failed maxAttempts
+// times in a row. The event is stored in the dead_event table, the operator is notified and
+// syncing continues with the next event of its type. Dead events are handled again after they've
+// been marked for retry.
+func (chainobs *ChainObserver) EnableDeadEvents(maxAttempts uint64, notifier alert.Notifier) {
+	chainobs.maxEventAttempts = maxAttempts
+	chainobs.notifier = notifier
+}
+
+// failedEvent counts the consecutive failures to handle the same event.
+type failedEvent struct {
+	blockNumber uint64
+	logIndex    uint
+	attempts    uint64
+}
+
+// record counts a failure to handle the event of log.
+func (f *failedEvent) record(log types.Log) {
+	if f.attempts > 0 && f.blockNumber == log.BlockNumber && f.logIndex == log.Index {
+		f.attempts++
+		return
+	}
+	*f = failedEvent{blockNumber: log.BlockNumber, logIndex: log.Index, attempts: 1}
+}
+
+// giveUpEvent moves a failing event to the dead events and advances the cursor of its event type
+// past it. This is only done if the event is the next one to be handled, so no other event is
+// skipped.
+func (chainobs *ChainObserver) giveUpEvent(
+	ctx context.Context, eventType *eventsyncer.EventType, keys []string, evErr *eventError, attempts uint64,
+) error {
+	logData, err := json.Marshal(evErr.log)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode log")
+	}
+	var id int64
+	err = chainobs.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		db := chainobsdb.New(tx)
+		progress, err := db.GetEventTypeSyncProgress(ctx, eventType.Key())
+		if err != nil {
+			return errors.Wrapf(err, "failed to get sync progress of %s events from db", eventType.Name)
+		}
+		if uint64(progress.NextBlockNumber) != evErr.log.BlockNumber ||
+			uint64(progress.NextLogIndex) != uint64(evErr.log.Index) {
+			return errors.Errorf(
+				"failing event at block %d, log index %d is not the next one to be handled",
+				evErr.log.BlockNumber, evErr.log.Index,
+			)
+		}
+		id, err = db.InsertDeadEvent(ctx, chainobsdb.InsertDeadEventParams{
+			EventType:   eventType.Key(),
+			BlockNumber: int64(evErr.log.BlockNumber),
+			LogIndex:    int64(evErr.log.Index),
+			TxHash:      evErr.log.TxHash.Bytes(),
+			Log:         logData,
+			Attempts:    int32(attempts),
+			LastError:   evErr.err.Error(),
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to insert dead event")
+		}
+		if err := db.UpdateEventTypeSyncProgress(ctx, chainobsdb.UpdateEventTypeSyncProgressParams{
+			EventType:       eventType.Key(),
+			NextBlockNumber: int64(evErr.log.BlockNumber),
+			NextLogIndex:    int64(evErr.log.Index) + 1,
+		}); err != nil {
+			return errors.Wrap(err, "failed to update last synced event")
+		}
+		return db.UpdateEventSyncProgressFromEventTypes(ctx, keys)
+	})
+	if err != nil {
+		return err
+	}
+
+	metricsDeadEvents.WithLabelValues(eventType.Key()).Inc()
+	log.Error().Err(evErr.err).
+		Int64("dead-event-id", id).
+		Str("event", eventType.Name).
+		Uint64("block-number", evErr.log.BlockNumber).
+		Uint("log-index", evErr.log.Index).
+		Uint64("attempts", attempts).
+		Msg("giving up on event, moved it to the dead events")
+	if chainobs.notifier != nil {
+		nerr := chainobs.notifier.Notify(ctx, alert.Alert{
+			Severity: alert.SeverityCritical,
+			Summary:  fmt.Sprintf("gave up handling a %s event, it has to be retried manually", eventType.Name),
+			Details: map[string]string{
+				"dead-event-id": fmt.Sprint(id),
+				"block-number":  fmt.Sprint(evErr.log.BlockNumber),
+				"log-index":     fmt.Sprint(evErr.log.Index),
+				"tx-hash":       evErr.log.TxHash.Hex(),
+				"error":         evErr.err.Error(),
+			},
+		})
+		if nerr != nil {
+			log.Error().Err(nerr).Msg("failed to notify about dead event")
+		}
+	}
+	return nil
+}
+
+// retryDeadEvents periodically handles the dead events marked for retry.
+func (chainobs *ChainObserver) retryDeadEvents(ctx context.Context, eventTypes []*eventsyncer.EventType) error {
+	byKey := make(map[string]*eventsyncer.EventType)
+	for _, eventType := range eventTypes {
+		byKey[eventType.Key()] = eventType
+	}
+	for {
+		deadEvents, err := chainobsdb.New(chainobs.dbpool).GetDeadEventsToRetry(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get dead events to retry")
+		}
+		for _, deadEvent := range deadEvents {
+			err := chainobs.retryDeadEvent(ctx, byKey[deadEvent.EventType], deadEvent)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil {
+				log.Error().Err(err).Int64("dead-event-id", deadEvent.ID).Msg("retrying dead event failed")
+				err = chainobsdb.New(chainobs.dbpool).FailDeadEventRetry(ctx, chainobsdb.FailDeadEventRetryParams{
+					ID:        deadEvent.ID,
+					LastError: err.Error(),
+				})
+				if err != nil {
+					log.Error().Err(err).Int64("dead-event-id", deadEvent.ID).Msg("failed to update dead event")
+				}
+				continue
+			}
+			log.Info().Int64("dead-event-id", deadEvent.ID).Msg("handled dead event")
+		}
+
+		select {
+		case <-time.After(deadEventRetryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// retryDeadEvent handles a dead event and deletes it if that succeeds. Dead events are handled out
+// of order, after the events following them.
+func (chainobs *ChainObserver) retryDeadEvent(
+	ctx context.Context, eventType *eventsyncer.EventType, deadEvent chainobsdb.DeadEvent,
+) error {
+	if eventType == nil {
+		return errors.Errorf("event type %s is not observed", deadEvent.EventType)
+	}
+	var raw types.Log
+	if err := json.Unmarshal(deadEvent.Log, &raw); err != nil {
+		return errors.Wrap(err, "failed to decode log")
+	}
+	event, err := eventType.Unpack(raw)
+	if err != nil {
+		return err
+	}
+	if chainobs.verifier != nil {
+		if err := chainobs.verifier.VerifyEvent(ctx, event); err != nil {
+			return err
+		}
+	}
+	event, err = chainobs.amendEvent(ctx, event)
+	if err != nil {
+		return err
+	}
+	return chainobs.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		db := chainobsdb.New(tx)
+		if err := chainobs.handleEvent(ctx, db, event); err != nil {
+			return err
+		}
+		if err := auditEvent(ctx, auditdb.New(tx), event); err != nil {
+			return err
+		}
+		return db.DeleteDeadEvent(ctx, deadEvent.ID)
+	})
+}
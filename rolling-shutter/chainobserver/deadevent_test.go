@@ -0,0 +1,81 @@
+package chainobserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+)
+
+func TestFailedEvent(t *testing.T) {
+	var failed failedEvent
+	failed.record(types.Log{BlockNumber: 5, Index: 1})
+	failed.record(types.Log{BlockNumber: 5, Index: 1})
+	assert.Equal(t, failed.attempts, uint64(2))
+	failed.record(types.Log{BlockNumber: 5, Index: 2})
+	assert.Equal(t, failed.attempts, uint64(1))
+}
+
+func TestWrapEventError(t *testing.T) {
+	err := errors.New("call reverted")
+	assert.Assert(t, wrapEventError(nil, nil) == nil)
+	assert.Equal(t, wrapEventError(nil, err), err)
+
+	var evErr *eventError
+	wrapped := wrapEventError(eventsyncer.DynamicEvent{Raw: types.Log{BlockNumber: 7}}, err)
+	assert.Assert(t, errors.As(wrapped, &evErr))
+	assert.Equal(t, evErr.log.BlockNumber, uint64(7))
+	assert.Assert(t, errors.Is(wrapped, err))
+}
+
+func TestGiveUpEventIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+	db := chainobsdb.New(dbpool)
+
+	eventType := &eventsyncer.EventType{Address: common.HexToAddress("0x1"), Name: "A"}
+	keys := []string{eventType.Key()}
+	chainobs := &ChainObserver{dbpool: dbpool}
+	assert.NilError(t, db.UpdateEventTypeSyncProgress(ctx, chainobsdb.UpdateEventTypeSyncProgressParams{
+		EventType:       eventType.Key(),
+		NextBlockNumber: 10,
+		NextLogIndex:    2,
+	}))
+
+	// events behind the cursor are not skipped
+	evErr := &eventError{log: types.Log{BlockNumber: 10, Index: 3}, err: errors.New("call reverted")}
+	assert.ErrorContains(t, chainobs.giveUpEvent(ctx, eventType, keys, evErr, 3), "not the next one")
+
+	evErr.log.Index = 2
+	assert.NilError(t, chainobs.giveUpEvent(ctx, eventType, keys, evErr, 3))
+	progress, err := db.GetEventTypeSyncProgress(ctx, eventType.Key())
+	assert.NilError(t, err)
+	assert.Equal(t, progress.NextBlockNumber, int64(10))
+	assert.Equal(t, progress.NextLogIndex, int64(3))
+
+	deadEvents, err := db.GetDeadEvents(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(deadEvents), 1)
+	assert.Equal(t, deadEvents[0].Attempts, int32(3))
+	assert.Equal(t, deadEvents[0].LastError, "call reverted")
+
+	// retrying an event of a type that isn't observed anymore fails
+	n, err := db.RetryDeadEvent(ctx, deadEvents[0].ID)
+	assert.NilError(t, err)
+	assert.Equal(t, n, int64(1))
+	toRetry, err := db.GetDeadEventsToRetry(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(toRetry), 1)
+	assert.ErrorContains(t, chainobs.retryDeadEvent(ctx, nil, toRetry[0]), "not observed")
+}
@@ -0,0 +1,126 @@
+package chainobserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+)
+
+const (
+	// maxIgnoredEvents is the number of ignored events kept in the db.
+	maxIgnoredEvents = 1000
+	// defaultIgnoredEventsLimit is the number of ignored events listed if the request doesn't
+	// specify a limit.
+	defaultIgnoredEventsLimit = 100
+)
+
+// rawLog extracts the log an event was decoded from. Events of the contract bindings and dynamic
+// events carry it in their Raw field.
+func rawLog(event interface{}) (types.Log, bool) {
+	v := reflect.Indirect(reflect.ValueOf(event))
+	if v.Kind() != reflect.Struct {
+		return types.Log{}, false
+	}
+	field := v.FieldByName("Raw")
+	if !field.IsValid() || !field.CanInterface() {
+		return types.Log{}, false
+	}
+	raw, ok := field.Interface().(types.Log)
+	if !ok {
+		return types.Log{}, false
+	}
+	return raw, true
+}
+
+// ignoreEvent counts and records an event that is not handled, so that misconfigured deployments
+// are noticed instead of silently losing data. Events are identified by the address of the emitting
+// contract and their signature, i.e. the first topic of the log.
+func ignoreEvent(ctx context.Context, db *chainobsdb.Queries, event interface{}, reason string) error {
+	raw, ok := rawLog(event)
+	address := "unknown"
+	signature := "unknown"
+	if ok {
+		address = raw.Address.Hex()
+		if len(raw.Topics) > 0 {
+			signature = raw.Topics[0].Hex()
+		}
+	}
+	metricsIgnoredEvents.WithLabelValues(address, signature).Inc()
+	log.Warn().Str("event-type", reflect.TypeOf(event).String()).Str("contract", address).
+		Str("signature", signature).Uint64("block-number", raw.BlockNumber).Str("reason", reason).
+		Msg("ignoring event")
+
+	err := db.InsertIgnoredEvent(ctx, chainobsdb.InsertIgnoredEventParams{
+		BlockNumber: int64(raw.BlockNumber),
+		LogIndex:    int64(raw.Index),
+		TxHash:      raw.TxHash.Bytes(),
+		Address:     address,
+		Signature:   signature,
+		Reason:      reason,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to insert ignored event into db")
+	}
+	if _, err := db.PruneIgnoredEvents(ctx, maxIgnoredEvents); err != nil {
+		return errors.Wrap(err, "failed to prune ignored events")
+	}
+	return nil
+}
+
+// IgnoredEvent is an ignored event as listed by the API.
+type IgnoredEvent struct {
+	BlockNumber int64         `json:"blockNumber"`
+	LogIndex    int64         `json:"logIndex"`
+	TxHash      hexutil.Bytes `json:"txHash"`
+	Contract    string        `json:"contract"`
+	Signature   string        `json:"signature"`
+	Reason      string        `json:"reason"`
+	RecordedAt  time.Time     `json:"recordedAt"`
+}
+
+// IgnoredEventsHandler lists the most recently ignored events, newest first. The number of events
+// can be limited with the limit query parameter.
+func IgnoredEventsHandler(dbpool *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultIgnoredEventsLimit
+		if s := r.URL.Query().Get("limit"); s != "" {
+			l, err := strconv.Atoi(s)
+			if err != nil || l <= 0 || l > maxIgnoredEvents {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+			limit = l
+		}
+		rows, err := chainobsdb.New(dbpool).GetRecentIgnoredEvents(r.Context(), int32(limit))
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get ignored events from db")
+			http.Error(w, "failed to get ignored events", http.StatusInternalServerError)
+			return
+		}
+		events := make([]IgnoredEvent, len(rows))
+		for i, row := range rows {
+			events[i] = IgnoredEvent{
+				BlockNumber: row.BlockNumber,
+				LogIndex:    row.LogIndex,
+				TxHash:      row.TxHash,
+				Contract:    row.Address,
+				Signature:   row.Signature,
+				Reason:      row.Reason,
+				RecordedAt:  row.RecordedAt,
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(events)
+	}
+}
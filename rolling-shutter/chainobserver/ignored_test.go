@@ -0,0 +1,28 @@
+package chainobserver
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+)
+
+func TestRawLog(t *testing.T) {
+	log := types.Log{Address: common.HexToAddress("0x1"), BlockNumber: 5}
+
+	raw, ok := rawLog(eventsyncer.DynamicEvent{Raw: log})
+	assert.Check(t, ok)
+	assert.Equal(t, raw.BlockNumber, log.BlockNumber)
+
+	raw, ok = rawLog(&eventsyncer.DynamicEvent{Raw: log})
+	assert.Check(t, ok)
+	assert.Equal(t, raw.Address, log.Address)
+
+	_, ok = rawLog(struct{ Raw int }{})
+	assert.Check(t, !ok)
+	_, ok = rawLog(42)
+	assert.Check(t, !ok)
+}
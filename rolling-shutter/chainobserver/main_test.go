@@ -0,0 +1,12 @@
+package chainobserver
+
+import (
+	"os"
+	"testing"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testdb.Run(m))
+}
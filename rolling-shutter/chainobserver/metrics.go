@@ -0,0 +1,39 @@
+package chainobserver
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsIgnoredEvents = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "chainobserver",
+		Name:      "ignored_events_total",
+		Help:      "Number of contract events that were observed, but not handled",
+	},
+	[]string{"contract", "signature"},
+)
+
+var metricsEventSyncRestarts = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "chainobserver",
+		Name:      "event_sync_restarts_total",
+		Help:      "Number of times syncing the events of a type was restarted after a failure",
+	},
+	[]string{"event_type"},
+)
+
+var metricsDeadEvents = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "chainobserver",
+		Name:      "dead_events_total",
+		Help:      "Number of contract events that were given up on after handling them failed repeatedly",
+	},
+	[]string{"event_type"},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsIgnoredEvents)
+	prometheus.MustRegister(metricsEventSyncRestarts)
+	prometheus.MustRegister(metricsDeadEvents)
+}
@@ -2,11 +2,15 @@ package chainobserver
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"reflect"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
@@ -15,14 +19,17 @@ import (
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/contract"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/contract/deployment"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/auditdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/paramdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/alert"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/beaconapi"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/paramregistry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
 
-const finalityOffset = 3
-
 func retryGetAddrs(ctx context.Context, addrsSeq *contract.AddrsSeq, n uint64) ([]common.Address, error) {
 	callOpts := &bind.CallOpts{
 		Pending: false,
@@ -41,70 +48,295 @@ func retryGetAddrs(ctx context.Context, addrsSeq *contract.AddrsSeq, n uint64) (
 }
 
 type ChainObserver struct {
-	contracts *deployment.Contracts
-	dbpool    *pgxpool.Pool
+	contracts  *deployment.Contracts
+	dbpool     *pgxpool.Pool
+	verifier   *eventsyncer.LogVerifier
+	handlers   map[string]EventHandler
+	blobs      *eventsyncer.BlobFetcher
+	checkpoint eventsyncer.CheckpointReader
+
+	crossCheck       *ethclient.Client
+	maxEventAttempts uint64
+	notifier         alert.Notifier
 }
 
-func New(contracts *deployment.Contracts, dbpool *pgxpool.Pool) *ChainObserver {
-	return &ChainObserver{contracts: contracts, dbpool: dbpool}
+// New creates a ChainObserver. If witness URLs are given, every event is verified against these
+// endpoints before it is applied, see eventsyncer.LogVerifier.
+func New(contracts *deployment.Contracts, dbpool *pgxpool.Pool, witnessURLs []string) (*ChainObserver, error) {
+	chainobs := &ChainObserver{
+		contracts: contracts,
+		dbpool:    dbpool,
+		handlers:  make(map[string]EventHandler),
+	}
+	if len(witnessURLs) > 0 {
+		verifier, err := eventsyncer.NewLogVerifier(contracts.Client, witnessURLs)
+		if err != nil {
+			return nil, err
+		}
+		chainobs.verifier = verifier
+	}
+	return chainobs, nil
 }
 
-func (chainobs *ChainObserver) Observe(ctx context.Context, eventTypes []*eventsyncer.EventType) error {
-	db := chainobsdb.New(chainobs.dbpool)
-	eventSyncProgress, err := db.GetEventSyncProgress(ctx)
+// EnableCrossCheck makes the observer fetch all events from the endpoint at url as well, which
+// should be operated by a different provider than the main one. If the endpoints return different
+// events, the observer halts and notifies the operator, see eventsyncer.ErrProviderDivergence.
+func (chainobs *ChainObserver) EnableCrossCheck(url string, notifier alert.Notifier) error {
+	client, err := ethclient.Dial(url)
 	if err != nil {
-		return errors.Wrap(err, "failed to get last synced event from db")
+		return errors.Wrapf(err, "failed to connect to cross-check endpoint %s", url)
 	}
+	chainobs.crossCheck = client
+	chainobs.notifier = notifier
+	return nil
+}
 
-	var fromBlock, fromLogIndex uint64
+// EnableCheckpointSync makes the observer only sync blocks once they are covered by the
+// checkpoint of the beacon chain the sync mode follows, instead of once they trail the head of the
+// chain by the number of blocks of the finality offset protocol parameter. Modes that don't follow a checkpoint are ignored.
+func (chainobs *ChainObserver) EnableCheckpointSync(beacon *beaconapi.Client, mode beaconapi.SyncMode) {
+	if !mode.UsesCheckpoint() {
+		return
+	}
+	chainobs.checkpoint = beacon.Checkpoint(mode)
+}
+
+// Observe syncs the events of the given types and handles them. Every event type is synced on its
+// own with a separate cursor, so that a failure handling the events of one type, e.g. a failed
+// contract call, only stalls that type. Its syncing is restarted from its cursor after
+// restartDelay. Events that keep failing are given up on if enabled, see EnableDeadEvents. The
+// observer as a whole only stops if the rpc providers disagree about the events emitted or if the
+// config contract announces a keyper config conflicting with a known one.
+func (chainobs *ChainObserver) Observe(ctx context.Context, eventTypes []*eventsyncer.EventType) error {
 	if len(eventTypes) == 0 {
 		return errors.New("no events to observe")
 	}
+	if err := chainobs.initSyncProgress(ctx, eventTypes); err != nil {
+		return err
+	}
+	keys := make([]string, len(eventTypes))
+	for i, eventType := range eventTypes {
+		keys[i] = eventType.Key()
+	}
+
+	errorgroup, errorctx := errgroup.WithContext(ctx)
+	for _, eventType := range eventTypes {
+		eventType := eventType
+		errorgroup.Go(func() error {
+			return chainobs.observeEventType(errorctx, eventType, keys)
+		})
+	}
+	errorgroup.Go(func() error {
+		return chainobs.retryDeadEvents(errorctx, eventTypes)
+	})
+	return errorgroup.Wait()
+}
+
+// initSyncProgress creates the cursors of event types observed for the first time. They start
+// where the shared cursor in event_sync_progress is, which is where all event types were synced to
+// before cursors were kept per event type.
+func (chainobs *ChainObserver) initSyncProgress(ctx context.Context, eventTypes []*eventsyncer.EventType) error {
+	return chainobs.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return initSyncProgress(ctx, chainobsdb.New(tx), eventTypes)
+	})
+}
+
+func initSyncProgress(ctx context.Context, db chainobsdb.SyncProgressStore, eventTypes []*eventsyncer.EventType) error {
+	progress, err := db.GetEventSyncProgress(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get last synced event from db")
+	}
+	keys := []string{}
+	for _, eventType := range eventTypes {
+		keys = append(keys, eventType.Key())
+		_, err := db.GetEventTypeSyncProgress(ctx, eventType.Key())
+		if err == nil {
+			continue
+		} else if err != pgx.ErrNoRows {
+			return errors.Wrapf(err, "failed to get sync progress of %s events from db", eventType.Name)
+		}
 
-	// first find the min of all event's from-blocks
-	fromBlock = eventTypes[0].FromBlockNumber
-	for _, event := range eventTypes {
-		if event.FromBlockNumber < fromBlock {
-			fromBlock = event.FromBlockNumber
+		fromBlock, fromLogIndex := eventType.FromBlockNumber, uint64(0)
+		// only use the saved log index when we're using the saved block number
+		if uint64(progress.NextBlockNumber) > fromBlock {
+			fromBlock = uint64(progress.NextBlockNumber)
+			fromLogIndex = uint64(progress.NextLogIndex)
+		}
+		err = db.UpdateEventTypeSyncProgress(ctx, chainobsdb.UpdateEventTypeSyncProgressParams{
+			EventType:       eventType.Key(),
+			NextBlockNumber: int64(fromBlock),
+			NextLogIndex:    int64(fromLogIndex),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to initialize sync progress of %s events", eventType.Name)
 		}
 	}
+	return db.UpdateEventSyncProgressFromEventTypes(ctx, keys)
+}
 
-	// then check if our saved progress is already later
-	progressBlock := uint64(eventSyncProgress.NextBlockNumber)
-	if progressBlock > fromBlock {
-		fromBlock = progressBlock
-		// only use the saved log index when we're using the
-		// saved block-number
-		fromLogIndex = uint64(eventSyncProgress.NextLogIndex)
+// restartDelay is the time to wait before the syncing of an event type is restarted after it
+// failed.
+const restartDelay = 10 * time.Second
+
+// observeEventType syncs the events of a single type and restarts syncing if it fails.
+func (chainobs *ChainObserver) observeEventType(ctx context.Context, eventType *eventsyncer.EventType, keys []string) error {
+	var failed failedEvent
+	for {
+		err := chainobs.syncEventType(ctx, eventType, keys)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, eventsyncer.ErrProviderDivergence) {
+			return err
+		}
+		if errors.Is(err, ErrConfigConflict) {
+			chainobs.notifyConfigConflict(ctx, err)
+			return err
+		}
+		var evErr *eventError
+		if errors.As(err, &evErr) {
+			failed.record(evErr.log)
+			if chainobs.maxEventAttempts > 0 && failed.attempts >= chainobs.maxEventAttempts {
+				gerr := chainobs.giveUpEvent(ctx, eventType, keys, evErr, failed.attempts)
+				if gerr == nil {
+					failed = failedEvent{}
+					continue
+				}
+				log.Error().Err(gerr).Str("event", eventType.Name).Msg("failed to give up on event")
+			}
+		}
+		metricsEventSyncRestarts.WithLabelValues(eventType.Key()).Inc()
+		log.Warn().Err(err).
+			Str("event", eventType.Name).
+			Str("address", eventType.Address.Hex()).
+			Dur("restart-delay", restartDelay).
+			Msg("syncing events failed, restarting")
+		select {
+		case <-time.After(restartDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// syncEventType syncs the events of a single type, starting at its cursor in the db. keys are
+// the keys of all observed event types, which are needed to keep the shared cursor up to date.
+func (chainobs *ChainObserver) syncEventType(ctx context.Context, eventType *eventsyncer.EventType, keys []string) error {
+	progress, err := chainobsdb.New(chainobs.dbpool).GetEventTypeSyncProgress(ctx, eventType.Key())
+	if err != nil {
+		return errors.Wrapf(err, "failed to get sync progress of %s events from db", eventType.Name)
+	}
+	fromBlock := uint64(progress.NextBlockNumber)
+	fromLogIndex := uint64(progress.NextLogIndex)
+	finalityOffset, err := paramregistry.New(chainobs.dbpool).Uint64(ctx, paramregistry.FinalityOffset)
+	if err != nil {
+		return err
 	}
 
-	log.Info().Uint64("from-block", fromBlock).Uint64("from-log-index", fromLogIndex).
+	log.Info().
+		Str("event", eventType.Name).
+		Str("address", eventType.Address.Hex()).
+		Uint64("from-block", fromBlock).
+		Uint64("from-log-index", fromLogIndex).
+		Uint64("finality-offset", finalityOffset).
 		Msg("starting event syncing")
-	syncer := eventsyncer.New(chainobs.contracts.Client, finalityOffset, eventTypes, fromBlock, fromLogIndex)
+	syncer := eventsyncer.New(
+		chainobs.contracts.Client, finalityOffset, []*eventsyncer.EventType{eventType}, fromBlock, fromLogIndex,
+	)
+	syncer.CrossCheckClient = chainobs.crossCheck
+	syncer.Checkpoint = chainobs.checkpoint
 
 	errorgroup, errorctx := errgroup.WithContext(ctx)
 	errorgroup.Go(func() error {
-		return syncer.Run(errorctx)
+		err := syncer.Run(errorctx)
+		if errors.Is(err, eventsyncer.ErrProviderDivergence) {
+			chainobs.notifyDivergence(ctx, err)
+		}
+		return err
 	})
+	// Amending events requires contract calls which are done concurrently for up to
+	// maxPendingEvents events. The amended events are applied to the db strictly in the order
+	// they were emitted, so the sync progress stays consistent.
+	pending := make(chan chan amendedUpdate, maxPendingEvents)
 	errorgroup.Go(func() error {
+		defer close(pending)
 		for {
+			eventSyncUpdate, err := syncer.Next(errorctx)
+			var unpackErr *eventsyncer.UnpackError
+			if errors.As(err, &unpackErr) {
+				// fail only after the events before it have been applied
+				result := make(chan amendedUpdate, 1)
+				result <- amendedUpdate{err: &eventError{log: unpackErr.Log, err: err}}
+				select {
+				case pending <- result:
+				case <-errorctx.Done():
+					return errorctx.Err()
+				}
+				return nil
+			} else if err != nil {
+				return err
+			}
+			result := make(chan amendedUpdate, 1)
 			select {
+			case pending <- result:
 			case <-errorctx.Done():
 				return errorctx.Err()
-			default:
-				eventSyncUpdate, err := syncer.Next(errorctx)
-				if err != nil {
-					return err
+			}
+			go func() {
+				event := eventSyncUpdate.Event
+				var err error
+				if chainobs.verifier != nil && event != nil {
+					err = chainobs.verifier.VerifyEvent(errorctx, event)
 				}
-				if err := chainobs.handleEventSyncUpdate(errorctx, eventSyncUpdate); err != nil {
-					return err
+				if err == nil {
+					eventSyncUpdate.Event, err = chainobs.amendEvent(errorctx, event)
 				}
+				result <- amendedUpdate{update: eventSyncUpdate, err: wrapEventError(event, err)}
+			}()
+		}
+	})
+	errorgroup.Go(func() error {
+		for result := range pending {
+			var amended amendedUpdate
+			select {
+			case amended = <-result:
+			case <-errorctx.Done():
+				return errorctx.Err()
+			}
+			if amended.err != nil {
+				return amended.err
+			}
+			if err := chainobs.handleEventSyncUpdate(errorctx, eventType.Key(), keys, amended.update); err != nil {
+				return wrapEventError(amended.update.Event, err)
 			}
 		}
+		return errorctx.Err()
 	})
 	return errorgroup.Wait()
 }
 
+// notifyDivergence alerts the operator that the observer halted because the rpc providers
+// disagree about the events emitted.
+func (chainobs *ChainObserver) notifyDivergence(ctx context.Context, err error) {
+	nerr := chainobs.notifier.Notify(ctx, alert.Alert{
+		Severity: alert.SeverityCritical,
+		Summary:  "contract event syncing halted, rpc providers disagree about the events emitted",
+		Details:  map[string]string{"error": err.Error()},
+	})
+	if nerr != nil {
+		log.Error().Err(nerr).Msg("failed to notify about rpc provider divergence")
+	}
+}
+
+// maxPendingEvents is the maximum number of events that are amended concurrently while waiting
+// to be applied to the db.
+const maxPendingEvents = 16
+
+type amendedUpdate struct {
+	update eventsyncer.EventSyncUpdate
+	err    error
+}
+
 type newKeyperConfig struct {
 	contract.KeypersConfigsListNewConfig
 	addrs []common.Address
@@ -135,16 +367,12 @@ func (chainobs *ChainObserver) amendEvent(
 	return event, nil
 }
 
-// handleEventSyncUpdate handles events and advances the sync state, but rolls back any db updates
-// on failure.
+// handleEventSyncUpdate handles an amended event and advances the cursor of its event type, but
+// rolls back any db updates on failure. The shared cursor is set to the earliest cursor of the
+// event types in keys.
 func (chainobs *ChainObserver) handleEventSyncUpdate(
-	ctx context.Context, eventSyncUpdate eventsyncer.EventSyncUpdate,
+	ctx context.Context, key string, keys []string, eventSyncUpdate eventsyncer.EventSyncUpdate,
 ) error {
-	var err error
-	eventSyncUpdate.Event, err = chainobs.amendEvent(ctx, eventSyncUpdate.Event)
-	if err != nil {
-		return err
-	}
 	return chainobs.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
 		db := chainobsdb.New(tx)
 
@@ -152,6 +380,12 @@ func (chainobs *ChainObserver) handleEventSyncUpdate(
 			if err := chainobs.handleEvent(ctx, db, eventSyncUpdate.Event); err != nil {
 				return err
 			}
+			if err := auditEvent(ctx, auditdb.New(tx), eventSyncUpdate.Event); err != nil {
+				return err
+			}
+			if err := recordParameters(ctx, tx, eventSyncUpdate.Event); err != nil {
+				return err
+			}
 		}
 
 		var nextBlockNumber uint64
@@ -163,12 +397,16 @@ func (chainobs *ChainObserver) handleEventSyncUpdate(
 			nextBlockNumber = eventSyncUpdate.BlockNumber
 			nextLogIndex = eventSyncUpdate.LogIndex + 1
 		}
-		if err := db.UpdateEventSyncProgress(ctx, chainobsdb.UpdateEventSyncProgressParams{
-			NextBlockNumber: int32(nextBlockNumber),
-			NextLogIndex:    int32(nextLogIndex),
+		if err := db.UpdateEventTypeSyncProgress(ctx, chainobsdb.UpdateEventTypeSyncProgressParams{
+			EventType:       key,
+			NextBlockNumber: int64(nextBlockNumber),
+			NextLogIndex:    int64(nextLogIndex),
 		}); err != nil {
 			return errors.Wrap(err, "failed to update last synced event")
 		}
+		if err := db.UpdateEventSyncProgressFromEventTypes(ctx, keys); err != nil {
+			return errors.Wrap(err, "failed to update shared sync progress")
+		}
 		return nil
 	})
 }
@@ -182,13 +420,57 @@ func (chainobs *ChainObserver) handleEvent(
 		err = chainobs.handleKeypersConfigsListNewConfigEvent(ctx, db, event)
 	case newCollatorConfig:
 		err = chainobs.handleCollatorConfigsListNewConfigEvent(ctx, db, event)
+	case eventsyncer.DynamicEvent:
+		err = chainobs.handleDynamicEvent(ctx, db, event)
 	default:
-		log.Info().Str("event-type", reflect.TypeOf(event).String()).Interface("event", event).
-			Msg("ignoring unknown event")
+		err = ignoreEvent(ctx, db, event, "unknown event type "+reflect.TypeOf(event).String())
 	}
 	return err
 }
 
+// auditEvent records a handled event in the audit log. Events that are ignored are not recorded.
+func auditEvent(ctx context.Context, db *auditdb.Queries, event interface{}) error {
+	var raw types.Log
+	var action, details string
+	switch event := event.(type) {
+	case newKeyperConfig:
+		raw = event.Raw
+		action = "KeypersConfigsListNewConfig"
+		details = fmt.Sprintf(
+			"keyper-config-index=%d activation-block-number=%d threshold=%d keypers=%s",
+			event.KeyperConfigIndex, event.ActivationBlockNumber, event.Threshold, event.addrs)
+	case newCollatorConfig:
+		raw = event.Raw
+		action = "CollatorConfigsListNewConfig"
+		details = fmt.Sprintf(
+			"collator-config-index=%d activation-block-number=%d collators=%s",
+			event.CollatorConfigIndex, event.ActivationBlockNumber, event.addrs)
+	default:
+		return nil
+	}
+	err := db.InsertAuditLogEntry(ctx, auditdb.InsertAuditLogEntryParams{
+		Source:      auditdb.SourceChain,
+		Actor:       raw.Address.Hex(),
+		Action:      action,
+		Details:     fmt.Sprintf("block-number=%d log-index=%d %s", raw.BlockNumber, raw.Index, details),
+		TriggerHash: raw.TxHash.Bytes(),
+	})
+	return errors.Wrap(err, "failed to insert audit log entry")
+}
+
+// recordParameters updates the protocol parameters that are set by a handled event.
+func recordParameters(ctx context.Context, db paramdb.DBTX, event interface{}) error {
+	switch event := event.(type) {
+	case newKeyperConfig:
+		return paramregistry.Set(
+			ctx, db, paramregistry.KeyperThreshold, paramregistry.FormatUint64(event.Threshold),
+			paramregistry.SourceContract, event.Raw.BlockNumber,
+		)
+	default:
+		return nil
+	}
+}
+
 func (chainobs *ChainObserver) handleKeypersConfigsListNewConfigEvent(
 	ctx context.Context, db *chainobsdb.Queries, event newKeyperConfig,
 ) error {
@@ -203,15 +485,40 @@ func (chainobs *ChainObserver) handleKeypersConfigsListNewConfigEvent(
 			"activation block number %d from config contract would overflow int64",
 			event.ActivationBlockNumber)
 	}
-	err := db.InsertKeyperSet(ctx, chainobsdb.InsertKeyperSetParams{
+	keyperSet := chainobsdb.KeyperSet{
 		KeyperConfigIndex:     int64(event.KeyperConfigIndex),
 		ActivationBlockNumber: int64(event.ActivationBlockNumber),
 		Keypers:               shdb.EncodeAddresses(event.addrs),
 		Threshold:             int32(event.Threshold),
-	})
+	}
+	duplicate, err := checkKeyperSet(ctx, db, keyperSet)
+	if err != nil {
+		return err
+	}
+	if duplicate {
+		log.Info().
+			Uint64("keyper-config-index", event.KeyperConfigIndex).
+			Msg("ignoring NewConfig event for a known keyper config")
+		return nil
+	}
+	err = db.InsertKeyperSet(ctx, chainobsdb.InsertKeyperSetParams(keyperSet))
 	if err != nil {
 		return errors.Wrapf(err, "failed to insert keyper set into db")
 	}
+	// The config is pending until its activation block is reached. As the activation block
+	// number refers to the chain the keypers observe and not necessarily the one the config
+	// contract lives on, we can't tell here if it has been reached already. This is left to the
+	// PendingConfigMonitor.
+	err = db.InsertPendingConfig(ctx, chainobsdb.InsertPendingConfigParams{
+		KeyperConfigIndex:     int64(event.KeyperConfigIndex),
+		ActivationBlockNumber: int64(event.ActivationBlockNumber),
+		Keypers:               shdb.EncodeAddresses(event.addrs),
+		Threshold:             int32(event.Threshold),
+		ScheduledBlockNumber:  int64(event.Raw.BlockNumber),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to insert pending config into db")
+	}
 	return nil
 }
 
@@ -0,0 +1,24 @@
+package chainobserver
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/contract/deployment"
+)
+
+func TestNewWitnesses(t *testing.T) {
+	contracts := &deployment.Contracts{}
+
+	chainobs, err := New(contracts, nil, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, chainobs.verifier == nil)
+
+	chainobs, err = New(contracts, nil, []string{"http://127.0.0.1:8545"})
+	assert.NilError(t, err)
+	assert.Assert(t, chainobs.verifier != nil)
+
+	_, err = New(contracts, nil, []string{"ftp://127.0.0.1:8545"})
+	assert.ErrorContains(t, err, "failed to connect to witness endpoint")
+}
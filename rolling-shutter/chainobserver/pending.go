@@ -0,0 +1,301 @@
+package chainobserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/alert"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/broker"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+const pendingConfigPollInterval = 30 * time.Second
+
+// PendingConfigMonitor notifies the operator about keyper configs that have been scheduled in the
+// config contract, but are not active yet, if they affect the node, i.e. if the node is part of the
+// current or the scheduled keyper set. This gives operators time to prepare, e.g. to make sure
+// their node is online for the DKG of the new keyper set.
+//
+// Activation times are estimated from block samples the monitor stores along the way. If leadTime
+// is positive, operators are notified a second time once a config is estimated to activate within
+// that time. Once a config activates, a KeyperSetActivated event is published to the bus.
+type PendingConfigMonitor struct {
+	dbpool   *pgxpool.Pool
+	l1Client *ethclient.Client
+	address  common.Address
+	notifier alert.Notifier
+	leadTime time.Duration
+	bus      *broker.Bus
+}
+
+func NewPendingConfigMonitor(
+	dbpool *pgxpool.Pool,
+	l1Client *ethclient.Client,
+	address common.Address,
+	notifier alert.Notifier,
+	leadTime time.Duration,
+	bus *broker.Bus,
+) *PendingConfigMonitor {
+	return &PendingConfigMonitor{
+		dbpool:   dbpool,
+		l1Client: l1Client,
+		address:  address,
+		notifier: notifier,
+		leadTime: leadTime,
+		bus:      bus,
+	}
+}
+
+// Run periodically checks for new pending configs until the context is canceled.
+func (m *PendingConfigMonitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(pendingConfigPollInterval)
+	defer ticker.Stop()
+	for {
+		if err := m.check(ctx); err != nil {
+			log.Warn().Err(err).Msg("failed to check pending keyper configs")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *PendingConfigMonitor) check(ctx context.Context) error {
+	header, err := m.l1Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get current block header")
+	}
+	blockNumber := header.Number.Uint64()
+	db := chainobsdb.New(m.dbpool)
+	if err := sampleBlock(ctx, db, header); err != nil {
+		return err
+	}
+	activated, err := db.DeleteActivatedPendingConfigs(ctx, int64(blockNumber))
+	if err != nil {
+		return errors.Wrap(err, "failed to delete activated pending configs from db")
+	}
+	for _, cfg := range activated {
+		if err := m.publishActivation(ctx, cfg); err != nil {
+			return err
+		}
+	}
+	configs, err := db.GetPendingConfigs(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get pending configs from db")
+	}
+	if len(configs) == 0 {
+		return nil
+	}
+	estimate, ok, err := estimateBlockTimes(ctx, db)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// sampleBlock has just stored a sample, so this only happens if the chain went backwards
+		return errors.New("no block samples to estimate activation times from")
+	}
+
+	inCurrentSet := false
+	currentSet, err := db.GetKeyperSet(ctx, int64(blockNumber))
+	if err != nil && err != pgx.ErrNoRows {
+		return errors.Wrap(err, "failed to get current keyper set from db")
+	}
+	if err == nil {
+		inCurrentSet, err = containsAddress(currentSet.Keypers, m.address)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, cfg := range configs {
+		activationTime := estimate.Time(cfg.ActivationBlockNumber)
+		dueSoon := m.leadTime > 0 && time.Until(activationTime) < m.leadTime
+		if cfg.Notified && (cfg.LeadTimeNotified || !dueSoon) {
+			continue
+		}
+		inPendingSet, err := containsAddress(cfg.Keypers, m.address)
+		if err != nil {
+			return err
+		}
+		if inPendingSet || inCurrentSet {
+			a := pendingConfigAlert(cfg, blockNumber, activationTime, inCurrentSet, inPendingSet)
+			if cfg.Notified {
+				a.Summary += " within " + m.leadTime.String()
+			}
+			if err := m.notifier.Notify(ctx, a); err != nil {
+				return errors.Wrapf(err, "failed to notify about pending keyper config %d", cfg.KeyperConfigIndex)
+			}
+		} else if !cfg.Notified {
+			log.Info().Int64("keyper-config-index", cfg.KeyperConfigIndex).
+				Msg("ignoring pending keyper config not affecting this node")
+		}
+		if !cfg.Notified {
+			if err := db.SetPendingConfigNotified(ctx, cfg.KeyperConfigIndex); err != nil {
+				return errors.Wrap(err, "failed to mark pending config as notified in db")
+			}
+		}
+		// The lead time alert is skipped if the config is already due when it's first seen.
+		if dueSoon {
+			if err := db.SetPendingConfigLeadTimeNotified(ctx, cfg.KeyperConfigIndex); err != nil {
+				return errors.Wrap(err, "failed to mark pending config as lead time notified in db")
+			}
+		}
+	}
+	return nil
+}
+
+func (m *PendingConfigMonitor) publishActivation(ctx context.Context, cfg chainobsdb.PendingConfig) error {
+	keypers, err := shdb.DecodeAddresses(cfg.Keypers)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode keyper addresses")
+	}
+	log.Info().Int64("keyper-config-index", cfg.KeyperConfigIndex).
+		Int64("activation-block-number", cfg.ActivationBlockNumber).
+		Msg("keyper set activated")
+	return broker.Publish(ctx, m.bus, broker.KeyperSetActivatedTopic, broker.KeyperSetActivated{
+		KeyperConfigIndex:     uint64(cfg.KeyperConfigIndex),
+		ActivationBlockNumber: uint64(cfg.ActivationBlockNumber),
+		Keypers:               keypers,
+		Threshold:             uint64(cfg.Threshold),
+	})
+}
+
+func pendingConfigAlert(
+	cfg chainobsdb.PendingConfig,
+	blockNumber uint64,
+	activationTime time.Time,
+	inCurrentSet, inPendingSet bool,
+) alert.Alert {
+	var summary string
+	switch {
+	case inPendingSet && !inCurrentSet:
+		summary = "this node is scheduled to join the keyper set"
+	case !inPendingSet && inCurrentSet:
+		summary = "this node is scheduled to leave the keyper set"
+	default:
+		summary = "the keyper set of this node is scheduled to change"
+	}
+	return alert.Alert{
+		Severity: alert.SeverityWarning,
+		Summary:  summary,
+		Details: map[string]string{
+			"keyper-config-index":       strconv.FormatInt(cfg.KeyperConfigIndex, 10),
+			"activation-block-number":   strconv.FormatInt(cfg.ActivationBlockNumber, 10),
+			"blocks-until-activation":   strconv.FormatInt(cfg.ActivationBlockNumber-int64(blockNumber), 10),
+			"estimated-activation-time": activationTime.UTC().Format(time.RFC3339),
+			"num-keypers":               strconv.Itoa(len(cfg.Keypers)),
+			"threshold":                 fmt.Sprint(cfg.Threshold),
+		},
+		Time: time.Now(),
+	}
+}
+
+// IsKeyperWithin reports whether the address is a member of the keyper set active at the given
+// block, or of a pending keyper set that activates within lookahead blocks after it.
+func IsKeyperWithin(
+	ctx context.Context, db *chainobsdb.Queries, address common.Address, blockNumber, lookahead uint64,
+) (bool, error) {
+	if blockNumber > math.MaxInt64 {
+		return false, errors.Errorf("block number %d overflows int64", blockNumber)
+	}
+	currentSet, err := db.GetKeyperSet(ctx, int64(blockNumber))
+	if err != nil && err != pgx.ErrNoRows {
+		return false, errors.Wrap(err, "failed to get current keyper set from db")
+	}
+	if err == nil {
+		inCurrentSet, err := containsAddress(currentSet.Keypers, address)
+		if err != nil || inCurrentSet {
+			return inCurrentSet, err
+		}
+	}
+	configs, err := db.GetPendingConfigs(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get pending configs from db")
+	}
+	for _, cfg := range configs {
+		// configs that have been activated already are covered by the current set
+		if cfg.ActivationBlockNumber <= int64(blockNumber) ||
+			uint64(cfg.ActivationBlockNumber)-blockNumber > lookahead {
+			continue
+		}
+		inPendingSet, err := containsAddress(cfg.Keypers, address)
+		if err != nil || inPendingSet {
+			return inPendingSet, err
+		}
+	}
+	return false, nil
+}
+
+func containsAddress(encodedAddrs []string, address common.Address) (bool, error) {
+	addrs, err := shdb.DecodeAddresses(encodedAddrs)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to decode keyper addresses")
+	}
+	for _, a := range addrs {
+		if a == address {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// PendingConfig is a pending keyper config as listed by the API.
+type PendingConfig struct {
+	KeyperConfigIndex       int64      `json:"keyperConfigIndex"`
+	ActivationBlockNumber   int64      `json:"activationBlockNumber"`
+	EstimatedActivationTime *time.Time `json:"estimatedActivationTime,omitempty"`
+	ScheduledBlockNumber    int64      `json:"scheduledBlockNumber"`
+	NumKeypers              int        `json:"numKeypers"`
+	Threshold               int32      `json:"threshold"`
+}
+
+// PendingConfigsHandler lists the keyper configs that are scheduled, but not active yet, together
+// with their estimated activation times.
+func PendingConfigsHandler(dbpool *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		db := chainobsdb.New(dbpool)
+		rows, err := db.GetPendingConfigs(r.Context())
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get pending configs from db")
+			http.Error(w, "failed to get pending configs", http.StatusInternalServerError)
+			return
+		}
+		estimate, ok, err := estimateBlockTimes(r.Context(), db)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to estimate block times")
+			http.Error(w, "failed to estimate block times", http.StatusInternalServerError)
+			return
+		}
+		configs := make([]PendingConfig, len(rows))
+		for i, row := range rows {
+			configs[i] = PendingConfig{
+				KeyperConfigIndex:     row.KeyperConfigIndex,
+				ActivationBlockNumber: row.ActivationBlockNumber,
+				ScheduledBlockNumber:  row.ScheduledBlockNumber,
+				NumKeypers:            len(row.Keypers),
+				Threshold:             row.Threshold,
+			}
+			if ok {
+				t := estimate.Time(row.ActivationBlockNumber).UTC()
+				configs[i].EstimatedActivationTime = &t
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(configs)
+	}
+}
@@ -0,0 +1,58 @@
+package chainobserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+func TestIsKeyperWithinIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+	db := chainobsdb.New(dbpool)
+
+	current := common.HexToAddress("0x1")
+	next := common.HexToAddress("0x2")
+	other := common.HexToAddress("0x3")
+	err := db.InsertKeyperSet(ctx, chainobsdb.InsertKeyperSetParams{
+		KeyperConfigIndex:     1,
+		ActivationBlockNumber: 10,
+		Keypers:               shdb.EncodeAddresses([]common.Address{current}),
+		Threshold:             1,
+	})
+	assert.NilError(t, err)
+	err = db.InsertPendingConfig(ctx, chainobsdb.InsertPendingConfigParams{
+		KeyperConfigIndex:     2,
+		ActivationBlockNumber: 100,
+		Keypers:               shdb.EncodeAddresses([]common.Address{next}),
+		Threshold:             1,
+		ScheduledBlockNumber:  20,
+	})
+	assert.NilError(t, err)
+
+	for _, tc := range []struct {
+		address     common.Address
+		blockNumber uint64
+		want        bool
+	}{
+		{current, 5, false},
+		{current, 50, true},
+		{next, 50, false},
+		{next, 90, true},
+		{other, 90, false},
+	} {
+		isKeyper, err := IsKeyperWithin(ctx, db, tc.address, tc.blockNumber, 10)
+		assert.NilError(t, err)
+		assert.Equal(t, isKeyper, tc.want, "address %s at block %d", tc.address, tc.blockNumber)
+	}
+}
@@ -0,0 +1,133 @@
+package chainobserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+)
+
+// fakeSyncProgress is an in-memory chainobsdb.SyncProgressStore.
+type fakeSyncProgress struct {
+	shared     chainobsdb.GetEventSyncProgressRow
+	eventTypes map[string]chainobsdb.EventTypeSyncProgress
+}
+
+func (f *fakeSyncProgress) GetEventSyncProgress(context.Context) (chainobsdb.GetEventSyncProgressRow, error) {
+	return f.shared, nil
+}
+
+func (f *fakeSyncProgress) UpdateEventSyncProgress(_ context.Context, arg chainobsdb.UpdateEventSyncProgressParams) error {
+	f.shared = chainobsdb.GetEventSyncProgressRow(arg)
+	return nil
+}
+
+func (f *fakeSyncProgress) GetEventTypeSyncProgress(
+	_ context.Context, eventType string,
+) (chainobsdb.EventTypeSyncProgress, error) {
+	progress, ok := f.eventTypes[eventType]
+	if !ok {
+		return progress, pgx.ErrNoRows
+	}
+	return progress, nil
+}
+
+func (f *fakeSyncProgress) UpdateEventTypeSyncProgress(
+	_ context.Context, arg chainobsdb.UpdateEventTypeSyncProgressParams,
+) error {
+	f.eventTypes[arg.EventType] = chainobsdb.EventTypeSyncProgress{
+		EventType:       arg.EventType,
+		NextBlockNumber: arg.NextBlockNumber,
+		NextLogIndex:    arg.NextLogIndex,
+	}
+	return nil
+}
+
+func (f *fakeSyncProgress) UpdateEventSyncProgressFromEventTypes(_ context.Context, eventTypes []string) error {
+	for i, eventType := range eventTypes {
+		p := f.eventTypes[eventType]
+		if i == 0 || p.NextBlockNumber < int64(f.shared.NextBlockNumber) ||
+			p.NextBlockNumber == int64(f.shared.NextBlockNumber) && p.NextLogIndex < int64(f.shared.NextLogIndex) {
+			f.shared = chainobsdb.GetEventSyncProgressRow{
+				NextBlockNumber: int32(p.NextBlockNumber),
+				NextLogIndex:    int32(p.NextLogIndex),
+			}
+		}
+	}
+	return nil
+}
+
+func TestInitSyncProgress(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeSyncProgress{
+		shared:     chainobsdb.GetEventSyncProgressRow{NextBlockNumber: 20, NextLogIndex: 3},
+		eventTypes: make(map[string]chainobsdb.EventTypeSyncProgress),
+	}
+	known := &eventsyncer.EventType{Address: common.HexToAddress("0x1"), Name: "A", FromBlockNumber: 5}
+	early := &eventsyncer.EventType{Address: common.HexToAddress("0x2"), Name: "B", FromBlockNumber: 5}
+	late := &eventsyncer.EventType{Address: common.HexToAddress("0x3"), Name: "C", FromBlockNumber: 50}
+	db.eventTypes[known.Key()] = chainobsdb.EventTypeSyncProgress{EventType: known.Key(), NextBlockNumber: 30}
+
+	assert.NilError(t, initSyncProgress(ctx, db, []*eventsyncer.EventType{known, early, late}))
+	assert.Equal(t, db.eventTypes[known.Key()].NextBlockNumber, int64(30))
+	assert.Equal(t, db.eventTypes[early.Key()].NextBlockNumber, int64(20))
+	assert.Equal(t, db.eventTypes[early.Key()].NextLogIndex, int64(3))
+	assert.Equal(t, db.eventTypes[late.Key()].NextBlockNumber, int64(50))
+	assert.Equal(t, db.shared.NextBlockNumber, int32(20))
+}
+
+func TestEventTypeSyncProgressIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+	db := chainobsdb.New(dbpool)
+	assert.NilError(t, db.UpdateEventSyncProgress(ctx, chainobsdb.UpdateEventSyncProgressParams{
+		NextBlockNumber: 20,
+		NextLogIndex:    3,
+	}))
+
+	early := &eventsyncer.EventType{Address: common.HexToAddress("0x1"), Name: "A", FromBlockNumber: 5}
+	late := &eventsyncer.EventType{Address: common.HexToAddress("0x2"), Name: "B", FromBlockNumber: 50}
+	eventTypes := []*eventsyncer.EventType{early, late}
+	keys := []string{early.Key(), late.Key()}
+	chainobs := &ChainObserver{dbpool: dbpool}
+	assert.NilError(t, chainobs.initSyncProgress(ctx, eventTypes))
+
+	// new event types start at the shared cursor, unless they're deployed later
+	progress, err := db.GetEventTypeSyncProgress(ctx, early.Key())
+	assert.NilError(t, err)
+	assert.Equal(t, progress.NextBlockNumber, int64(20))
+	assert.Equal(t, progress.NextLogIndex, int64(3))
+	progress, err = db.GetEventTypeSyncProgress(ctx, late.Key())
+	assert.NilError(t, err)
+	assert.Equal(t, progress.NextBlockNumber, int64(50))
+	assert.Equal(t, progress.NextLogIndex, int64(0))
+
+	// the shared cursor follows the event type that's behind
+	assert.NilError(t, chainobs.handleEventSyncUpdate(ctx, late.Key(), keys, eventsyncer.EventSyncUpdate{BlockNumber: 60}))
+	shared, err := db.GetEventSyncProgress(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, shared.NextBlockNumber, int32(20))
+	assert.Equal(t, shared.NextLogIndex, int32(3))
+
+	assert.NilError(t, chainobs.handleEventSyncUpdate(ctx, early.Key(), keys, eventsyncer.EventSyncUpdate{BlockNumber: 70}))
+	shared, err = db.GetEventSyncProgress(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, shared.NextBlockNumber, int32(61))
+	assert.Equal(t, shared.NextLogIndex, int32(0))
+
+	// cursors are kept across restarts
+	assert.NilError(t, chainobs.initSyncProgress(ctx, eventTypes))
+	progress, err = db.GetEventTypeSyncProgress(ctx, early.Key())
+	assert.NilError(t, err)
+	assert.Equal(t, progress.NextBlockNumber, int64(71))
+}
@@ -0,0 +1,143 @@
+package chainobserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+const (
+	// KeyperRotationsContractName is the name of the contract announcing keyper rotations, as
+	// used in deployment.Contracts.
+	KeyperRotationsContractName = "KeyperRotations"
+	// KeyperRotatedEventName is the name of the event emitted for each rotation.
+	KeyperRotatedEventName = "Rotated"
+)
+
+// KeyperRotation replaces the address of the keyper at KeyperIndex in the keyper set with index
+// KeyperConfigIndex. The keyper keeps its index and thereby its eon key share.
+type KeyperRotation struct {
+	KeyperConfigIndex uint64
+	KeyperIndex       uint64
+	OldAddress        common.Address
+	NewAddress        common.Address
+	Raw               types.Log // the log of the Rotated event, if any
+}
+
+// RotationFunc is called for each rotation in the transaction the rotation is handled in. Nodes
+// use it to migrate the rows of their own databases to the new address.
+type RotationFunc func(ctx context.Context, db *chainobsdb.Queries, rotation KeyperRotation) error
+
+// RotationHash computes the hash both the old and the new address have to sign to rotate a keyper,
+// matching KeyperRotations.rotationHash.
+func RotationHash(chainID *big.Int, contractAddress common.Address, rotation KeyperRotation) []byte {
+	return ethcrypto.Keccak256(
+		[]byte{0x19},
+		[]byte("keyperRotation"),
+		math.U256Bytes(new(big.Int).Set(chainID)),
+		contractAddress.Bytes(),
+		math.PaddedBigBytes(new(big.Int).SetUint64(rotation.KeyperConfigIndex), 8),
+		math.PaddedBigBytes(new(big.Int).SetUint64(rotation.KeyperIndex), 8),
+		rotation.OldAddress.Bytes(),
+		rotation.NewAddress.Bytes(),
+	)
+}
+
+// SignRotation signs the rotation hash in the format expected by OpenZeppelin's ECDSA.recover,
+// i.e. with a recovery id of 27 or 28.
+func SignRotation(hash []byte, privateKey *ecdsa.PrivateKey) ([]byte, error) {
+	signature, err := ethcrypto.Sign(hash, privateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign rotation")
+	}
+	signature[64] += 27
+	return signature, nil
+}
+
+func decodeKeyperRotation(event eventsyncer.DynamicEvent) (KeyperRotation, error) {
+	r := KeyperRotation{Raw: event.Raw}
+	var ok bool
+	if r.KeyperConfigIndex, ok = event.Args["keyperConfigIndex"].(uint64); !ok {
+		return r, errors.New("missing or invalid keyperConfigIndex in Rotated event")
+	}
+	if r.KeyperIndex, ok = event.Args["keyperIndex"].(uint64); !ok {
+		return r, errors.New("missing or invalid keyperIndex in Rotated event")
+	}
+	if r.OldAddress, ok = event.Args["oldAddress"].(common.Address); !ok {
+		return r, errors.New("missing or invalid oldAddress in Rotated event")
+	}
+	if r.NewAddress, ok = event.Args["newAddress"].(common.Address); !ok {
+		return r, errors.New("missing or invalid newAddress in Rotated event")
+	}
+	return r, nil
+}
+
+// KeyperRotationHandler returns the handler for Rotated events of the KeyperRotations contract.
+// It replaces the old address by the new one in the keyper set and, if it's still pending, in the
+// pending config, records the rotation and finally calls onRotation, which may be nil.
+func KeyperRotationHandler(onRotation RotationFunc) EventHandler {
+	return func(ctx context.Context, db *chainobsdb.Queries, event eventsyncer.DynamicEvent) error {
+		r, err := decodeKeyperRotation(event)
+		if err != nil {
+			return err
+		}
+		log.Info().
+			Uint64("block-number", event.Raw.BlockNumber).
+			Uint64("keyper-config-index", r.KeyperConfigIndex).
+			Uint64("keyper-index", r.KeyperIndex).
+			Str("old-address", r.OldAddress.Hex()).
+			Str("new-address", r.NewAddress.Hex()).
+			Msg("handling Rotated event from keyper rotations contract")
+
+		rows, err := db.RotateKeyperSetAddress(ctx, chainobsdb.RotateKeyperSetAddressParams{
+			KeyperIndex:       int32(r.KeyperIndex),
+			NewAddress:        shdb.EncodeAddress(r.NewAddress),
+			KeyperConfigIndex: int64(r.KeyperConfigIndex),
+			OldAddress:        shdb.EncodeAddress(r.OldAddress),
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to rotate keyper address in keyper set")
+		}
+		if rows == 0 {
+			// The contract only emits rotations of existing keypers, so this means we haven't
+			// seen the keyper set, or that the db is inconsistent with the contract.
+			return errors.Errorf("keyper %d with address %s not found in keyper set %d",
+				r.KeyperIndex, r.OldAddress.Hex(), r.KeyperConfigIndex)
+		}
+		_, err = db.RotatePendingConfigAddress(ctx, chainobsdb.RotatePendingConfigAddressParams{
+			KeyperIndex:       int32(r.KeyperIndex),
+			NewAddress:        shdb.EncodeAddress(r.NewAddress),
+			KeyperConfigIndex: int64(r.KeyperConfigIndex),
+			OldAddress:        shdb.EncodeAddress(r.OldAddress),
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to rotate keyper address in pending config")
+		}
+		err = db.InsertKeyperRotation(ctx, chainobsdb.InsertKeyperRotationParams{
+			BlockNumber:       int64(event.Raw.BlockNumber),
+			LogIndex:          int64(event.Raw.Index),
+			KeyperConfigIndex: int64(r.KeyperConfigIndex),
+			KeyperIndex:       int64(r.KeyperIndex),
+			OldAddress:        shdb.EncodeAddress(r.OldAddress),
+			NewAddress:        shdb.EncodeAddress(r.NewAddress),
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to insert keyper rotation into db")
+		}
+		if onRotation == nil {
+			return nil
+		}
+		return onRotation(ctx, db, r)
+	}
+}
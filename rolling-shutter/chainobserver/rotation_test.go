@@ -0,0 +1,66 @@
+package chainobserver
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+)
+
+func TestRotationHash(t *testing.T) {
+	rotation := KeyperRotation{
+		KeyperConfigIndex: 3,
+		KeyperIndex:       1,
+		OldAddress:        common.HexToAddress("0x1"),
+		NewAddress:        common.HexToAddress("0x2"),
+	}
+	contractAddress := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	hash := RotationHash(big.NewInt(31337), contractAddress, rotation)
+	assert.Equal(t, hexutil.Encode(hash), "0xdbd113d25a001ee1eafec65e686a0421609647342638749b2f979d4fd105648b")
+}
+
+func TestSignRotation(t *testing.T) {
+	privateKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	hash := ethcrypto.Keccak256([]byte("rotation"))
+
+	signature, err := SignRotation(hash, privateKey)
+	assert.NilError(t, err)
+	assert.Assert(t, signature[64] == 27 || signature[64] == 28)
+
+	signature[64] -= 27
+	pubkey, err := ethcrypto.SigToPub(hash, signature)
+	assert.NilError(t, err)
+	assert.Equal(t, ethcrypto.PubkeyToAddress(*pubkey), ethcrypto.PubkeyToAddress(privateKey.PublicKey))
+}
+
+func TestDecodeKeyperRotation(t *testing.T) {
+	event := eventsyncer.DynamicEvent{
+		ContractName: KeyperRotationsContractName,
+		Name:         KeyperRotatedEventName,
+		Args: map[string]interface{}{
+			"keyperConfigIndex": uint64(3),
+			"keyperIndex":       uint64(1),
+			"oldAddress":        common.HexToAddress("0x1"),
+			"newAddress":        common.HexToAddress("0x2"),
+		},
+		Raw: types.Log{BlockNumber: 10},
+	}
+	r, err := decodeKeyperRotation(event)
+	assert.NilError(t, err)
+	assert.Equal(t, r.KeyperConfigIndex, uint64(3))
+	assert.Equal(t, r.KeyperIndex, uint64(1))
+	assert.Equal(t, r.OldAddress, common.HexToAddress("0x1"))
+	assert.Equal(t, r.NewAddress, common.HexToAddress("0x2"))
+	assert.Equal(t, r.Raw.BlockNumber, uint64(10))
+
+	delete(event.Args, "newAddress")
+	_, err = decodeKeyperRotation(event)
+	assert.ErrorContains(t, err, "newAddress")
+}
@@ -0,0 +1,108 @@
+package chainobserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+)
+
+// EventHandler handles an event of a type loaded from an event schema. It is called in the same
+// db transaction that advances the sync progress.
+type EventHandler func(ctx context.Context, db *chainobsdb.Queries, event eventsyncer.DynamicEvent) error
+
+func handlerKey(contractName, eventName string) string {
+	return contractName + "." + eventName
+}
+
+// RegisterEventHandler registers the handler for an event loaded from an event schema. Events
+// without a registered handler are ignored.
+func (chainobs *ChainObserver) RegisterEventHandler(contractName, eventName string, handler EventHandler) {
+	chainobs.handlers[handlerKey(contractName, eventName)] = handler
+}
+
+// LoadEventTypes loads the event schemas from the event_schema table and, if dir is not empty,
+// from the JSON files in dir. Schemas from dir replace the ones from the db with the same contract
+// name. Note that events of a newly added schema are only synced from the current sync progress
+// on, regardless of the schema's from block number.
+func (chainobs *ChainObserver) LoadEventTypes(ctx context.Context, dir string) ([]*eventsyncer.EventType, error) {
+	rows, err := chainobsdb.New(chainobs.dbpool).GetEventSchemas(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get event schemas from db")
+	}
+	schemas := []*eventsyncer.EventSchema{}
+	for _, row := range rows {
+		if !common.IsHexAddress(row.Address) {
+			return nil, errors.Errorf("invalid address %q of event schema %s", row.Address, row.ContractName)
+		}
+		schemas = append(schemas, &eventsyncer.EventSchema{
+			ContractName:    row.ContractName,
+			Address:         common.HexToAddress(row.Address),
+			FromBlockNumber: uint64(row.FromBlockNumber),
+			ABI:             []byte(row.Abi),
+			Events:          row.Events,
+			BlobHashArgs:    row.BlobHashArgs,
+		})
+	}
+	if dir != "" {
+		dirSchemas, err := eventsyncer.ReadEventSchemaDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		schemas = mergeEventSchemas(schemas, dirSchemas)
+	}
+
+	eventTypes := []*eventsyncer.EventType{}
+	for _, schema := range schemas {
+		types, err := schema.EventTypes(chainobs.contracts.Client)
+		if err != nil {
+			return nil, err
+		}
+		log.Info().Str("contract", schema.ContractName).Strs("events", schema.Events).
+			Str("address", schema.Address.Hex()).Msg("loaded event schema")
+		eventTypes = append(eventTypes, types...)
+	}
+	return eventTypes, nil
+}
+
+// mergeEventSchemas returns the schemas of base, with those replaced by the ones in overrides
+// with the same contract name.
+func mergeEventSchemas(base, overrides []*eventsyncer.EventSchema) []*eventsyncer.EventSchema {
+	index := make(map[string]int)
+	merged := []*eventsyncer.EventSchema{}
+	for _, schema := range append(base, overrides...) {
+		if i, ok := index[schema.ContractName]; ok {
+			merged[i] = schema
+			continue
+		}
+		index[schema.ContractName] = len(merged)
+		merged = append(merged, schema)
+	}
+	return merged
+}
+
+func (chainobs *ChainObserver) handleDynamicEvent(
+	ctx context.Context, db *chainobsdb.Queries, event eventsyncer.DynamicEvent,
+) error {
+	handler, ok := chainobs.handlers[handlerKey(event.ContractName, event.Name)]
+	if !ok {
+		return ignoreEvent(ctx, db, event, fmt.Sprintf(
+			"no handler registered for %s event of contract %s", event.Name, event.ContractName))
+	}
+	if chainobs.blobs != nil && len(event.BlobHashes) > 0 {
+		blobs, err := chainobs.loadBlobs(ctx, db, event)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load blobs of %s event of contract %s", event.Name, event.ContractName)
+		}
+		event.Blobs = blobs
+	}
+	return errors.Wrapf(
+		handler(ctx, db, event),
+		"failed to handle %s event of contract %s", event.Name, event.ContractName,
+	)
+}
@@ -2,6 +2,8 @@ package collator
 
 import (
 	"context"
+	"encoding/json"
+	"os"
 
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
@@ -12,8 +14,11 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/config"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/metadb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/provenancedb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration/command"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p/provenance"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
 
@@ -28,6 +33,9 @@ func Cmd() *cobra.Command {
 		command.WithGenerateConfigSubcommand(),
 	)
 	builder.AddInitDBCommand(initDB)
+	builder.AddRedecryptCommand(redecrypt)
+	builder.AddReplicationCommand(replication)
+	builder.AddProvenanceCommand(provenanceRecords)
 	return builder.Command()
 }
 
@@ -60,3 +68,104 @@ func initDB(cfg *config.Config) error {
 func main(cfg *config.Config) error {
 	return service.RunWithSighandler(context.Background(), collator.New(cfg))
 }
+
+func redecrypt(cfg *config.Config, fromEpoch, toEpoch uint64) error {
+	ctx := context.Background()
+
+	dbpool, err := pgxpool.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	if err := cltrdb.ValidateDB(ctx, dbpool); err != nil {
+		return err
+	}
+	reports, err := collator.Redecrypt(
+		ctx,
+		cltrdb.New(dbpool),
+		cfg.Ethereum.PrivateKey.Key,
+		epochid.Uint64ToEpochID(fromEpoch),
+		epochid.Uint64ToEpochID(toEpoch),
+	)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	numInconsistent := 0
+	for _, report := range reports {
+		if !report.Consistent() {
+			numInconsistent++
+		}
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	}
+	if numInconsistent > 0 {
+		return errors.Errorf("%d of %d batchtxs could not be reproduced", numInconsistent, len(reports))
+	}
+	return nil
+}
+
+func replication(cfg *config.Config, action string) error {
+	ctx := context.Background()
+
+	dbpool, err := pgxpool.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	if err := cltrdb.ValidateDB(ctx, dbpool); err != nil {
+		return err
+	}
+	switch action {
+	case "setup":
+		return cltrdb.Publication.Setup(ctx, dbpool)
+	case "drop":
+		return cltrdb.Publication.Drop(ctx, dbpool)
+	case "status":
+		status, err := cltrdb.Publication.Status(ctx, dbpool)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(status); err != nil {
+			return err
+		}
+		if !status.OK() {
+			return errors.Errorf("publication %s is not fully set up", cltrdb.Publication.Name)
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown replication action %s", action)
+	}
+}
+
+func provenanceRecords(cfg *config.Config, query command.ProvenanceQuery) error {
+	ctx := context.Background()
+
+	dbpool, err := pgxpool.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	if err := cltrdb.ValidateDB(ctx, dbpool); err != nil {
+		return err
+	}
+	records, err := provenancedb.New(dbpool).FindMessageProvenance(ctx, provenancedb.FindMessageProvenanceParams{
+		Since:       query.Since,
+		Until:       query.Until,
+		Peer:        query.Peer,
+		MessageType: query.MessageType,
+		EpochID:     query.EpochID,
+		MessageHash: query.MessageHash,
+		MaxEntries:  query.Limit,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to query message provenance")
+	}
+	return provenance.WriteJSON(os.Stdout, records)
+}
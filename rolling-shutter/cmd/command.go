@@ -7,13 +7,23 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/chain"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/collator"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/cryptocmd"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/debug"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/diffstate"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/explorer"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/gateway"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/gentestvectors"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/keyper"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/loadgen"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/mockkeypers"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/mocknode"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/mocksequencer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/p2pnode"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/proxy"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/simulateconfig"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/snapshot"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/snapshotkeyper"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/top"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/verifydkg"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/rootcmd"
 )
 
@@ -24,12 +34,23 @@ func Subcommands() []*cobra.Command {
 		collator.Cmd(),
 		keyper.Cmd(),
 		mocknode.Cmd(),
+		mockkeypers.Cmd(),
+		loadgen.Cmd(),
 		snapshot.Cmd(),
 		snapshotkeyper.Cmd(),
 		cryptocmd.Cmd(),
+		debug.Cmd(),
 		proxy.Cmd(),
+		explorer.Cmd(),
+		gateway.Cmd(),
 		mocksequencer.Cmd(),
 		p2pnode.Cmd(),
+		simulateconfig.Cmd(),
+		verifydkg.Cmd(),
+		verifydkg.ExportCmd(),
+		diffstate.Cmd(),
+		gentestvectors.Cmd(),
+		top.Cmd(),
 	}
 }
 
@@ -0,0 +1,143 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/annotation"
+)
+
+var annotationFlags struct {
+	kind   string
+	entity string
+	label  string
+	note   string
+	author string
+	delete int64
+}
+
+func annotationsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "annotations",
+		Short: "Show, add and delete operator annotations of a running keyper",
+		Long: `This command prints the notes and labels operators attached to keypers, eons
+and epochs of a running keyper, optionally only those of the entity given by
+--kind and --entity. With --label or --note it annotates that entity, with
+--delete it deletes an annotation, both of which require operator access. The
+annotations are served at /annotations on the keyper's HTTP API.
+
+The entity is the address of a keyper, the index of an eon or the hex encoded
+id of an epoch, e.g.
+
+  rolling-shutter debug annotations -u http://localhost:8080 --kind eon \
+    --entity 5 --note "DKG restarted due to outage" --author alice`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return annotations(cmd.Context())
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&urlFlag, "url", "u", "", "URL of the keyper's HTTP API")
+	cmd.PersistentFlags().StringVarP(&tokenFlag, "token", "t", "", "bearer token granting access to the keyper")
+	cmd.PersistentFlags().StringVar(&annotationFlags.kind, "kind", "", "kind of the annotated entity: keyper, eon or epoch")
+	cmd.PersistentFlags().StringVar(&annotationFlags.entity, "entity", "", "id of the annotated entity")
+	cmd.PersistentFlags().StringVar(&annotationFlags.label, "label", "", "short label to attach to the entity")
+	cmd.PersistentFlags().StringVar(&annotationFlags.note, "note", "", "note to attach to the entity")
+	cmd.PersistentFlags().StringVar(&annotationFlags.author, "author", "", "name of the operator adding the annotation")
+	cmd.PersistentFlags().Int64Var(&annotationFlags.delete, "delete", 0, "id of the annotation to delete")
+	cmd.PersistentFlags().StringVar(&tlsFlags.CAFile, "ca-file", "", "PEM encoded CA certificates used to verify the node")
+	cmd.PersistentFlags().StringVar(&tlsFlags.CertFile, "cert-file", "", "PEM encoded client certificate for mTLS")
+	cmd.PersistentFlags().StringVar(&tlsFlags.KeyFile, "key-file", "", "PEM encoded client private key for mTLS")
+
+	cmd.MarkPersistentFlagRequired("url")
+	cmd.MarkFlagsRequiredTogether("kind", "entity")
+	cmd.MarkFlagsMutuallyExclusive("delete", "label")
+	cmd.MarkFlagsMutuallyExclusive("delete", "note")
+
+	return cmd
+}
+
+func annotations(ctx context.Context) error {
+	if err := tlsFlags.Validate(); err != nil {
+		return err
+	}
+	client, err := tlsFlags.HTTPClient()
+	if err != nil {
+		return err
+	}
+
+	method := http.MethodGet
+	path := "/annotations/"
+	var body io.Reader
+	switch {
+	case annotationFlags.delete != 0:
+		method = http.MethodDelete
+		path += fmt.Sprint(annotationFlags.delete)
+	case annotationFlags.label != "" || annotationFlags.note != "":
+		method = http.MethodPost
+		data, err := json.Marshal(annotation.Request{
+			Kind:   annotationFlags.kind,
+			Entity: annotationFlags.entity,
+			Label:  annotationFlags.label,
+			Note:   annotationFlags.note,
+			Author: annotationFlags.author,
+		})
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	case annotationFlags.kind != "":
+		path += "?" + url.Values{"kind": {annotationFlags.kind}, "entity": {annotationFlags.entity}}.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(urlFlag, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	if tokenFlag != "" {
+		req.Header.Set("Authorization", "Bearer "+tokenFlag)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		fmt.Printf("deleted annotation %d\n", annotationFlags.delete)
+		return nil
+	case resp.StatusCode != http.StatusOK:
+		return errors.Errorf("unexpected response status %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	case method == http.MethodPost:
+		created := annotation.Annotation{}
+		if err := json.Unmarshal(data, &created); err != nil {
+			return errors.Wrap(err, "failed to decode annotation")
+		}
+		fmt.Printf("added annotation %d: %s\n", created.ID, created)
+		return nil
+	}
+	list := []annotation.Annotation{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.Wrap(err, "failed to decode annotations")
+	}
+	for _, a := range list {
+		fmt.Printf("%4d  %s  %s\n", a.ID, a.CreatedAt.Format("2006-01-02 15:04"), a)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package debug
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/tlsconfig"
+)
+
+var (
+	urlFlag      string
+	tokenFlag    string
+	durationFlag time.Duration
+	outputFlag   string
+	tlsFlags     = &tlsconfig.ClientConfig{}
+)
+
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "debug",
+		Short: "Tools to diagnose running nodes",
+	}
+	cmd.AddCommand(profileCmd())
+	cmd.AddCommand(featuresCmd())
+	cmd.AddCommand(annotationsCmd())
+	return cmd
+}
+
+func profileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Capture CPU, heap and goroutine profiles from a running node",
+		Long: `This command fetches profiles from the pprof endpoint of a running keyper or
+collator and writes them to a gzipped tarball, e.g. to attach to a support
+ticket. The endpoint is served at /debug/pprof on the node's HTTP API and
+requires admin access.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return profile(cmd.Context())
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&urlFlag, "url", "u", "", "URL of the node's HTTP API")
+	cmd.PersistentFlags().StringVarP(&tokenFlag, "token", "t", "", "bearer token granting admin access")
+	cmd.PersistentFlags().DurationVarP(&durationFlag, "duration", "d", 30*time.Second, "duration of the CPU profile")
+	cmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "", "output file (default profile-<timestamp>.tar.gz)")
+	cmd.PersistentFlags().StringVar(&tlsFlags.CAFile, "ca-file", "", "PEM encoded CA certificates used to verify the node")
+	cmd.PersistentFlags().StringVar(&tlsFlags.CertFile, "cert-file", "", "PEM encoded client certificate for mTLS")
+	cmd.PersistentFlags().StringVar(&tlsFlags.KeyFile, "key-file", "", "PEM encoded client private key for mTLS")
+
+	cmd.MarkPersistentFlagRequired("url")
+
+	return cmd
+}
+
+type profileRequest struct {
+	name  string
+	query string
+}
+
+func profile(ctx context.Context) error {
+	if err := tlsFlags.Validate(); err != nil {
+		return err
+	}
+	client, err := tlsFlags.HTTPClient()
+	if err != nil {
+		return err
+	}
+	if outputFlag == "" {
+		outputFlag = fmt.Sprintf("profile-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+	}
+
+	requests := []profileRequest{
+		{name: "cpu.pprof", query: fmt.Sprintf("profile?seconds=%d", int(durationFlag.Seconds()))},
+		{name: "heap.pprof", query: "heap"},
+		{name: "goroutine.pprof", query: "goroutine"},
+		{name: "goroutine.txt", query: "goroutine?debug=2"},
+	}
+
+	f, err := os.Create(outputFlag)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", outputFlag)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	for _, req := range requests {
+		log.Info().Str("profile", req.name).Msg("fetching profile")
+		data, err := fetchProfile(ctx, client, req.query)
+		if err != nil {
+			return errors.Wrapf(err, "failed to fetch %s", req.name)
+		}
+		err = tw.WriteHeader(&tar.Header{
+			Name:    req.name,
+			Mode:    0o644,
+			Size:    int64(len(data)),
+			ModTime: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	log.Info().Str("output", outputFlag).Msg("wrote profiles")
+	return nil
+}
+
+func fetchProfile(ctx context.Context, client *http.Client, query string) ([]byte, error) {
+	url := strings.TrimSuffix(urlFlag, "/") + "/debug/pprof/" + query
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if tokenFlag != "" {
+		req.Header.Set("Authorization", "Bearer "+tokenFlag)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
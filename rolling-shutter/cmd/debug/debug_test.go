@@ -0,0 +1,84 @@
+package debug
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// newProfileServer serves a fake pprof endpoint that answers each request with the requested
+// profile and its query, and requires the given bearer token.
+func newProfileServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = io.WriteString(w, r.URL.Path+"?"+r.URL.RawQuery)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func runProfile(t *testing.T, args ...string) error {
+	t.Helper()
+	cmd := profileCmd()
+	cmd.SetArgs(args)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	return cmd.ExecuteContext(context.Background())
+}
+
+func readTarball(t *testing.T, path string) map[string]string {
+	t.Helper()
+	f, err := os.Open(path)
+	assert.NilError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	assert.NilError(t, err)
+	tr := tar.NewReader(gz)
+	files := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		assert.NilError(t, err)
+		data, err := io.ReadAll(tr)
+		assert.NilError(t, err)
+		files[header.Name] = string(data)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	server := newProfileServer(t, "secret")
+	output := filepath.Join(t.TempDir(), "profile.tar.gz")
+
+	err := runProfile(t, "--url", server.URL+"/", "--token", "secret", "--duration", "2s", "--output", output)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, readTarball(t, output), map[string]string{
+		"cpu.pprof":       "/debug/pprof/profile?seconds=2",
+		"heap.pprof":      "/debug/pprof/heap?",
+		"goroutine.pprof": "/debug/pprof/goroutine?",
+		"goroutine.txt":   "/debug/pprof/goroutine?debug=2",
+	})
+}
+
+func TestProfileForbidden(t *testing.T) {
+	server := newProfileServer(t, "secret")
+	output := filepath.Join(t.TempDir(), "profile.tar.gz")
+
+	err := runProfile(t, "--url", server.URL, "--token", "wrong", "--output", output)
+	assert.ErrorContains(t, err, "403 Forbidden")
+}
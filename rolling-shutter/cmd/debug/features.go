@@ -0,0 +1,108 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/featureflag"
+)
+
+var (
+	enableFlag  string
+	disableFlag string
+	resetFlag   string
+)
+
+func featuresCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "features",
+		Short: "Show and override the feature flags of a running node",
+		Long: `This command prints the feature flags of a running keyper or collator. With
+--enable, --disable or --reset it overrides a flag until the node is restarted,
+which requires admin access. The flags are served at /features on the node's
+HTTP API.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return features(cmd.Context())
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&urlFlag, "url", "u", "", "URL of the node's HTTP API")
+	cmd.PersistentFlags().StringVarP(&tokenFlag, "token", "t", "", "bearer token granting access to the node")
+	cmd.PersistentFlags().StringVar(&enableFlag, "enable", "", "feature to enable")
+	cmd.PersistentFlags().StringVar(&disableFlag, "disable", "", "feature to disable")
+	cmd.PersistentFlags().StringVar(&resetFlag, "reset", "", "feature to reset to its configured value")
+	cmd.PersistentFlags().StringVar(&tlsFlags.CAFile, "ca-file", "", "PEM encoded CA certificates used to verify the node")
+	cmd.PersistentFlags().StringVar(&tlsFlags.CertFile, "cert-file", "", "PEM encoded client certificate for mTLS")
+	cmd.PersistentFlags().StringVar(&tlsFlags.KeyFile, "key-file", "", "PEM encoded client private key for mTLS")
+
+	cmd.MarkPersistentFlagRequired("url")
+	cmd.MarkFlagsMutuallyExclusive("enable", "disable", "reset")
+
+	return cmd
+}
+
+func features(ctx context.Context) error {
+	if err := tlsFlags.Validate(); err != nil {
+		return err
+	}
+	client, err := tlsFlags.HTTPClient()
+	if err != nil {
+		return err
+	}
+
+	method := http.MethodGet
+	path := "/features/"
+	var body io.Reader
+	switch {
+	case enableFlag != "" || disableFlag != "":
+		method = http.MethodPut
+		path += enableFlag + disableFlag
+		data, err := json.Marshal(featureflag.OverrideRequest{Enabled: enableFlag != ""})
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	case resetFlag != "":
+		method = http.MethodDelete
+		path += resetFlag
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(urlFlag, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	if tokenFlag != "" {
+		req.Header.Set("Authorization", "Bearer "+tokenFlag)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected response status %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	flags := []featureflag.Status{}
+	if err := json.Unmarshal(data, &flags); err != nil {
+		return errors.Wrap(err, "failed to decode feature flags")
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(flags)
+}
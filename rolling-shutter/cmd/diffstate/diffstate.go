@@ -0,0 +1,91 @@
+package diffstate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/statediff"
+)
+
+var (
+	databaseURLAFlag   string
+	databaseURLBFlag   string
+	maxDivergencesFlag int
+)
+
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff-state",
+		Short: "Compare the databases of two keypers",
+		Long: `This command compares the databases of two keypers of the same instance table
+by table: the keyper sets, the eons, the public outcome of the DKGs, the eon
+public keys, the decryption keys and the decryption key shares. It reports the
+rows whose values differ or which are missing from one of the databases, the
+earliest ones first, together with the block and epoch they belong to.
+Decryption key shares missing from one database are only counted, as keypers
+don't necessarily receive or keep all of them.
+
+The command fails if the databases diverge.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return diffState(cmd.Context())
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&databaseURLAFlag, "a", "", "URL of the first keyper database")
+	cmd.PersistentFlags().StringVar(&databaseURLBFlag, "b", "", "URL of the second keyper database")
+	cmd.PersistentFlags().IntVar(&maxDivergencesFlag, "max-divergences", 100, "maximum number of divergences listed per table")
+
+	cmd.MarkPersistentFlagRequired("a")
+	cmd.MarkPersistentFlagRequired("b")
+
+	return cmd
+}
+
+func connect(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
+	dbpool, err := pgxpool.Connect(ctx, databaseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to database")
+	}
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		dbpool.Close()
+		return nil, err
+	}
+	return dbpool, nil
+}
+
+func diffState(ctx context.Context) error {
+	if maxDivergencesFlag <= 0 {
+		return errors.New("--max-divergences must be positive")
+	}
+	dbpoolA, err := connect(ctx, databaseURLAFlag)
+	if err != nil {
+		return errors.Wrap(err, "database a")
+	}
+	defer dbpoolA.Close()
+	dbpoolB, err := connect(ctx, databaseURLBFlag)
+	if err != nil {
+		return errors.Wrap(err, "database b")
+	}
+	defer dbpoolB.Close()
+
+	report, err := statediff.Compare(ctx, dbpoolA, dbpoolB, maxDivergencesFlag)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	if report.Diverged() {
+		return errors.Errorf("databases diverge, earliest at block %d in table %s", report.Earliest.Block, report.Earliest.Table)
+	}
+	return nil
+}
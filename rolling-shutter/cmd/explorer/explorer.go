@@ -0,0 +1,34 @@
+package explorer
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/shversion"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/explorer"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration/command"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+)
+
+func Cmd() *cobra.Command {
+	builder := command.Build(
+		main,
+		command.Usage(
+			"Serve a read-only explorer over a keyper's database",
+			`This command serves a web UI and a JSON API showing the eons, keyper sets, DKG
+results, eon public key confirmations and recently finalized epochs with their
+decryption keys stored in a keyper's database. It only reads from the database,
+so it can be exposed to give insight into a deployment without granting access
+to the database itself.`,
+		),
+		command.WithGenerateConfigSubcommand(),
+	)
+	return builder.Command()
+}
+
+func main(config *explorer.Config) error {
+	log.Info().Msgf("Starting shutter explorer version %s", shversion.Version())
+	return service.RunWithSighandler(context.Background(), explorer.New(config))
+}
@@ -0,0 +1,43 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/shversion"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/gateway"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration/command"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+)
+
+func Cmd() *cobra.Command {
+	builder := command.Build(
+		main,
+		command.Usage(
+			"Run a gateway serving eon public keys and decryption keys over HTTP",
+			`This command follows the gossip of a Shutter instance without taking part in it
+and serves the eon public keys and decryption keys it sees over a rate limited
+HTTP API. Eon public keys are served once enough of the configured keypers
+signed them, and decryption keys only if they match them. Responses carry
+ETags and are cacheable, so that the API can be put behind a CDN and dapps can
+fetch keys at scale without connecting to the keypers.`,
+		),
+		command.WithGenerateConfigSubcommand(),
+		command.WithDumpConfigSubcommand(),
+	)
+	return builder.Command()
+}
+
+func main(config *gateway.Config) error {
+	log.Info().
+		Str("version", shversion.Version()).
+		Msg("starting gateway")
+	g, err := gateway.New(config)
+	if err != nil {
+		return errors.Wrapf(err, "failed to instantiate gateway")
+	}
+	return service.RunWithSighandler(context.Background(), g)
+}
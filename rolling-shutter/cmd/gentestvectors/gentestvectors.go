@@ -0,0 +1,66 @@
+package gentestvectors
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testvectors"
+)
+
+var (
+	seedFlag       int64
+	numKeypersFlag uint64
+	thresholdFlag  uint64
+	numEpochsFlag  int
+	outputFlag     string
+)
+
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gen-testvectors",
+		Short: "Generate deterministic test vectors for the encryption scheme",
+		Long: `This command generates test vectors for a single eon from a fixed seed: the eon
+key, the key shares and signing keys of the keypers, their signatures of the eon
+public key message, and for each epoch the epoch secret key shares, the epoch
+secret key and an encrypted message. The same arguments always produce the same
+output, so the JSON can be used as fixture by other implementations.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return genTestVectors()
+		},
+	}
+
+	cmd.PersistentFlags().Int64Var(&seedFlag, "seed", 1, "seed the test vectors are derived from")
+	cmd.PersistentFlags().Uint64Var(&numKeypersFlag, "keypers", 3, "number of keypers")
+	cmd.PersistentFlags().Uint64Var(&thresholdFlag, "threshold", 2, "number of keypers needed to compute an epoch secret key")
+	cmd.PersistentFlags().IntVar(&numEpochsFlag, "epochs", 6, "number of epochs")
+	cmd.PersistentFlags().StringVar(&outputFlag, "output", "", "file to write the test vectors to instead of stdout")
+
+	return cmd
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func genTestVectors() error {
+	vectors, err := testvectors.Generate(seedFlag, numKeypersFlag, thresholdFlag, numEpochsFlag)
+	if err != nil {
+		return err
+	}
+	if outputFlag == "" {
+		return writeJSON(os.Stdout, vectors)
+	}
+	f, err := os.Create(outputFlag)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", outputFlag)
+	}
+	defer f.Close()
+	return writeJSON(f, vectors)
+}
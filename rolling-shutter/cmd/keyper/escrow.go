@@ -0,0 +1,126 @@
+package keyper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/escrow"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+var escrowFlags struct {
+	backup      string
+	recoveryKey string
+	shares      []string
+	output      string
+}
+
+func escrowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "escrow",
+		Short: "Recover key shares from encrypted backups",
+		Long: `The keyper writes a backup of its key share of every eon to the directory
+configured in the Escrow section. The backups are encrypted such that a threshold
+of the holders of the configured recovery keys are needed to decrypt them.
+
+To recover a key share, each recovery key holder decrypts their share of the
+backup with decrypt-share. Once enough shares have been collected, recover
+decrypts the backup. The recovered key share can be restored with the
+repair-from-backup command of the keyper.`,
+	}
+	cmd.AddCommand(escrowDecryptShareCmd())
+	cmd.AddCommand(escrowRecoverCmd())
+	return cmd
+}
+
+func escrowDecryptShareCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "decrypt-share",
+		Short: "Decrypt the share of a backup that is encrypted to a recovery key",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return escrowDecryptShare()
+		},
+	}
+	cmd.Flags().StringVar(&escrowFlags.backup, "backup", "", "backup file")
+	cmd.Flags().StringVar(&escrowFlags.recoveryKey, "recovery-key", "", "private recovery key (hex encoded)")
+	_ = cmd.MarkFlagRequired("backup")
+	_ = cmd.MarkFlagRequired("recovery-key")
+	return cmd
+}
+
+func escrowRecoverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recover",
+		Short: "Recover the key share from a backup and decrypted shares",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return escrowRecover()
+		},
+	}
+	cmd.Flags().StringVar(&escrowFlags.backup, "backup", "", "backup file")
+	cmd.Flags().StringArrayVar(&escrowFlags.shares, "share", nil,
+		"file containing a share written by decrypt-share (can be given multiple times)")
+	cmd.Flags().StringVar(&escrowFlags.output, "output", "", "file to write the recovered DKG result to")
+	for _, name := range []string{"backup", "share", "output"} {
+		_ = cmd.MarkFlagRequired(name)
+	}
+	return cmd
+}
+
+func escrowDecryptShare() error {
+	blob, err := escrow.ReadFile(escrowFlags.backup)
+	if err != nil {
+		return err
+	}
+	privateKey, err := ethcrypto.HexToECDSA(strings.TrimPrefix(escrowFlags.recoveryKey, "0x"))
+	if err != nil {
+		return errors.Wrap(err, "invalid recovery key")
+	}
+	share, err := blob.DecryptShare(ecies.ImportECDSA(privateKey))
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(share)
+}
+
+func escrowRecover() error {
+	blob, err := escrow.ReadFile(escrowFlags.backup)
+	if err != nil {
+		return err
+	}
+	shares := []escrow.Share{}
+	for _, path := range escrowFlags.shares {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read share %s", path)
+		}
+		var share escrow.Share
+		if err := json.Unmarshal(data, &share); err != nil {
+			return errors.Wrapf(err, "failed to parse share %s", path)
+		}
+		shares = append(shares, share)
+	}
+	pureResult, err := blob.Recover(shares)
+	if err != nil {
+		return err
+	}
+	result, err := shdb.DecodePureDKGResult(pureResult)
+	if err != nil {
+		return errors.Wrap(err, "recovered data is not a DKG result")
+	}
+	if err := os.WriteFile(escrowFlags.output, pureResult, 0o600); err != nil {
+		return errors.Wrap(err, "failed to write DKG result")
+	}
+	fmt.Printf("recovered key share of keyper %d for eon %d\n", result.Keyper, result.Eon)
+	return nil
+}
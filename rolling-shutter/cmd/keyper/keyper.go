@@ -2,21 +2,42 @@ package keyper
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/shversion"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/auditdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/jobdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/metadb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/metricsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/migration"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/provenancedb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/checkpoint"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/quorum"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/shareintegrity"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration/command"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p/provenance"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
 
+var (
+	options   keyper.Options
+	approvals []string
+)
+
 func Cmd() *cobra.Command {
 	builder := command.Build(
 		main,
@@ -28,7 +49,27 @@ Shuttermint node which have to be started separately in advance.`,
 		command.WithGenerateConfigSubcommand(),
 	)
 	builder.AddInitDBCommand(initDB)
-	return builder.Command()
+	builder.AddMigrateCommand(migrate)
+	builder.AddReplicationCommand(replication)
+	builder.AddAuditLogCommand(auditLog)
+	builder.AddProvenanceCommand(provenanceRecords)
+	builder.AddQuorumStatusCommand(quorumStatus)
+	builder.AddMetricsSnapshotsCommand(metricsSnapshots)
+	builder.AddDeadJobsCommand(deadJobs)
+	builder.AddEventsCommand(deadEvents, retryEvents)
+	builder.AddRepairFromBackupCommand(repairFromBackup)
+	builder.AddRestoreCheckpointCommand(restoreCheckpoint)
+	cmd := builder.Command()
+	cmd.Flags().BoolVar(&options.StealLease, "steal-lease", false,
+		"take over the database from another keyper process using it")
+	cmd.Flags().StringSliceVar(&approvals, "approval", nil,
+		"operator signature approving the actions of this run, as printed by sign-action (repeatable)")
+	cmd.Flags().Int64Var(&options.ApprovalNotAfter, "approval-not-after", 0,
+		"unix timestamp until which the approvals are valid")
+	cmd.AddCommand(signRotationCmd())
+	cmd.AddCommand(signActionCmd())
+	cmd.AddCommand(escrowCmd())
+	return cmd
 }
 
 func main(config *keyper.Config) error {
@@ -38,7 +79,10 @@ func main(config *keyper.Config) error {
 		Str("shuttermint", config.Shuttermint.ShuttermintURL).
 		Msg("starting keyper")
 
-	return service.RunWithSighandler(context.Background(), keyper.New(config))
+	if err := options.AddApprovals(approvals); err != nil {
+		return err
+	}
+	return service.RunWithSighandler(context.Background(), keyper.New(config, options))
 }
 
 func initDB(config *keyper.Config) error {
@@ -66,3 +110,451 @@ func initDB(config *keyper.Config) error {
 	shdb.AddConnectionInfo(log.Info(), dbpool).Msg("database initialized")
 	return nil
 }
+
+func migrate(config *keyper.Config, name, step string, batchSize int64) error {
+	ctx := context.Background()
+
+	m, ok := kprdb.Migrations[name]
+	if !ok {
+		return errors.Errorf("unknown migration %s", name)
+	}
+	dbpool, err := pgxpool.Connect(ctx, config.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		return err
+	}
+	switch step {
+	case "expand":
+		return m.RunExpand(ctx, dbpool)
+	case "backfill":
+		return m.RunBackfill(ctx, dbpool, batchSize)
+	case "switch":
+		return m.RunSwitch(ctx, dbpool)
+	case "contract":
+		return m.RunContract(ctx, dbpool)
+	case "status":
+		phase, err := m.Phase(ctx, dbpool)
+		if err != nil {
+			return err
+		}
+		if phase == migration.PhaseNone {
+			phase = "not started"
+		}
+		log.Info().Str("migration", name).Str("phase", string(phase)).Msg("migration status")
+		return nil
+	default:
+		return errors.Errorf("unknown migration step %s", step)
+	}
+}
+
+func replication(config *keyper.Config, action string) error {
+	ctx := context.Background()
+
+	dbpool, err := pgxpool.Connect(ctx, config.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		return err
+	}
+	switch action {
+	case "setup":
+		return kprdb.Publication.Setup(ctx, dbpool)
+	case "drop":
+		return kprdb.Publication.Drop(ctx, dbpool)
+	case "status":
+		status, err := kprdb.Publication.Status(ctx, dbpool)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(status); err != nil {
+			return err
+		}
+		if !status.OK() {
+			return errors.Errorf("publication %s is not fully set up", kprdb.Publication.Name)
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown replication action %s", action)
+	}
+}
+
+type auditLogEntry struct {
+	ID          int64     `json:"id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Source      string    `json:"source"`
+	Actor       string    `json:"actor"`
+	Action      string    `json:"action"`
+	Details     string    `json:"details"`
+	TriggerHash string    `json:"triggerHash"`
+}
+
+func auditLog(config *keyper.Config, query command.AuditLogQuery) error {
+	ctx := context.Background()
+
+	dbpool, err := pgxpool.Connect(ctx, config.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		return err
+	}
+	entries, err := auditdb.New(dbpool).FindAuditLogEntries(ctx, auditdb.FindAuditLogEntriesParams{
+		Since:       query.Since,
+		Until:       query.Until,
+		Source:      query.Source,
+		Actor:       query.Actor,
+		TriggerHash: query.TriggerHash,
+		MaxEntries:  query.Limit,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to query audit log")
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	for _, e := range entries {
+		err := encoder.Encode(auditLogEntry{
+			ID:          e.ID,
+			CreatedAt:   e.CreatedAt,
+			Source:      e.Source,
+			Actor:       e.Actor,
+			Action:      e.Action,
+			Details:     e.Details,
+			TriggerHash: hexutil.Encode(e.TriggerHash),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func provenanceRecords(config *keyper.Config, query command.ProvenanceQuery) error {
+	ctx := context.Background()
+
+	dbpool, err := pgxpool.Connect(ctx, config.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		return err
+	}
+	records, err := provenancedb.New(dbpool).FindMessageProvenance(ctx, provenancedb.FindMessageProvenanceParams{
+		Since:       query.Since,
+		Until:       query.Until,
+		Peer:        query.Peer,
+		MessageType: query.MessageType,
+		EpochID:     query.EpochID,
+		MessageHash: query.MessageHash,
+		MaxEntries:  query.Limit,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to query message provenance")
+	}
+	return provenance.WriteJSON(os.Stdout, records)
+}
+
+func quorumStatus(config *keyper.Config) error {
+	ctx := context.Background()
+
+	if config.QuorumWindow == 0 {
+		return errors.New("quorum health tracking is disabled, set QuorumWindow to enable it")
+	}
+	dbpool, err := pgxpool.Connect(ctx, config.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		return err
+	}
+	status, err := quorum.GetStatus(ctx, dbpool, int32(config.QuorumWindow))
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(status)
+}
+
+type metricsSnapshot struct {
+	TakenAt time.Time          `json:"takenAt"`
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+func metricsSnapshots(config *keyper.Config, since time.Time) error {
+	ctx := context.Background()
+
+	dbpool, err := pgxpool.Connect(ctx, config.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		return err
+	}
+	rows, err := metricsdb.New(dbpool).FindMetricsSnapshots(ctx, since)
+	if err != nil {
+		return errors.Wrap(err, "failed to query metrics snapshots")
+	}
+
+	// rows are ordered by time, so the values of a snapshot are adjacent
+	encoder := json.NewEncoder(os.Stdout)
+	var snapshot *metricsSnapshot
+	for _, row := range rows {
+		if snapshot != nil && !snapshot.TakenAt.Equal(row.TakenAt) {
+			if err := encoder.Encode(snapshot); err != nil {
+				return err
+			}
+			snapshot = nil
+		}
+		if snapshot == nil {
+			snapshot = &metricsSnapshot{TakenAt: row.TakenAt, Metrics: map[string]float64{}}
+		}
+		snapshot.Metrics[row.Name] = row.Value
+	}
+	if snapshot != nil {
+		return encoder.Encode(snapshot)
+	}
+	return nil
+}
+
+type deadJob struct {
+	ID        int64           `json:"id"`
+	Kind      string          `json:"kind"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int32           `json:"attempts"`
+	LastError string          `json:"lastError"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+func deadJobs(config *keyper.Config, retry []int64) error {
+	ctx := context.Background()
+
+	dbpool, err := pgxpool.Connect(ctx, config.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		return err
+	}
+	db := jobdb.New(dbpool)
+	for _, id := range retry {
+		n, err := db.RetryDeadJob(ctx, id)
+		if err != nil {
+			return errors.Wrapf(err, "failed to retry job %d", id)
+		}
+		if n == 0 {
+			return errors.Errorf("job %d is not a dead job", id)
+		}
+		log.Info().Int64("job-id", id).Msg("scheduled dead job for retry")
+	}
+	jobs, err := db.GetDeadJobs(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to query dead jobs")
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	for _, j := range jobs {
+		err := encoder.Encode(deadJob{
+			ID:        j.ID,
+			Kind:      j.Kind,
+			Payload:   j.Payload,
+			Attempts:  j.Attempts,
+			LastError: j.LastError,
+			CreatedAt: j.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type deadEvent struct {
+	ID          int64           `json:"id"`
+	EventType   string          `json:"eventType"`
+	BlockNumber int64           `json:"blockNumber"`
+	LogIndex    int64           `json:"logIndex"`
+	TxHash      string          `json:"txHash"`
+	Log         json.RawMessage `json:"log"`
+	Attempts    int32           `json:"attempts"`
+	LastError   string          `json:"lastError"`
+	Retry       bool            `json:"retry"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+func deadEvents(config *keyper.Config) error {
+	ctx := context.Background()
+
+	dbpool, err := pgxpool.Connect(ctx, config.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		return err
+	}
+	events, err := chainobsdb.New(dbpool).GetDeadEvents(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to query dead events")
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	for _, e := range events {
+		err := encoder.Encode(deadEvent{
+			ID:          e.ID,
+			EventType:   e.EventType,
+			BlockNumber: e.BlockNumber,
+			LogIndex:    e.LogIndex,
+			TxHash:      hexutil.Encode(e.TxHash),
+			Log:         e.Log,
+			Attempts:    e.Attempts,
+			LastError:   e.LastError,
+			Retry:       e.Retry,
+			CreatedAt:   e.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func retryEvents(config *keyper.Config, ids []int64) error {
+	ctx := context.Background()
+
+	dbpool, err := pgxpool.Connect(ctx, config.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		return err
+	}
+	db := chainobsdb.New(dbpool)
+	for _, id := range ids {
+		n, err := db.RetryDeadEvent(ctx, id)
+		if err != nil {
+			return errors.Wrapf(err, "failed to retry dead event %d", id)
+		}
+		if n == 0 {
+			return errors.Errorf("event %d is not a dead event", id)
+		}
+		log.Info().Int64("dead-event-id", id).Msg("scheduled dead event for retry")
+	}
+	return nil
+}
+
+func repairFromBackup(config *keyper.Config, resultFiles []string) error {
+	ctx := context.Background()
+
+	dbpool, err := pgxpool.Connect(ctx, config.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		return err
+	}
+	for _, path := range resultFiles {
+		pureResult, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read DKG result %s", path)
+		}
+		eon, err := shareintegrity.Repair(ctx, dbpool, pureResult)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to repair from %s", path)
+		}
+		log.Info().Uint64("eon", eon).Str("path", path).Msg("restored key share from backup")
+	}
+	corruptions, err := shareintegrity.Find(ctx, dbpool)
+	if err != nil {
+		return err
+	}
+	for _, c := range corruptions {
+		log.Warn().Int64("eon", c.Eon).Str("reason", c.Reason).Msg("eon key share is still corrupted")
+	}
+	if len(corruptions) > 0 {
+		return errors.Errorf("key shares of %d eons are still corrupted", len(corruptions))
+	}
+	return nil
+}
+
+func restoreCheckpoint(
+	config *keyper.Config, sources, signers []string, minSigners int, resultFiles []string,
+) error {
+	ctx := context.Background()
+
+	trusted := []common.Address{}
+	for _, s := range signers {
+		if !common.IsHexAddress(s) {
+			return errors.Errorf("invalid signer address %q", s)
+		}
+		trusted = append(trusted, common.HexToAddress(s))
+	}
+	checkpoints := []*checkpoint.Checkpoint{}
+	for _, source := range sources {
+		cp, err := checkpoint.Fetch(ctx, source)
+		if err != nil {
+			log.Warn().Err(err).Str("source", source).Msg("failed to fetch checkpoint")
+			continue
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	body, agreeing, err := checkpoint.Agree(checkpoints, config.InstanceID, trusted, minSigners)
+	if err != nil {
+		return err
+	}
+	pureResults := [][]byte{}
+	for _, path := range resultFiles {
+		pureResult, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read DKG result %s", path)
+		}
+		pureResults = append(pureResults, pureResult)
+	}
+
+	dbpool, err := pgxpool.Connect(ctx, config.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		return err
+	}
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return checkpoint.Restore(ctx, tx, body, pureResults)
+	})
+	if err != nil {
+		return err
+	}
+	log.Info().
+		Int("signers", len(agreeing)).
+		Int("eons", len(body.State.Eons)).
+		Int("dkg-results", len(pureResults)).
+		Int64("next-block-number", body.Cursor.NextBlockNumber).
+		Int64("shuttermint-height", body.Cursor.ShuttermintHeight).
+		Msg("restored checkpoint")
+	return nil
+}
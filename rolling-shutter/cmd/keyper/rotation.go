@@ -0,0 +1,110 @@
+package keyper
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/chainobserver"
+)
+
+var rotationFlags struct {
+	privateKey        string
+	chainID           uint64
+	contract          string
+	keyperConfigIndex uint64
+	keyperIndex       uint64
+	oldAddress        string
+	newAddress        string
+}
+
+type signedRotation struct {
+	Hash      hexutil.Bytes  `json:"hash"`
+	Signer    common.Address `json:"signer"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+func signRotationCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign-rotation",
+		Short: "Sign the rotation of a keyper address",
+		Long: `This command signs the rotation of a keyper to a new address, as expected by the
+KeyperRotations contract. Both the old and the new address have to sign the
+rotation, so the command has to be run once with each private key. The keyper
+keeps its index in the keyper set and thereby its eon key share.
+
+The signatures are printed as JSON and passed to KeyperRotations.rotate by the
+owner of the contract.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return signRotation()
+		},
+	}
+	cmd.Flags().StringVar(&rotationFlags.privateKey, "private-key", "",
+		"private key of the old or new address (hex encoded)")
+	cmd.Flags().Uint64Var(&rotationFlags.chainID, "chain-id", 0,
+		"id of the chain the KeyperRotations contract is deployed on")
+	cmd.Flags().StringVar(&rotationFlags.contract, "contract", "", "address of the KeyperRotations contract")
+	cmd.Flags().Uint64Var(&rotationFlags.keyperConfigIndex, "keyper-config-index", 0, "index of the keyper config")
+	cmd.Flags().Uint64Var(&rotationFlags.keyperIndex, "keyper-index", 0, "index of the keyper in the keyper set")
+	cmd.Flags().StringVar(&rotationFlags.oldAddress, "old-address", "", "current address of the keyper")
+	cmd.Flags().StringVar(&rotationFlags.newAddress, "new-address", "", "new address of the keyper")
+	for _, name := range []string{"private-key", "chain-id", "contract", "keyper-config-index", "keyper-index", "old-address", "new-address"} {
+		_ = cmd.MarkFlagRequired(name)
+	}
+	return cmd
+}
+
+func parseAddressFlag(name, value string) (common.Address, error) {
+	if !common.IsHexAddress(value) {
+		return common.Address{}, errors.Errorf("invalid %s %q", name, value)
+	}
+	return common.HexToAddress(value), nil
+}
+
+func signRotation() error {
+	privateKey, err := ethcrypto.HexToECDSA(strings.TrimPrefix(rotationFlags.privateKey, "0x"))
+	if err != nil {
+		return errors.Wrap(err, "invalid private key")
+	}
+	contractAddress, err := parseAddressFlag("contract address", rotationFlags.contract)
+	if err != nil {
+		return err
+	}
+	rotation := chainobserver.KeyperRotation{
+		KeyperConfigIndex: rotationFlags.keyperConfigIndex,
+		KeyperIndex:       rotationFlags.keyperIndex,
+	}
+	rotation.OldAddress, err = parseAddressFlag("old address", rotationFlags.oldAddress)
+	if err != nil {
+		return err
+	}
+	rotation.NewAddress, err = parseAddressFlag("new address", rotationFlags.newAddress)
+	if err != nil {
+		return err
+	}
+	signer := ethcrypto.PubkeyToAddress(privateKey.PublicKey)
+	if signer != rotation.OldAddress && signer != rotation.NewAddress {
+		return errors.Errorf("private key of %s matches neither the old nor the new address", signer.Hex())
+	}
+
+	hash := chainobserver.RotationHash(new(big.Int).SetUint64(rotationFlags.chainID), contractAddress, rotation)
+	signature, err := chainobserver.SignRotation(hash, privateKey)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(signedRotation{
+		Hash:      hash,
+		Signer:    signer,
+		Signature: signature,
+	})
+}
@@ -0,0 +1,132 @@
+package keyper
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/opapproval"
+)
+
+var actionFlags struct {
+	privateKey string
+	name       string
+	instanceID uint64
+	node       string
+	validFor   time.Duration
+	notAfter   int64
+	httpMethod string
+	httpPath   string
+	httpBody   string
+}
+
+type signedAction struct {
+	Action    *opapproval.Action `json:"action"`
+	Hash      hexutil.Bytes      `json:"hash"`
+	Signer    common.Address     `json:"signer"`
+	Signature hexutil.Bytes      `json:"signature"`
+}
+
+func signActionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign-action",
+		Short: "Approve a sensitive action of a keyper",
+		Long: `This command signs the approval of a sensitive action of a keyper with the key of
+one of the operators listed in the OperatorApproval section of its config. The
+keyper only executes the action once it has received enough approvals.
+
+To approve stealing the lease, pass the signatures to the keyper with --approval
+and the expiry time with --approval-not-after. To approve a request to the HTTP
+API, specify it with --http-method, --http-path and --http-body-file and send the
+signatures in Shutter-Approval headers and the expiry time in the
+Shutter-Approval-Not-After header.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return signAction()
+		},
+	}
+	cmd.Flags().StringVar(&actionFlags.privateKey, "private-key", "", "private key of the operator (hex encoded)")
+	cmd.Flags().StringVar(&actionFlags.name, "name", "",
+		"name of the action (steal-lease, override-feature, set-log-filter, pause-key-generation or rebroadcast-keys)")
+	cmd.Flags().Uint64Var(&actionFlags.instanceID, "instance-id", 0, "instance id of the keyper")
+	cmd.Flags().StringVar(&actionFlags.node, "node", "", "ethereum address of the keyper")
+	cmd.Flags().DurationVar(&actionFlags.validFor, "valid-for", 10*time.Minute,
+		"how long the approval is valid, ignored if --not-after is given")
+	cmd.Flags().Int64Var(&actionFlags.notAfter, "not-after", 0, "unix timestamp until which the approval is valid")
+	cmd.Flags().StringVar(&actionFlags.httpMethod, "http-method", "", "method of the approved HTTP request")
+	cmd.Flags().StringVar(&actionFlags.httpPath, "http-path", "", "path of the approved HTTP request")
+	cmd.Flags().StringVar(&actionFlags.httpBody, "http-body-file", "",
+		"file containing the body of the approved HTTP request")
+	for _, name := range []string{"private-key", "name", "instance-id", "node"} {
+		_ = cmd.MarkFlagRequired(name)
+	}
+	return cmd
+}
+
+func signAction() error {
+	privateKey, err := ethcrypto.HexToECDSA(strings.TrimPrefix(actionFlags.privateKey, "0x"))
+	if err != nil {
+		return errors.Wrap(err, "invalid private key")
+	}
+	node, err := parseAddressFlag("node address", actionFlags.node)
+	if err != nil {
+		return err
+	}
+	notAfter := actionFlags.notAfter
+	if notAfter == 0 {
+		notAfter = time.Now().Add(actionFlags.validFor).Unix()
+	}
+
+	var action *opapproval.Action
+	switch actionFlags.name {
+	case opapproval.ActionStealLease:
+		action = &opapproval.Action{
+			Name:       actionFlags.name,
+			InstanceID: actionFlags.instanceID,
+			Node:       node,
+			NotAfter:   notAfter,
+		}
+	case opapproval.ActionOverrideFeature, opapproval.ActionSetLogFilter:
+		if actionFlags.httpMethod == "" || actionFlags.httpPath == "" {
+			return errors.Errorf("action %s requires --http-method and --http-path", actionFlags.name)
+		}
+		var body []byte
+		if actionFlags.httpBody != "" {
+			body, err = os.ReadFile(actionFlags.httpBody)
+			if err != nil {
+				return errors.Wrap(err, "failed to read request body")
+			}
+		}
+		action = opapproval.HTTPAction(
+			actionFlags.name,
+			actionFlags.instanceID,
+			node,
+			strings.ToUpper(actionFlags.httpMethod),
+			actionFlags.httpPath,
+			body,
+			notAfter,
+		)
+	default:
+		return errors.Errorf("unknown action %q", actionFlags.name)
+	}
+
+	signature, err := opapproval.Sign(action, privateKey)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(signedAction{
+		Action:    action,
+		Hash:      action.Hash(),
+		Signer:    ethcrypto.PubkeyToAddress(privateKey.PublicKey),
+		Signature: signature,
+	})
+}
@@ -0,0 +1,45 @@
+package loadgen
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/loadgen"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration/command"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+)
+
+func Cmd() *cobra.Command {
+	builder := command.Build(
+		main,
+		command.Usage(
+			"Generate load on a collator and measure decryption latency",
+			`This command submits valid encrypted transactions to a collator at a fixed rate
+for the configured duration. It listens for the decryption keys the keypers
+release on the p2p network, checks that each transaction can be decrypted and
+measures the time from submission until the key arrived. Transactions whose key
+doesn't arrive within the timeout are counted as lost. When the run is over or
+interrupted, a summary report is written to stdout as JSON.`,
+		),
+		command.WithGenerateConfigSubcommand(),
+	)
+	return builder.Command()
+}
+
+func main(cfg *loadgen.Config) error {
+	g, err := loadgen.New(cfg)
+	if err != nil {
+		return err
+	}
+	err = service.RunWithSighandler(context.Background(), g)
+	if err != nil && !errors.Is(err, loadgen.ErrDone) && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g.Report())
+}
@@ -0,0 +1,39 @@
+package mockkeypers
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration/command"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/mockkeypers"
+)
+
+var options mockkeypers.Options
+
+func Cmd() *cobra.Command {
+	builder := command.Build(
+		main,
+		command.Usage(
+			"Run a swarm of mock keypers for load testing",
+			`This command runs many lightweight keyper simulators in a single process. Each
+of them joins the p2p network with its own key and listen port and sends valid
+decryption key shares of a common test eon key, either for consecutive epochs
+at a fixed rate or in response to decryption triggers. The eon public key is
+logged at startup.`,
+		),
+		command.WithGenerateConfigSubcommand(),
+	)
+	cmd := builder.Command()
+	cmd.Flags().Uint64Var(&options.Count, "count", 0, "number of keypers, overrides Count of the config")
+	return cmd
+}
+
+func main(cfg *mockkeypers.Config) error {
+	swarm, err := mockkeypers.New(cfg, options)
+	if err != nil {
+		return err
+	}
+	return service.RunWithSighandler(context.Background(), swarm)
+}
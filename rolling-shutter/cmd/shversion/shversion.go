@@ -10,8 +10,9 @@ import (
 
 var version string
 
-// Version returns shuttermint's version string.
-func Version() string {
+// ReleaseVersion returns the version of the shuttermint module without any build information, e.g.
+// "v1.2.3". Development builds return "(devel)" or "(devel-<revision>)".
+func ReleaseVersion() string {
 	if version == "" {
 		info, ok := debug.ReadBuildInfo()
 		if ok {
@@ -26,10 +27,14 @@ func Version() string {
 			}
 		}
 	}
+	return version
+}
 
+// Version returns shuttermint's version string.
+func Version() string {
 	var raceinfo string
 	if raceDetectorEnabled {
 		raceinfo = ", race detector enabled"
 	}
-	return fmt.Sprintf("%s (%s, %s-%s%s)", version, runtime.Version(), runtime.GOOS, runtime.GOARCH, raceinfo)
+	return fmt.Sprintf("%s (%s, %s-%s%s)", ReleaseVersion(), runtime.Version(), runtime.GOOS, runtime.GOARCH, raceinfo)
 }
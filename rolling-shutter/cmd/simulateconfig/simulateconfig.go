@@ -0,0 +1,84 @@
+package simulateconfig
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/configsim"
+)
+
+var (
+	databaseURLFlag     string
+	keypersFlag         string
+	thresholdFlag       uint64
+	activationBlockFlag uint64
+)
+
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "simulate-config",
+		Short: "Simulate the activation of a proposed keyper set",
+		Long: `This command loads the state of a keyper database and simulates activating the
+proposed keyper set, without changing anything. It reports which keypers have
+to take part in the DKG, the fault tolerance and collusion resistance of the
+proposed threshold and conflicts with pending eons and keyper configs.
+
+The keypers file contains a JSON list of keyper addresses, or an object with
+the fields "keypers", "threshold" and "activationBlockNumber". Flags override
+the values from the file.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return simulateConfig(cmd.Context())
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&databaseURLFlag, "database-url", "", "URL of the keyper database")
+	cmd.PersistentFlags().StringVar(&keypersFlag, "keypers", "", "JSON file containing the proposed keyper set")
+	cmd.PersistentFlags().Uint64Var(&thresholdFlag, "threshold", 0, "threshold of the proposed keyper set")
+	cmd.PersistentFlags().Uint64Var(&activationBlockFlag, "activation-block", 0, "activation block number of the proposed keyper set")
+
+	cmd.MarkPersistentFlagRequired("database-url")
+	cmd.MarkPersistentFlagRequired("keypers")
+
+	return cmd
+}
+
+func simulateConfig(ctx context.Context) error {
+	proposal, err := configsim.ReadProposal(keypersFlag)
+	if err != nil {
+		return err
+	}
+	if thresholdFlag != 0 {
+		proposal.Threshold = thresholdFlag
+	}
+	if activationBlockFlag != 0 {
+		proposal.ActivationBlockNumber = activationBlockFlag
+	}
+
+	dbpool, err := pgxpool.Connect(ctx, databaseURLFlag)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		return err
+	}
+
+	state, err := configsim.LoadState(ctx, kprdb.New(dbpool))
+	if err != nil {
+		return err
+	}
+	report, err := configsim.Simulate(state, proposal)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
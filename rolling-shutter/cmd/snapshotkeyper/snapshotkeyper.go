@@ -18,6 +18,11 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/snapshotkeyper"
 )
 
+var (
+	options   keyper.Options
+	approvals []string
+)
+
 func Cmd() *cobra.Command {
 	builder := command.Build(
 		main,
@@ -31,7 +36,14 @@ Shuttermint node which have to be started separately in advance.`,
 		command.WithDumpConfigSubcommand(),
 	)
 	builder.AddInitDBCommand(initDB)
-	return builder.Command()
+	cmd := builder.Command()
+	cmd.Flags().BoolVar(&options.StealLease, "steal-lease", false,
+		"take over the database from another keyper process using it")
+	cmd.Flags().StringSliceVar(&approvals, "approval", nil,
+		"operator signature approving the actions of this run, as printed by keyper sign-action (repeatable)")
+	cmd.Flags().Int64Var(&options.ApprovalNotAfter, "approval-not-after", 0,
+		"unix timestamp until which the approvals are valid")
+	return cmd
 }
 
 func main(config *keyper.Config) error {
@@ -41,7 +53,10 @@ func main(config *keyper.Config) error {
 		Str("shuttermint", config.Shuttermint.ShuttermintURL).
 		Msg("starting snapshotkeyper")
 
-	return service.RunWithSighandler(context.Background(), snapshotkeyper.New(config))
+	if err := options.AddApprovals(approvals); err != nil {
+		return err
+	}
+	return service.RunWithSighandler(context.Background(), snapshotkeyper.New(config, options))
 }
 
 func initDB(config *keyper.Config) error {
@@ -0,0 +1,303 @@
+package top
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprapi"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/tlsconfig"
+)
+
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+
+	// maxErrors is the number of recent errors shown.
+	maxErrors = 8
+)
+
+// Counters scraped from the metrics endpoint whose rates are shown.
+const (
+	metricSharesSent     = "shutter_epochkg_decryption_keyshares_sent_total"
+	metricSharesReceived = "shutter_epochkg_decryption_keyshares_received_total"
+	metricKeysGenerated  = "shutter_epochkg_decryption_keys_generated_total"
+)
+
+var counterNames = []string{metricSharesSent, metricSharesReceived, metricKeysGenerated}
+
+var (
+	urlFlag      string
+	tokenFlag    string
+	intervalFlag time.Duration
+	tlsFlags     = &tlsconfig.ClientConfig{}
+)
+
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "Show a live dashboard of a running keyper",
+		Long: `This command shows how far a running keyper lags behind the chain, its current
+eon and epoch, the rate of decryption key shares it sends and receives, the number
+of connected peers, the latency of writes to its database and its most recent
+errors. It polls /status and /metrics on the keyper's HTTP API, which requires
+read access, and refreshes the screen every interval until interrupted.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return top(cmd.Context())
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&urlFlag, "url", "u", "", "URL of the keyper's HTTP API")
+	cmd.PersistentFlags().StringVarP(&tokenFlag, "token", "t", "", "bearer token granting read access")
+	cmd.PersistentFlags().DurationVarP(&intervalFlag, "interval", "i", 2*time.Second, "refresh interval")
+	cmd.PersistentFlags().StringVar(&tlsFlags.CAFile, "ca-file", "", "PEM encoded CA certificates used to verify the node")
+	cmd.PersistentFlags().StringVar(&tlsFlags.CertFile, "cert-file", "", "PEM encoded client certificate for mTLS")
+	cmd.PersistentFlags().StringVar(&tlsFlags.KeyFile, "key-file", "", "PEM encoded client private key for mTLS")
+
+	cmd.MarkPersistentFlagRequired("url")
+
+	return cmd
+}
+
+// sample is the state of the keyper at one point in time.
+type sample struct {
+	at       time.Time
+	status   kprapi.Status
+	counters map[string]float64
+}
+
+func top(ctx context.Context) error {
+	if err := tlsFlags.Validate(); err != nil {
+		return err
+	}
+	if intervalFlag <= 0 {
+		return errors.New("interval must be positive")
+	}
+	client, err := tlsFlags.HTTPClient()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Print(hideCursor)
+	defer fmt.Print(showCursor)
+
+	ticker := time.NewTicker(intervalFlag)
+	defer ticker.Stop()
+	var prev, cur *sample
+	for {
+		next, err := fetch(ctx, client)
+		if err == nil {
+			prev, cur = cur, next
+		}
+		buf := &bytes.Buffer{}
+		buf.WriteString(clearScreen)
+		render(buf, prev, cur, err)
+		_, _ = os.Stdout.Write(buf.Bytes())
+
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func fetch(ctx context.Context, client *http.Client) (*sample, error) {
+	s := &sample{at: time.Now()}
+	body, err := get(ctx, client, "/status")
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &s.status); err != nil {
+		return nil, errors.Wrap(err, "failed to decode status")
+	}
+	body, err = get(ctx, client, "/metrics")
+	if err != nil {
+		return nil, err
+	}
+	s.counters, err = parseCounters(bytes.NewReader(body), counterNames)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse metrics")
+	}
+	return s, nil
+}
+
+func get(ctx context.Context, client *http.Client, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(urlFlag, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if tokenFlag != "" {
+		req.Header.Set("Authorization", "Bearer "+tokenFlag)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response status %s from %s: %s",
+			resp.Status, path, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// parseCounters sums the values of all series of the given metrics in the Prometheus text format.
+func parseCounters(r io.Reader, names []string) (map[string]float64, error) {
+	counters := make(map[string]float64, len(names))
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := line
+		if i := strings.IndexAny(line, "{ "); i >= 0 {
+			name = line[:i]
+		}
+		if !contains(names, name) {
+			continue
+		}
+		// The value follows the labels, optionally followed by a timestamp.
+		rest := line[len(name):]
+		if strings.HasPrefix(rest, "{") {
+			rest = rest[strings.LastIndex(rest, "}")+1:]
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return nil, errors.Errorf("missing value of metric %s", name)
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of metric %s", name)
+		}
+		counters[name] += value
+	}
+	return counters, scanner.Err()
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// rate returns the per second rate of a counter between two samples, or nil if it's unknown.
+func rate(prev, cur *sample, name string) *float64 {
+	if prev == nil || cur == nil {
+		return nil
+	}
+	elapsed := cur.at.Sub(prev.at).Seconds()
+	delta := cur.counters[name] - prev.counters[name]
+	if elapsed <= 0 || delta < 0 { // the counter was reset by a restart
+		return nil
+	}
+	r := delta / elapsed
+	return &r
+}
+
+func render(w io.Writer, prev, cur *sample, fetchErr error) {
+	now := time.Now().UTC()
+	fmt.Fprintf(w, "rolling-shutter top - %s - %s\n", urlFlag, now.Format("2006-01-02 15:04:05 MST"))
+	if fetchErr != nil {
+		fmt.Fprintf(w, "failed to update: %v\n", fetchErr)
+	}
+	if cur == nil {
+		return
+	}
+	status := cur.status
+	if fetchErr == nil {
+		fmt.Fprintln(w)
+	}
+
+	row := func(label, value string) {
+		fmt.Fprintf(w, "%-20s %s\n", label, value)
+	}
+	metric := func(name, format string, scale float64) string {
+		value, ok := status.Metrics[name]
+		if !ok {
+			return "-"
+		}
+		return fmt.Sprintf(format, value*scale)
+	}
+	counter := func(name string) string {
+		total := fmt.Sprintf("(total %.0f)", cur.counters[name])
+		if r := rate(prev, cur, name); r != nil {
+			return fmt.Sprintf("%8.2f/s  %s", *r, total)
+		}
+		return fmt.Sprintf("%8s/s  %s", "-", total)
+	}
+
+	row("Keyper", status.Keyper.Hex())
+	row("Sync lag", metric(keyper.MetricSyncLag, "%.0f blocks", 1))
+	if status.Eon != nil {
+		row("Eon", strconv.FormatInt(*status.Eon, 10))
+	} else {
+		row("Eon", "-")
+	}
+	if epoch := status.LatestEpoch; epoch != nil {
+		age := status.Time.Sub(epoch.FinalizedAt).Truncate(time.Second)
+		row("Latest epoch", fmt.Sprintf("%s (eon %d, %s ago)", epoch.EpochID, epoch.Eon, age))
+	} else {
+		row("Latest epoch", "-")
+	}
+	row("Pending epochs", metric(keyper.MetricEpochsBehind, "%.0f", 1))
+	row("Peers", metric(keyper.MetricPeers, "%.0f", 1))
+	row("DB write latency", metric(keyper.MetricDBWriteLatency, "%.1f ms", 1000))
+	fmt.Fprintln(w)
+	row("Shares sent", counter(metricSharesSent))
+	row("Shares received", counter(metricSharesReceived))
+	row("Keys generated", counter(metricKeysGenerated))
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "Recent errors")
+	errs := status.RecentErrors
+	if len(errs) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	if len(errs) > maxErrors {
+		errs = errs[len(errs)-maxErrors:]
+	}
+	for i := len(errs) - 1; i >= 0; i-- {
+		e := errs[i]
+		line := fmt.Sprintf("  %s %-5s %s", e.Time.UTC().Format("15:04:05"), e.Level, e.Message)
+		if e.Error != "" {
+			line += ": " + e.Error
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	if len(status.Annotations) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Annotations")
+		for _, a := range status.Annotations {
+			fmt.Fprintln(w, "  "+a.String())
+		}
+	}
+}
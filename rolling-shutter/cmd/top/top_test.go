@@ -0,0 +1,69 @@
+package top
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/annotation"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprapi"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/recentlog"
+)
+
+func TestParseCounters(t *testing.T) {
+	metrics := `# HELP shutter_epochkg_decryption_keyshares_sent_total Number of sent decryption key shares
+# TYPE shutter_epochkg_decryption_keyshares_sent_total counter
+shutter_epochkg_decryption_keyshares_sent_total 12
+shutter_epochkg_decryption_keyshares_received_total{eon="1"} 3
+shutter_epochkg_decryption_keyshares_received_total{eon="2"} 4 1700000000000
+shutter_epochkg_decryption_keyshares_received_total_other 100
+`
+	counters, err := parseCounters(strings.NewReader(metrics), counterNames)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, counters, map[string]float64{
+		metricSharesSent:     12,
+		metricSharesReceived: 7,
+	})
+
+	_, err = parseCounters(strings.NewReader(metricSharesSent+" abc\n"), counterNames)
+	assert.ErrorContains(t, err, "invalid value")
+}
+
+func TestRate(t *testing.T) {
+	now := time.Now()
+	prev := &sample{at: now, counters: map[string]float64{metricSharesSent: 10}}
+	cur := &sample{at: now.Add(2 * time.Second), counters: map[string]float64{metricSharesSent: 14}}
+	assert.Equal(t, *rate(prev, cur, metricSharesSent), 2.0)
+	assert.Assert(t, rate(nil, cur, metricSharesSent) == nil)
+	assert.Assert(t, rate(cur, prev, metricSharesSent) == nil)
+}
+
+func TestRender(t *testing.T) {
+	eon := int64(3)
+	cur := &sample{
+		at: time.Now(),
+		status: kprapi.Status{
+			Time:    time.Now(),
+			Eon:     &eon,
+			Metrics: map[string]float64{keyper.MetricSyncLag: 5, keyper.MetricDBWriteLatency: 0.0042},
+			RecentErrors: []recentlog.Entry{
+				{Time: time.Now(), Level: "error", Message: "failed to send", Error: "timeout"},
+			},
+			Annotations: []annotation.Annotation{
+				{Kind: annotation.KindEon, Entity: "3", Label: "restarted", Author: "alice"},
+			},
+		},
+		counters: map[string]float64{},
+	}
+	buf := &bytes.Buffer{}
+	render(buf, nil, cur, nil)
+	out := buf.String()
+	assert.Assert(t, strings.Contains(out, "5 blocks"))
+	assert.Assert(t, strings.Contains(out, "4.2 ms"))
+	assert.Assert(t, strings.Contains(out, "failed to send: timeout"))
+	assert.Assert(t, strings.Contains(out, "eon 3 [restarted] (alice)"))
+}
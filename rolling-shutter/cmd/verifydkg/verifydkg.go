@@ -0,0 +1,150 @@
+package verifydkg
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/dkgtranscript"
+)
+
+var (
+	databaseURLFlag  string
+	transcriptFlag   string
+	eonFlag          uint64
+	eonPublicKeyFlag string
+	outputFlag       string
+)
+
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-dkg",
+		Short: "Verify the DKG transcript of an eon",
+		Long: `This command replays the public messages of the DKG of an eon and checks that
+the recorded eon public key follows from them. It reports the keypers
+contributing to the eon key, the keypers excluded as corrupt and messages that
+were ignored. No secret of any keyper is needed.
+
+The transcript is either read from a file created by export-dkg or loaded from
+a keyper database. If an eon public key is given, e.g. the one published on
+chain, it is checked as well. The command fails if the transcript is invalid.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return verifyDKG(cmd.Context())
+		},
+	}
+
+	cmd.PersistentFlags().Uint64Var(&eonFlag, "eon", 0, "eon whose DKG to verify")
+	cmd.PersistentFlags().StringVar(&transcriptFlag, "transcript", "", "JSON file containing the DKG transcript")
+	cmd.PersistentFlags().StringVar(&databaseURLFlag, "database-url", "", "URL of the keyper database to load the transcript from")
+	cmd.PersistentFlags().StringVar(&eonPublicKeyFlag, "eon-public-key", "", "expected eon public key (hex encoded)")
+
+	cmd.MarkPersistentFlagRequired("eon")
+
+	return cmd
+}
+
+func ExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-dkg",
+		Short: "Export the DKG transcript of an eon",
+		Long: `This command exports the DKG transcript of an eon from a keyper database as
+JSON, so that it can be verified by third parties with verify-dkg. The
+transcript contains the keyper set, the public DKG messages and the eon public
+key. Poly evals are only included in encrypted form.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportDKG(cmd.Context())
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&databaseURLFlag, "database-url", "", "URL of the keyper database")
+	cmd.PersistentFlags().Uint64Var(&eonFlag, "eon", 0, "eon whose DKG to export")
+	cmd.PersistentFlags().StringVar(&outputFlag, "output", "", "file to write the transcript to instead of stdout")
+
+	cmd.MarkPersistentFlagRequired("database-url")
+	cmd.MarkPersistentFlagRequired("eon")
+
+	return cmd
+}
+
+func loadTranscript(ctx context.Context, eon uint64) (*dkgtranscript.Transcript, error) {
+	dbpool, err := pgxpool.Connect(ctx, databaseURLFlag)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		return nil, err
+	}
+	return dkgtranscript.Load(ctx, kprdb.New(dbpool), eon)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func verifyDKG(ctx context.Context) error {
+	var (
+		transcript *dkgtranscript.Transcript
+		err        error
+	)
+	switch {
+	case transcriptFlag != "" && databaseURLFlag != "":
+		return errors.New("--transcript and --database-url are mutually exclusive")
+	case transcriptFlag != "":
+		transcript, err = dkgtranscript.ReadFile(transcriptFlag)
+	case databaseURLFlag != "":
+		transcript, err = loadTranscript(ctx, eonFlag)
+	default:
+		return errors.New("either --transcript or --database-url is required")
+	}
+	if err != nil {
+		return err
+	}
+	if transcript.Eon != eonFlag {
+		return errors.Errorf("transcript is for eon %d, not %d", transcript.Eon, eonFlag)
+	}
+
+	var expectedEonPublicKey []byte
+	if eonPublicKeyFlag != "" {
+		expectedEonPublicKey, err = hexutil.Decode(eonPublicKeyFlag)
+		if err != nil {
+			return errors.Wrap(err, "invalid eon public key")
+		}
+	}
+
+	verdict := dkgtranscript.Verify(transcript, expectedEonPublicKey)
+	if err := writeJSON(os.Stdout, verdict); err != nil {
+		return err
+	}
+	if !verdict.Valid {
+		return errors.Errorf("DKG transcript of eon %d is invalid", eonFlag)
+	}
+	return nil
+}
+
+func exportDKG(ctx context.Context) error {
+	transcript, err := loadTranscript(ctx, eonFlag)
+	if err != nil {
+		return err
+	}
+	if outputFlag == "" {
+		return writeJSON(os.Stdout, transcript)
+	}
+	f, err := os.Create(outputFlag)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", outputFlag)
+	}
+	defer f.Close()
+	return writeJSON(f, transcript)
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/batchhandler"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/config"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/txfilter"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
@@ -36,6 +37,7 @@ type Batcher struct {
 	l1EthClient         *ethclient.Client
 	config              *config.Config
 	signer              txtypes.Signer
+	filter              *txfilter.Chain
 	dbpool              *pgxpool.Pool
 	nextBatchChainState *ChainState
 	mux                 sync.Mutex
@@ -54,12 +56,17 @@ func newBatcherFromClients(
 		return nil, err
 	}
 	signer := txtypes.LatestSignerForChainID(chainID)
+	filter, err := txfilter.New(ctx, cfg.TxFilter)
+	if err != nil {
+		return nil, err
+	}
 
 	btchr := &Batcher{
 		l2Client:            l2Client,
 		l1EthClient:         l1EthClient,
 		config:              cfg,
 		signer:              signer,
+		filter:              filter,
 		dbpool:              dbpool,
 		nextBatchChainState: nil,
 	}
@@ -79,7 +86,7 @@ func NewBatcher(ctx context.Context, cfg *config.Config, dbpool *pgxpool.Pool) (
 		return nil, err
 	}
 
-	l2Client, err := NewRPCClient(ctx, cfg.SequencerURL)
+	l2Client, err := NewRPCClient(ctx, cfg.SequencerURL, cfg.SequencerTLS)
 	if err != nil {
 		return nil, err
 	}
@@ -363,6 +370,16 @@ func (btchr *Batcher) EnqueueTx(ctx context.Context, txBytes []byte) error {
 	if err != nil {
 		return err
 	}
+	// Run the filters before taking the lock, the external filter may take a while to answer.
+	err = btchr.filter.Check(ctx, &txfilter.Tx{
+		Sender:     account,
+		Hash:       tx.Hash(),
+		BatchIndex: tx.BatchIndex(),
+		Bytes:      txBytes,
+	})
+	if err != nil {
+		return err
+	}
 
 	btchr.mux.Lock()
 	defer btchr.mux.Unlock()
@@ -464,6 +481,6 @@ func hashTransactions(txs []cltrdb.Transaction) []byte {
 	for i, t := range txs {
 		txHashes[i] = t.TxHash
 	}
-	// Hash the list of transaction hashes
-	return p2pmsg.HashByteList(txHashes)
+	// commit to the list of transaction hashes, see p2pmsg.DecryptionTrigger.VerifyBatch
+	return p2pmsg.MerkleRoot(txHashes)
 }
@@ -227,7 +227,7 @@ func TestDecryptionTriggerGeneratedIntegration(t *testing.T) {
 	assert.Equal(t, len(triggers), 1)
 	trigger := triggers[0]
 
-	expectedHash := p2pmsg.HashByteList([][]byte{txHash, tx2Hash})
+	expectedHash := p2pmsg.MerkleRoot([][]byte{txHash, tx2Hash})
 	assert.DeepEqual(t, expectedHash, trigger.BatchHash)
 
 	err = fixtures.DB.UpdateDecryptionTriggerSent(ctx, trigger.EpochID)
@@ -9,6 +9,7 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/l2client"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/tlsconfig"
 )
 
 type AccountInfo struct {
@@ -62,8 +63,8 @@ func (rc *rpcClient) GetBlockInfo(ctx context.Context) (BlockInfo, error) {
 	return ethclient.NewClient(rc.client).BlockByNumber(ctx, nil)
 }
 
-func NewRPCClient(ctx context.Context, url string) (L2ClientReader, error) {
-	client, err := rpc.DialContext(ctx, url)
+func NewRPCClient(ctx context.Context, url string, tlsConfig *tlsconfig.ClientConfig) (L2ClientReader, error) {
+	client, err := tlsConfig.DialRPC(ctx, url)
 	if err != nil {
 		return nil, err
 	}
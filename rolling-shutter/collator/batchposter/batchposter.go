@@ -0,0 +1,77 @@
+// Package batchposter posts the signed batch transactions of the collator to the place the
+// sequencer reads them from. Which way is used is configured per deployment.
+package batchposter
+
+import (
+	"context"
+	"crypto/ecdsa"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/featureflag"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/mocksequencer/client"
+)
+
+// FeatureBlobPosting gates the blob method. While it is disabled, batches are posted as calldata
+// instead.
+const FeatureBlobPosting = "blob-posting"
+
+// Poster posts a signed batch transaction. It may be called repeatedly with the same batch until
+// the sequencer has included it, so implementations have to avoid posting a batch twice while an
+// earlier attempt is still pending.
+type Poster interface {
+	PostBatch(ctx context.Context, epoch epochid.EpochID, batchTx []byte) error
+}
+
+// New creates the poster selected by the config. The sequencer client is used by the sequencer
+// method, the database, the L1 client and the private key by the calldata and blob methods.
+func New(
+	ctx context.Context,
+	cfg *Config,
+	dbpool *pgxpool.Pool,
+	sequencer *client.Client,
+	l1Client *ethclient.Client,
+	privKey *ecdsa.PrivateKey,
+	features *featureflag.Set,
+) (Poster, error) {
+	method, err := cfg.parseMethod()
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Str("method", string(method)).Msg("posting batches")
+	switch method {
+	case MethodCalldata:
+		sender, err := newL1Sender(ctx, cfg, dbpool, l1Client, privKey)
+		if err != nil {
+			return nil, err
+		}
+		return &CalldataPoster{l1Sender: sender}, nil
+	case MethodBlob:
+		sender, err := newL1Sender(ctx, cfg, dbpool, l1Client, privKey)
+		if err != nil {
+			return nil, err
+		}
+		return &BlobPoster{
+			l1Sender:         sender,
+			maxFeePerBlobGas: cfg.MaxFeePerBlobGas,
+			features:         features,
+			calldata:         &CalldataPoster{l1Sender: sender},
+		}, nil
+	default:
+		return &SequencerPoster{sequencer: sequencer}, nil
+	}
+}
+
+// SequencerPoster submits batches to the sequencer via its JSON RPC API.
+type SequencerPoster struct {
+	sequencer *client.Client
+}
+
+func (p *SequencerPoster) PostBatch(ctx context.Context, epoch epochid.EpochID, batchTx []byte) error {
+	_, err := p.sequencer.SubmitBatchData(ctx, batchTx)
+	log.Info().Uint64("epoch-id", epoch.Uint64()).Err(err).Msg("submitted batch data")
+	return err
+}
@@ -0,0 +1,180 @@
+package batchposter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/featureflag"
+)
+
+const (
+	fieldElementsPerBlob = 4096
+	bytesPerFieldElement = 32
+	// Only 31 bytes of each field element are used, so that it is always smaller than the modulus
+	// of the BLS12-381 scalar field.
+	usableBytesPerFieldElement = 31
+	blobLengthPrefixSize       = 8
+	// MaxBlobsPerTx is the maximum number of blobs a single transaction can carry.
+	MaxBlobsPerTx = 6
+	// BlobCapacity is the number of bytes of data that fit into a single blob.
+	BlobCapacity = fieldElementsPerBlob * usableBytesPerFieldElement
+
+	blobCommitmentVersionKZG = 0x01
+)
+
+// EncodeBlobs encodes data into as many blobs as needed. The data is prefixed with its length as
+// big endian uint64 and then written to the last 31 bytes of each field element, leaving the first
+// byte zero.
+func EncodeBlobs(data []byte) []kzg4844.Blob {
+	payload := make([]byte, blobLengthPrefixSize+len(data))
+	binary.BigEndian.PutUint64(payload, uint64(len(data)))
+	copy(payload[blobLengthPrefixSize:], data)
+
+	numBlobs := (len(payload) + BlobCapacity - 1) / BlobCapacity
+	blobs := make([]kzg4844.Blob, numBlobs)
+	for i := range blobs {
+		chunk := payload[i*BlobCapacity:]
+		if len(chunk) > BlobCapacity {
+			chunk = chunk[:BlobCapacity]
+		}
+		for j := 0; j*usableBytesPerFieldElement < len(chunk); j++ {
+			element := chunk[j*usableBytesPerFieldElement:]
+			if len(element) > usableBytesPerFieldElement {
+				element = element[:usableBytesPerFieldElement]
+			}
+			copy(blobs[i][j*bytesPerFieldElement+1:], element)
+		}
+	}
+	return blobs
+}
+
+// DecodeBlobs decodes the data encoded with EncodeBlobs.
+func DecodeBlobs(blobs []kzg4844.Blob) ([]byte, error) {
+	payload := make([]byte, 0, len(blobs)*BlobCapacity)
+	for i := range blobs {
+		for j := 0; j < fieldElementsPerBlob; j++ {
+			element := blobs[i][j*bytesPerFieldElement : (j+1)*bytesPerFieldElement]
+			if element[0] != 0 {
+				return nil, errors.Errorf("invalid field element %d in blob %d", j, i)
+			}
+			payload = append(payload, element[1:]...)
+		}
+	}
+	if len(payload) < blobLengthPrefixSize {
+		return nil, errors.New("blobs too short")
+	}
+	length := binary.BigEndian.Uint64(payload)
+	if length > uint64(len(payload)-blobLengthPrefixSize) {
+		return nil, errors.Errorf("blobs contain %d bytes, but data length is %d",
+			len(payload)-blobLengthPrefixSize, length)
+	}
+	return payload[blobLengthPrefixSize : blobLengthPrefixSize+length], nil
+}
+
+func blobHash(commitment kzg4844.Commitment) common.Hash {
+	hash := common.Hash(sha256.Sum256(commitment[:]))
+	hash[0] = blobCommitmentVersionKZG
+	return hash
+}
+
+// blobTxWithBlobs is the network representation of a blob transaction, which includes the blobs
+// together with their commitments and proofs.
+type blobTxWithBlobs struct {
+	Tx          *types.BlobTx
+	Blobs       []kzg4844.Blob
+	Commitments []kzg4844.Commitment
+	Proofs      []kzg4844.Proof
+}
+
+// BlobPoster posts batches as blobs of EIP-4844 transactions to the inbox address on L1. While
+// the blob posting feature is disabled, it falls back to posting them as calldata.
+type BlobPoster struct {
+	*l1Sender
+	maxFeePerBlobGas uint64
+	features         *featureflag.Set
+	calldata         *CalldataPoster
+}
+
+func (p *BlobPoster) PostBatch(ctx context.Context, epoch epochid.EpochID, batchTx []byte) error {
+	if !p.features.Enabled(FeatureBlobPosting) {
+		return p.calldata.PostBatch(ctx, epoch, batchTx)
+	}
+	pending, err := p.isPending(ctx, epoch, batchTx)
+	if err != nil || pending {
+		return err
+	}
+
+	blobs := EncodeBlobs(batchTx)
+	if len(blobs) > MaxBlobsPerTx {
+		return errors.Errorf("batch of %d bytes needs %d blobs, but at most %d fit into a transaction",
+			len(batchTx), len(blobs), MaxBlobsPerTx)
+	}
+	wrapped := &blobTxWithBlobs{
+		Blobs:       blobs,
+		Commitments: make([]kzg4844.Commitment, len(blobs)),
+		Proofs:      make([]kzg4844.Proof, len(blobs)),
+	}
+	hashes := make([]common.Hash, len(blobs))
+	for i, blob := range blobs {
+		wrapped.Commitments[i], err = kzg4844.BlobToCommitment(blob)
+		if err != nil {
+			return errors.Wrap(err, "failed to compute blob commitment")
+		}
+		wrapped.Proofs[i], err = kzg4844.ComputeBlobProof(blob, wrapped.Commitments[i])
+		if err != nil {
+			return errors.Wrap(err, "failed to compute blob proof")
+		}
+		hashes[i] = blobHash(wrapped.Commitments[i])
+	}
+
+	nonce, gasTipCap, gasFeeCap, err := p.txParams(ctx)
+	if err != nil {
+		return err
+	}
+	gas, err := p.estimateGas(ctx, gasTipCap, gasFeeCap, nil)
+	if err != nil {
+		return err
+	}
+	blobTx := &types.BlobTx{
+		ChainID:    uint256.MustFromBig(p.chainID),
+		Nonce:      nonce,
+		GasTipCap:  uint256.MustFromBig(gasTipCap),
+		GasFeeCap:  uint256.MustFromBig(gasFeeCap),
+		Gas:        gas,
+		To:         &p.inbox,
+		Value:      new(uint256.Int),
+		BlobFeeCap: uint256.NewInt(p.maxFeePerBlobGas),
+		BlobHashes: hashes,
+	}
+	tx, err := types.SignNewTx(p.privKey, p.signer, blobTx)
+	if err != nil {
+		return errors.Wrap(err, "failed to sign batch transaction")
+	}
+	v, r, s := tx.RawSignatureValues()
+	blobTx.V = uint256.MustFromBig(v)
+	blobTx.R = uint256.MustFromBig(r)
+	blobTx.S = uint256.MustFromBig(s)
+	wrapped.Tx = blobTx
+
+	encoded, err := rlp.EncodeToBytes(wrapped)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode blob transaction")
+	}
+	raw := append([]byte{types.BlobTxType}, encoded...)
+	if err := p.send(ctx, MethodBlob, epoch, batchTx, tx, raw); err != nil {
+		return err
+	}
+	log.Info().Uint64("epoch-id", epoch.Uint64()).Str("tx", tx.Hash().Hex()).
+		Int("size", len(batchTx)).Int("num-blobs", len(blobs)).Msg("posted batch as L1 blobs")
+	return nil
+}
@@ -0,0 +1,36 @@
+package batchposter
+
+import (
+	"bytes"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestEncodeBlobsRoundtrip(t *testing.T) {
+	for _, size := range []int{0, 1, 31, BlobCapacity - blobLengthPrefixSize, BlobCapacity, 3*BlobCapacity + 17} {
+		data := bytes.Repeat([]byte{0xff}, size)
+		blobs := EncodeBlobs(data)
+		assert.Equal(t, len(blobs), (size+blobLengthPrefixSize+BlobCapacity-1)/BlobCapacity, "size %d", size)
+		for _, blob := range blobs {
+			for j := 0; j < fieldElementsPerBlob; j++ {
+				assert.Equal(t, blob[j*bytesPerFieldElement], byte(0))
+			}
+		}
+		decoded, err := DecodeBlobs(blobs)
+		assert.NilError(t, err)
+		assert.Check(t, bytes.Equal(decoded, data), "size %d", size)
+	}
+}
+
+func TestDecodeBlobsInvalid(t *testing.T) {
+	blobs := EncodeBlobs([]byte{1, 2, 3})
+	blobs[0][bytesPerFieldElement] = 1
+	_, err := DecodeBlobs(blobs)
+	assert.ErrorContains(t, err, "invalid field element")
+
+	blobs = EncodeBlobs([]byte{1, 2, 3})
+	blobs[0][1] = 0xff
+	_, err = DecodeBlobs(blobs)
+	assert.ErrorContains(t, err, "data length")
+}
@@ -0,0 +1,85 @@
+package batchposter
+
+import (
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+// Config selects how the collator posts signed batch transactions.
+type Config struct {
+	Method           string `comment:"How batches are posted: sequencer (JSON RPC of the sequencer), calldata (calldata of L1 transactions) or blob (EIP-4844 blob transactions on L1)"`
+	InboxAddress     string `comment:"L1 address batch transactions are sent to, used by the calldata and blob methods"`
+	MaxFeePerBlobGas uint64 `comment:"Maximum fee per blob gas in wei, used by the blob method"`
+}
+
+// Method is the way batches are posted.
+type Method string
+
+const (
+	// MethodSequencer submits batches to the sequencer via its JSON RPC API.
+	MethodSequencer Method = "sequencer"
+	// MethodCalldata posts batches as calldata of L1 transactions.
+	MethodCalldata Method = "calldata"
+	// MethodBlob posts batches as blobs of EIP-4844 transactions on L1.
+	MethodBlob Method = "blob"
+)
+
+func (c *Config) Init() {}
+
+func (c *Config) Name() string {
+	return "batchposting"
+}
+
+func (c *Config) Validate() error {
+	method, err := c.parseMethod()
+	if err != nil {
+		return err
+	}
+	if method == MethodSequencer {
+		return nil
+	}
+	if !common.IsHexAddress(c.InboxAddress) {
+		return errors.Errorf("invalid inbox address %q for batch posting method %s", c.InboxAddress, method)
+	}
+	if method == MethodBlob && c.MaxFeePerBlobGas == 0 {
+		return errors.New("max fee per blob gas must be set for the blob batch posting method")
+	}
+	return nil
+}
+
+func (c *Config) parseMethod() (Method, error) {
+	switch method := Method(c.Method); method {
+	case "":
+		return MethodSequencer, nil
+	case MethodSequencer, MethodCalldata, MethodBlob:
+		return method, nil
+	default:
+		return "", errors.Errorf("unknown batch posting method %q", c.Method)
+	}
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.Method = string(MethodSequencer)
+	c.MaxFeePerBlobGas = 1_000_000_000
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c *Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
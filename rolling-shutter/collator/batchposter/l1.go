@@ -0,0 +1,296 @@
+package batchposter
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+// l1Sender sends the transactions carrying batches to the inbox address on L1 and keeps track of
+// the last one sent. Each transaction is recorded as intent in the database before it is
+// broadcast, see cltrdb.L1Intent.
+type l1Sender struct {
+	client  *ethclient.Client
+	dbpool  *pgxpool.Pool
+	privKey *ecdsa.PrivateKey
+	sender  common.Address
+	chainID *big.Int
+	signer  types.Signer
+	inbox   common.Address
+
+	pending *cltrdb.L1Intent
+}
+
+func newL1Sender(
+	ctx context.Context,
+	cfg *Config,
+	dbpool *pgxpool.Pool,
+	client *ethclient.Client,
+	privKey *ecdsa.PrivateKey,
+) (*l1Sender, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get L1 chain id")
+	}
+	s := &l1Sender{
+		client:  client,
+		dbpool:  dbpool,
+		privKey: privKey,
+		sender:  crypto.PubkeyToAddress(privKey.PublicKey),
+		chainID: chainID,
+		signer:  types.LatestSignerForChainID(chainID),
+		inbox:   common.HexToAddress(cfg.InboxAddress),
+	}
+	if err := s.reconcile(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// txState is the state of a batch transaction on L1.
+type txState int
+
+const (
+	// txUnknown transactions are neither included nor replaced. They may be in the mempool or
+	// may never have been broadcast.
+	txUnknown txState = iota
+	txSucceeded
+	txFailed
+	// txReplaced transactions have been replaced by another transaction with the same nonce.
+	txReplaced
+)
+
+func (s *l1Sender) txState(ctx context.Context, intent *cltrdb.L1Intent) (txState, error) {
+	txHash := common.BytesToHash(intent.TxHash)
+	receipt, err := s.client.TransactionReceipt(ctx, txHash)
+	if err == nil {
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return txFailed, nil
+		}
+		return txSucceeded, nil
+	}
+	if err != ethereum.NotFound {
+		return txUnknown, errors.Wrapf(err, "failed to get receipt of batch transaction %s", txHash)
+	}
+	nonce, err := s.client.NonceAt(ctx, s.sender, nil)
+	if err != nil {
+		return txUnknown, errors.Wrap(err, "failed to get nonce")
+	}
+	if nonce > uint64(intent.Nonce) {
+		return txReplaced, nil
+	}
+	return txUnknown, nil
+}
+
+// reconcile checks the intent recorded before the last restart against the chain, see resume.
+func (s *l1Sender) reconcile(ctx context.Context) error {
+	intent, err := cltrdb.New(s.dbpool).GetL1Intent(ctx)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get intent of batch transaction from db")
+	}
+	return s.resume(ctx, &intent)
+}
+
+// resume continues tracking the transaction of a recorded intent. If the transaction is neither
+// included nor replaced, we may have crashed before broadcasting it, so it is broadcast again.
+func (s *l1Sender) resume(ctx context.Context, intent *cltrdb.L1Intent) error {
+	state, err := s.txState(ctx, intent)
+	if err != nil {
+		return err
+	}
+	logger := log.With().Hex("epoch-id", intent.EpochID).Hex("tx", intent.TxHash).
+		Int64("nonce", intent.Nonce).Logger()
+	if state == txUnknown {
+		if err := s.broadcast(ctx, intent.RawTx); err != nil {
+			// Nodes reject transactions they know already, e.g. because we broadcast it before
+			// crashing, but they don't agree on an error code for that, so we ask for the
+			// transaction instead.
+			known, knownErr := s.isKnown(ctx, common.BytesToHash(intent.TxHash))
+			if knownErr != nil {
+				return knownErr
+			}
+			if !known {
+				// The transaction can't be included anymore, e.g. because the fee caps are too
+				// low now, so PostBatch has to send a new one.
+				logger.Warn().Err(err).Msg("failed to broadcast recorded batch transaction again")
+				return nil
+			}
+		}
+		logger.Info().Msg("broadcast recorded batch transaction again")
+	}
+	s.pending = intent
+	return nil
+}
+
+// isKnown checks if the L1 node knows the transaction, i.e. if it's in its mempool or included.
+func (s *l1Sender) isKnown(ctx context.Context, txHash common.Hash) (bool, error) {
+	_, _, err := s.client.TransactionByHash(ctx, txHash)
+	if err == ethereum.NotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get batch transaction %s", txHash)
+	}
+	return true, nil
+}
+
+// isPending checks if a transaction carrying the given batch of the given epoch has already been
+// sent and is either still pending or has been included successfully. In both cases, the batch
+// must not be sent again.
+func (s *l1Sender) isPending(ctx context.Context, epoch epochid.EpochID, batchTx []byte) (bool, error) {
+	if s.pending == nil ||
+		!bytes.Equal(s.pending.EpochID, epoch.Bytes()) ||
+		!bytes.Equal(s.pending.PayloadHash, crypto.Keccak256(batchTx)) {
+		return false, nil
+	}
+	state, err := s.txState(ctx, s.pending)
+	if err != nil {
+		return false, err
+	}
+	switch state {
+	case txFailed:
+		log.Warn().Uint64("epoch-id", epoch.Uint64()).Hex("tx", s.pending.TxHash).
+			Msg("batch transaction failed, sending it again")
+		return false, nil
+	case txReplaced:
+		log.Warn().Uint64("epoch-id", epoch.Uint64()).Hex("tx", s.pending.TxHash).
+			Msg("batch transaction has been replaced, sending it again")
+		return false, nil
+	default:
+		return true, nil
+	}
+}
+
+// send records the signed transaction carrying the batch as intent and broadcasts it. raw is the
+// transaction as it is broadcast with eth_sendRawTransaction.
+func (s *l1Sender) send(
+	ctx context.Context, method Method, epoch epochid.EpochID, batchTx []byte, tx *types.Transaction, raw []byte,
+) error {
+	intent := cltrdb.L1Intent{
+		EpochID:     epoch.Bytes(),
+		Action:      string(method),
+		PayloadHash: crypto.Keccak256(batchTx),
+		Nonce:       int64(tx.Nonce()),
+		TxHash:      tx.Hash().Bytes(),
+		RawTx:       raw,
+	}
+	err := cltrdb.New(s.dbpool).SetL1Intent(ctx, cltrdb.SetL1IntentParams{
+		EpochID:     intent.EpochID,
+		Action:      intent.Action,
+		PayloadHash: intent.PayloadHash,
+		Nonce:       intent.Nonce,
+		TxHash:      intent.TxHash,
+		RawTx:       intent.RawTx,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to insert intent of batch transaction into db")
+	}
+	if err := s.broadcast(ctx, raw); err != nil {
+		return err
+	}
+	s.pending = &intent
+	return nil
+}
+
+func (s *l1Sender) broadcast(ctx context.Context, raw []byte) error {
+	// ethclient.SendTransaction can't send the blobs along with blob transactions, so we use the
+	// raw rpc client.
+	err := s.client.Client().CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(raw))
+	return errors.Wrap(err, "failed to send batch transaction")
+}
+
+// txParams returns the nonce and the fee caps for the next transaction.
+func (s *l1Sender) txParams(ctx context.Context) (uint64, *big.Int, *big.Int, error) {
+	nonce, err := s.client.PendingNonceAt(ctx, s.sender)
+	if err != nil {
+		return 0, nil, nil, errors.Wrap(err, "failed to get nonce")
+	}
+	gasTipCap, err := s.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return 0, nil, nil, errors.Wrap(err, "failed to get gas tip cap")
+	}
+	header, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, nil, nil, errors.Wrap(err, "failed to get latest L1 header")
+	}
+	if header.BaseFee == nil {
+		return 0, nil, nil, errors.New("L1 doesn't support dynamic fee transactions")
+	}
+	// allow the base fee to double before the transaction becomes unincludable
+	gasFeeCap := new(big.Int).Add(gasTipCap, new(big.Int).Mul(header.BaseFee, big.NewInt(2)))
+	return nonce, gasTipCap, gasFeeCap, nil
+}
+
+func (s *l1Sender) estimateGas(ctx context.Context, gasTipCap, gasFeeCap *big.Int, data []byte) (uint64, error) {
+	gas, err := s.client.EstimateGas(ctx, ethereum.CallMsg{
+		From:      s.sender,
+		To:        &s.inbox,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+		Data:      data,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to estimate gas of batch transaction")
+	}
+	return gas, nil
+}
+
+// CalldataPoster posts batches as calldata of transactions to the inbox address on L1.
+type CalldataPoster struct {
+	*l1Sender
+}
+
+func (p *CalldataPoster) PostBatch(ctx context.Context, epoch epochid.EpochID, batchTx []byte) error {
+	pending, err := p.isPending(ctx, epoch, batchTx)
+	if err != nil || pending {
+		return err
+	}
+	nonce, gasTipCap, gasFeeCap, err := p.txParams(ctx)
+	if err != nil {
+		return err
+	}
+	gas, err := p.estimateGas(ctx, gasTipCap, gasFeeCap, batchTx)
+	if err != nil {
+		return err
+	}
+	tx, err := types.SignNewTx(p.privKey, p.signer, &types.DynamicFeeTx{
+		ChainID:   p.chainID,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gas,
+		To:        &p.inbox,
+		Data:      batchTx,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to sign batch transaction")
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(err, "failed to encode batch transaction")
+	}
+	if err := p.send(ctx, MethodCalldata, epoch, batchTx, tx, raw); err != nil {
+		return err
+	}
+	log.Info().Uint64("epoch-id", epoch.Uint64()).Str("tx", tx.Hash().Hex()).
+		Int("size", len(batchTx)).Msg("posted batch as L1 calldata")
+	return nil
+}
@@ -0,0 +1,116 @@
+package batchposter
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+// fakeL1 implements the part of the eth namespace the l1Sender uses to track its transactions.
+type fakeL1 struct {
+	receipt *types.Receipt
+	nonce   uint64
+	known   *types.Transaction
+	reject  bool
+	sent    int
+}
+
+func (l1 *fakeL1) GetTransactionReceipt(common.Hash) *types.Receipt {
+	return l1.receipt
+}
+
+func (l1 *fakeL1) GetTransactionCount(common.Address, string) hexutil.Uint64 {
+	return hexutil.Uint64(l1.nonce)
+}
+
+func (l1 *fakeL1) GetTransactionByHash(common.Hash) *types.Transaction {
+	return l1.known
+}
+
+func (l1 *fakeL1) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	if l1.reject {
+		return common.Hash{}, errors.New("transaction rejected")
+	}
+	l1.sent++
+	return crypto.Keccak256Hash(raw), nil
+}
+
+func TestResumeIntent(t *testing.T) {
+	ctx := context.Background()
+	privKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	inbox := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	epoch := epochid.Uint64ToEpochID(7)
+	batchTx := []byte("batch")
+	tx, err := types.SignNewTx(privKey, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     5,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(2),
+		Gas:       21000,
+		To:        &inbox,
+		Data:      batchTx,
+	})
+	assert.NilError(t, err)
+	raw, err := tx.MarshalBinary()
+	assert.NilError(t, err)
+	receipt := func(status uint64) *types.Receipt {
+		return &types.Receipt{Status: status, TxHash: tx.Hash(), Logs: []*types.Log{}}
+	}
+
+	for _, test := range []struct {
+		name    string
+		l1      *fakeL1
+		sent    int
+		resumed bool
+		pending bool
+	}{
+		{name: "succeeded", l1: &fakeL1{receipt: receipt(types.ReceiptStatusSuccessful), nonce: 6}, resumed: true, pending: true},
+		{name: "failed", l1: &fakeL1{receipt: receipt(types.ReceiptStatusFailed), nonce: 6}, resumed: true},
+		{name: "replaced", l1: &fakeL1{nonce: 6}, resumed: true},
+		{name: "not broadcast", l1: &fakeL1{nonce: 5}, sent: 1, resumed: true, pending: true},
+		{name: "in mempool", l1: &fakeL1{nonce: 5, known: tx, reject: true}, resumed: true, pending: true},
+		{name: "dropped", l1: &fakeL1{nonce: 5, reject: true}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := rpc.NewServer()
+			defer server.Stop()
+			assert.NilError(t, server.RegisterName("eth", test.l1))
+			s := &l1Sender{
+				client: ethclient.NewClient(rpc.DialInProc(server)),
+				sender: crypto.PubkeyToAddress(privKey.PublicKey),
+			}
+
+			err := s.resume(ctx, &cltrdb.L1Intent{
+				EpochID:     epoch.Bytes(),
+				Action:      string(MethodCalldata),
+				PayloadHash: crypto.Keccak256(batchTx),
+				Nonce:       int64(tx.Nonce()),
+				TxHash:      tx.Hash().Bytes(),
+				RawTx:       raw,
+			})
+			assert.NilError(t, err)
+			assert.Equal(t, test.l1.sent, test.sent)
+			assert.Equal(t, s.pending != nil, test.resumed)
+
+			pending, err := s.isPending(ctx, epoch, batchTx)
+			assert.NilError(t, err)
+			assert.Equal(t, pending, test.pending)
+			pending, err = s.isPending(ctx, epoch, []byte("other batch"))
+			assert.NilError(t, err)
+			assert.Check(t, !pending)
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
@@ -13,10 +15,16 @@ import (
 	txtypes "github.com/shutter-network/txtypes/types"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/batcher"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/batchposter"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/config"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/inclusion"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/stream"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/featureflag"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/plugin"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/mocksequencer/client"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 )
 
 type Submitter struct {
@@ -25,7 +33,9 @@ type Submitter struct {
 	dbpool    *pgxpool.Pool
 	privKey   *ecdsa.PrivateKey
 	signer    txtypes.Signer
-	sequencer *client.Client
+	poster    batchposter.Poster
+	stream    *stream.Config
+	inclusion *inclusion.Config
 	collator  *collator
 }
 
@@ -33,13 +43,14 @@ func NewSubmitter(
 	ctx context.Context,
 	cfg *config.Config,
 	dbpool *pgxpool.Pool,
+	features *featureflag.Set,
 ) (*Submitter, error) {
 	l1Client, err := ethclient.Dial(cfg.Ethereum.EthereumURL)
 	if err != nil {
 		return nil, err
 	}
 
-	l2Client, err := batcher.NewRPCClient(ctx, cfg.SequencerURL)
+	l2Client, err := batcher.NewRPCClient(ctx, cfg.SequencerURL, cfg.SequencerTLS)
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +59,12 @@ func NewSubmitter(
 		return nil, err
 	}
 	signer := txtypes.LatestSignerForChainID(chainID)
-	sequencer, err := client.DialContext(ctx, cfg.SequencerURL)
+	sequencerRPC, err := cfg.SequencerTLS.DialRPC(ctx, cfg.SequencerURL)
+	if err != nil {
+		return nil, err
+	}
+	sequencer := client.NewClient(sequencerRPC)
+	poster, err := batchposter.New(ctx, cfg.BatchPosting, dbpool, sequencer, l1Client, cfg.Ethereum.PrivateKey.Key, features)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +74,9 @@ func NewSubmitter(
 		dbpool:    dbpool,
 		signer:    signer,
 		privKey:   cfg.Ethereum.PrivateKey.Key,
-		sequencer: sequencer,
+		poster:    poster,
+		stream:    cfg.Stream,
+		inclusion: cfg.Inclusion,
 	}, nil
 }
 
@@ -87,9 +105,22 @@ func (submitter *Submitter) createBatchTx(
 		return err
 	}
 
+	// only decrypt the batch the trigger committed to, so that the decryption key is never
+	// published in a batchtx together with transactions it wasn't released for
+	trigger, err := db.GetTrigger(ctx, epoch.Bytes())
+	if err != nil {
+		return err
+	}
 	transactions := [][]byte{}
+	txHashes := [][]byte{}
 	for _, t := range txs {
 		transactions = append(transactions, t.TxBytes)
+		txHashes = append(txHashes, t.TxHash)
+	}
+	commitment := &p2pmsg.DecryptionTrigger{EpochID: epoch.Bytes(), TransactionsHash: trigger.BatchHash}
+	err = commitment.VerifyBatch(txHashes)
+	if err != nil {
+		return err
 	}
 	// XXX the collator will advertise the L1 block number in the future. Users will include
 	// this L1 block number in their signed transactions and the collator will also include it
@@ -115,20 +146,57 @@ func (submitter *Submitter) createBatchTx(
 	if err != nil {
 		return err
 	}
-	err = db.InsertBatchTx(ctx, cltrdb.InsertBatchTxParams{
-		EpochID:   epoch.Bytes(),
-		Marshaled: txbytes,
+	// the batch is added to the stream outbox and its inclusion proof is enqueued in the same
+	// transaction, so that they are published if and only if the batchtx is stored
+	err = submitter.dbpool.BeginFunc(ctx, func(dbtx pgx.Tx) error {
+		txdb := db.WithTx(dbtx)
+		err := txdb.InsertBatchTx(ctx, cltrdb.InsertBatchTxParams{
+			EpochID:   epoch.Bytes(),
+			Marshaled: txbytes,
+		})
+		if err != nil {
+			return err
+		}
+		err = stream.Enqueue(ctx, txdb, submitter.stream, &stream.Batch{
+			EpochID:       epoch.Bytes(),
+			BatchIndex:    epoch.Uint64(),
+			DecryptionKey: decryptionKey.DecryptionKey,
+			Transactions:  transactionBytes(transactions),
+			BatchTx:       txbytes,
+			Collator:      ethcrypto.PubkeyToAddress(submitter.privKey.PublicKey),
+		})
+		if err != nil {
+			return err
+		}
+		return submitter.enqueueDecryptedBundle(ctx, dbtx, epoch, decryptionKey.DecryptionKey, txs, txbytes)
 	})
 	if err != nil {
 		return err
 	}
 	logger.Info().Int("num-tx", len(txs)).Msg("created batchtx")
+	if submitter.collator != nil {
+		submitter.collator.plugins.BatchDecrypted(plugin.BatchDecrypted{
+			EpochID:       epoch.Bytes(),
+			BatchIndex:    epoch.Uint64(),
+			DecryptionKey: decryptionKey.DecryptionKey,
+			Transactions:  transactionBytes(transactions),
+		})
+	}
 	return nil
 }
 
-// submitBatchTxToSequencer reads the unsubmitted batchtx from the database and tries to submit it
-// to the sequencer.
-func (submitter *Submitter) submitBatchTxToSequencer(ctx context.Context) error {
+// transactionBytes converts the transactions of a batch for the plugins.
+func transactionBytes(transactions [][]byte) []hexutil.Bytes {
+	result := make([]hexutil.Bytes, len(transactions))
+	for i, tx := range transactions {
+		result[i] = tx
+	}
+	return result
+}
+
+// postBatchTx reads the unsubmitted batchtx from the database and tries to post it the way
+// configured for the deployment.
+func (submitter *Submitter) postBatchTx(ctx context.Context) error {
 	db := cltrdb.New(submitter.dbpool)
 	unsubmitted, err := db.GetUnsubmittedBatchTx(ctx)
 	if err == pgx.ErrNoRows {
@@ -151,10 +219,7 @@ func (submitter *Submitter) submitBatchTxToSequencer(ctx context.Context) error
 		return db.SetBatchSubmitted(ctx)
 	}
 
-	_, err = submitter.sequencer.SubmitBatchData(ctx, unsubmitted.Marshaled)
-	log.Info().Uint64("epoch-id", epoch.Uint64()).Err(err).Msg("submitted batch data")
-
-	return err
+	return submitter.poster.PostBatch(ctx, epoch, unsubmitted.Marshaled)
 }
 
 func (submitter *Submitter) submitBatch(ctx context.Context) error {
@@ -5,8 +5,11 @@ import (
 )
 
 const (
-	CipherBatch       = "cipherBatch"
-	DecryptionTrigger = kprtopics.DecryptionTrigger
-	DecryptionKey     = kprtopics.DecryptionKey
-	EonPublicKey      = kprtopics.EonPublicKey
+	CipherBatch            = "cipherBatch"
+	DecryptionTrigger      = kprtopics.DecryptionTrigger
+	DecryptionTriggerBatch = kprtopics.DecryptionTriggerBatch
+	DecryptionKey          = kprtopics.DecryptionKey
+	EonPublicKey           = kprtopics.EonPublicKey
+	EpochPreAnnouncement   = kprtopics.EpochPreAnnouncement
+	NodeAttestation        = kprtopics.NodeAttestation
 )
@@ -23,15 +23,31 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/batcher"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/cltrtopics"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/config"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/externaltrigger"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/inclusion"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/l2client"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/oapi"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/stream"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/txfilter"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/contract/deployment"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/alert"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/beaconapi"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/featureflag"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/httpauth"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/jobqueue"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/logfilter"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/metricsserver"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/opapproval"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/paramregistry"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/plugin"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/triggeroffset"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p/provenance"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
@@ -54,7 +70,13 @@ type collator struct {
 	p2p       *p2p.P2PHandler
 	dbpool    *pgxpool.Pool
 	submitter *Submitter
+	features  *featureflag.Set
+	plugins   *plugin.Host
 	signals   signals
+
+	triggerPolicy *externaltrigger.Policy
+	triggerOffset triggeroffset.Offset
+	inclusionHub  *inclusion.Hub
 }
 
 func New(cfg *config.Config) service.Service {
@@ -88,11 +110,38 @@ func (c *collator) Start(ctx context.Context, runner service.Runner) error {
 	if err != nil {
 		return err
 	}
+	if !cfg.Ethereum.SkipDeploymentVerification {
+		if err := contracts.Verify(ctx); err != nil {
+			return err
+		}
+	}
 
 	err = cltrdb.ValidateDB(ctx, dbpool)
 	if err != nil {
 		return err
 	}
+	params := paramregistry.New(dbpool)
+	err = params.Populate(ctx, map[string]string{
+		paramregistry.FinalityOffset:      paramregistry.FormatUint64(cfg.Ethereum.FinalityOffset),
+		paramregistry.EpochDuration:       cfg.EpochDuration.Duration.String(),
+		paramregistry.TriggerOffsetBlocks: paramregistry.FormatUint64(cfg.TriggerOffsetBlocks),
+		paramregistry.TriggerOffset:       cfg.TriggerOffset.Duration.String(),
+	})
+	if err != nil {
+		return err
+	}
+	epochDuration, err := params.Duration(ctx, paramregistry.EpochDuration)
+	if err != nil {
+		return err
+	}
+	c.triggerOffset, err = triggeroffset.Load(ctx, params)
+	if err != nil {
+		return err
+	}
+	// the stored parameters may differ from the config
+	if err := c.triggerOffset.Validate(epochDuration, cfg.L1BlockTime.Duration); err != nil {
+		return err
+	}
 
 	btchr, err := batcher.NewBatcher(ctx, cfg, dbpool)
 	if err != nil {
@@ -100,11 +149,15 @@ func (c *collator) Start(ctx context.Context, runner service.Runner) error {
 	}
 
 	log.Info().Str("sequencer-url", cfg.SequencerURL).Msg("connecting sequencer")
-	l2RPCClient, err := rpc.Dial(cfg.SequencerURL)
+	l2RPCClient, err := cfg.SequencerTLS.DialRPC(ctx, cfg.SequencerURL)
+	if err != nil {
+		return err
+	}
+	features, err := featureflag.New(cfg.Name(), cfg.Features, Features...)
 	if err != nil {
 		return err
 	}
-	submitter, err := NewSubmitter(ctx, cfg, dbpool)
+	submitter, err := NewSubmitter(ctx, cfg, dbpool, features)
 	if err != nil {
 		return err
 	}
@@ -113,14 +166,27 @@ func (c *collator) Start(ctx context.Context, runner service.Runner) error {
 	c.l2Client = l2RPCClient
 	c.contracts = contracts
 
-	c.p2p, err = p2p.New(cfg.P2P)
+	c.p2p, err = p2p.New(cfg.P2P, cfg.InstanceID)
 	if err != nil {
 		return err
 	}
 	c.batcher = btchr
 	c.dbpool = dbpool
 	c.submitter = submitter
+	c.features = features
 	c.submitter.collator = c
+	if cfg.ExternalTriggers.Enabled() {
+		c.triggerPolicy = externaltrigger.NewPolicy(cfg.ExternalTriggers, cfg.InstanceID)
+	}
+	if cfg.Plugins.Enabled() {
+		c.plugins = plugin.NewHost(cfg.Plugins, "collator")
+		runner.Go(func() error {
+			return c.plugins.Run(ctx)
+		})
+	}
+	if cfg.Inclusion.WebSocket {
+		c.inclusionHub = inclusion.NewHub()
+	}
 	c.setupP2PHandler()
 
 	httpServer := &http.Server{
@@ -136,9 +202,11 @@ func (c *collator) Start(ctx context.Context, runner service.Runner) error {
 	}
 	c.signals.newDecryptionTrigger = newSignal(newDecryptionTrigger, c.sendDecryptionTriggers)
 	c.signals.newDecryptionKey = newSignal(newDecryptionKey, c.submitter.submitBatch)
-	c.signals.newBatchTx = newSignal(newBatchtx, c.submitter.submitBatchTxToSequencer)
+	c.signals.newBatchTx = newSignal(newBatchtx, c.submitter.postBatchTx)
 
-	runner.Go(httpServer.ListenAndServe)
+	runner.Go(func() error {
+		return cfg.HTTPTLS.ListenAndServe(httpServer)
+	})
 	runner.Go(func() error {
 		<-ctx.Done()
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -148,26 +216,66 @@ func (c *collator) Start(ctx context.Context, runner service.Runner) error {
 	runner.Go(func() error {
 		return c.handleContractEvents(ctx)
 	})
+	if cfg.ProvenanceRetention.Duration > 0 {
+		err = runner.StartService(provenance.NewPruner(dbpool, cfg.ProvenanceRetention.Duration))
+		if err != nil {
+			return err
+		}
+	}
+	if relay := stream.NewRelay(cfg.Stream, dbpool); relay != nil {
+		runner.Go(func() error {
+			return relay.Run(ctx)
+		})
+	}
+	if cfg.Inclusion.Enabled() {
+		jobs := jobqueue.New(dbpool, 1)
+		inclusion.Register(jobs, cfg.Inclusion, c.inclusionHub)
+		runner.Go(func() error {
+			return jobs.Run(ctx)
+		})
+	}
 	err = runner.StartService(c.p2p)
 	if err != nil {
 		return err
 	}
+	if cfg.Metrics.Enabled {
+		txfilter.InitMetrics()
+		plugin.InitMetrics()
+		stream.InitMetrics()
+		jobqueue.InitMetrics()
+		p2p.InitMetrics()
+		err = runner.StartService(metricsserver.New(cfg.Metrics))
+		if err != nil {
+			return err
+		}
+	}
 	runner.Go(func() error {
 		return c.handleDatabaseNotifications(ctx)
 	})
 	runner.Go(func() error {
-		return c.closeBatchesTicker(ctx, c.Config.EpochDuration.Duration)
+		return c.closeBatchesTicker(ctx, epochDuration)
 	})
 	return nil
 }
 
 func (c *collator) setupP2PHandler() {
-	c.p2p.AddMessageHandler(
-		&eonPublicKeyHandler{config: c.Config, dbpool: c.dbpool},
+	c.p2p.AddMessageHandler(provenance.Wrap(
+		c.dbpool,
+		nil,
+		&eonPublicKeyHandler{
+			config: c.Config,
+			dbpool: c.dbpool,
+			domain: p2pmsg.SigningDomain{
+				ChainID:    c.contracts.Deployments.ChainID,
+				InstanceID: c.Config.InstanceID,
+			},
+		},
 		&decryptionKeyHandler{Config: c.Config, dbpool: c.dbpool},
-	)
+	)...)
 
 	c.p2p.AddGossipTopic(cltrtopics.DecryptionTrigger)
+	c.p2p.AddGossipTopic(cltrtopics.DecryptionTriggerBatch)
+	c.p2p.AddGossipTopic(cltrtopics.EpochPreAnnouncement)
 }
 
 func (c *collator) setupAPIRouter(swagger *openapi3.T) http.Handler {
@@ -190,6 +298,8 @@ func (c *collator) setupRouter() *chi.Mux {
 	router := chi.NewRouter()
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
+	router.Use(httpauth.New(c.Config.HTTPAuth).Middleware)
+	router.Use(httpauth.RequireRoleByMethod())
 	router.Mount("/v1", http.StripPrefix("/v1", c.setupAPIRouter(swagger)))
 	apiJSON, _ := json.Marshal(swagger)
 	router.Get("/api.json", func(w http.ResponseWriter, r *http.Request) {
@@ -199,6 +309,23 @@ func (c *collator) setupRouter() *chi.Mux {
 		w.Header().Set("Content-Type", "application/json")
 		_, _ = w.Write(apiJSON)
 	})
+	approvals := opapproval.NewVerifier(c.Config.OperatorApproval)
+	instanceID, address := c.Config.InstanceID, c.Config.Ethereum.PrivateKey.EthereumAddress()
+	router.With(approvals.Middleware(opapproval.ActionOverrideFeature, instanceID, address)).
+		Mount("/features", c.features.Router())
+	router.With(approvals.Middleware(opapproval.ActionSetLogFilter, instanceID, address)).
+		Mount("/log", logfilter.Default.Router())
+	router.Post("/encryption-preview", (&server{c: c}).EncryptionPreview)
+	router.Get("/inclusion-proofs/{txHash}", (&server{c: c}).InclusionProof)
+	if c.inclusionHub != nil {
+		router.Get("/inclusion-proofs/ws", c.inclusionHub.ServeHTTP)
+	}
+	if c.triggerPolicy != nil {
+		router.Post("/external-triggers", (&server{c: c}).ExternalTrigger)
+	}
+	router.With(httpauth.RequireRole(httpauth.RoleAdmin)).
+		Get("/ignored-events", chainobserver.IgnoredEventsHandler(c.dbpool))
+	router.With(httpauth.RequireRole(httpauth.RoleAdmin)).Mount("/debug", middleware.Profiler())
 
 	/*
 	   The following enables the swagger ui. Run the following to use it:
@@ -268,7 +395,35 @@ func (c *collator) handleContractEvents(ctx context.Context) error {
 	events := []*eventsyncer.EventType{
 		c.contracts.KeypersConfigsListNewConfig,
 	}
-	return chainobserver.New(c.contracts, c.dbpool).Observe(ctx, events)
+	chainobs, err := chainobserver.New(c.contracts, c.dbpool, c.Config.Ethereum.EventWitnessURLs)
+	if err != nil {
+		return err
+	}
+	if url := c.Config.Ethereum.EventCrossCheckURL; url != "" {
+		// the collator has no alerting configured, so divergences are only logged
+		if err := chainobs.EnableCrossCheck(url, alert.New(alert.NewConfig())); err != nil {
+			return err
+		}
+	}
+	if url := c.Config.Ethereum.BeaconAPIURL; url != "" {
+		beacon := beaconapi.New(url, nil)
+		chainobs.EnableBlobs(beacon)
+		chainobs.EnableCheckpointSync(beacon, c.Config.Ethereum.GetSyncMode())
+	}
+	if c.contracts.KeyperRotationsRotated != nil {
+		events = append(events, c.contracts.KeyperRotationsRotated)
+		chainobs.RegisterEventHandler(
+			chainobserver.KeyperRotationsContractName,
+			chainobserver.KeyperRotatedEventName,
+			chainobserver.KeyperRotationHandler(nil),
+		)
+	}
+	schemaEvents, err := chainobs.LoadEventTypes(ctx, c.Config.Ethereum.EventSchemaDir)
+	if err != nil {
+		return err
+	}
+	events = append(events, schemaEvents...)
+	return chainobs.Observe(ctx, events)
 }
 
 func getNextEpochID(ctx context.Context, db *cltrdb.Queries) (epochid.EpochID, error) {
@@ -4,8 +4,22 @@ import (
 	"io"
 	"time"
 
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/batchposter"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/externaltrigger"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/inclusion"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/stream"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/txfilter"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
 	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/featureflag"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/httpauth"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/metricsserver"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/opapproval"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/plugin"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/tlsconfig"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/triggeroffset"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 )
 
@@ -21,6 +35,22 @@ func (c *Config) Init() {
 	c.P2P = p2p.NewConfig()
 	c.Ethereum = configuration.NewEthnodeConfig()
 	c.EpochDuration = &enctime.Duration{}
+	c.EpochPreAnnouncementLeadTime = &enctime.Duration{}
+	c.TriggerOffset = &enctime.Duration{}
+	c.L1BlockTime = &enctime.Duration{}
+	c.ProvenanceRetention = &enctime.Duration{}
+	c.HTTPAuth = httpauth.NewConfig()
+	c.OperatorApproval = opapproval.NewConfig()
+	c.HTTPTLS = tlsconfig.NewServerConfig()
+	c.SequencerTLS = tlsconfig.NewClientConfig()
+	c.BatchPosting = batchposter.NewConfig()
+	c.Features = featureflag.NewConfig()
+	c.ExternalTriggers = externaltrigger.NewConfig()
+	c.Plugins = plugin.NewConfig()
+	c.TxFilter = txfilter.NewConfig()
+	c.Stream = stream.NewConfig()
+	c.Inclusion = inclusion.NewConfig()
+	c.Metrics = metricsserver.NewConfig()
 }
 
 type Config struct {
@@ -28,18 +58,82 @@ type Config struct {
 	DatabaseURL string `shconfig:",required"`
 
 	HTTPListenAddress string
+	HTTPAuth          *httpauth.Config
+	HTTPTLS           *tlsconfig.ServerConfig
+	OperatorApproval  *opapproval.Config
 
 	SequencerURL                 string
+	SequencerTLS                 *tlsconfig.ClientConfig
 	EpochDuration                *enctime.Duration
+	EpochPreAnnouncementLeadTime *enctime.Duration // 0 disables pre-announcements
+	TriggerOffsetBlocks          uint64            // blocks to wait for after the epoch boundary
+	TriggerOffset                *enctime.Duration // time to wait for after the epoch boundary
+	L1BlockTime                  *enctime.Duration // expected time between L1 blocks, bounds TriggerOffsetBlocks
 	ExecutionBlockDelay          uint32
 	BatchIndexAcceptenceInterval uint32
+	BatchPosting                 *batchposter.Config
+	ProvenanceRetention          *enctime.Duration // 0 keeps the records of accepted p2p messages forever
 
-	P2P      *p2p.Config
-	Ethereum *configuration.EthnodeConfig
+	P2P              *p2p.Config
+	Ethereum         *configuration.EthnodeConfig
+	Features         *featureflag.Config
+	ExternalTriggers *externaltrigger.Config
+	Plugins          *plugin.Config
+	TxFilter         *txfilter.Config
+	Stream           *stream.Config
+	Inclusion        *inclusion.Config
+	Metrics          *metricsserver.MetricsConfig
 }
 
 func (c *Config) Validate() error {
-	return nil
+	if err := c.Ethereum.Validate(); err != nil {
+		return err
+	}
+	if err := c.HTTPAuth.Validate(); err != nil {
+		return err
+	}
+	if err := c.HTTPTLS.Validate(); err != nil {
+		return err
+	}
+	if err := c.OperatorApproval.Validate(); err != nil {
+		return err
+	}
+	if err := c.SequencerTLS.Validate(); err != nil {
+		return err
+	}
+	if err := c.BatchPosting.Validate(); err != nil {
+		return err
+	}
+	if err := c.ExternalTriggers.Validate(); err != nil {
+		return err
+	}
+	if err := c.Plugins.Validate(); err != nil {
+		return err
+	}
+	if err := c.TxFilter.Validate(); err != nil {
+		return err
+	}
+	if err := c.Stream.Validate(); err != nil {
+		return err
+	}
+	if err := c.Inclusion.Validate(); err != nil {
+		return err
+	}
+	if err := c.Metrics.Validate(); err != nil {
+		return err
+	}
+	if c.EpochPreAnnouncementLeadTime.Duration < 0 ||
+		c.EpochPreAnnouncementLeadTime.Duration >= c.EpochDuration.Duration {
+		return errors.Errorf(
+			"EpochPreAnnouncementLeadTime must be between 0 and EpochDuration (%s), got %s",
+			c.EpochDuration.Duration, c.EpochPreAnnouncementLeadTime.Duration,
+		)
+	}
+	offset := triggeroffset.Offset{Blocks: c.TriggerOffsetBlocks, Delay: c.TriggerOffset.Duration}
+	if err := offset.Validate(c.EpochDuration.Duration, c.L1BlockTime.Duration); err != nil {
+		return err
+	}
+	return c.Features.Validate()
 }
 
 func (c *Config) Name() string {
@@ -50,6 +144,16 @@ func (c *Config) SetDefaultValues() error {
 	c.EpochDuration = &enctime.Duration{
 		Duration: time.Second * 5,
 	}
+	c.EpochPreAnnouncementLeadTime = &enctime.Duration{
+		Duration: time.Second * 2,
+	}
+	c.TriggerOffset = &enctime.Duration{}
+	c.L1BlockTime = &enctime.Duration{
+		Duration: 12 * time.Second,
+	}
+	c.ProvenanceRetention = &enctime.Duration{
+		Duration: 30 * 24 * time.Hour,
+	}
 	c.SequencerURL = "http://127.0.0.1:8555/"
 	// default: the contracts are deployed on L2
 	c.Ethereum.ContractsURL = c.SequencerURL
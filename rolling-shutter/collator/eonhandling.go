@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
@@ -31,12 +32,27 @@ func ensureNoIntegerOverflowsInEonPublicKey(key *p2pmsg.EonPublicKey) error {
 	return nil
 }
 
+// recoverEonPublicKeySigner recovers the keyper that signed the EonPublicKey. Signatures in the
+// signing domain and legacy ones are both accepted, as the collator doesn't know if the keyper set
+// has switched to signing in the domain yet.
+func recoverEonPublicKeySigner(
+	keyperSet chainobsdb.KeyperSet,
+	key *p2pmsg.EonPublicKey,
+	domain p2pmsg.SigningDomain,
+) (common.Address, error) {
+	return p2pmsg.RecoverSigner(key, domain, true, func(address common.Address) bool {
+		_, ok := kprdb.GetKeyperIndex(address, keyperSet.Keypers)
+		return ok
+	})
+}
+
 // ensureEonPublicKeyMatchesKeyperSet checks that the information stored in the EonPublicKey
 // matches the chainobsdb.KeyperSet stored in the database. It returns an error if there is a
 // mismatch.
 func ensureEonPublicKeyMatchesKeyperSet(
 	keyperSet chainobsdb.KeyperSet,
 	key *p2pmsg.EonPublicKey,
+	domain p2pmsg.SigningDomain,
 ) error {
 	activationBlock := int64(key.ActivationBlock)
 
@@ -51,28 +67,20 @@ func ensureEonPublicKeyMatchesKeyperSet(
 		)
 	}
 
-	recoveredAddress, err := p2pmsg.RecoverAddress(key)
-	if err != nil {
+	if _, err := recoverEonPublicKeySigner(keyperSet, key, domain); err != nil {
 		return errors.Wrap(
 			err,
 			fmt.Sprintf("Validation: Error while recovering signature for EonPublicKey "+
 				"(activation-block=%d)", activationBlock),
 		)
 	}
-	_, ok := kprdb.GetKeyperIndex(recoveredAddress, keyperSet.Keypers)
-
-	if !ok {
-		return errors.Errorf(
-			"eonPublicKey's recovered address %s not found in on-chain addresses",
-			recoveredAddress.Hex(),
-		)
-	}
 	return nil
 }
 
 type eonPublicKeyHandler struct {
 	config *config.Config
 	dbpool *pgxpool.Pool
+	domain p2pmsg.SigningDomain
 }
 
 func (*eonPublicKeyHandler) MessagePrototypes() []p2pmsg.Message {
@@ -109,7 +117,7 @@ func (handler *eonPublicKeyHandler) ValidateMessage(
 		return false, errors.Wrap(err, "failed to retrieve keyper set from db")
 	}
 
-	if err := ensureEonPublicKeyMatchesKeyperSet(keyperSet, key); err != nil {
+	if err := ensureEonPublicKeyMatchesKeyperSet(keyperSet, key, handler.domain); err != nil {
 		return false, err
 	}
 	return true, nil
@@ -120,11 +128,7 @@ func (handler *eonPublicKeyHandler) HandleMessage(
 	k p2pmsg.Message,
 ) ([]p2pmsg.Message, error) {
 	key := k.(*p2pmsg.EonPublicKey)
-	recoveredAddress, err := p2pmsg.RecoverAddress(key)
-	if err != nil {
-		return make([]p2pmsg.Message, 0), err
-	}
-	err = handler.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+	err := handler.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
 		var err error
 
 		db := cltrdb.New(tx)
@@ -134,6 +138,10 @@ func (handler *eonPublicKeyHandler) HandleMessage(
 		if err != nil {
 			return errors.Wrap(err, "failed to retrieve keyper set from db")
 		}
+		recoveredAddress, err := recoverEonPublicKeySigner(keyperSet, key, handler.domain)
+		if err != nil {
+			return err
+		}
 		hash := key.Hash()
 		err = db.InsertEonPublicKeyCandidate(ctx, cltrdb.InsertEonPublicKeyCandidateParams{
 			Hash:                  hash,
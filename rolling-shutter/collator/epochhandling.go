@@ -14,10 +14,12 @@ import (
 	"github.com/shutter-network/shutter/shlib/shcrypto"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/batcher"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/batchhandler"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/config"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/triggeroffset"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 )
 
@@ -126,7 +128,24 @@ func (c *collator) sendDecryptionTriggers(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	for _, msg := range triggers {
+	groups := [][]*p2pmsg.DecryptionTrigger{}
+	if c.features.Enabled(FeatureTriggerBatching) {
+		groups = groupContiguousTriggers(triggers, p2pmsg.MaxDecryptionTriggerBatchSize)
+	} else {
+		for _, trigger := range triggers {
+			groups = append(groups, []*p2pmsg.DecryptionTrigger{trigger})
+		}
+	}
+	for _, group := range groups {
+		var msg p2pmsg.Message = group[0]
+		if len(group) > 1 {
+			msg, err = p2pmsg.NewSignedDecryptionTriggerBatch(
+				c.Config.InstanceID, group, c.Config.Ethereum.PrivateKey.Key,
+			)
+			if err != nil {
+				return err
+			}
+		}
 		err := c.p2p.SendMessage(ctx,
 			msg,
 			retry.Interval(time.Second),
@@ -138,24 +157,111 @@ func (c *collator) sendDecryptionTriggers(ctx context.Context) error {
 			continue // continue sending other messages
 		}
 		err = c.dbpool.BeginFunc(ctx, func(dbtx pgx.Tx) error {
-			return cltrdb.New(dbtx).UpdateDecryptionTriggerSent(ctx, msg.EpochID)
+			db := cltrdb.New(dbtx)
+			for _, trigger := range group {
+				if err := db.UpdateDecryptionTriggerSent(ctx, trigger.EpochID); err != nil {
+					return err
+				}
+				if err := c.enqueueIncludedBundle(ctx, dbtx, trigger); err != nil {
+					return err
+				}
+			}
+			return nil
 		})
 		if err != nil {
 			return err
 		}
-		log.Info().
-			Str("msg", msg.LogInfo()).
-			Str("commitment", hexutil.Encode(msg.TransactionsHash)).
-			Msg("sent decryption trigger")
+		for _, trigger := range group {
+			log.Info().
+				Str("msg", trigger.LogInfo()).
+				Str("commitment", hexutil.Encode(trigger.TransactionsHash)).
+				Int("batch-size", len(group)).
+				Msg("sent decryption trigger")
+		}
 	}
 	return nil
 }
 
+// groupContiguousTriggers splits the triggers, which are ordered by epoch, into groups of at most
+// maxSize triggers for contiguous epochs.
+func groupContiguousTriggers(triggers []*p2pmsg.DecryptionTrigger, maxSize int) [][]*p2pmsg.DecryptionTrigger {
+	groups := [][]*p2pmsg.DecryptionTrigger{}
+	var prev epochid.EpochID
+	for i, trigger := range triggers {
+		epochID, err := epochid.BytesToEpochID(trigger.EpochID)
+		contiguous := false
+		if err == nil && i > 0 {
+			next, err := batchhandler.ComputeNextEpochID(prev)
+			contiguous = err == nil && next == epochID
+		}
+		last := len(groups) - 1
+		if contiguous && len(groups[last]) < maxSize {
+			groups[last] = append(groups[last], trigger)
+		} else {
+			groups = append(groups, []*p2pmsg.DecryptionTrigger{trigger})
+		}
+		prev = epochID
+	}
+	return groups
+}
+
+// sendEpochPreAnnouncement announces the epoch of the current batch, which will be triggered when
+// the batch is closed at expectedTriggerTime.
+func (c *collator) sendEpochPreAnnouncement(ctx context.Context, expectedTriggerTime time.Time) error {
+	epochID, err := getNextEpochID(ctx, cltrdb.New(c.dbpool))
+	if err != nil {
+		return err
+	}
+	// The trigger is created for the L1 block that is the latest one when the batch is closed. We
+	// can only guess that block, the keypers take the deviation into account.
+	blockNumber, err := c.l1Client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	msg, err := p2pmsg.NewSignedEpochPreAnnouncement(
+		c.Config.InstanceID, epochID, blockNumber, expectedTriggerTime, c.Config.Ethereum.PrivateKey.Key,
+	)
+	if err != nil {
+		return err
+	}
+	return c.p2p.SendMessage(ctx, msg, retry.NumberOfRetries(1), retry.LogIdentifier(msg.LogInfo()))
+}
+
+// waitForTriggerOffset delays closing the batch of the epoch that ended at boundary until the
+// trigger offset has passed, so that its trigger refers to a block all keypers know.
+func (c *collator) waitForTriggerOffset(ctx context.Context, boundary time.Time, pollInterval time.Duration) error {
+	if c.triggerOffset.IsZero() {
+		return nil
+	}
+	boundaryBlock, err := c.l1Client.BlockNumber(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// without the boundary block, the offset can only be applied to the time
+		log.Warn().Err(err).Msg("failed to get block at epoch boundary")
+		offset := triggeroffset.Offset{Delay: c.triggerOffset.Delay}
+		_, err = offset.Wait(ctx, 0, boundary, c.l1Client.BlockNumber, pollInterval)
+		return err
+	}
+	blockNumber, err := c.triggerOffset.Wait(ctx, boundaryBlock, boundary, c.l1Client.BlockNumber, pollInterval)
+	if err != nil {
+		return err
+	}
+	log.Debug().
+		Uint64("boundary-block", boundaryBlock).
+		Uint64("block", blockNumber).
+		Msg("trigger offset passed")
+	return nil
+}
+
 // closeBatchesTicker constantly tries to close the current batch after `interval` duration.
 // Every time the `interval` has passed, closeBatchesTicker will first try to close the batch
 // until successful.
 // Then it will wait some time and try to initialize the chain state for the next batch
 // in order to validate queued up transactions early on in the batch life-cycle.
+// If configured, the epoch of the batch is pre-announced shortly before it is closed and the batch
+// is closed only once the trigger offset has passed after the tick.
 func (c *collator) closeBatchesTicker(ctx context.Context, interval time.Duration) error {
 	t := time.NewTicker(interval)
 	assumedBatchProcessingDuration := time.Second
@@ -163,9 +269,27 @@ func (c *collator) closeBatchesTicker(ctx context.Context, interval time.Duratio
 	if minRetryPollInterval > retryPollInterval {
 		retryPollInterval = minRetryPollInterval
 	}
+	leadTime := c.Config.EpochPreAnnouncementLeadTime.Duration
+	var preAnnounce <-chan time.Time
+	nextTick := time.Now().Add(interval)
+	schedulePreAnnouncement := func() {
+		if leadTime > 0 {
+			preAnnounce = time.After(time.Until(nextTick.Add(-leadTime)))
+		}
+	}
+	schedulePreAnnouncement()
 	for {
 		select {
-		case <-t.C:
+		case <-preAnnounce:
+			if err := c.sendEpochPreAnnouncement(ctx, nextTick); err != nil {
+				log.Warn().Err(err).Msg("failed to send epoch pre-announcement")
+			}
+		case tick := <-t.C:
+			nextTick = tick.Add(interval)
+			schedulePreAnnouncement()
+			if err := c.waitForTriggerOffset(ctx, tick, retryPollInterval); err != nil {
+				return err
+			}
 			fnCloseBatch := func(ctx context.Context) (struct{}, error) {
 				return struct{}{}, c.batcher.CloseBatch(ctx)
 			}
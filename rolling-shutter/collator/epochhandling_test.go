@@ -36,7 +36,7 @@ func TestHandleDecryptionTriggerIntegration(t *testing.T) {
 	// The reason the p2p.SendMessage works without configuration
 	// is because the handler has no rooms subscribed and thus will actually
 	// skip to forward the messages sent to the transport
-	p2pHandler, err := p2p.New(config.P2P)
+	p2pHandler, err := p2p.New(config.P2P, config.InstanceID)
 	assert.NilError(t, err)
 	c := collator{dbpool: dbpool, Config: config, p2p: p2pHandler}
 	var newDecryptionTriggerLoop shdb.SignalLoopFunc
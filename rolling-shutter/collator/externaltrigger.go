@@ -0,0 +1,86 @@
+package collator
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/externaltrigger"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+)
+
+// zeroBatchHash is the batch hash of triggers that don't belong to a batch.
+var zeroBatchHash = make([]byte, 32)
+
+// ExternalTrigger is the response of the external trigger webhook.
+type ExternalTrigger struct {
+	Requester     common.Address `json:"requester"`
+	EpochID       hexutil.Bytes  `json:"epochID"`
+	L1BlockNumber int64          `json:"l1BlockNumber"`
+	// Created is false if the epoch had already been triggered before.
+	Created bool `json:"created"`
+}
+
+// ExternalTrigger enqueues a decryption trigger for the identity of an external requester, see
+// package externaltrigger. Requesting the same identity again is a no-op.
+func (srv *server) ExternalTrigger(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req externaltrigger.SignedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, errcode.ErrInvalidRequest.Errorf("invalid trigger request"))
+		return
+	}
+	requester, epochID, err := srv.c.triggerPolicy.Check(&req, time.Now())
+	switch {
+	case errors.Is(err, externaltrigger.ErrNotAllowed):
+		sendError(w, errcode.ErrTriggerNotAllowed.Wrap(err))
+		return
+	case errors.Is(err, externaltrigger.ErrTooEarly):
+		sendError(w, errcode.ErrTriggerTooEarly.Wrap(err))
+		return
+	case err != nil:
+		sendError(w, errcode.ErrInvalidRequest.Wrap(err))
+		return
+	}
+	blockNumber, err := getBlockNumber(ctx, srv.c.l1Client)
+	if err != nil {
+		sendError(w, errcode.ErrInternal.Wrapf(err, "failed to get current block number"))
+		return
+	}
+
+	result := ExternalTrigger{Requester: requester, EpochID: epochID.Bytes()}
+	err = srv.c.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		db := cltrdb.New(tx)
+		trigger, err := db.GetTrigger(ctx, epochID.Bytes())
+		if err == nil {
+			result.L1BlockNumber = trigger.L1BlockNumber
+			return nil
+		} else if err != pgx.ErrNoRows {
+			return err
+		}
+		result.L1BlockNumber = int64(blockNumber)
+		result.Created = true
+		return db.InsertTrigger(ctx, cltrdb.InsertTriggerParams{
+			EpochID:       epochID.Bytes(),
+			BatchHash:     zeroBatchHash,
+			L1BlockNumber: result.L1BlockNumber,
+		})
+	})
+	if err != nil {
+		sendError(w, errcode.WrapDB(err, "failed to store decryption trigger"))
+		return
+	}
+	if result.Created {
+		log.Info().Str("requester", requester.Hex()).Str("epoch-id", epochID.Hex()).
+			Msg("enqueued external decryption trigger")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,62 @@
+package externaltrigger
+
+import (
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+// Config lists the external systems allowed to request decryption triggers.
+type Config struct {
+	Requesters []string `comment:"Ethereum addresses of the keys allowed to sign trigger requests, empty disables the webhook"`
+}
+
+func (c *Config) Init() {}
+
+func (c *Config) Name() string {
+	return "externaltriggers"
+}
+
+// Enabled reports whether the webhook accepts requests.
+func (c *Config) Enabled() bool {
+	return len(c.Requesters) > 0
+}
+
+func (c *Config) Validate() error {
+	seen := map[common.Address]bool{}
+	for _, requester := range c.Requesters {
+		if !common.IsHexAddress(requester) {
+			return errors.Errorf("invalid requester address %q", requester)
+		}
+		address := common.HexToAddress(requester)
+		if seen[address] {
+			return errors.Errorf("requester %s configured multiple times", address.Hex())
+		}
+		seen[address] = true
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.Requesters = []string{}
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
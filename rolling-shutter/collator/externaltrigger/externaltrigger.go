@@ -0,0 +1,115 @@
+// Package externaltrigger lets external systems, e.g. sealed-bid auctions or private votes, request
+// the decryption key of an identity of their own without a custom integration. A request is signed
+// by one of the configured requester keys and names an identity and the earliest time the key may
+// be released. The identity is mapped to an epoch id in the requester's own namespace, so that a
+// requester can neither trigger the epochs of the rollup nor those of other requesters.
+package externaltrigger
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+var (
+	// ErrNotAllowed is returned for requests that aren't signed by a configured requester.
+	ErrNotAllowed = errors.New("requester not allowed to trigger decryption")
+	// ErrTooEarly is returned for requests whose identity must not be decrypted yet.
+	ErrTooEarly = errors.New("decryption requested too early")
+)
+
+// maxIdentitySize is the maximum size of an identity in bytes.
+const maxIdentitySize = 1024
+
+var (
+	hashPrefix    = []byte("\x19Shutter external trigger:\n")
+	epochIDPrefix = []byte("shutter-external-trigger")
+)
+
+// Request asks for the decryption key of an identity to be released not before NotBefore, a unix
+// timestamp.
+type Request struct {
+	InstanceID uint64        `json:"instanceID"`
+	Identity   hexutil.Bytes `json:"identity"`
+	NotBefore  int64         `json:"notBefore"`
+}
+
+// SignedRequest is the body of a request to the webhook.
+type SignedRequest struct {
+	Request   Request       `json:"request"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// Hash returns the hash requesters sign. It covers the JSON encoding of the request.
+func (r *Request) Hash() []byte {
+	payload, err := json.Marshal(r)
+	if err != nil {
+		panic(err) // a Request can always be encoded
+	}
+	return ethcrypto.Keccak256(hashPrefix, payload)
+}
+
+// Sign signs the request with the key of a requester.
+func Sign(r *Request, key *ecdsa.PrivateKey) (*SignedRequest, error) {
+	signature, err := ethcrypto.Sign(r.Hash(), key)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedRequest{Request: *r, Signature: signature}, nil
+}
+
+// EpochID returns the epoch id the decryption key of an identity of the requester is released for.
+// Messages for the identity have to be encrypted for this epoch id.
+func EpochID(requester common.Address, identity []byte) epochid.EpochID {
+	return epochid.EpochID(common.BytesToHash(ethcrypto.Keccak256(epochIDPrefix, requester.Bytes(), identity)))
+}
+
+// Policy checks trigger requests against the config.
+type Policy struct {
+	instanceID uint64
+	requesters map[common.Address]bool
+}
+
+// NewPolicy creates a policy for a validated config.
+func NewPolicy(config *Config, instanceID uint64) *Policy {
+	p := &Policy{
+		instanceID: instanceID,
+		requesters: make(map[common.Address]bool),
+	}
+	for _, requester := range config.Requesters {
+		p.requesters[common.HexToAddress(requester)] = true
+	}
+	return p
+}
+
+// Check verifies the request and returns the requester and the epoch id to trigger.
+func (p *Policy) Check(req *SignedRequest, now time.Time) (common.Address, epochid.EpochID, error) {
+	if req.Request.InstanceID != p.instanceID {
+		return common.Address{}, epochid.EpochID{}, errors.Wrapf(ErrNotAllowed,
+			"instance ID mismatch (want=%d, have=%d)", p.instanceID, req.Request.InstanceID)
+	}
+	if len(req.Request.Identity) == 0 || len(req.Request.Identity) > maxIdentitySize {
+		return common.Address{}, epochid.EpochID{}, errors.Errorf(
+			"identity must be between 1 and %d bytes, got %d", maxIdentitySize, len(req.Request.Identity))
+	}
+	pubkey, err := ethcrypto.SigToPub(req.Request.Hash(), req.Signature)
+	if err != nil {
+		return common.Address{}, epochid.EpochID{}, errors.Wrap(ErrNotAllowed, "invalid signature")
+	}
+	requester := ethcrypto.PubkeyToAddress(*pubkey)
+	if !p.requesters[requester] {
+		return common.Address{}, epochid.EpochID{}, errors.Wrapf(ErrNotAllowed, "unknown requester %s", requester.Hex())
+	}
+	if now.Unix() < req.Request.NotBefore {
+		return common.Address{}, epochid.EpochID{}, errors.Wrapf(ErrTooEarly,
+			"identity may be decrypted from %s on", time.Unix(req.Request.NotBefore, 0).UTC())
+	}
+	return requester, EpochID(requester, req.Request.Identity), nil
+}
@@ -0,0 +1,76 @@
+package externaltrigger
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+)
+
+func TestPolicy(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	otherKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	requester := ethcrypto.PubkeyToAddress(key.PublicKey)
+
+	config := NewConfig()
+	config.Requesters = []string{requester.Hex()}
+	assert.NilError(t, config.Validate())
+	policy := NewPolicy(config, 42)
+	now := time.Unix(1700000000, 0)
+
+	request := &Request{InstanceID: 42, Identity: []byte("auction-1"), NotBefore: now.Unix()}
+	signed, err := Sign(request, key)
+	assert.NilError(t, err)
+	signer, epochID, err := policy.Check(signed, now)
+	assert.NilError(t, err)
+	assert.Equal(t, signer, requester)
+	assert.Equal(t, epochID, EpochID(requester, []byte("auction-1")))
+
+	_, _, err = policy.Check(signed, now.Add(-time.Second))
+	assert.Assert(t, errors.Is(err, ErrTooEarly))
+
+	tampered := *signed
+	tampered.Request.Identity = []byte("auction-2")
+	_, _, err = policy.Check(&tampered, now)
+	assert.Assert(t, errors.Is(err, ErrNotAllowed))
+
+	unknown, err := Sign(request, otherKey)
+	assert.NilError(t, err)
+	_, _, err = policy.Check(unknown, now)
+	assert.Assert(t, errors.Is(err, ErrNotAllowed))
+
+	otherInstance, err := Sign(&Request{InstanceID: 1, Identity: []byte("auction-1")}, key)
+	assert.NilError(t, err)
+	_, _, err = policy.Check(otherInstance, now)
+	assert.Assert(t, errors.Is(err, ErrNotAllowed))
+}
+
+func TestEpochIDNamespace(t *testing.T) {
+	a := ethcrypto.PubkeyToAddress(mustKey(t).PublicKey)
+	b := ethcrypto.PubkeyToAddress(mustKey(t).PublicKey)
+	assert.Assert(t, EpochID(a, []byte("x")) != EpochID(b, []byte("x")))
+	assert.Assert(t, EpochID(a, []byte("x")) != EpochID(a, []byte("y")))
+}
+
+func TestConfigValidate(t *testing.T) {
+	config := NewConfig()
+	assert.NilError(t, config.Validate())
+	assert.Assert(t, !config.Enabled())
+	config.Requesters = []string{"0x123"}
+	assert.ErrorContains(t, config.Validate(), "invalid requester")
+	address := ethcrypto.PubkeyToAddress(mustKey(t).PublicKey).Hex()
+	config.Requesters = []string{address, address}
+	assert.ErrorContains(t, config.Validate(), "multiple times")
+}
+
+func mustKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	return key
+}
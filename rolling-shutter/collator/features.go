@@ -0,0 +1,25 @@
+package collator
+
+import (
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/batchposter"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/featureflag"
+)
+
+// FeatureTriggerBatching makes the collator send the decryption triggers of contiguous epochs that
+// are pending at the same time as a single batch. All keypers must be able to handle batches before
+// it's enabled.
+const FeatureTriggerBatching = "trigger-batching"
+
+// Features are the feature flags of the collator.
+var Features = []featureflag.Flag{
+	{
+		Name:        batchposter.FeatureBlobPosting,
+		Description: "Post batches as blobs if the blob method is configured, otherwise as calldata",
+		Default:     false,
+	},
+	{
+		Name:        FeatureTriggerBatching,
+		Description: "Send the decryption triggers of contiguous epochs as a single signed batch",
+		Default:     false,
+	},
+}
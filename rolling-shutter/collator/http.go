@@ -12,19 +12,29 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/batchhandler"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/oapi"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
 )
 
 type server struct {
 	c *collator
 }
 
-func sendError(w http.ResponseWriter, code int, message string) {
+// sendError responds with the HTTP status and error code of err's class, see errcode.Of.
+func sendError(w http.ResponseWriter, err error) {
+	class := errcode.Of(err)
+	logEvent := log.Debug()
+	if class.HTTPStatus >= http.StatusInternalServerError {
+		logEvent = log.Warn()
+	}
+	logEvent.Err(err).Str("error-code", string(class.Code)).Msg("collator api request failed")
+
 	e := oapi.Error{
-		Code:    int32(code),
-		Message: message,
+		Code:      int32(class.HTTPStatus),
+		ErrorCode: string(class.Code),
+		Message:   err.Error(),
 	}
-	w.WriteHeader(code)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(class.HTTPStatus)
 
 	_ = json.NewEncoder(w).Encode(e)
 }
@@ -37,7 +47,8 @@ func (srv *server) GetNextEpoch(w http.ResponseWriter, req *http.Request) {
 	db := cltrdb.New(srv.c.dbpool)
 	epoch, _, err := batchhandler.GetNextBatch(req.Context(), db)
 	if err != nil {
-		sendError(w, http.StatusInternalServerError, err.Error())
+		sendError(w, errcode.WrapDB(err, "failed to get next batch from db"))
+		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(oapi.NextEpoch{
@@ -49,7 +60,7 @@ func (srv *server) SubmitTransaction(w http.ResponseWriter, r *http.Request) {
 	// FIXME undefined
 	var x oapi.SubmitTransactionJSONBody
 	if err := json.NewDecoder(r.Body).Decode(&x); err != nil {
-		sendError(w, http.StatusBadRequest, "Invalid format for SubmitTransaction")
+		sendError(w, errcode.ErrInvalidRequest.Errorf("Invalid format for SubmitTransaction"))
 		return
 	}
 	ctx := r.Context()
@@ -73,7 +84,7 @@ func (srv *server) SubmitTransaction(w http.ResponseWriter, r *http.Request) {
 	err := srv.c.batcher.EnqueueTx(ctx, x.EncryptedTx)
 	if err != nil {
 		log.Error().Err(err).Msg("Error in SubmitTransaction")
-		sendError(w, http.StatusConflict, err.Error())
+		sendError(w, errcode.ErrTxRejected.Wrap(err))
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -108,7 +119,7 @@ func (srv *server) GetEonPublicKey(
 		return nil
 	})
 	if err != nil {
-		sendError(w, http.StatusInternalServerError, err.Error())
+		sendError(w, errcode.WrapDB(err, "failed to get eon public key from db"))
 		return
 	}
 	if len(votes) == 0 {
@@ -0,0 +1,170 @@
+package collator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v4"
+	"github.com/rs/zerolog/log"
+	txtypes "github.com/shutter-network/txtypes/types"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/inclusion"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// enqueueIncludedBundle enqueues the inclusion proof of the batch the trigger has been sent for.
+// Bundles that can't be built are logged and skipped, so that they don't hold up marking the
+// trigger as sent.
+func (c *collator) enqueueIncludedBundle(ctx context.Context, dbtx pgx.Tx, trigger *p2pmsg.DecryptionTrigger) error {
+	if !c.Config.Inclusion.Enabled() {
+		return nil
+	}
+	txs, err := cltrdb.New(dbtx).GetCommittedTransactionsByEpoch(ctx, trigger.EpochID)
+	if err != nil {
+		return err
+	}
+	bundle, err := inclusion.Included(trigger, txs, c.Config.Ethereum.PrivateKey.EthereumAddress())
+	if err != nil {
+		log.Warn().Err(err).Str("msg", trigger.LogInfo()).Msg("skipping inclusion proof")
+		return nil
+	}
+	return inclusion.Enqueue(ctx, dbtx, c.Config.Inclusion, bundle)
+}
+
+// enqueueDecryptedBundle enqueues the inclusion proof of the decrypted batch of the given epoch,
+// with the payloads of its shutter transactions decrypted.
+func (submitter *Submitter) enqueueDecryptedBundle(
+	ctx context.Context,
+	dbtx pgx.Tx,
+	epoch epochid.EpochID,
+	decryptionKey []byte,
+	txs []cltrdb.Transaction,
+	batchTx []byte,
+) error {
+	if !submitter.inclusion.Enabled() {
+		return nil
+	}
+	collator := ethcrypto.PubkeyToAddress(submitter.privKey.PublicKey)
+	bundle, err := inclusion.NewBundle(inclusion.StageDecrypted, epoch.Bytes(), txs, collator)
+	if err != nil {
+		return err
+	}
+	bundle.DecryptionKey = decryptionKey
+	bundle.BatchTx = batchTx
+
+	epochSecretKey := new(shcrypto.EpochSecretKey)
+	if err := epochSecretKey.GobDecode(decryptionKey); err != nil {
+		log.Warn().Err(err).Str("epoch-id", epoch.Hex()).Msg("skipping inclusion proof: invalid decryption key")
+		return nil
+	}
+	for i, t := range txs {
+		payload, err := decryptTransaction(t.TxBytes, epochSecretKey)
+		if err != nil {
+			bundle.Transactions[i].DecryptionError = err.Error()
+			continue
+		}
+		bundle.Transactions[i].DecryptedPayload = payload
+	}
+	return inclusion.Enqueue(ctx, dbtx, submitter.inclusion, bundle)
+}
+
+// decryptTransaction returns the decrypted payload of a shutter transaction. It returns nil for
+// other transactions.
+func decryptTransaction(txBytes []byte, epochSecretKey *shcrypto.EpochSecretKey) ([]byte, error) {
+	tx := new(txtypes.Transaction)
+	if err := tx.UnmarshalBinary(txBytes); err != nil {
+		return nil, err
+	}
+	if tx.Type() != txtypes.ShutterTxType {
+		return nil, nil
+	}
+	return decryptPayload(tx.EncryptedPayload(), epochSecretKey)
+}
+
+// inclusionProof returns the proof of the inclusion of the transaction with the given hash in
+// its batch. The errors carry the code to respond with.
+func (c *collator) inclusionProof(ctx context.Context, txHash []byte) (*inclusion.Proof, error) {
+	db := cltrdb.New(c.dbpool)
+	tx, err := db.GetTransaction(ctx, txHash)
+	if err == pgx.ErrNoRows {
+		return nil, errcode.ErrTxNotFound.Errorf("unknown transaction %s", hexutil.Encode(txHash))
+	} else if err != nil {
+		return nil, errcode.WrapDB(err, "failed to get transaction from db")
+	}
+	if tx.Status != cltrdb.TxstatusCommitted {
+		return nil, errcode.ErrTxNotFound.Errorf("transaction %s is not part of a batch", hexutil.Encode(txHash))
+	}
+	epoch, err := epochid.BytesToEpochID(tx.EpochID)
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+	trigger, err := db.GetTrigger(ctx, epoch.Bytes())
+	if err == pgx.ErrNoRows || (err == nil && !trigger.Sent.Valid) {
+		return nil, errcode.ErrTxNotFound.Errorf("the batch of transaction %s has not been triggered yet",
+			hexutil.Encode(txHash))
+	} else if err != nil {
+		return nil, errcode.WrapDB(err, "failed to get decryption trigger from db")
+	}
+	txs, err := db.GetCommittedTransactionsByEpoch(ctx, epoch.Bytes())
+	if err != nil {
+		return nil, errcode.WrapDB(err, "failed to get transactions from db")
+	}
+	// the signature is deterministic, so this is the trigger that has been sent
+	trigMsg, err := p2pmsg.NewSignedDecryptionTrigger(
+		c.Config.InstanceID,
+		epoch,
+		uint64(trigger.L1BlockNumber),
+		trigger.BatchHash,
+		c.Config.Ethereum.PrivateKey.Key,
+	)
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+	proof, err := inclusion.NewProof(trigMsg, txs, txHash, c.Config.Ethereum.PrivateKey.EthereumAddress())
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	decryptionKey, err := db.GetDecryptionKey(ctx, epoch.Bytes())
+	if err == pgx.ErrNoRows {
+		return proof, nil
+	} else if err != nil {
+		return nil, errcode.WrapDB(err, "failed to get decryption key from db")
+	}
+	proof.DecryptionKey = decryptionKey.DecryptionKey
+	epochSecretKey := new(shcrypto.EpochSecretKey)
+	if err := epochSecretKey.GobDecode(decryptionKey.DecryptionKey); err != nil {
+		return nil, errcode.ErrInternal.Wrapf(err, "invalid decryption key for epoch %s", epoch.Hex())
+	}
+	proof.DecryptedPayload, err = decryptTransaction(tx.TxBytes, epochSecretKey)
+	if err != nil {
+		proof.DecryptionError = err.Error()
+	}
+	return proof, nil
+}
+
+// InclusionProof responds with the proof of the inclusion of a transaction in its batch, so that
+// light clients can verify it without fetching the whole batch, see inclusion.Proof.
+func (srv *server) InclusionProof(w http.ResponseWriter, r *http.Request) {
+	txHash, err := hexutil.Decode(chi.URLParam(r, "txHash"))
+	if err != nil {
+		sendError(w, errcode.ErrInvalidRequest.Wrapf(err, "invalid transaction hash"))
+		return
+	}
+	proof, err := srv.c.inclusionProof(r.Context(), txHash)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(proof)
+}
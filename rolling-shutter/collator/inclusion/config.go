@@ -0,0 +1,72 @@
+package inclusion
+
+import (
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+// Config selects where inclusion proofs are pushed to.
+type Config struct {
+	WebhookURL string            `comment:"Inclusion proofs of batches are POSTed as JSON to this URL, empty disables them"`
+	Timeout    *enctime.Duration `comment:"Timeout of a single webhook request"`
+	WebSocket  bool              `comment:"Push inclusion proofs of batches to clients subscribed at /inclusion-proofs/ws"`
+}
+
+func (c *Config) Init() {
+	c.Timeout = &enctime.Duration{}
+}
+
+func (c *Config) Name() string {
+	return "inclusion"
+}
+
+// Enabled reports whether inclusion proofs are pushed.
+func (c *Config) Enabled() bool {
+	return c.WebhookURL != "" || c.WebSocket
+}
+
+func (c *Config) Validate() error {
+	if c.WebhookURL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.WebhookURL)
+	if err != nil {
+		return errors.Wrap(err, "invalid inclusion webhook URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.Errorf("inclusion webhook URL must use http or https, got %q", u.Scheme)
+	}
+	if c.Timeout.Duration <= 0 {
+		return errors.New("Timeout of inclusion webhook must be positive")
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.WebhookURL = ""
+	c.Timeout = &enctime.Duration{Duration: 10 * time.Second}
+	c.WebSocket = false
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
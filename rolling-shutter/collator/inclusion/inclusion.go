@@ -0,0 +1,180 @@
+// Package inclusion pushes proofs of the inclusion of transactions in batches to a webhook and to
+// WebSocket subscribers, so that wallets integrating the collator can show their users a
+// verifiable inclusion status.
+//
+// A bundle is pushed twice for every batch. When the decryption trigger of the batch is sent, the
+// included bundle lists the hashes of the transactions in their order in the batch, together with
+// the collator's signature of the trigger, which commits to the Merkle root over that list. Once
+// the batch has been decrypted, the decrypted bundle adds the decrypted payloads, the decryption
+// key, which doubles as the keypers' threshold signature of the epoch ID, and the batch
+// transaction signed by the collator.
+//
+// Bundles are delivered by jobs enqueued in the database transaction of the state change they
+// result from, so they are pushed even if the collator restarts in between.
+//
+// Clients that only care about a single transaction can fetch its Proof from the collator's
+// /inclusion-proofs/{txHash} endpoint instead.
+package inclusion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/jobdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/jobqueue"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+const (
+	// webhookJobKind is the kind of the jobs delivering bundles to the webhook.
+	webhookJobKind = "inclusion-webhook"
+	// websocketJobKind is the kind of the jobs pushing bundles to the WebSocket subscribers.
+	websocketJobKind = "inclusion-websocket"
+)
+
+// Stage tells how far the batch of a bundle has been processed.
+type Stage string
+
+const (
+	// StageIncluded is pushed when the decryption trigger of the batch has been sent.
+	StageIncluded Stage = "included"
+	// StageDecrypted is pushed when the batch has been decrypted and its batch transaction signed.
+	StageDecrypted Stage = "decrypted"
+)
+
+// Transaction is the part of a bundle concerning a single transaction.
+type Transaction struct {
+	TxHash hexutil.Bytes `json:"txHash"`
+	// Position is the index of the transaction in the batch.
+	Position int `json:"position"`
+	// DecryptedPayload is the decrypted payload of a shutter transaction. It is only set in the
+	// decrypted stage.
+	DecryptedPayload hexutil.Bytes `json:"decryptedPayload,omitempty"`
+	DecryptionError  string        `json:"decryptionError,omitempty"`
+}
+
+// Bundle is the proof of inclusion of the transactions of a batch.
+type Bundle struct {
+	Stage      Stage         `json:"stage"`
+	EpochID    hexutil.Bytes `json:"epochID"`
+	BatchIndex uint64        `json:"batchIndex"`
+	// BatchHash is the Merkle root over the hashes of the transactions in order, see
+	// p2pmsg.MerkleRoot.
+	BatchHash hexutil.Bytes  `json:"batchHash"`
+	Collator  common.Address `json:"collator"`
+	// L1BlockNumber and TriggerSignature are taken from the decryption trigger of the batch. They
+	// are only set in the included stage.
+	L1BlockNumber    uint64        `json:"l1BlockNumber,omitempty"`
+	TriggerSignature hexutil.Bytes `json:"triggerSignature,omitempty"`
+	// DecryptionKey and BatchTx are only set in the decrypted stage.
+	DecryptionKey hexutil.Bytes `json:"decryptionKey,omitempty"`
+	BatchTx       hexutil.Bytes `json:"batchTx,omitempty"`
+	Transactions  []Transaction `json:"transactions"`
+}
+
+// NewBundle returns the bundle of the given stage for the batch of the given epoch, with the
+// fields common to both stages filled in. txs are the committed transactions of the batch in
+// order.
+func NewBundle(stage Stage, epochID []byte, txs []cltrdb.Transaction, collator common.Address) (*Bundle, error) {
+	epoch, err := epochid.BytesToEpochID(epochID)
+	if err != nil {
+		return nil, err
+	}
+	txHashes := make([][]byte, len(txs))
+	transactions := make([]Transaction, len(txs))
+	for i, tx := range txs {
+		txHashes[i] = tx.TxHash
+		transactions[i] = Transaction{TxHash: tx.TxHash, Position: i}
+	}
+	return &Bundle{
+		Stage:        stage,
+		EpochID:      epoch.Bytes(),
+		BatchIndex:   epoch.Uint64(),
+		BatchHash:    p2pmsg.MerkleRoot(txHashes),
+		Collator:     collator,
+		Transactions: transactions,
+	}, nil
+}
+
+// Included returns the bundle of the included stage for the batch the trigger has been sent for.
+func Included(
+	trigger *p2pmsg.DecryptionTrigger, txs []cltrdb.Transaction, collator common.Address,
+) (*Bundle, error) {
+	bundle, err := NewBundle(StageIncluded, trigger.EpochID, txs, collator)
+	if err != nil {
+		return nil, err
+	}
+	txHashes := make([][]byte, len(txs))
+	for i, tx := range txs {
+		txHashes[i] = tx.TxHash
+	}
+	if err := trigger.VerifyBatch(txHashes); err != nil {
+		return nil, errors.Wrapf(err, "transactions of epoch %s don't match its trigger", bundle.EpochID)
+	}
+	bundle.L1BlockNumber = trigger.BlockNumber
+	bundle.TriggerSignature = trigger.Signature
+	return bundle, nil
+}
+
+// Enqueue enqueues the delivery of the bundle to the webhook and the WebSocket subscribers, if
+// enabled. Pass the transaction of the state change the bundle results from as db. The deliveries
+// are separate jobs, so that a failing webhook doesn't hold up the subscribers.
+func Enqueue(ctx context.Context, db jobdb.DBTX, config *Config, bundle *Bundle) error {
+	if config.WebhookURL != "" {
+		if err := jobqueue.Enqueue(ctx, db, webhookJobKind, bundle); err != nil {
+			return err
+		}
+	}
+	if config.WebSocket {
+		return jobqueue.Enqueue(ctx, db, websocketJobKind, bundle)
+	}
+	return nil
+}
+
+// Register registers the handlers delivering the enqueued bundles to the webhook and to the
+// subscribers of hub with the queue.
+func Register(queue *jobqueue.Queue, config *Config, hub *Hub) {
+	client := &http.Client{Timeout: config.Timeout.Duration}
+	queue.Register(webhookJobKind, func(ctx context.Context, payload []byte) error {
+		return post(ctx, client, config.WebhookURL, payload)
+	})
+	queue.Register(websocketJobKind, func(_ context.Context, payload []byte) error {
+		if err := hub.Publish(payload); err != nil {
+			return jobqueue.Permanent(err)
+		}
+		return nil
+	})
+}
+
+// post sends the JSON encoded bundle to the webhook.
+func post(ctx context.Context, client *http.Client, url string, payload []byte) error {
+	if !json.Valid(payload) {
+		return jobqueue.Permanent(errors.New("invalid inclusion bundle"))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return errors.Wrap(err, "failed to create inclusion webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send inclusion bundle to webhook")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := errors.Errorf("inclusion webhook responded with status %s", resp.Status)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return jobqueue.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
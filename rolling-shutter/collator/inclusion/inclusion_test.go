@@ -0,0 +1,159 @@
+package inclusion
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/websocket"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+func TestIncluded(t *testing.T) {
+	txs := []cltrdb.Transaction{{TxHash: []byte{1}}, {TxHash: []byte{2}}}
+	collator := common.HexToAddress("0x1")
+	trigger := &p2pmsg.DecryptionTrigger{
+		EpochID:          epochid.Uint64ToEpochID(5).Bytes(),
+		BlockNumber:      42,
+		TransactionsHash: p2pmsg.MerkleRoot([][]byte{{1}, {2}}),
+		Signature:        []byte{3},
+	}
+
+	bundle, err := Included(trigger, txs, collator)
+	assert.NilError(t, err)
+	assert.Equal(t, bundle.Stage, StageIncluded)
+	assert.Equal(t, bundle.BatchIndex, uint64(5))
+	assert.DeepEqual(t, []byte(bundle.BatchHash), trigger.TransactionsHash)
+	assert.Equal(t, bundle.L1BlockNumber, uint64(42))
+	assert.DeepEqual(t, []byte(bundle.TriggerSignature), []byte{3})
+	assert.Equal(t, bundle.Collator, collator)
+	assert.Equal(t, len(bundle.Transactions), 2)
+	for i, tx := range bundle.Transactions {
+		assert.Equal(t, tx.Position, i)
+		assert.DeepEqual(t, []byte(tx.TxHash), txs[i].TxHash)
+	}
+
+	// the order of the transactions is part of the batch hash
+	_, err = Included(trigger, []cltrdb.Transaction{txs[1], txs[0]}, collator)
+	assert.ErrorContains(t, err, "don't match its trigger")
+}
+
+func TestProof(t *testing.T) {
+	privKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	collator := ethcrypto.PubkeyToAddress(privKey.PublicKey)
+	txs := []cltrdb.Transaction{}
+	txHashes := [][]byte{}
+	for i := byte(0); i < 5; i++ {
+		txs = append(txs, cltrdb.Transaction{TxHash: []byte{i}})
+		txHashes = append(txHashes, []byte{i})
+	}
+	trigger, err := p2pmsg.NewSignedDecryptionTrigger(1, epochid.Uint64ToEpochID(5), 42, p2pmsg.MerkleRoot(txHashes), privKey)
+	assert.NilError(t, err)
+
+	proof, err := NewProof(trigger, txs, []byte{3}, collator)
+	assert.NilError(t, err)
+	assert.Equal(t, proof.Position, 3)
+	assert.Equal(t, proof.NumTxs, 5)
+	assert.Equal(t, proof.BatchIndex, uint64(5))
+	assert.NilError(t, proof.Verify())
+
+	// the proof survives the round trip through the API
+	encoded, err := json.Marshal(proof)
+	assert.NilError(t, err)
+	decoded := &Proof{}
+	assert.NilError(t, json.Unmarshal(encoded, decoded))
+	assert.NilError(t, decoded.Verify())
+
+	decoded.Position = 2
+	assert.ErrorContains(t, decoded.Verify(), "not at position 2")
+	decoded.Position = 3
+	decoded.Collator = common.HexToAddress("0x1")
+	assert.ErrorContains(t, decoded.Verify(), "not signed by collator")
+
+	_, err = NewProof(trigger, txs, []byte{7}, collator)
+	assert.ErrorContains(t, err, "not part of the batch")
+	_, err = NewProof(trigger, txs[:4], []byte{3}, collator)
+	assert.ErrorContains(t, err, "don't match its trigger")
+}
+
+func TestPost(t *testing.T) {
+	status := http.StatusOK
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	payload, err := json.Marshal(&Bundle{Stage: StageDecrypted, BatchIndex: 5})
+	assert.NilError(t, err)
+	assert.NilError(t, post(ctx, server.Client(), server.URL, payload))
+	bundle := Bundle{}
+	assert.NilError(t, json.Unmarshal(received, &bundle))
+	assert.Equal(t, bundle.Stage, StageDecrypted)
+	assert.Equal(t, bundle.BatchIndex, uint64(5))
+
+	status = http.StatusServiceUnavailable
+	assert.ErrorContains(t, post(ctx, server.Client(), server.URL, payload), "503")
+	assert.ErrorContains(t, post(ctx, server.Client(), server.URL, []byte("{")), "invalid inclusion bundle")
+}
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+	server := httptest.NewServer(hub)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	all, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NilError(t, err)
+	defer all.Close()
+	filtered, _, err := websocket.DefaultDialer.Dial(url+"?txHash=0x02", nil)
+	assert.NilError(t, err)
+	defer filtered.Close()
+	resp, err := http.Get(server.URL + "?txHash=invalid")
+	assert.NilError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+
+	// wait for both subscriptions to be registered
+	for {
+		hub.mux.Lock()
+		n := len(hub.subscribers)
+		hub.mux.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	first, err := json.Marshal(&Bundle{Stage: StageIncluded, BatchIndex: 1, Transactions: []Transaction{{TxHash: []byte{1}}}})
+	assert.NilError(t, err)
+	second, err := json.Marshal(&Bundle{Stage: StageIncluded, BatchIndex: 2, Transactions: []Transaction{{TxHash: []byte{2}}}})
+	assert.NilError(t, err)
+	assert.NilError(t, hub.Publish(first))
+	assert.NilError(t, hub.Publish(second))
+	assert.ErrorContains(t, hub.Publish([]byte("{")), "invalid inclusion bundle")
+
+	receive := func(conn *websocket.Conn) uint64 {
+		assert.NilError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		bundle := Bundle{}
+		assert.NilError(t, conn.ReadJSON(&bundle))
+		return bundle.BatchIndex
+	}
+	assert.Equal(t, receive(all), uint64(1))
+	assert.Equal(t, receive(all), uint64(2))
+	assert.Equal(t, receive(filtered), uint64(2))
+}
@@ -0,0 +1,103 @@
+package inclusion
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// Proof is the proof that a single transaction is part of a batch. Unlike a bundle, it doesn't
+// list the other transactions of the batch: the Merkle proof links the transaction to the batch
+// hash, which is signed by the collator in its decryption trigger. Once the batch has been
+// decrypted, the decryption key, i.e. the keypers' threshold signature of the epoch ID, is added.
+type Proof struct {
+	InstanceID       uint64          `json:"instanceID"`
+	EpochID          hexutil.Bytes   `json:"epochID"`
+	BatchIndex       uint64          `json:"batchIndex"`
+	BatchHash        hexutil.Bytes   `json:"batchHash"`
+	Collator         common.Address  `json:"collator"`
+	L1BlockNumber    uint64          `json:"l1BlockNumber"`
+	TriggerSignature hexutil.Bytes   `json:"triggerSignature"`
+	TxHash           hexutil.Bytes   `json:"txHash"`
+	Position         int             `json:"position"`
+	NumTxs           int             `json:"numTxs"`
+	MerkleProof      []hexutil.Bytes `json:"merkleProof"`
+	// DecryptionKey and DecryptedPayload are only set once the batch has been decrypted.
+	// DecryptedPayload is only set for shutter transactions.
+	DecryptionKey    hexutil.Bytes `json:"decryptionKey,omitempty"`
+	DecryptedPayload hexutil.Bytes `json:"decryptedPayload,omitempty"`
+	DecryptionError  string        `json:"decryptionError,omitempty"`
+}
+
+// NewProof returns the proof of the inclusion of the transaction with the given hash in the batch
+// the trigger has been sent for. txs are the committed transactions of the batch in order.
+func NewProof(
+	trigger *p2pmsg.DecryptionTrigger, txs []cltrdb.Transaction, txHash []byte, collator common.Address,
+) (*Proof, error) {
+	epoch, err := epochid.BytesToEpochID(trigger.EpochID)
+	if err != nil {
+		return nil, err
+	}
+	txHashes := make([][]byte, len(txs))
+	position := -1
+	for i, tx := range txs {
+		txHashes[i] = tx.TxHash
+		if bytes.Equal(tx.TxHash, txHash) {
+			position = i
+		}
+	}
+	if position == -1 {
+		return nil, errors.Errorf("transaction %s is not part of the batch of epoch %s",
+			hexutil.Encode(txHash), epoch.Hex())
+	}
+	if err := trigger.VerifyBatch(txHashes); err != nil {
+		return nil, errors.Wrapf(err, "transactions of epoch %s don't match its trigger", epoch.Hex())
+	}
+	merkleProof := []hexutil.Bytes{}
+	for _, node := range p2pmsg.MerkleProof(txHashes, position) {
+		merkleProof = append(merkleProof, node)
+	}
+	return &Proof{
+		InstanceID:       trigger.InstanceID,
+		EpochID:          epoch.Bytes(),
+		BatchIndex:       epoch.Uint64(),
+		BatchHash:        trigger.TransactionsHash,
+		Collator:         collator,
+		L1BlockNumber:    trigger.BlockNumber,
+		TriggerSignature: trigger.Signature,
+		TxHash:           txHash,
+		Position:         position,
+		NumTxs:           len(txs),
+		MerkleProof:      merkleProof,
+	}, nil
+}
+
+// Verify checks that the transaction is part of the batch the collator has signed the trigger
+// for. The decryption key has to be checked against the eon public key separately.
+func (p *Proof) Verify() error {
+	trigger := &p2pmsg.DecryptionTrigger{
+		InstanceID:       p.InstanceID,
+		EpochID:          p.EpochID,
+		BlockNumber:      p.L1BlockNumber,
+		TransactionsHash: p.BatchHash,
+		Signature:        p.TriggerSignature,
+	}
+	ok, err := p2pmsg.VerifySignature(trigger, p.Collator)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Errorf("trigger of epoch %s is not signed by collator %s", p.EpochID, p.Collator.Hex())
+	}
+	merkleProof := make([][]byte, len(p.MerkleProof))
+	for i, node := range p.MerkleProof {
+		merkleProof[i] = node
+	}
+	return trigger.VerifyInclusion(p.TxHash, p.Position, p.NumTxs, merkleProof)
+}
@@ -0,0 +1,153 @@
+package inclusion
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+)
+
+const (
+	// subscriberBufferSize is the number of bundles queued for a subscriber. Subscribers that fall
+	// further behind are disconnected.
+	subscriberBufferSize  = 64
+	websocketWriteTimeout = 10 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	// wallets connect from their own origins
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// subscriber is a WebSocket connection bundles are pushed to. If txHashes is not empty, only the
+// bundles of batches containing one of these transactions are pushed.
+type subscriber struct {
+	txHashes [][]byte
+	bundles  chan []byte
+}
+
+func (s *subscriber) wants(bundle *Bundle) bool {
+	if len(s.txHashes) == 0 {
+		return true
+	}
+	for _, tx := range bundle.Transactions {
+		for _, txHash := range s.txHashes {
+			if bytes.Equal(tx.TxHash, txHash) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Hub pushes bundles to the clients subscribed over WebSocket. Bundles are only pushed to clients
+// connected at the time, clients that reconnect fetch the proofs of their transactions from the
+// inclusion proof endpoint instead.
+type Hub struct {
+	mux         sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[*subscriber]struct{})}
+}
+
+// Publish pushes the JSON encoded bundle to all interested subscribers.
+func (h *Hub) Publish(payload []byte) error {
+	bundle := &Bundle{}
+	if err := json.Unmarshal(payload, bundle); err != nil {
+		return errors.Wrap(err, "invalid inclusion bundle")
+	}
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	for s := range h.subscribers {
+		if !s.wants(bundle) {
+			continue
+		}
+		select {
+		case s.bundles <- payload:
+		default:
+			log.Info().Msg("disconnecting slow inclusion proof subscriber")
+			h.remove(s)
+		}
+	}
+	return nil
+}
+
+func (h *Hub) add(s *subscriber) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.subscribers[s] = struct{}{}
+}
+
+// remove unsubscribes s and closes its channel. It must be called with the lock held.
+func (h *Hub) remove(s *subscriber) {
+	if _, ok := h.subscribers[s]; !ok {
+		return
+	}
+	delete(h.subscribers, s)
+	close(s.bundles)
+}
+
+// ServeHTTP subscribes the client to the bundles over WebSocket. The optional txHash query
+// parameters restrict the subscription to the batches of the given transactions.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s := &subscriber{bundles: make(chan []byte, subscriberBufferSize)}
+	for _, param := range r.URL.Query()["txHash"] {
+		txHash, err := hexutil.Decode(param)
+		if err != nil {
+			errcode.SendError(w, errcode.ErrInvalidRequest.Wrapf(err, "invalid transaction hash"))
+			return
+		}
+		s.txHashes = append(s.txHashes, txHash)
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already responded
+		return
+	}
+	defer conn.Close()
+
+	h.add(s)
+	defer func() {
+		h.mux.Lock()
+		defer h.mux.Unlock()
+		h.remove(s)
+	}()
+
+	// clients don't send anything, reading only processes control messages and notices when the
+	// connection is closed
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case payload, ok := <-s.bundles:
+			if !ok {
+				return
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout))
+			if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -30,8 +30,11 @@ type Eon struct {
 
 // Error defines model for Error.
 type Error struct {
-	Code    int32  `json:"code"`
-	Message string `json:"message"`
+	Code int32 `json:"code"`
+
+	// machine readable class of the error, e.g. EON_UNKNOWN
+	ErrorCode string `json:"error_code"`
+	Message   string `json:"message"`
 }
 
 // NextEpoch defines model for NextEpoch.
@@ -298,20 +301,21 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/8xV34vjNhD+V4Ra6Is3Tu9KH/LWQihLaVno9ul6BFmeOLrYI91ovI05/L8XSd44drxc",
-	"CrewT4ml+fHNNzOfvkhtG2cRkL3cfJFeH6BR8e/WYvhxZB0QG4iHSrN5Umws7ora6uMO26YACld7S41i",
-	"uZEG+eefZCa5c5A+oQKSfSYhhbzNcufaojZ6d4Ru4lR0DKOPZzJYBReDnhVq2JnyxiRH6BzQTlvcm2pn",
-	"sITTjZ7eVKi4pUSKYWj8TRiHA0WkOtn3mST43BqCUm4+TAq4YiB7kfrlOhLZE6Qfz/lt8Qk0B0BbIkvX",
-	"bda2hDkV798tUtGA96qK1rNyZ+XFmKP9Epo/4cRbZ/XhGtGsqcsEzwktF7M8kkIf2FwacEBNnWMod3y6",
-	"qaXwjPf/YUtu2TTfV9Del6/ISx9XaG9T+5GV5vAXVRMjHVpmoDsE/tfSUWaypVpu5IHZbfJ8uF4N13kA",
-	"XoLXZFyiWT4ejBfpqAAv+ACCbF0brMTg/IMX2ta1Ykvil4d7wVb4tmgMCx4p8DKTtdGAHi7Q/XH/GLfL",
-	"cB0+Z4HPYWUmn4B8ArRe/bhaBy/rAJUzciPfr9artcykU3yI5OaDYFUQuZhW9Buw2Fp8iDv6O3Rib0ko",
-	"DGcyRiX13LRge2kac5BqgIG83HyYh/7bOSBR2BbLGDXuu0BQFIkblWC4CTafWyAD5ZDehDDhKOQaWJoL",
-	"iLycCqYWskH+bxLB/mNw986iT5P4br1+nh3AyJdyrjY6Zsw/+UTlmOF7gr3cyO/y8QXKh+cnD0XEiZzy",
-	"srUowowGaKF6haVQdS2SAHoRxA5KkWTz7gidGNTGi7AYAjCIUJmmc6/amr8d4iikC5hbhJMDzVAKeLbp",
-	"M5kjnPjuLB4vTtik3MK2HCcgOIvo/M/isI1C+opNGpMslH3/FdxvogcuqONL7D8YrJJQeaAnoAWmg8mU",
-	"YWzrOoZOr4ezfiHyX0nWFIqz+l9q3FWaZP84sQibC55/tWX3zfi7zLDA4sX1KM5XItK/4sRN38IFiBcs",
-	"CvMG9rzv/wsAAP//r5CQ7GELAAA=",
+	"H4sIAAAAAAACA81V32vbMBD+V4Q32EsaZ+3YQ982CCOUpYWl7KErRrYviVpbUqVzl1Dyv+8kOXEcuzSD",
+	"FvoU53Q/vvt09+kpylSplQSJNjp/imy2hJL7z7GS7kcbpcGgAG/kGYpHjkLJJC1Udp/IqkzBuKO5MiXH",
+	"6DwSEr9+iQYRrjWEv7Agl80ggpDyOM9EV2khsuQe1q2gdI3QxFg0Qi5ciJAWucwgEfmRRSgztZZkSs7F",
+	"IhEyh9WRkVYsJMfKBFIEQmmPwlgbuDF8HW3IYOChEgYI8U2rgQ4Dg2ep7+8jkN1Cerurr9I7yNABGhuj",
+	"TPeaM5XDIRVnp71UlGAtX3jvTrvgsifbZDnYzAjtOiCvkmdLIYEZ4DlPC2BZwa1las5wCcxHDhgMF0M2",
+	"vpwm19OL6eXvaZfTAw59rVbhBmFf/1NY4VirbNnl4GCM+q/08Arz3iozw6V199e3UiAzs9YIeYKro4YI",
+	"tnj/D1sIG7TrvYB2kr8hLxu/tHMVBk4ilXSfkpc+07JCBHMiAf8qc08lKlOQfYmoz+O4Ph7Wx7ED3h6w",
+	"2VJYFkwpWD9VRhUFwWN18CfLMrJwVIZ9u5owVMxWaSmQYUOBpcq0hCAt7KH7OZn5fRZYuL8HiXdpKfYR",
+	"jA2ARsPPw5GLIjYl14JMZ8MRmQaR5rj05Ma1RC4AuyvzA5CRKl95VbiANaNLYFw6W+SzGr69NOe77+pr",
+	"GMJO6KjQzWHqa03RLFWVzH1WrzBMAjeeuEZ76hPn81CBEZDX5YVL40yuVs3SoWRF+1OBpoJB/eAcJbub",
+	"Wxdu6bWyYRJPR6Pt7ND75Z8nralfXzG+s4HKpsJHA3NK+CFu3ry4fvBi14SfyDYvZGZuRh001z0nfnhR",
+	"sCC5ljl5JQqCUJ+QldVqY5lbDEbLRiKUh+mc86rA10PspbsHcyVhpWnDCBdsfcgpJjOe7MTj2QlrtUsT",
+	"gX4CXDDzwX96h60R0je8pKZIT9uTF3C/izvQTh2fY/+KDoNQWTCkGz1MO5c2w7IqCp86vB5a2Z7Mv4Ks",
+	"kVbs1H9f4zplgv+s5eE2Fyx+V/n61fjbr9DD4t5xI84dEdm84cS138IeiHssMvEO9nyz+QcHaOqu0wsA",
+	"AA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -0,0 +1,175 @@
+package collator
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/batchhandler"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testkeygen"
+)
+
+// EncryptionPreviewRequest is the body of a request to the encryption preview endpoint.
+type EncryptionPreviewRequest struct {
+	// Payload is the plaintext to encrypt.
+	Payload hexutil.Bytes `json:"payload"`
+	// Epoch is the epoch to encrypt for. Defaults to the next epoch of the collator.
+	Epoch hexutil.Bytes `json:"epoch,omitempty"`
+	// Sigma makes the encryption deterministic. It's chosen randomly if omitted.
+	Sigma hexutil.Bytes `json:"sigma,omitempty"`
+	// DecryptionKey is an epoch secret key to decrypt the envelope with. It's checked against the
+	// eon public key first.
+	DecryptionKey hexutil.Bytes `json:"decryptionKey,omitempty"`
+	// TestKey makes the collator encrypt with a freshly generated test eon key instead of the
+	// real one and return the epoch secret key along with the envelope.
+	TestKey bool `json:"testKey,omitempty"`
+}
+
+// EncryptionPreview is the response of the encryption preview endpoint.
+type EncryptionPreview struct {
+	Epoch            hexutil.Bytes `json:"epoch"`
+	Eon              *int64        `json:"eon,omitempty"`
+	EonPublicKey     hexutil.Bytes `json:"eonPublicKey"`
+	Sigma            hexutil.Bytes `json:"sigma"`
+	EncryptedMessage hexutil.Bytes `json:"encryptedMessage"`
+	EpochSecretKey   hexutil.Bytes `json:"epochSecretKey,omitempty"`
+	Decrypted        hexutil.Bytes `json:"decrypted,omitempty"`
+}
+
+// previewEncryption encrypts the payload of the request for the given epoch and, if a decryption
+// key is known, decrypts it again. If the request asks for a test key, eonPublicKey is ignored.
+func previewEncryption(
+	req *EncryptionPreviewRequest,
+	eonPublicKey *shcrypto.EonPublicKey,
+	epochID epochid.EpochID,
+) (*EncryptionPreview, error) {
+	var (
+		sigma          shcrypto.Block
+		epochSecretKey *shcrypto.EpochSecretKey
+		err            error
+	)
+	if req.TestKey {
+		if req.DecryptionKey != nil {
+			return nil, errors.New("decryption key must not be given together with test key")
+		}
+		eonKeys, err := testkeygen.NewEonKeys(rand.Reader, 1, 1)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate test eon key")
+		}
+		eonPublicKey = eonKeys.PublicKey()
+		epochSecretKey, err = eonKeys.EpochSecretKey(epochID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to compute test epoch secret key")
+		}
+	} else if req.DecryptionKey != nil {
+		epochSecretKey = new(shcrypto.EpochSecretKey)
+		if err := epochSecretKey.Unmarshal(req.DecryptionKey); err != nil {
+			return nil, errors.Wrap(err, "invalid decryption key")
+		}
+		ok, err := shcrypto.VerifyEpochSecretKey(epochSecretKey, eonPublicKey, epochID.Bytes())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to verify decryption key")
+		}
+		if !ok {
+			return nil, errors.New("decryption key does not match eon public key and epoch")
+		}
+	}
+
+	if req.Sigma != nil {
+		if len(req.Sigma) != len(sigma) {
+			return nil, errors.Errorf("sigma must be %d bytes, got %d", len(sigma), len(req.Sigma))
+		}
+		copy(sigma[:], req.Sigma)
+	} else {
+		sigma, err = shcrypto.RandomSigma(rand.Reader)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate sigma")
+		}
+	}
+
+	encrypted := shcrypto.Encrypt(req.Payload, eonPublicKey, shcrypto.ComputeEpochID(epochID.Bytes()), sigma)
+	preview := &EncryptionPreview{
+		Epoch:            epochID.Bytes(),
+		EonPublicKey:     eonPublicKey.Marshal(),
+		Sigma:            sigma[:],
+		EncryptedMessage: encrypted.Marshal(),
+	}
+	if epochSecretKey != nil {
+		decrypted, err := encrypted.Decrypt(epochSecretKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decrypt envelope")
+		}
+		preview.Decrypted = decrypted
+		if req.TestKey {
+			preview.EpochSecretKey = epochSecretKey.Marshal()
+		}
+	}
+	return preview, nil
+}
+
+// EncryptionPreview encrypts a payload with the node's own crypto, so that integrators can check
+// their client implementations against it.
+func (srv *server) EncryptionPreview(w http.ResponseWriter, r *http.Request) {
+	var req EncryptionPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, errcode.ErrInvalidRequest.Errorf("invalid format for EncryptionPreview"))
+		return
+	}
+	ctx := r.Context()
+	db := cltrdb.New(srv.c.dbpool)
+
+	nextEpochID, l1BlockNumber, err := batchhandler.GetNextBatch(ctx, db)
+	if err != nil {
+		sendError(w, errcode.WrapDB(err, "failed to get next batch from db"))
+		return
+	}
+	epochID := nextEpochID
+	if req.Epoch != nil {
+		epochID, err = epochid.BytesToEpochID(req.Epoch)
+		if err != nil {
+			sendError(w, errcode.ErrInvalidEpochID.Wrap(err))
+			return
+		}
+	}
+
+	var (
+		eonPublicKey *shcrypto.EonPublicKey
+		eon          *int64
+	)
+	if !req.TestKey {
+		candidate, err := db.FindEonPublicKeyForBlock(ctx, int64(l1BlockNumber))
+		if err == pgx.ErrNoRows {
+			sendError(w, errcode.ErrEonKeyNotFound.Errorf("no eon public key known yet"))
+			return
+		} else if err != nil {
+			sendError(w, errcode.WrapDB(err, "failed to get eon public key from db"))
+			return
+		}
+		eonPublicKey = new(shcrypto.EonPublicKey)
+		if err := eonPublicKey.Unmarshal(candidate.EonPublicKey); err != nil {
+			sendError(w, err)
+			return
+		}
+		eon = &candidate.Eon
+	}
+
+	preview, err := previewEncryption(&req, eonPublicKey, epochID)
+	if err != nil {
+		log.Debug().Err(err).Msg("encryption preview failed")
+		sendError(w, errcode.ErrInvalidRequest.Wrap(err))
+		return
+	}
+	preview.Eon = eon
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(preview)
+}
@@ -0,0 +1,66 @@
+package collator
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testkeygen"
+)
+
+func TestPreviewEncryption(t *testing.T) {
+	eonKeys, err := testkeygen.NewEonKeys(rand.Reader, 3, 2)
+	assert.NilError(t, err)
+	epochID := epochid.Uint64ToEpochID(42)
+	epochSecretKey, err := eonKeys.EpochSecretKey(epochID)
+	assert.NilError(t, err)
+	payload := []byte("hello")
+	sigma := bytes.Repeat([]byte{7}, 32)
+
+	preview, err := previewEncryption(&EncryptionPreviewRequest{
+		Payload:       payload,
+		Sigma:         sigma,
+		DecryptionKey: epochSecretKey.Marshal(),
+	}, eonKeys.PublicKey(), epochID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []byte(preview.Decrypted), payload)
+	assert.DeepEqual(t, []byte(preview.Sigma), sigma)
+	assert.Check(t, preview.EpochSecretKey == nil)
+
+	// the same sigma yields the same envelope
+	again, err := previewEncryption(&EncryptionPreviewRequest{Payload: payload, Sigma: sigma}, eonKeys.PublicKey(), epochID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, again.EncryptedMessage, preview.EncryptedMessage)
+	assert.Check(t, again.Decrypted == nil)
+
+	otherKey, err := eonKeys.EpochSecretKey(epochid.Uint64ToEpochID(43))
+	assert.NilError(t, err)
+	_, err = previewEncryption(&EncryptionPreviewRequest{
+		Payload:       payload,
+		DecryptionKey: otherKey.Marshal(),
+	}, eonKeys.PublicKey(), epochID)
+	assert.ErrorContains(t, err, "does not match")
+
+	_, err = previewEncryption(&EncryptionPreviewRequest{Payload: payload, Sigma: []byte{1}}, eonKeys.PublicKey(), epochID)
+	assert.ErrorContains(t, err, "sigma")
+}
+
+func TestPreviewEncryptionTestKey(t *testing.T) {
+	epochID := epochid.Uint64ToEpochID(42)
+	preview, err := previewEncryption(&EncryptionPreviewRequest{Payload: []byte("hello"), TestKey: true}, nil, epochID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []byte(preview.Decrypted), []byte("hello"))
+
+	eonPublicKey := new(shcrypto.EonPublicKey)
+	assert.NilError(t, eonPublicKey.Unmarshal(preview.EonPublicKey))
+	epochSecretKey := new(shcrypto.EpochSecretKey)
+	assert.NilError(t, epochSecretKey.Unmarshal(preview.EpochSecretKey))
+	ok, err := shcrypto.VerifyEpochSecretKey(epochSecretKey, eonPublicKey, epochID.Bytes())
+	assert.NilError(t, err)
+	assert.Check(t, ok)
+}
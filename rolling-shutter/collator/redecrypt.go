@@ -0,0 +1,214 @@
+package collator
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	txtypes "github.com/shutter-network/txtypes/types"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+// RedecryptionReport describes how a stored batchtx compares to the batchtx rebuilt from the
+// decryption key and transactions stored for its epoch.
+type RedecryptionReport struct {
+	Epoch hexutil.Bytes `json:"epoch"`
+	// Eon is the eon whose public key the decryption key has been checked against.
+	Eon *int64 `json:"eon,omitempty"`
+	// KeyValid tells if the stored decryption key is the epoch secret key of the epoch.
+	KeyValid        bool `json:"keyValid"`
+	NumTransactions int  `json:"numTransactions"`
+	// NumDecrypted is the number of shutter transactions whose payload could be decrypted.
+	NumDecrypted       int                  `json:"numDecrypted"`
+	DecryptionFailures []*DecryptionFailure `json:"decryptionFailures,omitempty"`
+	// Mismatches lists the fields in which the stored and the rebuilt batchtx differ.
+	Mismatches []string `json:"mismatches,omitempty"`
+	// Error is set if the batchtx could not be reprocessed at all.
+	Error string `json:"error,omitempty"`
+}
+
+type DecryptionFailure struct {
+	TxHash common.Hash `json:"txHash"`
+	Error  string      `json:"error"`
+}
+
+// Consistent tells if reprocessing the batchtx reproduced the stored batchtx exactly.
+func (r *RedecryptionReport) Consistent() bool {
+	return r.Error == "" && r.KeyValid && len(r.DecryptionFailures) == 0 && len(r.Mismatches) == 0
+}
+
+// Redecrypt reprocesses the batchtxs stored for the epochs from fromEpoch to toEpoch (inclusive).
+// For each of them, it checks the decryption key against the eon public key, decrypts the
+// shutter transactions and signs the batchtx again with privKey. Since signatures are
+// deterministic, the result has to match the stored batchtx byte by byte.
+func Redecrypt(
+	ctx context.Context,
+	db *cltrdb.Queries,
+	privKey *ecdsa.PrivateKey,
+	fromEpoch, toEpoch epochid.EpochID,
+) ([]*RedecryptionReport, error) {
+	batchTxs, err := db.GetBatchTxsInRange(ctx, cltrdb.GetBatchTxsInRangeParams{
+		FromEpochID: fromEpoch.Bytes(),
+		ToEpochID:   toEpoch.Bytes(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get batchtxs from db")
+	}
+	reports := []*RedecryptionReport{}
+	for _, batchTx := range batchTxs {
+		report := &RedecryptionReport{Epoch: batchTx.EpochID}
+		if err := redecryptBatchTx(ctx, db, privKey, batchTx, report); err != nil {
+			report.Error = err.Error()
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func redecryptBatchTx(
+	ctx context.Context,
+	db *cltrdb.Queries,
+	privKey *ecdsa.PrivateKey,
+	batchTx cltrdb.Batchtx,
+	report *RedecryptionReport,
+) error {
+	stored := new(txtypes.Transaction)
+	if err := stored.UnmarshalBinary(batchTx.Marshaled); err != nil {
+		return errors.Wrap(err, "failed to decode stored batchtx")
+	}
+	if stored.Type() != txtypes.BatchTxType {
+		return errors.Errorf("stored transaction has type %d, not a batchtx", stored.Type())
+	}
+	epochID, err := epochid.BytesToEpochID(batchTx.EpochID)
+	if err != nil {
+		return err
+	}
+
+	decryptionKey, err := db.GetDecryptionKey(ctx, epochID.Bytes())
+	if err == pgx.ErrNoRows {
+		return errors.New("no decryption key stored")
+	} else if err != nil {
+		return errors.Wrap(err, "failed to get decryption key from db")
+	}
+	if !bytes.Equal(stored.DecryptionKey(), decryptionKey.DecryptionKey) {
+		report.Mismatches = append(report.Mismatches, "decryptionKey")
+	}
+	epochSecretKey := new(shcrypto.EpochSecretKey)
+	if err := epochSecretKey.GobDecode(decryptionKey.DecryptionKey); err != nil {
+		return errors.Wrap(err, "failed to decode decryption key")
+	}
+	eonPub, err := db.FindEonPublicKeyForBlock(ctx, int64(stored.L1BlockNumber()))
+	if err == pgx.ErrNoRows {
+		return errors.Errorf("no eon public key known for block %d", stored.L1BlockNumber())
+	} else if err != nil {
+		return errors.Wrap(err, "failed to get eon public key from db")
+	}
+	report.Eon = &eonPub.Eon
+	eonPublicKey := new(shcrypto.EonPublicKey)
+	if err := eonPublicKey.GobDecode(eonPub.EonPublicKey); err != nil {
+		return errors.Wrap(err, "failed to decode eon public key")
+	}
+	report.KeyValid, err = shcrypto.VerifyEpochSecretKey(epochSecretKey, eonPublicKey, epochID.Bytes())
+	if err != nil {
+		return err
+	}
+
+	txs, err := db.GetCommittedTransactionsByEpoch(ctx, epochID.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "failed to get committed transactions from db")
+	}
+	report.NumTransactions = len(txs)
+	transactions := [][]byte{}
+	for _, t := range txs {
+		transactions = append(transactions, t.TxBytes)
+		if report.KeyValid {
+			redecryptTx(t, epochSecretKey, report)
+		}
+	}
+	if !equalTransactions(stored.Transactions(), transactions) {
+		report.Mismatches = append(report.Mismatches, "transactions")
+	}
+	if stored.BatchIndex() != epochID.Uint64() {
+		report.Mismatches = append(report.Mismatches, "batchIndex")
+	}
+
+	// The L1 block number and the timestamp depend on the time the batchtx was created, so we
+	// take them from the stored batchtx.
+	signer := txtypes.LatestSignerForChainID(stored.ChainId())
+	rebuilt, err := txtypes.SignNewTx(privKey, signer, &txtypes.BatchTx{
+		ChainID:       stored.ChainId(),
+		DecryptionKey: decryptionKey.DecryptionKey,
+		BatchIndex:    epochID.Uint64(),
+		L1BlockNumber: stored.L1BlockNumber(),
+		Timestamp:     stored.Timestamp(),
+		Transactions:  transactions,
+	})
+	if err != nil {
+		return err
+	}
+	rebuiltBytes, err := rebuilt.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(rebuiltBytes, batchTx.Marshaled) {
+		report.Mismatches = append(report.Mismatches, "marshaled")
+	}
+	return nil
+}
+
+// redecryptTx decrypts the payload of t if it is a shutter transaction and records the outcome.
+func redecryptTx(t cltrdb.Transaction, epochSecretKey *shcrypto.EpochSecretKey, report *RedecryptionReport) {
+	tx := new(txtypes.Transaction)
+	err := tx.UnmarshalBinary(t.TxBytes)
+	if err == nil && tx.Type() != txtypes.ShutterTxType {
+		return
+	}
+	if err == nil {
+		_, err = decryptPayload(tx.EncryptedPayload(), epochSecretKey)
+	}
+	if err != nil {
+		report.DecryptionFailures = append(report.DecryptionFailures, &DecryptionFailure{
+			TxHash: common.BytesToHash(t.TxHash),
+			Error:  err.Error(),
+		})
+		return
+	}
+	report.NumDecrypted++
+}
+
+// decryptPayload decrypts the encrypted payload of a shutter transaction and checks that it can be
+// decoded.
+func decryptPayload(messageBytes []byte, epochSecretKey *shcrypto.EpochSecretKey) ([]byte, error) {
+	message := new(shcrypto.EncryptedMessage)
+	if err := message.Unmarshal(messageBytes); err != nil {
+		return nil, errors.Wrap(err, "can't unmarshal message")
+	}
+	decryptedBytes, err := message.Decrypt(epochSecretKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't decrypt message")
+	}
+	if _, err := txtypes.DecodeShutterPayload(decryptedBytes); err != nil {
+		return nil, err
+	}
+	return decryptedBytes, nil
+}
+
+func equalTransactions(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
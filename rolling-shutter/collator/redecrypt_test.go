@@ -0,0 +1,150 @@
+package collator
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	txtypes "github.com/shutter-network/txtypes/types"
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testkeygen"
+)
+
+func TestRedecryptIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	db, _, closedb := testdb.NewCollatorTestDB(ctx, t)
+	defer closedb()
+
+	privKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	signer := txtypes.LatestSignerForChainID(big.NewInt(1))
+	tkg := testkeygen.NewTestKeyGenerator(t, 3, 2)
+
+	goodEpoch := epochid.Uint64ToEpochID(1)
+	badEpoch := epochid.Uint64ToEpochID(2)
+	err = db.InsertEonPublicKeyCandidate(ctx, cltrdb.InsertEonPublicKeyCandidateParams{
+		Hash:                  []byte{1},
+		EonPublicKey:          tkg.EonPublicKey(goodEpoch).Marshal(),
+		ActivationBlockNumber: 0,
+		KeyperConfigIndex:     1,
+		Eon:                   1,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, db.ConfirmEonPublicKey(ctx, []byte{1}))
+
+	for _, epoch := range []epochid.EpochID{goodEpoch, badEpoch} {
+		key, err := tkg.EpochSecretKey(epoch).GobEncode()
+		assert.NilError(t, err)
+		_, err = db.InsertDecryptionKey(ctx, cltrdb.InsertDecryptionKeyParams{EpochID: epoch.Bytes(), DecryptionKey: key})
+		assert.NilError(t, err)
+
+		tx := newTestShutterTx(t, privKey, signer, tkg.EonPublicKey(epoch), epoch)
+		insertTestTx(t, db, epoch, tx)
+		insertTestBatchTx(t, db, privKey, signer, epoch, key)
+	}
+	// a transaction committed after the batchtx of the bad epoch has been created
+	insertTestTx(t, db, badEpoch, []byte{0x50, 1, 2, 3})
+
+	reports, err := Redecrypt(ctx, db, privKey, goodEpoch, badEpoch)
+	assert.NilError(t, err)
+	assert.Equal(t, len(reports), 2)
+
+	good := reports[0]
+	assert.DeepEqual(t, []byte(good.Epoch), goodEpoch.Bytes())
+	assert.Check(t, good.Consistent(), "%+v", good)
+	assert.Equal(t, good.NumTransactions, 1)
+	assert.Equal(t, good.NumDecrypted, 1)
+
+	bad := reports[1]
+	assert.Check(t, !bad.Consistent())
+	assert.Check(t, bad.KeyValid)
+	assert.Equal(t, bad.NumTransactions, 2)
+	assert.Equal(t, bad.NumDecrypted, 1)
+	assert.Equal(t, len(bad.DecryptionFailures), 1)
+	assert.DeepEqual(t, bad.Mismatches, []string{"transactions", "marshaled"})
+
+	reports, err = Redecrypt(ctx, db, privKey, goodEpoch, goodEpoch)
+	assert.NilError(t, err)
+	assert.Equal(t, len(reports), 1)
+}
+
+func newTestShutterTx(
+	t *testing.T,
+	privKey *ecdsa.PrivateKey,
+	signer txtypes.Signer,
+	eonPublicKey *shcrypto.EonPublicKey,
+	epoch epochid.EpochID,
+) []byte {
+	t.Helper()
+	payload, err := (&txtypes.ShutterPayload{Data: []byte("hello"), Value: big.NewInt(0)}).Encode()
+	assert.NilError(t, err)
+	sigma, err := shcrypto.RandomSigma(rand.Reader)
+	assert.NilError(t, err)
+	encrypted := shcrypto.Encrypt(payload, eonPublicKey, shcrypto.ComputeEpochID(epoch.Bytes()), sigma)
+	tx, err := txtypes.SignNewTx(privKey, signer, &txtypes.ShutterTx{
+		ChainID:          signer.ChainID(),
+		GasTipCap:        big.NewInt(0),
+		GasFeeCap:        big.NewInt(0),
+		Gas:              21000,
+		EncryptedPayload: encrypted.Marshal(),
+		BatchIndex:       epoch.Uint64(),
+	})
+	assert.NilError(t, err)
+	txBytes, err := tx.MarshalBinary()
+	assert.NilError(t, err)
+	return txBytes
+}
+
+func insertTestTx(t *testing.T, db *cltrdb.Queries, epoch epochid.EpochID, txBytes []byte) {
+	t.Helper()
+	err := db.InsertTx(context.Background(), cltrdb.InsertTxParams{
+		TxHash:  ethcrypto.Keccak256(txBytes),
+		EpochID: epoch.Bytes(),
+		TxBytes: txBytes,
+		Status:  cltrdb.TxstatusCommitted,
+	})
+	assert.NilError(t, err)
+}
+
+func insertTestBatchTx(
+	t *testing.T,
+	db *cltrdb.Queries,
+	privKey *ecdsa.PrivateKey,
+	signer txtypes.Signer,
+	epoch epochid.EpochID,
+	decryptionKey []byte,
+) {
+	t.Helper()
+	ctx := context.Background()
+	txs, err := db.GetCommittedTransactionsByEpoch(ctx, epoch.Bytes())
+	assert.NilError(t, err)
+	transactions := [][]byte{}
+	for _, tx := range txs {
+		transactions = append(transactions, tx.TxBytes)
+	}
+	tx, err := txtypes.SignNewTx(privKey, signer, &txtypes.BatchTx{
+		ChainID:       signer.ChainID(),
+		DecryptionKey: decryptionKey,
+		BatchIndex:    epoch.Uint64(),
+		L1BlockNumber: 10,
+		Timestamp:     big.NewInt(1000),
+		Transactions:  transactions,
+	})
+	assert.NilError(t, err)
+	txBytes, err := tx.MarshalBinary()
+	assert.NilError(t, err)
+	assert.NilError(t, db.InsertBatchTx(ctx, cltrdb.InsertBatchTxParams{EpochID: epoch.Bytes(), Marshaled: txBytes}))
+	assert.NilError(t, db.SetBatchSubmitted(ctx))
+}
@@ -0,0 +1,93 @@
+package collator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/config"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/featureflag"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/httpauth"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/opapproval"
+)
+
+func newTestRouter(t *testing.T, cfg *config.Config) http.Handler {
+	t.Helper()
+	features, err := featureflag.New(cfg.Name(), cfg.Features, Features...)
+	assert.NilError(t, err)
+	return (&collator{Config: cfg, features: features}).setupRouter()
+}
+
+func TestRouterOperatorApproval(t *testing.T) {
+	operator, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	cfg := config.New()
+	cfg.InstanceID = 42
+	cfg.OperatorApproval = &opapproval.Config{
+		Threshold: 1,
+		Operators: []string{ethcrypto.PubkeyToAddress(operator.PublicKey).Hex()},
+		Actions:   []string{opapproval.ActionOverrideFeature, opapproval.ActionSetLogFilter},
+	}
+	router := newTestRouter(t, cfg)
+
+	serve := func(method, path, body string, approve bool) int {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		if approve {
+			notAfter := time.Now().Add(time.Minute).Unix()
+			action := opapproval.HTTPAction(
+				opapproval.ActionOverrideFeature, cfg.InstanceID, cfg.Ethereum.PrivateKey.EthereumAddress(),
+				method, path, []byte(body), notAfter,
+			)
+			signature, err := opapproval.Sign(action, operator)
+			assert.NilError(t, err)
+			r.Header.Set(opapproval.NotAfterHeader, strconv.FormatInt(notAfter, 10))
+			r.Header.Set(opapproval.SignatureHeader, hexutil.Encode(signature))
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	feature := "/features/" + FeatureTriggerBatching
+	assert.Equal(t, serve(http.MethodGet, "/features", "", false), http.StatusOK)
+	assert.Equal(t, serve(http.MethodPut, feature, `{"enabled": true}`, false), http.StatusForbidden)
+	assert.Equal(t, serve(http.MethodPut, feature, `{"enabled": true}`, true), http.StatusOK)
+	assert.Equal(t, serve(http.MethodPut, "/log", `{"filter": "debug"}`, false), http.StatusForbidden)
+}
+
+func TestRouterProfiler(t *testing.T) {
+	cfg := config.New()
+	cfg.HTTPAuth = &httpauth.Config{
+		Enabled:        true,
+		ReadOnlyTokens: []string{"readonly"},
+		OperatorTokens: []string{"operator"},
+		AdminTokens:    []string{"admin"},
+	}
+	router := newTestRouter(t, cfg)
+
+	testCases := []struct {
+		token  string
+		status int
+	}{
+		{token: "", status: http.StatusUnauthorized},
+		{token: "readonly", status: http.StatusForbidden},
+		{token: "operator", status: http.StatusForbidden},
+		{token: "admin", status: http.StatusOK},
+	}
+	for _, tc := range testCases {
+		r := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+		if tc.token != "" {
+			r.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		assert.Equal(t, w.Code, tc.status, "token %q", tc.token)
+	}
+}
@@ -0,0 +1,117 @@
+package stream
+
+import (
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+// Config selects the broker decrypted batches are streamed to.
+type Config struct {
+	Kind          string            `comment:"Where decrypted batches are streamed to: nats (NATS server), kafka (Kafka brokers), kafka-rest (Kafka REST proxy) or empty to disable streaming"`
+	Brokers       []string          `comment:"Brokers tried in order: host:port or URLs of NATS servers, host:port of Kafka brokers or base URLs of Kafka REST proxies"`
+	Topic         string            `comment:"NATS subject or Kafka topic the batches are published to"`
+	Serialization string            `comment:"Format of the messages: json (epoch, decryption key, transactions and signed batch transaction) or batchtx (the signed batch transaction only)"`
+	JetStream     bool              `comment:"Wait for the acknowledgement of a JetStream stream bound to the subject instead of the NATS server, only used by nats"`
+	PollInterval  *enctime.Duration `comment:"How often batches not yet published are looked up in the database"`
+	Timeout       *enctime.Duration `comment:"How long to wait for a broker to acknowledge a message"`
+}
+
+// Kind is the type of broker batches are streamed to.
+type Kind string
+
+const (
+	// KindNATS publishes batches to a NATS server, with the epoch ID as Nats-Msg-Id header.
+	KindNATS Kind = "nats"
+	// KindKafka produces batches directly to the brokers of a Kafka topic, keyed by epoch ID.
+	KindKafka Kind = "kafka"
+	// KindKafkaREST produces batches to a Kafka topic via the REST proxy, keyed by epoch ID.
+	KindKafkaREST Kind = "kafka-rest"
+)
+
+// Serialization is the format of the messages.
+type Serialization string
+
+const (
+	// SerializationJSON encodes a Batch as JSON.
+	SerializationJSON Serialization = "json"
+	// SerializationBatchTx sends the signed batch transaction as it is submitted to the sequencer.
+	SerializationBatchTx Serialization = "batchtx"
+)
+
+func (c *Config) Init() {
+	c.PollInterval = &enctime.Duration{}
+	c.Timeout = &enctime.Duration{}
+}
+
+func (c *Config) Name() string {
+	return "stream"
+}
+
+// Enabled reports whether decrypted batches are streamed to a broker.
+func (c *Config) Enabled() bool {
+	return c.Kind != ""
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	switch Kind(c.Kind) {
+	case KindNATS, KindKafka, KindKafkaREST:
+	default:
+		return errors.Errorf("unknown stream kind %q", c.Kind)
+	}
+	switch Serialization(c.Serialization) {
+	case SerializationJSON, SerializationBatchTx:
+	default:
+		return errors.Errorf("unknown stream serialization %q", c.Serialization)
+	}
+	if len(c.Brokers) == 0 {
+		return errors.New("at least one broker must be configured for streaming")
+	}
+	if c.Topic == "" {
+		return errors.New("Topic of stream must be set")
+	}
+	if c.JetStream && Kind(c.Kind) != KindNATS {
+		return errors.New("JetStream can only be used with the nats stream kind")
+	}
+	if c.PollInterval.Duration <= 0 {
+		return errors.New("PollInterval of stream must be positive")
+	}
+	if c.Timeout.Duration <= 0 {
+		return errors.New("Timeout of stream must be positive")
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.Kind = ""
+	c.Brokers = []string{}
+	c.Topic = "shutter.batches"
+	c.Serialization = string(SerializationJSON)
+	c.JetStream = false
+	c.PollInterval = &enctime.Duration{Duration: time.Second}
+	c.Timeout = &enctime.Duration{Duration: 10 * time.Second}
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,51 @@
+package stream
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaSink produces messages directly to the Kafka brokers. Records are keyed by the message ID
+// and only count as delivered once all in-sync replicas acknowledged them. Consumers that rely on
+// the order of the batches need a topic with one partition.
+type kafkaSink struct {
+	config *Config
+	writer *kafka.Writer
+	// transport replaces the connections to the brokers in tests.
+	transport kafka.RoundTripper
+}
+
+func newKafkaSink(config *Config) *kafkaSink {
+	return &kafkaSink{config: config}
+}
+
+func (s *kafkaSink) Publish(ctx context.Context, msg Message) error {
+	if s.writer == nil {
+		s.writer = &kafka.Writer{
+			Addr:         kafka.TCP(s.config.Brokers...),
+			Topic:        s.config.Topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			// the relay retries failed messages itself
+			MaxAttempts: 1,
+			// every message is written on its own, so don't wait for a batch to fill up
+			BatchSize:    1,
+			ReadTimeout:  s.config.Timeout.Duration,
+			WriteTimeout: s.config.Timeout.Duration,
+			Transport:    s.transport,
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout.Duration)
+	defer cancel()
+	return s.writer.WriteMessages(ctx, kafka.Message{Key: []byte(msg.ID), Value: msg.Payload})
+}
+
+func (s *kafkaSink) Close() error {
+	if s.writer == nil {
+		return nil
+	}
+	err := s.writer.Close()
+	s.writer = nil
+	return err
+}
@@ -0,0 +1,122 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	kafkaContentType = "application/vnd.kafka.binary.v2+json"
+	kafkaAccept      = "application/vnd.kafka.v2+json"
+)
+
+// kafkaRESTSink produces messages via the REST proxy of Kafka, using its v2 API, for deployments
+// where the brokers themselves aren't reachable. Records are keyed by the message ID. Consumers
+// that rely on the order of the batches need a topic with one partition.
+type kafkaRESTSink struct {
+	config *Config
+	client *http.Client
+	// broker is the index of the broker used last, which is tried first.
+	broker int
+}
+
+type kafkaRecord struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+type kafkaErrorResponse struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+func newKafkaRESTSink(config *Config) *kafkaRESTSink {
+	return &kafkaRESTSink{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout.Duration},
+	}
+}
+
+func (s *kafkaRESTSink) Publish(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(kafkaProduceRequest{
+		Records: []kafkaRecord{{Key: []byte(msg.ID), Value: msg.Payload}},
+	})
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for i := range s.config.Brokers {
+		broker := (s.broker + i) % len(s.config.Brokers)
+		lastErr = s.produce(ctx, s.config.Brokers[broker], body)
+		if lastErr == nil {
+			s.broker = broker
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return lastErr
+}
+
+func (s *kafkaRESTSink) produce(ctx context.Context, broker string, body []byte) error {
+	endpoint := strings.TrimSuffix(broker, "/") + "/topics/" + url.PathEscape(s.config.Topic)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", kafkaAccept)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var kafkaErr kafkaErrorResponse
+		if json.Unmarshal(respBody, &kafkaErr) == nil && kafkaErr.Message != "" {
+			return errors.Errorf("kafka rest proxy %s: %s (error code %d)", broker, kafkaErr.Message, kafkaErr.ErrorCode)
+		}
+		return errors.Errorf("kafka rest proxy %s: unexpected status %s", broker, resp.Status)
+	}
+	var produced kafkaProduceResponse
+	if err := json.Unmarshal(respBody, &produced); err != nil {
+		return errors.Wrapf(err, "kafka rest proxy %s: invalid response", broker)
+	}
+	if len(produced.Offsets) != 1 {
+		return errors.Errorf("kafka rest proxy %s: expected 1 offset, got %d", broker, len(produced.Offsets))
+	}
+	if offset := produced.Offsets[0]; offset.ErrorCode != nil {
+		return errors.Errorf("kafka rest proxy %s: %s (error code %d)", broker, offset.Error, *offset.ErrorCode)
+	}
+	return nil
+}
+
+func (s *kafkaRESTSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
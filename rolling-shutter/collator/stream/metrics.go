@@ -0,0 +1,36 @@
+package stream
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsPublished = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "stream",
+		Name:      "published_total",
+		Help:      "Number of decrypted batches acknowledged by the broker",
+	},
+)
+
+var metricsPublishErrors = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "stream",
+		Name:      "publish_errors_total",
+		Help:      "Number of failed attempts to publish a decrypted batch",
+	},
+)
+
+var metricsPending = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "stream",
+		Name:      "pending",
+		Help:      "Number of decrypted batches in the outbox that are not yet published",
+	},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsPublished)
+	prometheus.MustRegister(metricsPublishErrors)
+	prometheus.MustRegister(metricsPending)
+}
@@ -0,0 +1,90 @@
+package stream
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// natsSink publishes messages to NATS with the epoch ID as Nats-Msg-Id header. Without JetStream
+// a message counts as delivered once the server answered the flush after it. With JetStream, the
+// acknowledgement of the stream bound to the subject is awaited.
+type natsSink struct {
+	config *Config
+	conn   *nats.Conn
+	js     nats.JetStreamContext
+}
+
+func newNATSSink(config *Config) *natsSink {
+	return &natsSink{config: config}
+}
+
+func (s *natsSink) Publish(ctx context.Context, msg Message) error {
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout.Duration)
+	defer cancel()
+
+	natsMsg := nats.NewMsg(s.config.Topic)
+	natsMsg.Header.Set(nats.MsgIdHdr, msg.ID)
+	natsMsg.Data = msg.Payload
+	if s.js != nil {
+		ack, err := s.js.PublishMsg(natsMsg, nats.Context(ctx))
+		if err != nil {
+			return errors.Wrap(err, "jetstream did not store message")
+		}
+		if ack.Duplicate {
+			log.Debug().Str("stream", ack.Stream).Uint64("seq", ack.Sequence).Msg("jetstream dropped duplicate batch")
+		}
+		return nil
+	}
+	if err := s.conn.PublishMsg(natsMsg); err != nil {
+		return err
+	}
+	return s.conn.FlushWithContext(ctx)
+}
+
+// connect connects to the first reachable server. Servers are given as host:port or as URLs with
+// the nats or tls scheme and optionally user and password.
+func (s *natsSink) connect() error {
+	conn, err := nats.Connect(
+		strings.Join(s.config.Brokers, ","),
+		nats.Name("rolling-shutter-collator"),
+		nats.DontRandomize(),
+		nats.Timeout(s.config.Timeout.Duration),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to nats")
+	}
+	if !conn.HeadersSupported() {
+		conn.Close()
+		return errors.Errorf("nats server %s does not support headers", conn.ConnectedUrlRedacted())
+	}
+	var js nats.JetStreamContext
+	if s.config.JetStream {
+		js, err = conn.JetStream()
+		if err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	s.conn = conn
+	s.js = js
+	return nil
+}
+
+func (s *natsSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	s.conn.Close()
+	s.conn = nil
+	s.js = nil
+	return nil
+}
@@ -0,0 +1,167 @@
+// Package stream publishes the batches decrypted by the collator to NATS or Kafka, so that data
+// pipelines can consume them without polling the database.
+//
+// Batches are written to an outbox table in the same transaction as their batch transaction. The
+// Relay publishes the outbox in order and deletes each entry only after the broker acknowledged it.
+// A crash between the two may publish a batch twice, so each message carries the epoch ID as
+// deduplication ID: the Nats-Msg-Id header for NATS and the record key for Kafka.
+package stream
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
+)
+
+// batchSize is the maximum number of outbox entries published per round.
+const batchSize = 100
+
+// Batch is the message streamed for each decrypted batch.
+type Batch struct {
+	EpochID    hexutil.Bytes `json:"epochID"`
+	BatchIndex uint64        `json:"batchIndex"`
+	// DecryptionKey is aggregated from the decryption key shares of the keypers and doubles as
+	// their threshold signature of the epoch ID.
+	DecryptionKey hexutil.Bytes   `json:"decryptionKey"`
+	Transactions  []hexutil.Bytes `json:"transactions"`
+	// BatchTx is the batch transaction signed by Collator, as submitted to the sequencer.
+	BatchTx  hexutil.Bytes  `json:"batchTx"`
+	Collator common.Address `json:"collator"`
+}
+
+// Encode serializes the batch in the given format.
+func (b *Batch) Encode(serialization Serialization) ([]byte, error) {
+	switch serialization {
+	case SerializationJSON:
+		return json.Marshal(b)
+	case SerializationBatchTx:
+		return b.BatchTx, nil
+	default:
+		return nil, errors.Errorf("unknown stream serialization %q", serialization)
+	}
+}
+
+// Enqueue adds the batch to the outbox if streaming is enabled. It should be called in the
+// transaction that stores the batch transaction.
+func Enqueue(ctx context.Context, db *cltrdb.Queries, config *Config, batch *Batch) error {
+	if !config.Enabled() {
+		return nil
+	}
+	payload, err := batch.Encode(Serialization(config.Serialization))
+	if err != nil {
+		return err
+	}
+	return db.InsertStreamOutbox(ctx, cltrdb.InsertStreamOutboxParams{
+		EpochID: batch.EpochID,
+		Payload: payload,
+	})
+}
+
+// Message is a single message published to the broker.
+type Message struct {
+	// ID identifies the message for deduplication by the broker or the consumers.
+	ID      string
+	Payload []byte
+}
+
+// Sink publishes messages to a broker.
+type Sink interface {
+	// Publish returns once the broker acknowledged the message.
+	Publish(ctx context.Context, msg Message) error
+	// Close closes the connection to the broker. The sink reconnects on the next call of Publish.
+	Close() error
+}
+
+func newSink(config *Config) Sink {
+	switch Kind(config.Kind) {
+	case KindKafka:
+		return newKafkaSink(config)
+	case KindKafkaREST:
+		return newKafkaRESTSink(config)
+	default:
+		return newNATSSink(config)
+	}
+}
+
+// Relay publishes the batches in the outbox.
+type Relay struct {
+	config *Config
+	dbpool *pgxpool.Pool
+	sink   Sink
+}
+
+// NewRelay creates the relay of the outbox. It returns nil if streaming is disabled.
+func NewRelay(config *Config, dbpool *pgxpool.Pool) *Relay {
+	if !config.Enabled() {
+		return nil
+	}
+	return &Relay{
+		config: config,
+		dbpool: dbpool,
+		sink:   newSink(config),
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) error {
+	defer r.sink.Close()
+	log.Info().
+		Str("kind", r.config.Kind).
+		Strs("brokers", r.config.Brokers).
+		Str("topic", r.config.Topic).
+		Msg("streaming decrypted batches")
+	ticker := time.NewTicker(r.config.PollInterval.Duration)
+	defer ticker.Stop()
+	for {
+		if err := r.publishPending(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			metricsPublishErrors.Inc()
+			log.Warn().Err(err).Msg("failed to stream decrypted batches, retrying")
+			_ = r.sink.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishPending publishes the outbox in order until it is empty.
+func (r *Relay) publishPending(ctx context.Context) error {
+	db := cltrdb.New(r.dbpool)
+	for {
+		entries, err := db.GetStreamOutbox(ctx, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			err := r.sink.Publish(ctx, Message{ID: hex.EncodeToString(entry.EpochID), Payload: entry.Payload})
+			if err != nil {
+				return errors.Wrapf(err, "failed to publish batch of epoch %x", entry.EpochID)
+			}
+			metricsPublished.Inc()
+			if err := db.DeleteStreamOutbox(ctx, entry.ID); err != nil {
+				return err
+			}
+		}
+		pending, err := db.CountStreamOutbox(ctx)
+		if err != nil {
+			return err
+		}
+		metricsPending.Set(float64(pending))
+		if len(entries) < batchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go/protocol"
+	"github.com/segmentio/kafka-go/protocol/metadata"
+	"github.com/segmentio/kafka-go/protocol/produce"
+	"gotest.tools/v3/assert"
+)
+
+func testConfig(t *testing.T, kind Kind, brokers ...string) *Config {
+	t.Helper()
+	config := NewConfig()
+	assert.NilError(t, config.SetDefaultValues())
+	config.Kind = string(kind)
+	config.Brokers = brokers
+	assert.NilError(t, config.Validate())
+	return config
+}
+
+func TestEncode(t *testing.T) {
+	batch := &Batch{
+		EpochID:       []byte{1},
+		BatchIndex:    1,
+		DecryptionKey: []byte{2},
+		Transactions:  []hexutil.Bytes{{3}},
+		BatchTx:       []byte{4, 5},
+	}
+	encoded, err := batch.Encode(SerializationJSON)
+	assert.NilError(t, err)
+	var decoded Batch
+	assert.NilError(t, json.Unmarshal(encoded, &decoded))
+	assert.DeepEqual(t, decoded, *batch)
+
+	encoded, err = batch.Encode(SerializationBatchTx)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, encoded, []byte{4, 5})
+}
+
+// serveNATS accepts one connection and answers like a NATS server. Published messages are
+// acknowledged with the reply of ack, if they have a reply subject, like JetStream does. The
+// headers of the published messages are sent to the returned channel.
+func serveNATS(t *testing.T, ack func(seq int) string) (string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	headers := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		fmt.Fprint(conn, "INFO {\"headers\":true,\"proto\":1,\"max_payload\":1048576}\r\n")
+		seq := 0
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			switch fields[0] {
+			case "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			case "HPUB":
+				reply := ""
+				if len(fields) == 5 {
+					reply = fields[2]
+					fields = append(fields[:2], fields[3:]...)
+				}
+				headerSize, _ := strconv.Atoi(fields[2])
+				total, _ := strconv.Atoi(fields[3])
+				data := make([]byte, total+2)
+				if _, err := io.ReadFull(reader, data); err != nil {
+					return
+				}
+				headers <- string(data[:headerSize])
+				seq++
+				if reply != "" {
+					// the reply subjects of JetStream are received by the client's first subscription
+					response := ack(seq)
+					fmt.Fprintf(conn, "MSG %s 1 %d\r\n%s\r\n", reply, len(response), response)
+				}
+			}
+		}
+	}()
+	return listener.Addr().String(), headers
+}
+
+func jetStreamAck(seq int) string {
+	return fmt.Sprintf("{\"stream\":\"batches\",\"seq\":%d}", seq)
+}
+
+func TestNATSSink(t *testing.T) {
+	addr, headers := serveNATS(t, jetStreamAck)
+	sink := newNATSSink(testConfig(t, KindNATS, "127.0.0.1:1", addr))
+	defer sink.Close()
+
+	ctx := context.Background()
+	assert.NilError(t, sink.Publish(ctx, Message{ID: "01", Payload: []byte("first")}))
+	assert.NilError(t, sink.Publish(ctx, Message{ID: "02", Payload: []byte("second")}))
+	assert.Equal(t, <-headers, "NATS/1.0\r\nNats-Msg-Id: 01\r\n\r\n")
+	assert.Equal(t, <-headers, "NATS/1.0\r\nNats-Msg-Id: 02\r\n\r\n")
+}
+
+func TestNATSSinkJetStream(t *testing.T) {
+	addr, headers := serveNATS(t, jetStreamAck)
+	config := testConfig(t, KindNATS, addr)
+	config.JetStream = true
+	sink := newNATSSink(config)
+	defer sink.Close()
+
+	assert.NilError(t, sink.Publish(context.Background(), Message{ID: "01", Payload: []byte("first")}))
+	assert.Equal(t, <-headers, "NATS/1.0\r\nNats-Msg-Id: 01\r\n\r\n")
+
+	addr, _ = serveNATS(t, func(int) string {
+		return `{"error":{"code":400,"err_code":10001,"description":"bad request"}}`
+	})
+	config = testConfig(t, KindNATS, addr)
+	config.JetStream = true
+	sink = newNATSSink(config)
+	defer sink.Close()
+	err := sink.Publish(context.Background(), Message{ID: "01", Payload: []byte("first")})
+	assert.ErrorContains(t, err, "bad request")
+}
+
+// kafkaTransport answers the requests of the Kafka writer like a cluster with a single broker
+// and a topic with a single partition. The produced records are sent to records.
+type kafkaTransport struct {
+	records chan kafkaRecord
+}
+
+func (k *kafkaTransport) RoundTrip(_ context.Context, _ net.Addr, req protocol.Message) (protocol.Message, error) {
+	switch req := req.(type) {
+	case *metadata.Request:
+		return &metadata.Response{
+			Brokers: []metadata.ResponseBroker{{NodeID: 1, Host: "127.0.0.1", Port: 9092}},
+			Topics: []metadata.ResponseTopic{{
+				Name:       "shutter.batches",
+				Partitions: []metadata.ResponsePartition{{PartitionIndex: 0, LeaderID: 1}},
+			}},
+		}, nil
+	case *produce.Request:
+		if req.Acks != -1 {
+			return nil, errors.Errorf("expected acks of all replicas, got %d", req.Acks)
+		}
+		response := &produce.Response{}
+		for _, topic := range req.Topics {
+			responseTopic := produce.ResponseTopic{Topic: topic.Topic}
+			for _, partition := range topic.Partitions {
+				for {
+					record, err := partition.RecordSet.Records.ReadRecord()
+					if errors.Is(err, io.EOF) {
+						break
+					} else if err != nil {
+						return nil, err
+					}
+					key, err := protocol.ReadAll(record.Key)
+					if err != nil {
+						return nil, err
+					}
+					value, err := protocol.ReadAll(record.Value)
+					if err != nil {
+						return nil, err
+					}
+					k.records <- kafkaRecord{Key: key, Value: value}
+				}
+				responseTopic.Partitions = append(responseTopic.Partitions, produce.ResponsePartition{
+					Partition: partition.Partition,
+				})
+			}
+			response.Topics = append(response.Topics, responseTopic)
+		}
+		return response, nil
+	default:
+		return nil, errors.Errorf("unexpected request %T", req)
+	}
+}
+
+func TestKafkaSink(t *testing.T) {
+	transport := &kafkaTransport{records: make(chan kafkaRecord, 10)}
+	sink := newKafkaSink(testConfig(t, KindKafka, "127.0.0.1:9092"))
+	sink.transport = transport
+	defer sink.Close()
+
+	ctx := context.Background()
+	assert.NilError(t, sink.Publish(ctx, Message{ID: "01", Payload: []byte("first")}))
+	assert.NilError(t, sink.Publish(ctx, Message{ID: "02", Payload: []byte("second")}))
+	assert.DeepEqual(t, <-transport.records, kafkaRecord{Key: []byte("01"), Value: []byte("first")})
+	assert.DeepEqual(t, <-transport.records, kafkaRecord{Key: []byte("02"), Value: []byte("second")})
+}
+
+func TestKafkaRESTSink(t *testing.T) {
+	var received []kafkaRecord
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, "/topics/shutter.batches")
+		assert.Equal(t, r.Header.Get("Content-Type"), kafkaContentType)
+		var req kafkaProduceRequest
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&req))
+		received = append(received, req.Records...)
+		fmt.Fprint(w, `{"offsets":[{"partition":0,"offset":7,"error_code":null,"error":null}]}`)
+	}))
+	defer proxy.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error_code":40401,"message":"Topic not found."}`)
+	}))
+	defer failing.Close()
+
+	sink := newKafkaRESTSink(testConfig(t, KindKafkaREST, failing.URL, proxy.URL))
+	assert.NilError(t, sink.Publish(context.Background(), Message{ID: "01", Payload: []byte("first")}))
+	assert.Equal(t, sink.broker, 1)
+	assert.DeepEqual(t, received, []kafkaRecord{{Key: []byte("01"), Value: []byte("first")}})
+
+	sink = newKafkaRESTSink(testConfig(t, KindKafkaREST, failing.URL))
+	err := sink.Publish(context.Background(), Message{ID: "01", Payload: []byte("first")})
+	assert.ErrorContains(t, err, "Topic not found. (error code 40401)")
+}
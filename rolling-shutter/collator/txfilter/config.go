@@ -0,0 +1,95 @@
+package txfilter
+
+import (
+	"io"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+// Config configures the filters transactions submitted to the collator have to pass.
+type Config struct {
+	MaxTxSize              uint64            `comment:"Maximum size of a submitted transaction in bytes, 0 disables the check"`
+	AllowedSenders         []string          `comment:"If not empty, only transactions sent by these addresses are accepted"`
+	DeniedSenders          []string          `comment:"Transactions sent by these addresses are rejected"`
+	RateLimit              uint64            `comment:"Maximum number of transactions accepted per sender within RateLimitWindow, 0 disables rate limiting"`
+	RateLimitWindow        *enctime.Duration `comment:"Length of the window the rate limit applies to"`
+	ExternalFilterURL      string            `comment:"JSON RPC endpoint asked via shutter_filterTransaction whether to accept a transaction, empty disables it"`
+	ExternalFilterTimeout  *enctime.Duration `comment:"How long to wait for the answer of the external filter"`
+	ExternalFilterFailOpen bool              `comment:"Accept transactions if the external filter fails to answer instead of rejecting them"`
+}
+
+func (c *Config) Init() {
+	c.RateLimitWindow = &enctime.Duration{}
+	c.ExternalFilterTimeout = &enctime.Duration{}
+}
+
+func (c *Config) Name() string {
+	return "txfilter"
+}
+
+func (c *Config) Validate() error {
+	allowed, err := parseAddresses(c.AllowedSenders)
+	if err != nil {
+		return errors.WithMessage(err, "invalid AllowedSenders")
+	}
+	denied, err := parseAddresses(c.DeniedSenders)
+	if err != nil {
+		return errors.WithMessage(err, "invalid DeniedSenders")
+	}
+	for address := range denied {
+		if allowed[address] {
+			return errors.Errorf("sender %s is both allowed and denied", address.Hex())
+		}
+	}
+	if c.RateLimit > 0 && c.RateLimitWindow.Duration <= 0 {
+		return errors.New("RateLimitWindow must be positive if a RateLimit is set")
+	}
+	if c.ExternalFilterURL != "" && c.ExternalFilterTimeout.Duration <= 0 {
+		return errors.New("ExternalFilterTimeout must be positive if an ExternalFilterURL is set")
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.MaxTxSize = 0
+	c.AllowedSenders = []string{}
+	c.DeniedSenders = []string{}
+	c.RateLimit = 0
+	c.RateLimitWindow = &enctime.Duration{Duration: time.Minute}
+	c.ExternalFilterURL = ""
+	c.ExternalFilterTimeout = &enctime.Duration{Duration: time.Second}
+	c.ExternalFilterFailOpen = false
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
+
+func parseAddresses(addresses []string) (map[common.Address]bool, error) {
+	parsed := map[common.Address]bool{}
+	for _, a := range addresses {
+		if !common.IsHexAddress(a) {
+			return nil, errors.Errorf("invalid address %q", a)
+		}
+		parsed[common.HexToAddress(a)] = true
+	}
+	return parsed, nil
+}
@@ -0,0 +1,27 @@
+package txfilter
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsRejects = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "txfilter",
+		Name:      "rejects_total",
+		Help:      "Number of submitted transactions rejected, by rule",
+	},
+	[]string{"rule"},
+)
+
+var metricsExternalErrors = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "txfilter",
+		Name:      "external_errors_total",
+		Help:      "Number of calls to the external filter that failed",
+	},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsRejects)
+	prometheus.MustRegister(metricsExternalErrors)
+}
@@ -0,0 +1,243 @@
+// Package txfilter lets operators enforce local policy on the encrypted transactions submitted to
+// the collator, e.g. to comply with regulations or to fend off spam. A submitted transaction has to
+// pass a chain of rules before it is accepted. The built-in rules check the size of the
+// transaction, its sender against allow and deny lists and the number of transactions per sender
+// within a time window. Further policy can be implemented by an external filter, a JSON RPC server
+// asked about every transaction.
+package txfilter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ExternalFilterMethod is the JSON RPC method called on the external filter. It takes a Request
+// and returns a Verdict.
+const ExternalFilterMethod = "shutter_filterTransaction"
+
+// Tx is a submitted transaction. Bytes holds the encoding of the transaction as submitted; its
+// payload is encrypted.
+type Tx struct {
+	Sender     common.Address
+	Hash       common.Hash
+	BatchIndex uint64
+	Bytes      []byte
+}
+
+// Rule is a single check of the filter chain.
+type Rule interface {
+	// Name identifies the rule in errors and metrics.
+	Name() string
+	// Check returns an error describing why the transaction is rejected, nil if it's accepted.
+	Check(ctx context.Context, tx *Tx) error
+}
+
+// Chain checks transactions against a list of rules, in order.
+type Chain struct {
+	rules []Rule
+}
+
+// NewChain creates a chain of the given rules.
+func NewChain(rules ...Rule) *Chain {
+	return &Chain{rules: rules}
+}
+
+// New creates the chain of the rules enabled by a validated config.
+func New(ctx context.Context, config *Config) (*Chain, error) {
+	c := &Chain{}
+	if config.MaxTxSize > 0 {
+		c.rules = append(c.rules, &sizeRule{maxSize: config.MaxTxSize})
+	}
+	if len(config.AllowedSenders) > 0 || len(config.DeniedSenders) > 0 {
+		allowed, err := parseAddresses(config.AllowedSenders)
+		if err != nil {
+			return nil, err
+		}
+		denied, err := parseAddresses(config.DeniedSenders)
+		if err != nil {
+			return nil, err
+		}
+		c.rules = append(c.rules, &senderRule{allowed: allowed, denied: denied})
+	}
+	if config.RateLimit > 0 {
+		c.rules = append(c.rules, newRateLimitRule(config.RateLimit, config.RateLimitWindow.Duration, time.Now))
+	}
+	if config.ExternalFilterURL != "" {
+		client, err := rpc.DialContext(ctx, config.ExternalFilterURL)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to connect to external filter")
+		}
+		c.rules = append(c.rules, &externalRule{
+			client:   client,
+			timeout:  config.ExternalFilterTimeout.Duration,
+			failOpen: config.ExternalFilterFailOpen,
+		})
+	}
+	return c, nil
+}
+
+// Check returns an error naming the first rule that rejects the transaction, nil if all rules
+// accept it.
+func (c *Chain) Check(ctx context.Context, tx *Tx) error {
+	for _, rule := range c.rules {
+		if err := rule.Check(ctx, tx); err != nil {
+			metricsRejects.WithLabelValues(rule.Name()).Inc()
+			log.Debug().Err(err).Str("rule", rule.Name()).Str("sender", tx.Sender.Hex()).
+				Str("tx-hash", tx.Hash.Hex()).Msg("filter rejected transaction")
+			return errors.WithMessagef(err, "rejected by %s filter", rule.Name())
+		}
+	}
+	return nil
+}
+
+type sizeRule struct {
+	maxSize uint64
+}
+
+func (r *sizeRule) Name() string {
+	return "size"
+}
+
+func (r *sizeRule) Check(_ context.Context, tx *Tx) error {
+	if uint64(len(tx.Bytes)) > r.maxSize {
+		return errors.Errorf("transaction has %d bytes, at most %d are allowed", len(tx.Bytes), r.maxSize)
+	}
+	return nil
+}
+
+type senderRule struct {
+	allowed map[common.Address]bool
+	denied  map[common.Address]bool
+}
+
+func (r *senderRule) Name() string {
+	return "sender"
+}
+
+func (r *senderRule) Check(_ context.Context, tx *Tx) error {
+	if r.denied[tx.Sender] {
+		return errors.Errorf("sender %s is denied", tx.Sender.Hex())
+	}
+	if len(r.allowed) > 0 && !r.allowed[tx.Sender] {
+		return errors.Errorf("sender %s is not allowed", tx.Sender.Hex())
+	}
+	return nil
+}
+
+// rateLimitRule limits the number of transactions per sender within fixed windows starting with
+// the first transaction of the sender.
+type rateLimitRule struct {
+	limit  uint64
+	window time.Duration
+	now    func() time.Time
+
+	mux       sync.Mutex
+	senders   map[common.Address]*senderWindow
+	lastPrune time.Time
+}
+
+type senderWindow struct {
+	start time.Time
+	count uint64
+}
+
+func newRateLimitRule(limit uint64, window time.Duration, now func() time.Time) *rateLimitRule {
+	return &rateLimitRule{
+		limit:     limit,
+		window:    window,
+		now:       now,
+		senders:   make(map[common.Address]*senderWindow),
+		lastPrune: now(),
+	}
+}
+
+func (r *rateLimitRule) Name() string {
+	return "rate-limit"
+}
+
+func (r *rateLimitRule) Check(_ context.Context, tx *Tx) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	now := r.now()
+	if now.Sub(r.lastPrune) >= r.window {
+		// forget the senders whose window has passed, so that the map doesn't grow unbounded
+		for sender, w := range r.senders {
+			if now.Sub(w.start) >= r.window {
+				delete(r.senders, sender)
+			}
+		}
+		r.lastPrune = now
+	}
+	w, ok := r.senders[tx.Sender]
+	if !ok || now.Sub(w.start) >= r.window {
+		w = &senderWindow{start: now}
+		r.senders[tx.Sender] = w
+	}
+	if w.count >= r.limit {
+		return errors.Errorf("sender %s submitted more than %d transactions within %s", tx.Sender.Hex(), r.limit, r.window)
+	}
+	w.count++
+	return nil
+}
+
+// Request is the parameter of ExternalFilterMethod.
+type Request struct {
+	Sender     common.Address `json:"sender"`
+	TxHash     common.Hash    `json:"txHash"`
+	BatchIndex hexutil.Uint64 `json:"batchIndex"`
+	Tx         hexutil.Bytes  `json:"tx"`
+}
+
+// Verdict is the result of ExternalFilterMethod. Reason should explain why a transaction is
+// rejected, it's returned to the submitter.
+type Verdict struct {
+	Accept bool   `json:"accept"`
+	Reason string `json:"reason"`
+}
+
+type externalRule struct {
+	client   *rpc.Client
+	timeout  time.Duration
+	failOpen bool
+}
+
+func (r *externalRule) Name() string {
+	return "external"
+}
+
+func (r *externalRule) Check(ctx context.Context, tx *Tx) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	verdict := Verdict{}
+	err := r.client.CallContext(ctx, &verdict, ExternalFilterMethod, Request{
+		Sender:     tx.Sender,
+		TxHash:     tx.Hash,
+		BatchIndex: hexutil.Uint64(tx.BatchIndex),
+		Tx:         tx.Bytes,
+	})
+	if err != nil {
+		metricsExternalErrors.Inc()
+		if r.failOpen {
+			log.Warn().Err(err).Str("tx-hash", tx.Hash.Hex()).Msg("external filter failed, accepting transaction")
+			return nil
+		}
+		log.Warn().Err(err).Str("tx-hash", tx.Hash.Hex()).Msg("external filter failed, rejecting transaction")
+		return errors.New("external filter unavailable")
+	}
+	if !verdict.Accept {
+		if verdict.Reason == "" {
+			return errors.New("transaction not accepted")
+		}
+		return errors.New(verdict.Reason)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package txfilter
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gotest.tools/v3/assert"
+
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+var (
+	alice = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob   = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	carol = common.HexToAddress("0x3333333333333333333333333333333333333333")
+)
+
+func TestConfigValidate(t *testing.T) {
+	config := NewConfig()
+	assert.NilError(t, config.SetDefaultValues())
+	assert.NilError(t, config.Validate())
+
+	config.AllowedSenders = []string{alice.Hex(), bob.Hex()}
+	config.DeniedSenders = []string{bob.Hex()}
+	assert.ErrorContains(t, config.Validate(), "both allowed and denied")
+
+	config.DeniedSenders = []string{"0x1234"}
+	assert.ErrorContains(t, config.Validate(), "invalid DeniedSenders")
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	config := NewConfig()
+	assert.NilError(t, config.SetDefaultValues())
+	config.MaxTxSize = 4
+	config.DeniedSenders = []string{carol.Hex()}
+	assert.NilError(t, config.Validate())
+	chain, err := New(ctx, config)
+	assert.NilError(t, err)
+
+	assert.NilError(t, chain.Check(ctx, &Tx{Sender: alice, Bytes: []byte{1, 2, 3, 4}}))
+	assert.ErrorContains(t, chain.Check(ctx, &Tx{Sender: alice, Bytes: []byte{1, 2, 3, 4, 5}}),
+		"rejected by size filter")
+	assert.ErrorContains(t, chain.Check(ctx, &Tx{Sender: carol}), "rejected by sender filter")
+
+	config.DeniedSenders = nil
+	config.AllowedSenders = []string{alice.Hex()}
+	chain, err = New(ctx, config)
+	assert.NilError(t, err)
+	assert.NilError(t, chain.Check(ctx, &Tx{Sender: alice}))
+	assert.ErrorContains(t, chain.Check(ctx, &Tx{Sender: bob}), "is not allowed")
+}
+
+func TestRateLimit(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	rule := newRateLimitRule(2, time.Minute, func() time.Time { return now })
+
+	assert.NilError(t, rule.Check(ctx, &Tx{Sender: alice}))
+	assert.NilError(t, rule.Check(ctx, &Tx{Sender: alice}))
+	assert.ErrorContains(t, rule.Check(ctx, &Tx{Sender: alice}), "more than 2 transactions")
+	assert.NilError(t, rule.Check(ctx, &Tx{Sender: bob}))
+
+	now = now.Add(time.Minute)
+	assert.NilError(t, rule.Check(ctx, &Tx{Sender: alice}))
+	assert.Equal(t, len(rule.senders), 1)
+}
+
+type filterService struct{}
+
+func (filterService) FilterTransaction(req Request) Verdict {
+	if req.Sender == bob {
+		return Verdict{Accept: false, Reason: "sanctioned"}
+	}
+	return Verdict{Accept: true}
+}
+
+func TestExternalFilter(t *testing.T) {
+	ctx := context.Background()
+	server := rpc.NewServer()
+	assert.NilError(t, server.RegisterName("shutter", filterService{}))
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	config := NewConfig()
+	assert.NilError(t, config.SetDefaultValues())
+	config.ExternalFilterURL = httpServer.URL
+	assert.NilError(t, config.Validate())
+	chain, err := New(ctx, config)
+	assert.NilError(t, err)
+	assert.NilError(t, chain.Check(ctx, &Tx{Sender: alice}))
+	assert.ErrorContains(t, chain.Check(ctx, &Tx{Sender: bob}), "rejected by external filter: sanctioned")
+
+	// an unreachable filter rejects all transactions unless it fails open
+	httpServer.Close()
+	assert.ErrorContains(t, chain.Check(ctx, &Tx{Sender: alice}), "external filter unavailable")
+	config.ExternalFilterFailOpen = true
+	config.ExternalFilterTimeout = &enctime.Duration{Duration: time.Second}
+	chain, err = New(ctx, config)
+	assert.NilError(t, err)
+	assert.NilError(t, chain.Check(ctx, &Tx{Sender: alice}))
+}
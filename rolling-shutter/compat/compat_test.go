@@ -0,0 +1,126 @@
+package compat
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/metadb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/migration"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// fixtureMessage is a p2p message as published by a previous release.
+type fixtureMessage struct {
+	Topic  string          `json:"topic"`
+	Type   string          `json:"type"`
+	Data   hexutil.Bytes   `json:"data"`
+	Signer *common.Address `json:"signer,omitempty"`
+}
+
+// releases returns the fixture directories of all previous releases.
+func releases(t *testing.T) []string {
+	t.Helper()
+	dirs, err := filepath.Glob(filepath.Join("testdata", "*"))
+	assert.NilError(t, err)
+	assert.Assert(t, len(dirs) > 0, "no fixtures found")
+	return dirs
+}
+
+func readMessages(t *testing.T, dir string) []fixtureMessage {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "p2pmsgs.json"))
+	assert.NilError(t, err)
+	var msgs []fixtureMessage
+	assert.NilError(t, json.Unmarshal(data, &msgs))
+	return msgs
+}
+
+func TestP2PMessages(t *testing.T) {
+	for _, dir := range releases(t) {
+		for i, fixture := range readMessages(t, dir) {
+			t.Run(filepath.Base(dir)+"/"+fixture.Type, func(t *testing.T) {
+				msg, _, err := p2pmsg.UnmarshalTopic(fixture.Topic, fixture.Data)
+				assert.NilError(t, err, "message %d", i)
+				assert.Equal(t, reflect.TypeOf(msg).Elem().Name(), fixture.Type)
+				assert.NilError(t, msg.Validate())
+
+				if fixture.Signer != nil {
+					signable, ok := msg.(p2pmsg.Signable)
+					assert.Assert(t, ok, "%s is not signed", fixture.Type)
+					ok, err := p2pmsg.VerifySignature(signable, *fixture.Signer)
+					assert.NilError(t, err)
+					assert.Check(t, ok, "invalid signature")
+				}
+
+				// Old nodes must accept what we publish, on the topics they subscribe to.
+				assert.Equal(t, msg.Topic(), fixture.Topic)
+				data, err := p2pmsg.Marshal(msg, nil)
+				assert.NilError(t, err)
+				assert.DeepEqual(t, data, []byte(fixture.Data))
+			})
+		}
+	}
+}
+
+func countRows(ctx context.Context, t *testing.T, db kprdb.DBTX, query string) int64 {
+	t.Helper()
+	var n int64
+	assert.NilError(t, db.QueryRow(ctx, query).Scan(&n))
+	return n
+}
+
+func TestKeyperDBIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+
+	names := []string{}
+	for name := range kprdb.Migrations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, dir := range releases(t) {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			dump, err := os.ReadFile(filepath.Join(dir, "kprdb.sql"))
+			assert.NilError(t, err)
+			dbpool, closedb := testdb.NewTestDBPool(ctx, t)
+			defer closedb()
+			_, err = dbpool.Exec(ctx, string(dump))
+			assert.NilError(t, err)
+
+			// Incompatible schema changes aren't migrated. The keyper has to refuse to start
+			// instead of misinterpreting the old tables.
+			assert.ErrorIs(t, kprdb.ValidateKeyperDB(ctx, dbpool), metadb.ErrSchemaMismatch)
+
+			shares := countRows(ctx, t, dbpool, "SELECT count(*) FROM decryption_key_share")
+			keys := countRows(ctx, t, dbpool, "SELECT count(*) FROM decryption_key")
+			for _, name := range names {
+				m := kprdb.Migrations[name]
+				assert.NilError(t, m.RunExpand(ctx, dbpool), name)
+				assert.NilError(t, m.RunBackfill(ctx, dbpool, 3), name)
+				assert.NilError(t, m.RunSwitch(ctx, dbpool), name)
+				assert.NilError(t, m.RunContract(ctx, dbpool), name)
+				phase, err := m.Phase(ctx, dbpool)
+				assert.NilError(t, err)
+				assert.Equal(t, phase, migration.PhaseContracted)
+			}
+			assert.Equal(t, countRows(ctx, t, dbpool, "SELECT count(*) FROM decryption_key_share"), shares)
+			assert.Equal(t, countRows(ctx, t, dbpool, "SELECT count(*) FROM decryption_key"), keys)
+			assert.Equal(t, countRows(ctx, t, dbpool,
+				"SELECT count(*) FROM decryption_key_share WHERE created_at IS NULL"), int64(0))
+		})
+	}
+}
@@ -0,0 +1,11 @@
+// Package compat checks that the current code is compatible with data written by previous
+// releases, so that the nodes of a live keyper set can be upgraded one by one.
+//
+// Each directory in testdata contains golden fixtures of one release, named after the keyper
+// schema version of that release:
+//
+//   - kprdb.sql: a keyper database dump, which the online migrations are applied to
+//   - p2pmsgs.json: p2p messages as published by that release, which must still be accepted
+//
+// The fixtures must never be changed. When cutting a release, add a directory for it instead.
+package compat
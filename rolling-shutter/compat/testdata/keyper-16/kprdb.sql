@@ -0,0 +1,163 @@
+-- Keyper database as written by the release using schema version keyper-16: the schemas of
+-- kprdb, chainobsdb and metadb of that release followed by the rows of a keyper that has gone
+-- through one eon. Do not edit, add a directory for the next release instead.
+
+-- schema-version: keyper-16 --
+-- Please change the version above if you make incompatible changes to
+-- the schema. We'll use this to check we're using the right schema.
+
+CREATE TABLE decryption_trigger (
+       epoch_id bytea PRIMARY KEY
+);
+CREATE TABLE decryption_key_share (
+       eon bigint,
+       epoch_id bytea,
+       keyper_index bigint,
+       decryption_key_share bytea,
+       PRIMARY KEY (eon, epoch_id, keyper_index)
+);
+CREATE TABLE decryption_key (
+       eon bigint,
+       epoch_id bytea,
+       decryption_key bytea,
+       PRIMARY KEY (eon, epoch_id)
+);
+
+----- tendermint events
+
+-- store the last batch config message we sent to shuttermint. We store this in order to prevent us
+-- from sending the message multiple times.
+CREATE TABLE last_batch_config_sent(
+       enforce_one_row BOOL PRIMARY KEY DEFAULT TRUE,
+       keyper_config_index bigint NOT NULL
+);
+INSERT INTO last_batch_config_sent (keyper_config_index) VALUES (0);
+
+-- store the last block number seen we sent to shuttermint.
+CREATE TABLE last_block_seen(
+       enforce_one_row BOOL PRIMARY KEY DEFAULT TRUE,
+       block_number bigint NOT NULL
+);
+INSERT INTO last_block_seen (block_number) VALUES (-1);
+
+-- tendermint_sync_meta contains meta information about the synchronization process with the
+-- tendermint app. At the moment we just insert new entries into the table and sort by
+-- current_block to get the latest entry. When handling new events from shuttermint, we do that in
+-- batches inside a PostgreSQL transaction. last_committed_height is the last block that we know is
+-- available, current_block is the last block in the batch we're currently handling.
+CREATE TABLE tendermint_sync_meta (
+       current_block bigint NOT NULL,
+       last_committed_height bigint NOT NULL,
+       sync_timestamp timestamp NOT NULL,
+       PRIMARY KEY (current_block, last_committed_height)
+);
+
+-- puredkg contains a gob serialized puredkg instance.  We already have the DKG process
+-- implemented in go, without any database access.  When new events come in, we feed those to the
+-- go object and store it afterwards in the puredkg table.
+CREATE TABLE puredkg (
+       eon bigint PRIMARY KEY,
+       puredkg BYTEA NOT NULL
+);
+
+CREATE TABLE tendermint_batch_config(
+       keyper_config_index integer PRIMARY KEY,
+       height bigint NOT NULL,
+       keypers text[] NOT NULL,
+       threshold integer NOT NULL,
+       started boolean NOT NULL,
+       activation_block_number bigint NOT NULL
+);
+
+CREATE TABLE tendermint_encryption_key(
+       address TEXT PRIMARY KEY,
+       encryption_public_key BYTEA NOT NULL
+);
+
+CREATE TABLE tendermint_outgoing_messages(
+       id SERIAL PRIMARY KEY,
+       description TEXT NOT NULL,
+       msg BYTEA NOT NULL
+);
+
+CREATE TABLE eons(
+       eon bigint PRIMARY KEY,
+       height bigint NOT NULL,
+       activation_block_number bigint NOT NULL,
+       keyper_config_index bigint NOT NULL
+);
+
+CREATE TABLE poly_evals(
+       eon bigint NOT NULL,
+       receiver_address TEXT NOT NULL,
+       eval BYTEA NOT NULL,
+       PRIMARY KEY (eon, receiver_address)
+);
+
+-- dkg_result contains the result of running the DKG process or an error message, if the DKG
+-- process failed.
+CREATE TABLE dkg_result(
+       eon bigint PRIMARY KEY,
+       success BOOLEAN NOT NULL,
+       error TEXT,
+       pure_result BYTEA  -- shdb.EncodePureDKGResult/shdb.DecodePureDKGResult
+);
+
+-- outgoing_eon_keys contains the eon public key(s) that should be broadcast as a result of a successful DKG
+CREATE TABLE outgoing_eon_keys(
+       eon_public_key bytea,
+       eon bigint NOT NULL PRIMARY KEY
+);
+
+CREATE TABLE event_sync_progress (
+       id bool UNIQUE NOT NULL DEFAULT true,
+       next_block_number integer NOT NULL,
+       next_log_index integer NOT NULL
+);
+INSERT INTO event_sync_progress (next_block_number, next_log_index) VALUES (0,0);
+
+CREATE TABLE keyper_set(
+       keyper_config_index bigint NOT NULL,
+       activation_block_number bigint NOT NULL,
+       keypers text[] NOT NULL,
+       threshold integer NOT NULL,
+       PRIMARY KEY (keyper_config_index)
+);
+
+CREATE TABLE chain_collator(
+       activation_block_number bigint PRIMARY KEY,
+       collator text NOT NULL
+);
+
+CREATE TABLE meta_inf(
+       key text PRIMARY KEY,
+       value text NOT NULL
+);
+
+INSERT INTO meta_inf (key, value) VALUES ('schema-version', 'keyper-16');
+INSERT INTO tendermint_sync_meta (current_block, last_committed_height, sync_timestamp) VALUES (1200, 1200, '2023-06-01 12:00:00');
+INSERT INTO tendermint_batch_config (keyper_config_index, height, keypers, threshold, started, activation_block_number) VALUES (1, 10, ARRAY['0x6d851af346eb7ceb578f70b789f025ab64294dd3', '0xc609249407374d4427175f07b42bae903a528101', '0x7d31dc7fb7cd20a19bcd029af58a9a03992ae4b7'], 2, true, 100);
+INSERT INTO keyper_set (keyper_config_index, activation_block_number, keypers, threshold) VALUES (1, 100, ARRAY['0x6d851af346eb7ceb578f70b789f025ab64294dd3', '0xc609249407374d4427175f07b42bae903a528101', '0x7d31dc7fb7cd20a19bcd029af58a9a03992ae4b7'], 2);
+INSERT INTO eons (eon, height, activation_block_number, keyper_config_index) VALUES (1, 20, 100, 1);
+INSERT INTO dkg_result (eon, success, error, pure_result) VALUES (1, false, 'fixture without dkg result', NULL);
+INSERT INTO outgoing_eon_keys (eon_public_key, eon) VALUES ('\x2e1a277292d48274ce20a1bc9a44a12c7ec817830ed3c26b969dc33d8b0aeb5829f0909b60fa8ca5d841406f6a86be6ce89e9381f27f2e6e698840f7faf297fb2927099965f41a9b36384125d805b42eff654de4893a72aa8eed8241dca175e5219246e5b48b9bb790931c3c4385b8e50888a0cf3c2796a577603ad48d12e49f', 1);
+UPDATE last_batch_config_sent SET keyper_config_index = 1;
+UPDATE last_block_seen SET block_number = 150;
+UPDATE event_sync_progress SET next_block_number = 151, next_log_index = 0;
+INSERT INTO decryption_trigger (epoch_id) VALUES ('\x0000000000000000000000000000000000000000000000000000000000000001');
+INSERT INTO decryption_trigger (epoch_id) VALUES ('\x0000000000000000000000000000000000000000000000000000000000000002');
+INSERT INTO decryption_trigger (epoch_id) VALUES ('\x0000000000000000000000000000000000000000000000000000000000000003');
+INSERT INTO decryption_trigger (epoch_id) VALUES ('\x0000000000000000000000000000000000000000000000000000000000000004');
+INSERT INTO decryption_key_share (eon, epoch_id, keyper_index, decryption_key_share) VALUES (1, '\x0000000000000000000000000000000000000000000000000000000000000001', 0, '\x2723986e8dfc3bd4d4c9d9eee2cf862330b1ea17b28e281631d56dc2d6047ab518a67bafd1d01664d7f431046d0693afa8eb281192e41bb7acdca67b1f777125');
+INSERT INTO decryption_key_share (eon, epoch_id, keyper_index, decryption_key_share) VALUES (1, '\x0000000000000000000000000000000000000000000000000000000000000001', 1, '\x29a6ad0ac41ec678d13e7f725920babe40e7dbc57f784d86d2f55eef85770ac10aacc1b83c5adb9b13053c92fda75b2ae750889ac2cee7098f81b95921eccf09');
+INSERT INTO decryption_key_share (eon, epoch_id, keyper_index, decryption_key_share) VALUES (1, '\x0000000000000000000000000000000000000000000000000000000000000001', 2, '\x2e5e3386fc666e35e3a4c0defece9de5c26f9ae584d1d5a276d6d27ef43fd85a0c1885ba632d3f46f64e84d162fa99c6abcbd19aa8f500b62afb5d7119ceed99');
+INSERT INTO decryption_key_share (eon, epoch_id, keyper_index, decryption_key_share) VALUES (1, '\x0000000000000000000000000000000000000000000000000000000000000002', 0, '\x104c4bd9dfa87fe1571f8152d8bf9e165d09576292ca9a9da8ca51b41609865f1fc7dfdb09117c5e1edb53b12cd2ce719da80cd743020a620a507ae818df0f76');
+INSERT INTO decryption_key_share (eon, epoch_id, keyper_index, decryption_key_share) VALUES (1, '\x0000000000000000000000000000000000000000000000000000000000000002', 1, '\x117b77d521a4cfe6c32bf4a2abe18641278ab7bd2251330218ecc7e108ea00a82d334d9356f5cb25729184500814ea904ba3c801935a0de697cbdd1b45a160ed');
+INSERT INTO decryption_key_share (eon, epoch_id, keyper_index, decryption_key_share) VALUES (1, '\x0000000000000000000000000000000000000000000000000000000000000002', 2, '\x2c97ae1dc91811e5806112fbae48f502e930a22601f8db354e6c39a27043881e2cdcaf639efa40c064da8c4b103f26fb77346b3eb1b12910d0d84022f542c31e');
+INSERT INTO decryption_key_share (eon, epoch_id, keyper_index, decryption_key_share) VALUES (1, '\x0000000000000000000000000000000000000000000000000000000000000003', 0, '\x183fac86e80675f3343b75392052c696a9de26901691235883086883bd5d801016ca559c2f4bca0338daaac1fe0852887e9d44f58bac00276adb9f37eba8602c');
+INSERT INTO decryption_key_share (eon, epoch_id, keyper_index, decryption_key_share) VALUES (1, '\x0000000000000000000000000000000000000000000000000000000000000003', 1, '\x0530c35a60630ca951847d9db0c8f1dee6465573b4b3ab838ba6721b0883d5ac2e60dd1f3e1c82bac61a433d1160dca8158f76686abb1c661e39544db511b1a7');
+INSERT INTO decryption_key_share (eon, epoch_id, keyper_index, decryption_key_share) VALUES (1, '\x0000000000000000000000000000000000000000000000000000000000000003', 2, '\x2a296a9bd55a76d5748dfd47378923b1c385313a3e867a5716007de2d151802807d07f582bac7aa6c0d041faa66b725900b109c19127116b14c21648980777f3');
+INSERT INTO decryption_key_share (eon, epoch_id, keyper_index, decryption_key_share) VALUES (1, '\x0000000000000000000000000000000000000000000000000000000000000004', 0, '\x1d521e8dea80ac7f37a8e06361baed2c2d1bfee57464ebcf6307e98eb86d84600f6ae873bd3e0c73281db735c28d656a602a7d9b90ac95f308930dfda4a5ea53');
+INSERT INTO decryption_key (eon, epoch_id, decryption_key) VALUES (1, '\x0000000000000000000000000000000000000000000000000000000000000001', '\x2bf1f96f1ab2bee852e704e979bcab153c5122bedd9fedb6d3ac128300b20d48195bc0f85cca8f52f678fe615772762e62b99cd0f4364fd89d3aef027f8cf577');
+INSERT INTO decryption_key (eon, epoch_id, decryption_key) VALUES (1, '\x0000000000000000000000000000000000000000000000000000000000000002', '\x04bb12a64320ecca0af9617164f00fd45ddc47fafb1fcd55ad708adddb326b662a51a5653396913a93608bf44395b23f5bcefb8ee0b76f841a1d0dc11f9a7200');
+INSERT INTO decryption_key (eon, epoch_id, decryption_key) VALUES (1, '\x0000000000000000000000000000000000000000000000000000000000000003', '\x11459890a0de22f8c1518ceac6267739a45393f92258628d450a96c8d7556de52f55c38eda10484da82696437f62e5332a9f3af851a220dea3fa2ffcbf615760');
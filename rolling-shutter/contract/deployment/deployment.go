@@ -19,6 +19,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/pkg/errors"
 
@@ -53,6 +54,31 @@ type Contracts struct {
 	CollatorsAdded                *eventsyncer.EventType
 	CollatorsAppended             *eventsyncer.EventType
 	CollatorsOwnershipTransferred *eventsyncer.EventType
+
+	// KeyperRotationsDeployment and KeyperRotationsRotated are nil if the KeyperRotations
+	// contract has not been deployed. There are no bindings for it, so its events are yielded as
+	// eventsyncer.DynamicEvent.
+	KeyperRotationsDeployment *Deployment
+	KeyperRotationsRotated    *eventsyncer.EventType
+
+	// KeyperBondsDeployment and its event types are nil if the KeyperBonds contract has not been
+	// deployed. Its events are yielded as eventsyncer.DynamicEvent as well.
+	KeyperBondsDeployment         *Deployment
+	KeyperBondsBondChanged        *eventsyncer.EventType
+	KeyperBondsMinimumBondChanged *eventsyncer.EventType
+
+	// VersionRequirementsDeployment and VersionRequirementsMinimumVersionChanged are nil if the
+	// VersionRequirements contract has not been deployed.
+	VersionRequirementsDeployment            *Deployment
+	VersionRequirementsMinimumVersionChanged *eventsyncer.EventType
+
+	// DKGParametersDeployment and DKGParametersChanged are nil if the DKGParameters contract has
+	// not been deployed.
+	DKGParametersDeployment *Deployment
+	DKGParametersChanged    *eventsyncer.EventType
+
+	// backend is used by the contract bindings. It batches their calls with Multicall3.
+	backend bind.ContractBackend
 }
 
 // Deployments contains information about all deployed contracts loaded from a deployment
@@ -69,12 +95,16 @@ type Deployment struct {
 	Address           common.Address
 	ABI               abi.ABI
 	DeployBlockNumber uint64
+	// DeployedBytecode is the code of the contract according to the deployment artifact, or
+	// nil if the artifact doesn't contain it.
+	DeployedBytecode []byte
 }
 
 type deploymentJSON struct {
-	Address common.Address
-	ABI     []interface{}
-	Receipt receiptJSON
+	Address          common.Address
+	ABI              []interface{}
+	Receipt          receiptJSON
+	DeployedBytecode hexutil.Bytes
 }
 
 type receiptJSON struct {
@@ -90,6 +120,7 @@ func NewContracts(client *ethclient.Client, deploymentDir string) (*Contracts, e
 		Client:      client,
 		Deployments: deployments,
 	}
+	c.initBackend()
 	if err := c.initKeypersConfigsList(); err != nil {
 		return nil, err
 	}
@@ -102,17 +133,29 @@ func NewContracts(client *ethclient.Client, deploymentDir string) (*Contracts, e
 	if err := c.initCollator(); err != nil {
 		return nil, err
 	}
+	c.initKeyperRotations()
+	c.initKeyperBonds()
+	c.initVersionRequirements()
+	c.initDKGParameters()
 
 	return c, nil
 }
 
+func (c *Contracts) initBackend() {
+	multicallAddress := MulticallAddress
+	if d, ok := c.Deployments.Deployments["Multicall3"]; ok {
+		multicallAddress = d.Address
+	}
+	c.backend = batchingBackend{Client: c.Client, caller: NewBatchingCaller(c.Client, multicallAddress)}
+}
+
 func (c *Contracts) initKeypersConfigsList() error {
 	d, err := c.getDeployment("KeyperConfig")
 	if err != nil {
 		return err
 	}
 	c.KeypersConfigsListDeployment = d
-	c.KeypersConfigsList, err = contract.NewKeypersConfigsList(d.Address, c.Client)
+	c.KeypersConfigsList, err = contract.NewKeypersConfigsList(d.Address, c.backend)
 	if err != nil {
 		return err
 	}
@@ -134,7 +177,7 @@ func (c *Contracts) initCollatorConfigsList() error {
 		return err
 	}
 	c.CollatorConfigsListDeployment = d
-	c.CollatorConfigsList, err = contract.NewCollatorConfigsList(d.Address, c.Client)
+	c.CollatorConfigsList, err = contract.NewCollatorConfigsList(d.Address, c.backend)
 	if err != nil {
 		return err
 	}
@@ -156,7 +199,7 @@ func (c *Contracts) initKeypers() error {
 		return err
 	}
 	c.KeypersDeployment = d
-	c.Keypers, err = contract.NewAddrsSeq(d.Address, c.Client)
+	c.Keypers, err = contract.NewAddrsSeq(d.Address, c.backend)
 	if err != nil {
 		return err
 	}
@@ -194,7 +237,7 @@ func (c *Contracts) initCollator() error {
 		return err
 	}
 	c.CollatorsDeployment = d
-	c.Collators, err = contract.NewAddrsSeq(d.Address, c.Client)
+	c.Collators, err = contract.NewAddrsSeq(d.Address, c.backend)
 	if err != nil {
 		return err
 	}
@@ -226,6 +269,80 @@ func (c *Contracts) initCollator() error {
 	return nil
 }
 
+func (c *Contracts) initKeyperRotations() {
+	d, ok := c.Deployments.Deployments["KeyperRotations"]
+	if !ok {
+		return
+	}
+	c.KeyperRotationsDeployment = d
+	boundContract := bind.NewBoundContract(d.Address, d.ABI, c.Client, c.Client, c.Client)
+	c.KeyperRotationsRotated = &eventsyncer.EventType{
+		FromBlockNumber: d.DeployBlockNumber,
+		Contract:        boundContract,
+		Address:         d.Address,
+		ABI:             d.ABI,
+		Name:            "Rotated",
+		ContractName:    "KeyperRotations",
+	}
+}
+
+func (c *Contracts) initKeyperBonds() {
+	d, ok := c.Deployments.Deployments["KeyperBonds"]
+	if !ok {
+		return
+	}
+	c.KeyperBondsDeployment = d
+	boundContract := bind.NewBoundContract(d.Address, d.ABI, c.Client, c.Client, c.Client)
+	c.KeyperBondsBondChanged = &eventsyncer.EventType{
+		FromBlockNumber: d.DeployBlockNumber,
+		Contract:        boundContract,
+		Address:         d.Address,
+		ABI:             d.ABI,
+		Name:            "BondChanged",
+		ContractName:    "KeyperBonds",
+	}
+	c.KeyperBondsMinimumBondChanged = &eventsyncer.EventType{
+		FromBlockNumber: d.DeployBlockNumber,
+		Contract:        boundContract,
+		Address:         d.Address,
+		ABI:             d.ABI,
+		Name:            "MinimumBondChanged",
+		ContractName:    "KeyperBonds",
+	}
+}
+
+func (c *Contracts) initVersionRequirements() {
+	d, ok := c.Deployments.Deployments["VersionRequirements"]
+	if !ok {
+		return
+	}
+	c.VersionRequirementsDeployment = d
+	c.VersionRequirementsMinimumVersionChanged = &eventsyncer.EventType{
+		FromBlockNumber: d.DeployBlockNumber,
+		Contract:        bind.NewBoundContract(d.Address, d.ABI, c.Client, c.Client, c.Client),
+		Address:         d.Address,
+		ABI:             d.ABI,
+		Name:            "MinimumVersionChanged",
+		ContractName:    "VersionRequirements",
+	}
+}
+
+func (c *Contracts) initDKGParameters() {
+	d, ok := c.Deployments.Deployments["DKGParameters"]
+	if !ok {
+		return
+	}
+	c.DKGParametersDeployment = d
+	c.DKGParametersChanged = &eventsyncer.EventType{
+		FromBlockNumber: d.DeployBlockNumber,
+		Contract:        bind.NewBoundContract(d.Address, d.ABI, c.Client, c.Client, c.Client),
+		Address:         d.Address,
+		ABI:             d.ABI,
+		Name:            "DKGParametersChanged",
+		ContractName:    "DKGParameters",
+	}
+}
+
 func (c *Contracts) getDeployment(name string) (*Deployment, error) {
 	d, ok := c.Deployments.Deployments[name]
 	if !ok {
@@ -306,6 +423,7 @@ func LoadDeployment(path string, chainID uint64) (*Deployment, error) {
 		Address:           parsedDeployment.Address,
 		ABI:               parsedABI,
 		DeployBlockNumber: parsedDeployment.Receipt.BlockNumber,
+		DeployedBytecode:  parsedDeployment.DeployedBytecode,
 	}, nil
 }
 
@@ -0,0 +1,242 @@
+package deployment
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// MulticallAddress is the address the Multicall3 contract is deployed at on most chains. A
+// deployment named Multicall3 in the deployment directory takes precedence.
+var MulticallAddress = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+const (
+	// multicallWindow is the time calls are collected before they are sent in one batch.
+	multicallWindow = 10 * time.Millisecond
+	// maxMulticallSize is the maximum number of calls in one batch.
+	maxMulticallSize = 500
+	// multicallTimeout is the time a batch has to complete. Batches don't use the context of
+	// any of their calls, since each call may be canceled on its own.
+	multicallTimeout = 30 * time.Second
+)
+
+const multicallABIJSON = `[{
+	"name": "aggregate3",
+	"type": "function",
+	"stateMutability": "payable",
+	"inputs": [{"name": "calls", "type": "tuple[]", "components": [
+		{"name": "target", "type": "address"},
+		{"name": "allowFailure", "type": "bool"},
+		{"name": "callData", "type": "bytes"}
+	]}],
+	"outputs": [{"name": "returnData", "type": "tuple[]", "components": [
+		{"name": "success", "type": "bool"},
+		{"name": "returnData", "type": "bytes"}
+	]}]
+}]`
+
+var multicallABI = func() abi.ABI {
+	a, err := abi.JSON(strings.NewReader(multicallABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return a
+}()
+
+type multicallCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// BatchingCaller is a bind.ContractCaller that batches calls made concurrently into a single
+// call of the Multicall3 contract, so that bursts of contract reads, e.g. while catching up with
+// the chain, take one request to the RPC provider instead of one per read. If Multicall3 is not
+// deployed, calls are made one by one.
+type BatchingCaller struct {
+	caller  bind.ContractCaller
+	address common.Address
+	window  time.Duration
+
+	mux       sync.Mutex
+	available *bool
+	batches   map[string]*callBatch
+}
+
+type callBatch struct {
+	blockNumber *big.Int
+	calls       []*batchedCall
+}
+
+type batchedCall struct {
+	target common.Address
+	data   []byte
+	done   chan struct{}
+	result []byte
+	err    error
+}
+
+func NewBatchingCaller(caller bind.ContractCaller, multicallAddress common.Address) *BatchingCaller {
+	return &BatchingCaller{
+		caller:  caller,
+		address: multicallAddress,
+		window:  multicallWindow,
+		batches: make(map[string]*callBatch),
+	}
+}
+
+func (b *BatchingCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return b.caller.CodeAt(ctx, contract, blockNumber)
+}
+
+// CallContract makes the call as part of the next batch. Calls that send value, set a gas limit
+// or depend on the sender are made on their own.
+func (b *BatchingCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	batchable := msg.To != nil && msg.From == (common.Address{}) && msg.Gas == 0 &&
+		(msg.Value == nil || msg.Value.Sign() == 0) && len(msg.AccessList) == 0
+	if !batchable || !b.isAvailable(ctx) {
+		return b.caller.CallContract(ctx, msg, blockNumber)
+	}
+
+	call := &batchedCall{target: *msg.To, data: msg.Data, done: make(chan struct{})}
+	b.enqueue(call, blockNumber)
+	select {
+	case <-call.done:
+		return call.result, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// isAvailable checks once whether the Multicall3 contract is deployed.
+func (b *BatchingCaller) isAvailable(ctx context.Context) bool {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if b.available != nil {
+		return *b.available
+	}
+	code, err := b.caller.CodeAt(ctx, b.address, nil)
+	if err != nil {
+		// try again with the next call
+		return false
+	}
+	available := len(code) > 0
+	b.available = &available
+	if !available {
+		log.Info().Str("address", b.address.Hex()).Msg("Multicall3 is not deployed, contract calls are not batched")
+	}
+	return available
+}
+
+func (b *BatchingCaller) enqueue(call *batchedCall, blockNumber *big.Int) {
+	key := "latest"
+	if blockNumber != nil {
+		key = blockNumber.String()
+	}
+
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	batch, ok := b.batches[key]
+	if !ok {
+		batch = &callBatch{blockNumber: blockNumber}
+		b.batches[key] = batch
+		time.AfterFunc(b.window, func() { b.flush(key, batch) })
+	}
+	batch.calls = append(batch.calls, call)
+	if len(batch.calls) >= maxMulticallSize {
+		delete(b.batches, key)
+		go b.send(batch)
+	}
+}
+
+func (b *BatchingCaller) flush(key string, batch *callBatch) {
+	b.mux.Lock()
+	if b.batches[key] != batch {
+		// the batch has been sent already because it was full
+		b.mux.Unlock()
+		return
+	}
+	delete(b.batches, key)
+	b.mux.Unlock()
+	b.send(batch)
+}
+
+func (b *BatchingCaller) send(batch *callBatch) {
+	ctx, cancel := context.WithTimeout(context.Background(), multicallTimeout)
+	defer cancel()
+	defer func() {
+		for _, call := range batch.calls {
+			close(call.done)
+		}
+	}()
+
+	if len(batch.calls) == 1 {
+		call := batch.calls[0]
+		call.result, call.err = b.caller.CallContract(
+			ctx, ethereum.CallMsg{To: &call.target, Data: call.data}, batch.blockNumber,
+		)
+		return
+	}
+
+	results, err := b.aggregate(ctx, batch)
+	for i, call := range batch.calls {
+		switch {
+		case err != nil:
+			call.err = err
+		case !results[i].Success:
+			call.err = errors.Errorf("execution reverted: %s", hexutil.Encode(results[i].ReturnData))
+		default:
+			call.result = results[i].ReturnData
+		}
+	}
+}
+
+func (b *BatchingCaller) aggregate(ctx context.Context, batch *callBatch) ([]multicallResult, error) {
+	calls := make([]multicallCall, len(batch.calls))
+	for i, call := range batch.calls {
+		calls[i] = multicallCall{Target: call.target, AllowFailure: true, CallData: call.data}
+	}
+	input, err := multicallABI.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode multicall")
+	}
+	output, err := b.caller.CallContract(ctx, ethereum.CallMsg{To: &b.address, Data: input}, batch.blockNumber)
+	if err != nil {
+		return nil, errors.Wrapf(err, "multicall of %d calls failed", len(calls))
+	}
+	values, err := multicallABI.Unpack("aggregate3", output)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode multicall result")
+	}
+	results := *abi.ConvertType(values[0], new([]multicallResult)).(*[]multicallResult)
+	if len(results) != len(calls) {
+		return nil, errors.Errorf("multicall returned %d results for %d calls", len(results), len(calls))
+	}
+	return results, nil
+}
+
+// batchingBackend is a bind.ContractBackend whose calls are batched.
+type batchingBackend struct {
+	*ethclient.Client
+	caller *BatchingCaller
+}
+
+func (b batchingBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return b.caller.CallContract(ctx, msg, blockNumber)
+}
@@ -0,0 +1,130 @@
+package deployment
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+)
+
+var (
+	echoContract   = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	revertContract = common.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+// fakeChain echoes the call data of calls to echoContract, reverts calls to revertContract and
+// implements Multicall3 at MulticallAddress if deployed.
+type fakeChain struct {
+	deployed bool
+
+	mux   sync.Mutex
+	calls int
+}
+
+func (c *fakeChain) CodeAt(_ context.Context, contract common.Address, _ *big.Int) ([]byte, error) {
+	if contract == MulticallAddress && !c.deployed {
+		return nil, nil
+	}
+	return []byte{1}, nil
+}
+
+func (c *fakeChain) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	c.mux.Lock()
+	c.calls++
+	c.mux.Unlock()
+	if *msg.To == MulticallAddress {
+		return c.aggregate(msg.Data)
+	}
+	return call(*msg.To, msg.Data)
+}
+
+func (c *fakeChain) aggregate(input []byte) ([]byte, error) {
+	method := multicallABI.Methods["aggregate3"]
+	values, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil, err
+	}
+	calls := *abi.ConvertType(values[0], new([]multicallCall)).(*[]multicallCall)
+	results := []multicallResult{}
+	for _, mc := range calls {
+		output, err := call(mc.Target, mc.CallData)
+		results = append(results, multicallResult{Success: err == nil, ReturnData: output})
+	}
+	return method.Outputs.Pack(results)
+}
+
+func call(target common.Address, data []byte) ([]byte, error) {
+	if target == revertContract {
+		return nil, errors.New("execution reverted")
+	}
+	return data, nil
+}
+
+func (c *fakeChain) numCalls() int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.calls
+}
+
+func callConcurrently(t *testing.T, caller *BatchingCaller, targets []common.Address) []error {
+	t.Helper()
+	ctx := context.Background()
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		i, target := i, target
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data := []byte{byte(i)}
+			output, err := caller.CallContract(ctx, ethereum.CallMsg{To: &target, Data: data}, nil)
+			if err == nil && !bytes.Equal(output, data) {
+				err = errors.Errorf("call %d returned %x", i, output)
+			}
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+func TestBatchingCaller(t *testing.T) {
+	chain := &fakeChain{deployed: true}
+	caller := NewBatchingCaller(chain, MulticallAddress)
+	// make sure all calls end up in the same batch
+	caller.window = 200 * time.Millisecond
+
+	targets := make([]common.Address, 20)
+	for i := range targets {
+		targets[i] = echoContract
+	}
+	targets[7] = revertContract
+	errs := callConcurrently(t, caller, targets)
+	for i, err := range errs {
+		if i == 7 {
+			assert.ErrorContains(t, err, "execution reverted")
+		} else {
+			assert.NilError(t, err)
+		}
+	}
+	assert.Equal(t, chain.numCalls(), 1)
+}
+
+func TestBatchingCallerWithoutMulticall(t *testing.T) {
+	chain := &fakeChain{deployed: false}
+	caller := NewBatchingCaller(chain, MulticallAddress)
+
+	targets := []common.Address{echoContract, echoContract, echoContract}
+	for _, err := range callConcurrently(t, caller, targets) {
+		assert.NilError(t, err)
+	}
+	assert.Equal(t, chain.numCalls(), len(targets))
+}
@@ -0,0 +1,96 @@
+package deployment
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// opPush1 is the opcode pushing one byte onto the stack. The opcodes pushing n bytes follow it.
+const opPush1 = 0x60
+
+// Verify checks that the contracts the node interacts with are deployed on the chain the client
+// is connected to, so that a wrong endpoint or deployment directory makes the node fail at
+// startup instead of silently observing no events. See Deployment.VerifyCode for how the code of
+// each contract is checked.
+func (c *Contracts) Verify(ctx context.Context) error {
+	chainID, err := c.Client.ChainID(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to query chain id")
+	}
+	if !chainID.IsUint64() || chainID.Uint64() != c.Deployments.ChainID {
+		return errors.Errorf("the contracts are deployed on chain %d, but the client is connected to chain %s",
+			c.Deployments.ChainID, chainID)
+	}
+
+	deployments := []*Deployment{
+		c.KeypersConfigsListDeployment,
+		c.CollatorConfigsListDeployment,
+		c.KeypersDeployment,
+		c.CollatorsDeployment,
+		c.KeyperRotationsDeployment,
+		c.KeyperBondsDeployment,
+		c.VersionRequirementsDeployment,
+	}
+	for _, d := range deployments {
+		if d == nil {
+			continue
+		}
+		code, err := c.Client.CodeAt(ctx, d.Address, nil)
+		if err != nil {
+			return errors.Wrapf(err, "failed to fetch code of %s contract", d.Name)
+		}
+		if err := d.VerifyCode(code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyCode checks that code is the code of the deployed contract. It is accepted if its hash
+// matches the deployed bytecode of the deployment artifact or, since the code differs from the
+// artifact if the contract has immutable variables, if it contains the dispatcher entries of all
+// functions of the contract's ABI.
+func (d *Deployment) VerifyCode(code []byte) error {
+	if len(code) == 0 {
+		return errors.Errorf("there is no contract code at address %s of the %s contract, "+
+			"is the node connected to the right chain and using the right deployment directory?", d.Address.Hex(), d.Name)
+	}
+	if len(d.DeployedBytecode) > 0 && crypto.Keccak256Hash(code) == crypto.Keccak256Hash(d.DeployedBytecode) {
+		return nil
+	}
+
+	missing := []string{}
+	for name, method := range d.ABI.Methods {
+		if !hasSelector(code, method.ID) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.Errorf("the code at address %s does not match the %s contract, it lacks the functions %s",
+			d.Address.Hex(), d.Name, strings.Join(missing, ", "))
+	}
+	if len(d.DeployedBytecode) > 0 {
+		log.Debug().Str("contract", d.Name).
+			Msg("contract code differs from the deployment artifact, but implements all of its functions")
+	}
+	return nil
+}
+
+// hasSelector reports whether code pushes the function selector onto the stack, as the
+// dispatcher of Solidity contracts does for every function. Leading zero bytes of the selector
+// are omitted by the compiler.
+func hasSelector(code []byte, selector []byte) bool {
+	trimmed := bytes.TrimLeft(selector, "\x00")
+	if len(trimmed) == 0 {
+		return true
+	}
+	push := append([]byte{byte(opPush1 + len(trimmed) - 1)}, trimmed...)
+	return bytes.Contains(code, push)
+}
@@ -0,0 +1,57 @@
+package deployment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+)
+
+const testABI = `[
+	{"name": "count", "type": "function", "stateMutability": "view", "inputs": [], "outputs": [{"type": "uint64"}]},
+	{"name": "append", "type": "function", "stateMutability": "nonpayable", "inputs": [{"type": "address"}], "outputs": []}
+]`
+
+func newTestDeployment(t *testing.T) *Deployment {
+	t.Helper()
+	parsed, err := abi.JSON(strings.NewReader(testABI))
+	assert.NilError(t, err)
+	return &Deployment{
+		Name:    "Test",
+		Address: common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		ABI:     parsed,
+	}
+}
+
+// dispatcher returns code pushing the selectors of the given functions.
+func dispatcher(d *Deployment, names ...string) []byte {
+	code := []byte{0x60, 0x80}
+	for _, name := range names {
+		code = append(code, 0x63)
+		code = append(code, d.ABI.Methods[name].ID...)
+		code = append(code, 0x14) // EQ
+	}
+	return code
+}
+
+func TestVerifyCode(t *testing.T) {
+	d := newTestDeployment(t)
+
+	assert.ErrorContains(t, d.VerifyCode(nil), "there is no contract code")
+	assert.NilError(t, d.VerifyCode(dispatcher(d, "count", "append")))
+	assert.ErrorContains(t, d.VerifyCode(dispatcher(d, "count")), "lacks the functions append")
+
+	// code matching the artifact is accepted even if the dispatcher can't be recognized
+	d.DeployedBytecode = []byte{0xfe}
+	assert.NilError(t, d.VerifyCode([]byte{0xfe}))
+	assert.ErrorContains(t, d.VerifyCode([]byte{0xff}), "does not match the Test contract")
+}
+
+func TestHasSelector(t *testing.T) {
+	assert.Assert(t, hasSelector([]byte{0x63, 1, 2, 3, 4}, []byte{1, 2, 3, 4}))
+	assert.Assert(t, !hasSelector([]byte{0x62, 1, 2, 3, 4}, []byte{1, 2, 3, 4}))
+	assert.Assert(t, hasSelector([]byte{0x62, 2, 3, 4}, []byte{0, 2, 3, 4}))
+	assert.Assert(t, hasSelector([]byte{}, []byte{0, 0, 0, 0}))
+}
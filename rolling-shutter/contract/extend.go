@@ -3,20 +3,34 @@ package contract
 import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
 )
 
+// maxConcurrentCalls is the maximum number of calls GetAddrs makes at the same time. Concurrent
+// calls are batched into one if the backend supports it, see deployment.BatchingCaller.
+const maxConcurrentCalls = 32
+
 func (_AddrsSeq *AddrsSeqCaller) GetAddrs(opts *bind.CallOpts, n uint64) ([]common.Address, error) {
 	numAddresses, err := _AddrsSeq.CountNth(opts, n)
 	if err != nil {
 		return nil, err
 	}
-	addresses := []common.Address{}
+	addresses := make([]common.Address, numAddresses)
+	var group errgroup.Group
+	group.SetLimit(maxConcurrentCalls)
 	for i := uint64(0); i < numAddresses; i++ {
-		address, err := _AddrsSeq.At(opts, n, i)
-		if err != nil {
-			return nil, err
-		}
-		addresses = append(addresses, address)
+		i := i
+		group.Go(func() error {
+			address, err := _AddrsSeq.At(opts, n, i)
+			if err != nil {
+				return err
+			}
+			addresses[i] = address
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
 	}
 	return addresses, nil
 }
@@ -0,0 +1,21 @@
+// Package auditdb contains the sqlc generated files for the audit log, which records every change
+// the event and message handlers make to a node's state.
+package auditdb
+
+import (
+	"context"
+	"time"
+)
+
+// Sources of the changes recorded in the audit log.
+const (
+	SourceChain       = "chain"
+	SourceShuttermint = "shuttermint"
+	SourceP2P         = "p2p"
+)
+
+// Prune deletes all entries older than the given retention period and returns the number of
+// deleted entries.
+func (q *Queries) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	return q.DeleteAuditLogEntriesBefore(ctx, time.Now().Add(-retention))
+}
@@ -0,0 +1,15 @@
+-- name: InsertAuditLogEntry :exec
+INSERT INTO audit_log (source, actor, action, details, trigger_hash)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: FindAuditLogEntries :many
+SELECT * FROM audit_log
+WHERE created_at >= @since AND created_at < @until
+AND (@source::text = '' OR source = @source)
+AND (@actor::text = '' OR actor = @actor)
+AND (length(@trigger_hash::bytea) = 0 OR trigger_hash = @trigger_hash)
+ORDER BY id
+LIMIT @max_entries;
+
+-- name: DeleteAuditLogEntriesBefore :execrows
+DELETE FROM audit_log WHERE created_at < $1;
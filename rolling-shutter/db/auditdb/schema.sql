@@ -0,0 +1,22 @@
+-- audit_log records every change the event and message handlers make to the node's state. Rows
+-- are only ever inserted, and deleted once they are older than the configured retention period.
+CREATE TABLE audit_log (
+       id bigserial PRIMARY KEY,
+       created_at timestamptz NOT NULL DEFAULT now(),
+       source text NOT NULL,
+       actor text NOT NULL,
+       action text NOT NULL,
+       details text NOT NULL,
+       trigger_hash bytea NOT NULL
+);
+CREATE INDEX audit_log_created_at_idx ON audit_log (created_at);
+CREATE INDEX audit_log_trigger_hash_idx ON audit_log (trigger_hash);
+
+CREATE FUNCTION audit_log_reject_update() RETURNS trigger AS $$
+BEGIN
+       RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE ON audit_log
+       FOR EACH ROW EXECUTE FUNCTION audit_log_reject_update();
@@ -0,0 +1,7 @@
+package chainobsdb
+
+// Conn returns the connection or transaction the queries are run on, so that queries of other
+// databases sharing it can take part in the same transaction.
+func (q *Queries) Conn() DBTX {
+	return q.db
+}
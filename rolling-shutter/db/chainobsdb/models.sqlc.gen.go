@@ -4,22 +4,93 @@
 
 package chainobsdb
 
-import ()
+import (
+	"time"
+)
+
+type Blob struct {
+	VersionedHash []byte
+	BlockNumber   int64
+	Data          []byte
+}
+
+type BlockSample struct {
+	BlockNumber int64
+	Timestamp   int64
+}
 
 type ChainCollator struct {
 	ActivationBlockNumber int64
 	Collator              string
 }
 
+type DeadEvent struct {
+	ID          int64
+	EventType   string
+	BlockNumber int64
+	LogIndex    int64
+	TxHash      []byte
+	Log         []byte
+	Attempts    int32
+	LastError   string
+	Retry       bool
+	CreatedAt   time.Time
+}
+
+type EventSchema struct {
+	ContractName    string
+	Address         string
+	FromBlockNumber int64
+	Abi             string
+	Events          []string
+	BlobHashArgs    []string
+}
+
 type EventSyncProgress struct {
 	ID              bool
 	NextBlockNumber int32
 	NextLogIndex    int32
 }
 
+type EventTypeSyncProgress struct {
+	EventType       string
+	NextBlockNumber int64
+	NextLogIndex    int64
+}
+
+type IgnoredEvent struct {
+	ID          int64
+	BlockNumber int64
+	LogIndex    int64
+	TxHash      []byte
+	Address     string
+	Signature   string
+	Reason      string
+	RecordedAt  time.Time
+}
+
+type KeyperRotation struct {
+	BlockNumber       int64
+	LogIndex          int64
+	KeyperConfigIndex int64
+	KeyperIndex       int64
+	OldAddress        string
+	NewAddress        string
+}
+
 type KeyperSet struct {
 	KeyperConfigIndex     int64
 	ActivationBlockNumber int64
 	Keypers               []string
 	Threshold             int32
 }
+
+type PendingConfig struct {
+	KeyperConfigIndex     int64
+	ActivationBlockNumber int64
+	Keypers               []string
+	Threshold             int32
+	ScheduledBlockNumber  int64
+	Notified              bool
+	LeadTimeNotified      bool
+}
@@ -8,6 +8,27 @@ ON CONFLICT (id) DO UPDATE
 -- name: GetEventSyncProgress :one
 SELECT next_block_number, next_log_index FROM event_sync_progress LIMIT 1;
 
+-- name: GetEventTypeSyncProgress :one
+SELECT * FROM event_type_sync_progress WHERE event_type = $1;
+
+-- name: GetEventTypeSyncProgresses :many
+SELECT * FROM event_type_sync_progress ORDER BY event_type;
+
+-- name: UpdateEventTypeSyncProgress :exec
+INSERT INTO event_type_sync_progress (event_type, next_block_number, next_log_index)
+VALUES ($1, $2, $3)
+ON CONFLICT (event_type) DO UPDATE
+    SET next_block_number = $2,
+        next_log_index = $3;
+
+-- name: UpdateEventSyncProgressFromEventTypes :exec
+UPDATE event_sync_progress SET (next_block_number, next_log_index) = (
+    SELECT next_block_number, next_log_index FROM event_type_sync_progress
+    WHERE event_type = ANY(@event_types::text[])
+    ORDER BY next_block_number, next_log_index
+    LIMIT 1
+);
+
 -- name: GetNextBlockNumber :one
 SELECT next_block_number from event_sync_progress LIMIT 1;
 
@@ -24,11 +45,45 @@ INSERT INTO keyper_set (
 -- name: GetKeyperSetByKeyperConfigIndex :one
 SELECT * FROM keyper_set WHERE keyper_config_index=$1;
 
+-- name: GetKeyperSets :many
+SELECT * FROM keyper_set ORDER BY keyper_config_index;
+
+-- name: GetKeyperSetsByActivationBlockNumber :many
+SELECT * FROM keyper_set WHERE activation_block_number = $1 ORDER BY keyper_config_index;
+
 -- name: GetKeyperSet :one
 SELECT * FROM keyper_set
 WHERE activation_block_number <= $1
 ORDER BY activation_block_number DESC LIMIT 1;
 
+-- name: InsertPendingConfig :exec
+INSERT INTO pending_configs (
+    keyper_config_index,
+    activation_block_number,
+    keypers,
+    threshold,
+    scheduled_block_number
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT DO NOTHING;
+
+-- name: SetPendingConfigNotified :exec
+UPDATE pending_configs SET notified = true
+WHERE keyper_config_index = $1;
+
+-- name: GetPendingConfigs :many
+SELECT * FROM pending_configs
+ORDER BY keyper_config_index;
+
+-- name: SetPendingConfigLeadTimeNotified :exec
+UPDATE pending_configs SET lead_time_notified = true
+WHERE keyper_config_index = $1;
+
+-- name: DeleteActivatedPendingConfigs :many
+DELETE FROM pending_configs
+WHERE activation_block_number <= @block_number
+RETURNING *;
+
 -- name: InsertChainCollator :exec
 INSERT INTO chain_collator (activation_block_number, collator)
 VALUES ($1, $2);
@@ -37,3 +92,102 @@ VALUES ($1, $2);
 SELECT * FROM chain_collator
 WHERE activation_block_number <= $1
 ORDER BY activation_block_number DESC LIMIT 1;
+
+-- name: GetEventSchemas :many
+SELECT * FROM event_schema
+ORDER BY contract_name;
+
+-- name: UpsertEventSchema :exec
+INSERT INTO event_schema (contract_name, address, from_block_number, abi, events, blob_hash_args)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (contract_name) DO UPDATE SET
+    address = EXCLUDED.address,
+    from_block_number = EXCLUDED.from_block_number,
+    abi = EXCLUDED.abi,
+    events = EXCLUDED.events,
+    blob_hash_args = EXCLUDED.blob_hash_args;
+
+-- name: InsertIgnoredEvent :exec
+INSERT INTO ignored_event (block_number, log_index, tx_hash, address, signature, reason)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetRecentIgnoredEvents :many
+SELECT * FROM ignored_event
+ORDER BY id DESC
+LIMIT $1;
+
+-- name: PruneIgnoredEvents :execrows
+DELETE FROM ignored_event
+WHERE id <= (SELECT max(id) FROM ignored_event) - @keep::bigint;
+
+-- name: InsertBlockSample :exec
+INSERT INTO block_sample (block_number, timestamp)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: GetBlockSamples :many
+SELECT * FROM block_sample
+ORDER BY block_number DESC
+LIMIT $1;
+
+-- name: PruneBlockSamples :execrows
+DELETE FROM block_sample
+WHERE block_number < (
+    SELECT min(block_number) FROM (
+        SELECT block_number FROM block_sample ORDER BY block_number DESC LIMIT @keep::bigint
+    ) AS kept
+);
+
+-- name: InsertKeyperRotation :exec
+INSERT INTO keyper_rotation (
+    block_number, log_index, keyper_config_index, keyper_index, old_address, new_address
+) VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT DO NOTHING;
+
+-- name: GetKeyperRotations :many
+SELECT * FROM keyper_rotation
+ORDER BY block_number, log_index;
+
+-- name: RotateKeyperSetAddress :execrows
+UPDATE keyper_set
+SET keypers[@keyper_index::integer + 1] = @new_address::text
+WHERE keyper_config_index = @keyper_config_index
+AND keypers[@keyper_index::integer + 1] = @old_address::text;
+
+-- name: RotatePendingConfigAddress :execrows
+UPDATE pending_configs
+SET keypers[@keyper_index::integer + 1] = @new_address::text
+WHERE keyper_config_index = @keyper_config_index
+AND keypers[@keyper_index::integer + 1] = @old_address::text;
+
+-- name: InsertDeadEvent :one
+INSERT INTO dead_event (event_type, block_number, log_index, tx_hash, log, attempts, last_error)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (event_type, block_number, log_index) DO UPDATE
+    SET attempts = dead_event.attempts + EXCLUDED.attempts,
+        last_error = EXCLUDED.last_error,
+        retry = false
+RETURNING id;
+
+-- name: GetDeadEvents :many
+SELECT * FROM dead_event ORDER BY id;
+
+-- name: GetDeadEventsToRetry :many
+SELECT * FROM dead_event WHERE retry ORDER BY block_number, log_index;
+
+-- name: RetryDeadEvent :execrows
+UPDATE dead_event SET retry = true WHERE id = $1;
+
+-- name: FailDeadEventRetry :exec
+UPDATE dead_event SET retry = false, attempts = attempts + 1, last_error = $2 WHERE id = $1;
+
+-- name: DeleteDeadEvent :exec
+DELETE FROM dead_event WHERE id = $1;
+
+-- name: InsertBlob :exec
+INSERT INTO blob (versioned_hash, block_number, data)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: GetBlob :one
+SELECT * FROM blob WHERE versioned_hash = $1;
@@ -9,6 +9,100 @@ import (
 	"context"
 )
 
+const deleteActivatedPendingConfigs = `-- name: DeleteActivatedPendingConfigs :many
+DELETE FROM pending_configs
+WHERE activation_block_number <= $1
+RETURNING keyper_config_index, activation_block_number, keypers, threshold, scheduled_block_number, notified, lead_time_notified
+`
+
+func (q *Queries) DeleteActivatedPendingConfigs(ctx context.Context, blockNumber int64) ([]PendingConfig, error) {
+	rows, err := q.db.Query(ctx, deleteActivatedPendingConfigs, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PendingConfig
+	for rows.Next() {
+		var i PendingConfig
+		if err := rows.Scan(
+			&i.KeyperConfigIndex,
+			&i.ActivationBlockNumber,
+			&i.Keypers,
+			&i.Threshold,
+			&i.ScheduledBlockNumber,
+			&i.Notified,
+			&i.LeadTimeNotified,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteDeadEvent = `-- name: DeleteDeadEvent :exec
+DELETE FROM dead_event WHERE id = $1
+`
+
+func (q *Queries) DeleteDeadEvent(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteDeadEvent, id)
+	return err
+}
+
+const failDeadEventRetry = `-- name: FailDeadEventRetry :exec
+UPDATE dead_event SET retry = false, attempts = attempts + 1, last_error = $2 WHERE id = $1
+`
+
+type FailDeadEventRetryParams struct {
+	ID        int64
+	LastError string
+}
+
+func (q *Queries) FailDeadEventRetry(ctx context.Context, arg FailDeadEventRetryParams) error {
+	_, err := q.db.Exec(ctx, failDeadEventRetry, arg.ID, arg.LastError)
+	return err
+}
+
+const getBlob = `-- name: GetBlob :one
+SELECT versioned_hash, block_number, data FROM blob WHERE versioned_hash = $1
+`
+
+func (q *Queries) GetBlob(ctx context.Context, versionedHash []byte) (Blob, error) {
+	row := q.db.QueryRow(ctx, getBlob, versionedHash)
+	var i Blob
+	err := row.Scan(&i.VersionedHash, &i.BlockNumber, &i.Data)
+	return i, err
+}
+
+const getBlockSamples = `-- name: GetBlockSamples :many
+SELECT block_number, timestamp FROM block_sample
+ORDER BY block_number DESC
+LIMIT $1
+`
+
+func (q *Queries) GetBlockSamples(ctx context.Context, limit int32) ([]BlockSample, error) {
+	rows, err := q.db.Query(ctx, getBlockSamples, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BlockSample
+	for rows.Next() {
+		var i BlockSample
+		if err := rows.Scan(&i.BlockNumber, &i.Timestamp); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getChainCollator = `-- name: GetChainCollator :one
 SELECT activation_block_number, collator FROM chain_collator
 WHERE activation_block_number <= $1
@@ -22,6 +116,108 @@ func (q *Queries) GetChainCollator(ctx context.Context, activationBlockNumber in
 	return i, err
 }
 
+const getDeadEvents = `-- name: GetDeadEvents :many
+SELECT id, event_type, block_number, log_index, tx_hash, log, attempts, last_error, retry, created_at FROM dead_event ORDER BY id
+`
+
+func (q *Queries) GetDeadEvents(ctx context.Context) ([]DeadEvent, error) {
+	rows, err := q.db.Query(ctx, getDeadEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeadEvent
+	for rows.Next() {
+		var i DeadEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventType,
+			&i.BlockNumber,
+			&i.LogIndex,
+			&i.TxHash,
+			&i.Log,
+			&i.Attempts,
+			&i.LastError,
+			&i.Retry,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeadEventsToRetry = `-- name: GetDeadEventsToRetry :many
+SELECT id, event_type, block_number, log_index, tx_hash, log, attempts, last_error, retry, created_at FROM dead_event WHERE retry ORDER BY block_number, log_index
+`
+
+func (q *Queries) GetDeadEventsToRetry(ctx context.Context) ([]DeadEvent, error) {
+	rows, err := q.db.Query(ctx, getDeadEventsToRetry)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeadEvent
+	for rows.Next() {
+		var i DeadEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventType,
+			&i.BlockNumber,
+			&i.LogIndex,
+			&i.TxHash,
+			&i.Log,
+			&i.Attempts,
+			&i.LastError,
+			&i.Retry,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEventSchemas = `-- name: GetEventSchemas :many
+SELECT contract_name, address, from_block_number, abi, events, blob_hash_args FROM event_schema
+ORDER BY contract_name
+`
+
+func (q *Queries) GetEventSchemas(ctx context.Context) ([]EventSchema, error) {
+	rows, err := q.db.Query(ctx, getEventSchemas)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EventSchema
+	for rows.Next() {
+		var i EventSchema
+		if err := rows.Scan(
+			&i.ContractName,
+			&i.Address,
+			&i.FromBlockNumber,
+			&i.Abi,
+			&i.Events,
+			&i.BlobHashArgs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getEventSyncProgress = `-- name: GetEventSyncProgress :one
 SELECT next_block_number, next_log_index FROM event_sync_progress LIMIT 1
 `
@@ -38,6 +234,73 @@ func (q *Queries) GetEventSyncProgress(ctx context.Context) (GetEventSyncProgres
 	return i, err
 }
 
+const getEventTypeSyncProgress = `-- name: GetEventTypeSyncProgress :one
+SELECT event_type, next_block_number, next_log_index FROM event_type_sync_progress WHERE event_type = $1
+`
+
+func (q *Queries) GetEventTypeSyncProgress(ctx context.Context, eventType string) (EventTypeSyncProgress, error) {
+	row := q.db.QueryRow(ctx, getEventTypeSyncProgress, eventType)
+	var i EventTypeSyncProgress
+	err := row.Scan(&i.EventType, &i.NextBlockNumber, &i.NextLogIndex)
+	return i, err
+}
+
+const getEventTypeSyncProgresses = `-- name: GetEventTypeSyncProgresses :many
+SELECT event_type, next_block_number, next_log_index FROM event_type_sync_progress ORDER BY event_type
+`
+
+func (q *Queries) GetEventTypeSyncProgresses(ctx context.Context) ([]EventTypeSyncProgress, error) {
+	rows, err := q.db.Query(ctx, getEventTypeSyncProgresses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EventTypeSyncProgress
+	for rows.Next() {
+		var i EventTypeSyncProgress
+		if err := rows.Scan(&i.EventType, &i.NextBlockNumber, &i.NextLogIndex); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getKeyperRotations = `-- name: GetKeyperRotations :many
+SELECT block_number, log_index, keyper_config_index, keyper_index, old_address, new_address FROM keyper_rotation
+ORDER BY block_number, log_index
+`
+
+func (q *Queries) GetKeyperRotations(ctx context.Context) ([]KeyperRotation, error) {
+	rows, err := q.db.Query(ctx, getKeyperRotations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []KeyperRotation
+	for rows.Next() {
+		var i KeyperRotation
+		if err := rows.Scan(
+			&i.BlockNumber,
+			&i.LogIndex,
+			&i.KeyperConfigIndex,
+			&i.KeyperIndex,
+			&i.OldAddress,
+			&i.NewAddress,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getKeyperSet = `-- name: GetKeyperSet :one
 SELECT keyper_config_index, activation_block_number, keypers, threshold FROM keyper_set
 WHERE activation_block_number <= $1
@@ -72,6 +335,64 @@ func (q *Queries) GetKeyperSetByKeyperConfigIndex(ctx context.Context, keyperCon
 	return i, err
 }
 
+const getKeyperSets = `-- name: GetKeyperSets :many
+SELECT keyper_config_index, activation_block_number, keypers, threshold FROM keyper_set ORDER BY keyper_config_index
+`
+
+func (q *Queries) GetKeyperSets(ctx context.Context) ([]KeyperSet, error) {
+	rows, err := q.db.Query(ctx, getKeyperSets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []KeyperSet
+	for rows.Next() {
+		var i KeyperSet
+		if err := rows.Scan(
+			&i.KeyperConfigIndex,
+			&i.ActivationBlockNumber,
+			&i.Keypers,
+			&i.Threshold,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getKeyperSetsByActivationBlockNumber = `-- name: GetKeyperSetsByActivationBlockNumber :many
+SELECT keyper_config_index, activation_block_number, keypers, threshold FROM keyper_set WHERE activation_block_number = $1 ORDER BY keyper_config_index
+`
+
+func (q *Queries) GetKeyperSetsByActivationBlockNumber(ctx context.Context, activationBlockNumber int64) ([]KeyperSet, error) {
+	rows, err := q.db.Query(ctx, getKeyperSetsByActivationBlockNumber, activationBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []KeyperSet
+	for rows.Next() {
+		var i KeyperSet
+		if err := rows.Scan(
+			&i.KeyperConfigIndex,
+			&i.ActivationBlockNumber,
+			&i.Keypers,
+			&i.Threshold,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getNextBlockNumber = `-- name: GetNextBlockNumber :one
 SELECT next_block_number from event_sync_progress LIMIT 1
 `
@@ -83,6 +404,107 @@ func (q *Queries) GetNextBlockNumber(ctx context.Context) (int32, error) {
 	return next_block_number, err
 }
 
+const getPendingConfigs = `-- name: GetPendingConfigs :many
+SELECT keyper_config_index, activation_block_number, keypers, threshold, scheduled_block_number, notified, lead_time_notified FROM pending_configs
+ORDER BY keyper_config_index
+`
+
+func (q *Queries) GetPendingConfigs(ctx context.Context) ([]PendingConfig, error) {
+	rows, err := q.db.Query(ctx, getPendingConfigs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PendingConfig
+	for rows.Next() {
+		var i PendingConfig
+		if err := rows.Scan(
+			&i.KeyperConfigIndex,
+			&i.ActivationBlockNumber,
+			&i.Keypers,
+			&i.Threshold,
+			&i.ScheduledBlockNumber,
+			&i.Notified,
+			&i.LeadTimeNotified,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentIgnoredEvents = `-- name: GetRecentIgnoredEvents :many
+SELECT id, block_number, log_index, tx_hash, address, signature, reason, recorded_at FROM ignored_event
+ORDER BY id DESC
+LIMIT $1
+`
+
+func (q *Queries) GetRecentIgnoredEvents(ctx context.Context, limit int32) ([]IgnoredEvent, error) {
+	rows, err := q.db.Query(ctx, getRecentIgnoredEvents, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []IgnoredEvent
+	for rows.Next() {
+		var i IgnoredEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.BlockNumber,
+			&i.LogIndex,
+			&i.TxHash,
+			&i.Address,
+			&i.Signature,
+			&i.Reason,
+			&i.RecordedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertBlob = `-- name: InsertBlob :exec
+INSERT INTO blob (versioned_hash, block_number, data)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING
+`
+
+type InsertBlobParams struct {
+	VersionedHash []byte
+	BlockNumber   int64
+	Data          []byte
+}
+
+func (q *Queries) InsertBlob(ctx context.Context, arg InsertBlobParams) error {
+	_, err := q.db.Exec(ctx, insertBlob, arg.VersionedHash, arg.BlockNumber, arg.Data)
+	return err
+}
+
+const insertBlockSample = `-- name: InsertBlockSample :exec
+INSERT INTO block_sample (block_number, timestamp)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type InsertBlockSampleParams struct {
+	BlockNumber int64
+	Timestamp   int64
+}
+
+func (q *Queries) InsertBlockSample(ctx context.Context, arg InsertBlockSampleParams) error {
+	_, err := q.db.Exec(ctx, insertBlockSample, arg.BlockNumber, arg.Timestamp)
+	return err
+}
+
 const insertChainCollator = `-- name: InsertChainCollator :exec
 INSERT INTO chain_collator (activation_block_number, collator)
 VALUES ($1, $2)
@@ -98,6 +520,95 @@ func (q *Queries) InsertChainCollator(ctx context.Context, arg InsertChainCollat
 	return err
 }
 
+const insertDeadEvent = `-- name: InsertDeadEvent :one
+INSERT INTO dead_event (event_type, block_number, log_index, tx_hash, log, attempts, last_error)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (event_type, block_number, log_index) DO UPDATE
+    SET attempts = dead_event.attempts + EXCLUDED.attempts,
+        last_error = EXCLUDED.last_error,
+        retry = false
+RETURNING id
+`
+
+type InsertDeadEventParams struct {
+	EventType   string
+	BlockNumber int64
+	LogIndex    int64
+	TxHash      []byte
+	Log         []byte
+	Attempts    int32
+	LastError   string
+}
+
+func (q *Queries) InsertDeadEvent(ctx context.Context, arg InsertDeadEventParams) (int64, error) {
+	row := q.db.QueryRow(ctx, insertDeadEvent,
+		arg.EventType,
+		arg.BlockNumber,
+		arg.LogIndex,
+		arg.TxHash,
+		arg.Log,
+		arg.Attempts,
+		arg.LastError,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertIgnoredEvent = `-- name: InsertIgnoredEvent :exec
+INSERT INTO ignored_event (block_number, log_index, tx_hash, address, signature, reason)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type InsertIgnoredEventParams struct {
+	BlockNumber int64
+	LogIndex    int64
+	TxHash      []byte
+	Address     string
+	Signature   string
+	Reason      string
+}
+
+func (q *Queries) InsertIgnoredEvent(ctx context.Context, arg InsertIgnoredEventParams) error {
+	_, err := q.db.Exec(ctx, insertIgnoredEvent,
+		arg.BlockNumber,
+		arg.LogIndex,
+		arg.TxHash,
+		arg.Address,
+		arg.Signature,
+		arg.Reason,
+	)
+	return err
+}
+
+const insertKeyperRotation = `-- name: InsertKeyperRotation :exec
+INSERT INTO keyper_rotation (
+    block_number, log_index, keyper_config_index, keyper_index, old_address, new_address
+) VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT DO NOTHING
+`
+
+type InsertKeyperRotationParams struct {
+	BlockNumber       int64
+	LogIndex          int64
+	KeyperConfigIndex int64
+	KeyperIndex       int64
+	OldAddress        string
+	NewAddress        string
+}
+
+func (q *Queries) InsertKeyperRotation(ctx context.Context, arg InsertKeyperRotationParams) error {
+	_, err := q.db.Exec(ctx, insertKeyperRotation,
+		arg.BlockNumber,
+		arg.LogIndex,
+		arg.KeyperConfigIndex,
+		arg.KeyperIndex,
+		arg.OldAddress,
+		arg.NewAddress,
+	)
+	return err
+}
+
 const insertKeyperSet = `-- name: InsertKeyperSet :exec
 INSERT INTO keyper_set (
     keyper_config_index,
@@ -126,6 +637,153 @@ func (q *Queries) InsertKeyperSet(ctx context.Context, arg InsertKeyperSetParams
 	return err
 }
 
+const insertPendingConfig = `-- name: InsertPendingConfig :exec
+INSERT INTO pending_configs (
+    keyper_config_index,
+    activation_block_number,
+    keypers,
+    threshold,
+    scheduled_block_number
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT DO NOTHING
+`
+
+type InsertPendingConfigParams struct {
+	KeyperConfigIndex     int64
+	ActivationBlockNumber int64
+	Keypers               []string
+	Threshold             int32
+	ScheduledBlockNumber  int64
+}
+
+func (q *Queries) InsertPendingConfig(ctx context.Context, arg InsertPendingConfigParams) error {
+	_, err := q.db.Exec(ctx, insertPendingConfig,
+		arg.KeyperConfigIndex,
+		arg.ActivationBlockNumber,
+		arg.Keypers,
+		arg.Threshold,
+		arg.ScheduledBlockNumber,
+	)
+	return err
+}
+
+const pruneBlockSamples = `-- name: PruneBlockSamples :execrows
+DELETE FROM block_sample
+WHERE block_number < (
+    SELECT min(block_number) FROM (
+        SELECT block_number FROM block_sample ORDER BY block_number DESC LIMIT $1::bigint
+    ) AS kept
+)
+`
+
+func (q *Queries) PruneBlockSamples(ctx context.Context, keep int64) (int64, error) {
+	result, err := q.db.Exec(ctx, pruneBlockSamples, keep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const pruneIgnoredEvents = `-- name: PruneIgnoredEvents :execrows
+DELETE FROM ignored_event
+WHERE id <= (SELECT max(id) FROM ignored_event) - $1::bigint
+`
+
+func (q *Queries) PruneIgnoredEvents(ctx context.Context, keep int64) (int64, error) {
+	result, err := q.db.Exec(ctx, pruneIgnoredEvents, keep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const retryDeadEvent = `-- name: RetryDeadEvent :execrows
+UPDATE dead_event SET retry = true WHERE id = $1
+`
+
+func (q *Queries) RetryDeadEvent(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, retryDeadEvent, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rotateKeyperSetAddress = `-- name: RotateKeyperSetAddress :execrows
+UPDATE keyper_set
+SET keypers[$1::integer + 1] = $2::text
+WHERE keyper_config_index = $3
+AND keypers[$1::integer + 1] = $4::text
+`
+
+type RotateKeyperSetAddressParams struct {
+	KeyperIndex       int32
+	NewAddress        string
+	KeyperConfigIndex int64
+	OldAddress        string
+}
+
+func (q *Queries) RotateKeyperSetAddress(ctx context.Context, arg RotateKeyperSetAddressParams) (int64, error) {
+	result, err := q.db.Exec(ctx, rotateKeyperSetAddress,
+		arg.KeyperIndex,
+		arg.NewAddress,
+		arg.KeyperConfigIndex,
+		arg.OldAddress,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rotatePendingConfigAddress = `-- name: RotatePendingConfigAddress :execrows
+UPDATE pending_configs
+SET keypers[$1::integer + 1] = $2::text
+WHERE keyper_config_index = $3
+AND keypers[$1::integer + 1] = $4::text
+`
+
+type RotatePendingConfigAddressParams struct {
+	KeyperIndex       int32
+	NewAddress        string
+	KeyperConfigIndex int64
+	OldAddress        string
+}
+
+func (q *Queries) RotatePendingConfigAddress(ctx context.Context, arg RotatePendingConfigAddressParams) (int64, error) {
+	result, err := q.db.Exec(ctx, rotatePendingConfigAddress,
+		arg.KeyperIndex,
+		arg.NewAddress,
+		arg.KeyperConfigIndex,
+		arg.OldAddress,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setPendingConfigLeadTimeNotified = `-- name: SetPendingConfigLeadTimeNotified :exec
+UPDATE pending_configs SET lead_time_notified = true
+WHERE keyper_config_index = $1
+`
+
+func (q *Queries) SetPendingConfigLeadTimeNotified(ctx context.Context, keyperConfigIndex int64) error {
+	_, err := q.db.Exec(ctx, setPendingConfigLeadTimeNotified, keyperConfigIndex)
+	return err
+}
+
+const setPendingConfigNotified = `-- name: SetPendingConfigNotified :exec
+UPDATE pending_configs SET notified = true
+WHERE keyper_config_index = $1
+`
+
+func (q *Queries) SetPendingConfigNotified(ctx context.Context, keyperConfigIndex int64) error {
+	_, err := q.db.Exec(ctx, setPendingConfigNotified, keyperConfigIndex)
+	return err
+}
+
 const updateEventSyncProgress = `-- name: UpdateEventSyncProgress :exec
 INSERT INTO event_sync_progress (next_block_number, next_log_index)
 VALUES ($1, $2)
@@ -143,3 +801,68 @@ func (q *Queries) UpdateEventSyncProgress(ctx context.Context, arg UpdateEventSy
 	_, err := q.db.Exec(ctx, updateEventSyncProgress, arg.NextBlockNumber, arg.NextLogIndex)
 	return err
 }
+
+const updateEventSyncProgressFromEventTypes = `-- name: UpdateEventSyncProgressFromEventTypes :exec
+UPDATE event_sync_progress SET (next_block_number, next_log_index) = (
+    SELECT next_block_number, next_log_index FROM event_type_sync_progress
+    WHERE event_type = ANY($1::text[])
+    ORDER BY next_block_number, next_log_index
+    LIMIT 1
+)
+`
+
+func (q *Queries) UpdateEventSyncProgressFromEventTypes(ctx context.Context, eventTypes []string) error {
+	_, err := q.db.Exec(ctx, updateEventSyncProgressFromEventTypes, eventTypes)
+	return err
+}
+
+const updateEventTypeSyncProgress = `-- name: UpdateEventTypeSyncProgress :exec
+INSERT INTO event_type_sync_progress (event_type, next_block_number, next_log_index)
+VALUES ($1, $2, $3)
+ON CONFLICT (event_type) DO UPDATE
+    SET next_block_number = $2,
+        next_log_index = $3
+`
+
+type UpdateEventTypeSyncProgressParams struct {
+	EventType       string
+	NextBlockNumber int64
+	NextLogIndex    int64
+}
+
+func (q *Queries) UpdateEventTypeSyncProgress(ctx context.Context, arg UpdateEventTypeSyncProgressParams) error {
+	_, err := q.db.Exec(ctx, updateEventTypeSyncProgress, arg.EventType, arg.NextBlockNumber, arg.NextLogIndex)
+	return err
+}
+
+const upsertEventSchema = `-- name: UpsertEventSchema :exec
+INSERT INTO event_schema (contract_name, address, from_block_number, abi, events, blob_hash_args)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (contract_name) DO UPDATE SET
+    address = EXCLUDED.address,
+    from_block_number = EXCLUDED.from_block_number,
+    abi = EXCLUDED.abi,
+    events = EXCLUDED.events,
+    blob_hash_args = EXCLUDED.blob_hash_args
+`
+
+type UpsertEventSchemaParams struct {
+	ContractName    string
+	Address         string
+	FromBlockNumber int64
+	Abi             string
+	Events          []string
+	BlobHashArgs    []string
+}
+
+func (q *Queries) UpsertEventSchema(ctx context.Context, arg UpsertEventSchemaParams) error {
+	_, err := q.db.Exec(ctx, upsertEventSchema,
+		arg.ContractName,
+		arg.Address,
+		arg.FromBlockNumber,
+		arg.Abi,
+		arg.Events,
+		arg.BlobHashArgs,
+	)
+	return err
+}
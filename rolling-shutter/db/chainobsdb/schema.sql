@@ -5,6 +5,15 @@ CREATE TABLE event_sync_progress (
 );
 INSERT INTO event_sync_progress (next_block_number, next_log_index) VALUES (0,0);
 
+-- event_type_sync_progress contains the sync cursor of every observed event type. Event types are
+-- synced independently, so that a failure handling one of them doesn't stall the others.
+-- event_sync_progress is kept at the earliest cursor of the observed event types.
+CREATE TABLE event_type_sync_progress(
+       event_type text PRIMARY KEY,
+       next_block_number bigint NOT NULL,
+       next_log_index bigint NOT NULL
+);
+
 CREATE TABLE keyper_set(
        keyper_config_index bigint NOT NULL,
        activation_block_number bigint NOT NULL,
@@ -13,7 +22,104 @@ CREATE TABLE keyper_set(
        PRIMARY KEY (keyper_config_index)
 );
 
+CREATE OR REPLACE FUNCTION notify_new_keyper_set()
+  RETURNS TRIGGER AS $$
+DECLARE
+BEGIN
+  PERFORM pg_notify('new_keyper_set', 'payload');
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER notify_keyper_set
+         AFTER INSERT ON keyper_set
+    FOR EACH STATEMENT EXECUTE PROCEDURE notify_new_keyper_set();
+
+-- pending_configs contains the keyper configs that have been scheduled in the config contract,
+-- but have not been activated yet. Rows are deleted once their activation block is reached.
+CREATE TABLE pending_configs(
+       keyper_config_index bigint PRIMARY KEY,
+       activation_block_number bigint NOT NULL,
+       keypers text[] NOT NULL,
+       threshold integer NOT NULL,
+       scheduled_block_number bigint NOT NULL,
+       notified boolean NOT NULL DEFAULT false,
+       lead_time_notified boolean NOT NULL DEFAULT false
+);
+
 CREATE TABLE chain_collator(
        activation_block_number bigint PRIMARY KEY,
        collator text NOT NULL
 );
+
+-- event_schema contains contract ABIs whose events are observed in addition to the ones compiled
+-- into the contract bindings, so that events added by contract upgrades can be consumed without
+-- a rebuild. blob_hash_args names the event arguments holding versioned hashes of blobs, which
+-- are fetched for the event handlers.
+CREATE TABLE event_schema(
+       contract_name text PRIMARY KEY,
+       address text NOT NULL,
+       from_block_number bigint NOT NULL,
+       abi text NOT NULL,
+       events text[] NOT NULL,
+       blob_hash_args text[] NOT NULL DEFAULT '{}'
+);
+
+-- ignored_event contains the most recent contract events the chain observer didn't handle, e.g.
+-- because no handler is registered for them, so that misconfigured deployments are noticed.
+CREATE TABLE ignored_event(
+       id bigserial PRIMARY KEY,
+       block_number bigint NOT NULL,
+       log_index bigint NOT NULL,
+       tx_hash bytea NOT NULL,
+       address text NOT NULL,
+       signature text NOT NULL,
+       reason text NOT NULL,
+       recorded_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- block_sample contains L1 block headers sampled at regular intervals. They are used to estimate
+-- the time at which future blocks, e.g. the activation blocks of pending keyper configs, will be
+-- produced.
+CREATE TABLE block_sample(
+       block_number bigint PRIMARY KEY,
+       timestamp bigint NOT NULL
+);
+
+-- keyper_rotation contains the rotations of keyper addresses announced by the KeyperRotations
+-- contract. A rotated keyper keeps its index in the keyper set and thereby its eon key share, only
+-- its address is replaced in keyper_set and pending_configs.
+CREATE TABLE keyper_rotation(
+       block_number bigint NOT NULL,
+       log_index bigint NOT NULL,
+       keyper_config_index bigint NOT NULL,
+       keyper_index bigint NOT NULL,
+       old_address text NOT NULL,
+       new_address text NOT NULL,
+       PRIMARY KEY (block_number, log_index)
+);
+
+-- dead_event contains contract events the chain observer gave up on after handling them failed
+-- repeatedly, so that syncing their event type can continue. The operator can mark them for retry,
+-- e.g. after fixing the cause, and the observer handles them again.
+CREATE TABLE dead_event(
+       id bigserial PRIMARY KEY,
+       event_type text NOT NULL,
+       block_number bigint NOT NULL,
+       log_index bigint NOT NULL,
+       tx_hash bytea NOT NULL,
+       log bytea NOT NULL,
+       attempts integer NOT NULL,
+       last_error text NOT NULL,
+       retry boolean NOT NULL DEFAULT false,
+       created_at timestamptz NOT NULL DEFAULT now(),
+       UNIQUE (event_type, block_number, log_index)
+);
+
+-- blob caches the blobs referenced by contract events, keyed by their versioned hash. Beacon nodes
+-- prune blobs after a few weeks, so events can't be handled again later without it.
+CREATE TABLE blob(
+       versioned_hash bytea PRIMARY KEY,
+       block_number bigint NOT NULL,
+       data bytea NOT NULL
+);
@@ -0,0 +1,29 @@
+package chainobsdb
+
+import "context"
+
+// The store interfaces group the queries of a single domain, so that code can depend on just the
+// queries it needs and be tested against a fake implementation. Queries implements all of them.
+var (
+	_ KeyperSetStore    = (*Queries)(nil)
+	_ SyncProgressStore = (*Queries)(nil)
+)
+
+// KeyperSetStore gives access to the keyper sets announced by the keyper config contract.
+type KeyperSetStore interface {
+	GetKeyperSet(ctx context.Context, activationBlockNumber int64) (KeyperSet, error)
+	GetKeyperSetByKeyperConfigIndex(ctx context.Context, keyperConfigIndex int64) (KeyperSet, error)
+	GetKeyperSets(ctx context.Context) ([]KeyperSet, error)
+	GetKeyperSetsByActivationBlockNumber(ctx context.Context, activationBlockNumber int64) ([]KeyperSet, error)
+	InsertKeyperSet(ctx context.Context, arg InsertKeyperSetParams) error
+}
+
+// SyncProgressStore keeps track of how far the chain observer has synced the contract events,
+// overall and per event type.
+type SyncProgressStore interface {
+	GetEventSyncProgress(ctx context.Context) (GetEventSyncProgressRow, error)
+	UpdateEventSyncProgress(ctx context.Context, arg UpdateEventSyncProgressParams) error
+	GetEventTypeSyncProgress(ctx context.Context, eventType string) (EventTypeSyncProgress, error)
+	UpdateEventTypeSyncProgress(ctx context.Context, arg UpdateEventTypeSyncProgressParams) error
+	UpdateEventSyncProgressFromEventTypes(ctx context.Context, eventTypes []string) error
+}
@@ -17,7 +17,7 @@ import (
 var schemaVersion = db.MustFindSchemaVersion("cltrdb")
 
 func initDB(ctx context.Context, tx pgx.Tx) error {
-	err := db.Create(ctx, tx, []string{"cltrdb", "chainobsdb", "metadb"})
+	err := db.Create(ctx, tx, []string{"cltrdb", "chainobsdb", "metadb", "auditdb", "paramdb", "provenancedb", "jobdb"})
 	if err != nil {
 		return err
 	}
@@ -8,6 +8,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"time"
 )
 
 type Txstatus string
@@ -89,12 +90,29 @@ type EonPublicKeyVote struct {
 	KeyperConfigIndex int64
 }
 
+type L1Intent struct {
+	EnforceOneRow bool
+	EpochID       []byte
+	Action        string
+	PayloadHash   []byte
+	Nonce         int64
+	TxHash        []byte
+	RawTx         []byte
+}
+
 type NextBatch struct {
 	EnforceOneRow bool
 	EpochID       []byte
 	L1BlockNumber int64
 }
 
+type StreamOutbox struct {
+	ID        int64
+	EpochID   []byte
+	Payload   []byte
+	CreatedAt time.Time
+}
+
 type Transaction struct {
 	TxHash  []byte
 	ID      sql.NullInt32
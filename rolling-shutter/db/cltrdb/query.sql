@@ -69,6 +69,9 @@ SELECT epoch_id FROM decryption_trigger ORDER BY epoch_id DESC LIMIT 1;
 -- name: InsertTx :exec
 INSERT INTO transaction (tx_hash, epoch_id, tx_bytes, status) VALUES ($1, $2, $3, $4);
 
+-- name: GetTransaction :one
+SELECT * FROM transaction WHERE tx_hash = $1;
+
 -- name: GetTransactionsByEpoch :many
 SELECT * FROM transaction WHERE epoch_id = $1 ORDER BY id ASC;
 
@@ -96,11 +99,37 @@ SET epoch_id = $1, l1_block_number = $2;
 -- name: GetNextBatch :one
 SELECT * FROM next_batch LIMIT 1;
 
+-- name: SetL1Intent :exec
+INSERT INTO l1_intent (epoch_id, action, payload_hash, nonce, tx_hash, raw_tx)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (enforce_one_row) DO UPDATE
+SET epoch_id = $1, action = $2, payload_hash = $3, nonce = $4, tx_hash = $5, raw_tx = $6;
+
+-- name: GetL1Intent :one
+SELECT * FROM l1_intent LIMIT 1;
+
 -- name: InsertBatchTx :exec
 INSERT INTO batchtx (epoch_id, marshaled) VALUES ($1, $2);
 
+-- name: GetBatchTxsInRange :many
+SELECT * FROM batchtx
+WHERE epoch_id BETWEEN sqlc.arg(from_epoch_id) AND sqlc.arg(to_epoch_id)
+ORDER BY epoch_id ASC;
+
 -- name: GetUnsubmittedBatchTx :one
 SELECT * FROM batchtx WHERE submitted=false;
 
 -- name: SetBatchSubmitted :exec
 UPDATE batchtx SET submitted=true WHERE submitted=false;
+
+-- name: InsertStreamOutbox :exec
+INSERT INTO stream_outbox (epoch_id, payload) VALUES ($1, $2);
+
+-- name: GetStreamOutbox :many
+SELECT * FROM stream_outbox ORDER BY id ASC LIMIT $1;
+
+-- name: DeleteStreamOutbox :exec
+DELETE FROM stream_outbox WHERE id = $1;
+
+-- name: CountStreamOutbox :one
+SELECT count(*) FROM stream_outbox;
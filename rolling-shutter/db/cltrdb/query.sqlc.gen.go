@@ -33,6 +33,26 @@ func (q *Queries) CountEonPublicKeyVotes(ctx context.Context, hash []byte) (int6
 	return count, err
 }
 
+const countStreamOutbox = `-- name: CountStreamOutbox :one
+SELECT count(*) FROM stream_outbox
+`
+
+func (q *Queries) CountStreamOutbox(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countStreamOutbox)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteStreamOutbox = `-- name: DeleteStreamOutbox :exec
+DELETE FROM stream_outbox WHERE id = $1
+`
+
+func (q *Queries) DeleteStreamOutbox(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteStreamOutbox, id)
+	return err
+}
+
 const existsDecryptionKey = `-- name: ExistsDecryptionKey :one
 SELECT EXISTS (
     SELECT 1
@@ -99,6 +119,37 @@ func (q *Queries) FindEonPublicKeyVotes(ctx context.Context, hash []byte) ([]Eon
 	return items, nil
 }
 
+const getBatchTxsInRange = `-- name: GetBatchTxsInRange :many
+SELECT epoch_id, marshaled, submitted FROM batchtx
+WHERE epoch_id BETWEEN $1 AND $2
+ORDER BY epoch_id ASC
+`
+
+type GetBatchTxsInRangeParams struct {
+	FromEpochID []byte
+	ToEpochID   []byte
+}
+
+func (q *Queries) GetBatchTxsInRange(ctx context.Context, arg GetBatchTxsInRangeParams) ([]Batchtx, error) {
+	rows, err := q.db.Query(ctx, getBatchTxsInRange, arg.FromEpochID, arg.ToEpochID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Batchtx
+	for rows.Next() {
+		var i Batchtx
+		if err := rows.Scan(&i.EpochID, &i.Marshaled, &i.Submitted); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getCommittedTransactionsByEpoch = `-- name: GetCommittedTransactionsByEpoch :many
 SELECT tx_hash, id, epoch_id, tx_bytes, status FROM transaction WHERE status = 'committed' AND epoch_id = $1 ORDER BY id ASC
 `
@@ -161,6 +212,25 @@ func (q *Queries) GetEonPublicKey(ctx context.Context, eon int64) (EonPublicKeyC
 	return i, err
 }
 
+const getL1Intent = `-- name: GetL1Intent :one
+SELECT enforce_one_row, epoch_id, action, payload_hash, nonce, tx_hash, raw_tx FROM l1_intent LIMIT 1
+`
+
+func (q *Queries) GetL1Intent(ctx context.Context) (L1Intent, error) {
+	row := q.db.QueryRow(ctx, getL1Intent)
+	var i L1Intent
+	err := row.Scan(
+		&i.EnforceOneRow,
+		&i.EpochID,
+		&i.Action,
+		&i.PayloadHash,
+		&i.Nonce,
+		&i.TxHash,
+		&i.RawTx,
+	)
+	return i, err
+}
+
 const getLastBatchEpochID = `-- name: GetLastBatchEpochID :one
 SELECT epoch_id FROM decryption_trigger ORDER BY epoch_id DESC LIMIT 1
 `
@@ -213,6 +283,52 @@ func (q *Queries) GetNonRejectedTransactionsByEpoch(ctx context.Context, epochID
 	return items, nil
 }
 
+const getStreamOutbox = `-- name: GetStreamOutbox :many
+SELECT id, epoch_id, payload, created_at FROM stream_outbox ORDER BY id ASC LIMIT $1
+`
+
+func (q *Queries) GetStreamOutbox(ctx context.Context, limit int32) ([]StreamOutbox, error) {
+	rows, err := q.db.Query(ctx, getStreamOutbox, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StreamOutbox
+	for rows.Next() {
+		var i StreamOutbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.EpochID,
+			&i.Payload,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransaction = `-- name: GetTransaction :one
+SELECT tx_hash, id, epoch_id, tx_bytes, status FROM transaction WHERE tx_hash = $1
+`
+
+func (q *Queries) GetTransaction(ctx context.Context, txHash []byte) (Transaction, error) {
+	row := q.db.QueryRow(ctx, getTransaction, txHash)
+	var i Transaction
+	err := row.Scan(
+		&i.TxHash,
+		&i.ID,
+		&i.EpochID,
+		&i.TxBytes,
+		&i.Status,
+	)
+	return i, err
+}
+
 const getTransactionsByEpoch = `-- name: GetTransactionsByEpoch :many
 SELECT tx_hash, id, epoch_id, tx_bytes, status FROM transaction WHERE epoch_id = $1 ORDER BY id ASC
 `
@@ -383,6 +499,20 @@ func (q *Queries) InsertEonPublicKeyVote(ctx context.Context, arg InsertEonPubli
 	return err
 }
 
+const insertStreamOutbox = `-- name: InsertStreamOutbox :exec
+INSERT INTO stream_outbox (epoch_id, payload) VALUES ($1, $2)
+`
+
+type InsertStreamOutboxParams struct {
+	EpochID []byte
+	Payload []byte
+}
+
+func (q *Queries) InsertStreamOutbox(ctx context.Context, arg InsertStreamOutboxParams) error {
+	_, err := q.db.Exec(ctx, insertStreamOutbox, arg.EpochID, arg.Payload)
+	return err
+}
+
 const insertTrigger = `-- name: InsertTrigger :exec
 INSERT INTO decryption_trigger (epoch_id, batch_hash, l1_block_number) VALUES ($1, $2, $3)
 `
@@ -439,6 +569,34 @@ func (q *Queries) SetBatchSubmitted(ctx context.Context) error {
 	return err
 }
 
+const setL1Intent = `-- name: SetL1Intent :exec
+INSERT INTO l1_intent (epoch_id, action, payload_hash, nonce, tx_hash, raw_tx)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (enforce_one_row) DO UPDATE
+SET epoch_id = $1, action = $2, payload_hash = $3, nonce = $4, tx_hash = $5, raw_tx = $6
+`
+
+type SetL1IntentParams struct {
+	EpochID     []byte
+	Action      string
+	PayloadHash []byte
+	Nonce       int64
+	TxHash      []byte
+	RawTx       []byte
+}
+
+func (q *Queries) SetL1Intent(ctx context.Context, arg SetL1IntentParams) error {
+	_, err := q.db.Exec(ctx, setL1Intent,
+		arg.EpochID,
+		arg.Action,
+		arg.PayloadHash,
+		arg.Nonce,
+		arg.TxHash,
+		arg.RawTx,
+	)
+	return err
+}
+
 const setNextBatch = `-- name: SetNextBatch :exec
 INSERT INTO next_batch (epoch_id, l1_block_number) VALUES ($1, $2)
 ON CONFLICT (enforce_one_row) DO UPDATE
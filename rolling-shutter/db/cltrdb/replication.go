@@ -0,0 +1,17 @@
+package cltrdb
+
+import "github.com/shutter-network/rolling-shutter/rolling-shutter/db/replication"
+
+// Publication publishes the eon keys, decryption keys, batch transactions and keyper sets of the
+// collator database for logical replication. All published tables have a primary key that is
+// never updated, so the default replica identity is sufficient.
+var Publication = &replication.Publication{
+	Name: "shutter_collator",
+	Tables: []replication.Table{
+		{Name: "eon_public_key_candidate", Identity: replication.IdentityDefault},
+		{Name: "decryption_key", Identity: replication.IdentityDefault},
+		{Name: "batchtx", Identity: replication.IdentityDefault},
+		// keyper_set is part of the chain observer schema included in the collator database
+		{Name: "keyper_set", Identity: replication.IdentityDefault},
+	},
+}
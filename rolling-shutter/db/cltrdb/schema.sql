@@ -1,4 +1,4 @@
--- schema-version: collator-12 --
+-- schema-version: collator-27 --
 -- Please change the version above if you make incompatible changes to
 -- the schema. We'll use this to check we're using the right schema.
 
@@ -68,6 +68,20 @@ CREATE TABLE next_batch(
     l1_block_number bigint NOT NULL
 );
 
+-- l1_intent records the last transaction the batch poster signed, before it is broadcast to L1.
+-- After a restart, it is reconciled with the chain, so that a crash between signing and
+-- broadcasting neither posts a batch twice nor loses it. payload_hash is the keccak256 hash of the
+-- batch and raw_tx the signed transaction as it is broadcast.
+CREATE TABLE l1_intent(
+    enforce_one_row BOOL PRIMARY KEY DEFAULT TRUE,
+    epoch_id bytea NOT NULL,
+    action text NOT NULL,
+    payload_hash bytea NOT NULL,
+    nonce bigint NOT NULL,
+    tx_hash bytea NOT NULL,
+    raw_tx bytea NOT NULL
+);
+
 CREATE TABLE batchtx(
        epoch_id bytea PRIMARY KEY,
        marshaled bytea NOT NULL,
@@ -90,6 +104,16 @@ CREATE TRIGGER notify_batchtx
 -- ensure we only have at most one tx not submitted yet
 CREATE UNIQUE INDEX batchtx_at_most_one_not_yet_submitted ON batchtx (submitted) WHERE submitted = false;
 
+-- stream_outbox holds the decrypted batches still to be published to the configured stream. Rows
+-- are inserted in the same transaction as the batchtx and deleted once the broker acknowledged
+-- them, so that no batch is lost or published out of order when the collator crashes.
+CREATE TABLE stream_outbox(
+    id bigserial PRIMARY KEY,
+    epoch_id bytea NOT NULL,
+    payload bytea NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
 -- CREATE TABLE eon(
 --      activation_block_number bigint NOT NULL,
 --      eon_public_key bytea,
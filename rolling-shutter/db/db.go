@@ -4,7 +4,9 @@ package db
 
 import (
 	"context"
+	"crypto/sha256"
 	"embed"
+	"io/fs"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
@@ -23,6 +25,27 @@ func GetSchema(n string) string {
 	return string(b)
 }
 
+// SchemaFingerprint returns a hash of all schemas, which changes whenever any of them changes.
+func SchemaFingerprint() []byte {
+	hash := sha256.New()
+	err := fs.WalkDir(schemas, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := schemas.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		hash.Write([]byte(path))
+		hash.Write(b)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return hash.Sum(nil)
+}
+
 func MustFindSchemaVersion(path string) string {
 	return shdb.MustFindSchemaVersion(GetSchema(path), path)
 }
@@ -0,0 +1,3 @@
+// Package jobdb contains the sqlc generated files for the persistent job queue, which retries side
+// effects of a node until they succeed.
+package jobdb
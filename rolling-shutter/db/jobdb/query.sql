@@ -0,0 +1,36 @@
+-- name: InsertJob :one
+INSERT INTO job (kind, payload) VALUES ($1, $2)
+RETURNING id;
+
+-- name: InsertScheduledJob :one
+INSERT INTO job (kind, payload, run_at) VALUES ($1, $2, $3)
+RETURNING id;
+
+-- name: ClaimDueJob :one
+-- ClaimDueJob picks the job due first and postpones it until the lease ends, so that it is run
+-- again if the worker dies before the job is finished.
+UPDATE job SET attempts = attempts + 1, run_at = sqlc.arg(lease_until)
+WHERE id = (
+    SELECT id FROM job
+    WHERE NOT dead AND run_at <= sqlc.arg(now)
+    ORDER BY run_at, id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: DeleteJob :exec
+DELETE FROM job WHERE id = $1;
+
+-- name: RescheduleJob :exec
+UPDATE job SET run_at = $2, last_error = $3 WHERE id = $1;
+
+-- name: MarkJobDead :exec
+UPDATE job SET dead = true, last_error = $2 WHERE id = $1;
+
+-- name: GetDeadJobs :many
+SELECT * FROM job WHERE dead ORDER BY id;
+
+-- name: RetryDeadJob :execrows
+UPDATE job SET dead = false, attempts = 0, run_at = now()
+WHERE id = $1 AND dead;
@@ -0,0 +1,15 @@
+-- job contains side effects that have to be retried until they succeed, e.g. notifications sent
+-- to external services. Jobs are enqueued in the same transaction as the state change triggering
+-- them and deleted once they are done. Jobs that keep failing are kept as dead letters for the
+-- operator to inspect.
+CREATE TABLE job (
+       id bigserial PRIMARY KEY,
+       kind text NOT NULL,
+       payload bytea NOT NULL,
+       attempts integer NOT NULL DEFAULT 0,
+       run_at timestamptz NOT NULL DEFAULT now(),
+       last_error text NOT NULL DEFAULT '',
+       dead boolean NOT NULL DEFAULT false,
+       created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX job_due_idx ON job (run_at) WHERE NOT dead;
@@ -19,7 +19,9 @@ import (
 var schemaVersion = db.MustFindSchemaVersion("kprdb")
 
 func initDB(ctx context.Context, tx pgx.Tx) error {
-	err := db.Create(ctx, tx, []string{"kprdb", "chainobsdb", "metadb"})
+	err := db.Create(ctx, tx, []string{
+		"kprdb", "chainobsdb", "metadb", "auditdb", "metricsdb", "jobdb", "peerdb", "paramdb", "provenancedb",
+	})
 	if err != nil {
 		return err
 	}
@@ -30,6 +32,12 @@ func initDB(ctx context.Context, tx pgx.Tx) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to set schema version in meta_inf table")
 	}
+	// schema.sql creates the tables in the shape the migrations lead to
+	for _, m := range Migrations {
+		if err := m.MarkContracted(ctx, tx); err != nil {
+			return err
+		}
+	}
 	err = New(tx).TMSetSyncMeta(ctx, TMSetSyncMetaParams{
 		CurrentBlock:        0,
 		LastCommittedHeight: -1,
@@ -1,19 +1,39 @@
 package kprdb
 
 import (
+	"bytes"
 	"context"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/auditdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
 )
 
+// ErrNonceConsumed is returned if a signer signed another message with the same nonce before.
+var ErrNonceConsumed = errors.New("signing nonce has been consumed by another message")
+
+// ErrConflictingVote is returned if a keyper voted for something else in the same eon or sequence
+// before.
+var ErrConflictingVote = errors.New("conflicting vote")
+
+// ErrLeaseLost is returned if another process acquired the lease on the database.
+var ErrLeaseLost = errors.New("database lease has been acquired by another process")
+
+// Fence runs f in a transaction. Keypers pass their process lease, which only commits the
+// transaction while the process holds the lease on the database, so that two processes sharing a
+// database never sign messages at the same time. Tests may pass the *pgxpool.Pool.
+type Fence interface {
+	BeginFunc(ctx context.Context, f func(pgx.Tx) error) error
+}
+
 func GetKeyperIndex(addr common.Address, keypers []string) (uint64, bool) {
 	hexaddr := shdb.EncodeAddress(addr)
 	for i, a := range keypers {
@@ -28,10 +48,84 @@ func (bc *TendermintBatchConfig) KeyperIndex(addr common.Address) (uint64, bool)
 	return GetKeyperIndex(addr, bc.Keypers)
 }
 
-func (q *Queries) InsertDecryptionKeyMsg(ctx context.Context, msg *p2pmsg.DecryptionKey) error {
+// AuditLog returns the queries for the audit log, using the same connection or transaction.
+func (q *Queries) AuditLog() *auditdb.Queries {
+	return auditdb.New(q.db)
+}
+
+// CheckProcessLease fails with ErrLeaseLost unless the lease on the database is held with the given
+// token. The lease stays locked until the end of the transaction.
+func (q *Queries) CheckProcessLease(ctx context.Context, token int64) error {
+	current, err := q.GetProcessLeaseToken(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get lease token from db")
+	}
+	if current != token {
+		return errors.Wrapf(ErrLeaseLost, "lease token is %d, ours is %d", current, token)
+	}
+	return nil
+}
+
+// AllocateNonce sets the signing nonce of msg, which signer is about to sign in the given domain.
+// If signer has been allocated a nonce for msg before, msg gets the same one again, otherwise the
+// next one in the sequence of signer.
+func (q *Queries) AllocateNonce(
+	ctx context.Context, domain p2pmsg.SigningDomain, signer common.Address, msg p2pmsg.DomainSignable,
+) error {
+	separator := domain.Separator(msg.SigningKind())
+	encodedSigner := shdb.EncodeAddress(signer)
+	hash := msg.Hash()
+	nonce, err := q.GetSigningNonce(ctx, GetSigningNonceParams{
+		Domain:      separator,
+		Signer:      encodedSigner,
+		MessageHash: hash,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		nonce, err = q.AllocateSigningNonce(ctx, AllocateSigningNonceParams{
+			Domain:      separator,
+			Signer:      encodedSigner,
+			MessageHash: hash,
+		})
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to allocate signing nonce")
+	}
+	msg.SetSigningNonce(uint64(nonce))
+	return nil
+}
+
+// ConsumeNonce records that signer signed msg with its nonce in the given domain. Consuming the
+// nonce again for the same message is a no-op, for another message it returns ErrNonceConsumed.
+// Messages signed with the legacy hash have no nonce and are ignored.
+func (q *Queries) ConsumeNonce(
+	ctx context.Context, domain p2pmsg.SigningDomain, signer common.Address, msg p2pmsg.DomainSignable,
+) error {
+	if msg.GetSigningNonce() == 0 {
+		return nil
+	}
+	hash := msg.Hash()
+	consumedHash, err := q.ConsumeSigningNonce(ctx, ConsumeSigningNonceParams{
+		Domain:      domain.Separator(msg.SigningKind()),
+		Signer:      shdb.EncodeAddress(signer),
+		Nonce:       int64(msg.GetSigningNonce()),
+		MessageHash: hash,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to consume signing nonce")
+	}
+	if !bytes.Equal(consumedHash, hash) {
+		return errors.Wrapf(ErrNonceConsumed, "nonce %d of %s signed by %s",
+			msg.GetSigningNonce(), msg.SigningKind(), signer.Hex())
+	}
+	return nil
+}
+
+// InsertDecryptionKeyMsg stores the key from the message unless a key for the same eon and epoch
+// exists already. It returns whether the key has been inserted.
+func (q *Queries) InsertDecryptionKeyMsg(ctx context.Context, msg *p2pmsg.DecryptionKey) (bool, error) {
 	epochID, err := epochid.BytesToEpochID(msg.EpochID)
 	if err != nil {
-		return err
+		return false, err
 	}
 	tag, err := q.InsertDecryptionKey(ctx, InsertDecryptionKeyParams{
 		Eon:           int64(msg.Eon),
@@ -39,23 +133,26 @@ func (q *Queries) InsertDecryptionKeyMsg(ctx context.Context, msg *p2pmsg.Decryp
 		DecryptionKey: msg.Key,
 	})
 	if err != nil {
-		return errors.Wrapf(err, "failed to insert decryption key for epoch %s", epochID)
+		return false, errors.Wrapf(err, "failed to insert decryption key for epoch %s", epochID)
 	}
 	if tag.RowsAffected() == 0 {
 		log.Info().Str("epoch-id", epochID.Hex()).
 			Msg("attempted to insert decryption key in db, but it already exists")
+		return false, nil
 	}
-	return nil
+	return true, nil
 }
 
 func (q *Queries) InsertDecryptionKeySharesMsg(ctx context.Context, msg *p2pmsg.DecryptionKeyShares) error {
+	dualWrite := DecryptionKeyShareCreatedAt.DualWriting()
 	for _, share := range msg.GetShares() {
-		err := q.InsertDecryptionKeyShare(ctx, InsertDecryptionKeyShareParams{
+		arg := InsertDecryptionKeyShareParams{
 			Eon:                int64(msg.Eon),
 			EpochID:            share.EpochID,
 			KeyperIndex:        int64(msg.KeyperIndex),
 			DecryptionKeyShare: share.Share,
-		})
+		}
+		err := q.InsertDecryptionKeyShare(ctx, arg)
 		if err != nil {
 			return errors.Wrapf(
 				err,
@@ -63,6 +160,14 @@ func (q *Queries) InsertDecryptionKeySharesMsg(ctx context.Context, msg *p2pmsg.
 				msg.KeyperIndex,
 			)
 		}
+		if dualWrite {
+			err = DecryptionKeyShareCreatedAt.ExecDualWrite(
+				ctx, q.db, arg.Eon, arg.EpochID, arg.KeyperIndex, arg.DecryptionKeyShare,
+			)
+			if err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
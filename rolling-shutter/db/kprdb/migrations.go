@@ -0,0 +1,57 @@
+package kprdb
+
+import (
+	"context"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/migration"
+)
+
+// DecryptionKeyShareCreatedAt records the time decryption key shares have been received at.
+// Adding a column with a volatile default rewrites the whole decryption_key_share table while
+// holding an exclusive lock, so the column is added with an online migration instead. Databases
+// created with the column in schema.sql start out with the migration contracted.
+var DecryptionKeyShareCreatedAt = &migration.Migration{
+	Name: "decryption-key-share-created-at",
+	Expand: `
+CREATE TABLE decryption_key_share_v2 (
+       eon bigint,
+       epoch_id bytea,
+       keyper_index bigint,
+       decryption_key_share bytea,
+       created_at timestamptz NOT NULL DEFAULT now(),
+       PRIMARY KEY (eon, epoch_id, keyper_index)
+);`,
+	DualWrite: `
+INSERT INTO decryption_key_share_v2 (eon, epoch_id, keyper_index, decryption_key_share)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING`,
+	Backfill: `
+INSERT INTO decryption_key_share_v2 (eon, epoch_id, keyper_index, decryption_key_share)
+SELECT s.eon, s.epoch_id, s.keyper_index, s.decryption_key_share
+FROM decryption_key_share s
+WHERE NOT EXISTS (
+    SELECT 1 FROM decryption_key_share_v2 n
+    WHERE n.eon = s.eon AND n.epoch_id = s.epoch_id AND n.keyper_index = s.keyper_index
+)
+LIMIT $1`,
+	Lock: `LOCK TABLE decryption_key_share IN SHARE ROW EXCLUSIVE MODE;`,
+	Switch: `
+ALTER TABLE decryption_key_share RENAME TO decryption_key_share_old;
+ALTER TABLE decryption_key_share_v2 RENAME TO decryption_key_share;`,
+	Contract: `DROP TABLE decryption_key_share_old;`,
+}
+
+// Migrations lists the online migrations of the keyper database by name.
+var Migrations = map[string]*migration.Migration{
+	DecryptionKeyShareCreatedAt.Name: DecryptionKeyShareCreatedAt,
+}
+
+// LoadMigrations reads which of the migrations require dual writes.
+func LoadMigrations(ctx context.Context, db DBTX) error {
+	for _, m := range Migrations {
+		if err := m.LoadDualWriting(ctx, db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -9,6 +9,16 @@ import (
 	"time"
 )
 
+type Annotation struct {
+	ID         int64
+	EntityKind string
+	EntityID   string
+	Label      string
+	Note       string
+	Author     string
+	CreatedAt  time.Time
+}
+
 type DecryptionKey struct {
 	Eon           int64
 	EpochID       []byte
@@ -20,12 +30,20 @@ type DecryptionKeyShare struct {
 	EpochID            []byte
 	KeyperIndex        int64
 	DecryptionKeyShare []byte
+	CreatedAt          time.Time
 }
 
 type DecryptionTrigger struct {
 	EpochID []byte
 }
 
+type DkgParameter struct {
+	KeyperConfigIndex      int64
+	PhaseLength            int64
+	MaxMissingParticipants int64
+	BlockNumber            int64
+}
+
 type DkgResult struct {
 	Eon        int64
 	Success    bool
@@ -33,6 +51,15 @@ type DkgResult struct {
 	PureResult []byte
 }
 
+type DkgTranscript struct {
+	ID     int64
+	Eon    int64
+	Height int64
+	Phase  int32
+	Sender string
+	Event  []byte
+}
+
 type Eon struct {
 	Eon                   int64
 	Height                int64
@@ -40,6 +67,87 @@ type Eon struct {
 	KeyperConfigIndex     int64
 }
 
+type EonDkgParameter struct {
+	Eon                    int64
+	PhaseLength            int64
+	MaxMissingParticipants int64
+}
+
+type EonPublicKeyCandidate struct {
+	Hash                  []byte
+	EonPublicKey          []byte
+	ActivationBlockNumber int64
+	KeyperConfigIndex     int64
+	Eon                   int64
+	Confirmed             bool
+}
+
+type EonPublicKeyVote struct {
+	Hash              []byte
+	Sender            string
+	Signature         []byte
+	Eon               int64
+	KeyperConfigIndex int64
+}
+
+type EpochParticipation struct {
+	Eon           int64
+	EpochID       []byte
+	KeyperIndices []int64
+	RecordedAt    time.Time
+}
+
+type EpochPreAnnouncement struct {
+	EpochID             []byte
+	BlockNumber         int64
+	ExpectedTriggerTime time.Time
+	ReceivedAt          time.Time
+}
+
+type EpochSla struct {
+	EpochID          []byte
+	TriggeredAt      time.Time
+	SharesCompleteAt sql.NullTime
+	KeyAvailableAt   sql.NullTime
+	KeySource        string
+}
+
+type FinalizedEpoch struct {
+	Eon         int64
+	EpochID     []byte
+	FinalizedAt time.Time
+}
+
+type KeyEscrow struct {
+	Eon         int64
+	Fingerprint []byte
+	WrittenAt   time.Time
+}
+
+type KeyGenerationPause struct {
+	EnforceOneRow     bool
+	Paused            bool
+	Sequence          int64
+	KeyperConfigIndex int64
+	Reason            string
+	UpdatedAt         time.Time
+}
+
+type KeyperBond struct {
+	Address              string
+	Bonded               []byte
+	Unbonding            []byte
+	UnbondingBlockNumber int64
+	BlockNumber          int64
+	LogIndex             int64
+}
+
+type KeyperBondMinimum struct {
+	EnforceOneRow bool
+	MinimumBond   []byte
+	BlockNumber   int64
+}
+
 type LastBatchConfigSent struct {
 	EnforceOneRow     bool
 	KeyperConfigIndex int64
@@ -50,22 +158,78 @@ type LastBlockSeen struct {
 	BlockNumber   int64
 }
 
+type MempoolObservation struct {
+	TxHash               []byte
+	Sender               string
+	Inbox                string
+	Nonce                int64
+	PayloadHash          []byte
+	MaxFeePerGas         []byte
+	FirstSeenBlockNumber int64
+	FirstSeenAt          time.Time
+	IncludedBlockNumber  int64
+	Finding              string
+	FindingDetails       string
+}
+
+type NodeVersionRequirement struct {
+	EnforceOneRow          bool
+	MinimumVersion         string
+	MinimumProtocolVersion int64
+	BlockNumber            int64
+}
+
 type OutgoingEonKey struct {
 	EonPublicKey []byte
 	Eon          int64
 }
 
+type PauseVote struct {
+	KeyperConfigIndex int64
+	Sequence          int64
+	Sender            string
+	Paused            bool
+	Reason            string
+	Signature         []byte
+	ReceivedAt        time.Time
+}
+
 type PolyEval struct {
 	Eon             int64
 	ReceiverAddress string
 	Eval            []byte
 }
 
+type ProcessLease struct {
+	ID         bool
+	Pid        int32
+	Hostname   string
+	AcquiredAt time.Time
+	RenewedAt  time.Time
+	Token      int64
+}
+
 type Puredkg struct {
 	Eon     int64
 	Puredkg []byte
 }
 
+type ShareSelfAuditFailure struct {
+	Eon         int64
+	EpochID     []byte
+	KeyperIndex int64
+	Share       []byte
+	DetectedAt  time.Time
+}
+
+type SigningNonce struct {
+	Domain      []byte
+	Signer      string
+	Nonce       int64
+	MessageHash []byte
+	ConsumedAt  time.Time
+}
+
 type TendermintBatchConfig struct {
 	KeyperConfigIndex     int32
 	Height                int64
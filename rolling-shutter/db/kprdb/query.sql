@@ -7,6 +7,17 @@ ON CONFLICT DO NOTHING;
 SELECT * FROM decryption_key
 WHERE eon = $1 AND epoch_id = $2;
 
+-- name: GetDecryptionKeysOfEon :many
+SELECT * FROM decryption_key
+WHERE eon = $1
+ORDER BY epoch_id;
+
+-- name: GetDecryptionKeysInRange :many
+SELECT * FROM decryption_key
+WHERE eon = @eon AND epoch_id BETWEEN @from_epoch_id AND @to_epoch_id
+ORDER BY epoch_id
+LIMIT @max_keys;
+
 -- name: ExistsDecryptionKey :one
 SELECT EXISTS (
     SELECT 1
@@ -19,13 +30,24 @@ INSERT INTO decryption_key_share (eon, epoch_id, keyper_index, decryption_key_sh
 VALUES ($1, $2, $3, $4);
 
 -- name: SelectDecryptionKeyShares :many
-SELECT * FROM decryption_key_share
+SELECT eon, epoch_id, keyper_index, decryption_key_share FROM decryption_key_share
 WHERE eon = $1 AND epoch_id = $2;
 
 -- name: GetDecryptionKeyShare :one
-SELECT * FROM decryption_key_share
+SELECT eon, epoch_id, keyper_index, decryption_key_share FROM decryption_key_share
 WHERE eon = $1 AND epoch_id = $2 AND keyper_index = $3;
 
+-- name: GetDecryptionKeySharesOfEon :many
+SELECT eon, epoch_id, keyper_index, decryption_key_share FROM decryption_key_share
+WHERE eon = $1
+ORDER BY epoch_id, keyper_index;
+
+-- name: GetDecryptionKeySharesInRange :many
+SELECT eon, epoch_id, keyper_index, decryption_key_share FROM decryption_key_share
+WHERE eon = @eon AND epoch_id BETWEEN @from_epoch_id AND @to_epoch_id
+ORDER BY epoch_id, keyper_index
+LIMIT @max_shares;
+
 -- name: ExistsDecryptionKeyShare :one
 SELECT EXISTS (
     SELECT 1
@@ -37,6 +59,68 @@ SELECT EXISTS (
 SELECT count(*) FROM decryption_key_share
 WHERE eon = $1 AND epoch_id = $2;
 
+-- name: InsertFinalizedEpoch :exec
+INSERT INTO finalized_epochs (eon, epoch_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteFinalizedDecryptionKeyShares :execrows
+DELETE FROM decryption_key_share s
+USING finalized_epochs f
+WHERE s.eon = f.eon AND s.epoch_id = f.epoch_id AND f.finalized_at < @finalized_before;
+
+-- name: DeleteFinalizedEpochsBefore :execrows
+DELETE FROM finalized_epochs
+WHERE finalized_at < @finalized_before;
+
+-- name: GetFinalizedEpoch :one
+SELECT * FROM finalized_epochs
+WHERE eon = $1 AND epoch_id = $2;
+
+-- name: GetLatestFinalizedEpoch :one
+SELECT * FROM finalized_epochs
+ORDER BY finalized_at DESC
+LIMIT 1;
+
+-- name: GetRecentFinalizedEpochs :many
+SELECT f.eon, f.epoch_id, f.finalized_at, k.decryption_key FROM finalized_epochs f
+LEFT JOIN decryption_key k ON k.eon = f.eon AND k.epoch_id = f.epoch_id
+ORDER BY f.finalized_at DESC, f.eon DESC, f.epoch_id DESC
+LIMIT $1;
+
+-- name: GetFinalizedEpochsBefore :many
+SELECT f.eon, f.epoch_id, f.finalized_at, k.decryption_key FROM finalized_epochs f
+LEFT JOIN decryption_key k ON k.eon = f.eon AND k.epoch_id = f.epoch_id
+WHERE (f.finalized_at, f.eon, f.epoch_id) < (@finalized_at::timestamptz, @eon::bigint, @epoch_id::bytea)
+ORDER BY f.finalized_at DESC, f.eon DESC, f.epoch_id DESC
+LIMIT @max_epochs;
+
+-- name: InsertEpochParticipation :exec
+INSERT INTO epoch_participation (eon, epoch_id, keyper_indices)
+SELECT @eon::bigint, @epoch_id::bytea, COALESCE(array_agg(keyper_index ORDER BY keyper_index), '{}')
+FROM decryption_key_share
+WHERE eon = @eon AND epoch_id = @epoch_id
+ON CONFLICT DO NOTHING;
+
+-- name: GetRecentEpochParticipation :many
+SELECT * FROM epoch_participation
+WHERE eon = $1
+ORDER BY recorded_at DESC
+LIMIT $2;
+
+-- name: PruneEpochParticipation :execrows
+DELETE FROM epoch_participation
+WHERE recorded_at < (
+    SELECT min(recorded_at) FROM (
+        SELECT recorded_at FROM epoch_participation
+        ORDER BY recorded_at DESC LIMIT $1
+    ) AS recent
+);
+
+-- name: DeleteDecryptionKeySharesBeforeEon :execrows
+DELETE FROM decryption_key_share
+WHERE eon < $1;
+
 -- name: InsertBatchConfig :exec
 INSERT INTO tendermint_batch_config (keyper_config_index, height, keypers, threshold, started, activation_block_number)
 VALUES ($1, $2, $3, $4, $5, $6);
@@ -69,6 +153,12 @@ WHERE keyper_config_index = $1;
 UPDATE tendermint_batch_config SET started = TRUE
 WHERE keyper_config_index = $1;
 
+-- name: RotateBatchConfigAddress :execrows
+UPDATE tendermint_batch_config
+SET keypers[@keyper_index::integer + 1] = @new_address::text
+WHERE keyper_config_index = @keyper_config_index
+AND keypers[@keyper_index::integer + 1] = @old_address::text;
+
 -- name: TMSetSyncMeta :exec
 INSERT INTO tendermint_sync_meta (current_block, last_committed_height, sync_timestamp)
 VALUES ($1, $2, $3);
@@ -101,6 +191,13 @@ INSERT INTO tendermint_encryption_key (address, encryption_public_key) VALUES ($
 -- name: GetEncryptionKeys :many
 SELECT * FROM tendermint_encryption_key;
 
+-- name: CopyEncryptionKey :exec
+INSERT INTO tendermint_encryption_key (address, encryption_public_key)
+SELECT @new_address::text, encryption_public_key
+FROM tendermint_encryption_key
+WHERE address = @old_address::text
+ON CONFLICT DO NOTHING;
+
 -- name: ScheduleSerializedShutterMessage :one
 INSERT INTO tendermint_outgoing_messages (description, msg)
 VALUES ($1, $2)
@@ -154,6 +251,12 @@ DELETE FROM poly_evals ev WHERE ev.eon=$1 AND ev.receiver_address=$2;
 -- name: DeletePolyEvalByEon :execresult
 DELETE FROM poly_evals ev WHERE ev.eon=$1;
 
+-- name: RotatePolyEvalsReceiver :execrows
+UPDATE poly_evals
+SET receiver_address = @new_address::text
+WHERE receiver_address = @old_address::text
+AND eon IN (SELECT eon FROM eons WHERE keyper_config_index = @keyper_config_index);
+
 -- name: InsertDKGResult :exec
 INSERT INTO dkg_result (eon,success,error,pure_result)
 VALUES ($1,$2,$3,$4);
@@ -162,6 +265,25 @@ VALUES ($1,$2,$3,$4);
 SELECT * FROM dkg_result
 WHERE eon = $1;
 
+-- name: GetSuccessfulDKGResults :many
+SELECT * FROM dkg_result
+WHERE success
+ORDER BY eon;
+
+-- name: ReplaceDKGResult :execrows
+UPDATE dkg_result
+SET pure_result = $2
+WHERE eon = $1 AND success;
+
+-- name: InsertDKGTranscriptEntry :exec
+INSERT INTO dkg_transcript (eon, height, phase, sender, event)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetDKGTranscript :many
+SELECT * FROM dkg_transcript
+WHERE eon = $1
+ORDER BY id;
+
 -- name: GetDKGResultForBlockNumber :one
 SELECT * FROM dkg_result
 WHERE eon = (SELECT eon FROM eons WHERE activation_block_number <= sqlc.arg(block_number)
@@ -197,3 +319,269 @@ SET block_number = $1;
 
 -- name: GetLastBlockSeen :one
 SELECT block_number FROM last_block_seen LIMIT 1;
+
+-- name: InsertEonPublicKeyCandidate :exec
+INSERT INTO eon_public_key_candidate
+       (hash, eon_public_key, activation_block_number, keyper_config_index, eon)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT DO NOTHING;
+
+-- InsertEonPublicKeyVote stores the vote unless the sender voted in the same eon already and
+-- returns the hash of the key the sender voted for first.
+-- name: InsertEonPublicKeyVote :one
+INSERT INTO eon_public_key_vote
+       (hash, sender, signature, eon, keyper_config_index)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (sender, eon) DO UPDATE SET sender = EXCLUDED.sender
+RETURNING hash;
+
+-- name: CountEonPublicKeyVotes :one
+SELECT COUNT(*) from eon_public_key_vote WHERE hash=$1;
+
+-- name: ConfirmEonPublicKey :exec
+UPDATE eon_public_key_candidate
+SET confirmed=TRUE
+WHERE hash=$1;
+
+-- name: GetConfirmedEonPublicKey :one
+SELECT * FROM eon_public_key_candidate
+WHERE confirmed AND eon = $1
+LIMIT 1;
+
+-- name: GetEonPublicKeyCandidates :many
+SELECT * FROM eon_public_key_candidate
+WHERE eon = $1
+ORDER BY confirmed DESC, hash;
+
+-- name: FindEonPublicKeyVotes :many
+SELECT * FROM eon_public_key_vote WHERE hash=$1 ORDER BY sender;
+
+-- name: GetProcessLease :one
+SELECT * FROM process_lease;
+
+-- GetProcessLeaseToken locks the lease until the end of the transaction, so that it can't be
+-- acquired by another process in the meantime.
+-- name: GetProcessLeaseToken :one
+SELECT token FROM process_lease FOR SHARE;
+
+-- name: SetProcessLease :one
+INSERT INTO process_lease (pid, hostname, acquired_at, renewed_at, token)
+VALUES ($1, $2, now(), now(), 1)
+ON CONFLICT (id) DO UPDATE
+SET pid = EXCLUDED.pid, hostname = EXCLUDED.hostname,
+    acquired_at = EXCLUDED.acquired_at, renewed_at = EXCLUDED.renewed_at,
+    token = process_lease.token + 1
+RETURNING token;
+
+-- name: RenewProcessLease :execrows
+UPDATE process_lease SET renewed_at = now() WHERE token = $1;
+
+-- name: GetSigningNonce :one
+SELECT nonce FROM signing_nonce
+WHERE domain = $1 AND signer = $2 AND message_hash = $3;
+
+-- AllocateSigningNonce records the next nonce of the signer for the message.
+-- name: AllocateSigningNonce :one
+INSERT INTO signing_nonce (domain, signer, nonce, message_hash)
+SELECT @domain::bytea, @signer::text, COALESCE(max(nonce), 0) + 1, @message_hash::bytea
+FROM signing_nonce
+WHERE domain = @domain AND signer = @signer
+RETURNING nonce;
+
+-- ConsumeSigningNonce records the nonce unless it has been consumed already and returns the hash of
+-- the message it has been consumed by first.
+-- name: ConsumeSigningNonce :one
+INSERT INTO signing_nonce (domain, signer, nonce, message_hash)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (domain, signer, nonce) DO UPDATE SET domain = EXCLUDED.domain
+RETURNING message_hash;
+
+-- name: UpsertKeyperBond :exec
+INSERT INTO keyper_bond (address, bonded, unbonding, unbonding_block_number, block_number, log_index)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (address) DO UPDATE
+SET bonded = EXCLUDED.bonded, unbonding = EXCLUDED.unbonding,
+    unbonding_block_number = EXCLUDED.unbonding_block_number,
+    block_number = EXCLUDED.block_number, log_index = EXCLUDED.log_index;
+
+-- name: GetKeyperBonds :many
+SELECT * FROM keyper_bond ORDER BY address;
+
+-- name: SetMinimumBond :exec
+INSERT INTO keyper_bond_minimum (minimum_bond, block_number) VALUES ($1, $2)
+ON CONFLICT (enforce_one_row) DO UPDATE
+SET minimum_bond = EXCLUDED.minimum_bond, block_number = EXCLUDED.block_number;
+
+-- name: GetMinimumBond :one
+SELECT minimum_bond FROM keyper_bond_minimum LIMIT 1;
+
+-- CountPendingEpochs counts the epochs we have decryption key shares for, but no decryption key.
+-- name: CountPendingEpochs :one
+SELECT count(*) FROM (
+    SELECT DISTINCT s.eon, s.epoch_id FROM decryption_key_share s
+    WHERE NOT EXISTS (
+        SELECT 1 FROM decryption_key k WHERE k.eon = s.eon AND k.epoch_id = s.epoch_id
+    )
+) AS pending;
+
+-- name: InsertEpochPreAnnouncement :exec
+INSERT INTO epoch_pre_announcement (epoch_id, block_number, expected_trigger_time)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: GetEpochPreAnnouncement :one
+SELECT * FROM epoch_pre_announcement
+WHERE epoch_id = $1;
+
+-- name: SetNodeVersionRequirement :exec
+INSERT INTO node_version_requirement (minimum_version, minimum_protocol_version, block_number)
+VALUES ($1, $2, $3)
+ON CONFLICT (enforce_one_row) DO UPDATE
+SET minimum_version = EXCLUDED.minimum_version,
+    minimum_protocol_version = EXCLUDED.minimum_protocol_version,
+    block_number = EXCLUDED.block_number;
+
+-- name: SetDKGParameters :exec
+INSERT INTO dkg_parameters (keyper_config_index, phase_length, max_missing_participants, block_number)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (keyper_config_index) DO UPDATE
+SET phase_length = EXCLUDED.phase_length,
+    max_missing_participants = EXCLUDED.max_missing_participants,
+    block_number = EXCLUDED.block_number;
+
+-- name: GetDKGParameters :one
+SELECT * FROM dkg_parameters WHERE keyper_config_index = $1;
+
+-- name: InsertEonDKGParameters :exec
+INSERT INTO eon_dkg_parameters (eon, phase_length, max_missing_participants)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: GetEonDKGParameters :one
+SELECT * FROM eon_dkg_parameters WHERE eon = $1;
+
+-- name: GetNodeVersionRequirement :one
+SELECT minimum_version, minimum_protocol_version FROM node_version_requirement LIMIT 1;
+
+-- name: InsertShareSelfAuditFailure :exec
+INSERT INTO share_self_audit_failure (eon, epoch_id, keyper_index, share)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING;
+
+-- name: ExistsShareSelfAuditFailure :one
+SELECT EXISTS (
+    SELECT 1
+    FROM share_self_audit_failure
+    WHERE eon = $1
+);
+
+-- name: DeleteShareSelfAuditFailures :execrows
+DELETE FROM share_self_audit_failure WHERE eon = $1;
+
+-- name: GetUnescrowedDKGResults :many
+SELECT * FROM dkg_result r
+WHERE r.success AND NOT EXISTS (
+    SELECT 1 FROM key_escrow e WHERE e.eon = r.eon AND e.fingerprint = $1
+)
+ORDER BY r.eon;
+
+-- name: InsertKeyEscrow :exec
+INSERT INTO key_escrow (eon, fingerprint) VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- InsertPauseVote stores the vote unless the sender voted in the same sequence already and returns
+-- the decision the sender voted for first.
+-- name: InsertPauseVote :one
+INSERT INTO pause_vote (keyper_config_index, sequence, sender, paused, reason, signature)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (keyper_config_index, sequence, sender) DO UPDATE SET sender = EXCLUDED.sender
+RETURNING paused;
+
+-- name: CountPauseVotes :one
+SELECT COUNT(*) FROM pause_vote
+WHERE keyper_config_index = $1 AND sequence = $2 AND paused = $3;
+
+-- name: GetPendingPauseVotes :many
+SELECT * FROM pause_vote
+WHERE sequence > $1
+ORDER BY sequence, sender;
+
+-- SetKeyGenerationPause records a decision to pause or resume key generation unless a decision
+-- with the same or a higher sequence number has been recorded already.
+-- name: SetKeyGenerationPause :execrows
+INSERT INTO key_generation_pause (paused, sequence, keyper_config_index, reason)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (enforce_one_row) DO UPDATE
+SET paused = EXCLUDED.paused, sequence = EXCLUDED.sequence,
+    keyper_config_index = EXCLUDED.keyper_config_index, reason = EXCLUDED.reason, updated_at = now()
+WHERE key_generation_pause.sequence < EXCLUDED.sequence;
+
+-- name: GetKeyGenerationPause :one
+SELECT * FROM key_generation_pause LIMIT 1;
+
+-- name: InsertMempoolObservation :exec
+INSERT INTO mempool_observation (
+    tx_hash, sender, inbox, nonce, payload_hash, max_fee_per_gas, first_seen_block_number
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT DO NOTHING;
+
+-- name: GetPendingMempoolObservations :many
+SELECT * FROM mempool_observation
+WHERE included_block_number = 0 AND finding = ''
+ORDER BY first_seen_block_number, tx_hash;
+
+-- name: GetMempoolObservationsByPayloadHash :many
+SELECT * FROM mempool_observation
+WHERE payload_hash = $1
+ORDER BY first_seen_at, tx_hash;
+
+-- name: SetMempoolObservationIncluded :exec
+UPDATE mempool_observation SET included_block_number = $2
+WHERE tx_hash = $1;
+
+-- name: SetMempoolObservationFinding :exec
+UPDATE mempool_observation SET finding = $2, finding_details = $3
+WHERE tx_hash = $1;
+
+-- name: InsertAnnotation :one
+INSERT INTO annotation (entity_kind, entity_id, label, note, author)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetAnnotations :many
+SELECT * FROM annotation ORDER BY id;
+
+-- name: GetAnnotationsOfEntity :many
+SELECT * FROM annotation
+WHERE entity_kind = $1 AND entity_id = $2
+ORDER BY id;
+
+-- name: DeleteAnnotation :execrows
+DELETE FROM annotation WHERE id = $1;
+
+-- name: InsertEpochSLATrigger :exec
+INSERT INTO epoch_sla (epoch_id, triggered_at)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: SetEpochSLASharesComplete :exec
+UPDATE epoch_sla SET shares_complete_at = $2
+WHERE epoch_id = $1 AND shares_complete_at IS NULL;
+
+-- name: SetEpochSLAKeyAvailable :exec
+UPDATE epoch_sla SET key_available_at = $2, key_source = $3
+WHERE epoch_id = $1 AND key_available_at IS NULL;
+
+-- name: GetRecentEpochSLAs :many
+SELECT * FROM epoch_sla
+WHERE triggered_at <= $1
+ORDER BY triggered_at DESC
+LIMIT $2;
+
+-- name: GetEpochSLAsInRange :many
+SELECT * FROM epoch_sla
+WHERE triggered_at >= sqlc.arg(from_time) AND triggered_at < sqlc.arg(to_time)
+ORDER BY triggered_at;
+
+-- name: DeleteEpochSLAsBefore :execrows
+DELETE FROM epoch_sla WHERE triggered_at < $1;
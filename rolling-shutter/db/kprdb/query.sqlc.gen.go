@@ -13,6 +13,82 @@ import (
 	"github.com/jackc/pgconn"
 )
 
+const allocateSigningNonce = `-- name: AllocateSigningNonce :one
+INSERT INTO signing_nonce (domain, signer, nonce, message_hash)
+SELECT $1::bytea, $2::text, COALESCE(max(nonce), 0) + 1, $3::bytea
+FROM signing_nonce
+WHERE domain = $1 AND signer = $2
+RETURNING nonce
+`
+
+type AllocateSigningNonceParams struct {
+	Domain      []byte
+	Signer      string
+	MessageHash []byte
+}
+
+func (q *Queries) AllocateSigningNonce(ctx context.Context, arg AllocateSigningNonceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, allocateSigningNonce, arg.Domain, arg.Signer, arg.MessageHash)
+	var nonce int64
+	err := row.Scan(&nonce)
+	return nonce, err
+}
+
+const confirmEonPublicKey = `-- name: ConfirmEonPublicKey :exec
+UPDATE eon_public_key_candidate
+SET confirmed=TRUE
+WHERE hash=$1
+`
+
+func (q *Queries) ConfirmEonPublicKey(ctx context.Context, hash []byte) error {
+	_, err := q.db.Exec(ctx, confirmEonPublicKey, hash)
+	return err
+}
+
+const consumeSigningNonce = `-- name: ConsumeSigningNonce :one
+INSERT INTO signing_nonce (domain, signer, nonce, message_hash)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (domain, signer, nonce) DO UPDATE SET domain = EXCLUDED.domain
+RETURNING message_hash
+`
+
+type ConsumeSigningNonceParams struct {
+	Domain      []byte
+	Signer      string
+	Nonce       int64
+	MessageHash []byte
+}
+
+func (q *Queries) ConsumeSigningNonce(ctx context.Context, arg ConsumeSigningNonceParams) ([]byte, error) {
+	row := q.db.QueryRow(ctx, consumeSigningNonce,
+		arg.Domain,
+		arg.Signer,
+		arg.Nonce,
+		arg.MessageHash,
+	)
+	var message_hash []byte
+	err := row.Scan(&message_hash)
+	return message_hash, err
+}
+
+const copyEncryptionKey = `-- name: CopyEncryptionKey :exec
+INSERT INTO tendermint_encryption_key (address, encryption_public_key)
+SELECT $1::text, encryption_public_key
+FROM tendermint_encryption_key
+WHERE address = $2::text
+ON CONFLICT DO NOTHING
+`
+
+type CopyEncryptionKeyParams struct {
+	NewAddress string
+	OldAddress string
+}
+
+func (q *Queries) CopyEncryptionKey(ctx context.Context, arg CopyEncryptionKeyParams) error {
+	_, err := q.db.Exec(ctx, copyEncryptionKey, arg.NewAddress, arg.OldAddress)
+	return err
+}
+
 const countBatchConfigs = `-- name: CountBatchConfigs :one
 SELECT count(*) FROM tendermint_batch_config
 `
@@ -59,6 +135,115 @@ func (q *Queries) CountDecryptionKeyShares(ctx context.Context, arg CountDecrypt
 	return count, err
 }
 
+const countEonPublicKeyVotes = `-- name: CountEonPublicKeyVotes :one
+SELECT COUNT(*) from eon_public_key_vote WHERE hash=$1
+`
+
+func (q *Queries) CountEonPublicKeyVotes(ctx context.Context, hash []byte) (int64, error) {
+	row := q.db.QueryRow(ctx, countEonPublicKeyVotes, hash)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPauseVotes = `-- name: CountPauseVotes :one
+SELECT COUNT(*) FROM pause_vote
+WHERE keyper_config_index = $1 AND sequence = $2 AND paused = $3
+`
+
+type CountPauseVotesParams struct {
+	KeyperConfigIndex int64
+	Sequence          int64
+	Paused            bool
+}
+
+func (q *Queries) CountPauseVotes(ctx context.Context, arg CountPauseVotesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countPauseVotes, arg.KeyperConfigIndex, arg.Sequence, arg.Paused)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPendingEpochs = `-- name: CountPendingEpochs :one
+SELECT count(*) FROM (
+    SELECT DISTINCT s.eon, s.epoch_id FROM decryption_key_share s
+    WHERE NOT EXISTS (
+        SELECT 1 FROM decryption_key k WHERE k.eon = s.eon AND k.epoch_id = s.epoch_id
+    )
+) AS pending
+`
+
+func (q *Queries) CountPendingEpochs(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countPendingEpochs)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteAnnotation = `-- name: DeleteAnnotation :execrows
+DELETE FROM annotation WHERE id = $1
+`
+
+func (q *Queries) DeleteAnnotation(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteAnnotation, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteDecryptionKeySharesBeforeEon = `-- name: DeleteDecryptionKeySharesBeforeEon :execrows
+DELETE FROM decryption_key_share
+WHERE eon < $1
+`
+
+func (q *Queries) DeleteDecryptionKeySharesBeforeEon(ctx context.Context, eon int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDecryptionKeySharesBeforeEon, eon)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteEpochSLAsBefore = `-- name: DeleteEpochSLAsBefore :execrows
+DELETE FROM epoch_sla WHERE triggered_at < $1
+`
+
+func (q *Queries) DeleteEpochSLAsBefore(ctx context.Context, triggeredAt time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteEpochSLAsBefore, triggeredAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteFinalizedDecryptionKeyShares = `-- name: DeleteFinalizedDecryptionKeyShares :execrows
+DELETE FROM decryption_key_share s
+USING finalized_epochs f
+WHERE s.eon = f.eon AND s.epoch_id = f.epoch_id AND f.finalized_at < $1
+`
+
+func (q *Queries) DeleteFinalizedDecryptionKeyShares(ctx context.Context, finalizedBefore time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteFinalizedDecryptionKeyShares, finalizedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteFinalizedEpochsBefore = `-- name: DeleteFinalizedEpochsBefore :execrows
+DELETE FROM finalized_epochs
+WHERE finalized_at < $1
+`
+
+func (q *Queries) DeleteFinalizedEpochsBefore(ctx context.Context, finalizedBefore time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteFinalizedEpochsBefore, finalizedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deletePolyEval = `-- name: DeletePolyEval :exec
 
 DELETE FROM poly_evals ev WHERE ev.eon=$1 AND ev.receiver_address=$2
@@ -93,6 +278,18 @@ func (q *Queries) DeletePureDKG(ctx context.Context, eon int64) error {
 	return err
 }
 
+const deleteShareSelfAuditFailures = `-- name: DeleteShareSelfAuditFailures :execrows
+DELETE FROM share_self_audit_failure WHERE eon = $1
+`
+
+func (q *Queries) DeleteShareSelfAuditFailures(ctx context.Context, eon int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteShareSelfAuditFailures, eon)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteShutterMessage = `-- name: DeleteShutterMessage :exec
 DELETE FROM tendermint_outgoing_messages WHERE id=$1
 `
@@ -143,6 +340,51 @@ func (q *Queries) ExistsDecryptionKeyShare(ctx context.Context, arg ExistsDecryp
 	return exists, err
 }
 
+const existsShareSelfAuditFailure = `-- name: ExistsShareSelfAuditFailure :one
+SELECT EXISTS (
+    SELECT 1
+    FROM share_self_audit_failure
+    WHERE eon = $1
+)
+`
+
+func (q *Queries) ExistsShareSelfAuditFailure(ctx context.Context, eon int64) (bool, error) {
+	row := q.db.QueryRow(ctx, existsShareSelfAuditFailure, eon)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const findEonPublicKeyVotes = `-- name: FindEonPublicKeyVotes :many
+SELECT hash, sender, signature, eon, keyper_config_index FROM eon_public_key_vote WHERE hash=$1 ORDER BY sender
+`
+
+func (q *Queries) FindEonPublicKeyVotes(ctx context.Context, hash []byte) ([]EonPublicKeyVote, error) {
+	rows, err := q.db.Query(ctx, findEonPublicKeyVotes, hash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EonPublicKeyVote
+	for rows.Next() {
+		var i EonPublicKeyVote
+		if err := rows.Scan(
+			&i.Hash,
+			&i.Sender,
+			&i.Signature,
+			&i.Eon,
+			&i.KeyperConfigIndex,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAllEons = `-- name: GetAllEons :many
 SELECT eon, height, activation_block_number, keyper_config_index FROM eons ORDER BY eon
 `
@@ -216,6 +458,77 @@ func (q *Queries) GetAndDeleteEonPublicKeys(ctx context.Context) ([]GetAndDelete
 	return items, nil
 }
 
+const getAnnotations = `-- name: GetAnnotations :many
+SELECT id, entity_kind, entity_id, label, note, author, created_at FROM annotation ORDER BY id
+`
+
+func (q *Queries) GetAnnotations(ctx context.Context) ([]Annotation, error) {
+	rows, err := q.db.Query(ctx, getAnnotations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Annotation
+	for rows.Next() {
+		var i Annotation
+		if err := rows.Scan(
+			&i.ID,
+			&i.EntityKind,
+			&i.EntityID,
+			&i.Label,
+			&i.Note,
+			&i.Author,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAnnotationsOfEntity = `-- name: GetAnnotationsOfEntity :many
+SELECT id, entity_kind, entity_id, label, note, author, created_at FROM annotation
+WHERE entity_kind = $1 AND entity_id = $2
+ORDER BY id
+`
+
+type GetAnnotationsOfEntityParams struct {
+	EntityKind string
+	EntityID   string
+}
+
+func (q *Queries) GetAnnotationsOfEntity(ctx context.Context, arg GetAnnotationsOfEntityParams) ([]Annotation, error) {
+	rows, err := q.db.Query(ctx, getAnnotationsOfEntity, arg.EntityKind, arg.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Annotation
+	for rows.Next() {
+		var i Annotation
+		if err := rows.Scan(
+			&i.ID,
+			&i.EntityKind,
+			&i.EntityID,
+			&i.Label,
+			&i.Note,
+			&i.Author,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getBatchConfig = `-- name: GetBatchConfig :one
 SELECT keyper_config_index, height, keypers, threshold, started, activation_block_number
 FROM tendermint_batch_config
@@ -269,6 +582,42 @@ func (q *Queries) GetBatchConfigs(ctx context.Context) ([]TendermintBatchConfig,
 	return items, nil
 }
 
+const getConfirmedEonPublicKey = `-- name: GetConfirmedEonPublicKey :one
+SELECT hash, eon_public_key, activation_block_number, keyper_config_index, eon, confirmed FROM eon_public_key_candidate
+WHERE confirmed AND eon = $1
+LIMIT 1
+`
+
+func (q *Queries) GetConfirmedEonPublicKey(ctx context.Context, eon int64) (EonPublicKeyCandidate, error) {
+	row := q.db.QueryRow(ctx, getConfirmedEonPublicKey, eon)
+	var i EonPublicKeyCandidate
+	err := row.Scan(
+		&i.Hash,
+		&i.EonPublicKey,
+		&i.ActivationBlockNumber,
+		&i.KeyperConfigIndex,
+		&i.Eon,
+		&i.Confirmed,
+	)
+	return i, err
+}
+
+const getDKGParameters = `-- name: GetDKGParameters :one
+SELECT keyper_config_index, phase_length, max_missing_participants, block_number FROM dkg_parameters WHERE keyper_config_index = $1
+`
+
+func (q *Queries) GetDKGParameters(ctx context.Context, keyperConfigIndex int64) (DkgParameter, error) {
+	row := q.db.QueryRow(ctx, getDKGParameters, keyperConfigIndex)
+	var i DkgParameter
+	err := row.Scan(
+		&i.KeyperConfigIndex,
+		&i.PhaseLength,
+		&i.MaxMissingParticipants,
+		&i.BlockNumber,
+	)
+	return i, err
+}
+
 const getDKGResult = `-- name: GetDKGResult :one
 SELECT eon, success, error, pure_result FROM dkg_result
 WHERE eon = $1
@@ -305,6 +654,39 @@ func (q *Queries) GetDKGResultForBlockNumber(ctx context.Context, blockNumber in
 	return i, err
 }
 
+const getDKGTranscript = `-- name: GetDKGTranscript :many
+SELECT id, eon, height, phase, sender, event FROM dkg_transcript
+WHERE eon = $1
+ORDER BY id
+`
+
+func (q *Queries) GetDKGTranscript(ctx context.Context, eon int64) ([]DkgTranscript, error) {
+	rows, err := q.db.Query(ctx, getDKGTranscript, eon)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DkgTranscript
+	for rows.Next() {
+		var i DkgTranscript
+		if err := rows.Scan(
+			&i.ID,
+			&i.Eon,
+			&i.Height,
+			&i.Phase,
+			&i.Sender,
+			&i.Event,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getDecryptionKey = `-- name: GetDecryptionKey :one
 SELECT eon, epoch_id, decryption_key FROM decryption_key
 WHERE eon = $1 AND epoch_id = $2
@@ -333,9 +715,16 @@ type GetDecryptionKeyShareParams struct {
 	KeyperIndex int64
 }
 
-func (q *Queries) GetDecryptionKeyShare(ctx context.Context, arg GetDecryptionKeyShareParams) (DecryptionKeyShare, error) {
+type GetDecryptionKeyShareRow struct {
+	Eon                int64
+	EpochID            []byte
+	KeyperIndex        int64
+	DecryptionKeyShare []byte
+}
+
+func (q *Queries) GetDecryptionKeyShare(ctx context.Context, arg GetDecryptionKeyShareParams) (GetDecryptionKeyShareRow, error) {
 	row := q.db.QueryRow(ctx, getDecryptionKeyShare, arg.Eon, arg.EpochID, arg.KeyperIndex)
-	var i DecryptionKeyShare
+	var i GetDecryptionKeyShareRow
 	err := row.Scan(
 		&i.Eon,
 		&i.EpochID,
@@ -345,20 +734,47 @@ func (q *Queries) GetDecryptionKeyShare(ctx context.Context, arg GetDecryptionKe
 	return i, err
 }
 
-const getEncryptionKeys = `-- name: GetEncryptionKeys :many
-SELECT address, encryption_public_key FROM tendermint_encryption_key
+const getDecryptionKeySharesInRange = `-- name: GetDecryptionKeySharesInRange :many
+SELECT eon, epoch_id, keyper_index, decryption_key_share FROM decryption_key_share
+WHERE eon = $1 AND epoch_id BETWEEN $2 AND $3
+ORDER BY epoch_id, keyper_index
+LIMIT $4
 `
 
-func (q *Queries) GetEncryptionKeys(ctx context.Context) ([]TendermintEncryptionKey, error) {
-	rows, err := q.db.Query(ctx, getEncryptionKeys)
+type GetDecryptionKeySharesInRangeParams struct {
+	Eon         int64
+	FromEpochID []byte
+	ToEpochID   []byte
+	MaxShares   int32
+}
+
+type GetDecryptionKeySharesInRangeRow struct {
+	Eon                int64
+	EpochID            []byte
+	KeyperIndex        int64
+	DecryptionKeyShare []byte
+}
+
+func (q *Queries) GetDecryptionKeySharesInRange(ctx context.Context, arg GetDecryptionKeySharesInRangeParams) ([]GetDecryptionKeySharesInRangeRow, error) {
+	rows, err := q.db.Query(ctx, getDecryptionKeySharesInRange,
+		arg.Eon,
+		arg.FromEpochID,
+		arg.ToEpochID,
+		arg.MaxShares,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TendermintEncryptionKey
+	var items []GetDecryptionKeySharesInRangeRow
 	for rows.Next() {
-		var i TendermintEncryptionKey
-		if err := rows.Scan(&i.Address, &i.EncryptionPublicKey); err != nil {
+		var i GetDecryptionKeySharesInRangeRow
+		if err := rows.Scan(
+			&i.Eon,
+			&i.EpochID,
+			&i.KeyperIndex,
+			&i.DecryptionKeyShare,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -369,29 +785,167 @@ func (q *Queries) GetEncryptionKeys(ctx context.Context) ([]TendermintEncryption
 	return items, nil
 }
 
-const getEon = `-- name: GetEon :one
-SELECT eon, height, activation_block_number, keyper_config_index FROM eons WHERE eon=$1
+const getDecryptionKeySharesOfEon = `-- name: GetDecryptionKeySharesOfEon :many
+SELECT eon, epoch_id, keyper_index, decryption_key_share FROM decryption_key_share
+WHERE eon = $1
+ORDER BY epoch_id, keyper_index
 `
 
-func (q *Queries) GetEon(ctx context.Context, eon int64) (Eon, error) {
-	row := q.db.QueryRow(ctx, getEon, eon)
-	var i Eon
-	err := row.Scan(
-		&i.Eon,
-		&i.Height,
-		&i.ActivationBlockNumber,
-		&i.KeyperConfigIndex,
-	)
-	return i, err
+type GetDecryptionKeySharesOfEonRow struct {
+	Eon                int64
+	EpochID            []byte
+	KeyperIndex        int64
+	DecryptionKeyShare []byte
 }
 
-const getEonForBlockNumber = `-- name: GetEonForBlockNumber :one
-SELECT eon, height, activation_block_number, keyper_config_index FROM eons
-WHERE activation_block_number <= $1
-ORDER BY activation_block_number DESC, height DESC
-LIMIT 1
-`
-
+func (q *Queries) GetDecryptionKeySharesOfEon(ctx context.Context, eon int64) ([]GetDecryptionKeySharesOfEonRow, error) {
+	rows, err := q.db.Query(ctx, getDecryptionKeySharesOfEon, eon)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDecryptionKeySharesOfEonRow
+	for rows.Next() {
+		var i GetDecryptionKeySharesOfEonRow
+		if err := rows.Scan(
+			&i.Eon,
+			&i.EpochID,
+			&i.KeyperIndex,
+			&i.DecryptionKeyShare,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDecryptionKeysInRange = `-- name: GetDecryptionKeysInRange :many
+SELECT eon, epoch_id, decryption_key FROM decryption_key
+WHERE eon = $1 AND epoch_id BETWEEN $2 AND $3
+ORDER BY epoch_id
+LIMIT $4
+`
+
+type GetDecryptionKeysInRangeParams struct {
+	Eon         int64
+	FromEpochID []byte
+	ToEpochID   []byte
+	MaxKeys     int32
+}
+
+func (q *Queries) GetDecryptionKeysInRange(ctx context.Context, arg GetDecryptionKeysInRangeParams) ([]DecryptionKey, error) {
+	rows, err := q.db.Query(ctx, getDecryptionKeysInRange,
+		arg.Eon,
+		arg.FromEpochID,
+		arg.ToEpochID,
+		arg.MaxKeys,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DecryptionKey
+	for rows.Next() {
+		var i DecryptionKey
+		if err := rows.Scan(&i.Eon, &i.EpochID, &i.DecryptionKey); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDecryptionKeysOfEon = `-- name: GetDecryptionKeysOfEon :many
+SELECT eon, epoch_id, decryption_key FROM decryption_key
+WHERE eon = $1
+ORDER BY epoch_id
+`
+
+func (q *Queries) GetDecryptionKeysOfEon(ctx context.Context, eon int64) ([]DecryptionKey, error) {
+	rows, err := q.db.Query(ctx, getDecryptionKeysOfEon, eon)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DecryptionKey
+	for rows.Next() {
+		var i DecryptionKey
+		if err := rows.Scan(&i.Eon, &i.EpochID, &i.DecryptionKey); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEncryptionKeys = `-- name: GetEncryptionKeys :many
+SELECT address, encryption_public_key FROM tendermint_encryption_key
+`
+
+func (q *Queries) GetEncryptionKeys(ctx context.Context) ([]TendermintEncryptionKey, error) {
+	rows, err := q.db.Query(ctx, getEncryptionKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TendermintEncryptionKey
+	for rows.Next() {
+		var i TendermintEncryptionKey
+		if err := rows.Scan(&i.Address, &i.EncryptionPublicKey); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEon = `-- name: GetEon :one
+SELECT eon, height, activation_block_number, keyper_config_index FROM eons WHERE eon=$1
+`
+
+func (q *Queries) GetEon(ctx context.Context, eon int64) (Eon, error) {
+	row := q.db.QueryRow(ctx, getEon, eon)
+	var i Eon
+	err := row.Scan(
+		&i.Eon,
+		&i.Height,
+		&i.ActivationBlockNumber,
+		&i.KeyperConfigIndex,
+	)
+	return i, err
+}
+
+const getEonDKGParameters = `-- name: GetEonDKGParameters :one
+SELECT eon, phase_length, max_missing_participants FROM eon_dkg_parameters WHERE eon = $1
+`
+
+func (q *Queries) GetEonDKGParameters(ctx context.Context, eon int64) (EonDkgParameter, error) {
+	row := q.db.QueryRow(ctx, getEonDKGParameters, eon)
+	var i EonDkgParameter
+	err := row.Scan(&i.Eon, &i.PhaseLength, &i.MaxMissingParticipants)
+	return i, err
+}
+
+const getEonForBlockNumber = `-- name: GetEonForBlockNumber :one
+SELECT eon, height, activation_block_number, keyper_config_index FROM eons
+WHERE activation_block_number <= $1
+ORDER BY activation_block_number DESC, height DESC
+LIMIT 1
+`
+
 func (q *Queries) GetEonForBlockNumber(ctx context.Context, blockNumber int64) (Eon, error) {
 	row := q.db.QueryRow(ctx, getEonForBlockNumber, blockNumber)
 	var i Eon
@@ -404,6 +958,211 @@ func (q *Queries) GetEonForBlockNumber(ctx context.Context, blockNumber int64) (
 	return i, err
 }
 
+const getEonPublicKeyCandidates = `-- name: GetEonPublicKeyCandidates :many
+SELECT hash, eon_public_key, activation_block_number, keyper_config_index, eon, confirmed FROM eon_public_key_candidate
+WHERE eon = $1
+ORDER BY confirmed DESC, hash
+`
+
+func (q *Queries) GetEonPublicKeyCandidates(ctx context.Context, eon int64) ([]EonPublicKeyCandidate, error) {
+	rows, err := q.db.Query(ctx, getEonPublicKeyCandidates, eon)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EonPublicKeyCandidate
+	for rows.Next() {
+		var i EonPublicKeyCandidate
+		if err := rows.Scan(
+			&i.Hash,
+			&i.EonPublicKey,
+			&i.ActivationBlockNumber,
+			&i.KeyperConfigIndex,
+			&i.Eon,
+			&i.Confirmed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEpochPreAnnouncement = `-- name: GetEpochPreAnnouncement :one
+SELECT epoch_id, block_number, expected_trigger_time, received_at FROM epoch_pre_announcement
+WHERE epoch_id = $1
+`
+
+func (q *Queries) GetEpochPreAnnouncement(ctx context.Context, epochID []byte) (EpochPreAnnouncement, error) {
+	row := q.db.QueryRow(ctx, getEpochPreAnnouncement, epochID)
+	var i EpochPreAnnouncement
+	err := row.Scan(
+		&i.EpochID,
+		&i.BlockNumber,
+		&i.ExpectedTriggerTime,
+		&i.ReceivedAt,
+	)
+	return i, err
+}
+
+const getEpochSLAsInRange = `-- name: GetEpochSLAsInRange :many
+SELECT epoch_id, triggered_at, shares_complete_at, key_available_at, key_source FROM epoch_sla
+WHERE triggered_at >= $1 AND triggered_at < $2
+ORDER BY triggered_at
+`
+
+type GetEpochSLAsInRangeParams struct {
+	FromTime time.Time
+	ToTime   time.Time
+}
+
+func (q *Queries) GetEpochSLAsInRange(ctx context.Context, arg GetEpochSLAsInRangeParams) ([]EpochSla, error) {
+	rows, err := q.db.Query(ctx, getEpochSLAsInRange, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EpochSla
+	for rows.Next() {
+		var i EpochSla
+		if err := rows.Scan(
+			&i.EpochID,
+			&i.TriggeredAt,
+			&i.SharesCompleteAt,
+			&i.KeyAvailableAt,
+			&i.KeySource,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFinalizedEpoch = `-- name: GetFinalizedEpoch :one
+SELECT eon, epoch_id, finalized_at FROM finalized_epochs
+WHERE eon = $1 AND epoch_id = $2
+`
+
+type GetFinalizedEpochParams struct {
+	Eon     int64
+	EpochID []byte
+}
+
+func (q *Queries) GetFinalizedEpoch(ctx context.Context, arg GetFinalizedEpochParams) (FinalizedEpoch, error) {
+	row := q.db.QueryRow(ctx, getFinalizedEpoch, arg.Eon, arg.EpochID)
+	var i FinalizedEpoch
+	err := row.Scan(&i.Eon, &i.EpochID, &i.FinalizedAt)
+	return i, err
+}
+
+const getFinalizedEpochsBefore = `-- name: GetFinalizedEpochsBefore :many
+SELECT f.eon, f.epoch_id, f.finalized_at, k.decryption_key FROM finalized_epochs f
+LEFT JOIN decryption_key k ON k.eon = f.eon AND k.epoch_id = f.epoch_id
+WHERE (f.finalized_at, f.eon, f.epoch_id) < ($1::timestamptz, $2::bigint, $3::bytea)
+ORDER BY f.finalized_at DESC, f.eon DESC, f.epoch_id DESC
+LIMIT $4
+`
+
+type GetFinalizedEpochsBeforeParams struct {
+	FinalizedAt time.Time
+	Eon         int64
+	EpochID     []byte
+	MaxEpochs   int32
+}
+
+type GetFinalizedEpochsBeforeRow struct {
+	Eon           int64
+	EpochID       []byte
+	FinalizedAt   time.Time
+	DecryptionKey []byte
+}
+
+func (q *Queries) GetFinalizedEpochsBefore(ctx context.Context, arg GetFinalizedEpochsBeforeParams) ([]GetFinalizedEpochsBeforeRow, error) {
+	rows, err := q.db.Query(ctx, getFinalizedEpochsBefore,
+		arg.FinalizedAt,
+		arg.Eon,
+		arg.EpochID,
+		arg.MaxEpochs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFinalizedEpochsBeforeRow
+	for rows.Next() {
+		var i GetFinalizedEpochsBeforeRow
+		if err := rows.Scan(
+			&i.Eon,
+			&i.EpochID,
+			&i.FinalizedAt,
+			&i.DecryptionKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getKeyGenerationPause = `-- name: GetKeyGenerationPause :one
+SELECT enforce_one_row, paused, sequence, keyper_config_index, reason, updated_at FROM key_generation_pause LIMIT 1
+`
+
+func (q *Queries) GetKeyGenerationPause(ctx context.Context) (KeyGenerationPause, error) {
+	row := q.db.QueryRow(ctx, getKeyGenerationPause)
+	var i KeyGenerationPause
+	err := row.Scan(
+		&i.EnforceOneRow,
+		&i.Paused,
+		&i.Sequence,
+		&i.KeyperConfigIndex,
+		&i.Reason,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getKeyperBonds = `-- name: GetKeyperBonds :many
+SELECT address, bonded, unbonding, unbonding_block_number, block_number, log_index FROM keyper_bond ORDER BY address
+`
+
+func (q *Queries) GetKeyperBonds(ctx context.Context) ([]KeyperBond, error) {
+	rows, err := q.db.Query(ctx, getKeyperBonds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []KeyperBond
+	for rows.Next() {
+		var i KeyperBond
+		if err := rows.Scan(
+			&i.Address,
+			&i.Bonded,
+			&i.Unbonding,
+			&i.UnbondingBlockNumber,
+			&i.BlockNumber,
+			&i.LogIndex,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getLastBatchConfigSent = `-- name: GetLastBatchConfigSent :one
 SELECT keyper_config_index FROM last_batch_config_sent LIMIT 1
 `
@@ -461,6 +1220,68 @@ func (q *Queries) GetLatestBatchConfig(ctx context.Context) (TendermintBatchConf
 	return i, err
 }
 
+const getLatestFinalizedEpoch = `-- name: GetLatestFinalizedEpoch :one
+SELECT eon, epoch_id, finalized_at FROM finalized_epochs
+ORDER BY finalized_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestFinalizedEpoch(ctx context.Context) (FinalizedEpoch, error) {
+	row := q.db.QueryRow(ctx, getLatestFinalizedEpoch)
+	var i FinalizedEpoch
+	err := row.Scan(&i.Eon, &i.EpochID, &i.FinalizedAt)
+	return i, err
+}
+
+const getMempoolObservationsByPayloadHash = `-- name: GetMempoolObservationsByPayloadHash :many
+SELECT tx_hash, sender, inbox, nonce, payload_hash, max_fee_per_gas, first_seen_block_number, first_seen_at, included_block_number, finding, finding_details FROM mempool_observation
+WHERE payload_hash = $1
+ORDER BY first_seen_at, tx_hash
+`
+
+func (q *Queries) GetMempoolObservationsByPayloadHash(ctx context.Context, payloadHash []byte) ([]MempoolObservation, error) {
+	rows, err := q.db.Query(ctx, getMempoolObservationsByPayloadHash, payloadHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MempoolObservation
+	for rows.Next() {
+		var i MempoolObservation
+		if err := rows.Scan(
+			&i.TxHash,
+			&i.Sender,
+			&i.Inbox,
+			&i.Nonce,
+			&i.PayloadHash,
+			&i.MaxFeePerGas,
+			&i.FirstSeenBlockNumber,
+			&i.FirstSeenAt,
+			&i.IncludedBlockNumber,
+			&i.Finding,
+			&i.FindingDetails,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMinimumBond = `-- name: GetMinimumBond :one
+SELECT minimum_bond FROM keyper_bond_minimum LIMIT 1
+`
+
+func (q *Queries) GetMinimumBond(ctx context.Context) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getMinimumBond)
+	var minimum_bond []byte
+	err := row.Scan(&minimum_bond)
+	return minimum_bond, err
+}
+
 const getNextShutterMessage = `-- name: GetNextShutterMessage :one
 SELECT id, description, msg from tendermint_outgoing_messages
 ORDER BY id
@@ -474,6 +1295,354 @@ func (q *Queries) GetNextShutterMessage(ctx context.Context) (TendermintOutgoing
 	return i, err
 }
 
+const getNodeVersionRequirement = `-- name: GetNodeVersionRequirement :one
+SELECT minimum_version, minimum_protocol_version FROM node_version_requirement LIMIT 1
+`
+
+type GetNodeVersionRequirementRow struct {
+	MinimumVersion         string
+	MinimumProtocolVersion int64
+}
+
+func (q *Queries) GetNodeVersionRequirement(ctx context.Context) (GetNodeVersionRequirementRow, error) {
+	row := q.db.QueryRow(ctx, getNodeVersionRequirement)
+	var i GetNodeVersionRequirementRow
+	err := row.Scan(&i.MinimumVersion, &i.MinimumProtocolVersion)
+	return i, err
+}
+
+const getPendingMempoolObservations = `-- name: GetPendingMempoolObservations :many
+SELECT tx_hash, sender, inbox, nonce, payload_hash, max_fee_per_gas, first_seen_block_number, first_seen_at, included_block_number, finding, finding_details FROM mempool_observation
+WHERE included_block_number = 0 AND finding = ''
+ORDER BY first_seen_block_number, tx_hash
+`
+
+func (q *Queries) GetPendingMempoolObservations(ctx context.Context) ([]MempoolObservation, error) {
+	rows, err := q.db.Query(ctx, getPendingMempoolObservations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MempoolObservation
+	for rows.Next() {
+		var i MempoolObservation
+		if err := rows.Scan(
+			&i.TxHash,
+			&i.Sender,
+			&i.Inbox,
+			&i.Nonce,
+			&i.PayloadHash,
+			&i.MaxFeePerGas,
+			&i.FirstSeenBlockNumber,
+			&i.FirstSeenAt,
+			&i.IncludedBlockNumber,
+			&i.Finding,
+			&i.FindingDetails,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingPauseVotes = `-- name: GetPendingPauseVotes :many
+SELECT keyper_config_index, sequence, sender, paused, reason, signature, received_at FROM pause_vote
+WHERE sequence > $1
+ORDER BY sequence, sender
+`
+
+func (q *Queries) GetPendingPauseVotes(ctx context.Context, sequence int64) ([]PauseVote, error) {
+	rows, err := q.db.Query(ctx, getPendingPauseVotes, sequence)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PauseVote
+	for rows.Next() {
+		var i PauseVote
+		if err := rows.Scan(
+			&i.KeyperConfigIndex,
+			&i.Sequence,
+			&i.Sender,
+			&i.Paused,
+			&i.Reason,
+			&i.Signature,
+			&i.ReceivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getProcessLease = `-- name: GetProcessLease :one
+SELECT id, pid, hostname, acquired_at, renewed_at, token FROM process_lease
+`
+
+func (q *Queries) GetProcessLease(ctx context.Context) (ProcessLease, error) {
+	row := q.db.QueryRow(ctx, getProcessLease)
+	var i ProcessLease
+	err := row.Scan(
+		&i.ID,
+		&i.Pid,
+		&i.Hostname,
+		&i.AcquiredAt,
+		&i.RenewedAt,
+		&i.Token,
+	)
+	return i, err
+}
+
+const getProcessLeaseToken = `-- name: GetProcessLeaseToken :one
+SELECT token FROM process_lease FOR SHARE
+`
+
+func (q *Queries) GetProcessLeaseToken(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, getProcessLeaseToken)
+	var token int64
+	err := row.Scan(&token)
+	return token, err
+}
+
+const getRecentEpochParticipation = `-- name: GetRecentEpochParticipation :many
+SELECT eon, epoch_id, keyper_indices, recorded_at FROM epoch_participation
+WHERE eon = $1
+ORDER BY recorded_at DESC
+LIMIT $2
+`
+
+type GetRecentEpochParticipationParams struct {
+	Eon   int64
+	Limit int32
+}
+
+func (q *Queries) GetRecentEpochParticipation(ctx context.Context, arg GetRecentEpochParticipationParams) ([]EpochParticipation, error) {
+	rows, err := q.db.Query(ctx, getRecentEpochParticipation, arg.Eon, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EpochParticipation
+	for rows.Next() {
+		var i EpochParticipation
+		if err := rows.Scan(
+			&i.Eon,
+			&i.EpochID,
+			&i.KeyperIndices,
+			&i.RecordedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentEpochSLAs = `-- name: GetRecentEpochSLAs :many
+SELECT epoch_id, triggered_at, shares_complete_at, key_available_at, key_source FROM epoch_sla
+WHERE triggered_at <= $1
+ORDER BY triggered_at DESC
+LIMIT $2
+`
+
+type GetRecentEpochSLAsParams struct {
+	TriggeredAt time.Time
+	Limit       int32
+}
+
+func (q *Queries) GetRecentEpochSLAs(ctx context.Context, arg GetRecentEpochSLAsParams) ([]EpochSla, error) {
+	rows, err := q.db.Query(ctx, getRecentEpochSLAs, arg.TriggeredAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EpochSla
+	for rows.Next() {
+		var i EpochSla
+		if err := rows.Scan(
+			&i.EpochID,
+			&i.TriggeredAt,
+			&i.SharesCompleteAt,
+			&i.KeyAvailableAt,
+			&i.KeySource,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentFinalizedEpochs = `-- name: GetRecentFinalizedEpochs :many
+SELECT f.eon, f.epoch_id, f.finalized_at, k.decryption_key FROM finalized_epochs f
+LEFT JOIN decryption_key k ON k.eon = f.eon AND k.epoch_id = f.epoch_id
+ORDER BY f.finalized_at DESC, f.eon DESC, f.epoch_id DESC
+LIMIT $1
+`
+
+type GetRecentFinalizedEpochsRow struct {
+	Eon           int64
+	EpochID       []byte
+	FinalizedAt   time.Time
+	DecryptionKey []byte
+}
+
+func (q *Queries) GetRecentFinalizedEpochs(ctx context.Context, limit int32) ([]GetRecentFinalizedEpochsRow, error) {
+	rows, err := q.db.Query(ctx, getRecentFinalizedEpochs, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRecentFinalizedEpochsRow
+	for rows.Next() {
+		var i GetRecentFinalizedEpochsRow
+		if err := rows.Scan(
+			&i.Eon,
+			&i.EpochID,
+			&i.FinalizedAt,
+			&i.DecryptionKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSigningNonce = `-- name: GetSigningNonce :one
+SELECT nonce FROM signing_nonce
+WHERE domain = $1 AND signer = $2 AND message_hash = $3
+`
+
+type GetSigningNonceParams struct {
+	Domain      []byte
+	Signer      string
+	MessageHash []byte
+}
+
+func (q *Queries) GetSigningNonce(ctx context.Context, arg GetSigningNonceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getSigningNonce, arg.Domain, arg.Signer, arg.MessageHash)
+	var nonce int64
+	err := row.Scan(&nonce)
+	return nonce, err
+}
+
+const getSuccessfulDKGResults = `-- name: GetSuccessfulDKGResults :many
+SELECT eon, success, error, pure_result FROM dkg_result
+WHERE success
+ORDER BY eon
+`
+
+func (q *Queries) GetSuccessfulDKGResults(ctx context.Context) ([]DkgResult, error) {
+	rows, err := q.db.Query(ctx, getSuccessfulDKGResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DkgResult
+	for rows.Next() {
+		var i DkgResult
+		if err := rows.Scan(
+			&i.Eon,
+			&i.Success,
+			&i.Error,
+			&i.PureResult,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnescrowedDKGResults = `-- name: GetUnescrowedDKGResults :many
+SELECT eon, success, error, pure_result FROM dkg_result r
+WHERE r.success AND NOT EXISTS (
+    SELECT 1 FROM key_escrow e WHERE e.eon = r.eon AND e.fingerprint = $1
+)
+ORDER BY r.eon
+`
+
+func (q *Queries) GetUnescrowedDKGResults(ctx context.Context, fingerprint []byte) ([]DkgResult, error) {
+	rows, err := q.db.Query(ctx, getUnescrowedDKGResults, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DkgResult
+	for rows.Next() {
+		var i DkgResult
+		if err := rows.Scan(
+			&i.Eon,
+			&i.Success,
+			&i.Error,
+			&i.PureResult,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertAnnotation = `-- name: InsertAnnotation :one
+INSERT INTO annotation (entity_kind, entity_id, label, note, author)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, entity_kind, entity_id, label, note, author, created_at
+`
+
+type InsertAnnotationParams struct {
+	EntityKind string
+	EntityID   string
+	Label      string
+	Note       string
+	Author     string
+}
+
+func (q *Queries) InsertAnnotation(ctx context.Context, arg InsertAnnotationParams) (Annotation, error) {
+	row := q.db.QueryRow(ctx, insertAnnotation,
+		arg.EntityKind,
+		arg.EntityID,
+		arg.Label,
+		arg.Note,
+		arg.Author,
+	)
+	var i Annotation
+	err := row.Scan(
+		&i.ID,
+		&i.EntityKind,
+		&i.EntityID,
+		&i.Label,
+		&i.Note,
+		&i.Author,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const insertBatchConfig = `-- name: InsertBatchConfig :exec
 INSERT INTO tendermint_batch_config (keyper_config_index, height, keypers, threshold, started, activation_block_number)
 VALUES ($1, $2, $3, $4, $5, $6)
@@ -522,6 +1691,30 @@ func (q *Queries) InsertDKGResult(ctx context.Context, arg InsertDKGResultParams
 	return err
 }
 
+const insertDKGTranscriptEntry = `-- name: InsertDKGTranscriptEntry :exec
+INSERT INTO dkg_transcript (eon, height, phase, sender, event)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type InsertDKGTranscriptEntryParams struct {
+	Eon    int64
+	Height int64
+	Phase  int32
+	Sender string
+	Event  []byte
+}
+
+func (q *Queries) InsertDKGTranscriptEntry(ctx context.Context, arg InsertDKGTranscriptEntryParams) error {
+	_, err := q.db.Exec(ctx, insertDKGTranscriptEntry,
+		arg.Eon,
+		arg.Height,
+		arg.Phase,
+		arg.Sender,
+		arg.Event,
+	)
+	return err
+}
+
 const insertDecryptionKey = `-- name: InsertDecryptionKey :execresult
 INSERT INTO decryption_key (eon, epoch_id, decryption_key)
 VALUES ($1, $2, $3)
@@ -596,6 +1789,23 @@ func (q *Queries) InsertEon(ctx context.Context, arg InsertEonParams) error {
 	return err
 }
 
+const insertEonDKGParameters = `-- name: InsertEonDKGParameters :exec
+INSERT INTO eon_dkg_parameters (eon, phase_length, max_missing_participants)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING
+`
+
+type InsertEonDKGParametersParams struct {
+	Eon                    int64
+	PhaseLength            int64
+	MaxMissingParticipants int64
+}
+
+func (q *Queries) InsertEonDKGParameters(ctx context.Context, arg InsertEonDKGParametersParams) error {
+	_, err := q.db.Exec(ctx, insertEonDKGParameters, arg.Eon, arg.PhaseLength, arg.MaxMissingParticipants)
+	return err
+}
+
 const insertEonPublicKey = `-- name: InsertEonPublicKey :exec
 INSERT INTO outgoing_eon_keys (eon_public_key, eon)
 VALUES ($1, $2)
@@ -611,6 +1821,203 @@ func (q *Queries) InsertEonPublicKey(ctx context.Context, arg InsertEonPublicKey
 	return err
 }
 
+const insertEonPublicKeyCandidate = `-- name: InsertEonPublicKeyCandidate :exec
+INSERT INTO eon_public_key_candidate
+       (hash, eon_public_key, activation_block_number, keyper_config_index, eon)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT DO NOTHING
+`
+
+type InsertEonPublicKeyCandidateParams struct {
+	Hash                  []byte
+	EonPublicKey          []byte
+	ActivationBlockNumber int64
+	KeyperConfigIndex     int64
+	Eon                   int64
+}
+
+func (q *Queries) InsertEonPublicKeyCandidate(ctx context.Context, arg InsertEonPublicKeyCandidateParams) error {
+	_, err := q.db.Exec(ctx, insertEonPublicKeyCandidate,
+		arg.Hash,
+		arg.EonPublicKey,
+		arg.ActivationBlockNumber,
+		arg.KeyperConfigIndex,
+		arg.Eon,
+	)
+	return err
+}
+
+const insertEonPublicKeyVote = `-- name: InsertEonPublicKeyVote :one
+INSERT INTO eon_public_key_vote
+       (hash, sender, signature, eon, keyper_config_index)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (sender, eon) DO UPDATE SET sender = EXCLUDED.sender
+RETURNING hash
+`
+
+type InsertEonPublicKeyVoteParams struct {
+	Hash              []byte
+	Sender            string
+	Signature         []byte
+	Eon               int64
+	KeyperConfigIndex int64
+}
+
+func (q *Queries) InsertEonPublicKeyVote(ctx context.Context, arg InsertEonPublicKeyVoteParams) ([]byte, error) {
+	row := q.db.QueryRow(ctx, insertEonPublicKeyVote,
+		arg.Hash,
+		arg.Sender,
+		arg.Signature,
+		arg.Eon,
+		arg.KeyperConfigIndex,
+	)
+	var hash []byte
+	err := row.Scan(&hash)
+	return hash, err
+}
+
+const insertEpochParticipation = `-- name: InsertEpochParticipation :exec
+INSERT INTO epoch_participation (eon, epoch_id, keyper_indices)
+SELECT $1::bigint, $2::bytea, COALESCE(array_agg(keyper_index ORDER BY keyper_index), '{}')
+FROM decryption_key_share
+WHERE eon = $1 AND epoch_id = $2
+ON CONFLICT DO NOTHING
+`
+
+type InsertEpochParticipationParams struct {
+	Eon     int64
+	EpochID []byte
+}
+
+func (q *Queries) InsertEpochParticipation(ctx context.Context, arg InsertEpochParticipationParams) error {
+	_, err := q.db.Exec(ctx, insertEpochParticipation, arg.Eon, arg.EpochID)
+	return err
+}
+
+const insertEpochPreAnnouncement = `-- name: InsertEpochPreAnnouncement :exec
+INSERT INTO epoch_pre_announcement (epoch_id, block_number, expected_trigger_time)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING
+`
+
+type InsertEpochPreAnnouncementParams struct {
+	EpochID             []byte
+	BlockNumber         int64
+	ExpectedTriggerTime time.Time
+}
+
+func (q *Queries) InsertEpochPreAnnouncement(ctx context.Context, arg InsertEpochPreAnnouncementParams) error {
+	_, err := q.db.Exec(ctx, insertEpochPreAnnouncement, arg.EpochID, arg.BlockNumber, arg.ExpectedTriggerTime)
+	return err
+}
+
+const insertEpochSLATrigger = `-- name: InsertEpochSLATrigger :exec
+INSERT INTO epoch_sla (epoch_id, triggered_at)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type InsertEpochSLATriggerParams struct {
+	EpochID     []byte
+	TriggeredAt time.Time
+}
+
+func (q *Queries) InsertEpochSLATrigger(ctx context.Context, arg InsertEpochSLATriggerParams) error {
+	_, err := q.db.Exec(ctx, insertEpochSLATrigger, arg.EpochID, arg.TriggeredAt)
+	return err
+}
+
+const insertFinalizedEpoch = `-- name: InsertFinalizedEpoch :exec
+INSERT INTO finalized_epochs (eon, epoch_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type InsertFinalizedEpochParams struct {
+	Eon     int64
+	EpochID []byte
+}
+
+func (q *Queries) InsertFinalizedEpoch(ctx context.Context, arg InsertFinalizedEpochParams) error {
+	_, err := q.db.Exec(ctx, insertFinalizedEpoch, arg.Eon, arg.EpochID)
+	return err
+}
+
+const insertKeyEscrow = `-- name: InsertKeyEscrow :exec
+INSERT INTO key_escrow (eon, fingerprint) VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type InsertKeyEscrowParams struct {
+	Eon         int64
+	Fingerprint []byte
+}
+
+func (q *Queries) InsertKeyEscrow(ctx context.Context, arg InsertKeyEscrowParams) error {
+	_, err := q.db.Exec(ctx, insertKeyEscrow, arg.Eon, arg.Fingerprint)
+	return err
+}
+
+const insertMempoolObservation = `-- name: InsertMempoolObservation :exec
+INSERT INTO mempool_observation (
+    tx_hash, sender, inbox, nonce, payload_hash, max_fee_per_gas, first_seen_block_number
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT DO NOTHING
+`
+
+type InsertMempoolObservationParams struct {
+	TxHash               []byte
+	Sender               string
+	Inbox                string
+	Nonce                int64
+	PayloadHash          []byte
+	MaxFeePerGas         []byte
+	FirstSeenBlockNumber int64
+}
+
+func (q *Queries) InsertMempoolObservation(ctx context.Context, arg InsertMempoolObservationParams) error {
+	_, err := q.db.Exec(ctx, insertMempoolObservation,
+		arg.TxHash,
+		arg.Sender,
+		arg.Inbox,
+		arg.Nonce,
+		arg.PayloadHash,
+		arg.MaxFeePerGas,
+		arg.FirstSeenBlockNumber,
+	)
+	return err
+}
+
+const insertPauseVote = `-- name: InsertPauseVote :one
+INSERT INTO pause_vote (keyper_config_index, sequence, sender, paused, reason, signature)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (keyper_config_index, sequence, sender) DO UPDATE SET sender = EXCLUDED.sender
+RETURNING paused
+`
+
+type InsertPauseVoteParams struct {
+	KeyperConfigIndex int64
+	Sequence          int64
+	Sender            string
+	Paused            bool
+	Reason            string
+	Signature         []byte
+}
+
+func (q *Queries) InsertPauseVote(ctx context.Context, arg InsertPauseVoteParams) (bool, error) {
+	row := q.db.QueryRow(ctx, insertPauseVote,
+		arg.KeyperConfigIndex,
+		arg.Sequence,
+		arg.Sender,
+		arg.Paused,
+		arg.Reason,
+		arg.Signature,
+	)
+	var paused bool
+	err := row.Scan(&paused)
+	return paused, err
+}
+
 const insertPolyEval = `-- name: InsertPolyEval :exec
 INSERT INTO poly_evals (eon, receiver_address, eval)
 VALUES ($1, $2, $3)
@@ -642,6 +2049,29 @@ func (q *Queries) InsertPureDKG(ctx context.Context, arg InsertPureDKGParams) er
 	return err
 }
 
+const insertShareSelfAuditFailure = `-- name: InsertShareSelfAuditFailure :exec
+INSERT INTO share_self_audit_failure (eon, epoch_id, keyper_index, share)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING
+`
+
+type InsertShareSelfAuditFailureParams struct {
+	Eon         int64
+	EpochID     []byte
+	KeyperIndex int64
+	Share       []byte
+}
+
+func (q *Queries) InsertShareSelfAuditFailure(ctx context.Context, arg InsertShareSelfAuditFailureParams) error {
+	_, err := q.db.Exec(ctx, insertShareSelfAuditFailure,
+		arg.Eon,
+		arg.EpochID,
+		arg.KeyperIndex,
+		arg.Share,
+	)
+	return err
+}
+
 const polyEvalsWithEncryptionKeys = `-- name: PolyEvalsWithEncryptionKeys :many
 SELECT ev.eon, ev.receiver_address, ev.eval,
        k.encryption_public_key,
@@ -688,6 +2118,103 @@ func (q *Queries) PolyEvalsWithEncryptionKeys(ctx context.Context) ([]PolyEvalsW
 	return items, nil
 }
 
+const pruneEpochParticipation = `-- name: PruneEpochParticipation :execrows
+DELETE FROM epoch_participation
+WHERE recorded_at < (
+    SELECT min(recorded_at) FROM (
+        SELECT recorded_at FROM epoch_participation
+        ORDER BY recorded_at DESC LIMIT $1
+    ) AS recent
+)
+`
+
+func (q *Queries) PruneEpochParticipation(ctx context.Context, limit int32) (int64, error) {
+	result, err := q.db.Exec(ctx, pruneEpochParticipation, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const renewProcessLease = `-- name: RenewProcessLease :execrows
+UPDATE process_lease SET renewed_at = now() WHERE token = $1
+`
+
+func (q *Queries) RenewProcessLease(ctx context.Context, token int64) (int64, error) {
+	result, err := q.db.Exec(ctx, renewProcessLease, token)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const replaceDKGResult = `-- name: ReplaceDKGResult :execrows
+UPDATE dkg_result
+SET pure_result = $2
+WHERE eon = $1 AND success
+`
+
+type ReplaceDKGResultParams struct {
+	Eon        int64
+	PureResult []byte
+}
+
+func (q *Queries) ReplaceDKGResult(ctx context.Context, arg ReplaceDKGResultParams) (int64, error) {
+	result, err := q.db.Exec(ctx, replaceDKGResult, arg.Eon, arg.PureResult)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rotateBatchConfigAddress = `-- name: RotateBatchConfigAddress :execrows
+UPDATE tendermint_batch_config
+SET keypers[$1::integer + 1] = $2::text
+WHERE keyper_config_index = $3
+AND keypers[$1::integer + 1] = $4::text
+`
+
+type RotateBatchConfigAddressParams struct {
+	KeyperIndex       int32
+	NewAddress        string
+	KeyperConfigIndex int32
+	OldAddress        string
+}
+
+func (q *Queries) RotateBatchConfigAddress(ctx context.Context, arg RotateBatchConfigAddressParams) (int64, error) {
+	result, err := q.db.Exec(ctx, rotateBatchConfigAddress,
+		arg.KeyperIndex,
+		arg.NewAddress,
+		arg.KeyperConfigIndex,
+		arg.OldAddress,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rotatePolyEvalsReceiver = `-- name: RotatePolyEvalsReceiver :execrows
+UPDATE poly_evals
+SET receiver_address = $1::text
+WHERE receiver_address = $2::text
+AND eon IN (SELECT eon FROM eons WHERE keyper_config_index = $3)
+`
+
+type RotatePolyEvalsReceiverParams struct {
+	NewAddress        string
+	OldAddress        string
+	KeyperConfigIndex int64
+}
+
+func (q *Queries) RotatePolyEvalsReceiver(ctx context.Context, arg RotatePolyEvalsReceiverParams) (int64, error) {
+	result, err := q.db.Exec(ctx, rotatePolyEvalsReceiver, arg.NewAddress, arg.OldAddress, arg.KeyperConfigIndex)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const scheduleSerializedShutterMessage = `-- name: ScheduleSerializedShutterMessage :one
 INSERT INTO tendermint_outgoing_messages (description, msg)
 VALUES ($1, $2)
@@ -716,15 +2243,22 @@ type SelectDecryptionKeySharesParams struct {
 	EpochID []byte
 }
 
-func (q *Queries) SelectDecryptionKeyShares(ctx context.Context, arg SelectDecryptionKeySharesParams) ([]DecryptionKeyShare, error) {
+type SelectDecryptionKeySharesRow struct {
+	Eon                int64
+	EpochID            []byte
+	KeyperIndex        int64
+	DecryptionKeyShare []byte
+}
+
+func (q *Queries) SelectDecryptionKeyShares(ctx context.Context, arg SelectDecryptionKeySharesParams) ([]SelectDecryptionKeySharesRow, error) {
 	rows, err := q.db.Query(ctx, selectDecryptionKeyShares, arg.Eon, arg.EpochID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []DecryptionKeyShare
+	var items []SelectDecryptionKeySharesRow
 	for rows.Next() {
-		var i DecryptionKeyShare
+		var i SelectDecryptionKeySharesRow
 		if err := rows.Scan(
 			&i.Eon,
 			&i.EpochID,
@@ -775,6 +2309,92 @@ func (q *Queries) SetBatchConfigStarted(ctx context.Context, keyperConfigIndex i
 	return err
 }
 
+const setDKGParameters = `-- name: SetDKGParameters :exec
+INSERT INTO dkg_parameters (keyper_config_index, phase_length, max_missing_participants, block_number)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (keyper_config_index) DO UPDATE
+SET phase_length = EXCLUDED.phase_length,
+    max_missing_participants = EXCLUDED.max_missing_participants,
+    block_number = EXCLUDED.block_number
+`
+
+type SetDKGParametersParams struct {
+	KeyperConfigIndex      int64
+	PhaseLength            int64
+	MaxMissingParticipants int64
+	BlockNumber            int64
+}
+
+func (q *Queries) SetDKGParameters(ctx context.Context, arg SetDKGParametersParams) error {
+	_, err := q.db.Exec(ctx, setDKGParameters,
+		arg.KeyperConfigIndex,
+		arg.PhaseLength,
+		arg.MaxMissingParticipants,
+		arg.BlockNumber,
+	)
+	return err
+}
+
+const setEpochSLAKeyAvailable = `-- name: SetEpochSLAKeyAvailable :exec
+UPDATE epoch_sla SET key_available_at = $2, key_source = $3
+WHERE epoch_id = $1 AND key_available_at IS NULL
+`
+
+type SetEpochSLAKeyAvailableParams struct {
+	EpochID        []byte
+	KeyAvailableAt sql.NullTime
+	KeySource      string
+}
+
+func (q *Queries) SetEpochSLAKeyAvailable(ctx context.Context, arg SetEpochSLAKeyAvailableParams) error {
+	_, err := q.db.Exec(ctx, setEpochSLAKeyAvailable, arg.EpochID, arg.KeyAvailableAt, arg.KeySource)
+	return err
+}
+
+const setEpochSLASharesComplete = `-- name: SetEpochSLASharesComplete :exec
+UPDATE epoch_sla SET shares_complete_at = $2
+WHERE epoch_id = $1 AND shares_complete_at IS NULL
+`
+
+type SetEpochSLASharesCompleteParams struct {
+	EpochID          []byte
+	SharesCompleteAt sql.NullTime
+}
+
+func (q *Queries) SetEpochSLASharesComplete(ctx context.Context, arg SetEpochSLASharesCompleteParams) error {
+	_, err := q.db.Exec(ctx, setEpochSLASharesComplete, arg.EpochID, arg.SharesCompleteAt)
+	return err
+}
+
+const setKeyGenerationPause = `-- name: SetKeyGenerationPause :execrows
+INSERT INTO key_generation_pause (paused, sequence, keyper_config_index, reason)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (enforce_one_row) DO UPDATE
+SET paused = EXCLUDED.paused, sequence = EXCLUDED.sequence,
+    keyper_config_index = EXCLUDED.keyper_config_index, reason = EXCLUDED.reason, updated_at = now()
+WHERE key_generation_pause.sequence < EXCLUDED.sequence
+`
+
+type SetKeyGenerationPauseParams struct {
+	Paused            bool
+	Sequence          int64
+	KeyperConfigIndex int64
+	Reason            string
+}
+
+func (q *Queries) SetKeyGenerationPause(ctx context.Context, arg SetKeyGenerationPauseParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setKeyGenerationPause,
+		arg.Paused,
+		arg.Sequence,
+		arg.KeyperConfigIndex,
+		arg.Reason,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const setLastBatchConfigSent = `-- name: SetLastBatchConfigSent :exec
 INSERT INTO last_batch_config_sent (keyper_config_index) VALUES ($1)
 ON CONFLICT (enforce_one_row) DO UPDATE
@@ -797,6 +2417,95 @@ func (q *Queries) SetLastBlockSeen(ctx context.Context, blockNumber int64) error
 	return err
 }
 
+const setMempoolObservationFinding = `-- name: SetMempoolObservationFinding :exec
+UPDATE mempool_observation SET finding = $2, finding_details = $3
+WHERE tx_hash = $1
+`
+
+type SetMempoolObservationFindingParams struct {
+	TxHash         []byte
+	Finding        string
+	FindingDetails string
+}
+
+func (q *Queries) SetMempoolObservationFinding(ctx context.Context, arg SetMempoolObservationFindingParams) error {
+	_, err := q.db.Exec(ctx, setMempoolObservationFinding, arg.TxHash, arg.Finding, arg.FindingDetails)
+	return err
+}
+
+const setMempoolObservationIncluded = `-- name: SetMempoolObservationIncluded :exec
+UPDATE mempool_observation SET included_block_number = $2
+WHERE tx_hash = $1
+`
+
+type SetMempoolObservationIncludedParams struct {
+	TxHash              []byte
+	IncludedBlockNumber int64
+}
+
+func (q *Queries) SetMempoolObservationIncluded(ctx context.Context, arg SetMempoolObservationIncludedParams) error {
+	_, err := q.db.Exec(ctx, setMempoolObservationIncluded, arg.TxHash, arg.IncludedBlockNumber)
+	return err
+}
+
+const setMinimumBond = `-- name: SetMinimumBond :exec
+INSERT INTO keyper_bond_minimum (minimum_bond, block_number) VALUES ($1, $2)
+ON CONFLICT (enforce_one_row) DO UPDATE
+SET minimum_bond = EXCLUDED.minimum_bond, block_number = EXCLUDED.block_number
+`
+
+type SetMinimumBondParams struct {
+	MinimumBond []byte
+	BlockNumber int64
+}
+
+func (q *Queries) SetMinimumBond(ctx context.Context, arg SetMinimumBondParams) error {
+	_, err := q.db.Exec(ctx, setMinimumBond, arg.MinimumBond, arg.BlockNumber)
+	return err
+}
+
+const setNodeVersionRequirement = `-- name: SetNodeVersionRequirement :exec
+INSERT INTO node_version_requirement (minimum_version, minimum_protocol_version, block_number)
+VALUES ($1, $2, $3)
+ON CONFLICT (enforce_one_row) DO UPDATE
+SET minimum_version = EXCLUDED.minimum_version,
+    minimum_protocol_version = EXCLUDED.minimum_protocol_version,
+    block_number = EXCLUDED.block_number
+`
+
+type SetNodeVersionRequirementParams struct {
+	MinimumVersion         string
+	MinimumProtocolVersion int64
+	BlockNumber            int64
+}
+
+func (q *Queries) SetNodeVersionRequirement(ctx context.Context, arg SetNodeVersionRequirementParams) error {
+	_, err := q.db.Exec(ctx, setNodeVersionRequirement, arg.MinimumVersion, arg.MinimumProtocolVersion, arg.BlockNumber)
+	return err
+}
+
+const setProcessLease = `-- name: SetProcessLease :one
+INSERT INTO process_lease (pid, hostname, acquired_at, renewed_at, token)
+VALUES ($1, $2, now(), now(), 1)
+ON CONFLICT (id) DO UPDATE
+SET pid = EXCLUDED.pid, hostname = EXCLUDED.hostname,
+    acquired_at = EXCLUDED.acquired_at, renewed_at = EXCLUDED.renewed_at,
+    token = process_lease.token + 1
+RETURNING token
+`
+
+type SetProcessLeaseParams struct {
+	Pid      int32
+	Hostname string
+}
+
+func (q *Queries) SetProcessLease(ctx context.Context, arg SetProcessLeaseParams) (int64, error) {
+	row := q.db.QueryRow(ctx, setProcessLease, arg.Pid, arg.Hostname)
+	var token int64
+	err := row.Scan(&token)
+	return token, err
+}
+
 const tMGetSyncMeta = `-- name: TMGetSyncMeta :one
 SELECT current_block, last_committed_height, sync_timestamp
 FROM tendermint_sync_meta
@@ -826,3 +2535,33 @@ func (q *Queries) TMSetSyncMeta(ctx context.Context, arg TMSetSyncMetaParams) er
 	_, err := q.db.Exec(ctx, tMSetSyncMeta, arg.CurrentBlock, arg.LastCommittedHeight, arg.SyncTimestamp)
 	return err
 }
+
+const upsertKeyperBond = `-- name: UpsertKeyperBond :exec
+INSERT INTO keyper_bond (address, bonded, unbonding, unbonding_block_number, block_number, log_index)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (address) DO UPDATE
+SET bonded = EXCLUDED.bonded, unbonding = EXCLUDED.unbonding,
+    unbonding_block_number = EXCLUDED.unbonding_block_number,
+    block_number = EXCLUDED.block_number, log_index = EXCLUDED.log_index
+`
+
+type UpsertKeyperBondParams struct {
+	Address              string
+	Bonded               []byte
+	Unbonding            []byte
+	UnbondingBlockNumber int64
+	BlockNumber          int64
+	LogIndex             int64
+}
+
+func (q *Queries) UpsertKeyperBond(ctx context.Context, arg UpsertKeyperBondParams) error {
+	_, err := q.db.Exec(ctx, upsertKeyperBond,
+		arg.Address,
+		arg.Bonded,
+		arg.Unbonding,
+		arg.UnbondingBlockNumber,
+		arg.BlockNumber,
+		arg.LogIndex,
+	)
+	return err
+}
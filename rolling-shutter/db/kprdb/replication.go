@@ -0,0 +1,19 @@
+package kprdb
+
+import "github.com/shutter-network/rolling-shutter/rolling-shutter/db/replication"
+
+// Publication publishes the eon keys, decryption keys, batch configs and keyper sets of the keyper
+// database for logical replication. Tables holding secrets, such as dkg_result, poly_evals or
+// key_escrow, must never be added. All published tables have a primary key that is never updated,
+// so the default replica identity is sufficient.
+var Publication = &replication.Publication{
+	Name: "shutter_keyper",
+	Tables: []replication.Table{
+		{Name: "eons", Identity: replication.IdentityDefault},
+		{Name: "eon_public_key_candidate", Identity: replication.IdentityDefault},
+		{Name: "decryption_key", Identity: replication.IdentityDefault},
+		{Name: "tendermint_batch_config", Identity: replication.IdentityDefault},
+		// keyper_set is part of the chain observer schema included in the keyper database
+		{Name: "keyper_set", Identity: replication.IdentityDefault},
+	},
+}
@@ -1,15 +1,18 @@
--- schema-version: keyper-16 --
+-- schema-version: keyper-49 --
 -- Please change the version above if you make incompatible changes to
 -- the schema. We'll use this to check we're using the right schema.
 
 CREATE TABLE decryption_trigger (
        epoch_id bytea PRIMARY KEY
 );
+-- created_at is added to existing databases by the decryption-key-share-created-at migration.
+-- Queries must not depend on it before the migration has been switched.
 CREATE TABLE decryption_key_share (
        eon bigint,
        epoch_id bytea,
        keyper_index bigint,
        decryption_key_share bytea,
+       created_at timestamptz NOT NULL DEFAULT now(),
        PRIMARY KEY (eon, epoch_id, keyper_index)
 );
 CREATE TABLE decryption_key (
@@ -18,6 +21,25 @@ CREATE TABLE decryption_key (
        decryption_key bytea,
        PRIMARY KEY (eon, epoch_id)
 );
+-- finalized_epochs records when the decryption key of an epoch became known. From then on, the
+-- decryption key shares of the epoch aren't needed anymore and can be garbage collected.
+CREATE TABLE finalized_epochs (
+       eon bigint NOT NULL,
+       epoch_id bytea NOT NULL,
+       finalized_at timestamptz NOT NULL DEFAULT now(),
+       PRIMARY KEY (eon, epoch_id)
+);
+CREATE INDEX finalized_epochs_finalized_at_idx ON finalized_epochs (finalized_at);
+-- epoch_participation records which keypers had contributed a decryption key share to an epoch
+-- by the time its decryption key became known.
+CREATE TABLE epoch_participation (
+       eon bigint NOT NULL,
+       epoch_id bytea NOT NULL,
+       keyper_indices bigint[] NOT NULL,
+       recorded_at timestamptz NOT NULL DEFAULT now(),
+       PRIMARY KEY (eon, epoch_id)
+);
+CREATE INDEX epoch_participation_recorded_at_idx ON epoch_participation (recorded_at);
 
 ----- tendermint events
 
@@ -99,8 +121,230 @@ CREATE TABLE dkg_result(
        pure_result BYTEA  -- shdb.EncodePureDKGResult/shdb.DecodePureDKGResult
 );
 
+-- dkg_transcript contains the public messages of the DKG processes we took part in, in the order
+-- we handled them, together with the DKG phase we were in at that time. With the keyper set of the
+-- eon, it allows third parties to verify the outcome of a DKG, see the verify-dkg command.
+CREATE TABLE dkg_transcript(
+       id bigserial PRIMARY KEY,
+       eon bigint NOT NULL,
+       height bigint NOT NULL,
+       phase integer NOT NULL,
+       sender text NOT NULL,
+       event bytea NOT NULL  -- the marshaled ABCI event emitted by shuttermint
+);
+CREATE INDEX dkg_transcript_eon_idx ON dkg_transcript (eon);
+
 -- outgoing_eon_keys contains the eon public key(s) that should be broadcast as a result of a successful DKG
 CREATE TABLE outgoing_eon_keys(
        eon_public_key bytea,
        eon bigint NOT NULL PRIMARY KEY
 );
+
+CREATE OR REPLACE FUNCTION notify_new_outgoing_eon_key()
+  RETURNS TRIGGER AS $$
+DECLARE
+BEGIN
+  PERFORM pg_notify('new_outgoing_eon_key', 'payload');
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER notify_outgoing_eon_key
+         AFTER INSERT ON outgoing_eon_keys
+    FOR EACH STATEMENT EXECUTE PROCEDURE notify_new_outgoing_eon_key();
+
+-- eon_public_key_candidate stores the eon public keys keypers voted for. A candidate is confirmed
+-- once threshold many keypers of the keyper set have signed it.
+CREATE TABLE eon_public_key_candidate(
+    hash bytea PRIMARY KEY,
+    eon_public_key bytea NOT NULL,
+    activation_block_number bigint NOT NULL,
+    keyper_config_index bigint NOT NULL,
+    eon bigint NOT NULL,
+    confirmed BOOL NOT NULL DEFAULT FALSE
+);
+
+-- eon_public_key_vote stores the signatures of the keypers over an eon public key candidate.
+-- Together, the signatures of a confirmed candidate allow anyone to verify the eon public key
+-- without trusting a single keyper.
+CREATE TABLE eon_public_key_vote(
+    hash bytea REFERENCES eon_public_key_candidate(hash),
+    sender text NOT NULL,
+    signature bytea NOT NULL,
+    eon bigint NOT NULL,
+    keyper_config_index bigint NOT NULL,
+    PRIMARY KEY(sender, eon)
+);
+
+-- process_lease records which keyper process holds the lease on the database. The lease itself is
+-- a session level advisory lock, which Postgres releases as soon as the connection of the process
+-- is closed. The token is incremented whenever the lease is acquired, so that a process can check
+-- that it still holds the lease from within its own transactions.
+CREATE TABLE process_lease(
+    id bool UNIQUE NOT NULL DEFAULT true,
+    pid integer NOT NULL,
+    hostname text NOT NULL,
+    acquired_at timestamptz NOT NULL,
+    renewed_at timestamptz NOT NULL,
+    token bigint NOT NULL,
+    CHECK (id)
+);
+
+-- signing_nonce records the nonces of messages that may be submitted on-chain, together with the
+-- hash of the message signed with the nonce. Nonces are sequence numbers per signer and kind of
+-- message starting at 1, so that a signer can't sign different messages with the same nonce and
+-- old signatures can't be replayed for other messages.
+CREATE TABLE signing_nonce(
+    domain bytea NOT NULL,  -- p2pmsg.SigningDomain.Separator of the domain and kind
+    signer text NOT NULL,
+    nonce bigint NOT NULL,
+    message_hash bytea NOT NULL,
+    consumed_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (domain, signer, nonce)
+);
+
+-- keyper_bond stores the bonds of keypers as synced from the BondChanged events of the KeyperBonds
+-- contract. Amounts are in wei and encoded with shdb.EncodeBigint.
+CREATE TABLE keyper_bond(
+    address text PRIMARY KEY,
+    bonded bytea NOT NULL,
+    unbonding bytea NOT NULL,
+    unbonding_block_number bigint NOT NULL,  -- the block from which on the unbonding amount can be withdrawn
+    block_number bigint NOT NULL,
+    log_index bigint NOT NULL
+);
+
+-- keyper_bond_minimum stores the bond keypers are required to hold according to the KeyperBonds
+-- contract.
+CREATE TABLE keyper_bond_minimum(
+    enforce_one_row BOOL PRIMARY KEY DEFAULT TRUE,
+    minimum_bond bytea NOT NULL,
+    block_number bigint NOT NULL
+);
+
+-- epoch_pre_announcement stores the announcements of upcoming epochs gossiped by the collator, so
+-- that the actual trigger can be compared against them.
+CREATE TABLE epoch_pre_announcement(
+    epoch_id bytea PRIMARY KEY,
+    block_number bigint NOT NULL,
+    expected_trigger_time timestamptz NOT NULL,
+    received_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- node_version_requirement stores the minimum software and p2p protocol versions nodes are
+-- required to run according to the VersionRequirements contract.
+CREATE TABLE node_version_requirement(
+    enforce_one_row BOOL PRIMARY KEY DEFAULT TRUE,
+    minimum_version text NOT NULL,
+    minimum_protocol_version bigint NOT NULL,
+    block_number bigint NOT NULL
+);
+
+-- dkg_parameters stores the DKG parameters announced by the DKGParameters contract per keyper
+-- config. Eons of keyper configs without an entry use the DKG phase length of the local config and
+-- don't limit the number of missing participants.
+CREATE TABLE dkg_parameters(
+    keyper_config_index bigint PRIMARY KEY,
+    phase_length bigint NOT NULL,
+    max_missing_participants bigint NOT NULL,
+    block_number bigint NOT NULL
+);
+
+-- eon_dkg_parameters stores the DKG parameters the DKG of each eon runs with. They are fixed when
+-- the eon starts, so that parameter changes don't affect running DKGs.
+CREATE TABLE eon_dkg_parameters(
+    eon bigint PRIMARY KEY,
+    phase_length bigint NOT NULL,
+    max_missing_participants bigint NOT NULL
+);
+
+-- share_self_audit_failure stores the decryption key shares of our own that did not verify against
+-- our eon public key share. Keypers stop sending shares for an eon with a failure, delete its rows
+-- to resume once the key material has been restored.
+CREATE TABLE share_self_audit_failure(
+    eon bigint NOT NULL,
+    epoch_id bytea NOT NULL,
+    keyper_index bigint NOT NULL,
+    share bytea NOT NULL,
+    detected_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (eon, epoch_id)
+);
+
+-- key_escrow records the eons whose DKG result has been written to the escrow backup directory,
+-- encrypted to the recovery keys identified by fingerprint, see the escrow package.
+CREATE TABLE key_escrow(
+    eon bigint NOT NULL,
+    fingerprint bytea NOT NULL,
+    written_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (eon, fingerprint)
+);
+
+-- key_generation_pause stores whether the keypers decided to pause generating decryption keys for
+-- future epochs, e.g. in response to a discovered vulnerability. sequence is the sequence number of
+-- the pause votes the decision has been made by, see pause_vote.
+CREATE TABLE key_generation_pause(
+    enforce_one_row BOOL PRIMARY KEY DEFAULT TRUE,
+    paused boolean NOT NULL,
+    sequence bigint NOT NULL,
+    keyper_config_index bigint NOT NULL,
+    reason text NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- pause_vote stores the signed votes of the keypers to pause or resume key generation.
+CREATE TABLE pause_vote(
+    keyper_config_index bigint NOT NULL,
+    sequence bigint NOT NULL,
+    sender text NOT NULL,
+    paused boolean NOT NULL,
+    reason text NOT NULL,
+    signature bytea NOT NULL,
+    received_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (keyper_config_index, sequence, sender)
+);
+
+-- mempool_observation stores the encrypted Shutter transactions the mempool observer saw pending on
+-- the target chain. included_block_number is 0 as long as the transaction hasn't been seen in a
+-- block. finding is empty unless the transaction appears to have been censored, front-run or
+-- replaced, in which case finding_details describes why.
+CREATE TABLE mempool_observation(
+    tx_hash bytea PRIMARY KEY,
+    sender text NOT NULL,
+    inbox text NOT NULL,
+    nonce bigint NOT NULL,
+    payload_hash bytea NOT NULL,
+    max_fee_per_gas bytea NOT NULL,
+    first_seen_block_number bigint NOT NULL,
+    first_seen_at timestamptz NOT NULL DEFAULT now(),
+    included_block_number bigint NOT NULL DEFAULT 0,
+    finding text NOT NULL DEFAULT '',
+    finding_details text NOT NULL DEFAULT ''
+);
+CREATE INDEX mempool_observation_payload_hash_idx ON mempool_observation (payload_hash);
+
+-- annotation contains notes and labels operators attach to keypers, eons and epochs, e.g. to
+-- record why the DKG of an eon has been restarted. entity_id identifies the entity within its kind:
+-- the checksummed address of a keyper, the index of an eon or the hex encoded id of an epoch.
+CREATE TABLE annotation(
+    id bigserial PRIMARY KEY,
+    entity_kind text NOT NULL,
+    entity_id text NOT NULL,
+    label text NOT NULL DEFAULT '',
+    note text NOT NULL DEFAULT '',
+    author text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX annotation_entity_idx ON annotation (entity_kind, entity_id);
+
+-- epoch_sla records the progress of every epoch we received a decryption trigger for, so that it
+-- can be checked whether its decryption key became available within the SLA target, see the sla
+-- package. shares_complete_at is when we received the share completing the threshold, it stays
+-- NULL if we didn't aggregate the key ourselves.
+CREATE TABLE epoch_sla(
+    epoch_id bytea PRIMARY KEY,
+    triggered_at timestamptz NOT NULL,
+    shares_complete_at timestamptz,
+    key_available_at timestamptz,
+    key_source text NOT NULL DEFAULT ''
+);
+CREATE INDEX epoch_sla_triggered_at_idx ON epoch_sla (triggered_at);
@@ -0,0 +1,25 @@
+package kprdb
+
+import (
+	"context"
+
+	"github.com/jackc/pgconn"
+)
+
+// The store interfaces group the queries of a single domain, so that code can depend on just the
+// queries it needs and be tested against a fake implementation. Queries implements all of them.
+// The keyper sets and the sync progress live in chainobsdb, see chainobsdb.KeyperSetStore and
+// chainobsdb.SyncProgressStore.
+var _ DecryptionKeyStore = (*Queries)(nil)
+
+// DecryptionKeyStore stores the decryption keys and the decryption key shares of the epochs.
+type DecryptionKeyStore interface {
+	GetDecryptionKey(ctx context.Context, arg GetDecryptionKeyParams) (DecryptionKey, error)
+	ExistsDecryptionKey(ctx context.Context, arg ExistsDecryptionKeyParams) (bool, error)
+	InsertDecryptionKey(ctx context.Context, arg InsertDecryptionKeyParams) (pgconn.CommandTag, error)
+	GetDecryptionKeyShare(ctx context.Context, arg GetDecryptionKeyShareParams) (GetDecryptionKeyShareRow, error)
+	ExistsDecryptionKeyShare(ctx context.Context, arg ExistsDecryptionKeyShareParams) (bool, error)
+	InsertDecryptionKeyShare(ctx context.Context, arg InsertDecryptionKeyShareParams) error
+	SelectDecryptionKeyShares(ctx context.Context, arg SelectDecryptionKeySharesParams) ([]SelectDecryptionKeySharesRow, error)
+	CountDecryptionKeyShares(ctx context.Context, arg CountDecryptionKeySharesParams) (int64, error)
+}
@@ -3,3 +3,7 @@ INSERT INTO meta_inf (key, value) VALUES ($1, $2);
 
 -- name: GetMeta :one
 SELECT value FROM meta_inf WHERE key = $1;
+
+-- name: SetMeta :exec
+INSERT INTO meta_inf (key, value) VALUES ($1, $2)
+ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value;
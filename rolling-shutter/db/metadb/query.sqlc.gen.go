@@ -33,3 +33,18 @@ func (q *Queries) InsertMeta(ctx context.Context, arg InsertMetaParams) error {
 	_, err := q.db.Exec(ctx, insertMeta, arg.Key, arg.Value)
 	return err
 }
+
+const setMeta = `-- name: SetMeta :exec
+INSERT INTO meta_inf (key, value) VALUES ($1, $2)
+ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value
+`
+
+type SetMetaParams struct {
+	Key   string
+	Value string
+}
+
+func (q *Queries) SetMeta(ctx context.Context, arg SetMetaParams) error {
+	_, err := q.db.Exec(ctx, setMeta, arg.Key, arg.Value)
+	return err
+}
@@ -0,0 +1,3 @@
+// Package metricsdb contains the sqlc generated files for the snapshots of a node's key metrics,
+// which are persisted for post-mortem analysis.
+package metricsdb
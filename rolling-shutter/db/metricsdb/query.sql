@@ -0,0 +1,15 @@
+-- name: InsertMetricsSnapshotValue :exec
+INSERT INTO metrics_snapshot (taken_at, name, value) VALUES ($1, $2, $3);
+
+-- name: FindMetricsSnapshots :many
+SELECT * FROM metrics_snapshot
+WHERE taken_at >= $1
+ORDER BY taken_at, name;
+
+-- name: DeleteOldMetricsSnapshots :execrows
+DELETE FROM metrics_snapshot
+WHERE taken_at <= (
+    SELECT DISTINCT taken_at FROM metrics_snapshot
+    ORDER BY taken_at DESC
+    OFFSET @keep LIMIT 1
+);
@@ -0,0 +1,9 @@
+-- metrics_snapshot keeps periodic snapshots of key metrics of the node, so that incidents can be
+-- analyzed even if no monitoring system was scraping the node. It is used as a ring buffer: only
+-- the most recent snapshots are kept.
+CREATE TABLE metrics_snapshot (
+       taken_at timestamptz NOT NULL,
+       name text NOT NULL,
+       value double precision NOT NULL,
+       PRIMARY KEY (taken_at, name)
+);
@@ -0,0 +1,245 @@
+// Package migration implements online schema changes, which allow migrating large tables without
+// taking the nodes using the database offline. A migration goes through the following phases, each
+// of which is started by the operator while the nodes keep running:
+//
+//   - expand: the new tables are created next to the old ones. Nodes started from then on write to
+//     both the old and the new tables.
+//   - backfill: existing rows are copied to the new tables in small batches.
+//   - switch: the new tables atomically replace the old ones by renaming them. Rows written by
+//     nodes that didn't write to both are copied over first. From then on, nodes only write to the
+//     new tables under the old names.
+//   - contract: the old tables are dropped.
+//
+// The current phase of a migration is stored in the meta_inf table. Nodes read it when they start
+// to decide whether to write to both tables, so restarting them after the expand step saves most
+// of the work of the switch step.
+package migration
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/metadb"
+)
+
+type Phase string
+
+const (
+	PhaseNone       Phase = ""
+	PhaseExpanded   Phase = "expanded"
+	PhaseBackfilled Phase = "backfilled"
+	PhaseSwitched   Phase = "switched"
+	PhaseContracted Phase = "contracted"
+)
+
+// undefinedTable is the postgres error code returned when accessing a table that doesn't exist.
+const undefinedTable = "42P01"
+
+// Migration describes an online schema change by the SQL statements executed in its phases.
+type Migration struct {
+	Name string
+	// Expand creates the new tables. It must not interfere with nodes using the old ones.
+	Expand string
+	// DualWrite mirrors a single write to the old tables to the new tables.
+	DualWrite string
+	// Backfill copies at most $1 rows that are missing in the new tables from the old ones.
+	Backfill string
+	// Lock locks the old tables against concurrent writes before switching over.
+	Lock string
+	// Switch replaces the old tables with the new ones.
+	Switch string
+	// Contract drops the old tables.
+	Contract string
+
+	dualWriting atomic.Bool
+}
+
+func (m *Migration) metaKey() string {
+	return "migration:" + m.Name
+}
+
+// Phase returns the phase the migration is currently in.
+func (m *Migration) Phase(ctx context.Context, db metadb.DBTX) (Phase, error) {
+	val, err := metadb.New(db).GetMeta(ctx, m.metaKey())
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PhaseNone, nil
+	}
+	if err != nil {
+		return PhaseNone, errors.Wrapf(err, "failed to get phase of migration %s", m.Name)
+	}
+	return Phase(val), nil
+}
+
+// IsDualWriting checks if writes have to be mirrored to the new tables.
+func (m *Migration) IsDualWriting(ctx context.Context, db metadb.DBTX) (bool, error) {
+	phase, err := m.Phase(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	return phase == PhaseExpanded || phase == PhaseBackfilled, nil
+}
+
+// LoadDualWriting reads whether writes have to be mirrored to the new tables. Nodes call it once
+// at startup instead of querying the phase on every write.
+func (m *Migration) LoadDualWriting(ctx context.Context, db metadb.DBTX) error {
+	dualWriting, err := m.IsDualWriting(ctx, db)
+	if err != nil {
+		return err
+	}
+	m.dualWriting.Store(dualWriting)
+	return nil
+}
+
+// DualWriting returns whether writes have to be mirrored to the new tables as read by
+// LoadDualWriting.
+func (m *Migration) DualWriting() bool {
+	return m.dualWriting.Load()
+}
+
+// MarkContracted records the migration as finished, for databases created with the new tables
+// right away.
+func (m *Migration) MarkContracted(ctx context.Context, db metadb.DBTX) error {
+	return m.setPhase(ctx, db, PhaseContracted)
+}
+
+func (m *Migration) setPhase(ctx context.Context, db metadb.DBTX, phase Phase) error {
+	err := metadb.New(db).SetMeta(ctx, metadb.SetMetaParams{Key: m.metaKey(), Value: string(phase)})
+	return errors.Wrapf(err, "failed to set phase of migration %s", m.Name)
+}
+
+type beginner interface {
+	BeginFunc(ctx context.Context, f func(pgx.Tx) error) error
+}
+
+// ExecDualWrite mirrors a write to the new tables. Nodes that haven't noticed the switch yet
+// might still try to write to the new tables under their old names. Those writes are ignored,
+// since the rows have already been written to the tables that replaced them. If possible, the
+// write is executed in a nested transaction, so that a failure doesn't abort the surrounding one.
+func (m *Migration) ExecDualWrite(ctx context.Context, db metadb.DBTX, args ...interface{}) error {
+	exec := func(db metadb.DBTX) error {
+		_, err := db.Exec(ctx, m.DualWrite, args...)
+		return err
+	}
+	var err error
+	if b, ok := db.(beginner); ok {
+		err = b.BeginFunc(ctx, func(tx pgx.Tx) error {
+			return exec(tx)
+		})
+	} else {
+		err = exec(db)
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == undefinedTable {
+		return nil
+	}
+	return errors.Wrapf(err, "failed to dual write for migration %s", m.Name)
+}
+
+// step moves the migration from one phase to the next one after running fn in the same
+// transaction.
+func (m *Migration) step(
+	ctx context.Context, dbpool *pgxpool.Pool, from, to Phase, fn func(tx pgx.Tx) error,
+) error {
+	return dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		phase, err := m.Phase(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if phase != from {
+			return errors.Errorf("migration %s is in phase %q, expected %q", m.Name, phase, from)
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if err := m.setPhase(ctx, tx, to); err != nil {
+			return err
+		}
+		log.Info().Str("migration", m.Name).Str("phase", string(to)).Msg("migration advanced")
+		return nil
+	})
+}
+
+func (m *Migration) exec(ctx context.Context, tx pgx.Tx, sql string) error {
+	_, err := tx.Exec(ctx, sql)
+	return errors.Wrapf(err, "failed to execute statements of migration %s", m.Name)
+}
+
+func (m *Migration) backfillBatch(ctx context.Context, tx pgx.Tx, batchSize int64) (int64, error) {
+	tag, err := tx.Exec(ctx, m.Backfill, batchSize)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to backfill rows of migration %s", m.Name)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// RunExpand creates the new tables and starts dual writing.
+func (m *Migration) RunExpand(ctx context.Context, dbpool *pgxpool.Pool) error {
+	return m.step(ctx, dbpool, PhaseNone, PhaseExpanded, func(tx pgx.Tx) error {
+		return m.exec(ctx, tx, m.Expand)
+	})
+}
+
+// RunBackfill copies the existing rows to the new tables. Every batch is copied in its own
+// transaction, so that locks are only held for a short time.
+func (m *Migration) RunBackfill(ctx context.Context, dbpool *pgxpool.Pool, batchSize int64) error {
+	phase, err := m.Phase(ctx, dbpool)
+	if err != nil {
+		return err
+	}
+	if phase != PhaseExpanded {
+		return errors.Errorf("migration %s is in phase %q, expected %q", m.Name, phase, PhaseExpanded)
+	}
+	total := int64(0)
+	for {
+		var n int64
+		err := dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+			var err error
+			n, err = m.backfillBatch(ctx, tx, batchSize)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+		total += n
+		log.Info().Str("migration", m.Name).Int64("rows", total).Msg("backfilling")
+	}
+	return m.step(ctx, dbpool, PhaseExpanded, PhaseBackfilled, func(tx pgx.Tx) error {
+		return nil
+	})
+}
+
+// RunSwitch replaces the old tables with the new ones. Rows written by nodes that didn't dual
+// write are copied over while the old tables are locked.
+func (m *Migration) RunSwitch(ctx context.Context, dbpool *pgxpool.Pool) error {
+	return m.step(ctx, dbpool, PhaseBackfilled, PhaseSwitched, func(tx pgx.Tx) error {
+		if err := m.exec(ctx, tx, m.Lock); err != nil {
+			return err
+		}
+		for {
+			n, err := m.backfillBatch(ctx, tx, 1000)
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				break
+			}
+		}
+		return m.exec(ctx, tx, m.Switch)
+	})
+}
+
+// RunContract drops the old tables.
+func (m *Migration) RunContract(ctx context.Context, dbpool *pgxpool.Pool) error {
+	return m.step(ctx, dbpool, PhaseSwitched, PhaseContracted, func(tx pgx.Tx) error {
+		return m.exec(ctx, tx, m.Contract)
+	})
+}
@@ -0,0 +1,70 @@
+package migration_test
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/migration"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+func TestOnlineMigrationIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	db, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+	m := kprdb.DecryptionKeyShareCreatedAt
+
+	phase, err := m.Phase(ctx, dbpool)
+	assert.NilError(t, err)
+	assert.Equal(t, phase, migration.PhaseContracted)
+	// go back to the schema databases had before the migration
+	_, err = dbpool.Exec(ctx, `
+ALTER TABLE decryption_key_share DROP COLUMN created_at;
+DELETE FROM meta_inf WHERE key = 'migration:decryption-key-share-created-at';`)
+	assert.NilError(t, err)
+	assert.NilError(t, m.LoadDualWriting(ctx, dbpool))
+	assert.Assert(t, !m.DualWriting())
+
+	insertShare := func(keyperIndex uint64) {
+		err := db.InsertDecryptionKeySharesMsg(ctx, &p2pmsg.DecryptionKeyShares{
+			Eon:         1,
+			KeyperIndex: keyperIndex,
+			Shares:      []*p2pmsg.KeyShare{{EpochID: []byte{1}, Share: []byte{2}}},
+		})
+		assert.NilError(t, err)
+	}
+	countShares := func() int64 {
+		n, err := db.CountDecryptionKeyShares(ctx, kprdb.CountDecryptionKeySharesParams{
+			Eon:     1,
+			EpochID: []byte{1},
+		})
+		assert.NilError(t, err)
+		return n
+	}
+
+	insertShare(0)
+	assert.ErrorContains(t, m.RunSwitch(ctx, dbpool), "expected \"backfilled\"")
+	assert.NilError(t, m.RunExpand(ctx, dbpool))
+	assert.NilError(t, m.LoadDualWriting(ctx, dbpool))
+	assert.Assert(t, m.DualWriting())
+	insertShare(1)
+	assert.NilError(t, m.RunBackfill(ctx, dbpool, 1))
+	insertShare(2)
+	assert.NilError(t, m.RunSwitch(ctx, dbpool))
+	assert.Equal(t, countShares(), int64(3))
+
+	insertShare(3)
+	assert.NilError(t, m.RunContract(ctx, dbpool))
+	assert.Equal(t, countShares(), int64(4))
+
+	phase, err = m.Phase(ctx, dbpool)
+	assert.NilError(t, err)
+	assert.Equal(t, phase, migration.PhaseContracted)
+}
@@ -0,0 +1,3 @@
+// Package paramdb contains the sqlc generated files for the protocol parameter registry, which
+// keeps the parameters of a deployment together with a history of their changes.
+package paramdb
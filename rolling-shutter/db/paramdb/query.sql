@@ -0,0 +1,25 @@
+-- name: UpsertProtocolParameter :execrows
+-- UpsertProtocolParameter sets the value of a parameter. Nothing is changed if the parameter
+-- already has the value, so that the number of affected rows tells if a change has to be recorded
+-- in the history.
+INSERT INTO protocol_parameter (name, value, source, block_number)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (name) DO UPDATE
+SET value = EXCLUDED.value,
+    source = EXCLUDED.source,
+    block_number = EXCLUDED.block_number,
+    updated_at = now()
+WHERE protocol_parameter.value <> EXCLUDED.value;
+
+-- name: InsertProtocolParameterHistory :exec
+INSERT INTO protocol_parameter_history (name, value, source, block_number)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetProtocolParameter :one
+SELECT * FROM protocol_parameter WHERE name = $1;
+
+-- name: GetProtocolParameters :many
+SELECT * FROM protocol_parameter ORDER BY name;
+
+-- name: GetProtocolParameterHistory :many
+SELECT * FROM protocol_parameter_history WHERE name = $1 ORDER BY id;
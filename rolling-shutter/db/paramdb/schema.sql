@@ -0,0 +1,23 @@
+-- protocol_parameter contains the current value of each protocol parameter of the deployment,
+-- e.g. the finality offset or the threshold of the keyper set. source is where the value comes
+-- from: default, config or contract. block_number is the block of the contract event that set it
+-- and 0 for the other sources.
+CREATE TABLE protocol_parameter (
+       name text PRIMARY KEY,
+       value text NOT NULL,
+       source text NOT NULL,
+       block_number bigint NOT NULL DEFAULT 0,
+       updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- protocol_parameter_history contains every value a protocol parameter has had, in the order
+-- they were set.
+CREATE TABLE protocol_parameter_history (
+       id bigserial PRIMARY KEY,
+       name text NOT NULL,
+       value text NOT NULL,
+       source text NOT NULL,
+       block_number bigint NOT NULL DEFAULT 0,
+       changed_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX protocol_parameter_history_name ON protocol_parameter_history (name, id);
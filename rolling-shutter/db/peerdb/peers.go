@@ -0,0 +1,3 @@
+// Package peerdb contains the sqlc generated files for the identity attestations nodes broadcast
+// when they join the p2p network.
+package peerdb
@@ -0,0 +1,24 @@
+-- name: UpsertNodeAttestation :exec
+-- UpsertNodeAttestation stores an attestation unless a more recent one of the same node is
+-- already known.
+INSERT INTO node_attestation (address, role, version, protocol_versions, peer_id, attested_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (address) DO UPDATE
+SET role = EXCLUDED.role,
+    version = EXCLUDED.version,
+    protocol_versions = EXCLUDED.protocol_versions,
+    peer_id = EXCLUDED.peer_id,
+    attested_at = EXCLUDED.attested_at,
+    received_at = now()
+WHERE node_attestation.attested_at < EXCLUDED.attested_at;
+
+-- name: GetNodeAttestations :many
+SELECT * FROM node_attestation ORDER BY role, address;
+
+-- name: GetNodePeerIDs :many
+-- GetNodePeerIDs returns the peer ids of the given nodes, as far as they are known.
+SELECT address, peer_id FROM node_attestation
+WHERE address = ANY(sqlc.arg(addresses)::text[]) AND peer_id <> '';
+
+-- name: PruneNodeAttestations :execrows
+DELETE FROM node_attestation WHERE received_at < $1;
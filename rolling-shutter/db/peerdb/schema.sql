@@ -0,0 +1,12 @@
+-- node_attestation contains the most recent identity attestation received from each node on the
+-- p2p network, so that operators can see which node runs which software. peer_id is the libp2p
+-- peer the attestation was published by, so that messages can be sent to the node directly.
+CREATE TABLE node_attestation (
+       address text PRIMARY KEY,
+       role text NOT NULL,
+       version text NOT NULL,
+       protocol_versions bigint[] NOT NULL,
+       peer_id text NOT NULL DEFAULT '',
+       attested_at timestamptz NOT NULL,
+       received_at timestamptz NOT NULL DEFAULT now()
+);
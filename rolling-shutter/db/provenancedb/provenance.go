@@ -0,0 +1,14 @@
+// Package provenancedb contains the sqlc generated files for the provenance of p2p messages, which
+// records the peer every accepted message came from and when it was received.
+package provenancedb
+
+import (
+	"context"
+	"time"
+)
+
+// Prune deletes all records of messages received before the given retention period and returns
+// the number of deleted records.
+func (q *Queries) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	return q.DeleteMessageProvenanceBefore(ctx, time.Now().Add(-retention))
+}
@@ -0,0 +1,16 @@
+-- name: InsertMessageProvenance :exec
+INSERT INTO message_provenance (received_at, origin, received_from, message_type, epochs, message_hash, details)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: FindMessageProvenance :many
+SELECT * FROM message_provenance
+WHERE received_at >= @since AND received_at < @until
+AND (@peer::text = '' OR origin = @peer OR received_from = @peer)
+AND (@message_type::text = '' OR message_type = @message_type)
+AND (length(@epoch_id::bytea) = 0 OR epochs @> ARRAY[@epoch_id::bytea])
+AND (length(@message_hash::bytea) = 0 OR message_hash = @message_hash)
+ORDER BY id
+LIMIT @max_entries;
+
+-- name: DeleteMessageProvenanceBefore :execrows
+DELETE FROM message_provenance WHERE received_at < $1;
@@ -0,0 +1,18 @@
+-- message_provenance records for every p2p message a node accepted where it came from, so that
+-- data found in the other tables can be traced back to the peer that delivered it. origin is the
+-- peer that published the message and received_from the one we received it from, which differ for
+-- relayed gossip. epochs are the epoch ids the message concerns, if any. message_hash is the same
+-- hash of the message as the trigger_hash of the audit log.
+CREATE TABLE message_provenance (
+       id bigserial PRIMARY KEY,
+       received_at timestamptz NOT NULL,
+       origin text NOT NULL,
+       received_from text NOT NULL,
+       message_type text NOT NULL,
+       epochs bytea[] NOT NULL,
+       message_hash bytea NOT NULL,
+       details text NOT NULL
+);
+CREATE INDEX message_provenance_received_at_idx ON message_provenance (received_at);
+CREATE INDEX message_provenance_epochs_idx ON message_provenance USING GIN (epochs);
+CREATE INDEX message_provenance_message_hash_idx ON message_provenance (message_hash);
@@ -0,0 +1,245 @@
+// Package replication sets up the Postgres publications downstream systems subscribe to, so that
+// they can consume the state of a node via logical replication instead of its APIs.
+//
+// Publications need wal_level = logical on the server and are created by the operator with the
+// replication command, since that requires privileges nodes don't need otherwise. Once set up, the
+// tables of a publication are part of the interface of the node: renaming them or their columns
+// breaks the subscribers.
+package replication
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Identity is the replica identity of a table, which determines the columns of the old row sent
+// with updates and deletes.
+type Identity string
+
+const (
+	// IdentityDefault sends the primary key of the old row. New rows are always sent in full, so
+	// this is enough for tables whose primary key is never updated.
+	IdentityDefault Identity = "DEFAULT"
+	// IdentityFull sends the whole old row, for tables without a primary key or consumers that
+	// need the previous values of updated rows.
+	IdentityFull Identity = "FULL"
+	// IdentityNothing sends nothing for the old row. Postgres then rejects updates and deletes of
+	// published tables, so it is never set by Setup.
+	IdentityNothing Identity = "NOTHING"
+	// IdentityIndex sends the columns of an index. It is not set by Setup either.
+	IdentityIndex Identity = "INDEX"
+)
+
+// identityFromRelReplIdent converts the replica identity stored in pg_class.relreplident.
+func identityFromRelReplIdent(c string) Identity {
+	switch c {
+	case "d":
+		return IdentityDefault
+	case "f":
+		return IdentityFull
+	case "n":
+		return IdentityNothing
+	case "i":
+		return IdentityIndex
+	default:
+		return Identity(c)
+	}
+}
+
+// Table is a table of a publication.
+type Table struct {
+	Name     string
+	Identity Identity
+}
+
+// Publication describes a publication of tables for logical replication.
+type Publication struct {
+	Name   string
+	Tables []Table
+}
+
+func (p *Publication) tableList() string {
+	names := make([]string, len(p.Tables))
+	for i, table := range p.Tables {
+		names[i] = pgx.Identifier{table.Name}.Sanitize()
+	}
+	return strings.Join(names, ", ")
+}
+
+func (p *Publication) tableNames() []string {
+	names := make([]string, len(p.Tables))
+	for i, table := range p.Tables {
+		names[i] = table.Name
+	}
+	return names
+}
+
+// Setup sets the replica identities of the tables and creates the publication. If the
+// publication exists already, its tables are replaced, so Setup can be run again after
+// upgrading the node.
+func (p *Publication) Setup(ctx context.Context, dbpool *pgxpool.Pool) error {
+	walLevel, err := getWALLevel(ctx, dbpool)
+	if err != nil {
+		return err
+	}
+	if walLevel != "logical" {
+		log.Warn().
+			Str("wal-level", walLevel).
+			Msg("wal_level must be set to logical for subscribers to receive changes")
+	}
+	name := pgx.Identifier{p.Name}.Sanitize()
+	return dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		for _, table := range p.Tables {
+			if table.Identity != IdentityDefault && table.Identity != IdentityFull {
+				return errors.Errorf("unsupported replica identity %q of table %s", table.Identity, table.Name)
+			}
+			_, err := tx.Exec(ctx, "ALTER TABLE "+pgx.Identifier{table.Name}.Sanitize()+
+				" REPLICA IDENTITY "+string(table.Identity))
+			if err != nil {
+				return errors.Wrapf(err, "failed to set replica identity of table %s", table.Name)
+			}
+		}
+		exists, err := publicationExists(ctx, tx, p.Name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			_, err = tx.Exec(ctx, "ALTER PUBLICATION "+name+" SET TABLE "+p.tableList())
+		} else {
+			_, err = tx.Exec(ctx, "CREATE PUBLICATION "+name+" FOR TABLE "+p.tableList())
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to set up publication %s", p.Name)
+		}
+		log.Info().Str("publication", p.Name).Strs("tables", p.tableNames()).Msg("publication set up")
+		return nil
+	})
+}
+
+// Drop drops the publication. The replica identities of the tables are kept.
+func (p *Publication) Drop(ctx context.Context, dbpool *pgxpool.Pool) error {
+	_, err := dbpool.Exec(ctx, "DROP PUBLICATION IF EXISTS "+pgx.Identifier{p.Name}.Sanitize())
+	if err != nil {
+		return errors.Wrapf(err, "failed to drop publication %s", p.Name)
+	}
+	log.Info().Str("publication", p.Name).Msg("publication dropped")
+	return nil
+}
+
+// TableStatus compares a table of the publication with its state in the database.
+type TableStatus struct {
+	Name             string   `json:"name"`
+	Published        bool     `json:"published"`
+	Identity         Identity `json:"identity"`
+	ExpectedIdentity Identity `json:"expectedIdentity"`
+}
+
+// Status is the state of a publication in the database.
+type Status struct {
+	Publication string        `json:"publication"`
+	Exists      bool          `json:"exists"`
+	WALLevel    string        `json:"walLevel"`
+	Tables      []TableStatus `json:"tables"`
+}
+
+// OK checks that the publication is set up as described and subscribers can receive changes.
+func (s *Status) OK() bool {
+	if !s.Exists || s.WALLevel != "logical" {
+		return false
+	}
+	for _, table := range s.Tables {
+		if !table.Published || table.Identity != table.ExpectedIdentity {
+			return false
+		}
+	}
+	return true
+}
+
+// Status returns the state of the publication in the database.
+func (p *Publication) Status(ctx context.Context, dbpool *pgxpool.Pool) (*Status, error) {
+	status := &Status{Publication: p.Name}
+	var err error
+	status.WALLevel, err = getWALLevel(ctx, dbpool)
+	if err != nil {
+		return nil, err
+	}
+	status.Exists, err = publicationExists(ctx, dbpool, p.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	published := map[string]bool{}
+	rows, err := dbpool.Query(ctx,
+		"SELECT tablename FROM pg_publication_tables WHERE pubname = $1 AND schemaname = current_schema()",
+		p.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get tables of publication %s", p.Name)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		published[table] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	identities := map[string]Identity{}
+	rows, err = dbpool.Query(ctx, `
+SELECT c.relname, c.relreplident::text FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = current_schema() AND c.relname = ANY($1)`, p.tableNames())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get replica identities")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, replident string
+		if err := rows.Scan(&table, &replident); err != nil {
+			return nil, err
+		}
+		identities[table] = identityFromRelReplIdent(replident)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, table := range p.Tables {
+		status.Tables = append(status.Tables, TableStatus{
+			Name:             table.Name,
+			Published:        published[table.Name],
+			Identity:         identities[table.Name],
+			ExpectedIdentity: table.Identity,
+		})
+	}
+	return status, nil
+}
+
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+func getWALLevel(ctx context.Context, db queryRower) (string, error) {
+	var walLevel string
+	if err := db.QueryRow(ctx, "SHOW wal_level").Scan(&walLevel); err != nil {
+		return "", errors.Wrap(err, "failed to get wal_level")
+	}
+	return walLevel, nil
+}
+
+func publicationExists(ctx context.Context, db queryRower, name string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_publication WHERE pubname = $1)", name).Scan(&exists)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to look up publication %s", name)
+	}
+	return exists, nil
+}
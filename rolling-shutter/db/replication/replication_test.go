@@ -0,0 +1,67 @@
+package replication_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/replication"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+)
+
+func TestPublishedTablesExist(t *testing.T) {
+	for _, tc := range []struct {
+		publication *replication.Publication
+		schemas     []string
+	}{
+		{kprdb.Publication, []string{"kprdb", "chainobsdb"}},
+		{cltrdb.Publication, []string{"cltrdb", "chainobsdb"}},
+	} {
+		for _, table := range tc.publication.Tables {
+			pattern := regexp.MustCompile(`(?m)^CREATE TABLE ` + table.Name + `\s*\(`)
+			found := false
+			for _, schema := range tc.schemas {
+				found = found || pattern.MatchString(db.GetSchema(schema))
+			}
+			assert.Assert(t, found, "table %s of publication %s not found", table.Name, tc.publication.Name)
+		}
+	}
+}
+
+func TestPublicationIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+	p := kprdb.Publication
+	// publications are not dropped when the test db is reset
+	defer func() { _ = p.Drop(ctx, dbpool) }()
+
+	status, err := p.Status(ctx, dbpool)
+	assert.NilError(t, err)
+	assert.Assert(t, !status.Exists)
+	assert.Assert(t, !status.OK())
+
+	assert.NilError(t, p.Setup(ctx, dbpool))
+	// setting up again updates the existing publication
+	assert.NilError(t, p.Setup(ctx, dbpool))
+	status, err = p.Status(ctx, dbpool)
+	assert.NilError(t, err)
+	assert.Assert(t, status.Exists)
+	for _, table := range status.Tables {
+		assert.Assert(t, table.Published, table.Name)
+		assert.Equal(t, table.Identity, table.ExpectedIdentity, table.Name)
+	}
+
+	assert.NilError(t, p.Drop(ctx, dbpool))
+	status, err = p.Status(ctx, dbpool)
+	assert.NilError(t, err)
+	assert.Assert(t, !status.Exists)
+}
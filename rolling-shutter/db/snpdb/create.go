@@ -17,7 +17,7 @@ import (
 var schemaVersion = db.MustFindSchemaVersion("snpdb")
 
 func initSnapshotDB(ctx context.Context, tx pgx.Tx) error {
-	err := db.Create(ctx, tx, []string{"snpdb", "chainobsdb", "metadb"})
+	err := db.Create(ctx, tx, []string{"snpdb", "chainobsdb", "metadb", "paramdb"})
 	if err != nil {
 		return err
 	}
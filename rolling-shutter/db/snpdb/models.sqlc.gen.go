@@ -4,7 +4,10 @@
 
 package snpdb
 
-import ()
+import (
+	"database/sql"
+	"time"
+)
 
 type DecryptionKey struct {
 	EpochID []byte
@@ -15,3 +18,12 @@ type EonPublicKey struct {
 	EonID        int64
 	EonPublicKey []byte
 }
+
+type Proposal struct {
+	ProposalID  string
+	EpochID     []byte
+	Space       string
+	CreatedAt   time.Time
+	EndTime     time.Time
+	TriggeredAt sql.NullTime
+}
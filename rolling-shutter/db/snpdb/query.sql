@@ -42,3 +42,36 @@ LIMIT 1;
 -- name: GetEonCount :one
 SELECT COUNT(DISTINCT eon_id)
 FROM eon_public_key;
+
+-- name: UpsertProposal :exec
+INSERT INTO proposal (
+        proposal_id,
+        epoch_id,
+        space,
+        created_at,
+        end_time
+) VALUES (
+        $1, $2, $3, $4, $5
+)
+ON CONFLICT (proposal_id) DO UPDATE
+SET end_time = EXCLUDED.end_time;
+
+-- name: GetProposal :one
+SELECT *
+FROM proposal
+WHERE proposal_id = $1;
+
+-- name: GetLatestProposalCreatedAt :one
+SELECT COALESCE(MAX(created_at), 'epoch'::timestamptz)::timestamptz AS created_at
+FROM proposal;
+
+-- name: GetEndedProposalsToTrigger :many
+SELECT *
+FROM proposal
+WHERE triggered_at IS NULL AND end_time <= @now
+ORDER BY end_time;
+
+-- name: SetProposalTriggered :exec
+UPDATE proposal
+SET triggered_at = @triggered_at
+WHERE proposal_id = @proposal_id;
@@ -7,6 +7,8 @@ package snpdb
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const getDecryptionKey = `-- name: GetDecryptionKey :one
@@ -34,6 +36,40 @@ func (q *Queries) GetDecryptionKeyCount(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const getEndedProposalsToTrigger = `-- name: GetEndedProposalsToTrigger :many
+SELECT proposal_id, epoch_id, space, created_at, end_time, triggered_at
+FROM proposal
+WHERE triggered_at IS NULL AND end_time <= $1
+ORDER BY end_time
+`
+
+func (q *Queries) GetEndedProposalsToTrigger(ctx context.Context, now time.Time) ([]Proposal, error) {
+	rows, err := q.db.Query(ctx, getEndedProposalsToTrigger, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Proposal
+	for rows.Next() {
+		var i Proposal
+		if err := rows.Scan(
+			&i.ProposalID,
+			&i.EpochID,
+			&i.Space,
+			&i.CreatedAt,
+			&i.EndTime,
+			&i.TriggeredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getEonCount = `-- name: GetEonCount :one
 SELECT COUNT(DISTINCT eon_id)
 FROM eon_public_key
@@ -73,6 +109,38 @@ func (q *Queries) GetEonPublicKeyLatest(ctx context.Context) (EonPublicKey, erro
 	return i, err
 }
 
+const getLatestProposalCreatedAt = `-- name: GetLatestProposalCreatedAt :one
+SELECT COALESCE(MAX(created_at), 'epoch'::timestamptz)::timestamptz AS created_at
+FROM proposal
+`
+
+func (q *Queries) GetLatestProposalCreatedAt(ctx context.Context) (time.Time, error) {
+	row := q.db.QueryRow(ctx, getLatestProposalCreatedAt)
+	var created_at time.Time
+	err := row.Scan(&created_at)
+	return created_at, err
+}
+
+const getProposal = `-- name: GetProposal :one
+SELECT proposal_id, epoch_id, space, created_at, end_time, triggered_at
+FROM proposal
+WHERE proposal_id = $1
+`
+
+func (q *Queries) GetProposal(ctx context.Context, proposalID string) (Proposal, error) {
+	row := q.db.QueryRow(ctx, getProposal, proposalID)
+	var i Proposal
+	err := row.Scan(
+		&i.ProposalID,
+		&i.EpochID,
+		&i.Space,
+		&i.CreatedAt,
+		&i.EndTime,
+		&i.TriggeredAt,
+	)
+	return i, err
+}
+
 const insertDecryptionKey = `-- name: InsertDecryptionKey :execrows
 INSERT INTO decryption_key (
         epoch_id,
@@ -118,3 +186,52 @@ func (q *Queries) InsertEonPublicKey(ctx context.Context, arg InsertEonPublicKey
 	}
 	return result.RowsAffected(), nil
 }
+
+const setProposalTriggered = `-- name: SetProposalTriggered :exec
+UPDATE proposal
+SET triggered_at = $1
+WHERE proposal_id = $2
+`
+
+type SetProposalTriggeredParams struct {
+	TriggeredAt sql.NullTime
+	ProposalID  string
+}
+
+func (q *Queries) SetProposalTriggered(ctx context.Context, arg SetProposalTriggeredParams) error {
+	_, err := q.db.Exec(ctx, setProposalTriggered, arg.TriggeredAt, arg.ProposalID)
+	return err
+}
+
+const upsertProposal = `-- name: UpsertProposal :exec
+INSERT INTO proposal (
+        proposal_id,
+        epoch_id,
+        space,
+        created_at,
+        end_time
+) VALUES (
+        $1, $2, $3, $4, $5
+)
+ON CONFLICT (proposal_id) DO UPDATE
+SET end_time = EXCLUDED.end_time
+`
+
+type UpsertProposalParams struct {
+	ProposalID string
+	EpochID    []byte
+	Space      string
+	CreatedAt  time.Time
+	EndTime    time.Time
+}
+
+func (q *Queries) UpsertProposal(ctx context.Context, arg UpsertProposalParams) error {
+	_, err := q.db.Exec(ctx, upsertProposal,
+		arg.ProposalID,
+		arg.EpochID,
+		arg.Space,
+		arg.CreatedAt,
+		arg.EndTime,
+	)
+	return err
+}
@@ -1,4 +1,4 @@
--- schema-version: snapshot-1 --
+-- schema-version: snapshot-12 --
 -- Please change the version above if you make incompatible changes to
 -- the schema. We'll use this to check we're using the right schema.
 
@@ -11,3 +11,15 @@ CREATE TABLE IF NOT EXISTS eon_public_key (
         eon_id bigint PRIMARY KEY,
         eon_public_key bytea
 );
+-- proposal contains the Shutter-enabled proposals synced from Snapshot Hub. The decryption key of
+-- a proposal is the one of the epoch epoch_id. triggered_at is set once key generation has been
+-- triggered after the proposal ended.
+CREATE TABLE IF NOT EXISTS proposal (
+        proposal_id text PRIMARY KEY,
+        epoch_id bytea NOT NULL UNIQUE,
+        space text NOT NULL,
+        created_at timestamptz NOT NULL,
+        end_time timestamptz NOT NULL,
+        triggered_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS proposal_end_time_idx ON proposal (end_time) WHERE triggered_at IS NULL;
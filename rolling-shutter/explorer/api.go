@@ -0,0 +1,369 @@
+package explorer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/annotation"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+)
+
+// DKG states of an eon.
+const (
+	DKGPending   = "pending"
+	DKGSucceeded = "succeeded"
+	DKGFailed    = "failed"
+)
+
+type Eon struct {
+	Eon                   int64         `json:"eon"`
+	Height                int64         `json:"height"`
+	ActivationBlockNumber int64         `json:"activationBlockNumber"`
+	KeyperConfigIndex     int64         `json:"keyperConfigIndex"`
+	DKG                   string        `json:"dkg"`
+	DKGError              string        `json:"dkgError,omitempty"`
+	EonPublicKey          hexutil.Bytes `json:"eonPublicKey,omitempty"`
+	// Annotations are the notes and labels operators attached to the eon.
+	Annotations []annotation.Annotation `json:"annotations,omitempty"`
+}
+
+// EonDetails extends Eon by the keyper set that ran the DKG and the eon public key candidates
+// together with the keypers that voted for them.
+type EonDetails struct {
+	Eon
+	KeyperSet  *KeyperSet           `json:"keyperSet,omitempty"`
+	Candidates []EonPublicKeyStatus `json:"candidates"`
+}
+
+type EonPublicKeyStatus struct {
+	Hash         hexutil.Bytes `json:"hash"`
+	EonPublicKey hexutil.Bytes `json:"eonPublicKey"`
+	Confirmed    bool          `json:"confirmed"`
+	Votes        []string      `json:"votes"`
+}
+
+type KeyperSet struct {
+	KeyperConfigIndex     int64    `json:"keyperConfigIndex"`
+	ActivationBlockNumber int64    `json:"activationBlockNumber"`
+	Threshold             int32    `json:"threshold"`
+	Keypers               []string `json:"keypers"`
+	// Annotations are the notes and labels operators attached to the keypers, by address.
+	Annotations map[string][]annotation.Annotation `json:"annotations,omitempty"`
+}
+
+type Epoch struct {
+	Eon           int64         `json:"eon"`
+	EpochID       hexutil.Bytes `json:"epochID"`
+	FinalizedAt   time.Time     `json:"finalizedAt"`
+	DecryptionKey hexutil.Bytes `json:"decryptionKey,omitempty"`
+	// Annotations are the notes and labels operators attached to the epoch.
+	Annotations []annotation.Annotation `json:"annotations,omitempty"`
+}
+
+// EpochPage is a page of epochs. Next is the cursor of the following page and empty on the
+// last page.
+type EpochPage struct {
+	Epochs []Epoch `json:"epochs"`
+	Next   string  `json:"next,omitempty"`
+}
+
+type errorResponse struct {
+	Code      int    `json:"code"`
+	ErrorCode string `json:"errorCode"`
+	Message   string `json:"message"`
+}
+
+// sendError responds with the HTTP status and error code of err's class, see errcode.Of.
+func sendError(w http.ResponseWriter, err error) {
+	class := errcode.Of(err)
+	logEvent := log.Debug()
+	if class.HTTPStatus >= http.StatusInternalServerError {
+		logEvent = log.Warn()
+	}
+	logEvent.Err(err).Str("error-code", string(class.Code)).Msg("explorer request failed")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(class.HTTPStatus)
+	_ = json.NewEncoder(w).Encode(errorResponse{
+		Code:      class.HTTPStatus,
+		ErrorCode: string(class.Code),
+		Message:   err.Error(),
+	})
+}
+
+func sendJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (e *Explorer) GetEons(w http.ResponseWriter, r *http.Request) {
+	eons, err := e.eons(r.Context())
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	sendJSON(w, eons)
+}
+
+func (e *Explorer) GetEon(w http.ResponseWriter, r *http.Request) {
+	eon, err := strconv.ParseInt(chi.URLParam(r, "eon"), 10, 64)
+	if err != nil {
+		sendError(w, errcode.ErrInvalidRequest.Wrapf(err, "invalid eon"))
+		return
+	}
+	details, err := e.eonDetails(r.Context(), eon)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	sendJSON(w, details)
+}
+
+func (e *Explorer) GetKeyperSets(w http.ResponseWriter, r *http.Request) {
+	keyperSets, err := e.keyperSets(r.Context())
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	sendJSON(w, keyperSets)
+}
+
+// GetEpochs lists the most recently finalized epochs, newest first. The number of epochs can be
+// lowered with the limit query parameter. If there may be more epochs, the response contains a
+// cursor that is passed as the after query parameter to fetch the next page.
+func (e *Explorer) GetEpochs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := e.config.MaxEpochs
+	if s := query.Get("limit"); s != "" {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil || n == 0 {
+			sendError(w, errcode.ErrInvalidRequest.Errorf("limit must be a positive integer"))
+			return
+		}
+		if n < limit {
+			limit = n
+		}
+	}
+	var after *epochCursor
+	if s := query.Get("after"); s != "" {
+		cursor, err := decodeEpochCursor(s)
+		if err != nil {
+			sendError(w, errcode.ErrInvalidRequest.Wrap(err))
+			return
+		}
+		after = &cursor
+	}
+	page, err := e.epochs(r.Context(), limit, after)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	sendJSON(w, page)
+}
+
+func (e *Explorer) eons(ctx context.Context) ([]Eon, error) {
+	rows, err := kprdb.New(e.dbpool).GetAllEons(ctx)
+	if err != nil {
+		return nil, errcode.WrapDB(err, "failed to query eons")
+	}
+	annotations, err := e.loadAnnotations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	eons := []Eon{}
+	for _, row := range rows {
+		eon, err := e.eon(ctx, row, annotations)
+		if err != nil {
+			return nil, err
+		}
+		eons = append(eons, eon)
+	}
+	return eons, nil
+}
+
+func (e *Explorer) eon(ctx context.Context, row kprdb.Eon, annotations annotation.Index) (Eon, error) {
+	db := kprdb.New(e.dbpool)
+	eon := Eon{
+		Eon:                   row.Eon,
+		Height:                row.Height,
+		ActivationBlockNumber: row.ActivationBlockNumber,
+		KeyperConfigIndex:     row.KeyperConfigIndex,
+		DKG:                   DKGPending,
+		Annotations:           annotations.Eon(row.Eon),
+	}
+	dkgResult, err := db.GetDKGResult(ctx, row.Eon)
+	switch {
+	case err == pgx.ErrNoRows:
+	case err != nil:
+		return Eon{}, errcode.WrapDB(err, "failed to query DKG result of eon %d", row.Eon)
+	case dkgResult.Success:
+		eon.DKG = DKGSucceeded
+	default:
+		eon.DKG = DKGFailed
+		eon.DKGError = dkgResult.Error.String
+	}
+	candidate, err := db.GetConfirmedEonPublicKey(ctx, row.Eon)
+	if err == nil {
+		eon.EonPublicKey = candidate.EonPublicKey
+	} else if err != pgx.ErrNoRows {
+		return Eon{}, errcode.WrapDB(err, "failed to query eon public key of eon %d", row.Eon)
+	}
+	return eon, nil
+}
+
+func (e *Explorer) eonDetails(ctx context.Context, eonIndex int64) (*EonDetails, error) {
+	db := kprdb.New(e.dbpool)
+	row, err := db.GetEon(ctx, eonIndex)
+	if err == pgx.ErrNoRows {
+		return nil, errcode.ErrEonUnknown.Errorf("eon %d is unknown", eonIndex)
+	} else if err != nil {
+		return nil, errcode.WrapDB(err, "failed to query eon %d", eonIndex)
+	}
+	annotations, err := e.loadAnnotations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	eon, err := e.eon(ctx, row, annotations)
+	if err != nil {
+		return nil, err
+	}
+	details := &EonDetails{Eon: eon}
+	details.KeyperSet, err = e.keyperSet(ctx, row.KeyperConfigIndex, annotations)
+	if err != nil {
+		return nil, err
+	}
+	details.Candidates, err = e.candidates(ctx, eonIndex)
+	if err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+// keyperSet returns the keyper set with the given index, or nil if it is unknown.
+func (e *Explorer) keyperSet(
+	ctx context.Context, keyperConfigIndex int64, annotations annotation.Index,
+) (*KeyperSet, error) {
+	keyperSet, err := chainobsdb.New(e.dbpool).GetKeyperSetByKeyperConfigIndex(ctx, keyperConfigIndex)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errcode.WrapDB(err, "failed to query keyper set %d", keyperConfigIndex)
+	}
+	return newKeyperSet(keyperSet, annotations), nil
+}
+
+// candidates returns the eon public key candidates of the eon together with the keypers that
+// voted for them.
+func (e *Explorer) candidates(ctx context.Context, eonIndex int64) ([]EonPublicKeyStatus, error) {
+	db := kprdb.New(e.dbpool)
+	candidates, err := db.GetEonPublicKeyCandidates(ctx, eonIndex)
+	if err != nil {
+		return nil, errcode.WrapDB(err, "failed to query eon public key candidates of eon %d", eonIndex)
+	}
+	statuses := []EonPublicKeyStatus{}
+	for _, candidate := range candidates {
+		votes, err := db.FindEonPublicKeyVotes(ctx, candidate.Hash)
+		if err != nil {
+			return nil, errcode.WrapDB(err, "failed to query eon public key votes of eon %d", eonIndex)
+		}
+		status := EonPublicKeyStatus{
+			Hash:         candidate.Hash,
+			EonPublicKey: candidate.EonPublicKey,
+			Confirmed:    candidate.Confirmed,
+			Votes:        []string{},
+		}
+		for _, vote := range votes {
+			status.Votes = append(status.Votes, vote.Sender)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func newKeyperSet(row chainobsdb.KeyperSet, annotations annotation.Index) *KeyperSet {
+	keyperSet := &KeyperSet{
+		KeyperConfigIndex:     row.KeyperConfigIndex,
+		ActivationBlockNumber: row.ActivationBlockNumber,
+		Threshold:             row.Threshold,
+		Keypers:               row.Keypers,
+	}
+	for _, keyper := range row.Keypers {
+		if a := annotations.Keyper(keyper); len(a) > 0 {
+			if keyperSet.Annotations == nil {
+				keyperSet.Annotations = map[string][]annotation.Annotation{}
+			}
+			keyperSet.Annotations[keyper] = a
+		}
+	}
+	return keyperSet
+}
+
+func (e *Explorer) keyperSets(ctx context.Context) ([]*KeyperSet, error) {
+	rows, err := chainobsdb.New(e.dbpool).GetKeyperSets(ctx)
+	if err != nil {
+		return nil, errcode.WrapDB(err, "failed to query keyper sets")
+	}
+	annotations, err := e.loadAnnotations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keyperSets := []*KeyperSet{}
+	for _, row := range rows {
+		keyperSets = append(keyperSets, newKeyperSet(row, annotations))
+	}
+	return keyperSets, nil
+}
+
+func (e *Explorer) epochs(ctx context.Context, limit uint64, after *epochCursor) (*EpochPage, error) {
+	db := kprdb.New(e.dbpool)
+	var (
+		rows []kprdb.GetRecentFinalizedEpochsRow
+		err  error
+	)
+	if after == nil {
+		rows, err = db.GetRecentFinalizedEpochs(ctx, int32(limit))
+	} else {
+		var before []kprdb.GetFinalizedEpochsBeforeRow
+		before, err = db.GetFinalizedEpochsBefore(ctx, kprdb.GetFinalizedEpochsBeforeParams{
+			FinalizedAt: after.FinalizedAt,
+			Eon:         after.Eon,
+			EpochID:     after.EpochID,
+			MaxEpochs:   int32(limit),
+		})
+		for _, row := range before {
+			rows = append(rows, kprdb.GetRecentFinalizedEpochsRow(row))
+		}
+	}
+	if err != nil {
+		return nil, errcode.WrapDB(err, "failed to query finalized epochs")
+	}
+	annotations, err := e.loadAnnotations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page := &EpochPage{Epochs: []Epoch{}}
+	for _, row := range rows {
+		page.Epochs = append(page.Epochs, Epoch{
+			Eon:           row.Eon,
+			EpochID:       row.EpochID,
+			FinalizedAt:   row.FinalizedAt,
+			DecryptionKey: row.DecryptionKey,
+			Annotations:   annotations.Epoch(row.EpochID),
+		})
+	}
+	if uint64(len(rows)) == limit {
+		last := rows[len(rows)-1]
+		page.Next = epochCursor{FinalizedAt: last.FinalizedAt, Eon: last.Eon, EpochID: last.EpochID}.Encode()
+	}
+	return page, nil
+}
@@ -0,0 +1,180 @@
+package explorer
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/annotation"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+)
+
+func get(t *testing.T, handler http.Handler, path string, v interface{}) int {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	if v != nil && recorder.Code == http.StatusOK {
+		assert.NilError(t, json.Unmarshal(recorder.Body.Bytes(), v))
+	}
+	return recorder.Code
+}
+
+func graphQLQuery(t *testing.T, handler http.Handler, query string, v interface{}) {
+	t.Helper()
+	body, err := json.Marshal(map[string]string{"query": query})
+	assert.NilError(t, err)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+	assert.Equal(t, recorder.Code, http.StatusOK)
+	response := struct {
+		Data   json.RawMessage
+		Errors []interface{}
+	}{}
+	assert.NilError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, len(response.Errors), 0, "%v", response.Errors)
+	assert.NilError(t, json.Unmarshal(response.Data, v))
+}
+
+func TestAPIIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	db, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	keypers := []string{"0x1111111111111111111111111111111111111111", "0x2222222222222222222222222222222222222222"}
+	assert.NilError(t, chainobsdb.New(dbpool).InsertKeyperSet(ctx, chainobsdb.InsertKeyperSetParams{
+		KeyperConfigIndex: 1, ActivationBlockNumber: 100, Keypers: keypers, Threshold: 2,
+	}))
+	for eon := int64(1); eon <= 2; eon++ {
+		assert.NilError(t, db.InsertEon(ctx, kprdb.InsertEonParams{
+			Eon: eon, Height: eon, ActivationBlockNumber: 100, KeyperConfigIndex: 1,
+		}))
+	}
+	assert.NilError(t, db.InsertDKGResult(ctx, kprdb.InsertDKGResultParams{Eon: 1, Success: true}))
+	assert.NilError(t, db.InsertDKGResult(ctx, kprdb.InsertDKGResultParams{
+		Eon: 2, Success: false, Error: sql.NullString{String: "too few dealers", Valid: true},
+	}))
+	hash := []byte{0xaa}
+	assert.NilError(t, db.InsertEonPublicKeyCandidate(ctx, kprdb.InsertEonPublicKeyCandidateParams{
+		Hash: hash, EonPublicKey: []byte{1, 2, 3}, ActivationBlockNumber: 100, KeyperConfigIndex: 1, Eon: 1,
+	}))
+	for _, keyper := range keypers {
+		_, err := db.InsertEonPublicKeyVote(ctx, kprdb.InsertEonPublicKeyVoteParams{
+			Hash: hash, Sender: keyper, Signature: []byte{}, Eon: 1, KeyperConfigIndex: 1,
+		})
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, db.ConfirmEonPublicKey(ctx, hash))
+	for _, epochID := range [][]byte{{1}, {2}} {
+		assert.NilError(t, db.InsertFinalizedEpoch(ctx, kprdb.InsertFinalizedEpochParams{Eon: 1, EpochID: epochID}))
+	}
+	_, err := db.InsertDecryptionKey(ctx, kprdb.InsertDecryptionKeyParams{Eon: 1, EpochID: []byte{1}, DecryptionKey: []byte{9}})
+	assert.NilError(t, err)
+	for _, req := range []annotation.Request{
+		{Kind: annotation.KindEon, Entity: "2", Note: "DKG restarted due to outage", Author: "alice"},
+		{Kind: annotation.KindKeyper, Entity: keypers[0], Label: "ours", Author: "alice"},
+	} {
+		_, err = annotation.Add(ctx, dbpool, req)
+		assert.NilError(t, err)
+	}
+
+	config := NewConfig()
+	assert.NilError(t, config.SetDefaultValues())
+	config.GraphQL = true
+	e := &Explorer{config: config, dbpool: dbpool}
+	router := e.setupRouter()
+
+	var eons []Eon
+	assert.Equal(t, get(t, router, "/api/eons", &eons), http.StatusOK)
+	assert.Equal(t, len(eons), 2)
+	assert.Equal(t, eons[0].DKG, DKGSucceeded)
+	assert.DeepEqual(t, []byte(eons[0].EonPublicKey), []byte{1, 2, 3})
+	assert.Equal(t, eons[1].DKG, DKGFailed)
+	assert.Equal(t, eons[1].DKGError, "too few dealers")
+	assert.Equal(t, len(eons[1].EonPublicKey), 0)
+	assert.Equal(t, len(eons[0].Annotations), 0)
+	assert.Equal(t, eons[1].Annotations[0].Note, "DKG restarted due to outage")
+
+	var details EonDetails
+	assert.Equal(t, get(t, router, "/api/eons/1", &details), http.StatusOK)
+	assert.DeepEqual(t, details.KeyperSet.Keypers, keypers)
+	assert.Equal(t, len(details.Candidates), 1)
+	assert.Assert(t, details.Candidates[0].Confirmed)
+	assert.DeepEqual(t, details.Candidates[0].Votes, keypers)
+	assert.Equal(t, get(t, router, "/api/eons/3", nil), http.StatusNotFound)
+	assert.Equal(t, get(t, router, "/api/eons/x", nil), http.StatusBadRequest)
+
+	var keyperSets []KeyperSet
+	assert.Equal(t, get(t, router, "/api/keyper-sets", &keyperSets), http.StatusOK)
+	assert.Equal(t, len(keyperSets), 1)
+	assert.Equal(t, keyperSets[0].Threshold, int32(2))
+	assert.Equal(t, keyperSets[0].Annotations[keypers[0]][0].Label, "ours")
+	assert.Equal(t, len(keyperSets[0].Annotations[keypers[1]]), 0)
+
+	var page EpochPage
+	assert.Equal(t, get(t, router, "/api/epochs", &page), http.StatusOK)
+	assert.Equal(t, len(page.Epochs), 2)
+	assert.Equal(t, page.Next, "")
+
+	epochs := []Epoch{}
+	path := "/api/epochs?limit=1"
+	for {
+		page = EpochPage{}
+		assert.Equal(t, get(t, router, path, &page), http.StatusOK)
+		epochs = append(epochs, page.Epochs...)
+		if page.Next == "" {
+			break
+		}
+		path = "/api/epochs?limit=1&after=" + page.Next
+	}
+	assert.Equal(t, len(epochs), 2)
+	assert.Assert(t, !bytes.Equal(epochs[0].EpochID, epochs[1].EpochID))
+	assert.Equal(t, get(t, router, "/api/epochs?after=x", nil), http.StatusBadRequest)
+	assert.Equal(t, get(t, router, "/api/epochs?limit=0", nil), http.StatusBadRequest)
+
+	assert.Equal(t, get(t, router, "/", nil), http.StatusOK)
+
+	var data struct {
+		Eon struct {
+			Eon       int64
+			KeyperSet struct {
+				Keypers []struct {
+					Address     string
+					Annotations []struct{ Label string }
+				}
+			}
+			Candidates []struct{ Confirmed bool }
+		}
+		Unknown *struct{ Eon int64 }
+		Epochs  struct {
+			Edges    []struct{ Node struct{ EpochID string } }
+			PageInfo struct{ HasNextPage bool }
+		}
+	}
+	graphQLQuery(t, router, `{
+		eon(eon: 1) {
+			eon
+			keyperSet { keypers { address annotations { label } } }
+			candidates { confirmed }
+		}
+		unknown: eon(eon: 3) { eon }
+		epochs(first: 1) { edges { node { epochID } } pageInfo { hasNextPage } }
+	}`, &data)
+	assert.Equal(t, data.Eon.Eon, int64(1))
+	assert.Equal(t, data.Eon.KeyperSet.Keypers[0].Address, keypers[0])
+	assert.Equal(t, data.Eon.KeyperSet.Keypers[0].Annotations[0].Label, "ours")
+	assert.Assert(t, data.Eon.Candidates[0].Confirmed)
+	assert.Assert(t, data.Unknown == nil)
+	assert.Equal(t, len(data.Epochs.Edges), 1)
+	assert.Assert(t, data.Epochs.PageInfo.HasNextPage)
+}
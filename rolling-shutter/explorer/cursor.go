@@ -0,0 +1,51 @@
+package explorer
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// epochCursor points at the last epoch of a page. The next page continues with the epochs
+// finalized before it. Epochs are ordered by the time they were finalized and then by eon and
+// epoch id, which is unique, so that no epoch is skipped or listed twice when paging.
+type epochCursor struct {
+	FinalizedAt time.Time
+	Eon         int64
+	EpochID     []byte
+}
+
+// Encode returns the cursor in the opaque form handed out to clients.
+func (c epochCursor) Encode() string {
+	s := fmt.Sprintf("%d:%d:%s", c.FinalizedAt.UnixMicro(), c.Eon, hex.EncodeToString(c.EpochID))
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func decodeEpochCursor(s string) (epochCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return epochCursor{}, errors.Wrap(err, "malformed cursor")
+	}
+	parts := strings.Split(string(b), ":")
+	if len(parts) != 3 {
+		return epochCursor{}, errors.New("malformed cursor")
+	}
+	micros, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return epochCursor{}, errors.Wrap(err, "malformed cursor")
+	}
+	eon, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return epochCursor{}, errors.Wrap(err, "malformed cursor")
+	}
+	epochID, err := hex.DecodeString(parts[2])
+	if err != nil {
+		return epochCursor{}, errors.Wrap(err, "malformed cursor")
+	}
+	return epochCursor{FinalizedAt: time.UnixMicro(micros), Eon: eon, EpochID: epochID}, nil
+}
@@ -0,0 +1,26 @@
+package explorer
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestEpochCursorRoundtrip(t *testing.T) {
+	cursor := epochCursor{
+		FinalizedAt: time.UnixMicro(1700000000123456),
+		Eon:         7,
+		EpochID:     []byte{0, 1, 2, 0xff},
+	}
+	decoded, err := decodeEpochCursor(cursor.Encode())
+	assert.NilError(t, err)
+	assert.Assert(t, decoded.FinalizedAt.Equal(cursor.FinalizedAt))
+	assert.Equal(t, decoded.Eon, cursor.Eon)
+	assert.DeepEqual(t, decoded.EpochID, cursor.EpochID)
+
+	for _, s := range []string{"", "!", "MTox", "YToxOjAw"} {
+		_, err := decodeEpochCursor(s)
+		assert.ErrorContains(t, err, "malformed cursor", s)
+	}
+}
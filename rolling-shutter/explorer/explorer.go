@@ -0,0 +1,93 @@
+// Package explorer serves a read-only web UI and JSON API over the database of a keyper. It lets
+// operators publish the eons, keyper sets, DKG outcomes, eon key confirmations and decryption keys
+// their node has seen without granting access to the database itself.
+package explorer
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+type Explorer struct {
+	config *Config
+	dbpool *pgxpool.Pool
+}
+
+func New(config *Config) *Explorer {
+	return &Explorer{config: config}
+}
+
+// connect opens a connection pool whose transactions are read-only, so that the explorer can't
+// modify the database even if the role it connects as could.
+func connect(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid database url")
+	}
+	poolConfig.ConnConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	dbpool, err := pgxpool.ConnectConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to database")
+	}
+	return dbpool, nil
+}
+
+func (e *Explorer) Start(ctx context.Context, runner service.Runner) error {
+	dbpool, err := connect(ctx, e.config.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	runner.Defer(dbpool.Close)
+	shdb.AddConnectionInfo(log.Info(), dbpool).Msg("connected to database")
+	if err := kprdb.ValidateKeyperDB(ctx, dbpool); err != nil {
+		return err
+	}
+	e.dbpool = dbpool
+
+	httpServer := &http.Server{
+		Addr:              e.config.HTTPListenAddress,
+		Handler:           e.setupRouter(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	runner.Go(func() error {
+		log.Info().Str("address", e.config.HTTPListenAddress).Msg("running explorer")
+		if err := e.config.HTTPTLS.ListenAndServe(httpServer); err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	})
+	runner.Go(func() error {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	})
+	return nil
+}
+
+func (e *Explorer) setupRouter() *chi.Mux {
+	router := chi.NewRouter()
+	router.Use(middleware.Recoverer)
+	router.Get("/", e.Index)
+	router.Route("/api", func(r chi.Router) {
+		r.Get("/eons", e.GetEons)
+		r.Get("/eons/{eon}", e.GetEon)
+		r.Get("/keyper-sets", e.GetKeyperSets)
+		r.Get("/epochs", e.GetEpochs)
+	})
+	if e.config.GraphQL {
+		router.Handle("/graphql", e.GraphQLHandler())
+	}
+	return router
+}
@@ -0,0 +1,427 @@
+package explorer
+
+import (
+	"context"
+	_ "embed"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/graph-gophers/graphql-go/trace"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/annotation"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+)
+
+//go:embed schema.graphql
+var graphqlSchema string
+
+const (
+	// graphqlMaxDepth is the maximum nesting depth of fields in a query. It leaves room for the
+	// nested ofType fields of the introspection queries of common GraphQL clients.
+	graphqlMaxDepth = 12
+	// graphqlMaxCost is the maximum cost of a query, where each resolver querying the db costs
+	// one unit.
+	graphqlMaxCost = 1000
+)
+
+// GraphQLHandler returns the handler of the GraphQL API, see schema.graphql.
+func (e *Explorer) GraphQLHandler() http.Handler {
+	return &relay.Handler{Schema: e.graphQLSchema()}
+}
+
+func (e *Explorer) graphQLSchema() *graphql.Schema {
+	return graphql.MustParseSchema(
+		graphqlSchema,
+		&queryResolver{e: e},
+		graphql.MaxDepth(graphqlMaxDepth),
+		graphql.Tracer(queryTracer{}),
+	)
+}
+
+// queryState is shared by the resolvers of a single query. It accounts for the cost of the query
+// and loads the annotations only once per query instead of once per resolver.
+type queryState struct {
+	mu   sync.Mutex
+	cost int
+
+	annotationsOnce sync.Once
+	annotations     annotation.Index
+	annotationsErr  error
+}
+
+type queryStateKey struct{}
+
+// queryTracer attaches a new queryState to the context of each query.
+type queryTracer struct {
+	trace.OpenTracingTracer
+}
+
+func (t queryTracer) TraceQuery(
+	ctx context.Context,
+	queryString string,
+	operationName string,
+	variables map[string]interface{},
+	varTypes map[string]*introspection.Type,
+) (context.Context, trace.TraceQueryFinishFunc) {
+	ctx = context.WithValue(ctx, queryStateKey{}, &queryState{})
+	return t.OpenTracingTracer.TraceQuery(ctx, queryString, operationName, variables, varTypes)
+}
+
+// chargeQuery adds the cost of a resolver to the cost of the query and fails once the query
+// exceeds graphqlMaxCost.
+func chargeQuery(ctx context.Context, cost int) error {
+	state, ok := ctx.Value(queryStateKey{}).(*queryState)
+	if !ok {
+		return nil
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.cost += cost
+	if state.cost > graphqlMaxCost {
+		return errcode.ErrInvalidRequest.Errorf("query exceeds the maximum cost of %d", graphqlMaxCost)
+	}
+	return nil
+}
+
+// loadAnnotations returns the index of all annotations. Within a GraphQL query, the index is
+// loaded once and shared by all resolvers.
+func (e *Explorer) loadAnnotations(ctx context.Context) (annotation.Index, error) {
+	state, ok := ctx.Value(queryStateKey{}).(*queryState)
+	if !ok {
+		return annotation.Load(ctx, e.dbpool)
+	}
+	state.annotationsOnce.Do(func() {
+		state.annotations, state.annotationsErr = annotation.Load(ctx, e.dbpool)
+	})
+	return state.annotations, state.annotationsErr
+}
+
+// Long is the GraphQL scalar of 64 bit integers.
+type Long int64
+
+func (Long) ImplementsGraphQLType(name string) bool {
+	return name == "Long"
+}
+
+func (l *Long) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case int32:
+		*l = Long(input)
+	case int64:
+		*l = Long(input)
+	case float64:
+		// numbers in JSON encoded variables are decoded as float64
+		if input != math.Trunc(input) || input < math.MinInt64 || input >= math.MaxInt64 {
+			return errors.Errorf("invalid value for Long: %v", input)
+		}
+		*l = Long(input)
+	case string:
+		n, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return err
+		}
+		*l = Long(n)
+	default:
+		return errors.Errorf("wrong type for Long: %T", input)
+	}
+	return nil
+}
+
+func (l Long) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(l), 10), nil
+}
+
+// resolverError exposes the error code of the wrapped error to GraphQL clients, see errcode.Of.
+type resolverError struct {
+	error
+}
+
+func (err resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"errorCode": string(errcode.Of(err.error).Code)}
+}
+
+func wrapResolverError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return resolverError{err}
+}
+
+func optionalHex(b []byte) *string {
+	if len(b) == 0 {
+		return nil
+	}
+	s := hexutil.Encode(b)
+	return &s
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+type queryResolver struct {
+	e *Explorer
+}
+
+func (r *queryResolver) Eons(ctx context.Context) ([]*eonResolver, error) {
+	if err := chargeQuery(ctx, 1); err != nil {
+		return nil, wrapResolverError(err)
+	}
+	eons, err := r.e.eons(ctx)
+	if err != nil {
+		return nil, wrapResolverError(err)
+	}
+	resolvers := []*eonResolver{}
+	for _, eon := range eons {
+		resolvers = append(resolvers, &eonResolver{e: r.e, eon: eon})
+	}
+	return resolvers, nil
+}
+
+func (r *queryResolver) Eon(ctx context.Context, args struct{ Eon Long }) (*eonResolver, error) {
+	if err := chargeQuery(ctx, 1); err != nil {
+		return nil, wrapResolverError(err)
+	}
+	row, err := kprdb.New(r.e.dbpool).GetEon(ctx, int64(args.Eon))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, wrapResolverError(errcode.WrapDB(err, "failed to query eon %d", args.Eon))
+	}
+	annotations, err := r.e.loadAnnotations(ctx)
+	if err != nil {
+		return nil, wrapResolverError(err)
+	}
+	eon, err := r.e.eon(ctx, row, annotations)
+	if err != nil {
+		return nil, wrapResolverError(err)
+	}
+	return &eonResolver{e: r.e, eon: eon}, nil
+}
+
+func (r *queryResolver) KeyperSets(ctx context.Context) ([]*keyperSetResolver, error) {
+	if err := chargeQuery(ctx, 1); err != nil {
+		return nil, wrapResolverError(err)
+	}
+	keyperSets, err := r.e.keyperSets(ctx)
+	if err != nil {
+		return nil, wrapResolverError(err)
+	}
+	resolvers := []*keyperSetResolver{}
+	for _, keyperSet := range keyperSets {
+		resolvers = append(resolvers, &keyperSetResolver{keyperSet})
+	}
+	return resolvers, nil
+}
+
+func (r *queryResolver) Epochs(
+	ctx context.Context, args struct {
+		First *int32
+		After *string
+	},
+) (*epochConnectionResolver, error) {
+	limit := r.e.config.MaxEpochs
+	if args.First != nil {
+		if *args.First <= 0 {
+			return nil, wrapResolverError(errcode.ErrInvalidRequest.Errorf("first must be a positive integer"))
+		}
+		if uint64(*args.First) < limit {
+			limit = uint64(*args.First)
+		}
+	}
+	var after *epochCursor
+	if args.After != nil {
+		cursor, err := decodeEpochCursor(*args.After)
+		if err != nil {
+			return nil, wrapResolverError(errcode.ErrInvalidRequest.Wrap(err))
+		}
+		after = &cursor
+	}
+	if err := chargeQuery(ctx, 1); err != nil {
+		return nil, wrapResolverError(err)
+	}
+	page, err := r.e.epochs(ctx, limit, after)
+	if err != nil {
+		return nil, wrapResolverError(err)
+	}
+	return &epochConnectionResolver{page}, nil
+}
+
+type eonResolver struct {
+	e   *Explorer
+	eon Eon
+}
+
+func (r *eonResolver) Eon() Long                   { return Long(r.eon.Eon) }
+func (r *eonResolver) Height() Long                { return Long(r.eon.Height) }
+func (r *eonResolver) ActivationBlockNumber() Long { return Long(r.eon.ActivationBlockNumber) }
+func (r *eonResolver) KeyperConfigIndex() Long     { return Long(r.eon.KeyperConfigIndex) }
+func (r *eonResolver) DKG() string                 { return r.eon.DKG }
+func (r *eonResolver) DKGError() *string           { return optionalString(r.eon.DKGError) }
+func (r *eonResolver) EonPublicKey() *string       { return optionalHex(r.eon.EonPublicKey) }
+
+func (r *eonResolver) Annotations() []*annotationResolver {
+	return newAnnotationResolvers(r.eon.Annotations)
+}
+
+func (r *eonResolver) KeyperSet(ctx context.Context) (*keyperSetResolver, error) {
+	if err := chargeQuery(ctx, 1); err != nil {
+		return nil, wrapResolverError(err)
+	}
+	annotations, err := r.e.loadAnnotations(ctx)
+	if err != nil {
+		return nil, wrapResolverError(err)
+	}
+	keyperSet, err := r.e.keyperSet(ctx, r.eon.KeyperConfigIndex, annotations)
+	if err != nil {
+		return nil, wrapResolverError(err)
+	}
+	if keyperSet == nil {
+		return nil, nil
+	}
+	return &keyperSetResolver{keyperSet}, nil
+}
+
+func (r *eonResolver) Candidates(ctx context.Context) ([]*candidateResolver, error) {
+	if err := chargeQuery(ctx, 1); err != nil {
+		return nil, wrapResolverError(err)
+	}
+	candidates, err := r.e.candidates(ctx, r.eon.Eon)
+	if err != nil {
+		return nil, wrapResolverError(err)
+	}
+	resolvers := []*candidateResolver{}
+	for _, candidate := range candidates {
+		resolvers = append(resolvers, &candidateResolver{candidate})
+	}
+	return resolvers, nil
+}
+
+type candidateResolver struct {
+	candidate EonPublicKeyStatus
+}
+
+func (r *candidateResolver) Hash() string         { return hexutil.Encode(r.candidate.Hash) }
+func (r *candidateResolver) EonPublicKey() string { return hexutil.Encode(r.candidate.EonPublicKey) }
+func (r *candidateResolver) Confirmed() bool      { return r.candidate.Confirmed }
+func (r *candidateResolver) Votes() []string      { return r.candidate.Votes }
+
+type keyperSetResolver struct {
+	keyperSet *KeyperSet
+}
+
+func (r *keyperSetResolver) KeyperConfigIndex() Long {
+	return Long(r.keyperSet.KeyperConfigIndex)
+}
+
+func (r *keyperSetResolver) ActivationBlockNumber() Long {
+	return Long(r.keyperSet.ActivationBlockNumber)
+}
+
+func (r *keyperSetResolver) Threshold() int32 {
+	return r.keyperSet.Threshold
+}
+
+func (r *keyperSetResolver) Keypers() []*keyperResolver {
+	resolvers := []*keyperResolver{}
+	for _, address := range r.keyperSet.Keypers {
+		resolvers = append(resolvers, &keyperResolver{
+			address:     address,
+			annotations: r.keyperSet.Annotations[address],
+		})
+	}
+	return resolvers
+}
+
+type keyperResolver struct {
+	address     string
+	annotations []annotation.Annotation
+}
+
+func (r *keyperResolver) Address() string { return r.address }
+
+func (r *keyperResolver) Annotations() []*annotationResolver {
+	return newAnnotationResolvers(r.annotations)
+}
+
+type epochConnectionResolver struct {
+	page *EpochPage
+}
+
+func (r *epochConnectionResolver) Edges() []*epochEdgeResolver {
+	resolvers := []*epochEdgeResolver{}
+	for _, epoch := range r.page.Epochs {
+		resolvers = append(resolvers, &epochEdgeResolver{epoch})
+	}
+	return resolvers
+}
+
+func (r *epochConnectionResolver) PageInfo() *pageInfoResolver {
+	return &pageInfoResolver{r.page.Next}
+}
+
+type epochEdgeResolver struct {
+	epoch Epoch
+}
+
+func (r *epochEdgeResolver) Cursor() string {
+	return epochCursor{FinalizedAt: r.epoch.FinalizedAt, Eon: r.epoch.Eon, EpochID: r.epoch.EpochID}.Encode()
+}
+
+func (r *epochEdgeResolver) Node() *epochResolver {
+	return &epochResolver{r.epoch}
+}
+
+type pageInfoResolver struct {
+	next string
+}
+
+func (r *pageInfoResolver) HasNextPage() bool  { return r.next != "" }
+func (r *pageInfoResolver) EndCursor() *string { return optionalString(r.next) }
+
+type epochResolver struct {
+	epoch Epoch
+}
+
+func (r *epochResolver) Eon() Long                 { return Long(r.epoch.Eon) }
+func (r *epochResolver) EpochID() string           { return hexutil.Encode(r.epoch.EpochID) }
+func (r *epochResolver) FinalizedAt() graphql.Time { return graphql.Time{Time: r.epoch.FinalizedAt} }
+func (r *epochResolver) DecryptionKey() *string    { return optionalHex(r.epoch.DecryptionKey) }
+
+func (r *epochResolver) Annotations() []*annotationResolver {
+	return newAnnotationResolvers(r.epoch.Annotations)
+}
+
+type annotationResolver struct {
+	annotation annotation.Annotation
+}
+
+func newAnnotationResolvers(annotations []annotation.Annotation) []*annotationResolver {
+	resolvers := []*annotationResolver{}
+	for _, a := range annotations {
+		resolvers = append(resolvers, &annotationResolver{a})
+	}
+	return resolvers
+}
+
+func (r *annotationResolver) ID() Long       { return Long(r.annotation.ID) }
+func (r *annotationResolver) Kind() string   { return r.annotation.Kind }
+func (r *annotationResolver) Entity() string { return r.annotation.Entity }
+func (r *annotationResolver) Label() *string { return optionalString(r.annotation.Label) }
+func (r *annotationResolver) Note() *string  { return optionalString(r.annotation.Note) }
+func (r *annotationResolver) Author() string { return r.annotation.Author }
+func (r *annotationResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.annotation.CreatedAt}
+}
@@ -0,0 +1,71 @@
+package explorer
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestGraphQLSchema(t *testing.T) {
+	config := NewConfig()
+	assert.NilError(t, config.SetDefaultValues())
+	// parsing checks that the resolvers implement the schema
+	schema := (&Explorer{config: config}).graphQLSchema()
+
+	// invalid arguments are rejected before the database is queried
+	response := schema.Exec(context.Background(), `{ epochs(first: 0) { pageInfo { hasNextPage } } }`, "", nil)
+	assert.Equal(t, len(response.Errors), 1)
+	assert.Equal(t, response.Errors[0].Extensions["errorCode"], "INVALID_REQUEST")
+
+	// so are queries nested too deeply
+	response = schema.Exec(context.Background(), `{
+		__schema { types { fields { type {
+			ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { name } } } } } } } }
+		} } } }
+	}`, "", nil)
+	assert.Assert(t, len(response.Errors) > 0)
+	assert.ErrorContains(t, response.Errors[0], "exceeds max depth")
+}
+
+func TestGraphQLQueryCost(t *testing.T) {
+	ctx, finish := queryTracer{}.TraceQuery(context.Background(), "", "", nil, nil)
+	defer finish(nil)
+	for i := 0; i < graphqlMaxCost; i++ {
+		assert.NilError(t, chargeQuery(ctx, 1))
+	}
+	assert.ErrorContains(t, chargeQuery(ctx, 1), "maximum cost")
+
+	// outside of GraphQL queries, nothing is charged
+	assert.NilError(t, chargeQuery(context.Background(), graphqlMaxCost+1))
+}
+
+func TestLongUnmarshalGraphQL(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input interface{}
+		want  Long
+		err   bool
+	}{
+		{name: "int32", input: int32(-5), want: -5},
+		{name: "int64", input: int64(1) << 40, want: 1 << 40},
+		{name: "float64", input: float64(1 << 40), want: 1 << 40},
+		{name: "fractional float64", input: 1.5, err: true},
+		{name: "float64 out of range", input: 1e19, err: true},
+		{name: "string", input: "1099511627776", want: 1 << 40},
+		{name: "invalid string", input: "one", err: true},
+		{name: "bool", input: true, err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var l Long
+			err := l.UnmarshalGraphQL(tc.input)
+			if tc.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, l, tc.want)
+		})
+	}
+}
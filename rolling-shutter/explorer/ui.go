@@ -0,0 +1,118 @@
+package explorer
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+// overviewEpochs is the number of recent epochs shown on the overview page.
+const overviewEpochs = 20
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Shutter explorer</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+td.hex { font-family: monospace; max-width: 30em; overflow-wrap: anywhere; }
+.failed { color: #b00; }
+.note { font-family: sans-serif; color: #555; }
+</style>
+</head>
+<body>
+<h1>Shutter explorer</h1>
+<p>The same data is available as JSON at
+<a href="api/eons">api/eons</a>, <a href="api/keyper-sets">api/keyper-sets</a> and <a href="api/epochs">api/epochs</a>.</p>
+
+<h2>Eons</h2>
+<table>
+<tr><th>Eon</th><th>Activation block</th><th>Keyper set</th><th>DKG</th><th>Eon public key</th><th>Notes</th></tr>
+{{range .Eons}}
+<tr>
+<td><a href="api/eons/{{.Eon}}">{{.Eon}}</a></td>
+<td>{{.ActivationBlockNumber}}</td>
+<td>{{.KeyperConfigIndex}}</td>
+<td{{if eq .DKG "failed"}} class="failed" title="{{.DKGError}}"{{end}}>{{.DKG}}</td>
+<td class="hex">{{if .EonPublicKey}}{{.EonPublicKey}}{{else}}not confirmed{{end}}</td>
+<td>{{template "annotations" .Annotations}}</td>
+</tr>
+{{end}}
+</table>
+
+<h2>Keyper sets</h2>
+<table>
+<tr><th>Index</th><th>Activation block</th><th>Threshold</th><th>Keypers</th></tr>
+{{range .KeyperSets}}
+<tr>
+<td>{{.KeyperConfigIndex}}</td>
+<td>{{.ActivationBlockNumber}}</td>
+<td>{{.Threshold}} of {{len .Keypers}}</td>
+<td class="hex">{{$set := .}}{{range .Keypers}}{{.}} {{template "annotations" index $set.Annotations .}}<br>{{end}}</td>
+</tr>
+{{end}}
+</table>
+
+<h2>Recent epochs</h2>
+<table>
+<tr><th>Eon</th><th>Epoch id</th><th>Finalized at</th><th>Decryption key</th><th>Notes</th></tr>
+{{range .Epochs.Epochs}}
+<tr>
+<td>{{.Eon}}</td>
+<td class="hex">{{.EpochID}}</td>
+<td>{{.FinalizedAt.UTC.Format "2006-01-02 15:04:05"}}</td>
+<td class="hex">{{if .DecryptionKey}}{{.DecryptionKey}}{{else}}unknown{{end}}</td>
+<td>{{template "annotations" .Annotations}}</td>
+</tr>
+{{end}}
+</table>
+</body>
+</html>
+{{define "annotations"}}{{range .}}<span class="note" title="{{.Author}}, {{.CreatedAt.UTC.Format "2006-01-02"}}">
+{{- if .Label}}[{{.Label}}] {{end}}{{.Note}}</span> {{end}}{{end}}
+`))
+
+type overview struct {
+	Eons       []Eon
+	KeyperSets []*KeyperSet
+	Epochs     *EpochPage
+}
+
+// Index renders an overview of all eons and keyper sets and of the most recent epochs.
+func (e *Explorer) Index(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var (
+		data overview
+		err  error
+	)
+	if data.Eons, err = e.eons(ctx); err != nil {
+		sendError(w, err)
+		return
+	}
+	if data.KeyperSets, err = e.keyperSets(ctx); err != nil {
+		sendError(w, err)
+		return
+	}
+	limit := e.config.MaxEpochs
+	if limit > overviewEpochs {
+		limit = overviewEpochs
+	}
+	if data.Epochs, err = e.epochs(ctx, limit, nil); err != nil {
+		sendError(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := indexTemplate.Execute(&buf, data); err != nil {
+		log.Error().Err(err).Msg("failed to render explorer overview")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,101 @@
+package gateway
+
+import (
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/tlsconfig"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+func (c *Config) Init() {
+	c.P2P = p2p.NewConfig()
+	c.HTTPTLS = tlsconfig.NewServerConfig()
+}
+
+type Config struct {
+	InstanceID uint64 `shconfig:",required"`
+	ChainID    uint64 `comment:"Chain the keypers sign eon public keys for"`
+
+	Keypers   []string `shconfig:",required" comment:"Addresses of the keypers whose eon public keys are served"`
+	Threshold uint64   `shconfig:",required" comment:"Number of keypers that have to sign the same eon public key before it is served"`
+
+	MaxDecryptionKeys uint64 `comment:"Number of decryption keys kept, the oldest ones are dropped first"`
+
+	HTTPListenAddress string
+	HTTPTLS           *tlsconfig.ServerConfig
+	RateLimit         uint64 `comment:"Requests per second allowed per client, 0 disables the limit"`
+	TrustProxyHeaders bool   `comment:"Identify clients by the X-Forwarded-For header, only enable this behind a trusted proxy or CDN"`
+
+	P2P *p2p.Config
+}
+
+func (c *Config) Name() string {
+	return "gateway"
+}
+
+func (c *Config) Validate() error {
+	for _, keyper := range c.Keypers {
+		if !common.IsHexAddress(keyper) {
+			return errors.Errorf("invalid keyper address %q", keyper)
+		}
+	}
+	if c.Threshold == 0 || c.Threshold > uint64(len(c.Keypers)) {
+		return errors.Errorf("Threshold must be between 1 and the number of keypers (%d)", len(c.Keypers))
+	}
+	if c.MaxDecryptionKeys == 0 {
+		return errors.New("MaxDecryptionKeys must be positive")
+	}
+	if c.HTTPListenAddress == "" {
+		return errors.Errorf("configuration value HTTPListenAddress is missing")
+	}
+	return c.HTTPTLS.Validate()
+}
+
+// keypers returns the addresses of the keypers, see Validate.
+func (c *Config) keypers() map[common.Address]bool {
+	keypers := make(map[common.Address]bool, len(c.Keypers))
+	for _, keyper := range c.Keypers {
+		keypers[common.HexToAddress(keyper)] = true
+	}
+	return keypers
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.MaxDecryptionKeys = 100000
+	c.HTTPListenAddress = ":3040"
+	c.RateLimit = 20
+	c.TrustProxyHeaders = false
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	err := c.SetDefaultValues()
+	if err != nil {
+		return err
+	}
+	c.InstanceID = 42
+	c.ChainID = 1
+	c.Keypers = []string{
+		"0x1111111111111111111111111111111111111111",
+		"0x2222222222222222222222222222222222222222",
+		"0x3333333333333333333333333333333333333333",
+	}
+	c.Threshold = 2
+	return nil
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,24 @@
+package gateway
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration/test"
+)
+
+func TestSmokeGenerateConfig(t *testing.T) {
+	config := NewConfig()
+	test.SmokeGenerateConfig(t, config)
+}
+
+func TestParsedConfig(t *testing.T) {
+	config := NewConfig()
+
+	err := configuration.SetExampleValuesRecursive(config)
+	assert.NilError(t, err)
+	parsedConfig := test.RoundtripParseConfig(t, config)
+	assert.DeepEqual(t, config, parsedConfig)
+}
@@ -0,0 +1,80 @@
+// Package gateway implements a node that follows the gossip of a Shutter instance without taking
+// part in it, and serves the eon public keys and decryption keys it sees over a cacheable HTTP API.
+// It lets dapps fetch keys at scale, e.g. through a CDN, without connecting to the keypers.
+//
+// The gateway doesn't observe the chain. Instead, it trusts the eon public keys signed by at least
+// Threshold of the configured keypers, and only accepts decryption keys that match them.
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+type Gateway struct {
+	config *Config
+	p2p    *p2p.P2PHandler
+	store  *store
+}
+
+func New(config *Config) (*Gateway, error) {
+	p2pHandler, err := p2p.New(config.P2P, config.InstanceID)
+	if err != nil {
+		return nil, err
+	}
+	g := &Gateway{
+		config: config,
+		p2p:    p2pHandler,
+		store:  newStore(config.Threshold, config.MaxDecryptionKeys),
+	}
+	g.p2p.AddMessageHandler(
+		&eonPublicKeyHandler{
+			instanceID: config.InstanceID,
+			domain: p2pmsg.SigningDomain{
+				ChainID:    config.ChainID,
+				InstanceID: config.InstanceID,
+			},
+			keypers: config.keypers(),
+			store:   g.store,
+		},
+		&decryptionKeyHandler{instanceID: config.InstanceID, store: g.store},
+	)
+	return g, nil
+}
+
+func (g *Gateway) Start(ctx context.Context, runner service.Runner) error {
+	srv := &server{store: g.store}
+	if g.config.RateLimit > 0 {
+		srv.limiter = newRateLimiter(g.config.RateLimit, g.config.TrustProxyHeaders)
+	}
+	httpServer := &http.Server{
+		Addr:              g.config.HTTPListenAddress,
+		Handler:           srv.setupRouter(),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		MaxHeaderBytes:    1 << 14,
+	}
+	runner.Go(func() error {
+		log.Info().Str("address", g.config.HTTPListenAddress).Msg("running gateway")
+		if err := g.config.HTTPTLS.ListenAndServe(httpServer); err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	})
+	runner.Go(func() error {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	})
+	return runner.StartService(g.p2p)
+}
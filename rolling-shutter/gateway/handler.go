@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// eonPublicKeyHandler collects the eon public keys the configured keypers sign.
+type eonPublicKeyHandler struct {
+	instanceID uint64
+	domain     p2pmsg.SigningDomain
+	keypers    map[common.Address]bool
+	store      *store
+}
+
+func (*eonPublicKeyHandler) MessagePrototypes() []p2pmsg.Message {
+	return []p2pmsg.Message{&p2pmsg.EonPublicKey{}}
+}
+
+// recoverSigner recovers the keyper that signed the eon public key. Signatures in the signing
+// domain and legacy ones are both accepted, like the collator does.
+func (h *eonPublicKeyHandler) recoverSigner(key *p2pmsg.EonPublicKey) (common.Address, error) {
+	return p2pmsg.RecoverSigner(key, h.domain, true, func(address common.Address) bool {
+		return h.keypers[address]
+	})
+}
+
+func (h *eonPublicKeyHandler) ValidateMessage(_ context.Context, msg p2pmsg.Message) (bool, error) {
+	key := msg.(*p2pmsg.EonPublicKey)
+	if key.GetInstanceID() != h.instanceID {
+		return false, errors.Errorf("instance ID mismatch (want=%d, have=%d)", h.instanceID, key.GetInstanceID())
+	}
+	if err := new(shcrypto.EonPublicKey).GobDecode(key.PublicKey); err != nil {
+		return false, errors.Wrap(err, "invalid eon public key")
+	}
+	if _, err := h.recoverSigner(key); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (h *eonPublicKeyHandler) HandleMessage(_ context.Context, msg p2pmsg.Message) ([]p2pmsg.Message, error) {
+	key := msg.(*p2pmsg.EonPublicKey)
+	signer, err := h.recoverSigner(key)
+	if err != nil {
+		return nil, err
+	}
+	decoded := new(shcrypto.EonPublicKey)
+	if err := decoded.GobDecode(key.PublicKey); err != nil {
+		return nil, errors.Wrap(err, "invalid eon public key")
+	}
+	if h.store.addVote(key, decoded, signer) {
+		log.Info().Uint64("eon", key.Eon).Uint64("keyper-config-index", key.KeyperConfigIndex).
+			Msg("confirmed eon public key")
+	}
+	return nil, nil
+}
+
+// decryptionKeyHandler collects the decryption keys of the eons whose public key is confirmed.
+type decryptionKeyHandler struct {
+	instanceID uint64
+	store      *store
+}
+
+func (*decryptionKeyHandler) MessagePrototypes() []p2pmsg.Message {
+	return []p2pmsg.Message{&p2pmsg.DecryptionKey{}}
+}
+
+func (h *decryptionKeyHandler) ValidateMessage(_ context.Context, msg p2pmsg.Message) (bool, error) {
+	key := msg.(*p2pmsg.DecryptionKey)
+	if key.GetInstanceID() != h.instanceID {
+		return false, errors.Errorf("instance ID mismatch (want=%d, have=%d)", h.instanceID, key.GetInstanceID())
+	}
+	epochID, err := epochid.BytesToEpochID(key.EpochID)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid epoch id")
+	}
+	eonPublicKey, ok := h.store.eonPublicKey(key.Eon)
+	if !ok {
+		return false, errors.Errorf("eon public key of eon %d is not known", key.Eon)
+	}
+	epochSecretKey, err := key.GetEpochSecretKey()
+	if err != nil {
+		return false, err
+	}
+	ok, err = shcrypto.VerifyEpochSecretKey(epochSecretKey, eonPublicKey.decoded, epochID.Bytes())
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, errors.Errorf("recovery of epoch secret key failed for epoch %s", epochID)
+	}
+	return true, nil
+}
+
+func (h *decryptionKeyHandler) HandleMessage(_ context.Context, msg p2pmsg.Message) ([]p2pmsg.Message, error) {
+	key := msg.(*p2pmsg.DecryptionKey)
+	epochID, err := epochid.BytesToEpochID(key.EpochID)
+	if err != nil {
+		return nil, err
+	}
+	h.store.addDecryptionKey(key.Eon, epochID.Bytes(), key.Key)
+	return nil, nil
+}
@@ -0,0 +1,156 @@
+package gateway
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testkeygen"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+const testInstanceID = 42
+
+var testDomain = p2pmsg.SigningDomain{ChainID: 1, InstanceID: testInstanceID}
+
+type testSetup struct {
+	keys        []*ecdsa.PrivateKey
+	eonKeys     *testkeygen.EonKeys
+	store       *store
+	eonHandler  *eonPublicKeyHandler
+	keysHandler *decryptionKeyHandler
+}
+
+func newTestSetup(t *testing.T) *testSetup {
+	t.Helper()
+	s := &testSetup{}
+	keypers := make(map[common.Address]bool)
+	for i := 0; i < 3; i++ {
+		key, err := ethcrypto.GenerateKey()
+		assert.NilError(t, err)
+		s.keys = append(s.keys, key)
+		keypers[ethcrypto.PubkeyToAddress(key.PublicKey)] = true
+	}
+	var err error
+	s.eonKeys, err = testkeygen.NewEonKeys(rand.New(rand.NewSource(1)), 3, 2) //nolint:gosec
+	assert.NilError(t, err)
+	s.store = newStore(2, 10)
+	s.eonHandler = &eonPublicKeyHandler{
+		instanceID: testInstanceID,
+		domain:     testDomain,
+		keypers:    keypers,
+		store:      s.store,
+	}
+	s.keysHandler = &decryptionKeyHandler{instanceID: testInstanceID, store: s.store}
+	return s
+}
+
+func (s *testSetup) eonPublicKey(t *testing.T, key *ecdsa.PrivateKey) *p2pmsg.EonPublicKey {
+	t.Helper()
+	msg := &p2pmsg.EonPublicKey{
+		InstanceID:        testInstanceID,
+		PublicKey:         s.eonKeys.PublicKey().Marshal(),
+		ActivationBlock:   10,
+		KeyperConfigIndex: 1,
+		Eon:               5,
+	}
+	assert.NilError(t, p2pmsg.SignInDomain(msg, testDomain, key))
+	return msg
+}
+
+func (s *testSetup) decryptionKey(t *testing.T, epochID epochid.EpochID) *p2pmsg.DecryptionKey {
+	t.Helper()
+	epochSecretKey, err := s.eonKeys.EpochSecretKey(epochID)
+	assert.NilError(t, err)
+	return &p2pmsg.DecryptionKey{
+		InstanceID: testInstanceID,
+		Eon:        5,
+		EpochID:    epochID.Bytes(),
+		Key:        epochSecretKey.Marshal(),
+	}
+}
+
+// confirm confirms the eon public key with votes of the first two keypers.
+func (s *testSetup) confirm(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+	for _, key := range s.keys[:2] {
+		msg := s.eonPublicKey(t, key)
+		ok, err := s.eonHandler.ValidateMessage(ctx, msg)
+		assert.NilError(t, err)
+		assert.Assert(t, ok)
+		_, err = s.eonHandler.HandleMessage(ctx, msg)
+		assert.NilError(t, err)
+	}
+}
+
+func TestEonPublicKeyHandler(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSetup(t)
+
+	outsider, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	_, err = s.eonHandler.ValidateMessage(ctx, s.eonPublicKey(t, outsider))
+	assert.ErrorContains(t, err, "is not allowed to sign")
+
+	msg := s.eonPublicKey(t, s.keys[0])
+	_, err = s.eonHandler.HandleMessage(ctx, msg)
+	assert.NilError(t, err)
+	// a second vote of the same keyper doesn't count
+	_, err = s.eonHandler.HandleMessage(ctx, msg)
+	assert.NilError(t, err)
+	_, ok := s.store.eonPublicKey(5)
+	assert.Assert(t, !ok)
+
+	_, err = s.eonHandler.HandleMessage(ctx, s.eonPublicKey(t, s.keys[1]))
+	assert.NilError(t, err)
+	key, ok := s.store.eonPublicKey(5)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, key.PublicKey, msg.PublicKey)
+}
+
+func TestDecryptionKeyHandler(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSetup(t)
+	epochID := epochid.Uint64ToEpochID(7)
+	msg := s.decryptionKey(t, epochID)
+
+	_, err := s.keysHandler.ValidateMessage(ctx, msg)
+	assert.ErrorContains(t, err, "is not known")
+
+	s.confirm(t)
+	ok, err := s.keysHandler.ValidateMessage(ctx, msg)
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+
+	forged := s.decryptionKey(t, epochid.Uint64ToEpochID(8))
+	forged.EpochID = epochID.Bytes()
+	_, err = s.keysHandler.ValidateMessage(ctx, forged)
+	assert.ErrorContains(t, err, "recovery of epoch secret key failed")
+
+	_, err = s.keysHandler.HandleMessage(ctx, msg)
+	assert.NilError(t, err)
+	key, ok := s.store.decryptionKey(5, epochID.Bytes())
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, key, msg.Key)
+}
+
+func TestStoreDropsOldestDecryptionKeys(t *testing.T) {
+	s := newStore(1, 2)
+	for i := byte(0); i < 3; i++ {
+		s.addDecryptionKey(1, []byte{i}, []byte{i})
+	}
+	_, ok := s.decryptionKey(1, []byte{0})
+	assert.Assert(t, !ok)
+	for i := byte(1); i < 3; i++ {
+		key, ok := s.decryptionKey(1, []byte{i})
+		assert.Assert(t, ok)
+		assert.DeepEqual(t, key, []byte{i})
+	}
+}
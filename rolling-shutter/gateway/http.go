@@ -0,0 +1,173 @@
+package gateway
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+)
+
+const (
+	// Keys never change once they are known, so responses containing them can be cached forever.
+	cacheForever = "public, max-age=31536000, immutable"
+	// Keys that are not known yet may become known any moment. Caching the response for a short
+	// time lets a CDN absorb clients polling for them.
+	cacheNotFound = "public, max-age=1"
+)
+
+type eonPublicKeyResponse struct {
+	Eon               uint64        `json:"eon"`
+	ActivationBlock   uint64        `json:"activationBlock"`
+	KeyperConfigIndex uint64        `json:"keyperConfigIndex"`
+	PublicKey         hexutil.Bytes `json:"publicKey"`
+}
+
+type decryptionKeyResponse struct {
+	Eon           uint64        `json:"eon"`
+	EpochID       hexutil.Bytes `json:"epochID"`
+	DecryptionKey hexutil.Bytes `json:"decryptionKey"`
+}
+
+type errorResponse struct {
+	Code      int    `json:"code"`
+	ErrorCode string `json:"errorCode"`
+	Message   string `json:"message"`
+}
+
+type server struct {
+	store   *store
+	limiter *rateLimiter
+}
+
+func (srv *server) setupRouter() *chi.Mux {
+	router := chi.NewRouter()
+	router.Use(middleware.Recoverer)
+	router.Use(srv.headers)
+	if srv.limiter != nil {
+		router.Use(srv.rateLimit)
+	}
+	router.Get("/v1/eons/{eon}/public-key", srv.GetEonPublicKey)
+	router.Get("/v1/eons/{eon}/epochs/{epochID}/decryption-key", srv.GetDecryptionKey)
+	return router
+}
+
+// headers sets the headers of all responses. Keys are public, so any site may fetch them.
+func (srv *server) headers(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (srv *server) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, retryAfter := srv.limiter.allow(srv.limiter.client(r))
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			sendError(w, errcode.ErrRateLimited.Errorf("rate limit exceeded"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sendError responds with the HTTP status and error code of err's class, see errcode.Of.
+func sendError(w http.ResponseWriter, err error) {
+	class := errcode.Of(err)
+	if class.HTTPStatus >= http.StatusInternalServerError {
+		log.Warn().Err(err).Str("error-code", string(class.Code)).Msg("gateway request failed")
+	}
+	if class.HTTPStatus == http.StatusNotFound {
+		w.Header().Set("Cache-Control", cacheNotFound)
+	} else {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(class.HTTPStatus)
+	_ = json.NewEncoder(w).Encode(errorResponse{
+		Code:      class.HTTPStatus,
+		ErrorCode: string(class.Code),
+		Message:   err.Error(),
+	})
+}
+
+// sendImmutable responds with v, which must never change for the requested URL. The response
+// carries an ETag, so that clients and caches can revalidate it without transferring it again.
+func sendImmutable(w http.ResponseWriter, r *http.Request, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		sendError(w, errcode.ErrInternal.Wrap(err))
+		return
+	}
+	etag := fmt.Sprintf(`"%s"`, hex.EncodeToString(ethcrypto.Keccak256(body)[:16]))
+	w.Header().Set("Cache-Control", cacheForever)
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+func parseEon(r *http.Request) (uint64, error) {
+	eon, err := strconv.ParseUint(chi.URLParam(r, "eon"), 10, 64)
+	if err != nil {
+		return 0, errcode.ErrInvalidRequest.Wrapf(err, "invalid eon")
+	}
+	return eon, nil
+}
+
+func (srv *server) GetEonPublicKey(w http.ResponseWriter, r *http.Request) {
+	eon, err := parseEon(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	key, ok := srv.store.eonPublicKey(eon)
+	if !ok {
+		sendError(w, errcode.ErrEonKeyNotFound.Errorf("eon public key of eon %d is not known", eon))
+		return
+	}
+	sendImmutable(w, r, eonPublicKeyResponse{
+		Eon:               key.Eon,
+		ActivationBlock:   key.ActivationBlock,
+		KeyperConfigIndex: key.KeyperConfigIndex,
+		PublicKey:         key.PublicKey,
+	})
+}
+
+func (srv *server) GetDecryptionKey(w http.ResponseWriter, r *http.Request) {
+	eon, err := parseEon(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	epochID, err := epochid.HexToEpochID(chi.URLParam(r, "epochID"))
+	if err != nil {
+		sendError(w, errcode.ErrInvalidEpochID.Wrap(err))
+		return
+	}
+	key, ok := srv.store.decryptionKey(eon, epochID.Bytes())
+	if !ok {
+		sendError(w, errcode.ErrDecryptionKeyNotFound.Errorf("decryption key of epoch %s is not known", epochID))
+		return
+	}
+	sendImmutable(w, r, decryptionKeyResponse{
+		Eon:           eon,
+		EpochID:       epochID.Bytes(),
+		DecryptionKey: key,
+	})
+}
@@ -0,0 +1,80 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+func request(handler http.Handler, path string, header http.Header) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestHTTPCaching(t *testing.T) {
+	s := newTestSetup(t)
+	router := (&server{store: s.store}).setupRouter()
+	epochID := epochid.Uint64ToEpochID(7)
+	path := "/v1/eons/5/epochs/" + epochID.Hex() + "/decryption-key"
+
+	res := request(router, "/v1/eons/5/public-key", nil)
+	assert.Equal(t, res.Code, http.StatusNotFound)
+	assert.Equal(t, res.Header().Get("Cache-Control"), cacheNotFound)
+
+	s.confirm(t)
+	res = request(router, "/v1/eons/5/public-key", nil)
+	assert.Equal(t, res.Code, http.StatusOK)
+	assert.Equal(t, res.Header().Get("Cache-Control"), cacheForever)
+	assert.Equal(t, res.Header().Get("Access-Control-Allow-Origin"), "*")
+
+	assert.Equal(t, request(router, path, nil).Code, http.StatusNotFound)
+	msg := s.decryptionKey(t, epochID)
+	s.store.addDecryptionKey(msg.Eon, msg.EpochID, msg.Key)
+	res = request(router, path, nil)
+	assert.Equal(t, res.Code, http.StatusOK)
+	etag := res.Header().Get("ETag")
+	assert.Assert(t, etag != "")
+
+	res = request(router, path, http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, res.Code, http.StatusNotModified)
+	assert.Equal(t, res.Body.Len(), 0)
+
+	assert.Equal(t, request(router, "/v1/eons/x/public-key", nil).Code, http.StatusBadRequest)
+	assert.Equal(t, request(router, "/v1/eons/5/epochs/0x01/decryption-key", nil).Code, http.StatusBadRequest)
+}
+
+func TestRateLimit(t *testing.T) {
+	now := time.Unix(1000, 0)
+	limiter := newRateLimiter(2, false)
+	limiter.now = func() time.Time { return now }
+	router := (&server{store: newStore(1, 1), limiter: limiter}).setupRouter()
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, request(router, "/v1/eons/1/public-key", nil).Code, http.StatusNotFound)
+	}
+	res := request(router, "/v1/eons/1/public-key", nil)
+	assert.Equal(t, res.Code, http.StatusTooManyRequests)
+	assert.Equal(t, res.Header().Get("Retry-After"), "1")
+
+	now = now.Add(500 * time.Millisecond)
+	assert.Equal(t, request(router, "/v1/eons/1/public-key", nil).Code, http.StatusNotFound)
+	assert.Equal(t, request(router, "/v1/eons/1/public-key", nil).Code, http.StatusTooManyRequests)
+}
+
+func TestRateLimitClient(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "192.0.2.1, 10.0.0.2")
+	assert.Equal(t, newRateLimiter(1, false).client(req), "10.0.0.1")
+	assert.Equal(t, newRateLimiter(1, true).client(req), "192.0.2.1")
+}
@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTrackedClients is the number of clients whose buckets are kept before idle ones are dropped.
+const maxTrackedClients = 10000
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter limits the requests of each client with a token bucket that refills at rate tokens
+// per second and holds at most rate tokens.
+type rateLimiter struct {
+	rate              float64
+	trustProxyHeaders bool
+	now               func() time.Time
+
+	mux     sync.Mutex
+	buckets map[string]*bucket
+}
+
+func newRateLimiter(rate uint64, trustProxyHeaders bool) *rateLimiter {
+	return &rateLimiter{
+		rate:              float64(rate),
+		trustProxyHeaders: trustProxyHeaders,
+		now:               time.Now,
+		buckets:           make(map[string]*bucket),
+	}
+}
+
+// allow takes a token from the client's bucket. If the bucket is empty, it returns false and the
+// time until the next token is available.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[client]
+	if !ok {
+		if len(l.buckets) >= maxTrackedClients {
+			l.dropIdle(now)
+		}
+		b = &bucket{tokens: l.rate, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.rate {
+		b.tokens = l.rate
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// dropIdle drops the buckets that have been refilled completely, as they behave like new ones.
+func (l *rateLimiter) dropIdle(now time.Time) {
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.rate {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// client identifies the client that sent the request.
+func (l *rateLimiter) client(r *http.Request) string {
+	if l.trustProxyHeaders {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,123 @@
+package gateway
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// EonPublicKey is an eon public key signed by at least threshold keypers.
+type EonPublicKey struct {
+	Eon               uint64
+	ActivationBlock   uint64
+	KeyperConfigIndex uint64
+	PublicKey         []byte
+
+	decoded *shcrypto.EonPublicKey
+}
+
+type eonState struct {
+	confirmed *EonPublicKey
+	// votes holds the keypers that signed each candidate, by the candidate's hash.
+	votes map[string]map[common.Address]bool
+}
+
+type decryptionKeyID struct {
+	eon     uint64
+	epochID string
+}
+
+// store keeps the eon public keys and decryption keys the gateway serves in memory. Eon public
+// keys are kept forever, there is only one per eon. Decryption keys are dropped in the order they
+// were received once more than maxDecryptionKeys are stored.
+type store struct {
+	threshold         uint64
+	maxDecryptionKeys uint64
+
+	mux            sync.RWMutex
+	eons           map[uint64]*eonState
+	decryptionKeys map[decryptionKeyID][]byte
+	order          []decryptionKeyID
+}
+
+func newStore(threshold uint64, maxDecryptionKeys uint64) *store {
+	return &store{
+		threshold:         threshold,
+		maxDecryptionKeys: maxDecryptionKeys,
+		eons:              make(map[uint64]*eonState),
+		decryptionKeys:    make(map[decryptionKeyID][]byte),
+	}
+}
+
+// addVote records that signer signed the eon public key candidate. The candidate is confirmed
+// once threshold keypers signed it, unless another candidate has been confirmed for the eon
+// before. It reports whether the candidate got confirmed by this vote.
+func (s *store) addVote(key *p2pmsg.EonPublicKey, decoded *shcrypto.EonPublicKey, signer common.Address) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	eon, ok := s.eons[key.Eon]
+	if !ok {
+		eon = &eonState{votes: make(map[string]map[common.Address]bool)}
+		s.eons[key.Eon] = eon
+	}
+	if eon.confirmed != nil {
+		return false
+	}
+	hash := string(key.Hash())
+	votes, ok := eon.votes[hash]
+	if !ok {
+		votes = make(map[common.Address]bool)
+		eon.votes[hash] = votes
+	}
+	votes[signer] = true
+	if uint64(len(votes)) < s.threshold {
+		return false
+	}
+	eon.confirmed = &EonPublicKey{
+		Eon:               key.Eon,
+		ActivationBlock:   key.ActivationBlock,
+		KeyperConfigIndex: key.KeyperConfigIndex,
+		PublicKey:         key.PublicKey,
+		decoded:           decoded,
+	}
+	eon.votes = nil
+	return true
+}
+
+// eonPublicKey returns the confirmed eon public key of the eon.
+func (s *store) eonPublicKey(eon uint64) (*EonPublicKey, bool) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	state, ok := s.eons[eon]
+	if !ok || state.confirmed == nil {
+		return nil, false
+	}
+	return state.confirmed, true
+}
+
+func (s *store) addDecryptionKey(eon uint64, epochID []byte, key []byte) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	id := decryptionKeyID{eon: eon, epochID: string(epochID)}
+	if _, ok := s.decryptionKeys[id]; ok {
+		return
+	}
+	s.decryptionKeys[id] = key
+	s.order = append(s.order, id)
+	for uint64(len(s.order)) > s.maxDecryptionKeys {
+		delete(s.decryptionKeys, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+func (s *store) decryptionKey(eon uint64, epochID []byte) ([]byte, bool) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	key, ok := s.decryptionKeys[decryptionKeyID{eon: eon, epochID: string(epochID)}]
+	return key, ok
+}
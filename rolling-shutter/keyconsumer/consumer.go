@@ -0,0 +1,208 @@
+// Package keyconsumer lets services that decrypt Shutter ciphertexts obtain decryption keys
+// without re-implementing their verification. A Consumer fetches keys from the HTTP API of a
+// gateway (see package gateway) or receives them via gossip, verifies each decryption key against
+// the eon public key locally and caches it.
+//
+// Decryption keys are only as trustworthy as the eon public keys they are verified against. Eon
+// public keys fetched from the source are trusted, so consumers that don't trust their source
+// should pin the eon public keys, e.g. as read from the chain, with AddEonPublicKey.
+package keyconsumer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+var (
+	// ErrNotFound is returned if a key is not known yet.
+	ErrNotFound = errors.New("key not found")
+	// ErrInvalidKey is returned for decryption keys that don't match the eon public key.
+	ErrInvalidKey = errors.New("invalid decryption key")
+)
+
+type keyID struct {
+	eon     uint64
+	epochID string
+}
+
+// Consumer verifies and caches decryption keys. Eon public keys are cached forever, there is only
+// one per eon. Decryption keys are dropped in the order they were cached once more than maxKeys
+// are cached.
+type Consumer struct {
+	source       Source
+	maxKeys      int
+	pollInterval time.Duration
+
+	mux     sync.Mutex
+	eonKeys map[uint64]*shcrypto.EonPublicKey
+	keys    map[keyID]*shcrypto.EpochSecretKey
+	order   []keyID
+}
+
+// New creates a consumer fetching keys from source. source may be nil if all keys are added by
+// hand or via gossip. pollInterval is the time between two attempts to fetch a decryption key
+// that is not known yet.
+func New(source Source, maxKeys int, pollInterval time.Duration) *Consumer {
+	return &Consumer{
+		source:       source,
+		maxKeys:      maxKeys,
+		pollInterval: pollInterval,
+		eonKeys:      make(map[uint64]*shcrypto.EonPublicKey),
+		keys:         make(map[keyID]*shcrypto.EpochSecretKey),
+	}
+}
+
+// AddEonPublicKey pins the eon public key of the eon, replacing the one fetched from the source.
+func (c *Consumer) AddEonPublicKey(eon uint64, key []byte) error {
+	decoded := new(shcrypto.EonPublicKey)
+	if err := decoded.GobDecode(key); err != nil {
+		return errors.Wrapf(err, "invalid eon public key of eon %d", eon)
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.eonKeys[eon] = decoded
+	return nil
+}
+
+// EonPublicKey returns the eon public key of the eon, fetching it from the source if it is not
+// known.
+func (c *Consumer) EonPublicKey(ctx context.Context, eon uint64) (*shcrypto.EonPublicKey, error) {
+	c.mux.Lock()
+	key, ok := c.eonKeys[eon]
+	c.mux.Unlock()
+	if ok {
+		return key, nil
+	}
+	if c.source == nil {
+		return nil, errors.Wrapf(ErrNotFound, "eon public key of eon %d", eon)
+	}
+	encoded, err := c.source.EonPublicKey(ctx, eon)
+	if err != nil {
+		return nil, err
+	}
+	decoded := new(shcrypto.EonPublicKey)
+	if err := decoded.GobDecode(encoded); err != nil {
+		return nil, errors.Wrapf(err, "invalid eon public key of eon %d", eon)
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	// don't replace a key that has been pinned in the meantime
+	if key, ok := c.eonKeys[eon]; ok {
+		return key, nil
+	}
+	c.eonKeys[eon] = decoded
+	return decoded, nil
+}
+
+// AddDecryptionKey verifies the decryption key of the epoch against the eon public key and caches
+// it. It fails with ErrInvalidKey if the key doesn't match.
+func (c *Consumer) AddDecryptionKey(
+	ctx context.Context, eon uint64, epochID epochid.EpochID, key []byte,
+) (*shcrypto.EpochSecretKey, error) {
+	id := keyID{eon: eon, epochID: string(epochID.Bytes())}
+	if cached, ok := c.cached(id); ok {
+		return cached, nil
+	}
+	eonPublicKey, err := c.EonPublicKey(ctx, eon)
+	if err != nil {
+		return nil, err
+	}
+	decoded := new(shcrypto.EpochSecretKey)
+	if err := decoded.Unmarshal(key); err != nil {
+		return nil, errors.Wrapf(ErrInvalidKey, "failed to decode decryption key of epoch %s: %s", epochID, err)
+	}
+	ok, err := shcrypto.VerifyEpochSecretKey(decoded, eonPublicKey, epochID.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidKey, "failed to verify decryption key of epoch %s: %s", epochID, err)
+	}
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidKey, "decryption key of epoch %s doesn't match eon public key of eon %d",
+			epochID, eon)
+	}
+	c.cache(id, decoded)
+	return decoded, nil
+}
+
+// DecryptionKey returns the verified decryption key of the epoch, fetching it from the source if
+// it is not cached. It fails with ErrNotFound if the key is not known yet.
+func (c *Consumer) DecryptionKey(
+	ctx context.Context, eon uint64, epochID epochid.EpochID,
+) (*shcrypto.EpochSecretKey, error) {
+	if cached, ok := c.cached(keyID{eon: eon, epochID: string(epochID.Bytes())}); ok {
+		return cached, nil
+	}
+	if c.source == nil {
+		return nil, errors.Wrapf(ErrNotFound, "decryption key of epoch %s", epochID)
+	}
+	key, err := c.source.DecryptionKey(ctx, eon, epochID)
+	if err != nil {
+		return nil, err
+	}
+	return c.AddDecryptionKey(ctx, eon, epochID, key)
+}
+
+// WaitForDecryptionKey returns the verified decryption key of the epoch, waiting until it is known
+// or ctx is done.
+func (c *Consumer) WaitForDecryptionKey(
+	ctx context.Context, eon uint64, epochID epochid.EpochID,
+) (*shcrypto.EpochSecretKey, error) {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+	for {
+		key, err := c.DecryptionKey(ctx, eon, epochID)
+		if !errors.Is(err, ErrNotFound) {
+			return key, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Decrypt decrypts a ciphertext encrypted for the given eon and epoch, waiting until the
+// decryption key is known or ctx is done.
+func (c *Consumer) Decrypt(ctx context.Context, eon uint64, epochID epochid.EpochID, ciphertext []byte) ([]byte, error) {
+	encrypted := new(shcrypto.EncryptedMessage)
+	if err := encrypted.Unmarshal(ciphertext); err != nil {
+		return nil, errors.Wrap(err, "invalid ciphertext")
+	}
+	key, err := c.WaitForDecryptionKey(ctx, eon, epochID)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := encrypted.Decrypt(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt ciphertext")
+	}
+	return plaintext, nil
+}
+
+func (c *Consumer) cached(id keyID) (*shcrypto.EpochSecretKey, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	key, ok := c.keys[id]
+	return key, ok
+}
+
+func (c *Consumer) cache(id keyID, key *shcrypto.EpochSecretKey) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if _, ok := c.keys[id]; ok {
+		return
+	}
+	c.keys[id] = key
+	c.order = append(c.order, id)
+	for len(c.order) > c.maxKeys {
+		delete(c.keys, c.order[0])
+		c.order = c.order[1:]
+	}
+}
@@ -0,0 +1,135 @@
+package keyconsumer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	mathrand "math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testkeygen"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+const testEon = 5
+
+func newEonKeys(t *testing.T, seed int64) *testkeygen.EonKeys {
+	t.Helper()
+	eonKeys, err := testkeygen.NewEonKeys(mathrand.New(mathrand.NewSource(seed)), 3, 2) //nolint:gosec
+	assert.NilError(t, err)
+	return eonKeys
+}
+
+func epochSecretKey(t *testing.T, eonKeys *testkeygen.EonKeys, epochID epochid.EpochID) []byte {
+	t.Helper()
+	key, err := eonKeys.EpochSecretKey(epochID)
+	assert.NilError(t, err)
+	return key.Marshal()
+}
+
+// newTestGateway serves the eon public key of testEon and the decryption keys of the epochs in
+// available like the gateway does.
+func newTestGateway(t *testing.T, eonKeys *testkeygen.EonKeys, available map[string][]byte) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc(fmt.Sprintf("/v1/eons/%d/public-key", testEon), func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(eonPublicKeyResponse{PublicKey: eonKeys.PublicKey().Marshal()})
+	})
+	for epochID, key := range available {
+		key := key
+		path := fmt.Sprintf("/v1/eons/%d/epochs/%s/decryption-key", testEon, epochID)
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(decryptionKeyResponse{DecryptionKey: hexutil.Bytes(key)})
+		})
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDecrypt(t *testing.T) {
+	ctx := context.Background()
+	eonKeys := newEonKeys(t, 1)
+	epochID := epochid.Uint64ToEpochID(7)
+	server := newTestGateway(t, eonKeys, map[string][]byte{
+		epochID.Hex(): epochSecretKey(t, eonKeys, epochID),
+	})
+	c := New(NewHTTPSource(server.URL+"/", server.Client()), 10, 10*time.Millisecond)
+
+	sigma, err := shcrypto.RandomSigma(rand.Reader)
+	assert.NilError(t, err)
+	plaintext := []byte("hello")
+	ciphertext := shcrypto.Encrypt(plaintext, eonKeys.PublicKey(), shcrypto.ComputeEpochID(epochID.Bytes()), sigma)
+	decrypted, err := c.Decrypt(ctx, testEon, epochID, ciphertext.Marshal())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, decrypted, plaintext)
+
+	_, err = c.DecryptionKey(ctx, testEon, epochid.Uint64ToEpochID(8))
+	assert.ErrorIs(t, err, ErrNotFound)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = c.WaitForDecryptionKey(timeoutCtx, testEon, epochid.Uint64ToEpochID(8))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRejectInvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	eonKeys := newEonKeys(t, 1)
+	epochID := epochid.Uint64ToEpochID(7)
+	// the gateway serves the key of another epoch
+	server := newTestGateway(t, eonKeys, map[string][]byte{
+		epochID.Hex(): epochSecretKey(t, eonKeys, epochid.Uint64ToEpochID(8)),
+	})
+	c := New(NewHTTPSource(server.URL, server.Client()), 10, time.Second)
+	_, err := c.DecryptionKey(ctx, testEon, epochID)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	// a pinned eon public key takes precedence over the one of the source
+	otherEonKeys := newEonKeys(t, 2)
+	assert.NilError(t, c.AddEonPublicKey(testEon, otherEonKeys.PublicKey().Marshal()))
+	_, err = c.AddDecryptionKey(ctx, testEon, epochID, epochSecretKey(t, eonKeys, epochID))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = c.AddDecryptionKey(ctx, testEon, epochID, epochSecretKey(t, otherEonKeys, epochID))
+	assert.NilError(t, err)
+}
+
+func TestGossip(t *testing.T) {
+	ctx := context.Background()
+	eonKeys := newEonKeys(t, 1)
+	c := New(nil, 2, time.Second)
+	handler := c.MessageHandler(42)
+	epochID := epochid.Uint64ToEpochID(7)
+	msg := &p2pmsg.DecryptionKey{
+		InstanceID: 42,
+		Eon:        testEon,
+		EpochID:    epochID.Bytes(),
+		Key:        epochSecretKey(t, eonKeys, epochID),
+	}
+
+	_, err := handler.ValidateMessage(ctx, msg)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NilError(t, c.AddEonPublicKey(testEon, eonKeys.PublicKey().Marshal()))
+	ok, err := handler.ValidateMessage(ctx, msg)
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+	_, err = c.DecryptionKey(ctx, testEon, epochID)
+	assert.NilError(t, err)
+
+	// the oldest keys are dropped from the cache
+	for i := uint64(8); i < 10; i++ {
+		id := epochid.Uint64ToEpochID(i)
+		_, err := c.AddDecryptionKey(ctx, testEon, id, epochSecretKey(t, eonKeys, id))
+		assert.NilError(t, err)
+	}
+	_, err = c.DecryptionKey(ctx, testEon, epochID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,46 @@
+package keyconsumer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// MessageHandler returns a handler that verifies and caches the decryption keys gossiped in the
+// given instance. Add it to a p2p.P2PHandler to receive keys via gossip. Keys are cached as soon
+// as they are validated.
+func (c *Consumer) MessageHandler(instanceID uint64) p2p.MessageHandler {
+	return &decryptionKeyHandler{instanceID: instanceID, consumer: c}
+}
+
+type decryptionKeyHandler struct {
+	instanceID uint64
+	consumer   *Consumer
+}
+
+func (*decryptionKeyHandler) MessagePrototypes() []p2pmsg.Message {
+	return []p2pmsg.Message{&p2pmsg.DecryptionKey{}}
+}
+
+func (h *decryptionKeyHandler) ValidateMessage(ctx context.Context, msg p2pmsg.Message) (bool, error) {
+	key := msg.(*p2pmsg.DecryptionKey)
+	if key.GetInstanceID() != h.instanceID {
+		return false, errors.Errorf("instance ID mismatch (want=%d, have=%d)", h.instanceID, key.GetInstanceID())
+	}
+	epochID, err := epochid.BytesToEpochID(key.EpochID)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid epoch id")
+	}
+	if _, err := h.consumer.AddDecryptionKey(ctx, key.Eon, epochID, key.Key); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (*decryptionKeyHandler) HandleMessage(context.Context, p2pmsg.Message) ([]p2pmsg.Message, error) {
+	return nil, nil
+}
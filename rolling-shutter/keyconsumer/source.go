@@ -0,0 +1,93 @@
+package keyconsumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+// maxResponseSize limits the size of the responses of the key API.
+const maxResponseSize = 1 << 16
+
+// Source is where a Consumer fetches keys from. It returns keys encoded like in the gossip
+// messages, and fails with ErrNotFound for keys that are not known yet.
+type Source interface {
+	EonPublicKey(ctx context.Context, eon uint64) ([]byte, error)
+	DecryptionKey(ctx context.Context, eon uint64, epochID epochid.EpochID) ([]byte, error)
+}
+
+// HTTPSource fetches keys from the HTTP API of a gateway.
+type HTTPSource struct {
+	baseURL string
+	client  *http.Client
+}
+
+var _ Source = &HTTPSource{}
+
+func NewHTTPSource(baseURL string, client *http.Client) *HTTPSource {
+	return &HTTPSource{baseURL: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+type eonPublicKeyResponse struct {
+	PublicKey hexutil.Bytes `json:"publicKey"`
+}
+
+type decryptionKeyResponse struct {
+	DecryptionKey hexutil.Bytes `json:"decryptionKey"`
+}
+
+type errorResponse struct {
+	ErrorCode string `json:"errorCode"`
+	Message   string `json:"message"`
+}
+
+func (s *HTTPSource) EonPublicKey(ctx context.Context, eon uint64) ([]byte, error) {
+	var res eonPublicKeyResponse
+	if err := s.get(ctx, fmt.Sprintf("/v1/eons/%d/public-key", eon), &res); err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch eon public key of eon %d", eon)
+	}
+	return res.PublicKey, nil
+}
+
+func (s *HTTPSource) DecryptionKey(ctx context.Context, eon uint64, epochID epochid.EpochID) ([]byte, error) {
+	var res decryptionKeyResponse
+	err := s.get(ctx, fmt.Sprintf("/v1/eons/%d/epochs/%s/decryption-key", eon, epochID.Hex()), &res)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch decryption key of epoch %s", epochID)
+	}
+	return res.DecryptionKey, nil
+}
+
+func (s *HTTPSource) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	body := io.LimitReader(res.Body, maxResponseSize)
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return json.NewDecoder(body).Decode(v)
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		var errRes errorResponse
+		if err := json.NewDecoder(body).Decode(&errRes); err != nil || errRes.ErrorCode == "" {
+			return errors.Errorf("unexpected status %d", res.StatusCode)
+		}
+		return errors.Errorf("%s: %s", errRes.ErrorCode, errRes.Message)
+	}
+}
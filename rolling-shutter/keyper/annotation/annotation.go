@@ -0,0 +1,209 @@
+// Package annotation lets operators attach notes and labels to keypers, eons and epochs, e.g. to
+// mark a keyper as operated by themselves or to record why the DKG of an eon has been restarted.
+// Annotations are shown in the status of the keyper and in the explorer, so that the operators of
+// different nodes can coordinate.
+package annotation
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+)
+
+// The kinds of entities that can be annotated.
+const (
+	KindKeyper = "keyper"
+	KindEon    = "eon"
+	KindEpoch  = "epoch"
+)
+
+const (
+	maxLabelLength = 64
+	maxNoteLength  = 4096
+)
+
+// Annotation is a note or label attached to an entity.
+type Annotation struct {
+	ID        int64     `json:"id"`
+	Kind      string    `json:"kind"`
+	Entity    string    `json:"entity"`
+	Label     string    `json:"label,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Request is the body of a request to annotate an entity.
+type Request struct {
+	Kind   string `json:"kind"`
+	Entity string `json:"entity"`
+	Label  string `json:"label"`
+	Note   string `json:"note"`
+	Author string `json:"author"`
+}
+
+// String formats the annotation for display on a single line, e.g. "eon 5 [restarted] DKG failed
+// during the outage (alice)".
+func (a Annotation) String() string {
+	s := a.Kind + " " + a.Entity
+	if a.Label != "" {
+		s += " [" + a.Label + "]"
+	}
+	if a.Note != "" {
+		s += " " + a.Note
+	}
+	return s + " (" + a.Author + ")"
+}
+
+func newAnnotation(row kprdb.Annotation) Annotation {
+	return Annotation{
+		ID:        row.ID,
+		Kind:      row.EntityKind,
+		Entity:    row.EntityID,
+		Label:     row.Label,
+		Note:      row.Note,
+		Author:    row.Author,
+		CreatedAt: row.CreatedAt.UTC(),
+	}
+}
+
+// NormalizeEntity checks the id of an entity of the given kind and returns it in the form it is
+// stored in: the checksummed address of a keyper, the decimal index of an eon or the hex encoded
+// id of an epoch.
+func NormalizeEntity(kind, entity string) (string, error) {
+	switch kind {
+	case KindKeyper:
+		if !common.IsHexAddress(entity) {
+			return "", errcode.ErrInvalidRequest.Errorf("invalid keyper address %q", entity)
+		}
+		return common.HexToAddress(entity).Hex(), nil
+	case KindEon:
+		eon, err := strconv.ParseUint(entity, 10, 63)
+		if err != nil {
+			return "", errcode.ErrInvalidRequest.Errorf("invalid eon %q", entity)
+		}
+		return strconv.FormatUint(eon, 10), nil
+	case KindEpoch:
+		epochID, err := hexutil.Decode(entity)
+		if err != nil {
+			return "", errcode.ErrInvalidRequest.Errorf("invalid epoch id %q", entity)
+		}
+		return hexutil.Encode(epochID), nil
+	default:
+		return "", errcode.ErrInvalidRequest.Errorf("unknown kind of entity %q", kind)
+	}
+}
+
+// Add stores an annotation.
+func Add(ctx context.Context, db kprdb.DBTX, req Request) (Annotation, error) {
+	entity, err := NormalizeEntity(req.Kind, req.Entity)
+	if err != nil {
+		return Annotation{}, err
+	}
+	switch {
+	case req.Label == "" && req.Note == "":
+		return Annotation{}, errcode.ErrInvalidRequest.Errorf("either label or note must be set")
+	case len(req.Label) > maxLabelLength:
+		return Annotation{}, errcode.ErrInvalidRequest.Errorf("label must not exceed %d bytes", maxLabelLength)
+	case len(req.Note) > maxNoteLength:
+		return Annotation{}, errcode.ErrInvalidRequest.Errorf("note must not exceed %d bytes", maxNoteLength)
+	case req.Author == "":
+		return Annotation{}, errcode.ErrInvalidRequest.Errorf("author must be set")
+	}
+	row, err := kprdb.New(db).InsertAnnotation(ctx, kprdb.InsertAnnotationParams{
+		EntityKind: req.Kind,
+		EntityID:   entity,
+		Label:      req.Label,
+		Note:       req.Note,
+		Author:     req.Author,
+	})
+	if err != nil {
+		return Annotation{}, errcode.WrapDB(err, "failed to insert annotation")
+	}
+	return newAnnotation(row), nil
+}
+
+// List returns the annotations of an entity, or all annotations if kind is empty.
+func List(ctx context.Context, db kprdb.DBTX, kind, entity string) ([]Annotation, error) {
+	var (
+		rows []kprdb.Annotation
+		err  error
+	)
+	if kind == "" {
+		rows, err = kprdb.New(db).GetAnnotations(ctx)
+	} else {
+		entity, err = NormalizeEntity(kind, entity)
+		if err != nil {
+			return nil, err
+		}
+		rows, err = kprdb.New(db).GetAnnotationsOfEntity(ctx, kprdb.GetAnnotationsOfEntityParams{
+			EntityKind: kind,
+			EntityID:   entity,
+		})
+	}
+	if err != nil {
+		return nil, errcode.WrapDB(err, "failed to query annotations")
+	}
+	annotations := make([]Annotation, len(rows))
+	for i, row := range rows {
+		annotations[i] = newAnnotation(row)
+	}
+	return annotations, nil
+}
+
+// Delete removes an annotation.
+func Delete(ctx context.Context, db kprdb.DBTX, id int64) error {
+	n, err := kprdb.New(db).DeleteAnnotation(ctx, id)
+	if err != nil {
+		return errcode.WrapDB(err, "failed to delete annotation")
+	}
+	if n == 0 {
+		return errcode.ErrAnnotationNotFound.Errorf("annotation %d doesn't exist", id)
+	}
+	return nil
+}
+
+// Index groups annotations by the entity they are attached to.
+type Index map[string][]Annotation
+
+func indexKey(kind, entity string) string {
+	return kind + "/" + entity
+}
+
+// Load returns the index of all annotations.
+func Load(ctx context.Context, db kprdb.DBTX) (Index, error) {
+	annotations, err := List(ctx, db, "", "")
+	if err != nil {
+		return nil, err
+	}
+	index := Index{}
+	for _, a := range annotations {
+		key := indexKey(a.Kind, a.Entity)
+		index[key] = append(index[key], a)
+	}
+	return index, nil
+}
+
+// Keyper returns the annotations of the keyper with the given address.
+func (index Index) Keyper(address string) []Annotation {
+	if !common.IsHexAddress(address) {
+		return nil
+	}
+	return index[indexKey(KindKeyper, common.HexToAddress(address).Hex())]
+}
+
+// Eon returns the annotations of an eon.
+func (index Index) Eon(eon int64) []Annotation {
+	return index[indexKey(KindEon, strconv.FormatInt(eon, 10))]
+}
+
+// Epoch returns the annotations of an epoch.
+func (index Index) Epoch(epochID []byte) []Annotation {
+	return index[indexKey(KindEpoch, hexutil.Encode(epochID))]
+}
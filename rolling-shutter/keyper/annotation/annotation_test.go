@@ -0,0 +1,78 @@
+package annotation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+)
+
+func TestNormalizeEntity(t *testing.T) {
+	for _, tc := range []struct {
+		kind, entity, normalized string
+	}{
+		{KindKeyper, "0x5fbdb2315678afecb367f032d93f642f64180aa3", "0x5FbDB2315678afecb367f032d93F642f64180aa3"},
+		{KindEon, "007", "7"},
+		{KindEpoch, "0xABCD", "0xabcd"},
+	} {
+		normalized, err := NormalizeEntity(tc.kind, tc.entity)
+		assert.NilError(t, err)
+		assert.Equal(t, normalized, tc.normalized)
+	}
+	for _, tc := range []struct{ kind, entity string }{
+		{KindKeyper, "0x1234"},
+		{KindEon, "-1"},
+		{KindEpoch, "abcd"},
+		{"decryptor", "1"},
+	} {
+		_, err := NormalizeEntity(tc.kind, tc.entity)
+		assert.Check(t, errors.Is(err, errcode.ErrInvalidRequest), "%s %s", tc.kind, tc.entity)
+	}
+}
+
+func TestString(t *testing.T) {
+	a := Annotation{Kind: KindEon, Entity: "5", Label: "restarted", Note: "DKG failed during the outage", Author: "alice"}
+	assert.Equal(t, a.String(), "eon 5 [restarted] DKG failed during the outage (alice)")
+	a.Label = ""
+	assert.Equal(t, a.String(), "eon 5 DKG failed during the outage (alice)")
+}
+
+func TestAnnotationsIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	_, err := Add(ctx, dbpool, Request{Kind: KindEon, Entity: "5", Author: "alice"})
+	assert.Check(t, errors.Is(err, errcode.ErrInvalidRequest), "annotations without label and note must be rejected")
+
+	keyper := "0x5fbdb2315678afecb367f032d93f642f64180aa3"
+	ours, err := Add(ctx, dbpool, Request{Kind: KindKeyper, Entity: keyper, Label: "ours", Author: "alice"})
+	assert.NilError(t, err)
+	_, err = Add(ctx, dbpool, Request{Kind: KindEon, Entity: "5", Note: "DKG restarted due to outage", Author: "bob"})
+	assert.NilError(t, err)
+
+	all, err := List(ctx, dbpool, "", "")
+	assert.NilError(t, err)
+	assert.Equal(t, len(all), 2)
+	eon, err := List(ctx, dbpool, KindEon, "5")
+	assert.NilError(t, err)
+	assert.Equal(t, len(eon), 1)
+	assert.Equal(t, eon[0].Author, "bob")
+
+	index, err := Load(ctx, dbpool)
+	assert.NilError(t, err)
+	assert.Equal(t, len(index.Keyper(keyper)), 1)
+	assert.Equal(t, len(index.Eon(5)), 1)
+	assert.Equal(t, len(index.Epoch([]byte{1})), 0)
+
+	assert.NilError(t, Delete(ctx, dbpool, ours.ID))
+	err = Delete(ctx, dbpool, ours.ID)
+	assert.Check(t, errors.Is(err, errcode.ErrAnnotationNotFound))
+}
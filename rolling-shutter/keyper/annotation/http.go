@@ -0,0 +1,53 @@
+package annotation
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+)
+
+// Router serves the annotations. They can be filtered by entity with the kind and entity query
+// parameters. Adding and deleting annotations requires the operator role.
+func Router(dbpool *pgxpool.Pool) http.Handler {
+	router := chi.NewRouter()
+	router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		annotations, err := List(r.Context(), dbpool, query.Get("kind"), query.Get("entity"))
+		if err != nil {
+			errcode.SendError(w, err)
+			return
+		}
+		errcode.WriteJSON(w, http.StatusOK, annotations)
+	})
+	router.Post("/", func(w http.ResponseWriter, r *http.Request) {
+		req := Request{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errcode.SendError(w, errcode.ErrInvalidRequest.Wrapf(err, "invalid request body"))
+			return
+		}
+		annotation, err := Add(r.Context(), dbpool, req)
+		if err != nil {
+			errcode.SendError(w, err)
+			return
+		}
+		errcode.WriteJSON(w, http.StatusOK, annotation)
+	})
+	router.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			errcode.SendError(w, errcode.ErrInvalidRequest.Wrapf(err, "invalid annotation id"))
+			return
+		}
+		if err := Delete(r.Context(), dbpool, id); err != nil {
+			errcode.SendError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return router
+}
@@ -0,0 +1,92 @@
+package keyper
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/auditdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/storagemonitor"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p/provenance"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+const auditLogPruneInterval = time.Hour
+
+// auditedMessageHandler records every message the wrapped handler handled successfully in the
+// audit log, unless the storage monitor stops non-essential writes.
+type auditedMessageHandler struct {
+	p2p.MessageHandler
+	dbpool  *pgxpool.Pool
+	storage *storagemonitor.Monitor
+}
+
+func newAuditedMessageHandlers(
+	dbpool *pgxpool.Pool, storage *storagemonitor.Monitor, handlers ...p2p.MessageHandler,
+) []p2p.MessageHandler {
+	audited := make([]p2p.MessageHandler, len(handlers))
+	for i, h := range handlers {
+		audited[i] = auditedMessageHandler{MessageHandler: h, dbpool: dbpool, storage: storage}
+	}
+	return audited
+}
+
+func (h auditedMessageHandler) HandleMessage(ctx context.Context, msg p2pmsg.Message) ([]p2pmsg.Message, error) {
+	msgsOut, err := h.MessageHandler.HandleMessage(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	if !h.storage.AllowNonEssentialWrites() {
+		return msgsOut, nil
+	}
+	if err := auditMessage(ctx, auditdb.New(h.dbpool), msg); err != nil {
+		// the message has been handled already, so we still send out the resulting messages
+		log.Error().Err(err).Str("message", msg.LogInfo()).Msg("failed to record message in audit log")
+	}
+	return msgsOut, nil
+}
+
+func auditMessage(ctx context.Context, db *auditdb.Queries, msg p2pmsg.Message) error {
+	hash, err := provenance.MessageHash(msg)
+	if err != nil {
+		return err
+	}
+	actor := "unknown"
+	if sender, ok := p2p.SenderFromContext(ctx); ok {
+		actor = sender.String()
+	}
+	return db.InsertAuditLogEntry(ctx, auditdb.InsertAuditLogEntryParams{
+		Source:      auditdb.SourceP2P,
+		Actor:       actor,
+		Action:      string(proto.MessageName(msg)),
+		Details:     msg.LogInfo(),
+		TriggerHash: hash,
+	})
+}
+
+// NewAuditLogPruner returns a service that periodically deletes the entries of the audit log that
+// are older than the given retention period.
+func NewAuditLogPruner(dbpool *pgxpool.Pool, retention time.Duration) service.Service {
+	return service.ServiceFn{Fn: func(ctx context.Context) error {
+		ticker := time.NewTicker(auditLogPruneInterval)
+		defer ticker.Stop()
+		for {
+			n, err := auditdb.New(dbpool).Prune(ctx, retention)
+			if err != nil {
+				log.Warn().Err(err).Msg("failed to prune audit log")
+			} else if n > 0 {
+				log.Info().Int64("num-entries", n).Msg("pruned audit log")
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	}}
+}
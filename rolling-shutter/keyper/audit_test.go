@@ -0,0 +1,80 @@
+package keyper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/auditdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+type testMessageHandler struct {
+	err error
+}
+
+func (h testMessageHandler) ValidateMessage(context.Context, p2pmsg.Message) (bool, error) {
+	return true, nil
+}
+
+func (h testMessageHandler) HandleMessage(context.Context, p2pmsg.Message) ([]p2pmsg.Message, error) {
+	return nil, h.err
+}
+
+func (h testMessageHandler) MessagePrototypes() []p2pmsg.Message {
+	return []p2pmsg.Message{&p2pmsg.DecryptionKey{}}
+}
+
+func TestAuditLogIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+	queries := auditdb.New(dbpool)
+
+	handlers := newAuditedMessageHandlers(dbpool, nil, testMessageHandler{}, testMessageHandler{err: context.Canceled})
+	msg := &p2pmsg.DecryptionKey{InstanceID: 1, Eon: 2, EpochID: []byte{3}}
+	_, err := handlers[0].HandleMessage(ctx, msg)
+	assert.NilError(t, err)
+	_, err = handlers[1].HandleMessage(ctx, msg)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	query := auditdb.FindAuditLogEntriesParams{
+		Since:      time.Now().Add(-time.Hour),
+		Until:      time.Now().Add(time.Hour),
+		MaxEntries: 10,
+	}
+	entries, err := queries.FindAuditLogEntries(ctx, query)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 1, "failed messages must not be recorded")
+	assert.Equal(t, entries[0].Source, auditdb.SourceP2P)
+	assert.Equal(t, entries[0].Action, "p2pmsg.DecryptionKey")
+	assert.Equal(t, entries[0].Details, msg.LogInfo())
+
+	query.TriggerHash = entries[0].TriggerHash
+	entries, err = queries.FindAuditLogEntries(ctx, query)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 1)
+	query.TriggerHash = []byte{1}
+	entries, err = queries.FindAuditLogEntries(ctx, query)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 0)
+
+	_, err = dbpool.Exec(ctx, "UPDATE audit_log SET actor = 'someone else'")
+	assert.ErrorContains(t, err, "append-only")
+
+	n, err := queries.Prune(ctx, time.Hour)
+	assert.NilError(t, err)
+	assert.Equal(t, n, int64(0))
+	n, err = queries.Prune(ctx, -time.Hour)
+	assert.NilError(t, err)
+	assert.Equal(t, n, int64(1))
+}
+
+var _ p2p.MessageHandler = testMessageHandler{}
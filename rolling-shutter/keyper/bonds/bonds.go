@@ -0,0 +1,301 @@
+// Package bonds syncs the bonds keypers hold in the KeyperBonds contract and warns operators if the
+// bond of their node is too low or if keypers are unbonding so that the keyper set may lose its
+// quorum.
+package bonds
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/alert"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+const (
+	// ContractName is the name of the bonding contract, as used in deployment.Contracts.
+	ContractName = "KeyperBonds"
+	// BondChangedEventName is the name of the event emitted with the new state of a bond.
+	BondChangedEventName = "BondChanged"
+	// MinimumBondChangedEventName is the name of the event emitted when the minimum bond changes.
+	MinimumBondChangedEventName = "MinimumBondChanged"
+)
+
+const pollInterval = 30 * time.Second
+
+// Bond is the bond of a single keyper. Bonded excludes the amount that is being unbonded.
+type Bond struct {
+	Bonded               *big.Int
+	Unbonding            *big.Int
+	UnbondingBlockNumber uint64
+}
+
+// HandleBondChanged stores the bond state announced by a BondChanged event.
+func HandleBondChanged(ctx context.Context, chainDB *chainobsdb.Queries, event eventsyncer.DynamicEvent) error {
+	keyper, ok := event.Args["keyper"].(common.Address)
+	if !ok {
+		return errors.New("missing or invalid keyper in BondChanged event")
+	}
+	bonded, ok := event.Args["bonded"].(*big.Int)
+	if !ok {
+		return errors.New("missing or invalid bonded in BondChanged event")
+	}
+	unbonding, ok := event.Args["unbonding"].(*big.Int)
+	if !ok {
+		return errors.New("missing or invalid unbonding in BondChanged event")
+	}
+	unbondingBlock, ok := event.Args["unbondingBlock"].(uint64)
+	if !ok {
+		return errors.New("missing or invalid unbondingBlock in BondChanged event")
+	}
+	log.Info().
+		Uint64("block-number", event.Raw.BlockNumber).
+		Str("keyper", keyper.Hex()).
+		Str("bonded", bonded.String()).
+		Str("unbonding", unbonding.String()).
+		Uint64("unbonding-block-number", unbondingBlock).
+		Msg("handling BondChanged event from keyper bonds contract")
+
+	err := kprdb.New(chainDB.Conn()).UpsertKeyperBond(ctx, kprdb.UpsertKeyperBondParams{
+		Address:              shdb.EncodeAddress(keyper),
+		Bonded:               shdb.EncodeBigint(bonded),
+		Unbonding:            shdb.EncodeBigint(unbonding),
+		UnbondingBlockNumber: int64(unbondingBlock),
+		BlockNumber:          int64(event.Raw.BlockNumber),
+		LogIndex:             int64(event.Raw.Index),
+	})
+	return errors.Wrap(err, "failed to store keyper bond in db")
+}
+
+// HandleMinimumBondChanged stores the minimum bond announced by a MinimumBondChanged event.
+func HandleMinimumBondChanged(
+	ctx context.Context, chainDB *chainobsdb.Queries, event eventsyncer.DynamicEvent,
+) error {
+	minimumBond, ok := event.Args["minimumBond"].(*big.Int)
+	if !ok {
+		return errors.New("missing or invalid minimumBond in MinimumBondChanged event")
+	}
+	log.Info().
+		Uint64("block-number", event.Raw.BlockNumber).
+		Str("minimum-bond", minimumBond.String()).
+		Msg("handling MinimumBondChanged event from keyper bonds contract")
+
+	err := kprdb.New(chainDB.Conn()).SetMinimumBond(ctx, kprdb.SetMinimumBondParams{
+		MinimumBond: shdb.EncodeBigint(minimumBond),
+		BlockNumber: int64(event.Raw.BlockNumber),
+	})
+	return errors.Wrap(err, "failed to store minimum bond in db")
+}
+
+// Status is the bond status of the node and of the keyper set active at BlockNumber.
+type Status struct {
+	BlockNumber   uint64
+	MinimumBond   *big.Int
+	Own           Bond
+	OwnSufficient bool
+	// Bonded is the number of keypers of the set whose bond is at least the minimum bond.
+	Bonded    int
+	Threshold int
+	// Unbonding lists the keypers of the set with an unbonding amount.
+	Unbonding []common.Address
+}
+
+// QuorumAtRisk reports whether keypers of the set are unbonding while fewer keypers than the
+// threshold hold the minimum bond.
+func (s *Status) QuorumAtRisk() bool {
+	return len(s.Unbonding) > 0 && s.Bonded < s.Threshold
+}
+
+// ComputeStatus computes the bond status of the node with the given address and of the keyper set.
+// Keypers without a bond are treated as having bonded nothing.
+func ComputeStatus(
+	blockNumber uint64,
+	address common.Address,
+	keypers []common.Address,
+	threshold int,
+	bonds map[common.Address]Bond,
+	minimumBond *big.Int,
+) *Status {
+	bondOf := func(keyper common.Address) Bond {
+		b, ok := bonds[keyper]
+		if !ok {
+			return Bond{Bonded: new(big.Int), Unbonding: new(big.Int)}
+		}
+		return b
+	}
+	own := bondOf(address)
+	status := &Status{
+		BlockNumber:   blockNumber,
+		MinimumBond:   minimumBond,
+		Own:           own,
+		OwnSufficient: own.Bonded.Cmp(minimumBond) >= 0,
+		Threshold:     threshold,
+	}
+	for _, keyper := range keypers {
+		b := bondOf(keyper)
+		if b.Bonded.Cmp(minimumBond) >= 0 {
+			status.Bonded++
+		}
+		if b.Unbonding.Sign() > 0 {
+			status.Unbonding = append(status.Unbonding, keyper)
+		}
+	}
+	return status
+}
+
+// Monitor periodically checks the bond status and notifies the operator whenever the bond of the
+// node falls below the minimum bond or an unbond puts the quorum of the keyper set at risk.
+type Monitor struct {
+	dbpool   *pgxpool.Pool
+	l1Client *ethclient.Client
+	address  common.Address
+	notifier alert.Notifier
+
+	ownInsufficient bool
+	quorumAtRisk    bool
+}
+
+func NewMonitor(
+	dbpool *pgxpool.Pool, l1Client *ethclient.Client, address common.Address, notifier alert.Notifier,
+) *Monitor {
+	return &Monitor{
+		dbpool:   dbpool,
+		l1Client: l1Client,
+		address:  address,
+		notifier: notifier,
+	}
+}
+
+// Run periodically checks the bond status until the context is canceled.
+func (m *Monitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := m.check(ctx); err != nil {
+			log.Warn().Err(err).Msg("failed to check keyper bonds")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Monitor) check(ctx context.Context) error {
+	status, ok, err := m.status(ctx)
+	if err != nil || !ok {
+		return err
+	}
+	updateMetrics(status)
+
+	// Only changes are notified, so that operators are not alerted on every check.
+	if !status.OwnSufficient && !m.ownInsufficient {
+		if err := m.notifier.Notify(ctx, ownBondAlert(status)); err != nil {
+			return errors.Wrap(err, "failed to notify about insufficient bond")
+		}
+	}
+	m.ownInsufficient = !status.OwnSufficient
+	if status.QuorumAtRisk() && !m.quorumAtRisk {
+		if err := m.notifier.Notify(ctx, quorumAlert(status)); err != nil {
+			return errors.Wrap(err, "failed to notify about unbonding keypers")
+		}
+	}
+	m.quorumAtRisk = status.QuorumAtRisk()
+	return nil
+}
+
+// status returns the bond status at the current block. ok is false as long as the minimum bond has
+// not been synced, i.e. if the bonding contract is not deployed.
+func (m *Monitor) status(ctx context.Context) (*Status, bool, error) {
+	blockNumber, err := m.l1Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to get current block number")
+	}
+	db := kprdb.New(m.dbpool)
+	encodedMinimum, err := db.GetMinimumBond(ctx)
+	if err == pgx.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to get minimum bond from db")
+	}
+	rows, err := db.GetKeyperBonds(ctx)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to get keyper bonds from db")
+	}
+	bonds := make(map[common.Address]Bond, len(rows))
+	for _, row := range rows {
+		address, err := shdb.DecodeAddress(row.Address)
+		if err != nil {
+			return nil, false, errors.Wrap(err, "failed to decode keyper address")
+		}
+		bonds[address] = Bond{
+			Bonded:               shdb.DecodeBigint(row.Bonded),
+			Unbonding:            shdb.DecodeBigint(row.Unbonding),
+			UnbondingBlockNumber: uint64(row.UnbondingBlockNumber),
+		}
+	}
+
+	var (
+		keypers   []common.Address
+		threshold int
+	)
+	keyperSet, err := chainobsdb.New(m.dbpool).GetKeyperSet(ctx, int64(blockNumber))
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, false, errors.Wrap(err, "failed to get current keyper set from db")
+	}
+	if err == nil {
+		keypers, err = shdb.DecodeAddresses(keyperSet.Keypers)
+		if err != nil {
+			return nil, false, errors.Wrap(err, "failed to decode keyper addresses")
+		}
+		threshold = int(keyperSet.Threshold)
+	}
+	return ComputeStatus(blockNumber, m.address, keypers, threshold, bonds, shdb.DecodeBigint(encodedMinimum)), true, nil
+}
+
+func ownBondAlert(status *Status) alert.Alert {
+	return alert.Alert{
+		Severity: alert.SeverityWarning,
+		Summary:  "the bond of this node is below the minimum bond",
+		Details: map[string]string{
+			"bonded":       status.Own.Bonded.String(),
+			"unbonding":    status.Own.Unbonding.String(),
+			"minimum-bond": status.MinimumBond.String(),
+			"block-number": strconv.FormatUint(status.BlockNumber, 10),
+		},
+		Time: time.Now(),
+	}
+}
+
+func quorumAlert(status *Status) alert.Alert {
+	unbonding := make([]string, len(status.Unbonding))
+	for i, keyper := range status.Unbonding {
+		unbonding[i] = keyper.Hex()
+	}
+	return alert.Alert{
+		Severity: alert.SeverityCritical,
+		Summary:  "keypers are unbonding and fewer than threshold keypers hold the minimum bond",
+		Details: map[string]string{
+			"unbonding-keypers": strings.Join(unbonding, ","),
+			"bonded-keypers":    strconv.Itoa(status.Bonded),
+			"threshold":         strconv.Itoa(status.Threshold),
+			"minimum-bond":      status.MinimumBond.String(),
+			"block-number":      strconv.FormatUint(status.BlockNumber, 10),
+		},
+		Time: time.Now(),
+	}
+}
@@ -0,0 +1,39 @@
+package bonds
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+)
+
+func TestComputeStatus(t *testing.T) {
+	keypers := []common.Address{
+		common.HexToAddress("0x1"),
+		common.HexToAddress("0x2"),
+		common.HexToAddress("0x3"),
+	}
+	bonds := map[common.Address]Bond{
+		keypers[0]: {Bonded: big.NewInt(100), Unbonding: big.NewInt(0)},
+		keypers[1]: {Bonded: big.NewInt(40), Unbonding: big.NewInt(60), UnbondingBlockNumber: 20},
+		// keypers[2] has never bonded
+	}
+
+	status := ComputeStatus(10, keypers[0], keypers, 2, bonds, big.NewInt(100))
+	assert.Check(t, status.OwnSufficient)
+	assert.Equal(t, status.Bonded, 1)
+	assert.DeepEqual(t, status.Unbonding, []common.Address{keypers[1]})
+	assert.Check(t, status.QuorumAtRisk())
+
+	status = ComputeStatus(10, keypers[1], keypers, 1, bonds, big.NewInt(100))
+	assert.Check(t, !status.OwnSufficient)
+	assert.Equal(t, status.Own.Unbonding.Int64(), int64(60))
+	assert.Check(t, !status.QuorumAtRisk())
+
+	status = ComputeStatus(10, keypers[2], keypers, 2, bonds, big.NewInt(40))
+	assert.Check(t, !status.OwnSufficient)
+	assert.Equal(t, status.Own.Bonded.Sign(), 0)
+	assert.Equal(t, status.Bonded, 2)
+	assert.Check(t, !status.QuorumAtRisk())
+}
@@ -0,0 +1,73 @@
+package bonds
+
+import (
+	"math/big"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var metricsOwnBond = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "bonds",
+		Name:      "own_bond_wei",
+		Help:      "Amount bonded by this node, excluding the amount being unbonded",
+	},
+)
+
+var metricsOwnUnbonding = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "bonds",
+		Name:      "own_unbonding_wei",
+		Help:      "Amount this node is unbonding",
+	},
+)
+
+var metricsMinimumBond = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "bonds",
+		Name:      "minimum_bond_wei",
+		Help:      "Bond keypers are required to hold",
+	},
+)
+
+var metricsBondedKeypers = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "bonds",
+		Name:      "bonded_keypers",
+		Help:      "Number of keypers of the current keyper set holding at least the minimum bond",
+	},
+)
+
+var metricsUnbondingKeypers = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "bonds",
+		Name:      "unbonding_keypers",
+		Help:      "Number of keypers of the current keyper set that are unbonding",
+	},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsOwnBond)
+	prometheus.MustRegister(metricsOwnUnbonding)
+	prometheus.MustRegister(metricsMinimumBond)
+	prometheus.MustRegister(metricsBondedKeypers)
+	prometheus.MustRegister(metricsUnbondingKeypers)
+}
+
+func weiToFloat(n *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(n).Float64()
+	return f
+}
+
+func updateMetrics(status *Status) {
+	metricsOwnBond.Set(weiToFloat(status.Own.Bonded))
+	metricsOwnUnbonding.Set(weiToFloat(status.Own.Unbonding))
+	metricsMinimumBond.Set(weiToFloat(status.MinimumBond))
+	metricsBondedKeypers.Set(float64(status.Bonded))
+	metricsUnbondingKeypers.Set(float64(len(status.Unbonding)))
+}
@@ -0,0 +1,231 @@
+// Package checkpoint lets new or recovering keypers skip replaying the history of the chains. A
+// keyper periodically writes a signed checkpoint of the state it derived from the chains: the
+// keyper sets, the encryption keys of the keypers and the eons, together with the cursors up to
+// which it processed the events of the main chain and the blocks of shuttermint. Another node can
+// download checkpoints from several trusted keypers, check that enough of them agree on the state
+// and resume syncing from the cursors instead of from the start.
+//
+// Checkpoints don't contain any key material. The eon key shares of a recovering keyper must be
+// restored from their backups.
+package checkpoint
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+var (
+	hashPrefix      = []byte("\x19Shutter checkpoint:\n")
+	stateHashPrefix = []byte("\x19Shutter checkpoint state:\n")
+)
+
+// Body is the content of a checkpoint.
+type Body struct {
+	InstanceID uint64 `json:"instanceID"`
+	Cursor     Cursor `json:"cursor"`
+	State      State  `json:"state"`
+	// StateHash is the hash of the state. Checkpoints of different keypers with the same state
+	// hash agree on the state, even if they were taken at different cursors.
+	StateHash hexutil.Bytes `json:"stateHash"`
+	// CreatedAt is when the checkpoint was created, as unix timestamp.
+	CreatedAt int64 `json:"createdAt"`
+}
+
+// Cursor is the position up to which the chains have been processed.
+type Cursor struct {
+	NextBlockNumber int64             `json:"nextBlockNumber"`
+	NextLogIndex    int64             `json:"nextLogIndex"`
+	EventTypes      []EventTypeCursor `json:"eventTypes"`
+	// ShuttermintHeight is the last shuttermint block that has been processed.
+	ShuttermintHeight   int64 `json:"shuttermintHeight"`
+	LastCommittedHeight int64 `json:"lastCommittedHeight"`
+}
+
+// EventTypeCursor is the position up to which the events of one type have been processed.
+type EventTypeCursor struct {
+	EventType       string `json:"eventType"`
+	NextBlockNumber int64  `json:"nextBlockNumber"`
+	NextLogIndex    int64  `json:"nextLogIndex"`
+}
+
+// State is the state derived from the chains that all keypers agree on.
+type State struct {
+	KeyperSets     []KeyperSet     `json:"keyperSets"`
+	BatchConfigs   []BatchConfig   `json:"batchConfigs"`
+	EncryptionKeys []EncryptionKey `json:"encryptionKeys"`
+	Eons           []Eon           `json:"eons"`
+}
+
+// KeyperSet is a keyper set as observed in the keyper set manager contract.
+type KeyperSet struct {
+	KeyperConfigIndex     int64    `json:"keyperConfigIndex"`
+	ActivationBlockNumber int64    `json:"activationBlockNumber"`
+	Keypers               []string `json:"keypers"`
+	Threshold             int32    `json:"threshold"`
+}
+
+// BatchConfig is a keyper set as announced on shuttermint.
+type BatchConfig struct {
+	KeyperConfigIndex     int32    `json:"keyperConfigIndex"`
+	Height                int64    `json:"height"`
+	Keypers               []string `json:"keypers"`
+	Threshold             int32    `json:"threshold"`
+	Started               bool     `json:"started"`
+	ActivationBlockNumber int64    `json:"activationBlockNumber"`
+}
+
+// EncryptionKey is the key a keyper receives the messages of the DKG encrypted with.
+type EncryptionKey struct {
+	Address   string        `json:"address"`
+	PublicKey hexutil.Bytes `json:"publicKey"`
+}
+
+// Eon is the metadata of an eon. PublicKey is the eon public key if the DKG succeeded.
+type Eon struct {
+	Eon                   int64         `json:"eon"`
+	Height                int64         `json:"height"`
+	ActivationBlockNumber int64         `json:"activationBlockNumber"`
+	KeyperConfigIndex     int64         `json:"keyperConfigIndex"`
+	PublicKey             hexutil.Bytes `json:"publicKey,omitempty"`
+}
+
+// Checkpoint is a body signed by the keyper that created it.
+type Checkpoint struct {
+	Body      Body           `json:"body"`
+	Signer    common.Address `json:"signer"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// Hash returns the hash of the state.
+func (s *State) Hash() []byte {
+	payload, err := json.Marshal(s)
+	if err != nil {
+		panic(err) // a State can always be encoded
+	}
+	return ethcrypto.Keccak256(stateHashPrefix, payload)
+}
+
+// Hash returns the hash the keyper signs. It covers the JSON encoding of the body.
+func (b *Body) Hash() []byte {
+	payload, err := json.Marshal(b)
+	if err != nil {
+		panic(err) // a Body can always be encoded
+	}
+	return ethcrypto.Keccak256(hashPrefix, payload)
+}
+
+// Verify checks that the checkpoint has been signed by its signer and that the state hash matches
+// the state. Whether the signer is trusted is up to the caller.
+func Verify(checkpoint *Checkpoint) error {
+	pubkey, err := ethcrypto.SigToPub(checkpoint.Body.Hash(), checkpoint.Signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+	if signer := ethcrypto.PubkeyToAddress(*pubkey); signer != checkpoint.Signer {
+		return errors.Errorf("checkpoint signed by %s instead of %s", signer.Hex(), checkpoint.Signer.Hex())
+	}
+	if !bytes.Equal(checkpoint.Body.State.Hash(), checkpoint.Body.StateHash) {
+		return errors.New("state hash doesn't match the state")
+	}
+	return nil
+}
+
+// Agree checks the checkpoints of the trusted signers and returns the body of a checkpoint whose
+// state at least minSigners of them agree on. Checkpoints with an invalid signature, of untrusted
+// signers or of another instance are ignored. As the agreeing checkpoints may have been taken at
+// different cursors, the earliest cursors are returned, so that no event changing the state after
+// the checkpoint is skipped.
+func Agree(
+	checkpoints []*Checkpoint, instanceID uint64, trusted []common.Address, minSigners int,
+) (*Body, []common.Address, error) {
+	isTrusted := map[common.Address]bool{}
+	for _, signer := range trusted {
+		isTrusted[signer] = true
+	}
+	type group struct {
+		bodies  []*Body
+		signers map[common.Address]bool
+	}
+	groups := map[string]*group{}
+	for _, checkpoint := range checkpoints {
+		if !isTrusted[checkpoint.Signer] || checkpoint.Body.InstanceID != instanceID {
+			continue
+		}
+		if err := Verify(checkpoint); err != nil {
+			continue
+		}
+		key := hexutil.Encode(checkpoint.Body.StateHash)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{signers: map[common.Address]bool{}}
+			groups[key] = g
+		}
+		g.bodies = append(g.bodies, &checkpoint.Body)
+		g.signers[checkpoint.Signer] = true
+	}
+
+	var best *group
+	for _, g := range groups {
+		if best == nil || len(g.signers) > len(best.signers) {
+			best = g
+		}
+	}
+	if best == nil || len(best.signers) < minSigners {
+		agreeing := 0
+		if best != nil {
+			agreeing = len(best.signers)
+		}
+		return nil, nil, errors.Errorf(
+			"only %d trusted signers agree on the state, %d required", agreeing, minSigners,
+		)
+	}
+
+	body := *best.bodies[0]
+	for _, b := range best.bodies[1:] {
+		body.Cursor = earliestCursor(body.Cursor, b.Cursor)
+	}
+	signers := make([]common.Address, 0, len(best.signers))
+	for signer := range best.signers {
+		signers = append(signers, signer)
+	}
+	sort.Slice(signers, func(i, j int) bool { return signers[i].Hex() < signers[j].Hex() })
+	return &body, signers, nil
+}
+
+// earliestCursor returns the earlier of the positions of a and b in each of the chains. The main
+// chain and shuttermint are processed independently of each other, so the positions are compared
+// separately.
+func earliestCursor(a, b Cursor) Cursor {
+	c := a
+	if before(b.NextBlockNumber, b.NextLogIndex, a.NextBlockNumber, a.NextLogIndex) {
+		c.NextBlockNumber, c.NextLogIndex = b.NextBlockNumber, b.NextLogIndex
+	}
+	if b.ShuttermintHeight < a.ShuttermintHeight {
+		c.ShuttermintHeight, c.LastCommittedHeight = b.ShuttermintHeight, b.LastCommittedHeight
+	}
+	eventTypes := map[string]EventTypeCursor{}
+	for _, e := range a.EventTypes {
+		eventTypes[e.EventType] = e
+	}
+	c.EventTypes = []EventTypeCursor{}
+	for _, e := range b.EventTypes {
+		if f, ok := eventTypes[e.EventType]; ok {
+			if before(e.NextBlockNumber, e.NextLogIndex, f.NextBlockNumber, f.NextLogIndex) {
+				f = e
+			}
+			// event types only one of the checkpoints has synced start at the overall cursor
+			c.EventTypes = append(c.EventTypes, f)
+		}
+	}
+	return c
+}
+
+func before(blockA, logIndexA, blockB, logIndexB int64) bool {
+	return blockA < blockB || blockA == blockB && logIndexA < logIndexB
+}
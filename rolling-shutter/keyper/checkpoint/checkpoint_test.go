@@ -0,0 +1,119 @@
+package checkpoint
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+)
+
+func newBody() Body {
+	body := Body{
+		InstanceID: 42,
+		Cursor: Cursor{
+			NextBlockNumber: 100,
+			NextLogIndex:    3,
+			EventTypes: []EventTypeCursor{
+				{EventType: "KeyperSetAdded", NextBlockNumber: 100, NextLogIndex: 3},
+				{EventType: "ShutterRegistryChanged", NextBlockNumber: 90, NextLogIndex: 0},
+			},
+			ShuttermintHeight:   500,
+			LastCommittedHeight: 499,
+		},
+		State: State{
+			KeyperSets: []KeyperSet{
+				{KeyperConfigIndex: 1, ActivationBlockNumber: 10, Keypers: []string{"0x1"}, Threshold: 1},
+			},
+			BatchConfigs:   []BatchConfig{},
+			EncryptionKeys: []EncryptionKey{{Address: "0x1", PublicKey: []byte{1, 2, 3}}},
+			Eons:           []Eon{{Eon: 1, Height: 20, ActivationBlockNumber: 10, KeyperConfigIndex: 1}},
+		},
+		CreatedAt: 1700000000,
+	}
+	body.StateHash = body.State.Hash()
+	return body
+}
+
+func sign(t *testing.T, body Body, key *ecdsa.PrivateKey) *Checkpoint {
+	t.Helper()
+	signature, err := ethcrypto.Sign(body.Hash(), key)
+	assert.NilError(t, err)
+	return &Checkpoint{
+		Body:      body,
+		Signer:    ethcrypto.PubkeyToAddress(key.PublicKey),
+		Signature: signature,
+	}
+}
+
+func newKeys(t *testing.T, n int) ([]*ecdsa.PrivateKey, []common.Address) {
+	t.Helper()
+	keys := []*ecdsa.PrivateKey{}
+	addresses := []common.Address{}
+	for i := 0; i < n; i++ {
+		key, err := ethcrypto.GenerateKey()
+		assert.NilError(t, err)
+		keys = append(keys, key)
+		addresses = append(addresses, ethcrypto.PubkeyToAddress(key.PublicKey))
+	}
+	return keys, addresses
+}
+
+func TestVerify(t *testing.T) {
+	keys, _ := newKeys(t, 1)
+	assert.NilError(t, Verify(sign(t, newBody(), keys[0])))
+
+	cp := sign(t, newBody(), keys[0])
+	cp.Body.CreatedAt++
+	assert.ErrorContains(t, Verify(cp), "checkpoint signed by")
+
+	body := newBody()
+	body.State.Eons[0].ActivationBlockNumber++
+	assert.ErrorContains(t, Verify(sign(t, body, keys[0])), "state hash doesn't match")
+}
+
+func TestAgree(t *testing.T) {
+	keys, trusted := newKeys(t, 4)
+	outsiders, _ := newKeys(t, 2)
+
+	late := newBody()
+	late.Cursor.NextBlockNumber = 120
+	late.Cursor.ShuttermintHeight = 450
+	late.Cursor.LastCommittedHeight = 449
+	late.Cursor.EventTypes = []EventTypeCursor{
+		{EventType: "KeyperSetAdded", NextBlockNumber: 95, NextLogIndex: 1},
+		{EventType: "ShutterRegistryChanged", NextBlockNumber: 120, NextLogIndex: 0},
+	}
+	diverging := newBody()
+	diverging.State.Eons = append(diverging.State.Eons, Eon{Eon: 2})
+	diverging.StateHash = diverging.State.Hash()
+	otherInstance := newBody()
+	otherInstance.InstanceID++
+
+	checkpoints := []*Checkpoint{
+		sign(t, newBody(), keys[0]),
+		sign(t, late, keys[1]),
+		sign(t, diverging, keys[2]),
+		sign(t, otherInstance, keys[3]),
+		sign(t, diverging, outsiders[0]),
+		sign(t, diverging, outsiders[1]),
+	}
+	body, signers, err := Agree(checkpoints, 42, trusted, 2)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, len(signers), 2)
+	assert.DeepEqual(t, body.StateHash, newBody().StateHash)
+	assert.DeepEqual(t, body.Cursor, Cursor{
+		NextBlockNumber: 100,
+		NextLogIndex:    3,
+		EventTypes: []EventTypeCursor{
+			{EventType: "KeyperSetAdded", NextBlockNumber: 95, NextLogIndex: 1},
+			{EventType: "ShutterRegistryChanged", NextBlockNumber: 90, NextLogIndex: 0},
+		},
+		ShuttermintHeight:   450,
+		LastCommittedHeight: 449,
+	})
+
+	_, _, err = Agree(checkpoints, 42, trusted, 3)
+	assert.ErrorContains(t, err, "only 2 trusted signers agree on the state, 3 required")
+}
@@ -0,0 +1,65 @@
+package checkpoint
+
+import (
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+type Config struct {
+	Interval  *enctime.Duration `comment:"How often a signed checkpoint of the state derived from the chains is created, 0 disables checkpoints. The latest one is served at /checkpoint of the HTTP API"`
+	Directory string            `comment:"Directory the checkpoints are written to. If it's empty, they are only kept in memory"`
+	Kept      uint64            `comment:"Number of most recent checkpoints kept in Directory"`
+}
+
+func (c *Config) Init() {
+	c.Interval = &enctime.Duration{}
+}
+
+func (c *Config) Name() string {
+	return "checkpoint"
+}
+
+// Enabled reports whether checkpoints are created.
+func (c *Config) Enabled() bool {
+	return c.Interval.Duration > 0
+}
+
+func (c *Config) Validate() error {
+	if c.Interval.Duration < 0 {
+		return errors.New("checkpoint Interval must not be negative")
+	}
+	if c.Enabled() && c.Directory != "" && c.Kept == 0 {
+		return errors.New("checkpoint Kept must be positive if checkpoints are written to a directory")
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.Interval = &enctime.Duration{
+		Duration: time.Hour,
+	}
+	c.Directory = ""
+	c.Kept = 24
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,43 @@
+package checkpoint
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxCheckpointSize limits how much is read from a checkpoint source.
+const maxCheckpointSize = 64 << 20
+
+// Fetch reads a checkpoint from source, which is either the URL of the /checkpoint endpoint of a
+// keyper or the path of a file written by the Writer.
+func Fetch(ctx context.Context, source string) (*Checkpoint, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		return Decode(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCheckpointSize))
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
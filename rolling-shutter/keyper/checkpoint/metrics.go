@@ -0,0 +1,16 @@
+package checkpoint
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsCheckpointCreatedAt = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "keyper",
+		Name:      "checkpoint_created_at_seconds",
+		Help:      "Unix timestamp of the latest checkpoint",
+	},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsCheckpointCreatedAt)
+}
@@ -0,0 +1,248 @@
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"sort"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// Create creates a checkpoint of the keyper's current state and signs it with its key. The state
+// and the cursors are read in a single snapshot of the database, so that they are consistent.
+func Create(
+	ctx context.Context, dbpool *pgxpool.Pool, instanceID uint64, privKey *ecdsa.PrivateKey,
+) (*Checkpoint, error) {
+	body := Body{InstanceID: instanceID, CreatedAt: time.Now().Unix()}
+	txOptions := pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+	err := dbpool.BeginTxFunc(ctx, txOptions, func(tx pgx.Tx) error {
+		var err error
+		body.Cursor, err = loadCursor(ctx, tx)
+		if err != nil {
+			return err
+		}
+		body.State, err = loadState(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	body.StateHash = body.State.Hash()
+	signature, err := ethcrypto.Sign(body.Hash(), privKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Checkpoint{
+		Body:      body,
+		Signer:    ethcrypto.PubkeyToAddress(privKey.PublicKey),
+		Signature: signature,
+	}, nil
+}
+
+func loadCursor(ctx context.Context, tx pgx.Tx) (Cursor, error) {
+	cursor := Cursor{EventTypes: []EventTypeCursor{}}
+	obsDB := chainobsdb.New(tx)
+	progress, err := obsDB.GetEventSyncProgress(ctx)
+	if err != nil {
+		return cursor, errors.Wrap(err, "failed to get event sync progress from db")
+	}
+	cursor.NextBlockNumber = int64(progress.NextBlockNumber)
+	cursor.NextLogIndex = int64(progress.NextLogIndex)
+	eventTypes, err := obsDB.GetEventTypeSyncProgresses(ctx)
+	if err != nil {
+		return cursor, errors.Wrap(err, "failed to get sync progress of event types from db")
+	}
+	for _, eventType := range eventTypes {
+		cursor.EventTypes = append(cursor.EventTypes, EventTypeCursor(eventType))
+	}
+
+	syncMeta, err := kprdb.New(tx).TMGetSyncMeta(ctx)
+	if err != nil {
+		return cursor, errors.Wrap(err, "failed to get shuttermint sync progress from db")
+	}
+	cursor.ShuttermintHeight = syncMeta.CurrentBlock
+	cursor.LastCommittedHeight = syncMeta.LastCommittedHeight
+	return cursor, nil
+}
+
+func loadState(ctx context.Context, tx pgx.Tx) (State, error) {
+	state := State{
+		KeyperSets:     []KeyperSet{},
+		BatchConfigs:   []BatchConfig{},
+		EncryptionKeys: []EncryptionKey{},
+		Eons:           []Eon{},
+	}
+	keyperSets, err := chainobsdb.New(tx).GetKeyperSets(ctx)
+	if err != nil {
+		return state, errors.Wrap(err, "failed to get keyper sets from db")
+	}
+	for _, keyperSet := range keyperSets {
+		state.KeyperSets = append(state.KeyperSets, KeyperSet(keyperSet))
+	}
+
+	db := kprdb.New(tx)
+	batchConfigs, err := db.GetBatchConfigs(ctx)
+	if err != nil {
+		return state, errors.Wrap(err, "failed to get batch configs from db")
+	}
+	for _, batchConfig := range batchConfigs {
+		state.BatchConfigs = append(state.BatchConfigs, BatchConfig(batchConfig))
+	}
+
+	encryptionKeys, err := db.GetEncryptionKeys(ctx)
+	if err != nil {
+		return state, errors.Wrap(err, "failed to get encryption keys from db")
+	}
+	for _, key := range encryptionKeys {
+		state.EncryptionKeys = append(state.EncryptionKeys, EncryptionKey{
+			Address:   key.Address,
+			PublicKey: key.EncryptionPublicKey,
+		})
+	}
+	sort.Slice(state.EncryptionKeys, func(i, j int) bool {
+		return state.EncryptionKeys[i].Address < state.EncryptionKeys[j].Address
+	})
+
+	eons, err := db.GetAllEons(ctx)
+	if err != nil {
+		return state, errors.Wrap(err, "failed to get eons from db")
+	}
+	for _, eon := range eons {
+		e := Eon{
+			Eon:                   eon.Eon,
+			Height:                eon.Height,
+			ActivationBlockNumber: eon.ActivationBlockNumber,
+			KeyperConfigIndex:     eon.KeyperConfigIndex,
+		}
+		dkgResult, err := db.GetDKGResult(ctx, eon.Eon)
+		if err != nil && err != pgx.ErrNoRows {
+			return state, errors.Wrapf(err, "failed to get dkg result of eon %d from db", eon.Eon)
+		}
+		if err == nil && dkgResult.Success {
+			pureResult, err := shdb.DecodePureDKGResult(dkgResult.PureResult)
+			if err != nil {
+				return state, errors.Wrapf(err, "failed to decode dkg result of eon %d", eon.Eon)
+			}
+			e.PublicKey = pureResult.PublicKey.Marshal()
+		}
+		state.Eons = append(state.Eons, e)
+	}
+	return state, nil
+}
+
+// Restore stores the state of the checkpoint in an empty keyper database and sets the cursors, so
+// that the keyper resumes syncing the chains from there. pureResults are the keyper's DKG results
+// recovered from its backups. Each of them must match the eon public key in the checkpoint.
+func Restore(ctx context.Context, tx pgx.Tx, body *Body, pureResults [][]byte) error {
+	db := kprdb.New(tx)
+	obsDB := chainobsdb.New(tx)
+	eons, err := db.GetAllEons(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get eons from db")
+	}
+	keyperSets, err := obsDB.GetKeyperSets(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get keyper sets from db")
+	}
+	numBatchConfigs, err := db.CountBatchConfigs(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to count batch configs in db")
+	}
+	if len(eons) > 0 || len(keyperSets) > 0 || numBatchConfigs > 0 {
+		return errors.New("checkpoints can only be restored into a newly initialized database")
+	}
+
+	for _, keyperSet := range body.State.KeyperSets {
+		if err := obsDB.InsertKeyperSet(ctx, chainobsdb.InsertKeyperSetParams(keyperSet)); err != nil {
+			return errors.Wrapf(err, "failed to insert keyper set %d", keyperSet.KeyperConfigIndex)
+		}
+	}
+	for _, batchConfig := range body.State.BatchConfigs {
+		if err := db.InsertBatchConfig(ctx, kprdb.InsertBatchConfigParams(batchConfig)); err != nil {
+			return errors.Wrapf(err, "failed to insert batch config %d", batchConfig.KeyperConfigIndex)
+		}
+	}
+	for _, key := range body.State.EncryptionKeys {
+		err := db.InsertEncryptionKey(ctx, kprdb.InsertEncryptionKeyParams{
+			Address:             key.Address,
+			EncryptionPublicKey: key.PublicKey,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to insert encryption key of %s", key.Address)
+		}
+	}
+	for _, eon := range body.State.Eons {
+		err := db.InsertEon(ctx, kprdb.InsertEonParams{
+			Eon:                   eon.Eon,
+			Height:                eon.Height,
+			ActivationBlockNumber: eon.ActivationBlockNumber,
+			KeyperConfigIndex:     eon.KeyperConfigIndex,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to insert eon %d", eon.Eon)
+		}
+	}
+
+	for _, pureResult := range pureResults {
+		if err := restoreDKGResult(ctx, db, body, pureResult); err != nil {
+			return err
+		}
+	}
+
+	err = obsDB.UpdateEventSyncProgress(ctx, chainobsdb.UpdateEventSyncProgressParams{
+		NextBlockNumber: int32(body.Cursor.NextBlockNumber),
+		NextLogIndex:    int32(body.Cursor.NextLogIndex),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to set event sync progress")
+	}
+	for _, eventType := range body.Cursor.EventTypes {
+		err := obsDB.UpdateEventTypeSyncProgress(ctx, chainobsdb.UpdateEventTypeSyncProgressParams(eventType))
+		if err != nil {
+			return errors.Wrapf(err, "failed to set sync progress of %s events", eventType.EventType)
+		}
+	}
+	err = db.TMSetSyncMeta(ctx, kprdb.TMSetSyncMetaParams{
+		CurrentBlock:        body.Cursor.ShuttermintHeight,
+		LastCommittedHeight: body.Cursor.LastCommittedHeight,
+		SyncTimestamp:       time.Now(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to set shuttermint sync progress")
+	}
+	return nil
+}
+
+func restoreDKGResult(ctx context.Context, db *kprdb.Queries, body *Body, pureResult []byte) error {
+	result, err := shdb.DecodePureDKGResult(pureResult)
+	if err != nil {
+		return errors.Wrap(err, "recovered data is not a DKG result")
+	}
+	var eon *Eon
+	for i := range body.State.Eons {
+		if body.State.Eons[i].Eon == int64(result.Eon) {
+			eon = &body.State.Eons[i]
+		}
+	}
+	if eon == nil || len(eon.PublicKey) == 0 {
+		return errors.Errorf("checkpoint has no eon public key of eon %d", result.Eon)
+	}
+	if !bytes.Equal(result.PublicKey.Marshal(), eon.PublicKey) {
+		return errors.Errorf("DKG result of eon %d doesn't match the eon public key in the checkpoint", result.Eon)
+	}
+	err = db.InsertDKGResult(ctx, kprdb.InsertDKGResultParams{
+		Eon:        eon.Eon,
+		Success:    true,
+		PureResult: pureResult,
+	})
+	return errors.Wrapf(err, "failed to insert DKG result of eon %d", eon.Eon)
+}
@@ -0,0 +1,168 @@
+package checkpoint
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const fileNamePrefix = "checkpoint-"
+
+// Writer periodically creates a checkpoint and writes it to the configured directory. The latest
+// checkpoint is kept in memory to be served by Handler.
+type Writer struct {
+	config     *Config
+	dbpool     *pgxpool.Pool
+	instanceID uint64
+	privKey    *ecdsa.PrivateKey
+
+	mux    sync.Mutex
+	latest *Checkpoint
+}
+
+func NewWriter(config *Config, dbpool *pgxpool.Pool, instanceID uint64, privKey *ecdsa.PrivateKey) *Writer {
+	return &Writer{
+		config:     config,
+		dbpool:     dbpool,
+		instanceID: instanceID,
+		privKey:    privKey,
+	}
+}
+
+// FileName returns the name of the file of a checkpoint created at the given unix timestamp.
+func FileName(createdAt int64) string {
+	return fmt.Sprintf("%s%d.json", fileNamePrefix, createdAt)
+}
+
+// Latest returns the most recently created checkpoint, or nil if there is none yet.
+func (w *Writer) Latest() *Checkpoint {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	return w.latest
+}
+
+func (w *Writer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.config.Interval.Duration)
+	defer ticker.Stop()
+	for {
+		if err := w.write(ctx); err != nil {
+			log.Error().Err(err).Msg("failed to write checkpoint")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Writer) write(ctx context.Context) error {
+	cp, err := Create(ctx, w.dbpool, w.instanceID, w.privKey)
+	if err != nil {
+		return err
+	}
+	w.mux.Lock()
+	w.latest = cp
+	w.mux.Unlock()
+	metricsCheckpointCreatedAt.Set(float64(cp.Body.CreatedAt))
+
+	if w.config.Directory == "" {
+		return nil
+	}
+	path := filepath.Join(w.config.Directory, FileName(cp.Body.CreatedAt))
+	if err := writeFile(path, cp); err != nil {
+		return err
+	}
+	log.Info().
+		Int64("next-block-number", cp.Body.Cursor.NextBlockNumber).
+		Int64("shuttermint-height", cp.Body.Cursor.ShuttermintHeight).
+		Str("path", path).
+		Msg("wrote checkpoint")
+	return w.prune()
+}
+
+// prune deletes all but the most recent checkpoints in the directory.
+func (w *Writer) prune() error {
+	entries, err := os.ReadDir(w.config.Directory)
+	if err != nil {
+		return errors.Wrap(err, "failed to list checkpoints")
+	}
+	names := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, fileNamePrefix) && strings.HasSuffix(name, ".json") {
+			names = append(names, name)
+		}
+	}
+	if uint64(len(names)) <= w.config.Kept {
+		return nil
+	}
+	// the timestamps in the names all have the same number of digits, so they sort by age
+	sort.Strings(names)
+	for _, name := range names[:uint64(len(names))-w.config.Kept] {
+		if err := os.Remove(filepath.Join(w.config.Directory, name)); err != nil {
+			return errors.Wrap(err, "failed to delete old checkpoint")
+		}
+	}
+	return nil
+}
+
+// writeFile writes the checkpoint to path. It's written to a temporary file first, so that path
+// never holds a partial checkpoint.
+func writeFile(path string, cp *Checkpoint) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+filepath.Base(path))
+	if err != nil {
+		return errors.Wrap(err, "failed to create checkpoint")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "failed to write checkpoint")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to write checkpoint")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), path), "failed to write checkpoint")
+}
+
+// Decode decodes a checkpoint as written by the Writer or served by Handler.
+func Decode(data []byte) (*Checkpoint, error) {
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, errors.Wrap(err, "failed to decode checkpoint")
+	}
+	return cp, nil
+}
+
+// Handler serves the latest checkpoint of the writer. It responds with 404 if checkpoints are
+// disabled, i.e. the writer is nil, or none has been created yet.
+func Handler(w *Writer) http.HandlerFunc {
+	return func(rw http.ResponseWriter, _ *http.Request) {
+		var cp *Checkpoint
+		if w != nil {
+			cp = w.Latest()
+		}
+		if cp == nil {
+			http.Error(rw, "no checkpoint available", http.StatusNotFound)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(cp)
+	}
+}
@@ -4,15 +4,33 @@ import (
 	"crypto/ed25519"
 	"crypto/rand"
 	"io"
+	"math"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/pkg/errors"
 
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/checkpoint"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/dkgphase"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/escrow"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/mempool"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/shadow"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/sla"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/triggerpolicy"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/alert"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/clockcheck"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/keys"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/featureflag"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/httpauth"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/metricsserver"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/opapproval"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/plugin"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/storagemonitor"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/tlsconfig"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 )
 
@@ -32,6 +50,28 @@ func (c *Config) Init() {
 	c.Ethereum = configuration.NewEthnodeConfig()
 	c.Shuttermint = NewShuttermintConfig()
 	c.Metrics = metricsserver.NewConfig()
+	c.HTTPAuth = httpauth.NewConfig()
+	c.HTTPTLS = tlsconfig.NewServerConfig()
+	c.AuditLogRetention = &enctime.Duration{}
+	c.ProvenanceRetention = &enctime.Duration{}
+	c.GCEpochHorizon = &enctime.Duration{}
+	c.ActivationAlertLeadTime = &enctime.Duration{}
+	c.MetricsSnapshotInterval = &enctime.Duration{}
+	c.ShareVerificationWindow = &enctime.Duration{}
+	c.PublicationDelay = &enctime.Duration{}
+	c.TriggerOffset = &enctime.Duration{}
+	c.Alerting = alert.NewConfig()
+	c.Storage = storagemonitor.NewConfig()
+	c.Clock = clockcheck.NewConfig()
+	c.OperatorApproval = opapproval.NewConfig()
+	c.Escrow = escrow.NewConfig()
+	c.Checkpoint = checkpoint.NewConfig()
+	c.SLA = sla.NewConfig()
+	c.TriggerPolicy = triggerpolicy.NewConfig()
+	c.Shadow = shadow.NewConfig()
+	c.Mempool = mempool.NewConfig()
+	c.Plugins = plugin.NewConfig()
+	c.Features = featureflag.NewConfig()
 }
 
 type Config struct {
@@ -40,15 +80,125 @@ type Config struct {
 
 	HTTPEnabled       bool
 	HTTPListenAddress string
+	HTTPAuth          *httpauth.Config
+	HTTPTLS           *tlsconfig.ServerConfig
+
+	AuditLogRetention   *enctime.Duration `comment:"How long entries of the audit log are kept, 0 keeps them forever"`
+	ProvenanceRetention *enctime.Duration `comment:"How long the records of which peer delivered each accepted p2p message are kept, 0 keeps them forever"`
+
+	GCEpochHorizon *enctime.Duration `comment:"How long decryption key shares are kept after the key is known, 0 keeps them forever"`
+	GCEonHorizon   uint64            `comment:"Number of past eons whose decryption key shares are kept, 0 keeps them forever"`
+
+	QuorumWindow uint64 `comment:"Number of recent epochs over which the availability of the keypers is tracked, 0 disables tracking"`
+
+	ActivationAlertLeadTime *enctime.Duration `comment:"Alert again once a scheduled keyper set affecting this node is estimated to activate within this time, 0 disables the alert"`
+
+	MetricsSnapshotInterval *enctime.Duration `comment:"How often key metrics are persisted to the database for post-mortem analysis, 0 disables snapshots"`
+	MetricsSnapshotsKept    uint64            `comment:"Number of most recent metrics snapshots kept in the database"`
+
+	ShareVerificationWindow *enctime.Duration `comment:"How long received decryption key shares are collected to be verified in one batch, 0 verifies each share on its own"`
+
+	ShareAggregators uint64 `comment:"Number of keypers per epoch our decryption key shares are sent to directly, which then gossip the aggregated key, instead of gossiping the shares to all peers. 0 gossips the shares. Keypers that don't aggregate an epoch don't see the shares of the other keypers for it"`
+
+	PublicationDelay *enctime.Duration `comment:"Minimum time between the timestamp of a trigger's block and publishing our decryption key shares for it, 0 publishes them immediately. All keypers of a set must use the same delay, otherwise keys may become known earlier"`
+
+	TriggerOffsetBlocks uint64            `comment:"Number of blocks the collator waits for after the epoch boundary before triggering decryption. Triggers referring to an earlier block than the pre-announced one plus this number are ignored. Must match the collator of the deployment"`
+	TriggerOffset       *enctime.Duration `comment:"Time the collator waits for after the epoch boundary before triggering decryption. Triggers received earlier than the pre-announced time plus this offset are ignored. Must match the collator of the deployment"`
+
+	MaxEventAttempts uint64 `comment:"Number of times handling a contract event is attempted before it is moved to the dead events and skipped, 0 retries forever"`
+
+	RefuseOutdatedEons bool `comment:"Don't take part in the DKG of new eons while this node is below the minimum version announced in the VersionRequirements contract"`
+
+	EpochIDMode string `comment:"How epoch ids of decryption triggers are derived: sequential (chosen by the collator) or blockhash (from the number and hash of the trigger block, so that they are unpredictable until the block exists) or slot (the beacon chain slot of the trigger block, requires Ethereum.BeaconAPIURL)"`
 
 	P2P         *p2p.Config
 	Ethereum    *configuration.EthnodeConfig
 	Shuttermint *ShuttermintConfig
 	Metrics     *metricsserver.MetricsConfig
+	Alerting    *alert.Config
+	Storage     *storagemonitor.Config
+	Clock       *clockcheck.Config
+	Features    *featureflag.Config
+
+	OperatorApproval *opapproval.Config
+	Escrow           *escrow.Config
+	Checkpoint       *checkpoint.Config
+	SLA              *sla.Config
+	TriggerPolicy    *triggerpolicy.Config
+	Shadow           *shadow.Config
+	Mempool          *mempool.Config
+	Plugins          *plugin.Config
 }
 
 func (c *Config) Validate() error {
-	return nil
+	if err := c.Ethereum.Validate(); err != nil {
+		return err
+	}
+	if err := c.HTTPAuth.Validate(); err != nil {
+		return err
+	}
+	if err := c.HTTPTLS.Validate(); err != nil {
+		return err
+	}
+	if err := c.Alerting.Validate(); err != nil {
+		return err
+	}
+	if err := c.Storage.Validate(); err != nil {
+		return err
+	}
+	if err := c.Clock.Validate(); err != nil {
+		return err
+	}
+	if err := c.Features.Validate(); err != nil {
+		return err
+	}
+	if err := c.OperatorApproval.Validate(); err != nil {
+		return err
+	}
+	if err := c.Escrow.Validate(); err != nil {
+		return err
+	}
+	if err := c.Checkpoint.Validate(); err != nil {
+		return err
+	}
+	if err := c.SLA.Validate(); err != nil {
+		return err
+	}
+	if err := c.TriggerPolicy.Validate(); err != nil {
+		return err
+	}
+	if err := c.Shadow.Validate(); err != nil {
+		return err
+	}
+	if err := c.Mempool.Validate(); err != nil {
+		return err
+	}
+	if err := c.Plugins.Validate(); err != nil {
+		return err
+	}
+	if c.Shadow.Comparing() && c.Shadow.LiveDatabaseURL == c.DatabaseURL {
+		return errors.New("a shadow node must not use the database of the live node")
+	}
+	if c.PublicationDelay.Duration < 0 {
+		return errors.New("PublicationDelay must not be negative")
+	}
+	if c.TriggerOffset.Duration < 0 {
+		return errors.New("TriggerOffset must not be negative")
+	}
+	if c.QuorumWindow > math.MaxInt32 {
+		return errors.Errorf("QuorumWindow must not exceed %d", math.MaxInt32)
+	}
+	mode, err := epochid.ParseMode(c.EpochIDMode)
+	if err != nil {
+		return err
+	}
+	if mode == epochid.ModeSlot && c.Ethereum.BeaconAPIURL == "" {
+		return errors.Errorf("EpochIDMode %s requires an Ethereum.BeaconAPIURL", mode)
+	}
+	if c.MetricsSnapshotInterval.Duration > 0 && c.MetricsSnapshotsKept == 0 {
+		return errors.New("MetricsSnapshotsKept must be positive if metrics snapshots are enabled")
+	}
+	return c.Metrics.Validate()
 }
 
 func (c *Config) GetAddress() common.Address {
@@ -59,6 +209,17 @@ func (c *Config) GetDKGPhaseLength() *dkgphase.PhaseLength {
 	return dkgphase.NewConstantPhaseLength(c.Shuttermint.DKGPhaseLength)
 }
 
+// GetEpochIDMode returns the mode epoch ids of decryption triggers are derived in. It must only be
+// called on a validated config.
+func (c *Config) GetEpochIDMode() epochid.Mode {
+	mode, _ := epochid.ParseMode(c.EpochIDMode)
+	return mode
+}
+
+func (c *Config) GetRefuseOutdatedEons() bool {
+	return c.RefuseOutdatedEons
+}
+
 func (c *Config) GetValidatorPublicKey() ed25519.PublicKey {
 	return c.Shuttermint.ValidatorPublicKey.Key
 }
@@ -81,9 +242,46 @@ func (c *Config) GetHTTPListenAddress() string {
 	return c.HTTPListenAddress
 }
 
+func (c *Config) GetHTTPAuth() *httpauth.Config {
+	return c.HTTPAuth
+}
+
+func (c *Config) GetHTTPTLS() *tlsconfig.ServerConfig {
+	return c.HTTPTLS
+}
+
+func (c *Config) GetOperatorApproval() *opapproval.Config {
+	return c.OperatorApproval
+}
+
 func (c *Config) SetDefaultValues() error {
 	c.HTTPEnabled = false
 	c.HTTPListenAddress = ":3000"
+	c.AuditLogRetention = &enctime.Duration{
+		Duration: 90 * 24 * time.Hour,
+	}
+	c.ProvenanceRetention = &enctime.Duration{
+		Duration: 30 * 24 * time.Hour,
+	}
+	c.GCEpochHorizon = &enctime.Duration{
+		Duration: time.Hour,
+	}
+	c.GCEonHorizon = 2
+	c.QuorumWindow = 100
+	c.ActivationAlertLeadTime = &enctime.Duration{
+		Duration: 6 * time.Hour,
+	}
+	c.MetricsSnapshotInterval = &enctime.Duration{
+		Duration: time.Minute,
+	}
+	c.MetricsSnapshotsKept = 7 * 24 * 60
+	c.MaxEventAttempts = 10
+	c.ShareVerificationWindow = &enctime.Duration{
+		Duration: 10 * time.Millisecond,
+	}
+	c.PublicationDelay = &enctime.Duration{}
+	c.TriggerOffset = &enctime.Duration{}
+	c.EpochIDMode = string(epochid.ModeSequential)
 	return nil
 }
 
@@ -0,0 +1,236 @@
+// Package configsim simulates the activation of a proposed keyper set against the state of a
+// keyper database. It is meant to help reviewing proposals before they are submitted on chain.
+package configsim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+)
+
+// Proposal is a keyper set proposed to be activated.
+type Proposal struct {
+	Keypers               []common.Address `json:"keypers"`
+	Threshold             uint64           `json:"threshold"`
+	ActivationBlockNumber uint64           `json:"activationBlockNumber"`
+}
+
+// ReadProposal reads a proposal from a JSON file. The file either contains the list of keyper
+// addresses or an object with the fields of Proposal.
+func ReadProposal(path string) (*Proposal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+	proposal := &Proposal{}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		err = json.Unmarshal(data, &proposal.Keypers)
+	} else {
+		err = json.Unmarshal(data, proposal)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	return proposal, nil
+}
+
+// State is the part of the keyper database the simulation is based on.
+type State struct {
+	BatchConfigs   []kprdb.TendermintBatchConfig
+	Eons           []kprdb.Eon
+	FinishedDKGs   map[int64]bool
+	EncryptionKeys map[common.Address]bool
+	LastBlockSeen  int64
+}
+
+// LoadState loads the state from the keyper database.
+func LoadState(ctx context.Context, db *kprdb.Queries) (*State, error) {
+	state := &State{
+		FinishedDKGs:   make(map[int64]bool),
+		EncryptionKeys: make(map[common.Address]bool),
+	}
+	var err error
+	state.BatchConfigs, err = db.GetBatchConfigs(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get keyper configs from db")
+	}
+	state.Eons, err = db.GetAllEons(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get eons from db")
+	}
+	for _, eon := range state.Eons {
+		_, err := db.GetDKGResult(ctx, eon.Eon)
+		if err == pgx.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get DKG result for eon %d from db", eon.Eon)
+		}
+		state.FinishedDKGs[eon.Eon] = true
+	}
+	keys, err := db.GetEncryptionKeys(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get encryption keys from db")
+	}
+	for _, k := range keys {
+		state.EncryptionKeys[common.HexToAddress(k.Address)] = true
+	}
+	state.LastBlockSeen, err = db.GetLastBlockSeen(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get last block seen from db")
+	}
+	return state, nil
+}
+
+// Report is the result of a simulation.
+type Report struct {
+	CurrentKeyperConfigIndex int64            `json:"currentKeyperConfigIndex"`
+	CurrentKeypers           []common.Address `json:"currentKeypers"`
+	CurrentThreshold         uint64           `json:"currentThreshold"`
+	KeyperConfigIndex        int64            `json:"keyperConfigIndex"`
+
+	Added    []common.Address `json:"added"`
+	Removed  []common.Address `json:"removed"`
+	Retained []common.Address `json:"retained"`
+
+	// DKGParticipants are the keypers that have to take part in the DKG for the first eon of the
+	// proposed keyper set. MissingEncryptionKeys are the ones among them that have not registered
+	// an encryption key yet and can therefore not receive their shares.
+	DKGParticipants       []common.Address `json:"dkgParticipants"`
+	MissingEncryptionKeys []common.Address `json:"missingEncryptionKeys"`
+
+	NumKeypers uint64 `json:"numKeypers"`
+	Threshold  uint64 `json:"threshold"`
+	// FaultTolerance is the number of keypers that may be offline while keys are still released.
+	FaultTolerance uint64 `json:"faultTolerance"`
+	// CollusionResistance is the number of keypers that may collude without being able to
+	// release keys on their own.
+	CollusionResistance uint64 `json:"collusionResistance"`
+
+	Conflicts []string `json:"conflicts"`
+	Warnings  []string `json:"warnings"`
+}
+
+func (p *Proposal) validate() error {
+	if len(p.Keypers) == 0 {
+		return errors.New("proposal contains no keypers")
+	}
+	if p.Threshold == 0 || p.Threshold > uint64(len(p.Keypers)) {
+		return errors.Errorf("threshold must be between 1 and %d, got %d", len(p.Keypers), p.Threshold)
+	}
+	seen := make(map[common.Address]bool)
+	for _, k := range p.Keypers {
+		if seen[k] {
+			return errors.Errorf("keyper %s is listed more than once", k.Hex())
+		}
+		seen[k] = true
+	}
+	return nil
+}
+
+// Simulate reports the consequences of activating the proposed keyper set given the state.
+func Simulate(state *State, proposal *Proposal) (*Report, error) {
+	if err := proposal.validate(); err != nil {
+		return nil, err
+	}
+	n := uint64(len(proposal.Keypers))
+	t := proposal.Threshold
+	report := &Report{
+		CurrentKeyperConfigIndex: -1,
+		KeyperConfigIndex:        0,
+		Added:                    []common.Address{},
+		Removed:                  []common.Address{},
+		Retained:                 []common.Address{},
+		DKGParticipants:          proposal.Keypers,
+		MissingEncryptionKeys:    []common.Address{},
+		NumKeypers:               n,
+		Threshold:                t,
+		FaultTolerance:           n - t,
+		CollusionResistance:      t - 1,
+		Conflicts:                []string{},
+		Warnings:                 []string{},
+	}
+
+	current := make(map[common.Address]bool)
+	if len(state.BatchConfigs) > 0 {
+		latest := state.BatchConfigs[len(state.BatchConfigs)-1]
+		report.CurrentKeyperConfigIndex = int64(latest.KeyperConfigIndex)
+		report.CurrentThreshold = uint64(latest.Threshold)
+		report.KeyperConfigIndex = int64(latest.KeyperConfigIndex) + 1
+		report.CurrentKeypers = []common.Address{}
+		for _, k := range latest.Keypers {
+			addr := common.HexToAddress(k)
+			report.CurrentKeypers = append(report.CurrentKeypers, addr)
+			current[addr] = true
+		}
+		if proposal.ActivationBlockNumber != 0 &&
+			int64(proposal.ActivationBlockNumber) <= latest.ActivationBlockNumber {
+			report.Conflicts = append(report.Conflicts, fmt.Sprintf(
+				"activation block %d is not after the activation block %d of the current keyper config %d",
+				proposal.ActivationBlockNumber, latest.ActivationBlockNumber, latest.KeyperConfigIndex))
+		}
+	}
+
+	proposed := make(map[common.Address]bool)
+	for _, k := range proposal.Keypers {
+		proposed[k] = true
+		if current[k] {
+			report.Retained = append(report.Retained, k)
+		} else {
+			report.Added = append(report.Added, k)
+		}
+		if !state.EncryptionKeys[k] {
+			report.MissingEncryptionKeys = append(report.MissingEncryptionKeys, k)
+		}
+	}
+	for _, k := range report.CurrentKeypers {
+		if !proposed[k] {
+			report.Removed = append(report.Removed, k)
+		}
+	}
+
+	if proposal.ActivationBlockNumber != 0 && int64(proposal.ActivationBlockNumber) <= state.LastBlockSeen {
+		report.Conflicts = append(report.Conflicts, fmt.Sprintf(
+			"activation block %d is not after the last block seen %d",
+			proposal.ActivationBlockNumber, state.LastBlockSeen))
+	}
+	for _, cfg := range state.BatchConfigs {
+		if !cfg.Started {
+			report.Conflicts = append(report.Conflicts, fmt.Sprintf(
+				"keyper config %d has not been started yet", cfg.KeyperConfigIndex))
+		}
+	}
+	for _, eon := range state.Eons {
+		if !state.FinishedDKGs[eon.Eon] {
+			report.Conflicts = append(report.Conflicts, fmt.Sprintf(
+				"DKG for eon %d of keyper config %d is still pending", eon.Eon, eon.KeyperConfigIndex))
+		}
+		if proposal.ActivationBlockNumber != 0 && int64(proposal.ActivationBlockNumber) <= eon.ActivationBlockNumber {
+			report.Conflicts = append(report.Conflicts, fmt.Sprintf(
+				"eon %d activates at block %d, which is not before the proposed activation block",
+				eon.Eon, eon.ActivationBlockNumber))
+		}
+	}
+
+	if t == n && n > 1 {
+		report.Warnings = append(report.Warnings,
+			"threshold equals the number of keypers, a single offline keyper stops key release")
+	}
+	if 2*t <= n {
+		report.Warnings = append(report.Warnings,
+			"threshold is at most half of the keypers, a minority can release keys early")
+	}
+	if len(report.MissingEncryptionKeys) > 0 {
+		report.Warnings = append(report.Warnings,
+			"some keypers have not registered an encryption key and cannot take part in the DKG yet")
+	}
+	return report, nil
+}
@@ -0,0 +1,59 @@
+package configsim
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+)
+
+func TestSimulate(t *testing.T) {
+	a := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	b := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	c := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	d := common.HexToAddress("0x4444444444444444444444444444444444444444")
+
+	state := &State{
+		BatchConfigs: []kprdb.TendermintBatchConfig{
+			{KeyperConfigIndex: 1, Keypers: []string{a.Hex(), b.Hex(), c.Hex()}, Threshold: 2, Started: true, ActivationBlockNumber: 100},
+		},
+		Eons: []kprdb.Eon{
+			{Eon: 1, ActivationBlockNumber: 100, KeyperConfigIndex: 1},
+			{Eon: 2, ActivationBlockNumber: 200, KeyperConfigIndex: 1},
+		},
+		FinishedDKGs:   map[int64]bool{1: true},
+		EncryptionKeys: map[common.Address]bool{a: true, b: true, c: true},
+		LastBlockSeen:  150,
+	}
+	report, err := Simulate(state, &Proposal{Keypers: []common.Address{b, c, d}, Threshold: 3, ActivationBlockNumber: 180})
+	assert.NilError(t, err)
+
+	assert.Equal(t, report.CurrentKeyperConfigIndex, int64(1))
+	assert.Equal(t, report.KeyperConfigIndex, int64(2))
+	assert.DeepEqual(t, report.Added, []common.Address{d})
+	assert.DeepEqual(t, report.Removed, []common.Address{a})
+	assert.DeepEqual(t, report.Retained, []common.Address{b, c})
+	assert.DeepEqual(t, report.DKGParticipants, []common.Address{b, c, d})
+	assert.DeepEqual(t, report.MissingEncryptionKeys, []common.Address{d})
+	assert.Equal(t, report.FaultTolerance, uint64(0))
+	assert.Equal(t, report.CollusionResistance, uint64(2))
+	assert.DeepEqual(t, report.Conflicts, []string{
+		"DKG for eon 2 of keyper config 1 is still pending",
+		"eon 2 activates at block 200, which is not before the proposed activation block",
+	})
+	assert.Equal(t, len(report.Warnings), 2)
+}
+
+func TestSimulateInvalidProposal(t *testing.T) {
+	a := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	state := &State{}
+
+	_, err := Simulate(state, &Proposal{Threshold: 1})
+	assert.ErrorContains(t, err, "no keypers")
+	_, err = Simulate(state, &Proposal{Keypers: []common.Address{a}, Threshold: 2})
+	assert.ErrorContains(t, err, "threshold")
+	_, err = Simulate(state, &Proposal{Keypers: []common.Address{a, a}, Threshold: 1})
+	assert.ErrorContains(t, err, "more than once")
+}
@@ -0,0 +1,169 @@
+// Package dkgparams syncs the DKG parameters announced in the DKGParameters contract, so that the
+// phase length and the number of participants a DKG may miss can be tuned per keyper config
+// without redeploying the keypers.
+//
+// The parameters of an eon are fixed when the keyper observes the eon's start in shuttermint, and
+// they are taken from the keyper config the eon belongs to. All keypers of the eon have to agree on
+// them, so they have to be announced before the keyper config is activated.
+package dkgparams
+
+import (
+	"context"
+	"math"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/dkgphase"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+)
+
+const (
+	// ContractName is the name of the DKG parameters contract, as used in deployment.Contracts.
+	ContractName = "DKGParameters"
+	// ParametersChangedEventName is the name of the event emitted when the parameters of a keyper
+	// config change.
+	ParametersChangedEventName = "DKGParametersChanged"
+
+	// maxPhaseLength keeps the end of the last phase of a DKG from overflowing int64.
+	maxPhaseLength = math.MaxInt64 / 8
+)
+
+// Parameters are the parameters a DKG runs with. PhaseLength is the length of each phase in
+// shuttermint blocks. The DKG fails if more than MaxMissingParticipants keypers didn't deal.
+type Parameters struct {
+	PhaseLength            int64
+	MaxMissingParticipants int64
+}
+
+// Defaults returns the parameters of DKGs for which the contract hasn't announced any: the phase
+// length of the local config and no limit on the number of missing participants.
+func Defaults(phaseLength *dkgphase.PhaseLength) Parameters {
+	return Parameters{
+		PhaseLength:            phaseLength.Length(),
+		MaxMissingParticipants: math.MaxInt64,
+	}
+}
+
+// GetPhaseLength returns the phase lengths of the DKG.
+func (p Parameters) GetPhaseLength() *dkgphase.PhaseLength {
+	return dkgphase.NewConstantPhaseLength(p.PhaseLength)
+}
+
+// CheckMissing checks that no more than MaxMissingParticipants of the DKG's participants are
+// missing.
+func (p Parameters) CheckMissing(numMissing int) error {
+	if int64(numMissing) > p.MaxMissingParticipants {
+		return errors.Errorf(
+			"%d keypers didn't take part, but at most %d may be missing", numMissing, p.MaxMissingParticipants,
+		)
+	}
+	return nil
+}
+
+// FixForEon fixes the parameters of the DKG of the given eon, which belongs to the given keyper
+// config, and returns them. If they have been fixed before, the stored ones are returned.
+func FixForEon(
+	ctx context.Context, db *kprdb.Queries, eon int64, keyperConfigIndex int64, defaults Parameters,
+) (Parameters, error) {
+	p, ok, err := getForEon(ctx, db, eon)
+	if err != nil || ok {
+		return p, err
+	}
+	p = defaults
+	row, err := db.GetDKGParameters(ctx, keyperConfigIndex)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return Parameters{}, errors.Wrapf(err, "failed to query dkg parameters of keyper config %d from db", keyperConfigIndex)
+	default:
+		p = Parameters{PhaseLength: row.PhaseLength, MaxMissingParticipants: row.MaxMissingParticipants}
+	}
+	err = db.InsertEonDKGParameters(ctx, kprdb.InsertEonDKGParametersParams{
+		Eon:                    eon,
+		PhaseLength:            p.PhaseLength,
+		MaxMissingParticipants: p.MaxMissingParticipants,
+	})
+	if err != nil {
+		return Parameters{}, errors.Wrapf(err, "failed to store dkg parameters of eon %d in db", eon)
+	}
+	return p, nil
+}
+
+// GetForEon returns the parameters of the DKG of the given eon. Eons that started before the
+// parameters were stored in the db use the defaults.
+func GetForEon(ctx context.Context, db *kprdb.Queries, eon int64, defaults Parameters) (Parameters, error) {
+	p, ok, err := getForEon(ctx, db, eon)
+	if err != nil || ok {
+		return p, err
+	}
+	return defaults, nil
+}
+
+func getForEon(ctx context.Context, db *kprdb.Queries, eon int64) (Parameters, bool, error) {
+	row, err := db.GetEonDKGParameters(ctx, eon)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Parameters{}, false, nil
+	} else if err != nil {
+		return Parameters{}, false, errors.Wrapf(err, "failed to query dkg parameters of eon %d from db", eon)
+	}
+	return Parameters{PhaseLength: row.PhaseLength, MaxMissingParticipants: row.MaxMissingParticipants}, true, nil
+}
+
+// parseParametersChanged extracts the arguments of a DKGParametersChanged event. A maximum number
+// of missing participants that doesn't fit into an int64 means there is no limit.
+func parseParametersChanged(event eventsyncer.DynamicEvent) (keyperConfigIndex int64, p Parameters, err error) {
+	index, ok := event.Args["keyperConfigIndex"].(uint64)
+	if !ok {
+		return 0, Parameters{}, errors.New("missing or invalid keyperConfigIndex in DKGParametersChanged event")
+	}
+	phaseLength, ok := event.Args["phaseLength"].(uint64)
+	if !ok {
+		return 0, Parameters{}, errors.New("missing or invalid phaseLength in DKGParametersChanged event")
+	}
+	maxMissing, ok := event.Args["maxMissingParticipants"].(uint64)
+	if !ok {
+		return 0, Parameters{}, errors.New("missing or invalid maxMissingParticipants in DKGParametersChanged event")
+	}
+	if index > math.MaxInt64 {
+		return 0, Parameters{}, errors.Errorf("keyper config index %d would overflow int64", index)
+	}
+	if phaseLength == 0 || phaseLength > maxPhaseLength {
+		return 0, Parameters{}, errors.Errorf("phase length %d is out of range", phaseLength)
+	}
+	if maxMissing > math.MaxInt64 {
+		maxMissing = math.MaxInt64
+	}
+	return int64(index), Parameters{PhaseLength: int64(phaseLength), MaxMissingParticipants: int64(maxMissing)}, nil
+}
+
+// HandleParametersChanged stores the DKG parameters announced by a DKGParametersChanged event. They
+// apply to the eons of the keyper config that start after the event has been synced.
+func HandleParametersChanged(
+	ctx context.Context, chainDB *chainobsdb.Queries, event eventsyncer.DynamicEvent,
+) error {
+	keyperConfigIndex, p, err := parseParametersChanged(event)
+	if err != nil {
+		return err
+	}
+	log.Info().
+		Uint64("block-number", event.Raw.BlockNumber).
+		Int64("keyper-config-index", keyperConfigIndex).
+		Int64("phase-length", p.PhaseLength).
+		Int64("max-missing-participants", p.MaxMissingParticipants).
+		Msg("handling DKGParametersChanged event from dkg parameters contract")
+
+	err = kprdb.New(chainDB.Conn()).SetDKGParameters(ctx, kprdb.SetDKGParametersParams{
+		KeyperConfigIndex:      keyperConfigIndex,
+		PhaseLength:            p.PhaseLength,
+		MaxMissingParticipants: p.MaxMissingParticipants,
+		BlockNumber:            int64(event.Raw.BlockNumber),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to store dkg parameters in db")
+	}
+	return nil
+}
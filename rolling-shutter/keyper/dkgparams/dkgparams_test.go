@@ -0,0 +1,45 @@
+package dkgparams
+
+import (
+	"math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+)
+
+func TestParseParametersChanged(t *testing.T) {
+	event := eventsyncer.DynamicEvent{Args: map[string]interface{}{
+		"keyperConfigIndex":      uint64(3),
+		"phaseLength":            uint64(20),
+		"maxMissingParticipants": uint64(1),
+	}}
+	index, p, err := parseParametersChanged(event)
+	assert.NilError(t, err)
+	assert.Equal(t, index, int64(3))
+	assert.Equal(t, p, Parameters{PhaseLength: 20, MaxMissingParticipants: 1})
+
+	event.Args["maxMissingParticipants"] = uint64(math.MaxUint64)
+	_, p, err = parseParametersChanged(event)
+	assert.NilError(t, err)
+	assert.Equal(t, p.MaxMissingParticipants, int64(math.MaxInt64))
+
+	event.Args["phaseLength"] = uint64(0)
+	_, _, err = parseParametersChanged(event)
+	assert.ErrorContains(t, err, "out of range")
+	event.Args["phaseLength"] = uint64(math.MaxInt64)
+	_, _, err = parseParametersChanged(event)
+	assert.ErrorContains(t, err, "out of range")
+
+	delete(event.Args, "keyperConfigIndex")
+	_, _, err = parseParametersChanged(event)
+	assert.ErrorContains(t, err, "keyperConfigIndex")
+}
+
+func TestCheckMissing(t *testing.T) {
+	p := Parameters{PhaseLength: 10, MaxMissingParticipants: 1}
+	assert.NilError(t, p.CheckMissing(0))
+	assert.NilError(t, p.CheckMissing(1))
+	assert.ErrorContains(t, p.CheckMissing(2), "at most 1")
+}
@@ -30,6 +30,12 @@ func NewConstantPhaseLength(l int64) *PhaseLength {
 	}
 }
 
+// Length returns the length of a single phase. It assumes that all phases have the same length, as
+// with NewConstantPhaseLength.
+func (plen *PhaseLength) Length() int64 {
+	return plen.dealing - plen.off
+}
+
 func (plen *PhaseLength) UnmarshalText(b []byte) error {
 	phaseLenInt, err := strconv.ParseInt(string(b), 10, 64)
 	if err != nil {
@@ -0,0 +1,171 @@
+// Package dkgtranscript exports the transcript of a DKG process and verifies it offline, without
+// access to any secret of the keypers. This allows third parties to confirm that an eon public key
+// is the outcome of a correctly executed DKG.
+package dkgtranscript
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/shutterevents"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// Transcript contains everything needed to verify the DKG of an eon: the keyper set, the public
+// messages exchanged in the order they were handled and the result the keyper arrived at. Poly
+// evals are included in encrypted form only.
+type Transcript struct {
+	Eon                   uint64           `json:"eon"`
+	Height                int64            `json:"height"`
+	ActivationBlockNumber uint64           `json:"activationBlockNumber"`
+	KeyperConfigIndex     uint64           `json:"keyperConfigIndex"`
+	Keypers               []common.Address `json:"keypers"`
+	Threshold             uint64           `json:"threshold"`
+	Messages              []Message        `json:"messages"`
+	Result                *Result          `json:"result"`
+}
+
+// Message is a DKG message as emitted by shuttermint, together with the phase of the DKG it was
+// handled in.
+type Message struct {
+	Height int64          `json:"height"`
+	Phase  string         `json:"phase"`
+	Type   string         `json:"type"`
+	Sender common.Address `json:"sender"`
+	Event  hexutil.Bytes  `json:"event"`
+}
+
+// Result is the outcome of the DKG as recorded by the keyper. It is nil if the DKG hasn't been
+// finalized yet.
+type Result struct {
+	Success      bool          `json:"success"`
+	Error        string        `json:"error,omitempty"`
+	EonPublicKey hexutil.Bytes `json:"eonPublicKey,omitempty"`
+}
+
+// DecodeEvent decodes the shuttermint event of the message.
+func (m *Message) DecodeEvent() (shutterevents.IEvent, error) {
+	var ev abcitypes.Event
+	if err := ev.Unmarshal(m.Event); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal shuttermint event")
+	}
+	return shutterevents.MakeEvent(ev, m.Height)
+}
+
+// ParsePhase parses the phase of the message.
+func (m *Message) ParsePhase() (puredkg.Phase, error) {
+	for phase := puredkg.Off; phase <= puredkg.Finalized; phase++ {
+		if phase.String() == m.Phase {
+			return phase, nil
+		}
+	}
+	return puredkg.Off, errors.Errorf("unknown DKG phase %q", m.Phase)
+}
+
+// NewMessage creates a message from a shuttermint event handled in the given phase.
+func NewMessage(event shutterevents.IEvent, height int64, sender common.Address, phase puredkg.Phase) (Message, error) {
+	abciEvent := event.MakeABCIEvent()
+	eventBytes, err := abciEvent.Marshal()
+	if err != nil {
+		return Message{}, errors.Wrap(err, "failed to marshal shuttermint event")
+	}
+	return Message{
+		Height: height,
+		Phase:  phase.String(),
+		Type:   abciEvent.Type,
+		Sender: sender,
+		Event:  eventBytes,
+	}, nil
+}
+
+// Load loads the transcript of the DKG of the given eon from the keyper db.
+func Load(ctx context.Context, db *kprdb.Queries, eon uint64) (*Transcript, error) {
+	eonRow, err := db.GetEon(ctx, int64(eon))
+	if err == pgx.ErrNoRows {
+		return nil, errors.Errorf("eon %d not found in db", eon)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get eon %d from db", eon)
+	}
+	batchConfig, err := db.GetBatchConfig(ctx, int32(eonRow.KeyperConfigIndex))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get batch config %d from db", eonRow.KeyperConfigIndex)
+	}
+	keypers, err := shdb.DecodeAddresses(batchConfig.Keypers)
+	if err != nil {
+		return nil, err
+	}
+	transcript := &Transcript{
+		Eon:                   eon,
+		Height:                eonRow.Height,
+		ActivationBlockNumber: uint64(eonRow.ActivationBlockNumber),
+		KeyperConfigIndex:     uint64(eonRow.KeyperConfigIndex),
+		Keypers:               keypers,
+		Threshold:             uint64(batchConfig.Threshold),
+		Messages:              []Message{},
+	}
+
+	entries, err := db.GetDKGTranscript(ctx, int64(eon))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get DKG transcript from db")
+	}
+	for _, entry := range entries {
+		sender, err := shdb.DecodeAddress(entry.Sender)
+		if err != nil {
+			return nil, err
+		}
+		var ev abcitypes.Event
+		if err := ev.Unmarshal(entry.Event); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal DKG transcript entry %d", entry.ID)
+		}
+		transcript.Messages = append(transcript.Messages, Message{
+			Height: entry.Height,
+			Phase:  puredkg.Phase(entry.Phase).String(),
+			Type:   ev.Type,
+			Sender: sender,
+			Event:  entry.Event,
+		})
+	}
+
+	dkgResult, err := db.GetDKGResult(ctx, int64(eon))
+	if err == pgx.ErrNoRows {
+		return transcript, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get DKG result from db")
+	}
+	// Only the public part of the result is exported, the result also contains our secret key
+	// share.
+	transcript.Result = &Result{Success: dkgResult.Success, Error: dkgResult.Error.String}
+	if dkgResult.Success {
+		pureResult, err := shdb.DecodePureDKGResult(dkgResult.PureResult)
+		if err != nil {
+			return nil, err
+		}
+		transcript.Result.EonPublicKey = pureResult.PublicKey.Marshal()
+	}
+	return transcript, nil
+}
+
+// ReadFile reads a transcript exported as JSON.
+func ReadFile(path string) (*Transcript, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read DKG transcript %s", path)
+	}
+	transcript := &Transcript{}
+	if err := json.Unmarshal(data, transcript); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse DKG transcript %s", path)
+	}
+	return transcript, nil
+}
@@ -0,0 +1,307 @@
+package dkgtranscript
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/shutterevents"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
+)
+
+// Verdict is the outcome of verifying a transcript.
+type Verdict struct {
+	Eon uint64 `json:"eon"`
+	// Valid is true if the DKG succeeded and the recorded eon public key is the one determined by
+	// the transcript.
+	Valid        bool             `json:"valid"`
+	Success      bool             `json:"success"`
+	EonPublicKey hexutil.Bytes    `json:"eonPublicKey,omitempty"`
+	Participants []common.Address `json:"participants"`
+	Corrupt      []CorruptKeyper  `json:"corrupt"`
+	Ignored      []IgnoredMessage `json:"ignored"`
+	Warnings     []string         `json:"warnings"`
+	Errors       []string         `json:"errors"`
+}
+
+// CorruptKeyper is a keyper whose contribution is excluded from the eon key.
+type CorruptKeyper struct {
+	Address common.Address `json:"address"`
+	Reason  string         `json:"reason"`
+}
+
+// IgnoredMessage is a message of the transcript that doesn't affect the outcome, e.g. because it
+// was sent in the wrong phase.
+type IgnoredMessage struct {
+	Index  int            `json:"index"`
+	Sender common.Address `json:"sender"`
+	Reason string         `json:"reason"`
+}
+
+type verifier struct {
+	transcript *Transcript
+	verdict    *Verdict
+	pure       puredkg.PureDKG
+	// sharesSent[dealer][receiver] is true if the dealer sent an encrypted poly eval to the
+	// receiver in the dealing phase
+	sharesSent [][]bool
+}
+
+// Verify replays the public messages of the transcript and checks that the recorded result
+// follows from them. As the poly evals are encrypted, we can't check them directly. However,
+// every keyper receiving an invalid eval accuses the dealer, who then has to publish the eval in
+// an apology, which we can check against the dealer's commitment. If expectedEonPublicKey is not
+// nil, the resulting key is compared to it as well, e.g. to the key published on chain.
+func Verify(transcript *Transcript, expectedEonPublicKey []byte) *Verdict {
+	n := uint64(len(transcript.Keypers))
+	v := &verifier{
+		transcript: transcript,
+		verdict: &Verdict{
+			Eon:          transcript.Eon,
+			Participants: []common.Address{},
+			Corrupt:      []CorruptKeyper{},
+			Ignored:      []IgnoredMessage{},
+			Warnings:     []string{},
+			Errors:       []string{},
+		},
+		// We only observe the DKG, so we use an index that doesn't belong to any keyper.
+		pure:       puredkg.NewPureDKG(transcript.Eon, n, transcript.Threshold, n),
+		sharesSent: make([][]bool, n),
+	}
+	for i := range v.sharesSent {
+		v.sharesSent[i] = make([]bool, n)
+	}
+
+	if transcript.Threshold == 0 || transcript.Threshold > n {
+		v.fail("invalid threshold %d for %d keypers", transcript.Threshold, n)
+		return v.verdict
+	}
+	for i := range transcript.Messages {
+		if err := v.replay(i, &transcript.Messages[i]); err != nil {
+			v.fail("message %d: %s", i, err)
+			return v.verdict
+		}
+	}
+	v.pure.Phase = puredkg.Finalized
+	v.computeResult()
+	v.compareResult(expectedEonPublicKey)
+	v.verdict.Valid = v.verdict.Success && len(v.verdict.Errors) == 0
+	return v.verdict
+}
+
+func (v *verifier) fail(format string, args ...interface{}) {
+	v.verdict.Errors = append(v.verdict.Errors, fmt.Sprintf(format, args...))
+}
+
+func (v *verifier) ignore(index int, sender common.Address, format string, args ...interface{}) {
+	v.verdict.Ignored = append(v.verdict.Ignored, IgnoredMessage{
+		Index:  index,
+		Sender: sender,
+		Reason: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *verifier) replay(index int, msg *Message) error {
+	phase, err := msg.ParsePhase()
+	if err != nil {
+		return err
+	}
+	if phase < v.pure.Phase {
+		return errors.Errorf("phase %s follows phase %s", phase, v.pure.Phase)
+	}
+	v.pure.Phase = phase
+
+	event, err := msg.DecodeEvent()
+	if err != nil {
+		return err
+	}
+	keypers := v.transcript.Keypers
+	sender, err := medley.FindAddressIndex(keypers, msg.Sender)
+	if err != nil {
+		v.ignore(index, msg.Sender, "sender is not a keyper")
+		return nil
+	}
+	eon := v.transcript.Eon
+
+	switch e := event.(type) {
+	case *shutterevents.PolyCommitment:
+		if e.Eon != eon || e.Sender != msg.Sender {
+			return errors.Errorf("event of eon %d from %s doesn't match transcript", e.Eon, e.Sender)
+		}
+		err := v.pure.HandlePolyCommitmentMsg(puredkg.PolyCommitmentMsg{
+			Eon:    eon,
+			Sender: uint64(sender),
+			Gammas: e.Gammas,
+		})
+		if err != nil {
+			v.ignore(index, msg.Sender, "poly commitment: %s", err)
+		}
+	case *shutterevents.PolyEval:
+		if e.Eon != eon || e.Sender != msg.Sender {
+			return errors.Errorf("event of eon %d from %s doesn't match transcript", e.Eon, e.Sender)
+		}
+		if phase != puredkg.Dealing {
+			v.ignore(index, msg.Sender, "poly eval in phase %s", phase)
+			return nil
+		}
+		if len(e.Receivers) != len(e.EncryptedEvals) {
+			v.ignore(index, msg.Sender, "poly eval with %d receivers, but %d evals",
+				len(e.Receivers), len(e.EncryptedEvals))
+			return nil
+		}
+		for _, receiver := range e.Receivers {
+			if i, err := medley.FindAddressIndex(keypers, receiver); err == nil {
+				v.sharesSent[sender][i] = true
+			}
+		}
+	case *shutterevents.Accusation:
+		if e.Eon != eon || e.Sender != msg.Sender {
+			return errors.Errorf("event of eon %d from %s doesn't match transcript", e.Eon, e.Sender)
+		}
+		for _, accused := range e.Accused {
+			accusedIndex, err := medley.FindAddressIndex(keypers, accused)
+			if err != nil {
+				v.ignore(index, msg.Sender, "accused %s is not a keyper", accused)
+				continue
+			}
+			err = v.pure.HandleAccusationMsg(puredkg.AccusationMsg{
+				Eon:     eon,
+				Accuser: uint64(sender),
+				Accused: uint64(accusedIndex),
+			})
+			if err != nil {
+				v.ignore(index, msg.Sender, "accusation of %s: %s", accused, err)
+			}
+		}
+	case *shutterevents.Apology:
+		if e.Eon != eon || e.Sender != msg.Sender {
+			return errors.Errorf("event of eon %d from %s doesn't match transcript", e.Eon, e.Sender)
+		}
+		if len(e.Accusers) != len(e.PolyEval) {
+			v.ignore(index, msg.Sender, "apology with %d accusers, but %d evals",
+				len(e.Accusers), len(e.PolyEval))
+			return nil
+		}
+		for j, accuser := range e.Accusers {
+			accuserIndex, err := medley.FindAddressIndex(keypers, accuser)
+			if err != nil {
+				v.ignore(index, msg.Sender, "accuser %s is not a keyper", accuser)
+				continue
+			}
+			err = v.pure.HandleApologyMsg(puredkg.ApologyMsg{
+				Eon:     eon,
+				Accuser: uint64(accuserIndex),
+				Accused: uint64(sender),
+				Eval:    e.PolyEval[j],
+			})
+			if err != nil {
+				v.ignore(index, msg.Sender, "apology to %s: %s", accuser, err)
+			}
+		}
+	default:
+		return errors.Errorf("unexpected %s event", msg.Type)
+	}
+	return nil
+}
+
+// corruptReason returns why the dealer is considered corrupt, or the empty string if they are
+// not. It follows the rules of the keypers, see puredkg.PureDKG.
+func (v *verifier) corruptReason(dealer uint64) string {
+	keypers := v.transcript.Keypers
+	commitment := v.pure.Commitments[dealer]
+	if commitment == nil {
+		return "no poly commitment"
+	}
+
+	apologies := make(map[uint64]*big.Int)
+	for key, eval := range v.pure.Apologies {
+		if key.Accused == dealer {
+			apologies[key.Accuser] = eval
+		}
+	}
+	accusers := []uint64{}
+	for key := range v.pure.Accusations {
+		if key.Accused == dealer {
+			accusers = append(accusers, key.Accuser)
+		}
+	}
+	sort.Slice(accusers, func(i, j int) bool { return accusers[i] < accusers[j] })
+
+	for accuser := uint64(0); accuser < v.pure.NumKeypers; accuser++ {
+		eval, ok := apologies[accuser]
+		if ok && !shcrypto.VerifyPolyEval(int(accuser), eval, commitment, v.pure.Threshold) {
+			return fmt.Sprintf("apology to %s doesn't match poly commitment", keypers[accuser])
+		}
+	}
+	for _, accuser := range accusers {
+		if _, ok := apologies[accuser]; !ok {
+			return fmt.Sprintf("no apology to accuser %s", keypers[accuser])
+		}
+	}
+	return ""
+}
+
+func (v *verifier) computeResult() {
+	keypers := v.transcript.Keypers
+	commitments := []*shcrypto.Gammas{}
+	for dealer := uint64(0); dealer < v.pure.NumKeypers; dealer++ {
+		if reason := v.corruptReason(dealer); reason != "" {
+			v.verdict.Corrupt = append(v.verdict.Corrupt, CorruptKeyper{Address: keypers[dealer], Reason: reason})
+			commitments = append(commitments, shcrypto.ZeroGammas(shcrypto.DegreeFromThreshold(v.pure.Threshold)))
+			continue
+		}
+		v.verdict.Participants = append(v.verdict.Participants, keypers[dealer])
+		commitments = append(commitments, v.pure.Commitments[dealer])
+		for receiver, sent := range v.sharesSent[dealer] {
+			if !sent && uint64(receiver) != dealer {
+				v.verdict.Warnings = append(v.verdict.Warnings, fmt.Sprintf(
+					"%s sent no encrypted poly eval to %s", keypers[dealer], keypers[receiver]))
+			}
+		}
+	}
+	if uint64(len(v.verdict.Participants)) < v.pure.Threshold {
+		v.verdict.Warnings = append(v.verdict.Warnings, fmt.Sprintf(
+			"only %d keypers participated, but threshold is %d", len(v.verdict.Participants), v.pure.Threshold))
+		return
+	}
+	v.verdict.Success = true
+	v.verdict.EonPublicKey = shcrypto.ComputeEonPublicKey(commitments).Marshal()
+}
+
+func (v *verifier) compareResult(expectedEonPublicKey []byte) {
+	result := v.transcript.Result
+	if result == nil {
+		v.fail("transcript contains no result, the DKG may not be finalized yet")
+	} else {
+		if result.Success != v.verdict.Success {
+			v.fail("recorded success %t, but transcript implies %t", result.Success, v.verdict.Success)
+		}
+		if result.Success && v.verdict.Success && !equalEonPublicKeys(result.EonPublicKey, v.verdict.EonPublicKey) {
+			v.fail("recorded eon public key %s differs from the one implied by the transcript",
+				result.EonPublicKey)
+		}
+	}
+	if expectedEonPublicKey != nil && !equalEonPublicKeys(expectedEonPublicKey, v.verdict.EonPublicKey) {
+		v.fail("expected eon public key %s differs from the one implied by the transcript",
+			hexutil.Encode(expectedEonPublicKey))
+	}
+}
+
+func equalEonPublicKeys(a, b []byte) bool {
+	keyA := new(shcrypto.EonPublicKey)
+	if err := keyA.Unmarshal(a); err != nil {
+		return false
+	}
+	keyB := new(shcrypto.EonPublicKey)
+	if err := keyB.Unmarshal(b); err != nil {
+		return false
+	}
+	return keyA.Equal(keyB)
+}
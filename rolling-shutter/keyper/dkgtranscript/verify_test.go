@@ -0,0 +1,97 @@
+package dkgtranscript
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/shutterevents"
+)
+
+func TestVerify(t *testing.T) {
+	const eon = 5
+	keypers := []common.Address{
+		common.HexToAddress("0x1"),
+		common.HexToAddress("0x2"),
+		common.HexToAddress("0x3"),
+	}
+	threshold := uint64(2)
+	polynomials := []*shcrypto.Polynomial{}
+	for range keypers {
+		p, err := shcrypto.RandomPolynomial(rand.Reader, shcrypto.DegreeFromThreshold(threshold))
+		assert.NilError(t, err)
+		polynomials = append(polynomials, p)
+	}
+
+	transcript := &Transcript{Eon: eon, Keypers: keypers, Threshold: threshold}
+	add := func(event shutterevents.IEvent, sender common.Address, phase puredkg.Phase) {
+		msg, err := NewMessage(event, 10, sender, phase)
+		assert.NilError(t, err)
+		transcript.Messages = append(transcript.Messages, msg)
+	}
+	for i, keyper := range keypers {
+		add(&shutterevents.PolyCommitment{Eon: eon, Sender: keyper, Gammas: polynomials[i].Gammas()}, keyper, puredkg.Dealing)
+		receivers := []common.Address{}
+		evals := [][]byte{}
+		for _, receiver := range keypers {
+			if receiver != keyper {
+				receivers = append(receivers, receiver)
+				evals = append(evals, []byte{1})
+			}
+		}
+		add(&shutterevents.PolyEval{Eon: eon, Sender: keyper, Receivers: receivers, EncryptedEvals: evals}, keyper, puredkg.Dealing)
+	}
+	// keyper 0 accuses keyper 1 and 2, but only keyper 2 apologizes
+	add(&shutterevents.Accusation{Eon: eon, Sender: keypers[0], Accused: keypers[1:]}, keypers[0], puredkg.Accusing)
+	add(&shutterevents.Apology{
+		Eon:      eon,
+		Sender:   keypers[2],
+		Accusers: []common.Address{keypers[0]},
+		PolyEval: []*big.Int{polynomials[2].EvalForKeyper(0)},
+	}, keypers[2], puredkg.Apologizing)
+	// a late commitment is ignored
+	add(&shutterevents.PolyCommitment{Eon: eon, Sender: keypers[1], Gammas: polynomials[1].Gammas()}, keypers[1], puredkg.Apologizing)
+
+	expectedKey := shcrypto.ComputeEonPublicKey([]*shcrypto.Gammas{
+		polynomials[0].Gammas(),
+		shcrypto.ZeroGammas(shcrypto.DegreeFromThreshold(threshold)),
+		polynomials[2].Gammas(),
+	}).Marshal()
+	transcript.Result = &Result{Success: true, EonPublicKey: expectedKey}
+
+	// the transcript survives the roundtrip through its JSON export
+	data, err := json.Marshal(transcript)
+	assert.NilError(t, err)
+	transcript = &Transcript{}
+	assert.NilError(t, json.Unmarshal(data, transcript))
+
+	verdict := Verify(transcript, expectedKey)
+	assert.Check(t, verdict.Valid, "errors: %v", verdict.Errors)
+	assert.DeepEqual(t, verdict.Participants, []common.Address{keypers[0], keypers[2]})
+	assert.Equal(t, len(verdict.Corrupt), 1)
+	assert.Equal(t, verdict.Corrupt[0].Address, keypers[1])
+	assert.Equal(t, len(verdict.Ignored), 1)
+	assert.Equal(t, verdict.Ignored[0].Index, len(transcript.Messages)-1)
+
+	// a wrong apology makes keyper 2 corrupt as well, so the DKG fails
+	apology, err := NewMessage(&shutterevents.Apology{
+		Eon:      eon,
+		Sender:   keypers[2],
+		Accusers: []common.Address{keypers[0]},
+		PolyEval: []*big.Int{big.NewInt(1)},
+	}, 10, keypers[2], puredkg.Apologizing)
+	assert.NilError(t, err)
+	transcript.Messages[len(transcript.Messages)-2] = apology
+	verdict = Verify(transcript, nil)
+	assert.Check(t, !verdict.Valid)
+	assert.Check(t, !verdict.Success)
+	assert.Equal(t, len(verdict.Corrupt), 2)
+	assert.Equal(t, len(verdict.Errors), 1)
+}
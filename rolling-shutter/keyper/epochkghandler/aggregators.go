@@ -0,0 +1,85 @@
+package epochkghandler
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/peerdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// Aggregators returns the indices of the keypers that aggregate the decryption key of the given
+// epoch. They are count consecutive keypers starting at an index derived from the hash of the
+// epoch id, so that every keyper computes the same set and the work rotates between the keypers.
+func Aggregators(epochID epochid.EpochID, numKeypers uint64, count uint64) []uint64 {
+	if count > numKeypers {
+		count = numKeypers
+	}
+	hash := crypto.Keccak256(epochID.Bytes())
+	start := binary.BigEndian.Uint64(hash[:8]) % numKeypers
+	indices := make([]uint64, count)
+	for i := range indices {
+		indices[i] = (start + uint64(i)) % numKeypers
+	}
+	return indices
+}
+
+// NewAggregatorRoute creates the route that sends our decryption key shares directly to the count
+// aggregators of their epoch instead of gossiping them to all peers. The aggregators gossip the
+// decryption key once they have enough shares. If the peer of an aggregator is not known from its
+// node attestation, shares are gossiped as before.
+func NewAggregatorRoute(config Config, dbpool *pgxpool.Pool, count uint64) p2p.DirectRoute {
+	return func(ctx context.Context, msg p2pmsg.Message) ([]peer.ID, error) {
+		shares, ok := msg.(*p2pmsg.DecryptionKeyShares)
+		if !ok || len(shares.Shares) != 1 {
+			return nil, nil
+		}
+		epochID, err := epochid.BytesToEpochID(shares.Shares[0].EpochID)
+		if err != nil {
+			return nil, err
+		}
+		db := kprdb.New(dbpool)
+		eon, err := db.GetEon(ctx, int64(shares.Eon))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get eon %d from db", shares.Eon)
+		}
+		batchConfig, err := db.GetBatchConfig(ctx, int32(eon.KeyperConfigIndex))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get config %d from db", eon.KeyperConfigIndex)
+		}
+
+		self := shdb.EncodeAddress(config.GetAddress())
+		addresses := []string{}
+		for _, i := range Aggregators(epochID, uint64(len(batchConfig.Keypers)), count) {
+			if batchConfig.Keypers[i] != self {
+				addresses = append(addresses, batchConfig.Keypers[i])
+			}
+		}
+		rows, err := peerdb.New(dbpool).GetNodePeerIDs(ctx, addresses)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get peer ids of aggregators from db")
+		}
+		if len(rows) < len(addresses) {
+			// Keypers that don't know all aggregators would otherwise send their shares to a
+			// different subset than the others, so that possibly no aggregator gets enough.
+			return nil, errors.Errorf("peers of only %d of %d aggregators known", len(rows), len(addresses))
+		}
+		peers := make([]peer.ID, len(rows))
+		for i, row := range rows {
+			peers[i], err = peer.Decode(row.PeerID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid peer id of keyper %s", row.Address)
+			}
+		}
+		return peers, nil
+	}
+}
@@ -0,0 +1,27 @@
+package epochkghandler
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+func TestAggregators(t *testing.T) {
+	counts := make([]int, 5)
+	for i := uint64(0); i < 100; i++ {
+		epochID := epochid.Uint64ToEpochID(i)
+		aggregators := Aggregators(epochID, 5, 2)
+		assert.DeepEqual(t, aggregators, Aggregators(epochID, 5, 2))
+		assert.Equal(t, len(aggregators), 2)
+		assert.Equal(t, aggregators[1], (aggregators[0]+1)%5)
+		counts[aggregators[0]]++
+	}
+	// the aggregators rotate between all keypers
+	for i, count := range counts {
+		assert.Assert(t, count > 0, "keyper %d never aggregates", i)
+	}
+
+	assert.Equal(t, len(Aggregators(epochid.Uint64ToEpochID(1), 3, 5)), 3)
+}
@@ -0,0 +1,87 @@
+package epochkghandler
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// The benchmarks in this file require a test db, see medley/testdb. Run them with
+//
+//     go test -run '^$' -bench . ./keyper/epochkghandler
+
+func BenchmarkValidateDecryptionKeySharesIntegration(b *testing.B) {
+	if testing.Short() {
+		b.Skip("skipping integration benchmark")
+	}
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, b)
+	defer closedb()
+
+	keyperIndex := uint64(1)
+	tkg := initializeEon(ctx, b, dbpool, keyperIndex)
+	handler := &DecryptionKeyShareHandler{config: config, dbpool: dbpool, ingester: NewKeyIngester(dbpool, nil)}
+	epochID := epochid.Uint64ToEpochID(50)
+	msg := &p2pmsg.DecryptionKeyShares{
+		InstanceID:  config.GetInstanceID(),
+		Eon:         config.GetEon(),
+		KeyperIndex: keyperIndex,
+		Shares: []*p2pmsg.KeyShare{{
+			EpochID: epochID.Bytes(),
+			Share:   tkg.EpochSecretKeyShare(epochID, keyperIndex).Marshal(),
+		}},
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		ok, err := handler.ValidateMessage(ctx, msg)
+		assert.NilError(b, err)
+		assert.Assert(b, ok)
+	}
+}
+
+// BenchmarkHandleDecryptionKeySharesIntegration measures the throughput of the key share handler.
+// Every iteration handles a share for a new epoch, so the threshold is never reached and the
+// benchmark is dominated by the db round trips.
+func BenchmarkHandleDecryptionKeySharesIntegration(b *testing.B) {
+	if testing.Short() {
+		b.Skip("skipping integration benchmark")
+	}
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, b)
+	defer closedb()
+
+	keyperIndex := uint64(1)
+	tkg := initializeEon(ctx, b, dbpool, keyperIndex)
+	handler := &DecryptionKeyShareHandler{config: config, dbpool: dbpool, ingester: NewKeyIngester(dbpool, nil)}
+	// the test key generator switches eons every 100 epochs, so derive all shares from the eon
+	// secret key share of the eon the handler knows about
+	eonSecretKeyShare := tkg.EonSecretKeyShare(epochid.Uint64ToEpochID(0), 0)
+	msgs := make([]*p2pmsg.DecryptionKeyShares, b.N)
+	for n := range msgs {
+		epochID := epochid.Uint64ToEpochID(uint64(n))
+		share := shcrypto.ComputeEpochSecretKeyShare(eonSecretKeyShare, shcrypto.ComputeEpochID(epochID.Bytes()))
+		msgs[n] = &p2pmsg.DecryptionKeyShares{
+			InstanceID:  config.GetInstanceID(),
+			Eon:         config.GetEon(),
+			KeyperIndex: 0,
+			Shares: []*p2pmsg.KeyShare{{
+				EpochID: epochID.Bytes(),
+				Share:   share.Marshal(),
+			}},
+		}
+	}
+
+	b.ResetTimer()
+	for _, msg := range msgs {
+		_, err := handler.HandleMessage(ctx, msg)
+		assert.NilError(b, err)
+	}
+}
@@ -1,35 +1,133 @@
 package epochkghandler
 
 import (
+	"bytes"
 	"context"
+	"math"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
 
-func NewEonPublicKeyHandler(config Config, _ *pgxpool.Pool) p2p.MessageHandler {
-	return &EonPublicKeyHandler{config: config}
+func NewEonPublicKeyHandler(config Config, dbpool *pgxpool.Pool, signing Signing) p2p.MessageHandler {
+	return &EonPublicKeyHandler{config: config, dbpool: dbpool, signing: signing}
 }
 
+// EonPublicKeyHandler collects the votes of the keypers for eon public keys. Each keyper signs the
+// eon public key it generated. Once threshold many keypers signed the same key, it is confirmed
+// and can be verified by anyone with the collected signatures.
 type EonPublicKeyHandler struct {
-	config Config
+	config  Config
+	dbpool  *pgxpool.Pool
+	signing Signing
 }
 
 func (*EonPublicKeyHandler) MessagePrototypes() []p2pmsg.Message {
 	return []p2pmsg.Message{&p2pmsg.EonPublicKey{}}
 }
 
-func (handler *EonPublicKeyHandler) ValidateMessage(_ context.Context, msg p2pmsg.Message) (bool, error) {
+func (handler *EonPublicKeyHandler) ValidateMessage(ctx context.Context, msg p2pmsg.Message) (bool, error) {
 	key := msg.(*p2pmsg.EonPublicKey)
 	if key.GetInstanceID() != handler.config.GetInstanceID() {
 		return false, errors.Errorf("instance ID mismatch (want=%d, have=%d)", handler.config.GetInstanceID(), key.GetInstanceID())
 	}
+	if key.KeyperConfigIndex > math.MaxInt64 || key.ActivationBlock > math.MaxInt64 || key.Eon > math.MaxInt64 {
+		return false, errors.New("int64 overflow in eon public key")
+	}
+	keyperSet, err := chainobsdb.New(handler.dbpool).GetKeyperSetByKeyperConfigIndex(ctx, int64(key.KeyperConfigIndex))
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get keyper set %d from db", key.KeyperConfigIndex)
+	}
+	if keyperSet.ActivationBlockNumber != int64(key.ActivationBlock) {
+		return false, errors.Errorf(
+			"activation block mismatch (want=%d, have=%d)", keyperSet.ActivationBlockNumber, key.ActivationBlock,
+		)
+	}
+	if _, err := handler.signing.RecoverSigner(key, keyperSet.Keypers); err != nil {
+		return false, errors.Wrapf(err, "invalid signature of eon public key for keyper set %d", key.KeyperConfigIndex)
+	}
 	return true, nil
 }
 
-func (handler *EonPublicKeyHandler) HandleMessage(context.Context, p2pmsg.Message) ([]p2pmsg.Message, error) {
-	return nil, nil
+func (handler *EonPublicKeyHandler) HandleMessage(ctx context.Context, msg p2pmsg.Message) ([]p2pmsg.Message, error) {
+	key := msg.(*p2pmsg.EonPublicKey)
+	err := handler.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return StoreEonPublicKeyVote(ctx, tx, key, handler.signing)
+	})
+	return nil, err
+}
+
+// StoreEonPublicKeyVote stores the signed eon public key as a vote of its signer and confirms the
+// key once threshold many keypers of the keyper set voted for it. Only the first vote of a keyper in
+// an eon counts, voting for a different key afterwards fails with kprdb.ErrConflictingVote.
+func StoreEonPublicKeyVote(ctx context.Context, tx pgx.Tx, key *p2pmsg.EonPublicKey, signing Signing) error {
+	keyperSet, err := chainobsdb.New(tx).GetKeyperSetByKeyperConfigIndex(ctx, int64(key.KeyperConfigIndex))
+	if err != nil {
+		return errors.Wrapf(err, "failed to get keyper set %d from db", key.KeyperConfigIndex)
+	}
+	sender, err := signing.RecoverSigner(key, keyperSet.Keypers)
+	if err != nil {
+		return errors.Wrap(err, "failed to recover signer of eon public key")
+	}
+
+	db := kprdb.New(tx)
+	if err := db.ConsumeNonce(ctx, signing.Domain, sender, key); err != nil {
+		return err
+	}
+	hash := key.Hash()
+	err = db.InsertEonPublicKeyCandidate(ctx, kprdb.InsertEonPublicKeyCandidateParams{
+		Hash:                  hash,
+		EonPublicKey:          key.PublicKey,
+		ActivationBlockNumber: int64(key.ActivationBlock),
+		KeyperConfigIndex:     int64(key.KeyperConfigIndex),
+		Eon:                   int64(key.Eon),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to insert eon public key candidate")
+	}
+	votedHash, err := db.InsertEonPublicKeyVote(ctx, kprdb.InsertEonPublicKeyVoteParams{
+		Hash:              hash,
+		Sender:            shdb.EncodeAddress(sender),
+		Signature:         key.Signature,
+		Eon:               int64(key.Eon),
+		KeyperConfigIndex: int64(key.KeyperConfigIndex),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to insert eon public key vote")
+	}
+	if !bytes.Equal(votedHash, hash) {
+		return errors.Wrapf(kprdb.ErrConflictingVote, "%s voted for another key in eon %d already",
+			sender.Hex(), key.Eon)
+	}
+	count, err := db.CountEonPublicKeyVotes(ctx, hash)
+	if err != nil {
+		return err
+	}
+	logger := log.With().
+		Uint64("keyper-config-index", key.KeyperConfigIndex).
+		Uint64("eon", key.Eon).
+		Hex("hash", hash).
+		Str("sender", sender.Hex()).
+		Int32("threshold", keyperSet.Threshold).
+		Int64("count", count).
+		Logger()
+	if count < int64(keyperSet.Threshold) {
+		logger.Debug().Msg("inserted eon public key vote")
+		return nil
+	}
+	if err := db.ConfirmEonPublicKey(ctx, hash); err != nil {
+		return err
+	}
+	if count == int64(keyperSet.Threshold) {
+		logger.Info().Msg("confirmed eon public key")
+	}
+	return nil
 }
@@ -0,0 +1,90 @@
+package epochkghandler
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"testing"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p/p2ptest"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+func TestEonPublicKeyVotesIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	db, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	keys := []*ecdsa.PrivateKey{}
+	keypers := []string{}
+	for i := 0; i < 3; i++ {
+		key, err := ethcrypto.GenerateKey()
+		assert.NilError(t, err)
+		keys = append(keys, key)
+		keypers = append(keypers, shdb.EncodeAddress(ethcrypto.PubkeyToAddress(key.PublicKey)))
+	}
+	err := chainobsdb.New(dbpool).InsertKeyperSet(ctx, chainobsdb.InsertKeyperSetParams{
+		KeyperConfigIndex:     1,
+		ActivationBlockNumber: 10,
+		Keypers:               keypers,
+		Threshold:             2,
+	})
+	assert.NilError(t, err)
+
+	var handler p2p.MessageHandler = &EonPublicKeyHandler{config: config, dbpool: dbpool}
+	newKey := func(privKey *ecdsa.PrivateKey) *p2pmsg.EonPublicKey {
+		msg, err := p2pmsg.NewSignedEonPublicKey(config.GetInstanceID(), []byte("key"), 10, 1, 5, privKey)
+		assert.NilError(t, err)
+		return msg
+	}
+
+	outsider, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	p2ptest.MustValidateMessageResult(t, false, handler, ctx, newKey(outsider))
+
+	for i, privKey := range keys[:2] {
+		msg := newKey(privKey)
+		p2ptest.MustValidateMessageResult(t, true, handler, ctx, msg)
+		p2ptest.MustHandleMessage(t, handler, ctx, msg)
+
+		_, err := db.GetConfirmedEonPublicKey(ctx, 5)
+		if i == 0 {
+			assert.Assert(t, err != nil, "key confirmed with a single vote")
+		} else {
+			assert.NilError(t, err)
+		}
+	}
+
+	candidate, err := db.GetConfirmedEonPublicKey(ctx, 5)
+	assert.NilError(t, err)
+	votes, err := db.FindEonPublicKeyVotes(ctx, candidate.Hash)
+	assert.NilError(t, err)
+	assert.Equal(t, len(votes), 2)
+	for _, vote := range votes {
+		msg := newKey(keys[0])
+		msg.Signature = vote.Signature
+		sender, err := p2pmsg.RecoverAddress(msg)
+		assert.NilError(t, err)
+		assert.Equal(t, shdb.EncodeAddress(sender), vote.Sender)
+	}
+
+	// a keyper must not vote for another key in the same eon
+	equivocation, err := p2pmsg.NewSignedEonPublicKey(config.GetInstanceID(), []byte("other key"), 10, 1, 5, keys[0])
+	assert.NilError(t, err)
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return StoreEonPublicKeyVote(ctx, tx, equivocation, Signing{})
+	})
+	assert.Assert(t, errors.Is(err, kprdb.ErrConflictingVote))
+}
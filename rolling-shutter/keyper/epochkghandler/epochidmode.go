@@ -0,0 +1,83 @@
+package epochkghandler
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+// HeaderReader fetches block headers, e.g. an ethclient.Client.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// SlotReader maps execution blocks to the beacon chain slots they were proposed in, e.g. a
+// beaconapi.Client.
+type SlotReader interface {
+	SlotOfBlock(ctx context.Context, header *types.Header) (uint64, error)
+}
+
+// EpochIDValidator checks that the epoch ids of decryption triggers are derived as required by
+// the epoch id mode. A nil validator accepts any epoch id.
+type EpochIDValidator struct {
+	mode    epochid.Mode
+	headers HeaderReader
+	slots   SlotReader
+}
+
+// NewEpochIDValidator creates a validator for the given mode. headers is only used in
+// epochid.ModeBlockHash and epochid.ModeSlot, where it must provide the blocks of the chain
+// triggers refer to. slots is only used in epochid.ModeSlot and must belong to the same chain.
+func NewEpochIDValidator(mode epochid.Mode, headers HeaderReader, slots SlotReader) *EpochIDValidator {
+	return &EpochIDValidator{mode: mode, headers: headers, slots: slots}
+}
+
+// Validate checks the epoch id of a trigger for the given block.
+func (v *EpochIDValidator) Validate(ctx context.Context, blockNumber uint64, epochID epochid.EpochID) error {
+	if v == nil {
+		return nil
+	}
+	switch v.mode {
+	case epochid.ModeBlockHash:
+		return v.validateBlockHash(ctx, blockNumber, epochID)
+	case epochid.ModeSlot:
+		return v.validateSlot(ctx, blockNumber, epochID)
+	default:
+		return nil
+	}
+}
+
+func (v *EpochIDValidator) validateBlockHash(ctx context.Context, blockNumber uint64, epochID epochid.EpochID) error {
+	if epochID.BlockNumber() != blockNumber {
+		return errors.Errorf("epoch id %s is derived from block %d instead of trigger block %d",
+			epochID.Hex(), epochID.BlockNumber(), blockNumber)
+	}
+	header, err := v.headers.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch trigger block %d", blockNumber)
+	}
+	if !epochID.MatchesBlock(blockNumber, header.Hash()) {
+		return errors.Errorf("epoch id %s does not match hash %s of trigger block %d",
+			epochID.Hex(), header.Hash().Hex(), blockNumber)
+	}
+	return nil
+}
+
+func (v *EpochIDValidator) validateSlot(ctx context.Context, blockNumber uint64, epochID epochid.EpochID) error {
+	header, err := v.headers.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch trigger block %d", blockNumber)
+	}
+	slot, err := v.slots.SlotOfBlock(ctx, header)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get slot of trigger block %d", blockNumber)
+	}
+	if epochID != epochid.FromSlot(slot) {
+		return errors.Errorf("epoch id %s is not slot %d of trigger block %d", epochID.Hex(), slot, blockNumber)
+	}
+	return nil
+}
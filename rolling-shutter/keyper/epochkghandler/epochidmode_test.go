@@ -0,0 +1,58 @@
+package epochkghandler
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+type headersByNumber map[uint64]*types.Header
+
+func (h headersByNumber) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	header, ok := h[number.Uint64()]
+	if !ok {
+		return nil, context.DeadlineExceeded
+	}
+	return header, nil
+}
+
+type slotsByTime uint64
+
+func (s slotsByTime) SlotOfBlock(_ context.Context, header *types.Header) (uint64, error) {
+	return header.Time / uint64(s), nil
+}
+
+func TestEpochIDValidator(t *testing.T) {
+	ctx := context.Background()
+	header := &types.Header{Number: big.NewInt(10), Extra: []byte("block 10")}
+	headers := headersByNumber{10: header}
+	blockEpochID := epochid.FromBlock(10, header.Hash())
+	otherHeader := &types.Header{Number: big.NewInt(10), Extra: []byte("reorged block 10")}
+
+	var nilValidator *EpochIDValidator
+	assert.NilError(t, nilValidator.Validate(ctx, 10, epochid.Uint64ToEpochID(1)))
+	sequential := NewEpochIDValidator(epochid.ModeSequential, nil, nil)
+	assert.NilError(t, sequential.Validate(ctx, 10, epochid.Uint64ToEpochID(1)))
+
+	v := NewEpochIDValidator(epochid.ModeBlockHash, headers, nil)
+	assert.NilError(t, v.Validate(ctx, 10, blockEpochID))
+	assert.ErrorContains(t, v.Validate(ctx, 10, epochid.Uint64ToEpochID(1)), "derived from block 0")
+	assert.ErrorContains(t, v.Validate(ctx, 11, blockEpochID), "derived from block 10")
+	assert.ErrorContains(t, v.Validate(ctx, 10, epochid.FromBlock(10, otherHeader.Hash())), "does not match")
+	assert.ErrorContains(t, v.Validate(ctx, 12, epochid.FromBlock(12, header.Hash())), "failed to fetch")
+}
+
+func TestEpochIDValidatorSlot(t *testing.T) {
+	ctx := context.Background()
+	headers := headersByNumber{10: &types.Header{Number: big.NewInt(10), Time: 12*7 + 5}}
+
+	v := NewEpochIDValidator(epochid.ModeSlot, headers, slotsByTime(12))
+	assert.NilError(t, v.Validate(ctx, 10, epochid.FromSlot(7)))
+	assert.ErrorContains(t, v.Validate(ctx, 10, epochid.FromSlot(8)), "is not slot 7")
+	assert.ErrorContains(t, v.Validate(ctx, 11, epochid.FromSlot(7)), "failed to fetch")
+}
@@ -0,0 +1,108 @@
+package epochkghandler
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+)
+
+const gcInterval = time.Minute
+
+// GarbageCollector removes the decryption key shares that are not needed anymore. An epoch is
+// finalized as soon as its decryption key is known. The shares of finalized epochs are deleted
+// once the epoch has been finalized for longer than the epoch horizon, so that late shares are
+// still recognized as redundant in the meantime. Independently, all shares of eons older than the
+// eon horizon are deleted, including those of epochs which never got a key. A horizon of zero
+// disables the respective collection.
+type GarbageCollector struct {
+	dbpool       *pgxpool.Pool
+	epochHorizon time.Duration
+	eonHorizon   uint64
+}
+
+func NewGarbageCollector(dbpool *pgxpool.Pool, epochHorizon time.Duration, eonHorizon uint64) *GarbageCollector {
+	return &GarbageCollector{
+		dbpool:       dbpool,
+		epochHorizon: epochHorizon,
+		eonHorizon:   eonHorizon,
+	}
+}
+
+func (gc *GarbageCollector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(gcInterval)
+	defer ticker.Stop()
+	for {
+		n, err := gc.Collect(ctx)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to garbage collect decryption key shares")
+		} else if n > 0 {
+			log.Info().Int64("num-shares", n).Msg("garbage collected decryption key shares")
+			metricsEpochKGDecryptionKeySharesCollected.Add(float64(n))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect runs a single garbage collection and returns the number of deleted shares.
+func (gc *GarbageCollector) Collect(ctx context.Context) (int64, error) {
+	var deleted int64
+	err := gc.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		db := kprdb.New(tx)
+		// While the created_at migration mirrors shares into a second table, deleting them from
+		// the first one only would make the tables diverge.
+		dualWrite, err := kprdb.DecryptionKeyShareCreatedAt.IsDualWriting(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if dualWrite {
+			log.Debug().Msg("skipping garbage collection during decryption key share migration")
+			return nil
+		}
+
+		if gc.epochHorizon > 0 {
+			finalizedBefore := time.Now().Add(-gc.epochHorizon)
+			n, err := db.DeleteFinalizedDecryptionKeyShares(ctx, finalizedBefore)
+			if err != nil {
+				return errors.Wrap(err, "failed to delete decryption key shares of finalized epochs")
+			}
+			deleted += n
+			if _, err := db.DeleteFinalizedEpochsBefore(ctx, finalizedBefore); err != nil {
+				return errors.Wrap(err, "failed to delete finalized epochs")
+			}
+		}
+
+		if gc.eonHorizon > 0 {
+			lastBlock, err := db.GetLastBlockSeen(ctx)
+			if err != nil {
+				return errors.Wrap(err, "failed to get last block seen from db")
+			}
+			eon, err := db.GetEonForBlockNumber(ctx, lastBlock)
+			if err == pgx.ErrNoRows {
+				return nil
+			}
+			if err != nil {
+				return errors.Wrap(err, "failed to get current eon from db")
+			}
+			if uint64(eon.Eon) <= gc.eonHorizon {
+				return nil
+			}
+			n, err := db.DeleteDecryptionKeySharesBeforeEon(ctx, eon.Eon-int64(gc.eonHorizon))
+			if err != nil {
+				return errors.Wrap(err, "failed to delete decryption key shares of old eons")
+			}
+			deleted += n
+		}
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,95 @@
+package epochkghandler
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/broker"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// KeySource is where a decryption key came from.
+type KeySource string
+
+const (
+	// KeySourceGossip are keys received on the decryption key topic.
+	KeySourceGossip KeySource = "gossip"
+	// KeySourceAggregation are keys we aggregated from decryption key shares ourselves.
+	KeySourceAggregation KeySource = "aggregation"
+)
+
+// KeyIngester is the single entry point for decryption keys, no matter from which source they
+// come. Keys are deduplicated by eon and epoch, and a DecryptionKeyAvailable event is published
+// to the bus exactly once per key, for the first source delivering it.
+type KeyIngester struct {
+	dbpool *pgxpool.Pool
+	keys   kprdb.DecryptionKeyStore
+	bus    *broker.Bus
+}
+
+func NewKeyIngester(dbpool *pgxpool.Pool, bus *broker.Bus) *KeyIngester {
+	return &KeyIngester{dbpool: dbpool, keys: kprdb.New(dbpool), bus: bus}
+}
+
+// Known checks if a key for the same eon and epoch has already been ingested. As keys are only
+// ingested after verification, a known key does not have to be verified again. It returns
+// whether the key is known and, if it is, whether it equals the given one.
+func (ing *KeyIngester) Known(ctx context.Context, key *p2pmsg.DecryptionKey) (bool, bool, error) {
+	stored, err := ing.keys.GetDecryptionKey(ctx, kprdb.GetDecryptionKeyParams{
+		Eon:     int64(key.Eon),
+		EpochID: key.EpochID,
+	})
+	if err == pgx.ErrNoRows {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, errors.Wrap(err, "failed to get decryption key from db")
+	}
+	return true, bytes.Equal(stored.DecryptionKey, key.Key), nil
+}
+
+// Ingest stores a verified decryption key and publishes it if the key was not known before. It returns whether the key was new. A new key also finalizes its epoch, see
+// GarbageCollector.
+func (ing *KeyIngester) Ingest(ctx context.Context, key *p2pmsg.DecryptionKey, source KeySource) (bool, error) {
+	epochID, err := epochid.BytesToEpochID(key.EpochID)
+	if err != nil {
+		return false, err
+	}
+	var inserted bool
+	err = ing.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		db := kprdb.New(tx)
+		var err error
+		inserted, err = db.InsertDecryptionKeyMsg(ctx, key)
+		if err != nil || !inserted {
+			return err
+		}
+		err = db.InsertFinalizedEpoch(ctx, kprdb.InsertFinalizedEpochParams{
+			Eon:     int64(key.Eon),
+			EpochID: key.EpochID,
+		})
+		return errors.Wrap(err, "failed to insert finalized epoch into db")
+	})
+	if err != nil {
+		return false, err
+	}
+	if !inserted {
+		log.Debug().Str("epoch-id", epochID.Hex()).Str("source", string(source)).
+			Msg("ignoring already known decryption key")
+		return false, nil
+	}
+
+	err = broker.Publish(ctx, ing.bus, broker.DecryptionKeyAvailableTopic, broker.DecryptionKeyAvailable{
+		Eon:     key.Eon,
+		EpochID: epochID,
+		Key:     key.Key,
+		Source:  string(source),
+	})
+	return true, err
+}
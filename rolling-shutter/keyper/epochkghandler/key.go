@@ -17,13 +17,14 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
 
-func NewDecryptionKeyHandler(config Config, dbpool *pgxpool.Pool) p2p.MessageHandler {
-	return &DecryptionKeyHandler{config: config, dbpool: dbpool}
+func NewDecryptionKeyHandler(config Config, dbpool *pgxpool.Pool, ingester *KeyIngester) p2p.MessageHandler {
+	return &DecryptionKeyHandler{config: config, dbpool: dbpool, ingester: ingester}
 }
 
 type DecryptionKeyHandler struct {
-	config Config
-	dbpool *pgxpool.Pool
+	config   Config
+	dbpool   *pgxpool.Pool
+	ingester *KeyIngester
 }
 
 func (*DecryptionKeyHandler) MessagePrototypes() []p2pmsg.Message {
@@ -42,6 +43,15 @@ func (handler *DecryptionKeyHandler) ValidateMessage(ctx context.Context, msg p2
 		return false, errors.Errorf("eon %d overflows int64", key.Eon)
 	}
 
+	// Keys are unique per eon and epoch, so we don't need to verify keys we know already.
+	known, equal, err := handler.ingester.Known(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if known {
+		return equal, nil
+	}
+
 	dkgResultDB, err := kprdb.New(handler.dbpool).GetDKGResult(ctx, int64(key.Eon))
 	if err == pgx.ErrNoRows {
 		return false, errors.Errorf("no DKG result found for eon %d", key.Eon)
@@ -73,5 +83,6 @@ func (handler *DecryptionKeyHandler) HandleMessage(ctx context.Context, msg p2pm
 	key := msg.(*p2pmsg.DecryptionKey)
 	// Insert the key into the db. We assume that it's valid as it already passed the libp2p
 	// validator.
-	return nil, kprdb.New(handler.dbpool).InsertDecryptionKeyMsg(ctx, key)
+	_, err := handler.ingester.Ingest(ctx, key, KeySourceGossip)
+	return nil, err
 }
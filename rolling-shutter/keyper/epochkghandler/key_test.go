@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"gotest.tools/assert"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/broker"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
@@ -30,7 +32,7 @@ func TestHandleDecryptionKeyIntegration(t *testing.T) {
 
 	tkg := initializeEon(ctx, t, dbpool, keyperIndex)
 
-	var handler p2p.MessageHandler = &DecryptionKeyHandler{config: config, dbpool: dbpool}
+	var handler p2p.MessageHandler = &DecryptionKeyHandler{config: config, dbpool: dbpool, ingester: NewKeyIngester(dbpool, nil)}
 	encodedDecryptionKey := tkg.EpochSecretKey(epochID).Marshal()
 
 	// send a decryption key and check that it gets inserted
@@ -65,7 +67,7 @@ func TestDecryptionKeyValidatorIntegration(t *testing.T) {
 	tkg := initializeEon(ctx, t, dbpool, keyperIndex)
 	secretKey := tkg.EpochSecretKey(epochID).Marshal()
 
-	var handler p2p.MessageHandler = &DecryptionKeyHandler{config: config, dbpool: dbpool}
+	var handler p2p.MessageHandler = &DecryptionKeyHandler{config: config, dbpool: dbpool, ingester: NewKeyIngester(dbpool, nil)}
 	tests := []struct {
 		name  string
 		valid bool
@@ -108,3 +110,87 @@ func TestDecryptionKeyValidatorIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestKeyIngesterDeduplicatesIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	bus := broker.New()
+	notifications := broker.Subscribe(bus, broker.DecryptionKeyAvailableTopic, 2)
+	ingester := NewKeyIngester(dbpool, bus)
+
+	key := &p2pmsg.DecryptionKey{
+		InstanceID: config.GetInstanceID(),
+		Eon:        config.GetEon(),
+		EpochID:    epochid.Uint64ToEpochID(50).Bytes(),
+		Key:        []byte{1, 2, 3},
+	}
+	inserted, err := ingester.Ingest(ctx, key, KeySourceAggregation)
+	assert.NilError(t, err)
+	assert.Check(t, inserted)
+	inserted, err = ingester.Ingest(ctx, key, KeySourceGossip)
+	assert.NilError(t, err)
+	assert.Check(t, !inserted)
+
+	notification := <-notifications
+	assert.Equal(t, notification.Source, string(KeySourceAggregation))
+	assert.Check(t, bytes.Equal(notification.Key, key.Key))
+	select {
+	case <-notifications:
+		t.Error("duplicate key published")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	known, equal, err := ingester.Known(ctx, key)
+	assert.NilError(t, err)
+	assert.Check(t, known && equal)
+}
+
+func TestGarbageCollectFinalizedEpochsIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	db, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	eon := config.GetEon()
+	finalized := epochid.Uint64ToEpochID(50)
+	pending := epochid.Uint64ToEpochID(51)
+	for _, epochID := range []epochid.EpochID{finalized, pending} {
+		err := db.InsertDecryptionKeyShare(ctx, kprdb.InsertDecryptionKeyShareParams{
+			Eon:                int64(eon),
+			EpochID:            epochID.Bytes(),
+			KeyperIndex:        1,
+			DecryptionKeyShare: []byte{1},
+		})
+		assert.NilError(t, err)
+	}
+	_, err := NewKeyIngester(dbpool, nil).Ingest(ctx, &p2pmsg.DecryptionKey{
+		InstanceID: config.GetInstanceID(),
+		Eon:        eon,
+		EpochID:    finalized.Bytes(),
+		Key:        []byte{1, 2, 3},
+	}, KeySourceAggregation)
+	assert.NilError(t, err)
+
+	n, err := NewGarbageCollector(dbpool, time.Hour, 0).Collect(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, n, int64(0), "shares must be kept within the epoch horizon")
+
+	n, err = NewGarbageCollector(dbpool, -time.Hour, 0).Collect(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, n, int64(1))
+	for epochID, expected := range map[epochid.EpochID]int64{finalized: 0, pending: 1} {
+		count, err := db.CountDecryptionKeyShares(ctx, kprdb.CountDecryptionKeySharesParams{
+			Eon:     int64(eon),
+			EpochID: epochID.Bytes(),
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, count, expected)
+	}
+}
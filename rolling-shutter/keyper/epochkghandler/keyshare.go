@@ -3,6 +3,7 @@ package epochkghandler
 import (
 	"context"
 	"math"
+	"time"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
@@ -14,19 +15,33 @@ import (
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/epochkg"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/sla"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
 
-func NewDecryptionKeyShareHandler(config Config, dbpool *pgxpool.Pool) p2p.MessageHandler {
-	return &DecryptionKeyShareHandler{config: config, dbpool: dbpool}
+// NewDecryptionKeyShareHandler creates the handler of decryption key shares. If verifier is nil,
+// each share is verified on its own.
+func NewDecryptionKeyShareHandler(
+	config Config, dbpool *pgxpool.Pool, ingester *KeyIngester, verifier *ShareVerifier, tracker *sla.Tracker,
+) p2p.MessageHandler {
+	return &DecryptionKeyShareHandler{
+		config:   config,
+		dbpool:   dbpool,
+		ingester: ingester,
+		verifier: verifier,
+		sla:      tracker,
+	}
 }
 
 type DecryptionKeyShareHandler struct {
-	config Config
-	dbpool *pgxpool.Pool
+	config   Config
+	dbpool   *pgxpool.Pool
+	ingester *KeyIngester
+	verifier *ShareVerifier
+	sla      *sla.Tracker
 }
 
 func (*DecryptionKeyShareHandler) MessagePrototypes() []p2pmsg.Message {
@@ -59,6 +74,7 @@ func (handler *DecryptionKeyShareHandler) ValidateMessage(ctx context.Context, m
 	if len(keyShare.Shares) != 1 {
 		return false, errors.New("decryption key share must have exactly one share")
 	}
+	shares := make([]ShareToVerify, 0, len(keyShare.GetShares()))
 	for _, share := range keyShare.GetShares() {
 		if _, err = epochid.BytesToEpochID(share.EpochID); err != nil {
 			return false, errors.Wrap(err, "invalid epoch id")
@@ -67,29 +83,44 @@ func (handler *DecryptionKeyShareHandler) ValidateMessage(ctx context.Context, m
 		if err != nil {
 			return false, err
 		}
-		if !shcrypto.VerifyEpochSecretKeyShare(
-			epochSecretKeyShare,
-			pureDKGResult.PublicKeyShares[keyShare.KeyperIndex],
-			shcrypto.ComputeEpochID(share.EpochID),
-		) {
-			return false, errors.Errorf("cannot verify secret key share")
+		shares = append(shares, ShareToVerify{
+			Share:     epochSecretKeyShare,
+			PublicKey: pureDKGResult.PublicKeyShares[keyShare.KeyperIndex],
+			EpochID:   share.EpochID,
+		})
+	}
+	ok, err := handler.verifyShares(ctx, shares)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, errors.Errorf("cannot verify secret key share")
+	}
+	return true, nil
+}
+
+// verifyShares checks all shares of a message. With a batch verifier, they are submitted in a
+// single call.
+func (handler *DecryptionKeyShareHandler) verifyShares(ctx context.Context, shares []ShareToVerify) (bool, error) {
+	if handler.verifier != nil {
+		return handler.verifier.VerifyAll(ctx, shares)
+	}
+	for _, s := range shares {
+		if !shcrypto.VerifyEpochSecretKeyShare(s.Share, s.PublicKey, shcrypto.ComputeEpochID(s.EpochID)) {
+			return false, nil
 		}
 	}
 	return true, nil
 }
 
 func (handler *DecryptionKeyShareHandler) HandleMessage(ctx context.Context, m p2pmsg.Message) ([]p2pmsg.Message, error) {
+	receivedAt := time.Now()
 	metricsEpochKGDecryptionKeySharesReceived.Inc()
 	msg := m.(*p2pmsg.DecryptionKeyShares)
-	// Insert the share into the db. We assume that it's valid as it already passed the libp2p
-	// validator.
 	db := kprdb.New(handler.dbpool)
 
-	if err := db.InsertDecryptionKeySharesMsg(ctx, msg); err != nil {
-		return nil, err
-	}
-
-	// Check that we don't know the decryption key yet
+	// Check that we don't know the decryption key yet. Otherwise, the share is not needed and we
+	// don't store it, as the shares of finalized epochs are garbage collected.
 	epochID, err := epochid.BytesToEpochID(msg.GetShares()[0].EpochID)
 	if err != nil {
 		return nil, err
@@ -105,6 +136,12 @@ func (handler *DecryptionKeyShareHandler) HandleMessage(ctx context.Context, m p
 		return nil, nil
 	}
 
+	// Insert the share into the db. We assume that it's valid as it already passed the libp2p
+	// validator.
+	if err := db.InsertDecryptionKeySharesMsg(ctx, msg); err != nil {
+		return nil, err
+	}
+
 	// fetch dkg result from db
 	dkgResultDB, err := db.GetDKGResult(ctx, int64(msg.Eon))
 	if err != nil {
@@ -137,16 +174,21 @@ func (handler *DecryptionKeyShareHandler) HandleMessage(ctx context.Context, m p
 			epochID,
 		)
 	}
+	handler.sla.SharesComplete(ctx, epochID, receivedAt)
 	message := &p2pmsg.DecryptionKey{
 		InstanceID: handler.config.GetInstanceID(),
 		Eon:        msg.Eon,
 		EpochID:    epochID.Bytes(),
 		Key:        decryptionKey.Marshal(),
 	}
-	err = db.InsertDecryptionKeyMsg(ctx, message)
+	inserted, err := handler.ingester.Ingest(ctx, message, KeySourceAggregation)
 	if err != nil {
 		return nil, err
 	}
+	if !inserted {
+		// another source delivered the key while we were aggregating it
+		return nil, nil
+	}
 	metricsEpochKGDecryptionKeysGenerated.Inc()
 	log.Info().Str("epoch-id", epochID.Hex()).Str("message", message.LogInfo()).
 		Msg("broadcasting decryption key")
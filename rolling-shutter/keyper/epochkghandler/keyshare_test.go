@@ -27,7 +27,7 @@ func TestHandleDecryptionKeyShareIntegration(t *testing.T) {
 	keyperIndex := uint64(1)
 
 	tkg := initializeEon(ctx, t, dbpool, keyperIndex)
-	var handler p2p.MessageHandler = &DecryptionKeyShareHandler{config: config, dbpool: dbpool}
+	var handler p2p.MessageHandler = &DecryptionKeyShareHandler{config: config, dbpool: dbpool, ingester: NewKeyIngester(dbpool, nil)}
 	encodedDecryptionKey := tkg.EpochSecretKey(epochID).Marshal()
 
 	// threshold is two, so no outgoing message after first input
@@ -76,7 +76,7 @@ func TestDecryptionKeyshareValidatorIntegration(t *testing.T) {
 	wrongEpochID, _ := epochid.BigToEpochID(common.Big1)
 	tkg := initializeEon(ctx, t, dbpool, keyperIndex)
 	keyshare := tkg.EpochSecretKeyShare(epochID, keyperIndex).Marshal()
-	var handler p2p.MessageHandler = &DecryptionKeyShareHandler{config: config, dbpool: dbpool}
+	var handler p2p.MessageHandler = &DecryptionKeyShareHandler{config: config, dbpool: dbpool, ingester: NewKeyIngester(dbpool, nil)}
 
 	tests := []struct {
 		name  string
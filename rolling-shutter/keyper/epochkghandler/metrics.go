@@ -38,6 +38,15 @@ var metricsEpochKGDecryptionKeySharesSent = prometheus.NewCounter(
 	},
 )
 
+var metricsEpochKGDecryptionKeySharesCollected = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "epochkg",
+		Name:      "decryption_keyshares_collected_total",
+		Help:      "Number of garbage collected decryption key shares",
+	},
+)
+
 var metricsEpochKGDectyptionTriggersReceived = prometheus.NewCounter(
 	prometheus.CounterOpts{
 		Namespace: "shutter",
@@ -47,10 +56,75 @@ var metricsEpochKGDectyptionTriggersReceived = prometheus.NewCounter(
 	},
 )
 
+var metricsEpochKGTriggersRejected = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "epochkg",
+		Name:      "decryption_triggers_rejected_total",
+		Help:      "Number of decryption triggers ignored by the policy, clock skew or offset checks, by rule",
+	},
+	[]string{"rule"},
+)
+
+var metricsEpochKGTriggersDelayed = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "epochkg",
+		Name:      "decryption_triggers_delayed_total",
+		Help:      "Number of decryption triggers whose key shares were held back until the publication delay passed",
+	},
+)
+
+var metricsEpochKGPreAnnouncementsReceived = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "epochkg",
+		Name:      "epoch_pre_announcements_received_total",
+		Help:      "Number of received epoch pre-announcements",
+	},
+)
+
+var metricsEpochKGTriggerTimeDrift = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Namespace: "shutter",
+		Subsystem: "epochkg",
+		Name:      "decryption_trigger_time_drift_seconds",
+		Help:      "Time between the pre-announced and the actual reception of decryption triggers",
+		Buckets:   []float64{-5, -2, -1, -0.5, -0.25, 0, 0.25, 0.5, 1, 2, 5, 10},
+	},
+)
+
+var metricsEpochKGTriggerBlockDrift = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Namespace: "shutter",
+		Subsystem: "epochkg",
+		Name:      "decryption_trigger_block_drift",
+		Help:      "Difference between the actual and the pre-announced block number of decryption triggers",
+		Buckets:   []float64{-2, -1, 0, 1, 2, 5, 10},
+	},
+)
+
+var metricsEpochKGSelfAuditedShares = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "epochkg",
+		Name:      "self_audited_keyshares_total",
+		Help:      "Number of own decryption key shares verified before sending, by result",
+	},
+	[]string{"result"},
+)
+
 func InitMetrics() {
 	prometheus.MustRegister(metricsEpochKGDecryptionKeysReceived)
 	prometheus.MustRegister(metricsEpochKGDecryptionKeysGenerated)
 	prometheus.MustRegister(metricsEpochKGDecryptionKeySharesReceived)
 	prometheus.MustRegister(metricsEpochKGDecryptionKeySharesSent)
+	prometheus.MustRegister(metricsEpochKGDecryptionKeySharesCollected)
 	prometheus.MustRegister(metricsEpochKGDectyptionTriggersReceived)
+	prometheus.MustRegister(metricsEpochKGTriggersRejected)
+	prometheus.MustRegister(metricsEpochKGTriggersDelayed)
+	prometheus.MustRegister(metricsEpochKGPreAnnouncementsReceived)
+	prometheus.MustRegister(metricsEpochKGTriggerTimeDrift)
+	prometheus.MustRegister(metricsEpochKGTriggerBlockDrift)
+	prometheus.MustRegister(metricsEpochKGSelfAuditedShares)
 }
@@ -0,0 +1,153 @@
+package epochkghandler
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/clockcheck"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/triggeroffset"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// maxPreAnnouncementLead and maxPreAnnouncementDelay bound how far the expected trigger time of a
+// pre-announcement may lie in the future and in the past of our local clock. Announcements
+// outside of this window either come from a collator with a badly misaligned clock or are replays.
+const (
+	maxPreAnnouncementLead  = 5 * time.Minute
+	maxPreAnnouncementDelay = time.Minute
+)
+
+// triggerOffsetTolerance allows for the difference between the clocks of the collator and ours
+// when checking that a trigger isn't premature.
+const triggerOffsetTolerance = time.Second
+
+// rulePremature is the rule label of triggers rejected because they were sent before the trigger
+// offset had passed.
+const rulePremature = "premature"
+
+func NewEpochPreAnnouncementHandler(config Config, dbpool *pgxpool.Pool) p2p.MessageHandler {
+	return &EpochPreAnnouncementHandler{config: config, dbpool: dbpool}
+}
+
+// EpochPreAnnouncementHandler stores the announcements of upcoming epochs sent by the collator, so
+// that the drift between announced and actual triggers can be measured once the trigger arrives.
+type EpochPreAnnouncementHandler struct {
+	config Config
+	dbpool *pgxpool.Pool
+}
+
+func (*EpochPreAnnouncementHandler) MessagePrototypes() []p2pmsg.Message {
+	return []p2pmsg.Message{&p2pmsg.EpochPreAnnouncement{}}
+}
+
+func (handler *EpochPreAnnouncementHandler) ValidateMessage(ctx context.Context, msg p2pmsg.Message) (bool, error) {
+	announcement := msg.(*p2pmsg.EpochPreAnnouncement)
+	if announcement.GetInstanceID() != handler.config.GetInstanceID() {
+		return false, errors.Errorf(
+			"instance ID mismatch (want=%d, have=%d)", handler.config.GetInstanceID(), announcement.GetInstanceID(),
+		)
+	}
+	if _, err := epochid.BytesToEpochID(announcement.EpochID); err != nil {
+		return false, errors.Wrapf(err, "invalid epoch id")
+	}
+	if announcement.BlockNumber > math.MaxInt64 {
+		return false, errors.Errorf("block number %d overflows int64", announcement.BlockNumber)
+	}
+	if announcement.ExpectedTriggerTime > math.MaxInt64 {
+		return false, errors.Errorf("expected trigger time %d overflows int64", announcement.ExpectedTriggerTime)
+	}
+	offset := time.Until(announcement.ExpectedTriggerTimestamp())
+	if offset > maxPreAnnouncementLead || offset < -maxPreAnnouncementDelay {
+		return false, errors.Errorf(
+			"expected trigger time %s too far from local time (offset %s)",
+			announcement.ExpectedTriggerTimestamp().Format(time.RFC3339), offset,
+		)
+	}
+
+	err := verifyCollatorSignature(ctx, handler.dbpool, announcement, announcement.BlockNumber)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid pre-announcement for epoch: %x", announcement.EpochID)
+	}
+	return true, nil
+}
+
+func (handler *EpochPreAnnouncementHandler) HandleMessage(ctx context.Context, m p2pmsg.Message) ([]p2pmsg.Message, error) {
+	announcement, ok := m.(*p2pmsg.EpochPreAnnouncement)
+	if !ok {
+		return nil, errors.New("Message type assertion mismatch")
+	}
+	metricsEpochKGPreAnnouncementsReceived.Inc()
+	log.Debug().Str("message", announcement.LogInfo()).Msg("received epoch pre-announcement")
+	err := kprdb.New(handler.dbpool).InsertEpochPreAnnouncement(ctx, kprdb.InsertEpochPreAnnouncementParams{
+		EpochID:             announcement.EpochID,
+		BlockNumber:         int64(announcement.BlockNumber),
+		ExpectedTriggerTime: announcement.ExpectedTriggerTimestamp(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to store epoch pre-announcement")
+	}
+	return nil, nil
+}
+
+// observeTriggerDrift records how far a trigger received now deviates from the pre-announcement of
+// its epoch. Triggers without a pre-announcement are not taken into account.
+func observeTriggerDrift(ctx context.Context, db *kprdb.Queries, blockNumber int64, epochID epochid.EpochID) {
+	announcement, err := db.GetEpochPreAnnouncement(ctx, epochID.Bytes())
+	if err == pgx.ErrNoRows {
+		return
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("epoch-id", epochID.Hex()).Msg("failed to get epoch pre-announcement")
+		return
+	}
+	timeDrift := time.Since(announcement.ExpectedTriggerTime)
+	blockDrift := blockNumber - announcement.BlockNumber
+	metricsEpochKGTriggerTimeDrift.Observe(timeDrift.Seconds())
+	metricsEpochKGTriggerBlockDrift.Observe(float64(blockDrift))
+	log.Debug().
+		Str("epoch-id", epochID.Hex()).
+		Dur("time-drift", timeDrift).
+		Int64("block-drift", blockDrift).
+		Msg("decryption trigger deviates from pre-announcement")
+}
+
+// validateTriggerOffset checks a trigger against the pre-announcement of its epoch shifted by the
+// trigger offset. Premature triggers are counted and fail validation, so they are neither handled
+// nor relayed to our peers. Triggers without a pre-announcement are accepted.
+func validateTriggerOffset(
+	ctx context.Context,
+	db *kprdb.Queries,
+	offset triggeroffset.Offset,
+	clock *clockcheck.Monitor,
+	blockNumber uint64,
+	epochID epochid.EpochID,
+) error {
+	if offset.IsZero() {
+		return nil
+	}
+	announcement, err := db.GetEpochPreAnnouncement(ctx, epochID.Bytes())
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to get pre-announcement of epoch %s", epochID.Hex())
+	}
+	err = offset.Check(
+		uint64(announcement.BlockNumber), announcement.ExpectedTriggerTime,
+		blockNumber, clock.Now(), triggerOffsetTolerance,
+	)
+	if err != nil {
+		metricsEpochKGTriggersRejected.WithLabelValues(rulePremature).Inc()
+		return errors.Wrapf(err, "premature decryption trigger for epoch %s", epochID.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package epochkghandler
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/clockcheck"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/jobqueue"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// DelayedTriggerJobKind is the kind of the jobs that handle triggers once their publication
+// delay has passed.
+const DelayedTriggerJobKind = "delayed-decryption-trigger"
+
+// MessageSender broadcasts messages, e.g. a p2p.P2PHandler.
+type MessageSender interface {
+	SendMessage(ctx context.Context, msg p2pmsg.Message, retryOpts ...retry.Option) error
+}
+
+// PublicationDelay holds back our decryption key shares until a minimum time has passed since
+// the trigger, so that keys don't become known before a deadline, e.g. for timelock encryption.
+// The delay starts at the timestamp of the trigger block instead of the time the trigger is
+// received, so that all keypers configured with the same delay publish at the same time. Held
+// back triggers are stored in the job queue, so they survive restarts. The release time is
+// compensated for the offset of the local clock measured by clock. A nil delay publishes
+// immediately.
+type PublicationDelay struct {
+	delay   time.Duration
+	headers HeaderReader
+	dbpool  *pgxpool.Pool
+	clock   *clockcheck.Monitor
+}
+
+// NewPublicationDelay returns nil if delay is not positive. headers must provide the blocks of
+// the chain triggers refer to. clock may be nil to use the local clock as is.
+func NewPublicationDelay(
+	delay time.Duration, headers HeaderReader, dbpool *pgxpool.Pool, clock *clockcheck.Monitor,
+) *PublicationDelay {
+	if delay <= 0 {
+		return nil
+	}
+	return &PublicationDelay{delay: delay, headers: headers, dbpool: dbpool, clock: clock}
+}
+
+type delayedTrigger struct {
+	BlockNumber int64         `json:"blockNumber"`
+	EpochID     hexutil.Bytes `json:"epochID"`
+}
+
+// ReleaseTime returns the time from which our shares for a trigger in the given block may be
+// published.
+func (d *PublicationDelay) ReleaseTime(ctx context.Context, blockNumber uint64) (time.Time, error) {
+	header, err := d.headers.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to fetch trigger block %d", blockNumber)
+	}
+	return time.Unix(int64(header.Time), 0).Add(d.delay), nil
+}
+
+// Hold reports whether the trigger has to wait for its release time, in which case it is
+// scheduled to be handled by DelayedTriggerJobHandler.
+func (d *PublicationDelay) Hold(ctx context.Context, blockNumber uint64, epochID epochid.EpochID) (bool, error) {
+	if d == nil {
+		return false, nil
+	}
+	releaseTime, err := d.ReleaseTime(ctx, blockNumber)
+	if err != nil {
+		return false, err
+	}
+	if !d.clock.Now().Before(releaseTime) {
+		return false, nil
+	}
+	trigger := delayedTrigger{BlockNumber: int64(blockNumber), EpochID: epochID.Bytes()}
+	// the job queue runs jobs by the local clock
+	runAt := d.clock.Local(releaseTime)
+	if err := jobqueue.EnqueueAt(ctx, d.dbpool, DelayedTriggerJobKind, trigger, runAt); err != nil {
+		return false, err
+	}
+	metricsEpochKGTriggersDelayed.Inc()
+	log.Info().Str("epoch-id", epochID.Hex()).Time("release-time", releaseTime).
+		Msg("holding back decryption key share until release time")
+	return true, nil
+}
+
+// DelayedTriggerJobHandler returns the job handler sending our decryption key shares for held
+// back triggers. It must be registered even if the delay is disabled, so that triggers held back
+// before are still handled.
+func DelayedTriggerJobHandler(
+	config Config, dbpool *pgxpool.Pool, fence kprdb.Fence, selfAudit *SelfAudit, sender MessageSender,
+) jobqueue.Handler {
+	return func(ctx context.Context, payload []byte) error {
+		var trigger delayedTrigger
+		if err := json.Unmarshal(payload, &trigger); err != nil {
+			return jobqueue.Permanent(errors.Wrap(err, "failed to decode delayed trigger"))
+		}
+		epochID, err := epochid.BytesToEpochID(trigger.EpochID)
+		if err != nil {
+			return jobqueue.Permanent(err)
+		}
+		msgs, err := handleTrigger(ctx, config, kprdb.New(dbpool), fence, selfAudit, trigger.BlockNumber, epochID)
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if err := sender.SendMessage(ctx, msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
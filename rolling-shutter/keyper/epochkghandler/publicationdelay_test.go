@@ -0,0 +1,88 @@
+package epochkghandler
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v4"
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/jobdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+type recordingSender struct {
+	msgs []p2pmsg.Message
+}
+
+func (s *recordingSender) SendMessage(_ context.Context, msg p2pmsg.Message, _ ...retry.Option) error {
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
+func TestPublicationDelay(t *testing.T) {
+	ctx := context.Background()
+	blockTime := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	headers := headersByNumber{10: &types.Header{Number: big.NewInt(10), Time: uint64(blockTime.Unix())}}
+
+	assert.Assert(t, NewPublicationDelay(0, headers, nil, nil) == nil)
+	var nilDelay *PublicationDelay
+	held, err := nilDelay.Hold(ctx, 10, epochid.Uint64ToEpochID(1))
+	assert.NilError(t, err)
+	assert.Assert(t, !held)
+
+	d := NewPublicationDelay(time.Hour, headers, nil, nil)
+	releaseTime, err := d.ReleaseTime(ctx, 10)
+	assert.NilError(t, err)
+	assert.Assert(t, releaseTime.Equal(blockTime.Add(time.Hour)))
+	_, err = d.ReleaseTime(ctx, 11)
+	assert.ErrorContains(t, err, "failed to fetch")
+
+	// the release time of the old block has passed, so the trigger is handled right away
+	held, err = d.Hold(ctx, 10, epochid.Uint64ToEpochID(1))
+	assert.NilError(t, err)
+	assert.Assert(t, !held)
+}
+
+func TestPublicationDelayIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	initializeEon(ctx, t, dbpool, 1)
+	headers := headersByNumber{0: &types.Header{Number: big.NewInt(0), Time: uint64(time.Now().Unix())}}
+	d := NewPublicationDelay(time.Hour, headers, dbpool, nil)
+
+	epochID := epochid.Uint64ToEpochID(50)
+	held, err := d.Hold(ctx, 0, epochID)
+	assert.NilError(t, err)
+	assert.Assert(t, held)
+
+	db := jobdb.New(dbpool)
+	_, err = db.ClaimDueJob(ctx, jobdb.ClaimDueJobParams{LeaseUntil: time.Now().Add(time.Minute), Now: time.Now()})
+	assert.Equal(t, err, pgx.ErrNoRows, "trigger must be held back until the release time")
+	job, err := db.ClaimDueJob(ctx, jobdb.ClaimDueJobParams{
+		LeaseUntil: time.Now().Add(3 * time.Hour),
+		Now:        time.Now().Add(2 * time.Hour),
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, job.Kind, DelayedTriggerJobKind)
+
+	sender := &recordingSender{}
+	handle := DelayedTriggerJobHandler(config, dbpool, dbpool, nil, sender)
+	assert.NilError(t, handle(ctx, job.Payload))
+	assert.Equal(t, len(sender.msgs), 1)
+	shares, ok := sender.msgs[0].(*p2pmsg.DecryptionKeyShares)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, shares.Shares[0].EpochID, epochID.Bytes())
+}
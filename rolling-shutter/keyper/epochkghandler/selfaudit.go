@@ -0,0 +1,94 @@
+package epochkghandler
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/alert"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+)
+
+// SelfAudit verifies the decryption key shares we compute against our eon public key share before
+// they are sent. A share that does not verify means that our key material is corrupted. Instead of
+// broadcasting such shares, the failure is recorded in the db, the operator is alerted and no
+// further shares are sent for the eon until the recorded failures are deleted.
+//
+// A nil SelfAudit still verifies and records shares, but only logs failures.
+type SelfAudit struct {
+	notifier alert.Notifier
+}
+
+func NewSelfAudit(notifier alert.Notifier) *SelfAudit {
+	return &SelfAudit{notifier: notifier}
+}
+
+// checkEon fails with errcode.ErrSelfAuditFailed if a share of the eon failed the self-audit
+// before.
+func (a *SelfAudit) checkEon(ctx context.Context, db *kprdb.Queries, eon int64) error {
+	failed, err := db.ExistsShareSelfAuditFailure(ctx, eon)
+	if err != nil {
+		return errcode.WrapDB(err, "failed to query self-audit failures of eon %d from db", eon)
+	}
+	if failed {
+		return errcode.ErrSelfAuditFailed.Errorf("not sending decryption key shares for eon %d", eon)
+	}
+	return nil
+}
+
+// verify checks that share is our share of the epoch secret key. If it's not, the failure is
+// recorded and errcode.ErrSelfAuditFailed is returned.
+func (a *SelfAudit) verify(
+	ctx context.Context,
+	db *kprdb.Queries,
+	eon int64,
+	keyperIndex int64,
+	publicKeyShare *shcrypto.EonPublicKeyShare,
+	epochID epochid.EpochID,
+	share *shcrypto.EpochSecretKeyShare,
+) error {
+	if shcrypto.VerifyEpochSecretKeyShare(share, publicKeyShare, shcrypto.ComputeEpochID(epochID.Bytes())) {
+		metricsEpochKGSelfAuditedShares.WithLabelValues("ok").Inc()
+		return nil
+	}
+	metricsEpochKGSelfAuditedShares.WithLabelValues("failed").Inc()
+	err := db.InsertShareSelfAuditFailure(ctx, kprdb.InsertShareSelfAuditFailureParams{
+		Eon:         eon,
+		EpochID:     epochID.Bytes(),
+		KeyperIndex: keyperIndex,
+		Share:       share.Marshal(),
+	})
+	if err != nil {
+		return errcode.WrapDB(err, "failed to record self-audit failure in db")
+	}
+	a.notify(ctx, eon, keyperIndex, epochID)
+	return errcode.ErrSelfAuditFailed.Errorf(
+		"decryption key share for epoch %s of eon %d does not verify against our eon public key share",
+		epochID.Hex(), eon,
+	)
+}
+
+func (a *SelfAudit) notify(ctx context.Context, eon int64, keyperIndex int64, epochID epochid.EpochID) {
+	details := map[string]string{
+		"eon":          strconv.FormatInt(eon, 10),
+		"keyper-index": strconv.FormatInt(keyperIndex, 10),
+		"epoch-id":     epochID.Hex(),
+	}
+	if a == nil || a.notifier == nil {
+		log.Error().Fields(details).Msg("decryption key share failed the self-audit, stopped sending shares for the eon")
+		return
+	}
+	err := a.notifier.Notify(ctx, alert.Alert{
+		Severity: alert.SeverityCritical,
+		Summary:  "decryption key share failed the self-audit, stopped sending shares for the eon",
+		Details:  details,
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to send self-audit alert")
+	}
+}
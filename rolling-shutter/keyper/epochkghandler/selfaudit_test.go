@@ -0,0 +1,55 @@
+package epochkghandler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+)
+
+func TestSelfAuditIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	db, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	keyperIndex := uint64(1)
+	tkg := initializeEon(ctx, t, dbpool, keyperIndex)
+	eon := int64(config.GetEon())
+	epochID := epochid.Uint64ToEpochID(50)
+	publicKeyShare := tkg.EonPublicKeyShare(epochID, keyperIndex)
+
+	var selfAudit *SelfAudit
+	err := selfAudit.verify(
+		ctx, db, eon, int64(keyperIndex), publicKeyShare, epochID, tkg.EpochSecretKeyShare(epochID, keyperIndex),
+	)
+	assert.NilError(t, err)
+	assert.NilError(t, selfAudit.checkEon(ctx, db, eon))
+
+	// the share of another keyper stands in for a share computed from corrupted key material
+	err = selfAudit.verify(
+		ctx, db, eon, int64(keyperIndex), publicKeyShare, epochID, tkg.EpochSecretKeyShare(epochID, 0),
+	)
+	assert.Assert(t, errors.Is(err, errcode.ErrSelfAuditFailed))
+	assert.Assert(t, errors.Is(selfAudit.checkEon(ctx, db, eon), errcode.ErrSelfAuditFailed))
+
+	// no more shares are sent for the eon
+	_, err = SendDecryptionKeyShare(ctx, config, db, dbpool, selfAudit, 0, epochid.Uint64ToEpochID(51))
+	assert.Assert(t, errors.Is(err, errcode.ErrSelfAuditFailed))
+	exists, err := db.ExistsDecryptionKeyShare(ctx, kprdb.ExistsDecryptionKeyShareParams{
+		Eon:         eon,
+		EpochID:     epochid.Uint64ToEpochID(51).Bytes(),
+		KeyperIndex: int64(keyperIndex),
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, !exists)
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/epochkg"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/pause"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
@@ -19,10 +22,17 @@ type Config interface {
 	GetInstanceID() uint64
 }
 
+// SendDecryptionKeyShare computes our decryption key shares for the given epochs. It fails with
+// errcode.ErrNotInKeyperSet if we are not a keyper of the eon active at blockNumber and with
+// errcode.ErrSelfAuditFailed if our shares of the eon failed the self-audit. While the keypers
+// decided to pause key generation, it fails with errcode.ErrKeyGenerationPaused. The shares are
+// stored in a transaction run by fence and not returned if it fails.
 func SendDecryptionKeyShare(
 	ctx context.Context,
 	config Config,
 	db *kprdb.Queries,
+	fence kprdb.Fence,
+	selfAudit *SelfAudit,
 	blockNumber int64,
 	epochIDs ...epochid.EpochID,
 ) ([]p2pmsg.Message, error) {
@@ -30,12 +40,15 @@ func SendDecryptionKeyShare(
 		return nil, errors.New("cannot generate empty decryption key share")
 	}
 	eon, err := db.GetEonForBlockNumber(ctx, blockNumber)
+	if err == pgx.ErrNoRows {
+		return nil, errcode.ErrEonUnknown.Errorf("no eon known for block %d", blockNumber)
+	}
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get eon for block %d from db", blockNumber)
+		return nil, errcode.WrapDB(err, "failed to get eon for block %d from db", blockNumber)
 	}
 	batchConfig, err := db.GetBatchConfig(ctx, int32(eon.KeyperConfigIndex))
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get config %d from db", eon.KeyperConfigIndex)
+		return nil, errcode.WrapDB(err, "failed to get config %d from db", eon.KeyperConfigIndex)
 	}
 
 	// get our keyper index (and check that we in fact are a keyper)
@@ -48,7 +61,19 @@ func SendDecryptionKeyShare(
 		}
 	}
 	if keyperIndex == -1 {
-		log.Info().Msg("ignoring decryption trigger: we are not a keyper")
+		return nil, errcode.ErrNotInKeyperSet.Errorf("not a keyper in keyper set %d", eon.KeyperConfigIndex)
+	}
+
+	// check if the epoch is finalized already, in which case our share is not needed anymore and
+	// might have been garbage collected
+	keyExists, err := db.ExistsDecryptionKey(ctx, kprdb.ExistsDecryptionKeyParams{
+		Eon:     eon.Eon,
+		EpochID: epochIDs[0].Bytes(),
+	})
+	if err != nil {
+		return nil, errcode.WrapDB(err, "failed to query decryption key for epoch from db")
+	}
+	if keyExists {
 		return nil, nil
 	}
 
@@ -60,16 +85,22 @@ func SendDecryptionKeyShare(
 		KeyperIndex: keyperIndex,
 	})
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get decryption key share for epoch from db")
+		return nil, errcode.WrapDB(err, "failed to get decryption key share for epoch from db")
 	}
 	if shareExists {
 		return nil, nil // we already sent our share
 	}
+	if err := pause.Check(ctx, db); err != nil {
+		return nil, err
+	}
+	if err := selfAudit.checkEon(ctx, db, eon.Eon); err != nil {
+		return nil, err
+	}
 
 	// fetch dkg result from db
 	dkgResultDB, err := db.GetDKGResult(ctx, eon.Eon)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get dkg result for eon %d from db", eon.Eon)
+		return nil, errcode.WrapDB(err, "failed to get dkg result for eon %d from db", eon.Eon)
 	}
 	if !dkgResultDB.Success {
 		log.Info().Int64("eon", eon.Eon).Msg("ignoring decryption trigger: eon key generation failed")
@@ -86,6 +117,12 @@ func SendDecryptionKeyShare(
 
 	for _, epochID := range epochIDs {
 		share := epochKG.ComputeEpochSecretKeyShare(epochID)
+		err := selfAudit.verify(
+			ctx, db, eon.Eon, keyperIndex, pureDKGResult.PublicKeyShares[keyperIndex], epochID, share,
+		)
+		if err != nil {
+			return nil, err
+		}
 
 		shares = append(shares, &p2pmsg.KeyShare{
 			EpochID: epochID.Bytes(),
@@ -99,9 +136,11 @@ func SendDecryptionKeyShare(
 		KeyperIndex: uint64(keyperIndex),
 		Shares:      shares,
 	}
-	err = db.InsertDecryptionKeySharesMsg(ctx, msg)
+	err = fence.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return kprdb.New(tx).InsertDecryptionKeySharesMsg(ctx, msg)
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to insert decryption key share")
+		return nil, errcode.WrapDB(err, "failed to insert decryption key share")
 	}
 	metricsEpochKGDecryptionKeySharesSent.Inc()
 	log.Info().Int64("block-number", blockNumber).Msg("sending decryption key share")
@@ -54,7 +54,7 @@ func (c *TestConfig) GetCollatorKey() *ecdsa.PrivateKey {
 
 func initializeEon(
 	ctx context.Context,
-	t *testing.T,
+	t testing.TB,
 	dbpool *pgxpool.Pool,
 	keyperIndex uint64, //nolint:unparam
 ) *testkeygen.TestKeyGenerator {
@@ -0,0 +1,197 @@
+package epochkghandler
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"time"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+)
+
+// batchCoefficientBits is the size of the random coefficients shares are weighted with in a batch.
+// An invalid share passes the batch check with a probability of 2^-batchCoefficientBits.
+const batchCoefficientBits = 128
+
+// ShareVerifier verifies decryption key shares in batches. Verifying a share takes a pairing check,
+// which dominates the cost of validating key share messages. The verifier collects the shares
+// arriving within a short window and checks them with a single multi-pairing. If the batch fails,
+// the shares are verified individually to find the invalid ones.
+type ShareVerifier struct {
+	window   time.Duration
+	maxBatch int
+	workers  int
+	requests chan []*shareVerification
+}
+
+// ShareToVerify is a decryption key share together with the eon public key share of the keyper
+// who created it and the epoch it is for.
+type ShareToVerify struct {
+	Share     *shcrypto.EpochSecretKeyShare
+	PublicKey *shcrypto.EonPublicKeyShare
+	EpochID   []byte
+}
+
+type shareVerification struct {
+	share     *shcrypto.EpochSecretKeyShare
+	publicKey *shcrypto.EonPublicKeyShare
+	epochID   []byte
+	result    chan bool
+}
+
+// NewShareVerifier creates a verifier with the given number of workers. Each worker waits up to
+// window for more shares after receiving the first shares of a batch.
+func NewShareVerifier(window time.Duration, maxBatch, workers int) *ShareVerifier {
+	return &ShareVerifier{
+		window:   window,
+		maxBatch: maxBatch,
+		workers:  workers,
+		requests: make(chan []*shareVerification),
+	}
+}
+
+// Verify checks that share is the epoch secret key share for the epoch of the keyper with the
+// given eon public key share. It blocks until the share has been verified, which requires Run to
+// be running.
+func (v *ShareVerifier) Verify(
+	ctx context.Context,
+	share *shcrypto.EpochSecretKeyShare,
+	publicKey *shcrypto.EonPublicKeyShare,
+	epochID []byte,
+) (bool, error) {
+	return v.VerifyAll(ctx, []ShareToVerify{{Share: share, PublicKey: publicKey, EpochID: epochID}})
+}
+
+// VerifyAll checks that all given shares are valid. The shares are submitted together, so they
+// end up in the same batch and the caller waits for the batch window only once.
+func (v *ShareVerifier) VerifyAll(ctx context.Context, shares []ShareToVerify) (bool, error) {
+	if len(shares) == 0 {
+		return true, nil
+	}
+	reqs := make([]*shareVerification, len(shares))
+	for i, s := range shares {
+		reqs[i] = &shareVerification{
+			share:     s.Share,
+			publicKey: s.PublicKey,
+			epochID:   s.EpochID,
+			result:    make(chan bool, 1),
+		}
+	}
+	select {
+	case v.requests <- reqs:
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+	valid := true
+	for _, req := range reqs {
+		select {
+		case ok := <-req.result:
+			valid = valid && ok
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+	return valid, nil
+}
+
+// Run verifies shares until the context is canceled.
+func (v *ShareVerifier) Run(ctx context.Context) error {
+	group, ctx := errgroup.WithContext(ctx)
+	for i := 0; i < v.workers; i++ {
+		group.Go(func() error {
+			for {
+				batch, err := v.collectBatch(ctx)
+				if err != nil {
+					return err
+				}
+				verifyBatch(batch)
+			}
+		})
+	}
+	return group.Wait()
+}
+
+// collectBatch waits for the first shares, then collects more shares until the window has passed
+// or the batch is full. Shares submitted together are never split, so a batch may exceed maxBatch.
+func (v *ShareVerifier) collectBatch(ctx context.Context) ([]*shareVerification, error) {
+	var batch []*shareVerification
+	select {
+	case reqs := <-v.requests:
+		batch = append(batch, reqs...)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	timer := time.NewTimer(v.window)
+	defer timer.Stop()
+	for len(batch) < v.maxBatch {
+		select {
+		case reqs := <-v.requests:
+			batch = append(batch, reqs...)
+		case <-timer.C:
+			return batch, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return batch, nil
+}
+
+// verifyBatch reports the validity of each share of the batch.
+func verifyBatch(batch []*shareVerification) {
+	if len(batch) > 1 {
+		ok, err := batchVerifyShares(batch)
+		if err == nil && ok {
+			for _, req := range batch {
+				req.result <- true
+			}
+			return
+		}
+	}
+	for _, req := range batch {
+		req.result <- shcrypto.VerifyEpochSecretKeyShare(req.share, req.publicKey, shcrypto.ComputeEpochID(req.epochID))
+	}
+}
+
+// batchVerifyShares checks all shares at once. A share s_i of keyper i for the epoch point h_i is
+// valid if e(s_i, g2) = e(h_i, pk_i). With random coefficients r_i, all shares are valid (with
+// overwhelming probability) if
+//
+//	e(sum_i r_i s_i, g2) * prod_epochs e(-h, sum_{i in epoch} r_i pk_i) = 1
+//
+// which takes a single multi-pairing with one pairing per epoch in the batch.
+func batchVerifyShares(batch []*shareVerification) (bool, error) {
+	shareSum := new(bn256.G1).ScalarBaseMult(big.NewInt(0))
+	epochs := []string{}
+	epochPoints := map[string]*bn256.G1{}
+	publicKeySums := map[string]*bn256.G2{}
+	bound := new(big.Int).Lsh(big.NewInt(1), batchCoefficientBits)
+	for _, req := range batch {
+		r, err := rand.Int(rand.Reader, bound)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to sample batch coefficient")
+		}
+		shareSum = new(bn256.G1).Add(shareSum, new(bn256.G1).ScalarMult((*bn256.G1)(req.share), r))
+
+		epoch := string(req.epochID)
+		weightedKey := new(bn256.G2).ScalarMult((*bn256.G2)(req.publicKey), r)
+		if sum, ok := publicKeySums[epoch]; ok {
+			publicKeySums[epoch] = new(bn256.G2).Add(sum, weightedKey)
+			continue
+		}
+		epochs = append(epochs, epoch)
+		epochPoints[epoch] = new(bn256.G1).Neg((*bn256.G1)(shcrypto.ComputeEpochID(req.epochID)))
+		publicKeySums[epoch] = weightedKey
+	}
+
+	g1s := []*bn256.G1{shareSum}
+	g2s := []*bn256.G2{new(bn256.G2).ScalarBaseMult(big.NewInt(1))}
+	for _, epoch := range epochs {
+		g1s = append(g1s, epochPoints[epoch])
+		g2s = append(g2s, publicKeySums[epoch])
+	}
+	return bn256.PairingCheck(g1s, g2s), nil
+}
@@ -0,0 +1,103 @@
+package epochkghandler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testkeygen"
+)
+
+func TestShareVerifier(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	numKeypers := uint64(4)
+	tkg := testkeygen.NewTestKeyGenerator(t, numKeypers, 2)
+	verifier := NewShareVerifier(50*time.Millisecond, 64, 1)
+	go func() { _ = verifier.Run(ctx) }()
+
+	// two epochs, with the share of keyper 3 for the second epoch replaced by the share of keyper 2
+	epochs := []epochid.EpochID{epochid.Uint64ToEpochID(1), epochid.Uint64ToEpochID(2)}
+	type result struct {
+		epoch, keyper int
+		ok            bool
+	}
+	results := make(chan result, len(epochs)*int(numKeypers))
+	wg := sync.WaitGroup{}
+	for e, epochID := range epochs {
+		for k := uint64(0); k < numKeypers; k++ {
+			share := tkg.EpochSecretKeyShare(epochID, k)
+			if e == 1 && k == 3 {
+				share = tkg.EpochSecretKeyShare(epochID, 2)
+			}
+			wg.Add(1)
+			go func(e int, k uint64, epochID epochid.EpochID) {
+				defer wg.Done()
+				ok, err := verifier.Verify(ctx, share, tkg.EonPublicKeyShare(epochID, k), epochID.Bytes())
+				assert.Check(t, err)
+				results <- result{epoch: e, keyper: int(k), ok: ok}
+			}(e, k, epochID)
+		}
+	}
+	wg.Wait()
+	close(results)
+	for r := range results {
+		assert.Check(t, r.ok == !(r.epoch == 1 && r.keyper == 3), "epoch %d keyper %d", r.epoch, r.keyper)
+	}
+}
+
+func TestBatchVerifyShares(t *testing.T) {
+	tkg := testkeygen.NewTestKeyGenerator(t, 3, 2)
+	epochID := epochid.Uint64ToEpochID(7)
+	batch := []*shareVerification{}
+	for k := uint64(0); k < 3; k++ {
+		batch = append(batch, &shareVerification{
+			share:     tkg.EpochSecretKeyShare(epochID, k),
+			publicKey: tkg.EonPublicKeyShare(epochID, k),
+			epochID:   epochID.Bytes(),
+		})
+	}
+	ok, err := batchVerifyShares(batch)
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+
+	batch[0].share, batch[1].share = batch[1].share, batch[0].share
+	ok, err = batchVerifyShares(batch)
+	assert.NilError(t, err)
+	assert.Assert(t, !ok)
+}
+
+func TestShareVerifierVerifyAll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	numKeypers := uint64(3)
+	tkg := testkeygen.NewTestKeyGenerator(t, numKeypers, 2)
+	verifier := NewShareVerifier(50*time.Millisecond, 64, 1)
+	go func() { _ = verifier.Run(ctx) }()
+
+	shares := []ShareToVerify{}
+	for e := uint64(1); e <= 2; e++ {
+		epochID := epochid.Uint64ToEpochID(e)
+		for k := uint64(0); k < numKeypers; k++ {
+			shares = append(shares, ShareToVerify{
+				Share:     tkg.EpochSecretKeyShare(epochID, k),
+				PublicKey: tkg.EonPublicKeyShare(epochID, k),
+				EpochID:   epochID.Bytes(),
+			})
+		}
+	}
+	ok, err := verifier.VerifyAll(ctx, shares)
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+
+	shares[4].Share = shares[3].Share
+	ok, err = verifier.VerifyAll(ctx, shares)
+	assert.NilError(t, err)
+	assert.Assert(t, !ok)
+}
@@ -0,0 +1,46 @@
+package epochkghandler
+
+import (
+	"context"
+	"crypto/ecdsa"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// Signing configures how eon public keys, which may eventually be submitted on-chain, are signed
+// and verified.
+type Signing struct {
+	Domain p2pmsg.SigningDomain
+	// InDomain reports whether eon public keys are signed in the domain. As long as it's false,
+	// keys are signed with the legacy hash and both kinds of signatures are accepted, so that a
+	// keyper set can switch without having to upgrade all keypers at once. It may be nil.
+	InDomain func() bool
+}
+
+func (s Signing) inDomain() bool {
+	return s.InDomain != nil && s.InDomain()
+}
+
+// Sign signs the eon public key. Keys signed in the domain get the next signing nonce of the
+// signer allocated in db.
+func (s Signing) Sign(ctx context.Context, db *kprdb.Queries, key *p2pmsg.EonPublicKey, privKey *ecdsa.PrivateKey) error {
+	if !s.inDomain() {
+		return p2pmsg.Sign(key, privKey)
+	}
+	if err := db.AllocateNonce(ctx, s.Domain, ethcrypto.PubkeyToAddress(privKey.PublicKey), key); err != nil {
+		return err
+	}
+	return p2pmsg.SignInDomain(key, s.Domain, privKey)
+}
+
+// RecoverSigner recovers the signer of the eon public key, which has to be a member of keypers.
+func (s Signing) RecoverSigner(key *p2pmsg.EonPublicKey, keypers []string) (common.Address, error) {
+	return p2pmsg.RecoverSigner(key, s.Domain, !s.inDomain(), func(address common.Address) bool {
+		_, ok := kprdb.GetKeyperIndex(address, keypers)
+		return ok
+	})
+}
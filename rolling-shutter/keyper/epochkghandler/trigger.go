@@ -4,6 +4,7 @@ import (
 	"context"
 	"math"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
@@ -11,19 +12,74 @@ import (
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/sla"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/triggerpolicy"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/clockcheck"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/triggeroffset"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
 
-func NewDecryptionTriggerHandler(config Config, dbpool *pgxpool.Pool) p2p.MessageHandler {
-	return &DecryptionTriggerHandler{config: config, dbpool: dbpool}
+// ruleClockSkew is the rule label of triggers rejected because of the local clock's offset.
+const ruleClockSkew = "clock-skew"
+
+func NewDecryptionTriggerHandler(
+	config Config,
+	dbpool *pgxpool.Pool,
+	fence kprdb.Fence,
+	epochIDs *EpochIDValidator,
+	selfAudit *SelfAudit,
+	policy *triggerpolicy.Policy,
+	delay *PublicationDelay,
+	clock *clockcheck.Monitor,
+	tracker *sla.Tracker,
+	offset triggeroffset.Offset,
+) p2p.MessageHandler {
+	return newDecryptionTriggerHandler(
+		config, dbpool, fence, epochIDs, selfAudit, policy, delay, clock, tracker, offset,
+	)
+}
+
+func newDecryptionTriggerHandler(
+	config Config,
+	dbpool *pgxpool.Pool,
+	fence kprdb.Fence,
+	epochIDs *EpochIDValidator,
+	selfAudit *SelfAudit,
+	policy *triggerpolicy.Policy,
+	delay *PublicationDelay,
+	clock *clockcheck.Monitor,
+	tracker *sla.Tracker,
+	offset triggeroffset.Offset,
+) *DecryptionTriggerHandler {
+	return &DecryptionTriggerHandler{
+		config:    config,
+		dbpool:    dbpool,
+		fence:     fence,
+		epochIDs:  epochIDs,
+		selfAudit: selfAudit,
+		policy:    policy,
+		delay:     delay,
+		clock:     clock,
+		sla:       tracker,
+		offset:    offset,
+	}
 }
 
 type DecryptionTriggerHandler struct {
-	config Config
-	dbpool *pgxpool.Pool
+	config    Config
+	dbpool    *pgxpool.Pool
+	fence     kprdb.Fence
+	epochIDs  *EpochIDValidator
+	selfAudit *SelfAudit
+	policy    *triggerpolicy.Policy
+	delay     *PublicationDelay
+	clock     *clockcheck.Monitor
+	sla       *sla.Tracker
+	offset    triggeroffset.Offset
 }
 
 func (*DecryptionTriggerHandler) MessagePrototypes() []p2pmsg.Message {
@@ -35,47 +91,227 @@ func (handler *DecryptionTriggerHandler) ValidateMessage(ctx context.Context, ms
 	if trigger.GetInstanceID() != handler.config.GetInstanceID() {
 		return false, errors.Errorf("instance ID mismatch (want=%d, have=%d)", handler.config.GetInstanceID(), trigger.GetInstanceID())
 	}
-	if _, err := epochid.BytesToEpochID(trigger.EpochID); err != nil {
-		return false, errors.Wrapf(err, "invalid epoch id")
+	err := verifyCollatorSignature(ctx, handler.dbpool, trigger, trigger.BlockNumber)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid decryption trigger for epoch: %x", trigger.EpochID)
+	}
+	if err := handler.validateTrigger(ctx, trigger); err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
-	blk := trigger.BlockNumber
-	if blk > math.MaxInt64 {
-		return false, errors.Errorf("block number %d overflows int64", blk)
+// validateTrigger checks the epoch of a trigger and the time it arrived at, but not who signed
+// it.
+func (handler *DecryptionTriggerHandler) validateTrigger(ctx context.Context, trigger *p2pmsg.DecryptionTrigger) error {
+	epochID, err := epochid.BytesToEpochID(trigger.EpochID)
+	if err != nil {
+		return errors.Wrapf(err, "invalid epoch id")
 	}
-	chainCollator, err := chainobsdb.New(handler.dbpool).GetChainCollator(ctx, int64(blk))
-	if err == pgx.ErrNoRows {
-		return false, errors.Errorf("got decryption trigger with no collator for given block number: %d", blk)
+	if err := handler.epochIDs.Validate(ctx, trigger.BlockNumber, epochID); err != nil {
+		return err
 	}
+	return validateTriggerOffset(
+		ctx, kprdb.New(handler.dbpool), handler.offset, handler.clock, trigger.BlockNumber, epochID,
+	)
+}
+
+// verifyCollatorSignature checks that the message is signed by the collator of each of the given
+// blocks.
+func verifyCollatorSignature(
+	ctx context.Context, dbpool *pgxpool.Pool, msg p2pmsg.Signable, blockNumbers ...uint64,
+) error {
+	signer, err := p2pmsg.RecoverAddress(msg)
 	if err != nil {
-		return false, errors.Wrapf(err, "error while getting collator from db for block number: %d", blk)
+		return errors.Wrap(err, "error while recovering signer")
+	}
+	db := chainobsdb.New(dbpool)
+	for _, blk := range blockNumbers {
+		if blk > math.MaxInt64 {
+			return errors.Errorf("block number %d overflows int64", blk)
+		}
+		chainCollator, err := db.GetChainCollator(ctx, int64(blk))
+		if err == pgx.ErrNoRows {
+			return errors.Errorf("no collator for given block number: %d", blk)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "error while getting collator from db for block number: %d", blk)
+		}
+		collator, err := shdb.DecodeAddress(chainCollator.Collator)
+		if err != nil {
+			return errors.Wrapf(err, "error while converting collator from string to address: %s", chainCollator.Collator)
+		}
+		if signer != collator {
+			return errors.Errorf("signature invalid for block number %d", blk)
+		}
 	}
+	return nil
+}
 
-	collator, err := shdb.DecodeAddress(chainCollator.Collator)
+func (handler *DecryptionTriggerHandler) HandleMessage(ctx context.Context, m p2pmsg.Message) ([]p2pmsg.Message, error) {
+	msg, ok := m.(*p2pmsg.DecryptionTrigger)
+	if !ok {
+		return nil, errors.New("Message type assertion mismatch")
+	}
+	log.Info().Str("message", msg.LogInfo()).Msg("received decryption trigger")
+	source, err := p2pmsg.RecoverAddress(msg)
 	if err != nil {
-		return false, errors.Wrapf(err, "error while converting collator from string to address: %s", chainCollator.Collator)
+		return nil, errors.Wrap(err, "error while recovering signer")
 	}
+	return handler.handleTrigger(ctx, source, msg)
+}
 
-	signatureValid, err := p2pmsg.VerifySignature(trigger, collator)
+// handleTrigger applies the trigger policy and publication delay to a trigger signed by source
+// and sends our decryption key share if they allow it.
+func (handler *DecryptionTriggerHandler) handleTrigger(
+	ctx context.Context, source common.Address, trigger *p2pmsg.DecryptionTrigger,
+) ([]p2pmsg.Message, error) {
+	metricsEpochKGDectyptionTriggersReceived.Inc()
+	epochID, err := epochid.BytesToEpochID(trigger.EpochID)
 	if err != nil {
-		return false, errors.Wrapf(err, "error while verifying decryption trigger signature for epoch: %x", trigger.EpochID)
+		return nil, err
 	}
-	if !signatureValid {
-		return false, errors.Errorf("decryption trigger signature invalid for epoch: %x", trigger.EpochID)
+	if !allowedByPolicy(handler.policy, handler.delay, handler.clock, source, epochID) {
+		return nil, nil
 	}
-	return signatureValid, nil
+	handler.sla.Triggered(ctx, epochID)
+	if held, err := handler.delay.Hold(ctx, trigger.BlockNumber, epochID); err != nil || held {
+		return nil, err
+	}
+	return handleTrigger(
+		ctx, handler.config, kprdb.New(handler.dbpool), handler.fence, handler.selfAudit,
+		int64(trigger.BlockNumber), epochID,
+	)
 }
 
-func (handler *DecryptionTriggerHandler) HandleMessage(ctx context.Context, m p2pmsg.Message) ([]p2pmsg.Message, error) {
-	msg, ok := m.(*p2pmsg.DecryptionTrigger)
+// allowedByPolicy evaluates the trigger policy, logging and counting triggers it rejects. Since
+// the policy is local to this keyper, rejected triggers are still valid messages and are relayed
+// to our peers. If the local clock is off by too much, triggers are rejected as long as acting on
+// them depends on the time.
+func allowedByPolicy(
+	policy *triggerpolicy.Policy,
+	delay *PublicationDelay,
+	clock *clockcheck.Monitor,
+	source common.Address,
+	epochID epochid.EpochID,
+) bool {
+	if clock.RefuseTimeBased() && (delay != nil || policy.UsesTime()) {
+		metricsEpochKGTriggersRejected.WithLabelValues(ruleClockSkew).Inc()
+		log.Warn().Dur("clock-offset", clock.Offset()).Str("epoch-id", epochID.Hex()).
+			Msg("ignoring decryption trigger: local clock is off by more than the maximum skew")
+		return false
+	}
+	err := policy.Evaluate(triggerpolicy.Trigger{Source: source, EpochID: epochID, Time: clock.Now()})
+	if err == nil {
+		return true
+	}
+	rule := "unknown"
+	var violation *triggerpolicy.Violation
+	if errors.As(err, &violation) {
+		rule = violation.Rule
+	}
+	metricsEpochKGTriggersRejected.WithLabelValues(rule).Inc()
+	log.Warn().Err(err).Str("epoch-id", epochID.Hex()).Msg("ignoring decryption trigger")
+	return false
+}
+
+// handleTrigger sends our decryption key share for a trigger, ignoring triggers for eons we are
+// not a keyper of and triggers received while key generation is paused.
+func handleTrigger(
+	ctx context.Context,
+	config Config,
+	db *kprdb.Queries,
+	fence kprdb.Fence,
+	selfAudit *SelfAudit,
+	blockNumber int64,
+	epochID epochid.EpochID,
+) ([]p2pmsg.Message, error) {
+	observeTriggerDrift(ctx, db, blockNumber, epochID)
+	msgs, err := SendDecryptionKeyShare(ctx, config, db, fence, selfAudit, blockNumber, epochID)
+	if errors.Is(err, errcode.ErrNotInKeyperSet) {
+		log.Info().Str("error-code", string(errcode.ErrNotInKeyperSet.Code)).
+			Msg("ignoring decryption trigger: we are not a keyper")
+		return nil, nil
+	}
+	if errors.Is(err, errcode.ErrKeyGenerationPaused) {
+		log.Warn().Err(err).Str("epoch-id", epochID.Hex()).Msg("ignoring decryption trigger")
+		return nil, nil
+	}
+	return msgs, err
+}
+
+func NewDecryptionTriggerBatchHandler(
+	config Config,
+	dbpool *pgxpool.Pool,
+	fence kprdb.Fence,
+	epochIDs *EpochIDValidator,
+	selfAudit *SelfAudit,
+	policy *triggerpolicy.Policy,
+	delay *PublicationDelay,
+	clock *clockcheck.Monitor,
+	tracker *sla.Tracker,
+	offset triggeroffset.Offset,
+) p2p.MessageHandler {
+	return &DecryptionTriggerBatchHandler{
+		triggers: newDecryptionTriggerHandler(
+			config, dbpool, fence, epochIDs, selfAudit, policy, delay, clock, tracker, offset,
+		),
+	}
+}
+
+// DecryptionTriggerBatchHandler handles batches of decryption triggers by passing the individual
+// triggers they contain to a DecryptionTriggerHandler.
+type DecryptionTriggerBatchHandler struct {
+	triggers *DecryptionTriggerHandler
+}
+
+func (*DecryptionTriggerBatchHandler) MessagePrototypes() []p2pmsg.Message {
+	return []p2pmsg.Message{&p2pmsg.DecryptionTriggerBatch{}}
+}
+
+func (handler *DecryptionTriggerBatchHandler) ValidateMessage(ctx context.Context, msg p2pmsg.Message) (bool, error) {
+	batch := msg.(*p2pmsg.DecryptionTriggerBatch)
+	instanceID := handler.triggers.config.GetInstanceID()
+	if batch.GetInstanceID() != instanceID {
+		return false, errors.Errorf("instance ID mismatch (want=%d, have=%d)", instanceID, batch.GetInstanceID())
+	}
+	triggers, err := batch.Triggers()
+	if err != nil {
+		return false, errors.Wrap(err, "invalid decryption trigger batch")
+	}
+	err = verifyCollatorSignature(ctx, handler.triggers.dbpool, batch, batch.BlockNumbers...)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid decryption trigger batch starting at epoch: %x", batch.FirstEpochID)
+	}
+	for _, trigger := range triggers {
+		if err := handler.triggers.validateTrigger(ctx, trigger); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (handler *DecryptionTriggerBatchHandler) HandleMessage(ctx context.Context, m p2pmsg.Message) ([]p2pmsg.Message, error) {
+	batch, ok := m.(*p2pmsg.DecryptionTriggerBatch)
 	if !ok {
 		return nil, errors.New("Message type assertion mismatch")
 	}
-	metricsEpochKGDectyptionTriggersReceived.Inc()
-	log.Info().Str("message", msg.LogInfo()).Msg("received decryption trigger")
-	epochID, err := epochid.BytesToEpochID(msg.EpochID)
+	log.Info().Str("message", batch.LogInfo()).Msg("received decryption trigger batch")
+	triggers, err := batch.Triggers()
 	if err != nil {
 		return nil, err
 	}
-	return SendDecryptionKeyShare(ctx, handler.config, kprdb.New(handler.dbpool), int64(msg.BlockNumber), epochID)
+	source, err := p2pmsg.RecoverAddress(batch)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while recovering signer")
+	}
+	var msgs []p2pmsg.Message
+	for _, trigger := range triggers {
+		out, err := handler.triggers.handleTrigger(ctx, source, trigger)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, out...)
+	}
+	return msgs, nil
 }
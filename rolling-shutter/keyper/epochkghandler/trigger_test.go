@@ -12,6 +12,7 @@ import (
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/triggerpolicy"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
@@ -71,6 +72,50 @@ func TestHandleDecryptionTriggerIntegration(t *testing.T) {
 	assert.Check(t, len(msgs) == 0)
 }
 
+func TestDecryptionTriggerPolicyIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	initializeEon(ctx, t, dbpool, 1)
+	policyConfig := triggerpolicy.NewConfig()
+	policyConfig.AllowedSources = []string{ethcrypto.PubkeyToAddress(config.GetCollatorKey().PublicKey).Hex()}
+	policyConfig.AllowedEpochIDPrefixes = []string{"0x00"}
+	policy, err := triggerpolicy.NewPolicy(policyConfig)
+	assert.NilError(t, err)
+	var handler p2p.MessageHandler = &DecryptionTriggerHandler{config: config, dbpool: dbpool, policy: policy}
+
+	otherKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	deniedEpochID, err := epochid.BytesToEpochID(common.RightPadBytes([]byte{0xff}, 32))
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name    string
+		epochID epochid.EpochID
+		privKey *ecdsa.PrivateKey
+		shares  int
+	}{
+		{name: "other source", epochID: epochid.Uint64ToEpochID(50), privKey: otherKey, shares: 0},
+		{name: "denied epoch id", epochID: deniedEpochID, privKey: config.GetCollatorKey(), shares: 0},
+		{name: "allowed", epochID: epochid.Uint64ToEpochID(50), privKey: config.GetCollatorKey(), shares: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			trigger, err := p2pmsg.NewSignedDecryptionTrigger(
+				config.GetInstanceID(), tc.epochID, 0, make([]byte, 32), tc.privKey,
+			)
+			assert.NilError(t, err)
+			msgs := p2ptest.MustHandleMessage(t, handler, ctx, trigger)
+			assert.Equal(t, len(msgs), tc.shares)
+		})
+	}
+}
+
 func TestTriggerValidatorIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -163,7 +208,7 @@ func TestTriggerValidatorIntegration(t *testing.T) {
 				tc.instanceID,
 				tc.epochID,
 				tc.blockNumber,
-				[]byte{},
+				make([]byte, 32),
 				tc.privKey,
 			)
 			assert.NilError(t, err)
@@ -171,3 +216,37 @@ func TestTriggerValidatorIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestDecryptionTriggerPausedIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	db, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	initializeEon(ctx, t, dbpool, 1)
+	var handler p2p.MessageHandler = &DecryptionTriggerHandler{config: config, dbpool: dbpool}
+	newTrigger := func(epoch uint64) *p2pmsg.DecryptionTrigger {
+		trigger, err := p2pmsg.NewSignedDecryptionTrigger(
+			config.GetInstanceID(), epochid.Uint64ToEpochID(epoch), 0, make([]byte, 32), config.GetCollatorKey(),
+		)
+		assert.NilError(t, err)
+		return trigger
+	}
+
+	_, err := db.SetKeyGenerationPause(ctx, kprdb.SetKeyGenerationPauseParams{
+		Paused: true, Sequence: 1, KeyperConfigIndex: 1, Reason: "test",
+	})
+	assert.NilError(t, err)
+	msgs := p2ptest.MustHandleMessage(t, handler, ctx, newTrigger(50))
+	assert.Equal(t, len(msgs), 0)
+
+	_, err = db.SetKeyGenerationPause(ctx, kprdb.SetKeyGenerationPauseParams{
+		Paused: false, Sequence: 2, KeyperConfigIndex: 1, Reason: "fixed",
+	})
+	assert.NilError(t, err)
+	msgs = p2ptest.MustHandleMessage(t, handler, ctx, newTrigger(51))
+	assert.Equal(t, len(msgs), 1)
+}
@@ -0,0 +1,91 @@
+package escrow
+
+import (
+	"io"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+type Config struct {
+	Directory    string   `comment:"Directory the encrypted backups of the eon key shares are written to, e.g. a mounted backup volume. If it's empty, no backups are written"`
+	Threshold    uint64   `comment:"Number of recovery key holders needed to restore a backup"`
+	RecoveryKeys []string `comment:"Uncompressed secp256k1 public keys (hex encoded) of the recovery key holders"`
+}
+
+func (c *Config) Init() {}
+
+func (c *Config) Name() string {
+	return "escrow"
+}
+
+// Enabled reports whether backups are written.
+func (c *Config) Enabled() bool {
+	return c.Directory != ""
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	keys, err := c.PublicKeys()
+	if err != nil {
+		return err
+	}
+	if c.Threshold == 0 {
+		return errors.New("escrow threshold must be positive")
+	}
+	if c.Threshold > uint64(len(keys)) {
+		return errors.Errorf("escrow threshold %d exceeds the number of recovery keys %d", c.Threshold, len(keys))
+	}
+	return nil
+}
+
+// PublicKeys parses the recovery keys.
+func (c *Config) PublicKeys() ([]*ecies.PublicKey, error) {
+	keys := []*ecies.PublicKey{}
+	seen := make(map[string]bool)
+	for _, s := range c.RecoveryKeys {
+		b, err := hexutil.Decode(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid recovery key %q", s)
+		}
+		key, err := ethcrypto.UnmarshalPubkey(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid recovery key %q", s)
+		}
+		if seen[string(b)] {
+			return nil, errors.Errorf("recovery key %s is listed multiple times", s)
+		}
+		seen[string(b)] = true
+		keys = append(keys, ecies.ImportECDSAPublic(key))
+	}
+	return keys, nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.Directory = ""
+	c.Threshold = 0
+	c.RecoveryKeys = []string{}
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
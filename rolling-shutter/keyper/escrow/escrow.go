@@ -0,0 +1,200 @@
+// Package escrow writes backups of the eon key shares of a keyper, encrypted to a set of recovery
+// keys held by the operators. A backup can be restored by any threshold of recovery key holders
+// together, but not by fewer of them.
+//
+// Every backup is encrypted with a fresh random key. The key is split into shares with Shamir's
+// secret sharing and every share is encrypted to one of the recovery keys with ECIES. To restore a
+// backup, the holders decrypt their shares independently (DecryptShare) and the shares are
+// combined to decrypt the backup (Recover).
+package escrow
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/pkg/errors"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+)
+
+// blobVersion is the version of the backup format.
+const blobVersion = 1
+
+// ErrNotRecipient is returned when decrypting a share with a key the backup is not encrypted to.
+var ErrNotRecipient = errors.New("backup is not encrypted to this recovery key")
+
+// Blob is an encrypted backup of the DKG result of an eon.
+type Blob struct {
+	Version    int              `json:"version"`
+	InstanceID uint64           `json:"instanceID"`
+	Keyper     common.Address   `json:"keyper"`
+	Eon        uint64           `json:"eon"`
+	Threshold  uint64           `json:"threshold"`
+	Shares     []EncryptedShare `json:"shares"`
+	Nonce      hexutil.Bytes    `json:"nonce"`
+	Ciphertext hexutil.Bytes    `json:"ciphertext"`
+}
+
+// EncryptedShare is a share of the key of a backup, encrypted to a recovery key.
+type EncryptedShare struct {
+	Index       int           `json:"index"`
+	RecoveryKey hexutil.Bytes `json:"recoveryKey"`
+	Ciphertext  hexutil.Bytes `json:"ciphertext"`
+}
+
+// Share is a decrypted share of the key of a backup.
+type Share struct {
+	Index int          `json:"index"`
+	Value *hexutil.Big `json:"value"`
+}
+
+// additionalData binds the ciphertext to the metadata of the blob.
+func (b *Blob) additionalData() []byte {
+	return []byte(fmt.Sprintf("shutter key escrow v%d:%d:%s:%d", b.Version, b.InstanceID, b.Keyper.Hex(), b.Eon))
+}
+
+func newGCM(secret *big.Int) (cipher.AEAD, error) {
+	key := sha256.Sum256(secret.FillBytes(make([]byte, 32)))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Seal encrypts payload to the recovery keys, so that any threshold of them can decrypt it.
+func Seal(
+	r io.Reader, payload []byte, instanceID uint64, keyper common.Address, eon uint64,
+	threshold uint64, recoveryKeys []*ecies.PublicKey,
+) (*Blob, error) {
+	if threshold == 0 || threshold > uint64(len(recoveryKeys)) {
+		return nil, errors.Errorf("invalid threshold %d for %d recovery keys", threshold, len(recoveryKeys))
+	}
+	poly, err := shcrypto.RandomPolynomial(r, shcrypto.DegreeFromThreshold(threshold))
+	if err != nil {
+		return nil, err
+	}
+	blob := &Blob{
+		Version:    blobVersion,
+		InstanceID: instanceID,
+		Keyper:     keyper,
+		Eon:        eon,
+		Threshold:  threshold,
+	}
+	for i, key := range recoveryKeys {
+		value := poly.EvalForKeyper(i).FillBytes(make([]byte, 32))
+		encrypted, err := ecies.Encrypt(r, key, value, nil, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encrypt share")
+		}
+		blob.Shares = append(blob.Shares, EncryptedShare{
+			Index:       i,
+			RecoveryKey: ethcrypto.FromECDSAPub(key.ExportECDSA()),
+			Ciphertext:  encrypted,
+		})
+	}
+
+	gcm, err := newGCM(poly.Eval(big.NewInt(0)))
+	if err != nil {
+		return nil, err
+	}
+	blob.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(r, blob.Nonce); err != nil {
+		return nil, err
+	}
+	blob.Ciphertext = gcm.Seal(nil, blob.Nonce, payload, blob.additionalData())
+	return blob, nil
+}
+
+// DecryptShare decrypts the share of the backup encrypted to the given recovery key.
+func (b *Blob) DecryptShare(key *ecies.PrivateKey) (Share, error) {
+	public := ethcrypto.FromECDSAPub(&key.ExportECDSA().PublicKey)
+	for _, encrypted := range b.Shares {
+		if string(encrypted.RecoveryKey) != string(public) {
+			continue
+		}
+		value, err := key.Decrypt(encrypted.Ciphertext, nil, nil)
+		if err != nil {
+			return Share{}, errors.Wrap(err, "failed to decrypt share")
+		}
+		v := new(big.Int).SetBytes(value)
+		if !shcrypto.ValidEval(v) {
+			return Share{}, errors.New("decrypted share is out of range")
+		}
+		return Share{Index: encrypted.Index, Value: (*hexutil.Big)(v)}, nil
+	}
+	return Share{}, ErrNotRecipient
+}
+
+// Recover combines the decrypted shares of at least threshold recovery key holders and decrypts
+// the backup.
+func (b *Blob) Recover(shares []Share) ([]byte, error) {
+	byIndex := make(map[int]*big.Int)
+	for _, share := range shares {
+		if share.Value == nil || share.Index < 0 || share.Index >= len(b.Shares) {
+			return nil, errors.Errorf("invalid share with index %d", share.Index)
+		}
+		byIndex[share.Index] = share.Value.ToInt()
+	}
+	if uint64(len(byIndex)) < b.Threshold {
+		return nil, errors.Errorf("got %d distinct shares, but %d are required", len(byIndex), b.Threshold)
+	}
+
+	gcm, err := newGCM(interpolateAtZero(byIndex))
+	if err != nil {
+		return nil, err
+	}
+	if len(b.Nonce) != gcm.NonceSize() {
+		return nil, errors.New("invalid nonce")
+	}
+	payload, err := gcm.Open(nil, b.Nonce, b.Ciphertext, b.additionalData())
+	if err != nil {
+		return nil, errors.New("failed to decrypt backup, the shares don't belong to it")
+	}
+	return payload, nil
+}
+
+// interpolateAtZero computes the value at 0 of the polynomial going through the given points,
+// where the point of index i is at x = i+1.
+func interpolateAtZero(points map[int]*big.Int) *big.Int {
+	result := big.NewInt(0)
+	for i, y := range points {
+		xi := shcrypto.KeyperX(i)
+		numerator := big.NewInt(1)
+		denominator := big.NewInt(1)
+		for j := range points {
+			if j == i {
+				continue
+			}
+			xj := shcrypto.KeyperX(j)
+			numerator.Mul(numerator, xj)
+			numerator.Mod(numerator, bn256.Order)
+			denominator.Mul(denominator, new(big.Int).Sub(xj, xi))
+			denominator.Mod(denominator, bn256.Order)
+		}
+		term := new(big.Int).Mul(y, numerator)
+		term.Mul(term, new(big.Int).ModInverse(denominator, bn256.Order))
+		result.Add(result, term)
+		result.Mod(result, bn256.Order)
+	}
+	return result
+}
+
+// Fingerprint identifies the recovery keys and the threshold. Backups are written again if it
+// changes.
+func Fingerprint(threshold uint64, recoveryKeys []*ecies.PublicKey) []byte {
+	data := [][]byte{binary.BigEndian.AppendUint64(nil, threshold)}
+	for _, key := range recoveryKeys {
+		data = append(data, ethcrypto.FromECDSAPub(key.ExportECDSA()))
+	}
+	return ethcrypto.Keccak256(data...)
+}
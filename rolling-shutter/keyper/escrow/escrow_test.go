@@ -0,0 +1,100 @@
+package escrow
+
+import (
+	"crypto/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+)
+
+func newRecoveryKeys(t *testing.T, n int) ([]*ecies.PrivateKey, []*ecies.PublicKey) {
+	t.Helper()
+	var privateKeys []*ecies.PrivateKey
+	var publicKeys []*ecies.PublicKey
+	for i := 0; i < n; i++ {
+		key, err := ethcrypto.GenerateKey()
+		assert.NilError(t, err)
+		privateKeys = append(privateKeys, ecies.ImportECDSA(key))
+		publicKeys = append(publicKeys, &privateKeys[i].PublicKey)
+	}
+	return privateKeys, publicKeys
+}
+
+func TestSealRecover(t *testing.T) {
+	privateKeys, publicKeys := newRecoveryKeys(t, 3)
+	payload := []byte("dkg result")
+	keyper := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	blob, err := Seal(rand.Reader, payload, 42, keyper, 7, 2, publicKeys)
+	assert.NilError(t, err)
+
+	var shares []Share
+	for _, key := range privateKeys {
+		share, err := blob.DecryptShare(key)
+		assert.NilError(t, err)
+		shares = append(shares, share)
+	}
+
+	for _, pair := range [][]Share{{shares[0], shares[1]}, {shares[2], shares[0]}, shares} {
+		recovered, err := blob.Recover(pair)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, recovered, payload)
+	}
+
+	_, err = blob.Recover(shares[:1])
+	assert.ErrorContains(t, err, "required")
+	_, err = blob.Recover([]Share{shares[0], shares[0]})
+	assert.ErrorContains(t, err, "required")
+
+	wrong := Share{Index: shares[1].Index, Value: (*hexutil.Big)(common.Big1)}
+	_, err = blob.Recover([]Share{shares[0], wrong})
+	assert.ErrorContains(t, err, "don't belong")
+
+	// the metadata is bound to the ciphertext
+	blob.Eon = 8
+	_, err = blob.Recover(shares[:2])
+	assert.ErrorContains(t, err, "don't belong")
+
+	outsider, _ := newRecoveryKeys(t, 1)
+	_, err = blob.DecryptShare(outsider[0])
+	assert.Assert(t, errors.Is(err, ErrNotRecipient))
+}
+
+func TestSealInvalidThreshold(t *testing.T) {
+	_, publicKeys := newRecoveryKeys(t, 2)
+	_, err := Seal(rand.Reader, nil, 1, common.Address{}, 0, 3, publicKeys)
+	assert.ErrorContains(t, err, "invalid threshold")
+	_, err = Seal(rand.Reader, nil, 1, common.Address{}, 0, 0, publicKeys)
+	assert.ErrorContains(t, err, "invalid threshold")
+}
+
+func TestWriteReadFile(t *testing.T) {
+	_, publicKeys := newRecoveryKeys(t, 1)
+	blob, err := Seal(rand.Reader, []byte("x"), 1, common.Address{}, 3, 1, publicKeys)
+	assert.NilError(t, err)
+	path := filepath.Join(t.TempDir(), FileName(common.Address{}, 3))
+	assert.NilError(t, writeFile(path, blob))
+	read, err := ReadFile(path)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, read, blob)
+}
+
+func TestConfigValidate(t *testing.T) {
+	_, publicKeys := newRecoveryKeys(t, 2)
+	var keys []string
+	for _, key := range publicKeys {
+		keys = append(keys, hexutil.Encode(ethcrypto.FromECDSAPub(key.ExportECDSA())))
+	}
+	assert.NilError(t, (&Config{}).Validate())
+	assert.NilError(t, (&Config{Directory: "/backup", Threshold: 2, RecoveryKeys: keys}).Validate())
+	assert.ErrorContains(t, (&Config{Directory: "/backup", Threshold: 3, RecoveryKeys: keys}).Validate(), "exceeds")
+	assert.ErrorContains(t, (&Config{Directory: "/backup", Threshold: 0, RecoveryKeys: keys}).Validate(), "positive")
+	assert.ErrorContains(t,
+		(&Config{Directory: "/backup", Threshold: 1, RecoveryKeys: append(keys, keys[0])}).Validate(), "multiple")
+	assert.ErrorContains(t, (&Config{Directory: "/backup", Threshold: 1, RecoveryKeys: []string{"0x12"}}).Validate(), "invalid")
+}
@@ -0,0 +1,137 @@
+package escrow
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+)
+
+// writeInterval is how often the writer checks for eons that haven't been backed up yet.
+const writeInterval = time.Minute
+
+// Writer writes backups of the DKG results of all eons that haven't been backed up with the
+// configured recovery keys yet. Backups are written again if the recovery keys change.
+type Writer struct {
+	config       *Config
+	dbpool       *pgxpool.Pool
+	instanceID   uint64
+	keyper       common.Address
+	recoveryKeys []*ecies.PublicKey
+	fingerprint  []byte
+}
+
+func NewWriter(config *Config, dbpool *pgxpool.Pool, instanceID uint64, keyper common.Address) (*Writer, error) {
+	recoveryKeys, err := config.PublicKeys()
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{
+		config:       config,
+		dbpool:       dbpool,
+		instanceID:   instanceID,
+		keyper:       keyper,
+		recoveryKeys: recoveryKeys,
+		fingerprint:  Fingerprint(config.Threshold, recoveryKeys),
+	}, nil
+}
+
+// FileName returns the name of the backup file of the given keyper and eon.
+func FileName(keyper common.Address, eon uint64) string {
+	return fmt.Sprintf("eon-%d-%s.json", eon, keyper.Hex())
+}
+
+func (w *Writer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(writeInterval)
+	defer ticker.Stop()
+	for {
+		if err := w.writePending(ctx); err != nil {
+			log.Error().Err(err).Msg("failed to write key backups")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Writer) writePending(ctx context.Context) error {
+	db := kprdb.New(w.dbpool)
+	results, err := db.GetUnescrowedDKGResults(ctx, w.fingerprint)
+	if err != nil {
+		return errors.Wrap(err, "failed to query DKG results to back up")
+	}
+	for _, result := range results {
+		blob, err := Seal(
+			rand.Reader, result.PureResult, w.instanceID, w.keyper, uint64(result.Eon),
+			w.config.Threshold, w.recoveryKeys,
+		)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(w.config.Directory, FileName(w.keyper, uint64(result.Eon)))
+		if err := writeFile(path, blob); err != nil {
+			return err
+		}
+		err = db.InsertKeyEscrow(ctx, kprdb.InsertKeyEscrowParams{Eon: result.Eon, Fingerprint: w.fingerprint})
+		if err != nil {
+			return errors.Wrap(err, "failed to record key backup")
+		}
+		log.Info().Int64("eon", result.Eon).Str("path", path).Msg("wrote key backup")
+	}
+	return nil
+}
+
+// writeFile writes the blob to path. It's written to a temporary file first, so that path never
+// holds a partial backup.
+func writeFile(path string, blob *Blob) error {
+	data, err := json.MarshalIndent(blob, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+filepath.Base(path))
+	if err != nil {
+		return errors.Wrap(err, "failed to create key backup")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "failed to write key backup")
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "failed to write key backup")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to write key backup")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), path), "failed to write key backup")
+}
+
+// ReadFile reads a backup written by the Writer.
+func ReadFile(path string) (*Blob, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	blob := &Blob{}
+	if err := json.Unmarshal(data, blob); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode key backup %s", path)
+	}
+	if blob.Version != blobVersion {
+		return nil, errors.Errorf("unsupported key backup version %d", blob.Version)
+	}
+	return blob, nil
+}
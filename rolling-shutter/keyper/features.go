@@ -0,0 +1,35 @@
+package keyper
+
+import "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/featureflag"
+
+// FeatureEventSchemas enables observing the contract events of the schemas stored in the db and in
+// the event schema dir. It is only read when the keyper starts.
+const FeatureEventSchemas = "event-schemas"
+
+// FeatureSigningDomain makes keypers sign eon public keys in the signing domain of the chain and
+// instance and reject legacy signatures. It should only be enabled once all keypers of the set
+// have been upgraded.
+const FeatureSigningDomain = "signing-domain"
+
+// FeatureIdleMode makes keypers that are neither in the active keyper set nor in a keyper set
+// about to start its DKG stop receiving the gossip messages of epoch key generation.
+const FeatureIdleMode = "idle-mode"
+
+// Features are the feature flags of keypers and snapshot keypers.
+var Features = []featureflag.Flag{
+	{
+		Name:        FeatureEventSchemas,
+		Description: "Observe contract events of the configured event schemas (read at startup)",
+		Default:     true,
+	},
+	{
+		Name:        FeatureSigningDomain,
+		Description: "Sign eon public keys in the signing domain and reject legacy signatures",
+		Default:     false,
+	},
+	{
+		Name:        FeatureIdleMode,
+		Description: "Pause epoch key generation gossip while not in the active or upcoming keyper set (keypers only)",
+		Default:     false,
+	},
+}
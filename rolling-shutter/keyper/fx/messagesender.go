@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 	"github.com/tendermint/tendermint/rpc/client"
 	tmtypes "github.com/tendermint/tendermint/types"
 
@@ -173,3 +174,13 @@ func (ms *MockMessageSender) SendMessage(_ context.Context, msg *shmsg.Message)
 	ms.Msgs <- msg
 	return nil
 }
+
+// DiscardingMessageSender drops all messages instead of sending them to shuttermint.
+type DiscardingMessageSender struct{}
+
+var _ MessageSender = DiscardingMessageSender{}
+
+func (DiscardingMessageSender) SendMessage(_ context.Context, msg *shmsg.Message) error {
+	log.Info().Str("payload", fmt.Sprintf("%T", msg.Payload)).Msg("sending to shuttermint disabled, dropping message")
+	return nil
+}
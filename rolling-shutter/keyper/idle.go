@@ -0,0 +1,125 @@
+package keyper
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/chainobserver"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprtopics"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/featureflag"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+)
+
+const idlePollInterval = 30 * time.Second
+
+// idleTopics are the gossip topics an idle keyper stops receiving. Eon public keys are still
+// received, as they are cheap and needed to serve the API.
+var idleTopics = []string{
+	kprtopics.DecryptionTrigger,
+	kprtopics.DecryptionTriggerBatch,
+	kprtopics.EpochPreAnnouncement,
+	kprtopics.DecryptionKeyShares,
+	kprtopics.DecryptionKey,
+}
+
+// IdleMonitor puts the keyper into idle mode while it is not a member of the active keyper set,
+// if the idle mode feature is enabled. Idle keypers keep syncing the chain and shuttermint, but
+// don't receive the gossip messages of epoch key generation, so that standby nodes use less
+// resources. Full operation resumes when a keyper set the node is a member of is about to start
+// its DKG, so that the node takes part in it and is ready at the activation block.
+type IdleMonitor struct {
+	dbpool    *pgxpool.Pool
+	l1Client  *ethclient.Client
+	p2p       *p2p.P2PHandler
+	features  *featureflag.Set
+	address   common.Address
+	lookahead uint64
+	idle      bool
+	// keyperSetChanged wakes up Run before the next poll
+	keyperSetChanged chan struct{}
+}
+
+func NewIdleMonitor(
+	dbpool *pgxpool.Pool,
+	l1Client *ethclient.Client,
+	p2pHandler *p2p.P2PHandler,
+	features *featureflag.Set,
+	address common.Address,
+	dkgStartBlockDelta uint64,
+) *IdleMonitor {
+	return &IdleMonitor{
+		dbpool:    dbpool,
+		l1Client:  l1Client,
+		p2p:       p2pHandler,
+		features:  features,
+		address:   address,
+		lookahead: dkgStartBlockDelta,
+
+		keyperSetChanged: make(chan struct{}, 1),
+	}
+}
+
+// KeyperSetChanged makes the monitor update the mode right away instead of at the next poll.
+func (m *IdleMonitor) KeyperSetChanged() {
+	deliverSignal(m.keyperSetChanged)
+}
+
+// Run periodically, and whenever the keyper set changes, updates the mode of the keyper until the
+// context is canceled.
+func (m *IdleMonitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(idlePollInterval)
+	defer ticker.Stop()
+	for {
+		if err := m.update(ctx); err != nil {
+			log.Warn().Err(err).Msg("failed to update idle mode")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-m.keyperSetChanged:
+		case <-ticker.C:
+		}
+	}
+}
+
+// update pauses or resumes the idle topics. The mode is applied on every call, as the topics are
+// only joined once the p2p node is running.
+func (m *IdleMonitor) update(ctx context.Context) error {
+	idle := false
+	var blockNumber uint64
+	if m.features.Enabled(FeatureIdleMode) {
+		var err error
+		blockNumber, err = m.l1Client.BlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		isKeyper, err := chainobserver.IsKeyperWithin(
+			ctx, chainobsdb.New(m.dbpool), m.address, blockNumber, m.lookahead,
+		)
+		if err != nil {
+			return err
+		}
+		idle = !isKeyper
+	}
+
+	if idle {
+		m.p2p.PauseTopics(idleTopics...)
+	} else if err := m.p2p.ResumeTopics(idleTopics...); err != nil {
+		return err
+	}
+	if idle != m.idle {
+		m.idle = idle
+		if idle {
+			log.Info().Uint64("block-number", blockNumber).Msg("not in active keyper set, entering idle mode")
+		} else {
+			log.Info().Uint64("block-number", blockNumber).Msg("resuming full operation")
+		}
+	}
+	return nil
+}
@@ -4,51 +4,92 @@ package keyper
 import (
 	"context"
 	"fmt"
+	"runtime"
 	"time"
 
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
-	"github.com/tendermint/tendermint/rpc/client"
-	tmhttp "github.com/tendermint/tendermint/rpc/client/http"
 
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/chainobserver"
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/contract/deployment"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/metadb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/epochkghandler"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/fx"
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprapi"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/mempool"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/shadow"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/smobserver"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/metricsserver"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/alert"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/opapproval"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/paramregistry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/triggeroffset"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p/provenance"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
 )
 
+// maxShareVerificationBatch is the maximum number of decryption key shares verified in one batch.
+const maxShareVerificationBatch = 64
+
+// Options are settings of the keyper that are given on the command line instead of the config
+// file, since they only apply to a single run.
+type Options struct {
+	// StealLease makes the keyper take over the database from another keyper process using it.
+	StealLease bool
+	// Approvals are the operator signatures approving the actions of this run, e.g. stealing
+	// the lease. They are valid until ApprovalNotAfter (a unix timestamp).
+	Approvals        []hexutil.Bytes
+	ApprovalNotAfter int64
+}
+
+// AddApprovals adds the given hex encoded operator signatures to the approvals.
+func (o *Options) AddApprovals(approvals []string) error {
+	for _, approval := range approvals {
+		signature, err := hexutil.Decode(approval)
+		if err != nil {
+			return errors.Wrapf(err, "invalid approval %q", approval)
+		}
+		o.Approvals = append(o.Approvals, signature)
+	}
+	return nil
+}
+
+// VerifyStealLease checks that stealing the lease has been approved by the operators, if the
+// config requires it.
+func (o Options) VerifyStealLease(config *Config) error {
+	if !o.StealLease {
+		return nil
+	}
+	action := &opapproval.Action{
+		Name:       opapproval.ActionStealLease,
+		InstanceID: config.GetInstanceID(),
+		Node:       config.GetAddress(),
+		NotAfter:   o.ApprovalNotAfter,
+	}
+	return opapproval.NewVerifier(config.OperatorApproval).Verify(action, o.Approvals, time.Now())
+}
+
 type keyper struct {
-	config            *Config
-	dbpool            *pgxpool.Pool
-	shuttermintClient client.Client
-	messageSender     fx.RPCMessageSender
-	l1Client          *ethclient.Client
-	contracts         *deployment.Contracts
+	*Node
+	config  *Config
+	options Options
 
+	messageSender    fx.MessageSender
 	shuttermintState *smobserver.ShuttermintState
-	p2p              *p2p.P2PHandler
-	metricsServer    *metricsserver.MetricsServer
+	shareVerifier    *epochkghandler.ShareVerifier
+	idleMonitor      *IdleMonitor
+	signals          dbSignals
+	triggerOffset    triggeroffset.Offset
 }
 
-func New(config *Config) service.Service {
-	return &keyper{config: config}
+func New(config *Config, options Options) service.Service {
+	return &keyper{config: config, options: options}
 }
 
 // LinkConfigToDB ensures that we use a database compatible with the given config. On first use
@@ -76,96 +117,103 @@ func LinkConfigToDB(ctx context.Context, config *Config, dbpool *pgxpool.Pool) e
 	return nil
 }
 
+// PopulateParameters stores the protocol parameters set by the config in the parameter registry.
+func PopulateParameters(ctx context.Context, config *Config, dbpool *pgxpool.Pool) error {
+	return paramregistry.New(dbpool).Populate(ctx, map[string]string{
+		paramregistry.FinalityOffset:      paramregistry.FormatUint64(config.Ethereum.FinalityOffset),
+		paramregistry.TriggerOffsetBlocks: paramregistry.FormatUint64(config.TriggerOffsetBlocks),
+		paramregistry.TriggerOffset:       config.TriggerOffset.Duration.String(),
+	})
+}
+
 func (kpr *keyper) Start(ctx context.Context, runner service.Runner) error {
 	config := kpr.config
-	dbpool, err := pgxpool.Connect(ctx, config.DatabaseURL)
-	if err != nil {
-		return errors.Wrap(err, "failed to connect to database")
-	}
-	runner.Defer(dbpool.Close)
-	shdb.AddConnectionInfo(log.Info(), dbpool).Msg("connected to database")
-
-	l1Client, err := ethclient.Dial(config.Ethereum.EthereumURL)
-	if err != nil {
-		return err
-	}
-	l2Client, err := ethclient.Dial(config.Ethereum.ContractsURL)
-	if err != nil {
-		return err
-	}
-	contracts, err := deployment.NewContracts(l2Client, config.Ethereum.DeploymentDir)
+	node, err := StartNode(ctx, runner, config, kpr.options, NodeSpec{
+		Name:         "keyper",
+		ContractsURL: config.Ethereum.ContractsURL,
+		Checkpoints:  true,
+		SLA:          true,
+	})
 	if err != nil {
 		return err
 	}
+	kpr.Node = node
 
-	err = kprdb.ValidateKeyperDB(ctx, dbpool)
-	if err != nil {
-		return err
-	}
-	err = LinkConfigToDB(ctx, config, dbpool)
+	kpr.triggerOffset, err = triggeroffset.Load(ctx, paramregistry.New(node.DBPool))
 	if err != nil {
 		return err
 	}
-	shuttermintClient, err := tmhttp.New(config.Shuttermint.ShuttermintURL, "/websocket")
-	if err != nil {
-		return err
-	}
-	messageSender := fx.NewRPCMessageSender(shuttermintClient, config.Ethereum.PrivateKey.Key)
-
-	p2pHandler, err := p2p.New(config.P2P)
-	if err != nil {
-		return err
+	rpcMessageSender := fx.NewRPCMessageSender(node.ShuttermintClient, config.Ethereum.PrivateKey.Key)
+	kpr.messageSender = &rpcMessageSender
+	if config.Shadow.Enabled {
+		log.Warn().Msg("running in shadow mode, no messages are sent to the p2p network or to shuttermint")
+		node.P2P.DisablePublishing()
+		kpr.messageSender = fx.DiscardingMessageSender{}
 	}
-
-	if kpr.config.Metrics.Enabled {
-		epochkghandler.InitMetrics()
-		kpr.metricsServer = metricsserver.New(kpr.config.Metrics)
+	if config.Metrics.Enabled {
+		shadow.InitMetrics()
 	}
 
-	kpr.dbpool = dbpool
-	kpr.shuttermintClient = shuttermintClient
-	kpr.messageSender = messageSender
-	kpr.l1Client = l1Client
-	kpr.contracts = contracts
 	kpr.shuttermintState = smobserver.NewShuttermintState(config)
-	kpr.p2p = p2pHandler
+	if config.ShareVerificationWindow.Duration > 0 {
+		kpr.shareVerifier = epochkghandler.NewShareVerifier(
+			config.ShareVerificationWindow.Duration, maxShareVerificationBatch, runtime.NumCPU(),
+		)
+	}
+	kpr.idleMonitor = NewIdleMonitor(
+		node.DBPool, node.L1Client, node.P2P, node.Features, config.GetAddress(), config.Shuttermint.DKGStartBlockDelta,
+	)
+	kpr.signals = newDBSignals()
 
 	kpr.setupP2PHandler()
 	return runner.StartService(kpr.getServices()...)
 }
 
 func (kpr *keyper) setupP2PHandler() {
-	kpr.p2p.AddMessageHandler(
-		epochkghandler.NewDecryptionKeyHandler(kpr.config, kpr.dbpool),
-		epochkghandler.NewDecryptionKeyShareHandler(kpr.config, kpr.dbpool),
-		epochkghandler.NewDecryptionTriggerHandler(kpr.config, kpr.dbpool),
-		epochkghandler.NewEonPublicKeyHandler(kpr.config, kpr.dbpool),
+	epochIDs := epochkghandler.NewEpochIDValidator(kpr.config.GetEpochIDMode(), kpr.L1Client, kpr.Beacon)
+	handlers := append(
+		kpr.MessageHandlers(epochIDs, kpr.shareVerifier, kpr.triggerOffset),
+		epochkghandler.NewEpochPreAnnouncementHandler(kpr.config, kpr.DBPool),
 	)
+	kpr.P2P.AddMessageHandler(provenance.Wrap(
+		kpr.DBPool, kpr.Storage, newAuditedMessageHandlers(kpr.DBPool, kpr.Storage, handlers...)...,
+	)...)
+	kpr.P2P.AddMessageHandler(kpr.AttestationHandler()...)
+
+	// we aggregate the shares sent to us directly regardless of how we send our own ones
+	kpr.P2P.AcceptDirectMessages(&p2pmsg.DecryptionKeyShares{})
+	if kpr.config.ShareAggregators > 0 {
+		kpr.P2P.AddDirectRoute(
+			epochkghandler.NewAggregatorRoute(kpr.config, kpr.DBPool, kpr.config.ShareAggregators),
+			&p2pmsg.DecryptionKeyShares{},
+		)
+	}
 }
 
 func (kpr *keyper) getServices() []service.Service {
-	services := []service.Service{
-		kpr.p2p,
+	services := append(kpr.Services(),
 		service.ServiceFn{Fn: kpr.operateShuttermint},
 		service.ServiceFn{Fn: kpr.broadcastEonPublicKeys},
-		service.ServiceFn{Fn: kpr.handleContractEvents},
-	}
-
-	if kpr.config.HTTPEnabled {
-		services = append(services, kprapi.NewHTTPService(kpr.dbpool, kpr.config, kpr.p2p))
+		service.ServiceFn{Fn: kpr.idleMonitor.Run},
+		service.ServiceFn{Fn: kpr.handleDatabaseNotifications},
+	)
+	if kpr.shareVerifier != nil {
+		services = append(services, service.ServiceFn{Fn: kpr.shareVerifier.Run})
 	}
-	if kpr.config.Metrics.Enabled {
-		services = append(services, kpr.metricsServer)
+	if kpr.config.Shadow.Comparing() {
+		services = append(services, shadow.NewComparator(kpr.config.Shadow, kpr.DBPool))
 	}
 	return services
 }
 
-func (kpr *keyper) handleContractEvents(ctx context.Context) error {
-	events := []*eventsyncer.EventType{
-		kpr.contracts.KeypersConfigsListNewConfig,
-		kpr.contracts.CollatorConfigsListNewConfig,
+// NewMempoolObserver creates the observer of the target chain's mempool. Decryption triggers are
+// never requested in shadow mode, because a shadow node must not publish anything.
+func NewMempoolObserver(config *Config, dbpool *pgxpool.Pool, notifier alert.Notifier) *mempool.Observer {
+	var trigger *mempool.TriggerRequester
+	if config.Mempool.Action == mempool.ActionTrigger && !config.Shadow.Enabled {
+		trigger = mempool.NewTriggerRequester(config.Mempool.TriggerURL, config.InstanceID, config.Ethereum.PrivateKey.Key)
 	}
-	return chainobserver.New(kpr.contracts, kpr.dbpool).Observe(ctx, events)
+	return mempool.NewObserver(config.Mempool, dbpool, notifier, trigger)
 }
 
 func (kpr *keyper) handleOnChainChanges(
@@ -308,29 +356,30 @@ func (kpr *keyper) handleOnChainKeyperSetChanges(
 
 func (kpr *keyper) operateShuttermint(ctx context.Context) error {
 	for {
-		l1BlockNumber, err := retry.FunctionCall(ctx, kpr.l1Client.BlockNumber)
+		l1BlockNumber, err := retry.FunctionCall(ctx, kpr.L1Client.BlockNumber)
 		if err != nil {
 			return err
 		}
 
-		err = smobserver.SyncAppWithDB(ctx, kpr.shuttermintClient, kpr.dbpool, kpr.shuttermintState)
+		err = smobserver.SyncAppWithDB(ctx, kpr.ShuttermintClient, kpr.DBPool, kpr.shuttermintState)
 		if err != nil {
 			return err
 		}
-		err = kpr.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		err = kpr.DBPool.BeginFunc(ctx, func(tx pgx.Tx) error {
 			return kpr.handleOnChainChanges(ctx, tx, l1BlockNumber)
 		})
 		if err != nil {
 			return err
 		}
 
-		err = fx.SendShutterMessages(ctx, kprdb.New(kpr.dbpool), &kpr.messageSender)
+		err = fx.SendShutterMessages(ctx, kprdb.New(kpr.DBPool), kpr.messageSender)
 		if err != nil {
 			return err
 		}
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-kpr.signals.newKeyperSet:
 		case <-time.After(2 * time.Second):
 		}
 	}
@@ -338,7 +387,7 @@ func (kpr *keyper) operateShuttermint(ctx context.Context) error {
 
 func (kpr *keyper) broadcastEonPublicKeys(ctx context.Context) error {
 	for {
-		eonPublicKeys, err := kprdb.New(kpr.dbpool).GetAndDeleteEonPublicKeys(ctx)
+		eonPublicKeys, err := kprdb.New(kpr.DBPool).GetAndDeleteEonPublicKeys(ctx)
 		if err != nil {
 			return err
 		}
@@ -347,26 +396,21 @@ func (kpr *keyper) broadcastEonPublicKeys(ctx context.Context) error {
 			if !exists {
 				return errors.Errorf("own keyper index not found for Eon=%d", eonPublicKey.Eon)
 			}
-			msg, err := p2pmsg.NewSignedEonPublicKey(
-				kpr.config.InstanceID,
-				eonPublicKey.EonPublicKey,
-				uint64(eonPublicKey.ActivationBlockNumber),
-				uint64(eonPublicKey.KeyperConfigIndex),
-				uint64(eonPublicKey.Eon),
-				kpr.config.Ethereum.PrivateKey.Key,
-			)
-			if err != nil {
-				return errors.Wrap(err, "error while signing EonPublicKey")
+			msg := &p2pmsg.EonPublicKey{
+				InstanceID:        kpr.config.InstanceID,
+				PublicKey:         eonPublicKey.EonPublicKey,
+				ActivationBlock:   uint64(eonPublicKey.ActivationBlockNumber),
+				KeyperConfigIndex: uint64(eonPublicKey.KeyperConfigIndex),
+				Eon:               uint64(eonPublicKey.Eon),
 			}
-
-			err = kpr.p2p.SendMessage(ctx, msg)
-			if err != nil {
-				return errors.Wrap(err, "error while broadcasting EonPublicKey")
+			if err := kpr.BroadcastEonPublicKey(ctx, msg); err != nil {
+				return err
 			}
 		}
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-kpr.signals.newOutgoingEonKey:
 		case <-time.After(2 * time.Second):
 		}
 	}
@@ -13,16 +13,26 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/epochkghandler"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kproapi"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
 
-func sendError(w http.ResponseWriter, code int, message string) {
+// sendError responds with the HTTP status and error code of err's class, see errcode.Of.
+func sendError(w http.ResponseWriter, err error) {
+	class := errcode.Of(err)
+	logEvent := log.Debug()
+	if class.HTTPStatus >= http.StatusInternalServerError {
+		logEvent = log.Warn()
+	}
+	logEvent.Err(err).Str("error-code", string(class.Code)).Msg("keyper api request failed")
+
 	e := kproapi.Error{
-		Code:    int32(code),
-		Message: message,
+		Code:      int32(class.HTTPStatus),
+		ErrorCode: string(class.Code),
+		Message:   err.Error(),
 	}
-	w.WriteHeader(code)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(class.HTTPStatus)
 
 	_ = json.NewEncoder(w).Encode(e)
 }
@@ -37,7 +47,7 @@ func (srv *server) GetDecryptionKey(w http.ResponseWriter, r *http.Request, eon
 
 	epochIDBytes, err := hex.DecodeString(strings.TrimPrefix(string(epochID), "0x"))
 	if err != nil {
-		sendError(w, http.StatusBadRequest, err.Error())
+		sendError(w, errcode.ErrInvalidEpochID.Wrap(err))
 		return
 	}
 
@@ -46,11 +56,11 @@ func (srv *server) GetDecryptionKey(w http.ResponseWriter, r *http.Request, eon
 		EpochID: epochIDBytes,
 	})
 	if err == pgx.ErrNoRows {
-		sendError(w, http.StatusNotFound, "no decryption key found for given epoch")
+		sendError(w, errcode.ErrDecryptionKeyNotFound.Errorf("no decryption key found for given epoch"))
 		return
 	}
 	if err != nil {
-		sendError(w, http.StatusInternalServerError, err.Error())
+		sendError(w, errcode.WrapDB(err, "failed to get decryption key from db"))
 		return
 	}
 
@@ -67,7 +77,7 @@ func (srv *server) GetEons(w http.ResponseWriter, r *http.Request) {
 
 	eons, err := db.GetAllEons(ctx)
 	if err != nil {
-		sendError(w, http.StatusInternalServerError, err.Error())
+		sendError(w, errcode.WrapDB(err, "failed to get eons from db"))
 		return
 	}
 	for _, eon := range eons {
@@ -81,8 +91,7 @@ func (srv *server) GetEons(w http.ResponseWriter, r *http.Request) {
 			finished = false
 			successful = false
 		} else if err != nil {
-			log.Info().Msg("failed to get dkg result from db")
-			sendError(w, http.StatusInternalServerError, err.Error())
+			sendError(w, errcode.WrapDB(err, "failed to get dkg result from db"))
 			return
 		} else {
 			finished = true
@@ -90,7 +99,7 @@ func (srv *server) GetEons(w http.ResponseWriter, r *http.Request) {
 			if successful {
 				dkgResult, err := shdb.DecodePureDKGResult(encodedDKGResult.PureResult)
 				if err != nil {
-					sendError(w, http.StatusInternalServerError, err.Error())
+					sendError(w, err)
 					return
 				}
 				eonKey = dkgResult.PublicKey.Marshal()
@@ -111,32 +120,66 @@ func (srv *server) GetEons(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(res)
 }
 
+func (srv *server) GetEonPublicKey(w http.ResponseWriter, r *http.Request, eon int) {
+	ctx := r.Context()
+	db := kprdb.New(srv.dbpool)
+
+	candidate, err := db.GetConfirmedEonPublicKey(ctx, int64(eon))
+	if err == pgx.ErrNoRows {
+		sendError(w, errcode.ErrEonKeyNotFound.Errorf("no confirmed eon public key found for given eon"))
+		return
+	}
+	if err != nil {
+		sendError(w, errcode.WrapDB(err, "failed to get confirmed eon public key from db"))
+		return
+	}
+	votes, err := db.FindEonPublicKeyVotes(ctx, candidate.Hash)
+	if err != nil {
+		sendError(w, errcode.WrapDB(err, "failed to get eon public key votes from db"))
+		return
+	}
+
+	res := kproapi.ConfirmedEonPublicKey{
+		Eon:                   int(candidate.Eon),
+		EonPublicKey:          "0x" + hex.EncodeToString(candidate.EonPublicKey),
+		ActivationBlockNumber: int(candidate.ActivationBlockNumber),
+		KeyperConfigIndex:     int(candidate.KeyperConfigIndex),
+		Votes:                 []kproapi.EonPublicKeyVote{},
+	}
+	for _, vote := range votes {
+		res.Votes = append(res.Votes, kproapi.EonPublicKeyVote{
+			Sender:    vote.Sender,
+			Signature: "0x" + hex.EncodeToString(vote.Signature),
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
 func (srv *server) SubmitDecryptionTrigger(w http.ResponseWriter, r *http.Request) {
 	var requestBody kproapi.SubmitDecryptionTriggerJSONRequestBody
 	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
-		sendError(w, http.StatusBadRequest, "Invalid request for SubmitDecryptionTrigger")
+		sendError(w, errcode.ErrInvalidRequest.Errorf("Invalid request for SubmitDecryptionTrigger"))
 		return
 	}
 	epochIDBytes, err := hex.DecodeString(strings.TrimPrefix(requestBody.EpochId, "0x"))
 	if err != nil {
-		sendError(w, http.StatusBadRequest, err.Error())
+		sendError(w, errcode.ErrInvalidEpochID.Wrap(err))
 		return
 	}
 	epochID, err := epochid.BytesToEpochID(epochIDBytes)
 	if err != nil {
-		sendError(w, http.StatusBadRequest, err.Error())
+		sendError(w, errcode.ErrInvalidEpochID.Wrap(err))
 		return
 	}
 
 	ctx := r.Context()
 	msgs, err := epochkghandler.SendDecryptionKeyShare(
-		ctx, srv.config, kprdb.New(srv.dbpool), int64(requestBody.BlockNumber), epochID,
+		ctx, srv.config, kprdb.New(srv.dbpool), srv.fence, srv.selfAudit, int64(requestBody.BlockNumber), epochID,
 	)
 	if err != nil {
-		if err != nil {
-			sendError(w, http.StatusInternalServerError, err.Error())
-			return
-		}
+		sendError(w, err)
+		return
 	}
 
 	for _, msg := range msgs {
@@ -16,9 +16,27 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog/log"
 
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/chainobserver"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/shversion"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/annotation"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/checkpoint"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/epochkghandler"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kproapi"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/pause"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/rebroadcast"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/revelation"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/sla"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/featureflag"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/httpauth"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/logfilter"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/metricsnapshot"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/opapproval"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/paramregistry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/tlsconfig"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p/attestation"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 )
 
@@ -28,21 +46,52 @@ type P2PMessageSender interface {
 
 type Config interface {
 	GetHTTPListenAddress() string
+	GetHTTPAuth() *httpauth.Config
+	GetHTTPTLS() *tlsconfig.ServerConfig
 	GetAddress() common.Address
 	GetInstanceID() uint64
+	GetOperatorApproval() *opapproval.Config
 }
 
 type server struct {
-	dbpool *pgxpool.Pool
-	config Config
-	p2p    P2PMessageSender
+	dbpool        *pgxpool.Pool
+	fence         kprdb.Fence
+	config        Config
+	p2p           P2PMessageSender
+	features      *featureflag.Set
+	selfAudit     *epochkghandler.SelfAudit
+	pause         *pause.Controller
+	revelation    *revelation.Prover
+	checkpoints   *checkpoint.Writer
+	sla           *sla.Tracker
+	statusMetrics map[string]metricsnapshot.Sampler
 }
 
-func NewHTTPService(dbpool *pgxpool.Pool, config Config, p2p P2PMessageSender) service.Service {
+func NewHTTPService(
+	dbpool *pgxpool.Pool,
+	fence kprdb.Fence,
+	config Config,
+	p2p P2PMessageSender,
+	features *featureflag.Set,
+	selfAudit *epochkghandler.SelfAudit,
+	pause *pause.Controller,
+	revelation *revelation.Prover,
+	checkpoints *checkpoint.Writer,
+	tracker *sla.Tracker,
+	statusMetrics map[string]metricsnapshot.Sampler,
+) service.Service {
 	return &server{
-		dbpool: dbpool,
-		config: config,
-		p2p:    p2p,
+		dbpool:        dbpool,
+		fence:         fence,
+		config:        config,
+		p2p:           p2p,
+		features:      features,
+		selfAudit:     selfAudit,
+		pause:         pause,
+		revelation:    revelation,
+		checkpoints:   checkpoints,
+		sla:           tracker,
+		statusMetrics: statusMetrics,
 	}
 }
 
@@ -56,6 +105,8 @@ func (srv *server) setupRouter() *chi.Mux {
 	router := chi.NewRouter()
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
+	router.Use(httpauth.New(srv.config.GetHTTPAuth()).Middleware)
+	router.Use(httpauth.RequireRoleByMethod())
 	router.Mount("/v1", http.StripPrefix("/v1", srv.setupAPIRouter(swagger)))
 	apiJSON, _ := json.Marshal(swagger)
 	router.Get("/api.json", func(w http.ResponseWriter, r *http.Request) {
@@ -66,6 +117,31 @@ func (srv *server) setupRouter() *chi.Mux {
 		_, _ = w.Write(apiJSON)
 	})
 	router.Mount("/metrics", promhttp.Handler())
+	approvals := opapproval.NewVerifier(srv.config.GetOperatorApproval())
+	instanceID, address := srv.config.GetInstanceID(), srv.config.GetAddress()
+	router.With(approvals.Middleware(opapproval.ActionOverrideFeature, instanceID, address)).
+		Mount("/features", srv.features.Router())
+	router.With(approvals.Middleware(opapproval.ActionSetLogFilter, instanceID, address)).
+		Mount("/log", logfilter.Default.Router())
+	router.With(approvals.Middleware(opapproval.ActionPauseKeyGeneration, instanceID, address)).
+		Mount("/pause", srv.pause.Router())
+	router.With(
+		httpauth.RequireRole(httpauth.RoleAdmin),
+		approvals.Middleware(opapproval.ActionRebroadcastKeys, instanceID, address),
+	).Mount("/rebroadcast", rebroadcast.New(srv.dbpool, instanceID, srv.p2p).Router())
+	router.Get("/pending-configs", chainobserver.PendingConfigsHandler(srv.dbpool))
+	router.Get("/peers", attestation.PeersHandler(srv.dbpool, shversion.Version()))
+	router.Get("/parameters", paramregistry.Handler(srv.dbpool))
+	router.Mount("/annotations", annotation.Router(srv.dbpool))
+	router.Mount("/revelation-proofs", srv.revelation.Router())
+	router.Get("/checkpoint", checkpoint.Handler(srv.checkpoints))
+	if srv.sla != nil {
+		router.Mount("/sla", srv.sla.Router())
+	}
+	router.Get("/status", srv.handleStatus)
+	router.With(httpauth.RequireRole(httpauth.RoleAdmin)).
+		Get("/ignored-events", chainobserver.IgnoredEventsHandler(srv.dbpool))
+	router.With(httpauth.RequireRole(httpauth.RoleAdmin)).Mount("/debug", middleware.Profiler())
 	/*
 	   The following enables the swagger ui. Run the following to use it:
 
@@ -90,7 +166,9 @@ func (srv *server) Start(ctx context.Context, runner service.Runner) error {
 		Handler:           srv.setupRouter(),
 		ReadHeaderTimeout: 5 * time.Second,
 	}
-	runner.Go(httpServer.ListenAndServe)
+	runner.Go(func() error {
+		return srv.config.GetHTTPTLS().ListenAndServe(httpServer)
+	})
 	runner.Go(func() error {
 		<-ctx.Done()
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
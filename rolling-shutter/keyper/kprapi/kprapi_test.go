@@ -0,0 +1,53 @@
+package kprapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/httpauth"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/opapproval"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/tlsconfig"
+)
+
+type testConfig struct {
+	auth *httpauth.Config
+}
+
+func (c testConfig) GetHTTPListenAddress() string            { return "" }
+func (c testConfig) GetHTTPAuth() *httpauth.Config           { return c.auth }
+func (c testConfig) GetHTTPTLS() *tlsconfig.ServerConfig     { return tlsconfig.NewServerConfig() }
+func (c testConfig) GetAddress() common.Address              { return common.Address{} }
+func (c testConfig) GetInstanceID() uint64                   { return 0 }
+func (c testConfig) GetOperatorApproval() *opapproval.Config { return opapproval.NewConfig() }
+
+func TestRouterProfiler(t *testing.T) {
+	router := (&server{config: testConfig{auth: &httpauth.Config{
+		Enabled:        true,
+		ReadOnlyTokens: []string{"readonly"},
+		OperatorTokens: []string{"operator"},
+		AdminTokens:    []string{"admin"},
+	}}}).setupRouter()
+
+	testCases := []struct {
+		token  string
+		status int
+	}{
+		{token: "", status: http.StatusUnauthorized},
+		{token: "readonly", status: http.StatusForbidden},
+		{token: "operator", status: http.StatusForbidden},
+		{token: "admin", status: http.StatusOK},
+	}
+	for _, tc := range testCases {
+		r := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+		if tc.token != "" {
+			r.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		assert.Equal(t, w.Code, tc.status, "token %q", tc.token)
+	}
+}
@@ -0,0 +1,110 @@
+package kprapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/annotation"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/recentlog"
+)
+
+// Status is a summary of the state of the keyper, served at /status for operator dashboards.
+type Status struct {
+	Time         time.Time          `json:"time"`
+	Keyper       common.Address     `json:"keyper"`
+	Eon          *int64             `json:"eon,omitempty"`
+	LatestEpoch  *FinalizedEpoch    `json:"latestEpoch,omitempty"`
+	Metrics      map[string]float64 `json:"metrics"`
+	RecentErrors []recentlog.Entry  `json:"recentErrors"`
+	// Annotations are the operator annotations of this keyper, the current eon and the latest
+	// epoch.
+	Annotations []annotation.Annotation `json:"annotations"`
+}
+
+// FinalizedEpoch is the epoch whose decryption key became known most recently.
+type FinalizedEpoch struct {
+	Eon         int64         `json:"eon"`
+	EpochID     hexutil.Bytes `json:"epochID"`
+	FinalizedAt time.Time     `json:"finalizedAt"`
+}
+
+func (srv *server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	status := Status{
+		Time:         time.Now().UTC(),
+		Keyper:       srv.config.GetAddress(),
+		Metrics:      make(map[string]float64, len(srv.statusMetrics)),
+		RecentErrors: recentlog.Default.Entries(),
+	}
+	// Metrics that fail to be sampled are left out, so that the status is still available when
+	// e.g. the Ethereum node is down.
+	for name, sampler := range srv.statusMetrics {
+		value, err := sampler(ctx)
+		if err != nil {
+			log.Debug().Err(err).Str("metric", name).Msg("failed to sample metric")
+			continue
+		}
+		status.Metrics[name] = value
+	}
+	if err := srv.loadEpochStatus(ctx, &status); err != nil {
+		log.Error().Err(err).Msg("failed to get status from db")
+		http.Error(w, "failed to get status", http.StatusInternalServerError)
+		return
+	}
+	if err := srv.loadAnnotations(ctx, &status); err != nil {
+		log.Error().Err(err).Msg("failed to get annotations from db")
+		http.Error(w, "failed to get status", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+func (srv *server) loadEpochStatus(ctx context.Context, status *Status) error {
+	db := kprdb.New(srv.dbpool)
+	blockNumber, err := db.GetLastBlockSeen(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get last block seen")
+	}
+	eon, err := db.GetEonForBlockNumber(ctx, blockNumber)
+	if err == nil {
+		status.Eon = &eon.Eon
+	} else if err != pgx.ErrNoRows {
+		return errors.Wrap(err, "failed to get current eon")
+	}
+	epoch, err := db.GetLatestFinalizedEpoch(ctx)
+	if err == nil {
+		status.LatestEpoch = &FinalizedEpoch{
+			Eon:         epoch.Eon,
+			EpochID:     epoch.EpochID,
+			FinalizedAt: epoch.FinalizedAt.UTC(),
+		}
+	} else if err != pgx.ErrNoRows {
+		return errors.Wrap(err, "failed to get latest finalized epoch")
+	}
+	return nil
+}
+
+func (srv *server) loadAnnotations(ctx context.Context, status *Status) error {
+	index, err := annotation.Load(ctx, srv.dbpool)
+	if err != nil {
+		return err
+	}
+	status.Annotations = append([]annotation.Annotation{}, index.Keyper(status.Keyper.Hex())...)
+	if status.Eon != nil {
+		status.Annotations = append(status.Annotations, index.Eon(*status.Eon)...)
+	}
+	if status.LatestEpoch != nil {
+		status.Annotations = append(status.Annotations, index.Epoch(status.LatestEpoch.EpochID)...)
+	}
+	return nil
+}
@@ -18,6 +18,15 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
+// ConfirmedEonPublicKey defines model for ConfirmedEonPublicKey.
+type ConfirmedEonPublicKey struct {
+	ActivationBlockNumber int                `json:"activation_block_number"`
+	Eon                   int                `json:"eon"`
+	EonPublicKey          string             `json:"eon_public_key"`
+	KeyperConfigIndex     int                `json:"keyper_config_index"`
+	Votes                 []EonPublicKeyVote `json:"votes"`
+}
+
 // DecryptionKey defines model for DecryptionKey.
 type DecryptionKey string
 
@@ -36,6 +45,12 @@ type Eon struct {
 	Successful            bool   `json:"successful"`
 }
 
+// EonPublicKeyVote defines model for EonPublicKeyVote.
+type EonPublicKeyVote struct {
+	Sender    string `json:"sender"`
+	Signature string `json:"signature"`
+}
+
 // Eons defines model for Eons.
 type Eons []Eon
 
@@ -44,8 +59,11 @@ type EpochID string
 
 // Error defines model for Error.
 type Error struct {
-	Code    int32  `json:"code"`
-	Message string `json:"message"`
+	Code int32 `json:"code"`
+
+	// machine readable class of the error, e.g. EON_UNKNOWN
+	ErrorCode string `json:"error_code"`
+	Message   string `json:"message"`
 }
 
 // SubmitDecryptionTriggerJSONBody defines parameters for SubmitDecryptionTrigger.
@@ -63,6 +81,9 @@ type ServerInterface interface {
 	// (POST /decryptionTrigger)
 	SubmitDecryptionTrigger(w http.ResponseWriter, r *http.Request)
 
+	// (GET /eonPublicKey/{eon})
+	GetEonPublicKey(w http.ResponseWriter, r *http.Request, eon int)
+
 	// (GET /eons)
 	GetEons(w http.ResponseWriter, r *http.Request)
 
@@ -129,6 +150,32 @@ func (siw *ServerInterfaceWrapper) SubmitDecryptionTrigger(w http.ResponseWriter
 	handler(w, r.WithContext(ctx))
 }
 
+// GetEonPublicKey operation middleware
+func (siw *ServerInterfaceWrapper) GetEonPublicKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "eon" -------------
+	var eon int
+
+	err = runtime.BindStyledParameter("simple", false, "eon", chi.URLParam(r, "eon"), &eon)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "eon", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetEonPublicKey(w, r, eon)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
 // GetEons operation middleware
 func (siw *ServerInterfaceWrapper) GetEons(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -278,6 +325,9 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/decryptionTrigger", wrapper.SubmitDecryptionTrigger)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/eonPublicKey/{eon}", wrapper.GetEonPublicKey)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/eons", wrapper.GetEons)
 	})
@@ -291,20 +341,24 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/8xV32vjRhD+V5bpQV8US/eDQvV2JaGYUjho3tI0rKWRtRdpdrs7SmOM/vcyKyWWbDnX",
-	"HD24J6+1Oz++b76Z2UNhW2cJiQPkewhFja2Ox0ss/M6xsfQb7uSD08zoCXL4K3u8yS5+1hfV7f7tT/0b",
-	"SIB3DiGHwN7QFvpkYn7tzXaLPrrw1qFngzHCprHF/R117Wa4bQ2Ztmshz579GWIU2z4BdLao70z52lT6",
-	"BDz+3RmPJeQ3BzfJPP7ts6HdfMaCJeSVpdOsdcHmQQuwu1cCsHR3/zVUVoZMqDEiHy831jaoSW4Nlfj4",
-	"5eihKwoMoeqaJTdHJA0+k7NYD2Am2c1inKEzMmgY23h447GCHH5IDyJMRwWmwn3/7ER7r3fRh5Rvffl6",
-	"Eq+8twsaLGyJ8ltZ32oeKHv/DpYYbDEEvcUJfWckFn0e3p9S0ceyVXZIgFgXLEfSbfRadwLsgpD/sf4e",
-	"Euh8AznUzC5P0/F6NV6nklqJofAmdhvkcF2boIZPGwyKa1TeNo2hrRqNfwzqHncOvfr4aQ0JNKZACjhJ",
-	"4vf1daTfcCN/j+xHa0jgAX0Yomart6tMbKxD0s5ADu9X2SqDRCpVR7bTcjpU0j1a6tM9DjXt5cUWIxVz",
-	"QL8iRxQHa0lAVdYrTSqaq/UlxNA+ynVdDmbzISaZeN0iow+Q3xyHubKkbLUUia2SxKRqkEc4kDwxhZZg",
-	"Wn/2HSbjIP1SW/bJSQ4jmK9JZGyNl5J5seNG+76/FRfBWQpDk7zLsiepIsX6aOcaU0Sq089hGJP/Lcq8",
-	"IrEVjtV7jFpE9SH7cKoLlJ5WZpGpWgdFltUGkdQWSYSBpdohDw1T6a7h/w3VMF0W0HSEjw4LCY1Pb/pk",
-	"2gjT9WjDgvrHB8cAR0zyd9oIJ13wR7dpDZ+u40EmGPgXW+6+QXmf4iyQ8nGKhUd4wWFhqp1MmaGjNZUq",
-	"bh31vHXmwu6XZTqPNe6k76LoOC7As0NOloLsIeFFb2zHSjeNCqw9Y/knLU24uFS/Yb9G/wsY1y+lqiLS",
-	"74FyJwv6HOWfDG2H/RjQP6BfYFiezOmlrmnE+b8BAAD//1Yw9QY7CwAA",
+	"H4sIAAAAAAACA81W2W7cNhT9FUIN0JfxSFlQoH5LGqMYBHUM1GkfHGdASVcSY4lUSSrxwNC/93LRaB17",
+	"HMSAX2wNybucc9e7IBFVLThwrYLTu0AlBVTUfr6HRO5qzQT/ADtzUFOtQfLgNPgS3V5FJ7/Tk+z67uVv",
+	"7YtgFehdDXijtGQ8D9rVQPxSsjwHaVVIUYPUDKyFuBTJzZY3VexuK8ZZ1VTBabTXx7gGI4sKoRZJsWXp",
+	"Y11BUQn/NUwCil71alZj+9d7QRF/hUQbk2eCz72miWbfqAG2fSQAlLj5ESozVKsKsMj9ZSxECZSbW8ZT",
+	"uH3YumqSBJTKmnJJzYQkp3N1EGsPZuDdyMYBOi2DTENlP15IyPDFL2GfhKHPwNBw3+6VUCnpzuow4du8",
+	"fzyJZ1KKhRxMRArmfyZkRbWj7PWrYInBCqHRHAb09drBaN92ylJQiWQ2+fFVRZOCcSASaErjEkhSUqWI",
+	"yIgugFjJFYF1viZnH8+3n84/nH/89/zBPLa2RoZ7Dw+Qf9HEJUuwmv8RGuZUKMCoy0V4iuWc6kYugZ/4",
+	"5bUMZZbc+UPwjMkK0qFfP7fYjqvI2hrvCnOGHM/RHSQY3c23R9baN+T3UZk+jsws7actDLHNnL+vWJdA",
+	"dF7Og9PappIJVx5co17zyWllqSkaU3YnHPR3IW9QTyOxpQSF1vVpGPrrtb8ODZhxOVwWTBF3FIOyNSBF",
+	"WSLfxAv/qojzmLy92KABhAhcwcCJvzaXliWmS/NzIu+lDUSQylmN1i/XkZHB7OK0Znj0eh3h0cr0kcIG",
+	"KUyHIy+8Q4Zb/Os6Tmte5KDn9f0naIuilzYOEOwohHJixQl2LGta2vhsUic2HrHGE4n4EAG6czU1g2nS",
+	"9YyJJS2IccxEDd8ZOPjtmXK50mePlg2s/Jh/KJHb1cwHD+ZHHPGN+z5n7q0SL9+210aFwnvliuxVFHWp",
+	"ihK2a9Q15oylOvyqXCc4zso4IrYUptk7RW2S6k30Zp4XtjUTtshUQRXhQpMYgCNn3CQGpGQH2hVMRptS",
+	"/zRUbvYtoGk43NZY9Ggaujf4aFAIw+VNqIXs9w+mAD0m83NYCLMq+LuJK6bny6JLE1D6nUh3TxDezs4C",
+	"KW+HWLSHp5Amlu1Ml3EVTXlKbJsl+zY7Tux2OU3HtvzG9CyCDn49O9jkzFAwW5Lhhcai0YSWJVGaStT1",
+	"mS91OLvyPWG9Wv0LGDf3uUos0udAeW22jEOUX+Clm48KJE6yBYbNkzG9vCnLLpr7rcKNsgcHGD4ibqOw",
+	"JYw93pStKQKBcgVWwXemC+dRt9zt91g3c42/VH/mSbfeEaYX/M5tZvR73/Gjb+Dfk429pxwwy3vvgUHT",
+	"szgOzXEjZxLO0cjpNT+LkdO2/wOa9JWqiRAAAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
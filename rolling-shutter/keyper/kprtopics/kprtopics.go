@@ -1,8 +1,12 @@
 package kprtopics
 
 const (
-	DecryptionTrigger   = "decryptionTrigger"
-	DecryptionKey       = "decryptionKey"
-	DecryptionKeyShares = "decryptionKeyShares"
-	EonPublicKey        = "EonPublicKey"
+	DecryptionTrigger      = "decryptionTrigger"
+	DecryptionTriggerBatch = "decryptionTriggerBatch"
+	DecryptionKey          = "decryptionKey"
+	DecryptionKeyShares    = "decryptionKeyShares"
+	EonPublicKey           = "EonPublicKey"
+	EpochPreAnnouncement   = "epochPreAnnouncement"
+	NodeAttestation        = "nodeAttestation"
+	PauseVote              = "pauseVote"
 )
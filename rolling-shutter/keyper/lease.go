@@ -0,0 +1,178 @@
+package keyper
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+)
+
+// The advisory lock protecting the database is identified by these two keys. The first one is
+// shared by all locks taken by rolling shutter nodes.
+const (
+	advisoryLockNamespace    = 0x53485554
+	processLeaseAdvisoryLock = 1
+
+	leaseRenewInterval = 10 * time.Second
+	leaseStealAttempts = 10
+	leaseStealWait     = 500 * time.Millisecond
+)
+
+// ErrLeaseHeld is returned when another keyper process holds the lease on the database.
+var ErrLeaseHeld = errors.New("database is in use by another keyper process")
+
+// ProcessLease ensures that only a single keyper process uses a database, since two keypers
+// sharing a database would corrupt its state and could sign conflicting messages. The lease is a
+// session level advisory lock held on a dedicated connection. Postgres releases it as soon as that
+// connection is closed, so the lease of a crashed process is released automatically.
+//
+// A process whose lease has been stolen only notices when it renews the lease. In the meantime, it
+// may still use its other connections, so transactions that sign messages are run with BeginFunc,
+// which fences them with the token stored when the lease was acquired.
+type ProcessLease struct {
+	dbpool   *pgxpool.Pool
+	conn     *pgxpool.Conn
+	pid      int32
+	hostname string
+	token    int64
+}
+
+func tryAdvisoryLock(ctx context.Context, conn *pgxpool.Conn) (bool, error) {
+	var locked bool
+	err := conn.QueryRow(
+		ctx, "SELECT pg_try_advisory_lock($1, $2)", advisoryLockNamespace, processLeaseAdvisoryLock,
+	).Scan(&locked)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to acquire advisory lock")
+	}
+	return locked, nil
+}
+
+// terminateLeaseHolder closes the connection of the process currently holding the lock.
+func terminateLeaseHolder(ctx context.Context, conn *pgxpool.Conn) error {
+	_, err := conn.Exec(ctx, `
+SELECT pg_terminate_backend(pid) FROM pg_locks
+WHERE locktype = 'advisory' AND classid::bigint = $1 AND objid::bigint = $2 AND objsubid = 2
+AND pid <> pg_backend_pid()`,
+		advisoryLockNamespace, processLeaseAdvisoryLock,
+	)
+	return errors.Wrap(err, "failed to terminate connection of lease holder")
+}
+
+// AcquireProcessLease acquires the lease on the database. If another live process holds it, it
+// returns ErrLeaseHeld, unless steal is true. In that case, the connection of the other process is
+// terminated, which makes it shut down the next time it renews its lease.
+func AcquireProcessLease(ctx context.Context, dbpool *pgxpool.Pool, steal bool) (*ProcessLease, error) {
+	conn, err := dbpool.Acquire(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to acquire database connection")
+	}
+	lease, err := acquireProcessLease(ctx, conn, steal)
+	if err != nil {
+		conn.Release()
+		return nil, err
+	}
+	lease.dbpool = dbpool
+	return lease, nil
+}
+
+func acquireProcessLease(ctx context.Context, conn *pgxpool.Conn, steal bool) (*ProcessLease, error) {
+	db := kprdb.New(conn)
+	locked, err := tryAdvisoryLock(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		holder, err := db.GetProcessLease(ctx)
+		if err != nil && err != pgx.ErrNoRows {
+			return nil, errors.Wrap(err, "failed to get lease holder from db")
+		}
+		if !steal {
+			return nil, errors.Wrapf(ErrLeaseHeld,
+				"held by pid %d on %s since %s, renewed at %s (use --steal-lease to take it over)",
+				holder.Pid, holder.Hostname, holder.AcquiredAt, holder.RenewedAt)
+		}
+		log.Warn().
+			Int32("pid", holder.Pid).
+			Str("hostname", holder.Hostname).
+			Msg("stealing database lease from other keyper process")
+		for i := 0; i < leaseStealAttempts && !locked; i++ {
+			if err := terminateLeaseHolder(ctx, conn); err != nil {
+				return nil, err
+			}
+			time.Sleep(leaseStealWait)
+			locked, err = tryAdvisoryLock(ctx, conn)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !locked {
+			return nil, errors.Wrap(ErrLeaseHeld, "failed to steal lease")
+		}
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get hostname")
+	}
+	lease := &ProcessLease{conn: conn, pid: int32(os.Getpid()), hostname: hostname}
+	lease.token, err = db.SetProcessLease(ctx, kprdb.SetProcessLeaseParams{Pid: lease.pid, Hostname: lease.hostname})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to store lease holder in db")
+	}
+	log.Info().
+		Int32("pid", lease.pid).
+		Str("hostname", hostname).
+		Int64("token", lease.token).
+		Msg("acquired database lease")
+	return lease, nil
+}
+
+// Hold periodically renews the lease. It returns an error if the lease has been lost, e.g. because
+// another process has stolen it or the connection to the database broke.
+func (l *ProcessLease) Hold(ctx context.Context) error {
+	ticker := time.NewTicker(leaseRenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		n, err := kprdb.New(l.conn).RenewProcessLease(ctx, l.token)
+		if err != nil {
+			return errors.Wrap(err, "lost database lease")
+		}
+		if n == 0 {
+			return errors.Wrap(ErrLeaseHeld, "lost database lease")
+		}
+	}
+}
+
+// BeginFunc runs f in a transaction, which fails with kprdb.ErrLeaseLost if another process has
+// acquired the lease in the meantime. The lease can't be acquired before the transaction ends.
+func (l *ProcessLease) BeginFunc(ctx context.Context, f func(pgx.Tx) error) error {
+	return l.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if err := kprdb.New(tx).CheckProcessLease(ctx, l.token); err != nil {
+			return err
+		}
+		return f(tx)
+	})
+}
+
+// Release releases the lease and returns the connection to the pool.
+func (l *ProcessLease) Release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := l.conn.Exec(ctx, "SELECT pg_advisory_unlock($1, $2)", advisoryLockNamespace, processLeaseAdvisoryLock)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to release database lease")
+	}
+	l.conn.Release()
+}
@@ -0,0 +1,45 @@
+package keyper
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+)
+
+func TestProcessLeaseIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	db, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	lease, err := AcquireProcessLease(ctx, dbpool, false)
+	assert.NilError(t, err)
+	holder, err := db.GetProcessLease(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, holder.Pid, int32(os.Getpid()))
+
+	_, err = AcquireProcessLease(ctx, dbpool, false)
+	assert.ErrorIs(t, err, ErrLeaseHeld)
+
+	stolen, err := AcquireProcessLease(ctx, dbpool, true)
+	assert.NilError(t, err)
+	assert.Equal(t, stolen.token, lease.token+1)
+	noop := func(pgx.Tx) error { return nil }
+	// the old process must not sign anything before it notices that it lost the lease
+	assert.ErrorIs(t, lease.BeginFunc(ctx, noop), kprdb.ErrLeaseLost)
+	assert.NilError(t, stolen.BeginFunc(ctx, noop))
+	lease.Release()
+
+	stolen.Release()
+	lease, err = AcquireProcessLease(ctx, dbpool, false)
+	assert.NilError(t, err)
+	lease.Release()
+}
@@ -0,0 +1,96 @@
+package mempool
+
+import (
+	"io"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+// The actions taken for suspicious transactions.
+const (
+	ActionAlert   = "alert"
+	ActionTrigger = "trigger"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+type Config struct {
+	TargetURL        string            `comment:"JSON RPC endpoint of the target chain whose public mempool is observed. It must support pending transaction filters. If it's empty, the mempool is not observed"`
+	Inboxes          []string          `comment:"Addresses of the contracts encrypted Shutter transactions are sent to on the target chain"`
+	PollInterval     *enctime.Duration `comment:"How often new pending transactions and blocks are fetched"`
+	CensorshipBlocks uint64            `comment:"Number of blocks after which a pending transaction paying at least the base fee is considered censored"`
+	Action           string            `comment:"What to do about censored or front-run transactions: alert (notify the operator) or trigger (additionally request a decryption trigger for the transaction hash from the collator)"`
+	TriggerURL       string            `comment:"URL of the collator decryption triggers are requested from. The address of this keyper must be one of the collator's ExternalTriggers.Requesters. Only used by the trigger action"`
+}
+
+func (c *Config) Init() {
+	c.PollInterval = &enctime.Duration{}
+}
+
+func (c *Config) Name() string {
+	return "mempool"
+}
+
+// Enabled reports whether the mempool is observed.
+func (c *Config) Enabled() bool {
+	return c.TargetURL != ""
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if len(c.Inboxes) == 0 {
+		return errors.New("mempool Inboxes must not be empty if the mempool is observed")
+	}
+	for _, inbox := range c.Inboxes {
+		if !common.IsHexAddress(inbox) {
+			return errors.Errorf("invalid mempool inbox address %q", inbox)
+		}
+	}
+	if c.PollInterval.Duration <= 0 {
+		return errors.New("mempool PollInterval must be positive")
+	}
+	if c.CensorshipBlocks == 0 {
+		return errors.New("mempool CensorshipBlocks must be positive")
+	}
+	switch c.Action {
+	case ActionAlert:
+	case ActionTrigger:
+		if c.TriggerURL == "" {
+			return errors.Errorf("mempool Action %s requires a TriggerURL", c.Action)
+		}
+	default:
+		return errors.Errorf("unknown mempool Action %q", c.Action)
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.TargetURL = ""
+	c.Inboxes = []string{}
+	c.PollInterval = &enctime.Duration{Duration: 2 * time.Second}
+	c.CensorshipBlocks = 10
+	c.Action = ActionAlert
+	c.TriggerURL = ""
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,342 @@
+// Package mempool implements an optional observer of the public mempool of the target chain
+// encrypted Shutter transactions are submitted to. It records every encrypted transaction it sees
+// pending, i.e. every transaction sent to one of the configured inbox contracts, and follows it
+// until it is included in a block. The findings are heuristics, which are recorded in the database
+// for later analysis:
+//   - A transaction is considered censored if it stays pending for CensorshipBlocks blocks while
+//     its fee cap covers the base fee of the current block.
+//   - A transaction is considered front-run if a transaction of another sender with the same
+//     calldata, i.e. a copy of its encrypted payload, is included before it.
+//   - A pending transaction whose nonce has been used by another transaction of its sender is
+//     considered replaced.
+//
+// Censored and front-run transactions are reported to the operator and, with the trigger action, a
+// decryption trigger is requested for them, see TriggerRequester.
+package mempool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/alert"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// The kinds of findings.
+const (
+	FindingCensored = "censored"
+	FindingFrontRun = "front-run"
+	FindingReplaced = "replaced"
+)
+
+// maxCatchUpBlocks is the maximum number of blocks processed in a single poll. If the observer
+// falls further behind, e.g. after a connection loss, the blocks in between are skipped.
+const maxCatchUpBlocks = 100
+
+// censored reports whether the observed transaction, which is still pending at head, is censored.
+func censored(obs kprdb.MempoolObservation, head *types.Header, censorshipBlocks uint64) bool {
+	if head.Number.Uint64() < uint64(obs.FirstSeenBlockNumber)+censorshipBlocks {
+		return false
+	}
+	if head.BaseFee == nil {
+		return true
+	}
+	return shdb.DecodeBigint(obs.MaxFeePerGas).Cmp(head.BaseFee) >= 0
+}
+
+// frontRun returns the pending observed transactions front-run by the transaction of sender with
+// hash txHash that is being included. The observations all carry the same payload and are ordered
+// by the time they were first seen, so the payload belongs to the sender of the first one. Only
+// that sender can be front-run, and including one of its own transactions is a resubmission.
+func frontRun(
+	observations []kprdb.MempoolObservation, txHash common.Hash, sender common.Address,
+) []kprdb.MempoolObservation {
+	victims := []kprdb.MempoolObservation{}
+	if len(observations) == 0 || observations[0].Sender == shdb.EncodeAddress(sender) {
+		return victims
+	}
+	owner := observations[0].Sender
+	for _, obs := range observations {
+		if bytes.Equal(obs.TxHash, txHash.Bytes()) || obs.Sender != owner {
+			continue
+		}
+		if obs.IncludedBlockNumber != 0 || obs.Finding != "" {
+			continue
+		}
+		victims = append(victims, obs)
+	}
+	return victims
+}
+
+// target is the connection to the target chain and the progress of following it.
+type target struct {
+	rpc       *rpc.Client
+	client    *ethclient.Client
+	signer    types.Signer
+	filterID  string
+	nextBlock uint64
+}
+
+// Observer follows the encrypted Shutter transactions on the target chain.
+type Observer struct {
+	config   *Config
+	dbpool   *pgxpool.Pool
+	notifier alert.Notifier
+	trigger  *TriggerRequester
+	inboxes  map[common.Address]bool
+}
+
+// NewObserver creates an observer for a validated config. trigger is nil unless decryption
+// triggers should be requested for suspicious transactions.
+func NewObserver(
+	config *Config, dbpool *pgxpool.Pool, notifier alert.Notifier, trigger *TriggerRequester,
+) *Observer {
+	inboxes := make(map[common.Address]bool)
+	for _, inbox := range config.Inboxes {
+		inboxes[common.HexToAddress(inbox)] = true
+	}
+	return &Observer{
+		config:   config,
+		dbpool:   dbpool,
+		notifier: notifier,
+		trigger:  trigger,
+		inboxes:  inboxes,
+	}
+}
+
+// Run follows the target chain until the context is canceled.
+func (o *Observer) Run(ctx context.Context) error {
+	rpcClient, err := rpc.DialContext(ctx, o.config.TargetURL)
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to target chain at %s", o.config.TargetURL)
+	}
+	defer rpcClient.Close()
+	client := ethclient.NewClient(rpcClient)
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get chain id of target chain")
+	}
+	t := &target{rpc: rpcClient, client: client, signer: types.LatestSignerForChainID(chainID)}
+	log.Info().Str("url", o.config.TargetURL).Int("inboxes", len(o.inboxes)).Msg("observing mempool of target chain")
+
+	ticker := time.NewTicker(o.config.PollInterval.Duration)
+	defer ticker.Stop()
+	for {
+		if err := o.poll(ctx, t); err != nil {
+			log.Warn().Err(err).Msg("failed to observe mempool of target chain")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Observer) poll(ctx context.Context, t *target) error {
+	head, err := t.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get head of target chain")
+	}
+	headNumber := head.Number.Uint64()
+	if err := o.observePending(ctx, t, headNumber); err != nil {
+		return err
+	}
+
+	switch {
+	case t.nextBlock == 0:
+		t.nextBlock = headNumber
+	case headNumber >= t.nextBlock+maxCatchUpBlocks:
+		log.Warn().Uint64("from", t.nextBlock).Uint64("to", headNumber).
+			Msg("skipping blocks of target chain the mempool observer fell behind on")
+		t.nextBlock = headNumber
+	}
+	for ; t.nextBlock <= headNumber; t.nextBlock++ {
+		block, err := t.client.BlockByNumber(ctx, new(big.Int).SetUint64(t.nextBlock))
+		if err != nil {
+			return errors.Wrapf(err, "failed to get block %d of target chain", t.nextBlock)
+		}
+		if err := o.observeBlock(ctx, t, block); err != nil {
+			return err
+		}
+	}
+	return o.checkCensorship(ctx, t, head)
+}
+
+func (o *Observer) isShutterTransaction(tx *types.Transaction) bool {
+	return tx.To() != nil && o.inboxes[*tx.To()]
+}
+
+// observePending records the encrypted transactions that have been added to the mempool since the
+// last poll.
+func (o *Observer) observePending(ctx context.Context, t *target, headNumber uint64) error {
+	if t.filterID == "" {
+		if err := t.rpc.CallContext(ctx, &t.filterID, "eth_newPendingTransactionFilter"); err != nil {
+			return errors.Wrap(err, "failed to install pending transaction filter")
+		}
+	}
+	var hashes []common.Hash
+	if err := t.rpc.CallContext(ctx, &hashes, "eth_getFilterChanges", t.filterID); err != nil {
+		// filters expire if they aren't polled for a while, so a new one is installed next time
+		t.filterID = ""
+		return errors.Wrap(err, "failed to get pending transactions")
+	}
+
+	db := kprdb.New(o.dbpool)
+	for _, hash := range hashes {
+		tx, isPending, err := t.client.TransactionByHash(ctx, hash)
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to get pending transaction %s", hash.Hex())
+		}
+		if !isPending || !o.isShutterTransaction(tx) {
+			continue
+		}
+		sender, err := types.Sender(t.signer, tx)
+		if err != nil {
+			log.Debug().Err(err).Str("tx-hash", hash.Hex()).Msg("ignoring transaction with invalid signature")
+			continue
+		}
+		err = db.InsertMempoolObservation(ctx, kprdb.InsertMempoolObservationParams{
+			TxHash:               hash.Bytes(),
+			Sender:               shdb.EncodeAddress(sender),
+			Inbox:                shdb.EncodeAddress(*tx.To()),
+			Nonce:                int64(tx.Nonce()),
+			PayloadHash:          ethcrypto.Keccak256(tx.Data()),
+			MaxFeePerGas:         shdb.EncodeBigint(tx.GasFeeCap()),
+			FirstSeenBlockNumber: int64(headNumber),
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to store mempool observation in db")
+		}
+		metricsObservedTransactions.Inc()
+		log.Debug().Str("tx-hash", hash.Hex()).Str("sender", sender.Hex()).
+			Msg("observed encrypted transaction in mempool")
+	}
+	return nil
+}
+
+// observeBlock marks the encrypted transactions included in the block and checks if they are
+// copies of transactions observed before.
+func (o *Observer) observeBlock(ctx context.Context, t *target, block *types.Block) error {
+	db := kprdb.New(o.dbpool)
+	for _, tx := range block.Transactions() {
+		if !o.isShutterTransaction(tx) {
+			continue
+		}
+		sender, err := types.Sender(t.signer, tx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to recover sender of transaction %s", tx.Hash().Hex())
+		}
+		observations, err := db.GetMempoolObservationsByPayloadHash(ctx, ethcrypto.Keccak256(tx.Data()))
+		if err != nil {
+			return errors.Wrap(err, "failed to get mempool observations from db")
+		}
+		for _, victim := range frontRun(observations, tx.Hash(), sender) {
+			details := fmt.Sprintf("payload copied by transaction %s of %s included in block %d",
+				tx.Hash().Hex(), sender.Hex(), block.NumberU64())
+			if err := o.record(ctx, db, victim, FindingFrontRun, details); err != nil {
+				return err
+			}
+		}
+		err = db.SetMempoolObservationIncluded(ctx, kprdb.SetMempoolObservationIncludedParams{
+			TxHash:              tx.Hash().Bytes(),
+			IncludedBlockNumber: int64(block.NumberU64()),
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to store inclusion of mempool observation in db")
+		}
+	}
+	return nil
+}
+
+// checkCensorship records the pending transactions that have been censored or replaced.
+func (o *Observer) checkCensorship(ctx context.Context, t *target, head *types.Header) error {
+	db := kprdb.New(o.dbpool)
+	observations, err := db.GetPendingMempoolObservations(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get pending mempool observations from db")
+	}
+	for _, obs := range observations {
+		if !censored(obs, head, o.config.CensorshipBlocks) {
+			continue
+		}
+		sender, err := shdb.DecodeAddress(obs.Sender)
+		if err != nil {
+			return err
+		}
+		nonce, err := t.client.NonceAt(ctx, sender, head.Number)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get nonce of %s", sender.Hex())
+		}
+		kind := FindingCensored
+		details := fmt.Sprintf("pending since block %d", obs.FirstSeenBlockNumber)
+		if nonce > uint64(obs.Nonce) {
+			kind = FindingReplaced
+			details = fmt.Sprintf("nonce %d used by another transaction or in a skipped block", obs.Nonce)
+		}
+		if err := o.record(ctx, db, obs, kind, details); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record stores a finding and reports it unless the transaction has only been replaced.
+func (o *Observer) record(
+	ctx context.Context, db *kprdb.Queries, obs kprdb.MempoolObservation, kind string, details string,
+) error {
+	err := db.SetMempoolObservationFinding(ctx, kprdb.SetMempoolObservationFindingParams{
+		TxHash:         obs.TxHash,
+		Finding:        kind,
+		FindingDetails: details,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to store mempool finding in db")
+	}
+	metricsFindings.WithLabelValues(kind).Inc()
+	txHash := common.BytesToHash(obs.TxHash)
+	if kind == FindingReplaced {
+		log.Info().Str("tx-hash", txHash.Hex()).Str("details", details).Msg("encrypted transaction replaced")
+		return nil
+	}
+
+	err = o.notifier.Notify(ctx, alert.Alert{
+		Severity: alert.SeverityWarning,
+		Summary:  fmt.Sprintf("encrypted transaction appears to be %s on the target chain", kind),
+		Details: map[string]string{
+			"tx-hash":          txHash.Hex(),
+			"sender":           obs.Sender,
+			"inbox":            obs.Inbox,
+			"first-seen-block": strconv.FormatInt(obs.FirstSeenBlockNumber, 10),
+			"finding":          kind,
+			"finding-details":  details,
+		},
+		Time: time.Now(),
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("tx-hash", txHash.Hex()).Msg("failed to notify about mempool finding")
+	}
+	if o.trigger != nil {
+		if err := o.trigger.Request(ctx, txHash); err != nil {
+			log.Warn().Err(err).Str("tx-hash", txHash.Hex()).Msg("failed to request decryption trigger")
+		}
+	}
+	return nil
+}
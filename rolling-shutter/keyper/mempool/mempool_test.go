@@ -0,0 +1,99 @@
+package mempool
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/externaltrigger"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+func TestValidateConfig(t *testing.T) {
+	config := NewConfig()
+	assert.NilError(t, config.SetDefaultValues())
+	assert.NilError(t, config.Validate())
+
+	config.TargetURL = "http://localhost:8545"
+	assert.ErrorContains(t, config.Validate(), "Inboxes")
+	config.Inboxes = []string{"0x0000000000000000000000000000000000000001"}
+	assert.NilError(t, config.Validate())
+	config.Action = ActionTrigger
+	assert.ErrorContains(t, config.Validate(), "TriggerURL")
+	config.TriggerURL = "http://localhost:3000"
+	assert.NilError(t, config.Validate())
+	config.Action = "ignore"
+	assert.ErrorContains(t, config.Validate(), "unknown mempool Action")
+}
+
+func TestCensored(t *testing.T) {
+	obs := kprdb.MempoolObservation{FirstSeenBlockNumber: 100, MaxFeePerGas: shdb.EncodeBigint(big.NewInt(20))}
+	header := func(number int64, baseFee int64) *types.Header {
+		return &types.Header{Number: big.NewInt(number), BaseFee: big.NewInt(baseFee)}
+	}
+
+	assert.Check(t, !censored(obs, header(109, 10), 10))
+	assert.Check(t, censored(obs, header(110, 10), 10))
+	assert.Check(t, censored(obs, header(110, 20), 10))
+	// the transaction doesn't pay enough to be included
+	assert.Check(t, !censored(obs, header(110, 21), 10))
+	assert.Check(t, censored(obs, &types.Header{Number: big.NewInt(110)}, 10))
+}
+
+func TestFrontRun(t *testing.T) {
+	victim := common.HexToAddress("0x01")
+	attacker := common.HexToAddress("0x02")
+	copyHash := common.HexToHash("0xc0")
+	observations := []kprdb.MempoolObservation{
+		{TxHash: common.HexToHash("0xa1").Bytes(), Sender: shdb.EncodeAddress(victim)},
+		{TxHash: common.HexToHash("0xa2").Bytes(), Sender: shdb.EncodeAddress(victim), IncludedBlockNumber: 5},
+		{TxHash: copyHash.Bytes(), Sender: shdb.EncodeAddress(attacker)},
+	}
+
+	victims := frontRun(observations, copyHash, attacker)
+	assert.Equal(t, len(victims), 1)
+	assert.DeepEqual(t, victims[0].TxHash, common.HexToHash("0xa1").Bytes())
+	// resubmissions by the sender itself are not front-running
+	assert.Equal(t, len(frontRun(observations, common.HexToHash("0xa3"), victim)), 0)
+}
+
+func TestTriggerRequester(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	config := externaltrigger.NewConfig()
+	config.Requesters = []string{ethcrypto.PubkeyToAddress(key.PublicKey).Hex()}
+	policy := externaltrigger.NewPolicy(config, 42)
+	txHash := common.HexToHash("0xa1")
+
+	var epochIDs [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, "/external-triggers")
+		var req externaltrigger.SignedRequest
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&req))
+		_, epochID, err := policy.Check(&req, time.Now())
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		epochIDs = append(epochIDs, epochID.Bytes())
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	assert.NilError(t, NewTriggerRequester(server.URL+"/", 42, key).Request(ctx, txHash))
+	assert.Equal(t, len(epochIDs), 1)
+	assert.DeepEqual(t, epochIDs[0], externaltrigger.EpochID(ethcrypto.PubkeyToAddress(key.PublicKey), txHash.Bytes()).Bytes())
+
+	err = NewTriggerRequester(server.URL, 43, key).Request(ctx, txHash)
+	assert.ErrorContains(t, err, "status 403")
+}
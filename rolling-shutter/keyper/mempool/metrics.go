@@ -0,0 +1,27 @@
+package mempool
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsObservedTransactions = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "mempool",
+		Name:      "observed_transactions_total",
+		Help:      "Number of encrypted Shutter transactions seen pending in the mempool of the target chain",
+	},
+)
+
+var metricsFindings = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "mempool",
+		Name:      "findings_total",
+		Help:      "Number of encrypted Shutter transactions that appear to have been censored, front-run or replaced",
+	},
+	[]string{"finding"},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsObservedTransactions)
+	prometheus.MustRegister(metricsFindings)
+}
@@ -0,0 +1,68 @@
+package mempool
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/externaltrigger"
+)
+
+const triggerRequestTimeout = 10 * time.Second
+
+// TriggerRequester requests decryption triggers for suspicious transactions from the external
+// trigger webhook of a collator. The identity is the transaction hash, so the triggered epoch id is
+// externaltrigger.EpochID(keyper address, transaction hash), which contracts on the target chain
+// can use to learn that the keypers consider the transaction censored or front-run.
+type TriggerRequester struct {
+	url        string
+	client     *http.Client
+	instanceID uint64
+	key        *ecdsa.PrivateKey
+}
+
+func NewTriggerRequester(collatorURL string, instanceID uint64, key *ecdsa.PrivateKey) *TriggerRequester {
+	return &TriggerRequester{
+		url:        strings.TrimSuffix(collatorURL, "/") + "/external-triggers",
+		client:     &http.Client{Timeout: triggerRequestTimeout},
+		instanceID: instanceID,
+		key:        key,
+	}
+}
+
+// Request requests the decryption trigger for the transaction with the given hash.
+func (r *TriggerRequester) Request(ctx context.Context, txHash common.Hash) error {
+	req, err := externaltrigger.Sign(&externaltrigger.Request{
+		InstanceID: r.instanceID,
+		Identity:   txHash.Bytes(),
+		NotBefore:  time.Now().Unix(),
+	}, r.key)
+	if err != nil {
+		return errors.Wrap(err, "failed to sign trigger request")
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	res, err := r.client.Do(httpReq)
+	if err != nil {
+		return errors.Wrap(err, "failed to request decryption trigger")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("collator rejected trigger request with status %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package keyper
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/metadb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/metricsnapshot"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/storagemonitor"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+)
+
+// Names of the metrics persisted by the metrics snapshotter.
+const (
+	MetricSyncLag      = "sync-lag-blocks"
+	MetricPeers        = "p2p-peers"
+	MetricEpochsBehind = "epochs-behind"
+)
+
+// MetricDBWriteLatency is the name of the metric measuring how long a small write to the database
+// takes. It is only reported in the status served by the HTTP API, as sampling it writes to the
+// database.
+const MetricDBWriteLatency = "db-write-latency-seconds"
+
+// dbWriteProbeKey is the key of the meta_inf entry written to measure the database write latency.
+const dbWriteProbeKey = "write probe"
+
+// NewMetricsSnapshotter returns a service that persists the number of blocks the chain observer
+// lags behind the head of the chain, the number of connected peers and the number of epochs still
+// waiting for their decryption key every interval, keeping the given number of snapshots. No
+// snapshots are taken while the storage monitor stops non-essential writes.
+func NewMetricsSnapshotter(
+	dbpool *pgxpool.Pool,
+	l1Client *ethclient.Client,
+	p2pHandler *p2p.P2PHandler,
+	storage *storagemonitor.Monitor,
+	interval time.Duration,
+	keep uint64,
+) service.Service {
+	snapshotter := metricsnapshot.New(dbpool, interval, keep)
+	snapshotter.PauseUnless(storage.AllowNonEssentialWrites)
+	for name, sampler := range keyMetrics(dbpool, l1Client, p2pHandler) {
+		snapshotter.Add(name, sampler)
+	}
+	return service.ServiceFn{Fn: snapshotter.Run}
+}
+
+// StatusMetrics returns the samplers of the metrics reported in the status served by the HTTP API.
+// The database write latency isn't measured while the storage monitor stops non-essential writes.
+func StatusMetrics(
+	dbpool *pgxpool.Pool,
+	l1Client *ethclient.Client,
+	p2pHandler *p2p.P2PHandler,
+	storage *storagemonitor.Monitor,
+) map[string]metricsnapshot.Sampler {
+	samplers := keyMetrics(dbpool, l1Client, p2pHandler)
+	samplers[MetricDBWriteLatency] = func(ctx context.Context) (float64, error) {
+		if !storage.AllowNonEssentialWrites() {
+			return 0, errors.New("non-essential writes are paused")
+		}
+		start := time.Now()
+		err := metadb.New(dbpool).SetMeta(ctx, metadb.SetMetaParams{
+			Key:   dbWriteProbeKey,
+			Value: start.UTC().Format(time.RFC3339Nano),
+		})
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to write to db")
+		}
+		return time.Since(start).Seconds(), nil
+	}
+	return samplers
+}
+
+func keyMetrics(
+	dbpool *pgxpool.Pool,
+	l1Client *ethclient.Client,
+	p2pHandler *p2p.P2PHandler,
+) map[string]metricsnapshot.Sampler {
+	return map[string]metricsnapshot.Sampler{
+		MetricSyncLag: func(ctx context.Context) (float64, error) {
+			head, err := l1Client.BlockNumber(ctx)
+			if err != nil {
+				return 0, errors.Wrap(err, "failed to get current block number")
+			}
+			next, err := chainobsdb.New(dbpool).GetNextBlockNumber(ctx)
+			if err != nil {
+				return 0, errors.Wrap(err, "failed to get sync progress from db")
+			}
+			lag := int64(head) - int64(next) + 1
+			if lag < 0 {
+				lag = 0
+			}
+			return float64(lag), nil
+		},
+		MetricPeers: func(context.Context) (float64, error) {
+			return float64(p2pHandler.P2P.PeerCount()), nil
+		},
+		MetricEpochsBehind: func(ctx context.Context) (float64, error) {
+			n, err := kprdb.New(dbpool).CountPendingEpochs(ctx)
+			return float64(n), err
+		},
+	}
+}
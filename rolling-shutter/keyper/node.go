@@ -0,0 +1,461 @@
+package keyper
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/tendermint/tendermint/rpc/client"
+	tmhttp "github.com/tendermint/tendermint/rpc/client/http"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/chainobserver"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/shversion"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/contract/deployment"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/bonds"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/checkpoint"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/dkgparams"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/epochkghandler"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/escrow"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprapi"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/mempool"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/pause"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/quorum"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/revelation"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/shareintegrity"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/sla"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/triggerpolicy"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/upgrade"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/alert"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/beaconapi"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/broker"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/clockcheck"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/featureflag"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/jobqueue"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/metricsserver"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/plugin"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/storagemonitor"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/triggeroffset"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p/attestation"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p/provenance"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// Node holds the parts of a keyper that don't depend on what it generates keys for. They are
+// shared by the keyper and the snapshot keyper, which only add their own handling of shuttermint,
+// eon keys and decryption triggers on top.
+type Node struct {
+	Config *Config
+	// Name identifies the kind of keyper to plugins and in node attestations.
+	Name string
+
+	DBPool            *pgxpool.Pool
+	ShuttermintClient client.Client
+	L1Client          *ethclient.Client
+	Contracts         *deployment.Contracts
+	Beacon            *beaconapi.Client
+	P2P               *p2p.P2PHandler
+	MetricsServer     *metricsserver.MetricsServer
+	Lease             *ProcessLease
+	Features          *featureflag.Set
+	Signing           epochkghandler.Signing
+	Bus               *broker.Bus
+	Jobs              *jobqueue.Queue
+	Alerts            alert.Notifier
+	SelfAudit         *epochkghandler.SelfAudit
+	KeyIngester       *epochkghandler.KeyIngester
+	Storage           *storagemonitor.Monitor
+	Clock             *clockcheck.Monitor
+	Escrow            *escrow.Writer
+	Plugins           *plugin.Host
+	TriggerPolicy     *triggerpolicy.Policy
+	PublicationDelay  *epochkghandler.PublicationDelay
+	Pause             *pause.Controller
+
+	// Set by keypers that support them, nil otherwise.
+	Checkpoints *checkpoint.Writer
+	SLA         *sla.Tracker
+}
+
+// NodeSpec describes how a kind of keyper uses the shared components.
+type NodeSpec struct {
+	// Name identifies the kind of keyper to plugins and in node attestations.
+	Name string
+	// ContractsURL is the URL of the node the contracts are read from.
+	ContractsURL string
+	// Checkpoints and SLA enable writing checkpoints and tracking the SLA, respectively.
+	Checkpoints bool
+	SLA         bool
+}
+
+// StartNode connects to the database, the chains and the p2p network and sets up the shared
+// components of a keyper.
+func StartNode(
+	ctx context.Context, runner service.Runner, config *Config, options Options, spec NodeSpec,
+) (*Node, error) {
+	dbpool, err := pgxpool.Connect(ctx, config.DatabaseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to database")
+	}
+	runner.Defer(dbpool.Close)
+	shdb.AddConnectionInfo(log.Info(), dbpool).Msg("connected to database")
+
+	l1Client, err := ethclient.Dial(config.Ethereum.EthereumURL)
+	if err != nil {
+		return nil, err
+	}
+	contractsClient := l1Client
+	if spec.ContractsURL != config.Ethereum.EthereumURL {
+		contractsClient, err = ethclient.Dial(spec.ContractsURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	contracts, err := deployment.NewContracts(contractsClient, config.Ethereum.DeploymentDir)
+	if err != nil {
+		return nil, err
+	}
+	if !config.Ethereum.SkipDeploymentVerification {
+		if err := contracts.Verify(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	err = kprdb.ValidateKeyperDB(ctx, dbpool)
+	if err != nil {
+		return nil, err
+	}
+	err = kprdb.LoadMigrations(ctx, dbpool)
+	if err != nil {
+		return nil, err
+	}
+	if err := options.VerifyStealLease(config); err != nil {
+		return nil, err
+	}
+	lease, err := AcquireProcessLease(ctx, dbpool, options.StealLease)
+	if err != nil {
+		return nil, err
+	}
+	runner.Defer(lease.Release)
+	err = LinkConfigToDB(ctx, config, dbpool)
+	if err != nil {
+		return nil, err
+	}
+	err = PopulateParameters(ctx, config, dbpool)
+	if err != nil {
+		return nil, err
+	}
+	err = shareintegrity.Check(ctx, dbpool, alert.New(config.Alerting))
+	if err != nil {
+		return nil, err
+	}
+	shuttermintClient, err := tmhttp.New(config.Shuttermint.ShuttermintURL, "/websocket")
+	if err != nil {
+		return nil, err
+	}
+	p2pHandler, err := p2p.New(config.P2P, config.InstanceID)
+	if err != nil {
+		return nil, err
+	}
+	features, err := featureflag.New(config.Name(), config.Features, Features...)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Node{
+		Config:            config,
+		Name:              spec.Name,
+		DBPool:            dbpool,
+		ShuttermintClient: shuttermintClient,
+		L1Client:          l1Client,
+		Contracts:         contracts,
+		P2P:               p2pHandler,
+		Lease:             lease,
+		Features:          features,
+		Bus:               broker.New(),
+		Jobs:              jobqueue.New(dbpool, 1),
+	}
+	if config.Metrics.Enabled {
+		epochkghandler.InitMetrics()
+		quorum.InitMetrics()
+		bonds.InitMetrics()
+		storagemonitor.InitMetrics()
+		clockcheck.InitMetrics()
+		upgrade.InitMetrics()
+		featureflag.InitMetrics()
+		chainobserver.InitMetrics()
+		broker.InitMetrics()
+		attestation.InitMetrics()
+		p2p.InitMetrics()
+		jobqueue.InitMetrics()
+		pause.InitMetrics()
+		mempool.InitMetrics()
+		plugin.InitMetrics()
+		if spec.Checkpoints {
+			checkpoint.InitMetrics()
+		}
+		if spec.SLA {
+			sla.InitMetrics()
+		}
+		n.MetricsServer = metricsserver.New(config.Metrics)
+	}
+	if url := config.Ethereum.BeaconAPIURL; url != "" {
+		n.Beacon = beaconapi.New(url, nil)
+	}
+	n.Alerts = alert.NewQueued(config.Alerting, dbpool, n.Jobs)
+	n.SelfAudit = epochkghandler.NewSelfAudit(n.Alerts)
+	if config.Storage.Interval.Duration > 0 {
+		n.Storage = storagemonitor.New(config.Storage, dbpool, n.Alerts)
+	}
+	if config.Clock.Enabled() {
+		n.Clock = clockcheck.New(config.Clock, n.Alerts)
+		if err := n.Clock.Check(ctx); err != nil {
+			log.Warn().Err(err).Msg("failed to check local clock at startup")
+		}
+	}
+	if config.Escrow.Enabled() {
+		n.Escrow, err = escrow.NewWriter(config.Escrow, dbpool, config.InstanceID, config.GetAddress())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if spec.Checkpoints && config.Checkpoint.Enabled() {
+		n.Checkpoints = checkpoint.NewWriter(
+			config.Checkpoint, dbpool, config.InstanceID, config.Ethereum.PrivateKey.Key,
+		)
+	}
+	if config.Plugins.Enabled() {
+		n.Plugins = plugin.NewHost(config.Plugins, spec.Name)
+	}
+	n.TriggerPolicy, err = triggerpolicy.NewPolicy(config.TriggerPolicy)
+	if err != nil {
+		return nil, err
+	}
+	n.PublicationDelay = epochkghandler.NewPublicationDelay(
+		config.PublicationDelay.Duration, l1Client, dbpool, n.Clock,
+	)
+	n.Jobs.Register(
+		epochkghandler.DelayedTriggerJobKind,
+		epochkghandler.DelayedTriggerJobHandler(config, dbpool, lease, n.SelfAudit, p2pHandler),
+	)
+	if spec.SLA {
+		// the tracker subscribes to the keys before the ingester publishes any
+		n.SLA = sla.NewTracker(config.SLA, dbpool, n.Bus, config.InstanceID, config.Ethereum.PrivateKey.Key)
+	}
+	n.KeyIngester = epochkghandler.NewKeyIngester(dbpool, n.Bus)
+	n.Signing = NewEonPublicKeySigning(contracts, config.InstanceID, features)
+	n.Pause = pause.NewController(dbpool, lease, n.Signing.Domain, config.Ethereum.PrivateKey.Key, p2pHandler)
+	return n, nil
+}
+
+// MessageHandlers returns the p2p message handlers of epoch key generation shared by all keypers.
+// verifier may be nil to verify decryption key shares one by one.
+func (n *Node) MessageHandlers(
+	epochIDs *epochkghandler.EpochIDValidator,
+	verifier *epochkghandler.ShareVerifier,
+	offset triggeroffset.Offset,
+) []p2p.MessageHandler {
+	return []p2p.MessageHandler{
+		epochkghandler.NewDecryptionKeyHandler(n.Config, n.DBPool, n.KeyIngester),
+		epochkghandler.NewDecryptionKeyShareHandler(n.Config, n.DBPool, n.KeyIngester, verifier, n.SLA),
+		epochkghandler.NewDecryptionTriggerHandler(
+			n.Config, n.DBPool, n.Lease, epochIDs, n.SelfAudit, n.TriggerPolicy, n.PublicationDelay, n.Clock,
+			n.SLA, offset,
+		),
+		epochkghandler.NewDecryptionTriggerBatchHandler(
+			n.Config, n.DBPool, n.Lease, epochIDs, n.SelfAudit, n.TriggerPolicy, n.PublicationDelay, n.Clock,
+			n.SLA, offset,
+		),
+		epochkghandler.NewEonPublicKeyHandler(n.Config, n.DBPool, n.Signing),
+		pause.NewHandler(n.DBPool, n.Signing.Domain),
+	}
+}
+
+// AttestationHandler returns the handler of node attestations, wrapped to record provenance.
+func (n *Node) AttestationHandler() []p2p.MessageHandler {
+	return provenance.Wrap(
+		n.DBPool, n.Storage, attestation.NewHandler(n.Config.InstanceID, n.DBPool, shversion.Version()),
+	)
+}
+
+// BroadcastEonPublicKey signs the eon public key generated by this keyper, counts it as its own
+// vote and broadcasts it to the other keypers. A key for an eon this keyper voted for another key in
+// already is not signed.
+func (n *Node) BroadcastEonPublicKey(ctx context.Context, msg *p2pmsg.EonPublicKey) error {
+	err := n.Lease.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if err := n.Signing.Sign(ctx, kprdb.New(tx), msg, n.Config.Ethereum.PrivateKey.Key); err != nil {
+			return errors.Wrap(err, "error while signing EonPublicKey")
+		}
+		// gossip messages aren't delivered to their sender, so we count our own vote here
+		return epochkghandler.StoreEonPublicKeyVote(ctx, tx, msg, n.Signing)
+	})
+	if errors.Is(err, kprdb.ErrConflictingVote) {
+		log.Error().Err(err).Uint64("eon", msg.Eon).Msg("not signing EonPublicKey")
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "error while storing own EonPublicKey vote")
+	}
+
+	err = n.P2P.SendMessage(ctx, msg)
+	if err != nil {
+		return errors.Wrap(err, "error while broadcasting EonPublicKey")
+	}
+	return broker.Publish(ctx, n.Bus, broker.EonKeyGeneratedTopic, broker.EonKeyGenerated{
+		Eon:                   msg.Eon,
+		KeyperConfigIndex:     msg.KeyperConfigIndex,
+		ActivationBlockNumber: msg.ActivationBlock,
+		PublicKey:             msg.PublicKey,
+	})
+}
+
+// Services returns the services of the shared components.
+func (n *Node) Services() []service.Service {
+	config := n.Config
+	services := []service.Service{
+		service.ServiceFn{Fn: n.Lease.Hold},
+		n.P2P,
+		service.ServiceFn{Fn: n.HandleContractEvents},
+		service.ServiceFn{Fn: n.Jobs.Run},
+		service.ServiceFn{Fn: attestation.NewAnnouncer(
+			config.InstanceID, n.Name, shversion.Version(), config.Ethereum.PrivateKey.Key, n.P2P,
+		).Run},
+		service.ServiceFn{Fn: chainobserver.NewPendingConfigMonitor(
+			n.DBPool, n.L1Client, config.GetAddress(), n.Alerts,
+			config.ActivationAlertLeadTime.Duration, n.Bus,
+		).Run},
+	}
+
+	if config.HTTPEnabled {
+		services = append(services, kprapi.NewHTTPService(
+			n.DBPool, n.Lease, config, n.P2P, n.Features, n.SelfAudit, n.Pause,
+			revelation.NewProver(n.DBPool, n.Signing.Domain, config.Ethereum.PrivateKey.Key),
+			n.Checkpoints,
+			n.SLA,
+			StatusMetrics(n.DBPool, n.L1Client, n.P2P, n.Storage),
+		))
+	}
+	if n.MetricsServer != nil {
+		services = append(services, n.MetricsServer)
+	}
+	if config.MetricsSnapshotInterval.Duration > 0 {
+		services = append(services, NewMetricsSnapshotter(
+			n.DBPool, n.L1Client, n.P2P, n.Storage,
+			config.MetricsSnapshotInterval.Duration, config.MetricsSnapshotsKept,
+		))
+	}
+	if n.Storage != nil {
+		services = append(services, service.ServiceFn{Fn: n.Storage.Run})
+	}
+	if n.Clock != nil {
+		services = append(services, service.ServiceFn{Fn: n.Clock.Run})
+	}
+	if n.Escrow != nil {
+		services = append(services, service.ServiceFn{Fn: n.Escrow.Run})
+	}
+	if n.Checkpoints != nil {
+		services = append(services, service.ServiceFn{Fn: n.Checkpoints.Run})
+	}
+	if n.SLA != nil {
+		services = append(services, service.ServiceFn{Fn: n.SLA.Run})
+	}
+	if n.Plugins != nil {
+		services = append(services,
+			service.ServiceFn{Fn: n.Plugins.Run},
+			service.ServiceFn{Fn: func(ctx context.Context) error { return n.Plugins.Forward(ctx, n.Bus) }},
+		)
+	}
+	if config.AuditLogRetention.Duration > 0 {
+		services = append(services, NewAuditLogPruner(n.DBPool, config.AuditLogRetention.Duration))
+	}
+	if config.ProvenanceRetention.Duration > 0 {
+		services = append(services, provenance.NewPruner(n.DBPool, config.ProvenanceRetention.Duration))
+	}
+	if config.GCEpochHorizon.Duration > 0 || config.GCEonHorizon > 0 {
+		gc := epochkghandler.NewGarbageCollector(n.DBPool, config.GCEpochHorizon.Duration, config.GCEonHorizon)
+		services = append(services, service.ServiceFn{Fn: gc.Run})
+	}
+	if config.QuorumWindow > 0 {
+		monitor := quorum.NewMonitor(n.DBPool, n.Bus, int32(config.QuorumWindow))
+		services = append(services, service.ServiceFn{Fn: monitor.Run})
+	}
+	if n.Contracts.KeyperBondsDeployment != nil {
+		monitor := bonds.NewMonitor(n.DBPool, n.L1Client, config.GetAddress(), n.Alerts)
+		services = append(services, service.ServiceFn{Fn: monitor.Run})
+	}
+	if config.Mempool.Enabled() {
+		observer := NewMempoolObserver(config, n.DBPool, n.Alerts)
+		services = append(services, service.ServiceFn{Fn: observer.Run})
+	}
+	return services
+}
+
+// HandleContractEvents syncs the events of the contracts the keyper depends on.
+func (n *Node) HandleContractEvents(ctx context.Context) error {
+	events := []*eventsyncer.EventType{
+		n.Contracts.KeypersConfigsListNewConfig,
+		n.Contracts.CollatorConfigsListNewConfig,
+	}
+	chainobs, err := chainobserver.New(n.Contracts, n.DBPool, n.Config.Ethereum.EventWitnessURLs)
+	if err != nil {
+		return err
+	}
+	chainobs.EnableDeadEvents(n.Config.MaxEventAttempts, n.Alerts)
+	if url := n.Config.Ethereum.EventCrossCheckURL; url != "" {
+		if err := chainobs.EnableCrossCheck(url, n.Alerts); err != nil {
+			return err
+		}
+	}
+	if n.Beacon != nil {
+		chainobs.EnableBlobs(n.Beacon)
+		chainobs.EnableCheckpointSync(n.Beacon, n.Config.Ethereum.GetSyncMode())
+	}
+	if n.Contracts.KeyperRotationsRotated != nil {
+		events = append(events, n.Contracts.KeyperRotationsRotated)
+		chainobs.RegisterEventHandler(
+			chainobserver.KeyperRotationsContractName,
+			chainobserver.KeyperRotatedEventName,
+			chainobserver.KeyperRotationHandler(MigrateRotatedKeyper),
+		)
+	}
+	if n.Contracts.KeyperBondsDeployment != nil {
+		events = append(events,
+			n.Contracts.KeyperBondsBondChanged,
+			n.Contracts.KeyperBondsMinimumBondChanged,
+		)
+		chainobs.RegisterEventHandler(bonds.ContractName, bonds.BondChangedEventName, bonds.HandleBondChanged)
+		chainobs.RegisterEventHandler(
+			bonds.ContractName, bonds.MinimumBondChangedEventName, bonds.HandleMinimumBondChanged,
+		)
+	}
+	if n.Contracts.VersionRequirementsDeployment != nil {
+		events = append(events, n.Contracts.VersionRequirementsMinimumVersionChanged)
+		chainobs.RegisterEventHandler(
+			upgrade.ContractName, upgrade.MinimumVersionChangedEventName, upgrade.HandleMinimumVersionChanged,
+		)
+		// warn right away if the node is still outdated after a restart
+		if err := upgrade.Report(ctx, kprdb.New(n.DBPool)); err != nil {
+			return err
+		}
+	}
+	if n.Contracts.DKGParametersDeployment != nil {
+		events = append(events, n.Contracts.DKGParametersChanged)
+		chainobs.RegisterEventHandler(
+			dkgparams.ContractName, dkgparams.ParametersChangedEventName, dkgparams.HandleParametersChanged,
+		)
+	}
+	if n.Features.Enabled(FeatureEventSchemas) {
+		schemaEvents, err := chainobs.LoadEventTypes(ctx, n.Config.Ethereum.EventSchemaDir)
+		if err != nil {
+			return err
+		}
+		events = append(events, schemaEvents...)
+	}
+	return chainobs.Observe(ctx, events)
+}
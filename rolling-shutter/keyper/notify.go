@@ -0,0 +1,99 @@
+package keyper
+
+import (
+	"context"
+
+	"github.com/jackc/pgconn"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// Database notifications sent by the triggers of the keyper's schema. They let the components
+// writing to the database wake up the ones reading from it, so that the readers don't have to
+// wait for their next poll.
+const (
+	newKeyperSet      = "new_keyper_set"
+	newOutgoingEonKey = "new_outgoing_eon_key"
+)
+
+var dbListenChannels = []string{
+	newKeyperSet,
+	newOutgoingEonKey,
+}
+
+// dbSignals are delivered when the corresponding database notification has been received. A
+// signal delivered while the previous one has not been consumed yet is dropped, so the consumers
+// have to handle all changes made since they last checked.
+type dbSignals struct {
+	newKeyperSet      chan struct{}
+	newOutgoingEonKey chan struct{}
+}
+
+func newDBSignals() dbSignals {
+	return dbSignals{
+		newKeyperSet:      make(chan struct{}, 1),
+		newOutgoingEonKey: make(chan struct{}, 1),
+	}
+}
+
+func deliverSignal(ch chan<- struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func (kpr *keyper) listenDatabaseNotifications(ctx context.Context) <-chan *pgconn.Notification {
+	chann := make(chan *pgconn.Notification, 1)
+	go func() {
+		defer close(chann)
+		defer log.Debug().Msg("stop listening database notifications")
+
+		conn, err := kpr.DBPool.Acquire(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("error acquiring connection")
+			return
+		}
+		defer conn.Release()
+
+		err = shdb.ExecListenChannels(ctx, conn.Conn(), dbListenChannels)
+		if err != nil {
+			return
+		}
+		shdb.SlurpNotifications(ctx, conn.Conn(), chann)
+	}()
+	return chann
+}
+
+// handleDatabaseNotifications turns database notifications into signals. If listening fails, the
+// components keep polling the database, just with a higher latency.
+func (kpr *keyper) handleDatabaseNotifications(ctx context.Context) error {
+	notifications := kpr.listenDatabaseNotifications(ctx)
+	log.Info().Msg("listening for notifications")
+	for {
+		select {
+		case n, ok := <-notifications:
+			if !ok {
+				if ctx.Err() == nil {
+					log.Warn().Msg("stopped listening for database notifications, falling back to polling")
+				}
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			switch n.Channel {
+			case newKeyperSet:
+				deliverSignal(kpr.signals.newKeyperSet)
+				kpr.idleMonitor.KeyperSetChanged()
+			case newOutgoingEonKey:
+				deliverSignal(kpr.signals.newOutgoingEonKey)
+			default:
+				log.Error().
+					Str("channel", n.Channel).
+					Msg("ignoring database notification for unknown channel")
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
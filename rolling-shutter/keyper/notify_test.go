@@ -0,0 +1,61 @@
+package keyper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+func TestDatabaseNotificationsIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	kpr := &keyper{
+		Node:        &Node{DBPool: dbpool},
+		idleMonitor: NewIdleMonitor(dbpool, nil, nil, nil, common.Address{}, 0),
+		signals:     newDBSignals(),
+	}
+	go func() { _ = kpr.handleDatabaseNotifications(ctx) }()
+
+	// we don't know when the listener is ready, so we keep inserting until the signal arrives
+	received := func(ch <-chan struct{}, insert func(i int64) error) bool {
+		for i := int64(0); i < 50; i++ {
+			assert.NilError(t, insert(i))
+			select {
+			case <-ch:
+				return true
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+		return false
+	}
+	assert.Check(t, received(kpr.signals.newOutgoingEonKey, func(i int64) error {
+		return db.InsertEonPublicKey(ctx, kprdb.InsertEonPublicKeyParams{EonPublicKey: []byte{1}, Eon: i})
+	}))
+	assert.Check(t, received(kpr.signals.newKeyperSet, func(i int64) error {
+		return chainobsdb.New(dbpool).InsertKeyperSet(ctx, chainobsdb.InsertKeyperSetParams{
+			KeyperConfigIndex:     i,
+			ActivationBlockNumber: 10,
+			Keypers:               shdb.EncodeAddresses([]common.Address{{1}}),
+			Threshold:             1,
+		})
+	}))
+	select {
+	case <-kpr.idleMonitor.keyperSetChanged:
+	default:
+		t.Error("idle monitor not woken up by new keyper set")
+	}
+}
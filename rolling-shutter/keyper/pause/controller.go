@@ -0,0 +1,150 @@
+package pause
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+type MessageSender interface {
+	SendMessage(ctx context.Context, msg p2pmsg.Message, retryOpts ...retry.Option) error
+}
+
+// Controller casts the votes of this keyper.
+type Controller struct {
+	dbpool  *pgxpool.Pool
+	fence   kprdb.Fence
+	domain  p2pmsg.SigningDomain
+	privKey *ecdsa.PrivateKey
+	p2p     MessageSender
+}
+
+func NewController(
+	dbpool *pgxpool.Pool,
+	fence kprdb.Fence,
+	domain p2pmsg.SigningDomain,
+	privKey *ecdsa.PrivateKey,
+	p2p MessageSender,
+) *Controller {
+	return &Controller{dbpool: dbpool, fence: fence, domain: domain, privKey: privKey, p2p: p2p}
+}
+
+// Vote signs a vote to pause or resume key generation in the sequence after the current decision,
+// stores it and broadcasts it to the other keypers. Voting again the same way with the same
+// reason broadcasts the vote again.
+func (c *Controller) Vote(ctx context.Context, paused bool, reason string) (*p2pmsg.PauseVote, error) {
+	if len(reason) > p2pmsg.MaxPauseReasonLength {
+		return nil, errcode.ErrInvalidRequest.Errorf("reason must not exceed %d bytes", p2pmsg.MaxPauseReasonLength)
+	}
+	address := ethcrypto.PubkeyToAddress(c.privKey.PublicKey)
+	var vote *p2pmsg.PauseVote
+	err := c.fence.BeginFunc(ctx, func(tx pgx.Tx) error {
+		state, err := getState(ctx, kprdb.New(tx))
+		if err != nil {
+			return err
+		}
+		if state.Paused == paused {
+			return errcode.ErrInvalidRequest.Errorf(
+				"keypers already decided paused=%t in sequence %d", paused, state.Sequence,
+			)
+		}
+		keyperSet, err := currentKeyperSet(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if _, ok := kprdb.GetKeyperIndex(address, keyperSet.Keypers); !ok {
+			return errcode.ErrNotInKeyperSet.Errorf("not a keyper in keyper set %d", keyperSet.KeyperConfigIndex)
+		}
+		vote = &p2pmsg.PauseVote{
+			InstanceID:        c.domain.InstanceID,
+			KeyperConfigIndex: uint64(keyperSet.KeyperConfigIndex),
+			Sequence:          uint64(state.Sequence + 1),
+			Paused:            paused,
+			Reason:            reason,
+		}
+		if err := kprdb.New(tx).AllocateNonce(ctx, c.domain, address, vote); err != nil {
+			return err
+		}
+		if err := p2pmsg.SignInDomain(vote, c.domain, c.privKey); err != nil {
+			return errors.Wrap(err, "failed to sign pause vote")
+		}
+		err = StoreVote(ctx, tx, vote, c.domain)
+		if errors.Is(err, kprdb.ErrConflictingVote) {
+			return errcode.ErrInvalidRequest.Wrapf(err, "voted differently in sequence %d already", vote.Sequence)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Warn().Str("message", vote.LogInfo()).Str("reason", reason).Msg("sending pause vote")
+	if err := c.p2p.SendMessage(ctx, vote); err != nil {
+		return nil, errors.Wrap(err, "failed to send pause vote")
+	}
+	return vote, nil
+}
+
+// Vote is a vote of a keyper that hasn't led to a decision yet.
+type Vote struct {
+	KeyperConfigIndex int64     `json:"keyperConfigIndex"`
+	Sequence          int64     `json:"sequence"`
+	Sender            string    `json:"sender"`
+	Paused            bool      `json:"paused"`
+	Reason            string    `json:"reason"`
+	ReceivedAt        time.Time `json:"receivedAt"`
+}
+
+// Status is the current decision and the votes for later ones.
+type Status struct {
+	Paused            bool       `json:"paused"`
+	Sequence          int64      `json:"sequence"`
+	KeyperConfigIndex int64      `json:"keyperConfigIndex"`
+	Reason            string     `json:"reason"`
+	UpdatedAt         *time.Time `json:"updatedAt,omitempty"`
+	PendingVotes      []Vote     `json:"pendingVotes"`
+}
+
+func (c *Controller) Status(ctx context.Context) (*Status, error) {
+	db := kprdb.New(c.dbpool)
+	state, err := getState(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{
+		Paused:            state.Paused,
+		Sequence:          state.Sequence,
+		KeyperConfigIndex: state.KeyperConfigIndex,
+		Reason:            state.Reason,
+		PendingVotes:      []Vote{},
+	}
+	if state.Sequence > 0 {
+		updatedAt := state.UpdatedAt.UTC()
+		status.UpdatedAt = &updatedAt
+	}
+	votes, err := db.GetPendingPauseVotes(ctx, state.Sequence)
+	if err != nil {
+		return nil, errcode.WrapDB(err, "failed to get pause votes from db")
+	}
+	for _, v := range votes {
+		status.PendingVotes = append(status.PendingVotes, Vote{
+			KeyperConfigIndex: v.KeyperConfigIndex,
+			Sequence:          v.Sequence,
+			Sender:            v.Sender,
+			Paused:            v.Paused,
+			Reason:            v.Reason,
+			ReceivedAt:        v.ReceivedAt.UTC(),
+		})
+	}
+	return status, nil
+}
@@ -0,0 +1,47 @@
+package pause
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/httpauth"
+)
+
+// VoteRequest is the body of a request to vote for pausing or resuming key generation.
+type VoteRequest struct {
+	Paused bool   `json:"paused"`
+	Reason string `json:"reason"`
+}
+
+// Router serves the current decision and the pending votes. Voting requires the admin role.
+func (c *Controller) Router() http.Handler {
+	router := chi.NewRouter()
+	router.Get("/", c.handleStatus)
+	router.With(httpauth.RequireRole(httpauth.RoleAdmin)).Post("/", c.handleVote)
+	return router
+}
+
+func (c *Controller) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := c.Status(r.Context())
+	if err != nil {
+		errcode.SendError(w, err)
+		return
+	}
+	errcode.WriteJSON(w, http.StatusOK, status)
+}
+
+func (c *Controller) handleVote(w http.ResponseWriter, r *http.Request) {
+	req := VoteRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errcode.SendError(w, errcode.ErrInvalidRequest.Wrapf(err, "invalid request body"))
+		return
+	}
+	if _, err := c.Vote(r.Context(), req.Paused, req.Reason); err != nil {
+		errcode.SendError(w, err)
+		return
+	}
+	c.handleStatus(w, r)
+}
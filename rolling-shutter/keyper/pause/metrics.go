@@ -0,0 +1,16 @@
+package pause
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsPaused = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "pause",
+		Name:      "key_generation_paused",
+		Help:      "Whether the keypers decided to pause key generation (1) or not (0)",
+	},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsPaused)
+}
@@ -0,0 +1,214 @@
+// Package pause lets the keypers pause the generation of decryption keys for future epochs and
+// resume it later, e.g. in response to a discovered vulnerability. Operators vote to pause or
+// resume via the admin API of their keyper, which signs the vote and broadcasts it as a PauseVote.
+// Once threshold many keypers of the keyper set of the current eon voted the same way, every
+// keyper records the decision in its database. While paused, keypers ignore decryption triggers
+// and don't send decryption key shares.
+//
+// Decisions are numbered by sequence. Keypers vote in the sequence after the one of the current
+// decision and at most once per sequence, so the same decision is made by all keypers regardless
+// of the order the votes arrive in. A later decision supersedes all earlier ones.
+package pause
+
+import (
+	"context"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// Check fails with errcode.ErrKeyGenerationPaused if the keypers decided to pause key generation.
+func Check(ctx context.Context, db *kprdb.Queries) error {
+	state, err := getState(ctx, db)
+	if err != nil {
+		return err
+	}
+	metricsPaused.Set(boolToFloat(state.Paused))
+	if state.Paused {
+		return errcode.ErrKeyGenerationPaused.Errorf(
+			"key generation paused in sequence %d: %s", state.Sequence, state.Reason,
+		)
+	}
+	return nil
+}
+
+// getState returns the current decision, which is to not pause in sequence 0 if the keypers
+// haven't decided anything yet.
+func getState(ctx context.Context, db *kprdb.Queries) (kprdb.KeyGenerationPause, error) {
+	state, err := db.GetKeyGenerationPause(ctx)
+	if err == pgx.ErrNoRows {
+		return kprdb.KeyGenerationPause{}, nil
+	}
+	if err != nil {
+		return kprdb.KeyGenerationPause{}, errcode.WrapDB(err, "failed to get key generation pause from db")
+	}
+	return state, nil
+}
+
+// currentKeyperSet returns the keyper set of the eon active at the last block seen, which is the
+// one generating the decryption keys.
+func currentKeyperSet(ctx context.Context, db kprdb.DBTX) (chainobsdb.KeyperSet, error) {
+	queries := kprdb.New(db)
+	lastBlock, err := queries.GetLastBlockSeen(ctx)
+	if err != nil {
+		return chainobsdb.KeyperSet{}, errcode.WrapDB(err, "failed to get last block seen from db")
+	}
+	eon, err := queries.GetEonForBlockNumber(ctx, lastBlock)
+	if err == pgx.ErrNoRows {
+		return chainobsdb.KeyperSet{}, errcode.ErrEonUnknown.Errorf("no eon has been started until block %d", lastBlock)
+	}
+	if err != nil {
+		return chainobsdb.KeyperSet{}, errcode.WrapDB(err, "failed to get current eon from db")
+	}
+	keyperSet, err := chainobsdb.New(db).GetKeyperSetByKeyperConfigIndex(ctx, eon.KeyperConfigIndex)
+	if err != nil {
+		return chainobsdb.KeyperSet{}, errcode.WrapDB(err, "failed to get keyper set %d from db", eon.KeyperConfigIndex)
+	}
+	return keyperSet, nil
+}
+
+func recoverSigner(vote *p2pmsg.PauseVote, domain p2pmsg.SigningDomain, keypers []string) (common.Address, error) {
+	return p2pmsg.RecoverSigner(vote, domain, false, func(address common.Address) bool {
+		_, ok := kprdb.GetKeyperIndex(address, keypers)
+		return ok
+	})
+}
+
+func NewHandler(dbpool *pgxpool.Pool, domain p2pmsg.SigningDomain) p2p.MessageHandler {
+	return &Handler{dbpool: dbpool, domain: domain}
+}
+
+// Handler collects the pause votes of the keypers of the current keyper set.
+type Handler struct {
+	dbpool *pgxpool.Pool
+	domain p2pmsg.SigningDomain
+}
+
+func (*Handler) MessagePrototypes() []p2pmsg.Message {
+	return []p2pmsg.Message{&p2pmsg.PauseVote{}}
+}
+
+func (handler *Handler) ValidateMessage(ctx context.Context, msg p2pmsg.Message) (bool, error) {
+	vote := msg.(*p2pmsg.PauseVote)
+	if vote.GetInstanceID() != handler.domain.InstanceID {
+		return false, errors.Errorf(
+			"instance ID mismatch (want=%d, have=%d)", handler.domain.InstanceID, vote.GetInstanceID(),
+		)
+	}
+	if vote.KeyperConfigIndex > math.MaxInt64 || vote.Sequence > math.MaxInt64 {
+		return false, errors.New("int64 overflow in pause vote")
+	}
+	keyperSet, err := currentKeyperSet(ctx, handler.dbpool)
+	if err != nil {
+		return false, err
+	}
+	if keyperSet.KeyperConfigIndex != int64(vote.KeyperConfigIndex) {
+		return false, errors.Errorf(
+			"pause vote for keyper set %d, but keyper set %d is active", vote.KeyperConfigIndex, keyperSet.KeyperConfigIndex,
+		)
+	}
+	if _, err := recoverSigner(vote, handler.domain, keyperSet.Keypers); err != nil {
+		return false, errors.Wrap(err, "invalid signature of pause vote")
+	}
+	return true, nil
+}
+
+func (handler *Handler) HandleMessage(ctx context.Context, msg p2pmsg.Message) ([]p2pmsg.Message, error) {
+	vote := msg.(*p2pmsg.PauseVote)
+	err := handler.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return StoreVote(ctx, tx, vote, handler.domain)
+	})
+	return nil, err
+}
+
+// StoreVote stores the pause vote of its signer and records the decision once threshold many
+// keypers of the keyper set voted the same way in the same sequence. Only the first vote of a keyper
+// in a sequence counts, voting differently afterwards fails with kprdb.ErrConflictingVote.
+func StoreVote(ctx context.Context, tx pgx.Tx, vote *p2pmsg.PauseVote, domain p2pmsg.SigningDomain) error {
+	keyperSet, err := chainobsdb.New(tx).GetKeyperSetByKeyperConfigIndex(ctx, int64(vote.KeyperConfigIndex))
+	if err != nil {
+		return errors.Wrapf(err, "failed to get keyper set %d from db", vote.KeyperConfigIndex)
+	}
+	sender, err := recoverSigner(vote, domain, keyperSet.Keypers)
+	if err != nil {
+		return errors.Wrap(err, "failed to recover signer of pause vote")
+	}
+
+	db := kprdb.New(tx)
+	if err := db.ConsumeNonce(ctx, domain, sender, vote); err != nil {
+		return err
+	}
+	paused, err := db.InsertPauseVote(ctx, kprdb.InsertPauseVoteParams{
+		KeyperConfigIndex: int64(vote.KeyperConfigIndex),
+		Sequence:          int64(vote.Sequence),
+		Sender:            shdb.EncodeAddress(sender),
+		Paused:            vote.Paused,
+		Reason:            vote.Reason,
+		Signature:         vote.Signature,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to insert pause vote")
+	}
+	if paused != vote.Paused {
+		return errors.Wrapf(kprdb.ErrConflictingVote, "%s voted paused=%t in sequence %d already",
+			sender.Hex(), paused, vote.Sequence)
+	}
+	count, err := db.CountPauseVotes(ctx, kprdb.CountPauseVotesParams{
+		KeyperConfigIndex: int64(vote.KeyperConfigIndex),
+		Sequence:          int64(vote.Sequence),
+		Paused:            vote.Paused,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to count pause votes")
+	}
+	logger := log.With().
+		Uint64("keyper-config-index", vote.KeyperConfigIndex).
+		Uint64("sequence", vote.Sequence).
+		Bool("paused", vote.Paused).
+		Str("sender", sender.Hex()).
+		Str("reason", vote.Reason).
+		Int32("threshold", keyperSet.Threshold).
+		Int64("count", count).
+		Logger()
+	if count < int64(keyperSet.Threshold) {
+		logger.Info().Msg("inserted pause vote")
+		return nil
+	}
+	decided, err := db.SetKeyGenerationPause(ctx, kprdb.SetKeyGenerationPauseParams{
+		Paused:            vote.Paused,
+		Sequence:          int64(vote.Sequence),
+		KeyperConfigIndex: int64(vote.KeyperConfigIndex),
+		Reason:            vote.Reason,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to set key generation pause")
+	}
+	if decided == 0 {
+		return nil
+	}
+	metricsPaused.Set(boolToFloat(vote.Paused))
+	if vote.Paused {
+		logger.Warn().Msg("keypers decided to pause key generation")
+	} else {
+		logger.Warn().Msg("keypers decided to resume key generation")
+	}
+	return nil
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
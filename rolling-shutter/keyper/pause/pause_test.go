@@ -0,0 +1,111 @@
+package pause
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"testing"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p/p2ptest"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+type recordingSender struct {
+	msgs []p2pmsg.Message
+}
+
+func (s *recordingSender) SendMessage(_ context.Context, msg p2pmsg.Message, _ ...retry.Option) error {
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
+func TestPauseVotesIntegration(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	ctx := context.Background()
+	db, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	keys := []*ecdsa.PrivateKey{}
+	keypers := []string{}
+	for i := 0; i < 3; i++ {
+		key, err := ethcrypto.GenerateKey()
+		assert.NilError(t, err)
+		keys = append(keys, key)
+		keypers = append(keypers, shdb.EncodeAddress(ethcrypto.PubkeyToAddress(key.PublicKey)))
+	}
+	err := chainobsdb.New(dbpool).InsertKeyperSet(ctx, chainobsdb.InsertKeyperSetParams{
+		KeyperConfigIndex:     1,
+		ActivationBlockNumber: 10,
+		Keypers:               keypers,
+		Threshold:             2,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, db.InsertEon(ctx, kprdb.InsertEonParams{Eon: 1, ActivationBlockNumber: 10, KeyperConfigIndex: 1}))
+	assert.NilError(t, db.SetLastBlockSeen(ctx, 20))
+
+	domain := p2pmsg.SigningDomain{ChainID: 1, InstanceID: 2}
+	handler := NewHandler(dbpool, domain)
+	newVote := func(privKey *ecdsa.PrivateKey, keyperConfigIndex uint64, paused bool, nonce uint64) *p2pmsg.PauseVote {
+		vote, err := p2pmsg.NewSignedPauseVote(domain, keyperConfigIndex, 1, paused, "vulnerability", nonce, privKey)
+		assert.NilError(t, err)
+		return vote
+	}
+
+	outsider, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	p2ptest.MustValidateMessageResult(t, false, handler, ctx, newVote(outsider, 1, true, 1))
+	p2ptest.MustValidateMessageResult(t, false, handler, ctx, newVote(keys[0], 2, true, 1))
+
+	assert.NilError(t, Check(ctx, db))
+	for i, privKey := range keys[:2] {
+		vote := newVote(privKey, 1, true, 1)
+		p2ptest.MustValidateMessageResult(t, true, handler, ctx, vote)
+		p2ptest.MustHandleMessage(t, handler, ctx, vote)
+		if i == 0 {
+			assert.NilError(t, Check(ctx, db), "paused with a single vote")
+		}
+	}
+	assert.Assert(t, errors.Is(Check(ctx, db), errcode.ErrKeyGenerationPaused))
+
+	// a keyper can't vote both ways in the same sequence
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return StoreVote(ctx, tx, newVote(keys[0], 1, false, 2), domain)
+	})
+	assert.Assert(t, errors.Is(err, kprdb.ErrConflictingVote))
+	// and can't reuse the nonce of its vote for another one
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return StoreVote(ctx, tx, newVote(keys[0], 1, false, 1), domain)
+	})
+	assert.Assert(t, errors.Is(err, kprdb.ErrNonceConsumed))
+
+	sender := &recordingSender{}
+	controller := NewController(dbpool, dbpool, domain, keys[2], sender)
+	_, err = controller.Vote(ctx, true, "")
+	assert.Assert(t, errors.Is(err, errcode.ErrInvalidRequest))
+	vote, err := controller.Vote(ctx, false, "fixed")
+	assert.NilError(t, err)
+	assert.Equal(t, vote.Sequence, uint64(2))
+	assert.Equal(t, len(sender.msgs), 1)
+
+	status, err := controller.Status(ctx)
+	assert.NilError(t, err)
+	assert.Check(t, status.Paused)
+	assert.Equal(t, status.Sequence, int64(1))
+	assert.Equal(t, len(status.PendingVotes), 1)
+
+	_, err = NewController(dbpool, dbpool, domain, keys[0], sender).Vote(ctx, false, "fixed")
+	assert.NilError(t, err)
+	assert.NilError(t, Check(ctx, db))
+}
@@ -0,0 +1,46 @@
+package quorum
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsKeyperAvailability = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "quorum",
+		Name:      "keyper_availability",
+		Help:      "Fraction of the recent epochs the keyper contributed a decryption key share to",
+	},
+	[]string{"keyper"},
+)
+
+var metricsActiveKeypers = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "quorum",
+		Name:      "active_keypers",
+		Help:      "Number of keypers contributing to at least half of the recent epochs",
+	},
+)
+
+var metricsMargin = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "quorum",
+		Name:      "margin",
+		Help:      "Number of active keypers exceeding the threshold, negative if the quorum is lost",
+	},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsKeyperAvailability)
+	prometheus.MustRegister(metricsActiveKeypers)
+	prometheus.MustRegister(metricsMargin)
+}
+
+func updateMetrics(status *Status) {
+	metricsKeyperAvailability.Reset()
+	for _, k := range status.Keypers {
+		metricsKeyperAvailability.WithLabelValues(k.Address.Hex()).Set(k.Availability)
+	}
+	metricsActiveKeypers.Set(float64(status.ActiveKeypers))
+	metricsMargin.Set(float64(status.Margin))
+}
@@ -0,0 +1,211 @@
+// Package quorum tracks how many keypers of the active keyper set actually contribute decryption
+// key shares, so that operators notice before the set can't reach the threshold anymore.
+package quorum
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/broker"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// ActiveAvailability is the fraction of the epochs in the window a keyper must have contributed a
+// share to in order to count as active.
+const ActiveAvailability = 0.5
+
+// Risk classifies how close the keyper set is to losing its quorum.
+type Risk string
+
+const (
+	// RiskUnknown means that no epoch of the current eon has been observed yet.
+	RiskUnknown Risk = "unknown"
+	// RiskOK means that at least two more keypers than the threshold are active.
+	RiskOK Risk = "ok"
+	// RiskAtRisk means that the threshold is met, but by at most one keyper.
+	RiskAtRisk Risk = "at-risk"
+	// RiskNoQuorum means that fewer keypers than the threshold are active.
+	RiskNoQuorum Risk = "no-quorum"
+)
+
+// KeyperAvailability is the participation of a single keyper in the epochs of the window.
+type KeyperAvailability struct {
+	Index        uint64         `json:"index"`
+	Address      common.Address `json:"address"`
+	Epochs       int            `json:"epochs"`
+	Availability float64        `json:"availability"`
+	Active       bool           `json:"active"`
+}
+
+// Status is the quorum health of the keyper set of the current eon, computed over the most recent
+// epochs whose decryption key is known.
+type Status struct {
+	Eon           uint64               `json:"eon"`
+	Threshold     uint64               `json:"threshold"`
+	Window        int                  `json:"window"`
+	Epochs        int                  `json:"epochs"`
+	ActiveKeypers int                  `json:"activeKeypers"`
+	Margin        int                  `json:"margin"`
+	Risk          Risk                 `json:"risk"`
+	Keypers       []KeyperAvailability `json:"keypers"`
+}
+
+// ComputeStatus computes the status from the participation of the given keyper set in the epochs
+// of the window.
+func ComputeStatus(
+	eon uint64, keypers []common.Address, threshold uint64, window int, epochs []kprdb.EpochParticipation,
+) *Status {
+	participated := make([]int, len(keypers))
+	for _, epoch := range epochs {
+		for _, index := range epoch.KeyperIndices {
+			if index >= 0 && index < int64(len(keypers)) {
+				participated[index]++
+			}
+		}
+	}
+
+	status := &Status{
+		Eon:       eon,
+		Threshold: threshold,
+		Window:    window,
+		Epochs:    len(epochs),
+		Keypers:   make([]KeyperAvailability, len(keypers)),
+	}
+	for i, address := range keypers {
+		availability := KeyperAvailability{
+			Index:   uint64(i),
+			Address: address,
+			Epochs:  participated[i],
+		}
+		if len(epochs) > 0 {
+			availability.Availability = float64(participated[i]) / float64(len(epochs))
+			availability.Active = availability.Availability >= ActiveAvailability
+		}
+		if availability.Active {
+			status.ActiveKeypers++
+		}
+		status.Keypers[i] = availability
+	}
+	status.Margin = status.ActiveKeypers - int(threshold)
+
+	switch {
+	case len(epochs) == 0:
+		status.Risk = RiskUnknown
+	case status.Margin < 0:
+		status.Risk = RiskNoQuorum
+	case status.Margin <= 1:
+		status.Risk = RiskAtRisk
+	default:
+		status.Risk = RiskOK
+	}
+	return status
+}
+
+// GetStatus computes the status of the keyper set of the current eon from the db.
+func GetStatus(ctx context.Context, db kprdb.DBTX, window int32) (*Status, error) {
+	queries := kprdb.New(db)
+	lastBlock, err := queries.GetLastBlockSeen(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get last block seen from db")
+	}
+	eon, err := queries.GetEonForBlockNumber(ctx, lastBlock)
+	if err == pgx.ErrNoRows {
+		return nil, errors.Errorf("no eon has been started until block %d", lastBlock)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get current eon from db")
+	}
+	keyperSet, err := chainobsdb.New(db).GetKeyperSetByKeyperConfigIndex(ctx, eon.KeyperConfigIndex)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get keyper set %d from db", eon.KeyperConfigIndex)
+	}
+	keypers, err := shdb.DecodeAddresses(keyperSet.Keypers)
+	if err != nil {
+		return nil, err
+	}
+	epochs, err := queries.GetRecentEpochParticipation(ctx, kprdb.GetRecentEpochParticipationParams{
+		Eon:   eon.Eon,
+		Limit: window,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get epoch participation from db")
+	}
+	return ComputeStatus(uint64(eon.Eon), keypers, uint64(keyperSet.Threshold), int(window), epochs), nil
+}
+
+// Monitor records the participation in every epoch whose decryption key becomes known and keeps
+// the quorum metrics up to date. The participation in an epoch consists of the keypers whose
+// shares we had received by then, so keypers whose shares regularly arrive late count as
+// unavailable.
+type Monitor struct {
+	dbpool *pgxpool.Pool
+	window int32
+	keys   chan broker.DecryptionKeyAvailable
+	risk   Risk
+}
+
+// NewMonitor creates a monitor and subscribes it to the decryption keys published to the bus. It
+// must be created before keys are ingested and run for the bus not to block.
+func NewMonitor(dbpool *pgxpool.Pool, bus *broker.Bus, window int32) *Monitor {
+	return &Monitor{
+		dbpool: dbpool,
+		window: window,
+		keys:   broker.Subscribe(bus, broker.DecryptionKeyAvailableTopic, 32),
+		risk:   RiskUnknown,
+	}
+}
+
+func (m *Monitor) Run(ctx context.Context) error {
+	for {
+		select {
+		case key := <-m.keys:
+			if err := m.record(ctx, key); err != nil {
+				log.Warn().Err(err).Str("epoch-id", key.EpochID.Hex()).
+					Msg("failed to update quorum health")
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (m *Monitor) record(ctx context.Context, key broker.DecryptionKeyAvailable) error {
+	db := kprdb.New(m.dbpool)
+	err := db.InsertEpochParticipation(ctx, kprdb.InsertEpochParticipationParams{
+		Eon:     int64(key.Eon),
+		EpochID: key.EpochID.Bytes(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to insert epoch participation into db")
+	}
+	if _, err := db.PruneEpochParticipation(ctx, m.window); err != nil {
+		return errors.Wrap(err, "failed to prune epoch participation")
+	}
+
+	status, err := GetStatus(ctx, m.dbpool, m.window)
+	if err != nil {
+		return err
+	}
+	updateMetrics(status)
+	if status.Risk != m.risk {
+		ev := log.Info()
+		if status.Risk == RiskAtRisk || status.Risk == RiskNoQuorum {
+			ev = log.Warn()
+		}
+		ev.Uint64("eon", status.Eon).
+			Int("active-keypers", status.ActiveKeypers).
+			Uint64("threshold", status.Threshold).
+			Int("epochs", status.Epochs).
+			Str("risk", string(status.Risk)).
+			Msg("keyper quorum health changed")
+		m.risk = status.Risk
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package quorum
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+)
+
+func TestComputeStatus(t *testing.T) {
+	keypers := []common.Address{
+		common.HexToAddress("0x1"),
+		common.HexToAddress("0x2"),
+		common.HexToAddress("0x3"),
+		common.HexToAddress("0x4"),
+	}
+	epochs := []kprdb.EpochParticipation{
+		{KeyperIndices: []int64{0, 1, 2}},
+		{KeyperIndices: []int64{0, 1}},
+		{KeyperIndices: []int64{0, 2, 7}},
+		{KeyperIndices: []int64{0, 1}},
+	}
+
+	status := ComputeStatus(5, keypers, 2, 100, epochs)
+	assert.Equal(t, status.Epochs, 4)
+	assert.Equal(t, status.Keypers[0].Availability, 1.0)
+	assert.Equal(t, status.Keypers[1].Epochs, 3)
+	assert.Equal(t, status.Keypers[2].Availability, 0.5)
+	assert.Check(t, status.Keypers[2].Active)
+	assert.Check(t, !status.Keypers[3].Active)
+	assert.Equal(t, status.ActiveKeypers, 3)
+	assert.Equal(t, status.Margin, 1)
+	assert.Equal(t, status.Risk, RiskAtRisk)
+
+	assert.Equal(t, ComputeStatus(5, keypers, 1, 100, epochs).Risk, RiskOK)
+	assert.Equal(t, ComputeStatus(5, keypers, 4, 100, epochs).Risk, RiskNoQuorum)
+	assert.Equal(t, ComputeStatus(5, keypers, 2, 100, nil).Risk, RiskUnknown)
+}
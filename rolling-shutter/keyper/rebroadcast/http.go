@@ -0,0 +1,32 @@
+package rebroadcast
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+)
+
+// Router serves the rebroadcast of a range posted as JSON. It responds with the number of messages
+// sent.
+func (rb *Rebroadcaster) Router() http.Handler {
+	router := chi.NewRouter()
+	router.Post("/", rb.handleRebroadcast)
+	return router
+}
+
+func (rb *Rebroadcaster) handleRebroadcast(w http.ResponseWriter, r *http.Request) {
+	req := Range{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errcode.SendError(w, errcode.ErrInvalidRequest.Wrapf(err, "invalid request body"))
+		return
+	}
+	result, err := rb.Rebroadcast(r.Context(), req)
+	if err != nil {
+		errcode.SendError(w, err)
+		return
+	}
+	errcode.WriteJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,147 @@
+// Package rebroadcast republishes the decryption keys and key shares a keyper has stored for a
+// range of epochs. Operators use it to help a peer that missed the messages of some epochs to
+// recover without restarting their own node.
+package rebroadcast
+
+import (
+	"bytes"
+	"context"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// MaxMessages is the maximum number of keys and the maximum number of shares sent for a single
+// range. Larger ranges are rejected instead of flooding the network.
+const MaxMessages = 1000
+
+// Sender publishes messages to the network.
+type Sender interface {
+	SendMessage(ctx context.Context, msg p2pmsg.Message, retryOpts ...retry.Option) error
+}
+
+// Range is an inclusive range of epochs of an eon.
+type Range struct {
+	Eon         uint64        `json:"eon"`
+	FromEpochID hexutil.Bytes `json:"fromEpochID"`
+	ToEpochID   hexutil.Bytes `json:"toEpochID"`
+}
+
+// Result counts the messages sent for a range.
+type Result struct {
+	Keys   int `json:"keys"`
+	Shares int `json:"shares"`
+}
+
+type Rebroadcaster struct {
+	dbpool     *pgxpool.Pool
+	instanceID uint64
+	sender     Sender
+}
+
+func New(dbpool *pgxpool.Pool, instanceID uint64, sender Sender) *Rebroadcaster {
+	return &Rebroadcaster{dbpool: dbpool, instanceID: instanceID, sender: sender}
+}
+
+// Validate checks that the range is well-formed.
+func (r Range) Validate() error {
+	if r.Eon > math.MaxInt64 {
+		return errcode.ErrInvalidRequest.Errorf("eon %d overflows int64", r.Eon)
+	}
+	if _, err := epochid.BytesToEpochID(r.FromEpochID); err != nil {
+		return errcode.ErrInvalidEpochID.Wrapf(err, "invalid first epoch id")
+	}
+	if _, err := epochid.BytesToEpochID(r.ToEpochID); err != nil {
+		return errcode.ErrInvalidEpochID.Wrapf(err, "invalid last epoch id")
+	}
+	if bytes.Compare(r.FromEpochID, r.ToEpochID) > 0 {
+		return errcode.ErrInvalidRequest.Errorf("first epoch id %s is after last epoch id %s", r.FromEpochID, r.ToEpochID)
+	}
+	return nil
+}
+
+// Rebroadcast sends the stored decryption keys of the epochs in the range, and the stored shares
+// of those epochs in the range whose key is not known. Each share is sent in a message of its own,
+// as the original messages were. Nothing is sent if the range holds more than MaxMessages keys or
+// shares.
+func (rb *Rebroadcaster) Rebroadcast(ctx context.Context, r Range) (Result, error) {
+	result := Result{}
+	if err := r.Validate(); err != nil {
+		return result, err
+	}
+	db := kprdb.New(rb.dbpool)
+	keys, err := db.GetDecryptionKeysInRange(ctx, kprdb.GetDecryptionKeysInRangeParams{
+		Eon:         int64(r.Eon),
+		FromEpochID: r.FromEpochID,
+		ToEpochID:   r.ToEpochID,
+		MaxKeys:     MaxMessages + 1,
+	})
+	if err != nil {
+		return result, errcode.WrapDB(err, "failed to get decryption keys from db")
+	}
+	if len(keys) > MaxMessages {
+		return result, errcode.ErrInvalidRequest.Errorf("range holds more than %d decryption keys", MaxMessages)
+	}
+	shares, err := db.GetDecryptionKeySharesInRange(ctx, kprdb.GetDecryptionKeySharesInRangeParams{
+		Eon:         int64(r.Eon),
+		FromEpochID: r.FromEpochID,
+		ToEpochID:   r.ToEpochID,
+		MaxShares:   MaxMessages + 1,
+	})
+	if err != nil {
+		return result, errcode.WrapDB(err, "failed to get decryption key shares from db")
+	}
+	if len(shares) > MaxMessages {
+		return result, errcode.ErrInvalidRequest.Errorf("range holds more than %d decryption key shares", MaxMessages)
+	}
+
+	finalized := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		msg := &p2pmsg.DecryptionKey{
+			InstanceID: rb.instanceID,
+			Eon:        r.Eon,
+			EpochID:    key.EpochID,
+			Key:        key.DecryptionKey,
+		}
+		if err := rb.sender.SendMessage(ctx, msg); err != nil {
+			return result, err
+		}
+		finalized[string(key.EpochID)] = true
+		result.Keys++
+	}
+	for _, share := range shares {
+		// peers that receive the key don't need the shares
+		if finalized[string(share.EpochID)] {
+			continue
+		}
+		msg := &p2pmsg.DecryptionKeyShares{
+			InstanceID:  rb.instanceID,
+			Eon:         r.Eon,
+			KeyperIndex: uint64(share.KeyperIndex),
+			Shares: []*p2pmsg.KeyShare{{
+				EpochID: share.EpochID,
+				Share:   share.DecryptionKeyShare,
+			}},
+		}
+		if err := rb.sender.SendMessage(ctx, msg); err != nil {
+			return result, err
+		}
+		result.Shares++
+	}
+	log.Info().
+		Uint64("eon", r.Eon).
+		Str("from-epoch-id", r.FromEpochID.String()).
+		Str("to-epoch-id", r.ToEpochID.String()).
+		Int("num-keys", result.Keys).
+		Int("num-shares", result.Shares).
+		Msg("rebroadcast decryption keys and shares")
+	return result, nil
+}
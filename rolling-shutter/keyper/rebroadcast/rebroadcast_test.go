@@ -0,0 +1,84 @@
+package rebroadcast
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+type testSender struct {
+	msgs []p2pmsg.Message
+}
+
+func (s *testSender) SendMessage(_ context.Context, msg p2pmsg.Message, _ ...retry.Option) error {
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
+func epoch(n uint64) []byte {
+	return epochid.Uint64ToEpochID(n).Bytes()
+}
+
+func TestValidate(t *testing.T) {
+	assert.NilError(t, Range{Eon: 1, FromEpochID: epoch(1), ToEpochID: epoch(1)}.Validate())
+	err := Range{FromEpochID: epoch(2), ToEpochID: epoch(1)}.Validate()
+	assert.Equal(t, errcode.Of(err), errcode.ErrInvalidRequest)
+	err = Range{FromEpochID: []byte{1}, ToEpochID: epoch(1)}.Validate()
+	assert.Equal(t, errcode.Of(err), errcode.ErrInvalidEpochID)
+	err = Range{Eon: 1 << 63, FromEpochID: epoch(1), ToEpochID: epoch(1)}.Validate()
+	assert.Equal(t, errcode.Of(err), errcode.ErrInvalidRequest)
+}
+
+func TestRebroadcastIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	db, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	// epoch 2 is finalized, epoch 3 is not
+	_, err := db.InsertDecryptionKey(ctx, kprdb.InsertDecryptionKeyParams{
+		Eon: 1, EpochID: epoch(2), DecryptionKey: []byte("key"),
+	})
+	assert.NilError(t, err)
+	for _, epochID := range []uint64{2, 3, 4} {
+		for keyperIndex := int64(0); keyperIndex < 2; keyperIndex++ {
+			err := db.InsertDecryptionKeyShare(ctx, kprdb.InsertDecryptionKeyShareParams{
+				Eon: 1, EpochID: epoch(epochID), KeyperIndex: keyperIndex, DecryptionKeyShare: []byte("share"),
+			})
+			assert.NilError(t, err)
+		}
+	}
+
+	sender := &testSender{}
+	result, err := New(dbpool, 7, sender).Rebroadcast(ctx, Range{Eon: 1, FromEpochID: epoch(1), ToEpochID: epoch(3)})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, result, Result{Keys: 1, Shares: 2})
+	assert.Equal(t, len(sender.msgs), 3)
+	key := sender.msgs[0].(*p2pmsg.DecryptionKey)
+	assert.Equal(t, key.InstanceID, uint64(7))
+	assert.DeepEqual(t, key.EpochID, epoch(2))
+	assert.DeepEqual(t, key.Key, []byte("key"))
+	for i, msg := range sender.msgs[1:] {
+		shares := msg.(*p2pmsg.DecryptionKeyShares)
+		assert.Equal(t, shares.KeyperIndex, uint64(i))
+		assert.Equal(t, len(shares.Shares), 1)
+		assert.DeepEqual(t, shares.Shares[0].EpochID, epoch(3))
+	}
+
+	// other eons are not touched
+	sender = &testSender{}
+	result, err = New(dbpool, 7, sender).Rebroadcast(ctx, Range{Eon: 2, FromEpochID: epoch(1), ToEpochID: epoch(3)})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, result, Result{})
+	assert.Equal(t, len(sender.msgs), 0)
+}
@@ -0,0 +1,38 @@
+package revelation
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+)
+
+// Router serves the proofs at /{eon}/{epochID}.
+func (p *Prover) Router() http.Handler {
+	router := chi.NewRouter()
+	router.Get("/{eon}/{epochID}", p.handleProof)
+	return router
+}
+
+func (p *Prover) handleProof(w http.ResponseWriter, r *http.Request) {
+	eon, err := strconv.ParseUint(chi.URLParam(r, "eon"), 10, 64)
+	if err != nil {
+		errcode.SendError(w, errcode.ErrInvalidRequest.Wrapf(err, "invalid eon"))
+		return
+	}
+	epochID, err := hex.DecodeString(strings.TrimPrefix(chi.URLParam(r, "epochID"), "0x"))
+	if err != nil {
+		errcode.SendError(w, errcode.ErrInvalidEpochID.Wrap(err))
+		return
+	}
+	proof, err := p.Prove(r.Context(), eon, epochID)
+	if err != nil {
+		errcode.SendError(w, err)
+		return
+	}
+	errcode.WriteJSON(w, http.StatusOK, proof)
+}
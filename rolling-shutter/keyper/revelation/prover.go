@@ -0,0 +1,125 @@
+package revelation
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// Prover creates proofs from the keys the keyper knows and signs them with its key.
+type Prover struct {
+	dbpool  *pgxpool.Pool
+	domain  p2pmsg.SigningDomain
+	privKey *ecdsa.PrivateKey
+}
+
+// NewProver creates a prover for the keyper with the given key. The signing domain is the one eon
+// public keys are signed in.
+func NewProver(dbpool *pgxpool.Pool, domain p2pmsg.SigningDomain, privKey *ecdsa.PrivateKey) *Prover {
+	return &Prover{dbpool: dbpool, domain: domain, privKey: privKey}
+}
+
+// Prove creates the proof of the decryption key of an epoch. The proof is verified before it is
+// signed, so that the keyper never vouches for an inconsistent proof.
+func (p *Prover) Prove(ctx context.Context, eon uint64, epochID []byte) (*Proof, error) {
+	bundle := Bundle{
+		ChainID:    p.domain.ChainID,
+		InstanceID: p.domain.InstanceID,
+		Eon:        eon,
+		EpochID:    epochID,
+		CreatedAt:  time.Now().Unix(),
+	}
+	err := p.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return loadBundle(ctx, tx, &bundle)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyVotes(&bundle); err != nil {
+		return nil, errors.WithMessage(err, "stored votes don't confirm the eon public key")
+	}
+	if err := verifyDecryptionKey(&bundle); err != nil {
+		return nil, errors.WithMessage(err, "stored decryption key is invalid")
+	}
+	signature, err := ethcrypto.Sign(bundle.Hash(), p.privKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Proof{
+		Bundle:    bundle,
+		Signer:    ethcrypto.PubkeyToAddress(p.privKey.PublicKey),
+		Signature: signature,
+	}, nil
+}
+
+// loadBundle fills in the keys, votes and keyper set of the bundle's eon and epoch.
+func loadBundle(ctx context.Context, tx pgx.Tx, bundle *Bundle) error {
+	db := kprdb.New(tx)
+	decryptionKey, err := db.GetDecryptionKey(ctx, kprdb.GetDecryptionKeyParams{
+		Eon:     int64(bundle.Eon),
+		EpochID: bundle.EpochID,
+	})
+	if err == pgx.ErrNoRows {
+		return errcode.ErrDecryptionKeyNotFound.Errorf("no decryption key found for given epoch")
+	} else if err != nil {
+		return errcode.WrapDB(err, "failed to get decryption key from db")
+	}
+	bundle.DecryptionKey = decryptionKey.DecryptionKey
+
+	finalized, err := db.GetFinalizedEpoch(ctx, kprdb.GetFinalizedEpochParams{
+		Eon:     int64(bundle.Eon),
+		EpochID: bundle.EpochID,
+	})
+	if err == nil {
+		finalizedAt := finalized.FinalizedAt.Unix()
+		bundle.FinalizedAt = &finalizedAt
+	} else if err != pgx.ErrNoRows {
+		return errcode.WrapDB(err, "failed to get finalized epoch from db")
+	}
+
+	candidate, err := db.GetConfirmedEonPublicKey(ctx, int64(bundle.Eon))
+	if err == pgx.ErrNoRows {
+		return errcode.ErrEonKeyNotFound.Errorf("no confirmed eon public key found for given eon")
+	} else if err != nil {
+		return errcode.WrapDB(err, "failed to get confirmed eon public key from db")
+	}
+	votes, err := db.FindEonPublicKeyVotes(ctx, candidate.Hash)
+	if err != nil {
+		return errcode.WrapDB(err, "failed to get eon public key votes from db")
+	}
+	bundle.EonKey = EonKey{
+		PublicKey:             candidate.EonPublicKey,
+		ActivationBlockNumber: uint64(candidate.ActivationBlockNumber),
+		KeyperConfigIndex:     uint64(candidate.KeyperConfigIndex),
+		Votes:                 []Vote{},
+	}
+	for _, vote := range votes {
+		sender, err := shdb.DecodeAddress(vote.Sender)
+		if err != nil {
+			return err
+		}
+		bundle.EonKey.Votes = append(bundle.EonKey.Votes, Vote{Sender: sender, Signature: vote.Signature})
+	}
+
+	keyperSet, err := chainobsdb.New(tx).GetKeyperSetByKeyperConfigIndex(ctx, candidate.KeyperConfigIndex)
+	if err != nil {
+		return errcode.WrapDB(err, "failed to get keyper set %d from db", candidate.KeyperConfigIndex)
+	}
+	keypers, err := shdb.DecodeAddresses(keyperSet.Keypers)
+	if err != nil {
+		return err
+	}
+	bundle.KeyperSet = KeyperSet{Keypers: keypers, Threshold: uint64(keyperSet.Threshold)}
+	return nil
+}
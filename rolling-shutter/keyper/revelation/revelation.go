@@ -0,0 +1,154 @@
+// Package revelation packages everything needed to prove that the decryption key of an identity,
+// i.e. an epoch id, has been revealed correctly into a single JSON document, e.g. for sealed-bid
+// auctions whose participants want to check that bids were opened with the right key. A proof
+// contains the eon public key together with the votes of the keyper set that confirmed it, the
+// keyper set itself, the decryption key and when it became known. The keyper creating the proof
+// signs it, so that it can be verified offline with nothing but the proof.
+package revelation
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+var hashPrefix = []byte("\x19Shutter key revelation proof:\n")
+
+// Bundle is the content of a proof.
+type Bundle struct {
+	ChainID       uint64        `json:"chainID"`
+	InstanceID    uint64        `json:"instanceID"`
+	Eon           uint64        `json:"eon"`
+	EpochID       hexutil.Bytes `json:"epochID"`
+	EonKey        EonKey        `json:"eonKey"`
+	KeyperSet     KeyperSet     `json:"keyperSet"`
+	DecryptionKey hexutil.Bytes `json:"decryptionKey"`
+	// FinalizedAt is when the decryption key became known to the keyper, as unix timestamp. It's
+	// omitted if the keyper doesn't remember it anymore.
+	FinalizedAt *int64 `json:"finalizedAt,omitempty"`
+	// CreatedAt is when the proof was created, as unix timestamp.
+	CreatedAt int64 `json:"createdAt"`
+}
+
+// EonKey is the eon public key the decryption key belongs to, together with the votes of the
+// keypers that confirmed it.
+type EonKey struct {
+	PublicKey             hexutil.Bytes `json:"publicKey"`
+	ActivationBlockNumber uint64        `json:"activationBlockNumber"`
+	KeyperConfigIndex     uint64        `json:"keyperConfigIndex"`
+	Votes                 []Vote        `json:"votes"`
+}
+
+// Vote is the signature of a keyper on the eon public key, as broadcast during key generation.
+type Vote struct {
+	Sender    common.Address `json:"sender"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// KeyperSet is the keyper set that generated the eon key.
+type KeyperSet struct {
+	Keypers   []common.Address `json:"keypers"`
+	Threshold uint64           `json:"threshold"`
+}
+
+// Proof is a bundle signed by the keyper that created it.
+type Proof struct {
+	Bundle    Bundle         `json:"bundle"`
+	Signer    common.Address `json:"signer"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// Hash returns the hash the keyper signs. It covers the JSON encoding of the bundle.
+func (b *Bundle) Hash() []byte {
+	payload, err := json.Marshal(b)
+	if err != nil {
+		panic(err) // a Bundle can always be encoded
+	}
+	return ethcrypto.Keccak256(hashPrefix, payload)
+}
+
+// signingDomain returns the domain the votes on the eon public key are signed in.
+func (b *Bundle) signingDomain() p2pmsg.SigningDomain {
+	return p2pmsg.SigningDomain{ChainID: b.ChainID, InstanceID: b.InstanceID}
+}
+
+// eonPublicKeyMessage returns the message the keypers signed to vote for the eon public key.
+func (b *Bundle) eonPublicKeyMessage(signature []byte) *p2pmsg.EonPublicKey {
+	return &p2pmsg.EonPublicKey{
+		InstanceID:        b.InstanceID,
+		PublicKey:         b.EonKey.PublicKey,
+		ActivationBlock:   b.EonKey.ActivationBlockNumber,
+		KeyperConfigIndex: b.EonKey.KeyperConfigIndex,
+		Eon:               b.Eon,
+		Signature:         signature,
+	}
+}
+
+// Verify checks that the proof has been signed by its signer, that threshold many keypers of the
+// keyper set voted for the eon public key and that the decryption key is the one of the epoch for
+// the eon public key. Whether the signer and the keyper set are trusted is up to the caller.
+func Verify(proof *Proof) error {
+	pubkey, err := ethcrypto.SigToPub(proof.Bundle.Hash(), proof.Signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+	if signer := ethcrypto.PubkeyToAddress(*pubkey); signer != proof.Signer {
+		return errors.Errorf("proof signed by %s instead of %s", signer.Hex(), proof.Signer.Hex())
+	}
+	if err := verifyVotes(&proof.Bundle); err != nil {
+		return err
+	}
+	return verifyDecryptionKey(&proof.Bundle)
+}
+
+func verifyVotes(b *Bundle) error {
+	keypers := map[common.Address]bool{}
+	for _, keyper := range b.KeyperSet.Keypers {
+		keypers[keyper] = true
+	}
+	isKeyper := func(address common.Address) bool {
+		return keypers[address]
+	}
+	voters := map[common.Address]bool{}
+	for _, vote := range b.EonKey.Votes {
+		// keyper sets that haven't switched to signing in the domain yet sign with the legacy hash
+		signer, err := p2pmsg.RecoverSigner(b.eonPublicKeyMessage(vote.Signature), b.signingDomain(), true, isKeyper)
+		if err != nil {
+			return errors.WithMessagef(err, "invalid vote of %s", vote.Sender.Hex())
+		}
+		if signer != vote.Sender {
+			return errors.Errorf("vote of %s signed by %s", vote.Sender.Hex(), signer.Hex())
+		}
+		voters[signer] = true
+	}
+	if b.KeyperSet.Threshold == 0 || uint64(len(voters)) < b.KeyperSet.Threshold {
+		return errors.Errorf("eon public key confirmed by %d keypers, threshold is %d",
+			len(voters), b.KeyperSet.Threshold)
+	}
+	return nil
+}
+
+func verifyDecryptionKey(b *Bundle) error {
+	eonPublicKey := new(shcrypto.EonPublicKey)
+	if err := eonPublicKey.GobDecode(b.EonKey.PublicKey); err != nil {
+		return errors.Wrap(err, "failed to decode eon public key")
+	}
+	epochSecretKey := new(shcrypto.EpochSecretKey)
+	if err := epochSecretKey.GobDecode(b.DecryptionKey); err != nil {
+		return errors.Wrap(err, "failed to decode decryption key")
+	}
+	ok, err := shcrypto.VerifyEpochSecretKey(epochSecretKey, eonPublicKey, b.EpochID)
+	if err != nil {
+		return errors.Wrap(err, "failed to verify decryption key")
+	}
+	if !ok {
+		return errors.New("decryption key doesn't belong to the epoch and eon public key")
+	}
+	return nil
+}
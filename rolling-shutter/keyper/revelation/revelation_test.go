@@ -0,0 +1,116 @@
+package revelation
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testkeygen"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+type testSetup struct {
+	keyperKeys []*ecdsa.PrivateKey
+	nodeKey    *ecdsa.PrivateKey
+	eonKeys    *testkeygen.EonKeys
+	bundle     Bundle
+}
+
+func newTestSetup(t *testing.T) *testSetup {
+	t.Helper()
+	s := &testSetup{}
+	keypers := []common.Address{}
+	for i := 0; i < 3; i++ {
+		key, err := ethcrypto.GenerateKey()
+		assert.NilError(t, err)
+		s.keyperKeys = append(s.keyperKeys, key)
+		keypers = append(keypers, ethcrypto.PubkeyToAddress(key.PublicKey))
+	}
+	s.nodeKey = s.keyperKeys[0]
+	var err error
+	s.eonKeys, err = testkeygen.NewEonKeys(rand.Reader, 3, 2)
+	assert.NilError(t, err)
+	epochID := epochid.Uint64ToEpochID(7)
+	epochSecretKey, err := s.eonKeys.EpochSecretKey(epochID)
+	assert.NilError(t, err)
+	decryptionKey, err := epochSecretKey.GobEncode()
+	assert.NilError(t, err)
+	eonPublicKey, err := s.eonKeys.PublicKey().GobEncode()
+	assert.NilError(t, err)
+
+	s.bundle = Bundle{
+		ChainID:    1,
+		InstanceID: 42,
+		Eon:        3,
+		EpochID:    epochID.Bytes(),
+		EonKey: EonKey{
+			PublicKey:             eonPublicKey,
+			ActivationBlockNumber: 100,
+			KeyperConfigIndex:     2,
+		},
+		KeyperSet:     KeyperSet{Keypers: keypers, Threshold: 2},
+		DecryptionKey: decryptionKey,
+		CreatedAt:     1700000000,
+	}
+	// one keyper votes in the signing domain, the other with the legacy hash
+	s.addVote(t, s.keyperKeys[0], true)
+	s.addVote(t, s.keyperKeys[1], false)
+	return s
+}
+
+func (s *testSetup) addVote(t *testing.T, key *ecdsa.PrivateKey, inDomain bool) {
+	t.Helper()
+	msg := s.bundle.eonPublicKeyMessage(nil)
+	if inDomain {
+		assert.NilError(t, p2pmsg.SignInDomain(msg, s.bundle.signingDomain(), key))
+	} else {
+		assert.NilError(t, p2pmsg.Sign(msg, key))
+	}
+	s.bundle.EonKey.Votes = append(s.bundle.EonKey.Votes, Vote{
+		Sender:    ethcrypto.PubkeyToAddress(key.PublicKey),
+		Signature: msg.Signature,
+	})
+}
+
+func (s *testSetup) proof(t *testing.T) *Proof {
+	t.Helper()
+	signature, err := ethcrypto.Sign(s.bundle.Hash(), s.nodeKey)
+	assert.NilError(t, err)
+	return &Proof{
+		Bundle:    s.bundle,
+		Signer:    ethcrypto.PubkeyToAddress(s.nodeKey.PublicKey),
+		Signature: signature,
+	}
+}
+
+func TestVerify(t *testing.T) {
+	s := newTestSetup(t)
+	assert.NilError(t, Verify(s.proof(t)))
+
+	proof := s.proof(t)
+	proof.Bundle.CreatedAt++
+	assert.ErrorContains(t, Verify(proof), "proof signed by")
+
+	s = newTestSetup(t)
+	s.bundle.EonKey.Votes = s.bundle.EonKey.Votes[:1]
+	assert.ErrorContains(t, Verify(s.proof(t)), "confirmed by 1 keypers, threshold is 2")
+
+	s = newTestSetup(t)
+	outsider, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	s.bundle.EonKey.Votes = s.bundle.EonKey.Votes[:1]
+	s.addVote(t, outsider, true)
+	assert.ErrorContains(t, Verify(s.proof(t)), "invalid vote")
+
+	s = newTestSetup(t)
+	otherKey, err := s.eonKeys.EpochSecretKey(epochid.Uint64ToEpochID(8))
+	assert.NilError(t, err)
+	s.bundle.DecryptionKey, err = otherKey.GobEncode()
+	assert.NilError(t, err)
+	assert.ErrorContains(t, Verify(s.proof(t)), "decryption key doesn't belong")
+}
@@ -0,0 +1,68 @@
+package keyper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/chainobserver"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/auditdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// MigrateRotatedKeyper moves the rows of the keyper database referring to the old address of a
+// rotated keyper to its new address, in the transaction the rotation is handled in. As the keyper
+// keeps its index, its eon key share stays valid and nothing has to be redone. Votes and DKG
+// transcripts are not touched, as they record what has been signed and sent under the old address.
+func MigrateRotatedKeyper(ctx context.Context, chainDB *chainobsdb.Queries, r chainobserver.KeyperRotation) error {
+	db := kprdb.New(chainDB.Conn())
+	oldAddress := shdb.EncodeAddress(r.OldAddress)
+	newAddress := shdb.EncodeAddress(r.NewAddress)
+
+	// The batch config only exists once it has been sent to shuttermint. Later rotations are
+	// picked up from the keyper set when it's sent.
+	rows, err := db.RotateBatchConfigAddress(ctx, kprdb.RotateBatchConfigAddressParams{
+		KeyperIndex:       int32(r.KeyperIndex),
+		NewAddress:        newAddress,
+		KeyperConfigIndex: int32(r.KeyperConfigIndex),
+		OldAddress:        oldAddress,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to rotate keyper address in batch config")
+	}
+	err = db.CopyEncryptionKey(ctx, kprdb.CopyEncryptionKeyParams{
+		NewAddress: newAddress,
+		OldAddress: oldAddress,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to copy encryption key to new keyper address")
+	}
+	polyEvals, err := db.RotatePolyEvalsReceiver(ctx, kprdb.RotatePolyEvalsReceiverParams{
+		NewAddress:        newAddress,
+		OldAddress:        oldAddress,
+		KeyperConfigIndex: int64(r.KeyperConfigIndex),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to rotate receiver address of poly evals")
+	}
+	log.Info().
+		Uint64("keyper-config-index", r.KeyperConfigIndex).
+		Uint64("keyper-index", r.KeyperIndex).
+		Int64("batch-configs", rows).
+		Int64("poly-evals", polyEvals).
+		Msg("migrated keyper db to rotated keyper address")
+
+	err = db.AuditLog().InsertAuditLogEntry(ctx, auditdb.InsertAuditLogEntryParams{
+		Source: auditdb.SourceChain,
+		Actor:  oldAddress,
+		Action: "KeyperRotated",
+		Details: fmt.Sprintf("block-number=%d log-index=%d keyper-config-index=%d keyper-index=%d new-address=%s",
+			r.Raw.BlockNumber, r.Raw.Index, r.KeyperConfigIndex, r.KeyperIndex, newAddress),
+		TriggerHash: r.Raw.TxHash.Bytes(),
+	})
+	return errors.Wrap(err, "failed to insert audit log entry")
+}
@@ -0,0 +1,72 @@
+package shadow
+
+import (
+	"io"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+type Config struct {
+	Enabled         bool              `comment:"Run as a shadow node: do all computations, but don't send any messages to the p2p network or to shuttermint. The node must use its own copy of the live node's database and may use the live node's keys"`
+	LiveDatabaseURL string            `comment:"Database of the live node the decisions of the shadow node are compared with, e.g. a read replica. If it's empty, nothing is compared"`
+	CompareInterval *enctime.Duration `comment:"How often the decisions are compared"`
+	MaxEpochs       uint64            `comment:"Number of most recent finalized epochs whose keys and key shares are compared"`
+}
+
+func (c *Config) Init() {
+	c.CompareInterval = &enctime.Duration{}
+}
+
+func (c *Config) Name() string {
+	return "shadow"
+}
+
+// Comparing reports whether the decisions of the shadow node are compared with the live node.
+func (c *Config) Comparing() bool {
+	return c.Enabled && c.LiveDatabaseURL != ""
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled && c.LiveDatabaseURL != "" {
+		return errors.New("shadow LiveDatabaseURL is set, but shadow mode is not enabled")
+	}
+	if !c.Comparing() {
+		return nil
+	}
+	if c.CompareInterval.Duration <= 0 {
+		return errors.New("shadow CompareInterval must be positive")
+	}
+	if c.MaxEpochs == 0 || c.MaxEpochs > math.MaxInt32 {
+		return errors.Errorf("shadow MaxEpochs must be between 1 and %d", math.MaxInt32)
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.Enabled = false
+	c.LiveDatabaseURL = ""
+	c.CompareInterval = &enctime.Duration{Duration: time.Minute}
+	c.MaxEpochs = 1000
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,27 @@
+package shadow
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsDivergences = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "shadow",
+		Name:      "divergences_total",
+		Help:      "Number of decisions of the shadow node that differ from the ones of the live node",
+	},
+	[]string{"kind"},
+)
+
+var metricsComparisons = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "shadow",
+		Name:      "comparisons_total",
+		Help:      "Number of times the decisions of the shadow node have been compared with the live node",
+	},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsDivergences)
+	prometheus.MustRegister(metricsComparisons)
+}
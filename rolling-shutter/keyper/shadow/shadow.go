@@ -0,0 +1,303 @@
+// Package shadow implements the shadow mode of the keyper, which lets operators try out a new
+// release on production inputs before upgrading their live node. A shadow node follows the same
+// chain and gossip as the live node and does all the same computations, but it doesn't publish
+// anything. The Comparator periodically compares its decisions with the ones the live node stored
+// in its database and reports each divergence.
+//
+// The shadow node must run on its own copy of the live node's database. DKGs that run after the
+// copy was made are based on a random polynomial the shadow node never sent, so the eon key share
+// of the shadow node differs from the live one. The decryption key shares of such eons are not
+// compared.
+package shadow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// The kinds of decisions that are compared.
+const (
+	KindDKGOutcome         = "dkg-outcome"
+	KindEonPublicKey       = "eon-public-key"
+	KindDecryptionKey      = "decryption-key"
+	KindDecryptionKeyShare = "decryption-key-share"
+)
+
+// Divergence is a decision of the shadow node that differs from the one of the live node. EpochID
+// is nil for decisions that concern a whole eon. Shadow and Live describe the decisions.
+type Divergence struct {
+	Kind    string
+	Eon     int64
+	EpochID []byte
+	Shadow  string
+	Live    string
+}
+
+func (d Divergence) key() string {
+	return fmt.Sprintf("%s/%d/%x", d.Kind, d.Eon, d.EpochID)
+}
+
+type epochKey struct {
+	eon     int64
+	epochID string
+}
+
+// decisions are the decisions of a node about the given eons and epochs. Decisions the node
+// hasn't made yet are missing from the maps.
+type decisions struct {
+	dkgResults     map[int64]kprdb.DkgResult
+	eonPublicKeys  map[int64][]byte
+	decryptionKeys map[epochKey][]byte
+	keyShares      map[epochKey][]byte
+}
+
+// loadDecisions loads the decisions a node stored in db about the given eons and epochs.
+func loadDecisions(
+	ctx context.Context, db *kprdb.Queries, eons []int64, epochs []kprdb.GetRecentFinalizedEpochsRow,
+) (*decisions, error) {
+	d := &decisions{
+		dkgResults:     make(map[int64]kprdb.DkgResult),
+		eonPublicKeys:  make(map[int64][]byte),
+		decryptionKeys: make(map[epochKey][]byte),
+		keyShares:      make(map[epochKey][]byte),
+	}
+	keyperIndices := make(map[int64]int64)
+	for _, eon := range eons {
+		dkgResult, err := db.GetDKGResult(ctx, eon)
+		if err != nil && err != pgx.ErrNoRows {
+			return nil, errors.Wrapf(err, "failed to get dkg result of eon %d from db", eon)
+		}
+		if err == nil {
+			d.dkgResults[eon] = dkgResult
+			if dkgResult.Success {
+				pureResult, err := shdb.DecodePureDKGResult(dkgResult.PureResult)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to decode dkg result of eon %d", eon)
+				}
+				keyperIndices[eon] = int64(pureResult.Keyper)
+			}
+		}
+
+		key, err := db.GetConfirmedEonPublicKey(ctx, eon)
+		if err != nil && err != pgx.ErrNoRows {
+			return nil, errors.Wrapf(err, "failed to get eon public key of eon %d from db", eon)
+		}
+		if err == nil {
+			d.eonPublicKeys[eon] = key.EonPublicKey
+		}
+	}
+
+	for _, epoch := range epochs {
+		k := epochKey{eon: epoch.Eon, epochID: string(epoch.EpochID)}
+		key, err := db.GetDecryptionKey(ctx, kprdb.GetDecryptionKeyParams{Eon: epoch.Eon, EpochID: epoch.EpochID})
+		if err != nil && err != pgx.ErrNoRows {
+			return nil, errors.Wrap(err, "failed to get decryption key from db")
+		}
+		if err == nil {
+			d.decryptionKeys[k] = key.DecryptionKey
+		}
+
+		keyperIndex, ok := keyperIndices[epoch.Eon]
+		if !ok {
+			continue
+		}
+		share, err := db.GetDecryptionKeyShare(ctx, kprdb.GetDecryptionKeyShareParams{
+			Eon:         epoch.Eon,
+			EpochID:     epoch.EpochID,
+			KeyperIndex: keyperIndex,
+		})
+		if err != nil && err != pgx.ErrNoRows {
+			return nil, errors.Wrap(err, "failed to get decryption key share from db")
+		}
+		if err == nil {
+			d.keyShares[k] = share.DecryptionKeyShare
+		}
+	}
+	return d, nil
+}
+
+// diff returns the decisions that both nodes have made, but differently. Decryption key shares are
+// only compared if both nodes got the same eon key share from the DKG.
+func diff(shadow, live *decisions) []Divergence {
+	divergences := []Divergence{}
+	for eon, shadowResult := range shadow.dkgResults {
+		liveResult, ok := live.dkgResults[eon]
+		if ok && shadowResult.Success != liveResult.Success {
+			divergences = append(divergences, Divergence{
+				Kind:   KindDKGOutcome,
+				Eon:    eon,
+				Shadow: fmt.Sprint(shadowResult.Success),
+				Live:   fmt.Sprint(liveResult.Success),
+			})
+		}
+	}
+	for eon, shadowKey := range shadow.eonPublicKeys {
+		liveKey, ok := live.eonPublicKeys[eon]
+		if ok && !bytes.Equal(shadowKey, liveKey) {
+			divergences = append(divergences, Divergence{
+				Kind:   KindEonPublicKey,
+				Eon:    eon,
+				Shadow: hexutil.Encode(shadowKey),
+				Live:   hexutil.Encode(liveKey),
+			})
+		}
+	}
+	for k, shadowKey := range shadow.decryptionKeys {
+		liveKey, ok := live.decryptionKeys[k]
+		if ok && !bytes.Equal(shadowKey, liveKey) {
+			divergences = append(divergences, Divergence{
+				Kind:    KindDecryptionKey,
+				Eon:     k.eon,
+				EpochID: []byte(k.epochID),
+				Shadow:  hexutil.Encode(shadowKey),
+				Live:    hexutil.Encode(liveKey),
+			})
+		}
+	}
+	for k, shadowShare := range shadow.keyShares {
+		liveShare, ok := live.keyShares[k]
+		if !ok || bytes.Equal(shadowShare, liveShare) {
+			continue
+		}
+		if !bytes.Equal(shadow.dkgResults[k.eon].PureResult, live.dkgResults[k.eon].PureResult) {
+			continue
+		}
+		divergences = append(divergences, Divergence{
+			Kind:    KindDecryptionKeyShare,
+			Eon:     k.eon,
+			EpochID: []byte(k.epochID),
+			Shadow:  hexutil.Encode(shadowShare),
+			Live:    hexutil.Encode(liveShare),
+		})
+	}
+	return divergences
+}
+
+// Comparator periodically compares the decisions of the shadow node with the ones of the live node
+// and reports each divergence once.
+type Comparator struct {
+	config   *Config
+	dbpool   *pgxpool.Pool
+	reported map[string]bool
+}
+
+func NewComparator(config *Config, dbpool *pgxpool.Pool) *Comparator {
+	return &Comparator{
+		config:   config,
+		dbpool:   dbpool,
+		reported: make(map[string]bool),
+	}
+}
+
+var _ service.Service = &Comparator{}
+
+// connectLive opens a connection pool to the live node's database whose transactions are
+// read-only, so that the shadow node can't modify it even if the role it connects as could.
+func connectLive(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid live database url")
+	}
+	poolConfig.ConnConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	dbpool, err := pgxpool.ConnectConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to live database")
+	}
+	return dbpool, nil
+}
+
+func (c *Comparator) Start(ctx context.Context, runner service.Runner) error {
+	livePool, err := connectLive(ctx, c.config.LiveDatabaseURL)
+	if err != nil {
+		return err
+	}
+	runner.Defer(livePool.Close)
+	// The live node may still run a release with an older schema, so the schema version of its
+	// database isn't validated.
+	shdb.AddConnectionInfo(log.Info(), livePool).Msg("connected to live database")
+	runner.Go(func() error {
+		return c.run(ctx, kprdb.New(livePool))
+	})
+	return nil
+}
+
+func (c *Comparator) run(ctx context.Context, live *kprdb.Queries) error {
+	ticker := time.NewTicker(c.config.CompareInterval.Duration)
+	defer ticker.Stop()
+	for {
+		divergences, err := c.Compare(ctx, live)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to compare decisions with the live node")
+		} else {
+			c.report(divergences)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Compare compares the decisions of the shadow node with the ones of the live node about all eons
+// and the most recent finalized epochs of the shadow node.
+func (c *Comparator) Compare(ctx context.Context, live *kprdb.Queries) ([]Divergence, error) {
+	db := kprdb.New(c.dbpool)
+	eonRows, err := db.GetAllEons(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get eons from db")
+	}
+	eons := []int64{}
+	for _, eon := range eonRows {
+		eons = append(eons, eon.Eon)
+	}
+	epochs, err := db.GetRecentFinalizedEpochs(ctx, int32(c.config.MaxEpochs))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get finalized epochs from db")
+	}
+
+	shadowDecisions, err := loadDecisions(ctx, db, eons, epochs)
+	if err != nil {
+		return nil, err
+	}
+	liveDecisions, err := loadDecisions(ctx, live, eons, epochs)
+	if err != nil {
+		return nil, errors.Wrap(err, "live node")
+	}
+	metricsComparisons.Inc()
+	return diff(shadowDecisions, liveDecisions), nil
+}
+
+// report logs the divergences that haven't been reported before.
+func (c *Comparator) report(divergences []Divergence) int {
+	n := 0
+	for _, d := range divergences {
+		key := d.key()
+		if c.reported[key] {
+			continue
+		}
+		c.reported[key] = true
+		n++
+		metricsDivergences.WithLabelValues(d.Kind).Inc()
+		log.Error().
+			Str("kind", d.Kind).
+			Int64("eon", d.Eon).
+			Str("epoch-id", hexutil.Encode(d.EpochID)).
+			Str("shadow", d.Shadow).
+			Str("live", d.Live).
+			Msg("shadow node diverged from the live node")
+	}
+	return n
+}
@@ -0,0 +1,118 @@
+package shadow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+func newDecisions() *decisions {
+	return &decisions{
+		dkgResults:     make(map[int64]kprdb.DkgResult),
+		eonPublicKeys:  make(map[int64][]byte),
+		decryptionKeys: make(map[epochKey][]byte),
+		keyShares:      make(map[epochKey][]byte),
+	}
+}
+
+func TestDiff(t *testing.T) {
+	shadow, live := newDecisions(), newDecisions()
+	epoch := epochKey{eon: 1, epochID: "\x07"}
+	otherEpoch := epochKey{eon: 2, epochID: "\x08"}
+
+	shadow.dkgResults[1] = kprdb.DkgResult{Eon: 1, Success: true, PureResult: []byte("a")}
+	live.dkgResults[1] = kprdb.DkgResult{Eon: 1, Success: true, PureResult: []byte("a")}
+	shadow.dkgResults[2] = kprdb.DkgResult{Eon: 2, Success: true, PureResult: []byte("b")}
+	live.dkgResults[2] = kprdb.DkgResult{Eon: 2, Success: true, PureResult: []byte("c")}
+	shadow.eonPublicKeys[1] = []byte{1}
+	live.eonPublicKeys[1] = []byte{1}
+	shadow.decryptionKeys[epoch] = []byte{1}
+	// the live node hasn't made this decision yet
+	shadow.decryptionKeys[otherEpoch] = []byte{2}
+	shadow.keyShares[epoch] = []byte{1}
+	live.keyShares[epoch] = []byte{1}
+	// the eon key shares differ, so the key shares are expected to differ as well
+	shadow.keyShares[otherEpoch] = []byte{2}
+	live.keyShares[otherEpoch] = []byte{3}
+	assert.DeepEqual(t, diff(shadow, live), []Divergence{})
+
+	live.dkgResults[3] = kprdb.DkgResult{Eon: 3, Success: true}
+	shadow.dkgResults[3] = kprdb.DkgResult{Eon: 3, Success: false}
+	live.decryptionKeys[epoch] = []byte{2}
+	live.keyShares[epoch] = []byte{2}
+	divergences := diff(shadow, live)
+	kinds := make(map[string]bool)
+	for _, d := range divergences {
+		kinds[d.Kind] = true
+	}
+	assert.Equal(t, len(divergences), 3)
+	assert.Check(t, kinds[KindDKGOutcome])
+	assert.Check(t, kinds[KindDecryptionKey])
+	assert.Check(t, kinds[KindDecryptionKeyShare])
+}
+
+func TestReportOnce(t *testing.T) {
+	c := NewComparator(NewConfig(), nil)
+	divergences := []Divergence{
+		{Kind: KindDecryptionKey, Eon: 1, EpochID: []byte{7}, Shadow: "0x01", Live: "0x02"},
+		{Kind: KindDecryptionKeyShare, Eon: 1, EpochID: []byte{7}, Shadow: "0x01", Live: "0x02"},
+	}
+	assert.Equal(t, c.report(divergences), 2)
+	assert.Equal(t, c.report(divergences), 0)
+	divergences = append(divergences, Divergence{Kind: KindDecryptionKey, Eon: 1, EpochID: []byte{8}})
+	assert.Equal(t, c.report(divergences), 1)
+}
+
+func TestValidateConfig(t *testing.T) {
+	config := NewConfig()
+	assert.NilError(t, config.SetDefaultValues())
+	assert.NilError(t, config.Validate())
+
+	config.LiveDatabaseURL = "postgres://live"
+	assert.ErrorContains(t, config.Validate(), "not enabled")
+	config.Enabled = true
+	assert.NilError(t, config.Validate())
+	config.MaxEpochs = 0
+	assert.ErrorContains(t, config.Validate(), "MaxEpochs")
+}
+
+func TestLoadDecisionsIntegration(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	ctx := context.Background()
+	db, _, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	pureResult, err := shdb.EncodePureDKGResult(&puredkg.Result{Eon: 1, NumKeypers: 3, Threshold: 2, Keyper: 2})
+	assert.NilError(t, err)
+	assert.NilError(t, db.InsertDKGResult(ctx, kprdb.InsertDKGResultParams{Eon: 1, Success: true, PureResult: pureResult}))
+	epochID := []byte{7}
+	_, err = db.InsertDecryptionKey(ctx, kprdb.InsertDecryptionKeyParams{Eon: 1, EpochID: epochID, DecryptionKey: []byte{1}})
+	assert.NilError(t, err)
+	for i := int64(0); i < 3; i++ {
+		err = db.InsertDecryptionKeyShare(ctx, kprdb.InsertDecryptionKeyShareParams{
+			Eon:                1,
+			EpochID:            epochID,
+			KeyperIndex:        i,
+			DecryptionKeyShare: []byte{byte(i)},
+		})
+		assert.NilError(t, err)
+	}
+	epochs := []kprdb.GetRecentFinalizedEpochsRow{{Eon: 1, EpochID: epochID}, {Eon: 2, EpochID: epochID}}
+
+	d, err := loadDecisions(ctx, db, []int64{1, 2}, epochs)
+	assert.NilError(t, err)
+	assert.Equal(t, len(d.dkgResults), 1)
+	assert.Equal(t, len(d.eonPublicKeys), 0)
+	epoch := epochKey{eon: 1, epochID: string(epochID)}
+	assert.DeepEqual(t, d.decryptionKeys, map[epochKey][]byte{epoch: {1}})
+	// only the share of our own keyper index is loaded
+	assert.DeepEqual(t, d.keyShares, map[epochKey][]byte{epoch: {2}})
+}
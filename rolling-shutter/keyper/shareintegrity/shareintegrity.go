@@ -0,0 +1,211 @@
+// Package shareintegrity verifies the eon key shares stored in the database against the public key
+// shares stored alongside them. Keypers check all of their eons at startup and refuse to start if
+// any of them is corrupted, e.g. by a bit flip on disk or a botched database restore, instead of
+// sending invalid decryption key shares to the network. Corrupted eons can be repaired from the
+// backups written by the key escrow.
+package shareintegrity
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/alert"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// ErrCorrupted is returned by Check if the key material of at least one eon is corrupted.
+var ErrCorrupted = errors.New("eon key shares are corrupted")
+
+// Corruption describes an eon whose stored DKG result failed verification.
+type Corruption struct {
+	Eon    int64
+	Reason string
+}
+
+// VerifyResult checks that a DKG result is consistent: our eon secret key share must match our eon
+// public key share and all eon public key shares must lie on a polynomial of the threshold's degree
+// whose value at zero is the eon public key.
+func VerifyResult(result *puredkg.Result) error {
+	switch {
+	case result.SecretKeyShare == nil || result.PublicKey == nil:
+		return errors.New("key material is missing")
+	case result.Threshold == 0 || result.Threshold > result.NumKeypers:
+		return errors.Errorf("invalid threshold %d for %d keypers", result.Threshold, result.NumKeypers)
+	case uint64(len(result.PublicKeyShares)) != result.NumKeypers:
+		return errors.Errorf("got %d eon public key shares for %d keypers", len(result.PublicKeyShares), result.NumKeypers)
+	case uint64(result.Keyper) >= result.NumKeypers:
+		return errors.Errorf("keyper index %d out of range for %d keypers", result.Keyper, result.NumKeypers)
+	}
+	for i, share := range result.PublicKeyShares {
+		if share == nil {
+			return errors.Errorf("eon public key share %d is missing", i)
+		}
+	}
+
+	ownPublicKeyShare := new(bn256.G2).ScalarBaseMult((*big.Int)(result.SecretKeyShare))
+	if !shcrypto.EqualG2(ownPublicKeyShare, (*bn256.G2)(result.PublicKeyShares[result.Keyper])) {
+		return errors.New("eon secret key share does not match our eon public key share")
+	}
+
+	// Any threshold of the public key shares determine the polynomial, so we interpolate the first
+	// ones and check that the others and the public key agree with it.
+	points := make(map[int]*bn256.G2, result.Threshold)
+	for i := 0; i < int(result.Threshold); i++ {
+		points[i] = (*bn256.G2)(result.PublicKeyShares[i])
+	}
+	for i := int(result.Threshold); i < len(result.PublicKeyShares); i++ {
+		if !shcrypto.EqualG2(interpolate(points, shcrypto.KeyperX(i)), (*bn256.G2)(result.PublicKeyShares[i])) {
+			return errors.Errorf("eon public key share %d is inconsistent with the others", i)
+		}
+	}
+	if !shcrypto.EqualG2(interpolate(points, big.NewInt(0)), (*bn256.G2)(result.PublicKey)) {
+		return errors.New("eon public key is inconsistent with the eon public key shares")
+	}
+	return nil
+}
+
+// interpolate computes the value at x of the polynomial in the exponent going through the given
+// points, where the point of index i is at x = i+1.
+func interpolate(points map[int]*bn256.G2, x *big.Int) *bn256.G2 {
+	var result *bn256.G2
+	for i, y := range points {
+		xi := shcrypto.KeyperX(i)
+		numerator := big.NewInt(1)
+		denominator := big.NewInt(1)
+		for j := range points {
+			if j == i {
+				continue
+			}
+			xj := shcrypto.KeyperX(j)
+			numerator.Mul(numerator, new(big.Int).Sub(x, xj))
+			numerator.Mod(numerator, bn256.Order)
+			denominator.Mul(denominator, new(big.Int).Sub(xi, xj))
+			denominator.Mod(denominator, bn256.Order)
+		}
+		lambda := numerator.Mul(numerator, new(big.Int).ModInverse(denominator, bn256.Order))
+		lambda.Mod(lambda, bn256.Order)
+		term := new(bn256.G2).ScalarMult(y, lambda)
+		if result == nil {
+			result = term
+		} else {
+			result.Add(result, term)
+		}
+	}
+	return result
+}
+
+// verifyStored decodes and verifies the DKG result of an eon. If the eon public key has been
+// confirmed by the keyper set, the result must match it as well.
+func verifyStored(ctx context.Context, db *kprdb.Queries, eon int64, pureResult []byte) (*puredkg.Result, error) {
+	result, err := shdb.DecodePureDKGResult(pureResult)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode DKG result")
+	}
+	if int64(result.Eon) != eon {
+		return nil, errors.Errorf("DKG result is for eon %d", result.Eon)
+	}
+	if err := VerifyResult(result); err != nil {
+		return nil, err
+	}
+	candidate, err := db.GetConfirmedEonPublicKey(ctx, eon)
+	if err == pgx.ErrNoRows {
+		return result, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to query confirmed eon public key")
+	}
+	if string(candidate.EonPublicKey) != string(result.PublicKey.Marshal()) {
+		return nil, errors.New("eon public key differs from the one confirmed by the keyper set")
+	}
+	return result, nil
+}
+
+// Find verifies the DKG results of all eons and returns those that are corrupted.
+func Find(ctx context.Context, db kprdb.DBTX) ([]Corruption, error) {
+	queries := kprdb.New(db)
+	results, err := queries.GetSuccessfulDKGResults(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query DKG results")
+	}
+	corruptions := []Corruption{}
+	for _, row := range results {
+		if _, err := verifyStored(ctx, queries, row.Eon, row.PureResult); err != nil {
+			corruptions = append(corruptions, Corruption{Eon: row.Eon, Reason: err.Error()})
+		}
+	}
+	return corruptions, nil
+}
+
+// Check verifies the DKG results of all eons. If any of them is corrupted, the operator is alerted
+// and an error wrapping ErrCorrupted is returned.
+func Check(ctx context.Context, db kprdb.DBTX, notifier alert.Notifier) error {
+	corruptions, err := Find(ctx, db)
+	if err != nil {
+		return err
+	}
+	if len(corruptions) == 0 {
+		log.Info().Msg("verified integrity of eon key shares")
+		return nil
+	}
+	eons := make([]string, len(corruptions))
+	details := map[string]string{}
+	for i, c := range corruptions {
+		eons[i] = strconv.FormatInt(c.Eon, 10)
+		details["eon-"+eons[i]] = c.Reason
+		log.Error().Int64("eon", c.Eon).Str("reason", c.Reason).Msg("eon key share is corrupted")
+	}
+	if notifier != nil {
+		nerr := notifier.Notify(ctx, alert.Alert{
+			Severity: alert.SeverityCritical,
+			Summary:  fmt.Sprintf("keyper refuses to start, key shares of eons %s are corrupted", strings.Join(eons, ", ")),
+			Details:  details,
+		})
+		if nerr != nil {
+			log.Error().Err(nerr).Msg("failed to notify about corrupted eon key shares")
+		}
+	}
+	log.Error().Msg("restore the corrupted eons from their backups with 'keyper repair-from-backup'")
+	return errors.WithMessagef(ErrCorrupted, "eons %s", strings.Join(eons, ", "))
+}
+
+// Repair replaces the stored DKG result of an eon with one recovered from a backup, e.g. by
+// 'keyper escrow recover'. The recovered result must pass verification and belong to an eon with a
+// successful DKG. Self-audit failures of the eon are deleted, so that the keyper resumes sending
+// decryption key shares for it.
+func Repair(ctx context.Context, dbpool *pgxpool.Pool, pureResult []byte) (uint64, error) {
+	result, err := shdb.DecodePureDKGResult(pureResult)
+	if err != nil {
+		return 0, errors.Wrap(err, "recovered data is not a DKG result")
+	}
+	eon := int64(result.Eon)
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		db := kprdb.New(tx)
+		if _, err := verifyStored(ctx, db, eon, pureResult); err != nil {
+			return errors.Wrap(err, "recovered DKG result is invalid")
+		}
+		n, err := db.ReplaceDKGResult(ctx, kprdb.ReplaceDKGResultParams{Eon: eon, PureResult: pureResult})
+		if err != nil {
+			return errors.Wrap(err, "failed to replace DKG result")
+		}
+		if n == 0 {
+			return errors.Errorf("no successful DKG result stored for eon %d", eon)
+		}
+		_, err = db.DeleteShareSelfAuditFailures(ctx, eon)
+		return errors.Wrap(err, "failed to delete self-audit failures")
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.Eon, nil
+}
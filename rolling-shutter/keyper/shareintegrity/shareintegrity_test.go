@@ -0,0 +1,105 @@
+package shareintegrity
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testkeygen"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+func newResult(t *testing.T, eon uint64, numKeypers, threshold uint64, keyper uint64) *puredkg.Result {
+	t.Helper()
+	keys, err := testkeygen.NewEonKeys(rand.Reader, numKeypers, threshold)
+	assert.NilError(t, err)
+	publicKeyShares := []*shcrypto.EonPublicKeyShare{}
+	for i := uint64(0); i < numKeypers; i++ {
+		publicKeyShares = append(publicKeyShares, keys.KeyperShares(i).EonPublicKeyShare())
+	}
+	return &puredkg.Result{
+		Eon:             eon,
+		NumKeypers:      numKeypers,
+		Threshold:       threshold,
+		Keyper:          puredkg.KeyperIndex(keyper),
+		SecretKeyShare:  keys.KeyperShares(keyper).EonSecretKeyShare(),
+		PublicKey:       keys.PublicKey(),
+		PublicKeyShares: publicKeyShares,
+	}
+}
+
+func TestVerifyResult(t *testing.T) {
+	for _, threshold := range []uint64{1, 2, 3, 4} {
+		assert.NilError(t, VerifyResult(newResult(t, 1, 4, threshold, 1)))
+	}
+
+	other := newResult(t, 1, 4, 3, 1)
+	for name, corrupt := range map[string]func(*puredkg.Result){
+		"secret key share": func(r *puredkg.Result) {
+			r.SecretKeyShare = (*shcrypto.EonSecretKeyShare)(new(big.Int).Add((*big.Int)(r.SecretKeyShare), big.NewInt(1)))
+		},
+		"keyper index": func(r *puredkg.Result) {
+			r.Keyper = 2
+		},
+		"public key share": func(r *puredkg.Result) {
+			r.PublicKeyShares[3] = other.PublicKeyShares[3]
+		},
+		"public key": func(r *puredkg.Result) {
+			r.PublicKey = other.PublicKey
+		},
+		"missing public key share": func(r *puredkg.Result) {
+			r.PublicKeyShares = r.PublicKeyShares[:3]
+		},
+	} {
+		result := newResult(t, 1, 4, 3, 1)
+		corrupt(result)
+		assert.Check(t, VerifyResult(result) != nil, "corrupted %s must not verify", name)
+	}
+}
+
+func TestCheckAndRepairIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	db, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	results := map[int64][]byte{}
+	for eon := int64(1); eon <= 2; eon++ {
+		pureResult, err := shdb.EncodePureDKGResult(newResult(t, uint64(eon), 3, 2, 0))
+		assert.NilError(t, err)
+		results[eon] = pureResult
+		err = db.InsertDKGResult(ctx, kprdb.InsertDKGResultParams{Eon: eon, Success: true, PureResult: pureResult})
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, Check(ctx, dbpool, nil))
+
+	result := newResult(t, 2, 3, 2, 0)
+	result.SecretKeyShare = (*shcrypto.EonSecretKeyShare)(big.NewInt(1))
+	corrupted, err := shdb.EncodePureDKGResult(result)
+	assert.NilError(t, err)
+	_, err = db.ReplaceDKGResult(ctx, kprdb.ReplaceDKGResultParams{Eon: 2, PureResult: corrupted})
+	assert.NilError(t, err)
+
+	corruptions, err := Find(ctx, dbpool)
+	assert.NilError(t, err)
+	assert.Equal(t, len(corruptions), 1)
+	assert.Equal(t, corruptions[0].Eon, int64(2))
+	assert.Check(t, errors.Is(Check(ctx, dbpool, nil), ErrCorrupted))
+
+	_, err = Repair(ctx, dbpool, corrupted)
+	assert.Check(t, err != nil, "corrupted backups must be rejected")
+	eon, err := Repair(ctx, dbpool, results[2])
+	assert.NilError(t, err)
+	assert.Equal(t, eon, uint64(2))
+	assert.NilError(t, Check(ctx, dbpool, nil))
+}
@@ -0,0 +1,24 @@
+package keyper
+
+import (
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/contract/deployment"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/epochkghandler"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/featureflag"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// NewEonPublicKeySigning returns how eon public keys are signed and verified. The signing domain
+// is made up of the chain the contracts are deployed on and the instance ID.
+func NewEonPublicKeySigning(
+	contracts *deployment.Contracts, instanceID uint64, features *featureflag.Set,
+) epochkghandler.Signing {
+	return epochkghandler.Signing{
+		Domain: p2pmsg.SigningDomain{
+			ChainID:    contracts.Deployments.ChainID,
+			InstanceID: instanceID,
+		},
+		InDomain: func() bool {
+			return features.Enabled(FeatureSigningDomain)
+		},
+	}
+}
@@ -0,0 +1,80 @@
+package sla
+
+import (
+	"io"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+type Config struct {
+	Target    *enctime.Duration `comment:"Time after receiving a decryption trigger within which the decryption key must be available, 0 disables SLA tracking. It includes the PublicationDelay"`
+	Window    uint64            `comment:"Number of most recent epochs the rolling compliance is computed over"`
+	Retention *enctime.Duration `comment:"How long the per-epoch records are kept, must cover the months monthly reports are requested for"`
+}
+
+func (c *Config) Init() {
+	c.Target = &enctime.Duration{}
+	c.Retention = &enctime.Duration{}
+}
+
+func (c *Config) Name() string {
+	return "sla"
+}
+
+// Enabled reports whether the SLA is tracked.
+func (c *Config) Enabled() bool {
+	return c.Target.Duration > 0
+}
+
+func (c *Config) Validate() error {
+	if c.Target.Duration < 0 {
+		return errors.New("sla Target must not be negative")
+	}
+	if !c.Enabled() {
+		return nil
+	}
+	if c.Window == 0 || c.Window > math.MaxInt32 {
+		return errors.Errorf("sla Window must be between 1 and %d", math.MaxInt32)
+	}
+	if c.Retention.Duration <= c.Target.Duration {
+		return errors.New("sla Retention must be longer than the Target")
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.Target = &enctime.Duration{}
+	c.Window = 1000
+	c.Retention = &enctime.Duration{
+		Duration: 400 * 24 * time.Hour,
+	}
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	err := c.SetDefaultValues()
+	if err != nil {
+		return err
+	}
+	c.Target = &enctime.Duration{
+		Duration: 5 * time.Second,
+	}
+	return nil
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,41 @@
+package sla
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+)
+
+// Router serves the rolling compliance at / and the signed monthly reports at /reports/{month}.
+func (t *Tracker) Router() http.Handler {
+	router := chi.NewRouter()
+	router.Get("/", t.handleStatus)
+	router.Get("/reports/{month}", t.handleReport)
+	return router
+}
+
+func (t *Tracker) handleStatus(w http.ResponseWriter, r *http.Request) {
+	summary, err := t.Status(r.Context())
+	if err != nil {
+		errcode.SendError(w, err)
+		return
+	}
+	errcode.WriteJSON(w, http.StatusOK, summary)
+}
+
+func (t *Tracker) handleReport(w http.ResponseWriter, r *http.Request) {
+	month, err := ParseMonth(chi.URLParam(r, "month"), time.Now().UTC())
+	if err != nil {
+		errcode.SendError(w, err)
+		return
+	}
+	report, err := t.Report(r.Context(), month)
+	if err != nil {
+		errcode.SendError(w, err)
+		return
+	}
+	errcode.WriteJSON(w, http.StatusOK, report)
+}
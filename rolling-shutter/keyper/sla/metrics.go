@@ -0,0 +1,45 @@
+package sla
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsCompliance = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "sla",
+		Name:      "compliance_ratio",
+		Help:      "Fraction of the recent epochs whose decryption key became available within the SLA target",
+	},
+)
+
+var metricsEpochs = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "sla",
+		Name:      "epochs",
+		Help:      "Number of recent epochs the SLA compliance is computed over",
+	},
+)
+
+var metricsMisses = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "sla",
+		Name:      "misses",
+		Help:      "Number of the recent epochs that missed the SLA target, by reason",
+	},
+	[]string{"reason"},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsCompliance)
+	prometheus.MustRegister(metricsEpochs)
+	prometheus.MustRegister(metricsMisses)
+}
+
+func updateMetrics(summary *Summary) {
+	metricsCompliance.Set(summary.Compliance)
+	metricsEpochs.Set(float64(summary.Epochs))
+	for _, reason := range Reasons {
+		metricsMisses.WithLabelValues(string(reason)).Set(float64(summary.Misses[reason]))
+	}
+}
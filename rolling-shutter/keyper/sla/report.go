@@ -0,0 +1,94 @@
+package sla
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+)
+
+// MonthFormat is the format of the months reports are requested for, e.g. 2024-05.
+const MonthFormat = "2006-01"
+
+var hashPrefix = []byte("\x19Shutter SLA report:\n")
+
+// Report is the compliance of a keyper with the SLA in a calendar month (UTC), for deployment
+// governance. Only the epochs whose records haven't been pruned yet are included.
+type Report struct {
+	InstanceID uint64         `json:"instanceID"`
+	Keyper     common.Address `json:"keyper"`
+	Month      string         `json:"month"`
+	Summary    Summary        `json:"summary"`
+	// CreatedAt is when the report was created, as unix timestamp.
+	CreatedAt int64 `json:"createdAt"`
+}
+
+// SignedReport is a report signed by the keyper it is about.
+type SignedReport struct {
+	Report    Report        `json:"report"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// Hash returns the hash the keyper signs. It covers the JSON encoding of the report.
+func (r *Report) Hash() []byte {
+	payload, err := json.Marshal(r)
+	if err != nil {
+		panic(err) // a Report can always be encoded
+	}
+	return ethcrypto.Keccak256(hashPrefix, payload)
+}
+
+// Verify checks that the report is signed by the keyper it is about.
+func Verify(report *SignedReport) error {
+	pubkey, err := ethcrypto.SigToPub(report.Report.Hash(), report.Signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+	if signer := ethcrypto.PubkeyToAddress(*pubkey); signer != report.Report.Keyper {
+		return errors.Errorf("report signed by %s instead of %s", signer.Hex(), report.Report.Keyper.Hex())
+	}
+	return nil
+}
+
+// ParseMonth parses a month in MonthFormat and returns its start. Only months that have ended can
+// be reported on.
+func ParseMonth(s string, now time.Time) (time.Time, error) {
+	month, err := time.Parse(MonthFormat, s)
+	if err != nil {
+		return time.Time{}, errcode.ErrInvalidRequest.Wrapf(err, "invalid month %q", s)
+	}
+	if month.AddDate(0, 1, 0).After(now) {
+		return time.Time{}, errcode.ErrInvalidRequest.Wrap(errors.Errorf("month %s has not ended yet", s))
+	}
+	return month, nil
+}
+
+// Report creates the signed report of the month starting at the given time.
+func (t *Tracker) Report(ctx context.Context, month time.Time) (*SignedReport, error) {
+	epochs, err := kprdb.New(t.dbpool).GetEpochSLAsInRange(ctx, kprdb.GetEpochSLAsInRangeParams{
+		FromTime: month,
+		ToTime:   month.AddDate(0, 1, 0),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get SLA records from db")
+	}
+	report := Report{
+		InstanceID: t.instanceID,
+		Keyper:     ethcrypto.PubkeyToAddress(t.privKey.PublicKey),
+		Month:      month.Format(MonthFormat),
+		Summary:    *Summarize(epochs, t.config.Target.Duration),
+		CreatedAt:  time.Now().Unix(),
+	}
+	signature, err := ethcrypto.Sign(report.Hash(), t.privKey)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedReport{Report: report, Signature: signature}, nil
+}
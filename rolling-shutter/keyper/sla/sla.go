@@ -0,0 +1,201 @@
+// Package sla tracks whether the decryption keys of the epochs become available within a target
+// time after the decryption trigger was received, the service level agreed on for a deployment.
+// Every epoch we receive a trigger for is recorded together with the time we had enough
+// decryption key shares to aggregate its key and the time the key became available. Epochs
+// missing the target are classified by the most likely reason, so that operators can tell
+// whether keypers sent their shares late, the key was delayed on its way through the network or
+// this node was too slow to process it.
+package sla
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/broker"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+// updateInterval is how often the rolling compliance is recomputed and old records are pruned.
+const updateInterval = time.Minute
+
+// Reason is why an epoch missed the SLA target.
+type Reason string
+
+const (
+	// ReasonNoKey means that the decryption key isn't available (yet).
+	ReasonNoKey Reason = "no-key"
+	// ReasonSharesLate means that the share completing the threshold arrived after the deadline.
+	ReasonSharesLate Reason = "shares-late"
+	// ReasonGossipDelay means that we received the key from another keyper after the deadline,
+	// without having had enough shares to aggregate it ourselves.
+	ReasonGossipDelay Reason = "gossip-delay"
+	// ReasonDBSlow means that we had enough shares in time, but aggregating and storing the key
+	// took too long, e.g. because the database was slow.
+	ReasonDBSlow Reason = "db-slow"
+)
+
+// Reasons lists all reasons for misses.
+var Reasons = []Reason{ReasonNoKey, ReasonSharesLate, ReasonGossipDelay, ReasonDBSlow}
+
+// Classify returns whether the key of the epoch became available within target after the trigger
+// and, if it didn't, the reason for the miss.
+func Classify(epoch kprdb.EpochSla, target time.Duration) (bool, Reason) {
+	deadline := epoch.TriggeredAt.Add(target)
+	switch {
+	case !epoch.KeyAvailableAt.Valid:
+		return false, ReasonNoKey
+	case !epoch.KeyAvailableAt.Time.After(deadline):
+		return true, ""
+	case !epoch.SharesCompleteAt.Valid:
+		return false, ReasonGossipDelay
+	case epoch.SharesCompleteAt.Time.After(deadline):
+		return false, ReasonSharesLate
+	default:
+		return false, ReasonDBSlow
+	}
+}
+
+// Summary is the compliance with the SLA over a number of epochs.
+type Summary struct {
+	TargetSeconds float64 `json:"targetSeconds"`
+	Epochs        int     `json:"epochs"`
+	Met           int     `json:"met"`
+	// Compliance is the fraction of the epochs that met the target, 1 if there are no epochs.
+	Compliance float64        `json:"compliance"`
+	Misses     map[Reason]int `json:"misses"`
+}
+
+// Summarize computes the compliance of the given epochs with the target.
+func Summarize(epochs []kprdb.EpochSla, target time.Duration) *Summary {
+	summary := &Summary{
+		TargetSeconds: target.Seconds(),
+		Epochs:        len(epochs),
+		Compliance:    1,
+		Misses:        map[Reason]int{},
+	}
+	for _, epoch := range epochs {
+		met, reason := Classify(epoch, target)
+		if met {
+			summary.Met++
+		} else {
+			summary.Misses[reason]++
+		}
+	}
+	if len(epochs) > 0 {
+		summary.Compliance = float64(summary.Met) / float64(len(epochs))
+	}
+	return summary
+}
+
+// Tracker records the progress of the epochs and keeps the SLA metrics up to date. All its
+// methods can be called on a nil Tracker, which doesn't track anything.
+type Tracker struct {
+	config     *Config
+	dbpool     *pgxpool.Pool
+	instanceID uint64
+	privKey    *ecdsa.PrivateKey
+	keys       chan broker.DecryptionKeyAvailable
+}
+
+// NewTracker creates a tracker and subscribes it to the decryption keys published to the bus. It
+// must be created before keys are ingested and run for the bus not to block. It returns nil if
+// SLA tracking is disabled. Reports are signed with privKey.
+func NewTracker(
+	config *Config, dbpool *pgxpool.Pool, bus *broker.Bus, instanceID uint64, privKey *ecdsa.PrivateKey,
+) *Tracker {
+	if !config.Enabled() {
+		return nil
+	}
+	return &Tracker{
+		config:     config,
+		dbpool:     dbpool,
+		instanceID: instanceID,
+		privKey:    privKey,
+		keys:       broker.Subscribe(bus, broker.DecryptionKeyAvailableTopic, 32),
+	}
+}
+
+// Triggered records that we received the decryption trigger of the epoch. Only the first trigger
+// of an epoch counts.
+func (t *Tracker) Triggered(ctx context.Context, epochID epochid.EpochID) {
+	if t == nil {
+		return
+	}
+	err := kprdb.New(t.dbpool).InsertEpochSLATrigger(ctx, kprdb.InsertEpochSLATriggerParams{
+		EpochID:     epochID.Bytes(),
+		TriggeredAt: time.Now(),
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("epoch-id", epochID.Hex()).Msg("failed to record trigger for SLA")
+	}
+}
+
+// SharesComplete records that at the given time we received the share completing the threshold
+// of the epoch.
+func (t *Tracker) SharesComplete(ctx context.Context, epochID epochid.EpochID, at time.Time) {
+	if t == nil {
+		return
+	}
+	err := kprdb.New(t.dbpool).SetEpochSLASharesComplete(ctx, kprdb.SetEpochSLASharesCompleteParams{
+		EpochID:          epochID.Bytes(),
+		SharesCompleteAt: sql.NullTime{Time: at, Valid: true},
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("epoch-id", epochID.Hex()).Msg("failed to record shares for SLA")
+	}
+}
+
+func (t *Tracker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(updateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case key := <-t.keys:
+			err := kprdb.New(t.dbpool).SetEpochSLAKeyAvailable(ctx, kprdb.SetEpochSLAKeyAvailableParams{
+				EpochID:        key.EpochID.Bytes(),
+				KeyAvailableAt: sql.NullTime{Time: time.Now(), Valid: true},
+				KeySource:      key.Source,
+			})
+			if err != nil {
+				log.Warn().Err(err).Str("epoch-id", key.EpochID.Hex()).
+					Msg("failed to record decryption key for SLA")
+			}
+		case <-ticker.C:
+			if err := t.update(ctx); err != nil {
+				log.Warn().Err(err).Msg("failed to update SLA compliance")
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (t *Tracker) update(ctx context.Context) error {
+	summary, err := t.Status(ctx)
+	if err != nil {
+		return err
+	}
+	updateMetrics(summary)
+	_, err = kprdb.New(t.dbpool).DeleteEpochSLAsBefore(ctx, time.Now().Add(-t.config.Retention.Duration))
+	return errors.Wrap(err, "failed to prune SLA records")
+}
+
+// Status computes the rolling compliance over the most recent epochs whose deadline has passed.
+func (t *Tracker) Status(ctx context.Context) (*Summary, error) {
+	target := t.config.Target.Duration
+	epochs, err := kprdb.New(t.dbpool).GetRecentEpochSLAs(ctx, kprdb.GetRecentEpochSLAsParams{
+		TriggeredAt: time.Now().Add(-target),
+		Limit:       int32(t.config.Window),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get SLA records from db")
+	}
+	return Summarize(epochs, target), nil
+}
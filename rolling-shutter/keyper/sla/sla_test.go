@@ -0,0 +1,84 @@
+package sla
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+)
+
+func epoch(triggeredAt time.Time, sharesComplete, keyAvailable time.Duration) kprdb.EpochSla {
+	e := kprdb.EpochSla{TriggeredAt: triggeredAt}
+	if sharesComplete >= 0 {
+		e.SharesCompleteAt = sql.NullTime{Time: triggeredAt.Add(sharesComplete), Valid: true}
+	}
+	if keyAvailable >= 0 {
+		e.KeyAvailableAt = sql.NullTime{Time: triggeredAt.Add(keyAvailable), Valid: true}
+	}
+	return e
+}
+
+func TestSummarize(t *testing.T) {
+	target := 5 * time.Second
+	now := time.Unix(1700000000, 0)
+	epochs := []kprdb.EpochSla{
+		epoch(now, time.Second, 2*time.Second),
+		epoch(now, -1, target),
+		epoch(now, -1, -1),
+		epoch(now, 6*time.Second, 7*time.Second),
+		epoch(now, -1, 8*time.Second),
+		epoch(now, 4*time.Second, 9*time.Second),
+		epoch(now, time.Second, 3*time.Second),
+		epoch(now, -1, 10*time.Second),
+	}
+	summary := Summarize(epochs, target)
+	assert.DeepEqual(t, summary, &Summary{
+		TargetSeconds: 5,
+		Epochs:        8,
+		Met:           3,
+		Compliance:    3.0 / 8,
+		Misses: map[Reason]int{
+			ReasonNoKey:       1,
+			ReasonSharesLate:  1,
+			ReasonGossipDelay: 2,
+			ReasonDBSlow:      1,
+		},
+	})
+
+	assert.Equal(t, Summarize(nil, target).Compliance, 1.0)
+}
+
+func TestParseMonth(t *testing.T) {
+	now := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	month, err := ParseMonth("2024-05", now)
+	assert.NilError(t, err)
+	assert.Equal(t, month, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+
+	_, err = ParseMonth("2024-06", now)
+	assert.ErrorContains(t, err, "has not ended yet")
+	_, err = ParseMonth("May 2024", now)
+	assert.ErrorContains(t, err, "invalid month")
+}
+
+func TestVerify(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	report := Report{
+		InstanceID: 42,
+		Keyper:     ethcrypto.PubkeyToAddress(key.PublicKey),
+		Month:      "2024-05",
+		Summary:    *Summarize(nil, time.Second),
+		CreatedAt:  1700000000,
+	}
+	signature, err := ethcrypto.Sign(report.Hash(), key)
+	assert.NilError(t, err)
+	signed := &SignedReport{Report: report, Signature: signature}
+	assert.NilError(t, Verify(signed))
+
+	signed.Report.Summary.Met++
+	assert.ErrorContains(t, Verify(signed), "report signed by")
+}
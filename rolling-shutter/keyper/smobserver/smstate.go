@@ -5,6 +5,7 @@ import (
 	"crypto/ed25519"
 	"crypto/rand"
 	"database/sql"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"math"
@@ -12,15 +13,19 @@ import (
 	"reflect"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
 	"github.com/shutter-network/shutter/shlib/puredkg"
 
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/auditdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/dkgparams"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/dkgphase"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/shutterevents"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/upgrade"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
@@ -31,6 +36,7 @@ type Config interface {
 	GetDKGPhaseLength() *dkgphase.PhaseLength
 	GetValidatorPublicKey() ed25519.PublicKey
 	GetEncryptionKey() *ecies.PrivateKey
+	GetRefuseOutdatedEons() bool
 }
 
 type ActiveDKG struct {
@@ -38,6 +44,8 @@ type ActiveDKG struct {
 	startHeight int64
 	dirty       bool
 	keypers     []common.Address
+	params      dkgparams.Parameters
+	phaseLength PhaseLength
 }
 
 func (dkg *ActiveDKG) markDirty() {
@@ -56,7 +64,9 @@ type ShuttermintState struct {
 	isKeyper       bool
 	encryptionKeys map[common.Address]*ecies.PublicKey
 	dkg            map[uint64]*ActiveDKG
-	phaseLength    PhaseLength
+	// defaultDKGParams are used for the DKGs of eons for which the DKGParameters contract
+	// didn't announce any.
+	defaultDKGParams dkgparams.Parameters
 }
 
 func NewShuttermintState(config Config) *ShuttermintState {
@@ -64,7 +74,8 @@ func NewShuttermintState(config Config) *ShuttermintState {
 		config:         config,
 		encryptionKeys: make(map[common.Address]*ecies.PublicKey),
 		dkg:            make(map[uint64]*ActiveDKG),
-		phaseLength:    config.GetDKGPhaseLength(),
+
+		defaultDKGParams: dkgparams.Defaults(config.GetDKGPhaseLength()),
 	}
 }
 
@@ -125,11 +136,18 @@ func (st *ShuttermintState) loadDKG(ctx context.Context, queries *kprdb.Queries)
 			keypers = append(keypers, a)
 		}
 
+		params, err := dkgparams.GetForEon(ctx, queries, dkg.Eon, st.defaultDKGParams)
+		if err != nil {
+			return err
+		}
+
 		st.dkg[uint64(dkg.Eon)] = &ActiveDKG{
 			pure:        pure,
 			startHeight: keyperEon.Height,
 			dirty:       false,
 			keypers:     keypers,
+			params:      params,
+			phaseLength: params.GetPhaseLength(),
 		}
 	}
 	return nil
@@ -305,6 +323,11 @@ func (st *ShuttermintState) handleEonStarted(
 	if err != nil {
 		return err
 	}
+	params, err := dkgparams.FixForEon(ctx, queries, int64(e.Eon), int64(e.KeyperConfigIndex), st.defaultDKGParams)
+	if err != nil {
+		return err
+	}
+	phaseLength := params.GetPhaseLength()
 	batchConfig, err := queries.GetBatchConfig(ctx, int32(e.KeyperConfigIndex))
 	if err != nil {
 		return err
@@ -324,12 +347,24 @@ func (st *ShuttermintState) handleEonStarted(
 		return nil
 	}
 
+	if st.config.GetRefuseOutdatedEons() {
+		outdated, err := upgrade.Outdated(ctx, queries)
+		if err != nil {
+			return err
+		}
+		if outdated {
+			log.Error().Uint64("eon", e.Eon).
+				Msg("not taking part in the DKG of the eon, this node is below the minimum version")
+			return nil
+		}
+	}
+
 	lastCommittedHeight, err := queries.GetLastCommittedHeight(ctx)
 	if err != nil {
 		return err
 	}
 
-	phase := st.phaseLength.GetPhaseAtHeight(lastCommittedHeight+1, e.Height)
+	phase := phaseLength.GetPhaseAtHeight(lastCommittedHeight+1, e.Height)
 	if phase > puredkg.Dealing {
 		log.Info().Uint64("eon", e.Eon).Msg("missed the dealing phase")
 	}
@@ -348,6 +383,8 @@ func (st *ShuttermintState) handleEonStarted(
 		dirty:       true,
 		startHeight: e.Height,
 		keypers:     keypers,
+		params:      params,
+		phaseLength: phaseLength,
 	}
 	st.dkg[e.Eon] = dkg
 	return st.shiftPhase(ctx, queries, e.Height, e.Eon, dkg)
@@ -461,6 +498,9 @@ func (st *ShuttermintState) finalizeDKG(
 	var pureResult []byte
 
 	dkgresult, err := dkg.pure.ComputeResult()
+	if err == nil {
+		err = dkg.params.CheckMissing(numMissingDealers(dkg.pure))
+	}
 
 	dkgresultmsg := shmsg.NewDKGResult(eon, err == nil)
 
@@ -502,10 +542,21 @@ func (st *ShuttermintState) finalizeDKG(
 	})
 }
 
+// numMissingDealers returns the number of keypers from which no poly commitment was received.
+func numMissingDealers(pure *puredkg.PureDKG) int {
+	n := 0
+	for _, c := range pure.Commitments {
+		if c == nil {
+			n++
+		}
+	}
+	return n
+}
+
 func (st *ShuttermintState) shiftPhase(
 	ctx context.Context, queries *kprdb.Queries, height int64, eon uint64, dkg *ActiveDKG,
 ) error {
-	phase := st.phaseLength.GetPhaseAtHeight(height, dkg.startHeight)
+	phase := dkg.phaseLength.GetPhaseAtHeight(height, dkg.startHeight)
 	for currentPhase := dkg.pure.Phase; currentPhase < phase; currentPhase = dkg.pure.Phase {
 		log.Info().
 			Uint64("eon", eon).
@@ -760,7 +811,92 @@ func (st *ShuttermintState) HandleEvent(
 	default:
 		log.Warn().Str("type", reflect.TypeOf(event).String()).Interface("event", event).
 			Msg("HandleEvent not yet implemented for event type")
+		return nil
 	}
+	if err != nil {
+		return err
+	}
+	if err := st.recordDKGMessage(ctx, queries, event); err != nil {
+		return err
+	}
+	return auditEvent(ctx, queries.AuditLog(), event)
+}
 
-	return err
+// recordDKGMessage stores the DKG messages of the eons we take part in in the DKG transcript.
+func (st *ShuttermintState) recordDKGMessage(
+	ctx context.Context, queries *kprdb.Queries, event shutterevents.IEvent,
+) error {
+	var eon uint64
+	var height int64
+	var sender common.Address
+	switch e := event.(type) {
+	case *shutterevents.PolyCommitment:
+		eon, height, sender = e.Eon, e.Height, e.Sender
+	case *shutterevents.PolyEval:
+		eon, height, sender = e.Eon, e.Height, e.Sender
+	case *shutterevents.Accusation:
+		eon, height, sender = e.Eon, e.Height, e.Sender
+	case *shutterevents.Apology:
+		eon, height, sender = e.Eon, e.Height, e.Sender
+	default:
+		return nil
+	}
+	dkg, ok := st.dkg[eon]
+	if !ok {
+		return nil
+	}
+	abciEvent := event.MakeABCIEvent()
+	eventBytes, err := abciEvent.Marshal()
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal shuttermint event")
+	}
+	err = queries.InsertDKGTranscriptEntry(ctx, kprdb.InsertDKGTranscriptEntryParams{
+		Eon:    int64(eon),
+		Height: height,
+		Phase:  int32(dkg.pure.Phase),
+		Sender: shdb.EncodeAddress(sender),
+		Event:  eventBytes,
+	})
+	return errors.Wrap(err, "failed to insert DKG transcript entry")
+}
+
+// auditEvent records a handled shuttermint event in the audit log. The trigger hash is the hash of
+// the event and the height of the block it was emitted in.
+func auditEvent(ctx context.Context, db *auditdb.Queries, event shutterevents.IEvent) error {
+	abciEvent := event.MakeABCIEvent()
+	eventBytes, err := abciEvent.Marshal()
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal shuttermint event")
+	}
+	var height int64
+	actor := "shuttermint"
+	switch e := event.(type) {
+	case *shutterevents.CheckIn:
+		height, actor = e.Height, e.Sender.Hex()
+	case *shutterevents.BatchConfig:
+		height = e.Height
+	case *shutterevents.BatchConfigStarted:
+		height = e.Height
+	case *shutterevents.EonStarted:
+		height = e.Height
+	case *shutterevents.PolyCommitment:
+		height, actor = e.Height, e.Sender.Hex()
+	case *shutterevents.PolyEval:
+		height, actor = e.Height, e.Sender.Hex()
+	case *shutterevents.Accusation:
+		height, actor = e.Height, e.Sender.Hex()
+	case *shutterevents.Apology:
+		height, actor = e.Height, e.Sender.Hex()
+	}
+	heightBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(heightBytes, uint64(height))
+
+	err = db.InsertAuditLogEntry(ctx, auditdb.InsertAuditLogEntryParams{
+		Source:      auditdb.SourceShuttermint,
+		Actor:       actor,
+		Action:      abciEvent.Type,
+		Details:     event.String(),
+		TriggerHash: crypto.Keccak256(heightBytes, eventBytes),
+	})
+	return errors.Wrap(err, "failed to insert audit log entry")
 }
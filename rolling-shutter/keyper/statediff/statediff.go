@@ -0,0 +1,297 @@
+// Package statediff compares the databases of two keypers of the same instance table by table. It
+// only compares state that all keypers are supposed to agree on: the keyper sets, the eons, the
+// public outcome of the DKGs, the eon public keys, the decryption keys and the decryption key
+// shares. Secrets like the eon key shares of the keypers are never compared.
+package statediff
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// The tables that are compared, in the order they are reported.
+const (
+	TableKeyperSets          = "keyper_set"
+	TableEons                = "eons"
+	TableDKGResults          = "dkg_result"
+	TableEonPublicKeys       = "eon_public_key"
+	TableDecryptionKeys      = "decryption_key"
+	TableDecryptionKeyShares = "decryption_key_share"
+)
+
+var tables = []string{
+	TableKeyperSets,
+	TableEons,
+	TableDKGResults,
+	TableEonPublicKeys,
+	TableDecryptionKeys,
+	TableDecryptionKeyShares,
+}
+
+// partialTables are the tables which a keyper may legitimately store only partially, e.g. because
+// it garbage collects them or didn't receive all messages. Rows missing from one database are only
+// counted for them, but not reported as divergences.
+var partialTables = map[string]bool{
+	TableDecryptionKeyShares: true,
+}
+
+// Position locates a row in time: the activation block of the keyper set or eon the row belongs
+// to and, for rows concerning a single epoch, the eon and epoch id.
+type Position struct {
+	Block   int64         `json:"block"`
+	Eon     *int64        `json:"eon,omitempty"`
+	EpochID hexutil.Bytes `json:"epochID,omitempty"`
+}
+
+func (p Position) before(q Position) bool {
+	if p.Block != q.Block {
+		return p.Block < q.Block
+	}
+	if (p.Eon == nil) != (q.Eon == nil) {
+		return p.Eon == nil
+	}
+	if p.Eon != nil && *p.Eon != *q.Eon {
+		return *p.Eon < *q.Eon
+	}
+	return bytes.Compare(p.EpochID, q.EpochID) < 0
+}
+
+// Divergence is a row whose value differs between the two databases. A or B is empty if the row is
+// missing from the respective database.
+type Divergence struct {
+	Table string `json:"table"`
+	Key   string `json:"key"`
+	Position
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+func (d *Divergence) before(e *Divergence) bool {
+	if d.Position.before(e.Position) {
+		return true
+	}
+	if e.Position.before(d.Position) {
+		return false
+	}
+	return d.Key < e.Key
+}
+
+// TableReport is the result of comparing a single table. Compared is the number of rows present in
+// both databases.
+type TableReport struct {
+	Table       string       `json:"table"`
+	Compared    int          `json:"compared"`
+	OnlyInA     int          `json:"onlyInA"`
+	OnlyInB     int          `json:"onlyInB"`
+	Divergences []Divergence `json:"divergences"`
+}
+
+// Report is the result of comparing two databases. Earliest is the earliest divergence of all
+// tables, or nil if the databases agree.
+type Report struct {
+	Tables   []*TableReport `json:"tables"`
+	Earliest *Divergence    `json:"earliest"`
+}
+
+// Diverged reports whether the databases disagree.
+func (r *Report) Diverged() bool {
+	return r.Earliest != nil
+}
+
+type row struct {
+	Position
+	value string
+}
+
+// snapshot maps each table to its rows by key.
+type snapshot map[string]map[string]row
+
+func (s snapshot) add(table string, key string, r row) {
+	s[table][key] = r
+}
+
+// Compare compares the databases a and b. At most maxDivergences divergences are listed per table,
+// the earliest ones first.
+func Compare(ctx context.Context, a, b kprdb.DBTX, maxDivergences int) (*Report, error) {
+	snapshotA, err := load(ctx, a)
+	if err != nil {
+		return nil, errors.Wrap(err, "database a")
+	}
+	snapshotB, err := load(ctx, b)
+	if err != nil {
+		return nil, errors.Wrap(err, "database b")
+	}
+	return compare(snapshotA, snapshotB, maxDivergences), nil
+}
+
+func compare(a, b snapshot, maxDivergences int) *Report {
+	report := &Report{}
+	for _, table := range tables {
+		tableReport := &TableReport{Table: table, Divergences: []Divergence{}}
+		for key, rowA := range a[table] {
+			rowB, ok := b[table][key]
+			switch {
+			case !ok:
+				tableReport.OnlyInA++
+				if !partialTables[table] {
+					tableReport.Divergences = append(tableReport.Divergences, Divergence{
+						Table: table, Key: key, Position: rowA.Position, A: rowA.value,
+					})
+				}
+			case rowA.value != rowB.value:
+				tableReport.Compared++
+				tableReport.Divergences = append(tableReport.Divergences, Divergence{
+					Table: table, Key: key, Position: rowA.Position, A: rowA.value, B: rowB.value,
+				})
+			default:
+				tableReport.Compared++
+			}
+		}
+		for key, rowB := range b[table] {
+			if _, ok := a[table][key]; ok {
+				continue
+			}
+			tableReport.OnlyInB++
+			if !partialTables[table] {
+				tableReport.Divergences = append(tableReport.Divergences, Divergence{
+					Table: table, Key: key, Position: rowB.Position, B: rowB.value,
+				})
+			}
+		}
+
+		sort.Slice(tableReport.Divergences, func(i, j int) bool {
+			return tableReport.Divergences[i].before(&tableReport.Divergences[j])
+		})
+		if len(tableReport.Divergences) > 0 {
+			earliest := tableReport.Divergences[0]
+			if report.Earliest == nil || earliest.before(report.Earliest) {
+				report.Earliest = &earliest
+			}
+		}
+		if len(tableReport.Divergences) > maxDivergences {
+			tableReport.Divergences = tableReport.Divergences[:maxDivergences]
+		}
+		report.Tables = append(report.Tables, tableReport)
+	}
+	return report
+}
+
+func load(ctx context.Context, db kprdb.DBTX) (snapshot, error) {
+	s := snapshot{}
+	for _, table := range tables {
+		s[table] = make(map[string]row)
+	}
+
+	keyperSets, err := chainobsdb.New(db).GetKeyperSets(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get keyper sets from db")
+	}
+	for _, keyperSet := range keyperSets {
+		s.add(TableKeyperSets, fmt.Sprint(keyperSet.KeyperConfigIndex), row{
+			Position: Position{Block: keyperSet.ActivationBlockNumber},
+			value: fmt.Sprintf("activation block %d, threshold %d, keypers %s",
+				keyperSet.ActivationBlockNumber, keyperSet.Threshold, strings.Join(keyperSet.Keypers, ",")),
+		})
+	}
+
+	queries := kprdb.New(db)
+	eons, err := queries.GetAllEons(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get eons from db")
+	}
+	for _, eon := range eons {
+		eon := eon
+		position := Position{Block: eon.ActivationBlockNumber, Eon: &eon.Eon}
+		if err := loadEon(ctx, queries, s, eon, position); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func loadEon(ctx context.Context, queries *kprdb.Queries, s snapshot, eon kprdb.Eon, position Position) error {
+	eonKey := fmt.Sprint(eon.Eon)
+	s.add(TableEons, eonKey, row{
+		Position: position,
+		value: fmt.Sprintf("height %d, activation block %d, keyper config index %d",
+			eon.Height, eon.ActivationBlockNumber, eon.KeyperConfigIndex),
+	})
+
+	dkgResult, err := queries.GetDKGResult(ctx, eon.Eon)
+	switch {
+	case err == pgx.ErrNoRows:
+	case err != nil:
+		return errors.Wrapf(err, "failed to get dkg result of eon %d from db", eon.Eon)
+	default:
+		value, err := describeDKGResult(dkgResult)
+		if err != nil {
+			return err
+		}
+		s.add(TableDKGResults, eonKey, row{Position: position, value: value})
+	}
+
+	eonPublicKey, err := queries.GetConfirmedEonPublicKey(ctx, eon.Eon)
+	switch {
+	case err == pgx.ErrNoRows:
+	case err != nil:
+		return errors.Wrapf(err, "failed to get eon public key of eon %d from db", eon.Eon)
+	default:
+		s.add(TableEonPublicKeys, eonKey, row{Position: position, value: hexutil.Encode(eonPublicKey.EonPublicKey)})
+	}
+
+	keys, err := queries.GetDecryptionKeysOfEon(ctx, eon.Eon)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get decryption keys of eon %d from db", eon.Eon)
+	}
+	for _, key := range keys {
+		s.add(TableDecryptionKeys, fmt.Sprintf("%d/%s", eon.Eon, hexutil.Encode(key.EpochID)), row{
+			Position: Position{Block: position.Block, Eon: position.Eon, EpochID: key.EpochID},
+			value:    hexutil.Encode(key.DecryptionKey),
+		})
+	}
+
+	shares, err := queries.GetDecryptionKeySharesOfEon(ctx, eon.Eon)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get decryption key shares of eon %d from db", eon.Eon)
+	}
+	for _, share := range shares {
+		s.add(TableDecryptionKeyShares, fmt.Sprintf("%d/%s/%d", eon.Eon, hexutil.Encode(share.EpochID), share.KeyperIndex), row{
+			Position: Position{Block: position.Block, Eon: position.Eon, EpochID: share.EpochID},
+			value:    hexutil.Encode(share.DecryptionKeyShare),
+		})
+	}
+	return nil
+}
+
+// describeDKGResult describes the public part of a DKG result, which is the same for all keypers.
+func describeDKGResult(dkgResult kprdb.DkgResult) (string, error) {
+	if !dkgResult.Success {
+		return fmt.Sprintf("failed: %s", dkgResult.Error.String), nil
+	}
+	pureResult, err := shdb.DecodePureDKGResult(dkgResult.PureResult)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to decode dkg result of eon %d", dkgResult.Eon)
+	}
+	publicKeyShares := [][]byte{}
+	for _, share := range pureResult.PublicKeyShares {
+		publicKeyShares = append(publicKeyShares, share.Marshal())
+	}
+	return fmt.Sprintf("eon public key %s, threshold %d of %d, public key shares hash %s",
+		hexutil.Encode(pureResult.PublicKey.Marshal()),
+		pureResult.Threshold,
+		pureResult.NumKeypers,
+		hexutil.Encode(ethcrypto.Keccak256(publicKeyShares...)),
+	), nil
+}
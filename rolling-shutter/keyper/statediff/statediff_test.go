@@ -0,0 +1,105 @@
+package statediff
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+)
+
+func newSnapshot() snapshot {
+	s := snapshot{}
+	for _, table := range tables {
+		s[table] = make(map[string]row)
+	}
+	return s
+}
+
+func eonPosition(block int64, eon int64, epochID []byte) Position {
+	return Position{Block: block, Eon: &eon, EpochID: epochID}
+}
+
+func TestCompare(t *testing.T) {
+	a, b := newSnapshot(), newSnapshot()
+	for _, s := range []snapshot{a, b} {
+		s.add(TableKeyperSets, "1", row{Position: Position{Block: 10}, value: "set 1"})
+		s.add(TableEons, "1", row{Position: eonPosition(10, 1, nil), value: "eon 1"})
+		s.add(TableDecryptionKeys, "1/0x01", row{Position: eonPosition(10, 1, []byte{1}), value: "0x01"})
+	}
+	// missing shares are expected, e.g. because of garbage collection
+	a.add(TableDecryptionKeyShares, "1/0x01/0", row{Position: eonPosition(10, 1, []byte{1}), value: "0x01"})
+
+	report := compare(a, b, 10)
+	assert.Check(t, !report.Diverged())
+	assert.Equal(t, len(report.Tables), len(tables))
+	assert.Equal(t, report.Tables[len(tables)-1].OnlyInA, 1)
+
+	a.add(TableDecryptionKeys, "2/0x03", row{Position: eonPosition(20, 2, []byte{3}), value: "0x03"})
+	b.add(TableDecryptionKeys, "2/0x03", row{Position: eonPosition(20, 2, []byte{3}), value: "0x04"})
+	a.add(TableDecryptionKeys, "2/0x02", row{Position: eonPosition(20, 2, []byte{2}), value: "0x02"})
+	b.add(TableDecryptionKeys, "2/0x02", row{Position: eonPosition(20, 2, []byte{2}), value: "0x05"})
+	b.add(TableKeyperSets, "2", row{Position: Position{Block: 30}, value: "set 2"})
+
+	report = compare(a, b, 1)
+	assert.Check(t, report.Diverged())
+	assert.Equal(t, report.Earliest.Table, TableDecryptionKeys)
+	assert.Equal(t, report.Earliest.Key, "2/0x02")
+	assert.Equal(t, report.Earliest.A, "0x02")
+	assert.Equal(t, report.Earliest.B, "0x05")
+
+	keyperSets := report.Tables[0]
+	assert.Equal(t, keyperSets.OnlyInB, 1)
+	assert.Equal(t, keyperSets.Divergences[0].A, "")
+	keys := report.Tables[4]
+	assert.Equal(t, keys.Compared, 3)
+	assert.Equal(t, len(keys.Divergences), 1)
+}
+
+func TestCompareIntegration(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	ctx := context.Background()
+	db, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	err := chainobsdb.New(dbpool).InsertKeyperSet(ctx, chainobsdb.InsertKeyperSetParams{
+		KeyperConfigIndex:     1,
+		ActivationBlockNumber: 10,
+		Keypers:               []string{"0x0000000000000000000000000000000000000001"},
+		Threshold:             1,
+	})
+	assert.NilError(t, err)
+	err = db.InsertEon(ctx, kprdb.InsertEonParams{Eon: 1, Height: 5, ActivationBlockNumber: 10, KeyperConfigIndex: 1})
+	assert.NilError(t, err)
+	err = db.InsertDKGResult(ctx, kprdb.InsertDKGResultParams{
+		Eon:   1,
+		Error: sql.NullString{String: "too few dealers", Valid: true},
+	})
+	assert.NilError(t, err)
+	_, err = db.InsertDecryptionKey(ctx, kprdb.InsertDecryptionKeyParams{Eon: 1, EpochID: []byte{1}, DecryptionKey: []byte{2}})
+	assert.NilError(t, err)
+	err = db.InsertDecryptionKeyShare(ctx, kprdb.InsertDecryptionKeyShareParams{
+		Eon:                1,
+		EpochID:            []byte{1},
+		KeyperIndex:        0,
+		DecryptionKeyShare: []byte{3},
+	})
+	assert.NilError(t, err)
+
+	s, err := load(ctx, dbpool)
+	assert.NilError(t, err)
+	assert.Equal(t, s[TableKeyperSets]["1"].Block, int64(10))
+	assert.Equal(t, s[TableDKGResults]["1"].value, "failed: too few dealers")
+	assert.Equal(t, s[TableDecryptionKeys]["1/0x01"].value, "0x02")
+	assert.Equal(t, s[TableDecryptionKeyShares]["1/0x01/0"].value, "0x03")
+
+	report, err := Compare(ctx, dbpool, dbpool, 10)
+	assert.NilError(t, err)
+	assert.Check(t, !report.Diverged())
+}
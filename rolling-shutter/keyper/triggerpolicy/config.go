@@ -0,0 +1,104 @@
+package triggerpolicy
+
+import (
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+type Config struct {
+	AllowedSources         []string          `comment:"Addresses allowed to sign decryption triggers in addition to being the collator of the trigger block. If it's empty, any collator is accepted"`
+	AllowedEpochIDPrefixes []string          `comment:"Hex encoded prefixes of the epoch ids keys may be produced for. If it's empty, any epoch id is accepted"`
+	MaxEpochs              uint64            `comment:"Maximum number of epochs keys are produced for within RateLimitWindow, 0 disables the limit"`
+	RateLimitWindow        *enctime.Duration `comment:"Time window over which MaxEpochs applies"`
+	TimeWindows            []string          `comment:"Daily UTC time windows in which keys may be produced, e.g. 08:00-20:00. If it's empty, keys are produced at any time"`
+}
+
+func (c *Config) Init() {
+	c.RateLimitWindow = &enctime.Duration{}
+}
+
+func (c *Config) Name() string {
+	return "triggerpolicy"
+}
+
+func (c *Config) Validate() error {
+	if _, err := c.sources(); err != nil {
+		return err
+	}
+	if _, err := c.prefixes(); err != nil {
+		return err
+	}
+	if _, err := c.windows(); err != nil {
+		return err
+	}
+	if c.MaxEpochs > 0 && c.RateLimitWindow.Duration <= 0 {
+		return errors.New("RateLimitWindow must be positive if MaxEpochs is set")
+	}
+	return nil
+}
+
+func (c *Config) sources() (map[common.Address]bool, error) {
+	sources := make(map[common.Address]bool, len(c.AllowedSources))
+	for _, s := range c.AllowedSources {
+		if !common.IsHexAddress(s) {
+			return nil, errors.Errorf("invalid trigger source address %q", s)
+		}
+		sources[common.HexToAddress(s)] = true
+	}
+	return sources, nil
+}
+
+func (c *Config) prefixes() ([][]byte, error) {
+	prefixes := [][]byte{}
+	for _, s := range c.AllowedEpochIDPrefixes {
+		prefix, err := hexutil.Decode(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid epoch id prefix %q", s)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+func (c *Config) windows() ([]timeWindow, error) {
+	windows := []timeWindow{}
+	for _, s := range c.TimeWindows {
+		w, err := parseTimeWindow(s)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.AllowedSources = []string{}
+	c.AllowedEpochIDPrefixes = []string{}
+	c.MaxEpochs = 0
+	c.RateLimitWindow = &enctime.Duration{}
+	c.TimeWindows = []string{}
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,206 @@
+// Package triggerpolicy decides whether a keyper acts on a decryption trigger, i.e. produces its
+// decryption key share for the triggered epoch. The policy is evaluated in addition to the
+// validation of the trigger itself, so that deployments can restrict which epochs keys are
+// produced for and when.
+package triggerpolicy
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+// The rules of the policy, used to tell which one rejected a trigger.
+const (
+	RuleSource     = "source"
+	RuleEpochID    = "epoch-id"
+	RuleTimeWindow = "time-window"
+	RuleRateLimit  = "rate-limit"
+)
+
+// Violation is the error returned for triggers rejected by the policy.
+type Violation struct {
+	Rule   string
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("trigger rejected by %s rule: %s", v.Rule, v.Reason)
+}
+
+// Trigger is a decryption trigger as seen by the policy.
+type Trigger struct {
+	// Source is the address that signed the trigger.
+	Source  common.Address
+	EpochID epochid.EpochID
+	// Time is the time at which the trigger is acted on.
+	Time time.Time
+}
+
+// Policy evaluates triggers against the configured rules. The zero number of rules accepts every
+// trigger, as does a nil policy. The rate limit is kept in memory, so it starts over when the
+// keyper restarts.
+type Policy struct {
+	sources    map[common.Address]bool
+	prefixes   [][]byte
+	windows    []timeWindow
+	maxEpochs  int
+	rateWindow time.Duration
+
+	mux    sync.Mutex
+	recent []acceptedEpoch
+}
+
+type acceptedEpoch struct {
+	epochID epochid.EpochID
+	at      time.Time
+}
+
+func NewPolicy(config *Config) (*Policy, error) {
+	sources, err := config.sources()
+	if err != nil {
+		return nil, err
+	}
+	prefixes, err := config.prefixes()
+	if err != nil {
+		return nil, err
+	}
+	windows, err := config.windows()
+	if err != nil {
+		return nil, err
+	}
+	p := &Policy{
+		sources:  sources,
+		prefixes: prefixes,
+		windows:  windows,
+	}
+	if config.MaxEpochs > 0 {
+		p.maxEpochs = int(config.MaxEpochs)
+		p.rateWindow = config.RateLimitWindow.Duration
+	}
+	return p, nil
+}
+
+// Evaluate returns a *Violation if the trigger must not be acted on. Accepted triggers count
+// towards the rate limit, but triggers for an epoch that has already been accepted within the
+// rate limit window are accepted again without counting twice.
+func (p *Policy) Evaluate(trigger Trigger) error {
+	if p == nil {
+		return nil
+	}
+	if len(p.sources) > 0 && !p.sources[trigger.Source] {
+		return &Violation{Rule: RuleSource, Reason: fmt.Sprintf("%s is not an allowed source", trigger.Source.Hex())}
+	}
+	if len(p.prefixes) > 0 && !p.hasAllowedPrefix(trigger.EpochID) {
+		return &Violation{Rule: RuleEpochID, Reason: fmt.Sprintf("epoch id %s has no allowed prefix", trigger.EpochID.Hex())}
+	}
+	if len(p.windows) > 0 && !p.inTimeWindow(trigger.Time) {
+		return &Violation{
+			Rule:   RuleTimeWindow,
+			Reason: fmt.Sprintf("%s is outside the allowed time windows", trigger.Time.UTC().Format("15:04")),
+		}
+	}
+	if p.maxEpochs > 0 {
+		return p.countEpoch(trigger.EpochID, trigger.Time)
+	}
+	return nil
+}
+
+func (p *Policy) hasAllowedPrefix(epochID epochid.EpochID) bool {
+	for _, prefix := range p.prefixes {
+		if bytes.HasPrefix(epochID.Bytes(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesTime reports whether the policy depends on the wall clock time, i.e. restricts triggers to
+// time windows. The rate limit only depends on the time between triggers.
+func (p *Policy) UsesTime() bool {
+	return p != nil && len(p.windows) > 0
+}
+
+func (p *Policy) inTimeWindow(t time.Time) bool {
+	for _, w := range p.windows {
+		if w.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) countEpoch(epochID epochid.EpochID, now time.Time) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	cutoff := now.Add(-p.rateWindow)
+	i := 0
+	for i < len(p.recent) && !p.recent[i].at.After(cutoff) {
+		i++
+	}
+	p.recent = p.recent[i:]
+
+	for _, accepted := range p.recent {
+		if epochid.Equal(accepted.epochID, epochID) {
+			return nil
+		}
+	}
+	if len(p.recent) >= p.maxEpochs {
+		return &Violation{
+			Rule:   RuleRateLimit,
+			Reason: fmt.Sprintf("%d epochs have already been triggered within %s", len(p.recent), p.rateWindow),
+		}
+	}
+	p.recent = append(p.recent, acceptedEpoch{epochID: epochID, at: now})
+	return nil
+}
+
+// timeWindow is a daily time window in UTC, given as minutes since midnight. Windows with an end
+// before their start span midnight.
+type timeWindow struct {
+	start, end int
+}
+
+func parseTimeWindow(s string) (timeWindow, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return timeWindow{}, errors.Errorf("invalid time window %q, expected e.g. 08:00-20:00", s)
+	}
+	start, err := parseTimeOfDay(strings.TrimSpace(from))
+	if err != nil {
+		return timeWindow{}, errors.Wrapf(err, "invalid time window %q", s)
+	}
+	end, err := parseTimeOfDay(strings.TrimSpace(to))
+	if err != nil {
+		return timeWindow{}, errors.Wrapf(err, "invalid time window %q", s)
+	}
+	if start == end {
+		return timeWindow{}, errors.Errorf("time window %q is empty", s)
+	}
+	return timeWindow{start: start, end: end}, nil
+}
+
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (w timeWindow) contains(t time.Time) bool {
+	t = t.UTC()
+	minute := t.Hour()*60 + t.Minute()
+	if w.start < w.end {
+		return w.start <= minute && minute < w.end
+	}
+	return minute >= w.start || minute < w.end
+}
@@ -0,0 +1,124 @@
+package triggerpolicy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+var (
+	collator = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other    = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	noon     = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func newPolicy(t *testing.T, config *Config) *Policy {
+	t.Helper()
+	assert.NilError(t, config.Validate())
+	p, err := NewPolicy(config)
+	assert.NilError(t, err)
+	return p
+}
+
+func assertRule(t *testing.T, err error, rule string) {
+	t.Helper()
+	var v *Violation
+	assert.Assert(t, errors.As(err, &v), "expected violation, got %v", err)
+	assert.Equal(t, v.Rule, rule)
+}
+
+func TestEmptyPolicyAcceptsAll(t *testing.T) {
+	config := NewConfig()
+	assert.NilError(t, config.SetDefaultValues())
+	p := newPolicy(t, config)
+	for i := uint64(0); i < 100; i++ {
+		assert.NilError(t, p.Evaluate(Trigger{Source: other, EpochID: epochid.Uint64ToEpochID(i), Time: noon}))
+	}
+
+	var nilPolicy *Policy
+	assert.NilError(t, nilPolicy.Evaluate(Trigger{Source: other, Time: noon}))
+}
+
+func TestSources(t *testing.T) {
+	config := NewConfig()
+	config.AllowedSources = []string{collator.Hex()}
+	p := newPolicy(t, config)
+
+	epochID := epochid.Uint64ToEpochID(1)
+	assert.NilError(t, p.Evaluate(Trigger{Source: collator, EpochID: epochID, Time: noon}))
+	assertRule(t, p.Evaluate(Trigger{Source: other, EpochID: epochID, Time: noon}), RuleSource)
+}
+
+func TestEpochIDPrefixes(t *testing.T) {
+	config := NewConfig()
+	config.AllowedEpochIDPrefixes = []string{"0xabcd"}
+	p := newPolicy(t, config)
+
+	allowed, err := epochid.BytesToEpochID(common.RightPadBytes(common.FromHex("0xabcd0001"), 32))
+	assert.NilError(t, err)
+	denied, err := epochid.BytesToEpochID(common.RightPadBytes(common.FromHex("0xab000001"), 32))
+	assert.NilError(t, err)
+	assert.NilError(t, p.Evaluate(Trigger{Source: collator, EpochID: allowed, Time: noon}))
+	assertRule(t, p.Evaluate(Trigger{Source: collator, EpochID: denied, Time: noon}), RuleEpochID)
+}
+
+func TestTimeWindows(t *testing.T) {
+	config := NewConfig()
+	config.TimeWindows = []string{"08:00-10:00", "22:00-02:00"}
+	p := newPolicy(t, config)
+
+	at := func(hour, minute int) Trigger {
+		return Trigger{
+			Source:  collator,
+			EpochID: epochid.Uint64ToEpochID(1),
+			Time:    time.Date(2026, 1, 1, hour, minute, 0, 0, time.UTC),
+		}
+	}
+	for _, trigger := range []Trigger{at(8, 0), at(9, 59), at(22, 0), at(23, 30), at(1, 59)} {
+		assert.NilError(t, p.Evaluate(trigger), "at %s", trigger.Time)
+	}
+	for _, trigger := range []Trigger{at(7, 59), at(10, 0), at(12, 0), at(2, 0), at(21, 59)} {
+		assertRule(t, p.Evaluate(trigger), RuleTimeWindow)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	config := NewConfig()
+	config.MaxEpochs = 2
+	config.RateLimitWindow = &enctime.Duration{Duration: time.Minute}
+	p := newPolicy(t, config)
+
+	trigger := func(epoch uint64, at time.Time) Trigger {
+		return Trigger{Source: collator, EpochID: epochid.Uint64ToEpochID(epoch), Time: at}
+	}
+	assert.NilError(t, p.Evaluate(trigger(1, noon)))
+	assert.NilError(t, p.Evaluate(trigger(2, noon.Add(10*time.Second))))
+	assertRule(t, p.Evaluate(trigger(3, noon.Add(20*time.Second))), RuleRateLimit)
+	// triggers for epochs that have already been accepted don't count again
+	assert.NilError(t, p.Evaluate(trigger(2, noon.Add(30*time.Second))))
+	// the first epoch drops out of the window
+	assert.NilError(t, p.Evaluate(trigger(3, noon.Add(time.Minute))))
+	assertRule(t, p.Evaluate(trigger(4, noon.Add(time.Minute))), RuleRateLimit)
+}
+
+func TestValidate(t *testing.T) {
+	for name, mutate := range map[string]func(*Config){
+		"source":      func(c *Config) { c.AllowedSources = []string{"0x1234"} },
+		"prefix":      func(c *Config) { c.AllowedEpochIDPrefixes = []string{"abcd"} },
+		"window":      func(c *Config) { c.TimeWindows = []string{"08:00"} },
+		"empty":       func(c *Config) { c.TimeWindows = []string{"08:00-08:00"} },
+		"time":        func(c *Config) { c.TimeWindows = []string{"08:00-25:00"} },
+		"rate window": func(c *Config) { c.MaxEpochs = 1 },
+	} {
+		config := NewConfig()
+		assert.NilError(t, config.SetDefaultValues())
+		mutate(config)
+		assert.Assert(t, config.Validate() != nil, name)
+	}
+}
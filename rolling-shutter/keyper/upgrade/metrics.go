@@ -0,0 +1,16 @@
+package upgrade
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsOutdated = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "upgrade",
+		Name:      "node_outdated",
+		Help:      "1 if this node runs a version below the minimum announced by the VersionRequirements contract, 0 otherwise",
+	},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsOutdated)
+}
@@ -0,0 +1,157 @@
+// Package upgrade syncs the minimum node versions announced in the VersionRequirements contract and
+// checks whether this node satisfies them, so that operators learn about required upgrades before
+// nodes running incompatible versions make DKGs fail.
+package upgrade
+
+import (
+	"context"
+	"math"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/mod/semver"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/shversion"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+const (
+	// ContractName is the name of the version requirements contract, as used in
+	// deployment.Contracts.
+	ContractName = "VersionRequirements"
+	// MinimumVersionChangedEventName is the name of the event emitted when the minimum versions
+	// change.
+	MinimumVersionChangedEventName = "MinimumVersionChanged"
+)
+
+var (
+	// ErrOutdated is returned if a node runs a version below the required minimum.
+	ErrOutdated = errors.New("node version is below the required minimum")
+	// ErrUnknownVersion is returned if the software version of a node can't be compared, e.g.
+	// because it is a development build.
+	ErrUnknownVersion = errors.New("node version is not a semantic version")
+)
+
+// Requirement is the minimum software and p2p protocol version nodes are required to run.
+type Requirement struct {
+	MinimumVersion         string
+	MinimumProtocolVersion uint64
+}
+
+// Check checks that a node with the given software and protocol version satisfies the requirement.
+// A minimum software version that is not a semantic version is ignored.
+func (r Requirement) Check(version string, protocolVersion uint64) error {
+	if protocolVersion < r.MinimumProtocolVersion {
+		return errors.Wrapf(ErrOutdated, "protocol version %d is below %d", protocolVersion, r.MinimumProtocolVersion)
+	}
+	if !semver.IsValid(r.MinimumVersion) {
+		return nil
+	}
+	if !semver.IsValid(version) {
+		return errors.Wrapf(ErrUnknownVersion, "can't compare %q to %s", version, r.MinimumVersion)
+	}
+	if semver.Compare(version, r.MinimumVersion) < 0 {
+		return errors.Wrapf(ErrOutdated, "version %s is below %s", version, r.MinimumVersion)
+	}
+	return nil
+}
+
+// CheckOwn checks that this node satisfies the requirement.
+func (r Requirement) CheckOwn() error {
+	return r.Check(shversion.ReleaseVersion(), p2pmsg.ProtocolVersion)
+}
+
+// GetRequirement returns the requirement stored in the db. ok is false if the VersionRequirements
+// contract has not announced one yet.
+func GetRequirement(ctx context.Context, db *kprdb.Queries) (r Requirement, ok bool, err error) {
+	row, err := db.GetNodeVersionRequirement(ctx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Requirement{}, false, nil
+	} else if err != nil {
+		return Requirement{}, false, errors.Wrap(err, "failed to query node version requirement from db")
+	}
+	return Requirement{
+		MinimumVersion:         row.MinimumVersion,
+		MinimumProtocolVersion: uint64(row.MinimumProtocolVersion),
+	}, true, nil
+}
+
+// Outdated reports whether this node is below the requirement stored in the db. Nodes whose version
+// can't be compared are not considered to be outdated.
+func Outdated(ctx context.Context, db *kprdb.Queries) (bool, error) {
+	r, ok, err := GetRequirement(ctx, db)
+	if err != nil || !ok {
+		return false, err
+	}
+	return errors.Is(r.CheckOwn(), ErrOutdated), nil
+}
+
+// Report logs whether this node satisfies the requirement stored in the db and updates the
+// metrics accordingly.
+func Report(ctx context.Context, db *kprdb.Queries) error {
+	r, ok, err := GetRequirement(ctx, db)
+	if err != nil || !ok {
+		return err
+	}
+	report(r)
+	return nil
+}
+
+func report(r Requirement) {
+	err := r.CheckOwn()
+	logger := log.With().
+		Str("version", shversion.ReleaseVersion()).
+		Uint64("protocol-version", p2pmsg.ProtocolVersion).
+		Str("minimum-version", r.MinimumVersion).
+		Uint64("minimum-protocol-version", r.MinimumProtocolVersion).
+		Logger()
+	switch {
+	case errors.Is(err, ErrOutdated):
+		metricsOutdated.Set(1)
+		logger.Warn().Err(err).Msg("node is outdated, upgrade it to keep taking part in new eons")
+	case err != nil:
+		metricsOutdated.Set(0)
+		logger.Warn().Err(err).Msg("can't check node against the minimum version")
+	default:
+		metricsOutdated.Set(0)
+		logger.Info().Msg("node satisfies the minimum version")
+	}
+}
+
+// HandleMinimumVersionChanged stores the requirement announced by a MinimumVersionChanged event and
+// warns if this node does not satisfy it.
+func HandleMinimumVersionChanged(
+	ctx context.Context, chainDB *chainobsdb.Queries, event eventsyncer.DynamicEvent,
+) error {
+	minimumVersion, ok := event.Args["minimumVersion"].(string)
+	if !ok {
+		return errors.New("missing or invalid minimumVersion in MinimumVersionChanged event")
+	}
+	minimumProtocolVersion, ok := event.Args["minimumProtocolVersion"].(uint64)
+	if !ok {
+		return errors.New("missing or invalid minimumProtocolVersion in MinimumVersionChanged event")
+	}
+	if minimumProtocolVersion > math.MaxInt64 {
+		return errors.Errorf("minimum protocol version %d would overflow int64", minimumProtocolVersion)
+	}
+	log.Info().
+		Uint64("block-number", event.Raw.BlockNumber).
+		Str("minimum-version", minimumVersion).
+		Uint64("minimum-protocol-version", minimumProtocolVersion).
+		Msg("handling MinimumVersionChanged event from version requirements contract")
+
+	err := kprdb.New(chainDB.Conn()).SetNodeVersionRequirement(ctx, kprdb.SetNodeVersionRequirementParams{
+		MinimumVersion:         minimumVersion,
+		MinimumProtocolVersion: int64(minimumProtocolVersion),
+		BlockNumber:            int64(event.Raw.BlockNumber),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to store node version requirement in db")
+	}
+	report(Requirement{MinimumVersion: minimumVersion, MinimumProtocolVersion: minimumProtocolVersion})
+	return nil
+}
@@ -0,0 +1,26 @@
+package upgrade
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+)
+
+func TestRequirementCheck(t *testing.T) {
+	r := Requirement{MinimumVersion: "v1.2.0", MinimumProtocolVersion: 2}
+
+	assert.NilError(t, r.Check("v1.2.0", 2))
+	assert.NilError(t, r.Check("v1.10.1", 3))
+	assert.Assert(t, errors.Is(r.Check("v1.1.9", 2), ErrOutdated))
+	assert.Assert(t, errors.Is(r.Check("v1.2.0-rc1", 2), ErrOutdated))
+	assert.Assert(t, errors.Is(r.Check("v1.3.0", 1), ErrOutdated))
+	assert.Assert(t, errors.Is(r.Check("(devel)", 2), ErrUnknownVersion))
+	assert.Assert(t, errors.Is(r.Check("(devel)", 1), ErrOutdated))
+}
+
+func TestRequirementCheckIgnoresInvalidMinimumVersion(t *testing.T) {
+	r := Requirement{MinimumVersion: "latest", MinimumProtocolVersion: 1}
+	assert.NilError(t, r.Check("v0.1.0", 1))
+	assert.NilError(t, r.Check("(devel)", 1))
+}
@@ -0,0 +1,91 @@
+package loadgen
+
+import (
+	"crypto/rand"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/keys"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+func (c *Config) Init() {
+	c.PrivateKey = &keys.ECDSAPrivate{}
+	c.Duration = &enctime.Duration{}
+	c.Timeout = &enctime.Duration{}
+	c.P2P = p2p.NewConfig()
+}
+
+type Config struct {
+	InstanceID   uint64             `shconfig:",required"`
+	CollatorURL  string             `comment:"URL of the collator API transactions are submitted to"`
+	SequencerURL string             `comment:"JSON RPC endpoint of the sequencer, used to query the chain id and the nonce"`
+	PrivateKey   *keys.ECDSAPrivate `shconfig:",required" comment:"key of the funded L2 account that signs the transactions"`
+
+	Rate     float64           `comment:"number of transactions submitted per second"`
+	Duration *enctime.Duration `comment:"how long transactions are submitted, 0 to submit until interrupted"`
+	Timeout  *enctime.Duration `comment:"how long to wait for the decryption key of a transaction before counting it as lost"`
+
+	Gas       uint64
+	GasTipCap uint64 `comment:"in wei"`
+	GasFeeCap uint64 `comment:"in wei"`
+	DataSize  uint64 `comment:"number of random bytes of calldata in each transaction"`
+
+	P2P *p2p.Config
+}
+
+func (c *Config) Validate() error {
+	if c.Rate <= 0 {
+		return errors.New("Rate must be positive")
+	}
+	if c.Timeout.Duration <= 0 {
+		return errors.New("Timeout must be positive")
+	}
+	if c.GasTipCap > c.GasFeeCap {
+		return errors.New("GasTipCap must not exceed GasFeeCap")
+	}
+	return nil
+}
+
+func (c *Config) Name() string {
+	return "loadgen"
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.CollatorURL = "http://localhost:3000/v1"
+	c.SequencerURL = "http://127.0.0.1:8555/"
+	c.Rate = 10
+	c.Duration = &enctime.Duration{Duration: time.Minute}
+	c.Timeout = &enctime.Duration{Duration: time.Minute}
+	c.Gas = 100000
+	c.GasTipCap = 1
+	c.GasFeeCap = 1000000000
+	c.DataSize = 64
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	err := c.SetDefaultValues()
+	if err != nil {
+		return err
+	}
+	c.InstanceID = 42
+	c.PrivateKey, err = keys.GenerateECDSAKey(rand.Reader)
+	return err
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
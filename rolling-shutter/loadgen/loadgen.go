@@ -0,0 +1,387 @@
+// Package loadgen submits encrypted transactions to a collator at a configurable rate and measures
+// how long it takes until they can be decrypted, i.e. until the keypers have released the
+// decryption key of their epoch.
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	cryptorand "crypto/rand"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	txtypes "github.com/shutter-network/txtypes/types"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/collator/client"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/mocknode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// ErrDone is returned by the load generator once it has finished its run.
+var ErrDone = errors.New("load generation finished")
+
+const lostCheckInterval = time.Second
+
+type pendingTx struct {
+	payload          []byte
+	encryptedPayload []byte
+	submitted        time.Time
+}
+
+type LoadGenerator struct {
+	config   *Config
+	collator *client.Client
+	p2p      *p2p.P2PHandler
+	stats    *stats
+
+	mux     sync.Mutex
+	pending map[uint64][]pendingTx
+}
+
+func New(config *Config) (*LoadGenerator, error) {
+	collatorClient, err := client.NewClient(config.CollatorURL)
+	if err != nil {
+		return nil, err
+	}
+	p2pHandler, err := p2p.New(config.P2P, config.InstanceID)
+	if err != nil {
+		return nil, err
+	}
+	g := &LoadGenerator{
+		config:   config,
+		collator: collatorClient,
+		p2p:      p2pHandler,
+		stats:    newStats(time.Now()),
+		pending:  make(map[uint64][]pendingTx),
+	}
+	g.p2p.AddHandlerFunc(g.handleDecryptionKey, &p2pmsg.DecryptionKey{})
+	return g, nil
+}
+
+func (g *LoadGenerator) Start(ctx context.Context, runner service.Runner) error {
+	if err := runner.StartService(g.p2p); err != nil {
+		return err
+	}
+	runner.Go(func() error {
+		return g.run(ctx)
+	})
+	runner.Go(func() error {
+		return g.checkLost(ctx)
+	})
+	return nil
+}
+
+// Report summarizes the run so far.
+func (g *LoadGenerator) Report() Report {
+	g.mux.Lock()
+	pending := 0
+	for _, txs := range g.pending {
+		pending += len(txs)
+	}
+	g.mux.Unlock()
+	return g.stats.report(time.Now(), pending)
+}
+
+// run submits transactions until the configured duration has passed and then waits for the
+// outstanding ones to be decrypted or lost.
+func (g *LoadGenerator) run(ctx context.Context) error {
+	l2Client, err := ethclient.DialContext(ctx, g.config.SequencerURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to sequencer")
+	}
+	defer l2Client.Close()
+	chainID, err := l2Client.ChainID(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get chain id from sequencer")
+	}
+	eonPublicKey, err := g.getEonPublicKey(ctx)
+	if err != nil {
+		return err
+	}
+	sender := &txSender{
+		config:       g.config,
+		l2Client:     l2Client,
+		signer:       txtypes.LatestSignerForChainID(chainID),
+		chainID:      chainID,
+		eonPublicKey: eonPublicKey,
+	}
+	log.Info().Float64("rate", g.config.Rate).Str("duration", g.config.Duration.String()).
+		Str("account", g.config.PrivateKey.EthereumAddress().Hex()).Msg("starting load generation")
+
+	submitCtx := ctx
+	if g.config.Duration.Duration > 0 {
+		var cancel context.CancelFunc
+		submitCtx, cancel = context.WithTimeout(ctx, g.config.Duration.Duration)
+		defer cancel()
+	}
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / g.config.Rate))
+	defer ticker.Stop()
+submitLoop:
+	for {
+		select {
+		case <-submitCtx.Done():
+			break submitLoop
+		case <-ticker.C:
+		}
+		if err := g.submit(submitCtx, sender); err != nil {
+			if submitCtx.Err() != nil {
+				break submitLoop
+			}
+			g.stats.addRejected()
+			log.Warn().Err(err).Msg("failed to submit transaction")
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	log.Info().Msg("done submitting transactions, waiting for outstanding decryption keys")
+	deadline := time.NewTimer(g.config.Timeout.Duration + lostCheckInterval)
+	defer deadline.Stop()
+	ticker.Reset(lostCheckInterval)
+	for {
+		if g.Report().Pending == 0 {
+			return ErrDone
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return ErrDone
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *LoadGenerator) submit(ctx context.Context, sender *txSender) error {
+	httpResponse, err := g.collator.GetNextEpoch(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get next epoch from collator")
+	}
+	nextEpochResponse, err := client.ParseGetNextEpochResponse(httpResponse)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse next epoch response")
+	}
+	if nextEpochResponse.JSON200 == nil {
+		return errors.Errorf("collator returned no next epoch: %s", nextEpochResponse.Status())
+	}
+	epochID, err := epochid.BigToEpochID(new(big.Int).SetBytes(nextEpochResponse.JSON200.Id))
+	if err != nil {
+		return err
+	}
+
+	tx, err := sender.makeTx(ctx, epochID)
+	if err != nil {
+		return err
+	}
+	httpResponse, err = g.collator.SubmitTransaction(ctx, client.SubmitTransactionJSONRequestBody{
+		EncryptedTx: tx.encoded,
+		Epoch:       epochID.Bytes(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to submit transaction")
+	}
+	response, err := client.ParseSubmitTransactionResponse(httpResponse)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse submit transaction response")
+	}
+	if response.JSON200 == nil {
+		// the nonce may not have been used, so query it again before the next transaction
+		sender.resetNonce()
+		if response.JSONDefault != nil {
+			return errors.Errorf("collator rejected transaction: %s", response.JSONDefault.Message)
+		}
+		return errors.Errorf("collator rejected transaction: %s", response.Status())
+	}
+	sender.nonce++
+
+	g.stats.addSubmitted()
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	g.pending[epochID.Uint64()] = append(g.pending[epochID.Uint64()], pendingTx{
+		payload:          tx.payload,
+		encryptedPayload: tx.encryptedPayload,
+		submitted:        time.Now(),
+	})
+	return nil
+}
+
+func (g *LoadGenerator) getEonPublicKey(ctx context.Context) (*shcrypto.EonPublicKey, error) {
+	httpResponse, err := g.collator.GetEonPublicKey(ctx, &client.GetEonPublicKeyParams{
+		ActivationBlock: math.MaxInt64,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get eon public key from collator")
+	}
+	response, err := client.ParseGetEonPublicKeyResponse(httpResponse)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse eon public key response")
+	}
+	if response.JSON200 == nil || len(response.JSON200.EonPublicKey) == 0 {
+		return nil, errors.New("collator doesn't know an eon public key yet")
+	}
+	eonPublicKey := new(shcrypto.EonPublicKey)
+	if err := eonPublicKey.Unmarshal(response.JSON200.EonPublicKey); err != nil {
+		return nil, errors.Wrap(err, "invalid eon public key")
+	}
+	return eonPublicKey, nil
+}
+
+func (g *LoadGenerator) handleDecryptionKey(_ context.Context, m p2pmsg.Message) ([]p2pmsg.Message, error) {
+	key := m.(*p2pmsg.DecryptionKey)
+	received := time.Now()
+	epochID, err := epochid.BytesToEpochID(key.EpochID)
+	if err != nil {
+		return nil, err
+	}
+	g.mux.Lock()
+	txs := g.pending[epochID.Uint64()]
+	delete(g.pending, epochID.Uint64())
+	g.mux.Unlock()
+	if len(txs) == 0 {
+		return nil, nil
+	}
+
+	epochSecretKey, err := key.GetEpochSecretKey()
+	if err != nil {
+		for range txs {
+			g.stats.addCorrupted()
+		}
+		return nil, err
+	}
+	for _, tx := range txs {
+		encrypted := new(shcrypto.EncryptedMessage)
+		if err := encrypted.Unmarshal(tx.encryptedPayload); err != nil {
+			g.stats.addCorrupted()
+			continue
+		}
+		decrypted, err := encrypted.Decrypt(epochSecretKey)
+		if err != nil || !bytes.Equal(decrypted, tx.payload) {
+			log.Warn().Err(err).Str("epoch-id", epochID.Hex()).Msg("failed to decrypt transaction")
+			g.stats.addCorrupted()
+			continue
+		}
+		g.stats.addDecrypted(received.Sub(tx.submitted))
+	}
+	log.Debug().Str("epoch-id", epochID.Hex()).Int("num-txs", len(txs)).Msg("received decryption key")
+	return nil, nil
+}
+
+// checkLost periodically counts transactions whose decryption key didn't arrive in time as lost.
+func (g *LoadGenerator) checkLost(ctx context.Context) error {
+	ticker := time.NewTicker(lostCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		cutoff := time.Now().Add(-g.config.Timeout.Duration)
+		lost := 0
+		g.mux.Lock()
+		for epoch, txs := range g.pending {
+			kept := txs[:0]
+			for _, tx := range txs {
+				if tx.submitted.Before(cutoff) {
+					lost++
+				} else {
+					kept = append(kept, tx)
+				}
+			}
+			if len(kept) == 0 {
+				delete(g.pending, epoch)
+			} else {
+				g.pending[epoch] = kept
+			}
+		}
+		g.mux.Unlock()
+		if lost > 0 {
+			g.stats.addLost(lost)
+			log.Warn().Int("num-txs", lost).Msg("decryption keys didn't arrive in time")
+		}
+	}
+}
+
+// txSender builds signed shutter transactions with encrypted payloads.
+type txSender struct {
+	config       *Config
+	l2Client     *ethclient.Client
+	signer       txtypes.Signer
+	chainID      *big.Int
+	eonPublicKey *shcrypto.EonPublicKey
+
+	nonce      uint64
+	nonceKnown bool
+}
+
+type signedTx struct {
+	encoded          []byte
+	payload          []byte
+	encryptedPayload []byte
+}
+
+func (s *txSender) resetNonce() {
+	s.nonceKnown = false
+}
+
+func (s *txSender) makeTx(ctx context.Context, epochID epochid.EpochID) (*signedTx, error) {
+	if !s.nonceKnown {
+		nonce, err := s.l2Client.PendingNonceAt(ctx, s.config.PrivateKey.EthereumAddress())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get nonce from sequencer")
+		}
+		s.nonce = nonce
+		s.nonceKnown = true
+	}
+
+	data := make([]byte, s.config.DataSize)
+	if _, err := cryptorand.Read(data); err != nil {
+		return nil, errors.Wrap(err, "failed to generate calldata")
+	}
+	to := s.config.PrivateKey.EthereumAddress()
+	payload := &txtypes.ShutterPayload{
+		To:    &to,
+		Data:  data,
+		Value: big.NewInt(0),
+	}
+	encodedPayload, err := payload.Encode()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode payload")
+	}
+	encryptedPayload, err := mocknode.EncryptMessage(encodedPayload, epochID, s.eonPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := txtypes.SignNewTx(s.config.PrivateKey.Key, s.signer, &txtypes.ShutterTx{
+		ChainID:          s.chainID,
+		Nonce:            s.nonce,
+		GasTipCap:        new(big.Int).SetUint64(s.config.GasTipCap),
+		GasFeeCap:        new(big.Int).SetUint64(s.config.GasFeeCap),
+		Gas:              s.config.Gas,
+		EncryptedPayload: encryptedPayload,
+		BatchIndex:       epochID.Uint64(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign transaction")
+	}
+	encoded, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode transaction")
+	}
+	return &signedTx{
+		encoded:          encoded,
+		payload:          encodedPayload,
+		encryptedPayload: encryptedPayload,
+	}, nil
+}
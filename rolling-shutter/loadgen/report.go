@@ -0,0 +1,128 @@
+package loadgen
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Report summarizes a load generation run.
+type Report struct {
+	Duration  Duration `json:"duration"`
+	Submitted uint64   `json:"submitted"`
+	Rejected  uint64   `json:"rejected"`
+	Decrypted uint64   `json:"decrypted"`
+	// Corrupted counts transactions whose payload could not be decrypted with the decryption key
+	// of their epoch or didn't match the payload that was encrypted.
+	Corrupted uint64 `json:"corrupted"`
+	// Lost counts transactions whose decryption key didn't arrive within the timeout.
+	Lost uint64 `json:"lost"`
+	// Pending counts transactions still waiting for their decryption key when the report was made.
+	Pending      uint64   `json:"pending"`
+	LossRate     float64  `json:"lossRate"`
+	SubmitRate   float64  `json:"submitRate"`
+	LatencyP50   Duration `json:"latencyP50"`
+	LatencyP90   Duration `json:"latencyP90"`
+	LatencyP99   Duration `json:"latencyP99"`
+	LatencyMax   Duration `json:"latencyMax"`
+	LatencyMean  Duration `json:"latencyMean"`
+	LatencyCount int      `json:"latencyCount"`
+}
+
+// Duration is a time.Duration that is encoded in human readable form.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// stats collects the outcome of the submitted transactions.
+type stats struct {
+	mux       sync.Mutex
+	start     time.Time
+	submitted uint64
+	rejected  uint64
+	corrupted uint64
+	lost      uint64
+	latencies []time.Duration
+}
+
+func newStats(start time.Time) *stats {
+	return &stats{start: start}
+}
+
+func (s *stats) addSubmitted() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.submitted++
+}
+
+func (s *stats) addRejected() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.rejected++
+}
+
+func (s *stats) addCorrupted() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.corrupted++
+}
+
+func (s *stats) addLost(n int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.lost += uint64(n)
+}
+
+func (s *stats) addDecrypted(latency time.Duration) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.latencies = append(s.latencies, latency)
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(p * float64(len(sorted)-1))
+	return sorted[i]
+}
+
+func (s *stats) report(now time.Time, pending int) Report {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	latencies := append([]time.Duration{}, s.latencies...)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var sum time.Duration
+	for _, l := range latencies {
+		sum += l
+	}
+
+	elapsed := now.Sub(s.start)
+	r := Report{
+		Duration:     Duration(elapsed),
+		Submitted:    s.submitted,
+		Rejected:     s.rejected,
+		Decrypted:    uint64(len(latencies)),
+		Corrupted:    s.corrupted,
+		Lost:         s.lost,
+		Pending:      uint64(pending),
+		LatencyP50:   Duration(percentile(latencies, 0.5)),
+		LatencyP90:   Duration(percentile(latencies, 0.9)),
+		LatencyP99:   Duration(percentile(latencies, 0.99)),
+		LatencyCount: len(latencies),
+	}
+	if len(latencies) > 0 {
+		r.LatencyMax = Duration(latencies[len(latencies)-1])
+		r.LatencyMean = Duration(sum / time.Duration(len(latencies)))
+	}
+	if s.submitted > 0 {
+		r.LossRate = float64(s.lost+s.corrupted) / float64(s.submitted)
+	}
+	if elapsed > 0 {
+		r.SubmitRate = float64(s.submitted) / elapsed.Seconds()
+	}
+	return r
+}
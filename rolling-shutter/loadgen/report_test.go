@@ -0,0 +1,44 @@
+package loadgen
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestReport(t *testing.T) {
+	start := time.Unix(1000, 0)
+	s := newStats(start)
+	for i := 1; i <= 10; i++ {
+		s.addSubmitted()
+		s.addDecrypted(time.Duration(i) * time.Second)
+	}
+	s.addSubmitted()
+	s.addSubmitted()
+	s.addLost(1)
+	s.addCorrupted()
+	s.addRejected()
+
+	r := s.report(start.Add(4*time.Second), 3)
+	assert.Equal(t, r.Submitted, uint64(12))
+	assert.Equal(t, r.Decrypted, uint64(10))
+	assert.Equal(t, r.Rejected, uint64(1))
+	assert.Equal(t, r.Pending, uint64(3))
+	assert.Equal(t, r.LossRate, 2.0/12)
+	assert.Equal(t, r.SubmitRate, 3.0)
+	assert.Equal(t, r.LatencyP50, Duration(5*time.Second))
+	assert.Equal(t, r.LatencyP90, Duration(9*time.Second))
+	assert.Equal(t, r.LatencyMax, Duration(10*time.Second))
+	assert.Equal(t, r.LatencyMean, Duration(5500*time.Millisecond))
+
+	data, err := json.Marshal(r)
+	assert.NilError(t, err)
+	assert.Check(t, json.Valid(data))
+	assert.Assert(t, len(data) > 0)
+
+	empty := newStats(start).report(start, 0)
+	assert.Equal(t, empty.LatencyMax, Duration(0))
+	assert.Equal(t, empty.LossRate, 0.0)
+}
@@ -0,0 +1,140 @@
+// Package alert notifies node operators about events that require their attention.
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/jobqueue"
+)
+
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Alert is a single notification for the operator.
+type Alert struct {
+	Severity Severity          `json:"severity"`
+	Summary  string            `json:"summary"`
+	Details  map[string]string `json:"details,omitempty"`
+	Time     time.Time         `json:"time"`
+}
+
+// Notifier delivers alerts to the operator.
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// New returns a notifier that logs every alert and, if a webhook URL is configured, also POSTs it
+// to the webhook.
+func New(config *Config) Notifier {
+	if config.WebhookURL == "" {
+		return logNotifier{}
+	}
+	return &webhookNotifier{
+		url:    config.WebhookURL,
+		client: &http.Client{Timeout: config.Timeout.Duration},
+	}
+}
+
+type logNotifier struct{}
+
+func (logNotifier) Notify(_ context.Context, alert Alert) error {
+	ev := log.Warn()
+	if alert.Severity == SeverityInfo {
+		ev = log.Info()
+	}
+	for k, v := range alert.Details {
+		ev = ev.Str(k, v)
+	}
+	ev.Str("severity", string(alert.Severity)).Msg(alert.Summary)
+	return nil
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	if err := (logNotifier{}).Notify(ctx, alert); err != nil {
+		return err
+	}
+	if alert.Time.IsZero() {
+		alert.Time = time.Now()
+	}
+	return n.post(ctx, alert)
+}
+
+func (n *webhookNotifier) post(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal alert")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create alert request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send alert to webhook")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := errors.Errorf("alert webhook responded with status %s", resp.Status)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return jobqueue.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// webhookJobKind is the kind of the jobs delivering alerts to the webhook.
+const webhookJobKind = "alert-webhook"
+
+// NewQueued returns a notifier like New, except that alerts are delivered to the webhook by jobs
+// of the queue. Delivery is retried until the webhook accepts the alert, even across restarts.
+func NewQueued(config *Config, dbpool *pgxpool.Pool, queue *jobqueue.Queue) Notifier {
+	if config.WebhookURL == "" {
+		return logNotifier{}
+	}
+	webhook := &webhookNotifier{
+		url:    config.WebhookURL,
+		client: &http.Client{Timeout: config.Timeout.Duration},
+	}
+	queue.Register(webhookJobKind, func(ctx context.Context, payload []byte) error {
+		var alert Alert
+		if err := json.Unmarshal(payload, &alert); err != nil {
+			return jobqueue.Permanent(errors.Wrap(err, "failed to decode alert"))
+		}
+		return webhook.post(ctx, alert)
+	})
+	return &queuedNotifier{dbpool: dbpool}
+}
+
+type queuedNotifier struct {
+	dbpool *pgxpool.Pool
+}
+
+func (n *queuedNotifier) Notify(ctx context.Context, alert Alert) error {
+	if err := (logNotifier{}).Notify(ctx, alert); err != nil {
+		return err
+	}
+	if alert.Time.IsZero() {
+		alert.Time = time.Now()
+	}
+	return jobqueue.Enqueue(ctx, n.dbpool, webhookJobKind, alert)
+}
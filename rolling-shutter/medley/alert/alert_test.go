@@ -0,0 +1,51 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	received := make(chan Alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- alert
+	}))
+	defer server.Close()
+
+	notifier := New(&Config{WebhookURL: server.URL, Timeout: &enctime.Duration{Duration: time.Second}})
+	err := notifier.Notify(context.Background(), Alert{
+		Severity: SeverityWarning,
+		Summary:  "something happened",
+		Details:  map[string]string{"key": "value"},
+	})
+	assert.NilError(t, err)
+	alert := <-received
+	assert.Equal(t, alert.Severity, SeverityWarning)
+	assert.Equal(t, alert.Summary, "something happened")
+	assert.Equal(t, alert.Details["key"], "value")
+	assert.Assert(t, !alert.Time.IsZero())
+}
+
+func TestWebhookNotifierError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier := New(&Config{WebhookURL: server.URL, Timeout: &enctime.Duration{Duration: time.Second}})
+	err := notifier.Notify(context.Background(), Alert{Severity: SeverityInfo, Summary: "test"})
+	assert.ErrorContains(t, err, "500")
+}
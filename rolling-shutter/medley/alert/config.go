@@ -0,0 +1,61 @@
+package alert
+
+import (
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+type Config struct {
+	WebhookURL string            `comment:"Alerts are POSTed as JSON to this URL. If it's empty, alerts are only logged"`
+	Timeout    *enctime.Duration `comment:"Timeout of a single webhook request"`
+}
+
+func (c *Config) Init() {
+	c.Timeout = &enctime.Duration{}
+}
+
+func (c *Config) Name() string {
+	return "alerting"
+}
+
+func (c *Config) Validate() error {
+	if c.WebhookURL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.WebhookURL)
+	if err != nil {
+		return errors.Wrap(err, "invalid alerting webhook URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.Errorf("alerting webhook URL must use http or https, got %q", u.Scheme)
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.WebhookURL = ""
+	c.Timeout = &enctime.Duration{Duration: 10 * time.Second}
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,269 @@
+// Package beaconapi is a client for the standard beacon node API of the consensus layer of an
+// Ethereum chain. It maps execution blocks to the slots they were proposed in and the finality
+// checkpoints of the consensus layer to execution block numbers, and it fetches blob sidecars.
+package beaconapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// RequestTimeout limits each request if no http client is given to New.
+	RequestTimeout = 30 * time.Second
+	// maxResponseSize limits the size of the responses. A block carries a few blobs of 128KiB
+	// each, which are hex encoded in the response.
+	maxResponseSize = 32 << 20
+)
+
+// ErrNotFound is returned if the beacon node doesn't know the requested object, e.g. because it has
+// already been pruned.
+var ErrNotFound = errors.New("not found in beacon node")
+
+// Timing describes when the slots of the beacon chain start. It never changes.
+type Timing struct {
+	GenesisTime    uint64
+	SecondsPerSlot uint64
+	SlotsPerEpoch  uint64
+}
+
+// SlotAt returns the slot the given unix timestamp falls into.
+func (t Timing) SlotAt(timestamp uint64) (uint64, error) {
+	if timestamp < t.GenesisTime {
+		return 0, errors.Errorf("timestamp %d is before the beacon chain genesis at %d", timestamp, t.GenesisTime)
+	}
+	return (timestamp - t.GenesisTime) / t.SecondsPerSlot, nil
+}
+
+// SlotStart returns the unix timestamp the given slot starts at.
+func (t Timing) SlotStart(slot uint64) uint64 {
+	return t.GenesisTime + slot*t.SecondsPerSlot
+}
+
+// Epoch returns the beacon chain epoch the given slot belongs to.
+func (t Timing) Epoch(slot uint64) uint64 {
+	return slot / t.SlotsPerEpoch
+}
+
+// Client queries a beacon node. The timing of the beacon chain is only fetched once.
+type Client struct {
+	baseURL string
+	client  *http.Client
+
+	mux             sync.Mutex
+	timing          *Timing
+	checkpointRoots map[common.Hash]uint64
+}
+
+// New creates a client of the beacon node at baseURL. If httpClient is nil, a client with a timeout
+// of RequestTimeout is used.
+func New(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: RequestTimeout}
+	}
+	return &Client{
+		baseURL:         strings.TrimSuffix(baseURL, "/"),
+		client:          httpClient,
+		checkpointRoots: make(map[common.Hash]uint64),
+	}
+}
+
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to query beacon API at %s", path)
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return errors.Wrap(ErrNotFound, path)
+	default:
+		return errors.Errorf("beacon API returned status %d for %s", res.StatusCode, path)
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxResponseSize)).Decode(v); err != nil {
+		return errors.Wrapf(err, "failed to decode beacon API response for %s", path)
+	}
+	return nil
+}
+
+type genesisResponse struct {
+	Data struct {
+		GenesisTime string `json:"genesis_time"`
+	} `json:"data"`
+}
+
+type specResponse struct {
+	Data struct {
+		SecondsPerSlot string `json:"SECONDS_PER_SLOT"`
+		SlotsPerEpoch  string `json:"SLOTS_PER_EPOCH"`
+	} `json:"data"`
+}
+
+func parsePositive(name string, s string) (uint64, error) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || n == 0 {
+		return 0, errors.Errorf("invalid %s %q in beacon API response", name, s)
+	}
+	return n, nil
+}
+
+// Timing returns the timing of the beacon chain.
+func (c *Client) Timing(ctx context.Context) (Timing, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.timing != nil {
+		return *c.timing, nil
+	}
+	var genesis genesisResponse
+	if err := c.get(ctx, "/eth/v1/beacon/genesis", &genesis); err != nil {
+		return Timing{}, err
+	}
+	var spec specResponse
+	if err := c.get(ctx, "/eth/v1/config/spec", &spec); err != nil {
+		return Timing{}, err
+	}
+	genesisTime, err := strconv.ParseUint(genesis.Data.GenesisTime, 10, 64)
+	if err != nil {
+		return Timing{}, errors.Wrap(err, "invalid genesis time in beacon API response")
+	}
+	secondsPerSlot, err := parsePositive("seconds per slot", spec.Data.SecondsPerSlot)
+	if err != nil {
+		return Timing{}, err
+	}
+	slotsPerEpoch, err := parsePositive("slots per epoch", spec.Data.SlotsPerEpoch)
+	if err != nil {
+		return Timing{}, err
+	}
+	c.timing = &Timing{GenesisTime: genesisTime, SecondsPerSlot: secondsPerSlot, SlotsPerEpoch: slotsPerEpoch}
+	return *c.timing, nil
+}
+
+// SlotOfBlock returns the slot the given execution block was proposed in.
+func (c *Client) SlotOfBlock(ctx context.Context, header *types.Header) (uint64, error) {
+	timing, err := c.Timing(ctx)
+	if err != nil {
+		return 0, err
+	}
+	slot, err := timing.SlotAt(header.Time)
+	if err != nil {
+		return 0, errors.Wrapf(err, "block %d", header.Number)
+	}
+	return slot, nil
+}
+
+type checkpoint struct {
+	Root common.Hash `json:"root"`
+}
+
+type finalityCheckpointsResponse struct {
+	Data struct {
+		CurrentJustified checkpoint `json:"current_justified"`
+		Finalized        checkpoint `json:"finalized"`
+	} `json:"data"`
+}
+
+type blockResponse struct {
+	Data struct {
+		Message struct {
+			Body struct {
+				ExecutionPayload *struct {
+					BlockNumber string `json:"block_number"`
+				} `json:"execution_payload"`
+			} `json:"body"`
+		} `json:"message"`
+	} `json:"data"`
+}
+
+// CheckpointBlockNumber returns the number of the latest execution block covered by the checkpoint
+// of the given mode, i.e. the execution block of the current justified or the finalized beacon
+// block. It returns 0 as long as the checkpoint doesn't contain an execution block yet.
+func (c *Client) CheckpointBlockNumber(ctx context.Context, mode SyncMode) (uint64, error) {
+	var res finalityCheckpointsResponse
+	if err := c.get(ctx, "/eth/v1/beacon/states/head/finality_checkpoints", &res); err != nil {
+		return 0, err
+	}
+	var root common.Hash
+	switch mode {
+	case SyncModeSafe:
+		root = res.Data.CurrentJustified.Root
+	case SyncModeFinalized:
+		root = res.Data.Finalized.Root
+	default:
+		return 0, errors.Errorf("sync mode %s has no checkpoint", mode)
+	}
+	if root == (common.Hash{}) {
+		return 0, nil
+	}
+	return c.executionBlockNumber(ctx, root)
+}
+
+// executionBlockNumber returns the number of the execution block contained in the beacon block with
+// the given root. Checkpoints only change once per epoch, so the numbers are cached by root.
+func (c *Client) executionBlockNumber(ctx context.Context, root common.Hash) (uint64, error) {
+	c.mux.Lock()
+	number, ok := c.checkpointRoots[root]
+	c.mux.Unlock()
+	if ok {
+		return number, nil
+	}
+
+	var res blockResponse
+	if err := c.get(ctx, fmt.Sprintf("/eth/v2/beacon/blocks/%s", root.Hex()), &res); err != nil {
+		return 0, errors.Wrapf(err, "failed to fetch beacon block %s", root.Hex())
+	}
+	// blocks before the merge don't contain execution payloads
+	if payload := res.Data.Message.Body.ExecutionPayload; payload != nil {
+		var err error
+		number, err = strconv.ParseUint(payload.BlockNumber, 10, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid execution block number in beacon block %s", root.Hex())
+		}
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	// only the current checkpoints are needed
+	if len(c.checkpointRoots) > 2 {
+		c.checkpointRoots = make(map[common.Hash]uint64)
+	}
+	c.checkpointRoots[root] = number
+	return number, nil
+}
+
+// BlobSidecar is a blob published in a slot along with its KZG commitment and proof.
+type BlobSidecar struct {
+	Blob          hexutil.Bytes `json:"blob"`
+	KZGCommitment hexutil.Bytes `json:"kzg_commitment"`
+	KZGProof      hexutil.Bytes `json:"kzg_proof"`
+}
+
+// BlobSidecarsResponse is the response of the blob sidecars endpoint.
+type BlobSidecarsResponse struct {
+	Data []BlobSidecar `json:"data"`
+}
+
+// BlobSidecars returns the blob sidecars of the given slot. The sidecars are not verified.
+func (c *Client) BlobSidecars(ctx context.Context, slot uint64) ([]BlobSidecar, error) {
+	var res BlobSidecarsResponse
+	if err := c.get(ctx, fmt.Sprintf("/eth/v1/beacon/blob_sidecars/%d", slot), &res); err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch blob sidecars of slot %d", slot)
+	}
+	return res.Data, nil
+}
@@ -0,0 +1,101 @@
+package beaconapi
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+)
+
+const (
+	justifiedRoot = "0x00000000000000000000000000000000000000000000000000000000000000aa"
+	finalizedRoot = "0x00000000000000000000000000000000000000000000000000000000000000bb"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, map[string]int) {
+	t.Helper()
+	requests := make(map[string]int)
+	responses := map[string]string{
+		"/eth/v1/beacon/genesis": `{"data": {"genesis_time": "1000"}}`,
+		"/eth/v1/config/spec":    `{"data": {"SECONDS_PER_SLOT": "12", "SLOTS_PER_EPOCH": "32"}}`,
+		"/eth/v1/beacon/states/head/finality_checkpoints": `{"data": {
+			"previous_justified": {"epoch": "2", "root": "` + finalizedRoot + `"},
+			"current_justified": {"epoch": "3", "root": "` + justifiedRoot + `"},
+			"finalized": {"epoch": "2", "root": "` + finalizedRoot + `"}
+		}}`,
+		"/eth/v2/beacon/blocks/" + justifiedRoot: `{"data": {"message": {"body": {"execution_payload": {"block_number": "130"}}}}}`,
+		"/eth/v2/beacon/blocks/" + finalizedRoot: `{"data": {"message": {"body": {"execution_payload": {"block_number": "100"}}}}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		res, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(res))
+	}))
+	return server, requests
+}
+
+func TestTiming(t *testing.T) {
+	server, requests := newTestServer(t)
+	defer server.Close()
+	c := New(server.URL+"/", server.Client())
+	ctx := context.Background()
+
+	timing, err := c.Timing(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, timing, Timing{GenesisTime: 1000, SecondsPerSlot: 12, SlotsPerEpoch: 32})
+	assert.Equal(t, timing.SlotStart(5), uint64(1060))
+	assert.Equal(t, timing.Epoch(65), uint64(2))
+
+	slot, err := c.SlotOfBlock(ctx, &types.Header{Number: big.NewInt(1), Time: 1000 + 5*12 + 11})
+	assert.NilError(t, err)
+	assert.Equal(t, slot, uint64(5))
+	_, err = c.SlotOfBlock(ctx, &types.Header{Number: big.NewInt(1), Time: 999})
+	assert.ErrorContains(t, err, "before the beacon chain genesis")
+	assert.Equal(t, requests["/eth/v1/beacon/genesis"], 1)
+}
+
+func TestCheckpointBlockNumber(t *testing.T) {
+	server, requests := newTestServer(t)
+	defer server.Close()
+	c := New(server.URL, server.Client())
+	ctx := context.Background()
+
+	n, err := c.Checkpoint(SyncModeSafe).BlockNumber(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, n, uint64(130))
+	n, err = c.Checkpoint(SyncModeFinalized).BlockNumber(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, n, uint64(100))
+	_, err = c.CheckpointBlockNumber(ctx, SyncModeFinalized)
+	assert.NilError(t, err)
+	// the execution block numbers are cached by root
+	assert.Equal(t, requests["/eth/v2/beacon/blocks/"+finalizedRoot], 1)
+
+	_, err = c.CheckpointBlockNumber(ctx, SyncModeLatest)
+	assert.ErrorContains(t, err, "has no checkpoint")
+
+	_, err = c.BlobSidecars(ctx, 7)
+	assert.Assert(t, errors.Is(err, ErrNotFound))
+}
+
+func TestParseSyncMode(t *testing.T) {
+	m, err := ParseSyncMode("")
+	assert.NilError(t, err)
+	assert.Equal(t, m, SyncModeLatest)
+	assert.Check(t, !m.UsesCheckpoint())
+	m, err = ParseSyncMode("finalized")
+	assert.NilError(t, err)
+	assert.Equal(t, m, SyncModeFinalized)
+	assert.Check(t, m.UsesCheckpoint())
+	_, err = ParseSyncMode("pending")
+	assert.ErrorContains(t, err, "unknown sync mode")
+}
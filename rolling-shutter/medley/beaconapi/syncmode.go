@@ -0,0 +1,55 @@
+package beaconapi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// SyncMode determines up to which block contract events are synced.
+type SyncMode string
+
+const (
+	// SyncModeLatest syncs up to a few blocks behind the head of the chain, which doesn't require
+	// a beacon node.
+	SyncModeLatest SyncMode = "latest"
+	// SyncModeSafe syncs up to the execution block of the current justified checkpoint.
+	SyncModeSafe SyncMode = "safe"
+	// SyncModeFinalized syncs up to the execution block of the finalized checkpoint, so events are
+	// never reorged out after they have been applied.
+	SyncModeFinalized SyncMode = "finalized"
+)
+
+// ParseSyncMode returns the sync mode with the given name. The empty name selects SyncModeLatest.
+func ParseSyncMode(name string) (SyncMode, error) {
+	switch m := SyncMode(name); m {
+	case "":
+		return SyncModeLatest, nil
+	case SyncModeLatest, SyncModeSafe, SyncModeFinalized:
+		return m, nil
+	default:
+		return "", errors.Errorf("unknown sync mode %q", name)
+	}
+}
+
+// UsesCheckpoint reports whether the sync mode follows a checkpoint of the beacon chain.
+func (m SyncMode) UsesCheckpoint() bool {
+	return m == SyncModeSafe || m == SyncModeFinalized
+}
+
+// Checkpoint provides the latest execution block covered by a checkpoint of the beacon chain, see
+// eventsyncer.CheckpointReader.
+type Checkpoint struct {
+	client *Client
+	mode   SyncMode
+}
+
+// Checkpoint returns the checkpoint followed in the given mode, which must use one.
+func (c *Client) Checkpoint(mode SyncMode) *Checkpoint {
+	return &Checkpoint{client: c, mode: mode}
+}
+
+// BlockNumber returns the number of the latest execution block covered by the checkpoint.
+func (cp *Checkpoint) BlockNumber(ctx context.Context) (uint64, error) {
+	return cp.client.CheckpointBlockNumber(ctx, cp.mode)
+}
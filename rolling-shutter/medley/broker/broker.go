@@ -0,0 +1,78 @@
+// Package broker implements a typed in-process event bus. Subsystems publish domain events to the
+// topics of a bus, and other subsystems subscribe to the topics they are interested in, without
+// the publishers knowing about them.
+package broker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
+)
+
+// Topic identifies a stream of events of type T on a bus.
+type Topic[T any] struct {
+	Name string
+}
+
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{Name: name}
+}
+
+// Bus distributes the events published to a topic to all subscribers of the topic. Delivery is
+// blocking, i.e. publishing an event waits until all subscribers have received the previous one,
+// so that no event is lost. Subscribers must keep reading from their channels and should use a
+// buffer large enough to absorb bursts of events.
+//
+// Publishing to a nil *Bus drops the event, so that publishers can be used without a bus.
+type Bus struct {
+	mux     sync.Mutex
+	brokers map[string]any
+}
+
+func New() *Bus {
+	return &Bus{brokers: map[string]any{}}
+}
+
+// getBroker returns the broker of the topic, starting it on first use. Topics are identified by
+// name, so using the same name for topics of different types is a programming error.
+func getBroker[T any](bus *Bus, topic Topic[T]) *medley.Broker[T] {
+	bus.mux.Lock()
+	defer bus.mux.Unlock()
+	b, ok := bus.brokers[topic.Name]
+	if !ok {
+		b = medley.StartNewBroker[T](false)
+		bus.brokers[topic.Name] = b
+	}
+	typed, ok := b.(*medley.Broker[T])
+	if !ok {
+		panic(fmt.Sprintf("topic %q used with different event types", topic.Name))
+	}
+	return typed
+}
+
+// Publish sends the event to the subscribers of the topic. It blocks until the event has been
+// handed to the broker of the topic or the context is canceled.
+func Publish[T any](ctx context.Context, bus *Bus, topic Topic[T], event T) error {
+	if bus == nil {
+		return nil
+	}
+	select {
+	case getBroker(bus, topic).Publish <- event:
+		metricsEventsPublished.WithLabelValues(topic.Name).Inc()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Subscribe returns a channel receiving the events published to the topic from now on.
+func Subscribe[T any](bus *Bus, topic Topic[T], chanSize int) chan T {
+	return getBroker(bus, topic).Subscribe(chanSize)
+}
+
+// Unsubscribe stops the delivery of events to a channel returned by Subscribe and closes it.
+func Unsubscribe[T any](bus *Bus, topic Topic[T], ch chan T) {
+	getBroker(bus, topic).Unsubscribe(ch)
+}
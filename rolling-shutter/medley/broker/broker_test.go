@@ -0,0 +1,47 @@
+package broker
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	ctx := context.Background()
+	bus := New()
+	numbers := NewTopic[int]("numbers")
+	words := NewTopic[string]("words")
+
+	sub1 := Subscribe(bus, numbers, 2)
+	sub2 := Subscribe(bus, numbers, 2)
+	wordSub := Subscribe(bus, words, 1)
+
+	assert.NilError(t, Publish(ctx, bus, numbers, 1))
+	assert.NilError(t, Publish(ctx, bus, numbers, 2))
+	assert.NilError(t, Publish(ctx, bus, words, "hello"))
+	for _, sub := range []chan int{sub1, sub2} {
+		assert.Equal(t, <-sub, 1)
+		assert.Equal(t, <-sub, 2)
+	}
+	assert.Equal(t, <-wordSub, "hello")
+
+	Unsubscribe(bus, numbers, sub1)
+	_, ok := <-sub1
+	assert.Check(t, !ok)
+	assert.NilError(t, Publish(ctx, bus, numbers, 3))
+	assert.Equal(t, <-sub2, 3)
+}
+
+func TestPublishToNilBus(t *testing.T) {
+	assert.NilError(t, Publish(context.Background(), nil, NewTopic[int]("numbers"), 1))
+}
+
+func TestTopicTypeMismatch(t *testing.T) {
+	bus := New()
+	Subscribe(bus, NewTopic[int]("topic"), 1)
+	defer func() {
+		assert.Check(t, recover() != nil)
+	}()
+	Subscribe(bus, NewTopic[string]("topic"), 1)
+}
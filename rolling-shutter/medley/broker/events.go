@@ -0,0 +1,40 @@
+package broker
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+// The topics of the domain events shared between the subsystems of a node.
+var (
+	EonKeyGeneratedTopic        = NewTopic[EonKeyGenerated]("eon-key-generated")
+	DecryptionKeyAvailableTopic = NewTopic[DecryptionKeyAvailable]("decryption-key-available")
+	KeyperSetActivatedTopic     = NewTopic[KeyperSetActivated]("keyper-set-activated")
+)
+
+// EonKeyGenerated is published when the DKG of this node has produced an eon public key.
+type EonKeyGenerated struct {
+	Eon                   uint64
+	KeyperConfigIndex     uint64
+	ActivationBlockNumber uint64
+	PublicKey             []byte
+}
+
+// DecryptionKeyAvailable is published once for every epoch whose decryption key becomes known,
+// either by receiving it or by aggregating it from the keypers' shares. Source tells which of the
+// two happened first.
+type DecryptionKeyAvailable struct {
+	Eon     uint64
+	EpochID epochid.EpochID
+	Key     []byte
+	Source  string
+}
+
+// KeyperSetActivated is published when the activation block of a keyper set has been reached.
+type KeyperSetActivated struct {
+	KeyperConfigIndex     uint64
+	ActivationBlockNumber uint64
+	Keypers               []common.Address
+	Threshold             uint64
+}
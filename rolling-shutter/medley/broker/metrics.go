@@ -0,0 +1,17 @@
+package broker
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsEventsPublished = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "broker",
+		Name:      "events_published_total",
+		Help:      "Number of events published to the in-process event bus",
+	},
+	[]string{"topic"},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsEventsPublished)
+}
@@ -0,0 +1,190 @@
+// Package clockcheck compares the local clock with NTP servers. Keypers act on wall clock time when
+// they hold back decryption key shares until a publication delay has passed or evaluate the time
+// windows of a trigger policy, so a bad host clock makes them miss or jump deadlines. The Monitor
+// measures the offset of the local clock at startup and periodically, compensates time based
+// decisions for it and, if the offset exceeds a threshold, tells them to refuse to operate.
+package clockcheck
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/alert"
+)
+
+// Level classifies the measured offset of the local clock.
+type Level int32
+
+const (
+	LevelOK Level = iota
+	LevelWarning
+	LevelCritical
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelOK:
+		return "ok"
+	case LevelWarning:
+		return "warning"
+	case LevelCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// Evaluate returns the level of the given offset.
+func Evaluate(config *Config, offset time.Duration) Level {
+	if offset < 0 {
+		offset = -offset
+	}
+	switch {
+	case config.MaxSkew.Duration != 0 && offset > config.MaxSkew.Duration:
+		return LevelCritical
+	case config.WarnSkew.Duration != 0 && offset > config.WarnSkew.Duration:
+		return LevelWarning
+	default:
+		return LevelOK
+	}
+}
+
+// medianOffset returns the median of the offsets, which is robust against a single server with a
+// wrong clock.
+func medianOffset(offsets []time.Duration) time.Duration {
+	sorted := append([]time.Duration{}, offsets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// Monitor measures the offset of the local clock every interval. Until the first successful
+// measurement, the offset is taken to be zero. A nil Monitor uses the local clock as is and never
+// refuses time based operation.
+type Monitor struct {
+	config   *Config
+	notifier alert.Notifier
+	offset   atomic.Int64
+	level    atomic.Int32
+}
+
+func New(config *Config, notifier alert.Notifier) *Monitor {
+	return &Monitor{config: config, notifier: notifier}
+}
+
+// Offset returns the most recently measured offset of the local clock.
+func (m *Monitor) Offset() time.Duration {
+	if m == nil {
+		return 0
+	}
+	return time.Duration(m.offset.Load())
+}
+
+// Now returns the current time, compensated for the offset of the local clock.
+func (m *Monitor) Now() time.Time {
+	return time.Now().Add(m.Offset())
+}
+
+// Local converts a compensated time, e.g. a deadline, to the time the local clock shows at that
+// moment.
+func (m *Monitor) Local(t time.Time) time.Time {
+	return t.Add(-m.Offset())
+}
+
+// Level returns the level of the most recent measurement.
+func (m *Monitor) Level() Level {
+	if m == nil {
+		return LevelOK
+	}
+	return Level(m.level.Load())
+}
+
+// RefuseTimeBased reports whether time based operation should be refused. Even large offsets are
+// compensated, but the host clock of a node whose offset is critical can't be trusted to stay
+// within the measured offset until the next check.
+func (m *Monitor) RefuseTimeBased() bool {
+	return m.Level() == LevelCritical
+}
+
+// Measure queries all servers and returns the median of the offsets they report. It fails only if
+// no server responds.
+func (m *Monitor) Measure(ctx context.Context) (time.Duration, error) {
+	offsets := []time.Duration{}
+	var lastErr error
+	for _, server := range m.config.Servers {
+		queryCtx, cancel := context.WithTimeout(ctx, m.config.Timeout.Duration)
+		offset, err := Query(queryCtx, server)
+		cancel()
+		if err != nil {
+			log.Debug().Err(err).Str("server", server).Msg("failed to query NTP server")
+			lastErr = err
+			continue
+		}
+		offsets = append(offsets, offset)
+	}
+	if len(offsets) == 0 {
+		return 0, errors.Wrap(lastErr, "no NTP server responded")
+	}
+	return medianOffset(offsets), nil
+}
+
+// Check measures the offset of the local clock and alerts if its level changed.
+func (m *Monitor) Check(ctx context.Context) error {
+	offset, err := m.Measure(ctx)
+	if err != nil {
+		metricsCheckFailures.Inc()
+		return err
+	}
+	m.offset.Store(int64(offset))
+	metricsOffset.Set(offset.Seconds())
+	level := Evaluate(m.config, offset)
+	previous := Level(m.level.Swap(int32(level)))
+	metricsLevel.Set(float64(level))
+	log.Debug().Dur("offset", offset).Str("level", level.String()).Msg("checked local clock")
+	if level == previous {
+		return nil
+	}
+	return m.notify(ctx, offset, level)
+}
+
+// Run checks the clock every interval until the context is canceled. The first check is expected
+// to be done with Check at startup, before anything acts on the time.
+func (m *Monitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.config.Interval.Duration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		if err := m.Check(ctx); err != nil {
+			log.Warn().Err(err).Msg("failed to check local clock")
+		}
+	}
+}
+
+func (m *Monitor) notify(ctx context.Context, offset time.Duration, level Level) error {
+	a := alert.Alert{Details: map[string]string{"offset": offset.String()}}
+	switch level {
+	case LevelCritical:
+		a.Severity = alert.SeverityCritical
+		a.Summary = "local clock is off by more than the maximum skew, time based operation is refused"
+	case LevelWarning:
+		a.Severity = alert.SeverityWarning
+		a.Summary = "local clock is off"
+	default:
+		a.Severity = alert.SeverityInfo
+		a.Summary = "local clock is back in sync"
+	}
+	log.Info().Dur("offset", offset).Str("level", level.String()).Msg(a.Summary)
+	return m.notifier.Notify(ctx, a)
+}
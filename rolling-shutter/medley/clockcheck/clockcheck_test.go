@@ -0,0 +1,84 @@
+package clockcheck
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+// serveNTP answers NTP requests on a local UDP port with a clock that is ahead of the local one by
+// offset.
+func serveNTP(t *testing.T, offset time.Duration, stratum byte) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		request := make([]byte, ntpPacketSize)
+		for {
+			_, addr, err := conn.ReadFrom(request)
+			if err != nil {
+				return
+			}
+			now := toNTPTime(time.Now().Add(offset))
+			response := make([]byte, ntpPacketSize)
+			response[0] = 0x24 // version 4, mode 4 (server)
+			response[1] = stratum
+			copy(response[24:32], request[40:48])
+			binary.BigEndian.PutUint64(response[32:], now)
+			binary.BigEndian.PutUint64(response[40:], now)
+			_, _ = conn.WriteTo(response, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestNTPTime(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 123456789, time.UTC)
+	converted := fromNTPTime(toNTPTime(now))
+	assert.Assert(t, converted.Sub(now).Abs() < time.Microsecond)
+}
+
+func TestQuery(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	offset, err := Query(ctx, serveNTP(t, 10*time.Second, 2))
+	assert.NilError(t, err)
+	assert.Assert(t, (offset-10*time.Second).Abs() < 100*time.Millisecond, offset)
+
+	_, err = Query(ctx, serveNTP(t, 0, 0))
+	assert.ErrorContains(t, err, "not synchronized")
+}
+
+func TestEvaluate(t *testing.T) {
+	config := NewConfig()
+	config.WarnSkew = &enctime.Duration{Duration: time.Second}
+	config.MaxSkew = &enctime.Duration{Duration: 10 * time.Second}
+
+	assert.Equal(t, Evaluate(config, 500*time.Millisecond), LevelOK)
+	assert.Equal(t, Evaluate(config, -2*time.Second), LevelWarning)
+	assert.Equal(t, Evaluate(config, 11*time.Second), LevelCritical)
+	config.MaxSkew.Duration = 0
+	assert.Equal(t, Evaluate(config, time.Hour), LevelWarning)
+}
+
+func TestMedianOffset(t *testing.T) {
+	assert.Equal(t, medianOffset([]time.Duration{3, 1, 100}), time.Duration(3))
+	assert.Equal(t, medianOffset([]time.Duration{4, 2}), time.Duration(3))
+	assert.Equal(t, medianOffset([]time.Duration{-5}), time.Duration(-5))
+}
+
+func TestNilMonitor(t *testing.T) {
+	var m *Monitor
+	assert.Equal(t, m.Offset(), time.Duration(0))
+	assert.Assert(t, !m.RefuseTimeBased())
+	deadline := time.Now().Add(time.Hour)
+	assert.Assert(t, m.Local(deadline).Equal(deadline))
+}
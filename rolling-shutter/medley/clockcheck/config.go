@@ -0,0 +1,85 @@
+package clockcheck
+
+import (
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+type Config struct {
+	Interval *enctime.Duration `comment:"How often the local clock is compared with the NTP servers, 0 disables the check"`
+	Servers  []string          `comment:"NTP servers the local clock is compared with, as host or host:port"`
+	Timeout  *enctime.Duration `comment:"How long to wait for the response of an NTP server"`
+
+	WarnSkew *enctime.Duration `comment:"Alert if the local clock is off by more than this, 0 disables the threshold"`
+	MaxSkew  *enctime.Duration `comment:"Refuse time based operation, like publication delays and trigger time windows, if the local clock is off by more than this, 0 disables the threshold"`
+}
+
+func (c *Config) Init() {
+	c.Interval = &enctime.Duration{}
+	c.Timeout = &enctime.Duration{}
+	c.WarnSkew = &enctime.Duration{}
+	c.MaxSkew = &enctime.Duration{}
+}
+
+func (c *Config) Name() string {
+	return "clock"
+}
+
+// Enabled reports whether the clock is checked at all.
+func (c *Config) Enabled() bool {
+	return c.Interval.Duration > 0
+}
+
+func (c *Config) Validate() error {
+	if c.Interval.Duration < 0 || c.Timeout.Duration < 0 || c.WarnSkew.Duration < 0 || c.MaxSkew.Duration < 0 {
+		return errors.New("durations of the clock check must not be negative")
+	}
+	if !c.Enabled() {
+		return nil
+	}
+	if len(c.Servers) == 0 {
+		return errors.New("at least one NTP server is required if the clock check is enabled")
+	}
+	if c.Timeout.Duration == 0 {
+		return errors.New("Timeout must be positive if the clock check is enabled")
+	}
+	if c.MaxSkew.Duration != 0 && c.WarnSkew.Duration > c.MaxSkew.Duration {
+		return errors.New("WarnSkew must not be larger than MaxSkew")
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.Interval = &enctime.Duration{Duration: 15 * time.Minute}
+	c.Servers = []string{"pool.ntp.org"}
+	c.Timeout = &enctime.Duration{Duration: 5 * time.Second}
+	c.WarnSkew = &enctime.Duration{Duration: time.Second}
+	c.MaxSkew = &enctime.Duration{Duration: 30 * time.Second}
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	err := c.SetDefaultValues()
+	if err != nil {
+		return err
+	}
+	c.Servers = []string{"0.pool.ntp.org", "1.pool.ntp.org", "time.cloudflare.com"}
+	return nil
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
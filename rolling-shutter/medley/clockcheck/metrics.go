@@ -0,0 +1,36 @@
+package clockcheck
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsOffset = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "clock",
+		Name:      "offset_seconds",
+		Help:      "Measured offset of the local clock, i.e. how much the NTP servers are ahead of it",
+	},
+)
+
+var metricsLevel = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "clock",
+		Name:      "level",
+		Help:      "Clock skew level of the node: 0 ok, 1 warning, 2 critical with time based operation refused",
+	},
+)
+
+var metricsCheckFailures = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "clock",
+		Name:      "check_failures_total",
+		Help:      "Number of clock checks that failed because no NTP server could be queried",
+	},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsOffset)
+	prometheus.MustRegister(metricsLevel)
+	prometheus.MustRegister(metricsCheckFailures)
+}
@@ -0,0 +1,84 @@
+package clockcheck
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ntpPacketSize = 48
+	// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and the Unix epoch.
+	ntpEpochOffset = 2208988800
+	// ntpClientRequest sets leap indicator 0, version 4 and mode 3 (client).
+	ntpClientRequest = 0x23
+	ntpModeServer    = 4
+	ntpMaxStratum    = 15
+)
+
+func toNTPTime(t time.Time) uint64 {
+	seconds := uint64(t.Unix() + ntpEpochOffset)
+	fraction := (uint64(t.Nanosecond()) << 32) / uint64(time.Second)
+	return seconds<<32 | fraction
+}
+
+func fromNTPTime(ts uint64) time.Time {
+	seconds := int64(ts>>32) - ntpEpochOffset
+	nanos := int64(((ts & 0xffffffff) * uint64(time.Second)) >> 32)
+	return time.Unix(seconds, nanos)
+}
+
+// Query asks the NTP server at address, given as host or host:port, for the time and returns the
+// offset of the local clock, i.e. how much has to be added to the local time to get the server's.
+// Only the simple SNTP subset of the protocol is used.
+func Query(ctx context.Context, address string) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "123")
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to connect to NTP server %s", address)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return 0, err
+		}
+	}
+
+	request := make([]byte, ntpPacketSize)
+	request[0] = ntpClientRequest
+	sent := time.Now()
+	origin := toNTPTime(sent)
+	// The server echoes the transmit timestamp of the request as origin timestamp, which is used
+	// to match the response to the request.
+	binary.BigEndian.PutUint64(request[40:], origin)
+	if _, err := conn.Write(request); err != nil {
+		return 0, errors.Wrapf(err, "failed to send request to NTP server %s", address)
+	}
+	response := make([]byte, ntpPacketSize)
+	n, err := conn.Read(response)
+	received := time.Now()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to read response of NTP server %s", address)
+	}
+	if n < ntpPacketSize {
+		return 0, errors.Errorf("short response of %d bytes from NTP server %s", n, address)
+	}
+	if mode := response[0] & 0x7; mode != ntpModeServer {
+		return 0, errors.Errorf("unexpected mode %d in response of NTP server %s", mode, address)
+	}
+	if stratum := response[1]; stratum == 0 || stratum > ntpMaxStratum {
+		return 0, errors.Errorf("NTP server %s is not synchronized (stratum %d)", address, stratum)
+	}
+	if binary.BigEndian.Uint64(response[24:]) != origin {
+		return 0, errors.Errorf("response of NTP server %s doesn't match the request", address)
+	}
+	serverReceived := fromNTPTime(binary.BigEndian.Uint64(response[32:]))
+	serverSent := fromNTPTime(binary.BigEndian.Uint64(response[40:]))
+	return (serverReceived.Sub(sent) + serverSent.Sub(received)) / 2, nil
+}
@@ -1,14 +1,18 @@
 package command
 
 import (
+	"encoding/hex"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
@@ -48,6 +52,15 @@ func newCommandBuilder(name string) *commandBuilderConfig {
 	}
 }
 
+func newConfig[T configuration.Config]() T {
+	var zero T
+	nw, ok := reflect.New(reflect.TypeOf(zero).Elem()).Interface().(T)
+	if !ok {
+		panic("type error during instantiation of new config")
+	}
+	return nw
+}
+
 func newConfigForFunc[T configuration.Config](fn ConfigurableFunc[T]) T {
 	typ := reflect.TypeOf(fn).In(0).Elem()
 	nw, ok := reflect.New(typ).Interface().(T)
@@ -157,6 +170,20 @@ func Build[T configuration.Config](
 	return cb
 }
 
+// parseConfig returns a new config filled from the config file, the environment and the flags of
+// the subcommand cmd.
+func (cb *CommandBuilder[T]) parseConfig(cmd *cobra.Command) (T, error) {
+	cfg := newConfig[T]()
+	cfg.Init()
+	v := viper.GetViper()
+	v.SetFs(cb.builderConfig.filesystem)
+	err := ParseCLI(v, cmd, cfg)
+	if err != nil {
+		return cfg, errors.WithMessage(err, "Please check your configuration")
+	}
+	return cfg, nil
+}
+
 // AddInitDBCommand attaches an additional subcommand
 // 'initdb' to the command initially built by the Build method.
 // The initDB function argument is structured in the same way than the "main"
@@ -167,13 +194,9 @@ func (cb *CommandBuilder[T]) AddInitDBCommand(initDB ConfigurableFunc[T]) {
 		Short: fmt.Sprintf("Initialize the database of the '%s'", cb.builderConfig.name),
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg := newConfigForFunc(initDB)
-			cfg.Init()
-			v := viper.GetViper()
-			v.SetFs(cb.builderConfig.filesystem)
-			err := ParseCLI(v, cmd, cfg)
+			cfg, err := cb.parseConfig(cmd)
 			if err != nil {
-				return errors.WithMessage(err, "Please check your configuration")
+				return err
 			}
 			log.Debug().
 				Interface("config", cfg).
@@ -183,6 +206,474 @@ func (cb *CommandBuilder[T]) AddInitDBCommand(initDB ConfigurableFunc[T]) {
 	})
 }
 
+// MigrateFunc runs a step of an online database migration.
+type MigrateFunc[T configuration.Config] func(cfg T, migration, step string, batchSize int64) error
+
+// AddMigrateCommand attaches an additional subcommand 'migrate' to the command initially built by
+// the Build method. It runs a step (expand, backfill, switch, contract or status) of the online
+// database migration with the given name.
+func (cb *CommandBuilder[T]) AddMigrateCommand(migrate MigrateFunc[T]) {
+	cmd := &cobra.Command{
+		Use:   "migrate <migration> <expand|backfill|switch|contract|status>",
+		Short: fmt.Sprintf("Run a step of an online migration of the database of the '%s'", cb.builderConfig.name),
+		Long: `This command migrates the database while the nodes using it keep running. Run
+the steps of a migration in order. Nodes check whether to write to both the old
+and the new tables when they start, so restart them after the expand step to
+keep the switch step short.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cb.parseConfig(cmd)
+			if err != nil {
+				return err
+			}
+			batchSize, err := cmd.Flags().GetInt64("batch-size")
+			if err != nil {
+				return err
+			}
+			return migrate(cfg, args[0], args[1], batchSize)
+		},
+	}
+	cmd.PersistentFlags().Int64("batch-size", 1000, "number of rows copied per transaction when backfilling")
+	cb.cobraCommand.AddCommand(cmd)
+}
+
+// ReplicationFunc runs an action (setup, status or drop) on the logical replication publication
+// of the database.
+type ReplicationFunc[T configuration.Config] func(cfg T, action string) error
+
+// AddReplicationCommand attaches an additional subcommand 'replication' to the command initially
+// built by the Build method. It manages the publication downstream systems subscribe to via
+// logical replication.
+func (cb *CommandBuilder[T]) AddReplicationCommand(replication ReplicationFunc[T]) {
+	cb.cobraCommand.AddCommand(&cobra.Command{
+		Use:   "replication <setup|status|drop>",
+		Short: fmt.Sprintf("Manage the logical replication publication of the database of the '%s'", cb.builderConfig.name),
+		Long: `This command manages the Postgres publication of the tables downstream systems
+can consume via logical replication. setup sets the replica identities of the
+tables and creates the publication or updates its tables, status prints as JSON
+how the publication is set up and drop removes it. Subscribers only receive
+changes if the server runs with wal_level = logical.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cb.parseConfig(cmd)
+			if err != nil {
+				return err
+			}
+			return replication(cfg, args[0])
+		},
+	})
+}
+
+// AuditLogQuery selects entries of the audit log. Empty string and slice fields match all entries.
+type AuditLogQuery struct {
+	Since       time.Time
+	Until       time.Time
+	Source      string
+	Actor       string
+	TriggerHash []byte
+	Limit       int32
+}
+
+// AuditLogFunc prints the entries of the audit log selected by the query.
+type AuditLogFunc[T configuration.Config] func(cfg T, query AuditLogQuery) error
+
+// parseTimeFlag parses either an RFC 3339 timestamp or a duration that is subtracted from now.
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid time %q, expected RFC 3339 timestamp or duration", value)
+	}
+	return now.Add(-d), nil
+}
+
+// addTimeRangeFlags adds the since and until flags selecting the printed items by time.
+func addTimeRangeFlags(cmd *cobra.Command, items string) {
+	cmd.PersistentFlags().String("since", "24h",
+		fmt.Sprintf("print %s since this RFC 3339 time or duration ago", items))
+	cmd.PersistentFlags().String("until", "",
+		fmt.Sprintf("print %s until this RFC 3339 time or duration ago (default now)", items))
+}
+
+// parseTimeRangeFlags parses the flags added by addTimeRangeFlags. until defaults to now.
+func parseTimeRangeFlags(flags *pflag.FlagSet) (since, until time.Time, err error) {
+	now := time.Now()
+	sinceValue, _ := flags.GetString("since")
+	since, err = parseTimeFlag(sinceValue, now)
+	if err != nil {
+		return since, until, err
+	}
+	until = now
+	if untilValue, _ := flags.GetString("until"); untilValue != "" {
+		until, err = parseTimeFlag(untilValue, now)
+	}
+	return since, until, err
+}
+
+// AddAuditLogCommand attaches an additional subcommand 'audit-log' to the command initially built
+// by the Build method. It prints the entries of the audit log that match the given filters.
+func (cb *CommandBuilder[T]) AddAuditLogCommand(auditLog AuditLogFunc[T]) {
+	cmd := &cobra.Command{
+		Use:   "audit-log",
+		Short: fmt.Sprintf("Print the audit log of the '%s'", cb.builderConfig.name),
+		Long: `This command prints the recorded changes to the node's state as JSON, one entry
+per line, in the order they were made. Every entry names the source (chain,
+shuttermint or p2p), the actor that caused the change, the action and the hash
+of the event or message that triggered it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cb.parseConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			flags := cmd.Flags()
+			var query AuditLogQuery
+			query.Since, query.Until, err = parseTimeRangeFlags(flags)
+			if err != nil {
+				return err
+			}
+			query.Source, _ = flags.GetString("source")
+			query.Actor, _ = flags.GetString("actor")
+			triggerHash, _ := flags.GetString("trigger-hash")
+			query.TriggerHash, err = hex.DecodeString(strings.TrimPrefix(triggerHash, "0x"))
+			if err != nil {
+				return errors.Wrap(err, "invalid trigger hash")
+			}
+			query.Limit, _ = flags.GetInt32("limit")
+			return auditLog(cfg, query)
+		},
+	}
+	addTimeRangeFlags(cmd, "entries")
+	cmd.PersistentFlags().String("source", "", "only print entries from this source (chain, shuttermint or p2p)")
+	cmd.PersistentFlags().String("actor", "", "only print entries caused by this actor")
+	cmd.PersistentFlags().String("trigger-hash", "", "only print entries triggered by the event or message with this hash")
+	cmd.PersistentFlags().Int32("limit", 1000, "maximum number of entries to print")
+	cb.cobraCommand.AddCommand(cmd)
+}
+
+// ProvenanceQuery selects provenance records of p2p messages. Empty string and slice fields match
+// all records.
+type ProvenanceQuery struct {
+	Since       time.Time
+	Until       time.Time
+	Peer        string
+	MessageType string
+	EpochID     []byte
+	MessageHash []byte
+	Limit       int32
+}
+
+// ProvenanceFunc prints the provenance records selected by the query.
+type ProvenanceFunc[T configuration.Config] func(cfg T, query ProvenanceQuery) error
+
+// decodeHexFlag decodes a hex flag value with an optional 0x prefix.
+func decodeHexFlag(value, name string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", name)
+	}
+	return b, nil
+}
+
+// AddProvenanceCommand attaches an additional subcommand 'provenance' to the command initially
+// built by the Build method. It prints where the p2p messages accepted by the node came from.
+func (cb *CommandBuilder[T]) AddProvenanceCommand(provenance ProvenanceFunc[T]) {
+	cmd := &cobra.Command{
+		Use:   "provenance",
+		Short: fmt.Sprintf("Print where the p2p messages accepted by the '%s' came from", cb.builderConfig.name),
+		Long: `This command prints the provenance records of the p2p messages the node accepted
+as JSON, one record per line, in the order they were received. Every record
+names the peer that published the message, the peer it was received from, the
+time it was received, the epochs it concerns and its hash, which is the same as
+the trigger hash in the audit log. Use it to trace bad data found in the
+database back to the peer that delivered it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cb.parseConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			flags := cmd.Flags()
+			var query ProvenanceQuery
+			query.Since, query.Until, err = parseTimeRangeFlags(flags)
+			if err != nil {
+				return err
+			}
+			query.Peer, _ = flags.GetString("peer")
+			query.MessageType, _ = flags.GetString("type")
+			epochID, _ := flags.GetString("epoch-id")
+			query.EpochID, err = decodeHexFlag(epochID, "epoch id")
+			if err != nil {
+				return err
+			}
+			messageHash, _ := flags.GetString("message-hash")
+			query.MessageHash, err = decodeHexFlag(messageHash, "message hash")
+			if err != nil {
+				return err
+			}
+			query.Limit, _ = flags.GetInt32("limit")
+			return provenance(cfg, query)
+		},
+	}
+	addTimeRangeFlags(cmd, "records")
+	cmd.PersistentFlags().String("peer", "", "only print messages published by or received from this peer ID")
+	cmd.PersistentFlags().String("type", "", "only print messages of this type, e.g. p2pmsg.DecryptionKeyShares")
+	cmd.PersistentFlags().String("epoch-id", "", "only print messages concerning the epoch with this hex encoded id")
+	cmd.PersistentFlags().String("message-hash", "", "only print the message with this hash")
+	cmd.PersistentFlags().Int32("limit", 1000, "maximum number of records to print")
+	cb.cobraCommand.AddCommand(cmd)
+}
+
+// MetricsSnapshotsFunc prints the metrics snapshots taken since the given time.
+type MetricsSnapshotsFunc[T configuration.Config] func(cfg T, since time.Time) error
+
+// AddMetricsSnapshotsCommand attaches an additional subcommand 'metrics-snapshots' to the command
+// initially built by the Build method. It prints the snapshots of key metrics persisted by the node.
+func (cb *CommandBuilder[T]) AddMetricsSnapshotsCommand(metricsSnapshots MetricsSnapshotsFunc[T]) {
+	cmd := &cobra.Command{
+		Use:   "metrics-snapshots",
+		Short: fmt.Sprintf("Print the metrics snapshots persisted by the '%s'", cb.builderConfig.name),
+		Long: `This command prints the snapshots of key metrics the node persisted to its
+database as JSON, one snapshot per line, in the order they were taken. They
+allow analyzing incidents even if no monitoring system was scraping the node.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cb.parseConfig(cmd)
+			if err != nil {
+				return err
+			}
+			since, _ := cmd.Flags().GetString("since")
+			sinceTime, err := parseTimeFlag(since, time.Now())
+			if err != nil {
+				return err
+			}
+			return metricsSnapshots(cfg, sinceTime)
+		},
+	}
+	cmd.PersistentFlags().String("since", "24h", "print snapshots since this RFC 3339 time or duration ago")
+	cb.cobraCommand.AddCommand(cmd)
+}
+
+// DeadJobsFunc prints the jobs that have been given up on, after making the ones with the given
+// ids due again.
+type DeadJobsFunc[T configuration.Config] func(cfg T, retry []int64) error
+
+// AddDeadJobsCommand attaches an additional subcommand 'dead-jobs' to the command initially
+// built by the Build method. It lists and retries the jobs the node has given up on.
+func (cb *CommandBuilder[T]) AddDeadJobsCommand(deadJobs DeadJobsFunc[T]) {
+	cmd := &cobra.Command{
+		Use:   "dead-jobs",
+		Short: fmt.Sprintf("Print and retry the jobs the '%s' has given up on", cb.builderConfig.name),
+		Long: `This command prints the jobs the node has given up on as JSON, one job per line.
+Jobs are side effects like webhook notifications which are retried until they
+succeed, unless they fail permanently or too often. Pass the ids of jobs to
+--retry to let the running node attempt them again.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cb.parseConfig(cmd)
+			if err != nil {
+				return err
+			}
+			retry, _ := cmd.Flags().GetInt64Slice("retry")
+			return deadJobs(cfg, retry)
+		},
+	}
+	cmd.PersistentFlags().Int64Slice("retry", nil, "ids of the dead jobs to retry")
+	cb.cobraCommand.AddCommand(cmd)
+}
+
+// DeadEventsFunc prints the contract events that have been given up on.
+type DeadEventsFunc[T configuration.Config] func(cfg T) error
+
+// RetryEventsFunc marks the dead contract events with the given ids for retry.
+type RetryEventsFunc[T configuration.Config] func(cfg T, ids []int64) error
+
+// AddEventsCommand attaches an additional subcommand 'events' to the command initially built by
+// the Build method. Its subcommands list and retry the contract events the node has given up on.
+func (cb *CommandBuilder[T]) AddEventsCommand(deadEvents DeadEventsFunc[T], retryEvents RetryEventsFunc[T]) {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: fmt.Sprintf("Inspect the contract events the '%s' has given up on", cb.builderConfig.name),
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "dead",
+		Short: "Print the contract events that have been given up on",
+		Long: `This command prints the contract events the node has given up on as JSON, one
+event per line. Events are given up on if handling them failed too often, so
+that syncing the events of their type can continue.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cb.parseConfig(cmd)
+			if err != nil {
+				return err
+			}
+			return deadEvents(cfg)
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "retry <id>...",
+		Short: "Let the running node handle dead contract events again",
+		Long: `This command marks the dead contract events with the given ids for retry, e.g.
+after the cause of the failure has been fixed. The running node handles them
+again shortly after. Events that fail again stay dead.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids := make([]int64, len(args))
+			for i, arg := range args {
+				id, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					return errors.Errorf("invalid dead event id %q", arg)
+				}
+				ids[i] = id
+			}
+			cfg, err := cb.parseConfig(cmd)
+			if err != nil {
+				return err
+			}
+			return retryEvents(cfg, ids)
+		},
+	})
+	cb.cobraCommand.AddCommand(cmd)
+}
+
+// AddQuorumStatusCommand attaches an additional subcommand 'quorum-status' to the command
+// initially built by the Build method. It prints how close the keyper set is to losing its quorum.
+func (cb *CommandBuilder[T]) AddQuorumStatusCommand(quorumStatus ConfigurableFunc[T]) {
+	cb.cobraCommand.AddCommand(&cobra.Command{
+		Use:   "quorum-status",
+		Short: fmt.Sprintf("Print the quorum health of the keyper set observed by the '%s'", cb.builderConfig.name),
+		Long: `This command prints as JSON which keypers of the current eon's keyper set
+contributed decryption key shares to the recent epochs, how many of them are
+active and by how much they exceed the threshold.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cb.parseConfig(cmd)
+			if err != nil {
+				return err
+			}
+			return quorumStatus(cfg)
+		},
+	})
+}
+
+// RedecryptFunc reprocesses the epochs from fromEpoch to toEpoch (inclusive).
+type RedecryptFunc[T configuration.Config] func(cfg T, fromEpoch, toEpoch uint64) error
+
+// AddRedecryptCommand attaches an additional subcommand 'redecrypt' to the command initially
+// built by the Build method. It reprocesses a range of epochs from the data in the database.
+func (cb *CommandBuilder[T]) AddRedecryptCommand(redecrypt RedecryptFunc[T]) {
+	cmd := &cobra.Command{
+		Use:   "redecrypt",
+		Short: fmt.Sprintf("Reprocess stored epochs of the '%s' and compare the results", cb.builderConfig.name),
+		Long: `This command loads the decryption keys and transactions stored for a range of
+epochs, decrypts and signs them again and compares the results to the stored
+outputs. The result for each epoch is printed as JSON, one epoch per line. The
+command fails if any epoch could not be reproduced exactly, e.g. after fixing a
+decryption bug or to verify the integrity of historical data.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cb.parseConfig(cmd)
+			if err != nil {
+				return err
+			}
+			fromEpoch, _ := cmd.Flags().GetUint64("from-epoch")
+			toEpoch, _ := cmd.Flags().GetUint64("to-epoch")
+			if fromEpoch > toEpoch {
+				return errors.Errorf("from-epoch %d is after to-epoch %d", fromEpoch, toEpoch)
+			}
+			return redecrypt(cfg, fromEpoch, toEpoch)
+		},
+	}
+	cmd.PersistentFlags().Uint64("from-epoch", 0, "first epoch to reprocess")
+	cmd.PersistentFlags().Uint64("to-epoch", 0, "last epoch to reprocess")
+	_ = cmd.MarkPersistentFlagRequired("from-epoch")
+	_ = cmd.MarkPersistentFlagRequired("to-epoch")
+	cb.cobraCommand.AddCommand(cmd)
+}
+
+// RepairFromBackupFunc replaces the stored key material with the DKG results read from the given
+// files.
+type RepairFromBackupFunc[T configuration.Config] func(cfg T, resultFiles []string) error
+
+// AddRepairFromBackupCommand attaches an additional subcommand 'repair-from-backup' to the command
+// initially built by the Build method. It restores corrupted key shares from recovered backups.
+func (cb *CommandBuilder[T]) AddRepairFromBackupCommand(repair RepairFromBackupFunc[T]) {
+	cmd := &cobra.Command{
+		Use:   "repair-from-backup",
+		Short: fmt.Sprintf("Restore the corrupted key shares of the '%s' from backups", cb.builderConfig.name),
+		Long: `The node verifies its eon key shares at startup and refuses to start if any of
+them is corrupted. This command replaces the key material of the corrupted eons
+with the DKG results recovered from their backups with 'escrow recover'. Each
+result is verified before it is stored. Stop the node before running it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cb.parseConfig(cmd)
+			if err != nil {
+				return err
+			}
+			resultFiles, _ := cmd.Flags().GetStringArray("result")
+			return repair(cfg, resultFiles)
+		},
+	}
+	cmd.PersistentFlags().StringArray("result", nil,
+		"file containing a DKG result written by 'escrow recover' (can be given multiple times)")
+	_ = cmd.MarkPersistentFlagRequired("result")
+	cb.cobraCommand.AddCommand(cmd)
+}
+
+// RestoreCheckpointFunc initializes the database from the checkpoints read from the given sources
+// if enough of the trusted signers agree on them, together with the DKG results read from the given
+// files.
+type RestoreCheckpointFunc[T configuration.Config] func(
+	cfg T, sources, signers []string, minSigners int, resultFiles []string,
+) error
+
+// AddRestoreCheckpointCommand attaches an additional subcommand 'restore-checkpoint' to the command
+// initially built by the Build method. It lets a new node skip syncing the history of the chains.
+func (cb *CommandBuilder[T]) AddRestoreCheckpointCommand(restore RestoreCheckpointFunc[T]) {
+	cmd := &cobra.Command{
+		Use:   "restore-checkpoint",
+		Short: fmt.Sprintf("Initialize the database of the '%s' from signed checkpoints", cb.builderConfig.name),
+		Long: `This command downloads the signed checkpoints of several trusted keypers,
+verifies them and stores the state they agree on in a newly initialized
+database. The node then resumes syncing the chains from the earliest cursor of
+the agreeing checkpoints instead of from the start. Checkpoints don't contain
+key material, so the eon key shares of a recovering keyper must be given as
+DKG results recovered from their backups with 'escrow recover'. Each of them
+is checked against the eon public key in the checkpoint. Run 'initdb' before.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cb.parseConfig(cmd)
+			if err != nil {
+				return err
+			}
+			sources, _ := cmd.Flags().GetStringArray("from")
+			signers, _ := cmd.Flags().GetStringArray("signer")
+			minSigners, _ := cmd.Flags().GetInt("min-signers")
+			if minSigners <= 0 || minSigners > len(signers) {
+				return errors.Errorf("min-signers must be between 1 and the number of signers %d", len(signers))
+			}
+			resultFiles, _ := cmd.Flags().GetStringArray("result")
+			return restore(cfg, sources, signers, minSigners, resultFiles)
+		},
+	}
+	cmd.PersistentFlags().StringArray("from", nil,
+		"URL of the /checkpoint endpoint of a keyper or path of a checkpoint file (can be given multiple times)")
+	cmd.PersistentFlags().StringArray("signer", nil,
+		"address of a keyper whose checkpoints are trusted (can be given multiple times)")
+	cmd.PersistentFlags().Int("min-signers", 2, "number of trusted signers that must agree on the state")
+	cmd.PersistentFlags().StringArray("result", nil,
+		"file containing a DKG result written by 'escrow recover' (can be given multiple times)")
+	_ = cmd.MarkPersistentFlagRequired("from")
+	_ = cmd.MarkPersistentFlagRequired("signer")
+	cb.cobraCommand.AddCommand(cmd)
+}
+
 func (cb *CommandBuilder[_]) Command() *cobra.Command {
 	return cb.cobraCommand
 }
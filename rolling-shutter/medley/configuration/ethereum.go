@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/beaconapi"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/keys"
 )
 
@@ -21,6 +24,15 @@ type EthnodeConfig struct {
 	ContractsURL  string             `                     comment:"The JSON RPC endpoint where the contracts are accessible"`
 	DeploymentDir string             `                     comment:"Contract source directory"`
 	EthereumURL   string             `                     comment:"The layer 1 JSON RPC endpoint"`
+
+	EventWitnessURLs   []string `comment:"Independent JSON RPC endpoints of the contracts chain. If set, contract events are only applied if all of them agree on the block the event was emitted in"`
+	EventCrossCheckURL string   `comment:"JSON RPC endpoint of the contracts chain operated by a different provider than the main endpoint. If set, events are fetched from both and syncing halts with an alert if they disagree"`
+	EventSchemaDir     string   `comment:"Directory of JSON files with contract ABIs whose events are observed in addition to the built-in ones"`
+	BeaconAPIURL       string   `comment:"Beacon API endpoint of the chain the contracts are deployed on. It's used to fetch the blobs referenced by events of the contract ABIs, by the safe and finalized sync modes and by the slot epoch id mode of the keyper, which requires the contracts to be deployed on the layer 1 chain. If it's empty, blobs are not fetched"`
+	SyncMode           string   `comment:"Up to which block contract events are synced: latest (FinalityOffset blocks behind the head), safe (the justified checkpoint of the beacon chain) or finalized (the finalized checkpoint). safe and finalized require BeaconAPIURL"`
+	FinalityOffset     uint64   `comment:"Number of blocks contract events trail the head of the chain before they are synced in the latest sync mode"`
+
+	SkipDeploymentVerification bool `comment:"Don't check at startup that the contracts of the deployment directory are deployed at their addresses, e.g. if they are behind proxies"`
 }
 
 func (c *EthnodeConfig) Init() {
@@ -32,13 +44,30 @@ func (c *EthnodeConfig) Name() string {
 }
 
 func (c *EthnodeConfig) Validate() error {
+	mode, err := beaconapi.ParseSyncMode(c.SyncMode)
+	if err != nil {
+		return err
+	}
+	if mode.UsesCheckpoint() && c.BeaconAPIURL == "" {
+		return errors.Errorf("SyncMode %s requires a BeaconAPIURL", mode)
+	}
 	return nil
 }
 
+// GetSyncMode returns the mode contract events are synced in. It must only be called on a
+// validated config.
+func (c *EthnodeConfig) GetSyncMode() beaconapi.SyncMode {
+	mode, _ := beaconapi.ParseSyncMode(c.SyncMode)
+	return mode
+}
+
 func (c *EthnodeConfig) SetDefaultValues() error {
 	c.EthereumURL = "http://127.0.0.1:8545/"
 	c.ContractsURL = "http://127.0.0.1:8555/"
 	c.DeploymentDir = "./deployments/localhost/"
+	c.EventWitnessURLs = []string{}
+	c.SyncMode = string(beaconapi.SyncModeLatest)
+	c.FinalityOffset = 3
 	return nil
 }
 
@@ -52,6 +81,7 @@ func (c *EthnodeConfig) SetExampleValues() error {
 	if err != nil {
 		return err
 	}
+	c.EventWitnessURLs = []string{c.ContractsURL}
 	return nil
 }
 
@@ -0,0 +1,66 @@
+package epochid
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Mode determines how the epoch ids of decryption triggers are chosen.
+type Mode string
+
+const (
+	// ModeSequential lets the trigger producer choose epoch ids freely, usually as a counter. The
+	// ids of future epochs are known in advance.
+	ModeSequential Mode = "sequential"
+	// ModeBlockHash derives epoch ids from the number and hash of the trigger block, so that they
+	// can't be predicted, and nothing can be encrypted to them, before the block exists.
+	ModeBlockHash Mode = "blockhash"
+	// ModeSlot uses the beacon chain slot the trigger block was proposed in as epoch id, so that
+	// epochs are aligned with slots and the proposer of a slot knows its epoch id in advance.
+	ModeSlot Mode = "slot"
+)
+
+// blockNumberLength is the number of leading bytes of block derived epoch ids holding the block
+// number. The remaining bytes are a prefix of the block hash.
+const blockNumberLength = 8
+
+// ParseMode returns the epoch id mode with the given name. The empty name selects
+// ModeSequential.
+func ParseMode(name string) (Mode, error) {
+	switch m := Mode(name); m {
+	case "":
+		return ModeSequential, nil
+	case ModeSequential, ModeBlockHash, ModeSlot:
+		return m, nil
+	default:
+		return "", errors.Errorf("unknown epoch id mode %q", name)
+	}
+}
+
+// FromBlock derives the epoch id of the block with the given number and hash. It consists of the
+// block number as 8 byte big endian integer followed by the first 24 bytes of the block hash.
+func FromBlock(blockNumber uint64, blockHash common.Hash) EpochID {
+	var e EpochID
+	binary.BigEndian.PutUint64(e[:blockNumberLength], blockNumber)
+	copy(e[blockNumberLength:], blockHash[:len(e)-blockNumberLength])
+	return e
+}
+
+// FromSlot returns the epoch id of the beacon chain slot with the given number in ModeSlot.
+func FromSlot(slot uint64) EpochID {
+	return Uint64ToEpochID(slot)
+}
+
+// BlockNumber returns the number of the block a block derived epoch id belongs to.
+func (e EpochID) BlockNumber() uint64 {
+	return binary.BigEndian.Uint64(e[:blockNumberLength])
+}
+
+// MatchesBlock checks if the epoch id is derived from the block with the given number and hash.
+func (e EpochID) MatchesBlock(blockNumber uint64, blockHash common.Hash) bool {
+	return e.BlockNumber() == blockNumber &&
+		bytes.Equal(e[blockNumberLength:], blockHash[:len(e)-blockNumberLength])
+}
@@ -0,0 +1,39 @@
+package epochid
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+)
+
+func TestFromBlock(t *testing.T) {
+	hash := common.HexToHash("0x00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff")
+	e := FromBlock(0x0102030405060708, hash)
+	assert.Equal(t, e.Hex(), "0x010203040506070800112233445566778899aabbccddeeff0011223344556677")
+	assert.Equal(t, e.BlockNumber(), uint64(0x0102030405060708))
+	assert.Assert(t, e.MatchesBlock(0x0102030405060708, hash))
+	assert.Assert(t, !e.MatchesBlock(0x0102030405060709, hash))
+
+	otherHash := hash
+	otherHash[8] ^= 1
+	assert.Assert(t, !e.MatchesBlock(0x0102030405060708, otherHash))
+	// only the prefix of the hash is part of the epoch id
+	otherHash = hash
+	otherHash[31] ^= 1
+	assert.Assert(t, e.MatchesBlock(0x0102030405060708, otherHash))
+}
+
+func TestParseMode(t *testing.T) {
+	m, err := ParseMode("")
+	assert.NilError(t, err)
+	assert.Equal(t, m, ModeSequential)
+	m, err = ParseMode("blockhash")
+	assert.NilError(t, err)
+	assert.Equal(t, m, ModeBlockHash)
+	m, err = ParseMode("slot")
+	assert.NilError(t, err)
+	assert.Equal(t, m, ModeSlot)
+	_, err = ParseMode("random")
+	assert.ErrorContains(t, err, "unknown epoch id mode")
+}
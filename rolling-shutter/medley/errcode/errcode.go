@@ -0,0 +1,138 @@
+// Package errcode defines errors with stable, machine readable codes. Handlers attach a code to
+// the errors they return, so that API responses and log messages can name the class of a failure
+// and operator tooling can react to it without parsing error messages.
+package errcode
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/jackc/pgconn"
+	"github.com/pkg/errors"
+)
+
+// Code identifies a class of failures. Codes are part of the API and must not be changed.
+type Code string
+
+// Error is a class of failures with its code and the HTTP status it is reported with. The errors
+// returned by handlers are derived from one of the predefined errors using Errorf, Wrap or Wrapf,
+// and can be matched with errors.Is.
+type Error struct {
+	Code       Code
+	HTTPStatus int
+	message    string
+}
+
+// diskFullCode is the Postgres error code reported when the server runs out of disk space.
+const diskFullCode = "53100"
+
+func newError(code Code, httpStatus int, message string) *Error {
+	return &Error{Code: code, HTTPStatus: httpStatus, message: message}
+}
+
+var (
+	ErrInternal       = newError("INTERNAL", http.StatusInternalServerError, "internal error")
+	ErrUnauthorized   = newError("UNAUTHORIZED", http.StatusUnauthorized, "unauthorized")
+	ErrForbidden      = newError("FORBIDDEN", http.StatusForbidden, "forbidden")
+	ErrInvalidRequest = newError("INVALID_REQUEST", http.StatusBadRequest, "invalid request")
+	ErrInvalidEpochID = newError("INVALID_EPOCH_ID", http.StatusBadRequest, "invalid epoch id")
+	ErrDBUnavailable  = newError("DB_UNAVAILABLE", http.StatusServiceUnavailable, "database unavailable")
+	ErrNotInKeyperSet = newError("NOT_IN_KEYPER_SET", http.StatusConflict, "not a member of the keyper set")
+	ErrEonUnknown     = newError("EON_UNKNOWN", http.StatusNotFound, "eon unknown")
+	ErrEonKeyNotFound = newError(
+		"EON_PUBLIC_KEY_NOT_FOUND", http.StatusNotFound, "no eon public key found",
+	)
+	ErrDecryptionKeyNotFound = newError(
+		"DECRYPTION_KEY_NOT_FOUND", http.StatusNotFound, "no decryption key found",
+	)
+	ErrTxRejected      = newError("TX_REJECTED", http.StatusConflict, "transaction rejected")
+	ErrSelfAuditFailed = newError(
+		"SELF_AUDIT_FAILED", http.StatusServiceUnavailable, "own key material failed the self-audit",
+	)
+	ErrStorageExhausted = newError(
+		"STORAGE_EXHAUSTED", http.StatusInsufficientStorage, "database is out of disk space",
+	)
+	ErrKeyGenerationPaused = newError(
+		"KEY_GENERATION_PAUSED", http.StatusServiceUnavailable, "key generation paused by the keypers",
+	)
+	ErrTriggerNotAllowed = newError(
+		"TRIGGER_NOT_ALLOWED", http.StatusForbidden, "decryption trigger not allowed",
+	)
+	ErrTriggerTooEarly    = newError("TRIGGER_TOO_EARLY", http.StatusTooEarly, "decryption trigger too early")
+	ErrRateLimited        = newError("RATE_LIMITED", http.StatusTooManyRequests, "too many requests")
+	ErrAnnotationNotFound = newError("ANNOTATION_NOT_FOUND", http.StatusNotFound, "annotation not found")
+	ErrFeatureUnknown     = newError("FEATURE_UNKNOWN", http.StatusNotFound, "unknown feature")
+	ErrTxNotFound         = newError("TX_NOT_FOUND", http.StatusNotFound, "transaction not found")
+)
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+// ErrorCode returns e itself. It allows Of to find the code of errors derived from e.
+func (e *Error) ErrorCode() *Error {
+	return e
+}
+
+// Errorf returns a new error with the code of e and the given message.
+func (e *Error) Errorf(format string, args ...interface{}) error {
+	return &coded{code: e, err: errors.Errorf(format, args...)}
+}
+
+// Wrap returns an error with the code of e that otherwise behaves like err. It returns nil if err
+// is nil.
+func (e *Error) Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &coded{code: e, err: err}
+}
+
+// Wrapf annotates err with the given message and the code of e. It returns nil if err is nil.
+func (e *Error) Wrapf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &coded{code: e, err: errors.Wrapf(err, format, args...)}
+}
+
+type coded struct {
+	code *Error
+	err  error
+}
+
+func (c *coded) Error() string        { return c.err.Error() }
+func (c *coded) Unwrap() error        { return c.err }
+func (c *coded) Is(target error) bool { return target == c.code }
+func (c *coded) ErrorCode() *Error    { return c.code }
+
+// WrapDB annotates an error returned by a database call with the given message. Errors reported
+// by the database server get the code ErrInternal, except for the server running out of disk
+// space, which gets ErrStorageExhausted. All other errors mean that the database could
+// not be reached and get the code ErrDBUnavailable. It returns nil if err is nil.
+func WrapDB(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == diskFullCode {
+		return ErrStorageExhausted.Wrapf(err, format, args...)
+	}
+	if pgErr != nil || errors.Is(err, context.Canceled) {
+		return ErrInternal.Wrapf(err, format, args...)
+	}
+	return ErrDBUnavailable.Wrapf(err, format, args...)
+}
+
+// Of returns the class of err. Errors without a code are classified as ErrInternal. Of returns nil
+// if err is nil.
+func Of(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var c interface{ ErrorCode() *Error }
+	if errors.As(err, &c) {
+		return c.ErrorCode()
+	}
+	return ErrInternal
+}
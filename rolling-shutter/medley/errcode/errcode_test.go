@@ -0,0 +1,52 @@
+package errcode
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+)
+
+func TestOf(t *testing.T) {
+	assert.Assert(t, Of(nil) == nil)
+	assert.Equal(t, Of(errors.New("boom")), ErrInternal)
+	assert.Equal(t, Of(ErrEonUnknown), ErrEonUnknown)
+
+	err := errors.Wrap(ErrNotInKeyperSet.Errorf("not a keyper in keyper set %d", 3), "failed to handle trigger")
+	assert.Equal(t, Of(err), ErrNotInKeyperSet)
+	assert.Assert(t, errors.Is(err, ErrNotInKeyperSet))
+	assert.Assert(t, !errors.Is(err, ErrEonUnknown))
+	assert.Error(t, err, "failed to handle trigger: not a keyper in keyper set 3")
+
+	cause := errors.New("no rows")
+	err = ErrEonUnknown.Wrapf(cause, "no eon for block %d", 10)
+	assert.Assert(t, errors.Is(err, cause))
+	assert.Equal(t, Of(err), ErrEonUnknown)
+	assert.Assert(t, ErrEonUnknown.Wrap(nil) == nil)
+
+}
+
+func TestWrapDB(t *testing.T) {
+	assert.Assert(t, WrapDB(nil, "failed to query db") == nil)
+	err := WrapDB(errors.New("dial tcp: connection refused"), "failed to query db")
+	assert.Equal(t, Of(err), ErrDBUnavailable)
+	err = WrapDB(errors.WithStack(&pgconn.PgError{Code: "23505"}), "failed to query db")
+	assert.Equal(t, Of(err), ErrInternal)
+	err = WrapDB(errors.WithStack(&pgconn.PgError{Code: "53100"}), "failed to query db")
+	assert.Equal(t, Of(err), ErrStorageExhausted)
+}
+
+func TestSendError(t *testing.T) {
+	w := httptest.NewRecorder()
+	SendError(w, ErrEonUnknown.Errorf("no eon %d", 3))
+	assert.Equal(t, w.Code, http.StatusNotFound)
+	assert.Equal(t, w.Header().Get("Content-Type"), "application/json")
+
+	res := Response{}
+	assert.NilError(t, json.NewDecoder(w.Body).Decode(&res))
+	assert.DeepEqual(t, res, Response{Code: http.StatusNotFound, ErrorCode: "EON_UNKNOWN", Message: "no eon 3"})
+}
@@ -0,0 +1,30 @@
+package errcode
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Response is the body of error responses of the HTTP APIs.
+type Response struct {
+	Code      int32  `json:"code"`
+	ErrorCode string `json:"errorCode"`
+	Message   string `json:"message"`
+}
+
+// WriteJSON responds with the given status code and v encoded as JSON.
+func WriteJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// SendError responds with the HTTP status of the class of err and a body naming its code.
+func SendError(w http.ResponseWriter, err error) {
+	class := Of(err)
+	WriteJSON(w, class.HTTPStatus, Response{
+		Code:      int32(class.HTTPStatus),
+		ErrorCode: string(class.Code),
+		Message:   err.Error(),
+	})
+}
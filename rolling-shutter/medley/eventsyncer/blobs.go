@@ -0,0 +1,102 @@
+package eventsyncer
+
+import (
+	"context"
+	"crypto/sha256"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/beaconapi"
+)
+
+const blobCommitmentVersionKZG = 0x01
+
+// ErrBlobNotFound is returned if the beacon node doesn't know a blob, e.g. because it has already
+// been pruned.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// HeaderByHashReader provides the headers of the execution chain the events are emitted on.
+type HeaderByHashReader interface {
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+}
+
+// KZGToVersionedHash computes the versioned hash blobs are referenced by in transactions and
+// contracts from their KZG commitment, as specified in EIP-4844.
+func KZGToVersionedHash(commitment kzg4844.Commitment) common.Hash {
+	hash := common.Hash(sha256.Sum256(commitment[:]))
+	hash[0] = blobCommitmentVersionKZG
+	return hash
+}
+
+// BlobFetcher fetches the blobs events refer to from the blob sidecars served by the beacon API.
+// The sidecars are looked up by the slot of the event's block, and each blob is checked against its
+// KZG commitment and proof, so the beacon node doesn't have to be trusted.
+type BlobFetcher struct {
+	beacon  *beaconapi.Client
+	headers HeaderByHashReader
+}
+
+func NewBlobFetcher(beacon *beaconapi.Client, headers HeaderByHashReader) *BlobFetcher {
+	return &BlobFetcher{beacon: beacon, headers: headers}
+}
+
+// Fetch returns the blobs with the given versioned hashes, which must have been published in the
+// execution block with the given hash. It fails with ErrBlobNotFound if any of them is missing.
+func (f *BlobFetcher) Fetch(
+	ctx context.Context, blockHash common.Hash, hashes []common.Hash,
+) (map[common.Hash]*kzg4844.Blob, error) {
+	header, err := f.headers.HeaderByHash(ctx, blockHash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch block %s", blockHash.Hex())
+	}
+	slot, err := f.beacon.SlotOfBlock(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+	sidecars, err := f.beacon.BlobSidecars(ctx, slot)
+	if errors.Is(err, beaconapi.ErrNotFound) {
+		return nil, errors.Wrapf(ErrBlobNotFound, "no blob sidecars in slot %d", slot)
+	} else if err != nil {
+		return nil, err
+	}
+	wanted := make(map[common.Hash]bool)
+	for _, hash := range hashes {
+		wanted[hash] = true
+	}
+	blobs := make(map[common.Hash]*kzg4844.Blob)
+	for _, sidecar := range sidecars {
+		blob, hash, err := verifySidecar(sidecar)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid blob sidecar in slot %d", slot)
+		}
+		if wanted[hash] {
+			blobs[hash] = blob
+		}
+	}
+	for _, hash := range hashes {
+		if _, ok := blobs[hash]; !ok {
+			return nil, errors.Wrapf(ErrBlobNotFound, "blob %s not in slot %d", hash.Hex(), slot)
+		}
+	}
+	return blobs, nil
+}
+
+func verifySidecar(sidecar beaconapi.BlobSidecar) (*kzg4844.Blob, common.Hash, error) {
+	var blob kzg4844.Blob
+	var commitment kzg4844.Commitment
+	var proof kzg4844.Proof
+	if len(sidecar.Blob) != len(blob) || len(sidecar.KZGCommitment) != len(commitment) ||
+		len(sidecar.KZGProof) != len(proof) {
+		return nil, common.Hash{}, errors.New("blob, commitment or proof has the wrong length")
+	}
+	copy(blob[:], sidecar.Blob)
+	copy(commitment[:], sidecar.KZGCommitment)
+	copy(proof[:], sidecar.KZGProof)
+	if err := kzg4844.VerifyBlobProof(blob, commitment, proof); err != nil {
+		return nil, common.Hash{}, errors.Wrap(err, "blob doesn't match its commitment")
+	}
+	return &blob, KZGToVersionedHash(commitment), nil
+}
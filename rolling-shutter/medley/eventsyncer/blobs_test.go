@@ -0,0 +1,97 @@
+package eventsyncer
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/beaconapi"
+)
+
+type headersByHash map[common.Hash]*types.Header
+
+func (h headersByHash) HeaderByHash(_ context.Context, hash common.Hash) (*types.Header, error) {
+	header, ok := h[hash]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return header, nil
+}
+
+func newTestSidecar(t *testing.T, fill byte) (beaconapi.BlobSidecar, common.Hash) {
+	t.Helper()
+	var blob kzg4844.Blob
+	// keep the field elements below the modulus
+	for i := 1; i < len(blob); i += 32 {
+		blob[i] = fill
+	}
+	commitment, err := kzg4844.BlobToCommitment(blob)
+	assert.NilError(t, err)
+	proof, err := kzg4844.ComputeBlobProof(blob, commitment)
+	assert.NilError(t, err)
+	return beaconapi.BlobSidecar{Blob: blob[:], KZGCommitment: commitment[:], KZGProof: proof[:]}, KZGToVersionedHash(commitment)
+}
+
+func TestBlobHashesFromArgs(t *testing.T) {
+	a, b := common.HexToHash("0x01aa"), common.HexToHash("0x01bb")
+	args := map[string]interface{}{
+		"hash":   [32]byte(a),
+		"hashes": [][32]byte{b},
+		"amount": big.NewInt(1),
+	}
+	hashes, err := blobHashesFromArgs(args, []string{"hash", "hashes", "missing"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, hashes, []common.Hash{a, b})
+
+	_, err = blobHashesFromArgs(args, []string{"amount"})
+	assert.ErrorContains(t, err, "instead of bytes32")
+}
+
+func TestBlobFetcher(t *testing.T) {
+	sidecar, hash := newTestSidecar(t, 1)
+	otherSidecar, otherHash := newTestSidecar(t, 2)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eth/v1/beacon/genesis", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data": {"genesis_time": "1000"}}`))
+	})
+	mux.HandleFunc("/eth/v1/config/spec", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data": {"SECONDS_PER_SLOT": "12", "SLOTS_PER_EPOCH": "32"}}`))
+	})
+	mux.HandleFunc("/eth/v1/beacon/blob_sidecars/5", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(beaconapi.BlobSidecarsResponse{Data: []beaconapi.BlobSidecar{sidecar, otherSidecar}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	blockHash := common.HexToHash("0xb1")
+	headers := headersByHash{blockHash: &types.Header{Time: 1000 + 5*12 + 3}}
+	f := NewBlobFetcher(beaconapi.New(server.URL+"/", server.Client()), headers)
+	ctx := context.Background()
+
+	blobs, err := f.Fetch(ctx, blockHash, []common.Hash{hash})
+	assert.NilError(t, err)
+	assert.Equal(t, len(blobs), 1)
+	assert.DeepEqual(t, hexutil.Bytes(blobs[hash][:]), sidecar.Blob)
+
+	_, err = f.Fetch(ctx, blockHash, []common.Hash{hash, common.HexToHash("0x01cc")})
+	assert.Assert(t, errors.Is(err, ErrBlobNotFound))
+
+	// a blob that doesn't match its commitment is rejected
+	otherSidecar.Blob = sidecar.Blob
+	mux.HandleFunc("/eth/v1/beacon/blob_sidecars/6", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(beaconapi.BlobSidecarsResponse{Data: []beaconapi.BlobSidecar{otherSidecar}})
+	})
+	headers[blockHash].Time += 12
+	_, err = f.Fetch(ctx, blockHash, []common.Hash{otherHash})
+	assert.ErrorContains(t, err, "doesn't match")
+}
@@ -0,0 +1,92 @@
+package eventsyncer
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
+)
+
+// ErrProviderDivergence is returned by the syncer if the cross-check endpoint returns different
+// events than the main endpoint. Syncing can't continue safely, as one of them must be lying or
+// broken.
+var ErrProviderDivergence = errors.New("rpc providers returned different events")
+
+// minBlockNumber returns the current block number of the main endpoint, or the one of the
+// cross-check endpoint if that is behind, so that both are asked only for blocks they know.
+func (s *EventSyncer) minBlockNumber(ctx context.Context) (uint64, error) {
+	n, err := retry.FunctionCall(ctx, s.Client.BlockNumber)
+	if err != nil || s.CrossCheckClient == nil {
+		return n, err
+	}
+	checkN, err := retry.FunctionCall(ctx, s.CrossCheckClient.BlockNumber)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to query current block number from cross-check endpoint")
+	}
+	if checkN < n {
+		return checkN, nil
+	}
+	return n, nil
+}
+
+// filterLogs fetches the logs matching the query from the main endpoint and, if configured,
+// checks that the cross-check endpoint returns the same ones.
+func (s *EventSyncer) filterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	logs, err := retryFilterLogs(ctx, s.Client, query)
+	if err != nil || s.CrossCheckClient == nil {
+		return logs, err
+	}
+	checkLogs, err := retryFilterLogs(ctx, s.CrossCheckClient, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter event logs on cross-check endpoint")
+	}
+	return logs, compareLogs(logs, checkLogs)
+}
+
+func retryFilterLogs(ctx context.Context, client *ethclient.Client, query ethereum.FilterQuery) ([]types.Log, error) {
+	return retry.FunctionCall(ctx, func(ctx context.Context) ([]types.Log, error) {
+		return client.FilterLogs(ctx, query)
+	})
+}
+
+// compareLogs checks that a and b contain the same logs, regardless of their order.
+func compareLogs(a, b []types.Log) error {
+	if len(a) != len(b) {
+		return errors.Wrapf(ErrProviderDivergence, "got %d and %d logs", len(a), len(b))
+	}
+	a, b = sortedLogs(a), sortedLogs(b)
+	for i := range a {
+		if !logEqual(&a[i], &b[i]) {
+			return errors.Wrapf(ErrProviderDivergence,
+				"log %d of block %d (%s) differs from log %d of block %d (%s)",
+				a[i].Index, a[i].BlockNumber, a[i].BlockHash, b[i].Index, b[i].BlockNumber, b[i].BlockHash)
+		}
+	}
+	return nil
+}
+
+func sortedLogs(logs []types.Log) []types.Log {
+	sorted := append([]types.Log{}, logs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].BlockNumber != sorted[j].BlockNumber {
+			return sorted[i].BlockNumber < sorted[j].BlockNumber
+		}
+		return sorted[i].Index < sorted[j].Index
+	})
+	return sorted
+}
+
+func logEqual(a, b *types.Log) bool {
+	return a.BlockNumber == b.BlockNumber &&
+		a.BlockHash == b.BlockHash &&
+		a.TxHash == b.TxHash &&
+		a.TxIndex == b.TxIndex &&
+		a.Index == b.Index &&
+		a.Removed == b.Removed &&
+		logContentEqual(a, b)
+}
@@ -0,0 +1,34 @@
+package eventsyncer
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+)
+
+func TestCompareLogs(t *testing.T) {
+	logs := []types.Log{
+		{BlockNumber: 1, BlockHash: common.HexToHash("0x01"), Index: 0, Data: []byte{1}},
+		{BlockNumber: 1, BlockHash: common.HexToHash("0x01"), Index: 1, Data: []byte{2}},
+		{BlockNumber: 2, BlockHash: common.HexToHash("0x02"), Index: 0, Data: []byte{3}},
+	}
+	reordered := []types.Log{logs[2], logs[0], logs[1]}
+	assert.NilError(t, compareLogs(logs, reordered))
+	assert.NilError(t, compareLogs(nil, []types.Log{}))
+
+	err := compareLogs(logs, logs[:2])
+	assert.Assert(t, errors.Is(err, ErrProviderDivergence))
+
+	tampered := append([]types.Log{}, logs...)
+	tampered[1].Data = []byte{4}
+	err = compareLogs(logs, tampered)
+	assert.Assert(t, errors.Is(err, ErrProviderDivergence))
+
+	reorged := append([]types.Log{}, logs...)
+	reorged[2].BlockHash = common.HexToHash("0x03")
+	err = compareLogs(logs, reorged)
+	assert.Assert(t, errors.Is(err, ErrProviderDivergence))
+}
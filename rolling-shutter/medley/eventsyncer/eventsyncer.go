@@ -16,8 +16,6 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
-
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
 )
 
 const (
@@ -31,6 +29,20 @@ var (
 	ErrNotRunning     = errors.New("event syncer not running")
 )
 
+// UnpackError is returned by Next if a log can't be decoded.
+type UnpackError struct {
+	Log types.Log
+	Err error
+}
+
+func (e *UnpackError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UnpackError) Unwrap() error {
+	return e.Err
+}
+
 // EventType defines a single event type to filter for.
 type EventType struct {
 	Contract        *bind.BoundContract
@@ -39,6 +51,39 @@ type EventType struct {
 	ABI             abi.ABI
 	Name            string
 	Type            reflect.Type
+
+	// ContractName is only set for event types loaded from an EventSchema. These have no Type and
+	// are yielded as DynamicEvent.
+	ContractName string
+	// BlobHashArgs are the arguments of a schema event holding blob versioned hashes, see
+	// EventSchema.
+	BlobHashArgs []string
+}
+
+// Key identifies the event type, e.g. to keep track of its sync progress. It includes the contract
+// address, so that the events of a redeployed contract are synced from the start.
+func (t *EventType) Key() string {
+	return t.Address.Hex() + ":" + t.Name
+}
+
+// Unpack decodes a log of the event type. The result is of type `Type`, or a DynamicEvent if the
+// event type has no Type.
+func (t *EventType) Unpack(log types.Log) (interface{}, error) {
+	if t.Type == nil {
+		event, err := unpackDynamicEvent(t, log)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to unpack log of %s event of contract %s", t.Name, t.ContractName)
+		}
+		return event, nil
+	}
+
+	event := reflect.New(t.Type)
+	err := t.Contract.UnpackLog(event.Interface(), t.Name, log)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unpack log of %s event", t.Name)
+	}
+	reflect.Indirect(event).FieldByName("Raw").Set(reflect.ValueOf(log))
+	return reflect.Indirect(event).Interface(), nil
 }
 
 // logChannelItem is what is put on the (internal) channel of found logs. It can either contain a
@@ -57,11 +102,26 @@ type EventSyncUpdate struct {
 	LogIndex    uint64
 }
 
+// CheckpointReader provides the number of the latest execution block covered by a checkpoint of
+// the consensus layer, e.g. a beaconapi.Checkpoint.
+type CheckpointReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
 // EventSyncer watches the blockchain for events of given types and yields them in order.
 type EventSyncer struct {
 	Client         *ethclient.Client
 	FinalityOffset uint64
 
+	// Checkpoint is optional. If set, blocks are only synced once they are covered by the
+	// checkpoint instead of once they trail the current block by FinalityOffset.
+	Checkpoint CheckpointReader
+
+	// CrossCheckClient is an optional endpoint of a second provider. If set, every block range
+	// is fetched from both endpoints and syncing fails with ErrProviderDivergence if they return
+	// different events.
+	CrossCheckClient *ethclient.Client
+
 	Events       []*EventType
 	FromBlock    uint64
 	FromLogIndex uint64
@@ -104,18 +164,12 @@ func (s *EventSyncer) Next(ctx context.Context) (EventSyncUpdate, error) {
 			}, nil
 		}
 
-		event := reflect.New(item.eventType.Type)
-		err := item.eventType.Contract.UnpackLog(event.Interface(), item.eventType.Name, *item.log)
+		event, err := item.eventType.Unpack(*item.log)
 		if err != nil {
-			return EventSyncUpdate{}, errors.Wrapf(
-				err,
-				"failed to unpack log of %s event", item.eventType.Name,
-			)
+			return EventSyncUpdate{}, &UnpackError{Log: *item.log, Err: err}
 		}
-		reflect.Indirect(event).FieldByName("Raw").Set(reflect.ValueOf(*item.log))
-
 		return EventSyncUpdate{
-			Event:       reflect.Indirect(event).Interface(),
+			Event:       event,
 			BlockNumber: item.blockNumber,
 			LogIndex:    uint64(item.log.Index),
 		}, nil
@@ -138,17 +192,15 @@ func (s *EventSyncer) Run(ctx context.Context) error {
 func (s *EventSyncer) sync(ctx context.Context) error {
 	fromBlock := s.FromBlock
 	for {
-		currentBlock, err := retry.FunctionCall(ctx, s.Client.BlockNumber)
+		currentBlock, err := s.minBlockNumber(ctx)
 		if err != nil {
 			return errors.Wrap(err, "failed to query current block number")
 		}
 
 		toBlock := fromBlock + pageSizeBlocks - 1
-		var maxToBlock uint64
-		if currentBlock >= s.FinalityOffset {
-			maxToBlock = currentBlock - s.FinalityOffset
-		} else {
-			maxToBlock = 0
+		maxToBlock, err := s.maxToBlock(ctx, currentBlock)
+		if err != nil {
+			return err
 		}
 		if toBlock > maxToBlock {
 			toBlock = maxToBlock
@@ -177,6 +229,24 @@ func (s *EventSyncer) sync(ctx context.Context) error {
 	}
 }
 
+// maxToBlock returns the latest block that may be synced given the current block.
+func (s *EventSyncer) maxToBlock(ctx context.Context, currentBlock uint64) (uint64, error) {
+	if s.Checkpoint == nil {
+		if currentBlock < s.FinalityOffset {
+			return 0, nil
+		}
+		return currentBlock - s.FinalityOffset, nil
+	}
+	checkpointBlock, err := s.Checkpoint.BlockNumber(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to query checkpoint block number")
+	}
+	if checkpointBlock > currentBlock {
+		return currentBlock, nil
+	}
+	return checkpointBlock, nil
+}
+
 // syncAllInRange returns all events found in the given block range.
 func (s *EventSyncer) syncAllInRange(ctx context.Context, fromBlock uint64, toBlock uint64) ([]logChannelItem, error) {
 	logs := []logChannelItem{}
@@ -229,10 +299,10 @@ func (s *EventSyncer) syncSingleInRange(ctx context.Context, event *EventType, f
 		Topics:    [][]common.Hash{{topic}},
 	}
 
-	logs, err := retry.FunctionCall(ctx, func(ctx context.Context) ([]types.Log, error) {
-		return s.Client.FilterLogs(ctx, query)
-	})
-	if err != nil {
+	logs, err := s.filterLogs(ctx, query)
+	if errors.Is(err, ErrProviderDivergence) {
+		return nil, errors.Wrapf(err, "%s events in blocks %d to %d", event.Name, fromBlock, toBlock)
+	} else if err != nil {
 		return nil, errors.New("failed to filter event logs")
 	}
 
@@ -0,0 +1,137 @@
+package eventsyncer
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/pkg/errors"
+)
+
+// EventSchema describes the events of a contract by its ABI instead of compiled contract
+// bindings. It allows to observe events that have been added by a contract upgrade with a change
+// of configuration only. BlobHashArgs names the event arguments, of type bytes32 or bytes32[],
+// that hold versioned hashes of EIP-4844 blobs the events refer to.
+type EventSchema struct {
+	ContractName    string          `json:"contractName"`
+	Address         common.Address  `json:"address"`
+	FromBlockNumber uint64          `json:"fromBlockNumber"`
+	ABI             json.RawMessage `json:"abi"`
+	Events          []string        `json:"events"`
+	BlobHashArgs    []string        `json:"blobHashArgs,omitempty"`
+}
+
+// DynamicEvent is an event of a type loaded from an EventSchema. As there's no Go type for it, its
+// arguments are unpacked into a map keyed by argument name. BlobHashes are the versioned hashes
+// found in the blob hash arguments of the event. Blobs holds the blobs they refer to if blob
+// fetching is enabled in the consumer of the event, see BlobFetcher.
+type DynamicEvent struct {
+	ContractName string
+	Name         string
+	Args         map[string]interface{}
+	Raw          types.Log
+	BlobHashes   []common.Hash
+	Blobs        map[common.Hash]*kzg4844.Blob
+}
+
+// ReadEventSchemaDir reads all event schemas from the JSON files in the given directory.
+func ReadEventSchemaDir(dir string) ([]*EventSchema, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list event schemas in %s", dir)
+	}
+	sort.Strings(paths)
+	schemas := []*EventSchema{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read event schema %s", path)
+		}
+		schema := &EventSchema{}
+		if err := json.Unmarshal(data, schema); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse event schema %s", path)
+		}
+		if schema.ContractName == "" {
+			schema.ContractName = filepath.Base(path[:len(path)-len(filepath.Ext(path))])
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, nil
+}
+
+// EventTypes creates the event types for the events listed in the schema. Syncing them yields
+// DynamicEvents.
+func (s *EventSchema) EventTypes(backend bind.ContractBackend) ([]*EventType, error) {
+	contractABI, err := abi.JSON(bytes.NewReader(s.ABI))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse ABI of contract %s", s.ContractName)
+	}
+	if len(s.Events) == 0 {
+		return nil, errors.Errorf("no events given for contract %s", s.ContractName)
+	}
+	contract := bind.NewBoundContract(s.Address, contractABI, backend, backend, backend)
+	eventTypes := []*EventType{}
+	for _, name := range s.Events {
+		if _, ok := contractABI.Events[name]; !ok {
+			return nil, errors.Errorf("event %s not found in ABI of contract %s", name, s.ContractName)
+		}
+		eventTypes = append(eventTypes, &EventType{
+			Contract:        contract,
+			Address:         s.Address,
+			FromBlockNumber: s.FromBlockNumber,
+			ABI:             contractABI,
+			Name:            name,
+			ContractName:    s.ContractName,
+			BlobHashArgs:    s.BlobHashArgs,
+		})
+	}
+	return eventTypes, nil
+}
+
+func unpackDynamicEvent(eventType *EventType, log types.Log) (DynamicEvent, error) {
+	args := make(map[string]interface{})
+	if err := eventType.Contract.UnpackLogIntoMap(args, eventType.Name, log); err != nil {
+		return DynamicEvent{}, err
+	}
+	blobHashes, err := blobHashesFromArgs(args, eventType.BlobHashArgs)
+	if err != nil {
+		return DynamicEvent{}, err
+	}
+	return DynamicEvent{
+		ContractName: eventType.ContractName,
+		Name:         eventType.Name,
+		Args:         args,
+		Raw:          log,
+		BlobHashes:   blobHashes,
+	}, nil
+}
+
+// blobHashesFromArgs collects the versioned hashes from the given arguments. Arguments missing
+// from the event are skipped, since the blob hash arguments are given per contract, not per event.
+func blobHashesFromArgs(args map[string]interface{}, names []string) ([]common.Hash, error) {
+	var hashes []common.Hash
+	for _, name := range names {
+		arg, ok := args[name]
+		if !ok {
+			continue
+		}
+		switch v := arg.(type) {
+		case [32]byte:
+			hashes = append(hashes, v)
+		case [][32]byte:
+			for _, h := range v {
+				hashes = append(hashes, h)
+			}
+		default:
+			return nil, errors.Errorf("blob hash argument %s is of type %T instead of bytes32 or bytes32[]", name, arg)
+		}
+	}
+	return hashes, nil
+}
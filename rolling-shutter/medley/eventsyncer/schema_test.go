@@ -0,0 +1,68 @@
+package eventsyncer
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/v3/assert"
+)
+
+const testSchema = `{
+	"address": "0x0000000000000000000000000000000000000042",
+	"fromBlockNumber": 7,
+	"abi": [{
+		"type": "event",
+		"name": "Upgraded",
+		"anonymous": false,
+		"inputs": [
+			{"name": "version", "type": "uint64", "indexed": true},
+			{"name": "implementation", "type": "address", "indexed": false}
+		]
+	}],
+	"events": ["Upgraded"]
+}`
+
+func TestEventSchema(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "Proxy.json"), []byte(testSchema), 0o600)
+	assert.NilError(t, err)
+
+	schemas, err := ReadEventSchemaDir(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(schemas), 1)
+	assert.Equal(t, schemas[0].ContractName, "Proxy")
+	assert.Equal(t, schemas[0].FromBlockNumber, uint64(7))
+
+	eventTypes, err := schemas[0].EventTypes(nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(eventTypes), 1)
+	eventType := eventTypes[0]
+	assert.Equal(t, eventType.Address, common.HexToAddress("0x42"))
+	assert.Assert(t, eventType.Type == nil)
+
+	implementation := common.HexToAddress("0x1234")
+	data, err := eventType.ABI.Events["Upgraded"].Inputs.NonIndexed().Pack(implementation)
+	assert.NilError(t, err)
+	log := types.Log{
+		Address: eventType.Address,
+		Topics: []common.Hash{
+			eventType.ABI.Events["Upgraded"].ID,
+			common.BigToHash(big.NewInt(3)),
+		},
+		Data: data,
+	}
+	event, err := unpackDynamicEvent(eventType, log)
+	assert.NilError(t, err)
+	assert.Equal(t, event.ContractName, "Proxy")
+	assert.Equal(t, event.Name, "Upgraded")
+	assert.Equal(t, event.Args["version"], uint64(3))
+	assert.Equal(t, event.Args["implementation"], implementation)
+
+	schemas[0].Events = []string{"Unknown"}
+	_, err = schemas[0].EventTypes(nil)
+	assert.ErrorContains(t, err, "not found")
+}
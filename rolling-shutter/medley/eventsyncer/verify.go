@@ -0,0 +1,168 @@
+package eventsyncer
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
+)
+
+// ErrUnverifiedLog is returned if a log could not be proven to be part of the canonical chain.
+var ErrUnverifiedLog = errors.New("failed to verify event log")
+
+// LogVerifier verifies that a log returned by the RPC endpoint has actually been emitted on chain.
+type LogVerifier struct {
+	client    *ethclient.Client
+	witnesses []*ethclient.Client
+}
+
+// NewLogVerifier creates a verifier that checks logs returned by client against independent
+// witness endpoints. The block header containing a log has to be served identically by all
+// witnesses. The log is then proven to be part of that header by recomputing the receipts trie
+// of the block, whose root is committed to in the header. This protects against a single
+// malicious or faulty RPC endpoint, as long as at least one of the endpoints is honest.
+func NewLogVerifier(client *ethclient.Client, witnessURLs []string) (*LogVerifier, error) {
+	if len(witnessURLs) == 0 {
+		return nil, errors.New("no witness endpoints given")
+	}
+	v := &LogVerifier{client: client}
+	for _, url := range witnessURLs {
+		witness, err := ethclient.Dial(url)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to connect to witness endpoint %s", url)
+		}
+		v.witnesses = append(v.witnesses, witness)
+	}
+	return v, nil
+}
+
+// VerifyEvent verifies the log of an event as returned by EventSyncer.Next.
+func (v *LogVerifier) VerifyEvent(ctx context.Context, event interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(event))
+	if value.Kind() != reflect.Struct {
+		return errors.Errorf("event of type %T has no raw log", event)
+	}
+	raw := value.FieldByName("Raw")
+	if !raw.IsValid() || !raw.CanInterface() {
+		return errors.Errorf("event of type %T has no raw log", event)
+	}
+	log, ok := raw.Interface().(types.Log)
+	if !ok {
+		return errors.Errorf("raw log of event of type %T has unexpected type", event)
+	}
+	return v.VerifyLog(ctx, log)
+}
+
+// VerifyLog verifies that the log is part of the block it claims to be in and that this block is
+// part of the chain according to all witnesses.
+func (v *LogVerifier) VerifyLog(ctx context.Context, log types.Log) error {
+	if log.Removed {
+		return errors.Wrap(ErrUnverifiedLog, "log has been removed in a reorg")
+	}
+	block, err := retry.FunctionCall(ctx, func(ctx context.Context) (*types.Block, error) {
+		return v.client.BlockByHash(ctx, log.BlockHash)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get block %s", log.BlockHash)
+	}
+	header := block.Header()
+	if err := v.verifyHeader(ctx, header, log.BlockNumber, log.BlockHash); err != nil {
+		return err
+	}
+	if types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)) != header.TxHash {
+		return errors.Wrapf(ErrUnverifiedLog, "transactions of block %s don't match header", log.BlockHash)
+	}
+	if log.TxIndex >= uint(len(block.Transactions())) {
+		return errors.Wrapf(ErrUnverifiedLog, "transaction index %d out of range", log.TxIndex)
+	}
+
+	receipts, err := v.receipts(ctx, block.Transactions())
+	if err != nil {
+		return err
+	}
+	if types.DeriveSha(receipts, trie.NewStackTrie(nil)) != header.ReceiptHash {
+		return errors.Wrapf(ErrUnverifiedLog, "receipts of block %s don't match header", log.BlockHash)
+	}
+
+	// the receipts root doesn't commit to the hashes of the transactions, but the transactions root
+	// does
+	if txHash := block.Transactions()[log.TxIndex].Hash(); txHash != log.TxHash {
+		return errors.Wrapf(ErrUnverifiedLog, "log claims transaction %s, but transaction %d is %s",
+			log.TxHash, log.TxIndex, txHash)
+	}
+	for _, l := range receipts[log.TxIndex].Logs {
+		if l.Index == log.Index && logContentEqual(l, &log) {
+			return nil
+		}
+	}
+	return errors.Wrapf(ErrUnverifiedLog, "log %d not found in receipt of transaction %s", log.Index, log.TxHash)
+}
+
+// receipts fetches the receipts of the transactions in a single batch request.
+func (v *LogVerifier) receipts(ctx context.Context, txs types.Transactions) (types.Receipts, error) {
+	receipts := make(types.Receipts, len(txs))
+	batch := make([]rpc.BatchElem, len(txs))
+	for i, tx := range txs {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{tx.Hash()},
+			Result: &receipts[i],
+		}
+	}
+	_, err := retry.FunctionCall(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, v.client.Client().BatchCallContext(ctx, batch)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get receipts")
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, errors.Wrapf(elem.Error, "failed to get receipt of transaction %s", txs[i].Hash())
+		}
+		if receipts[i] == nil {
+			return nil, errors.Wrapf(ErrUnverifiedLog, "no receipt for transaction %s", txs[i].Hash())
+		}
+	}
+	return receipts, nil
+}
+
+// verifyHeader checks that the header returned by the client has the expected number and hash and
+// that all witnesses agree.
+func (v *LogVerifier) verifyHeader(ctx context.Context, header *types.Header, number uint64, hash common.Hash) error {
+	if header.Hash() != hash || header.Number.Uint64() != number {
+		return errors.Wrapf(ErrUnverifiedLog, "header %s does not match the log", hash)
+	}
+	for i, witness := range v.witnesses {
+		witnessHeader, err := retry.FunctionCall(ctx, func(ctx context.Context) (*types.Header, error) {
+			return witness.HeaderByNumber(ctx, header.Number)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get header %d from witness %d", number, i)
+		}
+		if witnessHeader.Hash() != hash {
+			return errors.Wrapf(ErrUnverifiedLog,
+				"witness %d has block %s at height %d, expected %s", i, witnessHeader.Hash(), number, hash)
+		}
+	}
+	return nil
+}
+
+func logContentEqual(a, b *types.Log) bool {
+	if a.Address != b.Address || len(a.Topics) != len(b.Topics) || !bytes.Equal(a.Data, b.Data) {
+		return false
+	}
+	for i := range a.Topics {
+		if a.Topics[i] != b.Topics[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,258 @@
+package eventsyncer
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+)
+
+// fakeChain serves a single block and the receipts of its transactions.
+type fakeChain struct {
+	header   *types.Header
+	txs      types.Transactions
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (c *fakeChain) block(full bool) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(c.header)
+	if err != nil {
+		return nil, err
+	}
+	block := map[string]interface{}{}
+	if err := json.Unmarshal(encoded, &block); err != nil {
+		return nil, err
+	}
+	if full {
+		block["transactions"] = c.txs
+	} else {
+		hashes := []common.Hash{}
+		for _, tx := range c.txs {
+			hashes = append(hashes, tx.Hash())
+		}
+		block["transactions"] = hashes
+	}
+	block["uncles"] = []common.Hash{}
+	return block, nil
+}
+
+func (c *fakeChain) GetBlockByHash(hash common.Hash, full bool) (map[string]interface{}, error) {
+	if hash != c.header.Hash() {
+		return nil, nil
+	}
+	return c.block(full)
+}
+
+func (c *fakeChain) GetBlockByNumber(number hexutil.Big, full bool) (map[string]interface{}, error) {
+	if number.ToInt().Cmp(c.header.Number) != 0 {
+		return nil, nil
+	}
+	return c.block(full)
+}
+
+func (c *fakeChain) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	return c.receipts[hash]
+}
+
+// serve serves the chain over http and counts the requests.
+func serve(t *testing.T, chain *fakeChain) (string, *int) {
+	t.Helper()
+	server := rpc.NewServer()
+	assert.NilError(t, server.RegisterName("eth", chain))
+	requests := 0
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(httpServer.Close)
+	t.Cleanup(server.Stop)
+	return httpServer.URL, &requests
+}
+
+func newFakeChain(t *testing.T) (*fakeChain, types.Log) {
+	t.Helper()
+	privKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	contract := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	signer := types.HomesteadSigner{}
+
+	chain := &fakeChain{receipts: map[common.Hash]*types.Receipt{}}
+	receipts := types.Receipts{}
+	for i := 0; i < 3; i++ {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), contract, big.NewInt(0), 50000, big.NewInt(1), nil), signer, privKey)
+		assert.NilError(t, err)
+		receipt := &types.Receipt{
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(i+1) * 30000,
+			TxHash:            tx.Hash(),
+			GasUsed:           30000,
+			TransactionIndex:  uint(i),
+			Logs: []*types.Log{{
+				Address:     contract,
+				Topics:      []common.Hash{crypto.Keccak256Hash([]byte("NewConfig(uint64)"))},
+				Data:        []byte{byte(i)},
+				BlockNumber: 10,
+				TxHash:      tx.Hash(),
+				TxIndex:     uint(i),
+				Index:       uint(i),
+			}},
+		}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		chain.txs = append(chain.txs, tx)
+		receipts = append(receipts, receipt)
+		chain.receipts[tx.Hash()] = receipt
+	}
+	chain.header = &types.Header{
+		ParentHash:  common.HexToHash("0x01"),
+		UncleHash:   types.EmptyUncleHash,
+		Root:        common.HexToHash("0x02"),
+		TxHash:      types.DeriveSha(chain.txs, trie.NewStackTrie(nil)),
+		ReceiptHash: types.DeriveSha(receipts, trie.NewStackTrie(nil)),
+		Bloom:       types.CreateBloom(receipts),
+		Difficulty:  big.NewInt(0),
+		Number:      big.NewInt(10),
+		GasLimit:    30000000,
+		GasUsed:     90000,
+		Time:        1000,
+		Extra:       []byte{},
+	}
+	blockHash := chain.header.Hash()
+	for _, receipt := range receipts {
+		receipt.BlockHash = blockHash
+		receipt.BlockNumber = chain.header.Number
+		receipt.Logs[0].BlockHash = blockHash
+	}
+	return chain, *receipts[1].Logs[0]
+}
+
+func TestVerifyLog(t *testing.T) {
+	ctx := context.Background()
+	chain, log := newFakeChain(t)
+	url, requests := serve(t, chain)
+	witnessURL, _ := serve(t, chain)
+	client, err := ethclient.Dial(url)
+	assert.NilError(t, err)
+	verifier, err := NewLogVerifier(client, []string{witnessURL})
+	assert.NilError(t, err)
+
+	assert.NilError(t, verifier.VerifyLog(ctx, log))
+	// the block and all receipts, regardless of the number of transactions
+	assert.Equal(t, *requests, 2)
+
+	forged := log
+	forged.Data = []byte{0xff}
+	assert.Assert(t, errors.Is(verifier.VerifyLog(ctx, forged), ErrUnverifiedLog))
+
+	forged = log
+	forged.TxHash = chain.txs[0].Hash()
+	err = verifier.VerifyLog(ctx, forged)
+	assert.Assert(t, errors.Is(err, ErrUnverifiedLog))
+	assert.ErrorContains(t, err, "log claims transaction")
+
+	forged = log
+	forged.Removed = true
+	assert.Assert(t, errors.Is(verifier.VerifyLog(ctx, forged), ErrUnverifiedLog))
+}
+
+func TestVerifyLogForgedReceipt(t *testing.T) {
+	ctx := context.Background()
+	chain, log := newFakeChain(t)
+	witnessURL, _ := serve(t, chain)
+
+	// the endpoint makes up a log and serves a receipt containing it
+	forged := log
+	forged.Data = []byte{0xff}
+	receipt := *chain.receipts[log.TxHash]
+	receipt.Logs = []*types.Log{&forged}
+	malicious := &fakeChain{header: chain.header, txs: chain.txs, receipts: map[common.Hash]*types.Receipt{}}
+	for hash, r := range chain.receipts {
+		malicious.receipts[hash] = r
+	}
+	malicious.receipts[log.TxHash] = &receipt
+	url, _ := serve(t, malicious)
+
+	client, err := ethclient.Dial(url)
+	assert.NilError(t, err)
+	verifier, err := NewLogVerifier(client, []string{witnessURL})
+	assert.NilError(t, err)
+	err = verifier.VerifyLog(ctx, forged)
+	assert.Assert(t, errors.Is(err, ErrUnverifiedLog))
+	assert.ErrorContains(t, err, "receipts of block")
+}
+
+func TestVerifyLogWitnessDisagrees(t *testing.T) {
+	ctx := context.Background()
+	chain, log := newFakeChain(t)
+	url, _ := serve(t, chain)
+
+	fork := *chain
+	forkHeader := *chain.header
+	forkHeader.Time++
+	fork.header = &forkHeader
+	witnessURL, _ := serve(t, &fork)
+
+	client, err := ethclient.Dial(url)
+	assert.NilError(t, err)
+	verifier, err := NewLogVerifier(client, []string{witnessURL})
+	assert.NilError(t, err)
+	assert.Assert(t, errors.Is(verifier.VerifyLog(ctx, log), ErrUnverifiedLog))
+}
+
+func TestVerifyEvent(t *testing.T) {
+	ctx := context.Background()
+	chain, log := newFakeChain(t)
+	url, _ := serve(t, chain)
+	witnessURL, _ := serve(t, chain)
+	client, err := ethclient.Dial(url)
+	assert.NilError(t, err)
+	verifier, err := NewLogVerifier(client, []string{witnessURL})
+	assert.NilError(t, err)
+
+	forged := log
+	forged.Data = []byte{0xff}
+	testCases := []struct {
+		name       string
+		event      interface{}
+		unverified bool
+		err        string
+	}{
+		{name: "event", event: DynamicEvent{Raw: log}},
+		{name: "event pointer", event: &DynamicEvent{Raw: log}},
+		{name: "forged event", event: DynamicEvent{Raw: forged}, unverified: true},
+		{name: "no raw log", event: struct{ Name string }{}, err: "has no raw log"},
+		{name: "raw log of wrong type", event: struct{ Raw string }{}, err: "unexpected type"},
+		{name: "not a struct", event: 42, err: "has no raw log"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifier.VerifyEvent(ctx, tc.event)
+			switch {
+			case tc.unverified:
+				assert.Assert(t, errors.Is(err, ErrUnverifiedLog), err)
+			case tc.err != "":
+				assert.ErrorContains(t, err, tc.err)
+			default:
+				assert.NilError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewLogVerifier(t *testing.T) {
+	_, err := NewLogVerifier(nil, nil)
+	assert.ErrorContains(t, err, "no witness endpoints")
+	_, err = NewLogVerifier(nil, []string{"ftp://witness"})
+	assert.ErrorContains(t, err, "failed to connect to witness endpoint")
+}
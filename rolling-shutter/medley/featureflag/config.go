@@ -0,0 +1,59 @@
+package featureflag
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+// Config enables and disables features of a node. Features not listed keep their default.
+type Config struct {
+	Enabled  []string `comment:"Features to enable, e.g. experimental ones"`
+	Disabled []string `comment:"Features to disable, even if they are enabled by default"`
+}
+
+func (c *Config) Init() {}
+
+func (c *Config) Name() string {
+	return "features"
+}
+
+func (c *Config) Validate() error {
+	enabled := map[string]bool{}
+	for _, name := range c.Enabled {
+		if name == "" {
+			return errors.New("empty feature name configured")
+		}
+		enabled[name] = true
+	}
+	for _, name := range c.Disabled {
+		if enabled[name] {
+			return errors.Errorf("feature %q is both enabled and disabled", name)
+		}
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.Enabled = []string{}
+	c.Disabled = []string{}
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c *Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
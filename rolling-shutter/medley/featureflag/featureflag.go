@@ -0,0 +1,136 @@
+// Package featureflag gates subsystems of a node behind flags, so that operators can enable
+// experimental features gradually across a keyper set. Flags are set in the config and can be
+// overridden at runtime by admins via the HTTP API.
+package featureflag
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Flag declares a feature of a service.
+type Flag struct {
+	Name        string
+	Description string
+	Default     bool
+}
+
+// Status is the state of a flag as reported by the API.
+type Status struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Configured  bool   `json:"configured"`
+	Override    *bool  `json:"override,omitempty"`
+}
+
+type flagState struct {
+	flag       Flag
+	configured bool
+	override   *bool
+}
+
+func (f *flagState) enabled() bool {
+	if f.override != nil {
+		return *f.override
+	}
+	return f.configured
+}
+
+// Set holds the flags of a service. It is safe for concurrent use.
+type Set struct {
+	service string
+
+	mu    sync.RWMutex
+	flags map[string]*flagState
+}
+
+// New creates the flag set of the given service from the config. It fails if the config refers
+// to unknown flags.
+func New(service string, config *Config, flags ...Flag) (*Set, error) {
+	s := &Set{
+		service: service,
+		flags:   make(map[string]*flagState),
+	}
+	for _, flag := range flags {
+		if _, ok := s.flags[flag.Name]; ok {
+			return nil, errors.Errorf("duplicate feature flag %q", flag.Name)
+		}
+		s.flags[flag.Name] = &flagState{flag: flag, configured: flag.Default}
+	}
+	for _, names := range []struct {
+		names   []string
+		enabled bool
+	}{{config.Enabled, true}, {config.Disabled, false}} {
+		for _, name := range names.names {
+			f, ok := s.flags[name]
+			if !ok {
+				return nil, errors.Errorf("unknown feature %q for %s", name, service)
+			}
+			f.configured = names.enabled
+		}
+	}
+	for _, f := range s.flags {
+		updateMetrics(service, f)
+		if f.enabled() {
+			log.Info().Str("feature", f.flag.Name).Msg("feature enabled")
+		}
+	}
+	return s, nil
+}
+
+// Enabled checks if the given feature is enabled. Unknown features are disabled.
+func (s *Set) Enabled(name string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, ok := s.flags[name]
+	if !ok {
+		log.Warn().Str("feature", name).Msg("checked unknown feature")
+		return false
+	}
+	return f.enabled()
+}
+
+// Override overrides the configured value of the given feature until the node is restarted. A
+// nil value removes the override.
+func (s *Set) Override(name string, enabled *bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.flags[name]
+	if !ok {
+		return errors.Errorf("unknown feature %q", name)
+	}
+	if enabled != nil {
+		v := *enabled
+		enabled = &v
+	}
+	f.override = enabled
+	updateMetrics(s.service, f)
+	log.Info().Str("feature", name).Bool("enabled", f.enabled()).Bool("override", enabled != nil).
+		Msg("feature flag overridden")
+	return nil
+}
+
+// Status returns the state of all flags, sorted by name.
+func (s *Set) Status() []Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]Status, 0, len(s.flags))
+	for _, f := range s.flags {
+		res = append(res, Status{
+			Name:        f.flag.Name,
+			Description: f.flag.Description,
+			Enabled:     f.enabled(),
+			Configured:  f.configured,
+			Override:    f.override,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
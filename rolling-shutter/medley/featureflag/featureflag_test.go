@@ -0,0 +1,39 @@
+package featureflag
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSet(t *testing.T) {
+	flags := []Flag{
+		{Name: "a", Default: false},
+		{Name: "b", Default: true},
+		{Name: "c", Default: true},
+	}
+	s, err := New("test", &Config{Enabled: []string{"a"}, Disabled: []string{"c"}}, flags...)
+	assert.NilError(t, err)
+	assert.Check(t, s.Enabled("a"))
+	assert.Check(t, s.Enabled("b"))
+	assert.Check(t, !s.Enabled("c"))
+	assert.Check(t, !s.Enabled("unknown"))
+
+	enabled := true
+	assert.NilError(t, s.Override("c", &enabled))
+	enabled = false
+	assert.Check(t, s.Enabled("c"))
+	status := s.Status()
+	assert.Equal(t, len(status), 3)
+	assert.Equal(t, status[2].Name, "c")
+	assert.Check(t, status[2].Enabled)
+	assert.Check(t, !status[2].Configured)
+
+	assert.NilError(t, s.Override("c", nil))
+	assert.Check(t, !s.Enabled("c"))
+	assert.ErrorContains(t, s.Override("unknown", nil), "unknown feature")
+
+	_, err = New("test", &Config{Enabled: []string{"d"}}, flags...)
+	assert.ErrorContains(t, err, "unknown feature")
+	assert.ErrorContains(t, (&Config{Enabled: []string{"a"}, Disabled: []string{"a"}}).Validate(), "both")
+}
@@ -0,0 +1,47 @@
+package featureflag
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/httpauth"
+)
+
+// OverrideRequest is the body of a request overriding a flag.
+type OverrideRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// Router serves the flags of the set. Listing them requires read access, overriding them
+// requires the admin role.
+func (s *Set) Router() http.Handler {
+	router := chi.NewRouter()
+	router.Get("/", s.handleList)
+	router.With(httpauth.RequireRole(httpauth.RoleAdmin)).Put("/{name}", s.handleOverride)
+	router.With(httpauth.RequireRole(httpauth.RoleAdmin)).Delete("/{name}", s.handleOverride)
+	return router
+}
+
+func (s *Set) handleList(w http.ResponseWriter, _ *http.Request) {
+	errcode.WriteJSON(w, http.StatusOK, s.Status())
+}
+
+func (s *Set) handleOverride(w http.ResponseWriter, r *http.Request) {
+	var enabled *bool
+	if r.Method == http.MethodPut {
+		req := OverrideRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errcode.SendError(w, errcode.ErrInvalidRequest.Wrapf(err, "invalid request body"))
+			return
+		}
+		enabled = &req.Enabled
+	}
+	if err := s.Override(chi.URLParam(r, "name"), enabled); err != nil {
+		errcode.SendError(w, errcode.ErrFeatureUnknown.Wrap(err))
+		return
+	}
+	errcode.WriteJSON(w, http.StatusOK, s.Status())
+}
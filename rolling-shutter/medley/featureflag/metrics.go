@@ -0,0 +1,25 @@
+package featureflag
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsFeatureEnabled = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "feature",
+		Name:      "enabled",
+		Help:      "Whether the feature is enabled (1) or disabled (0)",
+	},
+	[]string{"service", "feature"},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsFeatureEnabled)
+}
+
+func updateMetrics(service string, f *flagState) {
+	v := 0.0
+	if f.enabled() {
+		v = 1
+	}
+	metricsFeatureEnabled.WithLabelValues(service, f.flag.Name).Set(v)
+}
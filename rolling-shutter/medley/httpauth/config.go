@@ -0,0 +1,77 @@
+package httpauth
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+// Config configures the authentication of clients of a HTTP API. Clients either authenticate
+// with a bearer token in the Authorization header, or - if the server requires client
+// certificates - with the common name of their certificate.
+type Config struct {
+	Enabled bool
+
+	ReadOnlyTokens []string `comment:"Bearer tokens granting read-only access"`
+	OperatorTokens []string `comment:"Bearer tokens granting operator access"`
+	AdminTokens    []string `comment:"Bearer tokens granting admin access"`
+
+	ReadOnlyCommonNames []string `comment:"Client certificate common names granting read-only access"`
+	OperatorCommonNames []string `comment:"Client certificate common names granting operator access"`
+	AdminCommonNames    []string `comment:"Client certificate common names granting admin access"`
+}
+
+func (c *Config) Init() {}
+
+func (c *Config) Name() string {
+	return "httpauth"
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	seen := map[string]bool{}
+	for _, tokens := range [][]string{c.ReadOnlyTokens, c.OperatorTokens, c.AdminTokens} {
+		for _, token := range tokens {
+			if token == "" {
+				return errors.New("empty bearer token configured")
+			}
+			if seen[token] {
+				return errors.New("bearer token configured for multiple roles")
+			}
+			seen[token] = true
+		}
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.Enabled = false
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	c.Enabled = false
+	c.ReadOnlyTokens = []string{"change-me-readonly"}
+	c.OperatorTokens = []string{"change-me-operator"}
+	c.AdminTokens = []string{"change-me-admin"}
+	c.ReadOnlyCommonNames = []string{"monitoring"}
+	c.OperatorCommonNames = []string{"operator"}
+	c.AdminCommonNames = []string{"admin"}
+	return nil
+}
+
+func (c *Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
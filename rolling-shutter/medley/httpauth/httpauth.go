@@ -0,0 +1,163 @@
+// Package httpauth implements role based access control for the HTTP APIs of the nodes.
+package httpauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+)
+
+// Role is the access level granted to an authenticated client. Roles are ordered, a client with
+// a higher role is allowed to do everything a client with a lower role is allowed to do.
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleReadOnly
+	RoleOperator
+	RoleAdmin
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleNone:
+		return "none"
+	case RoleReadOnly:
+		return "read-only"
+	case RoleOperator:
+		return "operator"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "unknown"
+	}
+}
+
+type contextKey struct{}
+
+// RoleFromContext returns the role of the client that sent the request the context belongs to.
+func RoleFromContext(ctx context.Context) Role {
+	role, ok := ctx.Value(contextKey{}).(Role)
+	if !ok {
+		return RoleNone
+	}
+	return role
+}
+
+// Authenticator resolves the role of HTTP clients according to the configured credentials.
+type Authenticator struct {
+	config *Config
+}
+
+func New(config *Config) *Authenticator {
+	return &Authenticator{config: config}
+}
+
+func matchToken(token string, tokens []string) bool {
+	found := false
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			found = true
+		}
+	}
+	return found
+}
+
+func matchName(name string, names []string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the role of the client that sent the given request. If authentication is
+// disabled, every client is an admin.
+func (a *Authenticator) Resolve(r *http.Request) Role {
+	if a.config == nil || !a.config.Enabled {
+		return RoleAdmin
+	}
+
+	role := RoleNone
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		switch {
+		case matchToken(token, a.config.AdminTokens):
+			role = RoleAdmin
+		case matchToken(token, a.config.OperatorTokens):
+			role = RoleOperator
+		case matchToken(token, a.config.ReadOnlyTokens):
+			role = RoleReadOnly
+		}
+	}
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		name := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		certRole := RoleNone
+		switch {
+		case matchName(name, a.config.AdminCommonNames):
+			certRole = RoleAdmin
+		case matchName(name, a.config.OperatorCommonNames):
+			certRole = RoleOperator
+		case matchName(name, a.config.ReadOnlyCommonNames):
+			certRole = RoleReadOnly
+		}
+		if certRole > role {
+			role = certRole
+		}
+	}
+	return role
+}
+
+// Middleware resolves the role of the client and stores it in the request's context. Requests of
+// clients that could not be authenticated are rejected.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role := a.Resolve(r)
+		if role == RoleNone {
+			errcode.SendError(w, errcode.ErrUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, role)))
+	})
+}
+
+// RequireRole returns a middleware that only passes on requests of clients with at least the
+// given role. It must be used after Authenticator.Middleware.
+func RequireRole(required Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role := RoleFromContext(r.Context())
+			if role < required {
+				log.Info().Str("path", r.URL.Path).Str("role", role.String()).
+					Str("required-role", required.String()).Msg("rejecting unauthorized request")
+				errcode.SendError(w, errcode.ErrForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRoleByMethod returns a middleware that allows read-only clients to use safe HTTP methods
+// and requires the operator role for everything else.
+func RequireRoleByMethod() func(http.Handler) http.Handler {
+	readOnly := RequireRole(RoleReadOnly)
+	operator := RequireRole(RoleOperator)
+	return func(next http.Handler) http.Handler {
+		readOnlyNext := readOnly(next)
+		operatorNext := operator(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				readOnlyNext.ServeHTTP(w, r)
+			default:
+				operatorNext.ServeHTTP(w, r)
+			}
+		})
+	}
+}
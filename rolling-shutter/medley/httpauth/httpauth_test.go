@@ -0,0 +1,76 @@
+package httpauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func testConfig() *Config {
+	return &Config{
+		Enabled:        true,
+		ReadOnlyTokens: []string{"ro"},
+		OperatorTokens: []string{"op"},
+		AdminTokens:    []string{"adm"},
+	}
+}
+
+func TestResolveDisabled(t *testing.T) {
+	auth := New(&Config{Enabled: false})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Equal(t, auth.Resolve(req), RoleAdmin)
+}
+
+func TestResolveTokens(t *testing.T) {
+	auth := New(testConfig())
+	for token, role := range map[string]Role{
+		"":      RoleNone,
+		"wrong": RoleNone,
+		"ro":    RoleReadOnly,
+		"op":    RoleOperator,
+		"adm":   RoleAdmin,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		assert.Equal(t, auth.Resolve(req), role, "token %q", token)
+	}
+}
+
+func TestRequireRoleByMethod(t *testing.T) {
+	auth := New(testConfig())
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := auth.Middleware(RequireRoleByMethod()(ok))
+
+	tests := []struct {
+		method string
+		token  string
+		code   int
+	}{
+		{http.MethodGet, "", http.StatusUnauthorized},
+		{http.MethodGet, "ro", http.StatusOK},
+		{http.MethodPost, "ro", http.StatusForbidden},
+		{http.MethodPost, "op", http.StatusOK},
+		{http.MethodPost, "adm", http.StatusOK},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(tc.method, "/", nil)
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, rec.Code, tc.code, "%s with token %q", tc.method, tc.token)
+	}
+}
+
+func TestValidateRejectsDuplicateTokens(t *testing.T) {
+	cfg := testConfig()
+	cfg.AdminTokens = append(cfg.AdminTokens, "ro")
+	assert.ErrorContains(t, cfg.Validate(), "multiple roles")
+}
@@ -0,0 +1,198 @@
+// Package jobqueue runs side effects that have to be retried until they succeed, e.g. webhook
+// notifications or submissions to other chains. Jobs are persisted in the database, so they
+// survive restarts, and should be enqueued in the same transaction as the state change they
+// result from, so that neither happens without the other.
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/jobdb"
+)
+
+const (
+	// jobTimeout is the time a handler has to finish a job. If the worker dies in the meantime,
+	// the job is run again once the timeout has passed.
+	jobTimeout   = 5 * time.Minute
+	pollInterval = time.Second
+	minBackoff   = time.Second
+	maxBackoff   = time.Hour
+	// defaultMaxAttempts is the number of failed attempts after which a job becomes a dead letter.
+	// With the backoff above, it is retried for about a day.
+	defaultMaxAttempts = 30
+)
+
+// Handler performs a job given its payload. If it returns an error, the job is retried with
+// exponential backoff, unless the error is marked as permanent.
+type Handler func(ctx context.Context, payload []byte) error
+
+type permanentError struct {
+	error
+}
+
+func (e permanentError) Unwrap() error { return e.error }
+
+// Permanent marks an error as not worth retrying. Jobs failing with it become dead letters
+// immediately.
+func Permanent(err error) error {
+	return permanentError{err}
+}
+
+// Queue runs the jobs stored in the database with the handlers registered for their kinds.
+type Queue struct {
+	dbpool      *pgxpool.Pool
+	workers     int
+	maxAttempts int32
+
+	mux      sync.Mutex
+	handlers map[string]Handler
+}
+
+func New(dbpool *pgxpool.Pool, workers int) *Queue {
+	return &Queue{
+		dbpool:      dbpool,
+		workers:     workers,
+		maxAttempts: defaultMaxAttempts,
+		handlers:    map[string]Handler{},
+	}
+}
+
+// Register sets the handler for the jobs of the given kind. Every kind must be registered once,
+// before Run is called.
+func (q *Queue) Register(kind string, handler Handler) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	if _, ok := q.handlers[kind]; ok {
+		panic("job handler registered twice for kind " + kind)
+	}
+	q.handlers[kind] = handler
+}
+
+func (q *Queue) handler(kind string) (Handler, bool) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	h, ok := q.handlers[kind]
+	return h, ok
+}
+
+// Enqueue stores a job with the JSON encoded payload. Pass the transaction of the state change
+// resulting in the job as db.
+func Enqueue(ctx context.Context, db jobdb.DBTX, kind string, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode payload of %s job", kind)
+	}
+	id, err := jobdb.New(db).InsertJob(ctx, jobdb.InsertJobParams{Kind: kind, Payload: b})
+	if err != nil {
+		return errors.Wrapf(err, "failed to enqueue %s job", kind)
+	}
+	log.Debug().Int64("job-id", id).Str("kind", kind).Msg("enqueued job")
+	return nil
+}
+
+// EnqueueAt is like Enqueue, but the job isn't run before runAt.
+func EnqueueAt(ctx context.Context, db jobdb.DBTX, kind string, payload any, runAt time.Time) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode payload of %s job", kind)
+	}
+	id, err := jobdb.New(db).InsertScheduledJob(ctx, jobdb.InsertScheduledJobParams{Kind: kind, Payload: b, RunAt: runAt})
+	if err != nil {
+		return errors.Wrapf(err, "failed to enqueue %s job", kind)
+	}
+	log.Debug().Int64("job-id", id).Str("kind", kind).Time("run-at", runAt).Msg("enqueued job")
+	return nil
+}
+
+// Run runs due jobs until the context is canceled.
+func (q *Queue) Run(ctx context.Context) error {
+	group, ctx := errgroup.WithContext(ctx)
+	for i := 0; i < q.workers; i++ {
+		group.Go(func() error {
+			return q.work(ctx)
+		})
+	}
+	return group.Wait()
+}
+
+func (q *Queue) work(ctx context.Context) error {
+	for {
+		ran, err := q.runNext(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Warn().Err(err).Msg("failed to run job")
+		}
+		if ran {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// runNext runs the next due job, if there is one, and records the outcome.
+func (q *Queue) runNext(ctx context.Context) (bool, error) {
+	db := jobdb.New(q.dbpool)
+	now := time.Now()
+	job, err := db.ClaimDueJob(ctx, jobdb.ClaimDueJobParams{LeaseUntil: now.Add(jobTimeout), Now: now})
+	if err == pgx.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to claim job")
+	}
+	logger := log.With().Int64("job-id", job.ID).Str("kind", job.Kind).Int32("attempt", job.Attempts).Logger()
+
+	handler, ok := q.handler(job.Kind)
+	if !ok {
+		err = Permanent(errors.Errorf("no handler registered for %s jobs", job.Kind))
+	} else {
+		jobCtx, cancel := context.WithTimeout(ctx, jobTimeout)
+		err = handler(jobCtx, job.Payload)
+		cancel()
+	}
+	if err == nil {
+		metricsJobsDone.WithLabelValues(job.Kind).Inc()
+		logger.Debug().Msg("job done")
+		return true, db.DeleteJob(ctx, job.ID)
+	}
+	if ctx.Err() != nil {
+		// the job will be run again once the lease ends
+		return true, ctx.Err()
+	}
+
+	metricsJobsFailed.WithLabelValues(job.Kind).Inc()
+	if errors.As(err, &permanentError{}) || job.Attempts >= q.maxAttempts {
+		metricsJobsDead.WithLabelValues(job.Kind).Inc()
+		logger.Error().Err(err).Msg("job failed, giving up")
+		return true, db.MarkJobDead(ctx, jobdb.MarkJobDeadParams{ID: job.ID, LastError: err.Error()})
+	}
+	runAt := time.Now().Add(backoff(job.Attempts))
+	logger.Warn().Err(err).Time("retry-at", runAt).Msg("job failed, retrying")
+	return true, db.RescheduleJob(ctx, jobdb.RescheduleJobParams{ID: job.ID, RunAt: runAt, LastError: err.Error()})
+}
+
+// backoff returns the delay before the next attempt after the given number of failed attempts.
+func backoff(attempts int32) time.Duration {
+	d := minBackoff
+	for i := int32(1); i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
@@ -0,0 +1,113 @@
+package jobqueue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/jobdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+)
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, backoff(1), minBackoff)
+	assert.Equal(t, backoff(2), 2*minBackoff)
+	assert.Equal(t, backoff(5), 16*minBackoff)
+	assert.Equal(t, backoff(defaultMaxAttempts), maxBackoff)
+}
+
+func TestQueueIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	q := New(dbpool, 1)
+	q.maxAttempts = 2
+	var received []string
+	q.Register("ok", func(_ context.Context, payload []byte) error {
+		received = append(received, string(payload))
+		return nil
+	})
+	q.Register("flaky", func(context.Context, []byte) error {
+		return errors.New("unavailable")
+	})
+	q.Register("broken", func(context.Context, []byte) error {
+		return Permanent(errors.New("invalid"))
+	})
+
+	tx, err := dbpool.Begin(ctx)
+	assert.NilError(t, err)
+	assert.NilError(t, Enqueue(ctx, tx, "ok", "rolled back"))
+	assert.NilError(t, tx.Rollback(ctx))
+	for _, kind := range []string{"ok", "flaky", "broken"} {
+		assert.NilError(t, Enqueue(ctx, dbpool, kind, kind))
+	}
+
+	for i := 0; i < 3; i++ {
+		ran, err := q.runNext(ctx)
+		assert.NilError(t, err)
+		assert.Assert(t, ran)
+	}
+	ran, err := q.runNext(ctx)
+	assert.NilError(t, err)
+	assert.Assert(t, !ran, "failed job must be retried after a backoff")
+	assert.DeepEqual(t, received, []string{`"ok"`})
+
+	db := jobdb.New(dbpool)
+	dead, err := db.GetDeadJobs(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(dead), 1)
+	assert.Equal(t, dead[0].Kind, "broken")
+	assert.Equal(t, dead[0].LastError, "invalid")
+
+	// make the flaky job due again, so that it exceeds the maximum number of attempts
+	_, err = dbpool.Exec(ctx, "UPDATE job SET run_at = now() WHERE kind = 'flaky'")
+	assert.NilError(t, err)
+	ran, err = q.runNext(ctx)
+	assert.NilError(t, err)
+	assert.Assert(t, ran)
+	dead, err = db.GetDeadJobs(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(dead), 2)
+	assert.Equal(t, dead[1].Kind, "flaky")
+	assert.Equal(t, dead[1].Attempts, int32(2))
+
+	n, err := db.RetryDeadJob(ctx, dead[0].ID)
+	assert.NilError(t, err)
+	assert.Equal(t, n, int64(1))
+	dead, err = db.GetDeadJobs(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(dead), 1)
+}
+
+func TestEnqueueAtIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	q := New(dbpool, 1)
+	var received []string
+	q.Register("scheduled", func(_ context.Context, payload []byte) error {
+		received = append(received, string(payload))
+		return nil
+	})
+
+	assert.NilError(t, EnqueueAt(ctx, dbpool, "scheduled", "later", time.Now().Add(time.Hour)))
+	assert.NilError(t, EnqueueAt(ctx, dbpool, "scheduled", "now", time.Now().Add(-time.Second)))
+	ran, err := q.runNext(ctx)
+	assert.NilError(t, err)
+	assert.Assert(t, ran)
+	ran, err = q.runNext(ctx)
+	assert.NilError(t, err)
+	assert.Assert(t, !ran, "job must not run before it is due")
+	assert.DeepEqual(t, received, []string{`"now"`})
+}
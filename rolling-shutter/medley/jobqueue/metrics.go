@@ -0,0 +1,39 @@
+package jobqueue
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsJobsDone = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "jobqueue",
+		Name:      "jobs_done_total",
+		Help:      "Number of jobs that have been run successfully",
+	},
+	[]string{"kind"},
+)
+
+var metricsJobsFailed = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "jobqueue",
+		Name:      "job_failures_total",
+		Help:      "Number of failed attempts to run a job",
+	},
+	[]string{"kind"},
+)
+
+var metricsJobsDead = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "jobqueue",
+		Name:      "dead_jobs_total",
+		Help:      "Number of jobs given up on after failing permanently or too often",
+	},
+	[]string{"kind"},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsJobsDone)
+	prometheus.MustRegister(metricsJobsFailed)
+	prometheus.MustRegister(metricsJobsDead)
+}
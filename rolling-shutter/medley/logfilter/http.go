@@ -0,0 +1,47 @@
+package logfilter
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/httpauth"
+)
+
+// Router serves the configuration of the filter. Reading it requires read access, changing it
+// requires the admin role.
+func (f *Filter) Router() http.Handler {
+	router := chi.NewRouter()
+	router.Get("/", f.handleGet)
+	router.With(httpauth.RequireRole(httpauth.RoleAdmin)).Put("/", f.handleSet)
+	router.With(httpauth.RequireRole(httpauth.RoleAdmin)).Delete("/", f.handleReset)
+	return router
+}
+
+func (f *Filter) handleGet(w http.ResponseWriter, _ *http.Request) {
+	errcode.WriteJSON(w, http.StatusOK, f.Config())
+}
+
+func (f *Filter) handleSet(w http.ResponseWriter, r *http.Request) {
+	config := Config{}
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		errcode.SendError(w, errcode.ErrInvalidRequest.Wrapf(err, "invalid request body"))
+		return
+	}
+	if err := f.Set(config); err != nil {
+		errcode.SendError(w, errcode.ErrInvalidRequest.Wrap(err))
+		return
+	}
+	config = f.Config()
+	log.Warn().Str("level", config.Level).Interface("modules", config.Modules).Msg("log levels changed")
+	errcode.WriteJSON(w, http.StatusOK, config)
+}
+
+func (f *Filter) handleReset(w http.ResponseWriter, _ *http.Request) {
+	f.Reset()
+	log.Warn().Str("level", f.Config().Level).Msg("log levels reset")
+	errcode.WriteJSON(w, http.StatusOK, f.Config())
+}
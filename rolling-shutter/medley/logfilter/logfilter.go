@@ -0,0 +1,161 @@
+// Package logfilter allows changing the log level at runtime, both globally and for individual
+// modules. A module is a package of this repository given by its path relative to the repository
+// root, e.g. "p2p" or "medley/eventsyncer", and includes its subpackages.
+package logfilter
+
+import (
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const modulePrefix = "github.com/shutter-network/rolling-shutter/rolling-shutter/"
+
+// Config is the log level configuration. Levels are given by their zerolog names, e.g. "debug".
+type Config struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules,omitempty"`
+}
+
+// Filter is a zerolog hook that discards events below the level configured for the module they
+// are logged from.
+type Filter struct {
+	mu      sync.RWMutex
+	initial Config
+	level   zerolog.Level
+	modules map[string]zerolog.Level
+}
+
+// Default is the filter installed by Install.
+var Default = &Filter{level: zerolog.DebugLevel}
+
+// Install makes Default filter the events of the logger, starting with the current global level.
+// The current configuration is restored when the filter is reset.
+func Install(l zerolog.Logger) zerolog.Logger {
+	Default.mu.Lock()
+	Default.level = zerolog.GlobalLevel()
+	Default.modules = nil
+	Default.initial = Config{Level: Default.level.String()}
+	Default.mu.Unlock()
+	return l.Hook(Default)
+}
+
+// Config returns the active configuration.
+func (f *Filter) Config() Config {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	config := Config{Level: f.level.String()}
+	if len(f.modules) > 0 {
+		config.Modules = make(map[string]string, len(f.modules))
+		for module, level := range f.modules {
+			config.Modules[module] = level.String()
+		}
+	}
+	return config
+}
+
+// Set replaces the active configuration.
+func (f *Filter) Set(config Config) error {
+	level, err := zerolog.ParseLevel(config.Level)
+	if err != nil {
+		return errors.Wrapf(err, "invalid log level '%s'", config.Level)
+	}
+	modules := make(map[string]zerolog.Level, len(config.Modules))
+	for module, levelName := range config.Modules {
+		module = strings.Trim(module, "/")
+		if module == "" {
+			return errors.New("module name must not be empty")
+		}
+		moduleLevel, err := zerolog.ParseLevel(levelName)
+		if err != nil {
+			return errors.Wrapf(err, "invalid log level '%s' for module '%s'", levelName, module)
+		}
+		modules[module] = moduleLevel
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.level = level
+	f.modules = modules
+	// The global level decides which events are created at all, so it has to allow the most
+	// verbose level of any module. The hook discards the events other modules don't want.
+	minLevel := level
+	for _, moduleLevel := range modules {
+		if moduleLevel < minLevel {
+			minLevel = moduleLevel
+		}
+	}
+	zerolog.SetGlobalLevel(minLevel)
+	return nil
+}
+
+// Reset restores the configuration active when the filter was installed.
+func (f *Filter) Reset() {
+	f.mu.RLock()
+	initial := f.initial
+	f.mu.RUnlock()
+	_ = f.Set(initial)
+}
+
+// Run implements zerolog.Hook.
+func (f *Filter) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if level == zerolog.NoLevel {
+		return
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if len(f.modules) == 0 {
+		return // the global level already filtered the event
+	}
+	if level < f.levelOf(callerPackage()) {
+		e.Discard()
+	}
+}
+
+// levelOf returns the level of the most specific module containing pkg.
+func (f *Filter) levelOf(pkg string) zerolog.Level {
+	modules := make([]string, 0, len(f.modules))
+	for module := range f.modules {
+		if pkg == module || strings.HasPrefix(pkg, module+"/") {
+			modules = append(modules, module)
+		}
+	}
+	if len(modules) == 0 {
+		return f.level
+	}
+	sort.Slice(modules, func(i, j int) bool { return len(modules[i]) > len(modules[j]) })
+	return f.modules[modules[0]]
+}
+
+// callerPackage returns the path, relative to the repository root, of the package that logged the
+// event currently being handled.
+func callerPackage() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		pkg := packageOf(frame.Function)
+		if !strings.HasPrefix(pkg, "github.com/rs/zerolog") {
+			return strings.TrimPrefix(pkg, modulePrefix)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// packageOf returns the package path of a fully qualified function name as reported by the
+// runtime, e.g. "github.com/org/repo/pkg.(*T).Method.func1".
+func packageOf(function string) string {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return function
+	}
+	return function[:slash+1+dot]
+}
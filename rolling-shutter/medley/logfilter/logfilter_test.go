@@ -0,0 +1,43 @@
+package logfilter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"gotest.tools/v3/assert"
+)
+
+func TestPackageOf(t *testing.T) {
+	assert.Equal(t, packageOf("github.com/org/repo/p2p.(*P2PHandler).handle.func1"), "github.com/org/repo/p2p")
+	assert.Equal(t, packageOf("main.main"), "main")
+}
+
+func TestFilter(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+
+	f := &Filter{}
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf).Hook(f)
+
+	assert.NilError(t, f.Set(Config{Level: "warn"}))
+	logger.Debug().Msg("discarded")
+	assert.Equal(t, buf.Len(), 0)
+
+	assert.NilError(t, f.Set(Config{Level: "warn", Modules: map[string]string{"medley": "debug"}}))
+	assert.Equal(t, zerolog.GlobalLevel(), zerolog.DebugLevel)
+	logger.Debug().Msg("logged")
+	assert.Assert(t, bytes.Contains(buf.Bytes(), []byte("logged")))
+
+	buf.Reset()
+	assert.NilError(t, f.Set(Config{
+		Level:   "debug",
+		Modules: map[string]string{"medley": "debug", "medley/logfilter": "error"},
+	}))
+	logger.Warn().Msg("discarded")
+	assert.Equal(t, buf.Len(), 0)
+	assert.DeepEqual(t, f.Config().Modules, map[string]string{"medley": "debug", "medley/logfilter": "error"})
+
+	assert.ErrorContains(t, f.Set(Config{Level: "loud"}), "invalid log level")
+	assert.ErrorContains(t, f.Set(Config{Level: "info", Modules: map[string]string{"/": "debug"}}), "must not be empty")
+}
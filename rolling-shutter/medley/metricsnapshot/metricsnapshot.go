@@ -0,0 +1,113 @@
+// Package metricsnapshot periodically persists the values of a node's key metrics to its database,
+// so that incidents can be analyzed even if no monitoring system was scraping the node.
+package metricsnapshot
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/metricsdb"
+)
+
+// Sampler returns the current value of a metric.
+type Sampler func(ctx context.Context) (float64, error)
+
+// Snapshotter takes a snapshot of all metrics every interval and keeps the most recent snapshots.
+type Snapshotter struct {
+	dbpool   *pgxpool.Pool
+	interval time.Duration
+	keep     int32
+	samplers map[string]Sampler
+	allowed  func() bool
+}
+
+func New(dbpool *pgxpool.Pool, interval time.Duration, keep uint64) *Snapshotter {
+	if keep > math.MaxInt32 {
+		keep = math.MaxInt32
+	}
+	return &Snapshotter{
+		dbpool:   dbpool,
+		interval: interval,
+		keep:     int32(keep),
+		samplers: make(map[string]Sampler),
+	}
+}
+
+// Add registers the sampler of a metric. It must be called before Run.
+func (s *Snapshotter) Add(name string, sampler Sampler) {
+	s.samplers[name] = sampler
+}
+
+// PauseUnless makes the snapshotter skip snapshots while allowed returns false. It must be called
+// before Run.
+func (s *Snapshotter) PauseUnless(allowed func() bool) {
+	s.allowed = allowed
+}
+
+// Run takes snapshots until the context is canceled.
+func (s *Snapshotter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		if s.allowed != nil && !s.allowed() {
+			log.Debug().Msg("skipping metrics snapshot")
+			continue
+		}
+		if err := s.snapshot(ctx); err != nil {
+			log.Warn().Err(err).Msg("failed to persist metrics snapshot")
+		}
+	}
+}
+
+// snapshot samples all metrics and stores them under the same timestamp. Metrics that fail to be
+// sampled are left out, as a partial snapshot is more useful than none during an incident.
+func (s *Snapshotter) snapshot(ctx context.Context) error {
+	takenAt := time.Now()
+	names := make([]string, 0, len(s.samplers))
+	for name := range s.samplers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make(map[string]float64, len(names))
+	for _, name := range names {
+		value, err := s.samplers[name](ctx)
+		if err != nil {
+			log.Debug().Err(err).Str("metric", name).Msg("failed to sample metric")
+			continue
+		}
+		values[name] = value
+	}
+
+	return s.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		db := metricsdb.New(tx)
+		for _, name := range names {
+			value, ok := values[name]
+			if !ok {
+				continue
+			}
+			err := db.InsertMetricsSnapshotValue(ctx, metricsdb.InsertMetricsSnapshotValueParams{
+				TakenAt: takenAt,
+				Name:    name,
+				Value:   value,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to insert value of metric %s", name)
+			}
+		}
+		_, err := db.DeleteOldMetricsSnapshots(ctx, s.keep)
+		return errors.Wrap(err, "failed to delete old metrics snapshots")
+	})
+}
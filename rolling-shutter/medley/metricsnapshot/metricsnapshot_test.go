@@ -0,0 +1,43 @@
+package metricsnapshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/metricsdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+)
+
+func TestSnapshotIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	value := 0.0
+	snapshotter := New(dbpool, time.Minute, 2)
+	snapshotter.Add("counter", func(context.Context) (float64, error) {
+		value++
+		return value, nil
+	})
+	snapshotter.Add("broken", func(context.Context) (float64, error) {
+		return 0, errors.New("unavailable")
+	})
+	for i := 0; i < 3; i++ {
+		assert.NilError(t, snapshotter.snapshot(ctx))
+	}
+
+	rows, err := metricsdb.New(dbpool).FindMetricsSnapshots(ctx, time.Time{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(rows), 2)
+	for i, row := range rows {
+		assert.Equal(t, row.Name, "counter")
+		assert.Equal(t, row.Value, float64(i+2))
+	}
+}
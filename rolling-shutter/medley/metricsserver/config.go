@@ -4,6 +4,7 @@ import (
 	"io"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/tlsconfig"
 )
 
 var _ configuration.Config = &MetricsConfig{}
@@ -18,9 +19,11 @@ type MetricsConfig struct {
 	Enabled bool
 	Host    string
 	Port    uint16
+	TLS     *tlsconfig.ServerConfig
 }
 
 func (mc *MetricsConfig) Init() {
+	mc.TLS = tlsconfig.NewServerConfig()
 }
 
 func (mc *MetricsConfig) Name() string {
@@ -28,7 +31,7 @@ func (mc *MetricsConfig) Name() string {
 }
 
 func (mc *MetricsConfig) Validate() error {
-	return nil
+	return mc.TLS.Validate()
 }
 
 func (mc *MetricsConfig) SetDefaultValues() error {
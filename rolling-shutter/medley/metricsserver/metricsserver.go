@@ -34,7 +34,7 @@ func (srv *MetricsServer) Start(ctx context.Context, group service.Runner) error
 		}
 
 		log.Info().Str("address", addr).Msg("Running metrics server at")
-		if err := srv.httpServer.ListenAndServe(); err != http.ErrServerClosed {
+		if err := srv.config.TLS.ListenAndServe(srv.httpServer); err != http.ErrServerClosed {
 			return err
 		}
 		return nil
@@ -0,0 +1,75 @@
+package opapproval
+
+import (
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+// Config lists the operator keys that approve sensitive actions and the actions that require
+// their approval.
+type Config struct {
+	Threshold uint64   `comment:"Number of operator signatures a sensitive action requires, 0 disables approvals"`
+	Operators []string `comment:"Ethereum addresses of the operator keys allowed to approve actions"`
+	Actions   []string `comment:"Actions requiring approval, out of steal-lease, override-feature, set-log-filter, pause-key-generation and rebroadcast-keys"`
+}
+
+func (c *Config) Init() {}
+
+func (c *Config) Name() string {
+	return "operatorapproval"
+}
+
+func (c *Config) Validate() error {
+	if c.Threshold == 0 {
+		return nil
+	}
+	if c.Threshold > uint64(len(c.Operators)) {
+		return errors.Errorf("approval threshold %d exceeds the number of operators %d", c.Threshold, len(c.Operators))
+	}
+	seen := map[common.Address]bool{}
+	for _, operator := range c.Operators {
+		if !common.IsHexAddress(operator) {
+			return errors.Errorf("invalid operator address %q", operator)
+		}
+		address := common.HexToAddress(operator)
+		if seen[address] {
+			return errors.Errorf("operator %s configured multiple times", address.Hex())
+		}
+		seen[address] = true
+	}
+	for _, action := range c.Actions {
+		if !knownActions[action] {
+			return errors.Errorf("unknown action %q", action)
+		}
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.Threshold = 0
+	c.Operators = []string{}
+	c.Actions = []string{
+		ActionStealLease, ActionOverrideFeature, ActionSetLogFilter, ActionPauseKeyGeneration, ActionRebroadcastKeys,
+	}
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,92 @@
+package opapproval
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
+)
+
+const (
+	// SignatureHeader carries one operator signature. It is repeated for every signature.
+	SignatureHeader = "Shutter-Approval"
+	// NotAfterHeader carries the expiry time of the approvals as a unix timestamp.
+	NotAfterHeader = "Shutter-Approval-Not-After"
+)
+
+// maxBodySize is the maximum size of the body of an approved request.
+const maxBodySize = 1 << 20
+
+// HTTPAction describes executing an action by the given HTTP request. The body is included by its
+// hash.
+func HTTPAction(
+	name string, instanceID uint64, node common.Address, method, path string, body []byte, notAfter int64,
+) *Action {
+	return &Action{
+		Name:       name,
+		InstanceID: instanceID,
+		Node:       node,
+		Params: map[string]string{
+			"method":    method,
+			"path":      path,
+			"body-hash": hexutil.Encode(ethcrypto.Keccak256(body)),
+		},
+		NotAfter: notAfter,
+	}
+}
+
+// Middleware returns a middleware that rejects requests executing the named action unless they
+// carry the required approvals. Requests with safe HTTP methods don't change anything and are
+// passed on.
+func (v *Verifier) Middleware(name string, instanceID uint64, node common.Address) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !v.Required(name) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+			if err != nil {
+				errcode.SendError(w, errcode.ErrInvalidRequest.Wrapf(err, "failed to read request body"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			notAfter, err := strconv.ParseInt(r.Header.Get(NotAfterHeader), 10, 64)
+			if err != nil {
+				errcode.SendError(w, errcode.ErrForbidden.Errorf("missing or invalid %s header", NotAfterHeader))
+				return
+			}
+			var signatures []hexutil.Bytes
+			for _, s := range r.Header.Values(SignatureHeader) {
+				signature, err := hexutil.Decode(s)
+				if err != nil {
+					errcode.SendError(w, errcode.ErrForbidden.Errorf("invalid %s header", SignatureHeader))
+					return
+				}
+				signatures = append(signatures, signature)
+			}
+
+			action := HTTPAction(name, instanceID, node, r.Method, r.URL.Path, body, notAfter)
+			if err := v.Verify(action, signatures, time.Now()); err != nil {
+				log.Info().Err(err).Str("path", r.URL.Path).Msg("rejecting unapproved request")
+				errcode.SendError(w, errcode.ErrForbidden.Wrap(err))
+				return
+			}
+			log.Info().Str("action", name).Str("path", r.URL.Path).Msg("executing approved action")
+			next.ServeHTTP(w, r)
+		})
+	}
+}
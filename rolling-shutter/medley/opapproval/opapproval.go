@@ -0,0 +1,124 @@
+// Package opapproval lets node operators require that sensitive actions are approved by M of N
+// operator keys. Each operator signs a canonical description of the action, which binds the
+// signature to the action, the node and an expiry time, so that approvals can't be replayed for a
+// different action or node.
+package opapproval
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// Names of the actions that can be configured to require approval.
+const (
+	ActionStealLease         = "steal-lease"
+	ActionOverrideFeature    = "override-feature"
+	ActionSetLogFilter       = "set-log-filter"
+	ActionPauseKeyGeneration = "pause-key-generation"
+	ActionRebroadcastKeys    = "rebroadcast-keys"
+)
+
+var knownActions = map[string]bool{
+	ActionStealLease:         true,
+	ActionOverrideFeature:    true,
+	ActionSetLogFilter:       true,
+	ActionPauseKeyGeneration: true,
+	ActionRebroadcastKeys:    true,
+}
+
+// ErrNotApproved is returned if an action lacks the approvals it requires.
+var ErrNotApproved = errors.New("action not approved by enough operators")
+
+var hashPrefix = []byte("\x19Shutter operator action:\n")
+
+// Action describes a single execution of a sensitive action. Params holds the arguments of the
+// action, e.g. the HTTP request it is executed by.
+type Action struct {
+	Name       string            `json:"name"`
+	InstanceID uint64            `json:"instanceID"`
+	Node       common.Address    `json:"node"`
+	Params     map[string]string `json:"params,omitempty"`
+	NotAfter   int64             `json:"notAfter"`
+}
+
+// Payload returns the canonical encoding of the action operators sign. It's the JSON encoding of
+// the action with the fields in declaration order and the params sorted by key.
+func (a *Action) Payload() []byte {
+	payload, err := json.Marshal(a)
+	if err != nil {
+		panic(err) // an Action can always be encoded
+	}
+	return payload
+}
+
+// Hash returns the hash operators sign to approve the action.
+func (a *Action) Hash() []byte {
+	return ethcrypto.Keccak256(hashPrefix, a.Payload())
+}
+
+// Sign approves the action with the given operator key.
+func Sign(action *Action, key *ecdsa.PrivateKey) ([]byte, error) {
+	return ethcrypto.Sign(action.Hash(), key)
+}
+
+// Verifier checks the approvals of actions according to the config.
+type Verifier struct {
+	threshold int
+	operators map[common.Address]bool
+	actions   map[string]bool
+}
+
+// NewVerifier creates a verifier for a validated config.
+func NewVerifier(config *Config) *Verifier {
+	v := &Verifier{
+		threshold: int(config.Threshold),
+		operators: make(map[common.Address]bool),
+		actions:   make(map[string]bool),
+	}
+	for _, operator := range config.Operators {
+		v.operators[common.HexToAddress(operator)] = true
+	}
+	for _, action := range config.Actions {
+		v.actions[action] = true
+	}
+	return v
+}
+
+// Required reports whether the action with the given name requires approval.
+func (v *Verifier) Required(name string) bool {
+	return v != nil && v.threshold > 0 && v.actions[name]
+}
+
+// Verify checks that enough distinct operators signed the action, unless the action does not
+// require approval. Signatures of unknown keys are ignored.
+func (v *Verifier) Verify(action *Action, signatures []hexutil.Bytes, now time.Time) error {
+	if !v.Required(action.Name) {
+		return nil
+	}
+	if now.Unix() > action.NotAfter {
+		return errors.Wrapf(ErrNotApproved, "approval of %s expired at %s", action.Name, time.Unix(action.NotAfter, 0))
+	}
+	hash := action.Hash()
+	approvers := make(map[common.Address]bool)
+	for _, signature := range signatures {
+		pubkey, err := ethcrypto.SigToPub(hash, signature)
+		if err != nil {
+			continue
+		}
+		signer := ethcrypto.PubkeyToAddress(*pubkey)
+		if v.operators[signer] {
+			approvers[signer] = true
+		}
+	}
+	if len(approvers) < v.threshold {
+		return errors.Wrapf(ErrNotApproved, "%s approved by %d of %d required operators",
+			action.Name, len(approvers), v.threshold)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package opapproval
+
+import (
+	"crypto/ecdsa"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+)
+
+func newOperators(t *testing.T, n int) ([]*ecdsa.PrivateKey, []string) {
+	t.Helper()
+	var keys []*ecdsa.PrivateKey
+	var addresses []string
+	for i := 0; i < n; i++ {
+		key, err := ethcrypto.GenerateKey()
+		assert.NilError(t, err)
+		keys = append(keys, key)
+		addresses = append(addresses, ethcrypto.PubkeyToAddress(key.PublicKey).Hex())
+	}
+	return keys, addresses
+}
+
+func sign(t *testing.T, action *Action, keys ...*ecdsa.PrivateKey) []hexutil.Bytes {
+	t.Helper()
+	var signatures []hexutil.Bytes
+	for _, key := range keys {
+		signature, err := Sign(action, key)
+		assert.NilError(t, err)
+		signatures = append(signatures, signature)
+	}
+	return signatures
+}
+
+func TestVerify(t *testing.T) {
+	keys, operators := newOperators(t, 3)
+	outsider, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	config := &Config{Threshold: 2, Operators: operators, Actions: []string{ActionStealLease}}
+	assert.NilError(t, config.Validate())
+	v := NewVerifier(config)
+
+	now := time.Unix(1_700_000_000, 0)
+	action := &Action{
+		Name:       ActionStealLease,
+		InstanceID: 1,
+		Node:       common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		NotAfter:   now.Unix() + 60,
+	}
+	assert.NilError(t, v.Verify(action, sign(t, action, keys[0], keys[2]), now))
+	assert.Assert(t, errors.Is(v.Verify(action, sign(t, action, keys[0]), now), ErrNotApproved))
+	assert.Assert(t, errors.Is(v.Verify(action, sign(t, action, keys[0], keys[0]), now), ErrNotApproved))
+	assert.Assert(t, errors.Is(v.Verify(action, sign(t, action, keys[0], outsider), now), ErrNotApproved))
+	assert.Assert(t, errors.Is(
+		v.Verify(action, sign(t, action, keys[0], keys[1]), now.Add(2*time.Minute)), ErrNotApproved,
+	))
+
+	// approvals don't carry over to other nodes
+	signatures := sign(t, action, keys[0], keys[1])
+	other := *action
+	other.Node = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	assert.Assert(t, errors.Is(v.Verify(&other, signatures, now), ErrNotApproved))
+
+	// actions that are not configured don't require approval
+	assert.NilError(t, v.Verify(&Action{Name: ActionSetLogFilter}, nil, now))
+}
+
+func TestValidate(t *testing.T) {
+	_, operators := newOperators(t, 2)
+	assert.NilError(t, (&Config{}).Validate())
+	assert.ErrorContains(t, (&Config{Threshold: 3, Operators: operators}).Validate(), "exceeds")
+	assert.ErrorContains(t, (&Config{Threshold: 1, Operators: []string{"0x12"}}).Validate(), "invalid")
+	assert.ErrorContains(t, (&Config{Threshold: 1, Operators: append(operators, operators[0])}).Validate(), "multiple")
+	assert.ErrorContains(t, (&Config{Threshold: 1, Operators: operators, Actions: []string{"x"}}).Validate(), "unknown")
+}
+
+func TestMiddleware(t *testing.T) {
+	keys, operators := newOperators(t, 2)
+	v := NewVerifier(&Config{Threshold: 2, Operators: operators, Actions: []string{ActionOverrideFeature}})
+	node := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	handler := v.Middleware(ActionOverrideFeature, 1, node)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(method, body string, notAfter int64, signatures []hexutil.Bytes) int {
+		r := httptest.NewRequest(method, "/features/x", strings.NewReader(body))
+		r.Header.Set(NotAfterHeader, strconv.FormatInt(notAfter, 10))
+		for _, signature := range signatures {
+			r.Header.Add(SignatureHeader, signature.String())
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	notAfter := time.Now().Add(time.Minute).Unix()
+	action := HTTPAction(ActionOverrideFeature, 1, node, http.MethodPut, "/features/x", []byte("true"), notAfter)
+	signatures := sign(t, action, keys...)
+	assert.Equal(t, serve(http.MethodGet, "", 0, nil), http.StatusNoContent)
+	assert.Equal(t, serve(http.MethodPut, "true", notAfter, signatures), http.StatusNoContent)
+	assert.Equal(t, serve(http.MethodPut, "false", notAfter, signatures), http.StatusForbidden)
+	assert.Equal(t, serve(http.MethodDelete, "true", notAfter, signatures), http.StatusForbidden)
+	assert.Equal(t, serve(http.MethodPut, "true", notAfter, signatures[:1]), http.StatusForbidden)
+}
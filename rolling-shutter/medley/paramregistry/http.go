@@ -0,0 +1,64 @@
+package paramregistry
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Change is a value a protocol parameter has had.
+type Change struct {
+	Value       string    `json:"value"`
+	Source      string    `json:"source"`
+	BlockNumber int64     `json:"blockNumber"`
+	ChangedAt   time.Time `json:"changedAt"`
+}
+
+// Parameter is a protocol parameter with its current value and the history of its changes.
+type Parameter struct {
+	Name    string   `json:"name"`
+	Value   string   `json:"value"`
+	Source  string   `json:"source"`
+	History []Change `json:"history"`
+}
+
+// Handler serves the protocol parameters together with their history as JSON.
+func Handler(dbpool *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		registry := New(dbpool)
+		rows, err := registry.All(r.Context())
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get protocol parameters from db")
+			http.Error(w, "failed to get protocol parameters", http.StatusInternalServerError)
+			return
+		}
+		params := make([]Parameter, len(rows))
+		for i, row := range rows {
+			history, err := registry.History(r.Context(), row.Name)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to get protocol parameter history from db")
+				http.Error(w, "failed to get protocol parameter history", http.StatusInternalServerError)
+				return
+			}
+			params[i] = Parameter{
+				Name:    row.Name,
+				Value:   row.Value,
+				Source:  row.Source,
+				History: make([]Change, len(history)),
+			}
+			for j, change := range history {
+				params[i].History[j] = Change{
+					Value:       change.Value,
+					Source:      change.Source,
+					BlockNumber: change.BlockNumber,
+					ChangedAt:   change.ChangedAt.UTC(),
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(params)
+	}
+}
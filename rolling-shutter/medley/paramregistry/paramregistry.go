@@ -0,0 +1,171 @@
+// Package paramregistry gives access to the protocol parameters of a deployment, e.g. the finality
+// offset or the threshold of the keyper set. The parameters are stored in the database together
+// with a history of their changes. They are populated from built-in defaults, from the config at
+// startup and from contract events while they are synced.
+package paramregistry
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/paramdb"
+)
+
+// The names of the protocol parameters.
+const (
+	// FinalityOffset is the number of blocks contract events trail the head of the chain before
+	// they are synced, unless events are synced up to a checkpoint of the beacon chain.
+	FinalityOffset = "finality-offset"
+	// EpochDuration is the time between two batches of the collator.
+	EpochDuration = "epoch-duration"
+	// KeyperThreshold is the threshold of the most recently scheduled keyper set.
+	KeyperThreshold = "keyper-threshold"
+	// TriggerOffsetBlocks is the number of blocks decryption is triggered after the epoch
+	// boundary, see package triggeroffset.
+	TriggerOffsetBlocks = "trigger-offset-blocks"
+	// TriggerOffset is the time decryption is triggered after the epoch boundary.
+	TriggerOffset = "trigger-offset"
+)
+
+// The sources a parameter value comes from.
+const (
+	SourceDefault  = "default"
+	SourceConfig   = "config"
+	SourceContract = "contract"
+)
+
+// defaults are the values of parameters that haven't been set.
+var defaults = map[string]string{
+	FinalityOffset:      "3",
+	TriggerOffsetBlocks: "0",
+	TriggerOffset:       "0s",
+}
+
+// Registry reads and writes the protocol parameters stored in the database.
+type Registry struct {
+	db paramdb.DBTX
+}
+
+func New(db paramdb.DBTX) *Registry {
+	return &Registry{db: db}
+}
+
+// Set sets a parameter and records the change in the history, unless the parameter has the value
+// already. blockNumber is the block of the contract event the value comes from and 0 otherwise.
+// Pass the transaction of the state change the value comes from as db.
+func Set(ctx context.Context, db paramdb.DBTX, name, value, source string, blockNumber uint64) error {
+	queries := paramdb.New(db)
+	changed, err := queries.UpsertProtocolParameter(ctx, paramdb.UpsertProtocolParameterParams{
+		Name:        name,
+		Value:       value,
+		Source:      source,
+		BlockNumber: int64(blockNumber),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to set protocol parameter %s", name)
+	}
+	if changed == 0 {
+		return nil
+	}
+	err = queries.InsertProtocolParameterHistory(ctx, paramdb.InsertProtocolParameterHistoryParams{
+		Name:        name,
+		Value:       value,
+		Source:      source,
+		BlockNumber: int64(blockNumber),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to record change of protocol parameter %s", name)
+	}
+	log.Info().
+		Str("name", name).
+		Str("value", value).
+		Str("source", source).
+		Msg("protocol parameter changed")
+	return nil
+}
+
+// Populate stores the defaults of the parameters that haven't been set yet and the given values
+// from the config. Values from the config replace values from other sources, so that the config
+// is authoritative for the parameters it sets.
+func (r *Registry) Populate(ctx context.Context, config map[string]string) error {
+	for name, value := range defaults {
+		_, err := paramdb.New(r.db).GetProtocolParameter(ctx, name)
+		if err == nil {
+			continue
+		} else if err != pgx.ErrNoRows {
+			return errors.Wrapf(err, "failed to get protocol parameter %s", name)
+		}
+		if err := Set(ctx, r.db, name, value, SourceDefault, 0); err != nil {
+			return err
+		}
+	}
+	for name, value := range config {
+		if err := Set(ctx, r.db, name, value, SourceConfig, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String returns the value of a parameter. Parameters that haven't been set have their default
+// value. If there is none, an error is returned.
+func (r *Registry) String(ctx context.Context, name string) (string, error) {
+	param, err := paramdb.New(r.db).GetProtocolParameter(ctx, name)
+	if err == pgx.ErrNoRows {
+		if value, ok := defaults[name]; ok {
+			return value, nil
+		}
+		return "", errors.Errorf("protocol parameter %s is not set", name)
+	} else if err != nil {
+		return "", errors.Wrapf(err, "failed to get protocol parameter %s", name)
+	}
+	return param.Value, nil
+}
+
+// Uint64 returns the value of a parameter holding an unsigned integer.
+func (r *Registry) Uint64(ctx context.Context, name string) (uint64, error) {
+	value, err := r.String(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid value %q of protocol parameter %s", value, name)
+	}
+	return n, nil
+}
+
+// Duration returns the value of a parameter holding a duration.
+func (r *Registry) Duration(ctx context.Context, name string) (time.Duration, error) {
+	value, err := r.String(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid value %q of protocol parameter %s", value, name)
+	}
+	return d, nil
+}
+
+// All returns the current values of all parameters that have been set.
+func (r *Registry) All(ctx context.Context) ([]paramdb.ProtocolParameter, error) {
+	params, err := paramdb.New(r.db).GetProtocolParameters(ctx)
+	return params, errors.Wrap(err, "failed to get protocol parameters")
+}
+
+// History returns all values a parameter has had, oldest first.
+func (r *Registry) History(ctx context.Context, name string) ([]paramdb.ProtocolParameterHistory, error) {
+	history, err := paramdb.New(r.db).GetProtocolParameterHistory(ctx, name)
+	return history, errors.Wrapf(err, "failed to get history of protocol parameter %s", name)
+}
+
+// FormatUint64 formats an unsigned integer as a parameter value.
+func FormatUint64(n uint64) string {
+	return strconv.FormatUint(n, 10)
+}
@@ -0,0 +1,55 @@
+package paramregistry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+)
+
+func TestRegistryIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+
+	registry := New(dbpool)
+	offset, err := registry.Uint64(ctx, FinalityOffset)
+	assert.NilError(t, err)
+	assert.Equal(t, offset, uint64(3))
+	_, err = registry.String(ctx, KeyperThreshold)
+	assert.ErrorContains(t, err, "not set")
+
+	assert.NilError(t, registry.Populate(ctx, map[string]string{EpochDuration: "5s"}))
+	d, err := registry.Duration(ctx, EpochDuration)
+	assert.NilError(t, err)
+	assert.Equal(t, d, 5*time.Second)
+
+	assert.NilError(t, Set(ctx, dbpool, KeyperThreshold, "2", SourceContract, 10))
+	assert.NilError(t, Set(ctx, dbpool, KeyperThreshold, "2", SourceContract, 11))
+	assert.NilError(t, Set(ctx, dbpool, KeyperThreshold, "3", SourceContract, 12))
+	threshold, err := registry.Uint64(ctx, KeyperThreshold)
+	assert.NilError(t, err)
+	assert.Equal(t, threshold, uint64(3))
+	history, err := registry.History(ctx, KeyperThreshold)
+	assert.NilError(t, err)
+	assert.Equal(t, len(history), 2, "setting the current value again must not be recorded")
+	assert.Equal(t, history[0].BlockNumber, int64(10))
+	assert.Equal(t, history[1].Value, "3")
+
+	// a config value equal to the current one is not recorded as a change
+	assert.NilError(t, registry.Populate(ctx, map[string]string{FinalityOffset: "3"}))
+	history, err = registry.History(ctx, FinalityOffset)
+	assert.NilError(t, err)
+	assert.Equal(t, len(history), 1)
+	assert.Equal(t, history[0].Source, SourceDefault)
+
+	params, err := registry.All(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(params), 5)
+}
@@ -0,0 +1,70 @@
+package plugin
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+type Config struct {
+	Commands     []string          `comment:"Plugins to run, each given as the path of its executable followed by its arguments, separated by spaces"`
+	CallTimeout  *enctime.Duration `comment:"How long a plugin may take to handle an event before it is restarted"`
+	RestartDelay *enctime.Duration `comment:"How long to wait before restarting a plugin that exited"`
+}
+
+func (c *Config) Init() {
+	c.CallTimeout = &enctime.Duration{}
+	c.RestartDelay = &enctime.Duration{}
+}
+
+func (c *Config) Name() string {
+	return "plugins"
+}
+
+// Enabled reports whether any plugin is configured.
+func (c *Config) Enabled() bool {
+	return len(c.Commands) > 0
+}
+
+func (c *Config) Validate() error {
+	for _, command := range c.Commands {
+		if strings.TrimSpace(command) == "" {
+			return errors.New("plugin commands must not be empty")
+		}
+	}
+	if c.CallTimeout.Duration <= 0 {
+		return errors.New("CallTimeout of plugins must be positive")
+	}
+	if c.RestartDelay.Duration < 0 {
+		return errors.New("RestartDelay of plugins must not be negative")
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.Commands = []string{}
+	c.CallTimeout = &enctime.Duration{Duration: 10 * time.Second}
+	c.RestartDelay = &enctime.Duration{Duration: 5 * time.Second}
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,24 @@
+package plugin_test
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/plugin"
+)
+
+// keyLogger is a plugin printing the decryption keys a keyper learns about.
+type keyLogger struct{}
+
+func (keyLogger) OnDecryptionKey(event plugin.DecryptionKey) error {
+	// stdout is the connection to the node, so we print to stderr
+	fmt.Fprintf(os.Stderr, "decryption key of epoch %s in eon %d\n", event.EpochID, event.Eon)
+	return nil
+}
+
+func ExampleServe() {
+	if err := plugin.Serve(keyLogger{}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,39 @@
+package plugin
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/broker"
+)
+
+// Forward notifies the plugins about the domain events published to the bus until ctx is
+// canceled.
+func (h *Host) Forward(ctx context.Context, bus *broker.Bus) error {
+	keys := broker.Subscribe(bus, broker.DecryptionKeyAvailableTopic, 32)
+	defer broker.Unsubscribe(bus, broker.DecryptionKeyAvailableTopic, keys)
+	keyperSets := broker.Subscribe(bus, broker.KeyperSetActivatedTopic, 8)
+	defer broker.Unsubscribe(bus, broker.KeyperSetActivatedTopic, keyperSets)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-keys:
+			h.DecryptionKey(DecryptionKey{
+				Eon:     ev.Eon,
+				EpochID: hexutil.Bytes(ev.EpochID.Bytes()),
+				Key:     ev.Key,
+				Source:  ev.Source,
+			})
+		case ev := <-keyperSets:
+			h.KeyperSetChange(KeyperSetChange{
+				KeyperConfigIndex:     ev.KeyperConfigIndex,
+				ActivationBlockNumber: ev.ActivationBlockNumber,
+				Keypers:               ev.Keypers,
+				Threshold:             ev.Threshold,
+			})
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package plugin
+
+import (
+	"context"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+)
+
+// queueSize is the number of events buffered for a plugin. Further events are dropped until the
+// plugin catches up, so that a slow plugin never blocks the node.
+const queueSize = 256
+
+// Host runs the plugins of a node and notifies them about the events of their hooks.
+//
+// Notifying a nil *Host does nothing, so that nodes can be run without plugins.
+type Host struct {
+	plugins []*process
+}
+
+// NewHost creates the host of the configured plugins. node is the name of the node, which is sent
+// to the plugins in the handshake.
+func NewHost(config *Config, node string) *Host {
+	h := &Host{}
+	for _, command := range config.Commands {
+		args := strings.Fields(command)
+		h.plugins = append(h.plugins, &process{
+			name:   filepath.Base(args[0]),
+			args:   args,
+			node:   node,
+			config: config,
+			events: make(chan event, queueSize),
+		})
+	}
+	return h
+}
+
+// Run starts the plugins and keeps them running until ctx is canceled.
+func (h *Host) Run(ctx context.Context) error {
+	group, ctx := errgroup.WithContext(ctx)
+	for _, p := range h.plugins {
+		p := p
+		group.Go(func() error {
+			return p.run(ctx)
+		})
+	}
+	return group.Wait()
+}
+
+// DecryptionKey notifies the plugins implementing HookDecryptionKey.
+func (h *Host) DecryptionKey(event DecryptionKey) {
+	h.dispatch(HookDecryptionKey, event)
+}
+
+// BatchDecrypted notifies the plugins implementing HookBatchDecrypted.
+func (h *Host) BatchDecrypted(event BatchDecrypted) {
+	h.dispatch(HookBatchDecrypted, event)
+}
+
+// KeyperSetChange notifies the plugins implementing HookKeyperSetChange.
+func (h *Host) KeyperSetChange(event KeyperSetChange) {
+	h.dispatch(HookKeyperSetChange, event)
+}
+
+func (h *Host) dispatch(hook string, args any) {
+	if h == nil {
+		return
+	}
+	for _, p := range h.plugins {
+		select {
+		case p.events <- event{hook: hook, args: args}:
+		default:
+			metricsPluginEvents.WithLabelValues(p.name, hook, "dropped").Inc()
+			log.Warn().Str("plugin", p.name).Str("hook", hook).Msg("plugin is falling behind, dropped event")
+		}
+	}
+}
+
+type event struct {
+	hook string
+	args any
+}
+
+// process runs a single plugin, restarting it whenever it exits or fails to handle an event in
+// time.
+type process struct {
+	name   string
+	args   []string
+	node   string
+	config *Config
+	events chan event
+}
+
+func (p *process) run(ctx context.Context) error {
+	for {
+		err := p.runOnce(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		metricsPluginRestarts.WithLabelValues(p.name).Inc()
+		log.Error().Err(err).Str("plugin", p.name).
+			Str("restart-delay", p.config.RestartDelay.Duration.String()).
+			Msg("plugin stopped, restarting it")
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(p.config.RestartDelay.Duration):
+		}
+	}
+}
+
+// runOnce starts the plugin and forwards events to it until it fails or ctx is canceled.
+func (p *process) runOnce(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.args[0], p.args[1:]...) //nolint:gosec
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "failed to start plugin")
+	}
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	client := rpc.NewClientWithCodec(jsonrpc.NewClientCodec(stdio{reader: stdout, writer: stdin}))
+	defer client.Close()
+
+	handshake := HandshakeReply{}
+	err = p.call(ctx, client, exited, serviceName+".Handshake",
+		HandshakeRequest{ProtocolVersion: ProtocolVersion, Node: p.node}, &handshake)
+	if err != nil {
+		return errors.Wrap(err, "handshake failed")
+	}
+	if handshake.ProtocolVersion != ProtocolVersion {
+		return errors.Errorf("plugin speaks protocol version %d, expected %d", handshake.ProtocolVersion, ProtocolVersion)
+	}
+	hooks := map[string]bool{}
+	for _, hook := range handshake.Hooks {
+		if _, ok := hookMethods[hook]; !ok {
+			return errors.Errorf("plugin requested unknown hook %q", hook)
+		}
+		hooks[hook] = true
+	}
+	log.Info().Str("plugin", p.name).Strs("hooks", handshake.Hooks).Msg("started plugin")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-exited:
+			return errors.Wrap(err, "plugin exited")
+		case ev := <-p.events:
+			if !hooks[ev.hook] {
+				continue
+			}
+			err := p.call(ctx, client, exited, hookMethods[ev.hook], ev.args, &Empty{})
+			var serverErr rpc.ServerError
+			if errors.As(err, &serverErr) {
+				// the plugin is alive, but failed to handle the event
+				metricsPluginEvents.WithLabelValues(p.name, ev.hook, "failed").Inc()
+				log.Warn().Err(err).Str("plugin", p.name).Str("hook", ev.hook).Msg("plugin failed to handle event")
+				continue
+			} else if err != nil {
+				metricsPluginEvents.WithLabelValues(p.name, ev.hook, "failed").Inc()
+				return errors.Wrapf(err, "failed to notify plugin about %s event", ev.hook)
+			}
+			metricsPluginEvents.WithLabelValues(p.name, ev.hook, "ok").Inc()
+		}
+	}
+}
+
+// call calls a method of the plugin and waits for the reply until the call timeout expires or the
+// plugin exits.
+func (p *process) call(
+	ctx context.Context, client *rpc.Client, exited <-chan error, method string, args, reply any,
+) error {
+	timer := time.NewTimer(p.config.CallTimeout.Duration)
+	defer timer.Stop()
+	call := client.Go(method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		return call.Error
+	case err := <-exited:
+		return errors.Wrap(err, "plugin exited")
+	case <-timer.C:
+		return errors.Errorf("plugin didn't reply to %s within %s", method, p.config.CallTimeout.Duration)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
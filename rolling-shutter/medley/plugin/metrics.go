@@ -0,0 +1,28 @@
+package plugin
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsPluginEvents = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "plugin",
+		Name:      "events_total",
+		Help:      "Number of events sent to plugins, by result: ok, failed or dropped",
+	},
+	[]string{"plugin", "hook", "result"},
+)
+
+var metricsPluginRestarts = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "plugin",
+		Name:      "restarts_total",
+		Help:      "Number of times a plugin has been restarted",
+	},
+	[]string{"plugin"},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsPluginEvents)
+	prometheus.MustRegister(metricsPluginRestarts)
+}
@@ -0,0 +1,96 @@
+// Package plugin lets integrators attach custom logic to a node, e.g. indexers, notifications or
+// compliance monitoring, without forking it. Plugins are separate executables started by the node.
+// They talk JSON-RPC 1.0 (as implemented by net/rpc/jsonrpc) with the node over their stdin and
+// stdout and are notified about the events of the hooks they implement. Plugins written in Go
+// implement the hook interfaces of this package and call Serve; plugins in other languages
+// implement the protocol described by the request and reply types.
+//
+// The node stays in control: plugins can't alter what the node does, events are dropped if a
+// plugin falls behind and plugins that crash or hang are restarted.
+package plugin
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ProtocolVersion is the version of the protocol between the node and its plugins. It's increased
+// whenever the protocol changes incompatibly.
+const ProtocolVersion = 1
+
+// serviceName is the name of the RPC service plugins serve. The methods of the protocol are
+// Plugin.Handshake and Plugin.<hook method> for every hook.
+const serviceName = "Plugin"
+
+// The hooks plugins can implement, named after the events they are notified about.
+const (
+	HookDecryptionKey   = "decryption-key"
+	HookBatchDecrypted  = "batch-decrypted"
+	HookKeyperSetChange = "keyper-set-change"
+)
+
+// hookMethods maps the hooks to the RPC methods notifying plugins about their events.
+var hookMethods = map[string]string{
+	HookDecryptionKey:   serviceName + ".OnDecryptionKey",
+	HookBatchDecrypted:  serviceName + ".OnBatchDecrypted",
+	HookKeyperSetChange: serviceName + ".OnKeyperSetChange",
+}
+
+// HandshakeRequest is sent by the node once a plugin has been started.
+type HandshakeRequest struct {
+	ProtocolVersion int    `json:"protocolVersion"`
+	Node            string `json:"node"`
+}
+
+// HandshakeReply is the plugin's answer to the handshake. Hooks lists the hooks the plugin wants to
+// be notified about.
+type HandshakeReply struct {
+	ProtocolVersion int      `json:"protocolVersion"`
+	Hooks           []string `json:"hooks"`
+}
+
+// Empty is the reply to the notification about an event.
+type Empty struct{}
+
+// DecryptionKey is the event of HookDecryptionKey. It's sent once for every epoch whose
+// decryption key becomes known to a keyper.
+type DecryptionKey struct {
+	Eon     uint64        `json:"eon"`
+	EpochID hexutil.Bytes `json:"epochID"`
+	Key     hexutil.Bytes `json:"key"`
+	Source  string        `json:"source"`
+}
+
+// BatchDecrypted is the event of HookBatchDecrypted. It's sent by the collator once the batch of
+// an epoch has been closed together with its decryption key, i.e. once its transactions can be
+// decrypted. Transactions are the transactions of the batch as submitted.
+type BatchDecrypted struct {
+	EpochID       hexutil.Bytes   `json:"epochID"`
+	BatchIndex    uint64          `json:"batchIndex"`
+	DecryptionKey hexutil.Bytes   `json:"decryptionKey"`
+	Transactions  []hexutil.Bytes `json:"transactions"`
+}
+
+// KeyperSetChange is the event of HookKeyperSetChange. It's sent when the activation block of a
+// keyper set has been reached.
+type KeyperSetChange struct {
+	KeyperConfigIndex     uint64           `json:"keyperConfigIndex"`
+	ActivationBlockNumber uint64           `json:"activationBlockNumber"`
+	Keypers               []common.Address `json:"keypers"`
+	Threshold             uint64           `json:"threshold"`
+}
+
+// DecryptionKeyHook is implemented by plugins that want to be notified about decryption keys.
+type DecryptionKeyHook interface {
+	OnDecryptionKey(event DecryptionKey) error
+}
+
+// BatchDecryptedHook is implemented by plugins that want to be notified about decrypted batches.
+type BatchDecryptedHook interface {
+	OnBatchDecrypted(event BatchDecrypted) error
+}
+
+// KeyperSetChangeHook is implemented by plugins that want to be notified about keyper set changes.
+type KeyperSetChangeHook interface {
+	OnKeyperSetChange(event KeyperSetChange) error
+}
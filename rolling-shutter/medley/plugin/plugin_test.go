@@ -0,0 +1,105 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+)
+
+// outputEnv is set when the test binary is started as a plugin by TestHost. The plugin appends the
+// events it handles to the file it names.
+const outputEnv = "SHUTTER_TEST_PLUGIN_OUTPUT"
+
+func TestMain(m *testing.M) {
+	if path := os.Getenv(outputEnv); path != "" {
+		if err := Serve(&recordingPlugin{path: path}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type recordingPlugin struct {
+	path string
+}
+
+func (p *recordingPlugin) OnDecryptionKey(event DecryptionKey) error {
+	if event.Source == "reject" {
+		return errors.New("rejected")
+	}
+	return p.record(fmt.Sprintf("decryption-key %s", event.EpochID))
+}
+
+func (p *recordingPlugin) OnKeyperSetChange(event KeyperSetChange) error {
+	return p.record(fmt.Sprintf("keyper-set-change %d", event.KeyperConfigIndex))
+}
+
+func (p *recordingPlugin) record(line string) error {
+	f, err := os.OpenFile(p.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func TestImplementedHooks(t *testing.T) {
+	assert.DeepEqual(t, implementedHooks(&recordingPlugin{}), []string{HookDecryptionKey, HookKeyperSetChange})
+	assert.DeepEqual(t, implementedHooks(struct{}{}), []string{})
+}
+
+func TestHost(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "events")
+	t.Setenv(outputEnv, output)
+	config := NewConfig()
+	assert.NilError(t, config.SetDefaultValues())
+	config.Commands = []string{os.Args[0]}
+	assert.NilError(t, config.Validate())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	host := NewHost(config, "test")
+	done := make(chan error, 1)
+	go func() {
+		done <- host.Run(ctx)
+	}()
+
+	// a failing hook must not stop the plugin and events of hooks the plugin doesn't implement are
+	// not sent to it
+	host.DecryptionKey(DecryptionKey{EpochID: []byte{1}, Source: "reject"})
+	host.DecryptionKey(DecryptionKey{EpochID: []byte{2}})
+	host.BatchDecrypted(BatchDecrypted{EpochID: []byte{3}})
+	host.KeyperSetChange(KeyperSetChange{KeyperConfigIndex: 4})
+
+	expected := "decryption-key 0x02\nkeyper-set-change 4\n"
+	var data []byte
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); {
+		data, _ = os.ReadFile(output)
+		if string(data) == expected {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.Equal(t, string(data), expected)
+
+	cancel()
+	assert.NilError(t, <-done)
+}
+
+func TestNilHost(t *testing.T) {
+	var host *Host
+	host.DecryptionKey(DecryptionKey{})
+	host.BatchDecrypted(BatchDecrypted{})
+	host.KeyperSetChange(KeyperSetChange{})
+}
@@ -0,0 +1,105 @@
+package plugin
+
+import (
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// errHookNotImplemented is returned to the node if it sends an event of a hook the plugin doesn't
+// implement.
+var errHookNotImplemented = errors.New("hook not implemented")
+
+// Serve serves the hooks implemented by impl to the node that started the plugin. It returns once
+// the node closes the connection, e.g. because it shuts down. Plugins must not write to stdout, as
+// it's used for the connection; logs can be written to stderr, which the node forwards to its own.
+func Serve(impl any) error {
+	return ServeConn(impl, stdio{reader: os.Stdin, writer: os.Stdout})
+}
+
+// ServeConn is like Serve, but uses the given connection to the node.
+func ServeConn(impl any, conn io.ReadWriteCloser) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName(serviceName, &hookServer{impl: impl}); err != nil {
+		return err
+	}
+	server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	return nil
+}
+
+// implementedHooks returns the hooks impl implements.
+func implementedHooks(impl any) []string {
+	hooks := []string{}
+	if _, ok := impl.(DecryptionKeyHook); ok {
+		hooks = append(hooks, HookDecryptionKey)
+	}
+	if _, ok := impl.(BatchDecryptedHook); ok {
+		hooks = append(hooks, HookBatchDecrypted)
+	}
+	if _, ok := impl.(KeyperSetChangeHook); ok {
+		hooks = append(hooks, HookKeyperSetChange)
+	}
+	return hooks
+}
+
+// hookServer adapts the hook interfaces to the method signatures required by net/rpc.
+type hookServer struct {
+	impl any
+}
+
+func (s *hookServer) Handshake(req HandshakeRequest, reply *HandshakeReply) error {
+	if req.ProtocolVersion != ProtocolVersion {
+		return errors.Errorf("unsupported protocol version %d, expected %d", req.ProtocolVersion, ProtocolVersion)
+	}
+	*reply = HandshakeReply{ProtocolVersion: ProtocolVersion, Hooks: implementedHooks(s.impl)}
+	return nil
+}
+
+func (s *hookServer) OnDecryptionKey(event DecryptionKey, _ *Empty) error {
+	hook, ok := s.impl.(DecryptionKeyHook)
+	if !ok {
+		return errHookNotImplemented
+	}
+	return hook.OnDecryptionKey(event)
+}
+
+func (s *hookServer) OnBatchDecrypted(event BatchDecrypted, _ *Empty) error {
+	hook, ok := s.impl.(BatchDecryptedHook)
+	if !ok {
+		return errHookNotImplemented
+	}
+	return hook.OnBatchDecrypted(event)
+}
+
+func (s *hookServer) OnKeyperSetChange(event KeyperSetChange, _ *Empty) error {
+	hook, ok := s.impl.(KeyperSetChangeHook)
+	if !ok {
+		return errHookNotImplemented
+	}
+	return hook.OnKeyperSetChange(event)
+}
+
+// stdio joins a reader and a writer to a connection, e.g. the stdin and stdout of a process.
+type stdio struct {
+	reader io.ReadCloser
+	writer io.WriteCloser
+}
+
+func (c stdio) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+func (c stdio) Write(p []byte) (int, error) {
+	return c.writer.Write(p)
+}
+
+func (c stdio) Close() error {
+	werr := c.writer.Close()
+	if err := c.reader.Close(); err != nil {
+		return err
+	}
+	return werr
+}
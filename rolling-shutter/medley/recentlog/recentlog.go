@@ -0,0 +1,92 @@
+// Package recentlog keeps the most recent errors logged by a node in memory, so that operators can
+// see them through the node's HTTP API without access to its log files.
+package recentlog
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultSize is the number of entries kept by Default.
+const DefaultSize = 50
+
+// Entry is a logged error.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	Error   string    `json:"error,omitempty"`
+	Caller  string    `json:"caller,omitempty"`
+}
+
+// Buffer is a zerolog.LevelWriter that keeps the most recent events of at least error level.
+type Buffer struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// Default is the buffer the root command's logger writes to.
+var Default = New(DefaultSize)
+
+func New(size int) *Buffer {
+	return &Buffer{entries: make([]Entry, size)}
+}
+
+// Write implements io.Writer. Events without a level are not kept.
+func (b *Buffer) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// WriteLevel implements zerolog.LevelWriter. It expects p to be an event encoded as JSON.
+func (b *Buffer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < zerolog.ErrorLevel || level == zerolog.NoLevel || len(b.entries) == 0 {
+		return len(p), nil
+	}
+	fields := make(map[string]interface{})
+	_ = json.Unmarshal(p, &fields)
+	entry := Entry{
+		Time:    time.Now(),
+		Level:   level.String(),
+		Message: stringField(fields, zerolog.MessageFieldName),
+		Error:   stringField(fields, zerolog.ErrorFieldName),
+		Caller:  stringField(fields, zerolog.CallerFieldName),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[b.next] = entry
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+	return len(p), nil
+}
+
+func stringField(fields map[string]interface{}, name string) string {
+	s, _ := fields[name].(string)
+	return s
+}
+
+// Entries returns the kept entries, oldest first.
+func (b *Buffer) Entries() []Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.full {
+		return append([]Entry{}, b.entries[:b.next]...)
+	}
+	return append(append([]Entry{}, b.entries[b.next:]...), b.entries[:b.next]...)
+}
+
+// Handler serves the kept entries as JSON.
+func (b *Buffer) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(b.Entries())
+	}
+}
@@ -0,0 +1,31 @@
+package recentlog
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"gotest.tools/v3/assert"
+)
+
+func TestBuffer(t *testing.T) {
+	b := New(2)
+	logger := zerolog.New(b)
+
+	logger.Warn().Msg("ignored")
+	assert.Equal(t, len(b.Entries()), 0)
+
+	logger.Error().Err(errors.New("boom")).Msg("first")
+	entries := b.Entries()
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, entries[0].Message, "first")
+	assert.Equal(t, entries[0].Error, "boom")
+	assert.Equal(t, entries[0].Level, "error")
+
+	logger.Error().Msg("second")
+	logger.Error().Msg("third")
+	entries = b.Entries()
+	assert.Equal(t, len(entries), 2)
+	assert.Equal(t, entries[0].Message, "second")
+	assert.Equal(t, entries[1].Message, "third")
+}
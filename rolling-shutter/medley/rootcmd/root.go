@@ -16,6 +16,8 @@ import (
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/shversion"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/logfilter"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/recentlog"
 )
 
 var (
@@ -123,8 +125,8 @@ func setupLogging() (zerolog.Logger, error) {
 		}
 	}
 
-	// reset the writer
-	l = l.Output(zerolog.ConsoleWriter{
+	// reset the writer, keeping recent errors around for the HTTP API
+	console := zerolog.ConsoleWriter{
 		NoColor:    logNoColorArg,
 		Out:        os.Stderr,
 		TimeFormat: zerolog.TimeFieldFormat,
@@ -138,7 +140,8 @@ func setupLogging() (zerolog.Logger, error) {
 		FormatCaller: func(i interface{}) string {
 			return colorize(fmt.Sprintf("[%20s]", i), 1, logNoColorArg)
 		},
-	})
+	}
+	l = l.Output(zerolog.MultiLevelWriter(console, recentlog.Default))
 
 	return l, nil
 }
@@ -158,7 +161,7 @@ func Cmd() *cobra.Command {
 			if err != nil {
 				return errors.Wrap(err, "failed to setup logging")
 			}
-			log.Logger = logger
+			log.Logger = logfilter.Install(logger)
 			return nil
 		},
 	}
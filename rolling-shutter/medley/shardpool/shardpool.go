@@ -0,0 +1,78 @@
+// Package shardpool implements a pool of workers that process tasks concurrently, while tasks
+// with the same key are processed sequentially in the order they were submitted.
+package shardpool
+
+import (
+	"context"
+	"hash/fnv"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Task is a unit of work processed by one of the pool's shards.
+type Task func(ctx context.Context)
+
+// Pool distributes tasks deterministically to a fixed number of shards based on their key. Each
+// shard is processed by a single goroutine, so tasks with the same key never run concurrently and
+// are processed in submission order.
+type Pool struct {
+	shards []chan Task
+}
+
+// New creates a pool with numShards shards, each buffering up to bufSize tasks. numShards is
+// clamped to at least one.
+func New(numShards, bufSize int) *Pool {
+	if numShards < 1 {
+		numShards = 1
+	}
+	shards := make([]chan Task, numShards)
+	for i := range shards {
+		shards[i] = make(chan Task, bufSize)
+	}
+	return &Pool{shards: shards}
+}
+
+// NumShards returns the number of shards of the pool.
+func (p *Pool) NumShards() int {
+	return len(p.shards)
+}
+
+// ShardIndex returns the index of the shard tasks with the given key are processed on.
+func (p *Pool) ShardIndex(key []byte) int {
+	if len(p.shards) == 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+	return int(h.Sum32() % uint32(len(p.shards)))
+}
+
+// Submit queues the task on the shard determined by key. It blocks while the shard's queue is
+// full.
+func (p *Pool) Submit(ctx context.Context, key []byte, task Task) error {
+	select {
+	case p.shards[p.ShardIndex(key)] <- task:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run processes the submitted tasks until the context is canceled.
+func (p *Pool) Run(ctx context.Context) error {
+	group, ctx := errgroup.WithContext(ctx)
+	for _, shard := range p.shards {
+		shard := shard
+		group.Go(func() error {
+			for {
+				select {
+				case task := <-shard:
+					task(ctx)
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		})
+	}
+	return group.Wait()
+}
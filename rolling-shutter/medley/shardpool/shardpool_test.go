@@ -0,0 +1,57 @@
+package shardpool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestShardIndexDeterministic(t *testing.T) {
+	p := New(8, 1)
+	for i := 0; i < 100; i++ {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		idx := p.ShardIndex(key)
+		assert.Assert(t, idx >= 0 && idx < p.NumShards())
+		assert.Equal(t, idx, p.ShardIndex(key))
+	}
+	assert.Equal(t, New(0, 1).NumShards(), 1)
+}
+
+func TestSameKeyOrderPreserved(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	p := New(4, 16)
+	go func() { _ = p.Run(ctx) }()
+
+	const numKeys = 10
+	const numTasks = 50
+	var mux sync.Mutex
+	seen := map[int][]int{}
+	var wg sync.WaitGroup
+	wg.Add(numKeys * numTasks)
+	for i := 0; i < numTasks; i++ {
+		for k := 0; k < numKeys; k++ {
+			k, i := k, i
+			err := p.Submit(ctx, []byte{byte(k)}, func(context.Context) {
+				defer wg.Done()
+				mux.Lock()
+				defer mux.Unlock()
+				seen[k] = append(seen[k], i)
+			})
+			assert.NilError(t, err)
+		}
+	}
+	wg.Wait()
+
+	for k := 0; k < numKeys; k++ {
+		assert.Equal(t, len(seen[k]), numTasks)
+		for i, v := range seen[k] {
+			assert.Equal(t, v, i)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package storagemonitor
+
+import (
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+type Config struct {
+	Interval *enctime.Duration `comment:"How often free disk space and database size are checked, 0 disables the monitor"`
+
+	DiskPath         string `comment:"A path on the disk holding the database, e.g. the Postgres data directory. If it's empty, free disk space is not monitored"`
+	WarnFreeDisk     uint64 `comment:"Alert if fewer bytes are free on the disk, 0 disables the threshold"`
+	CriticalFreeDisk uint64 `comment:"Stop non-essential writes if fewer bytes are free on the disk, 0 disables the threshold"`
+
+	WarnDatabaseSize     uint64 `comment:"Alert if the database grows larger than this many bytes, 0 disables the threshold"`
+	CriticalDatabaseSize uint64 `comment:"Stop non-essential writes if the database grows larger than this many bytes, 0 disables the threshold"`
+}
+
+func (c *Config) Init() {
+	c.Interval = &enctime.Duration{}
+}
+
+func (c *Config) Name() string {
+	return "storage"
+}
+
+func (c *Config) Validate() error {
+	if c.WarnFreeDisk != 0 && c.CriticalFreeDisk > c.WarnFreeDisk {
+		return errors.New("CriticalFreeDisk must not be larger than WarnFreeDisk")
+	}
+	if c.CriticalDatabaseSize != 0 && c.WarnDatabaseSize > c.CriticalDatabaseSize {
+		return errors.New("WarnDatabaseSize must not be larger than CriticalDatabaseSize")
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.Interval = &enctime.Duration{Duration: time.Minute}
+	c.DiskPath = ""
+	c.WarnFreeDisk = 10 << 30
+	c.CriticalFreeDisk = 2 << 30
+	c.WarnDatabaseSize = 0
+	c.CriticalDatabaseSize = 0
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	err := c.SetDefaultValues()
+	if err != nil {
+		return err
+	}
+	c.DiskPath = "/var/lib/postgresql/data"
+	return nil
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
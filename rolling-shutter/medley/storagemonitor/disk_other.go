@@ -0,0 +1,9 @@
+//go:build !unix
+
+package storagemonitor
+
+import "github.com/pkg/errors"
+
+func freeDiskSpace(string) (uint64, error) {
+	return 0, errors.New("free disk space monitoring is not supported on this platform")
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package storagemonitor
+
+import (
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// freeDiskSpace returns the number of bytes available to unprivileged users on the filesystem
+// holding path.
+func freeDiskSpace(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, errors.Wrapf(err, "failed to stat filesystem of %s", path)
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
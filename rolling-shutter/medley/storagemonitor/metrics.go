@@ -0,0 +1,43 @@
+package storagemonitor
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsFreeDisk = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "storage",
+		Name:      "free_disk_bytes",
+		Help:      "Free space on the disk holding the database",
+	},
+)
+
+var metricsDatabaseSize = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "storage",
+		Name:      "database_size_bytes",
+		Help:      "Size of the database",
+	},
+)
+
+var metricsLevel = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "storage",
+		Name:      "level",
+		Help:      "Storage level of the node: 0 ok, 1 warning, 2 critical with non-essential writes stopped",
+	},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsFreeDisk)
+	prometheus.MustRegister(metricsDatabaseSize)
+	prometheus.MustRegister(metricsLevel)
+}
+
+func updateMetrics(usage Usage) {
+	if usage.FreeDisk != nil {
+		metricsFreeDisk.Set(float64(*usage.FreeDisk))
+	}
+	metricsDatabaseSize.Set(float64(usage.DatabaseSize))
+}
@@ -0,0 +1,175 @@
+// Package storagemonitor watches the free space on the disk holding the database and the size of
+// the database. Operators are alerted when either crosses a threshold, and once storage is
+// critically low, non-essential writes like the audit log and metrics snapshots are stopped, so
+// that the remaining space is left to the writes needed to take part in the protocol.
+package storagemonitor
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/alert"
+)
+
+// Level classifies the storage situation of a node.
+type Level int32
+
+const (
+	LevelOK Level = iota
+	LevelWarning
+	LevelCritical
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelOK:
+		return "ok"
+	case LevelWarning:
+		return "warning"
+	case LevelCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// Usage is a single measurement of the storage used by a node. FreeDisk is nil if free disk space
+// is not monitored.
+type Usage struct {
+	FreeDisk     *uint64
+	DatabaseSize uint64
+}
+
+// Evaluate returns the level of the given usage and the reasons for it.
+func Evaluate(config *Config, usage Usage) (Level, []string) {
+	level := LevelOK
+	var reasons []string
+	raise := func(l Level, reason string) {
+		if l > level {
+			level = l
+		}
+		reasons = append(reasons, reason)
+	}
+	if usage.FreeDisk != nil {
+		free := *usage.FreeDisk
+		switch {
+		case config.CriticalFreeDisk != 0 && free < config.CriticalFreeDisk:
+			raise(LevelCritical, "free disk space below critical threshold")
+		case config.WarnFreeDisk != 0 && free < config.WarnFreeDisk:
+			raise(LevelWarning, "free disk space below warning threshold")
+		}
+	}
+	switch {
+	case config.CriticalDatabaseSize != 0 && usage.DatabaseSize > config.CriticalDatabaseSize:
+		raise(LevelCritical, "database size above critical threshold")
+	case config.WarnDatabaseSize != 0 && usage.DatabaseSize > config.WarnDatabaseSize:
+		raise(LevelWarning, "database size above warning threshold")
+	}
+	return level, reasons
+}
+
+// Monitor measures the storage usage every interval. A nil Monitor allows all writes.
+type Monitor struct {
+	config   *Config
+	dbpool   *pgxpool.Pool
+	notifier alert.Notifier
+	level    atomic.Int32
+}
+
+func New(config *Config, dbpool *pgxpool.Pool, notifier alert.Notifier) *Monitor {
+	return &Monitor{config: config, dbpool: dbpool, notifier: notifier}
+}
+
+// Level returns the level of the most recent measurement.
+func (m *Monitor) Level() Level {
+	if m == nil {
+		return LevelOK
+	}
+	return Level(m.level.Load())
+}
+
+// AllowNonEssentialWrites reports whether writes that are not needed to take part in the protocol
+// should be done. They are stopped while storage is critically low.
+func (m *Monitor) AllowNonEssentialWrites() bool {
+	return m.Level() < LevelCritical
+}
+
+// Run measures the storage usage until the context is canceled.
+func (m *Monitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.config.Interval.Duration)
+	defer ticker.Stop()
+	for {
+		if err := m.check(ctx); err != nil {
+			log.Warn().Err(err).Msg("failed to check storage usage")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Monitor) measure(ctx context.Context) (Usage, error) {
+	var usage Usage
+	if m.config.DiskPath != "" {
+		free, err := freeDiskSpace(m.config.DiskPath)
+		if err != nil {
+			return usage, err
+		}
+		usage.FreeDisk = &free
+	}
+	var size int64
+	err := m.dbpool.QueryRow(ctx, "SELECT pg_database_size(current_database())").Scan(&size)
+	if err != nil {
+		return usage, errors.Wrap(err, "failed to query database size")
+	}
+	usage.DatabaseSize = uint64(size)
+	return usage, nil
+}
+
+func (m *Monitor) check(ctx context.Context) error {
+	usage, err := m.measure(ctx)
+	if err != nil {
+		return err
+	}
+	updateMetrics(usage)
+	level, reasons := Evaluate(m.config, usage)
+	previous := Level(m.level.Swap(int32(level)))
+	metricsLevel.Set(float64(level))
+	if level == previous {
+		return nil
+	}
+	return m.notify(ctx, usage, level, reasons)
+}
+
+func (m *Monitor) notify(ctx context.Context, usage Usage, level Level, reasons []string) error {
+	details := map[string]string{
+		"database-size": strconv.FormatUint(usage.DatabaseSize, 10),
+	}
+	if usage.FreeDisk != nil {
+		details["free-disk"] = strconv.FormatUint(*usage.FreeDisk, 10)
+	}
+	for i, reason := range reasons {
+		details["reason-"+strconv.Itoa(i)] = reason
+	}
+	a := alert.Alert{Details: details}
+	switch level {
+	case LevelCritical:
+		a.Severity = alert.SeverityCritical
+		a.Summary = "storage is critically low, non-essential writes are stopped"
+	case LevelWarning:
+		a.Severity = alert.SeverityWarning
+		a.Summary = "storage is running low"
+	default:
+		a.Severity = alert.SeverityInfo
+		a.Summary = "storage usage is back to normal"
+	}
+	return m.notifier.Notify(ctx, a)
+}
@@ -0,0 +1,55 @@
+package storagemonitor
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	config := &Config{
+		WarnFreeDisk:         100,
+		CriticalFreeDisk:     10,
+		WarnDatabaseSize:     1000,
+		CriticalDatabaseSize: 2000,
+	}
+	free := func(n uint64) *uint64 { return &n }
+
+	testCases := []struct {
+		name       string
+		usage      Usage
+		level      Level
+		numReasons int
+	}{
+		{name: "ok", usage: Usage{FreeDisk: free(500), DatabaseSize: 500}, level: LevelOK},
+		{name: "disk not monitored", usage: Usage{DatabaseSize: 500}, level: LevelOK},
+		{name: "low disk", usage: Usage{FreeDisk: free(50), DatabaseSize: 500}, level: LevelWarning, numReasons: 1},
+		{name: "critical disk", usage: Usage{FreeDisk: free(5), DatabaseSize: 500}, level: LevelCritical, numReasons: 1},
+		{name: "large db", usage: Usage{FreeDisk: free(500), DatabaseSize: 1500}, level: LevelWarning, numReasons: 1},
+		{
+			name:       "critical db and low disk",
+			usage:      Usage{FreeDisk: free(50), DatabaseSize: 2500},
+			level:      LevelCritical,
+			numReasons: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			level, reasons := Evaluate(config, tc.usage)
+			assert.Equal(t, level, tc.level)
+			assert.Equal(t, len(reasons), tc.numReasons)
+		})
+	}
+}
+
+func TestEvaluateDisabledThresholds(t *testing.T) {
+	free := uint64(0)
+	level, reasons := Evaluate(&Config{}, Usage{FreeDisk: &free, DatabaseSize: 1 << 40})
+	assert.Equal(t, level, LevelOK)
+	assert.Equal(t, len(reasons), 0)
+}
+
+func TestNilMonitorAllowsWrites(t *testing.T) {
+	var m *Monitor
+	assert.Assert(t, m.AllowNonEssentialWrites())
+}
@@ -0,0 +1,147 @@
+package testdb
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// The embedded server is started by the first test that needs a database and stopped by Run once
+// all tests of the package have finished.
+var (
+	embeddedMu      sync.Mutex
+	embeddedEnabled bool
+	embeddedStarted bool
+	embeddedServer  *embeddedpostgres.EmbeddedPostgres
+	embeddedDir     string
+	embeddedURL     string
+	embeddedErr     error
+)
+
+// testDBBinariesVar names the directory containing the Postgres binaries (initdb, pg_ctl and
+// postgres) of the throwaway server.
+const testDBBinariesVar = "ROLLING_SHUTTER_TESTDB_POSTGRES_BIN"
+
+// Run runs the tests of a package using the test db and returns the exit code. Unless
+// ROLLING_SHUTTER_TESTDB_URL is set, the tests use a throwaway Postgres server which is started on
+// first use and stopped again by Run. Its binaries are taken from the directory given by
+// ROLLING_SHUTTER_TESTDB_POSTGRES_BIN, or else from the installation of the pg_ctl found on the
+// PATH. Only if there is neither, they are downloaded. Call it from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(testdb.Run(m))
+//	}
+func Run(m *testing.M) int {
+	embeddedMu.Lock()
+	embeddedEnabled = true
+	embeddedMu.Unlock()
+
+	code := m.Run()
+
+	embeddedMu.Lock()
+	defer embeddedMu.Unlock()
+	embeddedEnabled = false
+	if embeddedServer != nil {
+		if err := embeddedServer.Stop(); err != nil {
+			log.Error().Err(err).Msg("failed to stop embedded test db")
+		}
+		embeddedServer = nil
+	}
+	if embeddedDir != "" {
+		_ = os.RemoveAll(embeddedDir)
+		embeddedDir = ""
+	}
+	return code
+}
+
+// embeddedDBURL returns the URL of the embedded server, starting it if necessary. Downloaded
+// Postgres binaries are cached in ~/.embedded-postgres-go.
+func embeddedDBURL() (string, error) {
+	embeddedMu.Lock()
+	defer embeddedMu.Unlock()
+	if !embeddedEnabled {
+		return "", errors.New("the embedded test db is only available to tests started by testdb.Run")
+	}
+	if !embeddedStarted {
+		embeddedStarted = true
+		embeddedURL, embeddedErr = startEmbedded()
+	}
+	return embeddedURL, embeddedErr
+}
+
+func freePort() (uint32, error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return uint32(listener.Addr().(*net.TCPAddr).Port), nil
+}
+
+// localBinaries returns the directory containing the local Postgres installation to use, or ""
+// if the binaries have to be downloaded. The installation is the parent of the bin directory.
+func localBinaries() (string, error) {
+	binDir, ok := os.LookupEnv(testDBBinariesVar)
+	if !ok {
+		pgCtl, err := exec.LookPath("pg_ctl")
+		if err != nil {
+			return "", nil
+		}
+		// distributions often link pg_ctl to a wrapper, so only use installations with initdb
+		pgCtl, err = filepath.EvalSymlinks(pgCtl)
+		if err != nil {
+			return "", nil
+		}
+		binDir = filepath.Dir(pgCtl)
+		if _, err := os.Stat(filepath.Join(binDir, "initdb")); err != nil {
+			return "", nil
+		}
+	}
+	for _, name := range []string{"initdb", "pg_ctl", "postgres"} {
+		if _, err := os.Stat(filepath.Join(binDir, name)); err != nil {
+			return "", errors.Wrapf(err, "no Postgres binaries found in %s", binDir)
+		}
+	}
+	return filepath.Dir(binDir), nil
+}
+
+func startEmbedded() (string, error) {
+	var err error
+	embeddedDir, err = os.MkdirTemp("", "rolling-shutter-testdb-")
+	if err != nil {
+		return "", err
+	}
+	port, err := freePort()
+	if err != nil {
+		return "", err
+	}
+	binaries, err := localBinaries()
+	if err != nil {
+		return "", err
+	}
+	output := &bytes.Buffer{}
+	config := embeddedpostgres.DefaultConfig().
+		Version(embeddedpostgres.V13).
+		Port(port).
+		RuntimePath(embeddedDir).
+		Logger(output)
+	if binaries != "" {
+		log.Debug().Str("path", binaries).Msg("using local Postgres binaries for the test db")
+		config = config.BinariesPath(binaries)
+	}
+	server := embeddedpostgres.NewDatabase(config)
+	if err := server.Start(); err != nil {
+		log.Debug().Str("output", output.String()).Msg("embedded test db output")
+		return "", errors.Wrap(err, "failed to start embedded test db")
+	}
+	embeddedServer = server
+	return config.GetConnectionURL() + "?sslmode=disable", nil
+}
@@ -0,0 +1,39 @@
+package testdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLocalBinaries(t *testing.T) {
+	installation := t.TempDir()
+	binDir := filepath.Join(installation, "bin")
+	assert.NilError(t, os.Mkdir(binDir, 0o755))
+	for _, name := range []string{"initdb", "pg_ctl"} {
+		assert.NilError(t, os.WriteFile(filepath.Join(binDir, name), nil, 0o755))
+	}
+
+	t.Setenv(testDBBinariesVar, binDir)
+	_, err := localBinaries()
+	assert.ErrorContains(t, err, "no Postgres binaries found")
+
+	assert.NilError(t, os.WriteFile(filepath.Join(binDir, "postgres"), nil, 0o755))
+	binaries, err := localBinaries()
+	assert.NilError(t, err)
+	assert.Equal(t, binaries, installation)
+
+	// without the variable, the installation of the pg_ctl on the PATH is used
+	assert.NilError(t, os.Unsetenv(testDBBinariesVar))
+	t.Setenv("PATH", binDir)
+	binaries, err = localBinaries()
+	assert.NilError(t, err)
+	assert.Equal(t, binaries, installation)
+
+	t.Setenv("PATH", t.TempDir())
+	binaries, err = localBinaries()
+	assert.NilError(t, err)
+	assert.Equal(t, binaries, "")
+}
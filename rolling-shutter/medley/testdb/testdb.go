@@ -2,12 +2,20 @@ package testdb
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"os"
+	"sync"
 	"testing"
 
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/cltrdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testlog"
@@ -38,18 +46,13 @@ BEGIN
 END $$;
 `
 
-// NewTestDBPool connects to a test db specified an environment variable and clears it from all
-// schemas we might have created. It returns the db connection pool and a close function. Call the
-// close function at the end of the test to reset the db again and close the connection.
-func NewTestDBPool(ctx context.Context, t *testing.T) (*pgxpool.Pool, func()) {
+// NewTestDBPool connects to the test db and clears it from all schemas we might have created. It
+// returns the db connection pool and a close function. Call the close function at the end of the
+// test to reset the db again and close the connection.
+func NewTestDBPool(ctx context.Context, t testing.TB) (*pgxpool.Pool, func()) {
 	t.Helper()
 
-	testDBURL, exists := os.LookupEnv(testDBURLVar)
-	if !exists {
-		t.Skipf("no test db specified, please set %s", testDBURLVar)
-	}
-
-	dbpool, err := pgxpool.Connect(ctx, testDBURL)
+	dbpool, err := pgxpool.Connect(ctx, testDBURL(t))
 	if err != nil {
 		t.Fatalf("failed to connect to test db: %v", err)
 	}
@@ -72,29 +75,227 @@ func NewTestDBPool(ctx context.Context, t *testing.T) (*pgxpool.Pool, func()) {
 	return dbpool, closedb
 }
 
-func NewKeyperTestDB(ctx context.Context, t *testing.T) (*kprdb.Queries, *pgxpool.Pool, func()) {
+// errCannotCreateDB is returned by newTemplatedDB if the test db role isn't allowed to create
+// databases. The test then falls back to initializing the schemas in the test db itself.
+var errCannotCreateDB = errors.New("test db role cannot create databases")
+
+// templates holds the names of the template databases this process has made sure to exist.
+var (
+	templatesMu sync.Mutex
+	templates   = make(map[string]bool)
+)
+
+func testDBURL(t testing.TB) string {
 	t.Helper()
 
-	dbpool, closedb := NewTestDBPool(ctx, t)
-	db := kprdb.New(dbpool)
-	err := kprdb.InitDB(ctx, dbpool)
+	if url, exists := os.LookupEnv(testDBURLVar); exists {
+		return url
+	}
+	url, err := embeddedDBURL()
 	if err != nil {
-		closedb()
-		t.Fatalf("failed to initialize keyper db")
+		// a CI run skipping all integration tests would pass without having tested anything
+		if _, ci := os.LookupEnv("CI"); ci {
+			t.Fatalf("no test db available: %v", err)
+		}
+		t.Skipf("no test db available, set %s to use an existing one or %s to use local Postgres binaries: %v",
+			testDBURLVar, testDBBinariesVar, err)
+	}
+	return url
+}
+
+func connect(ctx context.Context, url string, database string) (*pgxpool.Pool, error) {
+	config, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	if database != "" {
+		config.ConnConfig.Database = database
+	}
+	return pgxpool.ConnectConfig(ctx, config)
+}
+
+func isInsufficientPrivilege(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "42501"
+}
+
+// ensureTemplate makes sure that the template database for the given kind of db exists and
+// returns its name. The name contains a fingerprint of all schemas, so a new template is created
+// whenever a schema changes. Templates of outdated schemas are left in place and can be dropped
+// by hand.
+func ensureTemplate(
+	ctx context.Context,
+	admin *pgxpool.Pool,
+	url string,
+	kind string,
+	initDB func(context.Context, *pgxpool.Pool) error,
+) (string, error) {
+	name := fmt.Sprintf("rs_template_%s_%x", kind, db.SchemaFingerprint()[:6])
+
+	templatesMu.Lock()
+	defer templatesMu.Unlock()
+	if templates[name] {
+		return name, nil
+	}
+
+	conn, err := admin.Acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Release()
+	// The tests of different packages run in parallel processes, so creating the template is
+	// serialized with an advisory lock.
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock(hashtext($1))", name); err != nil {
+		return "", err
 	}
-	return db, dbpool, closedb
+	defer func() {
+		_, _ = conn.Exec(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", name)
+	}()
+
+	var exists bool
+	err = conn.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)", name).Scan(&exists)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		if err := createTemplate(ctx, conn, url, name, initDB); err != nil {
+			return "", err
+		}
+	}
+	templates[name] = true
+	return name, nil
 }
 
-func NewCollatorTestDB(ctx context.Context, t *testing.T) (*cltrdb.Queries, *pgxpool.Pool, func()) {
+// createTemplate initializes the template database under a temporary name and only renames it
+// once it is complete, so that a failed initialization never leaves a broken template behind.
+func createTemplate(
+	ctx context.Context,
+	conn *pgxpool.Conn,
+	url string,
+	name string,
+	initDB func(context.Context, *pgxpool.Pool) error,
+) error {
+	tmpName := name + "_init"
+	tmp := pgx.Identifier{tmpName}.Sanitize()
+	if _, err := conn.Exec(ctx, "DROP DATABASE IF EXISTS "+tmp); err != nil {
+		return err
+	}
+	if _, err := conn.Exec(ctx, "CREATE DATABASE "+tmp); err != nil {
+		if isInsufficientPrivilege(err) {
+			return errCannotCreateDB
+		}
+		return err
+	}
+
+	dbpool, err := connect(ctx, url, tmpName)
+	if err == nil {
+		err = initDB(ctx, dbpool)
+		dbpool.Close()
+	}
+	if err != nil {
+		_, _ = conn.Exec(ctx, "DROP DATABASE IF EXISTS "+tmp)
+		return errors.Wrapf(err, "failed to initialize template database %s", name)
+	}
+	_, err = conn.Exec(ctx, fmt.Sprintf("ALTER DATABASE %s RENAME TO %s", tmp, pgx.Identifier{name}.Sanitize()))
+	return err
+}
+
+// newTemplatedDB creates a throwaway database for a single test by copying the template database
+// of the given kind, which is much faster than creating all schemas from scratch. It returns a
+// pool connected to the new database and a close function that drops it again.
+func newTemplatedDB(
+	ctx context.Context,
+	t testing.TB,
+	kind string,
+	initDB func(context.Context, *pgxpool.Pool) error,
+) (*pgxpool.Pool, func(), error) {
 	t.Helper()
 
-	dbpool, closedb := NewTestDBPool(ctx, t)
-	db := cltrdb.New(dbpool)
-	err := cltrdb.InitDB(ctx, dbpool)
+	url := testDBURL(t)
+	admin, err := pgxpool.Connect(ctx, url)
+	if err != nil {
+		t.Fatalf("failed to connect to test db: %v", err)
+	}
+	template, err := ensureTemplate(ctx, admin, url, kind, initDB)
+	if err != nil {
+		admin.Close()
+		return nil, nil, err
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		admin.Close()
+		return nil, nil, err
+	}
+	name := pgx.Identifier{"rs_test_" + hex.EncodeToString(suffix)}
+	_, err = admin.Exec(ctx, fmt.Sprintf("CREATE DATABASE %s TEMPLATE %s", name.Sanitize(), pgx.Identifier{template}.Sanitize()))
 	if err != nil {
-		log.Error().Err(err).Msg("cltrdb.Initdb failed")
+		admin.Close()
+		if isInsufficientPrivilege(err) {
+			return nil, nil, errCannotCreateDB
+		}
+		return nil, nil, err
+	}
+
+	dbpool, err := connect(ctx, url, name[0])
+	if err != nil {
+		_, _ = admin.Exec(ctx, "DROP DATABASE "+name.Sanitize())
+		admin.Close()
+		t.Fatalf("failed to connect to test db: %v", err)
+	}
+	closedb := func() {
+		dbpool.Close()
+		// the server may not have noticed yet that the connections of the pool are closed
+		_, err := admin.Exec(context.Background(), "DROP DATABASE "+name.Sanitize()+" WITH (FORCE)")
+		admin.Close() // close db no matter if dropping failed
+		if err != nil {
+			t.Fatalf("failed to drop test db: %v", err)
+		}
+	}
+	return dbpool, closedb, nil
+}
+
+// newInitializedTestDB returns a pool connected to a database initialized with initDB. It uses a
+// throwaway copy of a template database if the test db role may create databases, and otherwise
+// initializes the test db itself.
+func newInitializedTestDB(
+	ctx context.Context,
+	t testing.TB,
+	kind string,
+	initDB func(context.Context, *pgxpool.Pool) error,
+) (*pgxpool.Pool, func()) {
+	t.Helper()
+
+	dbpool, closedb, err := newTemplatedDB(ctx, t, kind, initDB)
+	if err == nil {
+		return dbpool, closedb
+	}
+	if err != errCannotCreateDB {
+		t.Fatalf("failed to create %s test db: %v", kind, err)
+	}
+
+	dbpool, closedb = NewTestDBPool(ctx, t)
+	err = initDB(ctx, dbpool)
+	if err != nil {
+		log.Error().Err(err).Str("kind", kind).Msg("failed to initialize test db")
 		closedb()
-		t.Fatalf("failed to initialize collator db")
+		t.Fatalf("failed to initialize %s test db", kind)
 	}
-	return db, dbpool, closedb
+	return dbpool, closedb
+}
+
+// NewKeyperTestDB returns a fresh keyper database for a single test.
+func NewKeyperTestDB(ctx context.Context, t testing.TB) (*kprdb.Queries, *pgxpool.Pool, func()) {
+	t.Helper()
+
+	dbpool, closedb := newInitializedTestDB(ctx, t, "keyper", kprdb.InitDB)
+	return kprdb.New(dbpool), dbpool, closedb
+}
+
+// NewCollatorTestDB returns a fresh collator database for a single test.
+func NewCollatorTestDB(ctx context.Context, t testing.TB) (*cltrdb.Queries, *pgxpool.Pool, func()) {
+	t.Helper()
+
+	dbpool, closedb := newInitializedTestDB(ctx, t, "collator", cltrdb.InitDB)
+	return cltrdb.New(dbpool), dbpool, closedb
 }
@@ -21,6 +21,16 @@ func (kks *KeyperKeyShares) ComputeEpochSecretKeyShare(epochID epochid.EpochID)
 	return shcrypto.ComputeEpochSecretKeyShare(kks.eonSecretKeyShare, epochIDG1)
 }
 
+// EonSecretKeyShare returns the keyper's share of the eon secret key.
+func (kks *KeyperKeyShares) EonSecretKeyShare() *shcrypto.EonSecretKeyShare {
+	return kks.eonSecretKeyShare
+}
+
+// EonPublicKeyShare returns the keyper's share of the eon public key.
+func (kks *KeyperKeyShares) EonPublicKeyShare() *shcrypto.EonPublicKeyShare {
+	return kks.eonPublicKeyShare
+}
+
 // EonKeys holds all keys for one eon.
 type EonKeys struct {
 	publicKey    *shcrypto.EonPublicKey
@@ -65,6 +75,16 @@ func NewEonKeys(random io.Reader, numKeypers uint64, threshold uint64) (*EonKeys
 	}, nil
 }
 
+// PublicKey returns the eon public key.
+func (eonkeys *EonKeys) PublicKey() *shcrypto.EonPublicKey {
+	return eonkeys.publicKey
+}
+
+// KeyperShares returns the key shares of the keyper with the given index.
+func (eonkeys *EonKeys) KeyperShares(keyperIndex uint64) *KeyperKeyShares {
+	return &eonkeys.keyperShares[keyperIndex]
+}
+
 func (eonkeys *EonKeys) getEpochSecretKeyShares(
 	epochID epochid.EpochID,
 	keyperIndices []int,
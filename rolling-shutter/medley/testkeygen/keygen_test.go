@@ -146,3 +146,18 @@ func BenchmarkFullBlock(b *testing.B) {
 		decryptBlock(b, bb, keyperIndices, shares)
 	}
 }
+
+// BenchmarkVerifyEpochSecretKeyShare benchmarks the verification of a single epoch secret key
+// share against the sender's eon public key share, as done for every incoming key share.
+func BenchmarkVerifyEpochSecretKeyShare(b *testing.B) {
+	ek, err := NewEonKeys(random, numKeypers, threshold)
+	assert.NilError(b, err)
+	epochID := epochid.Uint64ToEpochID(55)
+	share := ek.keyperShares[0].ComputeEpochSecretKeyShare(epochID)
+	epochIDG1 := shcrypto.ComputeEpochID(epochID.Bytes())
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		ok := shcrypto.VerifyEpochSecretKeyShare(share, ek.keyperShares[0].eonPublicKeyShare, epochIDG1)
+		assert.Assert(b, ok)
+	}
+}
@@ -14,14 +14,14 @@ import (
 // TestKeyGenerator is a helper tool to generate secret and public eon and epoch keys and key
 // shares. It will generate a new eon key every eonInterval epochs.
 type TestKeyGenerator struct {
-	t           *testing.T
+	t           testing.TB
 	eonInterval uint64
 	eonKeyGen   map[uint64]*EonKeys
 	NumKeypers  uint64
 	Threshold   uint64
 }
 
-func NewTestKeyGenerator(t *testing.T, numKeypers uint64, threshold uint64) *TestKeyGenerator {
+func NewTestKeyGenerator(t testing.TB, numKeypers uint64, threshold uint64) *TestKeyGenerator {
 	t.Helper()
 	return &TestKeyGenerator{
 		t:           t,
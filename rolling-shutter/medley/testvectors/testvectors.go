@@ -0,0 +1,243 @@
+// Package testvectors generates deterministic test vectors for the threshold encryption scheme and
+// the messages keypers sign, so that other implementations, e.g. the JS and Python clients, can be
+// checked against this one.
+package testvectors
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testkeygen"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// messageLengths are the lengths of the encrypted messages, cycled through for consecutive epochs.
+// They cover messages shorter than, equal to and longer than a block.
+var messageLengths = []int{1, 31, 32, 33, 64, 100}
+
+// Vectors is a set of test vectors for a single eon.
+type Vectors struct {
+	Seed              int64         `json:"seed"`
+	NumKeypers        uint64        `json:"numKeypers"`
+	Threshold         uint64        `json:"threshold"`
+	InstanceID        uint64        `json:"instanceID"`
+	Eon               uint64        `json:"eon"`
+	ActivationBlock   uint64        `json:"activationBlock"`
+	KeyperConfigIndex uint64        `json:"keyperConfigIndex"`
+	EonPublicKey      hexutil.Bytes `json:"eonPublicKey"`
+	// EonPublicKeyHash is the hash of the eon public key message the keypers sign.
+	EonPublicKeyHash hexutil.Bytes `json:"eonPublicKeyHash"`
+	Keypers          []Keyper      `json:"keypers"`
+	Epochs           []Epoch       `json:"epochs"`
+}
+
+// Keyper contains the keys of a single keyper.
+type Keyper struct {
+	Index                 uint64         `json:"index"`
+	PrivateKey            hexutil.Bytes  `json:"privateKey"`
+	Address               common.Address `json:"address"`
+	EonSecretKeyShare     hexutil.Bytes  `json:"eonSecretKeyShare"`
+	EonPublicKeyShare     hexutil.Bytes  `json:"eonPublicKeyShare"`
+	EonPublicKeySignature hexutil.Bytes  `json:"eonPublicKeySignature"`
+}
+
+// Epoch contains the keys of a single epoch and a message encrypted for it.
+type Epoch struct {
+	EpochID              hexutil.Bytes   `json:"epochID"`
+	EpochSecretKeyShares []hexutil.Bytes `json:"epochSecretKeyShares"`
+	EpochSecretKey       hexutil.Bytes   `json:"epochSecretKey"`
+	Message              hexutil.Bytes   `json:"message"`
+	Sigma                hexutil.Bytes   `json:"sigma"`
+	EncryptedMessage     hexutil.Bytes   `json:"encryptedMessage"`
+}
+
+// Generate generates test vectors for numEpochs epochs. The output only depends on the arguments.
+func Generate(seed int64, numKeypers, threshold uint64, numEpochs int) (*Vectors, error) {
+	if threshold == 0 || threshold > numKeypers {
+		return nil, errors.Errorf("threshold must be between 1 and the number of keypers (%d), got %d",
+			numKeypers, threshold)
+	}
+	random := rand.New(rand.NewSource(seed)) //nolint:gosec // the vectors have to be reproducible
+
+	eonKeys, err := testkeygen.NewEonKeys(random, numKeypers, threshold)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate eon keys")
+	}
+	v := &Vectors{
+		Seed:              seed,
+		NumKeypers:        numKeypers,
+		Threshold:         threshold,
+		InstanceID:        uint64(seed),
+		Eon:               1,
+		ActivationBlock:   100,
+		KeyperConfigIndex: 1,
+		EonPublicKey:      eonKeys.PublicKey().Marshal(),
+	}
+	msg := &p2pmsg.EonPublicKey{
+		InstanceID:        v.InstanceID,
+		PublicKey:         v.EonPublicKey,
+		ActivationBlock:   v.ActivationBlock,
+		KeyperConfigIndex: v.KeyperConfigIndex,
+		Eon:               v.Eon,
+	}
+	v.EonPublicKeyHash = msg.Hash()
+
+	for i := uint64(0); i < numKeypers; i++ {
+		privKeyBytes, err := readBytes(random, 32)
+		if err != nil {
+			return nil, err
+		}
+		privKey, err := ethcrypto.ToECDSA(privKeyBytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate keyper private key")
+		}
+		signature, err := ethcrypto.Sign(v.EonPublicKeyHash, privKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to sign eon public key")
+		}
+		shares := eonKeys.KeyperShares(i)
+		v.Keypers = append(v.Keypers, Keyper{
+			Index:                 i,
+			PrivateKey:            privKeyBytes,
+			Address:               ethcrypto.PubkeyToAddress(privKey.PublicKey),
+			EonSecretKeyShare:     shares.EonSecretKeyShare().Marshal(),
+			EonPublicKeyShare:     shares.EonPublicKeyShare().Marshal(),
+			EonPublicKeySignature: signature,
+		})
+	}
+
+	for i := 0; i < numEpochs; i++ {
+		epochID := epochid.Uint64ToEpochID(uint64(i))
+		epoch := Epoch{EpochID: epochID.Bytes()}
+		for j := uint64(0); j < numKeypers; j++ {
+			share := eonKeys.KeyperShares(j).ComputeEpochSecretKeyShare(epochID)
+			epoch.EpochSecretKeyShares = append(epoch.EpochSecretKeyShares, share.Marshal())
+		}
+		epochSecretKey, err := eonKeys.EpochSecretKey(epochID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to compute epoch secret key")
+		}
+		epoch.EpochSecretKey = epochSecretKey.Marshal()
+
+		epoch.Message, err = readBytes(random, messageLengths[i%len(messageLengths)])
+		if err != nil {
+			return nil, err
+		}
+		var sigma shcrypto.Block
+		if _, err := io.ReadFull(random, sigma[:]); err != nil {
+			return nil, errors.Wrap(err, "failed to generate sigma")
+		}
+		epoch.Sigma = sigma[:]
+		encrypted := shcrypto.Encrypt(
+			epoch.Message, eonKeys.PublicKey(), shcrypto.ComputeEpochID(epoch.EpochID), sigma,
+		)
+		epoch.EncryptedMessage = encrypted.Marshal()
+		v.Epochs = append(v.Epochs, epoch)
+	}
+	return v, nil
+}
+
+func readBytes(r io.Reader, n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, errors.Wrap(err, "failed to read random bytes")
+	}
+	return b, nil
+}
+
+// Verify checks that the test vectors are consistent, using only the encoded values. It's meant
+// to check fixtures that have been written to disk.
+func Verify(v *Vectors) error {
+	eonPublicKey := new(shcrypto.EonPublicKey)
+	if err := eonPublicKey.Unmarshal(v.EonPublicKey); err != nil {
+		return errors.Wrap(err, "invalid eon public key")
+	}
+	if len(v.Keypers) != int(v.NumKeypers) {
+		return errors.Errorf("expected %d keypers, got %d", v.NumKeypers, len(v.Keypers))
+	}
+	msg := &p2pmsg.EonPublicKey{
+		InstanceID:        v.InstanceID,
+		PublicKey:         v.EonPublicKey,
+		ActivationBlock:   v.ActivationBlock,
+		KeyperConfigIndex: v.KeyperConfigIndex,
+		Eon:               v.Eon,
+	}
+	if !bytes.Equal(msg.Hash(), v.EonPublicKeyHash) {
+		return errors.New("eon public key hash mismatch")
+	}
+
+	eonPublicKeyShares := make([]*shcrypto.EonPublicKeyShare, len(v.Keypers))
+	for i, k := range v.Keypers {
+		eonPublicKeyShares[i] = new(shcrypto.EonPublicKeyShare)
+		if err := eonPublicKeyShares[i].Unmarshal(k.EonPublicKeyShare); err != nil {
+			return errors.Wrapf(err, "invalid eon public key share of keyper %d", i)
+		}
+		msg.Signature = k.EonPublicKeySignature
+		ok, err := p2pmsg.VerifySignature(msg, k.Address)
+		if err != nil {
+			return errors.Wrapf(err, "failed to verify eon public key signature of keyper %d", i)
+		}
+		if !ok {
+			return errors.Errorf("invalid eon public key signature of keyper %d", i)
+		}
+	}
+
+	for i, epoch := range v.Epochs {
+		epochID := shcrypto.ComputeEpochID(epoch.EpochID)
+		if len(epoch.EpochSecretKeyShares) != len(v.Keypers) {
+			return errors.Errorf("expected %d epoch secret key shares in epoch %d, got %d",
+				len(v.Keypers), i, len(epoch.EpochSecretKeyShares))
+		}
+		for j, encodedShare := range epoch.EpochSecretKeyShares {
+			share := new(shcrypto.EpochSecretKeyShare)
+			if err := share.Unmarshal(encodedShare); err != nil {
+				return errors.Wrapf(err, "invalid epoch secret key share %d in epoch %d", j, i)
+			}
+			if !shcrypto.VerifyEpochSecretKeyShare(share, eonPublicKeyShares[j], epochID) {
+				return errors.Errorf("invalid epoch secret key share %d in epoch %d", j, i)
+			}
+		}
+		epochSecretKey := new(shcrypto.EpochSecretKey)
+		if err := epochSecretKey.Unmarshal(epoch.EpochSecretKey); err != nil {
+			return errors.Wrapf(err, "invalid epoch secret key in epoch %d", i)
+		}
+		ok, err := shcrypto.VerifyEpochSecretKey(epochSecretKey, eonPublicKey, epoch.EpochID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to verify epoch secret key in epoch %d", i)
+		}
+		if !ok {
+			return errors.Errorf("invalid epoch secret key in epoch %d", i)
+		}
+
+		var sigma shcrypto.Block
+		if len(epoch.Sigma) != len(sigma) {
+			return errors.Errorf("invalid sigma length %d in epoch %d", len(epoch.Sigma), i)
+		}
+		copy(sigma[:], epoch.Sigma)
+		reencrypted := shcrypto.Encrypt(epoch.Message, eonPublicKey, epochID, sigma)
+		if !bytes.Equal(reencrypted.Marshal(), epoch.EncryptedMessage) {
+			return errors.Errorf("encrypted message mismatch in epoch %d", i)
+		}
+		encrypted := new(shcrypto.EncryptedMessage)
+		if err := encrypted.Unmarshal(epoch.EncryptedMessage); err != nil {
+			return errors.Wrapf(err, "invalid encrypted message in epoch %d", i)
+		}
+		decrypted, err := encrypted.Decrypt(epochSecretKey)
+		if err != nil {
+			return errors.Wrapf(err, "failed to decrypt message in epoch %d", i)
+		}
+		if !bytes.Equal(decrypted, epoch.Message) {
+			return errors.Errorf("decrypted message mismatch in epoch %d", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package testvectors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	v, err := Generate(1, 3, 2, 4)
+	assert.NilError(t, err)
+	assert.Equal(t, len(v.Keypers), 3)
+	assert.Equal(t, len(v.Epochs), 4)
+	assert.NilError(t, Verify(v))
+
+	again, err := Generate(1, 3, 2, 4)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, again, v)
+
+	other, err := Generate(2, 3, 2, 4)
+	assert.NilError(t, err)
+	assert.Check(t, other.EonPublicKey.String() != v.EonPublicKey.String())
+
+	data, err := json.Marshal(v)
+	assert.NilError(t, err)
+	decoded := &Vectors{}
+	assert.NilError(t, json.Unmarshal(data, decoded))
+	assert.DeepEqual(t, decoded, v)
+	assert.NilError(t, Verify(decoded))
+
+	decoded.Epochs[1].Message[0] ^= 1
+	assert.ErrorContains(t, Verify(decoded), "epoch 1")
+
+	_, err = Generate(1, 2, 3, 1)
+	assert.ErrorContains(t, err, "threshold")
+}
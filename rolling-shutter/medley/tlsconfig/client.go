@@ -0,0 +1,106 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+func NewClientConfig() *ClientConfig {
+	c := &ClientConfig{}
+	c.Init()
+	return c
+}
+
+// ClientConfig configures the TLS settings used when connecting to another service. If a
+// certificate and key are given, they are presented to the server (mTLS).
+type ClientConfig struct {
+	CAFile   string `comment:"PEM encoded CA certificates used to verify the server, system roots if empty"`
+	CertFile string `comment:"PEM encoded client certificate chain for mTLS"`
+	KeyFile  string `comment:"PEM encoded client private key for mTLS"`
+}
+
+func (c *ClientConfig) Init() {}
+
+func (c *ClientConfig) Name() string {
+	return "tlsclient"
+}
+
+func (c *ClientConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("client certificate and key file must be configured together")
+	}
+	return nil
+}
+
+func (c *ClientConfig) SetDefaultValues() error {
+	return nil
+}
+
+func (c *ClientConfig) SetExampleValues() error {
+	return nil
+}
+
+func (c *ClientConfig) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
+
+func (c *ClientConfig) isSet() bool {
+	return c != nil && (c.CAFile != "" || c.CertFile != "")
+}
+
+// TLSConfig builds the tls.Config to be used by clients. It returns nil if nothing has been
+// configured, in which case the default settings should be used.
+func (c *ClientConfig) TLSConfig() (*tls.Config, error) {
+	if !c.isSet() {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	var err error
+	if c.CAFile != "" {
+		tlsConfig.RootCAs, err = loadCertPool(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// HTTPClient returns a http.Client using the configured TLS settings.
+func (c *ClientConfig) HTTPClient() (*http.Client, error) {
+	tlsConfig, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil {
+		return http.DefaultClient, nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
+}
+
+// DialRPC connects a JSON RPC client to the given URL using the configured TLS settings.
+func (c *ClientConfig) DialRPC(ctx context.Context, rawurl string) (*rpc.Client, error) {
+	if !c.isSet() {
+		return rpc.DialContext(ctx, rawurl)
+	}
+	httpClient, err := c.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return rpc.DialOptions(ctx, rawurl, rpc.WithHTTPClient(httpClient))
+}
@@ -0,0 +1,112 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestClientConfigValidate(t *testing.T) {
+	assert.NilError(t, (&ClientConfig{}).Validate())
+	assert.NilError(t, (&ClientConfig{CertFile: "client.crt", KeyFile: "client.key"}).Validate())
+	assert.ErrorContains(t, (&ClientConfig{CertFile: "client.crt"}).Validate(), "configured together")
+	assert.ErrorContains(t, (&ClientConfig{KeyFile: "client.key"}).Validate(), "configured together")
+}
+
+func TestClientConfigTLSConfig(t *testing.T) {
+	pki := newTestPKI(t)
+	testCases := []struct {
+		name    string
+		config  *ClientConfig
+		unset   bool
+		rootCAs bool
+		certs   int
+		err     string
+	}{
+		{name: "nil", config: nil, unset: true},
+		{name: "empty", config: &ClientConfig{}, unset: true},
+		{name: "CA", config: &ClientConfig{CAFile: pki.caFile}, rootCAs: true},
+		{name: "client cert", config: &ClientConfig{CertFile: pki.clientCert, KeyFile: pki.clientKey}, certs: 1},
+		{
+			name:    "CA and client cert",
+			config:  &ClientConfig{CAFile: pki.caFile, CertFile: pki.clientCert, KeyFile: pki.clientKey},
+			rootCAs: true,
+			certs:   1,
+		},
+		{name: "missing CA", config: &ClientConfig{CAFile: pki.missingFile}, err: "failed to read CA file"},
+		{name: "invalid CA", config: &ClientConfig{CAFile: pki.notPEMFile}, err: "no certificates found"},
+		{
+			name:   "missing key",
+			config: &ClientConfig{CertFile: pki.clientCert, KeyFile: pki.missingFile},
+			err:    "failed to load client certificate",
+		},
+		{
+			name:   "mismatched key",
+			config: &ClientConfig{CertFile: pki.clientCert, KeyFile: pki.serverKey},
+			err:    "failed to load client certificate",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tlsConfig, err := tc.config.TLSConfig()
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			assert.NilError(t, err)
+			if tc.unset {
+				assert.Assert(t, tlsConfig == nil)
+				return
+			}
+			assert.Equal(t, tlsConfig.MinVersion, uint16(tls.VersionTLS12))
+			assert.Equal(t, tlsConfig.RootCAs != nil, tc.rootCAs)
+			assert.Equal(t, len(tlsConfig.Certificates), tc.certs)
+		})
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	serverConfig := &ServerConfig{
+		Enabled:           true,
+		CertFile:          pki.serverCert,
+		KeyFile:           pki.serverKey,
+		ClientCAFile:      pki.caFile,
+		RequireClientCert: true,
+	}
+	tlsConfig, err := serverConfig.TLSConfig()
+	assert.NilError(t, err)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	testCases := []struct {
+		name   string
+		config *ClientConfig
+		err    bool
+	}{
+		{name: "client cert", config: &ClientConfig{CAFile: pki.caFile, CertFile: pki.clientCert, KeyFile: pki.clientKey}},
+		{name: "no client cert", config: &ClientConfig{CAFile: pki.caFile}, err: true},
+		{name: "unknown server CA", config: &ClientConfig{CertFile: pki.clientCert, KeyFile: pki.clientKey}, err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := tc.config.HTTPClient()
+			assert.NilError(t, err)
+			resp, err := client.Get(server.URL)
+			if tc.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, resp.StatusCode, http.StatusNoContent)
+		})
+	}
+}
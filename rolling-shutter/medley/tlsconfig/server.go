@@ -0,0 +1,124 @@
+// Package tlsconfig provides configuration for native TLS support of the HTTP servers and for
+// mutually authenticated connections to other services.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+var (
+	_ configuration.Config = &ServerConfig{}
+	_ configuration.Config = &ClientConfig{}
+)
+
+func NewServerConfig() *ServerConfig {
+	c := &ServerConfig{}
+	c.Init()
+	return c
+}
+
+// ServerConfig configures TLS termination for a HTTP server.
+type ServerConfig struct {
+	Enabled           bool
+	CertFile          string `comment:"PEM encoded server certificate chain"`
+	KeyFile           string `comment:"PEM encoded server private key"`
+	ClientCAFile      string `comment:"PEM encoded CA certificates used to verify client certificates"`
+	RequireClientCert bool   `comment:"Reject clients that don't present a valid certificate (mTLS)"`
+}
+
+func (c *ServerConfig) Init() {}
+
+func (c *ServerConfig) Name() string {
+	return "tls"
+}
+
+func (c *ServerConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return errors.New("TLS enabled, but no certificate or key file configured")
+	}
+	if c.RequireClientCert && c.ClientCAFile == "" {
+		return errors.New("client certificates required, but no client CA file configured")
+	}
+	return nil
+}
+
+func (c *ServerConfig) SetDefaultValues() error {
+	c.Enabled = false
+	c.RequireClientCert = false
+	return nil
+}
+
+func (c *ServerConfig) SetExampleValues() error {
+	c.Enabled = false
+	c.CertFile = "/etc/shutter/tls/server.crt"
+	c.KeyFile = "/etc/shutter/tls/server.key"
+	c.ClientCAFile = "/etc/shutter/tls/ca.crt"
+	c.RequireClientCert = false
+	return nil
+}
+
+func (c *ServerConfig) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read CA file %s", path)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no certificates found in CA file %s", path)
+	}
+	return pool, nil
+}
+
+// TLSConfig builds the tls.Config to be used by the server. It returns nil if TLS is disabled.
+func (c *ServerConfig) TLSConfig() (*tls.Config, error) {
+	if c == nil || !c.Enabled {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load server certificate")
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	if c.ClientCAFile != "" {
+		tlsConfig.ClientCAs, err = loadCertPool(c.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if c.RequireClientCert {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return tlsConfig, nil
+}
+
+// ListenAndServe starts the given server, with TLS if it is enabled.
+func (c *ServerConfig) ListenAndServe(srv *http.Server) error {
+	tlsConfig, err := c.TLSConfig()
+	if err != nil {
+		return err
+	}
+	if tlsConfig == nil {
+		return srv.ListenAndServe()
+	}
+	srv.TLSConfig = tlsConfig
+	return srv.ListenAndServeTLS("", "")
+}
@@ -0,0 +1,193 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// testPKI holds the paths of a CA certificate and of server and client key pairs signed by it.
+type testPKI struct {
+	caFile                  string
+	serverCert, serverKey   string
+	clientCert, clientKey   string
+	notPEMFile, missingFile string
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600)
+	assert.NilError(t, err)
+}
+
+func newTestPKI(t *testing.T) testPKI {
+	t.Helper()
+	dir := t.TempDir()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	assert.NilError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	assert.NilError(t, err)
+
+	pki := testPKI{
+		caFile:      filepath.Join(dir, "ca.crt"),
+		notPEMFile:  filepath.Join(dir, "not-pem.crt"),
+		missingFile: filepath.Join(dir, "missing.crt"),
+	}
+	writePEM(t, pki.caFile, "CERTIFICATE", caDER)
+	assert.NilError(t, os.WriteFile(pki.notPEMFile, []byte("not a certificate"), 0o600))
+
+	issue := func(name string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.NilError(t, err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		assert.NilError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		assert.NilError(t, err)
+		certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+		writePEM(t, certFile, "CERTIFICATE", der)
+		writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+		return certFile, keyFile
+	}
+	pki.serverCert, pki.serverKey = issue("server", 2, x509.ExtKeyUsageServerAuth)
+	pki.clientCert, pki.clientKey = issue("client", 3, x509.ExtKeyUsageClientAuth)
+	return pki
+}
+
+func TestServerConfigValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config ServerConfig
+		err    string
+	}{
+		{name: "disabled", config: ServerConfig{}},
+		{name: "enabled", config: ServerConfig{Enabled: true, CertFile: "server.crt", KeyFile: "server.key"}},
+		{
+			name:   "no key",
+			config: ServerConfig{Enabled: true, CertFile: "server.crt"},
+			err:    "no certificate or key file",
+		},
+		{
+			name:   "client cert without CA",
+			config: ServerConfig{Enabled: true, CertFile: "server.crt", KeyFile: "server.key", RequireClientCert: true},
+			err:    "no client CA file",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.err == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestServerConfigTLSConfig(t *testing.T) {
+	pki := newTestPKI(t)
+	testCases := []struct {
+		name       string
+		config     *ServerConfig
+		disabled   bool
+		clientAuth tls.ClientAuthType
+		err        string
+	}{
+		{name: "nil", config: nil, disabled: true},
+		{name: "disabled", config: &ServerConfig{CertFile: pki.serverCert, KeyFile: pki.serverKey}, disabled: true},
+		{
+			name:       "no client CA",
+			config:     &ServerConfig{Enabled: true, CertFile: pki.serverCert, KeyFile: pki.serverKey},
+			clientAuth: tls.NoClientCert,
+		},
+		{
+			name: "optional client cert",
+			config: &ServerConfig{
+				Enabled: true, CertFile: pki.serverCert, KeyFile: pki.serverKey, ClientCAFile: pki.caFile,
+			},
+			clientAuth: tls.VerifyClientCertIfGiven,
+		},
+		{
+			name: "required client cert",
+			config: &ServerConfig{
+				Enabled: true, CertFile: pki.serverCert, KeyFile: pki.serverKey, ClientCAFile: pki.caFile,
+				RequireClientCert: true,
+			},
+			clientAuth: tls.RequireAndVerifyClientCert,
+		},
+		{
+			name:   "missing key",
+			config: &ServerConfig{Enabled: true, CertFile: pki.serverCert, KeyFile: pki.missingFile},
+			err:    "failed to load server certificate",
+		},
+		{
+			name:   "mismatched key",
+			config: &ServerConfig{Enabled: true, CertFile: pki.serverCert, KeyFile: pki.clientKey},
+			err:    "failed to load server certificate",
+		},
+		{
+			name: "missing client CA",
+			config: &ServerConfig{
+				Enabled: true, CertFile: pki.serverCert, KeyFile: pki.serverKey, ClientCAFile: pki.missingFile,
+			},
+			err: "failed to read CA file",
+		},
+		{
+			name: "invalid client CA",
+			config: &ServerConfig{
+				Enabled: true, CertFile: pki.serverCert, KeyFile: pki.serverKey, ClientCAFile: pki.notPEMFile,
+			},
+			err: "no certificates found",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tlsConfig, err := tc.config.TLSConfig()
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			assert.NilError(t, err)
+			if tc.disabled {
+				assert.Assert(t, tlsConfig == nil)
+				return
+			}
+			assert.Equal(t, tlsConfig.MinVersion, uint16(tls.VersionTLS12))
+			assert.Equal(t, len(tlsConfig.Certificates), 1)
+			assert.Equal(t, tlsConfig.ClientAuth, tc.clientAuth)
+			assert.Equal(t, tlsConfig.ClientCAs != nil, tc.config.ClientCAFile != "")
+		})
+	}
+}
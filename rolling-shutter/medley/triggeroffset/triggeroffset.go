@@ -0,0 +1,127 @@
+// Package triggeroffset implements the offset of decryption triggers against the nominal boundary
+// of their epoch, i.e. the time the collator's epoch ticks and the block that is the latest one at
+// that time. On chains whose blocks arrive with jitter, that block is often not yet known to all
+// keypers when the trigger arrives, or is about to be followed by the block it should have been.
+// With an offset, the collator triggers decryption only once a number of further blocks have been
+// produced and some time has passed. Keypers apply the same offset to the pre-announcement of an
+// epoch to reject triggers sent prematurely.
+package triggeroffset
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/paramregistry"
+)
+
+// Offset is the distance between the nominal epoch boundary and the decryption trigger. The zero
+// value triggers at the boundary.
+type Offset struct {
+	Blocks uint64
+	Delay  time.Duration
+}
+
+// IsZero reports whether triggers are sent at the boundary.
+func (o Offset) IsZero() bool {
+	return o.Blocks == 0 && o.Delay == 0
+}
+
+// Validate checks that the offset is shorter than an epoch, assuming a block is produced every
+// blockTime. Otherwise the trigger of an epoch would only be sent after the next epoch had ended.
+func (o Offset) Validate(epochDuration, blockTime time.Duration) error {
+	if o.Delay < 0 {
+		return errors.Errorf("trigger offset must not be negative, got %s", o.Delay)
+	}
+	if o.Blocks > 0 {
+		if blockTime <= 0 {
+			return errors.Errorf("block time must be positive, got %s", blockTime)
+		}
+		if o.Blocks > uint64(math.MaxInt64/blockTime) {
+			return errors.Errorf("trigger offset of %d blocks is too large", o.Blocks)
+		}
+	}
+	total := o.Delay + time.Duration(o.Blocks)*blockTime
+	if total < o.Delay || total >= epochDuration {
+		return errors.Errorf(
+			"trigger offset of %d blocks and %s must be shorter than the epoch duration %s, "+
+				"got %s at a block time of %s",
+			o.Blocks, o.Delay, epochDuration, total, blockTime,
+		)
+	}
+	return nil
+}
+
+// Load returns the offset stored in the protocol parameters of the deployment.
+func Load(ctx context.Context, params *paramregistry.Registry) (Offset, error) {
+	blocks, err := params.Uint64(ctx, paramregistry.TriggerOffsetBlocks)
+	if err != nil {
+		return Offset{}, err
+	}
+	delay, err := params.Duration(ctx, paramregistry.TriggerOffset)
+	if err != nil {
+		return Offset{}, err
+	}
+	return Offset{Blocks: blocks, Delay: delay}, nil
+}
+
+// HeadFunc returns the number of the latest block.
+type HeadFunc func(ctx context.Context) (uint64, error)
+
+// Wait blocks until the offset has passed since the boundary at the given block and time. head is
+// polled every pollInterval until it has advanced by Blocks. Errors of head are logged and
+// retried, so that a flaky node delays the trigger instead of skipping it. Wait returns the last
+// head seen, or the boundary block if the offset has no blocks.
+func (o Offset) Wait(
+	ctx context.Context, boundaryBlock uint64, boundaryTime time.Time, head HeadFunc, pollInterval time.Duration,
+) (uint64, error) {
+	if wait := time.Until(boundaryTime.Add(o.Delay)); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	if o.Blocks == 0 {
+		return boundaryBlock, nil
+	}
+	target := boundaryBlock + o.Blocks
+	for {
+		blockNumber, err := head(ctx)
+		if err != nil {
+			log.Warn().Err(err).Uint64("target-block", target).Msg("failed to get latest block")
+		} else if blockNumber >= target {
+			return blockNumber, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Check checks that a trigger in the given block, received at now, isn't premature for an epoch
+// whose boundary has been announced for the given block and time. Since the announced block is
+// the latest one some time before the boundary, a trigger sent with the offset can't refer to an
+// earlier block than the announced one plus Blocks. tolerance allows for the difference between
+// the clocks of the collator and the keyper. Parts of the offset that are zero are not checked.
+func (o Offset) Check(
+	announcedBlock uint64, announcedTime time.Time, triggerBlock uint64, now time.Time, tolerance time.Duration,
+) error {
+	if o.Blocks > 0 && triggerBlock < announcedBlock+o.Blocks {
+		return errors.Errorf("trigger block %d is less than %d blocks after announced block %d",
+			triggerBlock, o.Blocks, announcedBlock)
+	}
+	if o.Delay > 0 {
+		earliest := announcedTime.Add(o.Delay)
+		if now.Before(earliest.Add(-tolerance)) {
+			return errors.Errorf("trigger received %s before %s, the announced time plus the offset of %s",
+				earliest.Sub(now), earliest.Format(time.RFC3339Nano), o.Delay)
+		}
+	}
+	return nil
+}
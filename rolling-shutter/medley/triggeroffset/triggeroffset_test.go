@@ -0,0 +1,69 @@
+package triggeroffset
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+)
+
+func TestCheck(t *testing.T) {
+	announced := time.Unix(1000, 0)
+	offset := Offset{Blocks: 2, Delay: 3 * time.Second}
+
+	assert.NilError(t, offset.Check(10, announced, 12, announced.Add(3*time.Second), 0))
+	assert.NilError(t, offset.Check(10, announced, 15, announced.Add(time.Minute), 0))
+	assert.ErrorContains(t, offset.Check(10, announced, 11, announced.Add(time.Minute), 0), "less than 2 blocks")
+	assert.ErrorContains(t, offset.Check(10, announced, 12, announced.Add(time.Second), 0), "before")
+	// tolerance allows for skewed clocks
+	assert.NilError(t, offset.Check(10, announced, 12, announced.Add(2500*time.Millisecond), time.Second))
+
+	// parts of the offset that are zero are not checked
+	assert.NilError(t, Offset{Delay: time.Second}.Check(10, announced, 9, announced.Add(time.Second), 0))
+	assert.NilError(t, Offset{Blocks: 1}.Check(10, announced, 11, announced.Add(-time.Hour), 0))
+	assert.NilError(t, Offset{}.Check(10, announced, 0, time.Time{}, 0))
+}
+
+func TestWait(t *testing.T) {
+	ctx := context.Background()
+	head := uint64(10)
+	calls := 0
+	headFunc := func(context.Context) (uint64, error) {
+		calls++
+		if calls == 2 {
+			return 0, errors.New("node unavailable")
+		}
+		head++
+		return head, nil
+	}
+
+	blockNumber, err := Offset{Blocks: 3}.Wait(ctx, 10, time.Now(), headFunc, time.Millisecond)
+	assert.NilError(t, err)
+	assert.Equal(t, blockNumber, uint64(13))
+	assert.Equal(t, calls, 4)
+
+	start := time.Now()
+	blockNumber, err = Offset{Delay: 20 * time.Millisecond}.Wait(ctx, 10, start, headFunc, time.Millisecond)
+	assert.NilError(t, err)
+	assert.Equal(t, blockNumber, uint64(10))
+	assert.Assert(t, time.Since(start) >= 20*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Offset{Blocks: 100}.Wait(ctx, 10, time.Now(), headFunc, time.Hour)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestValidate(t *testing.T) {
+	epoch := 10 * time.Second
+	assert.NilError(t, Offset{}.Validate(epoch, 0))
+	assert.NilError(t, Offset{Blocks: 2, Delay: 3 * time.Second}.Validate(epoch, 3*time.Second))
+	assert.ErrorContains(t, Offset{Blocks: 2, Delay: 4 * time.Second}.Validate(epoch, 3*time.Second), "shorter than")
+	assert.ErrorContains(t, Offset{Delay: epoch}.Validate(epoch, time.Second), "shorter than")
+	assert.ErrorContains(t, Offset{Delay: -time.Second}.Validate(epoch, time.Second), "negative")
+	assert.ErrorContains(t, Offset{Blocks: 1}.Validate(epoch, 0), "block time")
+	assert.ErrorContains(t, Offset{Blocks: math.MaxUint64}.Validate(epoch, time.Second), "too large")
+}
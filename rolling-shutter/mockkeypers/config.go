@@ -0,0 +1,86 @@
+package mockkeypers
+
+import (
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+func (c *Config) Init() {
+	c.P2P = p2p.NewConfig()
+}
+
+type Config struct {
+	InstanceID uint64 `shconfig:",required"`
+	EonKeySeed int64  `shconfig:",required" comment:"a seed value used to generate the eon key and the keys of the keypers"`
+	Eon        uint64 `comment:"eon the decryption key shares are sent for"`
+
+	Count     uint64 `comment:"number of simulated keypers"`
+	Threshold uint64 `comment:"number of keypers needed to compute an epoch secret key"`
+
+	Rate              float64 `comment:"number of epochs per second the keypers send decryption key shares for, 0 to only respond to decryption triggers"`
+	RespondToTriggers bool    `comment:"send decryption key shares for the epochs of decryption triggers"`
+
+	ListenHost string `comment:"IP address the keypers listen on"`
+	BasePort   uint16 `comment:"keyper i listens on BasePort + i"`
+
+	// The P2P key and listen addresses are ignored, every keyper derives its own.
+	P2P *p2p.Config
+}
+
+func (c *Config) Validate() error {
+	if c.Count == 0 {
+		return errors.New("Count must be positive")
+	}
+	if c.Threshold == 0 || c.Threshold > c.Count {
+		return errors.Errorf("Threshold must be between 1 and Count (%d), got %d", c.Count, c.Threshold)
+	}
+	if c.Rate < 0 {
+		return errors.New("Rate must not be negative")
+	}
+	if uint64(c.BasePort)+c.Count-1 > math.MaxUint16 {
+		return errors.Errorf("not enough ports above BasePort %d for %d keypers", c.BasePort, c.Count)
+	}
+	return nil
+}
+
+func (c *Config) Name() string {
+	return "mock-keypers"
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.Count = 10
+	c.Threshold = 7
+	c.Rate = 1.0
+	c.RespondToTriggers = true
+	c.ListenHost = "127.0.0.1"
+	c.BasePort = 23100
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	err := c.SetDefaultValues()
+	if err != nil {
+		return err
+	}
+	c.InstanceID = 42
+	c.EonKeySeed = 1337
+	c.Eon = 1
+	return nil
+}
+
+func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,209 @@
+// Package mockkeypers runs many lightweight keyper simulators in a single process. They speak the
+// real p2p protocol and send valid decryption key shares of a common test eon key, so that
+// collators and decryptors can be load tested at realistic message rates without running a full
+// keyper set.
+package mockkeypers
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprtopics"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/address"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/keys"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testkeygen"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+const statsInterval = 10 * time.Second
+
+// Options are set on the command line and override the config.
+type Options struct {
+	Count uint64
+	// Faulty reports whether a keyper withholds its share for an epoch. It's used to inject
+	// faults in tests and may be nil.
+	Faulty func(keyperIndex uint64, epochID epochid.EpochID) bool
+}
+
+// Swarm is a set of simulated keypers sharing one eon key.
+type Swarm struct {
+	config  *Config
+	eonKeys *testkeygen.EonKeys
+	keypers []*mockKeyper
+	faulty  func(keyperIndex uint64, epochID epochid.EpochID) bool
+
+	sharesSent atomic.Uint64
+}
+
+type mockKeyper struct {
+	swarm  *Swarm
+	index  uint64
+	shares *testkeygen.KeyperKeyShares
+	p2p    *p2p.P2PHandler
+}
+
+func New(config *Config, options Options) (*Swarm, error) {
+	if options.Count > 0 {
+		config.Count = options.Count
+		if config.Threshold > config.Count {
+			config.Threshold = config.Count
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	random := rand.New(rand.NewSource(config.EonKeySeed)) //nolint:gosec // keys have to be reproducible
+	eonKeys, err := testkeygen.NewEonKeys(random, config.Count, config.Threshold)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate eon keys")
+	}
+	swarm := &Swarm{
+		config:  config,
+		eonKeys: eonKeys,
+		faulty:  options.Faulty,
+	}
+	for i := uint64(0); i < config.Count; i++ {
+		p2pConfig := *config.P2P
+		p2pConfig.P2PKey, err = keys.GenerateLibp2pPrivate(random)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate p2p key")
+		}
+		listenAddress := &address.P2PAddress{}
+		err := listenAddress.UnmarshalText([]byte(
+			fmt.Sprintf("/ip4/%s/tcp/%d", config.ListenHost, uint64(config.BasePort)+i),
+		))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid listen address")
+		}
+		p2pConfig.ListenAddresses = []*address.P2PAddress{listenAddress}
+		p2pHandler, err := p2p.New(&p2pConfig, config.InstanceID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create p2p handler of keyper %d", i)
+		}
+		k := &mockKeyper{
+			swarm:  swarm,
+			index:  i,
+			shares: eonKeys.KeyperShares(i),
+			p2p:    p2pHandler,
+		}
+		if config.RespondToTriggers {
+			p2pHandler.AddHandlerFunc(k.handleDecryptionTrigger, &p2pmsg.DecryptionTrigger{})
+		}
+		p2pHandler.AddGossipTopic(kprtopics.DecryptionKeyShares)
+		swarm.keypers = append(swarm.keypers, k)
+	}
+	return swarm, nil
+}
+
+// PublicKey returns the eon public key the shares of the swarm belong to.
+func (s *Swarm) PublicKey() *shcrypto.EonPublicKey {
+	return s.eonKeys.PublicKey()
+}
+
+func (s *Swarm) isFaulty(keyperIndex uint64, epochID epochid.EpochID) bool {
+	return s.faulty != nil && s.faulty(keyperIndex, epochID)
+}
+
+func (s *Swarm) Start(ctx context.Context, runner service.Runner) error {
+	log.Info().
+		Hex("eon-public-key", s.eonKeys.PublicKey().Marshal()).
+		Uint64("count", s.config.Count).
+		Uint64("threshold", s.config.Threshold).
+		Msg("starting mock keypers")
+	for _, k := range s.keypers {
+		if err := runner.StartService(k.p2p); err != nil {
+			return err
+		}
+	}
+	if s.config.Rate > 0 {
+		runner.Go(func() error {
+			return s.sendShares(ctx)
+		})
+	}
+	runner.Go(func() error {
+		return s.logStats(ctx)
+	})
+	return nil
+}
+
+// sendShares makes all keypers send their shares for consecutive epochs at the configured rate.
+func (s *Swarm) sendShares(ctx context.Context) error {
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / s.config.Rate))
+	defer ticker.Stop()
+	for epoch := uint64(0); ; epoch++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		epochID := epochid.Uint64ToEpochID(epoch)
+		for _, k := range s.keypers {
+			if s.isFaulty(k.index, epochID) {
+				continue
+			}
+			if err := k.p2p.SendMessage(ctx, k.decryptionKeyShares(epochID)); err != nil {
+				log.Warn().Err(err).Uint64("keyper-index", k.index).Str("epoch-id", epochID.Hex()).
+					Msg("failed to send decryption key share")
+				continue
+			}
+			s.sharesSent.Add(1)
+		}
+	}
+}
+
+func (s *Swarm) logStats(ctx context.Context) error {
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+	last := uint64(0)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		sent := s.sharesSent.Load()
+		log.Info().
+			Uint64("shares-sent", sent).
+			Float64("shares-per-second", float64(sent-last)/statsInterval.Seconds()).
+			Msg("mock keyper stats")
+		last = sent
+	}
+}
+
+func (k *mockKeyper) decryptionKeyShares(epochID epochid.EpochID) *p2pmsg.DecryptionKeyShares {
+	share := k.shares.ComputeEpochSecretKeyShare(epochID)
+	return &p2pmsg.DecryptionKeyShares{
+		InstanceID:  k.swarm.config.InstanceID,
+		Eon:         k.swarm.config.Eon,
+		KeyperIndex: k.index,
+		Shares: []*p2pmsg.KeyShare{{
+			EpochID: epochID.Bytes(),
+			Share:   share.Marshal(),
+		}},
+	}
+}
+
+func (k *mockKeyper) handleDecryptionTrigger(_ context.Context, m p2pmsg.Message) ([]p2pmsg.Message, error) {
+	trigger := m.(*p2pmsg.DecryptionTrigger)
+	epochID, err := epochid.BytesToEpochID(trigger.EpochID)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid epoch id in decryption trigger")
+	}
+	if k.swarm.isFaulty(k.index, epochID) {
+		return nil, nil
+	}
+	k.swarm.sharesSent.Add(1)
+	return []p2pmsg.Message{k.decryptionKeyShares(epochID)}, nil
+}
@@ -0,0 +1,52 @@
+package mockkeypers
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testkeygen"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+func TestDecryptionKeyShares(t *testing.T) {
+	config := NewConfig()
+	assert.NilError(t, config.SetExampleValues())
+	config.Count = 3
+	config.Threshold = 2
+	assert.NilError(t, config.Validate())
+
+	eonKeys, err := testkeygen.NewEonKeys(rand.New(rand.NewSource(config.EonKeySeed)), 3, 2) //nolint:gosec
+	assert.NilError(t, err)
+	swarm := &Swarm{config: config, eonKeys: eonKeys}
+
+	epochID := epochid.Uint64ToEpochID(7)
+	indices := []int{0, 2}
+	shares := []*shcrypto.EpochSecretKeyShare{}
+	for _, i := range indices {
+		k := &mockKeyper{swarm: swarm, index: uint64(i), shares: eonKeys.KeyperShares(uint64(i))}
+		msgs, err := k.handleDecryptionTrigger(context.Background(), &p2pmsg.DecryptionTrigger{EpochID: epochID.Bytes()})
+		assert.NilError(t, err)
+		assert.Equal(t, len(msgs), 1)
+		msg := msgs[0].(*p2pmsg.DecryptionKeyShares)
+		assert.NilError(t, msg.Validate())
+		assert.Equal(t, msg.KeyperIndex, uint64(i))
+		share, err := msg.Shares[0].GetEpochSecretKeyShare()
+		assert.NilError(t, err)
+		shares = append(shares, share)
+	}
+	epochSecretKey, err := shcrypto.ComputeEpochSecretKey(indices, shares, 2)
+	assert.NilError(t, err)
+	ok, err := shcrypto.VerifyEpochSecretKey(epochSecretKey, eonKeys.PublicKey(), epochID.Bytes())
+	assert.NilError(t, err)
+	assert.Check(t, ok)
+	assert.Equal(t, swarm.sharesSent.Load(), uint64(2))
+
+	config.Threshold = 4
+	assert.ErrorContains(t, config.Validate(), "Threshold")
+}
@@ -49,7 +49,7 @@ func New(config *Config) (*MockNode, error) {
 		return nil, err
 	}
 
-	p2pHandler, err := p2p.New(config.P2P)
+	p2pHandler, err := p2p.New(config.P2P, config.InstanceID)
 	if err != nil {
 		return nil, err
 	}
@@ -259,8 +259,9 @@ func (m *MockNode) sendMessagesForEpoch(ctx context.Context, epochID epochid.Epo
 func (m *MockNode) sendDecryptionTrigger(ctx context.Context, epochID epochid.EpochID) error {
 	log.Info().Str("epoch-id", epochID.Hex()).Msg("sending decryption trigger")
 	msg := &p2pmsg.DecryptionTrigger{
-		InstanceID: m.Config.InstanceID,
-		EpochID:    epochID.Bytes(),
+		InstanceID:       m.Config.InstanceID,
+		EpochID:          epochID.Bytes(),
+		TransactionsHash: p2pmsg.MerkleRoot(nil),
 	}
 	return m.p2p.SendMessage(ctx, msg)
 }
@@ -28,10 +28,15 @@ func DialContext(ctx context.Context, rawurl string) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	return NewClient(c), nil
+}
+
+// NewClient creates a client that uses the given RPC client.
+func NewClient(c *ethrpc.Client) *Client {
 	return &Client{
 		Client: ethclient.NewClient(c),
 		client: c,
-	}, nil
+	}
 }
 
 func (c *Client) SetBalance(ctx context.Context, address common.Address, balance *big.Int) error {
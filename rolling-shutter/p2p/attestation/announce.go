@@ -0,0 +1,77 @@
+package attestation
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+const (
+	// announceDelay gives the node time to connect to its peers before the first attestation is
+	// sent after joining.
+	announceDelay = 10 * time.Second
+	// announceInterval is how often the attestation is repeated, so that nodes joining later
+	// learn about us as well.
+	announceInterval = time.Hour
+)
+
+// MessageSender sends p2p messages, e.g. a p2p.P2PHandler.
+type MessageSender interface {
+	SendMessage(ctx context.Context, msg p2pmsg.Message, retryOpts ...retry.Option) error
+	// ProtocolVersions returns the p2p protocol versions the messages are exchanged in.
+	ProtocolVersions() []uint64
+}
+
+// Announcer broadcasts the attestation of our own node.
+type Announcer struct {
+	instanceID uint64
+	role       string
+	version    string
+	privKey    *ecdsa.PrivateKey
+	sender     MessageSender
+}
+
+func NewAnnouncer(
+	instanceID uint64, role, version string, privKey *ecdsa.PrivateKey, sender MessageSender,
+) *Announcer {
+	return &Announcer{
+		instanceID: instanceID,
+		role:       role,
+		version:    version,
+		privKey:    privKey,
+		sender:     sender,
+	}
+}
+
+// Run broadcasts our attestation shortly after startup and then periodically until the context
+// is canceled.
+func (a *Announcer) Run(ctx context.Context) error {
+	delay := announceDelay
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = announceInterval
+		if err := a.announce(ctx); err != nil {
+			log.Warn().Err(err).Msg("failed to send node attestation")
+		}
+	}
+}
+
+func (a *Announcer) announce(ctx context.Context) error {
+	attestation, err := p2pmsg.NewSignedNodeAttestation(
+		a.instanceID, a.role, a.version, a.sender.ProtocolVersions(), time.Now(), a.privKey,
+	)
+	if err != nil {
+		return err
+	}
+	log.Info().Str("message", attestation.LogInfo()).Msg("sending node attestation")
+	return a.sender.SendMessage(ctx, attestation)
+}
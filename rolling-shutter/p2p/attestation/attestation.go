@@ -0,0 +1,110 @@
+// Package attestation lets nodes announce their identity to their peers. Every node broadcasts a
+// signed NodeAttestation with its Ethereum address, role and software version when it joins the
+// network, and stores the attestations it receives, so that operators can see which node runs
+// what and are warned about peers running incompatible protocol versions.
+package attestation
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/peerdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+const (
+	// maxClockSkew is how far in the future an attestation may be made.
+	maxClockSkew = time.Minute
+	// maxAge is how long ago an attestation may have been made. It is longer than
+	// announceInterval, so that attestations of live nodes are never rejected.
+	maxAge = 2 * announceInterval
+	// retention is how long attestations of nodes that stopped announcing themselves are kept.
+	retention = 7 * 24 * time.Hour
+)
+
+func NewHandler(instanceID uint64, dbpool *pgxpool.Pool, version string) p2p.MessageHandler {
+	return &Handler{instanceID: instanceID, dbpool: dbpool, version: version}
+}
+
+// Handler stores the attestations of other nodes and warns about nodes with a different
+// software or protocol version than ours.
+type Handler struct {
+	instanceID uint64
+	dbpool     *pgxpool.Pool
+	version    string
+}
+
+func (*Handler) MessagePrototypes() []p2pmsg.Message {
+	return []p2pmsg.Message{&p2pmsg.NodeAttestation{}}
+}
+
+func (handler *Handler) ValidateMessage(_ context.Context, msg p2pmsg.Message) (bool, error) {
+	attestation := msg.(*p2pmsg.NodeAttestation)
+	if attestation.GetInstanceID() != handler.instanceID {
+		return false, errors.Errorf(
+			"instance ID mismatch (want=%d, have=%d)", handler.instanceID, attestation.GetInstanceID(),
+		)
+	}
+	if attestation.Timestamp > uint64(time.Now().Add(maxClockSkew).Unix()) {
+		return false, errors.Errorf("attestation time %d is in the future", attestation.Timestamp)
+	}
+	if attestation.AttestationTime().Before(time.Now().Add(-maxAge)) {
+		return false, errors.Errorf("attestation time %d is too old", attestation.Timestamp)
+	}
+	if err := attestation.Verify(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (handler *Handler) HandleMessage(ctx context.Context, m p2pmsg.Message) ([]p2pmsg.Message, error) {
+	attestation, ok := m.(*p2pmsg.NodeAttestation)
+	if !ok {
+		return nil, errors.New("Message type assertion mismatch")
+	}
+	metricsAttestationsReceived.Inc()
+	logger := log.With().
+		Str("address", attestation.AttesterAddress().Hex()).
+		Str("role", attestation.Role).
+		Str("version", attestation.Version).
+		Logger()
+	if !attestation.SupportsProtocolVersion(p2pmsg.ProtocolVersion) {
+		metricsIncompatiblePeers.Inc()
+		logger.Warn().Uints64("protocol-versions", attestation.ProtocolVersions).
+			Uint64("our-protocol-version", p2pmsg.ProtocolVersion).
+			Msg("peer does not support our p2p protocol version")
+	} else if attestation.Version != handler.version {
+		logger.Info().Str("our-version", handler.version).Msg("peer runs a different software version")
+	} else {
+		logger.Debug().Msg("received node attestation")
+	}
+
+	protocolVersions := make([]int64, len(attestation.ProtocolVersions))
+	for i, v := range attestation.ProtocolVersions {
+		protocolVersions[i] = int64(v)
+	}
+	// the attestation is signed by the node's address, the pubsub message by its libp2p key
+	sender, _ := p2p.SenderFromContext(ctx)
+	db := peerdb.New(handler.dbpool)
+	err := db.UpsertNodeAttestation(ctx, peerdb.UpsertNodeAttestationParams{
+		Address:          shdb.EncodeAddress(attestation.AttesterAddress()),
+		Role:             attestation.Role,
+		Version:          attestation.Version,
+		ProtocolVersions: protocolVersions,
+		PeerID:           sender.String(),
+		AttestedAt:       attestation.AttestationTime(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to store node attestation")
+	}
+	if _, err := db.PruneNodeAttestations(ctx, time.Now().Add(-retention)); err != nil {
+		return nil, errors.Wrap(err, "failed to prune node attestations")
+	}
+	return nil, nil
+}
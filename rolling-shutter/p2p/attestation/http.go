@@ -0,0 +1,60 @@
+package attestation
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/peerdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// Peer is a node as described by its most recent attestation.
+type Peer struct {
+	Address          string    `json:"address"`
+	Role             string    `json:"role"`
+	Version          string    `json:"version"`
+	ProtocolVersions []int64   `json:"protocolVersions"`
+	PeerID           string    `json:"peerID"`
+	AttestedAt       time.Time `json:"attestedAt"`
+	ReceivedAt       time.Time `json:"receivedAt"`
+	// Compatible is false if the node does not support our p2p protocol version.
+	Compatible bool `json:"compatible"`
+	// SameVersion is true if the node runs the same software version as we do.
+	SameVersion bool `json:"sameVersion"`
+}
+
+// PeersHandler serves the nodes that attested their identity as JSON.
+func PeersHandler(dbpool *pgxpool.Pool, version string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rows, err := peerdb.New(dbpool).GetNodeAttestations(r.Context())
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get node attestations from db")
+			http.Error(w, "failed to get node attestations", http.StatusInternalServerError)
+			return
+		}
+		peers := make([]Peer, len(rows))
+		for i, row := range rows {
+			peers[i] = Peer{
+				Address:          row.Address,
+				Role:             row.Role,
+				Version:          row.Version,
+				ProtocolVersions: row.ProtocolVersions,
+				PeerID:           row.PeerID,
+				AttestedAt:       row.AttestedAt.UTC(),
+				ReceivedAt:       row.ReceivedAt.UTC(),
+				SameVersion:      row.Version == version,
+			}
+			for _, v := range row.ProtocolVersions {
+				if v == p2pmsg.ProtocolVersion {
+					peers[i].Compatible = true
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(peers)
+	}
+}
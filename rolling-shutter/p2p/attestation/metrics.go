@@ -0,0 +1,26 @@
+package attestation
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsAttestationsReceived = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "p2p",
+		Name:      "node_attestations_received_total",
+		Help:      "Number of received node attestations",
+	},
+)
+
+var metricsIncompatiblePeers = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "p2p",
+		Name:      "incompatible_node_attestations_total",
+		Help:      "Number of received node attestations of peers not supporting our protocol version",
+	},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsAttestationsReceived)
+	prometheus.MustRegister(metricsIncompatiblePeers)
+}
@@ -0,0 +1,102 @@
+package p2p
+
+import (
+	"context"
+	"crypto/sha256"
+	"strconv"
+	"sync"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// bridgedSetSize is the number of recently bridged messages that are remembered in order not to
+// relay them again.
+const bridgedSetSize = 4096
+
+// bridgedSet remembers the most recently bridged messages. Bridging nodes receive each message
+// once per protocol version, e.g. the original and the copy relayed by another bridging node, and
+// must only relay it once.
+type bridgedSet struct {
+	mux    sync.Mutex
+	hashes map[[sha256.Size]byte]struct{}
+	order  [][sha256.Size]byte
+}
+
+func newBridgedSet() *bridgedSet {
+	return &bridgedSet{hashes: make(map[[sha256.Size]byte]struct{})}
+}
+
+// add adds the message to the set and reports whether it was not in it before.
+func (s *bridgedSet) add(m p2pmsg.Message) bool {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	if err != nil {
+		return false
+	}
+	hash := sha256.Sum256(data)
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, ok := s.hashes[hash]; ok {
+		return false
+	}
+	if len(s.order) >= bridgedSetSize {
+		delete(s.hashes, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.hashes[hash] = struct{}{}
+	s.order = append(s.order, hash)
+	return true
+}
+
+// bridges checks if messages of the given topic are bridged to the adjacent protocol version.
+func (handler *P2PHandler) bridges(topic string) bool {
+	if !handler.bridging {
+		return false
+	}
+	if len(handler.bridgeTopics) == 0 {
+		return true
+	}
+	_, ok := handler.bridgeTopics[topic]
+	return ok
+}
+
+// ProtocolVersions returns the p2p protocol versions the node exchanges messages in.
+func (handler *P2PHandler) ProtocolVersions() []uint64 {
+	if !handler.bridging {
+		return []uint64{p2pmsg.ProtocolVersion}
+	}
+	return []uint64{p2pmsg.ProtocolVersion, handler.bridgeVersion}
+}
+
+// relay republishes a message received on the topic of one protocol version on the topics of the
+// other one, so that nodes on either side of an upgrade receive each other's messages. Only
+// messages that passed a validator are relayed.
+func (handler *P2PHandler) relay(ctx context.Context, msg *pubsub.Message, m p2pmsg.Message) {
+	if !handler.bridges(m.Topic()) {
+		return
+	}
+	if _, ok := handler.validatorRegistry[msg.GetTopic()]; !ok {
+		return
+	}
+	if !handler.bridged.add(m) {
+		return
+	}
+	// messages received on global topics during a topic namespace migration count as the current
+	// version
+	toVersion := handler.bridgeVersion
+	if _, version, _, ok := p2pmsg.SplitNamespace(msg.GetTopic()); ok && version == handler.bridgeVersion {
+		toVersion = p2pmsg.ProtocolVersion
+	}
+	err := handler.publish(ctx, m, toVersion, nil, []retry.Option{retry.NumberOfRetries(0)})
+	if err != nil {
+		log.Info().Err(err).Str("message", m.LogInfo()).Uint64("protocol-version", toVersion).
+			Msg("failed to relay message to adjacent protocol version")
+		return
+	}
+	metricsBridgedMessages.WithLabelValues(m.Topic(), strconv.FormatUint(toVersion, 10)).Inc()
+}
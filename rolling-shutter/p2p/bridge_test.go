@@ -0,0 +1,23 @@
+package p2p
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+func TestBridgedSet(t *testing.T) {
+	s := newBridgedSet()
+	msg := &p2pmsg.DecryptionKey{InstanceID: 42, Eon: 1, EpochID: []byte{2}, Key: []byte{3}}
+	assert.Assert(t, s.add(msg))
+	assert.Assert(t, !s.add(&p2pmsg.DecryptionKey{InstanceID: 42, Eon: 1, EpochID: []byte{2}, Key: []byte{3}}))
+
+	for i := uint64(0); i < bridgedSetSize; i++ {
+		assert.Assert(t, s.add(&p2pmsg.DecryptionKey{InstanceID: 42, Eon: i + 2}))
+	}
+	assert.Equal(t, len(s.hashes), bridgedSetSize)
+	// the oldest message got evicted
+	assert.Assert(t, s.add(msg))
+}
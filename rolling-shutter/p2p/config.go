@@ -6,11 +6,13 @@ import (
 	"io"
 
 	"github.com/libp2p/go-libp2p"
+	"github.com/pkg/errors"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/address"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/env"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/keys"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 )
 
 var (
@@ -45,19 +47,148 @@ type Config struct {
 	ListenAddresses          []*address.P2PAddress
 	CustomBootstrapAddresses []*address.P2PAddress `comment:"Overwrite p2p boostrap nodes"`
 	Environment              env.Environment
+	MessageHandlerShards     uint16   `comment:"Number of workers handling messages of different epochs concurrently"`
+	Encodings                []string `comment:"Wire encodings messages are published and accepted in (protobuf, cbor, ssz)"`
+	TopicNamespace           string   `comment:"Gossip topic names: global (shared by all instances, the default), instance (scoped to the instance ID and protocol version) or migration (both, used while upgrading from global)"`
+	Bridge                   bool     `comment:"Join the topics of BridgeProtocolVersion and relay messages to and from them during an upgrade"`
+	BridgeProtocolVersion    uint64   `comment:"Adjacent protocol version bridged to if Bridge is enabled"`
+	BridgeTopics             []string `comment:"Topics bridged to the adjacent protocol version, all topics with a validator if empty"`
+
+	// Limits of the libp2p resource manager, 0 keeps the default of libp2p scaled to the machine.
+	MaxConnections        uint32 `comment:"Maximum number of connections, must not be below the connection manager's high water mark"`
+	MaxConnectionsPerPeer uint32 `comment:"Maximum number of connections to a single peer"`
+	MaxStreams            uint32 `comment:"Maximum number of streams"`
+	MaxStreamsPerPeer     uint32 `comment:"Maximum number of streams to a single peer"`
+	MaxStreamsPerProtocol uint32 `comment:"Maximum number of streams of a single protocol"`
+	MaxMemoryMB           uint32 `comment:"Maximum memory libp2p may reserve, in MiB"`
+	MaxMemoryPerPeerMB    uint32 `comment:"Maximum memory libp2p may reserve for a single peer, in MiB"`
 }
 
+// TopicNamespace determines how the names of the gossip topics are derived.
+type TopicNamespace string
+
+const (
+	// TopicNamespaceInstance scopes all topics to the instance ID and protocol version. It has to
+	// be configured explicitly.
+	TopicNamespaceInstance TopicNamespace = "instance"
+	// TopicNamespaceGlobal uses the plain topic names shared by all instances. This is how topics
+	// were named before they were scoped and it is the default.
+	TopicNamespaceGlobal TopicNamespace = "global"
+	// TopicNamespaceMigration subscribes and publishes to both the instance scoped and the global
+	// topics, so that nodes can be upgraded one by one.
+	TopicNamespaceMigration TopicNamespace = "migration"
+)
+
 func (c *Config) Name() string {
 	return "p2p"
 }
 
 func (c *Config) Validate() error {
+	_, err := c.parseEncodings()
+	if err != nil {
+		return err
+	}
+	_, err = c.parseTopicNamespace()
+	if err != nil {
+		return err
+	}
+	err = c.validateBridge()
+	if err != nil {
+		return err
+	}
+	return c.validateResourceLimits()
+}
+
+// validateBridge checks that the protocol version to bridge to, if any, can be bridged.
+func (c *Config) validateBridge() error {
+	if !c.Bridge {
+		return nil
+	}
+	if !p2pmsg.IsAdjacentProtocolVersion(c.BridgeProtocolVersion) {
+		return errors.Errorf(
+			"can only bridge to a protocol version adjacent to %d, got %d", p2pmsg.ProtocolVersion, c.BridgeProtocolVersion,
+		)
+	}
+	ns, err := c.parseTopicNamespace()
+	if err != nil {
+		return err
+	}
+	if ns == TopicNamespaceGlobal {
+		// global topic names don't contain the protocol version
+		return errors.New("can't bridge protocol versions with the global topic namespace")
+	}
 	return nil
 }
 
+func (c *Config) validateResourceLimits() error {
+	if c.MaxConnections != 0 && c.MaxConnections < connMgrHighWater {
+		// otherwise the resource manager blocks new connections before the connection manager
+		// starts to trim the existing ones
+		return errors.Errorf("MaxConnections must be at least %d, got %d", connMgrHighWater, c.MaxConnections)
+	}
+	perPeerLimits := []struct {
+		name            string
+		perPeer, global uint32
+	}{
+		{"MaxConnectionsPerPeer", c.MaxConnectionsPerPeer, c.MaxConnections},
+		{"MaxStreamsPerPeer", c.MaxStreamsPerPeer, c.MaxStreams},
+		{"MaxStreamsPerProtocol", c.MaxStreamsPerProtocol, c.MaxStreams},
+		{"MaxMemoryPerPeerMB", c.MaxMemoryPerPeerMB, c.MaxMemoryMB},
+	}
+	for _, l := range perPeerLimits {
+		if l.global != 0 && l.perPeer > l.global {
+			return errors.Errorf("%s must not exceed the overall limit %d, got %d", l.name, l.global, l.perPeer)
+		}
+	}
+	return nil
+}
+
+func (c *Config) parseTopicNamespace() (TopicNamespace, error) {
+	switch ns := TopicNamespace(c.TopicNamespace); ns {
+	case "":
+		// existing nodes keep the topic names they used before the namespace was configurable
+		return TopicNamespaceGlobal, nil
+	case TopicNamespaceInstance, TopicNamespaceGlobal, TopicNamespaceMigration:
+		return ns, nil
+	default:
+		return "", errors.Errorf("unknown topic namespace %q", c.TopicNamespace)
+	}
+}
+
+func (c *Config) parseEncodings() ([]p2pmsg.Encoding, error) {
+	if len(c.Encodings) == 0 {
+		return []p2pmsg.Encoding{p2pmsg.EncodingProtobuf}, nil
+	}
+	encodings := make([]p2pmsg.Encoding, 0, len(c.Encodings))
+	seen := make(map[p2pmsg.Encoding]struct{})
+	for _, name := range c.Encodings {
+		enc, err := p2pmsg.ParseEncoding(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[enc]; ok {
+			return nil, errors.Errorf("duplicate encoding %q", name)
+		}
+		seen[enc] = struct{}{}
+		encodings = append(encodings, enc)
+	}
+	return encodings, nil
+}
+
 func (c *Config) SetDefaultValues() error {
 	c.ListenAddresses = defaultListenAddrs
 	c.Environment = env.EnvironmentProduction
+	c.MessageHandlerShards = 4
+	c.Encodings = []string{string(p2pmsg.EncodingProtobuf)}
+	c.TopicNamespace = string(TopicNamespaceGlobal)
+	c.BridgeTopics = []string{}
+	c.MaxConnections = 256
+	c.MaxConnectionsPerPeer = 8
+	c.MaxStreams = 2048
+	c.MaxStreamsPerPeer = 256
+	c.MaxStreamsPerProtocol = 1024
+	c.MaxMemoryMB = 1024
+	c.MaxMemoryPerPeerMB = 64
 	return nil
 }
 
@@ -0,0 +1,45 @@
+package p2p
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestParseTopicNamespace(t *testing.T) {
+	cfg := NewConfig()
+	assert.NilError(t, cfg.SetDefaultValues())
+	ns, err := cfg.parseTopicNamespace()
+	assert.NilError(t, err)
+	assert.Equal(t, ns, TopicNamespaceGlobal)
+
+	cfg.TopicNamespace = ""
+	ns, err = cfg.parseTopicNamespace()
+	assert.NilError(t, err)
+	assert.Equal(t, ns, TopicNamespaceGlobal)
+
+	cfg.TopicNamespace = string(TopicNamespaceInstance)
+	ns, err = cfg.parseTopicNamespace()
+	assert.NilError(t, err)
+	assert.Equal(t, ns, TopicNamespaceInstance)
+
+	cfg.TopicNamespace = "local"
+	_, err = cfg.parseTopicNamespace()
+	assert.ErrorContains(t, err, "unknown topic namespace")
+}
+
+func TestValidateBridge(t *testing.T) {
+	cfg := NewConfig()
+	assert.NilError(t, cfg.SetDefaultValues())
+	assert.NilError(t, cfg.validateBridge())
+
+	// version 0 is adjacent to version 1, bridging to it must be possible
+	cfg.Bridge = true
+	cfg.BridgeProtocolVersion = 0
+	assert.ErrorContains(t, cfg.validateBridge(), "global topic namespace")
+	cfg.TopicNamespace = string(TopicNamespaceInstance)
+	assert.NilError(t, cfg.validateBridge())
+
+	cfg.BridgeProtocolVersion = 5
+	assert.ErrorContains(t, cfg.validateBridge(), "adjacent")
+}
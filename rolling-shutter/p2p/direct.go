@@ -0,0 +1,196 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+const (
+	// maxDirectMessageSize is the maximum size of a message sent on a direct stream.
+	maxDirectMessageSize = 1 << 20
+	// directStreamTimeout is how long reading a direct message may take.
+	directStreamTimeout = 10 * time.Second
+	// directSendTimeout is how long opening a stream and writing a direct message may take. It is
+	// short, as the message is sent on the broadcast path and gossiped if no recipient is reached.
+	directSendTimeout = 2 * time.Second
+)
+
+// directProtocolID is the libp2p protocol of direct messages. Like topic names, it contains the
+// protocol version, so that peers of other versions refuse the stream and the message is gossiped
+// instead.
+var directProtocolID = protocol.ID(fmt.Sprintf("/shutter/direct/v%d", p2pmsg.ProtocolVersion))
+
+// DirectMessage is a message received on a direct stream from a peer instead of via gossip.
+type DirectMessage struct {
+	From peer.ID
+	Data []byte
+}
+
+// DirectRoute returns the peers a message is sent to directly instead of gossiping it to all
+// peers, not including ourselves. If it returns no peers or none of them can be reached, the
+// message is gossiped.
+type DirectRoute func(ctx context.Context, msg p2pmsg.Message) ([]peer.ID, error)
+
+// handleDirectStream reads a direct message and queues it for handling. Messages are dropped if
+// the queue is full, as the sender falls back to gossip only if it can't reach any recipient.
+func (p *P2PNode) handleDirectStream(s network.Stream) {
+	defer s.Close()
+	_ = s.SetReadDeadline(time.Now().Add(directStreamTimeout))
+	data, err := io.ReadAll(io.LimitReader(s, maxDirectMessageSize+1))
+	if err != nil {
+		_ = s.Reset()
+		log.Debug().Err(err).Str("peer", s.Conn().RemotePeer().String()).Msg("failed to read direct message")
+		return
+	}
+	if len(data) > maxDirectMessageSize {
+		_ = s.Reset()
+		log.Info().Str("peer", s.Conn().RemotePeer().String()).Msg("dropping oversized direct message")
+		return
+	}
+	select {
+	case p.DirectMessages <- DirectMessage{From: s.Conn().RemotePeer(), Data: data}:
+	default:
+		log.Info().Str("peer", s.Conn().RemotePeer().String()).Msg("dropping direct message, queue is full")
+	}
+}
+
+// SendDirect sends the data to the given peer on a direct stream.
+func (p *P2PNode) SendDirect(ctx context.Context, peerID peer.ID, data []byte) error {
+	p.mux.Lock()
+	h := p.host
+	p.mux.Unlock()
+	if h == nil {
+		return errors.New("p2p host not started yet")
+	}
+	ctx, cancel := context.WithTimeout(ctx, directSendTimeout)
+	defer cancel()
+	s, err := h.NewStream(ctx, peerID, directProtocolID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open stream to %s", peerID)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.SetWriteDeadline(deadline)
+	}
+	if _, err := s.Write(data); err != nil {
+		_ = s.Reset()
+		return errors.Wrapf(err, "failed to send message to %s", peerID)
+	}
+	return s.Close()
+}
+
+// AddDirectRoute makes SendMessage send messages of the given types directly to the peers
+// returned by the route instead of gossiping them.
+func (handler *P2PHandler) AddDirectRoute(route DirectRoute, protos ...p2pmsg.Message) {
+	for _, p := range protos {
+		handler.directRoutes[proto.MessageName(p)] = route
+	}
+}
+
+// AcceptDirectMessages accepts messages of the given types on direct streams. They are validated
+// and handled like messages received via gossip, so a validator and a handler must be registered
+// for them.
+func (handler *P2PHandler) AcceptDirectMessages(protos ...p2pmsg.Message) {
+	for _, p := range protos {
+		handler.directAccepted[proto.MessageName(p)] = struct{}{}
+	}
+}
+
+// sendDirect sends the message to the peers of its direct route concurrently and reports whether at
+// least one of them received it.
+func (handler *P2PHandler) sendDirect(ctx context.Context, route DirectRoute, msg p2pmsg.Message) bool {
+	peers, err := route(ctx, msg)
+	if err != nil {
+		log.Info().Err(err).Str("message", msg.LogInfo()).Msg("failed to route message directly, gossiping it")
+		return false
+	}
+	if len(peers) == 0 {
+		return false
+	}
+	data, err := p2pmsg.Marshal(msg, nil)
+	if err != nil {
+		log.Info().Err(err).Str("message", msg.LogInfo()).Msg("failed to marshal message, gossiping it")
+		return false
+	}
+
+	var sent atomic.Int64
+	var wg sync.WaitGroup
+	for _, peerID := range peers {
+		wg.Add(1)
+		go func(peerID peer.ID) {
+			defer wg.Done()
+			if err := handler.P2P.SendDirect(ctx, peerID, data); err != nil {
+				metricsDirectMessagesFailed.WithLabelValues(msg.Topic()).Inc()
+				log.Info().Err(err).Str("message", msg.LogInfo()).Str("peer", peerID.String()).
+					Msg("failed to send message directly")
+				return
+			}
+			metricsDirectMessagesSent.WithLabelValues(msg.Topic()).Inc()
+			sent.Add(1)
+		}(peerID)
+	}
+	wg.Wait()
+	if sent.Load() == 0 {
+		log.Info().Str("message", msg.LogInfo()).Msg("could not send message to any peer directly, gossiping it")
+		return false
+	}
+	log.Info().Str("message", msg.LogInfo()).Int64("peers", sent.Load()).Msg("sent message directly")
+	return true
+}
+
+// receiveDirect validates and handles a message received on a direct stream.
+func (handler *P2PHandler) receiveDirect(ctx context.Context, dm DirectMessage) error {
+	receivedAt := time.Now()
+	logError := func(err error) {
+		log.Info().Err(err).Str("sender-id", dm.From.String()).Msg("failed to handle direct message")
+	}
+	m, traceContext, err := p2pmsg.Unmarshal(dm.Data)
+	if err != nil {
+		logError(err)
+		return nil
+	}
+	messageType := proto.MessageName(m)
+	valFunc, ok := handler.validatorFuncs[messageType]
+	if _, accepted := handler.directAccepted[messageType]; !accepted || !ok {
+		logError(errors.Errorf("direct messages of type %s are not accepted", messageType))
+		return nil
+	}
+	if err := m.Validate(); err != nil {
+		logError(errors.Wrap(err, "verification failed"))
+		return nil
+	}
+
+	// the message is handled as if it was received via gossip on its topic
+	topic := m.Topic()
+	msg := &pubsub.Message{
+		Message:      &pb.Message{From: []byte(dm.From), Data: dm.Data, Topic: &topic},
+		ReceivedFrom: dm.From,
+	}
+	return handler.handlerPool.Submit(ctx, shardKey(m), func(ctx context.Context) {
+		valid, err := valFunc(ctx, m)
+		if err != nil {
+			logError(err)
+		}
+		if !valid {
+			return
+		}
+		metricsDirectMessagesReceived.WithLabelValues(topic).Inc()
+		if err := handler.handle(ctx, msg, m, traceContext, receivedAt); err != nil {
+			logError(err)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package p2p
 import (
 	"context"
 	"reflect"
+	"time"
 
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -13,8 +14,10 @@ import (
 	"google.golang.org/protobuf/reflect/protoreflect"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/env"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/errcode"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/shardpool"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 )
 
@@ -30,13 +33,46 @@ const (
 	invalidResultType = pubsub.ValidationReject
 )
 
+type senderKey struct{}
+
+// SenderFromContext returns the peer that sent the message a HandlerFunc is called with.
+func SenderFromContext(ctx context.Context) (peer.ID, bool) {
+	sender, ok := ctx.Value(senderKey{}).(peer.ID)
+	return sender, ok
+}
+
+type deliveryKey struct{}
+
+// Delivery describes how the message a HandlerFunc is called with reached us.
+type Delivery struct {
+	// Origin is the peer that published the message.
+	Origin peer.ID
+	// ReceivedFrom is the peer that delivered the message to us. It differs from Origin if the
+	// message has been relayed by other peers.
+	ReceivedFrom peer.ID
+	ReceivedAt   time.Time
+}
+
+// ContextWithDelivery returns a copy of ctx carrying the delivery of a message.
+func ContextWithDelivery(ctx context.Context, delivery Delivery) context.Context {
+	return context.WithValue(ctx, deliveryKey{}, delivery)
+}
+
+// DeliveryFromContext returns how the message a HandlerFunc is called with reached us.
+func DeliveryFromContext(ctx context.Context) (Delivery, bool) {
+	delivery, ok := ctx.Value(deliveryKey{}).(Delivery)
+	return delivery, ok
+}
+
 type MessageHandler interface {
 	ValidateMessage(context.Context, p2pmsg.Message) (bool, error)
 	HandleMessage(context.Context, p2pmsg.Message) ([]p2pmsg.Message, error)
 	MessagePrototypes() []p2pmsg.Message
 }
 
-func New(config *Config) (*P2PHandler, error) {
+// New creates a P2PHandler. Unless configured otherwise, the gossip topics are scoped to the given
+// instance ID.
+func New(config *Config, instanceID uint64) (*P2PHandler, error) {
 	peerID, err := config.P2PKey.PeerID()
 	if err != nil {
 		return nil, err
@@ -54,6 +90,7 @@ func New(config *Config) (*P2PHandler, error) {
 		// they are not stable from our side
 		DisableTopicDHT:   true,
 		DisableRoutingDHT: true,
+		ResourceLimits:    config.resourceLimits(),
 	}
 
 	bootstrapAddresses := config.CustomBootstrapAddresses
@@ -87,21 +124,62 @@ func New(config *Config) (*P2PHandler, error) {
 	if len(cfg.BootstrapPeers) < 1 && !cfg.IsBootstrapNode {
 		return nil, errors.New("no bootstrap peers configured")
 	}
+	encodings, err := config.parseEncodings()
+	if err != nil {
+		return nil, err
+	}
+	topicNamespace, err := config.parseTopicNamespace()
+	if err != nil {
+		return nil, err
+	}
+	if err := config.validateBridge(); err != nil {
+		return nil, err
+	}
+	if err := config.validateResourceLimits(); err != nil {
+		return nil, err
+	}
+	bridgeTopics := make(map[string]struct{})
+	for _, topic := range config.BridgeTopics {
+		bridgeTopics[topic] = struct{}{}
+	}
 
 	return &P2PHandler{
 		P2P:               NewP2PNode(*cfg),
 		gossipTopicNames:  make(map[string]struct{}),
+		encodings:         encodings,
+		instanceID:        instanceID,
+		topicNamespace:    topicNamespace,
+		bridging:          config.Bridge,
+		bridgeVersion:     config.BridgeProtocolVersion,
+		bridgeTopics:      bridgeTopics,
+		bridged:           newBridgedSet(),
 		handlerRegistry:   make(HandlerRegistry),
 		validatorRegistry: make(ValidatorRegistry),
+		validatorFuncs:    make(map[protoreflect.FullName]ValidatorFunc),
+		directRoutes:      make(map[protoreflect.FullName]DirectRoute),
+		directAccepted:    make(map[protoreflect.FullName]struct{}),
+		handlerPool:       shardpool.New(int(config.MessageHandlerShards), messagesBufSize),
 	}, nil
 }
 
 type P2PHandler struct {
 	P2P              *P2PNode
 	gossipTopicNames map[string]struct{}
+	encodings        []p2pmsg.Encoding
+	instanceID       uint64
+	topicNamespace   TopicNamespace
+	bridging         bool
+	bridgeVersion    uint64 // adjacent protocol version messages are bridged to if bridging
+	bridgeTopics     map[string]struct{}
+	bridged          *bridgedSet
+	handlerPool      *shardpool.Pool
+	publishDisabled  bool
 
 	handlerRegistry   HandlerRegistry
 	validatorRegistry ValidatorRegistry
+	validatorFuncs    map[protoreflect.FullName]ValidatorFunc
+	directRoutes      map[protoreflect.FullName]DirectRoute
+	directAccepted    map[protoreflect.FullName]struct{}
 }
 
 // AddHandlerFunc will add a handler-function to a P2PHandler instance:
@@ -126,7 +204,45 @@ func (handler *P2PHandler) AddHandlerFunc(handlerFunc HandlerFunc, protos ...p2p
 }
 
 func (handler *P2PHandler) addValidatorImpl(valFunc ValidatorFunc, messProto p2pmsg.Message) {
-	topic := messProto.Topic()
+	handler.validatorFuncs[proto.MessageName(messProto)] = valFunc
+	for _, topic := range handler.wireTopics(messProto.Topic()) {
+		handler.addTopicValidator(valFunc, messProto, topic)
+	}
+}
+
+// wireTopics returns the names of the gossip topics on which messages of the given topic are
+// exchanged, for every configured encoding and topic namespace and, if the topic is bridged, for
+// the adjacent protocol version.
+func (handler *P2PHandler) wireTopics(topic string) []string {
+	topics := []string{}
+	for _, enc := range handler.encodings {
+		encodedTopic := p2pmsg.EncodedTopic(topic, enc)
+		topics = append(topics, handler.namespacedTopics(p2pmsg.ProtocolVersion, encodedTopic)...)
+		if handler.bridges(topic) {
+			topics = append(topics, handler.namespacedTopics(handler.bridgeVersion, encodedTopic)...)
+		}
+	}
+	return topics
+}
+
+// namespacedTopics returns the names of the given encoded topic in the configured topic
+// namespaces. Topics of other protocol versions than the current one only exist in the instance
+// namespace.
+func (handler *P2PHandler) namespacedTopics(version uint64, encodedTopic string) []string {
+	if version != p2pmsg.ProtocolVersion {
+		return []string{p2pmsg.VersionedTopic(handler.instanceID, version, encodedTopic)}
+	}
+	switch handler.topicNamespace {
+	case TopicNamespaceGlobal:
+		return []string{encodedTopic}
+	case TopicNamespaceMigration:
+		return []string{p2pmsg.NamespacedTopic(handler.instanceID, encodedTopic), encodedTopic}
+	default:
+		return []string{p2pmsg.NamespacedTopic(handler.instanceID, encodedTopic)}
+	}
+}
+
+func (handler *P2PHandler) addTopicValidator(valFunc ValidatorFunc, messProto p2pmsg.Message, topic string) {
 	_, exists := handler.validatorRegistry[topic]
 	if exists {
 		// This is likely not intended and happens when different messages return the same P2PMessage.Topic().
@@ -173,7 +289,7 @@ func (handler *P2PHandler) addValidatorImpl(valFunc ValidatorFunc, messProto p2p
 		return pubsub.ValidationAccept
 	}
 	handler.validatorRegistry[topic] = validate
-	handler.AddGossipTopic(topic)
+	handler.gossipTopicNames[topic] = struct{}{}
 }
 
 // AddValidator will add a validator-function to a P2PHandler instance:
@@ -204,8 +320,29 @@ func (handler *P2PHandler) AddMessageHandler(mhs ...MessageHandler) {
 // join a topic for which no handlers or validators are registered
 // with the AddHandlerFunc() and AddValidator() functions
 // (e.g. for a publish only scenario for the topic).
+// The topic is joined in all configured encodings and topic namespaces.
 func (handler *P2PHandler) AddGossipTopic(topic string) {
-	handler.gossipTopicNames[topic] = struct{}{}
+	for _, wireTopic := range handler.wireTopics(topic) {
+		handler.gossipTopicNames[wireTopic] = struct{}{}
+	}
+}
+
+// PauseTopics stops receiving messages of the given topics, e.g. while the node has nothing to
+// contribute to them. Messages can still be sent on paused topics.
+func (handler *P2PHandler) PauseTopics(topics ...string) {
+	for _, topic := range topics {
+		handler.P2P.PauseTopics(handler.wireTopics(topic))
+	}
+}
+
+// ResumeTopics starts receiving messages of the given topics again after they have been paused.
+func (handler *P2PHandler) ResumeTopics(topics ...string) error {
+	for _, topic := range topics {
+		if err := handler.P2P.ResumeTopics(handler.wireTopics(topic)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (handler *P2PHandler) Start(
@@ -216,6 +353,9 @@ func (handler *P2PHandler) Start(
 		return handler.P2P.Run(ctx, handler.topics(), handler.validatorRegistry)
 	})
 	if handler.hasHandler() {
+		runner.Go(func() error {
+			return handler.handlerPool.Run(ctx)
+		})
 		runner.Go(func() error {
 			return handler.runHandleMessages(ctx)
 		})
@@ -236,39 +376,77 @@ func (handler *P2PHandler) hasHandler() bool {
 	return len(handler.handlerRegistry) > 0
 }
 
+// shardKey returns the key that determines on which shard of the handler pool the message is
+// handled. Messages without a key are all handled on the same shard.
+func shardKey(m p2pmsg.Message) []byte {
+	if sharded, ok := m.(p2pmsg.ShardedMessage); ok {
+		return sharded.ShardKey()
+	}
+	return nil
+}
+
 func (handler *P2PHandler) runHandleMessages(ctx context.Context) error {
 	// This will consume incoming messages and dispatch them to the registered handler functions
 	// If the handler returns messages, then they will be sent to the broadcast
+	// Messages concerning different epochs are handled concurrently on different shards of the
+	// handler pool, messages concerning the same epoch are handled in the order they arrived.
 	for {
 		select {
 		case msg, ok := <-handler.P2P.GossipMessages:
 			if !ok {
 				return nil
 			}
-			if err := handler.handle(ctx, msg); err != nil {
+			receivedAt := time.Now()
+			logError := func(err error) {
 				log.Info().
 					Err(err).
+					Str("error-code", string(errcode.Of(err).Code)).
 					Str("topic", msg.GetTopic()).
 					Str("sender-id", msg.GetFrom().String()).
 					Msg("failed to handle message")
 			}
+			m, traceContext, err := UnmarshalPubsubMessage(msg)
+			if err != nil {
+				logError(err)
+				continue
+			}
+			err = handler.handlerPool.Submit(ctx, shardKey(m), func(ctx context.Context) {
+				handler.relay(ctx, msg, m)
+				if err := handler.handle(ctx, msg, m, traceContext, receivedAt); err != nil {
+					logError(err)
+				}
+			})
+			if err != nil {
+				return err
+			}
+		case dm := <-handler.P2P.DirectMessages:
+			if err := handler.receiveDirect(ctx, dm); err != nil {
+				return err
+			}
 		case <-ctx.Done():
 			return ctx.Err()
 		}
 	}
 }
 
-func (handler *P2PHandler) handle(ctx context.Context, msg *pubsub.Message) error {
+func (handler *P2PHandler) handle(
+	ctx context.Context,
+	msg *pubsub.Message,
+	m p2pmsg.Message,
+	traceContext *p2pmsg.TraceContext,
+	receivedAt time.Time,
+) error {
 	var msgsOut []p2pmsg.Message
 	var err error
 
-	m, traceContext, err := UnmarshalPubsubMessage(msg)
-	if err != nil {
-		return err
-	}
-
 	ctx, span, reportError := newSpanForReceive(ctx, handler.P2P, traceContext, msg, m)
 	defer span.End()
+	ctx = context.WithValue(ctx, senderKey{}, msg.GetFrom())
+	ctx = ContextWithDelivery(ctx, Delivery{
+		Origin:       msg.GetFrom(),
+		ReceivedFrom: msg.ReceivedFrom,
+		ReceivedAt:   receivedAt,
+	})
 
 	handlerFunc, exists := handler.handlerRegistry[proto.MessageName(m)]
 	if !exists {
@@ -299,18 +477,28 @@ func (handler *P2PHandler) handle(ctx context.Context, msg *pubsub.Message) erro
 	return nil
 }
 
+// DisablePublishing makes SendMessage drop all messages instead of publishing them, while
+// incoming messages are still received and handled. It must be called before the handler is
+// started.
+func (handler *P2PHandler) DisablePublishing() {
+	handler.publishDisabled = true
+}
+
 func (handler *P2PHandler) SendMessage(
 	ctx context.Context,
 	msg p2pmsg.Message,
 	retryOpts ...retry.Option,
 ) error {
+	if handler.publishDisabled {
+		log.Info().Str("message", msg.LogInfo()).Str("topic", msg.Topic()).Msg("publishing disabled, dropping message")
+		return nil
+	}
 	var traceContext *p2pmsg.TraceContext
 	ctx, span, reportError := newSpanForPublish(ctx, handler.P2P, traceContext, msg)
 	defer span.End()
 
-	msgBytes, err := p2pmsg.Marshal(msg, traceContext)
-	if err != nil {
-		return reportError(errors.Wrap(err, "failed to marshal p2p message"))
+	if route, ok := handler.directRoutes[proto.MessageName(msg)]; ok && handler.sendDirect(ctx, route, msg) {
+		return nil
 	}
 
 	// if no retry options are passed, don't do any retries!
@@ -318,14 +506,53 @@ func (handler *P2PHandler) SendMessage(
 		retryOpts = []retry.Option{retry.NumberOfRetries(0)}
 	}
 
-	log.Info().Str("message", msg.LogInfo()).Str("topic", msg.Topic()).
-		Msg("sending message")
-	_, callErr := retry.FunctionCall(
-		ctx,
-		func(ctx context.Context) (struct{}, error) {
-			return struct{}{}, handler.P2P.Publish(ctx, msg.Topic(), msgBytes)
-		},
-		retryOpts...,
-	)
-	return reportError(callErr)
+	if err := handler.publish(ctx, msg, p2pmsg.ProtocolVersion, traceContext, retryOpts); err != nil {
+		return reportError(err)
+	}
+	if handler.bridges(msg.Topic()) {
+		// publish on both sides of the bridge ourselves and make sure we don't relay the message
+		// again when we receive it from another bridging node
+		handler.bridged.add(msg)
+		if err := handler.publish(ctx, msg, handler.bridgeVersion, traceContext, retryOpts); err != nil {
+			return reportError(err)
+		}
+	}
+	return nil
+}
+
+// publish publishes the message on the topics of the given protocol version, in every configured
+// encoding and topic namespace.
+func (handler *P2PHandler) publish(
+	ctx context.Context,
+	msg p2pmsg.Message,
+	version uint64,
+	traceContext *p2pmsg.TraceContext,
+	retryOpts []retry.Option,
+) error {
+	versionMsg, err := p2pmsg.TranslateToVersion(msg, version)
+	if err != nil {
+		return errors.Wrapf(err, "failed to translate message to protocol version %d", version)
+	}
+	for _, enc := range handler.encodings {
+		msgBytes, err := p2pmsg.MarshalEncoding(versionMsg, traceContext, enc)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal p2p message")
+		}
+
+		for _, topic := range handler.namespacedTopics(version, p2pmsg.EncodedTopic(msg.Topic(), enc)) {
+			log.Info().Str("message", msg.LogInfo()).Str("topic", topic).
+				Msg("sending message")
+			_, err := retry.FunctionCall(
+				ctx,
+				func(ctx context.Context) (struct{}, error) {
+					return struct{}{}, handler.P2P.Publish(ctx, topic, msgBytes)
+				},
+				retryOpts...,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
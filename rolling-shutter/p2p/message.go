@@ -13,7 +13,7 @@ import (
 func UnmarshalPubsubMessage(msg *pubsub.Message) (p2pmsg.Message, *p2pmsg.TraceContext, error) {
 	var err error
 
-	unmshl, traceContext, err := p2pmsg.Unmarshal(msg.GetData())
+	unmshl, traceContext, err := p2pmsg.UnmarshalTopic(msg.GetTopic(), msg.GetData())
 	if err != nil {
 		return nil, traceContext, errors.Wrap(err, "failed to unmarshal message")
 	}
@@ -0,0 +1,61 @@
+package p2p
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var metricsResourceLimitHits = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "p2p",
+		Name:      "resource_limit_hits_total",
+		Help:      "Number of connections, streams and memory reservations blocked by a resource limit, by scope",
+	},
+	[]string{"scope", "resource"},
+)
+
+var metricsBridgedMessages = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "p2p",
+		Name:      "bridged_messages_total",
+		Help:      "Number of messages relayed between adjacent protocol versions, by topic and target version",
+	},
+	[]string{"topic", "version"},
+)
+
+var metricsDirectMessagesSent = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "p2p",
+		Name:      "direct_messages_sent_total",
+		Help:      "Number of messages sent directly to a peer instead of via gossip, by topic",
+	},
+	[]string{"topic"},
+)
+
+var metricsDirectMessagesFailed = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "p2p",
+		Name:      "direct_messages_failed_total",
+		Help:      "Number of messages that could not be sent directly to a peer, by topic",
+	},
+	[]string{"topic"},
+)
+
+var metricsDirectMessagesReceived = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "p2p",
+		Name:      "direct_messages_received_total",
+		Help:      "Number of valid messages received directly from a peer, by topic",
+	},
+	[]string{"topic"},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsResourceLimitHits)
+	prometheus.MustRegister(metricsBridgedMessages)
+	prometheus.MustRegister(metricsDirectMessagesSent)
+	prometheus.MustRegister(metricsDirectMessagesFailed)
+	prometheus.MustRegister(metricsDirectMessagesReceived)
+}
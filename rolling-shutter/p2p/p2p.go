@@ -12,6 +12,7 @@ import (
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/p2p/discovery/routing"
+	rcmgr "github.com/libp2p/go-libp2p/p2p/host/resource-manager"
 	rhost "github.com/libp2p/go-libp2p/p2p/host/routed"
 	"github.com/libp2p/go-libp2p/p2p/net/connmgr"
 	"github.com/multiformats/go-multiaddr"
@@ -42,6 +43,9 @@ const (
 	// messagesBufSize is the number of incoming messages to buffer for all of the rooms.
 	messagesBufSize = 128
 	protocolVersion = "/shutter/0.1.0"
+
+	connMgrLowWater  = 160
+	connMgrHighWater = 192
 )
 
 type Notifee interface {
@@ -59,6 +63,7 @@ type P2PNode struct {
 	gossipRooms map[string]*gossipRoom
 
 	GossipMessages chan *pubsub.Message
+	DirectMessages chan DirectMessage
 }
 
 type p2pNodeConfig struct {
@@ -69,6 +74,7 @@ type p2pNodeConfig struct {
 	IsBootstrapNode   bool
 	DisableTopicDHT   bool
 	DisableRoutingDHT bool
+	ResourceLimits    rcmgr.PartialLimitConfig
 }
 
 func NewP2PNode(config p2pNodeConfig) *P2PNode {
@@ -79,6 +85,7 @@ func NewP2PNode(config p2pNodeConfig) *P2PNode {
 		pubSub:         nil,
 		gossipRooms:    make(map[string]*gossipRoom),
 		GossipMessages: make(chan *pubsub.Message, messagesBufSize),
+		DirectMessages: make(chan DirectMessage, messagesBufSize),
 	}
 	return &p
 }
@@ -158,6 +165,8 @@ func (p *P2PNode) init(ctx context.Context) error {
 		return err
 	}
 
+	p2pHost.SetStreamHandler(directProtocolID, p.handleDirectStream)
+
 	p.host = p2pHost
 	p.dht = hashTable
 	p.connmngr = connectionManager
@@ -173,13 +182,17 @@ func createHost(
 	var err error
 
 	connectionManager, err := connmgr.NewConnManager(
-		160, // Lowwater
-		192, // HighWater,
+		connMgrLowWater,
+		connMgrHighWater,
 		connmgr.WithGracePeriod(time.Minute),
 	)
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	resourceManager, err := newResourceManager(config.ResourceLimits)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "failed to create resource manager")
+	}
 
 	options := []libp2p.Option{
 		libp2p.Identity(&config.PrivKey.Key),
@@ -187,6 +200,7 @@ func createHost(
 		libp2p.DefaultTransports,
 		libp2p.DefaultSecurity,
 		libp2p.ConnectionManager(connectionManager),
+		libp2p.ResourceManager(resourceManager),
 		libp2p.ProtocolVersion(protocolVersion),
 	}
 
@@ -323,6 +337,32 @@ func (p *P2PNode) joinTopic(topicName string) error {
 	return nil
 }
 
+// PauseTopics unsubscribes from the given topics, without leaving them, until they are resumed
+// with ResumeTopics. Topics that have not been joined are ignored.
+func (p *P2PNode) PauseTopics(topicNames []string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	for _, topicName := range topicNames {
+		if room, ok := p.gossipRooms[topicName]; ok {
+			room.pause()
+		}
+	}
+}
+
+// ResumeTopics subscribes to the given topics again after they have been paused.
+func (p *P2PNode) ResumeTopics(topicNames []string) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	for _, topicName := range topicNames {
+		if room, ok := p.gossipRooms[topicName]; ok {
+			if err := room.resume(); err != nil {
+				return errors.Wrapf(err, "failed to resubscribe to topic %s", topicName)
+			}
+		}
+	}
+	return nil
+}
+
 func (p *P2PNode) GetMultiaddr() (multiaddr.Multiaddr, error) {
 	p.mux.Lock()
 	defer p.mux.Unlock()
@@ -342,3 +382,13 @@ func (p *P2PNode) HostID() string {
 	defer p.mux.Unlock()
 	return p.host.ID().String()
 }
+
+// PeerCount returns the number of peers the node is connected to.
+func (p *P2PNode) PeerCount() int {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.host == nil {
+		return 0
+	}
+	return len(p.host.Network().Peers())
+}
@@ -26,7 +26,7 @@ func TestStartNetworkNodeIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 1200*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 2400*time.Millisecond)
 	defer cancel()
 
 	numBootstrappers := 2
@@ -87,7 +87,7 @@ func TestStartNetworkNodeIntegration(t *testing.T) {
 	waitGroup := sync.WaitGroup{}
 	p2ps := []*P2PNode{}
 	for _, cfg := range configs {
-		p2pHandler, err := New(cfg)
+		p2pHandler, err := New(cfg, 0)
 		assert.NilError(t, err)
 		p2ps = append(p2ps, p2pHandler.P2P)
 		waitGroup.Add(1)
@@ -103,24 +103,27 @@ func TestStartNetworkNodeIntegration(t *testing.T) {
 	}()
 	// The following loop publishes the same message over and over. Even though we did call
 	// ConnectToPeer, libp2p takes some time until the peer receives the first message.
-	var message *pubsub.Message
 	topicName := gossipTopicNames[0]
-	for message == nil {
-		if err := p2ps[1].Publish(ctx, topicName, testMessage); err != nil {
-			t.Fatalf("error while publishing message: %v", err)
-		}
+	receive := func() *pubsub.Message {
+		for {
+			if err := p2ps[1].Publish(ctx, topicName, testMessage); err != nil {
+				t.Fatalf("error while publishing message: %v", err)
+			}
 
-		select {
-		case message = <-p2ps[0].GossipMessages:
-			log.Info().Interface("message", message).Msg("got message")
-			if message == nil {
-				t.Fatalf("channel closed unexpectedly")
+			select {
+			case message := <-p2ps[0].GossipMessages:
+				log.Info().Interface("message", message).Msg("got message")
+				if message == nil {
+					t.Fatalf("channel closed unexpectedly")
+				}
+				return message
+			case <-ctx.Done():
+				t.Fatalf("waiting for message: %s", ctx.Err())
+			case <-time.After(5 * time.Millisecond):
 			}
-		case <-ctx.Done():
-			t.Fatalf("waiting for message: %s", ctx.Err())
-		case <-time.After(5 * time.Millisecond):
 		}
 	}
+	message := receive()
 	assert.Equal(t, topicName, message.GetTopic(), "received message with wrong topic")
 	assert.Check(t, bytes.Equal(testMessage, message.GetData()), "received wrong message")
 	assert.Equal(
@@ -129,4 +132,10 @@ func TestStartNetworkNodeIntegration(t *testing.T) {
 		message.GetFrom().String(),
 		"received message with wrong sender",
 	)
+
+	// messages are received again once a paused topic is resumed
+	p2ps[0].PauseTopics([]string{topicName})
+	assert.NilError(t, p2ps[0].ResumeTopics([]string{topicName}))
+	message = receive()
+	assert.Equal(t, topicName, message.GetTopic(), "received message with wrong topic")
 }
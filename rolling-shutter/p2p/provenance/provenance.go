@@ -0,0 +1,177 @@
+// Package provenance records where the p2p messages a node accepted came from. When bad data is
+// found in the database, the records tell which peer delivered the message it was derived from.
+package provenance
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/provenancedb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/storagemonitor"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+const pruneInterval = time.Hour
+
+// recordingHandler records the provenance of every message the wrapped handler handled
+// successfully, unless the storage monitor stops non-essential writes.
+type recordingHandler struct {
+	p2p.MessageHandler
+	dbpool  *pgxpool.Pool
+	storage *storagemonitor.Monitor
+}
+
+// Wrap returns the given handlers wrapped so that the provenance of the messages they accept is
+// recorded. storage may be nil.
+func Wrap(
+	dbpool *pgxpool.Pool, storage *storagemonitor.Monitor, handlers ...p2p.MessageHandler,
+) []p2p.MessageHandler {
+	wrapped := make([]p2p.MessageHandler, len(handlers))
+	for i, h := range handlers {
+		wrapped[i] = recordingHandler{MessageHandler: h, dbpool: dbpool, storage: storage}
+	}
+	return wrapped
+}
+
+func (h recordingHandler) HandleMessage(ctx context.Context, msg p2pmsg.Message) ([]p2pmsg.Message, error) {
+	msgsOut, err := h.MessageHandler.HandleMessage(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	if !h.storage.AllowNonEssentialWrites() {
+		return msgsOut, nil
+	}
+	if err := Record(ctx, provenancedb.New(h.dbpool), msg); err != nil {
+		// the message has been handled already, so we still send out the resulting messages
+		log.Error().Err(err).Str("message", msg.LogInfo()).Msg("failed to record message provenance")
+	}
+	return msgsOut, nil
+}
+
+// Record records the provenance of a message from the delivery stored in the context by the p2p
+// handler. Messages handled without a delivery, i.e. not received from the network, are skipped.
+func Record(ctx context.Context, db *provenancedb.Queries, msg p2pmsg.Message) error {
+	delivery, ok := p2p.DeliveryFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	hash, err := MessageHash(msg)
+	if err != nil {
+		return err
+	}
+	return db.InsertMessageProvenance(ctx, provenancedb.InsertMessageProvenanceParams{
+		ReceivedAt:   delivery.ReceivedAt,
+		Origin:       delivery.Origin.String(),
+		ReceivedFrom: delivery.ReceivedFrom.String(),
+		MessageType:  string(proto.MessageName(msg)),
+		Epochs:       Epochs(msg),
+		MessageHash:  hash,
+		Details:      msg.LogInfo(),
+	})
+}
+
+// MessageHash returns the hash identifying a message in the provenance records and the audit log.
+func MessageHash(msg p2pmsg.Message) ([]byte, error) {
+	msgBytes, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal message")
+	}
+	return crypto.Keccak256(msgBytes), nil
+}
+
+// Epochs returns the ids of the epochs a message concerns, which is empty for messages that don't
+// concern any epoch.
+func Epochs(msg p2pmsg.Message) [][]byte {
+	epochs := [][]byte{}
+	switch m := msg.(type) {
+	case *p2pmsg.DecryptionTrigger:
+		epochs = append(epochs, m.EpochID)
+	case *p2pmsg.DecryptionTriggerBatch:
+		triggers, err := m.Triggers()
+		if err != nil {
+			// accepted batches are valid, so this doesn't happen for the messages we record
+			return epochs
+		}
+		for _, trigger := range triggers {
+			epochs = append(epochs, trigger.EpochID)
+		}
+	case *p2pmsg.EpochPreAnnouncement:
+		epochs = append(epochs, m.EpochID)
+	case *p2pmsg.DecryptionKeyShares:
+		for _, share := range m.GetShares() {
+			epochs = append(epochs, share.GetEpochID())
+		}
+	case *p2pmsg.DecryptionKey:
+		epochs = append(epochs, m.EpochID)
+	}
+	return epochs
+}
+
+// NewPruner returns a service that periodically deletes the provenance records of messages
+// received before the given retention period.
+func NewPruner(dbpool *pgxpool.Pool, retention time.Duration) service.Service {
+	return service.ServiceFn{Fn: func(ctx context.Context) error {
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+		for {
+			n, err := provenancedb.New(dbpool).Prune(ctx, retention)
+			if err != nil {
+				log.Warn().Err(err).Msg("failed to prune message provenance")
+			} else if n > 0 {
+				log.Info().Int64("num-records", n).Msg("pruned message provenance")
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	}}
+}
+
+type recordJSON struct {
+	ID           int64           `json:"id"`
+	ReceivedAt   time.Time       `json:"receivedAt"`
+	Origin       string          `json:"origin"`
+	ReceivedFrom string          `json:"receivedFrom"`
+	MessageType  string          `json:"messageType"`
+	Epochs       []hexutil.Bytes `json:"epochs"`
+	MessageHash  hexutil.Bytes   `json:"messageHash"`
+	Details      string          `json:"details"`
+}
+
+// WriteJSON writes the given records as JSON, one record per line.
+func WriteJSON(w io.Writer, records []provenancedb.MessageProvenance) error {
+	encoder := json.NewEncoder(w)
+	for _, r := range records {
+		epochs := make([]hexutil.Bytes, len(r.Epochs))
+		for i, epoch := range r.Epochs {
+			epochs[i] = epoch
+		}
+		err := encoder.Encode(recordJSON{
+			ID:           r.ID,
+			ReceivedAt:   r.ReceivedAt,
+			Origin:       r.Origin,
+			ReceivedFrom: r.ReceivedFrom,
+			MessageType:  r.MessageType,
+			Epochs:       epochs,
+			MessageHash:  r.MessageHash,
+			Details:      r.Details,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package provenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/provenancedb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+type testMessageHandler struct {
+	err error
+}
+
+func (h testMessageHandler) ValidateMessage(context.Context, p2pmsg.Message) (bool, error) {
+	return true, nil
+}
+
+func (h testMessageHandler) HandleMessage(context.Context, p2pmsg.Message) ([]p2pmsg.Message, error) {
+	return nil, h.err
+}
+
+func (h testMessageHandler) MessagePrototypes() []p2pmsg.Message {
+	return []p2pmsg.Message{&p2pmsg.DecryptionKey{}}
+}
+
+func TestEpochs(t *testing.T) {
+	assert.DeepEqual(t, Epochs(&p2pmsg.DecryptionKey{EpochID: []byte{1}}), [][]byte{{1}})
+	assert.DeepEqual(t, Epochs(&p2pmsg.EpochPreAnnouncement{EpochID: []byte{2}}), [][]byte{{2}})
+	shares := &p2pmsg.DecryptionKeyShares{Shares: []*p2pmsg.KeyShare{{EpochID: []byte{3}}, {EpochID: []byte{4}}}}
+	assert.DeepEqual(t, Epochs(shares), [][]byte{{3}, {4}})
+	// the column is not nullable, so messages without epochs must not result in nil
+	epochs := Epochs(&p2pmsg.PauseVote{Sequence: 1})
+	assert.Assert(t, epochs != nil)
+	assert.Equal(t, len(epochs), 0)
+}
+
+func TestRecordIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbpool, closedb := testdb.NewKeyperTestDB(ctx, t)
+	defer closedb()
+	queries := provenancedb.New(dbpool)
+
+	handlers := Wrap(dbpool, nil, testMessageHandler{}, testMessageHandler{err: context.Canceled})
+	msg := &p2pmsg.DecryptionKey{InstanceID: 1, Eon: 2, EpochID: []byte{3}}
+	delivery := p2p.Delivery{
+		Origin:       peer.ID("origin"),
+		ReceivedFrom: peer.ID("relay"),
+		ReceivedAt:   time.Now().Add(-time.Minute).Truncate(time.Microsecond),
+	}
+	_, err := handlers[0].HandleMessage(p2p.ContextWithDelivery(ctx, delivery), msg)
+	assert.NilError(t, err)
+	_, err = handlers[1].HandleMessage(p2p.ContextWithDelivery(ctx, delivery), msg)
+	assert.ErrorIs(t, err, context.Canceled)
+	// messages not received from the network have no provenance
+	_, err = handlers[0].HandleMessage(ctx, msg)
+	assert.NilError(t, err)
+
+	query := provenancedb.FindMessageProvenanceParams{
+		Since:      time.Now().Add(-time.Hour),
+		Until:      time.Now().Add(time.Hour),
+		MaxEntries: 10,
+	}
+	records, err := queries.FindMessageProvenance(ctx, query)
+	assert.NilError(t, err)
+	assert.Equal(t, len(records), 1, "only successfully handled messages must be recorded")
+	assert.Equal(t, records[0].Origin, delivery.Origin.String())
+	assert.Equal(t, records[0].ReceivedFrom, delivery.ReceivedFrom.String())
+	assert.Assert(t, records[0].ReceivedAt.Equal(delivery.ReceivedAt))
+	assert.Equal(t, records[0].MessageType, "p2pmsg.DecryptionKey")
+	assert.DeepEqual(t, records[0].Epochs, [][]byte{{3}})
+	hash, err := MessageHash(msg)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, records[0].MessageHash, hash)
+
+	for _, tc := range []struct {
+		query   provenancedb.FindMessageProvenanceParams
+		records int
+	}{
+		{provenancedb.FindMessageProvenanceParams{Peer: delivery.ReceivedFrom.String()}, 1},
+		{provenancedb.FindMessageProvenanceParams{Peer: peer.ID("other").String()}, 0},
+		{provenancedb.FindMessageProvenanceParams{MessageType: "p2pmsg.DecryptionKeyShares"}, 0},
+		{provenancedb.FindMessageProvenanceParams{EpochID: []byte{3}}, 1},
+		{provenancedb.FindMessageProvenanceParams{EpochID: []byte{4}}, 0},
+		{provenancedb.FindMessageProvenanceParams{MessageHash: hash}, 1},
+	} {
+		tc.query.Since, tc.query.Until, tc.query.MaxEntries = query.Since, query.Until, query.MaxEntries
+		records, err := queries.FindMessageProvenance(ctx, tc.query)
+		assert.NilError(t, err)
+		assert.Equal(t, len(records), tc.records, "%+v", tc.query)
+	}
+
+	n, err := queries.Prune(ctx, time.Hour)
+	assert.NilError(t, err)
+	assert.Equal(t, n, int64(0))
+	n, err = queries.Prune(ctx, time.Second)
+	assert.NilError(t, err)
+	assert.Equal(t, n, int64(1))
+}
+
+var _ p2p.MessageHandler = testMessageHandler{}
@@ -0,0 +1,80 @@
+package p2p
+
+import (
+	"strings"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/network"
+	rcmgr "github.com/libp2p/go-libp2p/p2p/host/resource-manager"
+	"github.com/rs/zerolog/log"
+)
+
+const megabyte = 1 << 20
+
+// resourceLimits returns the limits of the resource manager set in the config. Limits that are
+// not set, i.e. zero, keep the default of libp2p, which is scaled to the memory and file
+// descriptors of the machine.
+func (c *Config) resourceLimits() rcmgr.PartialLimitConfig {
+	return rcmgr.PartialLimitConfig{
+		System: rcmgr.ResourceLimits{
+			Conns:   rcmgr.LimitVal(c.MaxConnections),
+			Streams: rcmgr.LimitVal(c.MaxStreams),
+			Memory:  rcmgr.LimitVal64(c.MaxMemoryMB) * megabyte,
+		},
+		PeerDefault: rcmgr.ResourceLimits{
+			Conns:   rcmgr.LimitVal(c.MaxConnectionsPerPeer),
+			Streams: rcmgr.LimitVal(c.MaxStreamsPerPeer),
+			Memory:  rcmgr.LimitVal64(c.MaxMemoryPerPeerMB) * megabyte,
+		},
+		ProtocolDefault: rcmgr.ResourceLimits{
+			Streams: rcmgr.LimitVal(c.MaxStreamsPerProtocol),
+		},
+	}
+}
+
+// newResourceManager creates the resource manager enforcing the given limits on top of the
+// default limits of libp2p. Requests it blocks are counted in metricsResourceLimitHits.
+func newResourceManager(limits rcmgr.PartialLimitConfig) (network.ResourceManager, error) {
+	defaults := rcmgr.DefaultLimits
+	libp2p.SetDefaultServiceLimits(&defaults)
+	limiter := rcmgr.NewFixedLimiter(limits.Build(defaults.AutoScale()))
+	return rcmgr.NewResourceManager(limiter, rcmgr.WithTraceReporter(limitHitReporter{}))
+}
+
+// limitHitReporter counts the requests for connections, streams and memory the resource manager
+// blocked because a limit was hit.
+type limitHitReporter struct{}
+
+func (limitHitReporter) ConsumeEvent(evt rcmgr.TraceEvt) {
+	var resource string
+	switch evt.Type { //nolint:exhaustive
+	case rcmgr.TraceBlockAddConnEvt:
+		resource = "conns"
+	case rcmgr.TraceBlockAddStreamEvt:
+		resource = "streams"
+	case rcmgr.TraceBlockReserveMemoryEvt:
+		resource = "memory"
+	default:
+		return
+	}
+	scope := scopeClass(evt.Name)
+	metricsResourceLimitHits.WithLabelValues(scope, resource).Inc()
+	log.Debug().Str("scope", evt.Name).Str("resource", resource).Msg("p2p resource limit hit")
+}
+
+// scopeClass maps the name of a resource manager scope to its class, e.g. "peer" for the scope of
+// a single peer, so that metrics don't get a label value per peer, connection or stream.
+func scopeClass(name string) string {
+	switch {
+	case strings.HasPrefix(name, "conn-"):
+		return "conn"
+	case strings.HasPrefix(name, "stream-"):
+		return "stream"
+	}
+	class, _, _ := strings.Cut(name, ":")
+	if class != "peer" && strings.Contains(name, ".peer:") {
+		// the scope of a peer within a protocol or service
+		class += "-peer"
+	}
+	return class
+}
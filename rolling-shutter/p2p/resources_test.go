@@ -0,0 +1,38 @@
+package p2p
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestScopeClass(t *testing.T) {
+	peer := "peer:12D3KooWEyoppNCUx8Yx66oV9fJnriXwCcXwDDUA2kj6vnc6iDEp"
+	for name, class := range map[string]string{
+		"system":                          "system",
+		"transient":                       "transient",
+		peer:                              "peer",
+		"protocol:/meshsub/1.1.0":         "protocol",
+		"protocol:/meshsub/1.1.0." + peer: "protocol-peer",
+		"service:libp2p.identify":         "service",
+		"service:libp2p.identify." + peer: "service-peer",
+		"conn-17":                         "conn",
+		"stream-42":                       "stream",
+	} {
+		assert.Equal(t, scopeClass(name), class, name)
+	}
+}
+
+func TestValidateResourceLimits(t *testing.T) {
+	cfg := NewConfig()
+	assert.NilError(t, cfg.SetDefaultValues())
+	assert.NilError(t, cfg.validateResourceLimits())
+
+	cfg.MaxConnections = connMgrHighWater - 1
+	assert.ErrorContains(t, cfg.validateResourceLimits(), "MaxConnections must be at least")
+
+	cfg.MaxConnections = 0
+	cfg.MaxStreams = 10
+	cfg.MaxStreamsPerPeer = 11
+	assert.ErrorContains(t, cfg.validateResourceLimits(), "MaxStreamsPerPeer must not exceed")
+}
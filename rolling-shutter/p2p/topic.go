@@ -2,6 +2,7 @@ package p2p
 
 import (
 	"context"
+	"sync"
 
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -10,12 +11,17 @@ import (
 // gossipRoom represents a subscription to a single PubSub topic. Messages
 // can be published to the topic with gossipRoom.Publish, and received
 // messages are pushed to the Messages channel.
+// While the room is paused, it is unsubscribed from the topic, so that we neither receive nor
+// relay its messages. Publishing is still possible.
 type gossipRoom struct {
-	pubSub       *pubsub.PubSub
-	topic        *pubsub.Topic
-	subscription *pubsub.Subscription
-	topicName    string
-	self         peer.ID
+	pubSub    *pubsub.PubSub
+	topic     *pubsub.Topic
+	topicName string
+	self      peer.ID
+
+	mux          sync.Mutex
+	subscription *pubsub.Subscription // nil while paused
+	resumed      chan struct{}        // closed when the room is resumed
 }
 
 // Publish sends a message to the pubsub topic.
@@ -27,10 +33,73 @@ func (room *gossipRoom) ListPeers() []peer.ID {
 	return room.pubSub.ListPeers(room.topicName)
 }
 
+// pause unsubscribes from the topic. It does nothing if the room is paused already.
+func (room *gossipRoom) pause() {
+	room.mux.Lock()
+	defer room.mux.Unlock()
+	if room.subscription == nil {
+		return
+	}
+	room.subscription.Cancel()
+	room.subscription = nil
+	room.resumed = make(chan struct{})
+}
+
+// resume subscribes to the topic again. It does nothing if the room is not paused.
+func (room *gossipRoom) resume() error {
+	room.mux.Lock()
+	defer room.mux.Unlock()
+	if room.subscription != nil {
+		return nil
+	}
+	sub, err := room.topic.Subscribe()
+	if err != nil {
+		return err
+	}
+	room.subscription = sub
+	close(room.resumed)
+	return nil
+}
+
+// currentSubscription returns the subscription of the room, waiting for the room to be resumed if
+// it is paused.
+func (room *gossipRoom) currentSubscription(ctx context.Context) (*pubsub.Subscription, error) {
+	for {
+		room.mux.Lock()
+		sub, resumed := room.subscription, room.resumed
+		room.mux.Unlock()
+		if sub != nil {
+			return sub, nil
+		}
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// isSubscribed reports whether sub is the current subscription of the room.
+func (room *gossipRoom) isSubscribed(sub *pubsub.Subscription) bool {
+	room.mux.Lock()
+	defer room.mux.Unlock()
+	return room.subscription == sub
+}
+
 // readLoop pulls messages from the pubsub topic and pushes them onto the given messages channel.
 func (room *gossipRoom) readLoop(ctx context.Context, messages chan *pubsub.Message) error {
 	for {
-		msg, err := room.subscription.Next(ctx)
+		sub, err := room.currentSubscription(ctx)
+		if err != nil {
+			return err
+		}
+		msg, err := sub.Next(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == pubsub.ErrSubscriptionCancelled && !room.isSubscribed(sub) {
+			continue // the room has been paused
+		}
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,100 @@
+package p2pmsg
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+)
+
+var attestationHashPrefix = []byte{0x19, 'a', 't', 't', 'e', 's', 't'}
+
+// maxAttestationFieldLength bounds the length of the role and the version of an attestation.
+const maxAttestationFieldLength = 64
+
+// NewSignedNodeAttestation attests that the node with the given key runs in the given role and
+// software version and supports the given protocol versions.
+func NewSignedNodeAttestation(
+	instanceID uint64,
+	role string,
+	version string,
+	protocolVersions []uint64,
+	timestamp time.Time,
+	privKey *ecdsa.PrivateKey,
+) (*NodeAttestation, error) {
+	attestation := &NodeAttestation{
+		InstanceID:       instanceID,
+		Address:          ethcrypto.PubkeyToAddress(privKey.PublicKey).Bytes(),
+		Role:             role,
+		Version:          version,
+		ProtocolVersions: protocolVersions,
+		Timestamp:        uint64(timestamp.Unix()),
+	}
+	if err := Sign(attestation, privKey); err != nil {
+		return nil, err
+	}
+	return attestation, nil
+}
+
+// AttesterAddress returns the address the attestation is made for.
+func (attestation *NodeAttestation) AttesterAddress() common.Address {
+	return common.BytesToAddress(attestation.Address)
+}
+
+// AttestationTime returns the time the attestation was made at.
+func (attestation *NodeAttestation) AttestationTime() time.Time {
+	return time.Unix(int64(attestation.Timestamp), 0)
+}
+
+// SupportsProtocolVersion checks if the node supports the given p2p protocol version.
+func (attestation *NodeAttestation) SupportsProtocolVersion(version uint64) bool {
+	for _, v := range attestation.ProtocolVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify checks that the attestation is signed by the node it is made for.
+func (attestation *NodeAttestation) Verify() error {
+	ok, err := VerifySignature(attestation, attestation.AttesterAddress())
+	if err != nil {
+		return errors.Wrap(err, "failed to recover signer of node attestation")
+	}
+	if !ok {
+		return errors.Errorf("node attestation not signed by %s", attestation.AttesterAddress().Hex())
+	}
+	return nil
+}
+
+func (attestation *NodeAttestation) SetSignature(s []byte) {
+	attestation.Signature = s
+}
+
+func (attestation *NodeAttestation) Hash() []byte {
+	hash := sha3.New256()
+	hash.Write(attestationHashPrefix)
+	_ = binary.Write(hash, binary.BigEndian, attestation.InstanceID)
+	hash.Write(attestation.Address)
+	writeString(hash, attestation.Role)
+	writeString(hash, attestation.Version)
+	_ = binary.Write(hash, binary.BigEndian, uint64(len(attestation.ProtocolVersions)))
+	for _, v := range attestation.ProtocolVersions {
+		_ = binary.Write(hash, binary.BigEndian, v)
+	}
+	_ = binary.Write(hash, binary.BigEndian, attestation.Timestamp)
+	return hash.Sum(nil)
+}
+
+// writeString writes s prefixed with its length, so that adjacent strings can't be shifted into
+// each other.
+func writeString(w io.Writer, s string) {
+	_ = binary.Write(w, binary.BigEndian, uint64(len(s)))
+	_, _ = w.Write([]byte(s))
+}
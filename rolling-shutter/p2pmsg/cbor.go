@@ -0,0 +1,275 @@
+package p2pmsg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// cborCodec encodes messages as CBOR (RFC 8949). A message is encoded as a map from field number
+// to field value containing all fields in ascending order, repeated fields are encoded as arrays.
+// Only the deterministic encoding with definite lengths and shortest-form integers is accepted.
+type cborCodec struct{}
+
+const (
+	cborMajorUint  = 0
+	cborMajorBytes = 2
+	cborMajorText  = 3
+	cborMajorArray = 4
+	cborMajorMap   = 5
+
+	cborFalse = 0xf4
+	cborTrue  = 0xf5
+)
+
+var errCBORUnexpectedEnd = errors.New("unexpected end of CBOR data")
+
+func (cborCodec) Encoding() Encoding {
+	return EncodingCBOR
+}
+
+func (cborCodec) Marshal(msg Message, _ *TraceContext) ([]byte, error) {
+	return cborAppendMessage(nil, msg.ProtoReflect())
+}
+
+func (cborCodec) Unmarshal(data []byte, prototype Message) (Message, *TraceContext, error) {
+	msg, err := newMessage(prototype)
+	if err != nil {
+		return nil, nil, err
+	}
+	rest, err := cborReadMessage(data, msg.ProtoReflect())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to unmarshal CBOR message")
+	}
+	if len(rest) != 0 {
+		return nil, nil, errors.Errorf("%d bytes of trailing data after CBOR message", len(rest))
+	}
+	return msg, nil, nil
+}
+
+func cborAppendHead(b []byte, major byte, n uint64) []byte {
+	m := major << 5
+	switch {
+	case n < 24:
+		return append(b, m|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, m|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, m|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, m|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, m|27), n)
+	}
+}
+
+func cborReadHead(b []byte) (byte, uint64, []byte, error) {
+	if len(b) == 0 {
+		return 0, 0, nil, errCBORUnexpectedEnd
+	}
+	major := b[0] >> 5
+	info := b[0] & 0x1f
+	b = b[1:]
+
+	var n, minValue uint64
+	switch {
+	case info < 24:
+		return major, uint64(info), b, nil
+	case info == 24:
+		if len(b) < 1 {
+			return 0, 0, nil, errCBORUnexpectedEnd
+		}
+		n, minValue, b = uint64(b[0]), 24, b[1:]
+	case info == 25:
+		if len(b) < 2 {
+			return 0, 0, nil, errCBORUnexpectedEnd
+		}
+		n, minValue, b = uint64(binary.BigEndian.Uint16(b)), math.MaxUint8+1, b[2:]
+	case info == 26:
+		if len(b) < 4 {
+			return 0, 0, nil, errCBORUnexpectedEnd
+		}
+		n, minValue, b = uint64(binary.BigEndian.Uint32(b)), math.MaxUint16+1, b[4:]
+	case info == 27:
+		if len(b) < 8 {
+			return 0, 0, nil, errCBORUnexpectedEnd
+		}
+		n, minValue, b = binary.BigEndian.Uint64(b), math.MaxUint32+1, b[8:]
+	default:
+		return 0, 0, nil, errors.Errorf("unsupported CBOR additional information %d", info)
+	}
+	if n < minValue {
+		return 0, 0, nil, errors.Errorf("non-canonical CBOR integer encoding of %d", n)
+	}
+	return major, n, b, nil
+}
+
+func cborAppendMessage(b []byte, m protoreflect.Message) ([]byte, error) {
+	var err error
+
+	fields := sortedFields(m.Descriptor())
+	b = cborAppendHead(b, cborMajorMap, uint64(len(fields)))
+	for _, fd := range fields {
+		b = cborAppendHead(b, cborMajorUint, uint64(fd.Number()))
+		v := m.Get(fd)
+		switch {
+		case fd.IsMap():
+			return nil, unsupportedField(fd)
+		case fd.IsList():
+			l := v.List()
+			b = cborAppendHead(b, cborMajorArray, uint64(l.Len()))
+			for i := 0; i < l.Len(); i++ {
+				b, err = cborAppendValue(b, fd, l.Get(i))
+				if err != nil {
+					return nil, err
+				}
+			}
+		default:
+			b, err = cborAppendValue(b, fd, v)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b, nil
+}
+
+func cborAppendValue(b []byte, fd protoreflect.FieldDescriptor, v protoreflect.Value) ([]byte, error) {
+	switch fd.Kind() {
+	case protoreflect.Uint64Kind:
+		return cborAppendHead(b, cborMajorUint, v.Uint()), nil
+	case protoreflect.BoolKind:
+		if v.Bool() {
+			return append(b, cborTrue), nil
+		}
+		return append(b, cborFalse), nil
+	case protoreflect.BytesKind:
+		b = cborAppendHead(b, cborMajorBytes, uint64(len(v.Bytes())))
+		return append(b, v.Bytes()...), nil
+	case protoreflect.StringKind:
+		b = cborAppendHead(b, cborMajorText, uint64(len(v.String())))
+		return append(b, v.String()...), nil
+	case protoreflect.MessageKind:
+		return cborAppendMessage(b, v.Message())
+	default:
+		return nil, unsupportedField(fd)
+	}
+}
+
+func cborReadMessage(b []byte, m protoreflect.Message) ([]byte, error) {
+	major, numFields, b, err := cborReadHead(b)
+	if err != nil {
+		return nil, err
+	}
+	if major != cborMajorMap {
+		return nil, errors.Errorf("expected CBOR map for message %s", m.Descriptor().FullName())
+	}
+
+	fields := m.Descriptor().Fields()
+	seen := make(map[protoreflect.FieldNumber]struct{})
+	for i := uint64(0); i < numFields; i++ {
+		var fieldNumber uint64
+		major, fieldNumber, b, err = cborReadHead(b)
+		if err != nil {
+			return nil, err
+		}
+		if major != cborMajorUint || fieldNumber > math.MaxInt32 {
+			return nil, errors.Errorf("invalid CBOR map key in message %s", m.Descriptor().FullName())
+		}
+		fd := fields.ByNumber(protoreflect.FieldNumber(fieldNumber))
+		if fd == nil || fd.IsMap() {
+			return nil, errors.Errorf("unknown field %d in message %s", fieldNumber, m.Descriptor().FullName())
+		}
+		if _, ok := seen[fd.Number()]; ok {
+			return nil, errors.Errorf("duplicate field %s", fd.FullName())
+		}
+		seen[fd.Number()] = struct{}{}
+
+		if !fd.IsList() {
+			var v protoreflect.Value
+			v, b, err = cborReadValue(b, fd, m.NewField(fd))
+			if err != nil {
+				return nil, err
+			}
+			m.Set(fd, v)
+			continue
+		}
+
+		var numElements uint64
+		major, numElements, b, err = cborReadHead(b)
+		if err != nil {
+			return nil, err
+		}
+		if major != cborMajorArray {
+			return nil, errors.Errorf("expected CBOR array for field %s", fd.FullName())
+		}
+		// every element takes at least one byte
+		if numElements > uint64(len(b)) {
+			return nil, errCBORUnexpectedEnd
+		}
+		l := m.Mutable(fd).List()
+		for j := uint64(0); j < numElements; j++ {
+			var v protoreflect.Value
+			v, b, err = cborReadValue(b, fd, l.NewElement())
+			if err != nil {
+				return nil, err
+			}
+			l.Append(v)
+		}
+	}
+	return b, nil
+}
+
+// cborReadValue reads a single value of the given field. For message fields, the value is read
+// into the message held by newValue.
+func cborReadValue(
+	b []byte,
+	fd protoreflect.FieldDescriptor,
+	newValue protoreflect.Value,
+) (protoreflect.Value, []byte, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		if len(b) == 0 {
+			return protoreflect.Value{}, nil, errCBORUnexpectedEnd
+		}
+		switch b[0] {
+		case cborTrue:
+			return protoreflect.ValueOfBool(true), b[1:], nil
+		case cborFalse:
+			return protoreflect.ValueOfBool(false), b[1:], nil
+		default:
+			return protoreflect.Value{}, nil, errors.Errorf("expected CBOR boolean for field %s", fd.FullName())
+		}
+	case protoreflect.MessageKind:
+		rest, err := cborReadMessage(b, newValue.Message())
+		return newValue, rest, err
+	case protoreflect.Uint64Kind, protoreflect.BytesKind, protoreflect.StringKind:
+	default:
+		return protoreflect.Value{}, nil, unsupportedField(fd)
+	}
+
+	major, n, b, err := cborReadHead(b)
+	if err != nil {
+		return protoreflect.Value{}, nil, err
+	}
+	switch {
+	case fd.Kind() == protoreflect.Uint64Kind && major == cborMajorUint:
+		return protoreflect.ValueOfUint64(n), b, nil
+	case fd.Kind() == protoreflect.BytesKind && major == cborMajorBytes:
+		if n > uint64(len(b)) {
+			return protoreflect.Value{}, nil, errCBORUnexpectedEnd
+		}
+		return protoreflect.ValueOfBytes(bytes.Clone(b[:n])), b[n:], nil
+	case fd.Kind() == protoreflect.StringKind && major == cborMajorText:
+		if n > uint64(len(b)) {
+			return protoreflect.Value{}, nil, errCBORUnexpectedEnd
+		}
+		return protoreflect.ValueOfString(string(b[:n])), b[n:], nil
+	default:
+		return protoreflect.Value{}, nil, errors.Errorf(
+			"unexpected CBOR major type %d for field %s", major, fd.FullName())
+	}
+}
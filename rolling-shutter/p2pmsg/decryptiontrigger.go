@@ -1,9 +1,13 @@
 package p2pmsg
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"encoding/binary"
+	"math/big"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
 	"golang.org/x/crypto/sha3"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
@@ -40,6 +44,113 @@ func (trigger *DecryptionTrigger) Hash() []byte {
 	return hash.Sum(nil)
 }
 
+// MaxDecryptionTriggerBatchSize is the maximum number of epochs a decryption trigger batch may
+// cover.
+const MaxDecryptionTriggerBatchSize = 256
+
+var triggerBatchHashPrefix = []byte{0x19, 't', 'r', 'i', 'g', 'g', 'e', 'r', 'B', 'a', 't', 'c', 'h'}
+
+// NewSignedDecryptionTriggerBatch combines triggers for contiguous epochs, in ascending order, into
+// a single batch signed with privKey. The signatures of the triggers are ignored.
+func NewSignedDecryptionTriggerBatch(
+	instanceID uint64, triggers []*DecryptionTrigger, privKey *ecdsa.PrivateKey,
+) (*DecryptionTriggerBatch, error) {
+	if len(triggers) == 0 || len(triggers) > MaxDecryptionTriggerBatchSize {
+		return nil, errors.Errorf("can't batch %d decryption triggers", len(triggers))
+	}
+	batch := &DecryptionTriggerBatch{
+		InstanceID:         instanceID,
+		FirstEpochID:       triggers[0].EpochID,
+		BlockNumbers:       make([]uint64, len(triggers)),
+		TransactionsHashes: make([][]byte, len(triggers)),
+	}
+	firstEpochID, err := epochid.BytesToEpochID(triggers[0].EpochID)
+	if err != nil {
+		return nil, err
+	}
+	for i, trigger := range triggers {
+		epochID, err := epochid.BigToEpochID(new(big.Int).Add(firstEpochID.Big(), big.NewInt(int64(i))))
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(trigger.EpochID, epochID.Bytes()) {
+			return nil, errors.Errorf("decryption triggers are not contiguous: expected epoch %s, got %x",
+				epochID.Hex(), trigger.EpochID)
+		}
+		batch.BlockNumbers[i] = trigger.BlockNumber
+		batch.TransactionsHashes[i] = trigger.TransactionsHash
+	}
+	if err := Sign(batch, privKey); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+func (batch *DecryptionTriggerBatch) SetSignature(s []byte) {
+	batch.Signature = s
+}
+
+func (batch *DecryptionTriggerBatch) Hash() []byte {
+	hash := sha3.New256()
+	hash.Write(triggerBatchHashPrefix)
+	_ = binary.Write(hash, binary.BigEndian, batch.InstanceID)
+	_ = binary.Write(hash, binary.BigEndian, batch.FirstEpochID)
+	_ = binary.Write(hash, binary.BigEndian, uint64(len(batch.BlockNumbers)))
+	for _, blockNumber := range batch.BlockNumbers {
+		_ = binary.Write(hash, binary.BigEndian, blockNumber)
+	}
+	hash.Write(HashByteList(batch.TransactionsHashes))
+	return hash.Sum(nil)
+}
+
+// Triggers expands the batch into a trigger for each epoch. The triggers are not signed, as the
+// signature of the batch covers all of them.
+func (batch *DecryptionTriggerBatch) Triggers() ([]*DecryptionTrigger, error) {
+	if err := batch.Validate(); err != nil {
+		return nil, err
+	}
+	firstEpochID, err := epochid.BytesToEpochID(batch.FirstEpochID)
+	if err != nil {
+		return nil, err
+	}
+	triggers := make([]*DecryptionTrigger, len(batch.BlockNumbers))
+	for i, blockNumber := range batch.BlockNumbers {
+		epochID, err := epochid.BigToEpochID(new(big.Int).Add(firstEpochID.Big(), big.NewInt(int64(i))))
+		if err != nil {
+			return nil, err
+		}
+		triggers[i] = &DecryptionTrigger{
+			InstanceID:       batch.InstanceID,
+			EpochID:          epochID.Bytes(),
+			BlockNumber:      blockNumber,
+			TransactionsHash: batch.TransactionsHashes[i],
+		}
+	}
+	return triggers, nil
+}
+
+// VerifyBatch checks that the hashes of the transactions in a batch, in batch order, match the
+// commitment of the collator in the trigger, which is the root of the Merkle tree over them.
+// Together with the signature of the trigger, this links the batch contents to the collator.
+func (trigger *DecryptionTrigger) VerifyBatch(txHashes [][]byte) error {
+	commitment := MerkleRoot(txHashes)
+	if !bytes.Equal(commitment, trigger.TransactionsHash) {
+		return errors.Errorf("batch commitment mismatch: trigger commits to %s, batch hashes to %s",
+			hexutil.Encode(trigger.TransactionsHash), hexutil.Encode(commitment))
+	}
+	return nil
+}
+
+// VerifyInclusion checks that the transaction with the given hash is at the given position of the
+// batch of numTxs transactions the trigger commits to, without needing the other transactions.
+func (trigger *DecryptionTrigger) VerifyInclusion(txHash []byte, position int, numTxs int, proof [][]byte) error {
+	if !VerifyMerkleProof(trigger.TransactionsHash, txHash, position, numTxs, proof) {
+		return errors.Errorf("transaction %s is not at position %d of the batch of epoch %s",
+			hexutil.Encode(txHash), position, hexutil.Encode(trigger.EpochID))
+	}
+	return nil
+}
+
 func HashByteList(l [][]byte) []byte {
 	hash := sha3.New256()
 	for _, bytes := range l {
@@ -0,0 +1,88 @@
+package p2pmsg
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+var domainHashPrefix = []byte{0x19, 0x01}
+
+// SigningDomain separates the signatures of messages that may eventually be submitted on-chain,
+// so that they can't be replayed on another chain or in another instance.
+type SigningDomain struct {
+	ChainID    uint64
+	InstanceID uint64
+}
+
+// DomainSignable is implemented by messages that may be signed in a signing domain. The signing
+// nonce is a sequence number the signer allocates for every message of a kind it signs in the
+// domain, see kprdb.AllocateNonce, so that a signature can only be submitted on-chain once.
+// Messages signed with the legacy hash don't have a nonce.
+type DomainSignable interface {
+	Signable
+	SigningKind() string
+	GetSigningNonce() uint64
+	SetSigningNonce(nonce uint64)
+}
+
+// Separator returns the hash identifying the domain and the kind of message.
+func (d SigningDomain) Separator(kind string) []byte {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], d.ChainID)
+	binary.BigEndian.PutUint64(b[8:], d.InstanceID)
+	return ethcrypto.Keccak256([]byte("shutter"), b[:], []byte(kind))
+}
+
+// Hash returns the hash signed in the domain. It commits to the domain, the kind and the nonce of
+// the message, and to the message itself via its legacy hash. It uses keccak256, so that it can be
+// checked on-chain.
+func (d SigningDomain) Hash(s DomainSignable) []byte {
+	var nonce [8]byte
+	binary.BigEndian.PutUint64(nonce[:], s.GetSigningNonce())
+	return ethcrypto.Keccak256(domainHashPrefix, d.Separator(s.SigningKind()), nonce[:], s.Hash())
+}
+
+// SignInDomain signs the message in the given domain.
+func SignInDomain(s DomainSignable, domain SigningDomain, privKey *ecdsa.PrivateKey) error {
+	signature, err := ethcrypto.Sign(domain.Hash(s), privKey)
+	if err != nil {
+		return err
+	}
+	s.SetSignature(signature)
+	return nil
+}
+
+// RecoverAddressInDomain recovers the address that signed the message in the given domain.
+func RecoverAddressInDomain(s DomainSignable, domain SigningDomain) (common.Address, error) {
+	pubkey, err := ethcrypto.SigToPub(domain.Hash(s), s.GetSignature())
+	if err != nil {
+		return common.Address{}, err
+	}
+	return ethcrypto.PubkeyToAddress(*pubkey), nil
+}
+
+// RecoverSigner recovers the signer of the message, which has to be accepted by isSigner. The
+// signature is checked in the given domain and, if legacy is set, against the legacy hash without
+// domain, so that nodes can accept both while a keyper set switches to signing in the domain.
+func RecoverSigner(
+	s DomainSignable, domain SigningDomain, legacy bool, isSigner func(common.Address) bool,
+) (common.Address, error) {
+	signer, err := RecoverAddressInDomain(s, domain)
+	if err == nil && isSigner(signer) {
+		return signer, nil
+	}
+	if legacy {
+		legacySigner, legacyErr := RecoverAddress(s)
+		if legacyErr == nil && isSigner(legacySigner) {
+			return legacySigner, nil
+		}
+	}
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "failed to recover signer")
+	}
+	return common.Address{}, errors.Errorf("signer %s is not allowed to sign %s", signer.Hex(), s.SigningKind())
+}
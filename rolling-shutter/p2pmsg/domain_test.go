@@ -0,0 +1,48 @@
+package p2pmsg
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/protobuf/proto"
+	"gotest.tools/v3/assert"
+)
+
+func TestSignInDomain(t *testing.T) {
+	privKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	signer := ethcrypto.PubkeyToAddress(privKey.PublicKey)
+	isSigner := func(address common.Address) bool { return address == signer }
+	domain := SigningDomain{ChainID: 1, InstanceID: 2}
+
+	key := &EonPublicKey{
+		InstanceID: 2, PublicKey: []byte("key"), ActivationBlock: 10, KeyperConfigIndex: 1, Eon: 5, SigningNonce: 1,
+	}
+	assert.NilError(t, SignInDomain(key, domain, privKey))
+	recovered, err := RecoverSigner(key, domain, false, isSigner)
+	assert.NilError(t, err)
+	assert.Equal(t, recovered, signer)
+
+	// the signature is bound to the chain, the instance, the nonce and the eon
+	for _, otherDomain := range []SigningDomain{{ChainID: 3, InstanceID: 2}, {ChainID: 1, InstanceID: 3}} {
+		_, err = RecoverSigner(key, otherDomain, true, isSigner)
+		assert.ErrorContains(t, err, "not allowed")
+	}
+	replayed := proto.Clone(key).(*EonPublicKey)
+	replayed.SigningNonce = 2
+	_, err = RecoverSigner(replayed, domain, true, isSigner)
+	assert.ErrorContains(t, err, "not allowed")
+	replayed = proto.Clone(key).(*EonPublicKey)
+	replayed.Eon = 6
+	_, err = RecoverSigner(replayed, domain, true, isSigner)
+	assert.ErrorContains(t, err, "not allowed")
+
+	// legacy signatures are only accepted if asked for
+	assert.NilError(t, Sign(key, privKey))
+	_, err = RecoverSigner(key, domain, false, isSigner)
+	assert.ErrorContains(t, err, "not allowed")
+	recovered, err = RecoverSigner(key, domain, true, isSigner)
+	assert.NilError(t, err)
+	assert.Equal(t, recovered, signer)
+}
@@ -0,0 +1,180 @@
+package p2pmsg
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Encoding is the wire encoding of a p2p message. Messages in the default protobuf encoding are
+// published on the plain message topic, messages in any other encoding on the message topic
+// suffixed with the name of the encoding, e.g. "decryptionKey/ssz".
+type Encoding string
+
+const (
+	EncodingProtobuf Encoding = "protobuf"
+	EncodingCBOR     Encoding = "cbor"
+	EncodingSSZ      Encoding = "ssz"
+
+	topicEncodingSeparator = "/"
+)
+
+// Codec marshals and unmarshals messages in a single encoding. Only the protobuf encoding wraps
+// messages in an envelope. The other encodings carry the bare message, which means the message
+// type is determined by the topic and no trace context is transmitted.
+type Codec interface {
+	Encoding() Encoding
+	Marshal(msg Message, traceContext *TraceContext) ([]byte, error)
+	Unmarshal(data []byte, prototype Message) (Message, *TraceContext, error)
+}
+
+var codecs = map[Encoding]Codec{
+	EncodingProtobuf: protobufCodec{},
+	EncodingCBOR:     cborCodec{},
+	EncodingSSZ:      sszCodec{},
+}
+
+// topicPrototypes maps each topic to the type of the messages published on it.
+var topicPrototypes = map[string]Message{}
+
+func init() {
+	for _, p := range []Message{
+		&DecryptionTrigger{},
+		&DecryptionTriggerBatch{},
+		&DecryptionKeyShares{},
+		&DecryptionKey{},
+		&EonPublicKey{},
+		&EpochPreAnnouncement{},
+		&NodeAttestation{},
+		&PauseVote{},
+	} {
+		topicPrototypes[p.Topic()] = p
+	}
+}
+
+// ParseEncoding returns the encoding with the given name.
+func ParseEncoding(name string) (Encoding, error) {
+	enc := Encoding(name)
+	if _, ok := codecs[enc]; !ok {
+		return "", errors.Errorf("unknown p2p message encoding %q", name)
+	}
+	return enc, nil
+}
+
+// GetCodec returns the codec for the given encoding.
+func GetCodec(enc Encoding) (Codec, error) {
+	codec, ok := codecs[enc]
+	if !ok {
+		return nil, errors.Errorf("unknown p2p message encoding %q", enc)
+	}
+	return codec, nil
+}
+
+// EncodedTopic returns the topic on which messages of the given topic are published in the given
+// encoding.
+func EncodedTopic(topic string, enc Encoding) string {
+	if enc == EncodingProtobuf {
+		return topic
+	}
+	return topic + topicEncodingSeparator + string(enc)
+}
+
+// SplitTopic splits an encoded topic into the message topic and the encoding.
+func SplitTopic(encodedTopic string) (string, Encoding) {
+	i := strings.LastIndex(encodedTopic, topicEncodingSeparator)
+	if i < 0 {
+		return encodedTopic, EncodingProtobuf
+	}
+	enc := Encoding(encodedTopic[i+len(topicEncodingSeparator):])
+	if _, ok := codecs[enc]; !ok || enc == EncodingProtobuf {
+		return encodedTopic, EncodingProtobuf
+	}
+	return encodedTopic[:i], enc
+}
+
+// MarshalEncoding marshals the message in the given encoding.
+func MarshalEncoding(msg Message, traceContext *TraceContext, enc Encoding) ([]byte, error) {
+	codec, err := GetCodec(enc)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Marshal(msg, traceContext)
+}
+
+// UnmarshalTopic unmarshals a message received on the given encoded topic, which may be
+// namespaced. Messages received on the topic of an adjacent protocol version are translated to
+// the current version.
+func UnmarshalTopic(namespacedTopic string, data []byte) (Message, *TraceContext, error) {
+	_, version, encodedTopic, namespaced := SplitNamespace(namespacedTopic)
+	msg, traceContext, err := unmarshalEncodedTopic(encodedTopic, data)
+	if err != nil || !namespaced {
+		return msg, traceContext, err
+	}
+	msg, err = TranslateFromVersion(msg, version)
+	if err != nil {
+		return nil, traceContext, errors.Wrapf(err, "failed to translate message from protocol version %d", version)
+	}
+	return msg, traceContext, nil
+}
+
+func unmarshalEncodedTopic(encodedTopic string, data []byte) (Message, *TraceContext, error) {
+	topic, enc := SplitTopic(encodedTopic)
+	if enc == EncodingProtobuf {
+		return Unmarshal(data)
+	}
+	prototype, ok := topicPrototypes[topic]
+	if !ok {
+		return nil, nil, errors.Errorf("no message type known for topic %q", topic)
+	}
+	return codecs[enc].Unmarshal(data, prototype)
+}
+
+type protobufCodec struct{}
+
+func (protobufCodec) Encoding() Encoding {
+	return EncodingProtobuf
+}
+
+func (protobufCodec) Marshal(msg Message, traceContext *TraceContext) ([]byte, error) {
+	return Marshal(msg, traceContext)
+}
+
+func (protobufCodec) Unmarshal(data []byte, prototype Message) (Message, *TraceContext, error) {
+	msg, traceContext, err := Unmarshal(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if prototype != nil && reflect.TypeOf(msg) != reflect.TypeOf(prototype) {
+		return nil, nil, errors.Errorf("received message of unexpected type %s", reflect.TypeOf(msg))
+	}
+	return msg, traceContext, nil
+}
+
+// newMessage returns a new, empty message of the same type as prototype.
+func newMessage(prototype Message) (Message, error) {
+	msg, ok := prototype.ProtoReflect().New().Interface().(Message)
+	if !ok {
+		return nil, errors.Errorf("type %s does not comply with message interface", reflect.TypeOf(prototype))
+	}
+	return msg, nil
+}
+
+// sortedFields returns the fields of a message ordered by field number. CBOR and SSZ encode the
+// fields in this order.
+func sortedFields(desc protoreflect.MessageDescriptor) []protoreflect.FieldDescriptor {
+	fields := make([]protoreflect.FieldDescriptor, desc.Fields().Len())
+	for i := range fields {
+		fields[i] = desc.Fields().Get(i)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Number() < fields[j].Number()
+	})
+	return fields
+}
+
+func unsupportedField(fd protoreflect.FieldDescriptor) error {
+	return errors.Errorf("field %s of kind %s is not supported", fd.FullName(), fd.Kind())
+}
@@ -0,0 +1,196 @@
+package p2pmsg
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprtopics"
+)
+
+// encodingTestMessages contains a message of every type with all fields set.
+func encodingTestMessages() []Message {
+	return []Message{
+		&DecryptionTrigger{
+			InstanceID:       1,
+			EpochID:          []byte{2},
+			BlockNumber:      300,
+			TransactionsHash: []byte{4, 4, 4, 4},
+			Signature:        []byte{5, 5},
+		},
+		&DecryptionKeyShares{
+			InstanceID:  1,
+			Eon:         2,
+			KeyperIndex: 3,
+			Shares: []*KeyShare{
+				{EpochID: []byte{1}, Share: []byte{2}},
+				{EpochID: []byte{3, 3}, Share: nil},
+				{EpochID: nil, Share: []byte{4, 4, 4}},
+			},
+		},
+		&DecryptionKey{
+			InstanceID: 1 << 40,
+			Eon:        2,
+			EpochID:    []byte{3},
+			Key:        make([]byte, 300),
+		},
+		&EonPublicKey{
+			InstanceID:        1,
+			PublicKey:         []byte{2, 2},
+			ActivationBlock:   3,
+			KeyperConfigIndex: 6,
+			Eon:               7,
+			Signature:         []byte{5},
+		},
+	}
+}
+
+func TestEncodingTestVectors(t *testing.T) {
+	key := &DecryptionKey{
+		InstanceID: 42,
+		Eon:        1,
+		EpochID:    []byte{0x01, 0x02},
+		Key:        []byte{0x0a, 0x0b, 0x0c},
+	}
+	shares := &DecryptionKeyShares{
+		InstanceID:  1,
+		Eon:         2,
+		KeyperIndex: 3,
+		Shares:      []*KeyShare{{EpochID: []byte{0x01}, Share: []byte{0x02}}},
+	}
+	testCases := []struct {
+		name string
+		msg  Message
+		enc  Encoding
+		hex  string
+	}{
+		{
+			name: "decryption key cbor",
+			msg:  key,
+			enc:  EncodingCBOR,
+			hex:  "a401182a02010342010204430a0b0c",
+		},
+		{
+			name: "decryption key ssz",
+			msg:  key,
+			enc:  EncodingSSZ,
+			hex: "2a00000000000000" + "0100000000000000" + "18000000" + "1a000000" +
+				"0102" + "0a0b0c",
+		},
+		{
+			name: "decryption key shares cbor",
+			msg:  shares,
+			enc:  EncodingCBOR,
+			hex:  "a40101040205030981a2014101024102",
+		},
+		{
+			name: "decryption key shares ssz",
+			msg:  shares,
+			enc:  EncodingSSZ,
+			hex: "0100000000000000" + "0200000000000000" + "0300000000000000" + "1c000000" +
+				"04000000" + "08000000" + "09000000" + "01" + "02",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := MarshalEncoding(tc.msg, nil, tc.enc)
+			assert.NilError(t, err)
+			assert.Equal(t, hex.EncodeToString(data), tc.hex)
+
+			decoded, _, err := UnmarshalTopic(EncodedTopic(tc.msg.Topic(), tc.enc), data)
+			assert.NilError(t, err)
+			assert.Assert(t, proto.Equal(decoded, tc.msg))
+		})
+	}
+}
+
+func TestCrossEncoding(t *testing.T) {
+	encodings := []Encoding{EncodingProtobuf, EncodingCBOR, EncodingSSZ}
+	for _, msg := range encodingTestMessages() {
+		for _, from := range encodings {
+			data, err := MarshalEncoding(msg, nil, from)
+			assert.NilError(t, err)
+			decoded, _, err := UnmarshalTopic(EncodedTopic(msg.Topic(), from), data)
+			assert.NilError(t, err)
+			assert.Assert(t, proto.Equal(decoded, msg), "%s in %s", msg.LogInfo(), from)
+
+			// re-encoding the decoded message in any encoding yields the same bytes as encoding
+			// the original message
+			for _, to := range encodings {
+				expected, err := MarshalEncoding(msg, nil, to)
+				assert.NilError(t, err)
+				reencoded, err := MarshalEncoding(decoded, nil, to)
+				assert.NilError(t, err)
+				assert.DeepEqual(t, expected, reencoded)
+			}
+		}
+	}
+}
+
+func TestUnmarshalInvalidEncoding(t *testing.T) {
+	testCases := []struct {
+		name  string
+		topic string
+		hex   string
+	}{
+		{name: "cbor truncated", topic: "decryptionKey/cbor", hex: "a401182a0201034201"},
+		{name: "cbor trailing data", topic: "decryptionKey/cbor", hex: "a401182a02010342010204430a0b0c00"},
+		{name: "cbor non-canonical integer", topic: "decryptionKey/cbor", hex: "a401180202010342010204430a0b0c"},
+		{name: "cbor duplicate field", topic: "decryptionKey/cbor", hex: "a201010101"},
+		{name: "cbor unknown field", topic: "decryptionKey/cbor", hex: "a10901"},
+		{name: "ssz truncated", topic: "decryptionKey/ssz", hex: "2a00000000000000"},
+		{name: "ssz invalid offset", topic: "decryptionKey/ssz", hex: "2a000000000000000100000000000000190000001a0000000102"},
+		{name: "unknown topic", topic: "unknown/ssz", hex: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.hex)
+			assert.NilError(t, err)
+			_, _, err = UnmarshalTopic(tc.topic, data)
+			assert.Assert(t, err != nil)
+		})
+	}
+}
+
+func TestSplitTopic(t *testing.T) {
+	for _, enc := range []Encoding{EncodingProtobuf, EncodingCBOR, EncodingSSZ} {
+		topic, e := SplitTopic(EncodedTopic(kprtopics.DecryptionKey, enc))
+		assert.Equal(t, topic, kprtopics.DecryptionKey)
+		assert.Equal(t, e, enc)
+	}
+	topic, enc := SplitTopic("decryptionKey/protobuf")
+	assert.Equal(t, topic, "decryptionKey/protobuf")
+	assert.Equal(t, enc, EncodingProtobuf)
+
+	_, err := ParseEncoding("json")
+	assert.ErrorContains(t, err, "unknown")
+}
+
+func TestNamespacedTopic(t *testing.T) {
+	topic := NamespacedTopic(42, EncodedTopic(kprtopics.DecryptionKey, EncodingSSZ))
+	assert.Equal(t, topic, "shutter/42/v1/decryptionKey/ssz")
+
+	instanceID, version, encodedTopic, ok := SplitNamespace(topic)
+	assert.Check(t, ok)
+	assert.Equal(t, instanceID, uint64(42))
+	assert.Equal(t, version, uint64(ProtocolVersion))
+	assert.Equal(t, encodedTopic, "decryptionKey/ssz")
+
+	for _, topic := range []string{"decryptionKey", "shutter/x/v1/decryptionKey", "shutter/1/1/decryptionKey"} {
+		_, _, unchanged, ok := SplitNamespace(topic)
+		assert.Check(t, !ok)
+		assert.Equal(t, unchanged, topic)
+	}
+
+	msg := &DecryptionKey{InstanceID: 42, Eon: 1, EpochID: []byte{2}, Key: []byte{3}}
+	for _, enc := range []Encoding{EncodingProtobuf, EncodingCBOR, EncodingSSZ} {
+		data, err := MarshalEncoding(msg, nil, enc)
+		assert.NilError(t, err)
+		decoded, _, err := UnmarshalTopic(NamespacedTopic(42, EncodedTopic(msg.Topic(), enc)), data)
+		assert.NilError(t, err)
+		assert.Assert(t, proto.Equal(decoded, msg))
+	}
+}
@@ -36,6 +36,14 @@ func (e *EonPublicKey) SetSignature(s []byte) {
 	e.Signature = s
 }
 
+func (*EonPublicKey) SigningKind() string {
+	return "eonPublicKey"
+}
+
+func (e *EonPublicKey) SetSigningNonce(nonce uint64) {
+	e.SigningNonce = nonce
+}
+
 func (e *EonPublicKey) Hash() []byte {
 	hash := sha3.New256()
 	hash.Write(eonPubKeyHashPrefix)
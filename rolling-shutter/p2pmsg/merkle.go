@@ -0,0 +1,120 @@
+package p2pmsg
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// The Merkle tree committing to the transactions of a batch hashes leaves and inner nodes with
+// different prefixes, so that an inner node can't be passed off as a leaf. A node without a
+// sibling is moved up a level unchanged. The root hashes the top node together with the number
+// of leaves, which the shape of the tree depends on.
+var (
+	merkleLeafPrefix = []byte{0x00}
+	merkleNodePrefix = []byte{0x01}
+	merkleRootPrefix = []byte{0x02}
+)
+
+// MerkleRootSize is the size of the root of the Merkle tree over the transactions of a batch,
+// which the collator commits to in the transactions hash of its decryption trigger.
+const MerkleRootSize = 32
+
+func merkleLeaf(data []byte) []byte {
+	hash := sha3.New256()
+	hash.Write(merkleLeafPrefix)
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
+func merkleRoot(top []byte, numLeaves int) []byte {
+	hash := sha3.New256()
+	hash.Write(merkleRootPrefix)
+	_ = binary.Write(hash, binary.BigEndian, uint64(numLeaves))
+	hash.Write(top)
+	return hash.Sum(nil)
+}
+
+func merkleNode(left, right []byte) []byte {
+	hash := sha3.New256()
+	hash.Write(merkleNodePrefix)
+	hash.Write(left)
+	hash.Write(right)
+	return hash.Sum(nil)
+}
+
+// merkleLevels returns the levels of the Merkle tree over the leaves, from the hashed leaves up to
+// the root.
+func merkleLevels(leaves [][]byte) [][][]byte {
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = merkleLeaf(leaf)
+	}
+	levels := [][][]byte{level}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+			} else {
+				next = append(next, merkleNode(level[i], level[i+1]))
+			}
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+// MerkleRoot returns the root of the Merkle tree over the leaves in the given order.
+func MerkleRoot(leaves [][]byte) []byte {
+	if len(leaves) == 0 {
+		return merkleRoot(nil, 0)
+	}
+	levels := merkleLevels(leaves)
+	return merkleRoot(levels[len(levels)-1][0], len(leaves))
+}
+
+// MerkleProof returns the proof that the leaf at the given index is part of the Merkle tree over
+// the leaves, i.e. the siblings on the path from the leaf to the root.
+func MerkleProof(leaves [][]byte, index int) [][]byte {
+	if index < 0 || index >= len(leaves) {
+		return nil
+	}
+	proof := [][]byte{}
+	levels := merkleLevels(leaves)
+	for _, level := range levels[:len(levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof = append(proof, level[sibling])
+		}
+		index /= 2
+	}
+	return proof
+}
+
+// VerifyMerkleProof checks that leaf is the leaf at the given index of the Merkle tree with the
+// given number of leaves and root.
+func VerifyMerkleProof(root []byte, leaf []byte, index int, numLeaves int, proof [][]byte) bool {
+	if index < 0 || index >= numLeaves {
+		return false
+	}
+	hash := merkleLeaf(leaf)
+	for size := numLeaves; size > 1; size = (size + 1) / 2 {
+		sibling := index ^ 1
+		if sibling < size {
+			if len(proof) == 0 {
+				return false
+			}
+			if index%2 == 0 {
+				hash = merkleNode(hash, proof[0])
+			} else {
+				hash = merkleNode(proof[0], hash)
+			}
+			proof = proof[1:]
+		}
+		index /= 2
+	}
+	return len(proof) == 0 && bytes.Equal(merkleRoot(hash, numLeaves), root)
+}
@@ -0,0 +1,112 @@
+package p2pmsg
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+func merkleTestLeaves(n int) [][]byte {
+	leaves := [][]byte{}
+	for i := 0; i < n; i++ {
+		leaves = append(leaves, []byte{byte(i), byte(i)})
+	}
+	return leaves
+}
+
+func TestMerkleProof(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		leaves := merkleTestLeaves(n)
+		root := MerkleRoot(leaves)
+		assert.Equal(t, len(root), MerkleRootSize)
+		for i, leaf := range leaves {
+			proof := MerkleProof(leaves, i)
+			assert.Check(t, VerifyMerkleProof(root, leaf, i, n, proof), "n=%d i=%d", n, i)
+
+			// the proof is bound to the leaf, its position and the size of the tree
+			assert.Check(t, !VerifyMerkleProof(root, []byte{0xff}, i, n, proof))
+			assert.Check(t, !VerifyMerkleProof(root, leaf, i, n+1, proof))
+			if n > 1 {
+				assert.Check(t, !VerifyMerkleProof(root, leaf, (i+1)%n, n, proof))
+				assert.Check(t, !VerifyMerkleProof(root, leaf, i, n, proof[1:]))
+				tampered := append([][]byte{}, proof...)
+				tampered[0] = MerkleRoot(nil)
+				assert.Check(t, !VerifyMerkleProof(root, leaf, i, n, tampered))
+			}
+		}
+		assert.Check(t, MerkleProof(leaves, n) == nil)
+	}
+}
+
+func TestMerkleRoot(t *testing.T) {
+	assert.Equal(t, len(MerkleRoot(nil)), MerkleRootSize)
+	leaves := merkleTestLeaves(3)
+	inner := merkleNode(merkleLeaf(leaves[0]), merkleLeaf(leaves[1]))
+	assert.DeepEqual(t, MerkleRoot(leaves), merkleRoot(merkleNode(inner, merkleLeaf(leaves[2])), 3))
+
+	// an inner node can't be passed off as a leaf
+	children := append(merkleLeaf(leaves[0]), merkleLeaf(leaves[1])...)
+	assert.Check(t, string(MerkleRoot(leaves)) != string(MerkleRoot([][]byte{children, leaves[2]})))
+	// the order of the leaves is committed to
+	assert.Check(t, string(MerkleRoot(leaves)) != string(MerkleRoot([][]byte{leaves[1], leaves[0], leaves[2]})))
+}
+
+func TestVerifyInclusion(t *testing.T) {
+	txHashes := merkleTestLeaves(5)
+	trigger := &DecryptionTrigger{EpochID: epochid.Uint64ToEpochID(1).Bytes(), TransactionsHash: MerkleRoot(txHashes)}
+
+	assert.NilError(t, trigger.VerifyInclusion(txHashes[3], 3, 5, MerkleProof(txHashes, 3)))
+	assert.ErrorContains(t, trigger.VerifyInclusion(txHashes[3], 2, 5, MerkleProof(txHashes, 3)), "not at position 2")
+}
+
+func TestVerifyBatch(t *testing.T) {
+	txHashes := merkleTestLeaves(4)
+	trigger := &DecryptionTrigger{EpochID: epochid.Uint64ToEpochID(1).Bytes(), TransactionsHash: MerkleRoot(txHashes)}
+	tampered := append([][]byte{}, txHashes...)
+	tampered[2] = []byte{0xff}
+
+	testCases := []struct {
+		name     string
+		txHashes [][]byte
+		ok       bool
+	}{
+		{name: "committed batch", txHashes: txHashes, ok: true},
+		{name: "reordered", txHashes: [][]byte{txHashes[1], txHashes[0], txHashes[2], txHashes[3]}},
+		{name: "missing transaction", txHashes: txHashes[:3]},
+		{name: "additional transaction", txHashes: append(append([][]byte{}, txHashes...), []byte{0xff})},
+		{name: "replaced transaction", txHashes: tampered},
+		{name: "empty", txHashes: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := trigger.VerifyBatch(tc.txHashes)
+			if tc.ok {
+				assert.NilError(t, err)
+			} else {
+				assert.ErrorContains(t, err, "batch commitment mismatch")
+			}
+		})
+	}
+
+	empty := &DecryptionTrigger{EpochID: epochid.Uint64ToEpochID(1).Bytes(), TransactionsHash: MerkleRoot(nil)}
+	assert.NilError(t, empty.VerifyBatch(nil))
+	assert.ErrorContains(t, empty.VerifyBatch(txHashes[:1]), "batch commitment mismatch")
+}
+
+func TestVerifyBatchOfTriggerBatch(t *testing.T) {
+	batches := [][][]byte{merkleTestLeaves(1), merkleTestLeaves(3)}
+	batch := &DecryptionTriggerBatch{
+		FirstEpochID:       epochid.Uint64ToEpochID(7).Bytes(),
+		BlockNumbers:       []uint64{100, 101},
+		TransactionsHashes: [][]byte{MerkleRoot(batches[0]), MerkleRoot(batches[1])},
+	}
+	triggers, err := batch.Triggers()
+	assert.NilError(t, err)
+	assert.Equal(t, len(triggers), 2)
+	for i, trigger := range triggers {
+		assert.NilError(t, trigger.VerifyBatch(batches[i]))
+		assert.ErrorContains(t, trigger.VerifyBatch(batches[1-i]), "batch commitment mismatch")
+	}
+}
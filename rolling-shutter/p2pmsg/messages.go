@@ -3,6 +3,7 @@ package p2pmsg
 import (
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -27,6 +28,13 @@ type Message interface {
 	String() string
 }
 
+// ShardedMessage is implemented by messages that only concern a single epoch or eon. Those
+// messages may be handled concurrently with messages with a different shard key, while messages
+// with the same shard key are handled in order.
+type ShardedMessage interface {
+	ShardKey() []byte
+}
+
 func Marshal(msg Message, traceContext *TraceContext) ([]byte, error) {
 	var msgBytes []byte
 	wrappedMsg, err := anypb.New(msg)
@@ -84,7 +92,115 @@ func (*DecryptionTrigger) Topic() string {
 	return kprtopics.DecryptionTrigger
 }
 
-func (*DecryptionTrigger) Validate() error {
+func (trigger *DecryptionTrigger) Validate() error {
+	if len(trigger.TransactionsHash) != MerkleRootSize {
+		return errors.Errorf("transactions hash must be %d bytes, got %d", MerkleRootSize, len(trigger.TransactionsHash))
+	}
+	return nil
+}
+
+func (trigger *DecryptionTrigger) ShardKey() []byte {
+	return trigger.EpochID
+}
+
+func (batch *DecryptionTriggerBatch) LogInfo() string {
+	firstEpochID, _ := epochid.BytesToEpochID(batch.FirstEpochID)
+	return fmt.Sprintf("DecryptionTriggerBatch{firstEpochid=%x, epochs=%d}", firstEpochID.String(), len(batch.BlockNumbers))
+}
+
+func (*DecryptionTriggerBatch) Topic() string {
+	return kprtopics.DecryptionTriggerBatch
+}
+
+// ShardKey returns the first epoch of the batch, so that a batch is handled in order with the
+// single triggers for that epoch.
+func (batch *DecryptionTriggerBatch) ShardKey() []byte {
+	return batch.FirstEpochID
+}
+
+func (batch *DecryptionTriggerBatch) Validate() error {
+	if len(batch.BlockNumbers) == 0 {
+		return errors.New("empty decryption trigger batch")
+	}
+	if len(batch.BlockNumbers) > MaxDecryptionTriggerBatchSize {
+		return errors.Errorf("decryption trigger batch of %d epochs exceeds the maximum of %d",
+			len(batch.BlockNumbers), MaxDecryptionTriggerBatchSize)
+	}
+	if len(batch.TransactionsHashes) != len(batch.BlockNumbers) {
+		return errors.Errorf("decryption trigger batch has %d block numbers, but %d transactions hashes",
+			len(batch.BlockNumbers), len(batch.TransactionsHashes))
+	}
+	for _, txHash := range batch.TransactionsHashes {
+		if len(txHash) != MerkleRootSize {
+			return errors.Errorf("transactions hash must be %d bytes, got %d", MerkleRootSize, len(txHash))
+		}
+	}
+	return nil
+}
+
+func (announcement *EpochPreAnnouncement) LogInfo() string {
+	epochID, _ := epochid.BytesToEpochID(announcement.EpochID)
+	return fmt.Sprintf("EpochPreAnnouncement{epochid=%x, blockNumber=%d}", epochID.String(), announcement.BlockNumber)
+}
+
+func (*EpochPreAnnouncement) Topic() string {
+	return kprtopics.EpochPreAnnouncement
+}
+
+func (*EpochPreAnnouncement) Validate() error {
+	return nil
+}
+
+func (announcement *EpochPreAnnouncement) ShardKey() []byte {
+	return announcement.EpochID
+}
+
+func (attestation *NodeAttestation) LogInfo() string {
+	return fmt.Sprintf(
+		"NodeAttestation{address=%s, role=%s, version=%s}",
+		attestation.AttesterAddress().Hex(), attestation.Role, attestation.Version,
+	)
+}
+
+func (*NodeAttestation) Topic() string {
+	return kprtopics.NodeAttestation
+}
+
+func (attestation *NodeAttestation) Validate() error {
+	if len(attestation.Address) != common.AddressLength {
+		return errors.Errorf("address must be %d bytes, got %d", common.AddressLength, len(attestation.Address))
+	}
+	if attestation.Role == "" {
+		return errors.New("role is empty")
+	}
+	if len(attestation.Role) > maxAttestationFieldLength || len(attestation.Version) > maxAttestationFieldLength {
+		return errors.Errorf("role and version must not exceed %d bytes", maxAttestationFieldLength)
+	}
+	return nil
+}
+
+func (attestation *NodeAttestation) ShardKey() []byte {
+	return attestation.Address
+}
+
+func (vote *PauseVote) LogInfo() string {
+	return fmt.Sprintf(
+		"PauseVote{keyperConfigIndex=%d, sequence=%d, paused=%t}",
+		vote.KeyperConfigIndex, vote.Sequence, vote.Paused,
+	)
+}
+
+func (*PauseVote) Topic() string {
+	return kprtopics.PauseVote
+}
+
+func (vote *PauseVote) Validate() error {
+	if vote.Sequence == 0 {
+		return errors.New("sequence must be positive")
+	}
+	if len(vote.Reason) > MaxPauseReasonLength {
+		return errors.Errorf("reason must not exceed %d bytes", MaxPauseReasonLength)
+	}
 	return nil
 }
 
@@ -99,6 +215,13 @@ func (*DecryptionKeyShares) Topic() string {
 	return kprtopics.DecryptionKeyShares
 }
 
+func (share *DecryptionKeyShares) ShardKey() []byte {
+	if len(share.GetShares()) == 0 {
+		return nil
+	}
+	return share.GetShares()[0].GetEpochID()
+}
+
 func (share *KeyShare) GetEpochSecretKeyShare() (*shcrypto.EpochSecretKeyShare, error) {
 	epochSecretKeyShare := new(shcrypto.EpochSecretKeyShare)
 	if err := epochSecretKeyShare.Unmarshal(share.GetShare()); err != nil {
@@ -125,6 +248,10 @@ func (*DecryptionKey) Topic() string {
 	return kprtopics.DecryptionKey
 }
 
+func (key *DecryptionKey) ShardKey() []byte {
+	return key.EpochID
+}
+
 func (key *DecryptionKey) GetEpochSecretKey() (*shcrypto.EpochSecretKey, error) {
 	epochSecretKey := new(shcrypto.EpochSecretKey)
 	if err := epochSecretKey.Unmarshal(key.GetKey()); err != nil {
@@ -79,7 +79,7 @@ func TestDecryptionTrigger(t *testing.T) {
 	privKey, err := ethcrypto.GenerateKey()
 	assert.NilError(t, err)
 
-	orig, err := NewSignedDecryptionTrigger(cfg.instanceID, cfg.epochID, cfg.blockNumber, HashByteList(txs), privKey)
+	orig, err := NewSignedDecryptionTrigger(cfg.instanceID, cfg.epochID, cfg.blockNumber, MerkleRoot(txs), privKey)
 	assert.NilError(t, err)
 	m, tc := marshalUnmarshalMessage(t, orig, nil)
 	assert.Assert(t, tc == nil)
@@ -104,6 +104,58 @@ func TestDecryptionKeyShare(t *testing.T) {
 	assert.DeepEqual(t, orig, m, cmpopts.IgnoreUnexported(DecryptionKeyShares{}, KeyShare{}))
 }
 
+func TestShardKey(t *testing.T) {
+	cfg := defaultTestConfig(t)
+	otherEpochID, _ := epochid.BigToEpochID(common.Big3)
+	txHash := MerkleRoot([][]byte{[]byte("tx")})
+
+	testCases := []struct {
+		name string
+		msg  Message
+		key  []byte
+	}{
+		{
+			name: "trigger",
+			msg:  &DecryptionTrigger{EpochID: cfg.epochID.Bytes(), TransactionsHash: txHash},
+			key:  cfg.epochID.Bytes(),
+		},
+		{
+			name: "trigger batch",
+			msg: &DecryptionTriggerBatch{
+				FirstEpochID:       cfg.epochID.Bytes(),
+				BlockNumbers:       []uint64{1, 2},
+				TransactionsHashes: [][]byte{txHash, txHash},
+			},
+			key: cfg.epochID.Bytes(),
+		},
+		{
+			name: "key shares",
+			msg: &DecryptionKeyShares{Shares: []*KeyShare{
+				{EpochID: cfg.epochID.Bytes()},
+				{EpochID: otherEpochID.Bytes()},
+			}},
+			key: cfg.epochID.Bytes(),
+		},
+		{
+			name: "empty key shares",
+			msg:  &DecryptionKeyShares{},
+			key:  nil,
+		},
+		{
+			name: "key",
+			msg:  &DecryptionKey{EpochID: otherEpochID.Bytes()},
+			key:  otherEpochID.Bytes(),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sharded, ok := tc.msg.(ShardedMessage)
+			assert.Assert(t, ok)
+			assert.DeepEqual(t, tc.key, sharded.ShardKey())
+		})
+	}
+}
+
 func TestEonPublicKey(t *testing.T) {
 	cfg := defaultTestConfig(t)
 	eonPublicKey := cfg.tkg.EonPublicKey(cfg.epochID).Marshal()
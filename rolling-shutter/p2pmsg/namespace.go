@@ -0,0 +1,44 @@
+package p2pmsg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ProtocolVersion is the version of the p2p protocol. It is part of the namespaced topic names, so
+// it has to be increased whenever messages change incompatibly.
+const ProtocolVersion = 1
+
+const topicNamespacePrefix = "shutter"
+
+// NamespacedTopic returns the topic name scoped to the given instance and the current protocol
+// version, e.g. "shutter/42/v1/decryptionKey". Deployments with different instance IDs can share
+// the same p2p infrastructure without receiving each other's messages.
+func NamespacedTopic(instanceID uint64, topic string) string {
+	return VersionedTopic(instanceID, ProtocolVersion, topic)
+}
+
+// VersionedTopic is like NamespacedTopic, but for the given protocol version. It's used to bridge
+// messages to the topics of an adjacent version during an upgrade.
+func VersionedTopic(instanceID uint64, version uint64, topic string) string {
+	return fmt.Sprintf("%s/%d/v%d/%s", topicNamespacePrefix, instanceID, version, topic)
+}
+
+// SplitNamespace splits a namespaced topic into the instance ID, the protocol version and the
+// topic name. If the topic is not namespaced, ok is false and the topic is returned unchanged.
+func SplitNamespace(namespacedTopic string) (instanceID uint64, version uint64, topic string, ok bool) {
+	parts := strings.SplitN(namespacedTopic, "/", 4)
+	if len(parts) != 4 || parts[0] != topicNamespacePrefix || !strings.HasPrefix(parts[2], "v") {
+		return 0, 0, namespacedTopic, false
+	}
+	instanceID, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, namespacedTopic, false
+	}
+	version, err = strconv.ParseUint(parts[2][1:], 10, 64)
+	if err != nil {
+		return 0, 0, namespacedTopic, false
+	}
+	return instanceID, version, parts[3], true
+}
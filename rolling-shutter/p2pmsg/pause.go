@@ -0,0 +1,61 @@
+package p2pmsg
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+
+	"golang.org/x/crypto/sha3"
+)
+
+var pauseVoteHashPrefix = []byte{0x19, 'p', 'a', 'u', 's', 'e'}
+
+// MaxPauseReasonLength bounds the length of the reason of a pause vote.
+const MaxPauseReasonLength = 256
+
+// NewSignedPauseVote creates a vote to pause or resume key generation and signs it in the given
+// domain with the given signing nonce.
+func NewSignedPauseVote(
+	domain SigningDomain,
+	keyperConfigIndex uint64,
+	sequence uint64,
+	paused bool,
+	reason string,
+	nonce uint64,
+	privKey *ecdsa.PrivateKey,
+) (*PauseVote, error) {
+	vote := &PauseVote{
+		InstanceID:        domain.InstanceID,
+		KeyperConfigIndex: keyperConfigIndex,
+		Sequence:          sequence,
+		Paused:            paused,
+		Reason:            reason,
+		SigningNonce:      nonce,
+	}
+	if err := SignInDomain(vote, domain, privKey); err != nil {
+		return nil, err
+	}
+	return vote, nil
+}
+
+func (vote *PauseVote) SetSignature(s []byte) {
+	vote.Signature = s
+}
+
+func (*PauseVote) SigningKind() string {
+	return "pauseVote"
+}
+
+func (vote *PauseVote) SetSigningNonce(nonce uint64) {
+	vote.SigningNonce = nonce
+}
+
+func (vote *PauseVote) Hash() []byte {
+	hash := sha3.New256()
+	hash.Write(pauseVoteHashPrefix)
+	_ = binary.Write(hash, binary.BigEndian, vote.InstanceID)
+	_ = binary.Write(hash, binary.BigEndian, vote.KeyperConfigIndex)
+	_ = binary.Write(hash, binary.BigEndian, vote.Sequence)
+	_ = binary.Write(hash, binary.BigEndian, vote.Paused)
+	writeString(hash, vote.Reason)
+	return hash.Sum(nil)
+}
@@ -0,0 +1,53 @@
+package p2pmsg
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+var preAnnouncementHashPrefix = []byte{0x19, 'p', 'r', 'e', 'A', 'n', 'n', 'o', 'u', 'n', 'c', 'e'}
+
+// NewSignedEpochPreAnnouncement announces that the epoch will be triggered in the given block at
+// the expected time.
+func NewSignedEpochPreAnnouncement(
+	instanceID uint64,
+	epochID epochid.EpochID,
+	blockNumber uint64,
+	expectedTriggerTime time.Time,
+	privKey *ecdsa.PrivateKey,
+) (*EpochPreAnnouncement, error) {
+	announcement := &EpochPreAnnouncement{
+		InstanceID:          instanceID,
+		EpochID:             epochID.Bytes(),
+		BlockNumber:         blockNumber,
+		ExpectedTriggerTime: uint64(expectedTriggerTime.UnixMilli()),
+	}
+	if err := Sign(announcement, privKey); err != nil {
+		return nil, err
+	}
+	return announcement, nil
+}
+
+// ExpectedTriggerTimestamp returns the time the collator expects to send the trigger at.
+func (announcement *EpochPreAnnouncement) ExpectedTriggerTimestamp() time.Time {
+	return time.UnixMilli(int64(announcement.ExpectedTriggerTime))
+}
+
+func (announcement *EpochPreAnnouncement) SetSignature(s []byte) {
+	announcement.Signature = s
+}
+
+func (announcement *EpochPreAnnouncement) Hash() []byte {
+	hash := sha3.New256()
+	hash.Write(preAnnouncementHashPrefix)
+	_ = binary.Write(hash, binary.BigEndian, announcement.InstanceID)
+	_ = binary.Write(hash, binary.BigEndian, announcement.EpochID)
+	_ = binary.Write(hash, binary.BigEndian, announcement.BlockNumber)
+	_ = binary.Write(hash, binary.BigEndian, announcement.ExpectedTriggerTime)
+	return hash.Sum(nil)
+}
@@ -0,0 +1,119 @@
+package p2pmsg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+// BenchmarkVerifySignature benchmarks the signature check done when validating a decryption
+// trigger.
+func BenchmarkVerifySignature(b *testing.B) {
+	privKey, err := ethcrypto.GenerateKey()
+	assert.NilError(b, err)
+	address := ethcrypto.PubkeyToAddress(privKey.PublicKey)
+	trigger, err := NewSignedDecryptionTrigger(1, epochid.Uint64ToEpochID(1), 2, MerkleRoot(nil), privKey)
+	assert.NilError(b, err)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		ok, err := VerifySignature(trigger, address)
+		assert.NilError(b, err)
+		assert.Assert(b, ok)
+	}
+}
+
+func TestDecryptionTriggerBatch(t *testing.T) {
+	privKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	address := ethcrypto.PubkeyToAddress(privKey.PublicKey)
+
+	triggers := []*DecryptionTrigger{}
+	for i := uint64(0); i < 3; i++ {
+		trigger, err := NewSignedDecryptionTrigger(1, epochid.Uint64ToEpochID(5+i), 10+i, MerkleRoot([][]byte{{byte(i)}}), privKey)
+		assert.NilError(t, err)
+		triggers = append(triggers, trigger)
+	}
+	batch, err := NewSignedDecryptionTriggerBatch(1, triggers, privKey)
+	assert.NilError(t, err)
+	ok, err := VerifySignature(batch, address)
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+
+	expanded, err := batch.Triggers()
+	assert.NilError(t, err)
+	assert.Equal(t, len(expanded), len(triggers))
+	for i, trigger := range expanded {
+		assert.DeepEqual(t, trigger.EpochID, triggers[i].EpochID)
+		assert.Equal(t, trigger.BlockNumber, triggers[i].BlockNumber)
+		assert.DeepEqual(t, trigger.TransactionsHash, triggers[i].TransactionsHash)
+	}
+
+	_, err = NewSignedDecryptionTriggerBatch(1, []*DecryptionTrigger{triggers[0], triggers[2]}, privKey)
+	assert.ErrorContains(t, err, "not contiguous")
+}
+
+func TestEpochPreAnnouncement(t *testing.T) {
+	privKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	address := ethcrypto.PubkeyToAddress(privKey.PublicKey)
+
+	expected := time.UnixMilli(1_700_000_000_123)
+	announcement, err := NewSignedEpochPreAnnouncement(1, epochid.Uint64ToEpochID(5), 10, expected, privKey)
+	assert.NilError(t, err)
+	assert.Assert(t, announcement.ExpectedTriggerTimestamp().Equal(expected))
+	ok, err := VerifySignature(announcement, address)
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+
+	announcement.BlockNumber++
+	ok, err = VerifySignature(announcement, address)
+	assert.NilError(t, err)
+	assert.Assert(t, !ok)
+}
+
+func TestNodeAttestation(t *testing.T) {
+	privKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+
+	timestamp := time.Unix(1_700_000_000, 0)
+	attestation, err := NewSignedNodeAttestation(1, "keyper", "v1.2.3", []uint64{1, 2}, timestamp, privKey)
+	assert.NilError(t, err)
+	assert.Equal(t, attestation.AttesterAddress(), ethcrypto.PubkeyToAddress(privKey.PublicKey))
+	assert.Assert(t, attestation.AttestationTime().Equal(timestamp))
+	assert.Assert(t, attestation.SupportsProtocolVersion(2))
+	assert.Assert(t, !attestation.SupportsProtocolVersion(3))
+	assert.NilError(t, attestation.Verify())
+
+	// the role and version are length prefixed, so moving bytes between them changes the hash
+	attestation.Role = "keyperv"
+	attestation.Version = "1.2.3"
+	assert.Assert(t, attestation.Verify() != nil)
+}
+
+func TestPauseVote(t *testing.T) {
+	privKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	signer := ethcrypto.PubkeyToAddress(privKey.PublicKey)
+	isSigner := func(address common.Address) bool { return address == signer }
+	domain := SigningDomain{ChainID: 1, InstanceID: 2}
+
+	vote, err := NewSignedPauseVote(domain, 3, 4, true, "vulnerability", 5, privKey)
+	assert.NilError(t, err)
+	assert.NilError(t, vote.Validate())
+	recovered, err := RecoverSigner(vote, domain, false, isSigner)
+	assert.NilError(t, err)
+	assert.Equal(t, recovered, signer)
+
+	// a vote to pause can't be turned into a vote to resume
+	vote.Paused = false
+	_, err = RecoverSigner(vote, domain, false, isSigner)
+	assert.ErrorContains(t, err, "not allowed")
+
+	vote.Sequence = 0
+	assert.ErrorContains(t, vote.Validate(), "sequence")
+}
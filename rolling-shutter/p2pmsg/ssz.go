@@ -0,0 +1,306 @@
+package p2pmsg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// sszCodec encodes messages with SimpleSerialize (SSZ) as used by the Ethereum consensus layer.
+// A message is encoded as a container of its fields in ascending order of the field numbers.
+// uint64 fields map to uint64, bool fields to boolean, bytes and string fields to List[byte],
+// repeated fields to lists and nested messages to containers.
+type sszCodec struct{}
+
+const sszOffsetSize = 4
+
+func (sszCodec) Encoding() Encoding {
+	return EncodingSSZ
+}
+
+func (sszCodec) Marshal(msg Message, _ *TraceContext) ([]byte, error) {
+	return sszAppendMessage(nil, msg.ProtoReflect())
+}
+
+func (sszCodec) Unmarshal(data []byte, prototype Message) (Message, *TraceContext, error) {
+	msg, err := newMessage(prototype)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := sszReadMessage(data, msg.ProtoReflect()); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to unmarshal SSZ message")
+	}
+	return msg, nil, nil
+}
+
+// sszFixedSize returns the size of a field if it has a fixed size.
+func sszFixedSize(fd protoreflect.FieldDescriptor) (int, bool) {
+	if fd.IsList() || fd.IsMap() {
+		return 0, false
+	}
+	return sszFixedElementSize(fd)
+}
+
+// sszFixedElementSize returns the size of a single value of a field if it has a fixed size.
+func sszFixedElementSize(fd protoreflect.FieldDescriptor) (int, bool) {
+	switch fd.Kind() {
+	case protoreflect.Uint64Kind:
+		return 8, true
+	case protoreflect.BoolKind:
+		return 1, true
+	case protoreflect.MessageKind:
+		size := 0
+		for _, f := range sortedFields(fd.Message()) {
+			s, ok := sszFixedSize(f)
+			if !ok {
+				return 0, false
+			}
+			size += s
+		}
+		return size, true
+	default:
+		return 0, false
+	}
+}
+
+func sszAppendOffset(b []byte, offset int) ([]byte, error) {
+	if uint64(offset) > math.MaxUint32 {
+		return nil, errors.New("SSZ message too large")
+	}
+	return binary.LittleEndian.AppendUint32(b, uint32(offset)), nil
+}
+
+func sszAppendMessage(b []byte, m protoreflect.Message) ([]byte, error) {
+	fields := sortedFields(m.Descriptor())
+
+	fixedLength := 0
+	for _, fd := range fields {
+		if size, ok := sszFixedSize(fd); ok {
+			fixedLength += size
+		} else {
+			fixedLength += sszOffsetSize
+		}
+	}
+
+	var variable []byte
+	var err error
+	for _, fd := range fields {
+		v := m.Get(fd)
+		if _, ok := sszFixedSize(fd); ok {
+			b, err = sszAppendValue(b, fd, v)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		b, err = sszAppendOffset(b, fixedLength+len(variable))
+		if err != nil {
+			return nil, err
+		}
+		if fd.IsList() {
+			variable, err = sszAppendList(variable, fd, v.List())
+		} else {
+			variable, err = sszAppendValue(variable, fd, v)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(b, variable...), nil
+}
+
+func sszAppendList(b []byte, fd protoreflect.FieldDescriptor, l protoreflect.List) ([]byte, error) {
+	var err error
+	if _, ok := sszFixedElementSize(fd); ok {
+		for i := 0; i < l.Len(); i++ {
+			b, err = sszAppendValue(b, fd, l.Get(i))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+
+	var elements []byte
+	for i := 0; i < l.Len(); i++ {
+		b, err = sszAppendOffset(b, l.Len()*sszOffsetSize+len(elements))
+		if err != nil {
+			return nil, err
+		}
+		elements, err = sszAppendValue(elements, fd, l.Get(i))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(b, elements...), nil
+}
+
+func sszAppendValue(b []byte, fd protoreflect.FieldDescriptor, v protoreflect.Value) ([]byte, error) {
+	switch {
+	case fd.IsMap():
+		return nil, unsupportedField(fd)
+	case fd.Kind() == protoreflect.Uint64Kind:
+		return binary.LittleEndian.AppendUint64(b, v.Uint()), nil
+	case fd.Kind() == protoreflect.BoolKind:
+		if v.Bool() {
+			return append(b, 1), nil
+		}
+		return append(b, 0), nil
+	case fd.Kind() == protoreflect.BytesKind:
+		return append(b, v.Bytes()...), nil
+	case fd.Kind() == protoreflect.StringKind:
+		return append(b, v.String()...), nil
+	case fd.Kind() == protoreflect.MessageKind:
+		return sszAppendMessage(b, v.Message())
+	default:
+		return nil, unsupportedField(fd)
+	}
+}
+
+func sszReadMessage(b []byte, m protoreflect.Message) error {
+	type variableField struct {
+		fd     protoreflect.FieldDescriptor
+		offset int
+	}
+
+	var variableFields []variableField
+	pos := 0
+	for _, fd := range sortedFields(m.Descriptor()) {
+		if fd.IsMap() {
+			return unsupportedField(fd)
+		}
+		if size, ok := sszFixedSize(fd); ok {
+			if pos+size > len(b) {
+				return errors.Errorf("SSZ data too short for field %s", fd.FullName())
+			}
+			v, err := sszReadValue(b[pos:pos+size], fd, m.NewField(fd))
+			if err != nil {
+				return err
+			}
+			m.Set(fd, v)
+			pos += size
+			continue
+		}
+		if pos+sszOffsetSize > len(b) {
+			return errors.Errorf("SSZ data too short for offset of field %s", fd.FullName())
+		}
+		offset := int(binary.LittleEndian.Uint32(b[pos:]))
+		variableFields = append(variableFields, variableField{fd: fd, offset: offset})
+		pos += sszOffsetSize
+	}
+
+	if len(variableFields) == 0 {
+		if pos != len(b) {
+			return errors.Errorf("%d bytes of trailing data after SSZ container", len(b)-pos)
+		}
+		return nil
+	}
+	if variableFields[0].offset != pos {
+		return errors.Errorf("invalid first SSZ offset %d, expected %d", variableFields[0].offset, pos)
+	}
+	for i, f := range variableFields {
+		end := len(b)
+		if i+1 < len(variableFields) {
+			end = variableFields[i+1].offset
+		}
+		if f.offset > end || end > len(b) {
+			return errors.Errorf("invalid SSZ offset for field %s", f.fd.FullName())
+		}
+		if err := sszReadVariable(b[f.offset:end], f.fd, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sszReadVariable reads the variable-size part of a field and sets it on the message.
+func sszReadVariable(b []byte, fd protoreflect.FieldDescriptor, m protoreflect.Message) error {
+	if !fd.IsList() {
+		v, err := sszReadValue(b, fd, m.NewField(fd))
+		if err != nil {
+			return err
+		}
+		m.Set(fd, v)
+		return nil
+	}
+
+	l := m.Mutable(fd).List()
+	if size, ok := sszFixedElementSize(fd); ok {
+		if size == 0 || len(b)%size != 0 {
+			return errors.Errorf("invalid SSZ list length for field %s", fd.FullName())
+		}
+		for i := 0; i < len(b); i += size {
+			v, err := sszReadValue(b[i:i+size], fd, l.NewElement())
+			if err != nil {
+				return err
+			}
+			l.Append(v)
+		}
+		return nil
+	}
+
+	if len(b) == 0 {
+		return nil
+	}
+	if len(b) < sszOffsetSize {
+		return errors.Errorf("SSZ data too short for list %s", fd.FullName())
+	}
+	first := int(binary.LittleEndian.Uint32(b))
+	if first == 0 || first%sszOffsetSize != 0 || first > len(b) {
+		return errors.Errorf("invalid first SSZ offset in list %s", fd.FullName())
+	}
+	offsets := make([]int, first/sszOffsetSize)
+	for i := range offsets {
+		offsets[i] = int(binary.LittleEndian.Uint32(b[i*sszOffsetSize:]))
+	}
+	for i, offset := range offsets {
+		end := len(b)
+		if i+1 < len(offsets) {
+			end = offsets[i+1]
+		}
+		if offset > end || end > len(b) {
+			return errors.Errorf("invalid SSZ offset in list %s", fd.FullName())
+		}
+		v, err := sszReadValue(b[offset:end], fd, l.NewElement())
+		if err != nil {
+			return err
+		}
+		l.Append(v)
+	}
+	return nil
+}
+
+// sszReadValue decodes a single value of the given field from b. For message fields, the value
+// is read into the message held by newValue.
+func sszReadValue(
+	b []byte,
+	fd protoreflect.FieldDescriptor,
+	newValue protoreflect.Value,
+) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.Uint64Kind:
+		if len(b) != 8 {
+			return protoreflect.Value{}, errors.Errorf("invalid SSZ uint64 length for field %s", fd.FullName())
+		}
+		return protoreflect.ValueOfUint64(binary.LittleEndian.Uint64(b)), nil
+	case protoreflect.BoolKind:
+		if len(b) != 1 || b[0] > 1 {
+			return protoreflect.Value{}, errors.Errorf("invalid SSZ boolean for field %s", fd.FullName())
+		}
+		return protoreflect.ValueOfBool(b[0] == 1), nil
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes(bytes.Clone(b)), nil
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(string(b)), nil
+	case protoreflect.MessageKind:
+		if err := sszReadMessage(b, newValue.Message()); err != nil {
+			return protoreflect.Value{}, err
+		}
+		return newValue, nil
+	default:
+		return protoreflect.Value{}, unsupportedField(fd)
+	}
+}
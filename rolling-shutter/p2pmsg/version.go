@@ -0,0 +1,65 @@
+package p2pmsg
+
+import (
+	"github.com/pkg/errors"
+)
+
+// VersionTranslator converts the messages of a topic between the current protocol version and an
+// adjacent version in which their wire format differs.
+type VersionTranslator struct {
+	// ToVersion converts a message of the current version to the adjacent version.
+	ToVersion func(Message) (Message, error)
+	// FromVersion converts a message of the adjacent version to the current version.
+	FromVersion func(Message) (Message, error)
+}
+
+// versionTranslators holds the registered translators by protocol version and topic.
+var versionTranslators = map[uint64]map[string]VersionTranslator{}
+
+// RegisterVersionTranslator registers how messages of the topic are translated to and from the
+// given adjacent protocol version. Messages of topics without a translator are bridged unchanged,
+// so a translator only has to be registered for messages that changed between the versions. It
+// must be called during initialization, e.g. in an init function.
+func RegisterVersionTranslator(version uint64, topic string, translator VersionTranslator) {
+	if !IsAdjacentProtocolVersion(version) {
+		panic(errors.Errorf("protocol version %d is not adjacent to %d", version, ProtocolVersion))
+	}
+	if versionTranslators[version] == nil {
+		versionTranslators[version] = map[string]VersionTranslator{}
+	}
+	if _, ok := versionTranslators[version][topic]; ok {
+		panic(errors.Errorf("translator for topic %s and protocol version %d already registered", topic, version))
+	}
+	versionTranslators[version][topic] = translator
+}
+
+// IsAdjacentProtocolVersion checks if messages can be bridged between the given protocol version
+// and the current one.
+func IsAdjacentProtocolVersion(version uint64) bool {
+	return version+1 == ProtocolVersion || version == ProtocolVersion+1
+}
+
+// TranslateToVersion converts a message of the current protocol version to the given version.
+func TranslateToVersion(msg Message, version uint64) (Message, error) {
+	if version == ProtocolVersion {
+		return msg, nil
+	}
+	translator, ok := versionTranslators[version][msg.Topic()]
+	if !ok {
+		return msg, nil
+	}
+	return translator.ToVersion(msg)
+}
+
+// TranslateFromVersion converts a message received on a topic of the given protocol version to
+// the current version.
+func TranslateFromVersion(msg Message, version uint64) (Message, error) {
+	if version == ProtocolVersion {
+		return msg, nil
+	}
+	translator, ok := versionTranslators[version][msg.Topic()]
+	if !ok {
+		return msg, nil
+	}
+	return translator.FromVersion(msg)
+}
@@ -0,0 +1,58 @@
+package p2pmsg
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprtopics"
+)
+
+func TestVersionTranslation(t *testing.T) {
+	nextVersion := uint64(ProtocolVersion + 1)
+	// pretend the next version got rid of the instance id in decryption keys
+	RegisterVersionTranslator(nextVersion, kprtopics.DecryptionKey, VersionTranslator{
+		ToVersion: func(m Message) (Message, error) {
+			key := proto.Clone(m).(*DecryptionKey)
+			key.InstanceID = 0
+			return key, nil
+		},
+		FromVersion: func(m Message) (Message, error) {
+			key := proto.Clone(m).(*DecryptionKey)
+			key.InstanceID = 42
+			return key, nil
+		},
+	})
+	t.Cleanup(func() { delete(versionTranslators, nextVersion) })
+
+	msg := &DecryptionKey{InstanceID: 42, Eon: 1, EpochID: []byte{2}, Key: []byte{3}}
+	unchanged, err := TranslateToVersion(msg, ProtocolVersion)
+	assert.NilError(t, err)
+	assert.Equal(t, unchanged, Message(msg))
+
+	translated, err := TranslateToVersion(msg, nextVersion)
+	assert.NilError(t, err)
+	assert.Equal(t, translated.GetInstanceID(), uint64(0))
+	assert.Equal(t, msg.InstanceID, uint64(42))
+
+	data, err := MarshalEncoding(translated, nil, EncodingCBOR)
+	assert.NilError(t, err)
+	topic := VersionedTopic(42, nextVersion, EncodedTopic(kprtopics.DecryptionKey, EncodingCBOR))
+	received, _, err := UnmarshalTopic(topic, data)
+	assert.NilError(t, err)
+	assert.Check(t, proto.Equal(received, msg))
+
+	// topics without a translator are bridged unchanged
+	trigger := &DecryptionTrigger{InstanceID: 42, EpochID: []byte{2}}
+	translated, err = TranslateToVersion(trigger, nextVersion)
+	assert.NilError(t, err)
+	assert.Equal(t, translated, Message(trigger))
+}
+
+func TestIsAdjacentProtocolVersion(t *testing.T) {
+	assert.Check(t, IsAdjacentProtocolVersion(ProtocolVersion-1))
+	assert.Check(t, IsAdjacentProtocolVersion(ProtocolVersion+1))
+	assert.Check(t, !IsAdjacentProtocolVersion(ProtocolVersion))
+	assert.Check(t, !IsAdjacentProtocolVersion(ProtocolVersion+2))
+}
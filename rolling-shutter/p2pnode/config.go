@@ -16,7 +16,8 @@ func NewConfig() *Config {
 }
 
 type Config struct {
-	ListenMessages bool `comment:"whether to register handlers on the messages and log them"`
+	InstanceID     uint64 `comment:"instance whose topics are joined, unless the p2p topic namespace is global"`
+	ListenMessages bool   `comment:"whether to register handlers on the messages and log them"`
 
 	P2P *p2p.Config
 }
@@ -39,7 +40,12 @@ func (c *Config) SetDefaultValues() error {
 }
 
 func (c *Config) SetExampleValues() error {
-	return c.SetDefaultValues()
+	err := c.SetDefaultValues()
+	if err != nil {
+		return err
+	}
+	c.InstanceID = 42
+	return nil
 }
 
 func (c Config) TOMLWriteHeader(w io.Writer) (int, error) {
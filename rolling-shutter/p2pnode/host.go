@@ -35,7 +35,7 @@ func (dummyMessageHandler) MessagePrototypes() []p2pmsg.Message {
 }
 
 func New(config *Config) (service.Service, error) {
-	p2pHandler, err := p2p.New(config.P2P)
+	p2pHandler, err := p2p.New(config.P2P, config.InstanceID)
 	if err != nil {
 		return nil, err
 	}
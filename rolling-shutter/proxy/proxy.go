@@ -17,6 +17,7 @@ import (
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/url"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/tlsconfig"
 )
 
 func NewConfig() *Config {
@@ -28,12 +29,14 @@ func NewConfig() *Config {
 func (c *Config) Init() {
 	c.SequencerURL = &url.URL{}
 	c.CollatorURL = &url.URL{}
+	c.HTTPTLS = tlsconfig.NewServerConfig()
 }
 
 type Config struct {
 	CollatorURL       *url.URL
 	SequencerURL      *url.URL
 	HTTPListenAddress string
+	HTTPTLS           *tlsconfig.ServerConfig
 }
 
 func (c *Config) Validate() error {
@@ -46,7 +49,7 @@ func (c *Config) Validate() error {
 	if c.HTTPListenAddress == "" {
 		return errors.Errorf("configuration value HTTPListenAddress is missing")
 	}
-	return nil
+	return c.HTTPTLS.Validate()
 }
 
 func (c *Config) Name() string {
@@ -123,7 +126,9 @@ func Run(ctx context.Context, config *Config) error {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 	errorgroup, errorctx := errgroup.WithContext(ctx)
-	errorgroup.Go(httpServer.ListenAndServe)
+	errorgroup.Go(func() error {
+		return config.HTTPTLS.ListenAndServe(httpServer)
+	})
 	errorgroup.Go(func() error {
 		<-errorctx.Done()
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -2,12 +2,16 @@ package snapshot
 
 import (
 	"io"
+	"time"
 
 	"github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/address"
+	enctime "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/time"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/metricsserver"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/tlsconfig"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 )
 
@@ -23,6 +27,8 @@ func (c *Config) Init() {
 	c.P2P = p2p.NewConfig()
 	c.Ethereum = configuration.NewEthnodeConfig()
 	c.Metrics = metricsserver.NewConfig()
+	c.JSONRPCTLS = tlsconfig.NewServerConfig()
+	c.ProposalSyncInterval = &enctime.Duration{}
 }
 
 type Config struct {
@@ -30,8 +36,12 @@ type Config struct {
 	DatabaseURL    string `shconfig:",required"`
 	SnapshotHubURL string `shconfig:",required"`
 
+	SnapshotHubGraphQLURL string            `comment:"Snapshot Hub GraphQL API to sync proposals from, empty disables the sync"`
+	ProposalSyncInterval  *enctime.Duration `comment:"How often proposals are synced and ended proposals are triggered"`
+
 	JSONRPCHost string
 	JSONRPCPort uint16
+	JSONRPCTLS  *tlsconfig.ServerConfig
 
 	P2P      *p2p.Config
 	Ethereum *configuration.EthnodeConfig
@@ -39,7 +49,13 @@ type Config struct {
 }
 
 func (c *Config) Validate() error {
-	return nil
+	if err := c.JSONRPCTLS.Validate(); err != nil {
+		return err
+	}
+	if c.SnapshotHubGraphQLURL != "" && c.ProposalSyncInterval.Duration <= 0 {
+		return errors.New("ProposalSyncInterval must be positive if SnapshotHubGraphQLURL is set")
+	}
+	return c.Metrics.Validate()
 }
 
 func (c *Config) Name() string {
@@ -54,6 +70,7 @@ func (c *Config) SetDefaultValues() error {
 	c.Metrics.Enabled = false
 	c.Metrics.Host = "127.0.0.1"
 	c.Metrics.Port = 9191
+	c.ProposalSyncInterval = &enctime.Duration{Duration: 30 * time.Second}
 	return nil
 }
 
@@ -4,20 +4,26 @@ import (
 	"context"
 	"encoding/hex"
 	"log"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/AdamSLevy/jsonrpc2/v14"
 )
 
 type HubAPI struct {
-	BaseURL string
-	Client  jsonrpc2.Client
+	BaseURL    string
+	Client     jsonrpc2.Client
+	GraphQLURL string
+	HTTPClient *http.Client
 }
 
-func New(hubURL string) *HubAPI {
+func New(hubURL string, graphQLURL string) *HubAPI {
 	return &HubAPI{
-		BaseURL: hubURL,
-		Client:  jsonrpc2.Client{},
+		BaseURL:    hubURL,
+		Client:     jsonrpc2.Client{},
+		GraphQLURL: graphQLURL,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
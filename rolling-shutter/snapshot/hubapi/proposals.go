@@ -0,0 +1,130 @@
+package hubapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// proposalPageSize is the number of proposals requested from the GraphQL API at once.
+const proposalPageSize = 100
+
+const proposalsQuery = `query Proposals($first: Int!, $skip: Int!, $created: Int!) {
+  proposals(
+    first: $first
+    skip: $skip
+    where: {privacy: "shutter", created_gte: $created}
+    orderBy: "created"
+    orderDirection: asc
+  ) {
+    id
+    space {
+      id
+    }
+    created
+    end
+  }
+}`
+
+// Proposal is a proposal on Snapshot Hub whose votes are encrypted with Shutter.
+type Proposal struct {
+	ID      string
+	Space   string
+	Created time.Time
+	End     time.Time
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+type proposalsResponse struct {
+	Data struct {
+		Proposals []struct {
+			ID    string `json:"id"`
+			Space struct {
+				ID string `json:"id"`
+			} `json:"space"`
+			Created int64 `json:"created"`
+			End     int64 `json:"end"`
+		} `json:"proposals"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// GetProposals returns the Shutter-enabled proposals created at or after the given time from the
+// GraphQL API, oldest first.
+func (hub *HubAPI) GetProposals(ctx context.Context, createdSince time.Time) ([]Proposal, error) {
+	if hub.GraphQLURL == "" {
+		return nil, errors.New("no GraphQL URL of Snapshot Hub configured")
+	}
+	proposals := []Proposal{}
+	for skip := 0; ; skip += proposalPageSize {
+		page, err := hub.getProposalPage(ctx, createdSince, skip)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, page...)
+		if len(page) < proposalPageSize {
+			return proposals, nil
+		}
+	}
+}
+
+func (hub *HubAPI) getProposalPage(ctx context.Context, createdSince time.Time, skip int) ([]Proposal, error) {
+	body, err := json.Marshal(graphQLRequest{
+		Query: proposalsQuery,
+		Variables: map[string]interface{}{
+			"first":   proposalPageSize,
+			"skip":    skip,
+			"created": createdSince.Unix(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hub.GraphQLURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := hub.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query proposals")
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read proposals")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response status %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var response proposalsResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, errors.Wrap(err, "failed to decode proposals")
+	}
+	if len(response.Errors) > 0 {
+		return nil, errors.Errorf("failed to query proposals: %s", response.Errors[0].Message)
+	}
+	proposals := make([]Proposal, len(response.Data.Proposals))
+	for i, p := range response.Data.Proposals {
+		proposals[i] = Proposal{
+			ID:      p.ID,
+			Space:   p.Space.ID,
+			Created: time.Unix(p.Created, 0).UTC(),
+			End:     time.Unix(p.End, 0).UTC(),
+		}
+	}
+	return proposals, nil
+}
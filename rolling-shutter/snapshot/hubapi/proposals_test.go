@@ -0,0 +1,56 @@
+package hubapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestGetProposals(t *testing.T) {
+	numProposals := proposalPageSize + 3
+	since := time.Unix(1700000000, 0).UTC()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, req.Variables["created"], float64(since.Unix()))
+		skip := int(req.Variables["skip"].(float64))
+		proposals := []map[string]interface{}{}
+		for i := skip; i < numProposals && i < skip+proposalPageSize; i++ {
+			proposals = append(proposals, map[string]interface{}{
+				"id":      fmt.Sprintf("0x%064x", i),
+				"space":   map[string]string{"id": "shutter.eth"},
+				"created": since.Unix() + int64(i),
+				"end":     since.Unix() + int64(i) + 3600,
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"proposals": proposals},
+		})
+	}))
+	defer server.Close()
+
+	hub := New("", server.URL)
+	proposals, err := hub.GetProposals(context.Background(), since)
+	assert.NilError(t, err)
+	assert.Equal(t, len(proposals), numProposals)
+	last := proposals[numProposals-1]
+	assert.Equal(t, last.ID, fmt.Sprintf("0x%064x", numProposals-1))
+	assert.Equal(t, last.Space, "shutter.eth")
+	assert.Equal(t, last.End, since.Add(time.Duration(numProposals-1)*time.Second+time.Hour))
+}
+
+func TestGetProposalsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors": [{"message": "rate limited"}]}`))
+	}))
+	defer server.Close()
+
+	_, err := New("", server.URL).GetProposals(context.Background(), time.Time{})
+	assert.ErrorContains(t, err, "rate limited")
+}
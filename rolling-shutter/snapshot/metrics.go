@@ -24,9 +24,19 @@ var metricEons = prometheus.NewCounter(
 	},
 )
 
+var metricProposalsTriggered = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "snapshot",
+		Name:      "proposals_triggered_total",
+		Help:      "Number of ended proposals key generation was triggered for",
+	},
+)
+
 func (snp *Snapshot) initMetrics(ctx context.Context) error {
 	prometheus.MustRegister(metricEons)
 	prometheus.MustRegister(metricKeysGenerated)
+	prometheus.MustRegister(metricProposalsTriggered)
 
 	eonCount, err := snp.db.GetEonCount(ctx)
 	if err != nil {
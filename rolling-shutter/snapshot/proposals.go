@@ -0,0 +1,122 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/snpdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+)
+
+// ProposalEpochID returns the ID of the epoch whose decryption key decrypts the votes of a proposal.
+// Snapshot Hub uses the proposal ID, a 32 byte hash, as the epoch ID.
+func ProposalEpochID(proposalID string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(proposalID, "0x"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "proposal id %s is not hex encoded", proposalID)
+	}
+	epochID, err := epochid.BytesToEpochID(b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid proposal id %s", proposalID)
+	}
+	return epochID.Bytes(), nil
+}
+
+// syncProposals periodically syncs the Shutter-enabled proposals from Snapshot Hub and triggers
+// key generation for the proposals that have ended.
+func (snp *Snapshot) syncProposals(ctx context.Context) error {
+	ticker := time.NewTicker(snp.Config.ProposalSyncInterval.Duration)
+	defer ticker.Stop()
+	for {
+		if err := snp.fetchProposals(ctx); err != nil {
+			log.Warn().Err(err).Msg("failed to sync proposals from Snapshot Hub")
+		}
+		if err := snp.triggerEndedProposals(ctx); err != nil {
+			log.Warn().Err(err).Msg("failed to trigger ended proposals")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetchProposals stores the proposals created since the most recent one we know of.
+func (snp *Snapshot) fetchProposals(ctx context.Context) error {
+	createdSince, err := snp.db.GetLatestProposalCreatedAt(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get latest proposal from db")
+	}
+	proposals, err := snp.hubapi.GetProposals(ctx, createdSince)
+	if err != nil {
+		return err
+	}
+	for _, proposal := range proposals {
+		epochID, err := ProposalEpochID(proposal.ID)
+		if err != nil {
+			log.Debug().Err(err).Str("proposal", proposal.ID).Msg("ignoring proposal")
+			continue
+		}
+		err = snp.db.UpsertProposal(ctx, snpdb.UpsertProposalParams{
+			ProposalID: proposal.ID,
+			EpochID:    epochID,
+			Space:      proposal.Space,
+			CreatedAt:  proposal.Created,
+			EndTime:    proposal.End,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to store proposal %s", proposal.ID)
+		}
+	}
+	return nil
+}
+
+// triggerEndedProposals requests the decryption keys of the proposals that have ended. Proposals
+// whose trigger fails are retried in the next round.
+func (snp *Snapshot) triggerEndedProposals(ctx context.Context) error {
+	now := time.Now()
+	proposals, err := snp.db.GetEndedProposalsToTrigger(ctx, now)
+	if err != nil {
+		return errors.Wrap(err, "failed to get ended proposals from db")
+	}
+	for _, proposal := range proposals {
+		if err := snp.handleDecryptionKeyRequest(ctx, proposal.EpochID); err != nil {
+			log.Warn().Err(err).Str("proposal", proposal.ProposalID).Msg("failed to trigger proposal")
+			continue
+		}
+		err := snp.db.SetProposalTriggered(ctx, snpdb.SetProposalTriggeredParams{
+			TriggeredAt: sql.NullTime{Time: now, Valid: true},
+			ProposalID:  proposal.ProposalID,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to mark proposal %s as triggered", proposal.ProposalID)
+		}
+		metricProposalsTriggered.Inc()
+		log.Info().Str("proposal", proposal.ProposalID).Str("space", proposal.Space).
+			Time("end", proposal.EndTime).Msg("triggered key generation for ended proposal")
+	}
+	return nil
+}
+
+// getProposalKey returns the decryption key of a proposal, or nil if it isn't known yet.
+func (snp *Snapshot) getProposalKey(ctx context.Context, proposalID string) ([]byte, error) {
+	epochID, err := ProposalEpochID(proposalID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := snp.db.GetDecryptionKey(ctx, epochID)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return key.Key, nil
+}
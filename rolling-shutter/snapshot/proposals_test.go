@@ -0,0 +1,20 @@
+package snapshot
+
+import (
+	"bytes"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestProposalEpochID(t *testing.T) {
+	epochID, err := ProposalEpochID("0x" + "ab" + "00000000000000000000000000000000000000000000000000000000000001")
+	assert.NilError(t, err)
+	assert.Assert(t, bytes.Equal(epochID[:1], []byte{0xab}))
+	assert.Equal(t, epochID[31], byte(1))
+
+	_, err = ProposalEpochID("QmWbpCtwdLzxuLKnMW4Vv4MPFd2pdPX71YBKPasfZxqLUS")
+	assert.ErrorContains(t, err, "not hex encoded")
+	_, err = ProposalEpochID("0x1234")
+	assert.ErrorContains(t, err, "invalid proposal id")
+}
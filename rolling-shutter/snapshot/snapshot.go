@@ -35,7 +35,7 @@ type Snapshot struct {
 }
 
 func New(config *Config) (service.Service, error) {
-	p2pInstance, err := p2p.New(config.P2P)
+	p2pInstance, err := p2p.New(config.P2P, config.InstanceID)
 	snp := &Snapshot{
 		Config: config,
 		p2p:    p2pInstance,
@@ -43,8 +43,10 @@ func New(config *Config) (service.Service, error) {
 	snp.jrpc = snpjrpc.New(
 		snp.Config.JSONRPCHost,
 		snp.Config.JSONRPCPort,
+		snp.Config.JSONRPCTLS,
 		snp.handleDecryptionKeyRequest,
 		snp.handleRequestEonKey,
+		snp.getProposalKey,
 	)
 	return snp, err
 }
@@ -83,7 +85,7 @@ func (snp *Snapshot) Start(ctx context.Context, runner service.Runner) error {
 		snp.metricsServer = metricsserver.New(snp.Config.Metrics)
 	}
 
-	hub := hubapi.New(snp.Config.SnapshotHubURL)
+	hub := hubapi.New(snp.Config.SnapshotHubURL, snp.Config.SnapshotHubGraphQLURL)
 	snp.hubapi = hub
 
 	snp.setupP2PHandler()
@@ -98,6 +100,9 @@ func (snp *Snapshot) getServices() []service.Service {
 	if snp.Config.Metrics.Enabled {
 		services = append(services, snp.metricsServer)
 	}
+	if snp.Config.SnapshotHubGraphQLURL != "" {
+		services = append(services, service.ServiceFn{Fn: snp.syncProposals})
+	}
 	return services
 }
 
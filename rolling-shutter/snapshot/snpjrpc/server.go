@@ -14,14 +14,17 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/tlsconfig"
 )
 
 type SnpJRPC struct {
 	Server *jrpc2.Server
 
 	httpServer               *http.Server
+	tlsConfig                *tlsconfig.ServerConfig
 	getDecryptionKeyCallback func(ctx context.Context, epochID []byte) error
 	requestEonKeyCallback    func(ctx context.Context) error
+	getProposalKeyCallback   func(ctx context.Context, proposalID string) ([]byte, error)
 }
 
 type HexEncodedByteArray []byte
@@ -31,6 +34,28 @@ type GetDecryptionKeyParams struct {
 	EpochID *HexEncodedByteArray `json:"proposal"`
 }
 
+type GetProposalKeyParams struct {
+	ProposalID *string `json:"proposal"`
+}
+
+func (gpkp *GetProposalKeyParams) FromPositional(params []interface{}) error {
+	if len(params) != 1 {
+		return errors.Errorf("One parameter required")
+	}
+	proposalID, ok := params[0].(string)
+	if !ok {
+		return errors.Errorf("Proposal id must be a string")
+	}
+	gpkp.ProposalID = &proposalID
+	return nil
+}
+
+// ProposalKey is the result of get_proposal_key. Key is null if the key isn't known yet.
+type ProposalKey struct {
+	ProposalID string               `json:"proposal"`
+	Key        *HexEncodedByteArray `json:"key"`
+}
+
 func (b HexEncodedByteArray) MarshalJSON() ([]byte, error) {
 	hexString := hex.EncodeToString(b)
 	return json.Marshal(hexString)
@@ -116,11 +141,49 @@ func (snpjrpc *SnpJRPC) RequestEonKey(ctx context.Context, _ json.RawMessage) (
 	return true, nil
 }
 
+func (snpjrpc *SnpJRPC) GetProposalKey(ctx context.Context, params json.RawMessage) (
+	interface{},
+	*jrpc2.ErrorObject,
+) {
+	gpkParams := new(GetProposalKeyParams)
+	if err := jrpc2.ParseParams(params, gpkParams); err != nil {
+		return nil, err
+	}
+	if gpkParams.ProposalID == nil {
+		return nil, &jrpc2.ErrorObject{
+			Code:    jrpc2.InvalidParamsCode,
+			Message: jrpc2.InvalidParamsMsg,
+			Data:    "One parameter required",
+		}
+	}
+
+	key, err := snpjrpc.getProposalKeyCallback(ctx, *gpkParams.ProposalID)
+	if err != nil {
+		return nil, &jrpc2.ErrorObject{
+			Code:    jrpc2.InternalErrorCode,
+			Message: jrpc2.InternalErrorMsg,
+			Data: fmt.Sprintf(
+				"Error getting decryption key for proposal %s: %v",
+				*gpkParams.ProposalID,
+				err,
+			),
+		}
+	}
+	result := ProposalKey{ProposalID: *gpkParams.ProposalID}
+	if key != nil {
+		hexKey := HexEncodedByteArray(key)
+		result.Key = &hexKey
+	}
+	return result, nil
+}
+
 func New(
 	jsonrpcHost string,
 	jsonrpcPort uint16,
+	tlsConfig *tlsconfig.ServerConfig,
 	getDecryptionKeyCallback func(ctx context.Context, epochID []byte) error,
 	requestEonKeyCallback func(ctx context.Context) error,
+	getProposalKeyCallback func(ctx context.Context, proposalID string) ([]byte, error),
 ) *SnpJRPC {
 	host := fmt.Sprintf("%s:%d", jsonrpcHost, jsonrpcPort)
 	server := jrpc2.NewServer(host, "/api/v1/rpc", nil)
@@ -129,8 +192,10 @@ func New(
 		Server: server,
 
 		httpServer:               nil,
+		tlsConfig:                tlsConfig,
 		getDecryptionKeyCallback: getDecryptionKeyCallback,
 		requestEonKeyCallback:    requestEonKeyCallback,
+		getProposalKeyCallback:   getProposalKeyCallback,
 	}
 
 	server.RegisterWithContext(
@@ -141,6 +206,10 @@ func New(
 		"request_eon_key",
 		jrpc2.MethodWithContext{Method: jrpc.RequestEonKey},
 	)
+	server.RegisterWithContext(
+		"get_proposal_key",
+		jrpc2.MethodWithContext{Method: jrpc.GetProposalKey},
+	)
 
 	return &jrpc
 }
@@ -149,7 +218,7 @@ func (snpjrpc *SnpJRPC) Start(ctx context.Context, group service.Runner) error {
 	group.Go(func() error {
 		httpServer := snpjrpc.Server.Prepare()
 		log.Info().Str("address", snpjrpc.Server.Host).Msg("Running JSON-RPC server at")
-		if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+		if err := snpjrpc.tlsConfig.ListenAndServe(httpServer); err != http.ErrServerClosed {
 			return err
 		}
 		return nil
@@ -6,137 +6,69 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
-	"github.com/tendermint/tendermint/rpc/client"
-	tmhttp "github.com/tendermint/tendermint/rpc/client/http"
 
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/chainobserver"
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/contract/deployment"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/chainobsdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/db/kprdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/epochkghandler"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/fx"
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprapi"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/smobserver"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/eventsyncer"
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/metricsserver"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/triggeroffset"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p/provenance"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
 )
 
 type snapshotkeyper struct {
-	config            *keyper.Config
-	dbpool            *pgxpool.Pool
-	shuttermintClient client.Client
-	messageSender     fx.RPCMessageSender
-	l1Client          *ethclient.Client
-	contracts         *deployment.Contracts
+	*keyper.Node
+	config  *keyper.Config
+	options keyper.Options
 
+	messageSender    fx.RPCMessageSender
 	shuttermintState *smobserver.ShuttermintState
-	p2p              *p2p.P2PHandler
-	metricsServer    *metricsserver.MetricsServer
 }
 
-func New(config *keyper.Config) service.Service {
-	return &snapshotkeyper{config: config}
+func New(config *keyper.Config, options keyper.Options) service.Service {
+	return &snapshotkeyper{config: config, options: options}
 }
 
 func (snkpr *snapshotkeyper) Start(ctx context.Context, runner service.Runner) error {
 	config := snkpr.config
-	dbpool, err := pgxpool.Connect(ctx, config.DatabaseURL)
-	if err != nil {
-		return errors.Wrap(err, "failed to connect to database")
-	}
-	runner.Defer(dbpool.Close)
-	shdb.AddConnectionInfo(log.Info(), dbpool).Msg("connected to database")
-
-	l1Client, err := ethclient.Dial(config.Ethereum.EthereumURL)
+	node, err := keyper.StartNode(ctx, runner, config, snkpr.options, keyper.NodeSpec{
+		Name:         "snapshotkeyper",
+		ContractsURL: config.Ethereum.EthereumURL,
+	})
 	if err != nil {
 		return err
 	}
-	contracts, err := deployment.NewContracts(l1Client, config.Ethereum.DeploymentDir)
-	if err != nil {
-		return err
-	}
-
-	err = kprdb.ValidateKeyperDB(ctx, dbpool)
-	if err != nil {
-		return err
-	}
-	err = keyper.LinkConfigToDB(ctx, config, dbpool)
-	if err != nil {
-		return err
-	}
-	shuttermintClient, err := tmhttp.New(config.Shuttermint.ShuttermintURL, "/websocket")
-	if err != nil {
-		return err
-	}
-	messageSender := fx.NewRPCMessageSender(shuttermintClient, config.Ethereum.PrivateKey.Key)
-
-	p2pHandler, err := p2p.New(config.P2P)
-	if err != nil {
-		return err
-	}
-
-	if snkpr.config.Metrics.Enabled {
-		epochkghandler.InitMetrics()
-		snkpr.metricsServer = metricsserver.New(snkpr.config.Metrics)
-	}
-
-	snkpr.dbpool = dbpool
-	snkpr.shuttermintClient = shuttermintClient
-	snkpr.messageSender = messageSender
-	snkpr.l1Client = l1Client
-	snkpr.contracts = contracts
+	snkpr.Node = node
+	snkpr.messageSender = fx.NewRPCMessageSender(node.ShuttermintClient, config.Ethereum.PrivateKey.Key)
 	snkpr.shuttermintState = smobserver.NewShuttermintState(config)
-	snkpr.p2p = p2pHandler
 
 	snkpr.setupP2PHandler()
 	return runner.StartService(snkpr.getServices()...)
 }
 
 func (snkpr *snapshotkeyper) setupP2PHandler() {
-	snkpr.p2p.AddMessageHandler(
-		epochkghandler.NewDecryptionKeyHandler(snkpr.config, snkpr.dbpool),
-		epochkghandler.NewDecryptionKeyShareHandler(snkpr.config, snkpr.dbpool),
-		epochkghandler.NewDecryptionTriggerHandler(snkpr.config, snkpr.dbpool),
-		epochkghandler.NewEonPublicKeyHandler(snkpr.config, snkpr.dbpool),
-	)
+	epochIDs := epochkghandler.NewEpochIDValidator(snkpr.config.GetEpochIDMode(), snkpr.L1Client, snkpr.Beacon)
+	// snapshot triggers are not pre-announced, so there is nothing to apply an offset to
+	handlers := snkpr.MessageHandlers(epochIDs, nil, triggeroffset.Offset{})
+	snkpr.P2P.AddMessageHandler(provenance.Wrap(snkpr.DBPool, snkpr.Storage, handlers...)...)
+	snkpr.P2P.AddMessageHandler(snkpr.AttestationHandler()...)
 }
 
 func (snkpr *snapshotkeyper) getServices() []service.Service {
-	services := []service.Service{
-		snkpr.p2p,
+	return append(snkpr.Services(),
 		service.ServiceFn{Fn: snkpr.operateShuttermint},
 		service.ServiceFn{Fn: snkpr.broadcastEonPublicKeys},
-		service.ServiceFn{Fn: snkpr.handleContractEvents},
-	}
-
-	if snkpr.config.HTTPEnabled {
-		services = append(services, kprapi.NewHTTPService(snkpr.dbpool, snkpr.config, snkpr.p2p))
-	}
-	if snkpr.config.Metrics.Enabled {
-		services = append(services, snkpr.metricsServer)
-	}
-	return services
-}
-
-func (snkpr *snapshotkeyper) handleContractEvents(ctx context.Context) error {
-	events := []*eventsyncer.EventType{
-		snkpr.contracts.KeypersConfigsListNewConfig,
-		snkpr.contracts.CollatorConfigsListNewConfig,
-	}
-	return chainobserver.New(snkpr.contracts, snkpr.dbpool).Observe(ctx, events)
+	)
 }
 
 func (snkpr *snapshotkeyper) handleOnChainChanges(ctx context.Context, tx pgx.Tx, l1BlockNumber uint64) error {
@@ -272,7 +204,7 @@ func (snkpr *snapshotkeyper) handleOnChainKeyperSetChanges(ctx context.Context,
 
 func (snkpr *snapshotkeyper) operateShuttermint(ctx context.Context) error {
 	for {
-		l1BlockNumber, err := retry.FunctionCall(ctx, snkpr.l1Client.BlockNumber)
+		l1BlockNumber, err := retry.FunctionCall(ctx, snkpr.L1Client.BlockNumber)
 		if err != nil {
 			log.Err(err).Msg("Error when getting block")
 			return err
@@ -280,15 +212,15 @@ func (snkpr *snapshotkeyper) operateShuttermint(ctx context.Context) error {
 
 		err = smobserver.SyncAppWithDB(
 			ctx,
-			snkpr.shuttermintClient,
-			snkpr.dbpool,
+			snkpr.ShuttermintClient,
+			snkpr.DBPool,
 			snkpr.shuttermintState,
 		)
 		if err != nil {
 			log.Err(err).Msg("Error on syncing app with db")
 			return err
 		}
-		err = snkpr.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		err = snkpr.DBPool.BeginFunc(ctx, func(tx pgx.Tx) error {
 			return snkpr.handleOnChainChanges(ctx, tx, l1BlockNumber)
 		})
 		if err != nil {
@@ -296,7 +228,7 @@ func (snkpr *snapshotkeyper) operateShuttermint(ctx context.Context) error {
 			return err
 		}
 
-		err = fx.SendShutterMessages(ctx, kprdb.New(snkpr.dbpool), &snkpr.messageSender)
+		err = fx.SendShutterMessages(ctx, kprdb.New(snkpr.DBPool), &snkpr.messageSender)
 		if err != nil {
 			log.Err(err).Msg("Error sending shutter messages")
 			return err
@@ -311,7 +243,7 @@ func (snkpr *snapshotkeyper) operateShuttermint(ctx context.Context) error {
 
 func (snkpr *snapshotkeyper) broadcastEonPublicKeys(ctx context.Context) error {
 	for {
-		eonPublicKeys, err := kprdb.New(snkpr.dbpool).GetAndDeleteEonPublicKeys(ctx)
+		eonPublicKeys, err := kprdb.New(snkpr.DBPool).GetAndDeleteEonPublicKeys(ctx)
 		if err != nil {
 			return err
 		}
@@ -320,21 +252,15 @@ func (snkpr *snapshotkeyper) broadcastEonPublicKeys(ctx context.Context) error {
 			if !exists {
 				return errors.Errorf("own keyper index not found for Eon=%d", eonPublicKey.Eon)
 			}
-			msg, err := p2pmsg.NewSignedEonPublicKey(
-				snkpr.config.InstanceID,
-				eonPublicKey.EonPublicKey,
-				uint64(eonPublicKey.ActivationBlockNumber),
-				uint64(eonPublicKey.KeyperConfigIndex),
-				uint64(eonPublicKey.Eon),
-				snkpr.config.Ethereum.PrivateKey.Key,
-			)
-			if err != nil {
-				return errors.Wrap(err, "error while signing EonPublicKey")
+			msg := &p2pmsg.EonPublicKey{
+				InstanceID:        snkpr.config.InstanceID,
+				PublicKey:         eonPublicKey.EonPublicKey,
+				ActivationBlock:   uint64(eonPublicKey.ActivationBlockNumber),
+				KeyperConfigIndex: uint64(eonPublicKey.KeyperConfigIndex),
+				Eon:               uint64(eonPublicKey.Eon),
 			}
-
-			err = snkpr.p2p.SendMessage(ctx, msg)
-			if err != nil {
-				return errors.Wrap(err, "error while broadcasting EonPublicKey")
+			if err := snkpr.BroadcastEonPublicKey(ctx, msg); err != nil {
+				return err
 			}
 		}
 		select {
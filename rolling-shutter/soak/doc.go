@@ -0,0 +1,8 @@
+// Package soak contains a long running test of the decryption key path. It runs a swarm of mock
+// keypers and an observer node in process for hours, withholds shares of a changing subset of
+// keypers and fails as soon as an invariant is violated: every epoch must be decrypted with a
+// valid key within the latency bound, keypers must not send conflicting shares and neither
+// goroutines nor heap may grow without bound.
+//
+// The test only builds with the soak tag, run it with `make test-soak`.
+package soak
@@ -0,0 +1,161 @@
+//go:build soak
+
+package soak
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// epochState collects the shares of an epoch until the threshold is reached.
+type epochState struct {
+	firstShare time.Time
+	shares     map[uint64][]byte
+	decrypted  time.Time
+}
+
+// Monitor aggregates the decryption key shares the keypers send, checks that the resulting keys
+// are valid and measures the time from the first share of an epoch until the threshold is
+// reached.
+type Monitor struct {
+	eonPublicKey *shcrypto.EonPublicKey
+	threshold    uint64
+	maxLatency   time.Duration
+	retention    time.Duration
+
+	mux        sync.Mutex
+	epochs     map[uint64]*epochState
+	latencies  []time.Duration
+	violations []error
+}
+
+func NewMonitor(
+	eonPublicKey *shcrypto.EonPublicKey,
+	threshold uint64,
+	maxLatency time.Duration,
+) *Monitor {
+	return &Monitor{
+		eonPublicKey: eonPublicKey,
+		threshold:    threshold,
+		maxLatency:   maxLatency,
+		retention:    10 * maxLatency,
+		epochs:       make(map[uint64]*epochState),
+	}
+}
+
+func (m *Monitor) violation(err error) {
+	m.violations = append(m.violations, err)
+}
+
+// HandleDecryptionKeyShares is the p2p handler for decryption key shares.
+func (m *Monitor) HandleDecryptionKeyShares(_ context.Context, msg p2pmsg.Message) ([]p2pmsg.Message, error) {
+	keyShares := msg.(*p2pmsg.DecryptionKeyShares)
+	now := time.Now()
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	for _, share := range keyShares.Shares {
+		epochID, err := epochid.BytesToEpochID(share.EpochID)
+		if err != nil {
+			return nil, err
+		}
+		epoch, ok := m.epochs[epochID.Uint64()]
+		if !ok {
+			epoch = &epochState{
+				firstShare: now,
+				shares:     make(map[uint64][]byte),
+			}
+			m.epochs[epochID.Uint64()] = epoch
+		}
+		if previous, ok := epoch.shares[keyShares.KeyperIndex]; ok {
+			if !bytes.Equal(previous, share.Share) {
+				m.violation(errors.Errorf("keyper %d sent conflicting shares for epoch %s",
+					keyShares.KeyperIndex, epochID.Hex()))
+			}
+			continue
+		}
+		epoch.shares[keyShares.KeyperIndex] = share.Share
+		if epoch.decrypted.IsZero() && uint64(len(epoch.shares)) >= m.threshold {
+			m.decrypt(epochID, epoch, now)
+		}
+	}
+	return nil, nil
+}
+
+// decrypt computes the epoch secret key from the shares of the epoch and checks it against the eon
+// public key.
+func (m *Monitor) decrypt(epochID epochid.EpochID, epoch *epochState, now time.Time) {
+	epoch.decrypted = now
+	latency := now.Sub(epoch.firstShare)
+	m.latencies = append(m.latencies, latency)
+	if latency > m.maxLatency {
+		m.violation(errors.Errorf("epoch %s took %s to decrypt", epochID.Hex(), latency))
+	}
+
+	indices := []int{}
+	shares := []*shcrypto.EpochSecretKeyShare{}
+	for keyperIndex, encoded := range epoch.shares {
+		share := new(shcrypto.EpochSecretKeyShare)
+		if err := share.Unmarshal(encoded); err != nil {
+			m.violation(errors.Wrapf(err, "keyper %d sent invalid share for epoch %s", keyperIndex, epochID.Hex()))
+			return
+		}
+		indices = append(indices, int(keyperIndex))
+		shares = append(shares, share)
+	}
+	epochSecretKey, err := shcrypto.ComputeEpochSecretKey(indices, shares, m.threshold)
+	if err != nil {
+		m.violation(errors.Wrapf(err, "failed to compute epoch secret key for epoch %s", epochID.Hex()))
+		return
+	}
+	ok, err := shcrypto.VerifyEpochSecretKey(epochSecretKey, m.eonPublicKey, epochID.Bytes())
+	if err != nil || !ok {
+		m.violation(errors.Errorf("invalid epoch secret key for epoch %s", epochID.Hex()))
+	}
+}
+
+// Check fails epochs that haven't been decrypted in time and forgets old ones, so that the monitor
+// itself doesn't leak memory over a long run. It returns the violations found since the last call.
+func (m *Monitor) Check(now time.Time) []error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	for epochID, epoch := range m.epochs {
+		age := now.Sub(epoch.firstShare)
+		if epoch.decrypted.IsZero() && age > m.maxLatency {
+			m.violation(errors.Errorf("epoch %s not decrypted after %s, got %d of %d shares",
+				epochid.Uint64ToEpochID(epochID).Hex(), age, len(epoch.shares), m.threshold))
+			epoch.decrypted = now
+		}
+		if age > m.retention {
+			delete(m.epochs, epochID)
+		}
+	}
+	violations := m.violations
+	m.violations = nil
+	return violations
+}
+
+// LatencyStats returns the number of decrypted epochs together with the median and maximum
+// latency since the last call and resets them.
+func (m *Monitor) LatencyStats() (int, time.Duration, time.Duration) {
+	m.mux.Lock()
+	latencies := m.latencies
+	m.latencies = nil
+	m.mux.Unlock()
+
+	if len(latencies) == 0 {
+		return 0, 0, 0
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return len(latencies), latencies[len(latencies)/2], latencies[len(latencies)-1]
+}
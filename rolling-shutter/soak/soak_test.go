@@ -0,0 +1,213 @@
+//go:build soak
+
+package soak
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/address"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/env"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/epochid"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testlog"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/mockkeypers"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+const (
+	durationVar     = "ROLLING_SHUTTER_SOAK_DURATION"
+	defaultDuration = 2 * time.Hour
+
+	numKeypers = 10
+	threshold  = 7
+	epochRate  = 5
+
+	maxLatency    = 5 * time.Second
+	checkInterval = 10 * time.Second
+	faultInterval = time.Minute
+	warmup        = 2 * time.Minute
+
+	// After the warmup, the heap may grow up to this factor and the number of goroutines by this
+	// amount before it's considered a leak.
+	maxHeapGrowth      = 3
+	maxGoroutineGrowth = 100
+
+	observerPort   = 23099
+	keyperBasePort = 23100
+)
+
+func init() {
+	testlog.Setup()
+}
+
+// faults decides which keypers withhold their shares. The set of faulty keypers changes every
+// faultInterval, but never contains more keypers than can fail without losing liveness.
+type faults struct {
+	mux    sync.Mutex
+	random *rand.Rand
+	faulty map[uint64]bool
+}
+
+func (f *faults) rotate() {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.faulty = make(map[uint64]bool)
+	n := f.random.Intn(numKeypers - threshold + 1)
+	for _, i := range f.random.Perm(numKeypers)[:n] {
+		f.faulty[uint64(i)] = true
+	}
+	log.Info().Int("num-faulty", n).Msg("rotated faulty keypers")
+}
+
+func (f *faults) isFaulty(keyperIndex uint64, _ epochid.EpochID) bool {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return f.faulty[keyperIndex]
+}
+
+func soakDuration(t *testing.T) time.Duration {
+	t.Helper()
+	s, ok := os.LookupEnv(durationVar)
+	if !ok {
+		return defaultDuration
+	}
+	d, err := time.ParseDuration(s)
+	assert.NilError(t, err)
+	return d
+}
+
+func newObserverConfig(t *testing.T) (*p2p.Config, *address.P2PAddress) {
+	t.Helper()
+	config := p2p.NewConfig()
+	assert.NilError(t, config.SetExampleValues())
+	config.Environment = env.EnvironmentLocal
+	config.ListenAddresses = []*address.P2PAddress{
+		address.MustP2PAddress(fmt.Sprintf("/ip4/127.0.0.1/tcp/%d", observerPort)),
+	}
+	peerID, err := config.P2PKey.PeerID()
+	assert.NilError(t, err)
+	bootstrapAddress := address.MustP2PAddress(fmt.Sprintf("/ip4/127.0.0.1/tcp/%d/p2p/%s", observerPort, peerID))
+	config.CustomBootstrapAddresses = []*address.P2PAddress{bootstrapAddress}
+	return config, bootstrapAddress
+}
+
+type heapStats struct {
+	heapAlloc  uint64
+	goroutines int
+}
+
+func readHeapStats() heapStats {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return heapStats{
+		heapAlloc:  m.HeapAlloc,
+		goroutines: runtime.NumGoroutine(),
+	}
+}
+
+func TestSoak(t *testing.T) {
+	duration := soakDuration(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	observerConfig, bootstrapAddress := newObserverConfig(t)
+
+	swarmConfig := mockkeypers.NewConfig()
+	assert.NilError(t, configuration.SetExampleValuesRecursive(swarmConfig))
+	swarmConfig.Count = numKeypers
+	swarmConfig.Threshold = threshold
+	swarmConfig.Rate = epochRate
+	swarmConfig.RespondToTriggers = false
+	swarmConfig.BasePort = keyperBasePort
+	swarmConfig.P2P.Environment = env.EnvironmentLocal
+	swarmConfig.P2P.CustomBootstrapAddresses = []*address.P2PAddress{bootstrapAddress}
+
+	f := &faults{random: rand.New(rand.NewSource(swarmConfig.EonKeySeed))} //nolint:gosec
+	swarm, err := mockkeypers.New(swarmConfig, mockkeypers.Options{Faulty: f.isFaulty})
+	assert.NilError(t, err)
+
+	observer, err := p2p.New(observerConfig, swarmConfig.InstanceID)
+	assert.NilError(t, err)
+	monitor := NewMonitor(swarm.PublicKey(), threshold, maxLatency)
+	observer.AddHandlerFunc(monitor.HandleDecryptionKeyShares, &p2pmsg.DecryptionKeyShares{})
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- service.Run(ctx, observer, swarm)
+	}()
+
+	start := time.Now()
+	checkTicker := time.NewTicker(checkInterval)
+	defer checkTicker.Stop()
+	faultTicker := time.NewTicker(faultInterval)
+	defer faultTicker.Stop()
+	var baseline *heapStats
+	for time.Since(start) < duration {
+		select {
+		case err := <-runErr:
+			t.Fatalf("testnet stopped unexpectedly: %v", err)
+		case <-faultTicker.C:
+			f.rotate()
+			continue
+		case <-checkTicker.C:
+		}
+
+		// Shares sent before the gossip mesh has formed get lost, so violations are only logged
+		// during the warmup.
+		warmedUp := time.Since(start) > warmup
+		violations := monitor.Check(time.Now())
+		for _, v := range violations {
+			if warmedUp {
+				t.Error(v)
+			} else {
+				log.Warn().Err(v).Msg("ignoring violation during warmup")
+			}
+		}
+		if warmedUp && len(violations) > 0 {
+			t.FailNow()
+		}
+		numDecrypted, median, maximum := monitor.LatencyStats()
+		if warmedUp && numDecrypted == 0 {
+			t.Fatalf("no epoch decrypted within %s", checkInterval)
+		}
+
+		stats := readHeapStats()
+		log.Info().
+			Str("elapsed", time.Since(start).Round(time.Second).String()).
+			Int("decrypted", numDecrypted).
+			Str("latency-median", median.String()).
+			Str("latency-max", maximum.String()).
+			Uint64("heap-alloc", stats.heapAlloc).
+			Int("goroutines", stats.goroutines).
+			Msg("soak test progress")
+		if baseline == nil {
+			if warmedUp {
+				baseline = &stats
+			}
+			continue
+		}
+		if stats.heapAlloc > maxHeapGrowth*baseline.heapAlloc {
+			t.Fatalf("heap grew from %d to %d bytes", baseline.heapAlloc, stats.heapAlloc)
+		}
+		if stats.goroutines > baseline.goroutines+maxGoroutineGrowth {
+			t.Fatalf("number of goroutines grew from %d to %d", baseline.goroutines, stats.goroutines)
+		}
+	}
+
+	cancel()
+	err = <-runErr
+	assert.Assert(t, err == nil || err == context.Canceled, "unexpected error: %v", err)
+}